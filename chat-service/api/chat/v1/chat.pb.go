@@ -33,6 +33,8 @@ const (
 	MessageType_MESSAGE_TYPE_IMAGE       MessageType = 2
 	MessageType_MESSAGE_TYPE_VIDEO       MessageType = 3
 	MessageType_MESSAGE_TYPE_FILE        MessageType = 4
+	MessageType_MESSAGE_TYPE_SYSTEM      MessageType = 5 // Thông báo hệ thống (đổi tên nhóm, thêm thành viên...): chỉ admin gửi được, không tính là chưa đọc
+	MessageType_MESSAGE_TYPE_CALL        MessageType = 6 // Sự kiện cuộc gọi (gọi nhỡ, kết thúc...): content mô tả cuộc gọi
 )
 
 // Enum value maps for MessageType.
@@ -43,6 +45,8 @@ var (
 		2: "MESSAGE_TYPE_IMAGE",
 		3: "MESSAGE_TYPE_VIDEO",
 		4: "MESSAGE_TYPE_FILE",
+		5: "MESSAGE_TYPE_SYSTEM",
+		6: "MESSAGE_TYPE_CALL",
 	}
 	MessageType_value = map[string]int32{
 		"MESSAGE_TYPE_UNSPECIFIED": 0,
@@ -50,6 +54,8 @@ var (
 		"MESSAGE_TYPE_IMAGE":       2,
 		"MESSAGE_TYPE_VIDEO":       3,
 		"MESSAGE_TYPE_FILE":        4,
+		"MESSAGE_TYPE_SYSTEM":      5,
+		"MESSAGE_TYPE_CALL":        6,
 	}
 )
 
@@ -80,16 +86,240 @@ func (MessageType) EnumDescriptor() ([]byte, []int) {
 	return file_chat_v1_chat_proto_rawDescGZIP(), []int{0}
 }
 
+// Trạng thái giao tin nhắn, tính theo góc nhìn của người gọi GetMessages.
+// Chỉ tin nhắn do chính người gọi gửi mới có status; tin nhắn của người khác là UNSPECIFIED.
+// Người nhận là các thành viên khác đã tham gia trước hoặc cùng lúc với tin nhắn.
+// - SENT: chưa giao tới người nhận nào (hoặc conversation không có người nhận)
+// - DELIVERED: đã giao (hoặc đã đọc) bởi ít nhất một người nhận, nhưng chưa phải tất cả đã đọc
+// - READ: tất cả người nhận đã đọc
+// Chat 1-1 chỉ có một người nhận nên DELIVERED/READ là của người đó; với nhóm,
+// DELIVERED là "ít nhất một người" còn READ là "tất cả mọi người".
+type MessageStatus int32
+
+const (
+	MessageStatus_MESSAGE_STATUS_UNSPECIFIED MessageStatus = 0
+	MessageStatus_MESSAGE_STATUS_SENT        MessageStatus = 1
+	MessageStatus_MESSAGE_STATUS_DELIVERED   MessageStatus = 2
+	MessageStatus_MESSAGE_STATUS_READ        MessageStatus = 3
+)
+
+// Enum value maps for MessageStatus.
+var (
+	MessageStatus_name = map[int32]string{
+		0: "MESSAGE_STATUS_UNSPECIFIED",
+		1: "MESSAGE_STATUS_SENT",
+		2: "MESSAGE_STATUS_DELIVERED",
+		3: "MESSAGE_STATUS_READ",
+	}
+	MessageStatus_value = map[string]int32{
+		"MESSAGE_STATUS_UNSPECIFIED": 0,
+		"MESSAGE_STATUS_SENT":        1,
+		"MESSAGE_STATUS_DELIVERED":   2,
+		"MESSAGE_STATUS_READ":        3,
+	}
+)
+
+func (x MessageStatus) Enum() *MessageStatus {
+	p := new(MessageStatus)
+	*p = x
+	return p
+}
+
+func (x MessageStatus) String() string {
+	return protoimpl.X.EnumStringOf(x.Descriptor(), protoreflect.EnumNumber(x))
+}
+
+func (MessageStatus) Descriptor() protoreflect.EnumDescriptor {
+	return file_chat_v1_chat_proto_enumTypes[1].Descriptor()
+}
+
+func (MessageStatus) Type() protoreflect.EnumType {
+	return &file_chat_v1_chat_proto_enumTypes[1]
+}
+
+func (x MessageStatus) Number() protoreflect.EnumNumber {
+	return protoreflect.EnumNumber(x)
+}
+
+// Deprecated: Use MessageStatus.Descriptor instead.
+func (MessageStatus) EnumDescriptor() ([]byte, []int) {
+	return file_chat_v1_chat_proto_rawDescGZIP(), []int{1}
+}
+
+// Loại conversation, quyết định quy tắc thành viên khi gửi tin nhắn
+// - DIRECT: đúng hai thành viên, không thể thêm người qua receiver_ids
+// - GROUP: chỉ thành viên mới được gửi; receiver_ids có thể thêm thành viên mới
+// - UNSPECIFIED: conversation tạo ngầm bởi SendMessage, không có ràng buộc
+type ConversationType int32
+
+const (
+	ConversationType_CONVERSATION_TYPE_UNSPECIFIED ConversationType = 0
+	ConversationType_CONVERSATION_TYPE_DIRECT      ConversationType = 1
+	ConversationType_CONVERSATION_TYPE_GROUP       ConversationType = 2
+)
+
+// Enum value maps for ConversationType.
+var (
+	ConversationType_name = map[int32]string{
+		0: "CONVERSATION_TYPE_UNSPECIFIED",
+		1: "CONVERSATION_TYPE_DIRECT",
+		2: "CONVERSATION_TYPE_GROUP",
+	}
+	ConversationType_value = map[string]int32{
+		"CONVERSATION_TYPE_UNSPECIFIED": 0,
+		"CONVERSATION_TYPE_DIRECT":      1,
+		"CONVERSATION_TYPE_GROUP":       2,
+	}
+)
+
+func (x ConversationType) Enum() *ConversationType {
+	p := new(ConversationType)
+	*p = x
+	return p
+}
+
+func (x ConversationType) String() string {
+	return protoimpl.X.EnumStringOf(x.Descriptor(), protoreflect.EnumNumber(x))
+}
+
+func (ConversationType) Descriptor() protoreflect.EnumDescriptor {
+	return file_chat_v1_chat_proto_enumTypes[2].Descriptor()
+}
+
+func (ConversationType) Type() protoreflect.EnumType {
+	return &file_chat_v1_chat_proto_enumTypes[2]
+}
+
+func (x ConversationType) Number() protoreflect.EnumNumber {
+	return protoreflect.EnumNumber(x)
+}
+
+// Deprecated: Use ConversationType.Descriptor instead.
+func (ConversationType) EnumDescriptor() ([]byte, []int) {
+	return file_chat_v1_chat_proto_rawDescGZIP(), []int{2}
+}
+
+// Mức thông báo đẩy của một thành viên cho conversation.
+// Chỉ ảnh hưởng cờ notify trong sự kiện tin nhắn; tin nhắn và số chưa đọc vẫn như bình thường.
+// - ALL: thông báo mọi tin nhắn (mặc định)
+// - MENTIONS: chỉ thông báo tin nhắn nhắc tên user (@<user_id>)
+// - NONE: tắt thông báo
+type NotificationLevel int32
+
+const (
+	NotificationLevel_NOTIFICATION_LEVEL_UNSPECIFIED NotificationLevel = 0
+	NotificationLevel_NOTIFICATION_LEVEL_ALL         NotificationLevel = 1
+	NotificationLevel_NOTIFICATION_LEVEL_MENTIONS    NotificationLevel = 2
+	NotificationLevel_NOTIFICATION_LEVEL_NONE        NotificationLevel = 3
+)
+
+// Enum value maps for NotificationLevel.
+var (
+	NotificationLevel_name = map[int32]string{
+		0: "NOTIFICATION_LEVEL_UNSPECIFIED",
+		1: "NOTIFICATION_LEVEL_ALL",
+		2: "NOTIFICATION_LEVEL_MENTIONS",
+		3: "NOTIFICATION_LEVEL_NONE",
+	}
+	NotificationLevel_value = map[string]int32{
+		"NOTIFICATION_LEVEL_UNSPECIFIED": 0,
+		"NOTIFICATION_LEVEL_ALL":         1,
+		"NOTIFICATION_LEVEL_MENTIONS":    2,
+		"NOTIFICATION_LEVEL_NONE":        3,
+	}
+)
+
+func (x NotificationLevel) Enum() *NotificationLevel {
+	p := new(NotificationLevel)
+	*p = x
+	return p
+}
+
+func (x NotificationLevel) String() string {
+	return protoimpl.X.EnumStringOf(x.Descriptor(), protoreflect.EnumNumber(x))
+}
+
+func (NotificationLevel) Descriptor() protoreflect.EnumDescriptor {
+	return file_chat_v1_chat_proto_enumTypes[3].Descriptor()
+}
+
+func (NotificationLevel) Type() protoreflect.EnumType {
+	return &file_chat_v1_chat_proto_enumTypes[3]
+}
+
+func (x NotificationLevel) Number() protoreflect.EnumNumber {
+	return protoreflect.EnumNumber(x)
+}
+
+// Deprecated: Use NotificationLevel.Descriptor instead.
+func (NotificationLevel) EnumDescriptor() ([]byte, []int) {
+	return file_chat_v1_chat_proto_rawDescGZIP(), []int{3}
+}
+
+// Định dạng dữ liệu của mỗi chunk khi export
+// - MESSAGES: chunk.messages chứa ChatMessage
+// - JSONL: chunk.data chứa JSON Lines, mỗi dòng một tin nhắn (có thể nối các chunk lại thành một file)
+type ExportFormat int32
+
+const (
+	ExportFormat_EXPORT_FORMAT_UNSPECIFIED ExportFormat = 0
+	ExportFormat_EXPORT_FORMAT_MESSAGES    ExportFormat = 1
+	ExportFormat_EXPORT_FORMAT_JSONL       ExportFormat = 2
+)
+
+// Enum value maps for ExportFormat.
+var (
+	ExportFormat_name = map[int32]string{
+		0: "EXPORT_FORMAT_UNSPECIFIED",
+		1: "EXPORT_FORMAT_MESSAGES",
+		2: "EXPORT_FORMAT_JSONL",
+	}
+	ExportFormat_value = map[string]int32{
+		"EXPORT_FORMAT_UNSPECIFIED": 0,
+		"EXPORT_FORMAT_MESSAGES":    1,
+		"EXPORT_FORMAT_JSONL":       2,
+	}
+)
+
+func (x ExportFormat) Enum() *ExportFormat {
+	p := new(ExportFormat)
+	*p = x
+	return p
+}
+
+func (x ExportFormat) String() string {
+	return protoimpl.X.EnumStringOf(x.Descriptor(), protoreflect.EnumNumber(x))
+}
+
+func (ExportFormat) Descriptor() protoreflect.EnumDescriptor {
+	return file_chat_v1_chat_proto_enumTypes[4].Descriptor()
+}
+
+func (ExportFormat) Type() protoreflect.EnumType {
+	return &file_chat_v1_chat_proto_enumTypes[4]
+}
+
+func (x ExportFormat) Number() protoreflect.EnumNumber {
+	return protoreflect.EnumNumber(x)
+}
+
+// Deprecated: Use ExportFormat.Descriptor instead.
+func (ExportFormat) EnumDescriptor() ([]byte, []int) {
+	return file_chat_v1_chat_proto_rawDescGZIP(), []int{4}
+}
+
 type SendMessageRequest struct {
 	state          protoimpl.MessageState `protogen:"open.v1"`
 	ConversationId string                 `protobuf:"bytes,1,opt,name=conversation_id,json=conversationId,proto3" json:"conversation_id,omitempty"`
 	// sender_id is extracted from JWT token via auth middleware
-	Content        string   `protobuf:"bytes,3,opt,name=content,proto3" json:"content,omitempty"`
+	Content        string   `protobuf:"bytes,3,opt,name=content,proto3" json:"content,omitempty"` // có thể để trống nếu tin nhắn có ít nhất một attachment
 	IdempotencyKey string   `protobuf:"bytes,4,opt,name=idempotency_key,json=idempotencyKey,proto3" json:"idempotency_key,omitempty"`
 	ReceiverIds    []string `protobuf:"bytes,5,rep,name=receiver_ids,json=receiverIds,proto3" json:"receiver_ids,omitempty"` // Optional list of receiver UUIDs
 	// Media support
-	Type          MessageType `protobuf:"varint,6,opt,name=type,proto3,enum=chat.v1.MessageType" json:"type,omitempty"` // TEXT, IMAGE, VIDEO, FILE (default: TEXT)
-	MediaUrl      string      `protobuf:"bytes,7,opt,name=media_url,json=mediaUrl,proto3" json:"media_url,omitempty"`   // URL of uploaded media (required for non-TEXT types)
+	Type     MessageType `protobuf:"varint,6,opt,name=type,proto3,enum=chat.v1.MessageType" json:"type,omitempty"` // TEXT, IMAGE, VIDEO, FILE, SYSTEM, CALL (default: TEXT)
+	MediaUrl string      `protobuf:"bytes,7,opt,name=media_url,json=mediaUrl,proto3" json:"media_url,omitempty"`   // URL of uploaded media (required for non-TEXT types)
+	// Attachments (optional): metadata của các file đã upload lên Cloudinary
+	Attachments   []*Attachment `protobuf:"bytes,8,rep,name=attachments,proto3" json:"attachments,omitempty"`
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
@@ -166,17 +396,103 @@ func (x *SendMessageRequest) GetMediaUrl() string {
 	return ""
 }
 
-type SendMessageResponse struct {
+func (x *SendMessageRequest) GetAttachments() []*Attachment {
+	if x != nil {
+		return x.Attachments
+	}
+	return nil
+}
+
+// Attachment metadata của một file đính kèm
+type Attachment struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
-	MessageId     string                 `protobuf:"bytes,1,opt,name=message_id,json=messageId,proto3" json:"message_id,omitempty"`
-	Status        string                 `protobuf:"bytes,2,opt,name=status,proto3" json:"status,omitempty"` // SENT, DELIVERED
+	Url           string                 `protobuf:"bytes,1,opt,name=url,proto3" json:"url,omitempty"`
+	MimeType      string                 `protobuf:"bytes,2,opt,name=mime_type,json=mimeType,proto3" json:"mime_type,omitempty"` // phải nằm trong allowlist (image/*, video/*, application/pdf, ...)
+	Size          int64                  `protobuf:"varint,3,opt,name=size,proto3" json:"size,omitempty"`                        // bytes
+	Width         int32                  `protobuf:"varint,4,opt,name=width,proto3" json:"width,omitempty"`                      // optional, cho ảnh/video
+	Height        int32                  `protobuf:"varint,5,opt,name=height,proto3" json:"height,omitempty"`                    // optional, cho ảnh/video
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
 
+func (x *Attachment) Reset() {
+	*x = Attachment{}
+	mi := &file_chat_v1_chat_proto_msgTypes[1]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Attachment) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Attachment) ProtoMessage() {}
+
+func (x *Attachment) ProtoReflect() protoreflect.Message {
+	mi := &file_chat_v1_chat_proto_msgTypes[1]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Attachment.ProtoReflect.Descriptor instead.
+func (*Attachment) Descriptor() ([]byte, []int) {
+	return file_chat_v1_chat_proto_rawDescGZIP(), []int{1}
+}
+
+func (x *Attachment) GetUrl() string {
+	if x != nil {
+		return x.Url
+	}
+	return ""
+}
+
+func (x *Attachment) GetMimeType() string {
+	if x != nil {
+		return x.MimeType
+	}
+	return ""
+}
+
+func (x *Attachment) GetSize() int64 {
+	if x != nil {
+		return x.Size
+	}
+	return 0
+}
+
+func (x *Attachment) GetWidth() int32 {
+	if x != nil {
+		return x.Width
+	}
+	return 0
+}
+
+func (x *Attachment) GetHeight() int32 {
+	if x != nil {
+		return x.Height
+	}
+	return 0
+}
+
+type SendMessageResponse struct {
+	state               protoimpl.MessageState `protogen:"open.v1"`
+	MessageId           string                 `protobuf:"bytes,1,opt,name=message_id,json=messageId,proto3" json:"message_id,omitempty"`
+	Status              string                 `protobuf:"bytes,2,opt,name=status,proto3" json:"status,omitempty"`                                                       // SENT, DELIVERED
+	ConversationCreated bool                   `protobuf:"varint,3,opt,name=conversation_created,json=conversationCreated,proto3" json:"conversation_created,omitempty"` // true khi tin nhắn này tạo ra conversation mới
+	Conversation        *Conversation          `protobuf:"bytes,4,opt,name=conversation,proto3" json:"conversation,omitempty"`                                           // chỉ có khi conversation_created, giống một phần tử của GetConversations
+	unknownFields       protoimpl.UnknownFields
+	sizeCache           protoimpl.SizeCache
+}
+
 func (x *SendMessageResponse) Reset() {
 	*x = SendMessageResponse{}
-	mi := &file_chat_v1_chat_proto_msgTypes[1]
+	mi := &file_chat_v1_chat_proto_msgTypes[2]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -188,7 +504,7 @@ func (x *SendMessageResponse) String() string {
 func (*SendMessageResponse) ProtoMessage() {}
 
 func (x *SendMessageResponse) ProtoReflect() protoreflect.Message {
-	mi := &file_chat_v1_chat_proto_msgTypes[1]
+	mi := &file_chat_v1_chat_proto_msgTypes[2]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -201,7 +517,7 @@ func (x *SendMessageResponse) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use SendMessageResponse.ProtoReflect.Descriptor instead.
 func (*SendMessageResponse) Descriptor() ([]byte, []int) {
-	return file_chat_v1_chat_proto_rawDescGZIP(), []int{1}
+	return file_chat_v1_chat_proto_rawDescGZIP(), []int{2}
 }
 
 func (x *SendMessageResponse) GetMessageId() string {
@@ -218,18 +534,35 @@ func (x *SendMessageResponse) GetStatus() string {
 	return ""
 }
 
+func (x *SendMessageResponse) GetConversationCreated() bool {
+	if x != nil {
+		return x.ConversationCreated
+	}
+	return false
+}
+
+func (x *SendMessageResponse) GetConversation() *Conversation {
+	if x != nil {
+		return x.Conversation
+	}
+	return nil
+}
+
 type GetMessagesRequest struct {
 	state           protoimpl.MessageState `protogen:"open.v1"`
 	ConversationId  string                 `protobuf:"bytes,1,opt,name=conversation_id,json=conversationId,proto3" json:"conversation_id,omitempty"`
 	Limit           int32                  `protobuf:"varint,2,opt,name=limit,proto3" json:"limit,omitempty"`                                           // default 50, max 100
 	BeforeTimestamp string                 `protobuf:"bytes,3,opt,name=before_timestamp,json=beforeTimestamp,proto3" json:"before_timestamp,omitempty"` // RFC3339 format, optional
-	unknownFields   protoimpl.UnknownFields
-	sizeCache       protoimpl.SizeCache
+	Cursor          string                 `protobuf:"bytes,4,opt,name=cursor,proto3" json:"cursor,omitempty"`                                          // opaque next_cursor from a previous page, takes precedence over before_timestamp
+	// Trả về cả tin nhắn đã xóa dưới dạng tombstone (deleted = true, nội dung rỗng) để client đồng bộ cache
+	IncludeDeleted bool `protobuf:"varint,5,opt,name=include_deleted,json=includeDeleted,proto3" json:"include_deleted,omitempty"`
+	unknownFields  protoimpl.UnknownFields
+	sizeCache      protoimpl.SizeCache
 }
 
 func (x *GetMessagesRequest) Reset() {
 	*x = GetMessagesRequest{}
-	mi := &file_chat_v1_chat_proto_msgTypes[2]
+	mi := &file_chat_v1_chat_proto_msgTypes[3]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -241,7 +574,7 @@ func (x *GetMessagesRequest) String() string {
 func (*GetMessagesRequest) ProtoMessage() {}
 
 func (x *GetMessagesRequest) ProtoReflect() protoreflect.Message {
-	mi := &file_chat_v1_chat_proto_msgTypes[2]
+	mi := &file_chat_v1_chat_proto_msgTypes[3]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -254,7 +587,7 @@ func (x *GetMessagesRequest) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use GetMessagesRequest.ProtoReflect.Descriptor instead.
 func (*GetMessagesRequest) Descriptor() ([]byte, []int) {
-	return file_chat_v1_chat_proto_rawDescGZIP(), []int{2}
+	return file_chat_v1_chat_proto_rawDescGZIP(), []int{3}
 }
 
 func (x *GetMessagesRequest) GetConversationId() string {
@@ -271,36 +604,2084 @@ func (x *GetMessagesRequest) GetLimit() int32 {
 	return 0
 }
 
-func (x *GetMessagesRequest) GetBeforeTimestamp() string {
+func (x *GetMessagesRequest) GetBeforeTimestamp() string {
+	if x != nil {
+		return x.BeforeTimestamp
+	}
+	return ""
+}
+
+func (x *GetMessagesRequest) GetCursor() string {
+	if x != nil {
+		return x.Cursor
+	}
+	return ""
+}
+
+func (x *GetMessagesRequest) GetIncludeDeleted() bool {
+	if x != nil {
+		return x.IncludeDeleted
+	}
+	return false
+}
+
+type GetMessagesResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Messages      []*ChatMessage         `protobuf:"bytes,1,rep,name=messages,proto3" json:"messages,omitempty"`
+	NextCursor    string                 `protobuf:"bytes,2,opt,name=next_cursor,json=nextCursor,proto3" json:"next_cursor,omitempty"` // opaque cursor for the next page
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetMessagesResponse) Reset() {
+	*x = GetMessagesResponse{}
+	mi := &file_chat_v1_chat_proto_msgTypes[4]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetMessagesResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetMessagesResponse) ProtoMessage() {}
+
+func (x *GetMessagesResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_chat_v1_chat_proto_msgTypes[4]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetMessagesResponse.ProtoReflect.Descriptor instead.
+func (*GetMessagesResponse) Descriptor() ([]byte, []int) {
+	return file_chat_v1_chat_proto_rawDescGZIP(), []int{4}
+}
+
+func (x *GetMessagesResponse) GetMessages() []*ChatMessage {
+	if x != nil {
+		return x.Messages
+	}
+	return nil
+}
+
+func (x *GetMessagesResponse) GetNextCursor() string {
+	if x != nil {
+		return x.NextCursor
+	}
+	return ""
+}
+
+type GetMentionsRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Limit         int32                  `protobuf:"varint,1,opt,name=limit,proto3" json:"limit,omitempty"`  // default 50, max 100
+	Cursor        string                 `protobuf:"bytes,2,opt,name=cursor,proto3" json:"cursor,omitempty"` // opaque next_cursor from a previous page
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetMentionsRequest) Reset() {
+	*x = GetMentionsRequest{}
+	mi := &file_chat_v1_chat_proto_msgTypes[5]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetMentionsRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetMentionsRequest) ProtoMessage() {}
+
+func (x *GetMentionsRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_chat_v1_chat_proto_msgTypes[5]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetMentionsRequest.ProtoReflect.Descriptor instead.
+func (*GetMentionsRequest) Descriptor() ([]byte, []int) {
+	return file_chat_v1_chat_proto_rawDescGZIP(), []int{5}
+}
+
+func (x *GetMentionsRequest) GetLimit() int32 {
+	if x != nil {
+		return x.Limit
+	}
+	return 0
+}
+
+func (x *GetMentionsRequest) GetCursor() string {
+	if x != nil {
+		return x.Cursor
+	}
+	return ""
+}
+
+type GetMentionsResponse struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// Chỉ gồm tin nhắn trong các conversation user vẫn là thành viên; tin nhắn đã xóa bị bỏ qua
+	Messages      []*ChatMessage `protobuf:"bytes,1,rep,name=messages,proto3" json:"messages,omitempty"`
+	NextCursor    string         `protobuf:"bytes,2,opt,name=next_cursor,json=nextCursor,proto3" json:"next_cursor,omitempty"` // opaque cursor for the next page
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetMentionsResponse) Reset() {
+	*x = GetMentionsResponse{}
+	mi := &file_chat_v1_chat_proto_msgTypes[6]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetMentionsResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetMentionsResponse) ProtoMessage() {}
+
+func (x *GetMentionsResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_chat_v1_chat_proto_msgTypes[6]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetMentionsResponse.ProtoReflect.Descriptor instead.
+func (*GetMentionsResponse) Descriptor() ([]byte, []int) {
+	return file_chat_v1_chat_proto_rawDescGZIP(), []int{6}
+}
+
+func (x *GetMentionsResponse) GetMessages() []*ChatMessage {
+	if x != nil {
+		return x.Messages
+	}
+	return nil
+}
+
+func (x *GetMentionsResponse) GetNextCursor() string {
+	if x != nil {
+		return x.NextCursor
+	}
+	return ""
+}
+
+type ChatMessage struct {
+	state          protoimpl.MessageState `protogen:"open.v1"`
+	Id             string                 `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
+	ConversationId string                 `protobuf:"bytes,2,opt,name=conversation_id,json=conversationId,proto3" json:"conversation_id,omitempty"`
+	SenderId       string                 `protobuf:"bytes,3,opt,name=sender_id,json=senderId,proto3" json:"sender_id,omitempty"`
+	Content        string                 `protobuf:"bytes,4,opt,name=content,proto3" json:"content,omitempty"` // trống với tin nhắn chỉ có attachment
+	CreatedAt      string                 `protobuf:"bytes,5,opt,name=created_at,json=createdAt,proto3" json:"created_at,omitempty"`
+	// Media support
+	Type        MessageType   `protobuf:"varint,6,opt,name=type,proto3,enum=chat.v1.MessageType" json:"type,omitempty"`
+	MediaUrl    string        `protobuf:"bytes,7,opt,name=media_url,json=mediaUrl,proto3" json:"media_url,omitempty"`
+	Attachments []*Attachment `protobuf:"bytes,8,rep,name=attachments,proto3" json:"attachments,omitempty"`
+	// Sender profile, empty when it could not be resolved
+	SenderName      string `protobuf:"bytes,9,opt,name=sender_name,json=senderName,proto3" json:"sender_name,omitempty"`
+	SenderAvatarUrl string `protobuf:"bytes,10,opt,name=sender_avatar_url,json=senderAvatarUrl,proto3" json:"sender_avatar_url,omitempty"`
+	// Trạng thái giao/đọc của tin nhắn do người gọi gửi (xem MessageStatus)
+	Status MessageStatus `protobuf:"varint,11,opt,name=status,proto3,enum=chat.v1.MessageStatus" json:"status,omitempty"`
+	// Sync: tin nhắn đã xóa chỉ xuất hiện khi include_deleted, với content/media_url/attachments rỗng
+	Deleted  bool   `protobuf:"varint,12,opt,name=deleted,proto3" json:"deleted,omitempty"`
+	Edited   bool   `protobuf:"varint,13,opt,name=edited,proto3" json:"edited,omitempty"`
+	EditedAt string `protobuf:"bytes,14,opt,name=edited_at,json=editedAt,proto3" json:"edited_at,omitempty"` // RFC3339, rỗng nếu chưa sửa
+	// Tổng hợp cảm xúc tại thời điểm đọc; sau đó client cập nhật bằng sự kiện reaction_added/reaction_removed
+	Reactions     []*ReactionSummary `protobuf:"bytes,15,rep,name=reactions,proto3" json:"reactions,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ChatMessage) Reset() {
+	*x = ChatMessage{}
+	mi := &file_chat_v1_chat_proto_msgTypes[7]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ChatMessage) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ChatMessage) ProtoMessage() {}
+
+func (x *ChatMessage) ProtoReflect() protoreflect.Message {
+	mi := &file_chat_v1_chat_proto_msgTypes[7]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ChatMessage.ProtoReflect.Descriptor instead.
+func (*ChatMessage) Descriptor() ([]byte, []int) {
+	return file_chat_v1_chat_proto_rawDescGZIP(), []int{7}
+}
+
+func (x *ChatMessage) GetId() string {
+	if x != nil {
+		return x.Id
+	}
+	return ""
+}
+
+func (x *ChatMessage) GetConversationId() string {
+	if x != nil {
+		return x.ConversationId
+	}
+	return ""
+}
+
+func (x *ChatMessage) GetSenderId() string {
+	if x != nil {
+		return x.SenderId
+	}
+	return ""
+}
+
+func (x *ChatMessage) GetContent() string {
+	if x != nil {
+		return x.Content
+	}
+	return ""
+}
+
+func (x *ChatMessage) GetCreatedAt() string {
+	if x != nil {
+		return x.CreatedAt
+	}
+	return ""
+}
+
+func (x *ChatMessage) GetType() MessageType {
+	if x != nil {
+		return x.Type
+	}
+	return MessageType_MESSAGE_TYPE_UNSPECIFIED
+}
+
+func (x *ChatMessage) GetMediaUrl() string {
+	if x != nil {
+		return x.MediaUrl
+	}
+	return ""
+}
+
+func (x *ChatMessage) GetAttachments() []*Attachment {
+	if x != nil {
+		return x.Attachments
+	}
+	return nil
+}
+
+func (x *ChatMessage) GetSenderName() string {
+	if x != nil {
+		return x.SenderName
+	}
+	return ""
+}
+
+func (x *ChatMessage) GetSenderAvatarUrl() string {
+	if x != nil {
+		return x.SenderAvatarUrl
+	}
+	return ""
+}
+
+func (x *ChatMessage) GetStatus() MessageStatus {
+	if x != nil {
+		return x.Status
+	}
+	return MessageStatus_MESSAGE_STATUS_UNSPECIFIED
+}
+
+func (x *ChatMessage) GetDeleted() bool {
+	if x != nil {
+		return x.Deleted
+	}
+	return false
+}
+
+func (x *ChatMessage) GetEdited() bool {
+	if x != nil {
+		return x.Edited
+	}
+	return false
+}
+
+func (x *ChatMessage) GetEditedAt() string {
+	if x != nil {
+		return x.EditedAt
+	}
+	return ""
+}
+
+func (x *ChatMessage) GetReactions() []*ReactionSummary {
+	if x != nil {
+		return x.Reactions
+	}
+	return nil
+}
+
+type ReactionSummary struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Emoji         string                 `protobuf:"bytes,1,opt,name=emoji,proto3" json:"emoji,omitempty"`
+	Count         int32                  `protobuf:"varint,2,opt,name=count,proto3" json:"count,omitempty"`
+	ReactedByMe   bool                   `protobuf:"varint,3,opt,name=reacted_by_me,json=reactedByMe,proto3" json:"reacted_by_me,omitempty"` // người gọi đã thả emoji này
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ReactionSummary) Reset() {
+	*x = ReactionSummary{}
+	mi := &file_chat_v1_chat_proto_msgTypes[8]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ReactionSummary) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ReactionSummary) ProtoMessage() {}
+
+func (x *ReactionSummary) ProtoReflect() protoreflect.Message {
+	mi := &file_chat_v1_chat_proto_msgTypes[8]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ReactionSummary.ProtoReflect.Descriptor instead.
+func (*ReactionSummary) Descriptor() ([]byte, []int) {
+	return file_chat_v1_chat_proto_rawDescGZIP(), []int{8}
+}
+
+func (x *ReactionSummary) GetEmoji() string {
+	if x != nil {
+		return x.Emoji
+	}
+	return ""
+}
+
+func (x *ReactionSummary) GetCount() int32 {
+	if x != nil {
+		return x.Count
+	}
+	return 0
+}
+
+func (x *ReactionSummary) GetReactedByMe() bool {
+	if x != nil {
+		return x.ReactedByMe
+	}
+	return false
+}
+
+type GetConversationsRequest struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// user_id is extracted from JWT token via auth middleware
+	Limit           int32  `protobuf:"varint,2,opt,name=limit,proto3" json:"limit,omitempty"`
+	Cursor          string `protobuf:"bytes,3,opt,name=cursor,proto3" json:"cursor,omitempty"`                                           // opaque next_cursor from a previous page
+	IncludeArchived bool   `protobuf:"varint,4,opt,name=include_archived,json=includeArchived,proto3" json:"include_archived,omitempty"` // mặc định conversation đã lưu trữ bị loại khỏi danh sách
+	OnlyArchived    bool   `protobuf:"varint,5,opt,name=only_archived,json=onlyArchived,proto3" json:"only_archived,omitempty"`          // chỉ lấy danh sách archived (ưu tiên hơn include_archived)
+	unknownFields   protoimpl.UnknownFields
+	sizeCache       protoimpl.SizeCache
+}
+
+func (x *GetConversationsRequest) Reset() {
+	*x = GetConversationsRequest{}
+	mi := &file_chat_v1_chat_proto_msgTypes[9]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetConversationsRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetConversationsRequest) ProtoMessage() {}
+
+func (x *GetConversationsRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_chat_v1_chat_proto_msgTypes[9]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetConversationsRequest.ProtoReflect.Descriptor instead.
+func (*GetConversationsRequest) Descriptor() ([]byte, []int) {
+	return file_chat_v1_chat_proto_rawDescGZIP(), []int{9}
+}
+
+func (x *GetConversationsRequest) GetLimit() int32 {
+	if x != nil {
+		return x.Limit
+	}
+	return 0
+}
+
+func (x *GetConversationsRequest) GetCursor() string {
+	if x != nil {
+		return x.Cursor
+	}
+	return ""
+}
+
+func (x *GetConversationsRequest) GetIncludeArchived() bool {
+	if x != nil {
+		return x.IncludeArchived
+	}
+	return false
+}
+
+func (x *GetConversationsRequest) GetOnlyArchived() bool {
+	if x != nil {
+		return x.OnlyArchived
+	}
+	return false
+}
+
+type GetConversationsResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Conversations []*Conversation        `protobuf:"bytes,1,rep,name=conversations,proto3" json:"conversations,omitempty"`
+	NextCursor    string                 `protobuf:"bytes,2,opt,name=next_cursor,json=nextCursor,proto3" json:"next_cursor,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetConversationsResponse) Reset() {
+	*x = GetConversationsResponse{}
+	mi := &file_chat_v1_chat_proto_msgTypes[10]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetConversationsResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetConversationsResponse) ProtoMessage() {}
+
+func (x *GetConversationsResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_chat_v1_chat_proto_msgTypes[10]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetConversationsResponse.ProtoReflect.Descriptor instead.
+func (*GetConversationsResponse) Descriptor() ([]byte, []int) {
+	return file_chat_v1_chat_proto_rawDescGZIP(), []int{10}
+}
+
+func (x *GetConversationsResponse) GetConversations() []*Conversation {
+	if x != nil {
+		return x.Conversations
+	}
+	return nil
+}
+
+func (x *GetConversationsResponse) GetNextCursor() string {
+	if x != nil {
+		return x.NextCursor
+	}
+	return ""
+}
+
+type GetConversationsByIdsRequest struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// user_id is extracted from JWT token via auth middleware
+	ConversationIds []string `protobuf:"bytes,1,rep,name=conversation_ids,json=conversationIds,proto3" json:"conversation_ids,omitempty"` // max 100
+	unknownFields   protoimpl.UnknownFields
+	sizeCache       protoimpl.SizeCache
+}
+
+func (x *GetConversationsByIdsRequest) Reset() {
+	*x = GetConversationsByIdsRequest{}
+	mi := &file_chat_v1_chat_proto_msgTypes[11]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetConversationsByIdsRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetConversationsByIdsRequest) ProtoMessage() {}
+
+func (x *GetConversationsByIdsRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_chat_v1_chat_proto_msgTypes[11]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetConversationsByIdsRequest.ProtoReflect.Descriptor instead.
+func (*GetConversationsByIdsRequest) Descriptor() ([]byte, []int) {
+	return file_chat_v1_chat_proto_rawDescGZIP(), []int{11}
+}
+
+func (x *GetConversationsByIdsRequest) GetConversationIds() []string {
+	if x != nil {
+		return x.ConversationIds
+	}
+	return nil
+}
+
+type GetConversationsByIdsResponse struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// Chỉ gồm các conversation mà user là thành viên (và chưa ẩn), sắp xếp theo last_message_at giảm dần
+	Conversations []*Conversation `protobuf:"bytes,1,rep,name=conversations,proto3" json:"conversations,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetConversationsByIdsResponse) Reset() {
+	*x = GetConversationsByIdsResponse{}
+	mi := &file_chat_v1_chat_proto_msgTypes[12]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetConversationsByIdsResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetConversationsByIdsResponse) ProtoMessage() {}
+
+func (x *GetConversationsByIdsResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_chat_v1_chat_proto_msgTypes[12]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetConversationsByIdsResponse.ProtoReflect.Descriptor instead.
+func (*GetConversationsByIdsResponse) Descriptor() ([]byte, []int) {
+	return file_chat_v1_chat_proto_rawDescGZIP(), []int{12}
+}
+
+func (x *GetConversationsByIdsResponse) GetConversations() []*Conversation {
+	if x != nil {
+		return x.Conversations
+	}
+	return nil
+}
+
+type Conversation struct {
+	state              protoimpl.MessageState `protogen:"open.v1"`
+	Id                 string                 `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
+	LastMessageContent string                 `protobuf:"bytes,2,opt,name=last_message_content,json=lastMessageContent,proto3" json:"last_message_content,omitempty"`
+	LastMessageAt      string                 `protobuf:"bytes,3,opt,name=last_message_at,json=lastMessageAt,proto3" json:"last_message_at,omitempty"`
+	UnreadCount        int32                  `protobuf:"varint,4,opt,name=unread_count,json=unreadCount,proto3" json:"unread_count,omitempty"`
+	ArchivedAt         string                 `protobuf:"bytes,5,opt,name=archived_at,json=archivedAt,proto3" json:"archived_at,omitempty"`                                                       // RFC3339, trống nếu conversation không bị lưu trữ
+	MessageTtlSeconds  int64                  `protobuf:"varint,6,opt,name=message_ttl_seconds,json=messageTtlSeconds,proto3" json:"message_ttl_seconds,omitempty"`                               // tin nhắn cũ hơn sẽ bị xoá, 0 = lưu vĩnh viễn
+	Type               ConversationType       `protobuf:"varint,7,opt,name=type,proto3,enum=chat.v1.ConversationType" json:"type,omitempty"`                                                      // UNSPECIFIED cho conversation tạo ngầm bởi SendMessage
+	Name               string                 `protobuf:"bytes,8,opt,name=name,proto3" json:"name,omitempty"`                                                                                     // tên nhóm, chỉ có với GROUP
+	Pinned             bool                   `protobuf:"varint,9,opt,name=pinned,proto3" json:"pinned,omitempty"`                                                                                // conversation được ghim bởi user hiện tại, đứng đầu danh sách
+	PinnedAt           string                 `protobuf:"bytes,10,opt,name=pinned_at,json=pinnedAt,proto3" json:"pinned_at,omitempty"`                                                            // RFC3339, trống nếu không ghim
+	NotificationLevel  NotificationLevel      `protobuf:"varint,11,opt,name=notification_level,json=notificationLevel,proto3,enum=chat.v1.NotificationLevel" json:"notification_level,omitempty"` // mức thông báo của user hiện tại
+	unknownFields      protoimpl.UnknownFields
+	sizeCache          protoimpl.SizeCache
+}
+
+func (x *Conversation) Reset() {
+	*x = Conversation{}
+	mi := &file_chat_v1_chat_proto_msgTypes[13]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Conversation) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Conversation) ProtoMessage() {}
+
+func (x *Conversation) ProtoReflect() protoreflect.Message {
+	mi := &file_chat_v1_chat_proto_msgTypes[13]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Conversation.ProtoReflect.Descriptor instead.
+func (*Conversation) Descriptor() ([]byte, []int) {
+	return file_chat_v1_chat_proto_rawDescGZIP(), []int{13}
+}
+
+func (x *Conversation) GetId() string {
+	if x != nil {
+		return x.Id
+	}
+	return ""
+}
+
+func (x *Conversation) GetLastMessageContent() string {
+	if x != nil {
+		return x.LastMessageContent
+	}
+	return ""
+}
+
+func (x *Conversation) GetLastMessageAt() string {
+	if x != nil {
+		return x.LastMessageAt
+	}
+	return ""
+}
+
+func (x *Conversation) GetUnreadCount() int32 {
+	if x != nil {
+		return x.UnreadCount
+	}
+	return 0
+}
+
+func (x *Conversation) GetArchivedAt() string {
+	if x != nil {
+		return x.ArchivedAt
+	}
+	return ""
+}
+
+func (x *Conversation) GetMessageTtlSeconds() int64 {
+	if x != nil {
+		return x.MessageTtlSeconds
+	}
+	return 0
+}
+
+func (x *Conversation) GetType() ConversationType {
+	if x != nil {
+		return x.Type
+	}
+	return ConversationType_CONVERSATION_TYPE_UNSPECIFIED
+}
+
+func (x *Conversation) GetName() string {
+	if x != nil {
+		return x.Name
+	}
+	return ""
+}
+
+func (x *Conversation) GetPinned() bool {
+	if x != nil {
+		return x.Pinned
+	}
+	return false
+}
+
+func (x *Conversation) GetPinnedAt() string {
+	if x != nil {
+		return x.PinnedAt
+	}
+	return ""
+}
+
+func (x *Conversation) GetNotificationLevel() NotificationLevel {
+	if x != nil {
+		return x.NotificationLevel
+	}
+	return NotificationLevel_NOTIFICATION_LEVEL_UNSPECIFIED
+}
+
+type CreateConversationRequest struct {
+	state          protoimpl.MessageState `protogen:"open.v1"`
+	Type           ConversationType       `protobuf:"varint,1,opt,name=type,proto3,enum=chat.v1.ConversationType" json:"type,omitempty"`            // bắt buộc: DIRECT hoặc GROUP
+	ParticipantIds []string               `protobuf:"bytes,2,rep,name=participant_ids,json=participantIds,proto3" json:"participant_ids,omitempty"` // các thành viên khác (UUID); DIRECT cần đúng một người
+	Name           string                 `protobuf:"bytes,3,opt,name=name,proto3" json:"name,omitempty"`                                           // tuỳ chọn, chỉ cho GROUP
+	unknownFields  protoimpl.UnknownFields
+	sizeCache      protoimpl.SizeCache
+}
+
+func (x *CreateConversationRequest) Reset() {
+	*x = CreateConversationRequest{}
+	mi := &file_chat_v1_chat_proto_msgTypes[14]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *CreateConversationRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*CreateConversationRequest) ProtoMessage() {}
+
+func (x *CreateConversationRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_chat_v1_chat_proto_msgTypes[14]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use CreateConversationRequest.ProtoReflect.Descriptor instead.
+func (*CreateConversationRequest) Descriptor() ([]byte, []int) {
+	return file_chat_v1_chat_proto_rawDescGZIP(), []int{14}
+}
+
+func (x *CreateConversationRequest) GetType() ConversationType {
+	if x != nil {
+		return x.Type
+	}
+	return ConversationType_CONVERSATION_TYPE_UNSPECIFIED
+}
+
+func (x *CreateConversationRequest) GetParticipantIds() []string {
+	if x != nil {
+		return x.ParticipantIds
+	}
+	return nil
+}
+
+func (x *CreateConversationRequest) GetName() string {
+	if x != nil {
+		return x.Name
+	}
+	return ""
+}
+
+type CreateConversationResponse struct {
+	state          protoimpl.MessageState `protogen:"open.v1"`
+	ConversationId string                 `protobuf:"bytes,1,opt,name=conversation_id,json=conversationId,proto3" json:"conversation_id,omitempty"`
+	Conversation   *Conversation          `protobuf:"bytes,2,opt,name=conversation,proto3" json:"conversation,omitempty"`
+	unknownFields  protoimpl.UnknownFields
+	sizeCache      protoimpl.SizeCache
+}
+
+func (x *CreateConversationResponse) Reset() {
+	*x = CreateConversationResponse{}
+	mi := &file_chat_v1_chat_proto_msgTypes[15]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *CreateConversationResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*CreateConversationResponse) ProtoMessage() {}
+
+func (x *CreateConversationResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_chat_v1_chat_proto_msgTypes[15]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use CreateConversationResponse.ProtoReflect.Descriptor instead.
+func (*CreateConversationResponse) Descriptor() ([]byte, []int) {
+	return file_chat_v1_chat_proto_rawDescGZIP(), []int{15}
+}
+
+func (x *CreateConversationResponse) GetConversationId() string {
+	if x != nil {
+		return x.ConversationId
+	}
+	return ""
+}
+
+func (x *CreateConversationResponse) GetConversation() *Conversation {
+	if x != nil {
+		return x.Conversation
+	}
+	return nil
+}
+
+type MarkAsReadRequest struct {
+	state          protoimpl.MessageState `protogen:"open.v1"`
+	ConversationId string                 `protobuf:"bytes,1,opt,name=conversation_id,json=conversationId,proto3" json:"conversation_id,omitempty"`
+	// user_id is extracted from JWT token via auth middleware
+	// Tin nhắn cuối cùng client đã hiển thị: chỉ đánh dấu đã đọc đến created_at của nó,
+	// tin nhắn đến sau đó vẫn là chưa đọc. Rỗng = đánh dấu đọc tất cả đến hiện tại.
+	UpToMessageId string `protobuf:"bytes,2,opt,name=up_to_message_id,json=upToMessageId,proto3" json:"up_to_message_id,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *MarkAsReadRequest) Reset() {
+	*x = MarkAsReadRequest{}
+	mi := &file_chat_v1_chat_proto_msgTypes[16]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *MarkAsReadRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*MarkAsReadRequest) ProtoMessage() {}
+
+func (x *MarkAsReadRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_chat_v1_chat_proto_msgTypes[16]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use MarkAsReadRequest.ProtoReflect.Descriptor instead.
+func (*MarkAsReadRequest) Descriptor() ([]byte, []int) {
+	return file_chat_v1_chat_proto_rawDescGZIP(), []int{16}
+}
+
+func (x *MarkAsReadRequest) GetConversationId() string {
+	if x != nil {
+		return x.ConversationId
+	}
+	return ""
+}
+
+func (x *MarkAsReadRequest) GetUpToMessageId() string {
+	if x != nil {
+		return x.UpToMessageId
+	}
+	return ""
+}
+
+type MarkAsReadResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Success       bool                   `protobuf:"varint,1,opt,name=success,proto3" json:"success,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *MarkAsReadResponse) Reset() {
+	*x = MarkAsReadResponse{}
+	mi := &file_chat_v1_chat_proto_msgTypes[17]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *MarkAsReadResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*MarkAsReadResponse) ProtoMessage() {}
+
+func (x *MarkAsReadResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_chat_v1_chat_proto_msgTypes[17]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use MarkAsReadResponse.ProtoReflect.Descriptor instead.
+func (*MarkAsReadResponse) Descriptor() ([]byte, []int) {
+	return file_chat_v1_chat_proto_rawDescGZIP(), []int{17}
+}
+
+func (x *MarkAsReadResponse) GetSuccess() bool {
+	if x != nil {
+		return x.Success
+	}
+	return false
+}
+
+type MarkAsDeliveredRequest struct {
+	state          protoimpl.MessageState `protogen:"open.v1"`
+	ConversationId string                 `protobuf:"bytes,1,opt,name=conversation_id,json=conversationId,proto3" json:"conversation_id,omitempty"` // user_id is extracted from JWT token via auth middleware
+	unknownFields  protoimpl.UnknownFields
+	sizeCache      protoimpl.SizeCache
+}
+
+func (x *MarkAsDeliveredRequest) Reset() {
+	*x = MarkAsDeliveredRequest{}
+	mi := &file_chat_v1_chat_proto_msgTypes[18]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *MarkAsDeliveredRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*MarkAsDeliveredRequest) ProtoMessage() {}
+
+func (x *MarkAsDeliveredRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_chat_v1_chat_proto_msgTypes[18]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use MarkAsDeliveredRequest.ProtoReflect.Descriptor instead.
+func (*MarkAsDeliveredRequest) Descriptor() ([]byte, []int) {
+	return file_chat_v1_chat_proto_rawDescGZIP(), []int{18}
+}
+
+func (x *MarkAsDeliveredRequest) GetConversationId() string {
+	if x != nil {
+		return x.ConversationId
+	}
+	return ""
+}
+
+type MarkAsDeliveredResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Success       bool                   `protobuf:"varint,1,opt,name=success,proto3" json:"success,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *MarkAsDeliveredResponse) Reset() {
+	*x = MarkAsDeliveredResponse{}
+	mi := &file_chat_v1_chat_proto_msgTypes[19]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *MarkAsDeliveredResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*MarkAsDeliveredResponse) ProtoMessage() {}
+
+func (x *MarkAsDeliveredResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_chat_v1_chat_proto_msgTypes[19]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use MarkAsDeliveredResponse.ProtoReflect.Descriptor instead.
+func (*MarkAsDeliveredResponse) Descriptor() ([]byte, []int) {
+	return file_chat_v1_chat_proto_rawDescGZIP(), []int{19}
+}
+
+func (x *MarkAsDeliveredResponse) GetSuccess() bool {
+	if x != nil {
+		return x.Success
+	}
+	return false
+}
+
+type GetParticipantsRequest struct {
+	state          protoimpl.MessageState `protogen:"open.v1"`
+	ConversationId string                 `protobuf:"bytes,1,opt,name=conversation_id,json=conversationId,proto3" json:"conversation_id,omitempty"` // user_id is extracted from JWT token via auth middleware
+	unknownFields  protoimpl.UnknownFields
+	sizeCache      protoimpl.SizeCache
+}
+
+func (x *GetParticipantsRequest) Reset() {
+	*x = GetParticipantsRequest{}
+	mi := &file_chat_v1_chat_proto_msgTypes[20]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetParticipantsRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetParticipantsRequest) ProtoMessage() {}
+
+func (x *GetParticipantsRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_chat_v1_chat_proto_msgTypes[20]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetParticipantsRequest.ProtoReflect.Descriptor instead.
+func (*GetParticipantsRequest) Descriptor() ([]byte, []int) {
+	return file_chat_v1_chat_proto_rawDescGZIP(), []int{20}
+}
+
+func (x *GetParticipantsRequest) GetConversationId() string {
+	if x != nil {
+		return x.ConversationId
+	}
+	return ""
+}
+
+type GetParticipantsResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Participants  []*Participant         `protobuf:"bytes,1,rep,name=participants,proto3" json:"participants,omitempty"` // ordered by joined_at
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetParticipantsResponse) Reset() {
+	*x = GetParticipantsResponse{}
+	mi := &file_chat_v1_chat_proto_msgTypes[21]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetParticipantsResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetParticipantsResponse) ProtoMessage() {}
+
+func (x *GetParticipantsResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_chat_v1_chat_proto_msgTypes[21]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetParticipantsResponse.ProtoReflect.Descriptor instead.
+func (*GetParticipantsResponse) Descriptor() ([]byte, []int) {
+	return file_chat_v1_chat_proto_rawDescGZIP(), []int{21}
+}
+
+func (x *GetParticipantsResponse) GetParticipants() []*Participant {
+	if x != nil {
+		return x.Participants
+	}
+	return nil
+}
+
+type Participant struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	UserId        string                 `protobuf:"bytes,1,opt,name=user_id,json=userId,proto3" json:"user_id,omitempty"`
+	JoinedAt      string                 `protobuf:"bytes,2,opt,name=joined_at,json=joinedAt,proto3" json:"joined_at,omitempty"` // RFC3339
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Participant) Reset() {
+	*x = Participant{}
+	mi := &file_chat_v1_chat_proto_msgTypes[22]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Participant) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Participant) ProtoMessage() {}
+
+func (x *Participant) ProtoReflect() protoreflect.Message {
+	mi := &file_chat_v1_chat_proto_msgTypes[22]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Participant.ProtoReflect.Descriptor instead.
+func (*Participant) Descriptor() ([]byte, []int) {
+	return file_chat_v1_chat_proto_rawDescGZIP(), []int{22}
+}
+
+func (x *Participant) GetUserId() string {
+	if x != nil {
+		return x.UserId
+	}
+	return ""
+}
+
+func (x *Participant) GetJoinedAt() string {
+	if x != nil {
+		return x.JoinedAt
+	}
+	return ""
+}
+
+type DeleteConversationRequest struct {
+	state          protoimpl.MessageState `protogen:"open.v1"`
+	ConversationId string                 `protobuf:"bytes,1,opt,name=conversation_id,json=conversationId,proto3" json:"conversation_id,omitempty"` // user_id is extracted from JWT token via auth middleware
+	unknownFields  protoimpl.UnknownFields
+	sizeCache      protoimpl.SizeCache
+}
+
+func (x *DeleteConversationRequest) Reset() {
+	*x = DeleteConversationRequest{}
+	mi := &file_chat_v1_chat_proto_msgTypes[23]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *DeleteConversationRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*DeleteConversationRequest) ProtoMessage() {}
+
+func (x *DeleteConversationRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_chat_v1_chat_proto_msgTypes[23]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use DeleteConversationRequest.ProtoReflect.Descriptor instead.
+func (*DeleteConversationRequest) Descriptor() ([]byte, []int) {
+	return file_chat_v1_chat_proto_rawDescGZIP(), []int{23}
+}
+
+func (x *DeleteConversationRequest) GetConversationId() string {
+	if x != nil {
+		return x.ConversationId
+	}
+	return ""
+}
+
+type DeleteConversationResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Success       bool                   `protobuf:"varint,1,opt,name=success,proto3" json:"success,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *DeleteConversationResponse) Reset() {
+	*x = DeleteConversationResponse{}
+	mi := &file_chat_v1_chat_proto_msgTypes[24]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *DeleteConversationResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*DeleteConversationResponse) ProtoMessage() {}
+
+func (x *DeleteConversationResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_chat_v1_chat_proto_msgTypes[24]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use DeleteConversationResponse.ProtoReflect.Descriptor instead.
+func (*DeleteConversationResponse) Descriptor() ([]byte, []int) {
+	return file_chat_v1_chat_proto_rawDescGZIP(), []int{24}
+}
+
+func (x *DeleteConversationResponse) GetSuccess() bool {
+	if x != nil {
+		return x.Success
+	}
+	return false
+}
+
+type ArchiveConversationRequest struct {
+	state          protoimpl.MessageState `protogen:"open.v1"`
+	ConversationId string                 `protobuf:"bytes,1,opt,name=conversation_id,json=conversationId,proto3" json:"conversation_id,omitempty"` // user_id is extracted from JWT token via auth middleware
+	unknownFields  protoimpl.UnknownFields
+	sizeCache      protoimpl.SizeCache
+}
+
+func (x *ArchiveConversationRequest) Reset() {
+	*x = ArchiveConversationRequest{}
+	mi := &file_chat_v1_chat_proto_msgTypes[25]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ArchiveConversationRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ArchiveConversationRequest) ProtoMessage() {}
+
+func (x *ArchiveConversationRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_chat_v1_chat_proto_msgTypes[25]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ArchiveConversationRequest.ProtoReflect.Descriptor instead.
+func (*ArchiveConversationRequest) Descriptor() ([]byte, []int) {
+	return file_chat_v1_chat_proto_rawDescGZIP(), []int{25}
+}
+
+func (x *ArchiveConversationRequest) GetConversationId() string {
+	if x != nil {
+		return x.ConversationId
+	}
+	return ""
+}
+
+type ArchiveConversationResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Success       bool                   `protobuf:"varint,1,opt,name=success,proto3" json:"success,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ArchiveConversationResponse) Reset() {
+	*x = ArchiveConversationResponse{}
+	mi := &file_chat_v1_chat_proto_msgTypes[26]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ArchiveConversationResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ArchiveConversationResponse) ProtoMessage() {}
+
+func (x *ArchiveConversationResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_chat_v1_chat_proto_msgTypes[26]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ArchiveConversationResponse.ProtoReflect.Descriptor instead.
+func (*ArchiveConversationResponse) Descriptor() ([]byte, []int) {
+	return file_chat_v1_chat_proto_rawDescGZIP(), []int{26}
+}
+
+func (x *ArchiveConversationResponse) GetSuccess() bool {
+	if x != nil {
+		return x.Success
+	}
+	return false
+}
+
+type UnarchiveConversationRequest struct {
+	state          protoimpl.MessageState `protogen:"open.v1"`
+	ConversationId string                 `protobuf:"bytes,1,opt,name=conversation_id,json=conversationId,proto3" json:"conversation_id,omitempty"` // user_id is extracted from JWT token via auth middleware
+	unknownFields  protoimpl.UnknownFields
+	sizeCache      protoimpl.SizeCache
+}
+
+func (x *UnarchiveConversationRequest) Reset() {
+	*x = UnarchiveConversationRequest{}
+	mi := &file_chat_v1_chat_proto_msgTypes[27]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *UnarchiveConversationRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*UnarchiveConversationRequest) ProtoMessage() {}
+
+func (x *UnarchiveConversationRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_chat_v1_chat_proto_msgTypes[27]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use UnarchiveConversationRequest.ProtoReflect.Descriptor instead.
+func (*UnarchiveConversationRequest) Descriptor() ([]byte, []int) {
+	return file_chat_v1_chat_proto_rawDescGZIP(), []int{27}
+}
+
+func (x *UnarchiveConversationRequest) GetConversationId() string {
+	if x != nil {
+		return x.ConversationId
+	}
+	return ""
+}
+
+type UnarchiveConversationResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Success       bool                   `protobuf:"varint,1,opt,name=success,proto3" json:"success,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *UnarchiveConversationResponse) Reset() {
+	*x = UnarchiveConversationResponse{}
+	mi := &file_chat_v1_chat_proto_msgTypes[28]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *UnarchiveConversationResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*UnarchiveConversationResponse) ProtoMessage() {}
+
+func (x *UnarchiveConversationResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_chat_v1_chat_proto_msgTypes[28]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use UnarchiveConversationResponse.ProtoReflect.Descriptor instead.
+func (*UnarchiveConversationResponse) Descriptor() ([]byte, []int) {
+	return file_chat_v1_chat_proto_rawDescGZIP(), []int{28}
+}
+
+func (x *UnarchiveConversationResponse) GetSuccess() bool {
+	if x != nil {
+		return x.Success
+	}
+	return false
+}
+
+type PinConversationRequest struct {
+	state          protoimpl.MessageState `protogen:"open.v1"`
+	ConversationId string                 `protobuf:"bytes,1,opt,name=conversation_id,json=conversationId,proto3" json:"conversation_id,omitempty"` // user_id is extracted from JWT token via auth middleware
+	unknownFields  protoimpl.UnknownFields
+	sizeCache      protoimpl.SizeCache
+}
+
+func (x *PinConversationRequest) Reset() {
+	*x = PinConversationRequest{}
+	mi := &file_chat_v1_chat_proto_msgTypes[29]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *PinConversationRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*PinConversationRequest) ProtoMessage() {}
+
+func (x *PinConversationRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_chat_v1_chat_proto_msgTypes[29]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use PinConversationRequest.ProtoReflect.Descriptor instead.
+func (*PinConversationRequest) Descriptor() ([]byte, []int) {
+	return file_chat_v1_chat_proto_rawDescGZIP(), []int{29}
+}
+
+func (x *PinConversationRequest) GetConversationId() string {
+	if x != nil {
+		return x.ConversationId
+	}
+	return ""
+}
+
+type PinConversationResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Success       bool                   `protobuf:"varint,1,opt,name=success,proto3" json:"success,omitempty"`
+	PinnedAt      string                 `protobuf:"bytes,2,opt,name=pinned_at,json=pinnedAt,proto3" json:"pinned_at,omitempty"` // RFC3339; ghim lại conversation đã ghim giữ nguyên thời điểm cũ
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *PinConversationResponse) Reset() {
+	*x = PinConversationResponse{}
+	mi := &file_chat_v1_chat_proto_msgTypes[30]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *PinConversationResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*PinConversationResponse) ProtoMessage() {}
+
+func (x *PinConversationResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_chat_v1_chat_proto_msgTypes[30]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use PinConversationResponse.ProtoReflect.Descriptor instead.
+func (*PinConversationResponse) Descriptor() ([]byte, []int) {
+	return file_chat_v1_chat_proto_rawDescGZIP(), []int{30}
+}
+
+func (x *PinConversationResponse) GetSuccess() bool {
+	if x != nil {
+		return x.Success
+	}
+	return false
+}
+
+func (x *PinConversationResponse) GetPinnedAt() string {
+	if x != nil {
+		return x.PinnedAt
+	}
+	return ""
+}
+
+type UnpinConversationRequest struct {
+	state          protoimpl.MessageState `protogen:"open.v1"`
+	ConversationId string                 `protobuf:"bytes,1,opt,name=conversation_id,json=conversationId,proto3" json:"conversation_id,omitempty"` // user_id is extracted from JWT token via auth middleware
+	unknownFields  protoimpl.UnknownFields
+	sizeCache      protoimpl.SizeCache
+}
+
+func (x *UnpinConversationRequest) Reset() {
+	*x = UnpinConversationRequest{}
+	mi := &file_chat_v1_chat_proto_msgTypes[31]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *UnpinConversationRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*UnpinConversationRequest) ProtoMessage() {}
+
+func (x *UnpinConversationRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_chat_v1_chat_proto_msgTypes[31]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use UnpinConversationRequest.ProtoReflect.Descriptor instead.
+func (*UnpinConversationRequest) Descriptor() ([]byte, []int) {
+	return file_chat_v1_chat_proto_rawDescGZIP(), []int{31}
+}
+
+func (x *UnpinConversationRequest) GetConversationId() string {
+	if x != nil {
+		return x.ConversationId
+	}
+	return ""
+}
+
+type UnpinConversationResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Success       bool                   `protobuf:"varint,1,opt,name=success,proto3" json:"success,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *UnpinConversationResponse) Reset() {
+	*x = UnpinConversationResponse{}
+	mi := &file_chat_v1_chat_proto_msgTypes[32]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *UnpinConversationResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*UnpinConversationResponse) ProtoMessage() {}
+
+func (x *UnpinConversationResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_chat_v1_chat_proto_msgTypes[32]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use UnpinConversationResponse.ProtoReflect.Descriptor instead.
+func (*UnpinConversationResponse) Descriptor() ([]byte, []int) {
+	return file_chat_v1_chat_proto_rawDescGZIP(), []int{32}
+}
+
+func (x *UnpinConversationResponse) GetSuccess() bool {
+	if x != nil {
+		return x.Success
+	}
+	return false
+}
+
+type SetNotificationLevelRequest struct {
+	state             protoimpl.MessageState `protogen:"open.v1"`
+	ConversationId    string                 `protobuf:"bytes,1,opt,name=conversation_id,json=conversationId,proto3" json:"conversation_id,omitempty"`
+	NotificationLevel NotificationLevel      `protobuf:"varint,2,opt,name=notification_level,json=notificationLevel,proto3,enum=chat.v1.NotificationLevel" json:"notification_level,omitempty"` // bắt buộc
+	unknownFields     protoimpl.UnknownFields
+	sizeCache         protoimpl.SizeCache
+}
+
+func (x *SetNotificationLevelRequest) Reset() {
+	*x = SetNotificationLevelRequest{}
+	mi := &file_chat_v1_chat_proto_msgTypes[33]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *SetNotificationLevelRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*SetNotificationLevelRequest) ProtoMessage() {}
+
+func (x *SetNotificationLevelRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_chat_v1_chat_proto_msgTypes[33]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use SetNotificationLevelRequest.ProtoReflect.Descriptor instead.
+func (*SetNotificationLevelRequest) Descriptor() ([]byte, []int) {
+	return file_chat_v1_chat_proto_rawDescGZIP(), []int{33}
+}
+
+func (x *SetNotificationLevelRequest) GetConversationId() string {
+	if x != nil {
+		return x.ConversationId
+	}
+	return ""
+}
+
+func (x *SetNotificationLevelRequest) GetNotificationLevel() NotificationLevel {
+	if x != nil {
+		return x.NotificationLevel
+	}
+	return NotificationLevel_NOTIFICATION_LEVEL_UNSPECIFIED
+}
+
+type SetNotificationLevelResponse struct {
+	state             protoimpl.MessageState `protogen:"open.v1"`
+	Success           bool                   `protobuf:"varint,1,opt,name=success,proto3" json:"success,omitempty"`
+	NotificationLevel NotificationLevel      `protobuf:"varint,2,opt,name=notification_level,json=notificationLevel,proto3,enum=chat.v1.NotificationLevel" json:"notification_level,omitempty"`
+	unknownFields     protoimpl.UnknownFields
+	sizeCache         protoimpl.SizeCache
+}
+
+func (x *SetNotificationLevelResponse) Reset() {
+	*x = SetNotificationLevelResponse{}
+	mi := &file_chat_v1_chat_proto_msgTypes[34]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *SetNotificationLevelResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*SetNotificationLevelResponse) ProtoMessage() {}
+
+func (x *SetNotificationLevelResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_chat_v1_chat_proto_msgTypes[34]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use SetNotificationLevelResponse.ProtoReflect.Descriptor instead.
+func (*SetNotificationLevelResponse) Descriptor() ([]byte, []int) {
+	return file_chat_v1_chat_proto_rawDescGZIP(), []int{34}
+}
+
+func (x *SetNotificationLevelResponse) GetSuccess() bool {
+	if x != nil {
+		return x.Success
+	}
+	return false
+}
+
+func (x *SetNotificationLevelResponse) GetNotificationLevel() NotificationLevel {
+	if x != nil {
+		return x.NotificationLevel
+	}
+	return NotificationLevel_NOTIFICATION_LEVEL_UNSPECIFIED
+}
+
+type SetConversationRetentionRequest struct {
+	state             protoimpl.MessageState `protogen:"open.v1"`
+	ConversationId    string                 `protobuf:"bytes,1,opt,name=conversation_id,json=conversationId,proto3" json:"conversation_id,omitempty"`
+	MessageTtlSeconds int64                  `protobuf:"varint,2,opt,name=message_ttl_seconds,json=messageTtlSeconds,proto3" json:"message_ttl_seconds,omitempty"` // tối thiểu 60, tối đa 10 năm; 0 = tắt tự động xoá
+	unknownFields     protoimpl.UnknownFields
+	sizeCache         protoimpl.SizeCache
+}
+
+func (x *SetConversationRetentionRequest) Reset() {
+	*x = SetConversationRetentionRequest{}
+	mi := &file_chat_v1_chat_proto_msgTypes[35]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *SetConversationRetentionRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*SetConversationRetentionRequest) ProtoMessage() {}
+
+func (x *SetConversationRetentionRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_chat_v1_chat_proto_msgTypes[35]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use SetConversationRetentionRequest.ProtoReflect.Descriptor instead.
+func (*SetConversationRetentionRequest) Descriptor() ([]byte, []int) {
+	return file_chat_v1_chat_proto_rawDescGZIP(), []int{35}
+}
+
+func (x *SetConversationRetentionRequest) GetConversationId() string {
+	if x != nil {
+		return x.ConversationId
+	}
+	return ""
+}
+
+func (x *SetConversationRetentionRequest) GetMessageTtlSeconds() int64 {
+	if x != nil {
+		return x.MessageTtlSeconds
+	}
+	return 0
+}
+
+type SetConversationRetentionResponse struct {
+	state             protoimpl.MessageState `protogen:"open.v1"`
+	Success           bool                   `protobuf:"varint,1,opt,name=success,proto3" json:"success,omitempty"`
+	MessageTtlSeconds int64                  `protobuf:"varint,2,opt,name=message_ttl_seconds,json=messageTtlSeconds,proto3" json:"message_ttl_seconds,omitempty"`
+	unknownFields     protoimpl.UnknownFields
+	sizeCache         protoimpl.SizeCache
+}
+
+func (x *SetConversationRetentionResponse) Reset() {
+	*x = SetConversationRetentionResponse{}
+	mi := &file_chat_v1_chat_proto_msgTypes[36]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *SetConversationRetentionResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*SetConversationRetentionResponse) ProtoMessage() {}
+
+func (x *SetConversationRetentionResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_chat_v1_chat_proto_msgTypes[36]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use SetConversationRetentionResponse.ProtoReflect.Descriptor instead.
+func (*SetConversationRetentionResponse) Descriptor() ([]byte, []int) {
+	return file_chat_v1_chat_proto_rawDescGZIP(), []int{36}
+}
+
+func (x *SetConversationRetentionResponse) GetSuccess() bool {
+	if x != nil {
+		return x.Success
+	}
+	return false
+}
+
+func (x *SetConversationRetentionResponse) GetMessageTtlSeconds() int64 {
+	if x != nil {
+		return x.MessageTtlSeconds
+	}
+	return 0
+}
+
+type AddReactionRequest struct {
+	state     protoimpl.MessageState `protogen:"open.v1"`
+	MessageId string                 `protobuf:"bytes,1,opt,name=message_id,json=messageId,proto3" json:"message_id,omitempty"`
+	// user_id is extracted from JWT token via auth middleware
+	Emoji         string `protobuf:"bytes,2,opt,name=emoji,proto3" json:"emoji,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *AddReactionRequest) Reset() {
+	*x = AddReactionRequest{}
+	mi := &file_chat_v1_chat_proto_msgTypes[37]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *AddReactionRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*AddReactionRequest) ProtoMessage() {}
+
+func (x *AddReactionRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_chat_v1_chat_proto_msgTypes[37]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use AddReactionRequest.ProtoReflect.Descriptor instead.
+func (*AddReactionRequest) Descriptor() ([]byte, []int) {
+	return file_chat_v1_chat_proto_rawDescGZIP(), []int{37}
+}
+
+func (x *AddReactionRequest) GetMessageId() string {
+	if x != nil {
+		return x.MessageId
+	}
+	return ""
+}
+
+func (x *AddReactionRequest) GetEmoji() string {
+	if x != nil {
+		return x.Emoji
+	}
+	return ""
+}
+
+type AddReactionResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	MessageId     string                 `protobuf:"bytes,1,opt,name=message_id,json=messageId,proto3" json:"message_id,omitempty"`
+	Emoji         string                 `protobuf:"bytes,2,opt,name=emoji,proto3" json:"emoji,omitempty"`
+	Count         int32                  `protobuf:"varint,3,opt,name=count,proto3" json:"count,omitempty"` // số lượng emoji này trên tin nhắn sau khi thêm
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *AddReactionResponse) Reset() {
+	*x = AddReactionResponse{}
+	mi := &file_chat_v1_chat_proto_msgTypes[38]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *AddReactionResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*AddReactionResponse) ProtoMessage() {}
+
+func (x *AddReactionResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_chat_v1_chat_proto_msgTypes[38]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use AddReactionResponse.ProtoReflect.Descriptor instead.
+func (*AddReactionResponse) Descriptor() ([]byte, []int) {
+	return file_chat_v1_chat_proto_rawDescGZIP(), []int{38}
+}
+
+func (x *AddReactionResponse) GetMessageId() string {
+	if x != nil {
+		return x.MessageId
+	}
+	return ""
+}
+
+func (x *AddReactionResponse) GetEmoji() string {
+	if x != nil {
+		return x.Emoji
+	}
+	return ""
+}
+
+func (x *AddReactionResponse) GetCount() int32 {
+	if x != nil {
+		return x.Count
+	}
+	return 0
+}
+
+type RemoveReactionRequest struct {
+	state     protoimpl.MessageState `protogen:"open.v1"`
+	MessageId string                 `protobuf:"bytes,1,opt,name=message_id,json=messageId,proto3" json:"message_id,omitempty"`
+	// user_id is extracted from JWT token via auth middleware
+	Emoji         string `protobuf:"bytes,2,opt,name=emoji,proto3" json:"emoji,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *RemoveReactionRequest) Reset() {
+	*x = RemoveReactionRequest{}
+	mi := &file_chat_v1_chat_proto_msgTypes[39]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *RemoveReactionRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*RemoveReactionRequest) ProtoMessage() {}
+
+func (x *RemoveReactionRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_chat_v1_chat_proto_msgTypes[39]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use RemoveReactionRequest.ProtoReflect.Descriptor instead.
+func (*RemoveReactionRequest) Descriptor() ([]byte, []int) {
+	return file_chat_v1_chat_proto_rawDescGZIP(), []int{39}
+}
+
+func (x *RemoveReactionRequest) GetMessageId() string {
+	if x != nil {
+		return x.MessageId
+	}
+	return ""
+}
+
+func (x *RemoveReactionRequest) GetEmoji() string {
+	if x != nil {
+		return x.Emoji
+	}
+	return ""
+}
+
+type RemoveReactionResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	MessageId     string                 `protobuf:"bytes,1,opt,name=message_id,json=messageId,proto3" json:"message_id,omitempty"`
+	Emoji         string                 `protobuf:"bytes,2,opt,name=emoji,proto3" json:"emoji,omitempty"`
+	Count         int32                  `protobuf:"varint,3,opt,name=count,proto3" json:"count,omitempty"` // số lượng còn lại sau khi bỏ
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *RemoveReactionResponse) Reset() {
+	*x = RemoveReactionResponse{}
+	mi := &file_chat_v1_chat_proto_msgTypes[40]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *RemoveReactionResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*RemoveReactionResponse) ProtoMessage() {}
+
+func (x *RemoveReactionResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_chat_v1_chat_proto_msgTypes[40]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use RemoveReactionResponse.ProtoReflect.Descriptor instead.
+func (*RemoveReactionResponse) Descriptor() ([]byte, []int) {
+	return file_chat_v1_chat_proto_rawDescGZIP(), []int{40}
+}
+
+func (x *RemoveReactionResponse) GetMessageId() string {
+	if x != nil {
+		return x.MessageId
+	}
+	return ""
+}
+
+func (x *RemoveReactionResponse) GetEmoji() string {
+	if x != nil {
+		return x.Emoji
+	}
+	return ""
+}
+
+func (x *RemoveReactionResponse) GetCount() int32 {
 	if x != nil {
-		return x.BeforeTimestamp
+		return x.Count
 	}
-	return ""
+	return 0
 }
 
-type GetMessagesResponse struct {
+// key nằm trong body (không phải path) vì client tự chọn key và có thể chứa ký tự bất kỳ
+type InspectIdempotencyKeyRequest struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
-	Messages      []*ChatMessage         `protobuf:"bytes,1,rep,name=messages,proto3" json:"messages,omitempty"`
-	NextCursor    string                 `protobuf:"bytes,2,opt,name=next_cursor,json=nextCursor,proto3" json:"next_cursor,omitempty"` // timestamp string for next page
+	Key           string                 `protobuf:"bytes,1,opt,name=key,proto3" json:"key,omitempty"` // idempotency_key như client đã gửi, không có prefix Redis
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
 
-func (x *GetMessagesResponse) Reset() {
-	*x = GetMessagesResponse{}
-	mi := &file_chat_v1_chat_proto_msgTypes[3]
+func (x *InspectIdempotencyKeyRequest) Reset() {
+	*x = InspectIdempotencyKeyRequest{}
+	mi := &file_chat_v1_chat_proto_msgTypes[41]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
 
-func (x *GetMessagesResponse) String() string {
+func (x *InspectIdempotencyKeyRequest) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*GetMessagesResponse) ProtoMessage() {}
+func (*InspectIdempotencyKeyRequest) ProtoMessage() {}
 
-func (x *GetMessagesResponse) ProtoReflect() protoreflect.Message {
-	mi := &file_chat_v1_chat_proto_msgTypes[3]
+func (x *InspectIdempotencyKeyRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_chat_v1_chat_proto_msgTypes[41]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -311,54 +2692,42 @@ func (x *GetMessagesResponse) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use GetMessagesResponse.ProtoReflect.Descriptor instead.
-func (*GetMessagesResponse) Descriptor() ([]byte, []int) {
-	return file_chat_v1_chat_proto_rawDescGZIP(), []int{3}
-}
-
-func (x *GetMessagesResponse) GetMessages() []*ChatMessage {
-	if x != nil {
-		return x.Messages
-	}
-	return nil
+// Deprecated: Use InspectIdempotencyKeyRequest.ProtoReflect.Descriptor instead.
+func (*InspectIdempotencyKeyRequest) Descriptor() ([]byte, []int) {
+	return file_chat_v1_chat_proto_rawDescGZIP(), []int{41}
 }
 
-func (x *GetMessagesResponse) GetNextCursor() string {
+func (x *InspectIdempotencyKeyRequest) GetKey() string {
 	if x != nil {
-		return x.NextCursor
+		return x.Key
 	}
 	return ""
 }
 
-type ChatMessage struct {
-	state          protoimpl.MessageState `protogen:"open.v1"`
-	Id             string                 `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
-	ConversationId string                 `protobuf:"bytes,2,opt,name=conversation_id,json=conversationId,proto3" json:"conversation_id,omitempty"`
-	SenderId       string                 `protobuf:"bytes,3,opt,name=sender_id,json=senderId,proto3" json:"sender_id,omitempty"`
-	Content        string                 `protobuf:"bytes,4,opt,name=content,proto3" json:"content,omitempty"`
-	CreatedAt      string                 `protobuf:"bytes,5,opt,name=created_at,json=createdAt,proto3" json:"created_at,omitempty"`
-	// Media support
-	Type          MessageType `protobuf:"varint,6,opt,name=type,proto3,enum=chat.v1.MessageType" json:"type,omitempty"`
-	MediaUrl      string      `protobuf:"bytes,7,opt,name=media_url,json=mediaUrl,proto3" json:"media_url,omitempty"`
+type InspectIdempotencyKeyResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Key           string                 `protobuf:"bytes,1,opt,name=key,proto3" json:"key,omitempty"`
+	Exists        bool                   `protobuf:"varint,2,opt,name=exists,proto3" json:"exists,omitempty"`
+	TtlSeconds    int64                  `protobuf:"varint,3,opt,name=ttl_seconds,json=ttlSeconds,proto3" json:"ttl_seconds,omitempty"` // thời gian sống còn lại; -1 = không bao giờ hết hạn, 0 khi key không tồn tại
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
 
-func (x *ChatMessage) Reset() {
-	*x = ChatMessage{}
-	mi := &file_chat_v1_chat_proto_msgTypes[4]
+func (x *InspectIdempotencyKeyResponse) Reset() {
+	*x = InspectIdempotencyKeyResponse{}
+	mi := &file_chat_v1_chat_proto_msgTypes[42]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
 
-func (x *ChatMessage) String() string {
+func (x *InspectIdempotencyKeyResponse) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*ChatMessage) ProtoMessage() {}
+func (*InspectIdempotencyKeyResponse) ProtoMessage() {}
 
-func (x *ChatMessage) ProtoReflect() protoreflect.Message {
-	mi := &file_chat_v1_chat_proto_msgTypes[4]
+func (x *InspectIdempotencyKeyResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_chat_v1_chat_proto_msgTypes[42]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -369,84 +2738,99 @@ func (x *ChatMessage) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use ChatMessage.ProtoReflect.Descriptor instead.
-func (*ChatMessage) Descriptor() ([]byte, []int) {
-	return file_chat_v1_chat_proto_rawDescGZIP(), []int{4}
+// Deprecated: Use InspectIdempotencyKeyResponse.ProtoReflect.Descriptor instead.
+func (*InspectIdempotencyKeyResponse) Descriptor() ([]byte, []int) {
+	return file_chat_v1_chat_proto_rawDescGZIP(), []int{42}
 }
 
-func (x *ChatMessage) GetId() string {
+func (x *InspectIdempotencyKeyResponse) GetKey() string {
 	if x != nil {
-		return x.Id
+		return x.Key
 	}
 	return ""
 }
 
-func (x *ChatMessage) GetConversationId() string {
+func (x *InspectIdempotencyKeyResponse) GetExists() bool {
 	if x != nil {
-		return x.ConversationId
+		return x.Exists
 	}
-	return ""
+	return false
 }
 
-func (x *ChatMessage) GetSenderId() string {
+func (x *InspectIdempotencyKeyResponse) GetTtlSeconds() int64 {
 	if x != nil {
-		return x.SenderId
+		return x.TtlSeconds
 	}
-	return ""
+	return 0
 }
 
-func (x *ChatMessage) GetContent() string {
-	if x != nil {
-		return x.Content
-	}
-	return ""
+type ClearIdempotencyKeyRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Key           string                 `protobuf:"bytes,1,opt,name=key,proto3" json:"key,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
 }
 
-func (x *ChatMessage) GetCreatedAt() string {
-	if x != nil {
-		return x.CreatedAt
-	}
-	return ""
+func (x *ClearIdempotencyKeyRequest) Reset() {
+	*x = ClearIdempotencyKeyRequest{}
+	mi := &file_chat_v1_chat_proto_msgTypes[43]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
 }
 
-func (x *ChatMessage) GetType() MessageType {
+func (x *ClearIdempotencyKeyRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ClearIdempotencyKeyRequest) ProtoMessage() {}
+
+func (x *ClearIdempotencyKeyRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_chat_v1_chat_proto_msgTypes[43]
 	if x != nil {
-		return x.Type
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
 	}
-	return MessageType_MESSAGE_TYPE_UNSPECIFIED
+	return mi.MessageOf(x)
 }
 
-func (x *ChatMessage) GetMediaUrl() string {
+// Deprecated: Use ClearIdempotencyKeyRequest.ProtoReflect.Descriptor instead.
+func (*ClearIdempotencyKeyRequest) Descriptor() ([]byte, []int) {
+	return file_chat_v1_chat_proto_rawDescGZIP(), []int{43}
+}
+
+func (x *ClearIdempotencyKeyRequest) GetKey() string {
 	if x != nil {
-		return x.MediaUrl
+		return x.Key
 	}
 	return ""
 }
 
-type GetConversationsRequest struct {
-	state protoimpl.MessageState `protogen:"open.v1"`
-	// user_id is extracted from JWT token via auth middleware
-	Limit         int32  `protobuf:"varint,2,opt,name=limit,proto3" json:"limit,omitempty"`
-	Cursor        string `protobuf:"bytes,3,opt,name=cursor,proto3" json:"cursor,omitempty"` // timestamp của last_message_at
+type ClearIdempotencyKeyResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Key           string                 `protobuf:"bytes,1,opt,name=key,proto3" json:"key,omitempty"`
+	Existed       bool                   `protobuf:"varint,2,opt,name=existed,proto3" json:"existed,omitempty"` // false nếu key đã hết hạn hoặc chưa từng tồn tại
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
 
-func (x *GetConversationsRequest) Reset() {
-	*x = GetConversationsRequest{}
-	mi := &file_chat_v1_chat_proto_msgTypes[5]
+func (x *ClearIdempotencyKeyResponse) Reset() {
+	*x = ClearIdempotencyKeyResponse{}
+	mi := &file_chat_v1_chat_proto_msgTypes[44]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
 
-func (x *GetConversationsRequest) String() string {
+func (x *ClearIdempotencyKeyResponse) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*GetConversationsRequest) ProtoMessage() {}
+func (*ClearIdempotencyKeyResponse) ProtoMessage() {}
 
-func (x *GetConversationsRequest) ProtoReflect() protoreflect.Message {
-	mi := &file_chat_v1_chat_proto_msgTypes[5]
+func (x *ClearIdempotencyKeyResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_chat_v1_chat_proto_msgTypes[44]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -457,48 +2841,49 @@ func (x *GetConversationsRequest) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use GetConversationsRequest.ProtoReflect.Descriptor instead.
-func (*GetConversationsRequest) Descriptor() ([]byte, []int) {
-	return file_chat_v1_chat_proto_rawDescGZIP(), []int{5}
+// Deprecated: Use ClearIdempotencyKeyResponse.ProtoReflect.Descriptor instead.
+func (*ClearIdempotencyKeyResponse) Descriptor() ([]byte, []int) {
+	return file_chat_v1_chat_proto_rawDescGZIP(), []int{44}
 }
 
-func (x *GetConversationsRequest) GetLimit() int32 {
+func (x *ClearIdempotencyKeyResponse) GetKey() string {
 	if x != nil {
-		return x.Limit
+		return x.Key
 	}
-	return 0
+	return ""
 }
 
-func (x *GetConversationsRequest) GetCursor() string {
+func (x *ClearIdempotencyKeyResponse) GetExisted() bool {
 	if x != nil {
-		return x.Cursor
+		return x.Existed
 	}
-	return ""
+	return false
 }
 
-type GetConversationsResponse struct {
-	state         protoimpl.MessageState `protogen:"open.v1"`
-	Conversations []*Conversation        `protobuf:"bytes,1,rep,name=conversations,proto3" json:"conversations,omitempty"`
-	NextCursor    string                 `protobuf:"bytes,2,opt,name=next_cursor,json=nextCursor,proto3" json:"next_cursor,omitempty"`
-	unknownFields protoimpl.UnknownFields
-	sizeCache     protoimpl.SizeCache
+type ExportConversationRequest struct {
+	state          protoimpl.MessageState `protogen:"open.v1"`
+	ConversationId string                 `protobuf:"bytes,1,opt,name=conversation_id,json=conversationId,proto3" json:"conversation_id,omitempty"`
+	Format         ExportFormat           `protobuf:"varint,2,opt,name=format,proto3,enum=chat.v1.ExportFormat" json:"format,omitempty"` // mặc định MESSAGES
+	PageSize       int32                  `protobuf:"varint,3,opt,name=page_size,json=pageSize,proto3" json:"page_size,omitempty"`       // số tin nhắn mỗi chunk, mặc định 500, tối đa 1000
+	unknownFields  protoimpl.UnknownFields
+	sizeCache      protoimpl.SizeCache
 }
 
-func (x *GetConversationsResponse) Reset() {
-	*x = GetConversationsResponse{}
-	mi := &file_chat_v1_chat_proto_msgTypes[6]
+func (x *ExportConversationRequest) Reset() {
+	*x = ExportConversationRequest{}
+	mi := &file_chat_v1_chat_proto_msgTypes[45]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
 
-func (x *GetConversationsResponse) String() string {
+func (x *ExportConversationRequest) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*GetConversationsResponse) ProtoMessage() {}
+func (*ExportConversationRequest) ProtoMessage() {}
 
-func (x *GetConversationsResponse) ProtoReflect() protoreflect.Message {
-	mi := &file_chat_v1_chat_proto_msgTypes[6]
+func (x *ExportConversationRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_chat_v1_chat_proto_msgTypes[45]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -509,50 +2894,57 @@ func (x *GetConversationsResponse) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use GetConversationsResponse.ProtoReflect.Descriptor instead.
-func (*GetConversationsResponse) Descriptor() ([]byte, []int) {
-	return file_chat_v1_chat_proto_rawDescGZIP(), []int{6}
+// Deprecated: Use ExportConversationRequest.ProtoReflect.Descriptor instead.
+func (*ExportConversationRequest) Descriptor() ([]byte, []int) {
+	return file_chat_v1_chat_proto_rawDescGZIP(), []int{45}
 }
 
-func (x *GetConversationsResponse) GetConversations() []*Conversation {
+func (x *ExportConversationRequest) GetConversationId() string {
 	if x != nil {
-		return x.Conversations
+		return x.ConversationId
 	}
-	return nil
+	return ""
 }
 
-func (x *GetConversationsResponse) GetNextCursor() string {
+func (x *ExportConversationRequest) GetFormat() ExportFormat {
 	if x != nil {
-		return x.NextCursor
+		return x.Format
 	}
-	return ""
+	return ExportFormat_EXPORT_FORMAT_UNSPECIFIED
 }
 
-type Conversation struct {
-	state              protoimpl.MessageState `protogen:"open.v1"`
-	Id                 string                 `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
-	LastMessageContent string                 `protobuf:"bytes,2,opt,name=last_message_content,json=lastMessageContent,proto3" json:"last_message_content,omitempty"`
-	LastMessageAt      string                 `protobuf:"bytes,3,opt,name=last_message_at,json=lastMessageAt,proto3" json:"last_message_at,omitempty"`
-	UnreadCount        int32                  `protobuf:"varint,4,opt,name=unread_count,json=unreadCount,proto3" json:"unread_count,omitempty"`
-	unknownFields      protoimpl.UnknownFields
-	sizeCache          protoimpl.SizeCache
+func (x *ExportConversationRequest) GetPageSize() int32 {
+	if x != nil {
+		return x.PageSize
+	}
+	return 0
 }
 
-func (x *Conversation) Reset() {
-	*x = Conversation{}
-	mi := &file_chat_v1_chat_proto_msgTypes[7]
+// Một trang của bản export; các chunk nối lại theo thứ tự là toàn bộ lịch sử
+type ExportConversationChunk struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Messages      []*ChatMessage         `protobuf:"bytes,1,rep,name=messages,proto3" json:"messages,omitempty"`                              // với EXPORT_FORMAT_MESSAGES
+	Data          []byte                 `protobuf:"bytes,2,opt,name=data,proto3" json:"data,omitempty"`                                      // với EXPORT_FORMAT_JSONL
+	MessageCount  int32                  `protobuf:"varint,3,opt,name=message_count,json=messageCount,proto3" json:"message_count,omitempty"` // số tin nhắn trong chunk
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ExportConversationChunk) Reset() {
+	*x = ExportConversationChunk{}
+	mi := &file_chat_v1_chat_proto_msgTypes[46]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
 
-func (x *Conversation) String() string {
+func (x *ExportConversationChunk) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*Conversation) ProtoMessage() {}
+func (*ExportConversationChunk) ProtoMessage() {}
 
-func (x *Conversation) ProtoReflect() protoreflect.Message {
-	mi := &file_chat_v1_chat_proto_msgTypes[7]
+func (x *ExportConversationChunk) ProtoReflect() protoreflect.Message {
+	mi := &file_chat_v1_chat_proto_msgTypes[46]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -563,61 +2955,53 @@ func (x *Conversation) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use Conversation.ProtoReflect.Descriptor instead.
-func (*Conversation) Descriptor() ([]byte, []int) {
-	return file_chat_v1_chat_proto_rawDescGZIP(), []int{7}
-}
-
-func (x *Conversation) GetId() string {
-	if x != nil {
-		return x.Id
-	}
-	return ""
+// Deprecated: Use ExportConversationChunk.ProtoReflect.Descriptor instead.
+func (*ExportConversationChunk) Descriptor() ([]byte, []int) {
+	return file_chat_v1_chat_proto_rawDescGZIP(), []int{46}
 }
 
-func (x *Conversation) GetLastMessageContent() string {
+func (x *ExportConversationChunk) GetMessages() []*ChatMessage {
 	if x != nil {
-		return x.LastMessageContent
+		return x.Messages
 	}
-	return ""
+	return nil
 }
 
-func (x *Conversation) GetLastMessageAt() string {
+func (x *ExportConversationChunk) GetData() []byte {
 	if x != nil {
-		return x.LastMessageAt
+		return x.Data
 	}
-	return ""
+	return nil
 }
 
-func (x *Conversation) GetUnreadCount() int32 {
+func (x *ExportConversationChunk) GetMessageCount() int32 {
 	if x != nil {
-		return x.UnreadCount
+		return x.MessageCount
 	}
 	return 0
 }
 
-type MarkAsReadRequest struct {
-	state          protoimpl.MessageState `protogen:"open.v1"`
-	ConversationId string                 `protobuf:"bytes,1,opt,name=conversation_id,json=conversationId,proto3" json:"conversation_id,omitempty"` // user_id is extracted from JWT token via auth middleware
-	unknownFields  protoimpl.UnknownFields
-	sizeCache      protoimpl.SizeCache
+type StreamEventsRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
 }
 
-func (x *MarkAsReadRequest) Reset() {
-	*x = MarkAsReadRequest{}
-	mi := &file_chat_v1_chat_proto_msgTypes[8]
+func (x *StreamEventsRequest) Reset() {
+	*x = StreamEventsRequest{}
+	mi := &file_chat_v1_chat_proto_msgTypes[47]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
 
-func (x *MarkAsReadRequest) String() string {
+func (x *StreamEventsRequest) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*MarkAsReadRequest) ProtoMessage() {}
+func (*StreamEventsRequest) ProtoMessage() {}
 
-func (x *MarkAsReadRequest) ProtoReflect() protoreflect.Message {
-	mi := &file_chat_v1_chat_proto_msgTypes[8]
+func (x *StreamEventsRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_chat_v1_chat_proto_msgTypes[47]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -628,40 +3012,38 @@ func (x *MarkAsReadRequest) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use MarkAsReadRequest.ProtoReflect.Descriptor instead.
-func (*MarkAsReadRequest) Descriptor() ([]byte, []int) {
-	return file_chat_v1_chat_proto_rawDescGZIP(), []int{8}
-}
-
-func (x *MarkAsReadRequest) GetConversationId() string {
-	if x != nil {
-		return x.ConversationId
-	}
-	return ""
+// Deprecated: Use StreamEventsRequest.ProtoReflect.Descriptor instead.
+func (*StreamEventsRequest) Descriptor() ([]byte, []int) {
+	return file_chat_v1_chat_proto_rawDescGZIP(), []int{47}
 }
 
-type MarkAsReadResponse struct {
+// Real-time event, the same envelope the ws-gateway sends over WebSocket
+type ChatEvent struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
-	Success       bool                   `protobuf:"varint,1,opt,name=success,proto3" json:"success,omitempty"`
+	EventId       string                 `protobuf:"bytes,1,opt,name=event_id,json=eventId,proto3" json:"event_id,omitempty"`
+	AggregateType string                 `protobuf:"bytes,2,opt,name=aggregate_type,json=aggregateType,proto3" json:"aggregate_type,omitempty"` // message, conversation
+	AggregateId   string                 `protobuf:"bytes,3,opt,name=aggregate_id,json=aggregateId,proto3" json:"aggregate_id,omitempty"`
+	Payload       string                 `protobuf:"bytes,4,opt,name=payload,proto3" json:"payload,omitempty"`                       // JSON event body (event_type message.sent, conversation.read, ...)
+	CreatedAt     int64                  `protobuf:"varint,5,opt,name=created_at,json=createdAt,proto3" json:"created_at,omitempty"` // Unix milliseconds
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
 
-func (x *MarkAsReadResponse) Reset() {
-	*x = MarkAsReadResponse{}
-	mi := &file_chat_v1_chat_proto_msgTypes[9]
+func (x *ChatEvent) Reset() {
+	*x = ChatEvent{}
+	mi := &file_chat_v1_chat_proto_msgTypes[48]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
 
-func (x *MarkAsReadResponse) String() string {
+func (x *ChatEvent) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*MarkAsReadResponse) ProtoMessage() {}
+func (*ChatEvent) ProtoMessage() {}
 
-func (x *MarkAsReadResponse) ProtoReflect() protoreflect.Message {
-	mi := &file_chat_v1_chat_proto_msgTypes[9]
+func (x *ChatEvent) ProtoReflect() protoreflect.Message {
+	mi := &file_chat_v1_chat_proto_msgTypes[48]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -672,16 +3054,44 @@ func (x *MarkAsReadResponse) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use MarkAsReadResponse.ProtoReflect.Descriptor instead.
-func (*MarkAsReadResponse) Descriptor() ([]byte, []int) {
-	return file_chat_v1_chat_proto_rawDescGZIP(), []int{9}
+// Deprecated: Use ChatEvent.ProtoReflect.Descriptor instead.
+func (*ChatEvent) Descriptor() ([]byte, []int) {
+	return file_chat_v1_chat_proto_rawDescGZIP(), []int{48}
 }
 
-func (x *MarkAsReadResponse) GetSuccess() bool {
+func (x *ChatEvent) GetEventId() string {
 	if x != nil {
-		return x.Success
+		return x.EventId
 	}
-	return false
+	return ""
+}
+
+func (x *ChatEvent) GetAggregateType() string {
+	if x != nil {
+		return x.AggregateType
+	}
+	return ""
+}
+
+func (x *ChatEvent) GetAggregateId() string {
+	if x != nil {
+		return x.AggregateId
+	}
+	return ""
+}
+
+func (x *ChatEvent) GetPayload() string {
+	if x != nil {
+		return x.Payload
+	}
+	return ""
+}
+
+func (x *ChatEvent) GetCreatedAt() int64 {
+	if x != nil {
+		return x.CreatedAt
+	}
+	return 0
 }
 
 // Upload credentials for Cloudinary
@@ -693,7 +3103,7 @@ type GetUploadCredentialsRequest struct {
 
 func (x *GetUploadCredentialsRequest) Reset() {
 	*x = GetUploadCredentialsRequest{}
-	mi := &file_chat_v1_chat_proto_msgTypes[10]
+	mi := &file_chat_v1_chat_proto_msgTypes[49]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -705,7 +3115,7 @@ func (x *GetUploadCredentialsRequest) String() string {
 func (*GetUploadCredentialsRequest) ProtoMessage() {}
 
 func (x *GetUploadCredentialsRequest) ProtoReflect() protoreflect.Message {
-	mi := &file_chat_v1_chat_proto_msgTypes[10]
+	mi := &file_chat_v1_chat_proto_msgTypes[49]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -718,7 +3128,7 @@ func (x *GetUploadCredentialsRequest) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use GetUploadCredentialsRequest.ProtoReflect.Descriptor instead.
 func (*GetUploadCredentialsRequest) Descriptor() ([]byte, []int) {
-	return file_chat_v1_chat_proto_rawDescGZIP(), []int{10}
+	return file_chat_v1_chat_proto_rawDescGZIP(), []int{49}
 }
 
 type GetUploadCredentialsResponse struct {
@@ -734,7 +3144,7 @@ type GetUploadCredentialsResponse struct {
 
 func (x *GetUploadCredentialsResponse) Reset() {
 	*x = GetUploadCredentialsResponse{}
-	mi := &file_chat_v1_chat_proto_msgTypes[11]
+	mi := &file_chat_v1_chat_proto_msgTypes[50]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -746,7 +3156,7 @@ func (x *GetUploadCredentialsResponse) String() string {
 func (*GetUploadCredentialsResponse) ProtoMessage() {}
 
 func (x *GetUploadCredentialsResponse) ProtoReflect() protoreflect.Message {
-	mi := &file_chat_v1_chat_proto_msgTypes[11]
+	mi := &file_chat_v1_chat_proto_msgTypes[50]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -759,7 +3169,7 @@ func (x *GetUploadCredentialsResponse) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use GetUploadCredentialsResponse.ProtoReflect.Descriptor instead.
 func (*GetUploadCredentialsResponse) Descriptor() ([]byte, []int) {
-	return file_chat_v1_chat_proto_rawDescGZIP(), []int{11}
+	return file_chat_v1_chat_proto_rawDescGZIP(), []int{50}
 }
 
 func (x *GetUploadCredentialsResponse) GetSignature() string {
@@ -801,26 +3211,45 @@ var File_chat_v1_chat_proto protoreflect.FileDescriptor
 
 const file_chat_v1_chat_proto_rawDesc = "" +
 	"\n" +
-	"\x12chat/v1/chat.proto\x12\achat.v1\x1a\x1cgoogle/api/annotations.proto\"\xea\x01\n" +
+	"\x12chat/v1/chat.proto\x12\achat.v1\x1a\x1cgoogle/api/annotations.proto\"\xa1\x02\n" +
 	"\x12SendMessageRequest\x12'\n" +
 	"\x0fconversation_id\x18\x01 \x01(\tR\x0econversationId\x12\x18\n" +
 	"\acontent\x18\x03 \x01(\tR\acontent\x12'\n" +
 	"\x0fidempotency_key\x18\x04 \x01(\tR\x0eidempotencyKey\x12!\n" +
 	"\freceiver_ids\x18\x05 \x03(\tR\vreceiverIds\x12(\n" +
 	"\x04type\x18\x06 \x01(\x0e2\x14.chat.v1.MessageTypeR\x04type\x12\x1b\n" +
-	"\tmedia_url\x18\a \x01(\tR\bmediaUrl\"L\n" +
+	"\tmedia_url\x18\a \x01(\tR\bmediaUrl\x125\n" +
+	"\vattachments\x18\b \x03(\v2\x13.chat.v1.AttachmentR\vattachments\"}\n" +
+	"\n" +
+	"Attachment\x12\x10\n" +
+	"\x03url\x18\x01 \x01(\tR\x03url\x12\x1b\n" +
+	"\tmime_type\x18\x02 \x01(\tR\bmimeType\x12\x12\n" +
+	"\x04size\x18\x03 \x01(\x03R\x04size\x12\x14\n" +
+	"\x05width\x18\x04 \x01(\x05R\x05width\x12\x16\n" +
+	"\x06height\x18\x05 \x01(\x05R\x06height\"\xba\x01\n" +
 	"\x13SendMessageResponse\x12\x1d\n" +
 	"\n" +
 	"message_id\x18\x01 \x01(\tR\tmessageId\x12\x16\n" +
-	"\x06status\x18\x02 \x01(\tR\x06status\"~\n" +
+	"\x06status\x18\x02 \x01(\tR\x06status\x121\n" +
+	"\x14conversation_created\x18\x03 \x01(\bR\x13conversationCreated\x129\n" +
+	"\fconversation\x18\x04 \x01(\v2\x15.chat.v1.ConversationR\fconversation\"\xbf\x01\n" +
 	"\x12GetMessagesRequest\x12'\n" +
 	"\x0fconversation_id\x18\x01 \x01(\tR\x0econversationId\x12\x14\n" +
 	"\x05limit\x18\x02 \x01(\x05R\x05limit\x12)\n" +
-	"\x10before_timestamp\x18\x03 \x01(\tR\x0fbeforeTimestamp\"h\n" +
+	"\x10before_timestamp\x18\x03 \x01(\tR\x0fbeforeTimestamp\x12\x16\n" +
+	"\x06cursor\x18\x04 \x01(\tR\x06cursor\x12'\n" +
+	"\x0finclude_deleted\x18\x05 \x01(\bR\x0eincludeDeleted\"h\n" +
 	"\x13GetMessagesResponse\x120\n" +
 	"\bmessages\x18\x01 \x03(\v2\x14.chat.v1.ChatMessageR\bmessages\x12\x1f\n" +
 	"\vnext_cursor\x18\x02 \x01(\tR\n" +
-	"nextCursor\"\xe3\x01\n" +
+	"nextCursor\"B\n" +
+	"\x12GetMentionsRequest\x12\x14\n" +
+	"\x05limit\x18\x01 \x01(\x05R\x05limit\x12\x16\n" +
+	"\x06cursor\x18\x02 \x01(\tR\x06cursor\"h\n" +
+	"\x13GetMentionsResponse\x120\n" +
+	"\bmessages\x18\x01 \x03(\v2\x14.chat.v1.ChatMessageR\bmessages\x12\x1f\n" +
+	"\vnext_cursor\x18\x02 \x01(\tR\n" +
+	"nextCursor\"\x9e\x04\n" +
 	"\vChatMessage\x12\x0e\n" +
 	"\x02id\x18\x01 \x01(\tR\x02id\x12'\n" +
 	"\x0fconversation_id\x18\x02 \x01(\tR\x0econversationId\x12\x1b\n" +
@@ -829,23 +3258,150 @@ const file_chat_v1_chat_proto_rawDesc = "" +
 	"\n" +
 	"created_at\x18\x05 \x01(\tR\tcreatedAt\x12(\n" +
 	"\x04type\x18\x06 \x01(\x0e2\x14.chat.v1.MessageTypeR\x04type\x12\x1b\n" +
-	"\tmedia_url\x18\a \x01(\tR\bmediaUrl\"G\n" +
+	"\tmedia_url\x18\a \x01(\tR\bmediaUrl\x125\n" +
+	"\vattachments\x18\b \x03(\v2\x13.chat.v1.AttachmentR\vattachments\x12\x1f\n" +
+	"\vsender_name\x18\t \x01(\tR\n" +
+	"senderName\x12*\n" +
+	"\x11sender_avatar_url\x18\n" +
+	" \x01(\tR\x0fsenderAvatarUrl\x12.\n" +
+	"\x06status\x18\v \x01(\x0e2\x16.chat.v1.MessageStatusR\x06status\x12\x18\n" +
+	"\adeleted\x18\f \x01(\bR\adeleted\x12\x16\n" +
+	"\x06edited\x18\r \x01(\bR\x06edited\x12\x1b\n" +
+	"\tedited_at\x18\x0e \x01(\tR\beditedAt\x126\n" +
+	"\treactions\x18\x0f \x03(\v2\x18.chat.v1.ReactionSummaryR\treactions\"a\n" +
+	"\x0fReactionSummary\x12\x14\n" +
+	"\x05emoji\x18\x01 \x01(\tR\x05emoji\x12\x14\n" +
+	"\x05count\x18\x02 \x01(\x05R\x05count\x12\"\n" +
+	"\rreacted_by_me\x18\x03 \x01(\bR\vreactedByMe\"\x97\x01\n" +
 	"\x17GetConversationsRequest\x12\x14\n" +
 	"\x05limit\x18\x02 \x01(\x05R\x05limit\x12\x16\n" +
-	"\x06cursor\x18\x03 \x01(\tR\x06cursor\"x\n" +
+	"\x06cursor\x18\x03 \x01(\tR\x06cursor\x12)\n" +
+	"\x10include_archived\x18\x04 \x01(\bR\x0fincludeArchived\x12#\n" +
+	"\ronly_archived\x18\x05 \x01(\bR\fonlyArchived\"x\n" +
 	"\x18GetConversationsResponse\x12;\n" +
 	"\rconversations\x18\x01 \x03(\v2\x15.chat.v1.ConversationR\rconversations\x12\x1f\n" +
 	"\vnext_cursor\x18\x02 \x01(\tR\n" +
-	"nextCursor\"\x9b\x01\n" +
+	"nextCursor\"I\n" +
+	"\x1cGetConversationsByIdsRequest\x12)\n" +
+	"\x10conversation_ids\x18\x01 \x03(\tR\x0fconversationIds\"\\\n" +
+	"\x1dGetConversationsByIdsResponse\x12;\n" +
+	"\rconversations\x18\x01 \x03(\v2\x15.chat.v1.ConversationR\rconversations\"\xaf\x03\n" +
 	"\fConversation\x12\x0e\n" +
 	"\x02id\x18\x01 \x01(\tR\x02id\x120\n" +
 	"\x14last_message_content\x18\x02 \x01(\tR\x12lastMessageContent\x12&\n" +
 	"\x0flast_message_at\x18\x03 \x01(\tR\rlastMessageAt\x12!\n" +
-	"\funread_count\x18\x04 \x01(\x05R\vunreadCount\"<\n" +
+	"\funread_count\x18\x04 \x01(\x05R\vunreadCount\x12\x1f\n" +
+	"\varchived_at\x18\x05 \x01(\tR\n" +
+	"archivedAt\x12.\n" +
+	"\x13message_ttl_seconds\x18\x06 \x01(\x03R\x11messageTtlSeconds\x12-\n" +
+	"\x04type\x18\a \x01(\x0e2\x19.chat.v1.ConversationTypeR\x04type\x12\x12\n" +
+	"\x04name\x18\b \x01(\tR\x04name\x12\x16\n" +
+	"\x06pinned\x18\t \x01(\bR\x06pinned\x12\x1b\n" +
+	"\tpinned_at\x18\n" +
+	" \x01(\tR\bpinnedAt\x12I\n" +
+	"\x12notification_level\x18\v \x01(\x0e2\x1a.chat.v1.NotificationLevelR\x11notificationLevel\"\x87\x01\n" +
+	"\x19CreateConversationRequest\x12-\n" +
+	"\x04type\x18\x01 \x01(\x0e2\x19.chat.v1.ConversationTypeR\x04type\x12'\n" +
+	"\x0fparticipant_ids\x18\x02 \x03(\tR\x0eparticipantIds\x12\x12\n" +
+	"\x04name\x18\x03 \x01(\tR\x04name\"\x80\x01\n" +
+	"\x1aCreateConversationResponse\x12'\n" +
+	"\x0fconversation_id\x18\x01 \x01(\tR\x0econversationId\x129\n" +
+	"\fconversation\x18\x02 \x01(\v2\x15.chat.v1.ConversationR\fconversation\"e\n" +
 	"\x11MarkAsReadRequest\x12'\n" +
-	"\x0fconversation_id\x18\x01 \x01(\tR\x0econversationId\".\n" +
+	"\x0fconversation_id\x18\x01 \x01(\tR\x0econversationId\x12'\n" +
+	"\x10up_to_message_id\x18\x02 \x01(\tR\rupToMessageId\".\n" +
 	"\x12MarkAsReadResponse\x12\x18\n" +
-	"\asuccess\x18\x01 \x01(\bR\asuccess\"\x1d\n" +
+	"\asuccess\x18\x01 \x01(\bR\asuccess\"A\n" +
+	"\x16MarkAsDeliveredRequest\x12'\n" +
+	"\x0fconversation_id\x18\x01 \x01(\tR\x0econversationId\"3\n" +
+	"\x17MarkAsDeliveredResponse\x12\x18\n" +
+	"\asuccess\x18\x01 \x01(\bR\asuccess\"A\n" +
+	"\x16GetParticipantsRequest\x12'\n" +
+	"\x0fconversation_id\x18\x01 \x01(\tR\x0econversationId\"S\n" +
+	"\x17GetParticipantsResponse\x128\n" +
+	"\fparticipants\x18\x01 \x03(\v2\x14.chat.v1.ParticipantR\fparticipants\"C\n" +
+	"\vParticipant\x12\x17\n" +
+	"\auser_id\x18\x01 \x01(\tR\x06userId\x12\x1b\n" +
+	"\tjoined_at\x18\x02 \x01(\tR\bjoinedAt\"D\n" +
+	"\x19DeleteConversationRequest\x12'\n" +
+	"\x0fconversation_id\x18\x01 \x01(\tR\x0econversationId\"6\n" +
+	"\x1aDeleteConversationResponse\x12\x18\n" +
+	"\asuccess\x18\x01 \x01(\bR\asuccess\"E\n" +
+	"\x1aArchiveConversationRequest\x12'\n" +
+	"\x0fconversation_id\x18\x01 \x01(\tR\x0econversationId\"7\n" +
+	"\x1bArchiveConversationResponse\x12\x18\n" +
+	"\asuccess\x18\x01 \x01(\bR\asuccess\"G\n" +
+	"\x1cUnarchiveConversationRequest\x12'\n" +
+	"\x0fconversation_id\x18\x01 \x01(\tR\x0econversationId\"9\n" +
+	"\x1dUnarchiveConversationResponse\x12\x18\n" +
+	"\asuccess\x18\x01 \x01(\bR\asuccess\"A\n" +
+	"\x16PinConversationRequest\x12'\n" +
+	"\x0fconversation_id\x18\x01 \x01(\tR\x0econversationId\"P\n" +
+	"\x17PinConversationResponse\x12\x18\n" +
+	"\asuccess\x18\x01 \x01(\bR\asuccess\x12\x1b\n" +
+	"\tpinned_at\x18\x02 \x01(\tR\bpinnedAt\"C\n" +
+	"\x18UnpinConversationRequest\x12'\n" +
+	"\x0fconversation_id\x18\x01 \x01(\tR\x0econversationId\"5\n" +
+	"\x19UnpinConversationResponse\x12\x18\n" +
+	"\asuccess\x18\x01 \x01(\bR\asuccess\"\x91\x01\n" +
+	"\x1bSetNotificationLevelRequest\x12'\n" +
+	"\x0fconversation_id\x18\x01 \x01(\tR\x0econversationId\x12I\n" +
+	"\x12notification_level\x18\x02 \x01(\x0e2\x1a.chat.v1.NotificationLevelR\x11notificationLevel\"\x83\x01\n" +
+	"\x1cSetNotificationLevelResponse\x12\x18\n" +
+	"\asuccess\x18\x01 \x01(\bR\asuccess\x12I\n" +
+	"\x12notification_level\x18\x02 \x01(\x0e2\x1a.chat.v1.NotificationLevelR\x11notificationLevel\"z\n" +
+	"\x1fSetConversationRetentionRequest\x12'\n" +
+	"\x0fconversation_id\x18\x01 \x01(\tR\x0econversationId\x12.\n" +
+	"\x13message_ttl_seconds\x18\x02 \x01(\x03R\x11messageTtlSeconds\"l\n" +
+	" SetConversationRetentionResponse\x12\x18\n" +
+	"\asuccess\x18\x01 \x01(\bR\asuccess\x12.\n" +
+	"\x13message_ttl_seconds\x18\x02 \x01(\x03R\x11messageTtlSeconds\"I\n" +
+	"\x12AddReactionRequest\x12\x1d\n" +
+	"\n" +
+	"message_id\x18\x01 \x01(\tR\tmessageId\x12\x14\n" +
+	"\x05emoji\x18\x02 \x01(\tR\x05emoji\"`\n" +
+	"\x13AddReactionResponse\x12\x1d\n" +
+	"\n" +
+	"message_id\x18\x01 \x01(\tR\tmessageId\x12\x14\n" +
+	"\x05emoji\x18\x02 \x01(\tR\x05emoji\x12\x14\n" +
+	"\x05count\x18\x03 \x01(\x05R\x05count\"L\n" +
+	"\x15RemoveReactionRequest\x12\x1d\n" +
+	"\n" +
+	"message_id\x18\x01 \x01(\tR\tmessageId\x12\x14\n" +
+	"\x05emoji\x18\x02 \x01(\tR\x05emoji\"c\n" +
+	"\x16RemoveReactionResponse\x12\x1d\n" +
+	"\n" +
+	"message_id\x18\x01 \x01(\tR\tmessageId\x12\x14\n" +
+	"\x05emoji\x18\x02 \x01(\tR\x05emoji\x12\x14\n" +
+	"\x05count\x18\x03 \x01(\x05R\x05count\"0\n" +
+	"\x1cInspectIdempotencyKeyRequest\x12\x10\n" +
+	"\x03key\x18\x01 \x01(\tR\x03key\"j\n" +
+	"\x1dInspectIdempotencyKeyResponse\x12\x10\n" +
+	"\x03key\x18\x01 \x01(\tR\x03key\x12\x16\n" +
+	"\x06exists\x18\x02 \x01(\bR\x06exists\x12\x1f\n" +
+	"\vttl_seconds\x18\x03 \x01(\x03R\n" +
+	"ttlSeconds\".\n" +
+	"\x1aClearIdempotencyKeyRequest\x12\x10\n" +
+	"\x03key\x18\x01 \x01(\tR\x03key\"I\n" +
+	"\x1bClearIdempotencyKeyResponse\x12\x10\n" +
+	"\x03key\x18\x01 \x01(\tR\x03key\x12\x18\n" +
+	"\aexisted\x18\x02 \x01(\bR\aexisted\"\x90\x01\n" +
+	"\x19ExportConversationRequest\x12'\n" +
+	"\x0fconversation_id\x18\x01 \x01(\tR\x0econversationId\x12-\n" +
+	"\x06format\x18\x02 \x01(\x0e2\x15.chat.v1.ExportFormatR\x06format\x12\x1b\n" +
+	"\tpage_size\x18\x03 \x01(\x05R\bpageSize\"\x84\x01\n" +
+	"\x17ExportConversationChunk\x120\n" +
+	"\bmessages\x18\x01 \x03(\v2\x14.chat.v1.ChatMessageR\bmessages\x12\x12\n" +
+	"\x04data\x18\x02 \x01(\fR\x04data\x12#\n" +
+	"\rmessage_count\x18\x03 \x01(\x05R\fmessageCount\"\x15\n" +
+	"\x13StreamEventsRequest\"\xa9\x01\n" +
+	"\tChatEvent\x12\x19\n" +
+	"\bevent_id\x18\x01 \x01(\tR\aeventId\x12%\n" +
+	"\x0eaggregate_type\x18\x02 \x01(\tR\raggregateType\x12!\n" +
+	"\faggregate_id\x18\x03 \x01(\tR\vaggregateId\x12\x18\n" +
+	"\apayload\x18\x04 \x01(\tR\apayload\x12\x1d\n" +
+	"\n" +
+	"created_at\x18\x05 \x01(\x03R\tcreatedAt\"\x1d\n" +
 	"\x1bGetUploadCredentialsRequest\"\xaa\x01\n" +
 	"\x1cGetUploadCredentialsResponse\x12\x1c\n" +
 	"\tsignature\x18\x01 \x01(\tR\tsignature\x12\x1c\n" +
@@ -853,19 +3409,57 @@ const file_chat_v1_chat_proto_rawDesc = "" +
 	"\aapi_key\x18\x03 \x01(\tR\x06apiKey\x12\x1d\n" +
 	"\n" +
 	"cloud_name\x18\x04 \x01(\tR\tcloudName\x12\x16\n" +
-	"\x06folder\x18\x05 \x01(\tR\x06folder*\x89\x01\n" +
+	"\x06folder\x18\x05 \x01(\tR\x06folder*\xb9\x01\n" +
 	"\vMessageType\x12\x1c\n" +
 	"\x18MESSAGE_TYPE_UNSPECIFIED\x10\x00\x12\x15\n" +
 	"\x11MESSAGE_TYPE_TEXT\x10\x01\x12\x16\n" +
 	"\x12MESSAGE_TYPE_IMAGE\x10\x02\x12\x16\n" +
 	"\x12MESSAGE_TYPE_VIDEO\x10\x03\x12\x15\n" +
-	"\x11MESSAGE_TYPE_FILE\x10\x042\xe6\x04\n" +
+	"\x11MESSAGE_TYPE_FILE\x10\x04\x12\x17\n" +
+	"\x13MESSAGE_TYPE_SYSTEM\x10\x05\x12\x15\n" +
+	"\x11MESSAGE_TYPE_CALL\x10\x06*\x7f\n" +
+	"\rMessageStatus\x12\x1e\n" +
+	"\x1aMESSAGE_STATUS_UNSPECIFIED\x10\x00\x12\x17\n" +
+	"\x13MESSAGE_STATUS_SENT\x10\x01\x12\x1c\n" +
+	"\x18MESSAGE_STATUS_DELIVERED\x10\x02\x12\x17\n" +
+	"\x13MESSAGE_STATUS_READ\x10\x03*p\n" +
+	"\x10ConversationType\x12!\n" +
+	"\x1dCONVERSATION_TYPE_UNSPECIFIED\x10\x00\x12\x1c\n" +
+	"\x18CONVERSATION_TYPE_DIRECT\x10\x01\x12\x1b\n" +
+	"\x17CONVERSATION_TYPE_GROUP\x10\x02*\x91\x01\n" +
+	"\x11NotificationLevel\x12\"\n" +
+	"\x1eNOTIFICATION_LEVEL_UNSPECIFIED\x10\x00\x12\x1a\n" +
+	"\x16NOTIFICATION_LEVEL_ALL\x10\x01\x12\x1f\n" +
+	"\x1bNOTIFICATION_LEVEL_MENTIONS\x10\x02\x12\x1b\n" +
+	"\x17NOTIFICATION_LEVEL_NONE\x10\x03*b\n" +
+	"\fExportFormat\x12\x1d\n" +
+	"\x19EXPORT_FORMAT_UNSPECIFIED\x10\x00\x12\x1a\n" +
+	"\x16EXPORT_FORMAT_MESSAGES\x10\x01\x12\x17\n" +
+	"\x13EXPORT_FORMAT_JSONL\x10\x022\xfb\x17\n" +
 	"\vChatService\x12a\n" +
 	"\vSendMessage\x12\x1b.chat.v1.SendMessageRequest\x1a\x1c.chat.v1.SendMessageResponse\"\x17\x82\xd3\xe4\x93\x02\x11:\x01*\"\f/v1/messages\x12~\n" +
-	"\vGetMessages\x12\x1b.chat.v1.GetMessagesRequest\x1a\x1c.chat.v1.GetMessagesResponse\"4\x82\xd3\xe4\x93\x02.\x12,/v1/conversations/{conversation_id}/messages\x12r\n" +
-	"\x10GetConversations\x12 .chat.v1.GetConversationsRequest\x1a!.chat.v1.GetConversationsResponse\"\x19\x82\xd3\xe4\x93\x02\x13\x12\x11/v1/conversations\x12z\n" +
+	"\vGetMessages\x12\x1b.chat.v1.GetMessagesRequest\x1a\x1c.chat.v1.GetMessagesResponse\"4\x82\xd3\xe4\x93\x02.\x12,/v1/conversations/{conversation_id}/messages\x12^\n" +
+	"\vGetMentions\x12\x1b.chat.v1.GetMentionsRequest\x1a\x1c.chat.v1.GetMentionsResponse\"\x14\x82\xd3\xe4\x93\x02\x0e\x12\f/v1/mentions\x12r\n" +
+	"\x10GetConversations\x12 .chat.v1.GetConversationsRequest\x1a!.chat.v1.GetConversationsResponse\"\x19\x82\xd3\xe4\x93\x02\x13\x12\x11/v1/conversations\x12\x8d\x01\n" +
+	"\x15GetConversationsByIds\x12%.chat.v1.GetConversationsByIdsRequest\x1a&.chat.v1.GetConversationsByIdsResponse\"%\x82\xd3\xe4\x93\x02\x1f:\x01*\"\x1a/v1/conversations:batchGet\x12z\n" +
 	"\n" +
-	"MarkAsRead\x12\x1a.chat.v1.MarkAsReadRequest\x1a\x1b.chat.v1.MarkAsReadResponse\"3\x82\xd3\xe4\x93\x02-:\x01*\"(/v1/conversations/{conversation_id}/read\x12\x83\x01\n" +
+	"MarkAsRead\x12\x1a.chat.v1.MarkAsReadRequest\x1a\x1b.chat.v1.MarkAsReadResponse\"3\x82\xd3\xe4\x93\x02-:\x01*\"(/v1/conversations/{conversation_id}/read\x12\x8e\x01\n" +
+	"\x0fMarkAsDelivered\x12\x1f.chat.v1.MarkAsDeliveredRequest\x1a .chat.v1.MarkAsDeliveredResponse\"8\x82\xd3\xe4\x93\x022:\x01*\"-/v1/conversations/{conversation_id}/delivered\x12\x8e\x01\n" +
+	"\x0fGetParticipants\x12\x1f.chat.v1.GetParticipantsRequest\x1a .chat.v1.GetParticipantsResponse\"8\x82\xd3\xe4\x93\x022\x120/v1/conversations/{conversation_id}/participants\x12{\n" +
+	"\x12CreateConversation\x12\".chat.v1.CreateConversationRequest\x1a#.chat.v1.CreateConversationResponse\"\x1c\x82\xd3\xe4\x93\x02\x16:\x01*\"\x11/v1/conversations\x12\x8a\x01\n" +
+	"\x12DeleteConversation\x12\".chat.v1.DeleteConversationRequest\x1a#.chat.v1.DeleteConversationResponse\"+\x82\xd3\xe4\x93\x02%*#/v1/conversations/{conversation_id}\x12\x98\x01\n" +
+	"\x13ArchiveConversation\x12#.chat.v1.ArchiveConversationRequest\x1a$.chat.v1.ArchiveConversationResponse\"6\x82\xd3\xe4\x93\x020:\x01*\"+/v1/conversations/{conversation_id}/archive\x12\xa0\x01\n" +
+	"\x15UnarchiveConversation\x12%.chat.v1.UnarchiveConversationRequest\x1a&.chat.v1.UnarchiveConversationResponse\"8\x82\xd3\xe4\x93\x022:\x01*\"-/v1/conversations/{conversation_id}/unarchive\x12\x88\x01\n" +
+	"\x0fPinConversation\x12\x1f.chat.v1.PinConversationRequest\x1a .chat.v1.PinConversationResponse\"2\x82\xd3\xe4\x93\x02,:\x01*\"'/v1/conversations/{conversation_id}/pin\x12\x90\x01\n" +
+	"\x11UnpinConversation\x12!.chat.v1.UnpinConversationRequest\x1a\".chat.v1.UnpinConversationResponse\"4\x82\xd3\xe4\x93\x02.:\x01*\")/v1/conversations/{conversation_id}/unpin\x12\xa1\x01\n" +
+	"\x14SetNotificationLevel\x12$.chat.v1.SetNotificationLevelRequest\x1a%.chat.v1.SetNotificationLevelResponse\"<\x82\xd3\xe4\x93\x026:\x01*\"1/v1/conversations/{conversation_id}/notifications\x12\xa9\x01\n" +
+	"\x18SetConversationRetention\x12(.chat.v1.SetConversationRetentionRequest\x1a).chat.v1.SetConversationRetentionResponse\"8\x82\xd3\xe4\x93\x022:\x01*\"-/v1/conversations/{conversation_id}/retention\x12x\n" +
+	"\vAddReaction\x12\x1b.chat.v1.AddReactionRequest\x1a\x1c.chat.v1.AddReactionResponse\".\x82\xd3\xe4\x93\x02(:\x01*\"#/v1/messages/{message_id}/reactions\x12\x86\x01\n" +
+	"\x0eRemoveReaction\x12\x1e.chat.v1.RemoveReactionRequest\x1a\x1f.chat.v1.RemoveReactionResponse\"3\x82\xd3\xe4\x93\x02-*+/v1/messages/{message_id}/reactions/{emoji}\x12\x95\x01\n" +
+	"\x15InspectIdempotencyKey\x12%.chat.v1.InspectIdempotencyKeyRequest\x1a&.chat.v1.InspectIdempotencyKeyResponse\"-\x82\xd3\xe4\x93\x02':\x01*\"\"/v1/admin/idempotency-keys:inspect\x12\x8d\x01\n" +
+	"\x13ClearIdempotencyKey\x12#.chat.v1.ClearIdempotencyKeyRequest\x1a$.chat.v1.ClearIdempotencyKeyResponse\"+\x82\xd3\xe4\x93\x02%:\x01*\" /v1/admin/idempotency-keys:clear\x12\\\n" +
+	"\x12ExportConversation\x12\".chat.v1.ExportConversationRequest\x1a .chat.v1.ExportConversationChunk0\x01\x12B\n" +
+	"\fStreamEvents\x12\x1c.chat.v1.StreamEventsRequest\x1a\x12.chat.v1.ChatEvent0\x01\x12\x83\x01\n" +
 	"\x14GetUploadCredentials\x12$.chat.v1.GetUploadCredentialsRequest\x1a%.chat.v1.GetUploadCredentialsResponse\"\x1e\x82\xd3\xe4\x93\x02\x18\x12\x16/v1/upload-credentialsBv\n" +
 	"\vcom.chat.v1B\tChatProtoP\x01Z\x1fchat-service/api/chat/v1;chatv1\xa2\x02\x03CXX\xaa\x02\aChat.V1\xca\x02\aChat\\V1\xe2\x02\x13Chat\\V1\\GPBMetadata\xea\x02\bChat::V1b\x06proto3"
 
@@ -881,43 +3475,138 @@ func file_chat_v1_chat_proto_rawDescGZIP() []byte {
 	return file_chat_v1_chat_proto_rawDescData
 }
 
-var file_chat_v1_chat_proto_enumTypes = make([]protoimpl.EnumInfo, 1)
-var file_chat_v1_chat_proto_msgTypes = make([]protoimpl.MessageInfo, 12)
+var file_chat_v1_chat_proto_enumTypes = make([]protoimpl.EnumInfo, 5)
+var file_chat_v1_chat_proto_msgTypes = make([]protoimpl.MessageInfo, 51)
 var file_chat_v1_chat_proto_goTypes = []any{
-	(MessageType)(0),                     // 0: chat.v1.MessageType
-	(*SendMessageRequest)(nil),           // 1: chat.v1.SendMessageRequest
-	(*SendMessageResponse)(nil),          // 2: chat.v1.SendMessageResponse
-	(*GetMessagesRequest)(nil),           // 3: chat.v1.GetMessagesRequest
-	(*GetMessagesResponse)(nil),          // 4: chat.v1.GetMessagesResponse
-	(*ChatMessage)(nil),                  // 5: chat.v1.ChatMessage
-	(*GetConversationsRequest)(nil),      // 6: chat.v1.GetConversationsRequest
-	(*GetConversationsResponse)(nil),     // 7: chat.v1.GetConversationsResponse
-	(*Conversation)(nil),                 // 8: chat.v1.Conversation
-	(*MarkAsReadRequest)(nil),            // 9: chat.v1.MarkAsReadRequest
-	(*MarkAsReadResponse)(nil),           // 10: chat.v1.MarkAsReadResponse
-	(*GetUploadCredentialsRequest)(nil),  // 11: chat.v1.GetUploadCredentialsRequest
-	(*GetUploadCredentialsResponse)(nil), // 12: chat.v1.GetUploadCredentialsResponse
+	(MessageType)(0),                         // 0: chat.v1.MessageType
+	(MessageStatus)(0),                       // 1: chat.v1.MessageStatus
+	(ConversationType)(0),                    // 2: chat.v1.ConversationType
+	(NotificationLevel)(0),                   // 3: chat.v1.NotificationLevel
+	(ExportFormat)(0),                        // 4: chat.v1.ExportFormat
+	(*SendMessageRequest)(nil),               // 5: chat.v1.SendMessageRequest
+	(*Attachment)(nil),                       // 6: chat.v1.Attachment
+	(*SendMessageResponse)(nil),              // 7: chat.v1.SendMessageResponse
+	(*GetMessagesRequest)(nil),               // 8: chat.v1.GetMessagesRequest
+	(*GetMessagesResponse)(nil),              // 9: chat.v1.GetMessagesResponse
+	(*GetMentionsRequest)(nil),               // 10: chat.v1.GetMentionsRequest
+	(*GetMentionsResponse)(nil),              // 11: chat.v1.GetMentionsResponse
+	(*ChatMessage)(nil),                      // 12: chat.v1.ChatMessage
+	(*ReactionSummary)(nil),                  // 13: chat.v1.ReactionSummary
+	(*GetConversationsRequest)(nil),          // 14: chat.v1.GetConversationsRequest
+	(*GetConversationsResponse)(nil),         // 15: chat.v1.GetConversationsResponse
+	(*GetConversationsByIdsRequest)(nil),     // 16: chat.v1.GetConversationsByIdsRequest
+	(*GetConversationsByIdsResponse)(nil),    // 17: chat.v1.GetConversationsByIdsResponse
+	(*Conversation)(nil),                     // 18: chat.v1.Conversation
+	(*CreateConversationRequest)(nil),        // 19: chat.v1.CreateConversationRequest
+	(*CreateConversationResponse)(nil),       // 20: chat.v1.CreateConversationResponse
+	(*MarkAsReadRequest)(nil),                // 21: chat.v1.MarkAsReadRequest
+	(*MarkAsReadResponse)(nil),               // 22: chat.v1.MarkAsReadResponse
+	(*MarkAsDeliveredRequest)(nil),           // 23: chat.v1.MarkAsDeliveredRequest
+	(*MarkAsDeliveredResponse)(nil),          // 24: chat.v1.MarkAsDeliveredResponse
+	(*GetParticipantsRequest)(nil),           // 25: chat.v1.GetParticipantsRequest
+	(*GetParticipantsResponse)(nil),          // 26: chat.v1.GetParticipantsResponse
+	(*Participant)(nil),                      // 27: chat.v1.Participant
+	(*DeleteConversationRequest)(nil),        // 28: chat.v1.DeleteConversationRequest
+	(*DeleteConversationResponse)(nil),       // 29: chat.v1.DeleteConversationResponse
+	(*ArchiveConversationRequest)(nil),       // 30: chat.v1.ArchiveConversationRequest
+	(*ArchiveConversationResponse)(nil),      // 31: chat.v1.ArchiveConversationResponse
+	(*UnarchiveConversationRequest)(nil),     // 32: chat.v1.UnarchiveConversationRequest
+	(*UnarchiveConversationResponse)(nil),    // 33: chat.v1.UnarchiveConversationResponse
+	(*PinConversationRequest)(nil),           // 34: chat.v1.PinConversationRequest
+	(*PinConversationResponse)(nil),          // 35: chat.v1.PinConversationResponse
+	(*UnpinConversationRequest)(nil),         // 36: chat.v1.UnpinConversationRequest
+	(*UnpinConversationResponse)(nil),        // 37: chat.v1.UnpinConversationResponse
+	(*SetNotificationLevelRequest)(nil),      // 38: chat.v1.SetNotificationLevelRequest
+	(*SetNotificationLevelResponse)(nil),     // 39: chat.v1.SetNotificationLevelResponse
+	(*SetConversationRetentionRequest)(nil),  // 40: chat.v1.SetConversationRetentionRequest
+	(*SetConversationRetentionResponse)(nil), // 41: chat.v1.SetConversationRetentionResponse
+	(*AddReactionRequest)(nil),               // 42: chat.v1.AddReactionRequest
+	(*AddReactionResponse)(nil),              // 43: chat.v1.AddReactionResponse
+	(*RemoveReactionRequest)(nil),            // 44: chat.v1.RemoveReactionRequest
+	(*RemoveReactionResponse)(nil),           // 45: chat.v1.RemoveReactionResponse
+	(*InspectIdempotencyKeyRequest)(nil),     // 46: chat.v1.InspectIdempotencyKeyRequest
+	(*InspectIdempotencyKeyResponse)(nil),    // 47: chat.v1.InspectIdempotencyKeyResponse
+	(*ClearIdempotencyKeyRequest)(nil),       // 48: chat.v1.ClearIdempotencyKeyRequest
+	(*ClearIdempotencyKeyResponse)(nil),      // 49: chat.v1.ClearIdempotencyKeyResponse
+	(*ExportConversationRequest)(nil),        // 50: chat.v1.ExportConversationRequest
+	(*ExportConversationChunk)(nil),          // 51: chat.v1.ExportConversationChunk
+	(*StreamEventsRequest)(nil),              // 52: chat.v1.StreamEventsRequest
+	(*ChatEvent)(nil),                        // 53: chat.v1.ChatEvent
+	(*GetUploadCredentialsRequest)(nil),      // 54: chat.v1.GetUploadCredentialsRequest
+	(*GetUploadCredentialsResponse)(nil),     // 55: chat.v1.GetUploadCredentialsResponse
 }
 var file_chat_v1_chat_proto_depIdxs = []int32{
 	0,  // 0: chat.v1.SendMessageRequest.type:type_name -> chat.v1.MessageType
-	5,  // 1: chat.v1.GetMessagesResponse.messages:type_name -> chat.v1.ChatMessage
-	0,  // 2: chat.v1.ChatMessage.type:type_name -> chat.v1.MessageType
-	8,  // 3: chat.v1.GetConversationsResponse.conversations:type_name -> chat.v1.Conversation
-	1,  // 4: chat.v1.ChatService.SendMessage:input_type -> chat.v1.SendMessageRequest
-	3,  // 5: chat.v1.ChatService.GetMessages:input_type -> chat.v1.GetMessagesRequest
-	6,  // 6: chat.v1.ChatService.GetConversations:input_type -> chat.v1.GetConversationsRequest
-	9,  // 7: chat.v1.ChatService.MarkAsRead:input_type -> chat.v1.MarkAsReadRequest
-	11, // 8: chat.v1.ChatService.GetUploadCredentials:input_type -> chat.v1.GetUploadCredentialsRequest
-	2,  // 9: chat.v1.ChatService.SendMessage:output_type -> chat.v1.SendMessageResponse
-	4,  // 10: chat.v1.ChatService.GetMessages:output_type -> chat.v1.GetMessagesResponse
-	7,  // 11: chat.v1.ChatService.GetConversations:output_type -> chat.v1.GetConversationsResponse
-	10, // 12: chat.v1.ChatService.MarkAsRead:output_type -> chat.v1.MarkAsReadResponse
-	12, // 13: chat.v1.ChatService.GetUploadCredentials:output_type -> chat.v1.GetUploadCredentialsResponse
-	9,  // [9:14] is the sub-list for method output_type
-	4,  // [4:9] is the sub-list for method input_type
-	4,  // [4:4] is the sub-list for extension type_name
-	4,  // [4:4] is the sub-list for extension extendee
-	0,  // [0:4] is the sub-list for field type_name
+	6,  // 1: chat.v1.SendMessageRequest.attachments:type_name -> chat.v1.Attachment
+	18, // 2: chat.v1.SendMessageResponse.conversation:type_name -> chat.v1.Conversation
+	12, // 3: chat.v1.GetMessagesResponse.messages:type_name -> chat.v1.ChatMessage
+	12, // 4: chat.v1.GetMentionsResponse.messages:type_name -> chat.v1.ChatMessage
+	0,  // 5: chat.v1.ChatMessage.type:type_name -> chat.v1.MessageType
+	6,  // 6: chat.v1.ChatMessage.attachments:type_name -> chat.v1.Attachment
+	1,  // 7: chat.v1.ChatMessage.status:type_name -> chat.v1.MessageStatus
+	13, // 8: chat.v1.ChatMessage.reactions:type_name -> chat.v1.ReactionSummary
+	18, // 9: chat.v1.GetConversationsResponse.conversations:type_name -> chat.v1.Conversation
+	18, // 10: chat.v1.GetConversationsByIdsResponse.conversations:type_name -> chat.v1.Conversation
+	2,  // 11: chat.v1.Conversation.type:type_name -> chat.v1.ConversationType
+	3,  // 12: chat.v1.Conversation.notification_level:type_name -> chat.v1.NotificationLevel
+	2,  // 13: chat.v1.CreateConversationRequest.type:type_name -> chat.v1.ConversationType
+	18, // 14: chat.v1.CreateConversationResponse.conversation:type_name -> chat.v1.Conversation
+	27, // 15: chat.v1.GetParticipantsResponse.participants:type_name -> chat.v1.Participant
+	3,  // 16: chat.v1.SetNotificationLevelRequest.notification_level:type_name -> chat.v1.NotificationLevel
+	3,  // 17: chat.v1.SetNotificationLevelResponse.notification_level:type_name -> chat.v1.NotificationLevel
+	4,  // 18: chat.v1.ExportConversationRequest.format:type_name -> chat.v1.ExportFormat
+	12, // 19: chat.v1.ExportConversationChunk.messages:type_name -> chat.v1.ChatMessage
+	5,  // 20: chat.v1.ChatService.SendMessage:input_type -> chat.v1.SendMessageRequest
+	8,  // 21: chat.v1.ChatService.GetMessages:input_type -> chat.v1.GetMessagesRequest
+	10, // 22: chat.v1.ChatService.GetMentions:input_type -> chat.v1.GetMentionsRequest
+	14, // 23: chat.v1.ChatService.GetConversations:input_type -> chat.v1.GetConversationsRequest
+	16, // 24: chat.v1.ChatService.GetConversationsByIds:input_type -> chat.v1.GetConversationsByIdsRequest
+	21, // 25: chat.v1.ChatService.MarkAsRead:input_type -> chat.v1.MarkAsReadRequest
+	23, // 26: chat.v1.ChatService.MarkAsDelivered:input_type -> chat.v1.MarkAsDeliveredRequest
+	25, // 27: chat.v1.ChatService.GetParticipants:input_type -> chat.v1.GetParticipantsRequest
+	19, // 28: chat.v1.ChatService.CreateConversation:input_type -> chat.v1.CreateConversationRequest
+	28, // 29: chat.v1.ChatService.DeleteConversation:input_type -> chat.v1.DeleteConversationRequest
+	30, // 30: chat.v1.ChatService.ArchiveConversation:input_type -> chat.v1.ArchiveConversationRequest
+	32, // 31: chat.v1.ChatService.UnarchiveConversation:input_type -> chat.v1.UnarchiveConversationRequest
+	34, // 32: chat.v1.ChatService.PinConversation:input_type -> chat.v1.PinConversationRequest
+	36, // 33: chat.v1.ChatService.UnpinConversation:input_type -> chat.v1.UnpinConversationRequest
+	38, // 34: chat.v1.ChatService.SetNotificationLevel:input_type -> chat.v1.SetNotificationLevelRequest
+	40, // 35: chat.v1.ChatService.SetConversationRetention:input_type -> chat.v1.SetConversationRetentionRequest
+	42, // 36: chat.v1.ChatService.AddReaction:input_type -> chat.v1.AddReactionRequest
+	44, // 37: chat.v1.ChatService.RemoveReaction:input_type -> chat.v1.RemoveReactionRequest
+	46, // 38: chat.v1.ChatService.InspectIdempotencyKey:input_type -> chat.v1.InspectIdempotencyKeyRequest
+	48, // 39: chat.v1.ChatService.ClearIdempotencyKey:input_type -> chat.v1.ClearIdempotencyKeyRequest
+	50, // 40: chat.v1.ChatService.ExportConversation:input_type -> chat.v1.ExportConversationRequest
+	52, // 41: chat.v1.ChatService.StreamEvents:input_type -> chat.v1.StreamEventsRequest
+	54, // 42: chat.v1.ChatService.GetUploadCredentials:input_type -> chat.v1.GetUploadCredentialsRequest
+	7,  // 43: chat.v1.ChatService.SendMessage:output_type -> chat.v1.SendMessageResponse
+	9,  // 44: chat.v1.ChatService.GetMessages:output_type -> chat.v1.GetMessagesResponse
+	11, // 45: chat.v1.ChatService.GetMentions:output_type -> chat.v1.GetMentionsResponse
+	15, // 46: chat.v1.ChatService.GetConversations:output_type -> chat.v1.GetConversationsResponse
+	17, // 47: chat.v1.ChatService.GetConversationsByIds:output_type -> chat.v1.GetConversationsByIdsResponse
+	22, // 48: chat.v1.ChatService.MarkAsRead:output_type -> chat.v1.MarkAsReadResponse
+	24, // 49: chat.v1.ChatService.MarkAsDelivered:output_type -> chat.v1.MarkAsDeliveredResponse
+	26, // 50: chat.v1.ChatService.GetParticipants:output_type -> chat.v1.GetParticipantsResponse
+	20, // 51: chat.v1.ChatService.CreateConversation:output_type -> chat.v1.CreateConversationResponse
+	29, // 52: chat.v1.ChatService.DeleteConversation:output_type -> chat.v1.DeleteConversationResponse
+	31, // 53: chat.v1.ChatService.ArchiveConversation:output_type -> chat.v1.ArchiveConversationResponse
+	33, // 54: chat.v1.ChatService.UnarchiveConversation:output_type -> chat.v1.UnarchiveConversationResponse
+	35, // 55: chat.v1.ChatService.PinConversation:output_type -> chat.v1.PinConversationResponse
+	37, // 56: chat.v1.ChatService.UnpinConversation:output_type -> chat.v1.UnpinConversationResponse
+	39, // 57: chat.v1.ChatService.SetNotificationLevel:output_type -> chat.v1.SetNotificationLevelResponse
+	41, // 58: chat.v1.ChatService.SetConversationRetention:output_type -> chat.v1.SetConversationRetentionResponse
+	43, // 59: chat.v1.ChatService.AddReaction:output_type -> chat.v1.AddReactionResponse
+	45, // 60: chat.v1.ChatService.RemoveReaction:output_type -> chat.v1.RemoveReactionResponse
+	47, // 61: chat.v1.ChatService.InspectIdempotencyKey:output_type -> chat.v1.InspectIdempotencyKeyResponse
+	49, // 62: chat.v1.ChatService.ClearIdempotencyKey:output_type -> chat.v1.ClearIdempotencyKeyResponse
+	51, // 63: chat.v1.ChatService.ExportConversation:output_type -> chat.v1.ExportConversationChunk
+	53, // 64: chat.v1.ChatService.StreamEvents:output_type -> chat.v1.ChatEvent
+	55, // 65: chat.v1.ChatService.GetUploadCredentials:output_type -> chat.v1.GetUploadCredentialsResponse
+	43, // [43:66] is the sub-list for method output_type
+	20, // [20:43] is the sub-list for method input_type
+	20, // [20:20] is the sub-list for extension type_name
+	20, // [20:20] is the sub-list for extension extendee
+	0,  // [0:20] is the sub-list for field type_name
 }
 
 func init() { file_chat_v1_chat_proto_init() }
@@ -930,8 +3619,8 @@ func file_chat_v1_chat_proto_init() {
 		File: protoimpl.DescBuilder{
 			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
 			RawDescriptor: unsafe.Slice(unsafe.StringData(file_chat_v1_chat_proto_rawDesc), len(file_chat_v1_chat_proto_rawDesc)),
-			NumEnums:      1,
-			NumMessages:   12,
+			NumEnums:      5,
+			NumMessages:   51,
 			NumExtensions: 0,
 			NumServices:   1,
 		},
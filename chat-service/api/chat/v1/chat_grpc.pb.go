@@ -21,11 +21,29 @@ import (
 const _ = grpc.SupportPackageIsVersion9
 
 const (
-	ChatService_SendMessage_FullMethodName          = "/chat.v1.ChatService/SendMessage"
-	ChatService_GetMessages_FullMethodName          = "/chat.v1.ChatService/GetMessages"
-	ChatService_GetConversations_FullMethodName     = "/chat.v1.ChatService/GetConversations"
-	ChatService_MarkAsRead_FullMethodName           = "/chat.v1.ChatService/MarkAsRead"
-	ChatService_GetUploadCredentials_FullMethodName = "/chat.v1.ChatService/GetUploadCredentials"
+	ChatService_SendMessage_FullMethodName              = "/chat.v1.ChatService/SendMessage"
+	ChatService_GetMessages_FullMethodName              = "/chat.v1.ChatService/GetMessages"
+	ChatService_GetMentions_FullMethodName              = "/chat.v1.ChatService/GetMentions"
+	ChatService_GetConversations_FullMethodName         = "/chat.v1.ChatService/GetConversations"
+	ChatService_GetConversationsByIds_FullMethodName    = "/chat.v1.ChatService/GetConversationsByIds"
+	ChatService_MarkAsRead_FullMethodName               = "/chat.v1.ChatService/MarkAsRead"
+	ChatService_MarkAsDelivered_FullMethodName          = "/chat.v1.ChatService/MarkAsDelivered"
+	ChatService_GetParticipants_FullMethodName          = "/chat.v1.ChatService/GetParticipants"
+	ChatService_CreateConversation_FullMethodName       = "/chat.v1.ChatService/CreateConversation"
+	ChatService_DeleteConversation_FullMethodName       = "/chat.v1.ChatService/DeleteConversation"
+	ChatService_ArchiveConversation_FullMethodName      = "/chat.v1.ChatService/ArchiveConversation"
+	ChatService_UnarchiveConversation_FullMethodName    = "/chat.v1.ChatService/UnarchiveConversation"
+	ChatService_PinConversation_FullMethodName          = "/chat.v1.ChatService/PinConversation"
+	ChatService_UnpinConversation_FullMethodName        = "/chat.v1.ChatService/UnpinConversation"
+	ChatService_SetNotificationLevel_FullMethodName     = "/chat.v1.ChatService/SetNotificationLevel"
+	ChatService_SetConversationRetention_FullMethodName = "/chat.v1.ChatService/SetConversationRetention"
+	ChatService_AddReaction_FullMethodName              = "/chat.v1.ChatService/AddReaction"
+	ChatService_RemoveReaction_FullMethodName           = "/chat.v1.ChatService/RemoveReaction"
+	ChatService_InspectIdempotencyKey_FullMethodName    = "/chat.v1.ChatService/InspectIdempotencyKey"
+	ChatService_ClearIdempotencyKey_FullMethodName      = "/chat.v1.ChatService/ClearIdempotencyKey"
+	ChatService_ExportConversation_FullMethodName       = "/chat.v1.ChatService/ExportConversation"
+	ChatService_StreamEvents_FullMethodName             = "/chat.v1.ChatService/StreamEvents"
+	ChatService_GetUploadCredentials_FullMethodName     = "/chat.v1.ChatService/GetUploadCredentials"
 )
 
 // ChatServiceClient is the client API for ChatService service.
@@ -36,10 +54,46 @@ type ChatServiceClient interface {
 	SendMessage(ctx context.Context, in *SendMessageRequest, opts ...grpc.CallOption) (*SendMessageResponse, error)
 	// Lấy danh sách tin nhắn theo conversation với pagination
 	GetMessages(ctx context.Context, in *GetMessagesRequest, opts ...grpc.CallOption) (*GetMessagesResponse, error)
+	// Hộp thư nhắc tên: các tin nhắn gần đây nhắc đến user hiện tại (@user_id hoặc @username), mới nhất trước
+	GetMentions(ctx context.Context, in *GetMentionsRequest, opts ...grpc.CallOption) (*GetMentionsResponse, error)
 	// Lấy danh sách conversation của user
 	GetConversations(ctx context.Context, in *GetConversationsRequest, opts ...grpc.CallOption) (*GetConversationsResponse, error)
+	// Lấy metadata của một tập conversation theo id (đồng bộ danh sách đã cache ở client)
+	GetConversationsByIds(ctx context.Context, in *GetConversationsByIdsRequest, opts ...grpc.CallOption) (*GetConversationsByIdsResponse, error)
 	// Đánh dấu tin nhắn đã đọc
 	MarkAsRead(ctx context.Context, in *MarkAsReadRequest, opts ...grpc.CallOption) (*MarkAsReadResponse, error)
+	// Xác nhận tin nhắn đã được giao tới thiết bị của user hiện tại
+	MarkAsDelivered(ctx context.Context, in *MarkAsDeliveredRequest, opts ...grpc.CallOption) (*MarkAsDeliveredResponse, error)
+	// Lấy danh sách thành viên của conversation (chỉ thành viên mới được xem)
+	GetParticipants(ctx context.Context, in *GetParticipantsRequest, opts ...grpc.CallOption) (*GetParticipantsResponse, error)
+	// Tạo conversation với loại (direct/group) và danh sách thành viên cho trước; người gọi luôn là thành viên
+	CreateConversation(ctx context.Context, in *CreateConversationRequest, opts ...grpc.CallOption) (*CreateConversationResponse, error)
+	// Ẩn conversation khỏi danh sách của user hiện tại (không ảnh hưởng thành viên khác)
+	DeleteConversation(ctx context.Context, in *DeleteConversationRequest, opts ...grpc.CallOption) (*DeleteConversationResponse, error)
+	// Lưu trữ conversation: chuyển sang danh sách archived của user hiện tại, vẫn nhận tin nhắn
+	ArchiveConversation(ctx context.Context, in *ArchiveConversationRequest, opts ...grpc.CallOption) (*ArchiveConversationResponse, error)
+	// Khôi phục conversation đã lưu trữ về danh sách chính
+	UnarchiveConversation(ctx context.Context, in *UnarchiveConversationRequest, opts ...grpc.CallOption) (*UnarchiveConversationResponse, error)
+	// Ghim conversation lên đầu danh sách của user hiện tại (không ảnh hưởng thành viên khác)
+	PinConversation(ctx context.Context, in *PinConversationRequest, opts ...grpc.CallOption) (*PinConversationResponse, error)
+	// Bỏ ghim conversation, trả về vị trí theo last_message_at
+	UnpinConversation(ctx context.Context, in *UnpinConversationRequest, opts ...grpc.CallOption) (*UnpinConversationResponse, error)
+	// Đặt mức thông báo của user hiện tại cho conversation (tất cả, chỉ khi được nhắc tên, tắt thông báo)
+	SetNotificationLevel(ctx context.Context, in *SetNotificationLevelRequest, opts ...grpc.CallOption) (*SetNotificationLevelResponse, error)
+	// Đặt thời gian lưu giữ tin nhắn của conversation: tin nhắn cũ hơn message_ttl_seconds bị xoá định kỳ (0 = lưu vĩnh viễn)
+	SetConversationRetention(ctx context.Context, in *SetConversationRetentionRequest, opts ...grpc.CallOption) (*SetConversationRetentionResponse, error)
+	// Thả cảm xúc (emoji) cho một tin nhắn; thành viên khác nhận sự kiện reaction_added với số lượng mới
+	AddReaction(ctx context.Context, in *AddReactionRequest, opts ...grpc.CallOption) (*AddReactionResponse, error)
+	// Bỏ cảm xúc đã thả; thành viên khác nhận sự kiện reaction_removed
+	RemoveReaction(ctx context.Context, in *RemoveReactionRequest, opts ...grpc.CallOption) (*RemoveReactionResponse, error)
+	// Kiểm tra một idempotency key còn tồn tại không và thời gian sống còn lại (chỉ admin)
+	InspectIdempotencyKey(ctx context.Context, in *InspectIdempotencyKeyRequest, opts ...grpc.CallOption) (*InspectIdempotencyKeyResponse, error)
+	// Xoá một idempotency key để client có thể gửi lại với cùng key (chỉ admin)
+	ClearIdempotencyKey(ctx context.Context, in *ClearIdempotencyKeyRequest, opts ...grpc.CallOption) (*ClearIdempotencyKeyResponse, error)
+	// Xuất toàn bộ lịch sử conversation theo thứ tự thời gian tăng dần, từng trang một (admin hoặc thành viên, chỉ có trên gRPC)
+	ExportConversation(ctx context.Context, in *ExportConversationRequest, opts ...grpc.CallOption) (grpc.ServerStreamingClient[ExportConversationChunk], error)
+	// Nhận sự kiện real-time của user qua gRPC stream (thay thế cho WebSocket, chỉ có trên gRPC)
+	StreamEvents(ctx context.Context, in *StreamEventsRequest, opts ...grpc.CallOption) (grpc.ServerStreamingClient[ChatEvent], error)
 	// Lấy credentials để upload ảnh lên Cloudinary
 	GetUploadCredentials(ctx context.Context, in *GetUploadCredentialsRequest, opts ...grpc.CallOption) (*GetUploadCredentialsResponse, error)
 }
@@ -72,6 +126,16 @@ func (c *chatServiceClient) GetMessages(ctx context.Context, in *GetMessagesRequ
 	return out, nil
 }
 
+func (c *chatServiceClient) GetMentions(ctx context.Context, in *GetMentionsRequest, opts ...grpc.CallOption) (*GetMentionsResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(GetMentionsResponse)
+	err := c.cc.Invoke(ctx, ChatService_GetMentions_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
 func (c *chatServiceClient) GetConversations(ctx context.Context, in *GetConversationsRequest, opts ...grpc.CallOption) (*GetConversationsResponse, error) {
 	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
 	out := new(GetConversationsResponse)
@@ -82,6 +146,16 @@ func (c *chatServiceClient) GetConversations(ctx context.Context, in *GetConvers
 	return out, nil
 }
 
+func (c *chatServiceClient) GetConversationsByIds(ctx context.Context, in *GetConversationsByIdsRequest, opts ...grpc.CallOption) (*GetConversationsByIdsResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(GetConversationsByIdsResponse)
+	err := c.cc.Invoke(ctx, ChatService_GetConversationsByIds_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
 func (c *chatServiceClient) MarkAsRead(ctx context.Context, in *MarkAsReadRequest, opts ...grpc.CallOption) (*MarkAsReadResponse, error) {
 	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
 	out := new(MarkAsReadResponse)
@@ -92,6 +166,184 @@ func (c *chatServiceClient) MarkAsRead(ctx context.Context, in *MarkAsReadReques
 	return out, nil
 }
 
+func (c *chatServiceClient) MarkAsDelivered(ctx context.Context, in *MarkAsDeliveredRequest, opts ...grpc.CallOption) (*MarkAsDeliveredResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(MarkAsDeliveredResponse)
+	err := c.cc.Invoke(ctx, ChatService_MarkAsDelivered_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *chatServiceClient) GetParticipants(ctx context.Context, in *GetParticipantsRequest, opts ...grpc.CallOption) (*GetParticipantsResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(GetParticipantsResponse)
+	err := c.cc.Invoke(ctx, ChatService_GetParticipants_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *chatServiceClient) CreateConversation(ctx context.Context, in *CreateConversationRequest, opts ...grpc.CallOption) (*CreateConversationResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(CreateConversationResponse)
+	err := c.cc.Invoke(ctx, ChatService_CreateConversation_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *chatServiceClient) DeleteConversation(ctx context.Context, in *DeleteConversationRequest, opts ...grpc.CallOption) (*DeleteConversationResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(DeleteConversationResponse)
+	err := c.cc.Invoke(ctx, ChatService_DeleteConversation_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *chatServiceClient) ArchiveConversation(ctx context.Context, in *ArchiveConversationRequest, opts ...grpc.CallOption) (*ArchiveConversationResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(ArchiveConversationResponse)
+	err := c.cc.Invoke(ctx, ChatService_ArchiveConversation_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *chatServiceClient) UnarchiveConversation(ctx context.Context, in *UnarchiveConversationRequest, opts ...grpc.CallOption) (*UnarchiveConversationResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(UnarchiveConversationResponse)
+	err := c.cc.Invoke(ctx, ChatService_UnarchiveConversation_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *chatServiceClient) PinConversation(ctx context.Context, in *PinConversationRequest, opts ...grpc.CallOption) (*PinConversationResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(PinConversationResponse)
+	err := c.cc.Invoke(ctx, ChatService_PinConversation_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *chatServiceClient) UnpinConversation(ctx context.Context, in *UnpinConversationRequest, opts ...grpc.CallOption) (*UnpinConversationResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(UnpinConversationResponse)
+	err := c.cc.Invoke(ctx, ChatService_UnpinConversation_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *chatServiceClient) SetNotificationLevel(ctx context.Context, in *SetNotificationLevelRequest, opts ...grpc.CallOption) (*SetNotificationLevelResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(SetNotificationLevelResponse)
+	err := c.cc.Invoke(ctx, ChatService_SetNotificationLevel_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *chatServiceClient) SetConversationRetention(ctx context.Context, in *SetConversationRetentionRequest, opts ...grpc.CallOption) (*SetConversationRetentionResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(SetConversationRetentionResponse)
+	err := c.cc.Invoke(ctx, ChatService_SetConversationRetention_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *chatServiceClient) AddReaction(ctx context.Context, in *AddReactionRequest, opts ...grpc.CallOption) (*AddReactionResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(AddReactionResponse)
+	err := c.cc.Invoke(ctx, ChatService_AddReaction_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *chatServiceClient) RemoveReaction(ctx context.Context, in *RemoveReactionRequest, opts ...grpc.CallOption) (*RemoveReactionResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(RemoveReactionResponse)
+	err := c.cc.Invoke(ctx, ChatService_RemoveReaction_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *chatServiceClient) InspectIdempotencyKey(ctx context.Context, in *InspectIdempotencyKeyRequest, opts ...grpc.CallOption) (*InspectIdempotencyKeyResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(InspectIdempotencyKeyResponse)
+	err := c.cc.Invoke(ctx, ChatService_InspectIdempotencyKey_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *chatServiceClient) ClearIdempotencyKey(ctx context.Context, in *ClearIdempotencyKeyRequest, opts ...grpc.CallOption) (*ClearIdempotencyKeyResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(ClearIdempotencyKeyResponse)
+	err := c.cc.Invoke(ctx, ChatService_ClearIdempotencyKey_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *chatServiceClient) ExportConversation(ctx context.Context, in *ExportConversationRequest, opts ...grpc.CallOption) (grpc.ServerStreamingClient[ExportConversationChunk], error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	stream, err := c.cc.NewStream(ctx, &ChatService_ServiceDesc.Streams[0], ChatService_ExportConversation_FullMethodName, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &grpc.GenericClientStream[ExportConversationRequest, ExportConversationChunk]{ClientStream: stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type ChatService_ExportConversationClient = grpc.ServerStreamingClient[ExportConversationChunk]
+
+func (c *chatServiceClient) StreamEvents(ctx context.Context, in *StreamEventsRequest, opts ...grpc.CallOption) (grpc.ServerStreamingClient[ChatEvent], error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	stream, err := c.cc.NewStream(ctx, &ChatService_ServiceDesc.Streams[1], ChatService_StreamEvents_FullMethodName, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &grpc.GenericClientStream[StreamEventsRequest, ChatEvent]{ClientStream: stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type ChatService_StreamEventsClient = grpc.ServerStreamingClient[ChatEvent]
+
 func (c *chatServiceClient) GetUploadCredentials(ctx context.Context, in *GetUploadCredentialsRequest, opts ...grpc.CallOption) (*GetUploadCredentialsResponse, error) {
 	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
 	out := new(GetUploadCredentialsResponse)
@@ -110,10 +362,46 @@ type ChatServiceServer interface {
 	SendMessage(context.Context, *SendMessageRequest) (*SendMessageResponse, error)
 	// Lấy danh sách tin nhắn theo conversation với pagination
 	GetMessages(context.Context, *GetMessagesRequest) (*GetMessagesResponse, error)
+	// Hộp thư nhắc tên: các tin nhắn gần đây nhắc đến user hiện tại (@user_id hoặc @username), mới nhất trước
+	GetMentions(context.Context, *GetMentionsRequest) (*GetMentionsResponse, error)
 	// Lấy danh sách conversation của user
 	GetConversations(context.Context, *GetConversationsRequest) (*GetConversationsResponse, error)
+	// Lấy metadata của một tập conversation theo id (đồng bộ danh sách đã cache ở client)
+	GetConversationsByIds(context.Context, *GetConversationsByIdsRequest) (*GetConversationsByIdsResponse, error)
 	// Đánh dấu tin nhắn đã đọc
 	MarkAsRead(context.Context, *MarkAsReadRequest) (*MarkAsReadResponse, error)
+	// Xác nhận tin nhắn đã được giao tới thiết bị của user hiện tại
+	MarkAsDelivered(context.Context, *MarkAsDeliveredRequest) (*MarkAsDeliveredResponse, error)
+	// Lấy danh sách thành viên của conversation (chỉ thành viên mới được xem)
+	GetParticipants(context.Context, *GetParticipantsRequest) (*GetParticipantsResponse, error)
+	// Tạo conversation với loại (direct/group) và danh sách thành viên cho trước; người gọi luôn là thành viên
+	CreateConversation(context.Context, *CreateConversationRequest) (*CreateConversationResponse, error)
+	// Ẩn conversation khỏi danh sách của user hiện tại (không ảnh hưởng thành viên khác)
+	DeleteConversation(context.Context, *DeleteConversationRequest) (*DeleteConversationResponse, error)
+	// Lưu trữ conversation: chuyển sang danh sách archived của user hiện tại, vẫn nhận tin nhắn
+	ArchiveConversation(context.Context, *ArchiveConversationRequest) (*ArchiveConversationResponse, error)
+	// Khôi phục conversation đã lưu trữ về danh sách chính
+	UnarchiveConversation(context.Context, *UnarchiveConversationRequest) (*UnarchiveConversationResponse, error)
+	// Ghim conversation lên đầu danh sách của user hiện tại (không ảnh hưởng thành viên khác)
+	PinConversation(context.Context, *PinConversationRequest) (*PinConversationResponse, error)
+	// Bỏ ghim conversation, trả về vị trí theo last_message_at
+	UnpinConversation(context.Context, *UnpinConversationRequest) (*UnpinConversationResponse, error)
+	// Đặt mức thông báo của user hiện tại cho conversation (tất cả, chỉ khi được nhắc tên, tắt thông báo)
+	SetNotificationLevel(context.Context, *SetNotificationLevelRequest) (*SetNotificationLevelResponse, error)
+	// Đặt thời gian lưu giữ tin nhắn của conversation: tin nhắn cũ hơn message_ttl_seconds bị xoá định kỳ (0 = lưu vĩnh viễn)
+	SetConversationRetention(context.Context, *SetConversationRetentionRequest) (*SetConversationRetentionResponse, error)
+	// Thả cảm xúc (emoji) cho một tin nhắn; thành viên khác nhận sự kiện reaction_added với số lượng mới
+	AddReaction(context.Context, *AddReactionRequest) (*AddReactionResponse, error)
+	// Bỏ cảm xúc đã thả; thành viên khác nhận sự kiện reaction_removed
+	RemoveReaction(context.Context, *RemoveReactionRequest) (*RemoveReactionResponse, error)
+	// Kiểm tra một idempotency key còn tồn tại không và thời gian sống còn lại (chỉ admin)
+	InspectIdempotencyKey(context.Context, *InspectIdempotencyKeyRequest) (*InspectIdempotencyKeyResponse, error)
+	// Xoá một idempotency key để client có thể gửi lại với cùng key (chỉ admin)
+	ClearIdempotencyKey(context.Context, *ClearIdempotencyKeyRequest) (*ClearIdempotencyKeyResponse, error)
+	// Xuất toàn bộ lịch sử conversation theo thứ tự thời gian tăng dần, từng trang một (admin hoặc thành viên, chỉ có trên gRPC)
+	ExportConversation(*ExportConversationRequest, grpc.ServerStreamingServer[ExportConversationChunk]) error
+	// Nhận sự kiện real-time của user qua gRPC stream (thay thế cho WebSocket, chỉ có trên gRPC)
+	StreamEvents(*StreamEventsRequest, grpc.ServerStreamingServer[ChatEvent]) error
 	// Lấy credentials để upload ảnh lên Cloudinary
 	GetUploadCredentials(context.Context, *GetUploadCredentialsRequest) (*GetUploadCredentialsResponse, error)
 	mustEmbedUnimplementedChatServiceServer()
@@ -132,12 +420,66 @@ func (UnimplementedChatServiceServer) SendMessage(context.Context, *SendMessageR
 func (UnimplementedChatServiceServer) GetMessages(context.Context, *GetMessagesRequest) (*GetMessagesResponse, error) {
 	return nil, status.Errorf(codes.Unimplemented, "method GetMessages not implemented")
 }
+func (UnimplementedChatServiceServer) GetMentions(context.Context, *GetMentionsRequest) (*GetMentionsResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetMentions not implemented")
+}
 func (UnimplementedChatServiceServer) GetConversations(context.Context, *GetConversationsRequest) (*GetConversationsResponse, error) {
 	return nil, status.Errorf(codes.Unimplemented, "method GetConversations not implemented")
 }
+func (UnimplementedChatServiceServer) GetConversationsByIds(context.Context, *GetConversationsByIdsRequest) (*GetConversationsByIdsResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetConversationsByIds not implemented")
+}
 func (UnimplementedChatServiceServer) MarkAsRead(context.Context, *MarkAsReadRequest) (*MarkAsReadResponse, error) {
 	return nil, status.Errorf(codes.Unimplemented, "method MarkAsRead not implemented")
 }
+func (UnimplementedChatServiceServer) MarkAsDelivered(context.Context, *MarkAsDeliveredRequest) (*MarkAsDeliveredResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method MarkAsDelivered not implemented")
+}
+func (UnimplementedChatServiceServer) GetParticipants(context.Context, *GetParticipantsRequest) (*GetParticipantsResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetParticipants not implemented")
+}
+func (UnimplementedChatServiceServer) CreateConversation(context.Context, *CreateConversationRequest) (*CreateConversationResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method CreateConversation not implemented")
+}
+func (UnimplementedChatServiceServer) DeleteConversation(context.Context, *DeleteConversationRequest) (*DeleteConversationResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method DeleteConversation not implemented")
+}
+func (UnimplementedChatServiceServer) ArchiveConversation(context.Context, *ArchiveConversationRequest) (*ArchiveConversationResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ArchiveConversation not implemented")
+}
+func (UnimplementedChatServiceServer) UnarchiveConversation(context.Context, *UnarchiveConversationRequest) (*UnarchiveConversationResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method UnarchiveConversation not implemented")
+}
+func (UnimplementedChatServiceServer) PinConversation(context.Context, *PinConversationRequest) (*PinConversationResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method PinConversation not implemented")
+}
+func (UnimplementedChatServiceServer) UnpinConversation(context.Context, *UnpinConversationRequest) (*UnpinConversationResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method UnpinConversation not implemented")
+}
+func (UnimplementedChatServiceServer) SetNotificationLevel(context.Context, *SetNotificationLevelRequest) (*SetNotificationLevelResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method SetNotificationLevel not implemented")
+}
+func (UnimplementedChatServiceServer) SetConversationRetention(context.Context, *SetConversationRetentionRequest) (*SetConversationRetentionResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method SetConversationRetention not implemented")
+}
+func (UnimplementedChatServiceServer) AddReaction(context.Context, *AddReactionRequest) (*AddReactionResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method AddReaction not implemented")
+}
+func (UnimplementedChatServiceServer) RemoveReaction(context.Context, *RemoveReactionRequest) (*RemoveReactionResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method RemoveReaction not implemented")
+}
+func (UnimplementedChatServiceServer) InspectIdempotencyKey(context.Context, *InspectIdempotencyKeyRequest) (*InspectIdempotencyKeyResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method InspectIdempotencyKey not implemented")
+}
+func (UnimplementedChatServiceServer) ClearIdempotencyKey(context.Context, *ClearIdempotencyKeyRequest) (*ClearIdempotencyKeyResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ClearIdempotencyKey not implemented")
+}
+func (UnimplementedChatServiceServer) ExportConversation(*ExportConversationRequest, grpc.ServerStreamingServer[ExportConversationChunk]) error {
+	return status.Errorf(codes.Unimplemented, "method ExportConversation not implemented")
+}
+func (UnimplementedChatServiceServer) StreamEvents(*StreamEventsRequest, grpc.ServerStreamingServer[ChatEvent]) error {
+	return status.Errorf(codes.Unimplemented, "method StreamEvents not implemented")
+}
 func (UnimplementedChatServiceServer) GetUploadCredentials(context.Context, *GetUploadCredentialsRequest) (*GetUploadCredentialsResponse, error) {
 	return nil, status.Errorf(codes.Unimplemented, "method GetUploadCredentials not implemented")
 }
@@ -198,6 +540,24 @@ func _ChatService_GetMessages_Handler(srv interface{}, ctx context.Context, dec
 	return interceptor(ctx, in, info, handler)
 }
 
+func _ChatService_GetMentions_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetMentionsRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ChatServiceServer).GetMentions(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: ChatService_GetMentions_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ChatServiceServer).GetMentions(ctx, req.(*GetMentionsRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
 func _ChatService_GetConversations_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
 	in := new(GetConversationsRequest)
 	if err := dec(in); err != nil {
@@ -216,6 +576,24 @@ func _ChatService_GetConversations_Handler(srv interface{}, ctx context.Context,
 	return interceptor(ctx, in, info, handler)
 }
 
+func _ChatService_GetConversationsByIds_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetConversationsByIdsRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ChatServiceServer).GetConversationsByIds(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: ChatService_GetConversationsByIds_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ChatServiceServer).GetConversationsByIds(ctx, req.(*GetConversationsByIdsRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
 func _ChatService_MarkAsRead_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
 	in := new(MarkAsReadRequest)
 	if err := dec(in); err != nil {
@@ -234,6 +612,280 @@ func _ChatService_MarkAsRead_Handler(srv interface{}, ctx context.Context, dec f
 	return interceptor(ctx, in, info, handler)
 }
 
+func _ChatService_MarkAsDelivered_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(MarkAsDeliveredRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ChatServiceServer).MarkAsDelivered(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: ChatService_MarkAsDelivered_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ChatServiceServer).MarkAsDelivered(ctx, req.(*MarkAsDeliveredRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _ChatService_GetParticipants_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetParticipantsRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ChatServiceServer).GetParticipants(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: ChatService_GetParticipants_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ChatServiceServer).GetParticipants(ctx, req.(*GetParticipantsRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _ChatService_CreateConversation_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(CreateConversationRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ChatServiceServer).CreateConversation(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: ChatService_CreateConversation_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ChatServiceServer).CreateConversation(ctx, req.(*CreateConversationRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _ChatService_DeleteConversation_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(DeleteConversationRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ChatServiceServer).DeleteConversation(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: ChatService_DeleteConversation_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ChatServiceServer).DeleteConversation(ctx, req.(*DeleteConversationRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _ChatService_ArchiveConversation_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ArchiveConversationRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ChatServiceServer).ArchiveConversation(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: ChatService_ArchiveConversation_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ChatServiceServer).ArchiveConversation(ctx, req.(*ArchiveConversationRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _ChatService_UnarchiveConversation_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(UnarchiveConversationRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ChatServiceServer).UnarchiveConversation(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: ChatService_UnarchiveConversation_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ChatServiceServer).UnarchiveConversation(ctx, req.(*UnarchiveConversationRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _ChatService_PinConversation_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(PinConversationRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ChatServiceServer).PinConversation(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: ChatService_PinConversation_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ChatServiceServer).PinConversation(ctx, req.(*PinConversationRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _ChatService_UnpinConversation_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(UnpinConversationRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ChatServiceServer).UnpinConversation(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: ChatService_UnpinConversation_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ChatServiceServer).UnpinConversation(ctx, req.(*UnpinConversationRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _ChatService_SetNotificationLevel_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(SetNotificationLevelRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ChatServiceServer).SetNotificationLevel(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: ChatService_SetNotificationLevel_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ChatServiceServer).SetNotificationLevel(ctx, req.(*SetNotificationLevelRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _ChatService_SetConversationRetention_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(SetConversationRetentionRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ChatServiceServer).SetConversationRetention(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: ChatService_SetConversationRetention_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ChatServiceServer).SetConversationRetention(ctx, req.(*SetConversationRetentionRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _ChatService_AddReaction_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(AddReactionRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ChatServiceServer).AddReaction(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: ChatService_AddReaction_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ChatServiceServer).AddReaction(ctx, req.(*AddReactionRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _ChatService_RemoveReaction_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(RemoveReactionRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ChatServiceServer).RemoveReaction(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: ChatService_RemoveReaction_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ChatServiceServer).RemoveReaction(ctx, req.(*RemoveReactionRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _ChatService_InspectIdempotencyKey_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(InspectIdempotencyKeyRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ChatServiceServer).InspectIdempotencyKey(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: ChatService_InspectIdempotencyKey_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ChatServiceServer).InspectIdempotencyKey(ctx, req.(*InspectIdempotencyKeyRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _ChatService_ClearIdempotencyKey_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ClearIdempotencyKeyRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ChatServiceServer).ClearIdempotencyKey(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: ChatService_ClearIdempotencyKey_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ChatServiceServer).ClearIdempotencyKey(ctx, req.(*ClearIdempotencyKeyRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _ChatService_ExportConversation_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(ExportConversationRequest)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(ChatServiceServer).ExportConversation(m, &grpc.GenericServerStream[ExportConversationRequest, ExportConversationChunk]{ServerStream: stream})
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type ChatService_ExportConversationServer = grpc.ServerStreamingServer[ExportConversationChunk]
+
+func _ChatService_StreamEvents_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(StreamEventsRequest)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(ChatServiceServer).StreamEvents(m, &grpc.GenericServerStream[StreamEventsRequest, ChatEvent]{ServerStream: stream})
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type ChatService_StreamEventsServer = grpc.ServerStreamingServer[ChatEvent]
+
 func _ChatService_GetUploadCredentials_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
 	in := new(GetUploadCredentialsRequest)
 	if err := dec(in); err != nil {
@@ -267,19 +919,94 @@ var ChatService_ServiceDesc = grpc.ServiceDesc{
 			MethodName: "GetMessages",
 			Handler:    _ChatService_GetMessages_Handler,
 		},
+		{
+			MethodName: "GetMentions",
+			Handler:    _ChatService_GetMentions_Handler,
+		},
 		{
 			MethodName: "GetConversations",
 			Handler:    _ChatService_GetConversations_Handler,
 		},
+		{
+			MethodName: "GetConversationsByIds",
+			Handler:    _ChatService_GetConversationsByIds_Handler,
+		},
 		{
 			MethodName: "MarkAsRead",
 			Handler:    _ChatService_MarkAsRead_Handler,
 		},
+		{
+			MethodName: "MarkAsDelivered",
+			Handler:    _ChatService_MarkAsDelivered_Handler,
+		},
+		{
+			MethodName: "GetParticipants",
+			Handler:    _ChatService_GetParticipants_Handler,
+		},
+		{
+			MethodName: "CreateConversation",
+			Handler:    _ChatService_CreateConversation_Handler,
+		},
+		{
+			MethodName: "DeleteConversation",
+			Handler:    _ChatService_DeleteConversation_Handler,
+		},
+		{
+			MethodName: "ArchiveConversation",
+			Handler:    _ChatService_ArchiveConversation_Handler,
+		},
+		{
+			MethodName: "UnarchiveConversation",
+			Handler:    _ChatService_UnarchiveConversation_Handler,
+		},
+		{
+			MethodName: "PinConversation",
+			Handler:    _ChatService_PinConversation_Handler,
+		},
+		{
+			MethodName: "UnpinConversation",
+			Handler:    _ChatService_UnpinConversation_Handler,
+		},
+		{
+			MethodName: "SetNotificationLevel",
+			Handler:    _ChatService_SetNotificationLevel_Handler,
+		},
+		{
+			MethodName: "SetConversationRetention",
+			Handler:    _ChatService_SetConversationRetention_Handler,
+		},
+		{
+			MethodName: "AddReaction",
+			Handler:    _ChatService_AddReaction_Handler,
+		},
+		{
+			MethodName: "RemoveReaction",
+			Handler:    _ChatService_RemoveReaction_Handler,
+		},
+		{
+			MethodName: "InspectIdempotencyKey",
+			Handler:    _ChatService_InspectIdempotencyKey_Handler,
+		},
+		{
+			MethodName: "ClearIdempotencyKey",
+			Handler:    _ChatService_ClearIdempotencyKey_Handler,
+		},
 		{
 			MethodName: "GetUploadCredentials",
 			Handler:    _ChatService_GetUploadCredentials_Handler,
 		},
 	},
-	Streams:  []grpc.StreamDesc{},
+	Streams: []grpc.StreamDesc{
+		{
+			StreamName:    "ExportConversation",
+			Handler:       _ChatService_ExportConversation_Handler,
+			ServerStreams: true,
+		},
+		{
+			StreamName:    "StreamEvents",
+			Handler:       _ChatService_StreamEvents_Handler,
+			ServerStreams: true,
+		},
+	},
 	Metadata: "chat/v1/chat.proto",
 }
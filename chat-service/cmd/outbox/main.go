@@ -6,10 +6,12 @@ import (
 	"net/http"
 	"os"
 	"os/signal"
+	"strconv"
 	"syscall"
 	"time"
 
 	"chat-service/internal/config"
+	"chat-service/internal/health"
 	"chat-service/internal/middleware"
 	"chat-service/internal/outbox"
 
@@ -36,7 +38,9 @@ func main() {
 	logger.Info("starting outbox processor service",
 		zap.String("env", cfg.Environment),
 		zap.Int("poll_interval_ms", cfg.OutboxPollIntervalMs),
-		zap.Int("batch_size", cfg.OutboxBatchSize))
+		zap.Int("batch_size", cfg.OutboxBatchSize),
+		zap.Int("max_payload_bytes", cfg.OutboxMaxPayloadBytes),
+		zap.Int("max_inflight_publishes", cfg.OutboxMaxInFlightPublishes))
 
 	// 3. Connect to Database with pool configuration (Requirement 1.1, 1.2)
 	poolConfig, err := pgxpool.ParseConfig(cfg.GetDBSource())
@@ -47,10 +51,12 @@ func main() {
 	poolConfig.MinConns = cfg.GetDBMinConns()
 	poolConfig.MaxConnLifetime = cfg.GetDBMaxConnLifetime()
 	poolConfig.MaxConnIdleTime = cfg.GetDBMaxConnIdleTime()
+	poolConfig.ConnConfig.RuntimeParams["statement_timeout"] = strconv.FormatInt(cfg.GetDBStatementTimeout().Milliseconds(), 10)
 
 	logger.Info("database pool config",
 		zap.Int32("max_conns", poolConfig.MaxConns),
-		zap.Int32("min_conns", poolConfig.MinConns))
+		zap.Int32("min_conns", poolConfig.MinConns),
+		zap.Duration("statement_timeout", cfg.GetDBStatementTimeout()))
 
 	dbPool, err := pgxpool.NewWithConfig(context.Background(), poolConfig)
 	if err != nil {
@@ -71,19 +77,88 @@ func main() {
 
 	// 5. Create Processor with validated config
 	processorCfg := outbox.ProcessorConfig{
-		PollInterval: cfg.GetOutboxPollInterval(logger),
-		BatchSize:    cfg.GetOutboxBatchSize(logger),
+		PollInterval:    cfg.GetOutboxPollInterval(logger),
+		MaxPollInterval: cfg.GetOutboxMaxPollInterval(),
+		BatchSize:       cfg.GetOutboxBatchSize(logger),
+		MaxPayloadBytes: cfg.OutboxMaxPayloadBytes,
+		// Redis write concurrency, decoupled from how many events a batch fans out to workers
+		MaxInFlightPublishes: cfg.OutboxMaxInFlightPublishes,
+		StaleAfter:           cfg.GetOutboxStaleAfter(),
 	}
 	processor := outbox.NewProcessor(dbPool, redisClient, logger, processorCfg)
 
+	// Pub/Sub (default) drops events while no ws-gateway is subscribed; a stream keeps them
+	switch cfg.EventTransport {
+	case "", "pubsub":
+		if cfg.EventShards > 0 {
+			processor.SetPublisher(outbox.NewShardedPublisher(redisClient, cfg.EventShards))
+			logger.Info("publishing events to sharded Redis Pub/Sub",
+				zap.String("channel_prefix", outbox.ChannelName),
+				zap.Int("shards", cfg.EventShards))
+			break
+		}
+		logger.Info("publishing events to Redis Pub/Sub", zap.String("channel", outbox.ChannelName))
+	case "stream":
+		if cfg.EventShards > 0 {
+			logger.Fatal("EVENT_SHARDS requires EVENT_TRANSPORT=pubsub")
+		}
+		processor.SetPublisher(outbox.NewStreamPublisher(redisClient, cfg.GetOutboxStreamMaxLen()))
+		logger.Info("publishing events to Redis Stream",
+			zap.String("stream", outbox.StreamName),
+			zap.Int64("max_len", cfg.GetOutboxStreamMaxLen()))
+	default:
+		logger.Fatal("unknown EVENT_TRANSPORT (expected pubsub or stream)", zap.String("transport", cfg.EventTransport))
+	}
+
+	// 5.1 Optional leader election: only the leader polls at OUTBOX_POLL_INTERVAL_MS
+	var elector *outbox.LeaderElector
+	if cfg.OutboxLeaderElection {
+		elector = outbox.NewLeaderElector(redisClient, logger, outbox.LeaderElectionConfig{
+			InstanceID: instanceID(),
+			LeaseTTL:   cfg.GetOutboxLeaderLease(),
+		}, outbox.DefaultMetrics)
+		processor.SetLeaderElector(elector, cfg.GetOutboxFollowerPollInterval())
+		logger.Info("outbox leader election enabled",
+			zap.Duration("lease_ttl", cfg.GetOutboxLeaderLease()),
+			zap.Duration("follower_poll_interval", cfg.GetOutboxFollowerPollInterval()))
+	}
+
+	// 5.2 Optional LISTEN/NOTIFY: poll as soon as events are committed, keep polling as a safety net
+	var listener *outbox.NotifyListener
+	if cfg.OutboxListenNotify {
+		listener = outbox.NewNotifyListener(dbPool, logger)
+		processor.SetWakeup(listener.Wake())
+		logger.Info("outbox insert notifications enabled",
+			zap.String("channel", outbox.NotifyChannel),
+			zap.Duration("safety_poll_interval", cfg.GetOutboxMaxPollInterval()))
+	}
+
 	// 6. Setup context for graceful shutdown
 	ctx, cancel := context.WithCancel(context.Background())
 	defer cancel()
 
 	// 7. Start metrics HTTP server
-	metricsServer := startMetricsServer(logger, cfg.GetMetricsPort())
-
-	// 8. Start processor in a goroutine
+	healthHandler := health.NewHandler(logger, health.DefaultTimeout)
+	healthHandler.AddCheck("postgres", dbPool.Ping)
+	healthHandler.AddCheck("redis", func(ctx context.Context) error {
+		return redisClient.Ping(ctx).Err()
+	})
+	healthHandler.AddCheck("processor", processor.CheckHealth)
+	metricsServer := startMetricsServer(logger, cfg.GetMetricsPort(), healthHandler, processor.HealthHandler)
+
+	// 8. Start processor (and leader election) in goroutines
+	electorDone := make(chan struct{})
+	if elector != nil {
+		go func() {
+			defer close(electorDone)
+			elector.Run(ctx)
+		}()
+	} else {
+		close(electorDone)
+	}
+	if listener != nil {
+		go listener.Run(ctx)
+	}
 	go processor.Start(ctx)
 
 	logger.Info("outbox processor is running",
@@ -96,10 +171,16 @@ func main() {
 
 	logger.Info("received shutdown signal", zap.String("signal", sig.String()))
 	logger.Info("initiating graceful shutdown, waiting for current batch to complete...")
+	healthHandler.SetShuttingDown()
 
-	// Cancel context and stop processor (waits for current batch)
+	// Cancel context and stop processor (drains the current batch up to the deadline)
 	cancel()
-	processor.Stop()
+	stopCtx, stopCancel := context.WithTimeout(context.Background(), cfg.GetOutboxShutdownTimeout())
+	if err := processor.Stop(stopCtx); err != nil {
+		logger.Error("outbox processor did not drain before the shutdown deadline", zap.Error(err))
+	}
+	stopCancel()
+	<-electorDone // lease released so another replica takes over immediately
 
 	// Shutdown metrics server
 	shutdownCtx, shutdownCancel := context.WithTimeout(context.Background(), 5*time.Second)
@@ -111,14 +192,23 @@ func main() {
 	logger.Info("outbox processor shutdown complete")
 }
 
-// startMetricsServer starts the Prometheus metrics HTTP server.
-func startMetricsServer(logger *zap.Logger, port int) *http.Server {
+// instanceID identifies this replica in the leader lease (hostname-pid)
+func instanceID() string {
+	hostname, err := os.Hostname()
+	if err != nil || hostname == "" {
+		hostname = "outbox"
+	}
+	return fmt.Sprintf("%s-%d", hostname, os.Getpid())
+}
+
+// startMetricsServer starts the Prometheus metrics HTTP server, which also serves the health probes
+// and the processor state on /health.
+func startMetricsServer(logger *zap.Logger, port int, healthHandler *health.Handler, processorHealth http.HandlerFunc) *http.Server {
 	mux := http.NewServeMux()
 	mux.Handle("/metrics", promhttp.Handler())
-	mux.HandleFunc("/health", func(w http.ResponseWriter, _ *http.Request) {
-		w.WriteHeader(http.StatusOK)
-		_, _ = w.Write([]byte("ok"))
-	})
+	mux.HandleFunc("/health", processorHealth)
+	mux.HandleFunc("/healthz", healthHandler.Liveness)
+	mux.HandleFunc("/readyz", healthHandler.Readiness)
 
 	server := &http.Server{
 		Addr:              fmt.Sprintf(":%d", port),
@@ -8,19 +8,28 @@ import (
 	"net/http"
 	"os"
 	"os/signal"
+	"slices"
+	"strconv"
 	"syscall"
 	"time"
 
 	chatv1 "chat-service/api/chat/v1"
 	"chat-service/internal/auth"
 	"chat-service/internal/config"
+	"chat-service/internal/health"
 	"chat-service/internal/middleware"
+	"chat-service/internal/repository"
+	"chat-service/internal/retention"
 	"chat-service/internal/service"
+	"chat-service/internal/ws"
 	"chat-service/pkg/cloudinary"
 	"chat-service/pkg/idempotency"
+	"chat-service/pkg/profile"
 
 	"github.com/grpc-ecosystem/grpc-gateway/v2/runtime"
 	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
 	"github.com/redis/go-redis/v9"
 	"go.uber.org/zap"
 	"google.golang.org/grpc"
@@ -51,12 +60,16 @@ func main() {
 	poolConfig.MinConns = cfg.GetDBMinConns()
 	poolConfig.MaxConnLifetime = cfg.GetDBMaxConnLifetime()
 	poolConfig.MaxConnIdleTime = cfg.GetDBMaxConnIdleTime()
+	// Sent as a startup parameter, so every pooled connection runs with SET statement_timeout
+	statementTimeout := cfg.GetDBStatementTimeout()
+	poolConfig.ConnConfig.RuntimeParams["statement_timeout"] = strconv.FormatInt(statementTimeout.Milliseconds(), 10)
 
 	logger.Info("database pool config",
 		zap.Int32("max_conns", poolConfig.MaxConns),
 		zap.Int32("min_conns", poolConfig.MinConns),
 		zap.Duration("max_conn_lifetime", poolConfig.MaxConnLifetime),
-		zap.Duration("max_conn_idle_time", poolConfig.MaxConnIdleTime))
+		zap.Duration("max_conn_idle_time", poolConfig.MaxConnIdleTime),
+		zap.Duration("statement_timeout", statementTimeout))
 
 	dbPool, err := pgxpool.NewWithConfig(context.Background(), poolConfig)
 	if err != nil {
@@ -100,12 +113,108 @@ func main() {
 		chatService = service.NewChatService(dbPool, idempotencyChecker, logger)
 	}
 
+	chatService.SetQueryTimeout(statementTimeout)
+	chatService.SetMaxContentBytes(cfg.GetMaxContentBytes())
+	chatService.SetMaxReceivers(cfg.GetMaxReceiversPerMessage())
+	chatService.SetMaxGroupSize(cfg.GetMaxGroupSize())
+	chatService.SetKeepArchivedOnNewMessage(cfg.KeepArchivedOnNewMessage)
+	chatService.SetStrictParticipants(cfg.StrictParticipants)
+	duplicateTracker := service.NewDuplicateTracker(prometheus.DefaultRegisterer, service.DuplicateTrackerConfig{
+		TopN:           cfg.GetIdempotencyMetricsTopN(),
+		Window:         cfg.GetIdempotencyMetricsWindow(),
+		StormThreshold: cfg.GetIdempotencyStormThreshold(),
+	})
+	chatService.SetDuplicateTracker(duplicateTracker)
+	if mimeTypes := cfg.GetAttachmentAllowedMimeTypes(); cfg.AttachmentMaxSizeBytes > 0 || len(mimeTypes) > 0 {
+		chatService.SetAttachmentValidator(service.NewAllowlistAttachmentValidator(cfg.AttachmentMaxSizeBytes, mimeTypes))
+		logger.Info("attachment allowlist enabled",
+			zap.Int64("max_size_bytes", cfg.AttachmentMaxSizeBytes),
+			zap.Strings("mime_types", mimeTypes))
+	}
+	if blocked, flagged := cfg.GetContentBlockedWords(), cfg.GetContentFlaggedWords(); len(blocked) > 0 || len(flagged) > 0 {
+		chatService.SetContentFilter(service.NewWordlistContentFilter(blocked, flagged))
+		logger.Info("content wordlist filter enabled",
+			zap.Int("blocked_words", len(blocked)),
+			zap.Int("flagged_words", len(flagged)))
+	}
+
+	// 5.3 Sender profiles (optional)
+	switch cfg.ProfileSource {
+	case "redis":
+		chatService.SetProfileResolver(profile.NewRedisResolver(redisClient))
+		logger.Info("sender profiles enabled", zap.String("source", cfg.ProfileSource))
+	case "":
+	default:
+		logger.Fatal("unknown PROFILE_SOURCE", zap.String("source", cfg.ProfileSource))
+	}
+
+	// 5.4 gRPC event push (optional): the API server subscribes to the event transport
+	// like a ws-gateway and fans events out to StreamEvents callers through the same Router
+	var eventSource ws.EventSource
+	var streamHub *ws.StreamHub
+	if cfg.GRPCEventStream {
+		instanceID, err := ws.ConfigureInstanceID(cfg.InstanceIDStrategy)
+		if err != nil {
+			logger.Fatal("invalid instance ID configuration", zap.String("strategy", cfg.InstanceIDStrategy), zap.Error(err))
+		}
+		connManager := ws.NewConnectionManager()
+		router := ws.NewRouter(connManager, logger, nil)
+		streamHub = ws.NewStreamHub(connManager, logger, ws.DefaultSendBufferSize)
+		// Bounded routing queue, as on the ws-gateway (default workers, queue size and block timeout)
+		dispatcher := ws.NewEventDispatcher(router.HandleEvent, logger, 0, 0, 0)
+		dispatcher.Start(context.Background())
+
+		switch cfg.EventTransport {
+		case "", "pubsub":
+			if cfg.EventShards > 0 {
+				shards := ws.NewShardedSubscriber(redisClient, logger, dispatcher.Handle, ws.NewDBConversationLister(dbPool), cfg.EventShards)
+				streamHub.SetShardedSubscriber(shards)
+				eventSource = shards
+				break
+			}
+			eventSource = ws.NewSubscriber(redisClient, logger, dispatcher.Handle)
+		case "stream":
+			// Own consumer group, so gateways and API servers each receive every event
+			streamSubscriber := ws.NewStreamSubscriber(redisClient, logger, nil, "api-"+instanceID)
+			streamSubscriber.SetAckHandler(dispatcher.HandleAck)
+			eventSource = streamSubscriber
+		default:
+			logger.Fatal("unknown EVENT_TRANSPORT (expected pubsub or stream)", zap.String("transport", cfg.EventTransport))
+		}
+
+		if err := eventSource.Start(context.Background()); err != nil {
+			logger.Fatal("cannot start event subscriber", zap.Error(err))
+		}
+		chatService.SetEventSubscriber(streamHub)
+		logger.Info("gRPC event stream enabled",
+			zap.String("transport", cfg.EventTransport),
+			zap.Int("shards", cfg.EventShards),
+			zap.String("instance_id", instanceID),
+		)
+	}
+
+	// 5.5 Verify bearer tokens (and trust their roles) when the gateway's signing key is configured
+	var authOpts []auth.Option
+	if cfg.AccessTokenSecret != "" {
+		authOpts = append(authOpts, auth.WithJWTVerifier(auth.NewJWTVerifier(cfg.AccessTokenSecret)))
+		logger.Info("jwt verification enabled")
+	} else {
+		logger.Warn("ACCESS_TOKEN_SECRET not set - trusting x-user-id only, role-protected RPCs will be denied")
+	}
+
 	// 6. Setup gRPC Server
+	metrics := middleware.DefaultMetrics()
 	grpcServer := grpc.NewServer(
 		grpc.ChainUnaryInterceptor(
+			middleware.GrpcRequestID(),
 			middleware.GrpcLogger(logger),
+			middleware.GrpcMetrics(metrics),
 			middleware.GrpcRecovery(logger),
-			auth.GrpcAuthInterceptor(logger),
+			auth.GrpcAuthInterceptor(logger, authOpts...),
+		),
+		grpc.ChainStreamInterceptor(
+			middleware.GrpcStreamRecovery(logger),
+			auth.GrpcAuthStreamInterceptor(logger, authOpts...),
 		),
 	)
 
@@ -122,29 +231,81 @@ func main() {
 	ctx, cancel := context.WithCancel(context.Background())
 	defer cancel()
 
+	// Idempotency duplicate attribution: publish the noisiest users/conversations once per window
+	go duplicateTracker.Run(ctx)
+
+	// Message retention: delete messages past their conversation's TTL
+	if !cfg.RetentionPurgeDisabled {
+		purger := retention.NewPurger(repository.New(dbPool), logger,
+			cfg.GetRetentionPurgeInterval(), cfg.GetRetentionPurgeBatchSize())
+		go purger.Run(ctx)
+		logger.Info("message retention purge enabled",
+			zap.Duration("interval", cfg.GetRetentionPurgeInterval()),
+			zap.Int("batch_size", cfg.GetRetentionPurgeBatchSize()))
+	}
+
 	gatewayMux := runtime.NewServeMux(
 		runtime.WithErrorHandler(middleware.GatewayErrorHandler(logger)),
 		runtime.WithIncomingHeaderMatcher(middleware.CustomHeaderMatcher),
+		runtime.WithMiddlewares(middleware.GatewayMetrics(metrics)),
 	)
 
 	if err := chatv1.RegisterChatServiceHandlerServer(ctx, gatewayMux, chatService); err != nil {
 		logger.Fatal("cannot register chat gateway handler", zap.Error(err))
 	}
 
+	corsConfig := middleware.DefaultCORSConfig()
+	if origins := cfg.GetCORSAllowedOrigins(); origins != nil {
+		corsConfig.AllowedOrigins = origins
+	}
+	if methods := cfg.GetCORSAllowedMethods(); methods != nil {
+		corsConfig.AllowedMethods = methods
+	}
+	if headers := cfg.GetCORSAllowedHeaders(); headers != nil {
+		corsConfig.AllowedHeaders = headers
+	}
+	corsConfig.AllowCredentials = cfg.CORSAllowCredentials
+	corsConfig.MaxAge = cfg.GetCORSMaxAge()
+	if slices.Contains(corsConfig.AllowedOrigins, "*") {
+		if cfg.Environment == "production" {
+			logger.Warn("CORS allows any origin; set CORS_ALLOWED_ORIGINS")
+		}
+		if corsConfig.AllowCredentials {
+			logger.Warn("CORS_ALLOW_CREDENTIALS is ignored while any origin is allowed")
+		}
+	}
+
 	httpMux := http.NewServeMux()
-	httpHandler := middleware.CORS(
-		middleware.HTTPRecovery(logger)(
-			middleware.HTTPLogger(logger)(
-				middleware.HTTPAuthExtractor(logger)(gatewayMux))))
+	httpHandler := middleware.HTTPRequestID(
+		middleware.CORSWithConfig(corsConfig)(
+			middleware.HTTPRecovery(logger)(
+				middleware.HTTPLogger(logger)(
+					middleware.HTTPAuthExtractor(logger, authOpts...)(gatewayMux)))))
 	httpMux.Handle("/", httpHandler)
+	httpMux.Handle("/metrics", promhttp.Handler())
+
+	// Probes sit outside the middleware chain: no auth, and no log line per kubelet poll
+	healthHandler := health.NewHandler(logger, health.DefaultTimeout)
+	healthHandler.AddCheck("postgres", dbPool.Ping)
+	healthHandler.AddCheck("redis", func(ctx context.Context) error {
+		return redisClient.Ping(ctx).Err()
+	})
+	httpMux.HandleFunc("/healthz", healthHandler.Liveness)
+	httpMux.HandleFunc("/readyz", healthHandler.Readiness)
 
 	httpServer := &http.Server{
-		Addr:    cfg.HTTPServerAddress,
-		Handler: httpMux,
+		Addr:         cfg.HTTPServerAddress,
+		Handler:      httpMux,
+		ReadTimeout:  cfg.GetHTTPReadTimeout(),
+		WriteTimeout: cfg.GetHTTPWriteTimeout(),
 	}
 
 	logger.Info("gRPC server listening", zap.String("address", cfg.GRPCServerAddress))
-	logger.Info("HTTP gateway listening", zap.String("address", cfg.HTTPServerAddress))
+	logger.Info("HTTP gateway listening",
+		zap.String("address", cfg.HTTPServerAddress),
+		zap.Duration("read_timeout", httpServer.ReadTimeout),
+		zap.Duration("write_timeout", httpServer.WriteTimeout),
+	)
 
 	go func() {
 		if err := httpServer.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
@@ -158,6 +319,7 @@ func main() {
 		signal.Notify(sigChan, syscall.SIGINT, syscall.SIGTERM)
 		sig := <-sigChan
 		logger.Info("received shutdown signal", zap.String("signal", sig.String()))
+		healthHandler.SetShuttingDown()
 
 		// stop gateway registrations
 		cancel()
@@ -171,6 +333,14 @@ func main() {
 			logger.Info("HTTP server stopped")
 		}
 
+		// Open event streams would hold GracefulStop; end them so clients reconnect elsewhere
+		if eventSource != nil {
+			if err := eventSource.Stop(); err != nil {
+				logger.Error("failed to stop event subscriber", zap.Error(err))
+			}
+			streamHub.Close()
+		}
+
 		logger.Info("shutting down gRPC server...")
 		grpcServer.GracefulStop()
 		logger.Info("gRPC server stopped")
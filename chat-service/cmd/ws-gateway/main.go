@@ -3,17 +3,22 @@ package main
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"log"
 	"net/http"
 	"os"
 	"os/signal"
+	"strconv"
 	"syscall"
 	"time"
 
 	"chat-service/internal/auth"
+	"chat-service/internal/config"
+	"chat-service/internal/health"
 	"chat-service/internal/ws"
 
 	"github.com/gorilla/websocket"
+	"github.com/jackc/pgx/v5/pgxpool"
 	"github.com/prometheus/client_golang/prometheus/promhttp"
 	"github.com/redis/go-redis/v9"
 	"go.uber.org/zap"
@@ -29,16 +34,36 @@ var (
 		},
 	}
 	connManager = ws.NewConnectionManager()
-	subscriber  *ws.Subscriber
+	subscriber  ws.EventSource
 	router      *ws.Router
 	logger      *zap.Logger
 	metrics     *ws.Metrics
+	// jwtVerifier is set when WS_AUTH_MODE=jwt; nil means trust X-User-Id from the API Gateway
+	jwtVerifier *auth.JWTVerifier
+	// sendBufferSize is the per-connection outgoing queue length (WS_SEND_BUFFER_SIZE)
+	sendBufferSize = ws.DefaultSendBufferSize
+	// shardedSubscriber is set when EVENT_SHARDS > 0; it follows the conversations of connected users
+	shardedSubscriber *ws.ShardedSubscriber
+	// readLimit is the largest frame accepted from clients, derived from MAX_CONTENT_BYTES unless WS_READ_LIMIT_BYTES is set
+	readLimit = ws.ReadLimitForContent(config.DefaultMaxContentBytes)
+	// trustedProxies may report the client address in X-Forwarded-For (WS_TRUSTED_PROXIES)
+	trustedProxies ws.TrustedProxies
+	// reconnectJitter spreads reconnects after a shutdown or a rejected accept (WS_RECONNECT_MIN_MS, WS_RECONNECT_MAX_MS)
+	reconnectJitter = ws.NewReconnectJitter(ws.DefaultReconnectMin, ws.DefaultReconnectMax)
+	// unreadCounter is set with DB_SOURCE; it backs the unread_snapshot sent on connect (WS_UNREAD_SNAPSHOT_LIMIT)
+	unreadCounter      ws.UnreadCounter
+	unreadSnapshotSize = ws.DefaultUnreadSnapshotLimit
+	// presence records in Redis which instances hold each user's connections
+	presence *ws.PresenceRegistry
 )
 
 const (
 	// Time allowed to write a message to the peer.
 	writeWait = 10 * time.Second
 
+	// Time allowed to update the presence of a user in Redis.
+	presenceWait = 2 * time.Second
+
 	// Time allowed to read the next pong message from the peer.
 	pongWait = 90 * time.Second
 
@@ -47,39 +72,78 @@ const (
 )
 
 func serveWs(w http.ResponseWriter, r *http.Request) {
-	// Extract user_id from request (header first, then query param for browser WebSocket)
-	userID, err := auth.ExtractUserIDFromRequest(r)
+	userID, err := authenticate(r)
 	if err != nil {
 		log.Printf("Auth failed: %v", err)
 		http.Error(w, "Unauthorized", http.StatusUnauthorized)
 		return
 	}
 
+	// Each device keeps its own connection; without an ID the user has a single shared one
+	deviceID, err := ws.DeviceIDFromRequest(r)
+	if err != nil {
+		http.Error(w, "Invalid device_id", http.StatusBadRequest)
+		return
+	}
+
+	// Frames are JSON text unless the client negotiates MessagePack
+	encoding, subprotocol, err := ws.EncodingFromRequest(r)
+	if err != nil {
+		http.Error(w, "Invalid encoding", http.StatusBadRequest)
+		return
+	}
+	var responseHeader http.Header
+	if subprotocol != "" {
+		responseHeader = http.Header{"Sec-Websocket-Protocol": {subprotocol}}
+	}
+
 	// Upgrade connection
-	conn, err := upgrader.Upgrade(w, r, nil)
+	conn, err := upgrader.Upgrade(w, r, responseHeader)
 	if err != nil {
 		log.Println("Upgrade error:", err)
 		return
 	}
 
-	client := ws.NewClient(conn)
+	client := ws.NewClientWithBufferSize(conn, sendBufferSize)
+	client.DeviceID = deviceID
+	client.Encoding = encoding
+	client.SetRequestInfo(r, trustedProxies)
 	result := connManager.Add(userID, client)
 	metrics.ConnectionOpened()
 
+	if shardedSubscriber != nil {
+		if err := shardedSubscriber.AddUser(r.Context(), userID); err != nil {
+			logger.Warn("Failed to subscribe conversation shards", zap.String("user_id", userID), zap.Error(err))
+		}
+	}
+	// A failed registration heals with the next presence refresh
+	presenceCtx, cancel := context.WithTimeout(r.Context(), presenceWait)
+	if err := presence.Register(presenceCtx, userID); err != nil {
+		logger.Warn("Failed to register presence", zap.String("user_id", userID), zap.Error(err))
+	}
+	cancel()
+
 	// Send welcome or reconnected event
 	if err := sendConnectionEvent(client, userID, result); err != nil {
 		log.Printf("Failed to send connection event to %s: %v", userID, err)
 		// Continue anyway - client can still receive messages
 	}
+	if unreadCounter != nil {
+		if err := sendUnreadSnapshot(r.Context(), client, userID); err != nil {
+			logger.Warn("Failed to send unread snapshot", zap.String("user_id", userID), zap.Error(err))
+		}
+	}
 
 	if result.IsReconnect {
 		metrics.IncReconnections()
-		log.Printf("Client reconnected: %s (gap: %v, active: %d)",
-			userID,
-			client.ConnectedAt.Sub(result.PreviousConnectedAt),
-			connManager.Count())
+		logger.Info("Client reconnected", append(client.LogFields(userID),
+			zap.Duration("gap", client.ConnectedAt.Sub(result.PreviousConnectedAt)),
+			zap.Int("active", connManager.Count()),
+		)...)
 	} else {
-		log.Printf("Client connected: %s (active: %d)", userID, connManager.Count())
+		logger.Info("Client connected", append(client.LogFields(userID),
+			zap.Int("active", connManager.Count()),
+		)...)
 	}
 
 	// Track goroutines for graceful shutdown
@@ -93,18 +157,32 @@ func serveWs(w http.ResponseWriter, r *http.Request) {
 	readPump(userID, client)
 }
 
+// authenticate resolves the user of a /ws upgrade request
+// jwt mode verifies the access token itself; header mode trusts the API Gateway
+func authenticate(r *http.Request) (string, error) {
+	if jwtVerifier != nil {
+		return auth.ExtractUserIDFromJWT(r, jwtVerifier)
+	}
+	// Extract user_id from request (header first, then query param for browser WebSocket)
+	return auth.ExtractUserIDFromRequest(r)
+}
+
 func readPump(userID string, client *ws.Client) {
 	defer func() {
-		client.DoneGoroutine()
 		connManager.Remove(userID, client)
-		metrics.ConnectionClosed()
-		log.Printf("Client disconnected: %s (active: %d)", userID, connManager.Count())
+		releaseClient(userID, client)
+		logger.Info("Client disconnected", append(client.LogFields(userID),
+			zap.Duration("duration", time.Since(client.ConnectedAt)),
+			zap.Int("active", connManager.Count()),
+		)...)
+		// Last, so the reaper never sees exited pumps of a client that is still being cleaned up
+		client.DoneGoroutine()
 	}()
 
 	conn := client.Conn
-	conn.SetReadLimit(4096) // Max message size
 	_ = conn.SetReadDeadline(time.Now().Add(pongWait))
 	conn.SetPongHandler(func(string) error {
+		client.Touch()
 		_ = conn.SetReadDeadline(time.Now().Add(pongWait))
 		return nil
 	})
@@ -120,19 +198,48 @@ func readPump(userID string, client *ws.Client) {
 		default:
 		}
 
-		_, p, err := conn.ReadMessage()
+		p, err := ws.ReadMessage(conn, readLimit)
 		if err != nil {
+			if errors.Is(err, ws.ErrMessageTooLarge) {
+				log.Printf("Closing %s: message exceeds read limit of %d bytes", userID, readLimit)
+				return
+			}
 			if websocket.IsUnexpectedCloseError(err, websocket.CloseGoingAway, websocket.CloseAbnormalClosure) {
 				log.Printf("Read error for %s: %v", userID, err)
 			}
 			return
 		}
-		log.Printf("Received message from %s: %s", userID, string(p))
-		// Messages from client can be processed here if needed
-		// For chat, clients typically don't send messages via WebSocket (they use gRPC)
+		client.Touch()
+		if p, err = ws.DecodeFrame(client.Encoding, p); err != nil {
+			logger.Debug("Ignoring undecodable client frame", zap.String("user_id", userID), zap.String("encoding", client.Encoding), zap.Error(err))
+			continue
+		}
+		// Clients send chat messages through the API; the WebSocket only carries typing frames
+		router.HandleClientMessage(userID, p)
 	}
 }
 
+// releaseClient undoes the per-connection bookkeeping of serveWs once per client,
+// whether the read pump or the reaper removed it
+func releaseClient(userID string, client *ws.Client) {
+	if !client.Release() {
+		return
+	}
+	if shardedSubscriber != nil {
+		shardedSubscriber.RemoveUser(userID)
+	}
+	// A newer connection of the same user keeps its typing sessions and presence
+	if _, connected := connManager.Get(userID); !connected {
+		router.StopTyping(userID)
+		ctx, cancel := context.WithTimeout(context.Background(), presenceWait)
+		if err := presence.Unregister(ctx, userID); err != nil {
+			logger.Warn("Failed to unregister presence", zap.String("user_id", userID), zap.Error(err))
+		}
+		cancel()
+	}
+	metrics.ConnectionClosed()
+}
+
 func writePump(userID string, client *ws.Client) {
 	ticker := time.NewTicker(pingPeriod)
 	conn := client.Conn
@@ -148,11 +255,10 @@ func writePump(userID string, client *ws.Client) {
 	for {
 		select {
 		case <-ctx.Done():
-			// Graceful shutdown - send close message before exiting
+			// Graceful shutdown - send close message before exiting; a drained client gets its reconnect delay again
 			log.Printf("WritePump context cancelled for %s", userID)
 			_ = conn.SetWriteDeadline(time.Now().Add(writeWait))
-			_ = conn.WriteMessage(websocket.CloseMessage,
-				websocket.FormatCloseMessage(websocket.CloseGoingAway, "connection closed"))
+			_ = conn.WriteMessage(websocket.CloseMessage, ws.GoingAwayMessage(client.ReconnectAfter()))
 			return
 
 		case message, ok := <-client.Send:
@@ -163,7 +269,12 @@ func writePump(userID string, client *ws.Client) {
 				return
 			}
 
-			if err := conn.WriteMessage(websocket.TextMessage, message); err != nil {
+			messageType, frame, err := ws.EncodeFrame(client.Encoding, message)
+			if err != nil {
+				logger.Error("Failed to encode frame", zap.String("user_id", userID), zap.String("encoding", client.Encoding), zap.Error(err))
+				continue
+			}
+			if err := conn.WriteMessage(messageType, frame); err != nil {
 				log.Printf("Write error for %s: %v", userID, err)
 				return
 			}
@@ -189,6 +300,62 @@ func main() {
 		_ = logger.Sync()
 	}()
 
+	// One instance ID for consumer groups, presence, metrics and logs; fixed before anything reads it
+	instanceStrategy := getEnv("WS_INSTANCE_ID_STRATEGY", ws.InstanceIDAuto)
+	instanceID, err := ws.ConfigureInstanceID(instanceStrategy)
+	if err != nil {
+		logger.Fatal("Invalid instance ID configuration", zap.String("strategy", instanceStrategy), zap.Error(err))
+	}
+	logger = logger.With(zap.String("instance_id", instanceID))
+
+	// header (default): only safe strictly behind the API Gateway, which validates the JWT and sets X-User-Id
+	// jwt: validate the access token here, for deployments where clients can reach the ws-gateway directly
+	switch authMode := getEnv("WS_AUTH_MODE", "header"); authMode {
+	case "jwt":
+		secret := getEnv("ACCESS_TOKEN_SECRET", "")
+		if secret == "" {
+			logger.Fatal("ACCESS_TOKEN_SECRET is required when WS_AUTH_MODE=jwt")
+		}
+		jwtVerifier = auth.NewJWTVerifier(secret)
+		logger.Info("WebSocket auth: verifying JWT access tokens")
+	case "header":
+		logger.Info("WebSocket auth: trusting X-User-Id from API Gateway")
+	default:
+		logger.Fatal("Invalid WS_AUTH_MODE (expected header or jwt)", zap.String("mode", authMode))
+	}
+
+	if value := getEnv("WS_SEND_BUFFER_SIZE", ""); value != "" {
+		size, err := strconv.Atoi(value)
+		if err != nil || size <= 0 {
+			logger.Fatal("Invalid WS_SEND_BUFFER_SIZE (expected a positive integer)", zap.String("value", value))
+		}
+		sendBufferSize = size
+	}
+	logger.Info("WebSocket send buffer", zap.Int("messages_per_connection", sendBufferSize))
+
+	// Frames must fit any content the API accepts, or clients get disconnected for valid messages
+	contentLimit := getEnvInt("MAX_CONTENT_BYTES", config.DefaultMaxContentBytes)
+	if contentLimit == 0 {
+		contentLimit = config.DefaultMaxContentBytes
+	}
+	readLimit = ws.ReadLimitForContent(contentLimit)
+	if limit := getEnvInt("WS_READ_LIMIT_BYTES", 0); limit > 0 {
+		if int64(limit) < readLimit {
+			logger.Warn("WS_READ_LIMIT_BYTES is below what MAX_CONTENT_BYTES requires; large messages will close the connection",
+				zap.Int("read_limit", limit),
+				zap.Int64("required", readLimit),
+			)
+		}
+		readLimit = int64(limit)
+	}
+	logger.Info("WebSocket read limit", zap.Int64("bytes", readLimit), zap.Int("max_content_bytes", contentLimit))
+
+	// Client addresses: X-Forwarded-For is only read from these peers (load balancer, API Gateway)
+	trustedProxies, err = ws.ParseTrustedProxies(getEnv("WS_TRUSTED_PROXIES", ""))
+	if err != nil {
+		logger.Fatal("Invalid WS_TRUSTED_PROXIES", zap.Error(err))
+	}
+
 	// Initialize Redis client
 	redisAddr := getEnv("REDIS_ADDR", "localhost:6379")
 	redisClient := redis.NewClient(&redis.Options{
@@ -204,32 +371,183 @@ func main() {
 	}
 	logger.Info("Connected to Redis", zap.String("addr", redisAddr))
 
+	// Presence: which instances hold each user's connections, renewed so entries of a crashed gateway expire
+	presenceInterval := time.Duration(getEnvInt("WS_PRESENCE_REFRESH_INTERVAL_MS", int(ws.DefaultPresenceRefreshInterval.Milliseconds()))) * time.Millisecond
+	configuredTTL := time.Duration(getEnvInt("WS_PRESENCE_TTL_MS", 0)) * time.Millisecond
+	presenceTTL := ws.PresenceTTL(presenceInterval, configuredTTL)
+	if configuredTTL > 0 && presenceTTL != configuredTTL {
+		logger.Warn("WS_PRESENCE_TTL_MS must exceed the refresh interval, using the default",
+			zap.Duration("configured", configuredTTL), zap.Duration("ttl", presenceTTL))
+	}
+	presence = ws.NewPresenceRegistry(redisClient, logger, instanceID, presenceTTL)
+	go presence.Run(ctx, connManager, presenceInterval)
+	logger.Info("Presence heartbeat enabled", zap.Duration("interval", presenceInterval), zap.Duration("ttl", presenceTTL))
+
 	// Initialize metrics
 	metrics = ws.DefaultMetrics()
+	metrics.SetInstanceID(instanceID)
 
 	// Initialize message router with metrics
 	router = ws.NewRouter(connManager, logger, metrics)
 
-	// Initialize and start Redis Pub/Sub subscriber
-	subscriber = ws.NewSubscriber(redisClient, logger, router.HandleEvent)
+	// Optionally echo message.sent back to the sender's connection
+	if getEnv("WS_ECHO_TO_SENDER", "false") == "true" {
+		router.SetEchoToSender(true)
+		logger.Info("Sender echo enabled")
+	}
+
+	// Optional chat database: completes slim (oversized) message events and backs EVENT_SHARDS
+	var dbPool *pgxpool.Pool
+	if dbSource := getEnv("DB_SOURCE", ""); dbSource != "" {
+		pool, err := pgxpool.New(ctx, dbSource)
+		if err != nil {
+			logger.Fatal("Failed to connect to database", zap.Error(err))
+		}
+		defer pool.Close()
+		dbPool = pool
+		router.SetMessageLoader(ws.NewDBMessageLoader(dbPool))
+		logger.Info("Slim message events are completed from the database")
+
+		// Unread counts of the first conversations are pushed on connect; 0 turns the snapshot off
+		if unreadSnapshotSize = getEnvInt("WS_UNREAD_SNAPSHOT_LIMIT", ws.DefaultUnreadSnapshotLimit); unreadSnapshotSize > 0 {
+			unreadCounter = ws.NewDBUnreadCounter(dbPool)
+			logger.Info("Unread snapshot enabled", zap.Int("limit", unreadSnapshotSize))
+		}
+	}
+
+	// Typing indicators expire when a client stops refreshing them without sending typing_stopped.
+	// Typists and their receivers are checked against the conversation, so they need the chat database.
+	var typingRelay *ws.TypingRelay
+	if timeoutMs := getEnvInt("WS_TYPING_TIMEOUT_MS", int(ws.DefaultTypingTimeout.Milliseconds())); timeoutMs > 0 && dbPool == nil {
+		logger.Warn("Typing indicators are disabled: they require DB_SOURCE")
+	} else if timeoutMs > 0 {
+		tracker := ws.NewTypingTracker(time.Duration(timeoutMs) * time.Millisecond)
+		startsPerMinute := getEnvInt("WS_TYPING_STARTS_PER_MINUTE", ws.DefaultTypingStartsPerMinute)
+		tracker.SetStartLimit(startsPerMinute)
+		router.SetTypingTracker(tracker)
+		router.SetParticipantLister(ws.NewDBConversationLister(dbPool))
+		go router.RunTypingExpiry(ctx)
+
+		// Typing events reach receivers on other gateways over their own Pub/Sub channel, never the outbox
+		typingRelay = ws.NewTypingRelay(redisClient, logger, instanceID)
+		router.SetTypingRelay(typingRelay)
+		if err := router.StartTypingRelay(ctx); err != nil {
+			logger.Fatal("Failed to start typing relay", zap.Error(err))
+		}
+		logger.Info("Typing indicators enabled", zap.Int("timeout_ms", timeoutMs), zap.Int("starts_per_minute", startsPerMinute))
+	}
+
+	// Events are routed by a fixed pool of workers with bounded queues: a burst pushes back on
+	// the subscriber (and is dropped after WS_DISPATCH_BLOCK_TIMEOUT_MS) instead of piling up
+	dispatcher := ws.NewEventDispatcher(router.HandleEvent, logger,
+		getEnvInt("WS_DISPATCH_WORKERS", ws.DefaultDispatchWorkers),
+		getEnvInt("WS_DISPATCH_QUEUE_SIZE", ws.DefaultDispatchQueueSize),
+		time.Duration(getEnvInt("WS_DISPATCH_BLOCK_TIMEOUT_MS", int(ws.DefaultDispatchBlockTimeout.Milliseconds())))*time.Millisecond)
+	dispatcher.SetMetrics(metrics)
+	dispatcher.Start(ctx)
+
+	// Initialize and start the event subscriber (must match the outbox EVENT_TRANSPORT)
+	switch transport := getEnv("EVENT_TRANSPORT", "pubsub"); transport {
+	case "pubsub":
+		if shards := getEnvInt("EVENT_SHARDS", 0); shards > 0 {
+			// Shard subscriptions follow the conversations of connected users, read from the chat database
+			if dbPool == nil {
+				logger.Fatal("DB_SOURCE is required when EVENT_SHARDS is set")
+			}
+			shardedSubscriber = ws.NewShardedSubscriber(redisClient, logger, dispatcher.Handle, ws.NewDBConversationLister(dbPool), shards)
+			subscriber = shardedSubscriber
+			logger.Info("Sharded Pub/Sub enabled", zap.Int("shards", shards))
+			break
+		}
+		pubsub := ws.NewSubscriber(redisClient, logger, dispatcher.Handle)
+		pubsub.SetMetrics(metrics)
+		subscriber = pubsub
+	case "stream":
+		// The consumer group is named after the instance ID; a random one per start
+		// would begin at the stream tail and miss what was published during the restart
+		if instanceStrategy == ws.InstanceIDUUID || (instanceStrategy == ws.InstanceIDAuto && os.Getenv("WS_GATEWAY_INSTANCE_ID") == "") {
+			logger.Warn("EVENT_TRANSPORT=stream without a stable instance ID: events published during a restart will not be resumed")
+		}
+		// Entries are acknowledged once routed, so events the dispatcher drops are redelivered on restart
+		streamSubscriber := ws.NewStreamSubscriber(redisClient, logger, nil, instanceID)
+		streamSubscriber.SetAckHandler(dispatcher.HandleAck)
+		subscriber = streamSubscriber
+	default:
+		logger.Fatal("Invalid EVENT_TRANSPORT (expected pubsub or stream)", zap.String("transport", transport))
+	}
 	if err := subscriber.Start(ctx); err != nil {
 		logger.Fatal("Failed to start subscriber", zap.Error(err))
 	}
 
+	// Reap connections left registered after their pumps died, so they don't leak over long uptimes
+	if interval := getEnvInt("WS_REAPER_INTERVAL_MS", int(ws.DefaultReaperInterval.Milliseconds())); interval > 0 {
+		idleTimeout := getEnvInt("WS_REAPER_IDLE_TIMEOUT_MS", int(ws.DefaultReaperIdleTimeout.Milliseconds()))
+		reaper := ws.NewReaper(connManager, logger,
+			time.Duration(interval)*time.Millisecond,
+			time.Duration(idleTimeout)*time.Millisecond)
+		reaper.SetMetrics(metrics)
+		reaper.SetOnReap(releaseClient)
+		go reaper.Run(ctx)
+		logger.Info("Connection reaper enabled", zap.Int("interval_ms", interval), zap.Int("idle_timeout_ms", idleTimeout))
+	}
+
+	// /ws runs under its own handshake deadline: the server timeouts below are for short HTTP requests
+	handshakeTimeout := time.Duration(getEnvInt("WS_HANDSHAKE_TIMEOUT_MS", int(ws.DefaultHandshakeTimeout.Milliseconds()))) * time.Millisecond
+	upgrader.HandshakeTimeout = handshakeTimeout
+
+	// Spread reconnect storms: drained clients and rejected accepts are told to come back within this window
+	reconnectMin := getEnvInt("WS_RECONNECT_MIN_MS", int(ws.DefaultReconnectMin.Milliseconds()))
+	reconnectMax := getEnvInt("WS_RECONNECT_MAX_MS", int(ws.DefaultReconnectMax.Milliseconds()))
+	reconnectJitter = ws.NewReconnectJitter(time.Duration(reconnectMin)*time.Millisecond, time.Duration(reconnectMax)*time.Millisecond)
+
+	// New connections beyond WS_ACCEPT_RATE per second get 503 before authentication; 0 accepts at any rate
+	var acceptLimiter *ws.AcceptLimiter
+	if rate := getEnvInt("WS_ACCEPT_RATE", 0); rate > 0 {
+		burst := getEnvInt("WS_ACCEPT_BURST", rate)
+		acceptLimiter = ws.NewAcceptLimiter(float64(rate), burst)
+		logger.Info("Accept rate limit enabled", zap.Int("rate", rate), zap.Int("burst", burst))
+	}
+	acceptGate := ws.NewAcceptGate(acceptLimiter, reconnectJitter)
+	acceptGate.SetMetrics(metrics)
+	drainGrace := time.Duration(getEnvInt("WS_DRAIN_GRACE_MS", int(ws.DefaultDrainGrace.Milliseconds()))) * time.Millisecond
+
 	mux := http.NewServeMux()
-	mux.HandleFunc("/ws", serveWs)
+	mux.Handle("/ws", acceptGate.Wrap(ws.WithoutServerTimeouts(http.HandlerFunc(serveWs), handshakeTimeout)))
 	mux.HandleFunc("/health", func(w http.ResponseWriter, r *http.Request) {
 		w.WriteHeader(http.StatusOK)
 		_, _ = w.Write([]byte("OK"))
 	})
+
+	// Readiness fails when Redis is unreachable or the event subscriber has stopped:
+	// the gateway would accept connections but never deliver events
+	healthHandler := health.NewHandler(logger, health.DefaultTimeout)
+	healthHandler.AddCheck("redis", func(ctx context.Context) error {
+		return redisClient.Ping(ctx).Err()
+	})
+	healthHandler.AddCheck("subscriber", func(context.Context) error {
+		if !subscriber.IsRunning() {
+			return health.ErrNotRunning
+		}
+		return nil
+	})
+	mux.HandleFunc("/healthz", healthHandler.Liveness)
+	mux.HandleFunc("/readyz", healthHandler.Readiness)
 	mux.Handle("/metrics", promhttp.Handler())
 
+	// Admin connection dump (client addresses included): only with admin access tokens
+	if secret := getEnv("ACCESS_TOKEN_SECRET", ""); secret != "" {
+		mux.HandleFunc("/admin/connections", ws.NewConnectionDumpHandler(connManager, auth.NewJWTVerifier(secret)))
+		mux.HandleFunc("/internal/presence", ws.NewPresenceHandler(presence, auth.NewJWTVerifier(secret)))
+	}
+
 	addr := getEnv("WS_GATEWAY_ADDR", ":8080")
+	readTimeout := getEnvInt("WS_HTTP_READ_TIMEOUT_MS", int(ws.DefaultHTTPReadTimeout.Milliseconds()))
+	writeTimeout := getEnvInt("WS_HTTP_WRITE_TIMEOUT_MS", int(ws.DefaultHTTPWriteTimeout.Milliseconds()))
 	server := &http.Server{
 		Addr:         addr,
 		Handler:      mux,
-		ReadTimeout:  10 * time.Second,
-		WriteTimeout: 10 * time.Second,
+		ReadTimeout:  time.Duration(readTimeout) * time.Millisecond,
+		WriteTimeout: time.Duration(writeTimeout) * time.Millisecond,
 	}
 
 	// Graceful shutdown
@@ -239,6 +557,8 @@ func main() {
 		<-sigChan
 
 		logger.Info("Shutting down WebSocket Gateway...")
+		healthHandler.SetShuttingDown()
+		acceptGate.SetDraining()
 		shutdownCtx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
 		defer cancel()
 
@@ -246,17 +566,35 @@ func main() {
 		if subscriber != nil {
 			_ = subscriber.Stop()
 		}
+		if typingRelay != nil {
+			_ = typingRelay.Stop()
+		}
 
-		// Gracefully close all connections and wait for goroutines to exit
+		// Advise every client to reconnect after its own jittered delay, so they don't all come back at once
 		clients := connManager.GetAllClients()
-		logger.Info("Closing client connections", zap.Int("count", len(clients)))
+		advised := 0
+		for _, devices := range clients {
+			for _, client := range devices {
+				if ws.AdviseDrain(client, reconnectJitter.Next()) {
+					advised++
+				}
+			}
+		}
+		logger.Info("Drain advisory sent", zap.Int("clients", advised), zap.Duration("grace", drainGrace))
+		select {
+		case <-time.After(drainGrace):
+		case <-shutdownCtx.Done():
+		}
+
+		// Gracefully close all connections and wait for goroutines to exit
+		logger.Info("Closing client connections", zap.Int("count", connManager.Count()))
 
 		// Use a channel to track completion with timeout
 		done := make(chan struct{})
 		go func() {
-			for userID, client := range clients {
-				logger.Debug("Closing connection", zap.String("user_id", userID))
-				connManager.RemoveAndWait(userID, client)
+			for userID := range clients {
+				logger.Debug("Closing connections", zap.String("user_id", userID))
+				connManager.RemoveAndWait(userID, nil)
 			}
 			close(done)
 		}()
@@ -277,10 +615,12 @@ func main() {
 		}
 	}()
 
-	instanceID := ws.GetInstanceID()
 	logger.Info("WebSocket Gateway starting",
 		zap.String("addr", addr),
-		zap.String("instance_id", instanceID),
+		zap.String("instance_id_strategy", instanceStrategy),
+		zap.Int("http_read_timeout_ms", readTimeout),
+		zap.Int("http_write_timeout_ms", writeTimeout),
+		zap.Duration("handshake_timeout", handshakeTimeout),
 	)
 	if err := server.ListenAndServe(); err != http.ErrServerClosed {
 		logger.Fatal("ListenAndServe failed", zap.Error(err))
@@ -297,6 +637,19 @@ func getEnv(key, defaultValue string) string {
 	return defaultValue
 }
 
+// getEnvInt reads a non-negative integer setting, exiting on invalid values
+func getEnvInt(key string, defaultValue int) int {
+	value := getEnv(key, "")
+	if value == "" {
+		return defaultValue
+	}
+	n, err := strconv.Atoi(value)
+	if err != nil || n < 0 {
+		logger.Fatal("Invalid "+key+" (expected a non-negative integer)", zap.String("value", value))
+	}
+	return n
+}
+
 // sendConnectionEvent sends welcome or reconnected event to the client.
 func sendConnectionEvent(client *ws.Client, userID string, result ws.AddResult) error {
 	var event interface{}
@@ -307,12 +660,32 @@ func sendConnectionEvent(client *ws.Client, userID string, result ws.AddResult)
 		event = ws.NewWelcomeEvent(userID)
 	}
 
+	// Send directly to connection (not through channel, as writePump may not be started yet)
+	return writeEvent(client, event)
+}
+
+// sendUnreadSnapshot sends the unread counts of the user's first conversations after the connection event.
+// Like that event it is written directly, before the pumps start; events routed meanwhile queue behind it.
+func sendUnreadSnapshot(ctx context.Context, client *ws.Client, userID string) error {
+	event, err := ws.BuildUnreadSnapshot(ctx, unreadCounter, userID, unreadSnapshotSize)
+	if err != nil {
+		return err
+	}
+
+	return writeEvent(client, event)
+}
+
+// writeEvent writes event straight to the connection in the client's encoding
+func writeEvent(client *ws.Client, event interface{}) error {
 	data, err := json.Marshal(event)
 	if err != nil {
 		return err
 	}
+	messageType, frame, err := ws.EncodeFrame(client.Encoding, data)
+	if err != nil {
+		return err
+	}
 
-	// Send directly to connection (not through channel, as writePump may not be started yet)
 	_ = client.Conn.SetWriteDeadline(time.Now().Add(writeWait))
-	return client.Conn.WriteMessage(websocket.TextMessage, data)
+	return client.Conn.WriteMessage(messageType, frame)
 }
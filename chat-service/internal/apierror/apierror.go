@@ -0,0 +1,108 @@
+// Package apierror builds gRPC statuses with machine-readable details so HTTP clients
+// can branch on a stable error code instead of parsing messages.
+package apierror
+
+import (
+	"strings"
+	"unicode"
+
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/protoadapt"
+)
+
+// Domain is set on every ErrorInfo detail emitted by this service
+const Domain = "chat-service"
+
+// Documented error codes, returned as "code" in gateway error bodies.
+// Statuses without an ErrorInfo detail get the UPPER_SNAKE_CASE gRPC code name (e.g. INTERNAL).
+const (
+	CodeValidationFailed = "VALIDATION_FAILED"
+	CodeDuplicateRequest = "DUPLICATE_REQUEST"
+	CodeUnauthenticated  = "UNAUTHENTICATED"
+	CodePermissionDenied = "PERMISSION_DENIED"
+	CodeNotFound         = "NOT_FOUND"
+	// CodeAttachmentNotReady: an attachment cannot be accepted yet (e.g. its malware scan is pending); retry later
+	CodeAttachmentNotReady = "ATTACHMENT_NOT_READY"
+	// CodeGroupTooLarge: the conversation would exceed the configured maximum number of participants
+	CodeGroupTooLarge = "GROUP_TOO_LARGE"
+)
+
+// New returns a status error with an ErrorInfo detail carrying reason and metadata
+func New(code codes.Code, reason, message string, metadata map[string]string) error {
+	return withDetails(status.New(code, message), &errdetails.ErrorInfo{
+		Reason:   reason,
+		Domain:   Domain,
+		Metadata: metadata,
+	})
+}
+
+// Validation returns an InvalidArgument error for a single invalid request field
+// The field violation is omitted when field is empty (the request as a whole is invalid)
+func Validation(field, message string) error {
+	details := []protoadapt.MessageV1{
+		&errdetails.ErrorInfo{
+			Reason: CodeValidationFailed,
+			Domain: Domain,
+		},
+	}
+	if field != "" {
+		details = append(details, &errdetails.BadRequest{
+			FieldViolations: []*errdetails.BadRequest_FieldViolation{
+				{Field: field, Description: message},
+			},
+		})
+	}
+	return withDetails(status.New(codes.InvalidArgument, message), details...)
+}
+
+// Duplicate returns an AlreadyExists error for a replayed idempotency key
+func Duplicate(message, idempotencyKey string) error {
+	return New(codes.AlreadyExists, CodeDuplicateRequest, message, map[string]string{
+		"idempotency_key": idempotencyKey,
+	})
+}
+
+// Code returns the documented error code of st: the ErrorInfo reason when present,
+// otherwise derived from the gRPC code
+func Code(st *status.Status) string {
+	for _, detail := range st.Details() {
+		if info, ok := detail.(*errdetails.ErrorInfo); ok && info.Reason != "" {
+			return info.Reason
+		}
+	}
+
+	switch st.Code() {
+	case codes.InvalidArgument:
+		return CodeValidationFailed
+	case codes.Unauthenticated:
+		return CodeUnauthenticated
+	case codes.PermissionDenied:
+		return CodePermissionDenied
+	case codes.NotFound:
+		return CodeNotFound
+	}
+	return upperSnake(st.Code().String())
+}
+
+func withDetails(st *status.Status, details ...protoadapt.MessageV1) error {
+	detailed, err := st.WithDetails(details...)
+	if err != nil {
+		// Only fails for codes.OK, which is never used here; keep the bare status
+		return st.Err()
+	}
+	return detailed.Err()
+}
+
+// upperSnake converts a gRPC code name (e.g. "FailedPrecondition") to FAILED_PRECONDITION
+func upperSnake(name string) string {
+	var b strings.Builder
+	for i, r := range name {
+		if i > 0 && unicode.IsUpper(r) {
+			b.WriteByte('_')
+		}
+		b.WriteRune(unicode.ToUpper(r))
+	}
+	return b.String()
+}
@@ -0,0 +1,67 @@
+package apierror
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func TestValidation(t *testing.T) {
+	st := status.Convert(Validation("conversation_id", "conversation_id is required"))
+
+	assert.Equal(t, codes.InvalidArgument, st.Code())
+	assert.Equal(t, "conversation_id is required", st.Message())
+	assert.Equal(t, CodeValidationFailed, Code(st))
+
+	details := st.Details()
+	require.Len(t, details, 2)
+	badRequest, ok := details[1].(*errdetails.BadRequest)
+	require.True(t, ok)
+	require.Len(t, badRequest.FieldViolations, 1)
+	assert.Equal(t, "conversation_id", badRequest.FieldViolations[0].Field)
+}
+
+func TestValidation_NoField(t *testing.T) {
+	st := status.Convert(Validation("", "invalid request"))
+
+	assert.Len(t, st.Details(), 1)
+	assert.Equal(t, CodeValidationFailed, Code(st))
+}
+
+func TestDuplicate(t *testing.T) {
+	st := status.Convert(Duplicate("duplicate request", "key-1"))
+
+	assert.Equal(t, codes.AlreadyExists, st.Code())
+	assert.Equal(t, CodeDuplicateRequest, Code(st))
+
+	info, ok := st.Details()[0].(*errdetails.ErrorInfo)
+	require.True(t, ok)
+	assert.Equal(t, Domain, info.Domain)
+	assert.Equal(t, "key-1", info.Metadata["idempotency_key"])
+}
+
+func TestCode_DerivedFromGRPCCode(t *testing.T) {
+	tests := []struct {
+		code codes.Code
+		want string
+	}{
+		{codes.InvalidArgument, CodeValidationFailed},
+		{codes.Unauthenticated, CodeUnauthenticated},
+		{codes.PermissionDenied, CodePermissionDenied},
+		{codes.NotFound, CodeNotFound},
+		{codes.AlreadyExists, "ALREADY_EXISTS"},
+		{codes.FailedPrecondition, "FAILED_PRECONDITION"},
+		{codes.Internal, "INTERNAL"},
+		{codes.DeadlineExceeded, "DEADLINE_EXCEEDED"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.code.String(), func(t *testing.T) {
+			assert.Equal(t, tt.want, Code(status.New(tt.code, "boom")))
+		})
+	}
+}
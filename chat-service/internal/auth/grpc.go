@@ -2,6 +2,7 @@ package auth
 
 import (
 	"context"
+	"errors"
 
 	ctxkeys "chat-service/internal/context"
 
@@ -12,24 +13,110 @@ import (
 	"google.golang.org/grpc/status"
 )
 
+// Option configures how callers are authenticated
+type Option func(*options)
+
+type options struct {
+	verifier *JWTVerifier
+}
+
+// WithJWTVerifier enables reading user_id and roles from a verified "authorization: Bearer" token.
+// Without it (or without the header) only x-user-id from the API Gateway is used and no roles are granted.
+func WithJWTVerifier(verifier *JWTVerifier) Option {
+	return func(o *options) {
+		o.verifier = verifier
+	}
+}
+
+// Identity is the authenticated caller
+type Identity struct {
+	UserID string
+	Roles  []string
+}
+
+// IdentityFromMetadata resolves the caller from request metadata.
+// A bearer token (when a verifier is configured) takes precedence over x-user-id and is the only source of roles;
+// an invalid token is rejected rather than falling back to the header.
+func IdentityFromMetadata(md metadata.MD, opts ...Option) (*Identity, error) {
+	var o options
+	for _, opt := range opts {
+		opt(&o)
+	}
+
+	if o.verifier != nil {
+		if values := md.Get("authorization"); len(values) > 0 && values[0] != "" {
+			claims, err := o.verifier.VerifyAuthorization(values[0])
+			if err != nil {
+				return nil, err
+			}
+			return &Identity{UserID: claims.UserID(), Roles: claims.AllRoles()}, nil
+		}
+	}
+
+	// Get user_id from x-user-id header (set by API Gateway after JWT validation)
+	userIDs := md.Get("x-user-id")
+	if len(userIDs) == 0 || userIDs[0] == "" {
+		return nil, ErrMissingUserID
+	}
+	return &Identity{UserID: userIDs[0]}, nil
+}
+
 // GrpcAuthInterceptor extracts user_id from x-user-id header (set by API Gateway)
-// and injects it into the gRPC context.
-func GrpcAuthInterceptor(logger *zap.Logger) grpc.UnaryServerInterceptor {
+// and injects it into the gRPC context. With WithJWTVerifier, roles/scopes from the
+// verified token are injected as well (see RequireRole).
+func GrpcAuthInterceptor(logger *zap.Logger, opts ...Option) grpc.UnaryServerInterceptor {
 	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
-		md, ok := metadata.FromIncomingContext(ctx)
-		if !ok {
-			logger.Warn("no metadata in context", zap.String("method", info.FullMethod))
-			return nil, status.Error(codes.Unauthenticated, "missing metadata")
+		ctx, err := authenticate(ctx, logger, info.FullMethod, opts...)
+		if err != nil {
+			return nil, err
 		}
+		return handler(ctx, req)
+	}
+}
+
+// GrpcAuthStreamInterceptor is the streaming counterpart of GrpcAuthInterceptor.
+// The caller is authenticated once when the stream opens.
+func GrpcAuthStreamInterceptor(logger *zap.Logger, opts ...Option) grpc.StreamServerInterceptor {
+	return func(srv interface{}, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		ctx, err := authenticate(ss.Context(), logger, info.FullMethod, opts...)
+		if err != nil {
+			return err
+		}
+		return handler(srv, &authenticatedStream{ServerStream: ss, ctx: ctx})
+	}
+}
 
-		// Get user_id from x-user-id header (set by API Gateway after JWT validation)
-		userIDs := md.Get("x-user-id")
-		if len(userIDs) == 0 || userIDs[0] == "" {
-			logger.Warn("missing x-user-id header", zap.String("method", info.FullMethod))
+// authenticate resolves the caller from incoming metadata and returns a context carrying the identity
+func authenticate(ctx context.Context, logger *zap.Logger, method string, opts ...Option) (context.Context, error) {
+	md, ok := metadata.FromIncomingContext(ctx)
+	if !ok {
+		logger.Warn("no metadata in context", zap.String("method", method))
+		return nil, status.Error(codes.Unauthenticated, "missing metadata")
+	}
+
+	identity, err := IdentityFromMetadata(md, opts...)
+	if err != nil {
+		if errors.Is(err, ErrMissingUserID) {
+			logger.Warn("missing x-user-id header", zap.String("method", method))
 			return nil, status.Error(codes.Unauthenticated, "missing user id")
 		}
+		logger.Warn("invalid bearer token", zap.String("method", method), zap.Error(err))
+		return nil, status.Error(codes.Unauthenticated, "invalid token")
+	}
 
-		ctx = context.WithValue(ctx, ctxkeys.UserIDKey, userIDs[0])
-		return handler(ctx, req)
+	ctx = context.WithValue(ctx, ctxkeys.UserIDKey, identity.UserID)
+	if len(identity.Roles) > 0 {
+		ctx = SetRolesInContext(ctx, identity.Roles)
 	}
+	return ctx, nil
+}
+
+// authenticatedStream overrides the stream context with the authenticated one
+type authenticatedStream struct {
+	grpc.ServerStream
+	ctx context.Context
+}
+
+func (s *authenticatedStream) Context() context.Context {
+	return s.ctx
 }
@@ -3,9 +3,11 @@ package auth
 import (
 	"context"
 	"testing"
+	"time"
 
 	ctxkeys "chat-service/internal/context"
 
+	"github.com/golang-jwt/jwt/v5"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 	"go.uber.org/zap"
@@ -166,3 +168,147 @@ func TestGrpcAuthInterceptor_MultipleUserIDs(t *testing.T) {
 	assert.True(t, handlerCalled)
 	assert.Equal(t, "success", resp)
 }
+
+func TestGrpcAuthInterceptor_JWTRoles(t *testing.T) {
+	logger, _ := zap.NewDevelopment()
+	interceptor := GrpcAuthInterceptor(logger, WithJWTVerifier(NewJWTVerifier(testJWTSecret)))
+
+	token := signTestToken(t, jwt.SigningMethodHS256, []byte(testJWTSecret), jwt.MapClaims{
+		"id":    "admin-1",
+		"role":  RoleAdmin,
+		"scope": "messages:delete " + RoleModerator,
+		"exp":   time.Now().Add(time.Hour).Unix(),
+	})
+	// x-user-id is ignored when a verified token is present
+	md := metadata.New(map[string]string{
+		"authorization": "Bearer " + token,
+		"x-user-id":     "someone-else",
+	})
+	ctx := metadata.NewIncomingContext(context.Background(), md)
+
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		userID, err := GetUserIDFromContext(ctx)
+		require.NoError(t, err)
+		assert.Equal(t, "admin-1", userID)
+		assert.ElementsMatch(t, []string{RoleAdmin, "messages:delete", RoleModerator}, GetRolesFromContext(ctx))
+		return "success", RequireRole(ctx, RoleAdmin)
+	}
+
+	resp, err := interceptor(ctx, nil, &grpc.UnaryServerInfo{FullMethod: "/test.Service/Method"}, handler)
+
+	require.NoError(t, err)
+	assert.Equal(t, "success", resp)
+}
+
+func TestGrpcAuthInterceptor_InvalidJWT(t *testing.T) {
+	logger, _ := zap.NewDevelopment()
+	interceptor := GrpcAuthInterceptor(logger, WithJWTVerifier(NewJWTVerifier(testJWTSecret)))
+
+	// An invalid token must not fall back to x-user-id
+	md := metadata.New(map[string]string{
+		"authorization": "Bearer not-a-jwt",
+		"x-user-id":     "user-1",
+	})
+	ctx := metadata.NewIncomingContext(context.Background(), md)
+
+	handlerCalled := false
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		handlerCalled = true
+		return nil, nil
+	}
+
+	_, err := interceptor(ctx, nil, &grpc.UnaryServerInfo{FullMethod: "/test.Service/Method"}, handler)
+
+	require.Error(t, err)
+	assert.False(t, handlerCalled)
+	assert.Equal(t, codes.Unauthenticated, status.Code(err))
+}
+
+func TestGrpcAuthInterceptor_HeaderModeGrantsNoRoles(t *testing.T) {
+	logger, _ := zap.NewDevelopment()
+	interceptor := GrpcAuthInterceptor(logger, WithJWTVerifier(NewJWTVerifier(testJWTSecret)))
+
+	md := metadata.New(map[string]string{
+		"x-user-id": "user-1",
+	})
+	ctx := metadata.NewIncomingContext(context.Background(), md)
+
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		assert.Empty(t, GetRolesFromContext(ctx))
+		return nil, RequireRole(ctx, RoleAdmin)
+	}
+
+	_, err := interceptor(ctx, nil, &grpc.UnaryServerInfo{FullMethod: "/test.Service/Method"}, handler)
+
+	assert.Equal(t, codes.PermissionDenied, status.Code(err))
+}
+
+func TestRequireRole(t *testing.T) {
+	withUser := SetUserIDInContext(context.Background(), "user-1")
+
+	tests := []struct {
+		name     string
+		ctx      context.Context
+		roles    []string
+		wantCode codes.Code
+	}{
+		{"no user", context.Background(), []string{RoleAdmin}, codes.Unauthenticated},
+		{"no roles", withUser, []string{RoleAdmin}, codes.PermissionDenied},
+		{"other role", SetRolesInContext(withUser, []string{"user"}), []string{RoleAdmin}, codes.PermissionDenied},
+		{"matching role", SetRolesInContext(withUser, []string{RoleModerator}), []string{RoleAdmin, RoleModerator}, codes.OK},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.wantCode, status.Code(RequireRole(tt.ctx, tt.roles...)))
+		})
+	}
+}
+
+// testServerStream is a grpc.ServerStream that only carries a context
+type testServerStream struct {
+	grpc.ServerStream
+	ctx context.Context
+}
+
+func (s *testServerStream) Context() context.Context {
+	return s.ctx
+}
+
+func TestGrpcAuthStreamInterceptor_Success(t *testing.T) {
+	interceptor := GrpcAuthStreamInterceptor(zap.NewNop())
+
+	md := metadata.New(map[string]string{"x-user-id": "test-user-123"})
+	stream := &testServerStream{ctx: metadata.NewIncomingContext(context.Background(), md)}
+
+	handlerCalled := false
+	handler := func(srv interface{}, ss grpc.ServerStream) error {
+		handlerCalled = true
+		userID, ok := ss.Context().Value(ctxkeys.UserIDKey).(string)
+		assert.True(t, ok, "user_id should be in stream context")
+		assert.Equal(t, "test-user-123", userID)
+		return nil
+	}
+
+	info := &grpc.StreamServerInfo{FullMethod: "/test.Service/Stream", IsServerStream: true}
+
+	require.NoError(t, interceptor(nil, stream, info, handler))
+	assert.True(t, handlerCalled, "handler should be called")
+}
+
+func TestGrpcAuthStreamInterceptor_MissingUserID(t *testing.T) {
+	interceptor := GrpcAuthStreamInterceptor(zap.NewNop())
+
+	stream := &testServerStream{ctx: metadata.NewIncomingContext(context.Background(), metadata.MD{})}
+
+	handler := func(srv interface{}, ss grpc.ServerStream) error {
+		t.Fatal("handler should not be called")
+		return nil
+	}
+
+	info := &grpc.StreamServerInfo{FullMethod: "/test.Service/Stream", IsServerStream: true}
+
+	err := interceptor(nil, stream, info, handler)
+	require.Error(t, err)
+	assert.Equal(t, codes.Unauthenticated, status.Code(err))
+}
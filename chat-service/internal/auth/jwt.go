@@ -0,0 +1,135 @@
+package auth
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+const (
+	// AuthorizationHeader carries "Bearer <token>"
+	AuthorizationHeader = "Authorization"
+	// TokenQueryParam is the query parameter name for the access token (fallback for WebSocket from browsers)
+	TokenQueryParam = "token"
+)
+
+var (
+	ErrMissingToken = errors.New("missing token")
+	ErrInvalidToken = errors.New("invalid token")
+)
+
+// Claims are the access token claims issued by the API Gateway (see backend-gateway generateJwtToken)
+// The user id lives in "id"; "sub" is accepted as a fallback for other issuers.
+type Claims struct {
+	ID    string   `json:"id"`
+	Email string   `json:"email,omitempty"`
+	Role  string   `json:"role,omitempty"`
+	Roles []string `json:"roles,omitempty"`
+	// Scope is a space-separated list (OAuth2 style)
+	Scope string `json:"scope,omitempty"`
+	jwt.RegisteredClaims
+}
+
+// AllRoles merges role, roles and scope into one de-duplicated list
+func (c *Claims) AllRoles() []string {
+	candidates := make([]string, 0, 1+len(c.Roles))
+	candidates = append(candidates, c.Role)
+	candidates = append(candidates, c.Roles...)
+	candidates = append(candidates, strings.Fields(c.Scope)...)
+
+	seen := make(map[string]bool, len(candidates))
+	roles := make([]string, 0, len(candidates))
+	for _, role := range candidates {
+		if role == "" || seen[role] {
+			continue
+		}
+		seen[role] = true
+		roles = append(roles, role)
+	}
+	return roles
+}
+
+// UserID returns the user id from the verified claims
+func (c *Claims) UserID() string {
+	if c.ID != "" {
+		return c.ID
+	}
+	return c.Subject
+}
+
+// JWTVerifier validates HS256 access tokens signed with the gateway's ACCESS_TOKEN_SECRET
+type JWTVerifier struct {
+	secret []byte
+	parser *jwt.Parser
+}
+
+// NewJWTVerifier creates a verifier for tokens signed with secret
+func NewJWTVerifier(secret string) *JWTVerifier {
+	return &JWTVerifier{
+		secret: []byte(secret),
+		parser: jwt.NewParser(
+			jwt.WithValidMethods([]string{jwt.SigningMethodHS256.Alg()}),
+			jwt.WithExpirationRequired(),
+		),
+	}
+}
+
+// Verify checks the signature and expiry of token and returns its claims
+func (v *JWTVerifier) Verify(token string) (*Claims, error) {
+	claims := &Claims{}
+	_, err := v.parser.ParseWithClaims(token, claims, func(*jwt.Token) (interface{}, error) {
+		return v.secret, nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidToken, err)
+	}
+	if claims.UserID() == "" {
+		return nil, fmt.Errorf("%w: missing user id claim", ErrInvalidToken)
+	}
+	return claims, nil
+}
+
+// VerifyAuthorization verifies an "Authorization: Bearer <token>" value
+func (v *JWTVerifier) VerifyAuthorization(authorization string) (*Claims, error) {
+	token, ok := strings.CutPrefix(strings.TrimSpace(authorization), "Bearer ")
+	if !ok || strings.TrimSpace(token) == "" {
+		return nil, ErrMissingToken
+	}
+	return v.Verify(strings.TrimSpace(token))
+}
+
+// ExtractToken extracts the access token from HTTP request.
+// Priority: 1) Authorization: Bearer header, 2) token query parameter
+// Browsers can't set headers on WebSocket upgrades, hence the query fallback.
+func ExtractToken(r *http.Request) (string, error) {
+	if header := r.Header.Get(AuthorizationHeader); header != "" {
+		token, ok := strings.CutPrefix(header, "Bearer ")
+		if ok && strings.TrimSpace(token) != "" {
+			return strings.TrimSpace(token), nil
+		}
+	}
+
+	if token := r.URL.Query().Get(TokenQueryParam); token != "" {
+		return token, nil
+	}
+
+	return "", ErrMissingToken
+}
+
+// ExtractUserIDFromJWT verifies the request's access token and returns the user id from its claims.
+// Unlike ExtractUserIDFromRequest, X-User-Id and user_id are ignored, so direct clients can't impersonate users.
+func ExtractUserIDFromJWT(r *http.Request, verifier *JWTVerifier) (string, error) {
+	token, err := ExtractToken(r)
+	if err != nil {
+		return "", err
+	}
+
+	claims, err := verifier.Verify(token)
+	if err != nil {
+		return "", err
+	}
+	return claims.UserID(), nil
+}
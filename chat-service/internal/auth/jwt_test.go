@@ -0,0 +1,120 @@
+package auth
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const testJWTSecret = "test-access-token-secret"
+
+func signTestToken(t *testing.T, method jwt.SigningMethod, secret interface{}, claims jwt.MapClaims) string {
+	t.Helper()
+	token, err := jwt.NewWithClaims(method, claims).SignedString(secret)
+	require.NoError(t, err)
+	return token
+}
+
+func validClaims(userID string) jwt.MapClaims {
+	return jwt.MapClaims{
+		"id":  userID,
+		"exp": time.Now().Add(time.Hour).Unix(),
+	}
+}
+
+func TestJWTVerifier_Valid(t *testing.T) {
+	token := signTestToken(t, jwt.SigningMethodHS256, []byte(testJWTSecret), validClaims("user-123"))
+
+	claims, err := NewJWTVerifier(testJWTSecret).Verify(token)
+
+	require.NoError(t, err)
+	assert.Equal(t, "user-123", claims.UserID())
+}
+
+func TestJWTVerifier_SubjectFallback(t *testing.T) {
+	token := signTestToken(t, jwt.SigningMethodHS256, []byte(testJWTSecret), jwt.MapClaims{
+		"sub": "user-sub",
+		"exp": time.Now().Add(time.Hour).Unix(),
+	})
+
+	claims, err := NewJWTVerifier(testJWTSecret).Verify(token)
+
+	require.NoError(t, err)
+	assert.Equal(t, "user-sub", claims.UserID())
+}
+
+func TestJWTVerifier_Rejects(t *testing.T) {
+	tests := []struct {
+		name  string
+		token string
+	}{
+		{"wrong secret", signTestToken(t, jwt.SigningMethodHS256, []byte("other-secret"), validClaims("user-123"))},
+		{"expired", signTestToken(t, jwt.SigningMethodHS256, []byte(testJWTSecret), jwt.MapClaims{
+			"id":  "user-123",
+			"exp": time.Now().Add(-time.Minute).Unix(),
+		})},
+		{"missing exp", signTestToken(t, jwt.SigningMethodHS256, []byte(testJWTSecret), jwt.MapClaims{"id": "user-123"})},
+		{"missing user id", signTestToken(t, jwt.SigningMethodHS256, []byte(testJWTSecret), jwt.MapClaims{
+			"exp": time.Now().Add(time.Hour).Unix(),
+		})},
+		{"alg none", signTestToken(t, jwt.SigningMethodNone, jwt.UnsafeAllowNoneSignatureType, validClaims("user-123"))},
+		{"garbage", "not-a-jwt"},
+	}
+
+	verifier := NewJWTVerifier(testJWTSecret)
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			claims, err := verifier.Verify(tt.token)
+
+			require.ErrorIs(t, err, ErrInvalidToken)
+			assert.Nil(t, claims)
+		})
+	}
+}
+
+func TestExtractUserIDFromJWT_BearerHeader(t *testing.T) {
+	token := signTestToken(t, jwt.SigningMethodHS256, []byte(testJWTSecret), validClaims("user-123"))
+	req := httptest.NewRequest(http.MethodGet, "/ws", nil)
+	req.Header.Set(AuthorizationHeader, "Bearer "+token)
+
+	userID, err := ExtractUserIDFromJWT(req, NewJWTVerifier(testJWTSecret))
+
+	require.NoError(t, err)
+	assert.Equal(t, "user-123", userID)
+}
+
+func TestExtractUserIDFromJWT_QueryParamFallback(t *testing.T) {
+	token := signTestToken(t, jwt.SigningMethodHS256, []byte(testJWTSecret), validClaims("user-123"))
+	req := httptest.NewRequest(http.MethodGet, "/ws?token="+token, nil)
+
+	userID, err := ExtractUserIDFromJWT(req, NewJWTVerifier(testJWTSecret))
+
+	require.NoError(t, err)
+	assert.Equal(t, "user-123", userID)
+}
+
+func TestExtractUserIDFromJWT_IgnoresUserIDHeader(t *testing.T) {
+	// In JWT mode a forged X-User-Id without a token must not authenticate
+	req := httptest.NewRequest(http.MethodGet, "/ws?user_id=victim", nil)
+	req.Header.Set(UserIDHeader, "victim")
+
+	userID, err := ExtractUserIDFromJWT(req, NewJWTVerifier(testJWTSecret))
+
+	require.ErrorIs(t, err, ErrMissingToken)
+	assert.Empty(t, userID)
+}
+
+func TestExtractUserIDFromJWT_InvalidToken(t *testing.T) {
+	req := httptest.NewRequest(http.MethodGet, "/ws", nil)
+	req.Header.Set(AuthorizationHeader, "Bearer not-a-jwt")
+
+	userID, err := ExtractUserIDFromJWT(req, NewJWTVerifier(testJWTSecret))
+
+	require.ErrorIs(t, err, ErrInvalidToken)
+	assert.Empty(t, userID)
+}
@@ -0,0 +1,58 @@
+package auth
+
+import (
+	"context"
+	"slices"
+
+	ctxkeys "chat-service/internal/context"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+const (
+	// RoleAdmin can moderate any conversation
+	RoleAdmin = "admin"
+	// RoleModerator can moderate conversations (delete messages, ban users)
+	RoleModerator = "moderator"
+)
+
+// GetRolesFromContext retrieves the caller's roles from context (empty when none were verified)
+func GetRolesFromContext(ctx context.Context) []string {
+	roles, _ := ctx.Value(ctxkeys.RolesKey).([]string)
+	return roles
+}
+
+// SetRolesInContext sets the caller's roles in context
+func SetRolesInContext(ctx context.Context, roles []string) context.Context {
+	return context.WithValue(ctx, ctxkeys.RolesKey, roles)
+}
+
+// HasAnyRole reports whether the caller has at least one of roles
+func HasAnyRole(ctx context.Context, roles ...string) bool {
+	granted := GetRolesFromContext(ctx)
+	for _, role := range roles {
+		if slices.Contains(granted, role) {
+			return true
+		}
+	}
+	return false
+}
+
+// RequireRole is the per-RPC authorization check: admin-only methods call it first, e.g.
+//
+//	if err := auth.RequireRole(ctx, auth.RoleAdmin, auth.RoleModerator); err != nil {
+//		return nil, err
+//	}
+//
+// It returns codes.Unauthenticated without a user and codes.PermissionDenied when none of roles is granted.
+// Checking inside the method covers both gRPC and the in-process HTTP gateway, which skips interceptors.
+func RequireRole(ctx context.Context, roles ...string) error {
+	if _, err := GetUserIDFromContext(ctx); err != nil {
+		return status.Error(codes.Unauthenticated, "missing user id")
+	}
+	if !HasAnyRole(ctx, roles...) {
+		return status.Error(codes.PermissionDenied, "insufficient permissions")
+	}
+	return nil
+}
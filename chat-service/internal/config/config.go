@@ -3,6 +3,7 @@ package config
 import (
 	"fmt"
 	"net/url"
+	"strings"
 	"time"
 
 	"github.com/spf13/viper"
@@ -11,8 +12,26 @@ import (
 
 const (
 	DefaultOutboxPollIntervalMs = 100
+	DefaultOutboxMaxPollMs      = 2000
 	DefaultOutboxBatchSize      = 100
+	DefaultOutboxLeaderLeaseMs  = 10000
+	DefaultOutboxFollowerPollMs = 5000
+	DefaultOutboxShutdownMs     = 10000
+	DefaultOutboxStaleAfterMs   = 60000
+	DefaultOutboxStreamMaxLen   = 100000
 	DefaultMetricsPort          = 9090
+	DefaultCORSMaxAgeSeconds    = 86400
+	DefaultDBStatementTimeoutMs = 5000
+	DefaultHTTPReadTimeoutMs    = 10000
+	DefaultHTTPWriteTimeoutMs   = 10000
+	DefaultMaxContentBytes      = 16384
+	DefaultMaxReceivers         = 256
+	DefaultMaxGroupSize         = 1000
+	DefaultRetentionPurgeMs     = 300000
+	DefaultRetentionBatchSize   = 500
+	DefaultDuplicateTopN        = 10
+	DefaultDuplicateWindowMs    = 60000
+	DefaultDuplicateStorm       = 20
 )
 
 type Config struct {
@@ -22,6 +41,10 @@ type Config struct {
 	HTTPServerAddress string `mapstructure:"HTTP_SERVER_ADDRESS"`
 	GRPCServerAddress string `mapstructure:"GRPC_SERVER_ADDRESS"`
 
+	// HTTP gateway timeouts; every route is a short request (event streams are gRPC only)
+	HTTPReadTimeoutMs  int `mapstructure:"HTTP_READ_TIMEOUT_MS"`
+	HTTPWriteTimeoutMs int `mapstructure:"HTTP_WRITE_TIMEOUT_MS"`
+
 	// Database connection components (preferred over DB_SOURCE)
 	DBHost     string `mapstructure:"DB_HOST"`
 	DBPort     string `mapstructure:"DB_PORT"`
@@ -33,6 +56,31 @@ type Config struct {
 	// Outbox Processor Settings
 	OutboxPollIntervalMs int `mapstructure:"OUTBOX_POLL_INTERVAL_MS"`
 	OutboxBatchSize      int `mapstructure:"OUTBOX_BATCH_SIZE"`
+	// Idle polls back off exponentially up to this interval (set to OUTBOX_POLL_INTERVAL_MS to disable)
+	OutboxMaxPollIntervalMs int `mapstructure:"OUTBOX_MAX_POLL_INTERVAL_MS"`
+	// LISTEN for the outbox insert trigger (migration 000011) and poll as soon as events are committed
+	OutboxListenNotify bool `mapstructure:"OUTBOX_LISTEN_NOTIFY"`
+	// Message events with a larger payload are published ids-only; gateways load the message (0 = no limit)
+	OutboxMaxPayloadBytes int `mapstructure:"OUTBOX_MAX_PAYLOAD_BYTES"`
+	// Most Redis publishes in flight at once, independent of the worker count (0 = no limit)
+	OutboxMaxInFlightPublishes int `mapstructure:"OUTBOX_MAX_INFLIGHT_PUBLISHES"`
+	// Leader election between outbox replicas (followers poll at the slow interval)
+	OutboxLeaderElection         bool `mapstructure:"OUTBOX_LEADER_ELECTION"`
+	OutboxLeaderLeaseMs          int  `mapstructure:"OUTBOX_LEADER_LEASE_MS"`
+	OutboxFollowerPollIntervalMs int  `mapstructure:"OUTBOX_FOLLOWER_POLL_INTERVAL_MS"`
+	// How long shutdown waits for the in-flight batch to commit before abandoning it
+	OutboxShutdownTimeoutMs int `mapstructure:"OUTBOX_SHUTDOWN_TIMEOUT_MS"`
+	// The processor's /health returns 503 once no poll cycle finished for this long
+	OutboxStaleAfterMs int `mapstructure:"OUTBOX_STALE_AFTER_MS"`
+	// Real-time transport shared by the outbox processor and ws-gateway: "pubsub" (default) or "stream"
+	EventTransport     string `mapstructure:"EVENT_TRANSPORT"`
+	OutboxStreamMaxLen int64  `mapstructure:"OUTBOX_STREAM_MAXLEN"`
+	// Pub/Sub shard channels per conversation (0 = single chat:events channel)
+	EventShards int `mapstructure:"EVENT_SHARDS"`
+	// Serve the StreamEvents RPC from the API server (subscribes to the event transport above)
+	GRPCEventStream bool `mapstructure:"GRPC_EVENT_STREAM"`
+	// How the event stream picks its instance ID: auto, env, hostname or uuid (see ws.ConfigureInstanceID)
+	InstanceIDStrategy string `mapstructure:"WS_INSTANCE_ID_STRATEGY"`
 
 	// Metrics Settings
 	MetricsPort int `mapstructure:"METRICS_PORT"`
@@ -42,12 +90,61 @@ type Config struct {
 	DBMinConns     int32 `mapstructure:"DB_MIN_CONNS"`
 	DBMaxConnLife  int   `mapstructure:"DB_MAX_CONN_LIFE_MINUTES"`
 	DBMaxConnIdle  int   `mapstructure:"DB_MAX_CONN_IDLE_MINUTES"`
+	// Server-side statement_timeout for every pooled connection (also bounds each service query)
+	DBStatementTimeoutMs int `mapstructure:"DB_STATEMENT_TIMEOUT_MS"`
 
 	// Cloudinary Settings
 	CloudinaryCloudName   string `mapstructure:"CLOUDINARY_CLOUD_NAME"`
 	CloudinaryAPIKey      string `mapstructure:"CLOUDINARY_API_KEY"`
 	CloudinaryAPISecret   string `mapstructure:"CLOUDINARY_API_SECRET"`
 	CloudinaryUploadFolder string `mapstructure:"CLOUDINARY_UPLOAD_FOLDER"`
+
+	// Auth Settings
+	// HS256 key shared with the API Gateway; when set, bearer tokens are verified and their roles trusted
+	AccessTokenSecret string `mapstructure:"ACCESS_TOKEN_SECRET"`
+
+	// CORS Settings (comma separated lists; empty keeps the permissive default)
+	CORSAllowedOrigins   string `mapstructure:"CORS_ALLOWED_ORIGINS"`
+	CORSAllowedMethods   string `mapstructure:"CORS_ALLOWED_METHODS"`
+	CORSAllowedHeaders   string `mapstructure:"CORS_ALLOWED_HEADERS"`
+	CORSAllowCredentials bool   `mapstructure:"CORS_ALLOW_CREDENTIALS"`
+	CORSMaxAgeSeconds    int    `mapstructure:"CORS_MAX_AGE_SECONDS"`
+
+	// Sender profile source for message sender_name/sender_avatar_url ("redis" or empty to disable)
+	ProfileSource string `mapstructure:"PROFILE_SOURCE"`
+
+	// Largest message content accepted, in bytes; the ws-gateway derives its read limit from it
+	MaxContentBytes int `mapstructure:"MAX_CONTENT_BYTES"`
+
+	// Most receiver_ids accepted on a single message
+	MaxReceiversPerMessage int `mapstructure:"MAX_RECEIVERS_PER_MESSAGE"`
+
+	// Most participants a conversation may have
+	MaxGroupSize int `mapstructure:"MAX_GROUP_SIZE"`
+
+	// Optional stricter attachment rules on top of the built-in allowlist (unset = built-in rules only)
+	AttachmentMaxSizeBytes     int64  `mapstructure:"ATTACHMENT_MAX_SIZE_BYTES"`
+	AttachmentAllowedMimeTypes string `mapstructure:"ATTACHMENT_ALLOWED_MIME_TYPES"`
+
+	// Optional content wordlists (comma separated): blocked words reject a message, flagged words mark it for review
+	ContentBlockedWords string `mapstructure:"CONTENT_BLOCKED_WORDS"`
+	ContentFlaggedWords string `mapstructure:"CONTENT_FLAGGED_WORDS"`
+
+	// Message retention purge job (deletes messages past their conversation's message_ttl_seconds)
+	RetentionPurgeDisabled   bool `mapstructure:"RETENTION_PURGE_DISABLED"`
+	RetentionPurgeIntervalMs int  `mapstructure:"RETENTION_PURGE_INTERVAL_MS"`
+	RetentionPurgeBatchSize  int  `mapstructure:"RETENTION_PURGE_BATCH_SIZE"`
+
+	// Idempotency duplicate attribution: only the top-N noisiest users/conversations per window get a series
+	IdempotencyMetricsTopN     int `mapstructure:"IDEMPOTENCY_METRICS_TOP_N"`
+	IdempotencyMetricsWindowMs int `mapstructure:"IDEMPOTENCY_METRICS_WINDOW_MS"`
+	IdempotencyStormThreshold  int `mapstructure:"IDEMPOTENCY_STORM_THRESHOLD"`
+
+	// Keep archived conversations archived when a new message arrives (default: a new message unarchives)
+	KeepArchivedOnNewMessage bool `mapstructure:"KEEP_ARCHIVED_ON_NEW_MESSAGE"`
+
+	// Only participants may send into existing conversations (default: senders join any conversation they name)
+	StrictParticipants bool `mapstructure:"STRICT_PARTICIPANTS"`
 }
 
 // GetDBSource returns the database connection string.
@@ -110,6 +207,54 @@ func (c *Config) GetDBMaxConnIdleTime() time.Duration {
 	return time.Duration(c.DBMaxConnIdle) * time.Minute
 }
 
+// GetDBStatementTimeout returns the per-statement timeout (default: 5 seconds)
+func (c *Config) GetDBStatementTimeout() time.Duration {
+	if c.DBStatementTimeoutMs <= 0 {
+		return time.Duration(DefaultDBStatementTimeoutMs) * time.Millisecond
+	}
+	return time.Duration(c.DBStatementTimeoutMs) * time.Millisecond
+}
+
+// GetHTTPReadTimeout returns the HTTP gateway's read timeout (default: 10 seconds)
+func (c *Config) GetHTTPReadTimeout() time.Duration {
+	if c.HTTPReadTimeoutMs <= 0 {
+		return time.Duration(DefaultHTTPReadTimeoutMs) * time.Millisecond
+	}
+	return time.Duration(c.HTTPReadTimeoutMs) * time.Millisecond
+}
+
+// GetHTTPWriteTimeout returns the HTTP gateway's write timeout (default: 10 seconds)
+func (c *Config) GetHTTPWriteTimeout() time.Duration {
+	if c.HTTPWriteTimeoutMs <= 0 {
+		return time.Duration(DefaultHTTPWriteTimeoutMs) * time.Millisecond
+	}
+	return time.Duration(c.HTTPWriteTimeoutMs) * time.Millisecond
+}
+
+// GetMaxContentBytes returns the largest accepted message content in bytes (default: 16 KB)
+func (c *Config) GetMaxContentBytes() int {
+	if c.MaxContentBytes <= 0 {
+		return DefaultMaxContentBytes
+	}
+	return c.MaxContentBytes
+}
+
+// GetMaxReceiversPerMessage returns the most receiver_ids accepted on a message (default: 256)
+func (c *Config) GetMaxReceiversPerMessage() int {
+	if c.MaxReceiversPerMessage <= 0 {
+		return DefaultMaxReceivers
+	}
+	return c.MaxReceiversPerMessage
+}
+
+// GetMaxGroupSize returns the most participants a conversation may have (default: 1000)
+func (c *Config) GetMaxGroupSize() int {
+	if c.MaxGroupSize <= 0 {
+		return DefaultMaxGroupSize
+	}
+	return c.MaxGroupSize
+}
+
 // GetOutboxPollInterval returns the poll interval as time.Duration.
 // If the configured value is invalid (non-positive), it returns the default value and logs a warning.
 func (c *Config) GetOutboxPollInterval(logger *zap.Logger) time.Duration {
@@ -124,6 +269,19 @@ func (c *Config) GetOutboxPollInterval(logger *zap.Logger) time.Duration {
 	return time.Duration(c.OutboxPollIntervalMs) * time.Millisecond
 }
 
+// GetOutboxMaxPollInterval returns the longest interval idle polls back off to (default: 2 seconds).
+// It is never shorter than the poll interval.
+func (c *Config) GetOutboxMaxPollInterval() time.Duration {
+	maxInterval := time.Duration(DefaultOutboxMaxPollMs) * time.Millisecond
+	if c.OutboxMaxPollIntervalMs > 0 {
+		maxInterval = time.Duration(c.OutboxMaxPollIntervalMs) * time.Millisecond
+	}
+	if pollInterval := c.GetOutboxPollInterval(nil); maxInterval < pollInterval {
+		return pollInterval
+	}
+	return maxInterval
+}
+
 // GetOutboxBatchSize returns the batch size for outbox processing.
 // If the configured value is invalid (non-positive), it returns the default value and logs a warning.
 func (c *Config) GetOutboxBatchSize(logger *zap.Logger) int {
@@ -138,6 +296,47 @@ func (c *Config) GetOutboxBatchSize(logger *zap.Logger) int {
 	return c.OutboxBatchSize
 }
 
+// GetOutboxLeaderLease returns the outbox leader lease TTL (default: 10 seconds)
+func (c *Config) GetOutboxLeaderLease() time.Duration {
+	if c.OutboxLeaderLeaseMs <= 0 {
+		return time.Duration(DefaultOutboxLeaderLeaseMs) * time.Millisecond
+	}
+	return time.Duration(c.OutboxLeaderLeaseMs) * time.Millisecond
+}
+
+// GetOutboxFollowerPollInterval returns how often non-leader replicas poll (default: 5 seconds)
+func (c *Config) GetOutboxFollowerPollInterval() time.Duration {
+	if c.OutboxFollowerPollIntervalMs <= 0 {
+		return time.Duration(DefaultOutboxFollowerPollMs) * time.Millisecond
+	}
+	return time.Duration(c.OutboxFollowerPollIntervalMs) * time.Millisecond
+}
+
+// GetOutboxShutdownTimeout returns how long the processor may drain on shutdown (default: 10 seconds)
+func (c *Config) GetOutboxShutdownTimeout() time.Duration {
+	if c.OutboxShutdownTimeoutMs <= 0 {
+		return time.Duration(DefaultOutboxShutdownMs) * time.Millisecond
+	}
+	return time.Duration(c.OutboxShutdownTimeoutMs) * time.Millisecond
+}
+
+// GetOutboxStaleAfter returns how long the outbox poll loop may go without finishing a cycle before
+// /health reports it stalled (default: 1 minute)
+func (c *Config) GetOutboxStaleAfter() time.Duration {
+	if c.OutboxStaleAfterMs <= 0 {
+		return time.Duration(DefaultOutboxStaleAfterMs) * time.Millisecond
+	}
+	return time.Duration(c.OutboxStaleAfterMs) * time.Millisecond
+}
+
+// GetOutboxStreamMaxLen returns the approximate length the event stream is trimmed to (default: 100000)
+func (c *Config) GetOutboxStreamMaxLen() int64 {
+	if c.OutboxStreamMaxLen <= 0 {
+		return DefaultOutboxStreamMaxLen
+	}
+	return c.OutboxStreamMaxLen
+}
+
 // GetMetricsPort returns the metrics server port.
 // If the configured value is invalid (non-positive), it returns the default value.
 func (c *Config) GetMetricsPort() int {
@@ -147,6 +346,95 @@ func (c *Config) GetMetricsPort() int {
 	return c.MetricsPort
 }
 
+// GetCORSAllowedOrigins returns the allowed origins, or nil when unset
+func (c *Config) GetCORSAllowedOrigins() []string {
+	return splitList(c.CORSAllowedOrigins)
+}
+
+// GetCORSAllowedMethods returns the allowed methods, or nil when unset
+func (c *Config) GetCORSAllowedMethods() []string {
+	return splitList(c.CORSAllowedMethods)
+}
+
+// GetCORSAllowedHeaders returns the allowed request headers, or nil when unset
+func (c *Config) GetCORSAllowedHeaders() []string {
+	return splitList(c.CORSAllowedHeaders)
+}
+
+// GetCORSMaxAge returns how long browsers may cache preflight results (default: 24 hours)
+func (c *Config) GetCORSMaxAge() time.Duration {
+	if c.CORSMaxAgeSeconds <= 0 {
+		return time.Duration(DefaultCORSMaxAgeSeconds) * time.Second
+	}
+	return time.Duration(c.CORSMaxAgeSeconds) * time.Second
+}
+
+// GetRetentionPurgeInterval returns how often the retention job sweeps (default: 5 minutes)
+func (c *Config) GetRetentionPurgeInterval() time.Duration {
+	if c.RetentionPurgeIntervalMs <= 0 {
+		return time.Duration(DefaultRetentionPurgeMs) * time.Millisecond
+	}
+	return time.Duration(c.RetentionPurgeIntervalMs) * time.Millisecond
+}
+
+// GetRetentionPurgeBatchSize returns the messages deleted per statement by the retention job (default: 500)
+func (c *Config) GetRetentionPurgeBatchSize() int {
+	if c.RetentionPurgeBatchSize <= 0 {
+		return DefaultRetentionBatchSize
+	}
+	return c.RetentionPurgeBatchSize
+}
+
+// GetIdempotencyMetricsTopN returns how many noisy users/conversations get their own duplicate series (default: 10)
+func (c *Config) GetIdempotencyMetricsTopN() int {
+	if c.IdempotencyMetricsTopN <= 0 {
+		return DefaultDuplicateTopN
+	}
+	return c.IdempotencyMetricsTopN
+}
+
+// GetIdempotencyMetricsWindow returns the window duplicate hits are counted over (default: 1 minute)
+func (c *Config) GetIdempotencyMetricsWindow() time.Duration {
+	if c.IdempotencyMetricsWindowMs <= 0 {
+		return time.Duration(DefaultDuplicateWindowMs) * time.Millisecond
+	}
+	return time.Duration(c.IdempotencyMetricsWindowMs) * time.Millisecond
+}
+
+// GetIdempotencyStormThreshold returns the duplicate hits per window that count as a storm (default: 20)
+func (c *Config) GetIdempotencyStormThreshold() int {
+	if c.IdempotencyStormThreshold <= 0 {
+		return DefaultDuplicateStorm
+	}
+	return c.IdempotencyStormThreshold
+}
+
+// GetAttachmentAllowedMimeTypes returns the extra attachment mime allowlist, or nil when unset
+func (c *Config) GetAttachmentAllowedMimeTypes() []string {
+	return splitList(c.AttachmentAllowedMimeTypes)
+}
+
+// GetContentBlockedWords returns the words that reject a message, or nil when unset
+func (c *Config) GetContentBlockedWords() []string {
+	return splitList(c.ContentBlockedWords)
+}
+
+// GetContentFlaggedWords returns the words that flag a message for review, or nil when unset
+func (c *Config) GetContentFlaggedWords() []string {
+	return splitList(c.ContentFlaggedWords)
+}
+
+// splitList splits a comma separated value, dropping empty entries
+func splitList(value string) []string {
+	var items []string
+	for _, item := range strings.Split(value, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+	return items
+}
+
 func LoadConfig(path string) (config Config, err error) {
 	viper.AddConfigPath(path)
 	viper.SetConfigName("app")
@@ -164,17 +452,56 @@ func LoadConfig(path string) (config Config, err error) {
 	_ = viper.BindEnv("REDIS_ADDR")
 	_ = viper.BindEnv("HTTP_SERVER_ADDRESS")
 	_ = viper.BindEnv("GRPC_SERVER_ADDRESS")
+	_ = viper.BindEnv("HTTP_READ_TIMEOUT_MS")
+	_ = viper.BindEnv("HTTP_WRITE_TIMEOUT_MS")
 	_ = viper.BindEnv("OUTBOX_POLL_INTERVAL_MS")
 	_ = viper.BindEnv("OUTBOX_BATCH_SIZE")
+	_ = viper.BindEnv("OUTBOX_MAX_POLL_INTERVAL_MS")
+	_ = viper.BindEnv("OUTBOX_LISTEN_NOTIFY")
+	_ = viper.BindEnv("OUTBOX_MAX_PAYLOAD_BYTES")
+	_ = viper.BindEnv("OUTBOX_MAX_INFLIGHT_PUBLISHES")
+	_ = viper.BindEnv("OUTBOX_LEADER_ELECTION")
+	_ = viper.BindEnv("OUTBOX_LEADER_LEASE_MS")
+	_ = viper.BindEnv("OUTBOX_FOLLOWER_POLL_INTERVAL_MS")
+	_ = viper.BindEnv("OUTBOX_SHUTDOWN_TIMEOUT_MS")
+	_ = viper.BindEnv("OUTBOX_STALE_AFTER_MS")
+	_ = viper.BindEnv("EVENT_TRANSPORT")
+	_ = viper.BindEnv("OUTBOX_STREAM_MAXLEN")
+	_ = viper.BindEnv("EVENT_SHARDS")
+	_ = viper.BindEnv("GRPC_EVENT_STREAM")
+	_ = viper.BindEnv("WS_INSTANCE_ID_STRATEGY")
 	_ = viper.BindEnv("METRICS_PORT")
 	_ = viper.BindEnv("DB_MAX_CONNS")
 	_ = viper.BindEnv("DB_MIN_CONNS")
 	_ = viper.BindEnv("DB_MAX_CONN_LIFE_MINUTES")
 	_ = viper.BindEnv("DB_MAX_CONN_IDLE_MINUTES")
+	_ = viper.BindEnv("DB_STATEMENT_TIMEOUT_MS")
+	_ = viper.BindEnv("MAX_CONTENT_BYTES")
+	_ = viper.BindEnv("MAX_RECEIVERS_PER_MESSAGE")
+	_ = viper.BindEnv("MAX_GROUP_SIZE")
+	_ = viper.BindEnv("KEEP_ARCHIVED_ON_NEW_MESSAGE")
+	_ = viper.BindEnv("STRICT_PARTICIPANTS")
+	_ = viper.BindEnv("ATTACHMENT_MAX_SIZE_BYTES")
+	_ = viper.BindEnv("RETENTION_PURGE_DISABLED")
+	_ = viper.BindEnv("IDEMPOTENCY_METRICS_TOP_N")
+	_ = viper.BindEnv("IDEMPOTENCY_METRICS_WINDOW_MS")
+	_ = viper.BindEnv("IDEMPOTENCY_STORM_THRESHOLD")
+	_ = viper.BindEnv("RETENTION_PURGE_INTERVAL_MS")
+	_ = viper.BindEnv("RETENTION_PURGE_BATCH_SIZE")
+	_ = viper.BindEnv("ATTACHMENT_ALLOWED_MIME_TYPES")
+	_ = viper.BindEnv("CONTENT_BLOCKED_WORDS")
+	_ = viper.BindEnv("CONTENT_FLAGGED_WORDS")
 	_ = viper.BindEnv("CLOUDINARY_CLOUD_NAME")
 	_ = viper.BindEnv("CLOUDINARY_API_KEY")
 	_ = viper.BindEnv("CLOUDINARY_API_SECRET")
 	_ = viper.BindEnv("CLOUDINARY_UPLOAD_FOLDER")
+	_ = viper.BindEnv("ACCESS_TOKEN_SECRET")
+	_ = viper.BindEnv("CORS_ALLOWED_ORIGINS")
+	_ = viper.BindEnv("CORS_ALLOWED_METHODS")
+	_ = viper.BindEnv("CORS_ALLOWED_HEADERS")
+	_ = viper.BindEnv("CORS_ALLOW_CREDENTIALS")
+	_ = viper.BindEnv("CORS_MAX_AGE_SECONDS")
+	_ = viper.BindEnv("PROFILE_SOURCE")
 
 	// Đọc từ environment variables
 	viper.AutomaticEnv()
@@ -119,3 +119,89 @@ func TestGetOutboxBatchSize_LogsWarningOnInvalidValue(t *testing.T) {
 	result := cfg.GetOutboxBatchSize(logger)
 	assert.Equal(t, DefaultOutboxBatchSize, result, "should return default and log warning")
 }
+
+func TestGetCORSAllowedOrigins_SplitsList(t *testing.T) {
+	cfg := &Config{CORSAllowedOrigins: " https://app.example.com, ,https://*.example.com "}
+	result := cfg.GetCORSAllowedOrigins()
+	assert.Equal(t, []string{"https://app.example.com", "https://*.example.com"}, result)
+}
+
+func TestGetCORSAllowedOrigins_Unset(t *testing.T) {
+	cfg := &Config{}
+	assert.Nil(t, cfg.GetCORSAllowedOrigins(), "unset origins should keep the middleware default")
+}
+
+func TestGetCORSMaxAge_DefaultValue(t *testing.T) {
+	cfg := &Config{}
+	result := cfg.GetCORSMaxAge()
+	assert.Equal(t, time.Duration(DefaultCORSMaxAgeSeconds)*time.Second, result)
+}
+
+func TestGetDBStatementTimeout_DefaultValue(t *testing.T) {
+	cfg := &Config{}
+	result := cfg.GetDBStatementTimeout()
+	assert.Equal(t, time.Duration(DefaultDBStatementTimeoutMs)*time.Millisecond, result)
+}
+
+func TestGetDBStatementTimeout_ValidValue(t *testing.T) {
+	cfg := &Config{DBStatementTimeoutMs: 1500}
+	result := cfg.GetDBStatementTimeout()
+	assert.Equal(t, 1500*time.Millisecond, result)
+}
+
+func TestGetHTTPTimeouts(t *testing.T) {
+	cfg := &Config{}
+	assert.Equal(t, time.Duration(DefaultHTTPReadTimeoutMs)*time.Millisecond, cfg.GetHTTPReadTimeout())
+	assert.Equal(t, time.Duration(DefaultHTTPWriteTimeoutMs)*time.Millisecond, cfg.GetHTTPWriteTimeout())
+
+	cfg = &Config{HTTPReadTimeoutMs: 2000, HTTPWriteTimeoutMs: 30000}
+	assert.Equal(t, 2*time.Second, cfg.GetHTTPReadTimeout())
+	assert.Equal(t, 30*time.Second, cfg.GetHTTPWriteTimeout())
+}
+
+func TestGetOutboxLeaderLease_DefaultValue(t *testing.T) {
+	cfg := &Config{}
+	assert.Equal(t, time.Duration(DefaultOutboxLeaderLeaseMs)*time.Millisecond, cfg.GetOutboxLeaderLease())
+}
+
+func TestGetOutboxFollowerPollInterval(t *testing.T) {
+	cfg := &Config{}
+	assert.Equal(t, time.Duration(DefaultOutboxFollowerPollMs)*time.Millisecond, cfg.GetOutboxFollowerPollInterval())
+
+	cfg.OutboxFollowerPollIntervalMs = 2000
+	assert.Equal(t, 2*time.Second, cfg.GetOutboxFollowerPollInterval())
+}
+
+func TestGetOutboxMaxPollInterval(t *testing.T) {
+	cfg := &Config{}
+	assert.Equal(t, time.Duration(DefaultOutboxMaxPollMs)*time.Millisecond, cfg.GetOutboxMaxPollInterval())
+
+	cfg.OutboxMaxPollIntervalMs = 5000
+	assert.Equal(t, 5*time.Second, cfg.GetOutboxMaxPollInterval())
+
+	// Never below the poll interval, so setting both equal disables the backoff
+	cfg.OutboxPollIntervalMs = 8000
+	assert.Equal(t, 8*time.Second, cfg.GetOutboxMaxPollInterval())
+}
+
+func TestGetOutboxStreamMaxLen(t *testing.T) {
+	cfg := &Config{}
+	assert.Equal(t, int64(DefaultOutboxStreamMaxLen), cfg.GetOutboxStreamMaxLen())
+
+	cfg.OutboxStreamMaxLen = 5000
+	assert.Equal(t, int64(5000), cfg.GetOutboxStreamMaxLen())
+}
+
+func TestGetOutboxShutdownTimeout(t *testing.T) {
+	cfg := &Config{}
+	assert.Equal(t, time.Duration(DefaultOutboxShutdownMs)*time.Millisecond, cfg.GetOutboxShutdownTimeout())
+
+	cfg.OutboxShutdownTimeoutMs = 3000
+	assert.Equal(t, 3*time.Second, cfg.GetOutboxShutdownTimeout())
+}
+
+func TestGetMaxContentBytes(t *testing.T) {
+	assert.Equal(t, DefaultMaxContentBytes, (&Config{}).GetMaxContentBytes())
+	assert.Equal(t, DefaultMaxContentBytes, (&Config{MaxContentBytes: -1}).GetMaxContentBytes())
+	assert.Equal(t, 4096, (&Config{MaxContentBytes: 4096}).GetMaxContentBytes())
+}
@@ -7,4 +7,12 @@ const (
 	// UserIDKey is the context key for storing authenticated user ID
 	// This is set by auth middleware and used by service handlers
 	UserIDKey ContextKey = "user_id"
+
+	// RolesKey is the context key for storing the authenticated user's roles/scopes ([]string)
+	// Only set from verified JWT claims, never from plain headers
+	RolesKey ContextKey = "roles"
+
+	// RequestIDKey is the context key for the request correlation id (X-Request-ID)
+	// Set by the request id middleware and copied into outbox payloads
+	RequestIDKey ContextKey = "request_id"
 )
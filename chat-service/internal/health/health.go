@@ -0,0 +1,127 @@
+package health
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+// DefaultTimeout bounds each readiness probe so a hung dependency can't hang the check
+const DefaultTimeout = 2 * time.Second
+
+// ErrNotRunning is returned by checks for background workers that have stopped
+var ErrNotRunning = errors.New("not running")
+
+// Check probes a single dependency; it returns nil when the dependency is usable.
+// Checks must return once ctx is done.
+type Check func(ctx context.Context) error
+
+// Handler serves liveness (/healthz) and readiness (/readyz) probes.
+type Handler struct {
+	logger       *zap.Logger
+	timeout      time.Duration
+	names        []string
+	checks       map[string]Check
+	shuttingDown atomic.Bool
+}
+
+// Response is the JSON body of both probes
+type Response struct {
+	Status string            `json:"status"`
+	Checks map[string]string `json:"checks,omitempty"`
+}
+
+// NewHandler creates a health handler; timeout <= 0 uses DefaultTimeout.
+func NewHandler(logger *zap.Logger, timeout time.Duration) *Handler {
+	if timeout <= 0 {
+		timeout = DefaultTimeout
+	}
+	return &Handler{
+		logger:  logger,
+		timeout: timeout,
+		checks:  make(map[string]Check),
+	}
+}
+
+// AddCheck registers a readiness check. It must be called before serving.
+func (h *Handler) AddCheck(name string, check Check) {
+	if _, exists := h.checks[name]; !exists {
+		h.names = append(h.names, name)
+	}
+	h.checks[name] = check
+}
+
+// SetShuttingDown makes readiness fail so load balancers drain the instance before it stops.
+func (h *Handler) SetShuttingDown() {
+	h.shuttingDown.Store(true)
+}
+
+// Liveness reports that the process is up. It doesn't check dependencies:
+// restarting the pod won't fix a database outage.
+func (h *Handler) Liveness(w http.ResponseWriter, _ *http.Request) {
+	writeResponse(w, http.StatusOK, Response{Status: "ok"})
+}
+
+// Readiness runs all checks concurrently and returns 503 when any of them fails.
+func (h *Handler) Readiness(w http.ResponseWriter, r *http.Request) {
+	if h.shuttingDown.Load() {
+		writeResponse(w, http.StatusServiceUnavailable, Response{Status: "shutting_down"})
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(r.Context(), h.timeout)
+	defer cancel()
+
+	results := h.runChecks(ctx)
+
+	resp := Response{Status: "ok", Checks: make(map[string]string, len(results))}
+	code := http.StatusOK
+	for name, err := range results {
+		if err == nil {
+			resp.Checks[name] = "ok"
+			continue
+		}
+		resp.Checks[name] = err.Error()
+		resp.Status = "unavailable"
+		code = http.StatusServiceUnavailable
+		h.logger.Warn("readiness check failed", zap.String("check", name), zap.Error(err))
+	}
+
+	writeResponse(w, code, resp)
+}
+
+func (h *Handler) runChecks(ctx context.Context) map[string]error {
+	var (
+		mu      sync.Mutex
+		wg      sync.WaitGroup
+		results = make(map[string]error, len(h.names))
+	)
+
+	for _, name := range h.names {
+		check := h.checks[name]
+		wg.Add(1)
+		go func(name string) {
+			defer wg.Done()
+			err := check(ctx)
+			mu.Lock()
+			results[name] = err
+			mu.Unlock()
+		}(name)
+	}
+	wg.Wait()
+
+	return results
+}
+
+func writeResponse(w http.ResponseWriter, code int, resp Response) {
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Cache-Control", "no-store")
+	w.WriteHeader(code)
+	_ = json.NewEncoder(w).Encode(resp)
+}
@@ -0,0 +1,88 @@
+package health
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+func serve(t *testing.T, handler http.HandlerFunc) (int, Response) {
+	t.Helper()
+	rec := httptest.NewRecorder()
+	handler(rec, httptest.NewRequest(http.MethodGet, "/", nil))
+
+	var resp Response
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &resp))
+	return rec.Code, resp
+}
+
+func ok(context.Context) error { return nil }
+
+func TestLiveness_IgnoresFailingChecks(t *testing.T) {
+	h := NewHandler(zap.NewNop(), 0)
+	h.AddCheck("postgres", func(context.Context) error { return errors.New("down") })
+
+	code, resp := serve(t, h.Liveness)
+
+	assert.Equal(t, http.StatusOK, code)
+	assert.Equal(t, "ok", resp.Status)
+}
+
+func TestReadiness_AllHealthy(t *testing.T) {
+	h := NewHandler(zap.NewNop(), 0)
+	h.AddCheck("postgres", ok)
+	h.AddCheck("redis", ok)
+
+	code, resp := serve(t, h.Readiness)
+
+	assert.Equal(t, http.StatusOK, code)
+	assert.Equal(t, "ok", resp.Status)
+	assert.Equal(t, map[string]string{"postgres": "ok", "redis": "ok"}, resp.Checks)
+}
+
+func TestReadiness_FailingCheckReturns503(t *testing.T) {
+	h := NewHandler(zap.NewNop(), 0)
+	h.AddCheck("postgres", ok)
+	h.AddCheck("subscriber", func(context.Context) error { return ErrNotRunning })
+
+	code, resp := serve(t, h.Readiness)
+
+	assert.Equal(t, http.StatusServiceUnavailable, code)
+	assert.Equal(t, "unavailable", resp.Status)
+	assert.Equal(t, "ok", resp.Checks["postgres"])
+	assert.Equal(t, ErrNotRunning.Error(), resp.Checks["subscriber"])
+}
+
+func TestReadiness_HungCheckTimesOut(t *testing.T) {
+	h := NewHandler(zap.NewNop(), 50*time.Millisecond)
+	h.AddCheck("redis", func(ctx context.Context) error {
+		<-ctx.Done()
+		return ctx.Err()
+	})
+
+	start := time.Now()
+	code, resp := serve(t, h.Readiness)
+
+	assert.Less(t, time.Since(start), time.Second)
+	assert.Equal(t, http.StatusServiceUnavailable, code)
+	assert.Equal(t, context.DeadlineExceeded.Error(), resp.Checks["redis"])
+}
+
+func TestReadiness_ShuttingDown(t *testing.T) {
+	h := NewHandler(zap.NewNop(), 0)
+	h.AddCheck("postgres", ok)
+	h.SetShuttingDown()
+
+	code, resp := serve(t, h.Readiness)
+
+	assert.Equal(t, http.StatusServiceUnavailable, code)
+	assert.Equal(t, "shutting_down", resp.Status)
+}
@@ -0,0 +1,124 @@
+package integration
+
+import (
+	"context"
+	"net/http"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// containsConversation reports whether the conversation id is in the list
+func containsConversation(conversations []Conversation, conversationID string) bool {
+	for _, c := range conversations {
+		if c.ID == conversationID {
+			return true
+		}
+	}
+	return false
+}
+
+// TestDeleteConversation_HidesOnlyForRequester tests the per-user soft delete
+// This test verifies:
+// - DeleteConversation returns 200 OK with success=true
+// - The conversation disappears from the requester's GetConversations
+// - Other participants still see the conversation
+func TestDeleteConversation_HidesOnlyForRequester(t *testing.T) {
+	t.Parallel() // Safe to run in parallel - uses unique UUIDs
+	ctx := context.Background()
+
+	testIDs := GenerateTestIDs()
+
+	_, err := CreateTestConversation(ctx, testInfra.DBPool, testIDs.ConversationAB, []string{testIDs.UserA, testIDs.UserB})
+	require.NoError(t, err, "Failed to create test conversation")
+
+	defer func() {
+		err := CleanupConversation(ctx, testInfra.DBPool, testIDs.ConversationAB)
+		if err != nil {
+			t.Logf("Warning: Failed to cleanup conversation: %v", err)
+		}
+	}()
+
+	_, resp, err := testServer.SendMessage(testIDs.UserA, testIDs.ConversationAB, "Hello", uuid.New().String())
+	require.NoError(t, err)
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+
+	// Execute: UserA hides the conversation
+	result, resp, err := testServer.DeleteConversation(testIDs.UserA, testIDs.ConversationAB)
+	require.NoError(t, err, "Failed to delete conversation")
+	require.Equal(t, http.StatusOK, resp.StatusCode, "Should return 200 OK")
+	assert.True(t, result.Success)
+
+	// Verify: hidden for UserA
+	listA, _, err := testServer.GetConversations(testIDs.UserA, 100, "")
+	require.NoError(t, err)
+	assert.False(t, containsConversation(listA.Conversations, testIDs.ConversationAB), "Conversation should be hidden for UserA")
+
+	// Verify: still visible for UserB
+	listB, _, err := testServer.GetConversations(testIDs.UserB, 100, "")
+	require.NoError(t, err)
+	assert.True(t, containsConversation(listB.Conversations, testIDs.ConversationAB), "Conversation should remain visible for UserB")
+}
+
+// TestDeleteConversation_ResurfacesOnNewMessage tests the re-surface rule
+// This test verifies:
+// - A hidden conversation reappears in the list after a new message is sent to it
+func TestDeleteConversation_ResurfacesOnNewMessage(t *testing.T) {
+	t.Parallel() // Safe to run in parallel - uses unique UUIDs
+	ctx := context.Background()
+
+	testIDs := GenerateTestIDs()
+
+	_, err := CreateTestConversation(ctx, testInfra.DBPool, testIDs.ConversationAB, []string{testIDs.UserA, testIDs.UserB})
+	require.NoError(t, err, "Failed to create test conversation")
+
+	defer func() {
+		err := CleanupConversation(ctx, testInfra.DBPool, testIDs.ConversationAB)
+		if err != nil {
+			t.Logf("Warning: Failed to cleanup conversation: %v", err)
+		}
+	}()
+
+	_, resp, err := testServer.DeleteConversation(testIDs.UserA, testIDs.ConversationAB)
+	require.NoError(t, err)
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+
+	list, _, err := testServer.GetConversations(testIDs.UserA, 100, "")
+	require.NoError(t, err)
+	require.False(t, containsConversation(list.Conversations, testIDs.ConversationAB), "Conversation should be hidden before the new message")
+
+	// Execute: UserB sends a new message
+	_, resp, err = testServer.SendMessage(testIDs.UserB, testIDs.ConversationAB, "Are you there?", uuid.New().String())
+	require.NoError(t, err)
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+
+	// Verify: the conversation is back in UserA's list
+	list, _, err = testServer.GetConversations(testIDs.UserA, 100, "")
+	require.NoError(t, err)
+	assert.True(t, containsConversation(list.Conversations, testIDs.ConversationAB), "New message should re-surface the conversation")
+}
+
+// TestDeleteConversation_NotParticipant tests that non-members cannot hide a conversation
+func TestDeleteConversation_NotParticipant(t *testing.T) {
+	t.Parallel() // Safe to run in parallel - uses unique UUIDs
+	ctx := context.Background()
+
+	testIDs := GenerateTestIDs()
+
+	_, err := CreateTestConversation(ctx, testInfra.DBPool, testIDs.ConversationAB, []string{testIDs.UserA, testIDs.UserB})
+	require.NoError(t, err, "Failed to create test conversation")
+
+	defer func() {
+		err := CleanupConversation(ctx, testInfra.DBPool, testIDs.ConversationAB)
+		if err != nil {
+			t.Logf("Warning: Failed to cleanup conversation: %v", err)
+		}
+	}()
+
+	_, resp, err := testServer.DeleteConversation(testIDs.UserC, testIDs.ConversationAB)
+	require.NoError(t, err)
+	assert.Equal(t, http.StatusForbidden, resp.StatusCode, "Non-participants should get 403")
+	resp.Body.Close()
+}
@@ -0,0 +1,175 @@
+package integration
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"chat-service/internal/repository"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// legacyGetConversationsForUser is the previous query shape that computed
+// unread_count with a correlated subquery per conversation. Kept here only
+// as the baseline for BenchmarkGetConversationsForUser.
+const legacyGetConversationsForUser = `
+SELECT
+    c.id,
+    c.last_message_content,
+    c.last_message_at,
+    (
+        SELECT COUNT(*)
+        FROM messages m
+        WHERE m.conversation_id = c.id
+          AND m.created_at > cp.last_read_at
+    ) AS unread_count
+FROM conversations c
+JOIN conversation_participants cp ON c.id = cp.conversation_id
+WHERE cp.user_id = $1
+  AND ($2::timestamptz IS NULL OR c.last_message_at < $2::timestamptz)
+ORDER BY c.last_message_at DESC
+LIMIT $3
+`
+
+const (
+	benchConversations           = 300
+	benchMessagesPerConversation = 30
+)
+
+// seedUnreadBenchmarkData creates one user with many conversations, each with
+// a batch of messages from another participant that are unread.
+func seedUnreadBenchmarkData(ctx context.Context, b *testing.B) (string, []string) {
+	b.Helper()
+
+	userID := uuid.New().String()
+	otherID := uuid.New().String()
+	conversationIDs := make([]string, 0, benchConversations)
+
+	for i := 0; i < benchConversations; i++ {
+		conversationID := uuid.New().String()
+		if _, err := CreateTestConversation(ctx, testInfra.DBPool, conversationID, []string{userID, otherID}); err != nil {
+			b.Fatalf("failed to create conversation: %v", err)
+		}
+		conversationIDs = append(conversationIDs, conversationID)
+
+		_, err := testInfra.DBPool.Exec(ctx, `
+			INSERT INTO messages (conversation_id, sender_id, content, created_at)
+			SELECT $1, $2, 'bench message ' || g, NOW() - (g || ' seconds')::interval
+			FROM generate_series(1, $3) g
+		`, conversationID, otherID, benchMessagesPerConversation)
+		if err != nil {
+			b.Fatalf("failed to insert messages: %v", err)
+		}
+
+		_, err = testInfra.DBPool.Exec(ctx, `
+			UPDATE conversations
+			SET last_message_content = 'bench message 1', last_message_at = NOW() - ($2 || ' seconds')::interval
+			WHERE id = $1
+		`, conversationID, i)
+		if err != nil {
+			b.Fatalf("failed to update conversation: %v", err)
+		}
+	}
+
+	return userID, conversationIDs
+}
+
+// BenchmarkGetConversationsForUser compares the grouped unread-count query
+// against the legacy correlated subquery on the same data set.
+// Run with: go test ./internal/integration -run '^$' -bench GetConversationsForUser
+func BenchmarkGetConversationsForUser(b *testing.B) {
+	ctx := context.Background()
+	userID, conversationIDs := seedUnreadBenchmarkData(ctx, b)
+	defer func() {
+		if err := CleanupConversations(ctx, testInfra.DBPool, conversationIDs); err != nil {
+			b.Logf("Warning: Failed to cleanup conversations: %v", err)
+		}
+	}()
+
+	var userUUID pgtype.UUID
+	if err := userUUID.Scan(userID); err != nil {
+		b.Fatalf("invalid user id: %v", err)
+	}
+
+	for _, limit := range []int32{50, 100} {
+		b.Run(fmt.Sprintf("legacy_correlated/limit=%d", limit), func(b *testing.B) {
+			for i := 0; i < b.N; i++ {
+				rows, err := testInfra.DBPool.Query(ctx, legacyGetConversationsForUser, userUUID, pgtype.Timestamptz{}, limit)
+				if err != nil {
+					b.Fatalf("legacy query failed: %v", err)
+				}
+				rows.Close()
+			}
+		})
+
+		b.Run(fmt.Sprintf("grouped/limit=%d", limit), func(b *testing.B) {
+			queries := repository.New(testInfra.DBPool)
+			for i := 0; i < b.N; i++ {
+				_, err := queries.GetConversationsForUser(ctx, repository.GetConversationsForUserParams{
+					UserID: userUUID,
+					Limit:  limit,
+				})
+				if err != nil {
+					b.Fatalf("grouped query failed: %v", err)
+				}
+			}
+		})
+	}
+}
+
+// TestGetConversationsForUser_UnreadCountsMatchLegacy verifies the grouped query
+// returns the same unread counts as the correlated subquery it replaced.
+func TestGetConversationsForUser_UnreadCountsMatchLegacy(t *testing.T) {
+	t.Parallel() // Safe to run in parallel - uses unique UUIDs
+	ctx := context.Background()
+
+	testIDs := GenerateTestIDs()
+	conversations := []string{testIDs.ConversationAB, testIDs.ConversationAC}
+	_, err := CreateTestConversation(ctx, testInfra.DBPool, testIDs.ConversationAB, []string{testIDs.UserA, testIDs.UserB})
+	require.NoError(t, err, "Failed to create conversation AB")
+	_, err = CreateTestConversation(ctx, testInfra.DBPool, testIDs.ConversationAC, []string{testIDs.UserA, testIDs.UserC})
+	require.NoError(t, err, "Failed to create conversation AC")
+	defer func() {
+		if err := CleanupConversations(ctx, testInfra.DBPool, conversations); err != nil {
+			t.Logf("Warning: Failed to cleanup conversations: %v", err)
+		}
+	}()
+
+	// 3 unread in AB, none in AC
+	_, err = CreateMultipleTestMessages(ctx, testInfra.DBPool, testIDs.ConversationAB, testIDs.UserB, 3)
+	require.NoError(t, err, "Failed to create messages")
+	_, err = testInfra.DBPool.Exec(ctx, `UPDATE conversations SET last_message_at = $2 WHERE id = $1`, testIDs.ConversationAC, time.Now().Add(-2*time.Hour))
+	require.NoError(t, err, "Failed to update conversation AC")
+
+	var userUUID pgtype.UUID
+	err = userUUID.Scan(testIDs.UserA)
+	require.NoError(t, err, "Invalid user id")
+
+	legacy := map[string]int64{}
+	rows, err := testInfra.DBPool.Query(ctx, legacyGetConversationsForUser, userUUID, pgtype.Timestamptz{}, int32(50))
+	require.NoError(t, err, "Legacy query failed")
+	for rows.Next() {
+		var row repository.GetConversationsForUserRow
+		err = rows.Scan(&row.ID, &row.LastMessageContent, &row.LastMessageAt, &row.UnreadCount)
+		require.NoError(t, err, "Scan failed")
+		legacy[uuid.UUID(row.ID.Bytes).String()] = row.UnreadCount
+	}
+	rows.Close()
+
+	grouped, err := repository.New(testInfra.DBPool).GetConversationsForUser(ctx, repository.GetConversationsForUserParams{
+		UserID: userUUID,
+		Limit:  50,
+	})
+	require.NoError(t, err, "Grouped query failed")
+
+	require.Len(t, grouped, len(legacy), "Both queries should return the same conversations")
+	for _, row := range grouped {
+		id := uuid.UUID(row.ID.Bytes).String()
+		assert.Equal(t, legacy[id], row.UnreadCount, "Unread count mismatch for conversation %s", id)
+	}
+}
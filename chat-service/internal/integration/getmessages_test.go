@@ -210,6 +210,61 @@ func TestGetMessages_Pagination(t *testing.T) {
 	}
 }
 
+// TestGetMessages_PaginationWithCollidingTimestamps tests paging through messages that share created_at
+// This test verifies:
+// - Every message is returned exactly once across pages
+// - Pages split in the middle of a run of identical timestamps do not skip or repeat rows
+func TestGetMessages_PaginationWithCollidingTimestamps(t *testing.T) {
+	t.Parallel() // Safe to run in parallel - uses unique UUIDs
+	ctx := context.Background()
+
+	testIDs := GenerateTestIDs()
+
+	_, err := CreateTestConversation(ctx, testInfra.DBPool, testIDs.ConversationAB, []string{testIDs.UserA, testIDs.UserB})
+	require.NoError(t, err, "Failed to create test conversation")
+
+	defer func() {
+		err := CleanupConversation(ctx, testInfra.DBPool, testIDs.ConversationAB)
+		if err != nil {
+			t.Logf("Warning: Failed to cleanup conversation: %v", err)
+		}
+	}()
+
+	// Create 7 messages with the exact same timestamp
+	timestamp := time.Now().Add(-time.Hour).Truncate(time.Microsecond)
+	expectedIDs := make(map[string]bool)
+	for i := 0; i < 7; i++ {
+		messageID := uuid.New().String()
+		_, err := CreateTestMessageWithTimestamp(ctx, testInfra.DBPool, messageID, testIDs.ConversationAB, testIDs.UserA, fmt.Sprintf("Same time %d", i+1), timestamp)
+		require.NoError(t, err, "Failed to create test message %d", i+1)
+		expectedIDs[messageID] = true
+	}
+
+	// Execute: Page through with limit=3 until the cursor runs dry
+	seen := make(map[string]int)
+	cursor := ""
+	for page := 0; page < 5; page++ {
+		result, resp, err := testServer.GetMessages(testIDs.UserA, testIDs.ConversationAB, 3, cursor)
+		require.NoError(t, err, "Failed to get page %d", page)
+		require.Equal(t, http.StatusOK, resp.StatusCode, "Should return 200 OK for page %d", page)
+
+		if len(result.Messages) == 0 {
+			break
+		}
+		for _, msg := range result.Messages {
+			seen[msg.ID]++
+		}
+		cursor = result.NextCursor
+	}
+
+	// Verify: every message was seen exactly once
+	assert.Len(t, seen, len(expectedIDs), "Every message should be returned")
+	for id, count := range seen {
+		assert.True(t, expectedIDs[id], "Unexpected message %s", id)
+		assert.Equal(t, 1, count, "Message %s should be returned exactly once", id)
+	}
+}
+
 // TestGetMessages_EmptyConversation tests retrieving messages from a conversation with no messages
 // This test verifies:
 // - A conversation with no messages returns 200 OK
@@ -55,6 +55,7 @@ func NewTestServer(infra *TestInfrastructure) (*TestServer, error) {
 	// Setup gRPC server with all middleware (auth, logging, recovery)
 	grpcServer := grpc.NewServer(
 		grpc.ChainUnaryInterceptor(
+			middleware.GrpcRequestID(),
 			middleware.GrpcLogger(logger),
 			middleware.GrpcRecovery(logger),
 			auth.GrpcAuthInterceptor(logger),
@@ -103,9 +104,10 @@ func NewTestServer(infra *TestInfrastructure) (*TestServer, error) {
 	}
 
 	// Setup HTTP middleware chain
-	httpHandler := middleware.HTTPRecovery(logger)(
-		middleware.HTTPLogger(logger)(
-			middleware.HTTPAuthExtractor(logger)(gatewayMux)))
+	httpHandler := middleware.HTTPRequestID(
+		middleware.HTTPRecovery(logger)(
+			middleware.HTTPLogger(logger)(
+				middleware.HTTPAuthExtractor(logger)(gatewayMux))))
 
 	// Create httptest.Server for HTTP requests
 	httpServer := httptest.NewServer(httpHandler)
@@ -183,8 +185,10 @@ func (ts *TestServer) MakeRequest(method, path string, body interface{}, headers
 
 // SendMessageResponse represents the response from SendMessage API
 type SendMessageResponse struct {
-	MessageID string `json:"messageId"` // grpc-gateway uses camelCase
-	Status    string `json:"status"`
+	MessageID           string        `json:"messageId"` // grpc-gateway uses camelCase
+	Status              string        `json:"status"`
+	ConversationCreated bool          `json:"conversationCreated"`
+	Conversation        *Conversation `json:"conversation"` // Only set when ConversationCreated
 }
 
 // ChatMessage represents a single chat message
@@ -221,11 +225,16 @@ type MarkAsReadResponse struct {
 	Success bool `json:"success"`
 }
 
-// ErrorResponse represents an error response from the API
+// DeleteConversationResponse represents the response from DeleteConversation API
+type DeleteConversationResponse struct {
+	Success bool `json:"success"`
+}
+
+// ErrorResponse represents an error response from the API (the "error" object of the body)
 type ErrorResponse struct {
-	Code    int    `json:"code"`
-	Message string `json:"message"`
-	Details string `json:"details,omitempty"`
+	Code    string                   `json:"code"`
+	Message string                   `json:"message"`
+	Details []map[string]interface{} `json:"details"`
 }
 
 // SendMessage sends a message via HTTP POST with authentication header
@@ -390,6 +399,38 @@ func (ts *TestServer) MarkAsRead(userID, conversationID string) (*MarkAsReadResp
 	return nil, resp, nil
 }
 
+// DeleteConversation hides a conversation from the authenticated user's list
+func (ts *TestServer) DeleteConversation(userID, conversationID string) (*DeleteConversationResponse, *http.Response, error) {
+	path := fmt.Sprintf("/v1/conversations/%s", conversationID)
+
+	headers := map[string]string{
+		"x-user-id": userID,
+	}
+
+	resp, err := ts.MakeRequest("DELETE", path, nil, headers)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to delete conversation: %w", err)
+	}
+
+	// Parse response if successful
+	if resp.StatusCode == http.StatusOK {
+		var result DeleteConversationResponse
+		body, err := io.ReadAll(resp.Body)
+		resp.Body.Close()
+		if err != nil {
+			return nil, resp, fmt.Errorf("failed to read response body: %w", err)
+		}
+
+		if err := json.Unmarshal(body, &result); err != nil {
+			return nil, resp, fmt.Errorf("failed to parse response: %w", err)
+		}
+
+		return &result, resp, nil
+	}
+
+	return nil, resp, nil
+}
+
 // ParseErrorResponse parses an error response from the API
 func ParseErrorResponse(resp *http.Response) (*ErrorResponse, error) {
 	body, err := io.ReadAll(resp.Body)
@@ -397,14 +438,15 @@ func ParseErrorResponse(resp *http.Response) (*ErrorResponse, error) {
 		return nil, fmt.Errorf("failed to read error response body: %w", err)
 	}
 	
-	var errorResp ErrorResponse
-	if err := json.Unmarshal(body, &errorResp); err != nil {
+	var envelope struct {
+		Error ErrorResponse `json:"error"`
+	}
+	if err := json.Unmarshal(body, &envelope); err != nil {
 		// If JSON parsing fails, return raw body as message
 		return &ErrorResponse{
-			Code:    resp.StatusCode,
 			Message: string(body),
 		}, nil
 	}
 	
-	return &errorResp, nil
+	return &envelope.Error, nil
 }
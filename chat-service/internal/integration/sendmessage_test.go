@@ -85,6 +85,36 @@ func TestSendMessage_Success(t *testing.T) {
 	AssertConversationLastMessage(t, testInfra.DBPool, testIDs.ConversationAB, messageContent)
 }
 
+// TestSendMessage_ConversationCreated verifies that only the message creating a conversation
+// returns it, with the metadata GetConversations would list
+func TestSendMessage_ConversationCreated(t *testing.T) {
+	t.Parallel() // Safe to run in parallel - uses unique UUIDs
+	ctx := context.Background()
+
+	// The conversation does not exist yet: the first message creates it
+	testIDs := GenerateTestIDs()
+	defer func() {
+		if err := CleanupConversation(ctx, testInfra.DBPool, testIDs.ConversationAB); err != nil {
+			t.Logf("Warning: Failed to cleanup conversation: %v", err)
+		}
+	}()
+
+	first, resp, err := testServer.SendMessage(testIDs.UserA, testIDs.ConversationAB, "First", "test-key-"+uuid.New().String())
+	require.NoError(t, err, "Failed to send first message")
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.True(t, first.ConversationCreated, "First message should create the conversation")
+	require.NotNil(t, first.Conversation, "Created conversation should be returned")
+	assert.Equal(t, testIDs.ConversationAB, first.Conversation.ID)
+	assert.Equal(t, "First", first.Conversation.LastMessageContent)
+	assert.NotEmpty(t, first.Conversation.LastMessageAt)
+
+	second, resp, err := testServer.SendMessage(testIDs.UserA, testIDs.ConversationAB, "Second", "test-key-"+uuid.New().String())
+	require.NoError(t, err, "Failed to send second message")
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.False(t, second.ConversationCreated, "Later messages should not report a created conversation")
+	assert.Nil(t, second.Conversation)
+}
+
 // TestSendMessage_Idempotency tests the idempotency behavior of SendMessage
 // This test verifies:
 // - First request with idempotency key succeeds (200 OK)
@@ -151,9 +181,10 @@ func TestSendMessage_Idempotency(t *testing.T) {
 	// Verify: Second request does not return a successful result
 	assert.Nil(t, result2, "Second request should not return a result")
 
-	// Verify: Parse error response (if available)
-	// Note: The error is properly returned as 409 Conflict, which is the important part
-	// The exact error message format may vary based on grpc-gateway error handling
+	// Verify: Error body carries the documented code
+	errResp, err := ParseErrorResponse(resp2)
+	require.NoError(t, err, "Failed to parse error response")
+	assert.Equal(t, "DUPLICATE_REQUEST", errResp.Code, "Duplicate should map to DUPLICATE_REQUEST")
 
 	// Verify: Only one message exists in database
 	// Count messages in the conversation
@@ -171,6 +202,44 @@ func TestSendMessage_Idempotency(t *testing.T) {
 	assert.Equal(t, testIDs.ConversationAB, msg.ConversationID, "Message conversation should match")
 }
 
+// TestSendMessage_IdempotencyWithoutRedisKey verifies that the database recognises a retry
+// whose idempotency key Redis has lost, and returns the stored message instead of a copy
+func TestSendMessage_IdempotencyWithoutRedisKey(t *testing.T) {
+	t.Parallel() // Safe to run in parallel - uses unique UUIDs and idempotency keys
+	ctx := context.Background()
+
+	testIDs := GenerateTestIDs()
+	_, err := CreateTestConversation(ctx, testInfra.DBPool, testIDs.ConversationAB, []string{testIDs.UserA, testIDs.UserB})
+	require.NoError(t, err, "Failed to create test conversation")
+
+	idempotencyKey := "test-idempotency-" + uuid.New().String()
+	defer func() {
+		if err := CleanupRedisKeys(ctx, testInfra, []string{"idempotency:" + idempotencyKey}); err != nil {
+			t.Logf("Warning: Failed to cleanup Redis key: %v", err)
+		}
+		if err := CleanupConversation(ctx, testInfra.DBPool, testIDs.ConversationAB); err != nil {
+			t.Logf("Warning: Failed to cleanup conversation: %v", err)
+		}
+	}()
+
+	first, resp, err := testServer.SendMessage(testIDs.UserA, testIDs.ConversationAB, "Exactly once", idempotencyKey)
+	require.NoError(t, err, "Failed to send first message")
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+
+	// Simulate a Redis flush: the key is gone, so only the database can catch the retry
+	require.NoError(t, CleanupRedisKeys(ctx, testInfra, []string{"idempotency:" + idempotencyKey}))
+
+	retry, resp, err := testServer.SendMessage(testIDs.UserA, testIDs.ConversationAB, "Exactly once", idempotencyKey)
+	require.NoError(t, err, "Failed to send retry")
+	require.Equal(t, http.StatusOK, resp.StatusCode, "The retry should succeed with the stored message")
+	assert.Equal(t, first.MessageID, retry.MessageID, "The retry should return the original message id")
+
+	var messageCount int
+	err = testInfra.DBPool.QueryRow(ctx, `SELECT COUNT(*) FROM messages WHERE conversation_id = $1`, testIDs.ConversationAB).Scan(&messageCount)
+	require.NoError(t, err, "Failed to count messages")
+	assert.Equal(t, 1, messageCount, "Only one message should exist in database")
+}
+
 // TestSendMessage_Unauthenticated tests that SendMessage fails without authentication
 // This test verifies:
 // - Request without x-user-id header returns 401 Unauthenticated error
@@ -0,0 +1,225 @@
+package middleware
+
+import (
+	"net/http"
+	"net/url"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// CORSConfig is the cross-origin policy of the HTTP gateway.
+//
+// AllowedOrigins entries may be:
+//   - "*": any origin (credentials are never allowed with it)
+//   - an exact origin: "https://app.example.com"
+//   - a wildcard subdomain: "https://*.example.com" (any depth, not the apex), or "*.example.com" for any scheme
+type CORSConfig struct {
+	AllowedOrigins   []string
+	AllowedMethods   []string
+	AllowedHeaders   []string
+	ExposedHeaders   []string
+	AllowCredentials bool
+	MaxAge           time.Duration
+}
+
+// DefaultCORSConfig is the permissive development policy
+func DefaultCORSConfig() CORSConfig {
+	return CORSConfig{
+		AllowedOrigins: []string{"*"},
+		AllowedMethods: []string{"GET", "POST", "PUT", "DELETE", "OPTIONS", "PATCH"},
+		AllowedHeaders: []string{"Content-Type", "Authorization", "X-User-Id", "X-Request-ID", "X-Idempotency-TTL", "X-Device-Id"},
+		ExposedHeaders: []string{"X-Request-ID"},
+		MaxAge:         24 * time.Hour,
+	}
+}
+
+// CORS middleware cho phép mọi origin truy cập (DefaultCORSConfig)
+func CORS(next http.Handler) http.Handler {
+	return CORSWithConfig(DefaultCORSConfig())(next)
+}
+
+// CORSWithConfig enforces cfg: CORS headers are only written for allowed origins,
+// and preflight requests for disallowed origins, methods or headers get 403.
+func CORSWithConfig(cfg CORSConfig) func(http.Handler) http.Handler {
+	policy := newCORSPolicy(cfg)
+
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			origin := r.Header.Get("Origin")
+			preflight := r.Method == http.MethodOptions && r.Header.Get("Access-Control-Request-Method") != ""
+
+			// Not a CORS request (same-origin or non-browser client)
+			if origin == "" {
+				if r.Method == http.MethodOptions {
+					w.WriteHeader(http.StatusNoContent)
+					return
+				}
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			if !policy.allowAny {
+				w.Header().Add("Vary", "Origin")
+			}
+
+			if !policy.originAllowed(origin) {
+				if preflight {
+					w.WriteHeader(http.StatusForbidden)
+					return
+				}
+				// Serve without CORS headers; the browser blocks the response
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			if preflight {
+				w.Header().Add("Vary", "Access-Control-Request-Method")
+				w.Header().Add("Vary", "Access-Control-Request-Headers")
+				if !policy.methodAllowed(r.Header.Get("Access-Control-Request-Method")) ||
+					!policy.headersAllowed(r.Header.Get("Access-Control-Request-Headers")) {
+					w.WriteHeader(http.StatusForbidden)
+					return
+				}
+				policy.writeOriginHeaders(w, origin)
+				w.Header().Set("Access-Control-Allow-Methods", policy.methods)
+				w.Header().Set("Access-Control-Allow-Headers", policy.headers)
+				if policy.maxAge != "" {
+					w.Header().Set("Access-Control-Max-Age", policy.maxAge)
+				}
+				w.WriteHeader(http.StatusNoContent)
+				return
+			}
+
+			policy.writeOriginHeaders(w, origin)
+			if policy.exposed != "" {
+				w.Header().Set("Access-Control-Expose-Headers", policy.exposed)
+			}
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+// corsPolicy is CORSConfig pre-processed for per-request matching
+type corsPolicy struct {
+	allowAny    bool
+	exact       map[string]bool
+	wildcards   []originWildcard
+	methodSet   map[string]bool
+	headerSet   map[string]bool
+	anyHeader   bool
+	methods     string
+	headers     string
+	exposed     string
+	maxAge      string
+	credentials bool
+}
+
+// originWildcard matches "scheme://*.suffix"; an empty scheme matches any scheme
+type originWildcard struct {
+	scheme string
+	suffix string // ".example.com"
+}
+
+func newCORSPolicy(cfg CORSConfig) *corsPolicy {
+	p := &corsPolicy{
+		exact:     make(map[string]bool),
+		methodSet: make(map[string]bool),
+		headerSet: make(map[string]bool),
+		methods:   strings.Join(cfg.AllowedMethods, ", "),
+		headers:   strings.Join(cfg.AllowedHeaders, ", "),
+		exposed:   strings.Join(cfg.ExposedHeaders, ", "),
+	}
+
+	for _, origin := range cfg.AllowedOrigins {
+		origin = strings.ToLower(strings.TrimRight(strings.TrimSpace(origin), "/"))
+		switch {
+		case origin == "":
+		case origin == "*":
+			p.allowAny = true
+		case strings.Contains(origin, "*."):
+			scheme, host, found := strings.Cut(origin, "://")
+			if !found {
+				scheme, host = "", origin
+			}
+			p.wildcards = append(p.wildcards, originWildcard{scheme: scheme, suffix: strings.TrimPrefix(host, "*")})
+		default:
+			p.exact[origin] = true
+		}
+	}
+
+	for _, method := range cfg.AllowedMethods {
+		p.methodSet[strings.ToUpper(strings.TrimSpace(method))] = true
+	}
+	for _, header := range cfg.AllowedHeaders {
+		header = strings.TrimSpace(header)
+		if header == "*" {
+			p.anyHeader = true
+		}
+		p.headerSet[strings.ToLower(header)] = true
+	}
+
+	if cfg.MaxAge > 0 {
+		p.maxAge = strconv.Itoa(int(cfg.MaxAge.Seconds()))
+	}
+	// The spec forbids credentials with a wildcard origin
+	p.credentials = cfg.AllowCredentials && !p.allowAny
+
+	return p
+}
+
+func (p *corsPolicy) originAllowed(origin string) bool {
+	if p.allowAny {
+		return true
+	}
+	origin = strings.ToLower(origin)
+	if p.exact[origin] {
+		return true
+	}
+	if len(p.wildcards) == 0 {
+		return false
+	}
+
+	u, err := url.Parse(origin)
+	if err != nil || u.Host == "" {
+		return false
+	}
+	host := u.Hostname()
+	for _, wc := range p.wildcards {
+		if wc.scheme != "" && wc.scheme != u.Scheme {
+			continue
+		}
+		// Require at least one label before the suffix so "*.example.com" doesn't match "example.com"
+		if strings.HasSuffix(host, wc.suffix) && len(host) > len(wc.suffix) {
+			return true
+		}
+	}
+	return false
+}
+
+func (p *corsPolicy) methodAllowed(method string) bool {
+	return p.methodSet[strings.ToUpper(method)]
+}
+
+// headersAllowed checks a comma separated Access-Control-Request-Headers value
+func (p *corsPolicy) headersAllowed(requested string) bool {
+	if p.anyHeader || requested == "" {
+		return true
+	}
+	return !slices.ContainsFunc(strings.Split(requested, ","), func(header string) bool {
+		header = strings.ToLower(strings.TrimSpace(header))
+		return header != "" && !p.headerSet[header]
+	})
+}
+
+func (p *corsPolicy) writeOriginHeaders(w http.ResponseWriter, origin string) {
+	if p.allowAny {
+		w.Header().Set("Access-Control-Allow-Origin", "*")
+		return
+	}
+	w.Header().Set("Access-Control-Allow-Origin", origin)
+	if p.credentials {
+		w.Header().Set("Access-Control-Allow-Credentials", "true")
+	}
+}
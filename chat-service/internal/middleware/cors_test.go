@@ -0,0 +1,154 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func newCORSHandler(cfg CORSConfig) (http.Handler, *bool) {
+	called := false
+	next := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		called = true
+		w.WriteHeader(http.StatusOK)
+	})
+	return CORSWithConfig(cfg)(next), &called
+}
+
+func restrictedCORSConfig() CORSConfig {
+	return CORSConfig{
+		AllowedOrigins:   []string{"https://app.example.com", "https://*.example.org"},
+		AllowedMethods:   []string{"GET", "POST"},
+		AllowedHeaders:   []string{"Content-Type", "Authorization"},
+		ExposedHeaders:   []string{"X-Request-ID"},
+		AllowCredentials: true,
+		MaxAge:           10 * time.Minute,
+	}
+}
+
+func TestCORS_DefaultAllowsAnyOrigin(t *testing.T) {
+	handler, called := newCORSHandler(DefaultCORSConfig())
+
+	req := httptest.NewRequest(http.MethodGet, "/v1/conversations", nil)
+	req.Header.Set("Origin", "https://anything.test")
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, req)
+
+	assert.True(t, *called)
+	assert.Equal(t, "*", rec.Header().Get("Access-Control-Allow-Origin"))
+	assert.Equal(t, "X-Request-ID", rec.Header().Get("Access-Control-Expose-Headers"))
+	assert.Empty(t, rec.Header().Get("Access-Control-Allow-Credentials"))
+}
+
+func TestCORS_WildcardOriginNeverAllowsCredentials(t *testing.T) {
+	cfg := DefaultCORSConfig()
+	cfg.AllowCredentials = true
+	handler, _ := newCORSHandler(cfg)
+
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+	req.Header.Set("Origin", "https://evil.test")
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, req)
+
+	assert.Equal(t, "*", rec.Header().Get("Access-Control-Allow-Origin"))
+	assert.Empty(t, rec.Header().Get("Access-Control-Allow-Credentials"))
+}
+
+func TestCORS_OriginMatching(t *testing.T) {
+	tests := []struct {
+		origin  string
+		allowed bool
+	}{
+		{"https://app.example.com", true},
+		{"https://APP.example.com", true},
+		{"http://app.example.com", false},
+		{"https://other.example.com", false},
+		{"https://a.example.org", true},
+		{"https://a.b.example.org:8443", true},
+		{"https://example.org", false},
+		{"http://a.example.org", false},
+		{"https://a.example.org.evil.test", false},
+		{"https://evilexample.org", false},
+		{"null", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.origin, func(t *testing.T) {
+			handler, called := newCORSHandler(restrictedCORSConfig())
+
+			req := httptest.NewRequest(http.MethodGet, "/", nil)
+			req.Header.Set("Origin", tt.origin)
+			rec := httptest.NewRecorder()
+			handler.ServeHTTP(rec, req)
+
+			// Non-preflight requests always reach the handler; the browser enforces the policy
+			assert.True(t, *called)
+			assert.Contains(t, rec.Header().Values("Vary"), "Origin")
+			if tt.allowed {
+				assert.Equal(t, tt.origin, rec.Header().Get("Access-Control-Allow-Origin"))
+				assert.Equal(t, "true", rec.Header().Get("Access-Control-Allow-Credentials"))
+				assert.Equal(t, "X-Request-ID", rec.Header().Get("Access-Control-Expose-Headers"))
+			} else {
+				assert.Empty(t, rec.Header().Get("Access-Control-Allow-Origin"))
+				assert.Empty(t, rec.Header().Get("Access-Control-Allow-Credentials"))
+			}
+		})
+	}
+}
+
+func TestCORS_Preflight(t *testing.T) {
+	tests := []struct {
+		name    string
+		origin  string
+		method  string
+		headers string
+		status  int
+	}{
+		{"allowed", "https://app.example.com", "POST", "content-type, authorization", http.StatusNoContent},
+		{"disallowed origin", "https://evil.test", "POST", "", http.StatusForbidden},
+		{"disallowed method", "https://app.example.com", "DELETE", "", http.StatusForbidden},
+		{"disallowed header", "https://app.example.com", "POST", "Content-Type, X-Debug", http.StatusForbidden},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			handler, called := newCORSHandler(restrictedCORSConfig())
+
+			req := httptest.NewRequest(http.MethodOptions, "/v1/messages", nil)
+			req.Header.Set("Origin", tt.origin)
+			req.Header.Set("Access-Control-Request-Method", tt.method)
+			if tt.headers != "" {
+				req.Header.Set("Access-Control-Request-Headers", tt.headers)
+			}
+			rec := httptest.NewRecorder()
+			handler.ServeHTTP(rec, req)
+
+			assert.False(t, *called, "preflight must not reach the gateway")
+			assert.Equal(t, tt.status, rec.Code)
+			if tt.status != http.StatusNoContent {
+				assert.Empty(t, rec.Header().Get("Access-Control-Allow-Origin"))
+				return
+			}
+			assert.Equal(t, tt.origin, rec.Header().Get("Access-Control-Allow-Origin"))
+			assert.Equal(t, "GET, POST", rec.Header().Get("Access-Control-Allow-Methods"))
+			assert.Equal(t, "Content-Type, Authorization", rec.Header().Get("Access-Control-Allow-Headers"))
+			assert.Equal(t, "600", rec.Header().Get("Access-Control-Max-Age"))
+			assert.Equal(t, "true", rec.Header().Get("Access-Control-Allow-Credentials"))
+		})
+	}
+}
+
+func TestCORS_NoOrigin(t *testing.T) {
+	handler, called := newCORSHandler(restrictedCORSConfig())
+
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, req)
+
+	assert.True(t, *called)
+	assert.Empty(t, rec.Header().Get("Access-Control-Allow-Origin"))
+	assert.Empty(t, rec.Header().Values("Vary"))
+}
@@ -25,6 +25,7 @@ func GrpcLogger(logger *zap.Logger) grpc.UnaryServerInterceptor {
 		}
 
 		logger.Info("grpc request",
+			zap.String("request_id", RequestIDFromContext(ctx)),
 			zap.String("method", info.FullMethod),
 			zap.Int("status_code", int(statusCode)),
 			zap.String("status", statusCode.String()),
@@ -42,6 +43,7 @@ func GrpcRecovery(logger *zap.Logger) grpc.UnaryServerInterceptor {
 		defer func() {
 			if r := recover(); r != nil {
 				logger.Error("grpc panic recovery",
+					zap.String("request_id", RequestIDFromContext(ctx)),
 					zap.Any("panic", r),
 					zap.String("stack", string(debug.Stack())),
 				)
@@ -51,3 +53,21 @@ func GrpcRecovery(logger *zap.Logger) grpc.UnaryServerInterceptor {
 		return handler(ctx, req)
 	}
 }
+
+// GrpcStreamRecovery recovers from panics in streaming handlers
+func GrpcStreamRecovery(logger *zap.Logger) grpc.StreamServerInterceptor {
+	return func(srv interface{}, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) (err error) {
+		defer func() {
+			if r := recover(); r != nil {
+				logger.Error("grpc stream panic recovery",
+					zap.String("request_id", RequestIDFromContext(ss.Context())),
+					zap.String("method", info.FullMethod),
+					zap.Any("panic", r),
+					zap.String("stack", string(debug.Stack())),
+				)
+				err = status.Errorf(codes.Internal, "internal server error")
+			}
+		}()
+		return handler(srv, ss)
+	}
+}
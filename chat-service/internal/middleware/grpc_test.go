@@ -182,3 +182,46 @@ func TestGrpcRecovery_WithHandlerError(t *testing.T) {
 	assert.Nil(t, resp)
 	assert.Equal(t, expectedErr, err)
 }
+
+// testServerStream is a grpc.ServerStream that only carries a context
+type testServerStream struct {
+	grpc.ServerStream
+	ctx context.Context
+}
+
+func (s *testServerStream) Context() context.Context {
+	return s.ctx
+}
+
+func TestGrpcStreamRecovery_WithPanic(t *testing.T) {
+	logger, _ := zap.NewDevelopment()
+	interceptor := GrpcStreamRecovery(logger)
+
+	handler := func(srv interface{}, stream grpc.ServerStream) error {
+		panic("test panic")
+	}
+
+	info := &grpc.StreamServerInfo{FullMethod: "/test.Service/Stream", IsServerStream: true}
+
+	err := interceptor(nil, &testServerStream{ctx: context.Background()}, info, handler)
+
+	require.Error(t, err)
+	st, ok := status.FromError(err)
+	require.True(t, ok)
+	assert.Equal(t, codes.Internal, st.Code())
+}
+
+func TestGrpcStreamRecovery_WithHandlerError(t *testing.T) {
+	logger, _ := zap.NewDevelopment()
+	interceptor := GrpcStreamRecovery(logger)
+
+	expectedErr := status.Error(codes.Unavailable, "reconnect")
+	handler := func(srv interface{}, stream grpc.ServerStream) error {
+		return expectedErr
+	}
+
+	info := &grpc.StreamServerInfo{FullMethod: "/test.Service/Stream", IsServerStream: true}
+
+	err := interceptor(nil, &testServerStream{ctx: context.Background()}, info, handler)
+	assert.Equal(t, expectedErr, err)
+}
@@ -1,8 +1,12 @@
 package middleware
 
 import (
+	"chat-service/internal/apierror"
+	"chat-service/internal/auth"
 	ctxkeys "chat-service/internal/context"
 	"context"
+	"encoding/json"
+	"errors"
 	"net/http"
 	"runtime/debug"
 	"strings"
@@ -12,26 +16,9 @@ import (
 	"go.uber.org/zap"
 	"google.golang.org/grpc/metadata"
 	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/encoding/protojson"
 )
 
-// CORS middleware cho phép mọi origin truy cập
-func CORS(next http.Handler) http.Handler {
-	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-		w.Header().Set("Access-Control-Allow-Origin", "*")
-		w.Header().Set("Access-Control-Allow-Methods", "GET, POST, PUT, DELETE, OPTIONS, PATCH")
-		w.Header().Set("Access-Control-Allow-Headers", "Content-Type, Authorization, x-user-id, X-User-Id")
-		w.Header().Set("Access-Control-Max-Age", "86400")
-
-		// Handle preflight request
-		if r.Method == http.MethodOptions {
-			w.WriteHeader(http.StatusNoContent)
-			return
-		}
-
-		next.ServeHTTP(w, r)
-	})
-}
-
 // responseWriter wraps http.ResponseWriter to capture status and size.
 type responseWriter struct {
 	http.ResponseWriter
@@ -63,6 +50,7 @@ func HTTPLogger(logger *zap.Logger) func(http.Handler) http.Handler {
 			next.ServeHTTP(wrapper, r)
 
 			logger.Info("http request",
+				zap.String("request_id", RequestIDFromContext(r.Context())),
 				zap.String("method", r.Method),
 				zap.String("path", r.URL.Path),
 				zap.Int("status", wrapper.status),
@@ -80,6 +68,7 @@ func HTTPRecovery(logger *zap.Logger) func(http.Handler) http.Handler {
 			defer func() {
 				if rec := recover(); rec != nil {
 					logger.Error("http panic recovery",
+						zap.String("request_id", RequestIDFromContext(r.Context())),
 						zap.Any("panic", rec),
 						zap.String("stack", string(debug.Stack())),
 					)
@@ -95,13 +84,17 @@ type gatewayErrorResponse struct {
 	Error gatewayErrorBody `json:"error"`
 }
 
+// gatewayErrorBody is the stable error shape for HTTP clients.
+// Code is one of the documented apierror codes; Details are the status details
+// (google.rpc.ErrorInfo, google.rpc.BadRequest, ...) in protojson form with an "@type" field.
 type gatewayErrorBody struct {
-	Code    string        `json:"code"`
-	Message string        `json:"message"`
-	Details []interface{} `json:"details,omitempty"`
+	Code    string            `json:"code"`
+	Message string            `json:"message"`
+	Details []json.RawMessage `json:"details"`
 }
 
 // GatewayErrorHandler returns a custom error handler for grpc-gateway.
+// Every error body has the shape {"error": {"code", "message", "details"}}, see apierror for the codes.
 func GatewayErrorHandler(logger *zap.Logger) runtime.ErrorHandlerFunc {
 	return func(ctx context.Context, mux *runtime.ServeMux, marshaler runtime.Marshaler, w http.ResponseWriter, r *http.Request, err error) {
 		st := status.Convert(err)
@@ -109,24 +102,32 @@ func GatewayErrorHandler(logger *zap.Logger) runtime.ErrorHandlerFunc {
 
 		resp := gatewayErrorResponse{
 			Error: gatewayErrorBody{
-				Code:    st.Code().String(),
+				Code:    apierror.Code(st),
 				Message: st.Message(),
+				Details: make([]json.RawMessage, 0, len(st.Proto().GetDetails())),
 			},
 		}
 
-		if len(st.Details()) > 0 {
-			resp.Error.Details = make([]interface{}, 0, len(st.Details()))
-			resp.Error.Details = append(resp.Error.Details, st.Details()...)
+		for _, detail := range st.Proto().GetDetails() {
+			encoded, marshalErr := protojson.Marshal(detail)
+			if marshalErr != nil {
+				logger.Warn("failed to marshal error detail",
+					zap.String("type", detail.GetTypeUrl()),
+					zap.Error(marshalErr),
+				)
+				continue
+			}
+			resp.Error.Details = append(resp.Error.Details, encoded)
 		}
 
-		payload, marshalErr := marshaler.Marshal(resp)
+		payload, marshalErr := json.Marshal(resp)
 		if marshalErr != nil {
 			logger.Error("failed to marshal gateway error response", zap.Error(marshalErr))
 			http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
 			return
 		}
 
-		w.Header().Set("Content-Type", marshaler.ContentType(resp))
+		w.Header().Set("Content-Type", "application/json")
 		w.WriteHeader(httpStatus)
 		if _, writeErr := w.Write(payload); writeErr != nil {
 			logger.Warn("failed to write gateway error response", zap.Error(writeErr))
@@ -156,29 +157,46 @@ func CustomHeaderMatcher(key string) (string, bool) {
 
 // HTTPAuthExtractor extracts user_id from HTTP headers and injects it into context.
 // This is needed for grpc-gateway in-process handlers which don't go through gRPC interceptors.
-func HTTPAuthExtractor(logger *zap.Logger) func(http.Handler) http.Handler {
+// Accepts the same options as auth.GrpcAuthInterceptor so both transports resolve identity and roles alike.
+func HTTPAuthExtractor(logger *zap.Logger, opts ...auth.Option) func(http.Handler) http.Handler {
 	return func(next http.Handler) http.Handler {
 		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 			ctx := r.Context()
 
-			// Extract user_id from x-user-id header
-			userID := r.Header.Get("x-user-id")
-			if userID != "" {
+			headers := metadata.MD{}
+			if userID := r.Header.Get("x-user-id"); userID != "" {
+				headers.Set("x-user-id", userID)
+			}
+			if authorization := r.Header.Get("authorization"); authorization != "" {
+				headers.Set("authorization", authorization)
+			}
+
+			identity, err := auth.IdentityFromMetadata(headers, opts...)
+			if err == nil {
 				// Inject user_id into both the regular context and gRPC metadata
-				ctx = context.WithValue(ctx, ctxkeys.UserIDKey, userID)
+				ctx = context.WithValue(ctx, ctxkeys.UserIDKey, identity.UserID)
+				if len(identity.Roles) > 0 {
+					ctx = auth.SetRolesInContext(ctx, identity.Roles)
+				}
 
 				// Also add to gRPC metadata for consistency
-				md := metadata.Pairs("x-user-id", userID)
+				md := metadata.Pairs("x-user-id", identity.UserID)
 				ctx = metadata.NewIncomingContext(ctx, md)
 
 				logger.Debug("extracted user_id from HTTP header",
-					zap.String("user_id", userID),
+					zap.String("user_id", identity.UserID),
 					zap.String("path", r.URL.Path),
 				)
-			} else {
+			} else if errors.Is(err, auth.ErrMissingUserID) {
 				logger.Debug("no x-user-id header found",
 					zap.String("path", r.URL.Path),
 				)
+			} else {
+				// Handlers reject the request as unauthenticated since no user_id is set
+				logger.Warn("invalid bearer token",
+					zap.String("path", r.URL.Path),
+					zap.Error(err),
+				)
 			}
 
 			// Update request with new context
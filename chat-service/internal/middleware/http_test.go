@@ -1,16 +1,25 @@
 package middleware
 
 import (
+	"context"
+	"encoding/json"
 	"net/http"
 	"net/http/httptest"
 	"testing"
+	"time"
 
+	"chat-service/internal/apierror"
+	"chat-service/internal/auth"
 	ctxkeys "chat-service/internal/context"
 
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/grpc-ecosystem/grpc-gateway/v2/runtime"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 	"go.uber.org/zap"
+	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
 )
 
 func TestHTTPLogger(t *testing.T) {
@@ -217,6 +226,105 @@ func TestHTTPAuthExtractor_EmptyUserID(t *testing.T) {
 	assert.Equal(t, http.StatusOK, w.Code)
 }
 
+func TestHTTPAuthExtractor_BearerTokenRoles(t *testing.T) {
+	logger, _ := zap.NewDevelopment()
+	secret := "test-secret"
+	token, err := jwt.NewWithClaims(jwt.SigningMethodHS256, jwt.MapClaims{
+		"id":   "admin-1",
+		"role": auth.RoleAdmin,
+		"exp":  time.Now().Add(time.Hour).Unix(),
+	}).SignedString([]byte(secret))
+	require.NoError(t, err)
+
+	handlerCalled := false
+	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		handlerCalled = true
+
+		userID, err := auth.GetUserIDFromContext(r.Context())
+		assert.NoError(t, err)
+		assert.Equal(t, "admin-1", userID)
+		assert.NoError(t, auth.RequireRole(r.Context(), auth.RoleAdmin))
+
+		w.WriteHeader(http.StatusOK)
+	})
+
+	middleware := HTTPAuthExtractor(logger, auth.WithJWTVerifier(auth.NewJWTVerifier(secret)))(handler)
+
+	req := httptest.NewRequest(http.MethodGet, "/test", nil)
+	req.Header.Set("Authorization", "Bearer "+token)
+	w := httptest.NewRecorder()
+
+	middleware.ServeHTTP(w, req)
+
+	assert.True(t, handlerCalled)
+	assert.Equal(t, http.StatusOK, w.Code)
+}
+
+func TestHTTPAuthExtractor_InvalidBearerToken(t *testing.T) {
+	logger, _ := zap.NewDevelopment()
+
+	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		// An invalid token must not fall back to x-user-id
+		_, err := auth.GetUserIDFromContext(r.Context())
+		assert.Error(t, err)
+		w.WriteHeader(http.StatusOK)
+	})
+
+	middleware := HTTPAuthExtractor(logger, auth.WithJWTVerifier(auth.NewJWTVerifier("test-secret")))(handler)
+
+	req := httptest.NewRequest(http.MethodGet, "/test", nil)
+	req.Header.Set("Authorization", "Bearer not-a-jwt")
+	req.Header.Set("x-user-id", "user-1")
+	w := httptest.NewRecorder()
+
+	middleware.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+}
+
+func TestGatewayErrorHandler_StructuredBody(t *testing.T) {
+	logger, _ := zap.NewDevelopment()
+	handler := GatewayErrorHandler(logger)
+
+	req := httptest.NewRequest(http.MethodPost, "/v1/messages", nil)
+	w := httptest.NewRecorder()
+
+	handler(context.Background(), nil, &runtime.JSONPb{}, w, req,
+		apierror.Validation("conversation_id", "conversation_id is required"))
+
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	assert.Equal(t, "application/json", w.Header().Get("Content-Type"))
+
+	var body struct {
+		Error struct {
+			Code    string                   `json:"code"`
+			Message string                   `json:"message"`
+			Details []map[string]interface{} `json:"details"`
+		} `json:"error"`
+	}
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &body))
+	assert.Equal(t, apierror.CodeValidationFailed, body.Error.Code)
+	assert.Equal(t, "conversation_id is required", body.Error.Message)
+	require.Len(t, body.Error.Details, 2)
+	assert.Equal(t, "type.googleapis.com/google.rpc.ErrorInfo", body.Error.Details[0]["@type"])
+	assert.Equal(t, "type.googleapis.com/google.rpc.BadRequest", body.Error.Details[1]["@type"])
+}
+
+func TestGatewayErrorHandler_PlainStatus(t *testing.T) {
+	logger, _ := zap.NewDevelopment()
+	handler := GatewayErrorHandler(logger)
+
+	req := httptest.NewRequest(http.MethodGet, "/v1/conversations", nil)
+	w := httptest.NewRecorder()
+
+	handler(context.Background(), nil, &runtime.JSONPb{}, w, req,
+		status.Error(codes.Unauthenticated, "missing user id"))
+
+	assert.Equal(t, http.StatusUnauthorized, w.Code)
+	// details is always present so clients don't need to nil-check it
+	assert.JSONEq(t, `{"error":{"code":"UNAUTHENTICATED","message":"missing user id","details":[]}}`, w.Body.String())
+}
+
 func TestResponseWriter_WriteHeader(t *testing.T) {
 	w := httptest.NewRecorder()
 	wrapper := &responseWriter{
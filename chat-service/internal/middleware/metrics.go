@@ -1,19 +1,130 @@
 package middleware
 
 import (
+	"context"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/grpc-ecosystem/grpc-gateway/v2/runtime"
 	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/status"
 )
 
+const metricsNamespace = "chat_server"
+
+// Metrics holds the RED metrics (rate, errors, duration) of the chat API server.
+type Metrics struct {
+	// gRPC requests by method and status code
+	GRPCRequestsTotal *prometheus.CounterVec
+
+	// gRPC requests that returned a non-OK code, by method and status code
+	GRPCErrorsTotal *prometheus.CounterVec
+
+	// gRPC handling latency by method
+	GRPCRequestDuration *prometheus.HistogramVec
+
+	// HTTP gateway requests by method, route pattern and status
+	HTTPRequestsTotal *prometheus.CounterVec
+
+	// HTTP gateway handling latency by method and route pattern
+	HTTPRequestDuration *prometheus.HistogramVec
+}
+
+// NewMetrics creates and registers the server metrics on registry.
+func NewMetrics(registry prometheus.Registerer) *Metrics {
+	factory := promauto.With(registry)
+
+	return &Metrics{
+		GRPCRequestsTotal: factory.NewCounterVec(prometheus.CounterOpts{
+			Namespace: metricsNamespace,
+			Name:      "grpc_requests_total",
+			Help:      "Total number of gRPC requests handled",
+		}, []string{"method", "code"}),
+
+		GRPCErrorsTotal: factory.NewCounterVec(prometheus.CounterOpts{
+			Namespace: metricsNamespace,
+			Name:      "grpc_errors_total",
+			Help:      "Total number of gRPC requests that returned a non-OK code",
+		}, []string{"method", "code"}),
+
+		GRPCRequestDuration: factory.NewHistogramVec(prometheus.HistogramOpts{
+			Namespace: metricsNamespace,
+			Name:      "grpc_request_duration_seconds",
+			Help:      "Time spent handling gRPC requests",
+			Buckets:   []float64{0.005, 0.01, 0.025, 0.05, 0.1, 0.25, 0.5, 1, 2.5, 5},
+		}, []string{"method"}),
+
+		HTTPRequestsTotal: factory.NewCounterVec(prometheus.CounterOpts{
+			Namespace: metricsNamespace,
+			Name:      "http_requests_total",
+			Help:      "Total number of HTTP gateway requests handled",
+		}, []string{"method", "route", "status"}),
+
+		HTTPRequestDuration: factory.NewHistogramVec(prometheus.HistogramOpts{
+			Namespace: metricsNamespace,
+			Name:      "http_request_duration_seconds",
+			Help:      "Time spent handling HTTP gateway requests",
+			Buckets:   []float64{0.005, 0.01, 0.025, 0.05, 0.1, 0.25, 0.5, 1, 2.5, 5},
+		}, []string{"method", "route"}),
+	}
+}
+
 var (
-	HttpRequestsTotal = prometheus.NewCounterVec(
-		prometheus.CounterOpts{
-			Name: "http_requests_total",
-			Help: "Number of HTTP requests",
-		},
-		[]string{"path", "method", "status"},
-	)
+	defaultMetrics     *Metrics
+	defaultMetricsOnce sync.Once
 )
 
-func InitMetrics() {
-	prometheus.MustRegister(HttpRequestsTotal)
+// DefaultMetrics returns the metrics registered on the default Prometheus registry,
+// which is what promhttp.Handler() serves.
+func DefaultMetrics() *Metrics {
+	defaultMetricsOnce.Do(func() {
+		defaultMetrics = NewMetrics(prometheus.DefaultRegisterer)
+	})
+	return defaultMetrics
+}
+
+// GrpcMetrics records count, errors and latency of unary RPCs
+func GrpcMetrics(m *Metrics) grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		startTime := time.Now()
+
+		resp, err := handler(ctx, req)
+
+		code := status.Code(err).String()
+		m.GRPCRequestsTotal.WithLabelValues(info.FullMethod, code).Inc()
+		if err != nil {
+			m.GRPCErrorsTotal.WithLabelValues(info.FullMethod, code).Inc()
+		}
+		m.GRPCRequestDuration.WithLabelValues(info.FullMethod).Observe(time.Since(startTime).Seconds())
+
+		return resp, err
+	}
+}
+
+// GatewayMetrics records HTTP gateway requests. The gateway calls the service in-process,
+// so gRPC interceptors don't see these requests. It is installed with runtime.WithMiddlewares,
+// which runs after route matching and lets us label by route pattern rather than raw path.
+func GatewayMetrics(m *Metrics) runtime.Middleware {
+	return func(next runtime.HandlerFunc) runtime.HandlerFunc {
+		return func(w http.ResponseWriter, r *http.Request, pathParams map[string]string) {
+			startTime := time.Now()
+			wrapper := &responseWriter{
+				ResponseWriter: w,
+				status:         http.StatusOK,
+			}
+
+			next(wrapper, r, pathParams)
+
+			route := "unknown"
+			if pattern, ok := runtime.HTTPPattern(r.Context()); ok {
+				route = pattern.String()
+			}
+			m.HTTPRequestsTotal.WithLabelValues(r.Method, route, strconv.Itoa(wrapper.status)).Inc()
+			m.HTTPRequestDuration.WithLabelValues(r.Method, route).Observe(time.Since(startTime).Seconds())
+		}
+	}
 }
@@ -0,0 +1,78 @@
+package middleware
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/grpc-ecosystem/grpc-gateway/v2/runtime"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func TestNewMetrics(t *testing.T) {
+	registry := prometheus.NewRegistry()
+	m := NewMetrics(registry)
+
+	require.NotNil(t, m)
+	assert.NotNil(t, m.GRPCRequestsTotal)
+	assert.NotNil(t, m.GRPCErrorsTotal)
+	assert.NotNil(t, m.GRPCRequestDuration)
+	assert.NotNil(t, m.HTTPRequestsTotal)
+	assert.NotNil(t, m.HTTPRequestDuration)
+}
+
+func TestGrpcMetrics_RecordsByMethodAndCode(t *testing.T) {
+	m := NewMetrics(prometheus.NewRegistry())
+	interceptor := GrpcMetrics(m)
+	info := &grpc.UnaryServerInfo{FullMethod: "/chat.v1.ChatService/SendMessage"}
+
+	ok := func(ctx context.Context, req interface{}) (interface{}, error) { return "ok", nil }
+	fail := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return nil, status.Error(codes.InvalidArgument, "bad request")
+	}
+
+	_, err := interceptor(context.Background(), nil, info, ok)
+	require.NoError(t, err)
+	_, err = interceptor(context.Background(), nil, info, ok)
+	require.NoError(t, err)
+	_, err = interceptor(context.Background(), nil, info, fail)
+	require.Error(t, err)
+	assert.Equal(t, codes.InvalidArgument, status.Code(err), "interceptor must not change the error")
+
+	assert.Equal(t, 2.0, testutil.ToFloat64(m.GRPCRequestsTotal.WithLabelValues(info.FullMethod, "OK")))
+	assert.Equal(t, 1.0, testutil.ToFloat64(m.GRPCRequestsTotal.WithLabelValues(info.FullMethod, "InvalidArgument")))
+	assert.Equal(t, 0.0, testutil.ToFloat64(m.GRPCErrorsTotal.WithLabelValues(info.FullMethod, "OK")))
+	assert.Equal(t, 1.0, testutil.ToFloat64(m.GRPCErrorsTotal.WithLabelValues(info.FullMethod, "InvalidArgument")))
+	assert.Equal(t, 1, testutil.CollectAndCount(m.GRPCRequestDuration))
+}
+
+func TestGatewayMetrics_LabelsByRoutePattern(t *testing.T) {
+	m := NewMetrics(prometheus.NewRegistry())
+	mux := runtime.NewServeMux(runtime.WithMiddlewares(GatewayMetrics(m)))
+	err := mux.HandlePath(http.MethodGet, "/v1/conversations/{conversation_id}/messages",
+		func(w http.ResponseWriter, r *http.Request, pathParams map[string]string) {
+			if pathParams["conversation_id"] == "missing" {
+				w.WriteHeader(http.StatusNotFound)
+				return
+			}
+			w.WriteHeader(http.StatusOK)
+		})
+	require.NoError(t, err)
+
+	for _, path := range []string{"/v1/conversations/a/messages", "/v1/conversations/b/messages", "/v1/conversations/missing/messages"} {
+		rec := httptest.NewRecorder()
+		mux.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, path, nil))
+	}
+
+	route := "/v1/conversations/{conversation_id=*}/messages"
+	assert.Equal(t, 2.0, testutil.ToFloat64(m.HTTPRequestsTotal.WithLabelValues("GET", route, "200")))
+	assert.Equal(t, 1.0, testutil.ToFloat64(m.HTTPRequestsTotal.WithLabelValues("GET", route, "404")))
+	assert.Equal(t, 2, testutil.CollectAndCount(m.HTTPRequestsTotal), "raw paths must not become label values")
+}
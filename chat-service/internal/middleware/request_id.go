@@ -0,0 +1,74 @@
+package middleware
+
+import (
+	"context"
+	"net/http"
+
+	ctxkeys "chat-service/internal/context"
+
+	"github.com/google/uuid"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+)
+
+const (
+	// RequestIDHeader carries the correlation id on HTTP requests and responses
+	RequestIDHeader = "X-Request-ID"
+	// requestIDMetadataKey is the gRPC metadata key for the correlation id
+	requestIDMetadataKey = "x-request-id"
+	// maxRequestIDLength bounds client-supplied ids so they can't bloat logs and payloads
+	maxRequestIDLength = 128
+)
+
+// RequestIDFromContext returns the request id set by the request id middleware ("" if none)
+func RequestIDFromContext(ctx context.Context) string {
+	requestID, _ := ctx.Value(ctxkeys.RequestIDKey).(string)
+	return requestID
+}
+
+// sanitizeRequestID keeps a client-supplied id if it is short, printable ASCII; otherwise generates a new one
+func sanitizeRequestID(requestID string) string {
+	if requestID == "" || len(requestID) > maxRequestIDLength {
+		return uuid.NewString()
+	}
+	for i := 0; i < len(requestID); i++ {
+		if requestID[i] < 0x21 || requestID[i] > 0x7e {
+			return uuid.NewString()
+		}
+	}
+	return requestID
+}
+
+// HTTPRequestID reads X-Request-ID (or generates one), injects it into context and echoes it in the response.
+// The header is rewritten on the request so grpc-gateway forwards the same id as x-request-id metadata.
+func HTTPRequestID(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requestID := sanitizeRequestID(r.Header.Get(RequestIDHeader))
+
+		r.Header.Set(RequestIDHeader, requestID)
+		w.Header().Set(RequestIDHeader, requestID)
+
+		ctx := context.WithValue(r.Context(), ctxkeys.RequestIDKey, requestID)
+		next.ServeHTTP(w, r.WithContext(ctx))
+	})
+}
+
+// GrpcRequestID reads x-request-id metadata (or generates one), injects it into context
+// and returns it in the response header. Must run before GrpcLogger so the id is logged.
+func GrpcRequestID() grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		var requestID string
+		if md, ok := metadata.FromIncomingContext(ctx); ok {
+			if values := md.Get(requestIDMetadataKey); len(values) > 0 {
+				requestID = values[0]
+			}
+		}
+		requestID = sanitizeRequestID(requestID)
+
+		// Best effort: SetHeader only fails if headers were already sent
+		_ = grpc.SetHeader(ctx, metadata.Pairs(requestIDMetadataKey, requestID))
+
+		ctx = context.WithValue(ctx, ctxkeys.RequestIDKey, requestID)
+		return handler(ctx, req)
+	}
+}
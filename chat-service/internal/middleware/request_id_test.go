@@ -0,0 +1,81 @@
+package middleware
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+)
+
+func serveWithRequestID(t *testing.T, incoming string) (ctxID, forwardedID, responseID string) {
+	t.Helper()
+	handler := HTTPRequestID(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		ctxID = RequestIDFromContext(r.Context())
+		forwardedID = r.Header.Get(RequestIDHeader)
+		w.WriteHeader(http.StatusOK)
+	}))
+
+	req := httptest.NewRequest(http.MethodGet, "/test", nil)
+	if incoming != "" {
+		req.Header.Set(RequestIDHeader, incoming)
+	}
+	w := httptest.NewRecorder()
+	handler.ServeHTTP(w, req)
+
+	return ctxID, forwardedID, w.Header().Get(RequestIDHeader)
+}
+
+func TestHTTPRequestID_KeepsIncoming(t *testing.T) {
+	ctxID, forwardedID, responseID := serveWithRequestID(t, "req-abc-123")
+
+	assert.Equal(t, "req-abc-123", ctxID)
+	assert.Equal(t, "req-abc-123", forwardedID)
+	assert.Equal(t, "req-abc-123", responseID)
+}
+
+func TestHTTPRequestID_GeneratesWhenMissing(t *testing.T) {
+	ctxID, forwardedID, responseID := serveWithRequestID(t, "")
+
+	assert.NotEmpty(t, ctxID)
+	assert.Equal(t, ctxID, forwardedID, "gateway must forward the generated id")
+	assert.Equal(t, ctxID, responseID)
+}
+
+func TestHTTPRequestID_ReplacesInvalid(t *testing.T) {
+	for _, incoming := range []string{"has space", strings.Repeat("a", maxRequestIDLength+1)} {
+		ctxID, _, responseID := serveWithRequestID(t, incoming)
+
+		assert.NotEqual(t, incoming, ctxID)
+		assert.NotEmpty(t, ctxID)
+		assert.Equal(t, ctxID, responseID)
+	}
+}
+
+func TestGrpcRequestID(t *testing.T) {
+	interceptor := GrpcRequestID()
+	info := &grpc.UnaryServerInfo{FullMethod: "/test.Service/Method"}
+
+	var got string
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		got = RequestIDFromContext(ctx)
+		return nil, nil
+	}
+
+	// From incoming metadata
+	ctx := metadata.NewIncomingContext(context.Background(), metadata.Pairs("x-request-id", "req-grpc-1"))
+	_, err := interceptor(ctx, nil, info, handler)
+	require.NoError(t, err)
+	assert.Equal(t, "req-grpc-1", got)
+
+	// Generated when absent
+	_, err = interceptor(context.Background(), nil, info, handler)
+	require.NoError(t, err)
+	assert.NotEmpty(t, got)
+	assert.NotEqual(t, "req-grpc-1", got)
+}
@@ -0,0 +1,123 @@
+package outbox
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"time"
+)
+
+// DefaultStaleAfter is how long the poll loop may go without finishing a cycle before Health
+// reports it stalled.
+const DefaultStaleAfter = time.Minute
+
+// Processor states reported by Health
+const (
+	HealthOK         = "ok"
+	HealthDraining   = "draining"    // Stop was called; the current batch is finishing
+	HealthNotRunning = "not_running" // the poll loop has not started or has exited
+	HealthStalled    = "stalled"     // no poll cycle finished within the stale window
+)
+
+// Errors returned by CheckHealth
+var (
+	ErrProcessorDraining   = errors.New("processor is draining")
+	ErrProcessorNotRunning = errors.New("processor is not running")
+	ErrProcessorStalled    = errors.New("processor is stalled")
+)
+
+// HealthStatus is a snapshot of the poll loop, served as JSON by HealthHandler.
+// Failed polls (e.g. during a database outage) still count as finished cycles: they show in
+// ConsecutiveFailures and the readiness checks, but restarting the processor would not fix them.
+type HealthStatus struct {
+	Status              string    `json:"status"`
+	Running             bool      `json:"running"`
+	LastPoll            time.Time `json:"last_poll,omitzero"`            // last finished poll cycle
+	LastSuccessfulPoll  time.Time `json:"last_successful_poll,omitzero"` // last cycle without error
+	ConsecutiveFailures int64     `json:"consecutive_failures"`
+}
+
+// recordCycle records the outcome of a finished poll cycle
+func (p *Processor) recordCycle(now time.Time, err error) {
+	p.lastCycle.Store(now.UnixNano())
+	if err != nil {
+		p.pollFailures.Add(1)
+		return
+	}
+	p.lastSuccess.Store(now.UnixNano())
+	p.pollFailures.Store(0)
+}
+
+// staleWindow returns how long the loop may go without finishing a cycle. It is never shorter
+// than three of the longest waits between polls, so an idle or follower replica is not stalled.
+func (p *Processor) staleWindow() time.Duration {
+	window := p.staleAfter
+	if window <= 0 {
+		window = DefaultStaleAfter
+	}
+	longest := p.backoff.max
+	if p.elector != nil && p.followerPollInterval > longest {
+		longest = p.followerPollInterval
+	}
+	if window < 3*longest {
+		window = 3 * longest
+	}
+	return window
+}
+
+// Health reports the state of the poll loop at now.
+func (p *Processor) Health(now time.Time) HealthStatus {
+	h := HealthStatus{
+		Status:              HealthOK,
+		Running:             p.running.Load(),
+		LastPoll:            unixNanoTime(p.lastCycle.Load()),
+		LastSuccessfulPoll:  unixNanoTime(p.lastSuccess.Load()),
+		ConsecutiveFailures: p.pollFailures.Load(),
+	}
+	switch {
+	case !h.Running:
+		h.Status = HealthNotRunning
+	case p.stopping.Load():
+		h.Status = HealthDraining
+	case now.Sub(h.LastPoll) > p.staleWindow():
+		// Start records its start time as the last cycle, so this means a hung batch
+		h.Status = HealthStalled
+	}
+	return h
+}
+
+// CheckHealth is Health as a health.Check: it fails unless the processor is ok.
+func (p *Processor) CheckHealth(context.Context) error {
+	switch p.Health(time.Now()).Status {
+	case HealthDraining:
+		return ErrProcessorDraining
+	case HealthNotRunning:
+		return ErrProcessorNotRunning
+	case HealthStalled:
+		return ErrProcessorStalled
+	}
+	return nil
+}
+
+// HealthHandler serves Health as JSON: 200 when the processor is ok, 503 otherwise, so an
+// orchestrator can restart a processor whose poll loop exited or hung.
+func (p *Processor) HealthHandler(w http.ResponseWriter, _ *http.Request) {
+	h := p.Health(time.Now())
+	code := http.StatusOK
+	if h.Status != HealthOK {
+		code = http.StatusServiceUnavailable
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Cache-Control", "no-store")
+	w.WriteHeader(code)
+	_ = json.NewEncoder(w).Encode(h)
+}
+
+// unixNanoTime converts a stored unix nano timestamp, 0 being never
+func unixNanoTime(nanos int64) time.Time {
+	if nanos == 0 {
+		return time.Time{}
+	}
+	return time.Unix(0, nanos).UTC()
+}
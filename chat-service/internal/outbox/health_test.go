@@ -0,0 +1,113 @@
+package outbox
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+// TestProcessorHealth_Lifecycle verifies the states reported from before Start to after Stop
+func TestProcessorHealth_Lifecycle(t *testing.T) {
+	require := require.New(t)
+
+	processor := NewProcessor(nil, nil, zap.NewNop(), ProcessorConfig{PollInterval: time.Hour})
+	require.Equal(HealthNotRunning, processor.Health(time.Now()).Status)
+	require.ErrorIs(processor.CheckHealth(context.Background()), ErrProcessorNotRunning)
+
+	go processor.Start(context.Background())
+	require.Eventually(processor.IsRunning, time.Second, 10*time.Millisecond)
+	h := processor.Health(time.Now())
+	require.Equal(HealthOK, h.Status)
+	require.False(h.LastPoll.IsZero(), "Start counts as the last cycle until the first poll")
+	require.True(h.LastSuccessfulPoll.IsZero())
+	require.NoError(processor.CheckHealth(context.Background()))
+
+	processor.stopping.Store(true) // as Stop does before the loop exits
+	require.ErrorIs(processor.CheckHealth(context.Background()), ErrProcessorDraining)
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	require.NoError(processor.Stop(ctx))
+	require.Equal(HealthNotRunning, processor.Health(time.Now()).Status)
+}
+
+// TestProcessorHealth_Stalled verifies only a loop that stops finishing cycles is stalled;
+// failing cycles are counted but keep the processor healthy
+func TestProcessorHealth_Stalled(t *testing.T) {
+	require := require.New(t)
+
+	processor := NewProcessor(nil, nil, zap.NewNop(), ProcessorConfig{
+		PollInterval:    100 * time.Millisecond,
+		MaxPollInterval: time.Second,
+		StaleAfter:      10 * time.Second,
+	})
+	processor.running.Store(true)
+	start := time.Now()
+
+	processor.recordCycle(start, nil)
+	processor.recordCycle(start.Add(time.Second), errors.New("db down"))
+	processor.recordCycle(start.Add(2*time.Second), errors.New("db down"))
+	h := processor.Health(start.Add(5 * time.Second))
+	require.Equal(HealthOK, h.Status)
+	require.Equal(int64(2), h.ConsecutiveFailures)
+	require.True(h.LastSuccessfulPoll.Equal(start))
+
+	h = processor.Health(start.Add(13 * time.Second))
+	require.Equal(HealthStalled, h.Status)
+
+	processor.recordCycle(start.Add(13*time.Second), nil)
+	h = processor.Health(start.Add(14 * time.Second))
+	require.Equal(HealthOK, h.Status)
+	require.Zero(h.ConsecutiveFailures)
+}
+
+// TestProcessorHealth_StaleWindowCoversPollIntervals verifies a short StaleAfter cannot flag
+// a processor that is merely waiting for its next backed-off or follower poll
+func TestProcessorHealth_StaleWindowCoversPollIntervals(t *testing.T) {
+	require := require.New(t)
+
+	processor := NewProcessor(nil, nil, zap.NewNop(), ProcessorConfig{
+		PollInterval:    time.Second,
+		MaxPollInterval: 2 * time.Second,
+		StaleAfter:      time.Second,
+	})
+	require.Equal(6*time.Second, processor.staleWindow())
+
+	processor.SetLeaderElector(&LeaderElector{}, 5*time.Second)
+	require.Equal(15*time.Second, processor.staleWindow())
+
+	require.Equal(DefaultStaleAfter, NewProcessor(nil, nil, zap.NewNop(), ProcessorConfig{PollInterval: time.Second}).staleWindow())
+}
+
+// TestProcessorHealthHandler verifies /health answers 503 unless the processor is ok
+func TestProcessorHealthHandler(t *testing.T) {
+	require := require.New(t)
+
+	processor := NewProcessor(nil, nil, zap.NewNop(), ProcessorConfig{PollInterval: time.Hour})
+	serve := func() (int, HealthStatus) {
+		rec := httptest.NewRecorder()
+		processor.HealthHandler(rec, httptest.NewRequest(http.MethodGet, "/health", nil))
+		var h HealthStatus
+		require.NoError(json.Unmarshal(rec.Body.Bytes(), &h))
+		return rec.Code, h
+	}
+
+	code, h := serve()
+	require.Equal(http.StatusServiceUnavailable, code)
+	require.Equal(HealthNotRunning, h.Status)
+
+	processor.running.Store(true)
+	processor.recordCycle(time.Now(), nil)
+	code, h = serve()
+	require.Equal(http.StatusOK, code)
+	require.Equal(HealthOK, h.Status)
+	require.True(h.Running)
+	require.False(h.LastSuccessfulPoll.IsZero())
+}
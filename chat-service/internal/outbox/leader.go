@@ -0,0 +1,169 @@
+package outbox
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+	"go.uber.org/zap"
+)
+
+const (
+	// DefaultLeaderKey is the Redis key holding the outbox leader lease.
+	DefaultLeaderKey = "outbox:leader"
+
+	// DefaultLeaseTTL is how long a lease survives without renewal.
+	// A crashed leader is replaced at most this long after its last renewal.
+	DefaultLeaseTTL = 10 * time.Second
+
+	// leaseReleaseTimeout bounds the best-effort release on shutdown.
+	leaseReleaseTimeout = 2 * time.Second
+)
+
+// acquireOrRenewScript takes the lease when it is free and extends it when we
+// already hold it, atomically, so two replicas can never both believe they lead.
+var acquireOrRenewScript = redis.NewScript(`
+local current = redis.call("GET", KEYS[1])
+if not current then
+	redis.call("SET", KEYS[1], ARGV[1], "PX", ARGV[2])
+	return 1
+end
+if current == ARGV[1] then
+	redis.call("PEXPIRE", KEYS[1], ARGV[2])
+	return 1
+end
+return 0
+`)
+
+// releaseScript deletes the lease only if we still hold it.
+var releaseScript = redis.NewScript(`
+if redis.call("GET", KEYS[1]) == ARGV[1] then
+	return redis.call("DEL", KEYS[1])
+end
+return 0
+`)
+
+// LeaderElectionConfig holds configuration for outbox leader election.
+type LeaderElectionConfig struct {
+	Key           string        // Redis lease key (default: outbox:leader)
+	InstanceID    string        // Unique id of this replica, stored as the lease value
+	LeaseTTL      time.Duration // Lease lifetime (default: 10s)
+	RenewInterval time.Duration // How often the lease is acquired/renewed (default: LeaseTTL/3)
+}
+
+// LeaderElector elects a single outbox replica through a Redis lease key with a TTL.
+// It is advisory: SKIP LOCKED still keeps concurrent pollers correct, the lease only
+// decides which replica polls aggressively.
+type LeaderElector struct {
+	client        redis.Cmdable
+	logger        *zap.Logger
+	metrics       *Metrics
+	key           string
+	instanceID    string
+	leaseTTL      time.Duration
+	renewInterval time.Duration
+	leader        atomic.Bool
+}
+
+// NewLeaderElector creates a leader elector. metrics may be nil.
+func NewLeaderElector(client redis.Cmdable, logger *zap.Logger, cfg LeaderElectionConfig, metrics *Metrics) *LeaderElector {
+	key := cfg.Key
+	if key == "" {
+		key = DefaultLeaderKey
+	}
+
+	leaseTTL := cfg.LeaseTTL
+	if leaseTTL <= 0 {
+		leaseTTL = DefaultLeaseTTL
+	}
+
+	renewInterval := cfg.RenewInterval
+	if renewInterval <= 0 || renewInterval >= leaseTTL {
+		renewInterval = leaseTTL / 3
+	}
+
+	return &LeaderElector{
+		client:        client,
+		logger:        logger,
+		metrics:       metrics,
+		key:           key,
+		instanceID:    cfg.InstanceID,
+		leaseTTL:      leaseTTL,
+		renewInterval: renewInterval,
+	}
+}
+
+// IsLeader reports whether this replica currently holds the lease.
+func (e *LeaderElector) IsLeader() bool {
+	return e.leader.Load()
+}
+
+// Run acquires and renews the lease until ctx is cancelled, then releases it
+// so a follower can take over without waiting for the TTL.
+func (e *LeaderElector) Run(ctx context.Context) {
+	e.tryAcquire(ctx)
+
+	ticker := time.NewTicker(e.renewInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			e.release()
+			return
+		case <-ticker.C:
+			e.tryAcquire(ctx)
+		}
+	}
+}
+
+// tryAcquire takes or renews the lease. On Redis errors leadership is dropped:
+// we cannot prove we still hold the lease, and another replica may have it.
+func (e *LeaderElector) tryAcquire(ctx context.Context) {
+	acquired, err := acquireOrRenewScript.Run(ctx, e.client, []string{e.key}, e.instanceID, e.leaseTTL.Milliseconds()).Int()
+	if err != nil {
+		if ctx.Err() == nil {
+			e.logger.Warn("outbox leader lease check failed", zap.Error(err))
+		}
+		e.setLeader(false)
+		return
+	}
+	e.setLeader(acquired == 1)
+}
+
+// release gives up the lease if we still hold it.
+func (e *LeaderElector) release() {
+	wasLeader := e.leader.Load()
+	e.setLeader(false)
+	if !wasLeader {
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), leaseReleaseTimeout)
+	defer cancel()
+
+	if err := releaseScript.Run(ctx, e.client, []string{e.key}, e.instanceID).Err(); err != nil && !errors.Is(err, redis.Nil) {
+		e.logger.Warn("failed to release outbox leader lease", zap.Error(err))
+	}
+}
+
+// setLeader records leadership changes in logs and metrics.
+func (e *LeaderElector) setLeader(isLeader bool) {
+	if e.leader.Swap(isLeader) != isLeader {
+		if isLeader {
+			e.logger.Info("acquired outbox leadership", zap.String("instance_id", e.instanceID))
+		} else {
+			e.logger.Info("lost outbox leadership", zap.String("instance_id", e.instanceID))
+		}
+	}
+
+	if e.metrics != nil && e.metrics.IsLeader != nil {
+		if isLeader {
+			e.metrics.IsLeader.Set(1)
+		} else {
+			e.metrics.IsLeader.Set(0)
+		}
+	}
+}
@@ -0,0 +1,167 @@
+package outbox
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/redis/go-redis/v9"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+func newLeaderTestRedis(t *testing.T) (*miniredis.Miniredis, *redis.Client) {
+	mr := miniredis.RunT(t)
+	client := redis.NewClient(&redis.Options{Addr: mr.Addr()})
+	t.Cleanup(func() { _ = client.Close() })
+	return mr, client
+}
+
+func newTestElector(client *redis.Client, id string) *LeaderElector {
+	return NewLeaderElector(client, zap.NewNop(), LeaderElectionConfig{
+		InstanceID: id,
+		LeaseTTL:   time.Second,
+	}, nil)
+}
+
+// TestLeaderElector_SingleLeader verifies only one replica acquires the lease
+func TestLeaderElector_SingleLeader(t *testing.T) {
+	require := require.New(t)
+	mr, client := newLeaderTestRedis(t)
+	ctx := context.Background()
+
+	a := newTestElector(client, "replica-a")
+	b := newTestElector(client, "replica-b")
+
+	a.tryAcquire(ctx)
+	b.tryAcquire(ctx)
+
+	require.True(a.IsLeader())
+	require.False(b.IsLeader())
+
+	value, err := mr.Get(DefaultLeaderKey)
+	require.NoError(err)
+	require.Equal("replica-a", value)
+
+	// Renewal keeps the same leader
+	a.tryAcquire(ctx)
+	b.tryAcquire(ctx)
+	require.True(a.IsLeader())
+	require.False(b.IsLeader())
+}
+
+// TestLeaderElector_FailoverAfterLeaseExpiry verifies a follower takes over when the leader stops renewing
+func TestLeaderElector_FailoverAfterLeaseExpiry(t *testing.T) {
+	require := require.New(t)
+	mr, client := newLeaderTestRedis(t)
+	ctx := context.Background()
+
+	a := newTestElector(client, "replica-a")
+	b := newTestElector(client, "replica-b")
+
+	a.tryAcquire(ctx)
+	require.True(a.IsLeader())
+
+	// Leader dies: its lease expires without renewal
+	mr.FastForward(2 * time.Second)
+
+	b.tryAcquire(ctx)
+	require.True(b.IsLeader())
+
+	// The old leader notices on its next renewal attempt
+	a.tryAcquire(ctx)
+	require.False(a.IsLeader())
+}
+
+// TestLeaderElector_ReleaseOnStop verifies a stopping leader hands over immediately
+func TestLeaderElector_ReleaseOnStop(t *testing.T) {
+	require := require.New(t)
+	mr, client := newLeaderTestRedis(t)
+
+	a := newTestElector(client, "replica-a")
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		a.Run(ctx)
+		close(done)
+	}()
+
+	require.Eventually(a.IsLeader, time.Second, 10*time.Millisecond)
+
+	cancel()
+	<-done
+
+	require.False(a.IsLeader())
+	require.False(mr.Exists(DefaultLeaderKey), "lease should be released on shutdown")
+
+	b := newTestElector(client, "replica-b")
+	b.tryAcquire(context.Background())
+	require.True(b.IsLeader())
+}
+
+// TestLeaderElector_RedisErrorDropsLeadership verifies leadership is not assumed when Redis is unreachable
+func TestLeaderElector_RedisErrorDropsLeadership(t *testing.T) {
+	require := require.New(t)
+	mr, client := newLeaderTestRedis(t)
+	ctx := context.Background()
+
+	a := newTestElector(client, "replica-a")
+	a.tryAcquire(ctx)
+	require.True(a.IsLeader())
+
+	mr.SetError("connection lost")
+	a.tryAcquire(ctx)
+	require.False(a.IsLeader())
+}
+
+// TestLeaderElector_Metrics verifies the leadership gauge follows the lease
+func TestLeaderElector_Metrics(t *testing.T) {
+	require := require.New(t)
+	mr, client := newLeaderTestRedis(t)
+	ctx := context.Background()
+
+	metrics := NewMetrics("outbox_leader_test")
+	a := NewLeaderElector(client, zap.NewNop(), LeaderElectionConfig{InstanceID: "replica-a", LeaseTTL: time.Second}, metrics)
+
+	a.tryAcquire(ctx)
+	require.Equal(float64(1), testutil.ToFloat64(metrics.IsLeader))
+
+	mr.FastForward(2 * time.Second)
+	require.NoError(mr.Set(DefaultLeaderKey, "replica-b"))
+	a.tryAcquire(ctx)
+	require.Equal(float64(0), testutil.ToFloat64(metrics.IsLeader))
+}
+
+// TestNewLeaderElectorDefaults verifies defaults are applied
+func TestNewLeaderElectorDefaults(t *testing.T) {
+	require := require.New(t)
+
+	e := NewLeaderElector(nil, zap.NewNop(), LeaderElectionConfig{InstanceID: "replica-a"}, nil)
+
+	require.Equal(DefaultLeaderKey, e.key)
+	require.Equal(DefaultLeaseTTL, e.leaseTTL)
+	require.Equal(DefaultLeaseTTL/3, e.renewInterval)
+}
+
+// TestProcessorShouldPoll verifies followers poll at the slow interval only
+func TestProcessorShouldPoll(t *testing.T) {
+	require := require.New(t)
+	_, client := newLeaderTestRedis(t)
+
+	processor := NewProcessor(nil, nil, zap.NewNop(), ProcessorConfig{PollInterval: 100 * time.Millisecond})
+	now := time.Now()
+	require.True(processor.shouldPoll(now), "without election every tick polls")
+
+	follower := newTestElector(client, "replica-b")
+	processor.SetLeaderElector(follower, time.Second)
+	processor.lastPoll = now
+
+	require.False(processor.shouldPoll(now.Add(500 * time.Millisecond)))
+	require.True(processor.shouldPoll(now.Add(time.Second)))
+
+	follower.tryAcquire(context.Background())
+	require.True(follower.IsLeader())
+	require.True(processor.shouldPoll(now.Add(100*time.Millisecond)), "leader polls every tick")
+}
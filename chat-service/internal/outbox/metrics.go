@@ -24,6 +24,33 @@ type Metrics struct {
 
 	// DLQTotal is a counter of events moved to Dead Letter Queue
 	DLQTotal prometheus.Counter
+
+	// EventsProcessed counts events marked processed, by aggregate_type
+	EventsProcessed *prometheus.CounterVec
+
+	// EventsPublished counts events published to Redis, by aggregate_type
+	EventsPublished *prometheus.CounterVec
+
+	// EventsFailed counts failed publish attempts, by aggregate_type
+	EventsFailed *prometheus.CounterVec
+
+	// WorkerProcessed counts events published by each worker of the pool, by worker index
+	WorkerProcessed *prometheus.CounterVec
+
+	// PublishWait is a histogram of time workers waited for a publish slot (MaxInFlightPublishes)
+	PublishWait prometheus.Histogram
+
+	// PublishedPayloads counts published events by payload form: full, or slim (ids only, oversized)
+	PublishedPayloads *prometheus.CounterVec
+
+	// EventsPerSecond is the processed events rate over the last DefaultThroughputWindow
+	EventsPerSecond prometheus.Gauge
+
+	// PollInterval is the effective poll interval in seconds, raised by the idle backoff
+	PollInterval prometheus.Gauge
+
+	// IsLeader is 1 while this replica holds the outbox leader lease, 0 otherwise
+	IsLeader prometheus.Gauge
 }
 
 // NewMetrics creates and registers all outbox metrics.
@@ -70,6 +97,61 @@ func NewMetrics(namespace string) *Metrics {
 			Name:      "dlq_total",
 			Help:      "Total number of events moved to Dead Letter Queue",
 		}),
+
+		EventsProcessed: promauto.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "events_processed_total",
+			Help:      "Total number of outbox events marked processed, by aggregate type",
+		}, []string{"aggregate_type"}),
+
+		EventsPublished: promauto.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "events_published_total",
+			Help:      "Total number of outbox events published to Redis, by aggregate type",
+		}, []string{"aggregate_type"}),
+
+		EventsFailed: promauto.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "events_failed_total",
+			Help:      "Total number of failed outbox event publishes, by aggregate type",
+		}, []string{"aggregate_type"}),
+
+		WorkerProcessed: promauto.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "worker_processed_total",
+			Help:      "Total number of outbox events published by each worker",
+		}, []string{"worker"}),
+
+		PublishWait: promauto.NewHistogram(prometheus.HistogramOpts{
+			Namespace: namespace,
+			Name:      "publish_wait_seconds",
+			Help:      "Time workers waited for a free slot under the in-flight Redis publish limit",
+			Buckets:   []float64{0.0005, 0.001, 0.005, 0.01, 0.025, 0.05, 0.1, 0.25, 0.5, 1},
+		}),
+
+		PublishedPayloads: promauto.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "published_payloads_total",
+			Help:      "Total number of published outbox events by payload form (full or slim)",
+		}, []string{"form"}),
+
+		EventsPerSecond: promauto.NewGauge(prometheus.GaugeOpts{
+			Namespace: namespace,
+			Name:      "events_per_second",
+			Help:      "Outbox events processed per second, averaged over the last 10 seconds",
+		}),
+
+		PollInterval: promauto.NewGauge(prometheus.GaugeOpts{
+			Namespace: namespace,
+			Name:      "poll_interval_seconds",
+			Help:      "Effective outbox poll interval, raised while polls find no events",
+		}),
+
+		IsLeader: promauto.NewGauge(prometheus.GaugeOpts{
+			Namespace: namespace,
+			Name:      "is_leader",
+			Help:      "1 if this replica holds the outbox leader lease, 0 otherwise",
+		}),
 	}
 }
 
@@ -16,6 +16,11 @@ func TestDefaultMetricsExists(t *testing.T) {
 	require.NotNil(DefaultMetrics.PublishErrorsTotal)
 	require.NotNil(DefaultMetrics.ProcessingDuration)
 	require.NotNil(DefaultMetrics.BatchSize)
+	require.NotNil(DefaultMetrics.EventsProcessed)
+	require.NotNil(DefaultMetrics.EventsPublished)
+	require.NotNil(DefaultMetrics.EventsFailed)
+	require.NotNil(DefaultMetrics.WorkerProcessed)
+	require.NotNil(DefaultMetrics.EventsPerSecond)
 }
 
 func TestMetricsOperations(t *testing.T) {
@@ -0,0 +1,114 @@
+package outbox
+
+import (
+	"context"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"go.uber.org/zap"
+)
+
+const (
+	// NotifyChannel is the Postgres channel the outbox insert trigger notifies (migration 000011).
+	NotifyChannel = "outbox_inserted"
+
+	// DefaultNotifyReconnectDelay is how long the listener waits before reconnecting after an error.
+	DefaultNotifyReconnectDelay = time.Second
+)
+
+// notificationConn is the dedicated connection a NotifyListener waits on (*pgx.Conn).
+type notificationConn interface {
+	WaitForNotification(ctx context.Context) (*pgconn.Notification, error)
+	Close(ctx context.Context) error
+}
+
+// NotifyListener LISTENs on NotifyChannel and signals Wake whenever outbox rows are
+// committed, so the processor polls immediately instead of on its next tick.
+// Notifications are only a latency hint: they are lost while the connection is down,
+// so the processor keeps polling as a safety net.
+type NotifyListener struct {
+	connect        func(ctx context.Context) (notificationConn, error)
+	logger         *zap.Logger
+	reconnectDelay time.Duration
+	wake           chan struct{}
+}
+
+// NewNotifyListener creates a listener holding one connection taken out of pool while it runs.
+func NewNotifyListener(pool *pgxpool.Pool, logger *zap.Logger) *NotifyListener {
+	return newNotifyListener(func(ctx context.Context) (notificationConn, error) {
+		conn, err := pool.Acquire(ctx)
+		if err != nil {
+			return nil, err
+		}
+		if _, err := conn.Exec(ctx, "LISTEN "+NotifyChannel); err != nil {
+			conn.Release()
+			return nil, err
+		}
+		// A listening connection must not be handed out to other callers
+		return conn.Hijack(), nil
+	}, logger)
+}
+
+func newNotifyListener(connect func(ctx context.Context) (notificationConn, error), logger *zap.Logger) *NotifyListener {
+	return &NotifyListener{
+		connect:        connect,
+		logger:         logger,
+		reconnectDelay: DefaultNotifyReconnectDelay,
+		wake:           make(chan struct{}, 1),
+	}
+}
+
+// Wake returns the channel signalled after outbox inserts. Bursts of
+// notifications are coalesced into a single pending wake-up.
+func (l *NotifyListener) Wake() <-chan struct{} {
+	return l.wake
+}
+
+// Run listens until ctx is cancelled, reconnecting after connection errors.
+func (l *NotifyListener) Run(ctx context.Context) {
+	for {
+		if err := l.listen(ctx); err != nil && ctx.Err() == nil {
+			l.logger.Warn("outbox notification listener failed, reconnecting",
+				zap.Duration("delay", l.reconnectDelay),
+				zap.Error(err))
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(l.reconnectDelay):
+		}
+	}
+}
+
+// listen holds one LISTEN connection until it fails or ctx is cancelled.
+func (l *NotifyListener) listen(ctx context.Context) error {
+	conn, err := l.connect(ctx)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		closeCtx, cancel := context.WithTimeout(context.Background(), time.Second)
+		defer cancel()
+		_ = conn.Close(closeCtx)
+	}()
+
+	// Events committed while we were not listening produced no notification we saw
+	l.signal()
+
+	for {
+		if _, err := conn.WaitForNotification(ctx); err != nil {
+			return err
+		}
+		l.signal()
+	}
+}
+
+// signal queues a wake-up unless one is already pending.
+func (l *NotifyListener) signal() {
+	select {
+	case l.wake <- struct{}{}:
+	default:
+	}
+}
@@ -0,0 +1,124 @@
+package outbox
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/zap"
+)
+
+// fakeNotificationConn delivers the notifications sent on its channel; closing the channel fails the connection
+type fakeNotificationConn struct {
+	notifications chan struct{}
+	closed        chan struct{}
+}
+
+func newFakeNotificationConn() *fakeNotificationConn {
+	return &fakeNotificationConn{
+		notifications: make(chan struct{}),
+		closed:        make(chan struct{}),
+	}
+}
+
+func (c *fakeNotificationConn) WaitForNotification(ctx context.Context) (*pgconn.Notification, error) {
+	select {
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	case _, ok := <-c.notifications:
+		if !ok {
+			return nil, errors.New("connection lost")
+		}
+		return &pgconn.Notification{Channel: NotifyChannel}, nil
+	}
+}
+
+func (c *fakeNotificationConn) Close(ctx context.Context) error {
+	close(c.closed)
+	return nil
+}
+
+func requireWake(t *testing.T, listener *NotifyListener) {
+	t.Helper()
+	select {
+	case <-listener.Wake():
+	case <-time.After(time.Second):
+		t.Fatal("expected a wake-up")
+	}
+}
+
+func TestNotifyListener_WakesOnNotification(t *testing.T) {
+	conn := newFakeNotificationConn()
+	listener := newNotifyListener(func(ctx context.Context) (notificationConn, error) {
+		return conn, nil
+	}, zap.NewNop())
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		listener.Run(ctx)
+	}()
+
+	// Connecting wakes the processor to catch up on events inserted before LISTEN
+	requireWake(t, listener)
+
+	conn.notifications <- struct{}{}
+	requireWake(t, listener)
+
+	cancel()
+	<-done
+	select {
+	case <-conn.closed:
+	default:
+		t.Fatal("listener connection not closed on shutdown")
+	}
+}
+
+func TestNotifyListener_CoalescesWakeups(t *testing.T) {
+	listener := newNotifyListener(nil, zap.NewNop())
+
+	listener.signal()
+	listener.signal()
+	listener.signal()
+
+	requireWake(t, listener)
+	select {
+	case <-listener.Wake():
+		t.Fatal("wake-ups should be coalesced")
+	default:
+	}
+}
+
+func TestNotifyListener_Reconnects(t *testing.T) {
+	connected := make(chan *fakeNotificationConn, 3)
+	var attempts atomic.Int32
+	listener := newNotifyListener(func(ctx context.Context) (notificationConn, error) {
+		if attempts.Add(1) == 1 {
+			return nil, errors.New("connection refused")
+		}
+		conn := newFakeNotificationConn()
+		connected <- conn
+		return conn, nil
+	}, zap.NewNop())
+	listener.reconnectDelay = time.Millisecond
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go listener.Run(ctx)
+
+	first := <-connected
+	requireWake(t, listener)
+
+	// Notifications sent while reconnecting are lost, so reconnecting wakes the processor again
+	close(first.notifications)
+	<-first.closed
+	<-connected
+	requireWake(t, listener)
+
+	assert.Equal(t, int32(3), attempts.Load())
+}
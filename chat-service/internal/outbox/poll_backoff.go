@@ -0,0 +1,40 @@
+package outbox
+
+import "time"
+
+// pollBackoff adapts the poll interval to outbox activity: every poll that finds
+// no events doubles the interval up to max, and a poll that finds events resets
+// it to min so a burst after an idle period is drained at the fast interval.
+type pollBackoff struct {
+	min     time.Duration
+	max     time.Duration
+	current time.Duration
+}
+
+// newPollBackoff creates a backoff starting at min. A max not above min disables
+// the backoff: the interval then stays at min.
+func newPollBackoff(min, max time.Duration) *pollBackoff {
+	if max < min {
+		max = min
+	}
+	return &pollBackoff{min: min, max: max, current: min}
+}
+
+// Interval returns the current poll interval
+func (b *pollBackoff) Interval() time.Duration {
+	return b.current
+}
+
+// Next records the outcome of a poll and returns the interval until the next one.
+// Failed polls count as empty so a database outage is not hammered at the fast interval.
+func (b *pollBackoff) Next(found bool) time.Duration {
+	if found {
+		b.current = b.min
+		return b.current
+	}
+	b.current *= 2
+	if b.current > b.max || b.current <= 0 {
+		b.current = b.max
+	}
+	return b.current
+}
@@ -0,0 +1,30 @@
+package outbox
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPollBackoff_BacksOffWhileEmpty(t *testing.T) {
+	b := newPollBackoff(20*time.Millisecond, 150*time.Millisecond)
+	assert.Equal(t, 20*time.Millisecond, b.Interval())
+
+	assert.Equal(t, 40*time.Millisecond, b.Next(false))
+	assert.Equal(t, 80*time.Millisecond, b.Next(false))
+	assert.Equal(t, 150*time.Millisecond, b.Next(false), "capped at max")
+	assert.Equal(t, 150*time.Millisecond, b.Next(false))
+
+	// Rows appear: straight back to the fast interval
+	assert.Equal(t, 20*time.Millisecond, b.Next(true))
+	assert.Equal(t, 20*time.Millisecond, b.Interval())
+}
+
+func TestPollBackoff_DisabledWhenMaxNotAboveMin(t *testing.T) {
+	for _, maxInterval := range []time.Duration{0, 50 * time.Millisecond, 100 * time.Millisecond} {
+		b := newPollBackoff(100*time.Millisecond, maxInterval)
+		assert.Equal(t, 100*time.Millisecond, b.Next(false))
+		assert.Equal(t, 100*time.Millisecond, b.Next(false))
+	}
+}
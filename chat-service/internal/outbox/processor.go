@@ -2,18 +2,24 @@ package outbox
 
 import (
 	"context"
+	"strconv"
 	"sync"
+	"sync/atomic"
 	"time"
 
 	"chat-service/internal/repository"
 
 	"github.com/jackc/pgx/v5/pgtype"
 	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/prometheus/client_golang/prometheus"
 	"github.com/redis/go-redis/v9"
 	"go.uber.org/zap"
 )
 
 const (
+	// DefaultFollowerPollInterval is how often a non-leader replica polls when leader election is on.
+	DefaultFollowerPollInterval = 5 * time.Second
+
 	// DefaultMaxRetries is the maximum number of retry attempts for an event.
 	DefaultMaxRetries = 3
 
@@ -29,17 +35,24 @@ const (
 
 // ProcessorConfig holds configuration for the outbox processor.
 type ProcessorConfig struct {
-	PollInterval time.Duration
-	BatchSize    int           // Number of events to fetch per poll (default: 100)
-	MaxRetries   int           // Maximum retry attempts (default: 3)
-	BaseBackoff  time.Duration // Base backoff duration for exponential backoff (default: 1s)
-	WorkerCount  int           // Number of concurrent workers for publishing (default: 10)
+	PollInterval    time.Duration
+	MaxPollInterval time.Duration // Idle polls back off up to this interval (default: PollInterval, no backoff)
+	BatchSize       int           // Number of events to fetch per poll (default: 100)
+	MaxRetries      int           // Maximum retry attempts (default: 3)
+	BaseBackoff     time.Duration // Base backoff duration for exponential backoff (default: 1s)
+	WorkerCount     int           // Number of concurrent workers for publishing (default: 10)
+	// Most Redis publishes in flight at once across all workers (default: 0, bounded by WorkerCount only)
+	MaxInFlightPublishes int
+	// Message events with a larger payload are published slim, ids only (default: 0, no limit)
+	MaxPayloadBytes int
+	// Health reports the processor stalled when no poll cycle finished for this long (default: 1m)
+	StaleAfter time.Duration
 }
 
 // ProcessorInterface defines the interface for outbox processor (for testing).
 type ProcessorInterface interface {
 	Start(ctx context.Context)
-	Stop()
+	Stop(ctx context.Context) error
 	ProcessBatch(ctx context.Context, events []repository.Outbox) (int, error)
 }
 
@@ -47,17 +60,40 @@ type ProcessorInterface interface {
 type Processor struct {
 	db           *pgxpool.Pool
 	redis        *redis.Client
-	publisher    *Publisher
+	publisher    PublisherInterface
 	logger       *zap.Logger
 	metrics      *Metrics
 	pollInterval time.Duration
+	backoff      *pollBackoff // adapts the poll interval while the outbox is empty
+	throughput   *throughputMeter
 	batchSize    int
 	maxRetries   int
 	baseBackoff  time.Duration
 	workerCount  int
+	publishLimit *publishLimiter // caps concurrent Redis publishes (nil = unlimited)
+	maxPayload   int             // payload size above which message events are published slim (0 = no limit)
 	stopCh       chan struct{}
 	doneCh       chan struct{}
-	processing   bool      // indicates if currently processing a batch
+	running      atomic.Bool // true while the poll loop is active
+	stopOnce     sync.Once
+	stopping     atomic.Bool // set by Stop: workers stop publishing events not yet started
+	// Current batch, so Stop can abort it once its deadline expires
+	batchMu     sync.Mutex
+	batchCancel context.CancelFunc
+	inFlight    atomic.Int64
+	// Optional leader election: followers only poll every followerPollInterval
+	elector              *LeaderElector
+	followerPollInterval time.Duration
+	lastPoll             time.Time
+	// Optional wake-ups (e.g. from a NotifyListener) that trigger a poll before the next tick
+	wake <-chan struct{}
+	// Health: when the loop last finished a poll cycle and a successful one (unix nanos), and
+	// how many cycles in a row failed
+	staleAfter   time.Duration
+	lastCycle    atomic.Int64
+	lastSuccess  atomic.Int64
+	pollFailures atomic.Int64
+	processing   bool       // indicates if currently processing a batch
 	processingMu sync.Mutex // protects processing flag
 }
 
@@ -65,6 +101,7 @@ type Processor struct {
 type eventResult struct {
 	event   repository.Outbox
 	success bool
+	skipped bool // not published because the processor is stopping; stays pending
 	err     error
 }
 
@@ -106,25 +143,67 @@ func NewProcessorWithMetrics(db *pgxpool.Pool, redisClient *redis.Client, logger
 		logger:       logger,
 		metrics:      metrics,
 		pollInterval: cfg.PollInterval,
+		backoff:      newPollBackoff(cfg.PollInterval, cfg.MaxPollInterval),
+		throughput:   newThroughputMeter(DefaultThroughputWindow),
 		batchSize:    batchSize,
 		maxRetries:   maxRetries,
 		baseBackoff:  baseBackoff,
 		workerCount:  workerCount,
+		publishLimit: newPublishLimiter(cfg.MaxInFlightPublishes),
+		maxPayload:   cfg.MaxPayloadBytes,
+		staleAfter:   cfg.StaleAfter,
 		stopCh:       make(chan struct{}),
 		doneCh:       make(chan struct{}),
 	}
 }
 
+// SetPublisher replaces the default Pub/Sub publisher (e.g. with a StreamPublisher). Call before Start.
+func (p *Processor) SetPublisher(publisher PublisherInterface) {
+	p.publisher = publisher
+}
+
+// SetLeaderElector enables leader election. The leader polls every PollInterval,
+// followers every followerPollInterval (default: 5s) so events still drain if the
+// leader stalls while holding the lease. The elector must be run separately.
+func (p *Processor) SetLeaderElector(elector *LeaderElector, followerPollInterval time.Duration) {
+	if followerPollInterval <= 0 {
+		followerPollInterval = DefaultFollowerPollInterval
+	}
+	p.elector = elector
+	p.followerPollInterval = followerPollInterval
+}
 
-// Start begins the poll loop. It blocks until Stop() is called or context is cancelled.
+// SetWakeup makes the processor poll as soon as wake is signalled, e.g. by a
+// NotifyListener, in addition to its regular polls. Call before Start.
+func (p *Processor) SetWakeup(wake <-chan struct{}) {
+	p.wake = wake
+}
+
+// shouldPoll reports whether this tick should query the outbox.
+func (p *Processor) shouldPoll(now time.Time) bool {
+	if p.elector == nil || p.elector.IsLeader() {
+		return true
+	}
+	return now.Sub(p.lastPoll) >= p.followerPollInterval
+}
+
+// Start begins the poll loop. It blocks until Stop is called or context is cancelled.
+// Polls that find no events back off exponentially up to MaxPollInterval; the first
+// poll that finds events returns to PollInterval. A wake-up (see SetWakeup) polls immediately.
 func (p *Processor) Start(ctx context.Context) {
 	p.logger.Info("starting outbox processor",
 		zap.Duration("poll_interval", p.pollInterval),
+		zap.Duration("max_poll_interval", p.backoff.max),
 		zap.Int("batch_size", p.batchSize),
 		zap.Int("worker_count", p.workerCount))
 
-	ticker := time.NewTicker(p.pollInterval)
-	defer ticker.Stop()
+	p.lastCycle.Store(time.Now().UnixNano()) // the first poll is due one interval from now
+	p.running.Store(true)
+	defer p.running.Store(false)
+
+	p.setPollIntervalMetric(p.backoff.Interval())
+	timer := time.NewTimer(p.backoff.Interval())
+	defer timer.Stop()
 	defer close(p.doneCh)
 
 	for {
@@ -139,19 +218,94 @@ func (p *Processor) Start(ctx context.Context) {
 			p.waitForCurrentBatch()
 			p.logger.Info("outbox processor stopped")
 			return
-		case <-ticker.C:
-			if err := p.pollOnce(ctx); err != nil {
-				p.logger.Error("poll cycle failed", zap.Error(err))
-			}
+		case now := <-timer.C:
+			p.poll(ctx, now)
+			timer.Reset(p.backoff.Interval())
+		case <-p.wake:
+			p.poll(ctx, time.Now())
+			timer.Reset(p.backoff.Interval())
 		}
 	}
 }
 
-// Stop signals the processor to stop and waits for it to finish.
-// It ensures the current batch completes before returning.
-func (p *Processor) Stop() {
-	close(p.stopCh)
-	<-p.doneCh
+// poll runs one poll cycle if this replica should poll now, and adapts the interval to its outcome.
+func (p *Processor) poll(ctx context.Context, now time.Time) {
+	if !p.shouldPoll(now) {
+		return
+	}
+	p.lastPoll = now
+	found, err := p.pollOnce(ctx)
+	p.recordCycle(time.Now(), err)
+	if err != nil {
+		p.logger.Error("poll cycle failed", zap.Error(err))
+	}
+	p.setPollIntervalMetric(p.backoff.Next(found > 0))
+	// Updated on empty polls too, so the rate decays while idle
+	if p.metrics != nil && p.metrics.EventsPerSecond != nil {
+		p.metrics.EventsPerSecond.Set(p.throughput.Rate(time.Now()))
+	}
+}
+
+// setPollIntervalMetric exports the effective poll interval
+func (p *Processor) setPollIntervalMetric(interval time.Duration) {
+	if p.metrics != nil && p.metrics.PollInterval != nil {
+		p.metrics.PollInterval.Set(interval.Seconds())
+	}
+}
+
+// Stop signals the processor to stop and waits for the current batch to commit.
+// Workers stop publishing new events immediately: events already published are
+// marked processed, the rest are left pending for the next run.
+// If ctx expires first, the batch is aborted (its transaction rolls back, so nothing
+// is marked processed without being committed) and ctx.Err() is returned.
+func (p *Processor) Stop(ctx context.Context) error {
+	p.stopOnce.Do(func() {
+		p.stopping.Store(true)
+		close(p.stopCh)
+	})
+
+	select {
+	case <-p.doneCh:
+		return nil
+	case <-ctx.Done():
+		p.logger.Warn("outbox processor stop deadline exceeded, abandoning current batch",
+			zap.Int64("abandoned_events", p.inFlight.Load()),
+			zap.Error(ctx.Err()))
+		p.abortBatch()
+		return ctx.Err()
+	}
+}
+
+// beginBatch returns the context for one poll cycle. Cancelling the Start context
+// does not abort it, so a shutdown lets the batch commit; only abortBatch does.
+func (p *Processor) beginBatch(ctx context.Context) (context.Context, func()) {
+	batchCtx, cancel := context.WithCancel(context.WithoutCancel(ctx))
+
+	p.batchMu.Lock()
+	p.batchCancel = cancel
+	p.batchMu.Unlock()
+
+	return batchCtx, func() {
+		p.batchMu.Lock()
+		p.batchCancel = nil
+		p.batchMu.Unlock()
+		p.inFlight.Store(0)
+		cancel()
+	}
+}
+
+// abortBatch cancels the batch in progress, if any.
+func (p *Processor) abortBatch() {
+	p.batchMu.Lock()
+	defer p.batchMu.Unlock()
+	if p.batchCancel != nil {
+		p.batchCancel()
+	}
+}
+
+// IsRunning reports whether the poll loop is active.
+func (p *Processor) IsRunning() bool {
+	return p.running.Load()
 }
 
 // setProcessing sets the processing flag safely.
@@ -176,17 +330,21 @@ func (p *Processor) waitForCurrentBatch() {
 }
 
 // pollOnce executes a single poll cycle: query unprocessed events and process them.
-func (p *Processor) pollOnce(ctx context.Context) error {
+// It returns the number of events fetched.
+func (p *Processor) pollOnce(ctx context.Context) (int, error) {
 	// Mark as processing for graceful shutdown
 	p.setProcessing(true)
 	defer p.setProcessing(false)
 
+	ctx, endBatch := p.beginBatch(ctx)
+	defer endBatch()
+
 	startTime := time.Now()
 
 	// Start a transaction to use FOR UPDATE SKIP LOCKED
 	tx, err := p.db.Begin(ctx)
 	if err != nil {
-		return err
+		return 0, err
 	}
 	defer func() {
 		if err := tx.Rollback(ctx); err != nil {
@@ -197,7 +355,7 @@ func (p *Processor) pollOnce(ctx context.Context) error {
 	queries := repository.New(tx)
 	events, err := queries.GetAndLockUnprocessedOutbox(ctx, int32(p.batchSize))
 	if err != nil {
-		return err
+		return 0, err
 	}
 
 	// Update pending count metric (approximate - shows locked events count)
@@ -207,8 +365,9 @@ func (p *Processor) pollOnce(ctx context.Context) error {
 	}
 
 	if len(events) == 0 {
-		return nil
+		return 0, nil
 	}
+	p.inFlight.Store(int64(len(events)))
 
 	processed, publishErrors, err := p.processBatchWithTxAndMetrics(ctx, queries, events)
 
@@ -233,13 +392,13 @@ func (p *Processor) pollOnce(ctx context.Context) error {
 
 	// Commit the transaction to release locks and persist processed_at updates
 	if err := tx.Commit(ctx); err != nil {
-		return err
+		return len(events), err
 	}
+	p.throughput.Add(processed, time.Now())
 
-	return nil
+	return len(events), nil
 }
 
-
 // ProcessBatch processes a batch of events using partial success strategy.
 // It returns the count of successfully processed events.
 // This method is exposed for testing purposes.
@@ -286,9 +445,15 @@ func (p *Processor) processBatchWithTxAndMetrics(ctx context.Context, queries *r
 	// Phase 2: Sequential DB updates based on publish results
 	processed := 0
 	publishErrors := 0
+	skipped := 0
 	var lastErr error
 
 	for _, result := range results {
+		if result.skipped {
+			// Never published: leave it unprocessed without spending a retry
+			skipped++
+			continue
+		}
 		if result.success {
 			// Mark as processed
 			if err := p.markEventProcessed(ctx, queries, result.event.ID); err != nil {
@@ -299,9 +464,15 @@ func (p *Processor) processBatchWithTxAndMetrics(ctx context.Context, queries *r
 				continue
 			}
 			processed++
+			if p.metrics != nil {
+				incByAggregate(p.metrics.EventsProcessed, result.event)
+			}
 		} else {
 			// Handle failure - increment retry count or move to DLQ
 			publishErrors++
+			if p.metrics != nil {
+				incByAggregate(p.metrics.EventsFailed, result.event)
+			}
 			p.logger.Error("failed to process event",
 				zap.String("event_id", result.event.ID.String()),
 				zap.String("aggregate_type", result.event.AggregateType),
@@ -321,6 +492,12 @@ func (p *Processor) processBatchWithTxAndMetrics(ctx context.Context, queries *r
 		}
 	}
 
+	if skipped > 0 {
+		p.logger.Info("processor stopping, left unpublished events pending",
+			zap.Int("skipped", skipped),
+			zap.Int("processed", processed))
+	}
+
 	return processed, publishErrors, lastErr
 }
 
@@ -336,35 +513,58 @@ func (p *Processor) publishConcurrently(ctx context.Context, events []repository
 		workerCount = numEvents
 	}
 
+	// Worker slots double as a semaphore; the slot index labels per-worker metrics
 	var wg sync.WaitGroup
-	semaphore := make(chan struct{}, workerCount)
+	workers := make(chan int, workerCount)
+	for w := 0; w < workerCount; w++ {
+		workers <- w
+	}
 
 	for i, event := range events {
 		wg.Add(1)
 		go func(idx int, evt repository.Outbox) {
 			defer wg.Done()
 
-			// Acquire semaphore slot
-			semaphore <- struct{}{}
-			defer func() { <-semaphore }()
+			// Acquire a worker slot
+			worker := <-workers
+			defer func() { workers <- worker }()
+
+			// Wait for a publish slot, so a wide batch doesn't burst past the Redis write limit
+			waited, err := p.publishLimit.Acquire(ctx)
+			p.observePublishWait(waited)
+			if err != nil {
+				results[idx] = eventResult{
+					event:   evt,
+					skipped: true,
+				}
+				return
+			}
+			defer p.publishLimit.Release()
 
-			// Check context cancellation
-			if ctx.Err() != nil {
+			// Don't start new publishes once stopping or aborted
+			if p.stopping.Load() || ctx.Err() != nil {
 				results[idx] = eventResult{
 					event:   evt,
-					success: false,
-					err:     ctx.Err(),
+					skipped: true,
 				}
 				return
 			}
 
-			// Publish to Redis
-			err := p.processEvent(ctx, evt)
+			// Publish to Redis; oversized message events go out slim
+			published, slim := slimEvent(evt, p.maxPayload)
+			err = p.processEvent(ctx, published)
 			results[idx] = eventResult{
 				event:   evt,
 				success: err == nil,
 				err:     err,
 			}
+			if err == nil && p.metrics != nil {
+				incByAggregate(p.metrics.EventsPublished, evt)
+				p.recordPayloadForm(slim)
+				if p.metrics.WorkerProcessed != nil {
+					p.metrics.WorkerProcessed.WithLabelValues(strconv.Itoa(worker)).Inc()
+				}
+			}
 		}(i, event)
 	}
 
@@ -372,6 +572,33 @@ func (p *Processor) publishConcurrently(ctx context.Context, events []repository
 	return results
 }
 
+// observePublishWait records how long a worker waited for a publish slot.
+func (p *Processor) observePublishWait(waited time.Duration) {
+	if p.publishLimit == nil || p.metrics == nil || p.metrics.PublishWait == nil {
+		return
+	}
+	p.metrics.PublishWait.Observe(waited.Seconds())
+}
+
+// recordPayloadForm counts a published event as slim or full.
+func (p *Processor) recordPayloadForm(slim bool) {
+	if p.metrics.PublishedPayloads == nil {
+		return
+	}
+	form := PayloadFormFull
+	if slim {
+		form = PayloadFormSlim
+	}
+	p.metrics.PublishedPayloads.WithLabelValues(form).Inc()
+}
+
+// incByAggregate increments counter for the aggregate type of event.
+func incByAggregate(counter *prometheus.CounterVec, event repository.Outbox) {
+	if counter != nil {
+		counter.WithLabelValues(event.AggregateType).Inc()
+	}
+}
+
 // handleEventFailure handles a failed event by incrementing retry count.
 // If max retries exceeded, moves the event to Dead Letter Queue.
 func (p *Processor) handleEventFailure(ctx context.Context, queries *repository.Queries, event repository.Outbox, errMsg string) error {
@@ -463,8 +690,7 @@ func (p *Processor) ShouldRetryEvent(event repository.Outbox) bool {
 	return true
 }
 
-
-// processEvent publishes the event to Redis Streams.
+// processEvent publishes the event to Redis (Pub/Sub or Stream, depending on the publisher).
 func (p *Processor) processEvent(ctx context.Context, event repository.Outbox) error {
 	streamID, err := p.publisher.Publish(ctx, event)
 	if err != nil {
@@ -683,7 +683,7 @@ func TestIntegration_EndToEnd_MessageToRedisPubSub(t *testing.T) {
 	go processor.Start(processorCtx)
 	defer func() {
 		processorCancel()
-		processor.Stop()
+		_ = processor.Stop(context.Background())
 	}()
 
 	// Insert a test outbox event
@@ -798,7 +798,7 @@ func TestIntegration_EndToEnd_MultipleMessages(t *testing.T) {
 	go processor.Start(processorCtx)
 	defer func() {
 		processorCancel()
-		processor.Stop()
+		_ = processor.Stop(context.Background())
 	}()
 
 	// Insert multiple test events
@@ -921,7 +921,7 @@ func TestIntegration_Latency_P99(t *testing.T) {
 	go processor.Start(processorCtx)
 	defer func() {
 		processorCancel()
-		processor.Stop()
+		_ = processor.Stop(context.Background())
 	}()
 
 	// Measure latency for individual events
@@ -999,3 +999,114 @@ func TestIntegration_Latency_P99(t *testing.T) {
 		t.Errorf("P99 latency %v exceeds 100ms requirement", p99)
 	}
 }
+
+// TestIntegration_NotifyWakesProcessor verifies the outbox insert trigger delivers an event
+// well before the next poll: the processor only polls every 5 seconds here.
+func TestIntegration_NotifyWakesProcessor(t *testing.T) {
+	if testInfra == nil {
+		t.Skip("Test infrastructure not available")
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	if err := testInfra.cleanupOutbox(ctx); err != nil {
+		t.Fatalf("cleanup failed: %v", err)
+	}
+
+	pollInterval := 5 * time.Second
+	published := make(chan struct{}, 1)
+	publisher := &recordingPublisher{onPublish: func() {
+		select {
+		case published <- struct{}{}:
+		default:
+		}
+	}}
+
+	processor := NewProcessor(testInfra.DBPool, nil, zap.NewNop(), ProcessorConfig{
+		PollInterval:    pollInterval,
+		MaxPollInterval: pollInterval,
+	})
+	processor.SetPublisher(publisher)
+
+	listener := NewNotifyListener(testInfra.DBPool, zap.NewNop())
+	processor.SetWakeup(listener.Wake())
+
+	processorCtx, processorCancel := context.WithCancel(ctx)
+	go listener.Run(processorCtx)
+	go processor.Start(processorCtx)
+	defer func() {
+		processorCancel()
+		_ = processor.Stop(context.Background())
+	}()
+
+	// Let the listener connect and the catch-up poll of the empty outbox run
+	time.Sleep(200 * time.Millisecond)
+
+	start := time.Now()
+	if _, err := insertTestOutboxEvent(ctx, testInfra.DBPool, false); err != nil {
+		t.Fatalf("Failed to insert: %v", err)
+	}
+
+	select {
+	case <-published:
+		latency := time.Since(start)
+		t.Logf("Notified delivery latency: %v (poll interval %v)", latency, pollInterval)
+		if latency >= pollInterval/2 {
+			t.Errorf("delivery took %v, expected well under the %v poll interval", latency, pollInterval)
+		}
+	case <-time.After(pollInterval / 2):
+		t.Fatalf("event not published within %v; the insert notification did not wake the processor", pollInterval/2)
+	}
+}
+
+// TestIntegration_InsertOutboxBatch verifies a batch insert stores every event with its payload
+func TestIntegration_InsertOutboxBatch(t *testing.T) {
+	if testInfra == nil {
+		t.Skip("Test infrastructure not available")
+	}
+
+	ctx := context.Background()
+	if err := testInfra.cleanupOutbox(ctx); err != nil {
+		t.Fatalf("cleanup failed: %v", err)
+	}
+
+	aggregateID := pgtype.UUID{}
+	aggregateID.Scan("00000000-0000-0000-0000-000000000001")
+
+	queries := repository.New(testInfra.DBPool)
+	inserted, err := queries.InsertOutboxBatch(ctx, repository.InsertOutboxBatchParams{
+		AggregateTypes: []string{"message", "conversation", "conversation"},
+		AggregateIds:   []pgtype.UUID{aggregateID, aggregateID, aggregateID},
+		Payloads:       [][]byte{[]byte(`{"n": 1}`), []byte(`{"n": 2}`), []byte(`{"n": 3}`)},
+	})
+	if err != nil {
+		t.Fatalf("batch insert failed: %v", err)
+	}
+	if inserted != 3 {
+		t.Fatalf("expected 3 inserted events, got %d", inserted)
+	}
+
+	events, err := queries.GetUnprocessedOutbox(ctx, 10)
+	if err != nil {
+		t.Fatalf("query failed: %v", err)
+	}
+	if len(events) != 3 {
+		t.Fatalf("expected 3 unprocessed events, got %d", len(events))
+	}
+
+	var seen []int
+	for _, event := range events {
+		var payload struct {
+			N int `json:"n"`
+		}
+		if err := json.Unmarshal(event.Payload, &payload); err != nil {
+			t.Fatalf("invalid payload %s: %v", event.Payload, err)
+		}
+		seen = append(seen, payload.N)
+	}
+	sort.Ints(seen)
+	if fmt.Sprint(seen) != "[1 2 3]" {
+		t.Errorf("expected payloads 1, 2 and 3, got %v", seen)
+	}
+}
@@ -0,0 +1,186 @@
+package outbox
+
+import (
+	"context"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"chat-service/internal/repository"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+// recordingDB is a repository.DBTX that records the outbox updates of a batch
+type recordingDB struct {
+	mu        sync.Mutex
+	processed []pgtype.UUID
+	retried   []pgtype.UUID
+}
+
+func (db *recordingDB) Exec(ctx context.Context, sql string, args ...interface{}) (pgconn.CommandTag, error) {
+	db.mu.Lock()
+	defer db.mu.Unlock()
+
+	id, _ := args[0].(pgtype.UUID)
+	switch {
+	case strings.Contains(sql, "name: MarkOutboxProcessed"):
+		db.processed = append(db.processed, id)
+	case strings.Contains(sql, "name: IncrementOutboxRetry"):
+		db.retried = append(db.retried, id)
+	}
+	return pgconn.CommandTag{}, nil
+}
+
+func (db *recordingDB) Query(ctx context.Context, sql string, args ...interface{}) (pgx.Rows, error) {
+	return nil, nil
+}
+
+func (db *recordingDB) QueryRow(ctx context.Context, sql string, args ...interface{}) pgx.Row {
+	return nil
+}
+
+// recordingPublisher records published events and runs onPublish after each one
+type recordingPublisher struct {
+	mu        sync.Mutex
+	published []pgtype.UUID
+	onPublish func()
+}
+
+func (p *recordingPublisher) Publish(ctx context.Context, event repository.Outbox) (string, error) {
+	p.mu.Lock()
+	p.published = append(p.published, event.ID)
+	p.mu.Unlock()
+
+	if p.onPublish != nil {
+		p.onPublish()
+	}
+	return "0-1", nil
+}
+
+func newShutdownTestEvents(n int) []repository.Outbox {
+	events := make([]repository.Outbox, n)
+	for i := range events {
+		events[i] = repository.Outbox{
+			ID:            pgtype.UUID{Bytes: [16]byte{byte(i + 1)}, Valid: true},
+			AggregateType: "message",
+			Payload:       []byte(`{}`),
+		}
+	}
+	return events
+}
+
+// TestProcessBatch_StopMidBatchMarksOnlyPublishedEvents verifies that during shutdown
+// no event is marked processed without being published, and unpublished events keep their retries
+func TestProcessBatch_StopMidBatchMarksOnlyPublishedEvents(t *testing.T) {
+	require := require.New(t)
+
+	processor := NewProcessor(nil, nil, zap.NewNop(), ProcessorConfig{
+		PollInterval: time.Second,
+		WorkerCount:  1,
+	})
+
+	publisher := &recordingPublisher{}
+	// Stop arrives while the first event is being published
+	publisher.onPublish = func() { processor.stopping.Store(true) }
+	processor.publisher = publisher
+
+	db := &recordingDB{}
+	events := newShutdownTestEvents(5)
+
+	processed, publishErrors, err := processor.processBatchWithTxAndMetrics(context.Background(), repository.New(db), events)
+	require.NoError(err)
+	require.Zero(publishErrors)
+
+	require.Len(publisher.published, 1, "no new publishes should start once stopping")
+	require.Equal(1, processed)
+	require.ElementsMatch(publisher.published, db.processed, "only published events may be marked processed")
+	require.Empty(db.retried, "skipped events must not spend a retry")
+}
+
+// TestProcessorStop_DrainsIdleProcessor verifies Stop returns once the poll loop exits
+func TestProcessorStop_DrainsIdleProcessor(t *testing.T) {
+	require := require.New(t)
+
+	processor := NewProcessor(nil, nil, zap.NewNop(), ProcessorConfig{PollInterval: time.Hour})
+	go processor.Start(context.Background())
+	require.Eventually(processor.IsRunning, time.Second, 10*time.Millisecond)
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+
+	require.NoError(processor.Stop(ctx))
+	require.False(processor.IsRunning())
+
+	// Stopping twice is safe
+	require.NoError(processor.Stop(ctx))
+}
+
+// TestProcessorStop_DeadlineAbandonsBatch verifies Stop force-returns at the deadline
+// and aborts the in-flight batch so its transaction rolls back
+func TestProcessorStop_DeadlineAbandonsBatch(t *testing.T) {
+	require := require.New(t)
+
+	processor := NewProcessor(nil, nil, zap.NewNop(), ProcessorConfig{PollInterval: time.Hour})
+
+	// A batch that never finishes on its own
+	batchCtx, endBatch := processor.beginBatch(context.Background())
+	defer endBatch()
+	processor.inFlight.Store(3)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+
+	err := processor.Stop(ctx)
+	require.ErrorIs(err, context.DeadlineExceeded)
+	require.True(processor.stopping.Load())
+	require.ErrorIs(batchCtx.Err(), context.Canceled, "abandoned batch should be aborted")
+}
+
+// TestProcessorBeginBatch_IgnoresParentCancellation verifies cancelling the Start context
+// lets the current batch finish instead of aborting it half-way
+func TestProcessorBeginBatch_IgnoresParentCancellation(t *testing.T) {
+	require := require.New(t)
+
+	processor := NewProcessor(nil, nil, zap.NewNop(), ProcessorConfig{PollInterval: time.Hour})
+
+	parent, cancel := context.WithCancel(context.Background())
+	batchCtx, endBatch := processor.beginBatch(parent)
+	cancel()
+
+	require.NoError(batchCtx.Err())
+
+	endBatch()
+	require.Error(batchCtx.Err())
+	require.Zero(processor.inFlight.Load())
+}
+
+// TestProcessBatch_RecordsThroughputMetrics verifies per-aggregate and per-worker counters
+func TestProcessBatch_RecordsThroughputMetrics(t *testing.T) {
+	require := require.New(t)
+
+	processor := NewProcessor(nil, nil, zap.NewNop(), ProcessorConfig{
+		PollInterval: time.Second,
+		WorkerCount:  1,
+	})
+	processor.publisher = &recordingPublisher{}
+
+	processedBefore := testutil.ToFloat64(DefaultMetrics.EventsProcessed.WithLabelValues("message"))
+	publishedBefore := testutil.ToFloat64(DefaultMetrics.EventsPublished.WithLabelValues("message"))
+	workerBefore := testutil.ToFloat64(DefaultMetrics.WorkerProcessed.WithLabelValues("0"))
+
+	events := newShutdownTestEvents(3)
+	processed, _, err := processor.processBatchWithTxAndMetrics(context.Background(), repository.New(&recordingDB{}), events)
+	require.NoError(err)
+	require.Equal(3, processed)
+
+	require.Equal(processedBefore+3, testutil.ToFloat64(DefaultMetrics.EventsProcessed.WithLabelValues("message")))
+	require.Equal(publishedBefore+3, testutil.ToFloat64(DefaultMetrics.EventsPublished.WithLabelValues("message")))
+	require.Equal(workerBefore+3, testutil.ToFloat64(DefaultMetrics.WorkerProcessed.WithLabelValues("0")), "single worker publishes every event")
+}
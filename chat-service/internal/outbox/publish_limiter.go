@@ -0,0 +1,60 @@
+package outbox
+
+import (
+	"context"
+	"time"
+)
+
+// publishLimiter caps the Redis publishes in flight at once, independently of the
+// worker count: workers can fan out a batch as wide as they like while Redis (and the
+// client's connection pool) only ever sees max concurrent writes. A nil limiter is unlimited.
+type publishLimiter struct {
+	slots chan struct{}
+}
+
+// newPublishLimiter creates a limiter for max concurrent publishes; max <= 0 means no limit
+func newPublishLimiter(max int) *publishLimiter {
+	if max <= 0 {
+		return nil
+	}
+	return &publishLimiter{slots: make(chan struct{}, max)}
+}
+
+// Acquire waits for a publish slot and returns how long it waited.
+// It gives up with ctx's error when ctx ends first; the caller then holds no slot.
+func (l *publishLimiter) Acquire(ctx context.Context) (time.Duration, error) {
+	if l == nil {
+		return 0, nil
+	}
+
+	// Fast path: a free slot costs no clock reads
+	select {
+	case l.slots <- struct{}{}:
+		return 0, nil
+	default:
+	}
+
+	start := time.Now()
+	select {
+	case l.slots <- struct{}{}:
+		return time.Since(start), nil
+	case <-ctx.Done():
+		return time.Since(start), ctx.Err()
+	}
+}
+
+// Release frees a slot taken by a successful Acquire
+func (l *publishLimiter) Release() {
+	if l == nil {
+		return
+	}
+	<-l.slots
+}
+
+// Limit returns the maximum concurrent publishes (0 = unlimited)
+func (l *publishLimiter) Limit() int {
+	if l == nil {
+		return 0
+	}
+	return cap(l.slots)
+}
@@ -0,0 +1,193 @@
+package outbox
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"chat-service/internal/repository"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+func TestPublishLimiter_Unlimited(t *testing.T) {
+	var l *publishLimiter = newPublishLimiter(0)
+	assert.Nil(t, l)
+	assert.Zero(t, l.Limit())
+
+	waited, err := l.Acquire(context.Background())
+	assert.NoError(t, err)
+	assert.Zero(t, waited)
+	l.Release() // no-op
+}
+
+func TestPublishLimiter_WaitsForSlot(t *testing.T) {
+	l := newPublishLimiter(1)
+	require.Equal(t, 1, l.Limit())
+
+	_, err := l.Acquire(context.Background())
+	require.NoError(t, err)
+
+	acquired := make(chan time.Duration)
+	go func() {
+		waited, err := l.Acquire(context.Background())
+		assert.NoError(t, err)
+		acquired <- waited
+	}()
+
+	select {
+	case <-acquired:
+		t.Fatal("second acquire must wait for the first release")
+	case <-time.After(20 * time.Millisecond):
+	}
+
+	l.Release()
+	assert.GreaterOrEqual(t, <-acquired, 20*time.Millisecond)
+	l.Release()
+}
+
+func TestPublishLimiter_GivesUpWithContext(t *testing.T) {
+	l := newPublishLimiter(1)
+	_, err := l.Acquire(context.Background())
+	require.NoError(t, err)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	_, err = l.Acquire(ctx)
+	assert.ErrorIs(t, err, context.DeadlineExceeded)
+
+	// The failed acquire took no slot: one release frees the limiter
+	l.Release()
+	_, err = l.Acquire(context.Background())
+	assert.NoError(t, err)
+}
+
+// poolPublisher simulates Redis behind a client connection pool: a publish waits up to
+// poolTimeout for one of poolSize connections (failing like go-redis does when none frees
+// up in time) and holds it for serviceTime. It records the peak of concurrent publishes.
+type poolPublisher struct {
+	conns       chan struct{}
+	poolTimeout time.Duration
+	serviceTime time.Duration
+
+	inFlight     atomic.Int64
+	peak         atomic.Int64
+	poolTimeouts atomic.Int64
+}
+
+func newPoolPublisher(poolSize int, poolTimeout, serviceTime time.Duration) *poolPublisher {
+	return &poolPublisher{
+		conns:       make(chan struct{}, poolSize),
+		poolTimeout: poolTimeout,
+		serviceTime: serviceTime,
+	}
+}
+
+func (p *poolPublisher) Publish(ctx context.Context, event repository.Outbox) (string, error) {
+	n := p.inFlight.Add(1)
+	defer p.inFlight.Add(-1)
+	for {
+		peak := p.peak.Load()
+		if n <= peak || p.peak.CompareAndSwap(peak, n) {
+			break
+		}
+	}
+
+	timer := time.NewTimer(p.poolTimeout)
+	defer timer.Stop()
+	select {
+	case p.conns <- struct{}{}:
+	case <-timer.C:
+		p.poolTimeouts.Add(1)
+		return "", errors.New("redis: connection pool timeout")
+	}
+	defer func() { <-p.conns }()
+
+	time.Sleep(p.serviceTime)
+	return "0-1", nil
+}
+
+func newLimitedTestProcessor(workers, maxInFlight int) (*Processor, *Metrics) {
+	metrics := &Metrics{
+		PublishWait: prometheus.NewHistogram(prometheus.HistogramOpts{Name: "publish_wait_seconds"}),
+	}
+	processor := NewProcessorWithMetrics(nil, nil, zap.NewNop(), ProcessorConfig{
+		PollInterval:         time.Second,
+		WorkerCount:          workers,
+		MaxInFlightPublishes: maxInFlight,
+	}, metrics)
+	return processor, metrics
+}
+
+func TestPublishConcurrently_RespectsInFlightLimit(t *testing.T) {
+	processor, metrics := newLimitedTestProcessor(16, 3)
+	publisher := newPoolPublisher(3, time.Second, time.Millisecond)
+	processor.publisher = publisher
+
+	results := processor.publishConcurrently(context.Background(), newShutdownTestEvents(32))
+
+	for _, r := range results {
+		assert.True(t, r.success)
+	}
+	assert.LessOrEqual(t, publisher.peak.Load(), int64(3), "no more than MaxInFlightPublishes publishes at once")
+
+	registry := prometheus.NewRegistry()
+	registry.MustRegister(metrics.PublishWait)
+	families, err := registry.Gather()
+	require.NoError(t, err)
+	assert.Equal(t, uint64(32), families[0].GetMetric()[0].GetHistogram().GetSampleCount(), "every publish records its wait")
+}
+
+func TestPublishConcurrently_AbortWhileWaitingSkipsEvent(t *testing.T) {
+	processor, _ := newLimitedTestProcessor(4, 1)
+	processor.publisher = &recordingPublisher{}
+
+	// Another publish holds the only slot until the batch is aborted
+	_, err := processor.publishLimit.Acquire(context.Background())
+	require.NoError(t, err)
+	defer processor.publishLimit.Release()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	results := processor.publishConcurrently(ctx, newShutdownTestEvents(2))
+
+	for _, r := range results {
+		assert.True(t, r.skipped, "an event that never got a slot stays pending")
+	}
+}
+
+// BenchmarkPublishConcurrently_Burst drains a burst of events with a wide worker pool into
+// a Redis client pool of 8 connections. Without a cap all 64 workers publish at once, queue
+// on the client pool and some publishes fail with pool timeouts (each one a retry later);
+// capping in-flight publishes at the pool size keeps Redis load steady with no timeouts.
+func BenchmarkPublishConcurrently_Burst(b *testing.B) {
+	const (
+		workers  = 64
+		burst    = 256
+		poolSize = 8
+	)
+	events := newShutdownTestEvents(burst)
+
+	for _, limit := range []int{0, poolSize} {
+		b.Run(fmt.Sprintf("max_inflight=%d", limit), func(b *testing.B) {
+			processor, _ := newLimitedTestProcessor(workers, limit)
+			publisher := newPoolPublisher(poolSize, 3*time.Millisecond, time.Millisecond)
+			processor.publisher = publisher
+
+			b.ResetTimer()
+			for i := 0; i < b.N; i++ {
+				processor.publishConcurrently(context.Background(), events)
+			}
+			b.StopTimer()
+
+			b.ReportMetric(float64(publisher.peak.Load()), "peak_inflight")
+			b.ReportMetric(float64(publisher.poolTimeouts.Load())/float64(b.N), "pool_timeouts/op")
+		})
+	}
+}
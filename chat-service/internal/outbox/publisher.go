@@ -13,10 +13,16 @@ import (
 const (
 	// ChannelName is the Redis Pub/Sub channel for chat events.
 	ChannelName = "chat:events"
+
+	// SchemaVersion is the version of the event layout published by this processor.
+	// Bump it only for changes old consumers would misread (removed, renamed or retyped
+	// fields); new optional fields keep the version. See the README for the policy.
+	SchemaVersion = 1
 )
 
 // EventPayload represents the JSON payload published to Redis Pub/Sub.
 type EventPayload struct {
+	SchemaVersion int    `json:"schema_version"`
 	EventID       string `json:"event_id"`
 	AggregateType string `json:"aggregate_type"`
 	AggregateID   string `json:"aggregate_id"`
@@ -39,7 +45,23 @@ func NewPublisher(redisClient *redis.Client) *Publisher {
 // Publish publishes an outbox event to the Redis Pub/Sub channel.
 // Returns the number of subscribers that received the message.
 func (p *Publisher) Publish(ctx context.Context, event repository.Outbox) (string, error) {
+	jsonData, err := marshalEventPayload(event)
+	if err != nil {
+		return "", err
+	}
+
+	result, err := p.redis.Publish(ctx, ChannelName, jsonData).Result()
+	if err != nil {
+		return "", fmt.Errorf("failed to publish to channel %s: %w", ChannelName, err)
+	}
+
+	return fmt.Sprintf("%d", result), nil
+}
+
+// marshalEventPayload builds the JSON message delivered to subscribers for an outbox event.
+func marshalEventPayload(event repository.Outbox) ([]byte, error) {
 	payload := EventPayload{
+		SchemaVersion: SchemaVersion,
 		EventID:       event.ID.String(),
 		AggregateType: event.AggregateType,
 		AggregateID:   event.AggregateID.String(),
@@ -49,15 +71,9 @@ func (p *Publisher) Publish(ctx context.Context, event repository.Outbox) (strin
 
 	jsonData, err := json.Marshal(payload)
 	if err != nil {
-		return "", fmt.Errorf("failed to marshal event payload: %w", err)
+		return nil, fmt.Errorf("failed to marshal event payload: %w", err)
 	}
-
-	result, err := p.redis.Publish(ctx, ChannelName, jsonData).Result()
-	if err != nil {
-		return "", fmt.Errorf("failed to publish to channel %s: %w", ChannelName, err)
-	}
-
-	return fmt.Sprintf("%d", result), nil
+	return jsonData, nil
 }
 
 // PublisherInterface defines the interface for event publishing (for testing).
@@ -33,6 +33,7 @@ func TestPublisher_Publish(t *testing.T) {
 
 	// Build expected payload
 	expectedPayload := EventPayload{
+		SchemaVersion: SchemaVersion,
 		EventID:       event.ID.String(),
 		AggregateType: event.AggregateType,
 		AggregateID:   event.AggregateID.String(),
@@ -68,6 +69,7 @@ func TestPublisher_Publish_NoSubscribers(t *testing.T) {
 	}
 
 	expectedPayload := EventPayload{
+		SchemaVersion: SchemaVersion,
 		EventID:       event.ID.String(),
 		AggregateType: event.AggregateType,
 		AggregateID:   event.AggregateID.String(),
@@ -103,6 +105,7 @@ func TestPublisher_Publish_Error(t *testing.T) {
 	}
 
 	expectedPayload := EventPayload{
+		SchemaVersion: SchemaVersion,
 		EventID:       event.ID.String(),
 		AggregateType: event.AggregateType,
 		AggregateID:   event.AggregateID.String(),
@@ -143,3 +146,15 @@ func TestEventPayload_JSON(t *testing.T) {
 	assert.Equal(t, payload.CreatedAt, decoded.CreatedAt)
 	assert.JSONEq(t, `{"content":"hello"}`, string(decoded.Payload))
 }
+
+func TestMarshalEventPayload_SchemaVersion(t *testing.T) {
+	data, err := marshalEventPayload(repository.Outbox{
+		AggregateType: "message",
+		Payload:       []byte(`{}`),
+	})
+	require.NoError(t, err)
+
+	var envelope map[string]json.RawMessage
+	require.NoError(t, json.Unmarshal(data, &envelope))
+	assert.JSONEq(t, "1", string(envelope["schema_version"]), "every published event carries the processor's schema version")
+}
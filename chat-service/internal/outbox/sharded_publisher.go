@@ -0,0 +1,62 @@
+package outbox
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"chat-service/internal/repository"
+	"chat-service/pkg/eventshard"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// shardRouting is the part of an event payload that decides its channel.
+type shardRouting struct {
+	ConversationID  string `json:"conversation_id"`
+	NewParticipants bool   `json:"new_participants"`
+}
+
+// ShardedPublisher publishes each event on the Pub/Sub shard channel of its conversation
+// (chat:events:{n}), so a ws-gateway only receives events for conversations of its users.
+// Events that add participants, or carry no conversation, go to the ChannelName broadcast
+// channel every gateway listens on: the gateways of new members are not subscribed to
+// the conversation's shard yet.
+type ShardedPublisher struct {
+	redis  redis.Cmdable
+	shards int
+}
+
+// NewShardedPublisher creates a sharded Pub/Sub publisher over the given number of shards.
+func NewShardedPublisher(redisClient redis.Cmdable, shards int) *ShardedPublisher {
+	return &ShardedPublisher{
+		redis:  redisClient,
+		shards: shards,
+	}
+}
+
+// Publish publishes an outbox event on its channel.
+// Returns the number of subscribers that received the message.
+func (p *ShardedPublisher) Publish(ctx context.Context, event repository.Outbox) (string, error) {
+	jsonData, err := marshalEventPayload(event)
+	if err != nil {
+		return "", err
+	}
+
+	channel := p.channelFor(event.Payload)
+	result, err := p.redis.Publish(ctx, channel, jsonData).Result()
+	if err != nil {
+		return "", fmt.Errorf("failed to publish to channel %s: %w", channel, err)
+	}
+
+	return fmt.Sprintf("%d", result), nil
+}
+
+// channelFor picks the shard channel of the event's conversation, or the broadcast channel.
+func (p *ShardedPublisher) channelFor(payload []byte) string {
+	var routing shardRouting
+	if err := json.Unmarshal(payload, &routing); err != nil || routing.ConversationID == "" || routing.NewParticipants {
+		return ChannelName
+	}
+	return eventshard.ChannelFor(ChannelName, routing.ConversationID, p.shards)
+}
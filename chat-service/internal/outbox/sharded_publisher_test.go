@@ -0,0 +1,65 @@
+package outbox
+
+import (
+	"context"
+	"testing"
+
+	"chat-service/internal/repository"
+	"chat-service/pkg/eventshard"
+
+	"github.com/go-redis/redismock/v9"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestShardedPublisher_PublishesToConversationShard(t *testing.T) {
+	db, mock := redismock.NewClientMock()
+	publisher := NewShardedPublisher(db, 8)
+
+	event := newStreamTestEvent()
+	event.Payload = []byte(`{"conversation_id":"550e8400-e29b-41d4-a716-446655440000","receiver_ids":["u1"]}`)
+	expectedJSON, err := marshalEventPayload(event)
+	require.NoError(t, err)
+
+	channel := eventshard.ChannelFor(ChannelName, "550e8400-e29b-41d4-a716-446655440000", 8)
+	mock.ExpectPublish(channel, expectedJSON).SetVal(1)
+
+	result, err := publisher.Publish(context.Background(), event)
+	require.NoError(t, err)
+	assert.Equal(t, "1", result)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestShardedPublisher_ChannelFor(t *testing.T) {
+	publisher := NewShardedPublisher(nil, 8)
+	conversationID := "550e8400-e29b-41d4-a716-446655440000"
+
+	tests := []struct {
+		name    string
+		payload string
+		want    string
+	}{
+		{"conversation event", `{"conversation_id":"` + conversationID + `"}`, eventshard.ChannelFor(ChannelName, conversationID, 8)},
+		{"new participants are broadcast", `{"conversation_id":"` + conversationID + `","new_participants":true}`, ChannelName},
+		{"no conversation is broadcast", `{"user_id":"u1"}`, ChannelName},
+		{"invalid payload is broadcast", `not json`, ChannelName},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, publisher.channelFor([]byte(tt.payload)))
+		})
+	}
+}
+
+func TestShardedPublisher_Error(t *testing.T) {
+	db, mock := redismock.NewClientMock()
+	publisher := NewShardedPublisher(db, 4)
+
+	event := repository.Outbox{Payload: []byte(`{}`)}
+	expectedJSON, err := marshalEventPayload(event)
+	require.NoError(t, err)
+	mock.ExpectPublish(ChannelName, expectedJSON).SetErr(assert.AnError)
+
+	_, err = publisher.Publish(context.Background(), event)
+	assert.Error(t, err)
+}
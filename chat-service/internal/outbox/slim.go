@@ -0,0 +1,65 @@
+package outbox
+
+import (
+	"encoding/json"
+
+	"chat-service/internal/repository"
+)
+
+// Payload forms, the "form" label of the published payloads metric
+const (
+	PayloadFormFull = "full"
+	PayloadFormSlim = "slim"
+)
+
+// slimPayloadFields are the message event fields kept in a slim event:
+// what a gateway needs to route the event and to load the message itself.
+var slimPayloadFields = []string{
+	"event_type",
+	"message_id",
+	"conversation_id",
+	"sender_id",
+	"receiver_ids",
+	"deliveries",
+	"moderation",
+	"created_at",
+	"request_id",
+	"user_id",
+	"origin_device_id",
+}
+
+// slimEvent returns event with its payload reduced to ids when the payload exceeds maxBytes
+// (0 = no limit), and whether it did. Only message.sent events are slimmed: gateways load the
+// message by message_id before delivery. Content, attachments and sender profile are dropped,
+// receiver_ids are kept because routing needs them, deliveries because push notifications do,
+// moderation because reviewers cannot tell a flagged message from the message alone.
+func slimEvent(event repository.Outbox, maxBytes int) (repository.Outbox, bool) {
+	if maxBytes <= 0 || len(event.Payload) <= maxBytes || event.AggregateType != "message" {
+		return event, false
+	}
+
+	var fields map[string]json.RawMessage
+	if err := json.Unmarshal(event.Payload, &fields); err != nil || fields["message_id"] == nil {
+		// Not a payload a gateway could load; publish it as is
+		return event, false
+	}
+	if eventType, ok := fields["event_type"]; ok && string(eventType) != `"message.sent"` {
+		// Reaction deltas and the like carry their data inline, loading the message would not restore it
+		return event, false
+	}
+
+	slim := make(map[string]json.RawMessage, len(slimPayloadFields)+1)
+	for _, name := range slimPayloadFields {
+		if value, ok := fields[name]; ok {
+			slim[name] = value
+		}
+	}
+	slim["slim"] = json.RawMessage("true")
+
+	payload, err := json.Marshal(slim)
+	if err != nil {
+		return event, false
+	}
+	event.Payload = payload
+	return event, true
+}
@@ -0,0 +1,147 @@
+package outbox
+
+import (
+	"context"
+	"encoding/json"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"chat-service/internal/repository"
+
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+func newMessageEvent(t *testing.T, content string) repository.Outbox {
+	t.Helper()
+	payload, err := json.Marshal(map[string]interface{}{
+		"event_type":       "message.sent",
+		"message_id":       "770e8400-e29b-41d4-a716-446655440000",
+		"conversation_id":  "550e8400-e29b-41d4-a716-446655440000",
+		"sender_id":        "660e8400-e29b-41d4-a716-446655440000",
+		"receiver_ids":     []string{"880e8400-e29b-41d4-a716-446655440000"},
+		"deliveries":       []map[string]interface{}{{"user_id": "880e8400-e29b-41d4-a716-446655440000", "notify": false}},
+		"content":          content,
+		"type":             "text",
+		"created_at":       "2026-01-01T00:00:00Z",
+		"sender_name":      "Alice",
+		"origin_device_id": "phone",
+		"moderation":       map[string]interface{}{"flagged": true, "flags": []string{"wordlist"}},
+	})
+	require.NoError(t, err)
+	return repository.Outbox{
+		ID:            pgtype.UUID{Bytes: [16]byte{1}, Valid: true},
+		AggregateType: "message",
+		Payload:       payload,
+	}
+}
+
+func TestSlimEvent(t *testing.T) {
+	t.Run("within limit stays full", func(t *testing.T) {
+		event := newMessageEvent(t, "hello")
+		got, slim := slimEvent(event, 4096)
+		assert.False(t, slim)
+		assert.Equal(t, event.Payload, got.Payload)
+	})
+
+	t.Run("no limit stays full", func(t *testing.T) {
+		event := newMessageEvent(t, strings.Repeat("x", 8192))
+		_, slim := slimEvent(event, 0)
+		assert.False(t, slim)
+	})
+
+	t.Run("oversized message keeps only ids", func(t *testing.T) {
+		event := newMessageEvent(t, strings.Repeat("x", 8192))
+		got, slim := slimEvent(event, 1024)
+		require.True(t, slim)
+		assert.Less(t, len(got.Payload), 1024)
+		assert.Equal(t, event.ID, got.ID)
+
+		var fields map[string]interface{}
+		require.NoError(t, json.Unmarshal(got.Payload, &fields))
+		assert.Equal(t, true, fields["slim"])
+		assert.Equal(t, "770e8400-e29b-41d4-a716-446655440000", fields["message_id"])
+		assert.Equal(t, []interface{}{"880e8400-e29b-41d4-a716-446655440000"}, fields["receiver_ids"])
+		assert.Equal(t, "phone", fields["origin_device_id"])
+		assert.Len(t, fields["deliveries"], 1, "notify flags cannot be reloaded from the message")
+		assert.Contains(t, fields, "moderation", "moderation flags cannot be reloaded either")
+		assert.NotContains(t, fields, "content")
+		assert.NotContains(t, fields, "sender_name")
+	})
+
+	t.Run("other aggregates stay full", func(t *testing.T) {
+		event := newMessageEvent(t, strings.Repeat("x", 8192))
+		event.AggregateType = "conversation"
+		_, slim := slimEvent(event, 1024)
+		assert.False(t, slim)
+	})
+
+	t.Run("reaction deltas stay full", func(t *testing.T) {
+		receivers := make([]string, 64)
+		for i := range receivers {
+			receivers[i] = "880e8400-e29b-41d4-a716-446655440000"
+		}
+		payload, err := json.Marshal(map[string]interface{}{
+			"event_type":   "reaction_added",
+			"message_id":   "770e8400-e29b-41d4-a716-446655440000",
+			"emoji":        "👍",
+			"new_count":    3,
+			"receiver_ids": receivers,
+		})
+		require.NoError(t, err)
+		_, slim := slimEvent(repository.Outbox{AggregateType: "message", Payload: payload}, 1024)
+		assert.False(t, slim, "a gateway cannot restore emoji and new_count by loading the message")
+	})
+
+	t.Run("payload without message_id stays full", func(t *testing.T) {
+		event := repository.Outbox{AggregateType: "message", Payload: []byte(`{"content":"` + strings.Repeat("x", 2048) + `"}`)}
+		_, slim := slimEvent(event, 1024)
+		assert.False(t, slim)
+	})
+}
+
+// payloadPublisher records the payloads it publishes by event ID
+type payloadPublisher struct {
+	mu       sync.Mutex
+	payloads map[pgtype.UUID][]byte
+}
+
+func (p *payloadPublisher) Publish(ctx context.Context, event repository.Outbox) (string, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.payloads[event.ID] = event.Payload
+	return "0-1", nil
+}
+
+func TestProcessBatch_PublishesOversizedEventsSlim(t *testing.T) {
+	processor := NewProcessor(nil, nil, zap.NewNop(), ProcessorConfig{
+		PollInterval:    time.Second,
+		WorkerCount:     1,
+		MaxPayloadBytes: 1024,
+	})
+	publisher := &payloadPublisher{payloads: make(map[pgtype.UUID][]byte)}
+	processor.publisher = publisher
+
+	fullBefore := testutil.ToFloat64(DefaultMetrics.PublishedPayloads.WithLabelValues(PayloadFormFull))
+	slimBefore := testutil.ToFloat64(DefaultMetrics.PublishedPayloads.WithLabelValues(PayloadFormSlim))
+
+	small := newMessageEvent(t, "hello")
+	large := newMessageEvent(t, strings.Repeat("x", 8192))
+	large.ID = pgtype.UUID{Bytes: [16]byte{2}, Valid: true}
+
+	processed, _, err := processor.processBatchWithTxAndMetrics(context.Background(), repository.New(&recordingDB{}), []repository.Outbox{small, large})
+	require.NoError(t, err)
+	require.Equal(t, 2, processed)
+
+	require.Len(t, publisher.payloads, 2)
+	assert.Equal(t, small.Payload, publisher.payloads[small.ID])
+	assert.Contains(t, string(publisher.payloads[large.ID]), `"slim":true`)
+
+	assert.Equal(t, fullBefore+1, testutil.ToFloat64(DefaultMetrics.PublishedPayloads.WithLabelValues(PayloadFormFull)))
+	assert.Equal(t, slimBefore+1, testutil.ToFloat64(DefaultMetrics.PublishedPayloads.WithLabelValues(PayloadFormSlim)))
+}
@@ -0,0 +1,62 @@
+package outbox
+
+import (
+	"context"
+	"fmt"
+
+	"chat-service/internal/repository"
+
+	"github.com/redis/go-redis/v9"
+)
+
+const (
+	// StreamName is the Redis Stream chat events are appended to in stream mode.
+	StreamName = "chat:events:stream"
+
+	// StreamPayloadField is the stream entry field holding the JSON EventPayload.
+	StreamPayloadField = "payload"
+
+	// DefaultStreamMaxLen bounds the stream length (approximate trimming).
+	// Consumers that fall further behind than this lose the oldest events.
+	DefaultStreamMaxLen = 100000
+)
+
+// StreamPublisher appends outbox events to a Redis Stream. Unlike Pub/Sub, entries
+// are kept until trimmed, so consumer groups pick up events published while a
+// subscriber was down.
+type StreamPublisher struct {
+	redis  redis.Cmdable
+	maxLen int64
+}
+
+// NewStreamPublisher creates a Redis Stream publisher. maxLen <= 0 uses DefaultStreamMaxLen.
+func NewStreamPublisher(redisClient redis.Cmdable, maxLen int64) *StreamPublisher {
+	if maxLen <= 0 {
+		maxLen = DefaultStreamMaxLen
+	}
+	return &StreamPublisher{
+		redis:  redisClient,
+		maxLen: maxLen,
+	}
+}
+
+// Publish appends an outbox event to the stream (XADD with approximate MAXLEN trimming).
+// Returns the stream entry ID.
+func (p *StreamPublisher) Publish(ctx context.Context, event repository.Outbox) (string, error) {
+	jsonData, err := marshalEventPayload(event)
+	if err != nil {
+		return "", err
+	}
+
+	id, err := p.redis.XAdd(ctx, &redis.XAddArgs{
+		Stream: StreamName,
+		MaxLen: p.maxLen,
+		Approx: true,
+		Values: map[string]interface{}{StreamPayloadField: jsonData},
+	}).Result()
+	if err != nil {
+		return "", fmt.Errorf("failed to append to stream %s: %w", StreamName, err)
+	}
+
+	return id, nil
+}
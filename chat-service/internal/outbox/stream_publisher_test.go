@@ -0,0 +1,85 @@
+package outbox
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"chat-service/internal/repository"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/redis/go-redis/v9"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newStreamTestEvent() repository.Outbox {
+	return repository.Outbox{
+		ID:            pgtype.UUID{Bytes: uuid.New(), Valid: true},
+		AggregateType: "message",
+		AggregateID:   pgtype.UUID{Bytes: uuid.New(), Valid: true},
+		Payload:       []byte(`{"message_id":"123","content":"hello"}`),
+		CreatedAt:     pgtype.Timestamptz{Time: time.Now(), Valid: true},
+	}
+}
+
+func TestStreamPublisher_Publish(t *testing.T) {
+	mr := miniredis.RunT(t)
+	client := redis.NewClient(&redis.Options{Addr: mr.Addr()})
+	t.Cleanup(func() { _ = client.Close() })
+
+	publisher := NewStreamPublisher(client, 0)
+	event := newStreamTestEvent()
+
+	id, err := publisher.Publish(context.Background(), event)
+	require.NoError(t, err)
+	assert.NotEmpty(t, id)
+
+	entries, err := client.XRange(context.Background(), StreamName, "-", "+").Result()
+	require.NoError(t, err)
+	require.Len(t, entries, 1)
+	assert.Equal(t, id, entries[0].ID)
+
+	var payload EventPayload
+	require.NoError(t, json.Unmarshal([]byte(entries[0].Values[StreamPayloadField].(string)), &payload))
+	assert.Equal(t, event.ID.String(), payload.EventID)
+	assert.Equal(t, event.AggregateType, payload.AggregateType)
+	assert.Equal(t, event.AggregateID.String(), payload.AggregateID)
+	assert.JSONEq(t, string(event.Payload), string(payload.Payload))
+}
+
+// TestStreamPublisher_KeepsEventsWithoutConsumers verifies events survive with nobody reading,
+// unlike Pub/Sub where they are dropped
+func TestStreamPublisher_KeepsEventsWithoutConsumers(t *testing.T) {
+	mr := miniredis.RunT(t)
+	client := redis.NewClient(&redis.Options{Addr: mr.Addr()})
+	t.Cleanup(func() { _ = client.Close() })
+
+	publisher := NewStreamPublisher(client, 0)
+	for i := 0; i < 3; i++ {
+		_, err := publisher.Publish(context.Background(), newStreamTestEvent())
+		require.NoError(t, err)
+	}
+
+	length, err := client.XLen(context.Background(), StreamName).Result()
+	require.NoError(t, err)
+	assert.Equal(t, int64(3), length)
+}
+
+func TestStreamPublisher_Error(t *testing.T) {
+	mr := miniredis.RunT(t)
+	client := redis.NewClient(&redis.Options{Addr: mr.Addr()})
+	t.Cleanup(func() { _ = client.Close() })
+
+	mr.SetError("connection lost")
+	_, err := NewStreamPublisher(client, 0).Publish(context.Background(), newStreamTestEvent())
+	assert.Error(t, err)
+}
+
+func TestNewStreamPublisherDefaults(t *testing.T) {
+	assert.Equal(t, int64(DefaultStreamMaxLen), NewStreamPublisher(nil, 0).maxLen)
+	assert.Equal(t, int64(500), NewStreamPublisher(nil, 500).maxLen)
+}
@@ -0,0 +1,58 @@
+package outbox
+
+import (
+	"sync"
+	"time"
+)
+
+// DefaultThroughputWindow is the window the events/sec gauge is averaged over.
+const DefaultThroughputWindow = 10 * time.Second
+
+// throughputMeter computes a rolling events-per-second rate from one-second buckets.
+type throughputMeter struct {
+	mu      sync.Mutex
+	counts  []int64 // events per second, a ring indexed by unix second
+	seconds []int64 // unix second each bucket currently holds
+}
+
+// newThroughputMeter creates a meter averaging over window (at least one second).
+func newThroughputMeter(window time.Duration) *throughputMeter {
+	size := int(window / time.Second)
+	if size < 1 {
+		size = 1
+	}
+	return &throughputMeter{
+		counts:  make([]int64, size),
+		seconds: make([]int64, size),
+	}
+}
+
+// Add records n events at now.
+func (m *throughputMeter) Add(n int, now time.Time) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	sec := now.Unix()
+	i := int(sec % int64(len(m.counts)))
+	if m.seconds[i] != sec {
+		m.seconds[i] = sec
+		m.counts[i] = 0
+	}
+	m.counts[i] += int64(n)
+}
+
+// Rate returns the average events per second over the window ending at now.
+func (m *throughputMeter) Rate(now time.Time) float64 {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	sec := now.Unix()
+	window := int64(len(m.counts))
+	var total int64
+	for i, bucketSec := range m.seconds {
+		if sec-bucketSec < window && bucketSec <= sec {
+			total += m.counts[i]
+		}
+	}
+	return float64(total) / float64(window)
+}
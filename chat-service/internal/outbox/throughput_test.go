@@ -0,0 +1,29 @@
+package outbox
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestThroughputMeter_Rate(t *testing.T) {
+	m := newThroughputMeter(10 * time.Second)
+	start := time.Unix(1_700_000_000, 0)
+
+	assert.Zero(t, m.Rate(start))
+
+	// 20 events per second for 10 seconds
+	for i := 0; i < 10; i++ {
+		m.Add(20, start.Add(time.Duration(i)*time.Second))
+	}
+	assert.InDelta(t, 20, m.Rate(start.Add(9*time.Second)), 0.001)
+
+	// Idle: old buckets fall out of the window
+	assert.InDelta(t, 10, m.Rate(start.Add(14*time.Second)), 0.001)
+	assert.Zero(t, m.Rate(start.Add(time.Minute)))
+
+	// Reused buckets start from zero
+	m.Add(5, start.Add(time.Minute))
+	assert.InDelta(t, 0.5, m.Rate(start.Add(time.Minute)), 0.001)
+}
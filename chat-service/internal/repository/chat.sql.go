@@ -11,7 +11,7 @@ import (
 	"github.com/jackc/pgx/v5/pgtype"
 )
 
-const addConversationParticipants = `-- name: AddConversationParticipants :exec
+const addConversationParticipants = `-- name: AddConversationParticipants :execrows
 INSERT INTO conversation_participants (conversation_id, user_id, joined_at)
 SELECT $1, unnest($2::uuid[]), NOW()
 ON CONFLICT DO NOTHING
@@ -22,9 +22,13 @@ type AddConversationParticipantsParams struct {
 	Column2        []pgtype.UUID `json:"column_2"`
 }
 
-func (q *Queries) AddConversationParticipants(ctx context.Context, arg AddConversationParticipantsParams) error {
-	_, err := q.db.Exec(ctx, addConversationParticipants, arg.ConversationID, arg.Column2)
-	return err
+// Returns how many users joined (0 when everyone was already a participant)
+func (q *Queries) AddConversationParticipants(ctx context.Context, arg AddConversationParticipantsParams) (int64, error) {
+	result, err := q.db.Exec(ctx, addConversationParticipants, arg.ConversationID, arg.Column2)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected(), nil
 }
 
 const addParticipant = `-- name: AddParticipant :exec
@@ -43,6 +47,45 @@ func (q *Queries) AddParticipant(ctx context.Context, arg AddParticipantParams)
 	return err
 }
 
+const addReaction = `-- name: AddReaction :execrows
+INSERT INTO message_reactions (message_id, user_id, emoji)
+VALUES ($1, $2, $3)
+ON CONFLICT DO NOTHING
+`
+
+type AddReactionParams struct {
+	MessageID pgtype.UUID `json:"message_id"`
+	UserID    pgtype.UUID `json:"user_id"`
+	Emoji     string      `json:"emoji"`
+}
+
+// Returns 0 when the user already reacted with this emoji
+func (q *Queries) AddReaction(ctx context.Context, arg AddReactionParams) (int64, error) {
+	result, err := q.db.Exec(ctx, addReaction, arg.MessageID, arg.UserID, arg.Emoji)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected(), nil
+}
+
+const clearExpiredLastMessage = `-- name: ClearExpiredLastMessage :exec
+UPDATE conversations
+SET last_message_content = NULL
+WHERE id = $1
+  AND last_message_at < $2
+`
+
+type ClearExpiredLastMessageParams struct {
+	ID     pgtype.UUID        `json:"id"`
+	Cutoff pgtype.Timestamptz `json:"cutoff"`
+}
+
+// The conversation preview goes once its message is purged; last_message_at keeps the list order
+func (q *Queries) ClearExpiredLastMessage(ctx context.Context, arg ClearExpiredLastMessageParams) error {
+	_, err := q.db.Exec(ctx, clearExpiredLastMessage, arg.ID, arg.Cutoff)
+	return err
+}
+
 const countDLQEvents = `-- name: CountDLQEvents :one
 SELECT COUNT(*) FROM outbox_dlq
 `
@@ -65,6 +108,77 @@ func (q *Queries) CountDLQEventsByAggregateType(ctx context.Context, aggregateTy
 	return count, err
 }
 
+const countReactions = `-- name: CountReactions :one
+SELECT COUNT(*)::int
+FROM message_reactions
+WHERE message_id = $1
+  AND emoji = $2
+`
+
+type CountReactionsParams struct {
+	MessageID pgtype.UUID `json:"message_id"`
+	Emoji     string      `json:"emoji"`
+}
+
+func (q *Queries) CountReactions(ctx context.Context, arg CountReactionsParams) (int32, error) {
+	row := q.db.QueryRow(ctx, countReactions, arg.MessageID, arg.Emoji)
+	var column_1 int32
+	err := row.Scan(&column_1)
+	return column_1, err
+}
+
+const countUnreadMessages = `-- name: CountUnreadMessages :one
+SELECT COUNT(m.id)::int AS unread_count
+FROM conversation_participants cp
+JOIN messages m
+    ON m.conversation_id = cp.conversation_id
+   AND m.created_at > cp.last_read_at
+   AND m.type <> 'SYSTEM'
+WHERE cp.conversation_id = $1
+  AND cp.user_id = $2
+`
+
+type CountUnreadMessagesParams struct {
+	ConversationID pgtype.UUID `json:"conversation_id"`
+	UserID         pgtype.UUID `json:"user_id"`
+}
+
+// Counted like unread_count in GetConversationsForUser, so a badge set from it matches the next list refresh
+func (q *Queries) CountUnreadMessages(ctx context.Context, arg CountUnreadMessagesParams) (int32, error) {
+	row := q.db.QueryRow(ctx, countUnreadMessages, arg.ConversationID, arg.UserID)
+	var unread_count int32
+	err := row.Scan(&unread_count)
+	return unread_count, err
+}
+
+const createConversation = `-- name: CreateConversation :one
+INSERT INTO conversations (type, name, last_message_at)
+VALUES ($1, $2, NOW())
+RETURNING id, created_at, last_message_content, last_message_at, message_ttl_seconds, type, name
+`
+
+type CreateConversationParams struct {
+	Type pgtype.Text `json:"type"`
+	Name pgtype.Text `json:"name"`
+}
+
+// last_message_at starts at creation so an empty conversation sorts and paginates
+// like the others in conversation lists (by last activity) until its first message.
+func (q *Queries) CreateConversation(ctx context.Context, arg CreateConversationParams) (Conversation, error) {
+	row := q.db.QueryRow(ctx, createConversation, arg.Type, arg.Name)
+	var i Conversation
+	err := row.Scan(
+		&i.ID,
+		&i.CreatedAt,
+		&i.LastMessageContent,
+		&i.LastMessageAt,
+		&i.MessageTtlSeconds,
+		&i.Type,
+		&i.Name,
+	)
+	return i, err
+}
+
 const deleteDLQEvent = `-- name: DeleteDLQEvent :exec
 DELETE FROM outbox_dlq WHERE id = $1
 `
@@ -74,6 +188,35 @@ func (q *Queries) DeleteDLQEvent(ctx context.Context, id pgtype.UUID) error {
 	return err
 }
 
+const deleteExpiredMessages = `-- name: DeleteExpiredMessages :execrows
+DELETE FROM messages
+WHERE id IN (
+    SELECT m.id
+    FROM messages m
+    WHERE m.conversation_id = $1
+      AND m.created_at < $2
+    ORDER BY m.created_at
+    LIMIT $3
+    FOR UPDATE SKIP LOCKED
+)
+`
+
+type DeleteExpiredMessagesParams struct {
+	ConversationID pgtype.UUID        `json:"conversation_id"`
+	Cutoff         pgtype.Timestamptz `json:"cutoff"`
+	BatchSize      int32              `json:"batch_size"`
+}
+
+// Deletes at most batch_size messages older than the cutoff (attachments cascade).
+// SKIP LOCKED lets purge jobs on several replicas split the work instead of queueing.
+func (q *Queries) DeleteExpiredMessages(ctx context.Context, arg DeleteExpiredMessagesParams) (int64, error) {
+	result, err := q.db.Exec(ctx, deleteExpiredMessages, arg.ConversationID, arg.Cutoff, arg.BatchSize)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected(), nil
+}
+
 const deleteOutboxEvent = `-- name: DeleteOutboxEvent :exec
 DELETE FROM outbox WHERE id = $1
 `
@@ -83,6 +226,64 @@ func (q *Queries) DeleteOutboxEvent(ctx context.Context, id pgtype.UUID) error {
 	return err
 }
 
+const exportMessages = `-- name: ExportMessages :many
+SELECT id, conversation_id, sender_id, content, created_at, type, media_url, media_metadata, idempotency_key, deleted_at, edited_at
+FROM messages
+WHERE conversation_id = $1
+	AND deleted_at IS NULL
+	AND (
+		$2::timestamptz IS NULL
+		OR (created_at, id) > ($2::timestamptz, $3::uuid)
+	)
+ORDER BY created_at, id
+LIMIT $4
+`
+
+type ExportMessagesParams struct {
+	ConversationID pgtype.UUID        `json:"conversation_id"`
+	AfterCreatedAt pgtype.Timestamptz `json:"after_created_at"`
+	AfterID        pgtype.UUID        `json:"after_id"`
+	Limit          int32              `json:"limit"`
+}
+
+// Oldest-first keyset page for exports: messages after (after_created_at, after_id); deleted ones are left out
+func (q *Queries) ExportMessages(ctx context.Context, arg ExportMessagesParams) ([]Message, error) {
+	rows, err := q.db.Query(ctx, exportMessages,
+		arg.ConversationID,
+		arg.AfterCreatedAt,
+		arg.AfterID,
+		arg.Limit,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []Message
+	for rows.Next() {
+		var i Message
+		if err := rows.Scan(
+			&i.ID,
+			&i.ConversationID,
+			&i.SenderID,
+			&i.Content,
+			&i.CreatedAt,
+			&i.Type,
+			&i.MediaUrl,
+			&i.MediaMetadata,
+			&i.IdempotencyKey,
+			&i.DeletedAt,
+			&i.EditedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
 const getAndLockUnprocessedOutbox = `-- name: GetAndLockUnprocessedOutbox :many
 SELECT id, aggregate_type, aggregate_id, payload, created_at, processed_at, retry_count, last_retry_at
 FROM outbox
@@ -170,6 +371,82 @@ func (q *Queries) GetAndLockUnprocessedOutboxWithRetry(ctx context.Context, arg
 	return items, nil
 }
 
+const getAttachmentsForMessages = `-- name: GetAttachmentsForMessages :many
+SELECT message_id, url, mime_type, size_bytes, width, height
+FROM message_attachments
+WHERE message_id = ANY($1::uuid[])
+ORDER BY message_id, position
+`
+
+type GetAttachmentsForMessagesRow struct {
+	MessageID pgtype.UUID `json:"message_id"`
+	Url       string      `json:"url"`
+	MimeType  string      `json:"mime_type"`
+	SizeBytes int64       `json:"size_bytes"`
+	Width     pgtype.Int4 `json:"width"`
+	Height    pgtype.Int4 `json:"height"`
+}
+
+func (q *Queries) GetAttachmentsForMessages(ctx context.Context, messageIds []pgtype.UUID) ([]GetAttachmentsForMessagesRow, error) {
+	rows, err := q.db.Query(ctx, getAttachmentsForMessages, messageIds)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []GetAttachmentsForMessagesRow
+	for rows.Next() {
+		var i GetAttachmentsForMessagesRow
+		if err := rows.Scan(
+			&i.MessageID,
+			&i.Url,
+			&i.MimeType,
+			&i.SizeBytes,
+			&i.Width,
+			&i.Height,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getConversationNotificationLevels = `-- name: GetConversationNotificationLevels :many
+SELECT user_id, notification_level
+FROM conversation_participants
+WHERE conversation_id = $1
+  AND notification_level <> 'ALL'
+`
+
+type GetConversationNotificationLevelsRow struct {
+	UserID            pgtype.UUID `json:"user_id"`
+	NotificationLevel string      `json:"notification_level"`
+}
+
+// Only participants that changed the default: everyone else is notified of every message
+func (q *Queries) GetConversationNotificationLevels(ctx context.Context, conversationID pgtype.UUID) ([]GetConversationNotificationLevelsRow, error) {
+	rows, err := q.db.Query(ctx, getConversationNotificationLevels, conversationID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []GetConversationNotificationLevelsRow
+	for rows.Next() {
+		var i GetConversationNotificationLevelsRow
+		if err := rows.Scan(&i.UserID, &i.NotificationLevel); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
 const getConversationParticipants = `-- name: GetConversationParticipants :many
 SELECT user_id
 FROM conversation_participants
@@ -196,40 +473,190 @@ func (q *Queries) GetConversationParticipants(ctx context.Context, conversationI
 	return items, nil
 }
 
+const getConversationsByIDs = `-- name: GetConversationsByIDs :many
+WITH selected AS (
+    SELECT
+        c.id,
+        c.last_message_content,
+        c.last_message_at,
+        c.message_ttl_seconds,
+        c.type,
+        c.name,
+        cp.last_read_at,
+        cp.archived_at,
+        cp.pinned_at,
+        cp.notification_level
+    FROM conversations c
+    JOIN conversation_participants cp ON c.id = cp.conversation_id
+    WHERE cp.user_id = $1
+      AND cp.hidden_at IS NULL
+      AND c.id = ANY($2::uuid[])
+)
+SELECT
+    s.id,
+    s.last_message_content,
+    s.last_message_at,
+    s.archived_at,
+    s.pinned_at,
+    s.notification_level,
+    s.message_ttl_seconds,
+    s.type,
+    s.name,
+    COUNT(m.id) AS unread_count
+FROM selected s
+LEFT JOIN messages m
+    ON m.conversation_id = s.id
+   AND m.created_at > s.last_read_at
+   AND m.type <> 'SYSTEM'
+GROUP BY s.id, s.last_message_content, s.last_message_at, s.archived_at, s.pinned_at, s.notification_level, s.message_ttl_seconds, s.type, s.name
+ORDER BY s.last_message_at DESC, s.id DESC
+`
+
+type GetConversationsByIDsParams struct {
+	UserID pgtype.UUID   `json:"user_id"`
+	Ids    []pgtype.UUID `json:"ids"`
+}
+
+type GetConversationsByIDsRow struct {
+	ID                 pgtype.UUID        `json:"id"`
+	LastMessageContent pgtype.Text        `json:"last_message_content"`
+	LastMessageAt      pgtype.Timestamptz `json:"last_message_at"`
+	ArchivedAt         pgtype.Timestamptz `json:"archived_at"`
+	PinnedAt           pgtype.Timestamptz `json:"pinned_at"`
+	NotificationLevel  string             `json:"notification_level"`
+	MessageTtlSeconds  pgtype.Int4        `json:"message_ttl_seconds"`
+	Type               pgtype.Text        `json:"type"`
+	Name               pgtype.Text        `json:"name"`
+	UnreadCount        int64              `json:"unread_count"`
+}
+
+// Same rows as GetConversationsForUser for an explicit set of ids, archived or not;
+// ids the user does not participate in (or has hidden) are left out.
+func (q *Queries) GetConversationsByIDs(ctx context.Context, arg GetConversationsByIDsParams) ([]GetConversationsByIDsRow, error) {
+	rows, err := q.db.Query(ctx, getConversationsByIDs, arg.UserID, arg.Ids)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []GetConversationsByIDsRow
+	for rows.Next() {
+		var i GetConversationsByIDsRow
+		if err := rows.Scan(
+			&i.ID,
+			&i.LastMessageContent,
+			&i.LastMessageAt,
+			&i.ArchivedAt,
+			&i.PinnedAt,
+			&i.NotificationLevel,
+			&i.MessageTtlSeconds,
+			&i.Type,
+			&i.Name,
+			&i.UnreadCount,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
 const getConversationsForUser = `-- name: GetConversationsForUser :many
-SELECT 
-    c.id,
-    c.last_message_content,
-    c.last_message_at,
-    (
-        SELECT COUNT(*) 
-        FROM messages m 
-        WHERE m.conversation_id = c.id 
-          AND m.created_at > cp.last_read_at
-    ) AS unread_count
-FROM conversations c
-JOIN conversation_participants cp ON c.id = cp.conversation_id
-WHERE cp.user_id = $1
-  AND ($2::timestamptz IS NULL OR c.last_message_at < $2::timestamptz)
-ORDER BY c.last_message_at DESC
-LIMIT $3
+WITH page AS (
+    SELECT
+        c.id,
+        c.last_message_content,
+        c.last_message_at,
+        c.message_ttl_seconds,
+        c.type,
+        c.name,
+        cp.last_read_at,
+        cp.archived_at,
+        cp.pinned_at,
+        cp.notification_level
+    FROM conversations c
+    JOIN conversation_participants cp ON c.id = cp.conversation_id
+    WHERE cp.user_id = $1
+      AND cp.hidden_at IS NULL
+      AND (cp.archived_at IS NULL OR $2::boolean OR $3::boolean)
+      AND (cp.archived_at IS NOT NULL OR NOT $3::boolean)
+      AND (
+        $4::timestamptz IS NULL
+        OR (
+          $5::boolean
+          AND (cp.pinned_at IS NULL OR (cp.pinned_at, c.id) < ($4::timestamptz, $6::uuid))
+        )
+        OR (
+          NOT $5::boolean
+          AND cp.pinned_at IS NULL
+          AND (c.last_message_at, c.id) < ($4::timestamptz, $6::uuid)
+        )
+      )
+    ORDER BY (cp.pinned_at IS NOT NULL) DESC, COALESCE(cp.pinned_at, c.last_message_at) DESC, c.id DESC
+    LIMIT $7
+)
+SELECT
+    p.id,
+    p.last_message_content,
+    p.last_message_at,
+    p.archived_at,
+    p.pinned_at,
+    p.notification_level,
+    p.message_ttl_seconds,
+    p.type,
+    p.name,
+    COUNT(m.id) AS unread_count
+FROM page p
+LEFT JOIN messages m
+    ON m.conversation_id = p.id
+   AND m.created_at > p.last_read_at
+   AND m.type <> 'SYSTEM'
+GROUP BY p.id, p.last_message_content, p.last_message_at, p.archived_at, p.pinned_at, p.notification_level, p.message_ttl_seconds, p.type, p.name
+ORDER BY (p.pinned_at IS NOT NULL) DESC, COALESCE(p.pinned_at, p.last_message_at) DESC, p.id DESC
 `
 
 type GetConversationsForUserParams struct {
-	UserID  pgtype.UUID        `json:"user_id"`
-	Column2 pgtype.Timestamptz `json:"column_2"`
-	Limit   int32              `json:"limit"`
+	UserID          pgtype.UUID        `json:"user_id"`
+	IncludeArchived bool               `json:"include_archived"`
+	OnlyArchived    bool               `json:"only_archived"`
+	BeforeSortAt    pgtype.Timestamptz `json:"before_sort_at"`
+	BeforePinned    bool               `json:"before_pinned"`
+	BeforeID        pgtype.UUID        `json:"before_id"`
+	Limit           int32              `json:"limit"`
 }
 
 type GetConversationsForUserRow struct {
 	ID                 pgtype.UUID        `json:"id"`
 	LastMessageContent pgtype.Text        `json:"last_message_content"`
 	LastMessageAt      pgtype.Timestamptz `json:"last_message_at"`
+	ArchivedAt         pgtype.Timestamptz `json:"archived_at"`
+	PinnedAt           pgtype.Timestamptz `json:"pinned_at"`
+	NotificationLevel  string             `json:"notification_level"`
+	MessageTtlSeconds  pgtype.Int4        `json:"message_ttl_seconds"`
+	Type               pgtype.Text        `json:"type"`
+	Name               pgtype.Text        `json:"name"`
 	UnreadCount        int64              `json:"unread_count"`
 }
 
+// Unread counts are computed in one grouped pass over the selected page
+// instead of a correlated subquery per conversation. System messages are never unread.
+// Archived conversations are left out unless include_archived; only_archived lists just those.
+// Pinned conversations come first, most recently pinned first, then the rest by last_message_at.
+// The keyset is (pinned, sort_at, id) with sort_at the pin time or the last message time:
+// a cursor in the pinned section continues with older pins and then every unpinned conversation,
+// a cursor in the unpinned section never returns to the pins.
 func (q *Queries) GetConversationsForUser(ctx context.Context, arg GetConversationsForUserParams) ([]GetConversationsForUserRow, error) {
-	rows, err := q.db.Query(ctx, getConversationsForUser, arg.UserID, arg.Column2, arg.Limit)
+	rows, err := q.db.Query(ctx, getConversationsForUser,
+		arg.UserID,
+		arg.IncludeArchived,
+		arg.OnlyArchived,
+		arg.BeforeSortAt,
+		arg.BeforePinned,
+		arg.BeforeID,
+		arg.Limit,
+	)
 	if err != nil {
 		return nil, err
 	}
@@ -241,6 +668,12 @@ func (q *Queries) GetConversationsForUser(ctx context.Context, arg GetConversati
 			&i.ID,
 			&i.LastMessageContent,
 			&i.LastMessageAt,
+			&i.ArchivedAt,
+			&i.PinnedAt,
+			&i.NotificationLevel,
+			&i.MessageTtlSeconds,
+			&i.Type,
+			&i.Name,
 			&i.UnreadCount,
 		); err != nil {
 			return nil, err
@@ -333,26 +766,37 @@ func (q *Queries) GetDLQEventsByAggregateType(ctx context.Context, arg GetDLQEve
 	return items, nil
 }
 
-const getMessages = `-- name: GetMessages :many
-SELECT id, conversation_id, sender_id, content, created_at, type, media_url, media_metadata
-FROM messages
-WHERE conversation_id = $1
+const getMentions = `-- name: GetMentions :many
+SELECT m.id, m.conversation_id, m.sender_id, m.content, m.created_at, m.type, m.media_url, m.media_metadata, m.idempotency_key, m.deleted_at, m.edited_at
+FROM message_mentions mm
+JOIN messages m ON m.id = mm.message_id
+JOIN conversation_participants cp ON cp.conversation_id = mm.conversation_id AND cp.user_id = mm.user_id
+WHERE mm.user_id = $1
+	AND m.deleted_at IS NULL
 	AND (
 		$2::timestamptz IS NULL
-		OR created_at < $2::timestamptz
+		OR (mm.created_at, mm.message_id) < ($2::timestamptz, $3::uuid)
 	)
-ORDER BY created_at DESC
-LIMIT $3
+ORDER BY mm.created_at DESC, mm.message_id DESC
+LIMIT $4
 `
 
-type GetMessagesParams struct {
-	ConversationID pgtype.UUID        `json:"conversation_id"`
-	Before         pgtype.Timestamptz `json:"before"`
-	Limit          int32              `json:"limit"`
+type GetMentionsParams struct {
+	UserID          pgtype.UUID        `json:"user_id"`
+	BeforeCreatedAt pgtype.Timestamptz `json:"before_created_at"`
+	BeforeID        pgtype.UUID        `json:"before_id"`
+	Limit           int32              `json:"limit"`
 }
 
-func (q *Queries) GetMessages(ctx context.Context, arg GetMessagesParams) ([]Message, error) {
-	rows, err := q.db.Query(ctx, getMessages, arg.ConversationID, arg.Before, arg.Limit)
+// Newest-first keyset page of the messages that mention the user, in conversations the user still belongs to;
+// deleted messages are left out
+func (q *Queries) GetMentions(ctx context.Context, arg GetMentionsParams) ([]Message, error) {
+	rows, err := q.db.Query(ctx, getMentions,
+		arg.UserID,
+		arg.BeforeCreatedAt,
+		arg.BeforeID,
+		arg.Limit,
+	)
 	if err != nil {
 		return nil, err
 	}
@@ -369,6 +813,9 @@ func (q *Queries) GetMessages(ctx context.Context, arg GetMessagesParams) ([]Mes
 			&i.Type,
 			&i.MediaUrl,
 			&i.MediaMetadata,
+			&i.IdempotencyKey,
+			&i.DeletedAt,
+			&i.EditedAt,
 		); err != nil {
 			return nil, err
 		}
@@ -380,32 +827,99 @@ func (q *Queries) GetMessages(ctx context.Context, arg GetMessagesParams) ([]Mes
 	return items, nil
 }
 
-const getUnprocessedOutbox = `-- name: GetUnprocessedOutbox :many
-SELECT id, aggregate_type, aggregate_id, payload, created_at, processed_at, retry_count, last_retry_at
-FROM outbox
-WHERE processed_at IS NULL
-ORDER BY created_at ASC
-LIMIT $1
+const getMessageByID = `-- name: GetMessageByID :one
+SELECT id, conversation_id, sender_id, content, created_at, type, media_url, media_metadata, idempotency_key, deleted_at, edited_at
+FROM messages
+WHERE id = $1
 `
 
-func (q *Queries) GetUnprocessedOutbox(ctx context.Context, limit int32) ([]Outbox, error) {
-	rows, err := q.db.Query(ctx, getUnprocessedOutbox, limit)
+func (q *Queries) GetMessageByID(ctx context.Context, id pgtype.UUID) (Message, error) {
+	row := q.db.QueryRow(ctx, getMessageByID, id)
+	var i Message
+	err := row.Scan(
+		&i.ID,
+		&i.ConversationID,
+		&i.SenderID,
+		&i.Content,
+		&i.CreatedAt,
+		&i.Type,
+		&i.MediaUrl,
+		&i.MediaMetadata,
+		&i.IdempotencyKey,
+		&i.DeletedAt,
+		&i.EditedAt,
+	)
+	return i, err
+}
+
+const getMessageIDByIdempotencyKey = `-- name: GetMessageIDByIdempotencyKey :one
+SELECT id
+FROM messages
+WHERE sender_id = $1
+  AND idempotency_key = $2
+`
+
+type GetMessageIDByIdempotencyKeyParams struct {
+	SenderID       pgtype.UUID `json:"sender_id"`
+	IdempotencyKey pgtype.Text `json:"idempotency_key"`
+}
+
+func (q *Queries) GetMessageIDByIdempotencyKey(ctx context.Context, arg GetMessageIDByIdempotencyKeyParams) (pgtype.UUID, error) {
+	row := q.db.QueryRow(ctx, getMessageIDByIdempotencyKey, arg.SenderID, arg.IdempotencyKey)
+	var id pgtype.UUID
+	err := row.Scan(&id)
+	return id, err
+}
+
+const getMessages = `-- name: GetMessages :many
+SELECT id, conversation_id, sender_id, content, created_at, type, media_url, media_metadata, idempotency_key, deleted_at, edited_at
+FROM messages
+WHERE conversation_id = $1
+	AND ($2::boolean OR deleted_at IS NULL)
+	AND (
+		$3::timestamptz IS NULL
+		OR (created_at, id) < ($3::timestamptz, $4::uuid)
+	)
+ORDER BY created_at DESC, id DESC
+LIMIT $5
+`
+
+type GetMessagesParams struct {
+	ConversationID  pgtype.UUID        `json:"conversation_id"`
+	IncludeDeleted  bool               `json:"include_deleted"`
+	BeforeCreatedAt pgtype.Timestamptz `json:"before_created_at"`
+	BeforeID        pgtype.UUID        `json:"before_id"`
+	Limit           int32              `json:"limit"`
+}
+
+// Deleted messages are only returned (as tombstones) when include_deleted is set
+func (q *Queries) GetMessages(ctx context.Context, arg GetMessagesParams) ([]Message, error) {
+	rows, err := q.db.Query(ctx, getMessages,
+		arg.ConversationID,
+		arg.IncludeDeleted,
+		arg.BeforeCreatedAt,
+		arg.BeforeID,
+		arg.Limit,
+	)
 	if err != nil {
 		return nil, err
 	}
 	defer rows.Close()
-	var items []Outbox
+	var items []Message
 	for rows.Next() {
-		var i Outbox
+		var i Message
 		if err := rows.Scan(
 			&i.ID,
-			&i.AggregateType,
-			&i.AggregateID,
-			&i.Payload,
+			&i.ConversationID,
+			&i.SenderID,
+			&i.Content,
 			&i.CreatedAt,
-			&i.ProcessedAt,
-			&i.RetryCount,
-			&i.LastRetryAt,
+			&i.Type,
+			&i.MediaUrl,
+			&i.MediaMetadata,
+			&i.IdempotencyKey,
+			&i.DeletedAt,
+			&i.EditedAt,
 		); err != nil {
 			return nil, err
 		}
@@ -417,11 +931,246 @@ func (q *Queries) GetUnprocessedOutbox(ctx context.Context, limit int32) ([]Outb
 	return items, nil
 }
 
-const incrementOutboxRetry = `-- name: IncrementOutboxRetry :exec
-UPDATE outbox
-SET retry_count = retry_count + 1,
-    last_retry_at = NOW()
-WHERE id = $1
+const getParticipantReceipts = `-- name: GetParticipantReceipts :many
+SELECT user_id, joined_at, last_read_at, last_delivered_at
+FROM conversation_participants
+WHERE conversation_id = $1
+`
+
+type GetParticipantReceiptsRow struct {
+	UserID          pgtype.UUID        `json:"user_id"`
+	JoinedAt        pgtype.Timestamptz `json:"joined_at"`
+	LastReadAt      pgtype.Timestamptz `json:"last_read_at"`
+	LastDeliveredAt pgtype.Timestamptz `json:"last_delivered_at"`
+}
+
+func (q *Queries) GetParticipantReceipts(ctx context.Context, conversationID pgtype.UUID) ([]GetParticipantReceiptsRow, error) {
+	rows, err := q.db.Query(ctx, getParticipantReceipts, conversationID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []GetParticipantReceiptsRow
+	for rows.Next() {
+		var i GetParticipantReceiptsRow
+		if err := rows.Scan(
+			&i.UserID,
+			&i.JoinedAt,
+			&i.LastReadAt,
+			&i.LastDeliveredAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getReactionsForMessages = `-- name: GetReactionsForMessages :many
+SELECT
+    message_id,
+    emoji,
+    COUNT(*)::int AS count,
+    BOOL_OR(user_id = $1::uuid) AS reacted_by_me
+FROM message_reactions
+WHERE message_id = ANY($2::uuid[])
+GROUP BY message_id, emoji
+ORDER BY message_id, MIN(created_at), emoji
+`
+
+type GetReactionsForMessagesParams struct {
+	UserID     pgtype.UUID   `json:"user_id"`
+	MessageIds []pgtype.UUID `json:"message_ids"`
+}
+
+type GetReactionsForMessagesRow struct {
+	MessageID   pgtype.UUID `json:"message_id"`
+	Emoji       string      `json:"emoji"`
+	Count       int32       `json:"count"`
+	ReactedByMe bool        `json:"reacted_by_me"`
+}
+
+// Aggregate snapshot per (message, emoji), emojis in the order they were first used
+func (q *Queries) GetReactionsForMessages(ctx context.Context, arg GetReactionsForMessagesParams) ([]GetReactionsForMessagesRow, error) {
+	rows, err := q.db.Query(ctx, getReactionsForMessages, arg.UserID, arg.MessageIds)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []GetReactionsForMessagesRow
+	for rows.Next() {
+		var i GetReactionsForMessagesRow
+		if err := rows.Scan(
+			&i.MessageID,
+			&i.Emoji,
+			&i.Count,
+			&i.ReactedByMe,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getUnprocessedOutbox = `-- name: GetUnprocessedOutbox :many
+SELECT id, aggregate_type, aggregate_id, payload, created_at, processed_at, retry_count, last_retry_at
+FROM outbox
+WHERE processed_at IS NULL
+ORDER BY created_at ASC
+LIMIT $1
+`
+
+func (q *Queries) GetUnprocessedOutbox(ctx context.Context, limit int32) ([]Outbox, error) {
+	rows, err := q.db.Query(ctx, getUnprocessedOutbox, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []Outbox
+	for rows.Next() {
+		var i Outbox
+		if err := rows.Scan(
+			&i.ID,
+			&i.AggregateType,
+			&i.AggregateID,
+			&i.Payload,
+			&i.CreatedAt,
+			&i.ProcessedAt,
+			&i.RetryCount,
+			&i.LastRetryAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getUnreadCountsForUser = `-- name: GetUnreadCountsForUser :many
+WITH recent AS (
+    SELECT
+        c.id,
+        c.last_message_at,
+        cp.last_read_at,
+        cp.pinned_at
+    FROM conversations c
+    JOIN conversation_participants cp ON c.id = cp.conversation_id
+    WHERE cp.user_id = $1
+      AND cp.hidden_at IS NULL
+      AND cp.archived_at IS NULL
+    ORDER BY (cp.pinned_at IS NOT NULL) DESC, COALESCE(cp.pinned_at, c.last_message_at) DESC, c.id DESC
+    LIMIT $2
+)
+SELECT
+    r.id,
+    r.last_message_at,
+    COUNT(m.id)::int AS unread_count
+FROM recent r
+LEFT JOIN messages m
+    ON m.conversation_id = r.id
+   AND m.created_at > r.last_read_at
+   AND m.type <> 'SYSTEM'
+GROUP BY r.id, r.last_message_at, r.pinned_at
+ORDER BY (r.pinned_at IS NOT NULL) DESC, COALESCE(r.pinned_at, r.last_message_at) DESC, r.id DESC
+`
+
+type GetUnreadCountsForUserParams struct {
+	UserID pgtype.UUID `json:"user_id"`
+	Limit  int32       `json:"limit"`
+}
+
+type GetUnreadCountsForUserRow struct {
+	ID            pgtype.UUID        `json:"id"`
+	LastMessageAt pgtype.Timestamptz `json:"last_message_at"`
+	UnreadCount   int32              `json:"unread_count"`
+}
+
+// Unread counts for the first conversations of the user's list (pinned first, then by last message,
+// archived and hidden ones left out), counted like unread_count in GetConversationsForUser.
+func (q *Queries) GetUnreadCountsForUser(ctx context.Context, arg GetUnreadCountsForUserParams) ([]GetUnreadCountsForUserRow, error) {
+	rows, err := q.db.Query(ctx, getUnreadCountsForUser, arg.UserID, arg.Limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []GetUnreadCountsForUserRow
+	for rows.Next() {
+		var i GetUnreadCountsForUserRow
+		if err := rows.Scan(&i.ID, &i.LastMessageAt, &i.UnreadCount); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const hasUnreadMessages = `-- name: HasUnreadMessages :one
+SELECT EXISTS (
+    SELECT 1
+    FROM conversation_participants cp
+    JOIN messages m ON m.conversation_id = cp.conversation_id
+    WHERE cp.conversation_id = $1
+      AND cp.user_id = $2
+      AND m.sender_id <> cp.user_id
+      AND m.created_at > cp.last_read_at
+      AND m.type <> 'SYSTEM'
+      AND ($3::timestamptz IS NULL OR m.created_at <= $3::timestamptz)
+)
+`
+
+type HasUnreadMessagesParams struct {
+	ConversationID pgtype.UUID        `json:"conversation_id"`
+	UserID         pgtype.UUID        `json:"user_id"`
+	ReadUpTo       pgtype.Timestamptz `json:"read_up_to"`
+}
+
+// read_up_to (NULL = no bound) limits the check to messages a MarkAsRead up to it would read
+// System messages don't count: reading only those sends no read receipt
+func (q *Queries) HasUnreadMessages(ctx context.Context, arg HasUnreadMessagesParams) (bool, error) {
+	row := q.db.QueryRow(ctx, hasUnreadMessages, arg.ConversationID, arg.UserID, arg.ReadUpTo)
+	var exists bool
+	err := row.Scan(&exists)
+	return exists, err
+}
+
+const hideConversation = `-- name: HideConversation :execrows
+UPDATE conversation_participants
+SET hidden_at = NOW()
+WHERE conversation_id = $1
+  AND user_id = $2
+`
+
+type HideConversationParams struct {
+	ConversationID pgtype.UUID `json:"conversation_id"`
+	UserID         pgtype.UUID `json:"user_id"`
+}
+
+func (q *Queries) HideConversation(ctx context.Context, arg HideConversationParams) (int64, error) {
+	result, err := q.db.Exec(ctx, hideConversation, arg.ConversationID, arg.UserID)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected(), nil
+}
+
+const incrementOutboxRetry = `-- name: IncrementOutboxRetry :exec
+UPDATE outbox
+SET retry_count = retry_count + 1,
+    last_retry_at = NOW()
+WHERE id = $1
 `
 
 func (q *Queries) IncrementOutboxRetry(ctx context.Context, id pgtype.UUID) error {
@@ -432,7 +1181,7 @@ func (q *Queries) IncrementOutboxRetry(ctx context.Context, id pgtype.UUID) erro
 const insertMediaMessage = `-- name: InsertMediaMessage :one
 INSERT INTO messages (conversation_id, sender_id, content, type, media_url, media_metadata)
 VALUES ($1, $2, $3, $4, $5, $6)
-RETURNING id, conversation_id, sender_id, content, created_at, type, media_url, media_metadata
+RETURNING id, conversation_id, sender_id, content, created_at, type, media_url, media_metadata, idempotency_key, deleted_at, edited_at
 `
 
 type InsertMediaMessageParams struct {
@@ -463,14 +1212,17 @@ func (q *Queries) InsertMediaMessage(ctx context.Context, arg InsertMediaMessage
 		&i.Type,
 		&i.MediaUrl,
 		&i.MediaMetadata,
+		&i.IdempotencyKey,
+		&i.DeletedAt,
+		&i.EditedAt,
 	)
 	return i, err
 }
 
 const insertMessage = `-- name: InsertMessage :one
-INSERT INTO messages (conversation_id, sender_id, content, type, media_url, media_metadata)
-VALUES ($1, $2, $3, $4, $5, $6)
-RETURNING id, conversation_id, sender_id, content, created_at, type, media_url, media_metadata
+INSERT INTO messages (conversation_id, sender_id, content, type, media_url, media_metadata, idempotency_key)
+VALUES ($1, $2, $3, $4, $5, $6, $7)
+RETURNING id, conversation_id, sender_id, content, created_at, type, media_url, media_metadata, idempotency_key, deleted_at, edited_at
 `
 
 type InsertMessageParams struct {
@@ -480,6 +1232,7 @@ type InsertMessageParams struct {
 	Type           string      `json:"type"`
 	MediaUrl       pgtype.Text `json:"media_url"`
 	MediaMetadata  []byte      `json:"media_metadata"`
+	IdempotencyKey pgtype.Text `json:"idempotency_key"`
 }
 
 func (q *Queries) InsertMessage(ctx context.Context, arg InsertMessageParams) (Message, error) {
@@ -490,6 +1243,7 @@ func (q *Queries) InsertMessage(ctx context.Context, arg InsertMessageParams) (M
 		arg.Type,
 		arg.MediaUrl,
 		arg.MediaMetadata,
+		arg.IdempotencyKey,
 	)
 	var i Message
 	err := row.Scan(
@@ -501,10 +1255,72 @@ func (q *Queries) InsertMessage(ctx context.Context, arg InsertMessageParams) (M
 		&i.Type,
 		&i.MediaUrl,
 		&i.MediaMetadata,
+		&i.IdempotencyKey,
+		&i.DeletedAt,
+		&i.EditedAt,
 	)
 	return i, err
 }
 
+const insertMessageAttachments = `-- name: InsertMessageAttachments :exec
+INSERT INTO message_attachments (message_id, url, mime_type, size_bytes, width, height, position)
+SELECT
+    $1::uuid,
+    unnest($2::text[]),
+    unnest($3::text[]),
+    unnest($4::bigint[]),
+    NULLIF(unnest($5::int[]), 0),
+    NULLIF(unnest($6::int[]), 0),
+    unnest($7::int[])
+`
+
+type InsertMessageAttachmentsParams struct {
+	MessageID pgtype.UUID `json:"message_id"`
+	Urls      []string    `json:"urls"`
+	MimeTypes []string    `json:"mime_types"`
+	Sizes     []int64     `json:"sizes"`
+	Widths    []int32     `json:"widths"`
+	Heights   []int32     `json:"heights"`
+	Positions []int32     `json:"positions"`
+}
+
+func (q *Queries) InsertMessageAttachments(ctx context.Context, arg InsertMessageAttachmentsParams) error {
+	_, err := q.db.Exec(ctx, insertMessageAttachments,
+		arg.MessageID,
+		arg.Urls,
+		arg.MimeTypes,
+		arg.Sizes,
+		arg.Widths,
+		arg.Heights,
+		arg.Positions,
+	)
+	return err
+}
+
+const insertMessageMentions = `-- name: InsertMessageMentions :exec
+INSERT INTO message_mentions (message_id, user_id, conversation_id, created_at)
+SELECT $1, unnest($2::uuid[]), $3, $4
+ON CONFLICT DO NOTHING
+`
+
+type InsertMessageMentionsParams struct {
+	MessageID      pgtype.UUID        `json:"message_id"`
+	UserIds        []pgtype.UUID      `json:"user_ids"`
+	ConversationID pgtype.UUID        `json:"conversation_id"`
+	CreatedAt      pgtype.Timestamptz `json:"created_at"`
+}
+
+// Participants mentioned by a message, inserted in the message's transaction
+func (q *Queries) InsertMessageMentions(ctx context.Context, arg InsertMessageMentionsParams) error {
+	_, err := q.db.Exec(ctx, insertMessageMentions,
+		arg.MessageID,
+		arg.UserIds,
+		arg.ConversationID,
+		arg.CreatedAt,
+	)
+	return err
+}
+
 const insertOutbox = `-- name: InsertOutbox :exec
 INSERT INTO outbox (aggregate_type, aggregate_id, payload)
 VALUES ($1, $2, $3)
@@ -521,10 +1337,34 @@ func (q *Queries) InsertOutbox(ctx context.Context, arg InsertOutboxParams) erro
 	return err
 }
 
+const insertOutboxBatch = `-- name: InsertOutboxBatch :execrows
+INSERT INTO outbox (aggregate_type, aggregate_id, payload)
+SELECT
+    unnest($1::text[]),
+    unnest($2::uuid[]),
+    unnest($3::jsonb[])
+`
+
+type InsertOutboxBatchParams struct {
+	AggregateTypes []string      `json:"aggregate_types"`
+	AggregateIds   []pgtype.UUID `json:"aggregate_ids"`
+	Payloads       [][]byte      `json:"payloads"`
+}
+
+// Inserts one event per array element (the arrays have the same length) in a single statement,
+// for transactions that emit several events; the notify trigger fires once for all of them
+func (q *Queries) InsertOutboxBatch(ctx context.Context, arg InsertOutboxBatchParams) (int64, error) {
+	result, err := q.db.Exec(ctx, insertOutboxBatch, arg.AggregateTypes, arg.AggregateIds, arg.Payloads)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected(), nil
+}
+
 const insertTextMessage = `-- name: InsertTextMessage :one
 INSERT INTO messages (conversation_id, sender_id, content, type)
 VALUES ($1, $2, $3, 'TEXT')
-RETURNING id, conversation_id, sender_id, content, created_at, type, media_url, media_metadata
+RETURNING id, conversation_id, sender_id, content, created_at, type, media_url, media_metadata, idempotency_key, deleted_at, edited_at
 `
 
 type InsertTextMessageParams struct {
@@ -545,27 +1385,187 @@ func (q *Queries) InsertTextMessage(ctx context.Context, arg InsertTextMessagePa
 		&i.Type,
 		&i.MediaUrl,
 		&i.MediaMetadata,
+		&i.IdempotencyKey,
+		&i.DeletedAt,
+		&i.EditedAt,
 	)
 	return i, err
 }
 
-const markAsRead = `-- name: MarkAsRead :exec
+const isParticipant = `-- name: IsParticipant :one
+SELECT EXISTS (
+    SELECT 1
+    FROM conversation_participants
+    WHERE conversation_id = $1
+      AND user_id = $2
+)
+`
+
+type IsParticipantParams struct {
+	ConversationID pgtype.UUID `json:"conversation_id"`
+	UserID         pgtype.UUID `json:"user_id"`
+}
+
+func (q *Queries) IsParticipant(ctx context.Context, arg IsParticipantParams) (bool, error) {
+	row := q.db.QueryRow(ctx, isParticipant, arg.ConversationID, arg.UserID)
+	var exists bool
+	err := row.Scan(&exists)
+	return exists, err
+}
+
+const listConversationIDsForUser = `-- name: ListConversationIDsForUser :many
+SELECT conversation_id
+FROM conversation_participants
+WHERE user_id = $1
+`
+
+func (q *Queries) ListConversationIDsForUser(ctx context.Context, userID pgtype.UUID) ([]pgtype.UUID, error) {
+	rows, err := q.db.Query(ctx, listConversationIDsForUser, userID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []pgtype.UUID
+	for rows.Next() {
+		var conversation_id pgtype.UUID
+		if err := rows.Scan(&conversation_id); err != nil {
+			return nil, err
+		}
+		items = append(items, conversation_id)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listConversationParticipants = `-- name: ListConversationParticipants :many
+SELECT user_id, joined_at
+FROM conversation_participants
+WHERE conversation_id = $1
+ORDER BY joined_at, user_id
+`
+
+type ListConversationParticipantsRow struct {
+	UserID   pgtype.UUID        `json:"user_id"`
+	JoinedAt pgtype.Timestamptz `json:"joined_at"`
+}
+
+func (q *Queries) ListConversationParticipants(ctx context.Context, conversationID pgtype.UUID) ([]ListConversationParticipantsRow, error) {
+	rows, err := q.db.Query(ctx, listConversationParticipants, conversationID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []ListConversationParticipantsRow
+	for rows.Next() {
+		var i ListConversationParticipantsRow
+		if err := rows.Scan(&i.UserID, &i.JoinedAt); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listConversationsWithRetention = `-- name: ListConversationsWithRetention :many
+SELECT id, message_ttl_seconds::int AS message_ttl_seconds
+FROM conversations
+WHERE message_ttl_seconds IS NOT NULL
+ORDER BY id
+`
+
+type ListConversationsWithRetentionRow struct {
+	ID                pgtype.UUID `json:"id"`
+	MessageTtlSeconds int32       `json:"message_ttl_seconds"`
+}
+
+func (q *Queries) ListConversationsWithRetention(ctx context.Context) ([]ListConversationsWithRetentionRow, error) {
+	rows, err := q.db.Query(ctx, listConversationsWithRetention)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []ListConversationsWithRetentionRow
+	for rows.Next() {
+		var i ListConversationsWithRetentionRow
+		if err := rows.Scan(&i.ID, &i.MessageTtlSeconds); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const lockMessageForReactions = `-- name: LockMessageForReactions :one
+SELECT id
+FROM messages
+WHERE id = $1
+FOR NO KEY UPDATE
+`
+
+// Serializes reaction changes on one message, so each one counts the changes committed before it
+func (q *Queries) LockMessageForReactions(ctx context.Context, id pgtype.UUID) (pgtype.UUID, error) {
+	row := q.db.QueryRow(ctx, lockMessageForReactions, id)
+	err := row.Scan(&id)
+	return id, err
+}
+
+const markAsDelivered = `-- name: MarkAsDelivered :exec
 UPDATE conversation_participants
-SET last_read_at = NOW()
+SET last_delivered_at = NOW()
 WHERE conversation_id = $1
   AND user_id = $2
 `
 
-type MarkAsReadParams struct {
+type MarkAsDeliveredParams struct {
 	ConversationID pgtype.UUID `json:"conversation_id"`
 	UserID         pgtype.UUID `json:"user_id"`
 }
 
-func (q *Queries) MarkAsRead(ctx context.Context, arg MarkAsReadParams) error {
-	_, err := q.db.Exec(ctx, markAsRead, arg.ConversationID, arg.UserID)
+func (q *Queries) MarkAsDelivered(ctx context.Context, arg MarkAsDeliveredParams) error {
+	_, err := q.db.Exec(ctx, markAsDelivered, arg.ConversationID, arg.UserID)
 	return err
 }
 
+const markAsRead = `-- name: MarkAsRead :one
+UPDATE conversation_participants cp
+SET last_read_at = COALESCE($1::timestamptz, NOW())
+WHERE cp.conversation_id = $2
+  AND cp.user_id = $3
+  AND cp.last_read_at < COALESCE($1::timestamptz, NOW())
+  AND EXISTS (
+      SELECT 1
+      FROM messages m
+      WHERE m.conversation_id = cp.conversation_id
+        AND m.created_at > cp.last_read_at
+        AND m.created_at <= COALESCE($1::timestamptz, NOW())
+  )
+RETURNING cp.last_read_at
+`
+
+type MarkAsReadParams struct {
+	ReadUpTo       pgtype.Timestamptz `json:"read_up_to"`
+	ConversationID pgtype.UUID        `json:"conversation_id"`
+	UserID         pgtype.UUID        `json:"user_id"`
+}
+
+// Moves the read pointer to read_up_to (a message's created_at), or to NOW() when NULL.
+// It only moves forward, and only past at least one message: a repeated request while scrolling,
+// or a stale one from another device, matches no row and writes nothing.
+func (q *Queries) MarkAsRead(ctx context.Context, arg MarkAsReadParams) (pgtype.Timestamptz, error) {
+	row := q.db.QueryRow(ctx, markAsRead, arg.ReadUpTo, arg.ConversationID, arg.UserID)
+	var last_read_at pgtype.Timestamptz
+	err := row.Scan(&last_read_at)
+	return last_read_at, err
+}
+
 const markOutboxFailed = `-- name: MarkOutboxFailed :exec
 UPDATE outbox
 SET retry_count = retry_count + 1,
@@ -608,6 +1608,48 @@ func (q *Queries) MoveOutboxToDLQ(ctx context.Context, arg MoveOutboxToDLQParams
 	return err
 }
 
+const pinConversation = `-- name: PinConversation :one
+UPDATE conversation_participants
+SET pinned_at = COALESCE(pinned_at, NOW())
+WHERE conversation_id = $1
+  AND user_id = $2
+RETURNING pinned_at
+`
+
+type PinConversationParams struct {
+	ConversationID pgtype.UUID `json:"conversation_id"`
+	UserID         pgtype.UUID `json:"user_id"`
+}
+
+// Pinning keeps the original pinned_at when the conversation is already pinned
+func (q *Queries) PinConversation(ctx context.Context, arg PinConversationParams) (pgtype.Timestamptz, error) {
+	row := q.db.QueryRow(ctx, pinConversation, arg.ConversationID, arg.UserID)
+	var pinned_at pgtype.Timestamptz
+	err := row.Scan(&pinned_at)
+	return pinned_at, err
+}
+
+const removeReaction = `-- name: RemoveReaction :execrows
+DELETE FROM message_reactions
+WHERE message_id = $1
+  AND user_id = $2
+  AND emoji = $3
+`
+
+type RemoveReactionParams struct {
+	MessageID pgtype.UUID `json:"message_id"`
+	UserID    pgtype.UUID `json:"user_id"`
+	Emoji     string      `json:"emoji"`
+}
+
+func (q *Queries) RemoveReaction(ctx context.Context, arg RemoveReactionParams) (int64, error) {
+	result, err := q.db.Exec(ctx, removeReaction, arg.MessageID, arg.UserID, arg.Emoji)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected(), nil
+}
+
 const replayDLQEvent = `-- name: ReplayDLQEvent :exec
 INSERT INTO outbox (aggregate_type, aggregate_id, payload)
 SELECT d.aggregate_type, d.aggregate_id, d.payload
@@ -620,21 +1662,119 @@ func (q *Queries) ReplayDLQEvent(ctx context.Context, id pgtype.UUID) error {
 	return err
 }
 
+const setConversationArchived = `-- name: SetConversationArchived :execrows
+UPDATE conversation_participants
+SET archived_at = CASE WHEN $1::boolean THEN COALESCE(archived_at, NOW()) ELSE NULL END
+WHERE conversation_id = $2
+  AND user_id = $3
+`
+
+type SetConversationArchivedParams struct {
+	Archived       bool        `json:"archived"`
+	ConversationID pgtype.UUID `json:"conversation_id"`
+	UserID         pgtype.UUID `json:"user_id"`
+}
+
+// Archiving keeps the original archived_at when the conversation is already archived
+func (q *Queries) SetConversationArchived(ctx context.Context, arg SetConversationArchivedParams) (int64, error) {
+	result, err := q.db.Exec(ctx, setConversationArchived, arg.Archived, arg.ConversationID, arg.UserID)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected(), nil
+}
+
+const setConversationRetention = `-- name: SetConversationRetention :execrows
+UPDATE conversations
+SET message_ttl_seconds = $1
+WHERE id = $2
+`
+
+type SetConversationRetentionParams struct {
+	MessageTtlSeconds pgtype.Int4 `json:"message_ttl_seconds"`
+	ID                pgtype.UUID `json:"id"`
+}
+
+// A NULL message_ttl_seconds keeps messages forever
+func (q *Queries) SetConversationRetention(ctx context.Context, arg SetConversationRetentionParams) (int64, error) {
+	result, err := q.db.Exec(ctx, setConversationRetention, arg.MessageTtlSeconds, arg.ID)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected(), nil
+}
+
+const setNotificationLevel = `-- name: SetNotificationLevel :execrows
+UPDATE conversation_participants
+SET notification_level = $1
+WHERE conversation_id = $2
+  AND user_id = $3
+`
+
+type SetNotificationLevelParams struct {
+	NotificationLevel string      `json:"notification_level"`
+	ConversationID    pgtype.UUID `json:"conversation_id"`
+	UserID            pgtype.UUID `json:"user_id"`
+}
+
+func (q *Queries) SetNotificationLevel(ctx context.Context, arg SetNotificationLevelParams) (int64, error) {
+	result, err := q.db.Exec(ctx, setNotificationLevel, arg.NotificationLevel, arg.ConversationID, arg.UserID)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected(), nil
+}
+
+const unpinConversation = `-- name: UnpinConversation :execrows
+UPDATE conversation_participants
+SET pinned_at = NULL
+WHERE conversation_id = $1
+  AND user_id = $2
+`
+
+type UnpinConversationParams struct {
+	ConversationID pgtype.UUID `json:"conversation_id"`
+	UserID         pgtype.UUID `json:"user_id"`
+}
+
+func (q *Queries) UnpinConversation(ctx context.Context, arg UnpinConversationParams) (int64, error) {
+	result, err := q.db.Exec(ctx, unpinConversation, arg.ConversationID, arg.UserID)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected(), nil
+}
+
 const updateConversationLastMessage = `-- name: UpdateConversationLastMessage :exec
+WITH resurfaced AS (
+    UPDATE conversation_participants
+    SET hidden_at = NULL,
+        archived_at = CASE WHEN $4::boolean THEN NULL ELSE archived_at END
+    WHERE conversation_id = $3
+      AND (hidden_at IS NOT NULL OR ($4::boolean AND archived_at IS NOT NULL))
+)
 UPDATE conversations
-SET last_message_content = $2,
-    last_message_at = $3
-WHERE id = $1
+SET last_message_content = $1,
+    last_message_at = $2
+WHERE id = $3
 `
 
 type UpdateConversationLastMessageParams struct {
-	ID                 pgtype.UUID        `json:"id"`
 	LastMessageContent pgtype.Text        `json:"last_message_content"`
 	LastMessageAt      pgtype.Timestamptz `json:"last_message_at"`
+	ID                 pgtype.UUID        `json:"id"`
+	Unarchive          bool               `json:"unarchive"`
 }
 
+// A new message re-surfaces the conversation for participants who hid it,
+// and for those who archived it when unarchive is set.
 func (q *Queries) UpdateConversationLastMessage(ctx context.Context, arg UpdateConversationLastMessageParams) error {
-	_, err := q.db.Exec(ctx, updateConversationLastMessage, arg.ID, arg.LastMessageContent, arg.LastMessageAt)
+	_, err := q.db.Exec(ctx, updateConversationLastMessage,
+		arg.LastMessageContent,
+		arg.LastMessageAt,
+		arg.ID,
+		arg.Unarchive,
+	)
 	return err
 }
 
@@ -642,17 +1782,34 @@ const upsertConversation = `-- name: UpsertConversation :one
 INSERT INTO conversations (id)
 VALUES ($1)
 ON CONFLICT (id) DO UPDATE SET created_at = conversations.created_at
-RETURNING id, created_at, last_message_content, last_message_at
+RETURNING id, created_at, last_message_content, last_message_at, message_ttl_seconds, type, name, (xmax = 0)::boolean AS inserted
 `
 
-func (q *Queries) UpsertConversation(ctx context.Context, id pgtype.UUID) (Conversation, error) {
+type UpsertConversationRow struct {
+	ID                 pgtype.UUID        `json:"id"`
+	CreatedAt          pgtype.Timestamptz `json:"created_at"`
+	LastMessageContent pgtype.Text        `json:"last_message_content"`
+	LastMessageAt      pgtype.Timestamptz `json:"last_message_at"`
+	MessageTtlSeconds  pgtype.Int4        `json:"message_ttl_seconds"`
+	Type               pgtype.Text        `json:"type"`
+	Name               pgtype.Text        `json:"name"`
+	Inserted           bool               `json:"inserted"`
+}
+
+// inserted is true when this statement created the conversation: only a freshly
+// inserted row version has no xmax, the no-op update of an existing row sets it
+func (q *Queries) UpsertConversation(ctx context.Context, id pgtype.UUID) (UpsertConversationRow, error) {
 	row := q.db.QueryRow(ctx, upsertConversation, id)
-	var i Conversation
+	var i UpsertConversationRow
 	err := row.Scan(
 		&i.ID,
 		&i.CreatedAt,
 		&i.LastMessageContent,
 		&i.LastMessageAt,
+		&i.MessageTtlSeconds,
+		&i.Type,
+		&i.Name,
+		&i.Inserted,
 	)
 	return i, err
 }
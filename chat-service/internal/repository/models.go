@@ -13,13 +13,21 @@ type Conversation struct {
 	CreatedAt          pgtype.Timestamptz `json:"created_at"`
 	LastMessageContent pgtype.Text        `json:"last_message_content"`
 	LastMessageAt      pgtype.Timestamptz `json:"last_message_at"`
+	MessageTtlSeconds  pgtype.Int4        `json:"message_ttl_seconds"`
+	Type               pgtype.Text        `json:"type"`
+	Name               pgtype.Text        `json:"name"`
 }
 
 type ConversationParticipant struct {
-	ConversationID pgtype.UUID        `json:"conversation_id"`
-	UserID         pgtype.UUID        `json:"user_id"`
-	LastReadAt     pgtype.Timestamptz `json:"last_read_at"`
-	JoinedAt       pgtype.Timestamptz `json:"joined_at"`
+	ConversationID    pgtype.UUID        `json:"conversation_id"`
+	UserID            pgtype.UUID        `json:"user_id"`
+	LastReadAt        pgtype.Timestamptz `json:"last_read_at"`
+	JoinedAt          pgtype.Timestamptz `json:"joined_at"`
+	HiddenAt          pgtype.Timestamptz `json:"hidden_at"`
+	LastDeliveredAt   pgtype.Timestamptz `json:"last_delivered_at"`
+	ArchivedAt        pgtype.Timestamptz `json:"archived_at"`
+	PinnedAt          pgtype.Timestamptz `json:"pinned_at"`
+	NotificationLevel string             `json:"notification_level"`
 }
 
 type Message struct {
@@ -31,6 +39,35 @@ type Message struct {
 	Type           string             `json:"type"`
 	MediaUrl       pgtype.Text        `json:"media_url"`
 	MediaMetadata  []byte             `json:"media_metadata"`
+	IdempotencyKey pgtype.Text        `json:"idempotency_key"`
+	DeletedAt      pgtype.Timestamptz `json:"deleted_at"`
+	EditedAt       pgtype.Timestamptz `json:"edited_at"`
+}
+
+type MessageAttachment struct {
+	ID        pgtype.UUID        `json:"id"`
+	MessageID pgtype.UUID        `json:"message_id"`
+	Url       string             `json:"url"`
+	MimeType  string             `json:"mime_type"`
+	SizeBytes int64              `json:"size_bytes"`
+	Width     pgtype.Int4        `json:"width"`
+	Height    pgtype.Int4        `json:"height"`
+	Position  int32              `json:"position"`
+	CreatedAt pgtype.Timestamptz `json:"created_at"`
+}
+
+type MessageMention struct {
+	MessageID      pgtype.UUID        `json:"message_id"`
+	UserID         pgtype.UUID        `json:"user_id"`
+	ConversationID pgtype.UUID        `json:"conversation_id"`
+	CreatedAt      pgtype.Timestamptz `json:"created_at"`
+}
+
+type MessageReaction struct {
+	MessageID pgtype.UUID        `json:"message_id"`
+	UserID    pgtype.UUID        `json:"user_id"`
+	Emoji     string             `json:"emoji"`
+	CreatedAt pgtype.Timestamptz `json:"created_at"`
 }
 
 type Outbox struct {
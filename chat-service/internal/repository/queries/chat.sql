@@ -1,8 +1,14 @@
 -- name: InsertMessage :one
-INSERT INTO messages (conversation_id, sender_id, content, type, media_url, media_metadata)
-VALUES ($1, $2, $3, $4, $5, $6)
+INSERT INTO messages (conversation_id, sender_id, content, type, media_url, media_metadata, idempotency_key)
+VALUES ($1, $2, $3, $4, $5, $6, $7)
 RETURNING *;
 
+-- name: GetMessageIDByIdempotencyKey :one
+SELECT id
+FROM messages
+WHERE sender_id = $1
+  AND idempotency_key = $2;
+
 -- name: InsertTextMessage :one
 INSERT INTO messages (conversation_id, sender_id, content, type)
 VALUES ($1, $2, $3, 'TEXT')
@@ -14,26 +20,140 @@ VALUES ($1, $2, $3, $4, $5, $6)
 RETURNING *;
 
 -- name: GetMessages :many
-SELECT id, conversation_id, sender_id, content, created_at, type, media_url, media_metadata
+-- Deleted messages are only returned (as tombstones) when include_deleted is set
+SELECT id, conversation_id, sender_id, content, created_at, type, media_url, media_metadata, idempotency_key, deleted_at, edited_at
+FROM messages
+WHERE conversation_id = sqlc.arg('conversation_id')
+	AND (sqlc.arg('include_deleted')::boolean OR deleted_at IS NULL)
+	AND (
+		sqlc.narg('before_created_at')::timestamptz IS NULL
+		OR (created_at, id) < (sqlc.narg('before_created_at')::timestamptz, sqlc.arg('before_id')::uuid)
+	)
+ORDER BY created_at DESC, id DESC
+LIMIT sqlc.arg('limit');
+
+-- name: ExportMessages :many
+-- Oldest-first keyset page for exports: messages after (after_created_at, after_id); deleted ones are left out
+SELECT id, conversation_id, sender_id, content, created_at, type, media_url, media_metadata, idempotency_key, deleted_at, edited_at
 FROM messages
 WHERE conversation_id = sqlc.arg('conversation_id')
+	AND deleted_at IS NULL
 	AND (
-		sqlc.narg('before')::timestamptz IS NULL
-		OR created_at < sqlc.narg('before')::timestamptz
+		sqlc.narg('after_created_at')::timestamptz IS NULL
+		OR (created_at, id) > (sqlc.narg('after_created_at')::timestamptz, sqlc.arg('after_id')::uuid)
 	)
-ORDER BY created_at DESC
+ORDER BY created_at, id
 LIMIT sqlc.arg('limit');
 
+-- name: InsertMessageMentions :exec
+-- Participants mentioned by a message, inserted in the message's transaction
+INSERT INTO message_mentions (message_id, user_id, conversation_id, created_at)
+SELECT sqlc.arg('message_id'), unnest(sqlc.arg('user_ids')::uuid[]), sqlc.arg('conversation_id'), sqlc.arg('created_at')
+ON CONFLICT DO NOTHING;
+
+-- name: GetMentions :many
+-- Newest-first keyset page of the messages that mention the user, in conversations the user still belongs to;
+-- deleted messages are left out
+SELECT m.id, m.conversation_id, m.sender_id, m.content, m.created_at, m.type, m.media_url, m.media_metadata, m.idempotency_key, m.deleted_at, m.edited_at
+FROM message_mentions mm
+JOIN messages m ON m.id = mm.message_id
+JOIN conversation_participants cp ON cp.conversation_id = mm.conversation_id AND cp.user_id = mm.user_id
+WHERE mm.user_id = sqlc.arg('user_id')
+	AND m.deleted_at IS NULL
+	AND (
+		sqlc.narg('before_created_at')::timestamptz IS NULL
+		OR (mm.created_at, mm.message_id) < (sqlc.narg('before_created_at')::timestamptz, sqlc.arg('before_id')::uuid)
+	)
+ORDER BY mm.created_at DESC, mm.message_id DESC
+LIMIT sqlc.arg('limit');
+
+-- name: GetMessageByID :one
+SELECT id, conversation_id, sender_id, content, created_at, type, media_url, media_metadata, idempotency_key, deleted_at, edited_at
+FROM messages
+WHERE id = $1;
+
+-- name: InsertMessageAttachments :exec
+INSERT INTO message_attachments (message_id, url, mime_type, size_bytes, width, height, position)
+SELECT
+    sqlc.arg('message_id')::uuid,
+    unnest(sqlc.arg('urls')::text[]),
+    unnest(sqlc.arg('mime_types')::text[]),
+    unnest(sqlc.arg('sizes')::bigint[]),
+    NULLIF(unnest(sqlc.arg('widths')::int[]), 0),
+    NULLIF(unnest(sqlc.arg('heights')::int[]), 0),
+    unnest(sqlc.arg('positions')::int[]);
+
+-- name: GetAttachmentsForMessages :many
+SELECT message_id, url, mime_type, size_bytes, width, height
+FROM message_attachments
+WHERE message_id = ANY(sqlc.arg('message_ids')::uuid[])
+ORDER BY message_id, position;
+
+-- name: LockMessageForReactions :one
+-- Serializes reaction changes on one message, so each one counts the changes committed before it
+SELECT id
+FROM messages
+WHERE id = $1
+FOR NO KEY UPDATE;
+
+-- name: AddReaction :execrows
+-- Returns 0 when the user already reacted with this emoji
+INSERT INTO message_reactions (message_id, user_id, emoji)
+VALUES ($1, $2, $3)
+ON CONFLICT DO NOTHING;
+
+-- name: RemoveReaction :execrows
+DELETE FROM message_reactions
+WHERE message_id = $1
+  AND user_id = $2
+  AND emoji = $3;
+
+-- name: CountReactions :one
+SELECT COUNT(*)::int
+FROM message_reactions
+WHERE message_id = $1
+  AND emoji = $2;
+
+-- name: GetReactionsForMessages :many
+-- Aggregate snapshot per (message, emoji), emojis in the order they were first used
+SELECT
+    message_id,
+    emoji,
+    COUNT(*)::int AS count,
+    BOOL_OR(user_id = sqlc.arg('user_id')::uuid) AS reacted_by_me
+FROM message_reactions
+WHERE message_id = ANY(sqlc.arg('message_ids')::uuid[])
+GROUP BY message_id, emoji
+ORDER BY message_id, MIN(created_at), emoji;
+
 -- name: UpsertConversation :one
+-- inserted is true when this statement created the conversation: only a freshly
+-- inserted row version has no xmax, the no-op update of an existing row sets it
 INSERT INTO conversations (id)
 VALUES ($1)
 ON CONFLICT (id) DO UPDATE SET created_at = conversations.created_at
+RETURNING *, (xmax = 0)::boolean AS inserted;
+
+-- name: CreateConversation :one
+-- last_message_at starts at creation so an empty conversation sorts and paginates
+-- like the others in conversation lists (by last activity) until its first message.
+INSERT INTO conversations (type, name, last_message_at)
+VALUES (sqlc.arg('type'), sqlc.narg('name'), NOW())
 RETURNING *;
 
 -- name: InsertOutbox :exec
 INSERT INTO outbox (aggregate_type, aggregate_id, payload)
 VALUES ($1, $2, $3);
 
+-- name: InsertOutboxBatch :execrows
+-- Inserts one event per array element (the arrays have the same length) in a single statement,
+-- for transactions that emit several events; the notify trigger fires once for all of them
+INSERT INTO outbox (aggregate_type, aggregate_id, payload)
+SELECT
+    unnest(sqlc.arg('aggregate_types')::text[]),
+    unnest(sqlc.arg('aggregate_ids')::uuid[]),
+    unnest(sqlc.arg('payloads')::jsonb[]);
+
 -- name: GetUnprocessedOutbox :many
 SELECT *
 FROM outbox
@@ -50,50 +170,312 @@ LIMIT $1
 FOR UPDATE SKIP LOCKED;
 
 -- name: GetConversationsForUser :many
-SELECT 
-    c.id,
-    c.last_message_content,
-    c.last_message_at,
-    (
-        SELECT COUNT(*) 
-        FROM messages m 
-        WHERE m.conversation_id = c.id 
-          AND m.created_at > cp.last_read_at
-    ) AS unread_count
-FROM conversations c
-JOIN conversation_participants cp ON c.id = cp.conversation_id
-WHERE cp.user_id = $1
-  AND ($2::timestamptz IS NULL OR c.last_message_at < $2::timestamptz)
-ORDER BY c.last_message_at DESC
-LIMIT $3;
+-- Unread counts are computed in one grouped pass over the selected page
+-- instead of a correlated subquery per conversation. System messages are never unread.
+-- Archived conversations are left out unless include_archived; only_archived lists just those.
+-- Pinned conversations come first, most recently pinned first, then the rest by last_message_at.
+-- The keyset is (pinned, sort_at, id) with sort_at the pin time or the last message time:
+-- a cursor in the pinned section continues with older pins and then every unpinned conversation,
+-- a cursor in the unpinned section never returns to the pins.
+WITH page AS (
+    SELECT
+        c.id,
+        c.last_message_content,
+        c.last_message_at,
+        c.message_ttl_seconds,
+        c.type,
+        c.name,
+        cp.last_read_at,
+        cp.archived_at,
+        cp.pinned_at,
+        cp.notification_level
+    FROM conversations c
+    JOIN conversation_participants cp ON c.id = cp.conversation_id
+    WHERE cp.user_id = sqlc.arg('user_id')
+      AND cp.hidden_at IS NULL
+      AND (cp.archived_at IS NULL OR sqlc.arg('include_archived')::boolean OR sqlc.arg('only_archived')::boolean)
+      AND (cp.archived_at IS NOT NULL OR NOT sqlc.arg('only_archived')::boolean)
+      AND (
+        sqlc.narg('before_sort_at')::timestamptz IS NULL
+        OR (
+          sqlc.arg('before_pinned')::boolean
+          AND (cp.pinned_at IS NULL OR (cp.pinned_at, c.id) < (sqlc.narg('before_sort_at')::timestamptz, sqlc.arg('before_id')::uuid))
+        )
+        OR (
+          NOT sqlc.arg('before_pinned')::boolean
+          AND cp.pinned_at IS NULL
+          AND (c.last_message_at, c.id) < (sqlc.narg('before_sort_at')::timestamptz, sqlc.arg('before_id')::uuid)
+        )
+      )
+    ORDER BY (cp.pinned_at IS NOT NULL) DESC, COALESCE(cp.pinned_at, c.last_message_at) DESC, c.id DESC
+    LIMIT sqlc.arg('limit')
+)
+SELECT
+    p.id,
+    p.last_message_content,
+    p.last_message_at,
+    p.archived_at,
+    p.pinned_at,
+    p.notification_level,
+    p.message_ttl_seconds,
+    p.type,
+    p.name,
+    COUNT(m.id) AS unread_count
+FROM page p
+LEFT JOIN messages m
+    ON m.conversation_id = p.id
+   AND m.created_at > p.last_read_at
+   AND m.type <> 'SYSTEM'
+GROUP BY p.id, p.last_message_content, p.last_message_at, p.archived_at, p.pinned_at, p.notification_level, p.message_ttl_seconds, p.type, p.name
+ORDER BY (p.pinned_at IS NOT NULL) DESC, COALESCE(p.pinned_at, p.last_message_at) DESC, p.id DESC;
+
+-- name: GetConversationsByIDs :many
+-- Same rows as GetConversationsForUser for an explicit set of ids, archived or not;
+-- ids the user does not participate in (or has hidden) are left out.
+WITH selected AS (
+    SELECT
+        c.id,
+        c.last_message_content,
+        c.last_message_at,
+        c.message_ttl_seconds,
+        c.type,
+        c.name,
+        cp.last_read_at,
+        cp.archived_at,
+        cp.pinned_at,
+        cp.notification_level
+    FROM conversations c
+    JOIN conversation_participants cp ON c.id = cp.conversation_id
+    WHERE cp.user_id = sqlc.arg('user_id')
+      AND cp.hidden_at IS NULL
+      AND c.id = ANY(sqlc.arg('ids')::uuid[])
+)
+SELECT
+    s.id,
+    s.last_message_content,
+    s.last_message_at,
+    s.archived_at,
+    s.pinned_at,
+    s.notification_level,
+    s.message_ttl_seconds,
+    s.type,
+    s.name,
+    COUNT(m.id) AS unread_count
+FROM selected s
+LEFT JOIN messages m
+    ON m.conversation_id = s.id
+   AND m.created_at > s.last_read_at
+   AND m.type <> 'SYSTEM'
+GROUP BY s.id, s.last_message_content, s.last_message_at, s.archived_at, s.pinned_at, s.notification_level, s.message_ttl_seconds, s.type, s.name
+ORDER BY s.last_message_at DESC, s.id DESC;
 
 -- name: UpdateConversationLastMessage :exec
+-- A new message re-surfaces the conversation for participants who hid it,
+-- and for those who archived it when unarchive is set.
+WITH resurfaced AS (
+    UPDATE conversation_participants
+    SET hidden_at = NULL,
+        archived_at = CASE WHEN sqlc.arg('unarchive')::boolean THEN NULL ELSE archived_at END
+    WHERE conversation_id = sqlc.arg('id')
+      AND (hidden_at IS NOT NULL OR (sqlc.arg('unarchive')::boolean AND archived_at IS NOT NULL))
+)
 UPDATE conversations
-SET last_message_content = $2,
-    last_message_at = $3
-WHERE id = $1;
+SET last_message_content = sqlc.arg('last_message_content'),
+    last_message_at = sqlc.arg('last_message_at')
+WHERE id = sqlc.arg('id');
+
+-- name: HideConversation :execrows
+UPDATE conversation_participants
+SET hidden_at = NOW()
+WHERE conversation_id = $1
+  AND user_id = $2;
+
+-- name: SetConversationArchived :execrows
+-- Archiving keeps the original archived_at when the conversation is already archived
+UPDATE conversation_participants
+SET archived_at = CASE WHEN sqlc.arg('archived')::boolean THEN COALESCE(archived_at, NOW()) ELSE NULL END
+WHERE conversation_id = sqlc.arg('conversation_id')
+  AND user_id = sqlc.arg('user_id');
+
+-- name: PinConversation :one
+-- Pinning keeps the original pinned_at when the conversation is already pinned
+UPDATE conversation_participants
+SET pinned_at = COALESCE(pinned_at, NOW())
+WHERE conversation_id = $1
+  AND user_id = $2
+RETURNING pinned_at;
+
+-- name: UnpinConversation :execrows
+UPDATE conversation_participants
+SET pinned_at = NULL
+WHERE conversation_id = $1
+  AND user_id = $2;
+
+-- name: SetNotificationLevel :execrows
+UPDATE conversation_participants
+SET notification_level = sqlc.arg('notification_level')
+WHERE conversation_id = sqlc.arg('conversation_id')
+  AND user_id = sqlc.arg('user_id');
+
+-- name: GetConversationNotificationLevels :many
+-- Only participants that changed the default: everyone else is notified of every message
+SELECT user_id, notification_level
+FROM conversation_participants
+WHERE conversation_id = $1
+  AND notification_level <> 'ALL';
+
+-- name: SetConversationRetention :execrows
+-- A NULL message_ttl_seconds keeps messages forever
+UPDATE conversations
+SET message_ttl_seconds = sqlc.narg('message_ttl_seconds')
+WHERE id = sqlc.arg('id');
+
+-- name: ListConversationsWithRetention :many
+SELECT id, message_ttl_seconds::int AS message_ttl_seconds
+FROM conversations
+WHERE message_ttl_seconds IS NOT NULL
+ORDER BY id;
+
+-- name: DeleteExpiredMessages :execrows
+-- Deletes at most batch_size messages older than the cutoff (attachments cascade).
+-- SKIP LOCKED lets purge jobs on several replicas split the work instead of queueing.
+DELETE FROM messages
+WHERE id IN (
+    SELECT m.id
+    FROM messages m
+    WHERE m.conversation_id = sqlc.arg('conversation_id')
+      AND m.created_at < sqlc.arg('cutoff')
+    ORDER BY m.created_at
+    LIMIT sqlc.arg('batch_size')
+    FOR UPDATE SKIP LOCKED
+);
+
+-- name: ClearExpiredLastMessage :exec
+-- The conversation preview goes once its message is purged; last_message_at keeps the list order
+UPDATE conversations
+SET last_message_content = NULL
+WHERE id = sqlc.arg('id')
+  AND last_message_at < sqlc.arg('cutoff');
 
 -- name: AddParticipant :exec
 INSERT INTO conversation_participants (conversation_id, user_id, joined_at)
 VALUES ($1, $2, NOW())
 ON CONFLICT DO NOTHING;
 
--- name: AddConversationParticipants :exec
+-- name: AddConversationParticipants :execrows
+-- Returns how many users joined (0 when everyone was already a participant)
 INSERT INTO conversation_participants (conversation_id, user_id, joined_at)
 SELECT $1, unnest($2::uuid[]), NOW()
 ON CONFLICT DO NOTHING;
 
--- name: MarkAsRead :exec
+-- name: HasUnreadMessages :one
+-- read_up_to (NULL = no bound) limits the check to messages a MarkAsRead up to it would read
+-- System messages don't count: reading only those sends no read receipt
+SELECT EXISTS (
+    SELECT 1
+    FROM conversation_participants cp
+    JOIN messages m ON m.conversation_id = cp.conversation_id
+    WHERE cp.conversation_id = sqlc.arg('conversation_id')
+      AND cp.user_id = sqlc.arg('user_id')
+      AND m.sender_id <> cp.user_id
+      AND m.created_at > cp.last_read_at
+      AND m.type <> 'SYSTEM'
+      AND (sqlc.narg('read_up_to')::timestamptz IS NULL OR m.created_at <= sqlc.narg('read_up_to')::timestamptz)
+);
+
+-- name: MarkAsRead :one
+-- Moves the read pointer to read_up_to (a message's created_at), or to NOW() when NULL.
+-- It only moves forward, and only past at least one message: a repeated request while scrolling,
+-- or a stale one from another device, matches no row and writes nothing.
+UPDATE conversation_participants cp
+SET last_read_at = COALESCE(sqlc.narg('read_up_to')::timestamptz, NOW())
+WHERE cp.conversation_id = sqlc.arg('conversation_id')
+  AND cp.user_id = sqlc.arg('user_id')
+  AND cp.last_read_at < COALESCE(sqlc.narg('read_up_to')::timestamptz, NOW())
+  AND EXISTS (
+      SELECT 1
+      FROM messages m
+      WHERE m.conversation_id = cp.conversation_id
+        AND m.created_at > cp.last_read_at
+        AND m.created_at <= COALESCE(sqlc.narg('read_up_to')::timestamptz, NOW())
+  )
+RETURNING cp.last_read_at;
+
+-- name: CountUnreadMessages :one
+-- Counted like unread_count in GetConversationsForUser, so a badge set from it matches the next list refresh
+SELECT COUNT(m.id)::int AS unread_count
+FROM conversation_participants cp
+JOIN messages m
+    ON m.conversation_id = cp.conversation_id
+   AND m.created_at > cp.last_read_at
+   AND m.type <> 'SYSTEM'
+WHERE cp.conversation_id = $1
+  AND cp.user_id = $2;
+
+-- name: GetUnreadCountsForUser :many
+-- Unread counts for the first conversations of the user's list (pinned first, then by last message,
+-- archived and hidden ones left out), counted like unread_count in GetConversationsForUser.
+WITH recent AS (
+    SELECT
+        c.id,
+        c.last_message_at,
+        cp.last_read_at,
+        cp.pinned_at
+    FROM conversations c
+    JOIN conversation_participants cp ON c.id = cp.conversation_id
+    WHERE cp.user_id = sqlc.arg('user_id')
+      AND cp.hidden_at IS NULL
+      AND cp.archived_at IS NULL
+    ORDER BY (cp.pinned_at IS NOT NULL) DESC, COALESCE(cp.pinned_at, c.last_message_at) DESC, c.id DESC
+    LIMIT sqlc.arg('limit')
+)
+SELECT
+    r.id,
+    r.last_message_at,
+    COUNT(m.id)::int AS unread_count
+FROM recent r
+LEFT JOIN messages m
+    ON m.conversation_id = r.id
+   AND m.created_at > r.last_read_at
+   AND m.type <> 'SYSTEM'
+GROUP BY r.id, r.last_message_at, r.pinned_at
+ORDER BY (r.pinned_at IS NOT NULL) DESC, COALESCE(r.pinned_at, r.last_message_at) DESC, r.id DESC;
+
+-- name: MarkAsDelivered :exec
 UPDATE conversation_participants
-SET last_read_at = NOW()
+SET last_delivered_at = NOW()
 WHERE conversation_id = $1
   AND user_id = $2;
 
+-- name: GetParticipantReceipts :many
+SELECT user_id, joined_at, last_read_at, last_delivered_at
+FROM conversation_participants
+WHERE conversation_id = $1;
+
+-- name: ListConversationIDsForUser :many
+SELECT conversation_id
+FROM conversation_participants
+WHERE user_id = $1;
+
+-- name: IsParticipant :one
+SELECT EXISTS (
+    SELECT 1
+    FROM conversation_participants
+    WHERE conversation_id = $1
+      AND user_id = $2
+);
+
 -- name: GetConversationParticipants :many
 SELECT user_id
 FROM conversation_participants
 WHERE conversation_id = $1;
 
+-- name: ListConversationParticipants :many
+SELECT user_id, joined_at
+FROM conversation_participants
+WHERE conversation_id = $1
+ORDER BY joined_at, user_id;
+
 -- name: MarkOutboxProcessed :exec
 UPDATE outbox
 SET processed_at = NOW()
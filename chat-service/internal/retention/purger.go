@@ -0,0 +1,142 @@
+// Package retention deletes messages of conversations that opted into a message TTL.
+package retention
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"chat-service/internal/repository"
+
+	"github.com/jackc/pgx/v5/pgtype"
+	"go.uber.org/zap"
+)
+
+const (
+	// DefaultInterval is how often the purger sweeps conversations with a message TTL
+	DefaultInterval = 5 * time.Minute
+
+	// DefaultBatchSize bounds the messages deleted by one statement, keeping row locks
+	// and WAL bursts small next to regular traffic
+	DefaultBatchSize = 500
+)
+
+// Store is the subset of repository.Queries the purger uses.
+type Store interface {
+	ListConversationsWithRetention(ctx context.Context) ([]repository.ListConversationsWithRetentionRow, error)
+	DeleteExpiredMessages(ctx context.Context, arg repository.DeleteExpiredMessagesParams) (int64, error)
+	ClearExpiredLastMessage(ctx context.Context, arg repository.ClearExpiredLastMessageParams) error
+}
+
+// Purger periodically hard-deletes messages older than their conversation's message_ttl_seconds.
+// Deleting old messages only ever lowers unread counts, which are computed from the messages
+// newer than a participant's last_read_at. When the newest message expires, the conversation
+// preview is cleared but last_message_at stays, so the conversation keeps its place in lists.
+type Purger struct {
+	store     Store
+	logger    *zap.Logger
+	interval  time.Duration
+	batchSize int32
+	now       func() time.Time
+}
+
+// NewPurger creates a purger. Non-positive values use the defaults.
+func NewPurger(store Store, logger *zap.Logger, interval time.Duration, batchSize int) *Purger {
+	if interval <= 0 {
+		interval = DefaultInterval
+	}
+	if batchSize <= 0 {
+		batchSize = DefaultBatchSize
+	}
+	return &Purger{
+		store:     store,
+		logger:    logger,
+		interval:  interval,
+		batchSize: int32(batchSize),
+		now:       time.Now,
+	}
+}
+
+// Run sweeps every interval until ctx is cancelled.
+func (p *Purger) Run(ctx context.Context) {
+	ticker := time.NewTicker(p.interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			purged, err := p.PurgeOnce(ctx)
+			if err != nil {
+				p.logger.Error("message retention sweep failed", zap.Error(err), zap.Int64("purged", purged))
+				continue
+			}
+			if purged > 0 {
+				p.logger.Info("expired messages purged", zap.Int64("purged", purged))
+			}
+		}
+	}
+}
+
+// PurgeOnce deletes the expired messages of every conversation with a TTL and returns how many
+// it deleted. A failing conversation does not stop the sweep; the first error is returned.
+func (p *Purger) PurgeOnce(ctx context.Context) (int64, error) {
+	conversations, err := p.store.ListConversationsWithRetention(ctx)
+	if err != nil {
+		return 0, fmt.Errorf("failed to list conversations with retention: %w", err)
+	}
+
+	var total int64
+	var firstErr error
+	for _, conv := range conversations {
+		if ctx.Err() != nil {
+			return total, ctx.Err()
+		}
+		cutoff := p.now().Add(-time.Duration(conv.MessageTtlSeconds) * time.Second)
+		purged, err := p.purgeConversation(ctx, conv.ID, cutoff)
+		total += purged
+		if err != nil {
+			p.logger.Warn("failed to purge expired messages",
+				zap.String("conversation_id", conv.ID.String()),
+				zap.Error(err),
+			)
+			if firstErr == nil {
+				firstErr = err
+			}
+		}
+	}
+	return total, firstErr
+}
+
+// purgeConversation deletes messages older than cutoff in batches until a batch comes back short.
+func (p *Purger) purgeConversation(ctx context.Context, conversationID pgtype.UUID, cutoff time.Time) (int64, error) {
+	cutoffTs := pgtype.Timestamptz{Time: cutoff, Valid: true}
+
+	var purged int64
+	for {
+		deleted, err := p.store.DeleteExpiredMessages(ctx, repository.DeleteExpiredMessagesParams{
+			ConversationID: conversationID,
+			Cutoff:         cutoffTs,
+			BatchSize:      p.batchSize,
+		})
+		if err != nil {
+			return purged, fmt.Errorf("failed to delete expired messages: %w", err)
+		}
+		purged += deleted
+		if deleted < int64(p.batchSize) || ctx.Err() != nil {
+			break
+		}
+	}
+
+	if purged == 0 {
+		return 0, nil
+	}
+	if err := p.store.ClearExpiredLastMessage(ctx, repository.ClearExpiredLastMessageParams{
+		ID:     conversationID,
+		Cutoff: cutoffTs,
+	}); err != nil {
+		return purged, fmt.Errorf("failed to clear last message: %w", err)
+	}
+	return purged, nil
+}
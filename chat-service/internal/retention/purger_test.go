@@ -0,0 +1,105 @@
+package retention
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"chat-service/internal/repository"
+
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+// fakeStore holds the number of expired messages per conversation
+type fakeStore struct {
+	conversations []repository.ListConversationsWithRetentionRow
+	expired       map[pgtype.UUID]int64
+	deleteErr     map[pgtype.UUID]error
+	deleteCalls   int
+	cutoffs       map[pgtype.UUID]time.Time
+	cleared       []pgtype.UUID
+}
+
+func (s *fakeStore) ListConversationsWithRetention(ctx context.Context) ([]repository.ListConversationsWithRetentionRow, error) {
+	return s.conversations, nil
+}
+
+func (s *fakeStore) DeleteExpiredMessages(ctx context.Context, arg repository.DeleteExpiredMessagesParams) (int64, error) {
+	s.deleteCalls++
+	if err := s.deleteErr[arg.ConversationID]; err != nil {
+		return 0, err
+	}
+	s.cutoffs[arg.ConversationID] = arg.Cutoff.Time
+	deleted := min(s.expired[arg.ConversationID], int64(arg.BatchSize))
+	s.expired[arg.ConversationID] -= deleted
+	return deleted, nil
+}
+
+func (s *fakeStore) ClearExpiredLastMessage(ctx context.Context, arg repository.ClearExpiredLastMessageParams) error {
+	s.cleared = append(s.cleared, arg.ID)
+	return nil
+}
+
+func newFakeStore(conversations ...repository.ListConversationsWithRetentionRow) *fakeStore {
+	return &fakeStore{
+		conversations: conversations,
+		expired:       make(map[pgtype.UUID]int64),
+		deleteErr:     make(map[pgtype.UUID]error),
+		cutoffs:       make(map[pgtype.UUID]time.Time),
+	}
+}
+
+func conversationID(b byte) pgtype.UUID {
+	return pgtype.UUID{Bytes: [16]byte{b}, Valid: true}
+}
+
+func TestPurgeOnce_DeletesInBatches(t *testing.T) {
+	convA, convB := conversationID(1), conversationID(2)
+	store := newFakeStore(
+		repository.ListConversationsWithRetentionRow{ID: convA, MessageTtlSeconds: 3600},
+		repository.ListConversationsWithRetentionRow{ID: convB, MessageTtlSeconds: 86400},
+	)
+	store.expired[convA] = 25
+	store.expired[convB] = 0
+
+	now := time.Date(2026, 1, 2, 0, 0, 0, 0, time.UTC)
+	purger := NewPurger(store, zap.NewNop(), time.Minute, 10)
+	purger.now = func() time.Time { return now }
+
+	purged, err := purger.PurgeOnce(context.Background())
+	require.NoError(t, err)
+
+	assert.Equal(t, int64(25), purged)
+	assert.Equal(t, int64(0), store.expired[convA])
+	assert.Equal(t, 4, store.deleteCalls, "three batches for A (10, 10, 5), one empty batch for B")
+	assert.Equal(t, now.Add(-time.Hour), store.cutoffs[convA])
+	assert.Equal(t, now.Add(-24*time.Hour), store.cutoffs[convB])
+	assert.Equal(t, []pgtype.UUID{convA}, store.cleared, "only conversations that lost messages refresh their preview")
+}
+
+func TestPurgeOnce_ContinuesAfterFailure(t *testing.T) {
+	convA, convB := conversationID(1), conversationID(2)
+	store := newFakeStore(
+		repository.ListConversationsWithRetentionRow{ID: convA, MessageTtlSeconds: 3600},
+		repository.ListConversationsWithRetentionRow{ID: convB, MessageTtlSeconds: 3600},
+	)
+	store.deleteErr[convA] = errors.New("lock timeout")
+	store.expired[convB] = 3
+
+	purger := NewPurger(store, zap.NewNop(), time.Minute, 10)
+
+	purged, err := purger.PurgeOnce(context.Background())
+	assert.Error(t, err)
+	assert.Equal(t, int64(3), purged)
+	assert.Equal(t, []pgtype.UUID{convB}, store.cleared)
+}
+
+func TestNewPurger_Defaults(t *testing.T) {
+	purger := NewPurger(newFakeStore(), zap.NewNop(), 0, 0)
+	assert.Equal(t, DefaultInterval, purger.interval)
+	assert.Equal(t, int32(DefaultBatchSize), purger.batchSize)
+}
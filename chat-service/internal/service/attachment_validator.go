@@ -0,0 +1,94 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	chatv1 "chat-service/api/chat/v1"
+	"chat-service/internal/apierror"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// Attachment validator errors. Validators wrap one of them so SendMessage can map the result:
+// rejected attachments are the client's fault (InvalidArgument), attachments that cannot be
+// judged yet, e.g. while a malware scan is still running, may succeed on retry (FailedPrecondition).
+var (
+	ErrAttachmentRejected = errors.New("attachment rejected")
+	ErrAttachmentNotReady = errors.New("attachment not ready")
+)
+
+// AttachmentValidator decides whether the attachments of a message may be stored.
+// It runs after request validation and before the send transaction, so it may be slow
+// (e.g. wait for a scan result) without holding a database connection.
+type AttachmentValidator interface {
+	ValidateAttachments(ctx context.Context, attachments []*chatv1.Attachment) error
+}
+
+// NoopAttachmentValidator accepts every attachment. It is the default.
+type NoopAttachmentValidator struct{}
+
+// ValidateAttachments implements AttachmentValidator
+func (NoopAttachmentValidator) ValidateAttachments(context.Context, []*chatv1.Attachment) error {
+	return nil
+}
+
+// AllowlistAttachmentValidator rejects attachments larger than MaxSize or whose
+// mime type is not allowlisted. It narrows the service-wide attachment rules.
+type AllowlistAttachmentValidator struct {
+	MaxSize   int64           // Largest single attachment in bytes (0 = no limit)
+	MimeTypes map[string]bool // Allowed mime types; "image/*" allows a whole type (empty = any)
+}
+
+// NewAllowlistAttachmentValidator creates a validator for the given size limit and mime types
+func NewAllowlistAttachmentValidator(maxSize int64, mimeTypes []string) *AllowlistAttachmentValidator {
+	allowed := make(map[string]bool, len(mimeTypes))
+	for _, mimeType := range mimeTypes {
+		if mimeType = strings.ToLower(strings.TrimSpace(mimeType)); mimeType != "" {
+			allowed[mimeType] = true
+		}
+	}
+	return &AllowlistAttachmentValidator{MaxSize: maxSize, MimeTypes: allowed}
+}
+
+// ValidateAttachments implements AttachmentValidator
+func (v *AllowlistAttachmentValidator) ValidateAttachments(_ context.Context, attachments []*chatv1.Attachment) error {
+	for i, a := range attachments {
+		if v.MaxSize > 0 && a.Size > v.MaxSize {
+			return fmt.Errorf("%w: attachment %d exceeds %d bytes", ErrAttachmentRejected, i, v.MaxSize)
+		}
+		if !v.allowsMimeType(a.MimeType) {
+			return fmt.Errorf("%w: attachment %d has mime_type %s", ErrAttachmentRejected, i, a.MimeType)
+		}
+	}
+	return nil
+}
+
+func (v *AllowlistAttachmentValidator) allowsMimeType(mimeType string) bool {
+	if len(v.MimeTypes) == 0 {
+		return true
+	}
+	mimeType = strings.ToLower(mimeType)
+	if v.MimeTypes[mimeType] {
+		return true
+	}
+	if slash := strings.IndexByte(mimeType, '/'); slash > 0 {
+		return v.MimeTypes[mimeType[:slash]+"/*"]
+	}
+	return false
+}
+
+// attachmentValidationError maps an AttachmentValidator error to the status returned to the client
+func attachmentValidationError(err error) error {
+	switch {
+	case errors.Is(err, ErrAttachmentNotReady):
+		return apierror.New(codes.FailedPrecondition, apierror.CodeAttachmentNotReady, err.Error(), nil)
+	case errors.Is(err, ErrAttachmentRejected):
+		return apierror.Validation("attachments", err.Error())
+	}
+	// The validator itself failed (e.g. scanner unreachable)
+	return status.Error(codes.Internal, "failed to validate attachments")
+}
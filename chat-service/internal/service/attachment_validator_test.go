@@ -0,0 +1,147 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+
+	chatv1 "chat-service/api/chat/v1"
+	"chat-service/internal/apierror"
+	"chat-service/internal/repository"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"go.uber.org/zap"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// attachmentValidatorFunc adapts a function to AttachmentValidator
+type attachmentValidatorFunc func(ctx context.Context, attachments []*chatv1.Attachment) error
+
+func (f attachmentValidatorFunc) ValidateAttachments(ctx context.Context, attachments []*chatv1.Attachment) error {
+	return f(ctx, attachments)
+}
+
+func TestAllowlistAttachmentValidator(t *testing.T) {
+	png := &chatv1.Attachment{Url: "https://cdn.example.com/a.png", MimeType: "image/png", Size: 1024}
+	mp4 := &chatv1.Attachment{Url: "https://cdn.example.com/a.mp4", MimeType: "video/mp4", Size: 4096}
+
+	tests := []struct {
+		name        string
+		maxSize     int64
+		mimeTypes   []string
+		attachments []*chatv1.Attachment
+		wantErr     bool
+	}{
+		{name: "no limits", attachments: []*chatv1.Attachment{png, mp4}},
+		{name: "within size limit", maxSize: 4096, attachments: []*chatv1.Attachment{png, mp4}},
+		{name: "over size limit", maxSize: 2048, attachments: []*chatv1.Attachment{png, mp4}, wantErr: true},
+		{name: "exact mime type", mimeTypes: []string{"image/png"}, attachments: []*chatv1.Attachment{png}},
+		{name: "mime type not allowed", mimeTypes: []string{"image/png"}, attachments: []*chatv1.Attachment{png, mp4}, wantErr: true},
+		{name: "wildcard mime type", mimeTypes: []string{"image/*", "video/*"}, attachments: []*chatv1.Attachment{png, mp4}},
+		{name: "mime types are case insensitive", mimeTypes: []string{" IMAGE/PNG "}, attachments: []*chatv1.Attachment{png}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			v := NewAllowlistAttachmentValidator(tt.maxSize, tt.mimeTypes)
+			err := v.ValidateAttachments(context.Background(), tt.attachments)
+			if tt.wantErr {
+				assert.ErrorIs(t, err, ErrAttachmentRejected)
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
+
+func TestAttachmentValidationError(t *testing.T) {
+	tests := []struct {
+		name     string
+		err      error
+		wantCode codes.Code
+		wantAPI  string
+	}{
+		{name: "rejected", err: fmt.Errorf("%w: too big", ErrAttachmentRejected), wantCode: codes.InvalidArgument, wantAPI: apierror.CodeValidationFailed},
+		{name: "not ready", err: fmt.Errorf("%w: scan pending", ErrAttachmentNotReady), wantCode: codes.FailedPrecondition, wantAPI: apierror.CodeAttachmentNotReady},
+		{name: "validator failure", err: errors.New("scanner unreachable"), wantCode: codes.Internal, wantAPI: "INTERNAL"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			st := status.Convert(attachmentValidationError(tt.err))
+			assert.Equal(t, tt.wantCode, st.Code())
+			assert.Equal(t, tt.wantAPI, apierror.Code(st))
+		})
+	}
+}
+
+func TestSendMessage_AttachmentValidator(t *testing.T) {
+	tests := []struct {
+		name      string
+		validator AttachmentValidator
+		wantCode  codes.Code
+	}{
+		{name: "noop accepts", validator: NoopAttachmentValidator{}, wantCode: codes.OK},
+		{name: "allowlist rejects", validator: NewAllowlistAttachmentValidator(512, nil), wantCode: codes.InvalidArgument},
+		{
+			name: "scan pending",
+			validator: attachmentValidatorFunc(func(context.Context, []*chatv1.Attachment) error {
+				return ErrAttachmentNotReady
+			}),
+			wantCode: codes.FailedPrecondition,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockIdempotency := new(MockIdempotencyChecker)
+			mocks := newMockTransactionHelpers()
+
+			conversationID := mustParseUUID(t, "550e8400-e29b-41d4-a716-446655440000")
+			senderID := mustParseUUID(t, "660e8400-e29b-41d4-a716-446655440000")
+			messageID := mustParseUUID(t, "770e8400-e29b-41d4-a716-446655440000")
+			mocks.setupHappyPathTransaction(conversationID, senderID, messageID, "See attached")
+
+			txStarted := false
+			beginTx := mocks.mockBeginTx
+			mocks.mockBeginTx = func(ctx context.Context) (repository.DBTX, error) {
+				txStarted = true
+				return beginTx(ctx)
+			}
+
+			service := &ChatService{
+				idempotencyCheck: mockIdempotency,
+				logger:           zap.NewNop(),
+			}
+			mocks.injectIntoService(service)
+			service.insertMessageAttachmentsFn = func(ctx context.Context, qtx *repository.Queries, params repository.InsertMessageAttachmentsParams) error {
+				return nil
+			}
+			service.SetAttachmentValidator(tt.validator)
+
+			ctx := contextWithUserID(uuidToString(senderID))
+			mockIdempotency.On("Check", ctx, "key-123").Return(nil)
+			if tt.wantCode != codes.OK {
+				mockIdempotency.On("Remove", mock.Anything, "key-123").Return(nil)
+			}
+
+			req := &chatv1.SendMessageRequest{
+				ConversationId: uuidToString(conversationID),
+				Content:        "See attached",
+				IdempotencyKey: "key-123",
+				Attachments: []*chatv1.Attachment{
+					{Url: "https://cdn.example.com/a.png", MimeType: "image/png", Size: 1024},
+				},
+			}
+
+			_, err := service.SendMessage(ctx, req)
+			assert.Equal(t, tt.wantCode, status.Code(err))
+			// A rejected message never reaches the database and frees its idempotency key
+			assert.Equal(t, tt.wantCode == codes.OK, txStarted)
+			mockIdempotency.AssertExpectations(t)
+		})
+	}
+}
@@ -0,0 +1,126 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+
+	chatv1 "chat-service/api/chat/v1"
+	"chat-service/internal/repository"
+
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+const (
+	// maxAttachmentsPerMessage limits the number of files in a single message
+	maxAttachmentsPerMessage = 10
+	// maxAttachmentsTotalSize limits the combined size of all attachments (25 MB)
+	maxAttachmentsTotalSize int64 = 25 * 1024 * 1024
+)
+
+// Attachment errors
+var (
+	ErrTooManyAttachments    = fmt.Errorf("too many attachments, max %d", maxAttachmentsPerMessage)
+	ErrInvalidAttachmentURL  = errors.New("invalid attachment url format")
+	ErrUnsupportedMimeType   = errors.New("unsupported attachment mime_type")
+	ErrInvalidAttachmentSize = errors.New("attachment size must be greater than 0")
+	ErrInvalidAttachmentDims = errors.New("attachment width/height cannot be negative")
+	ErrAttachmentsTooLarge   = fmt.Errorf("attachments exceed total size limit of %d bytes", maxAttachmentsTotalSize)
+)
+
+// allowedAttachmentMimeTypes is the allowlist of mime types accepted for attachments
+var allowedAttachmentMimeTypes = map[string]bool{
+	"image/jpeg":      true,
+	"image/png":       true,
+	"image/gif":       true,
+	"image/webp":      true,
+	"video/mp4":       true,
+	"video/webm":      true,
+	"video/quicktime": true,
+	"audio/mpeg":      true,
+	"audio/ogg":       true,
+	"application/pdf": true,
+	"application/zip": true,
+	"text/plain":      true,
+}
+
+// validateAttachments checks count, url, mime type allowlist and total size
+func validateAttachments(attachments []*chatv1.Attachment) error {
+	if len(attachments) > maxAttachmentsPerMessage {
+		return ErrTooManyAttachments
+	}
+
+	var totalSize int64
+	for _, a := range attachments {
+		if a == nil || !isValidURL(a.Url) {
+			return ErrInvalidAttachmentURL
+		}
+		if !allowedAttachmentMimeTypes[a.MimeType] {
+			return fmt.Errorf("%w: %s", ErrUnsupportedMimeType, a.MimeType)
+		}
+		if a.Size <= 0 {
+			return ErrInvalidAttachmentSize
+		}
+		if a.Width < 0 || a.Height < 0 {
+			return ErrInvalidAttachmentDims
+		}
+		totalSize += a.Size
+		if totalSize > maxAttachmentsTotalSize {
+			return ErrAttachmentsTooLarge
+		}
+	}
+
+	return nil
+}
+
+// buildInsertAttachmentsParams converts proto attachments to the bulk insert params
+func buildInsertAttachmentsParams(messageID pgtype.UUID, attachments []*chatv1.Attachment) repository.InsertMessageAttachmentsParams {
+	params := repository.InsertMessageAttachmentsParams{
+		MessageID: messageID,
+		Urls:      make([]string, 0, len(attachments)),
+		MimeTypes: make([]string, 0, len(attachments)),
+		Sizes:     make([]int64, 0, len(attachments)),
+		Widths:    make([]int32, 0, len(attachments)),
+		Heights:   make([]int32, 0, len(attachments)),
+		Positions: make([]int32, 0, len(attachments)),
+	}
+	for i, a := range attachments {
+		params.Urls = append(params.Urls, a.Url)
+		params.MimeTypes = append(params.MimeTypes, a.MimeType)
+		params.Sizes = append(params.Sizes, a.Size)
+		params.Widths = append(params.Widths, a.Width)
+		params.Heights = append(params.Heights, a.Height)
+		params.Positions = append(params.Positions, int32(i))
+	}
+	return params
+}
+
+// attachmentFromRow converts a repository attachment row to proto
+func attachmentFromRow(row repository.GetAttachmentsForMessagesRow) *chatv1.Attachment {
+	return &chatv1.Attachment{
+		Url:      row.Url,
+		MimeType: row.MimeType,
+		Size:     row.SizeBytes,
+		Width:    row.Width.Int32,
+		Height:   row.Height.Int32,
+	}
+}
+
+// attachmentEventPayload converts attachments to the JSON shape used in outbox events
+func attachmentEventPayload(attachments []*chatv1.Attachment) []map[string]interface{} {
+	result := make([]map[string]interface{}, 0, len(attachments))
+	for _, a := range attachments {
+		item := map[string]interface{}{
+			"url":       a.Url,
+			"mime_type": a.MimeType,
+			"size":      a.Size,
+		}
+		if a.Width > 0 {
+			item["width"] = a.Width
+		}
+		if a.Height > 0 {
+			item["height"] = a.Height
+		}
+		result = append(result, item)
+	}
+	return result
+}
@@ -6,14 +6,19 @@ import (
 	"errors"
 	"fmt"
 	"net/url"
+	"strings"
 	"time"
 
 	chatv1 "chat-service/api/chat/v1"
+	"chat-service/internal/apierror"
+	"chat-service/internal/auth"
 	ctxkeys "chat-service/internal/context"
 	"chat-service/internal/repository"
 	"chat-service/pkg/cloudinary"
 	"chat-service/pkg/idempotency"
+	"chat-service/pkg/profile"
 
+	"github.com/google/uuid"
 	"github.com/jackc/pgx/v5"
 	"github.com/jackc/pgx/v5/pgtype"
 	"github.com/jackc/pgx/v5/pgxpool"
@@ -24,15 +29,27 @@ import (
 
 // Common errors
 var (
-	ErrInvalidRequest      = errors.New("invalid request")
-	ErrEmptyContent        = errors.New("message content cannot be empty")
-	ErrEmptyConversationID = errors.New("conversation_id cannot be empty")
-	ErrEmptyIdempotencyKey = errors.New("idempotency_key cannot be empty")
-	ErrEmptyMediaURL       = errors.New("media_url is required for media messages")
-	ErrInvalidMediaURL     = errors.New("invalid media_url format")
-	ErrTransactionFailed   = errors.New("transaction failed")
+	ErrInvalidRequest        = errors.New("invalid request")
+	ErrEmptyContent          = errors.New("message content cannot be empty without attachments")
+	ErrContentTooLong        = errors.New("message content is too long")
+	ErrEmptyConversationID   = errors.New("conversation_id cannot be empty")
+	ErrEmptyIdempotencyKey   = errors.New("idempotency_key cannot be empty")
+	ErrEmptyMediaURL         = errors.New("media_url is required for media messages")
+	ErrInvalidMediaURL       = errors.New("invalid media_url format")
+	ErrTransactionFailed     = errors.New("transaction failed")
+	ErrInvalidMessageType    = errors.New("invalid message type")
+	ErrTooManyReceivers      = errors.New("too many receiver_ids")
+	ErrInvalidReceiverID     = errors.New("invalid receiver_id")
+	ErrMediaNotAllowed       = errors.New("media_url is only allowed on media messages")
+	ErrAttachmentsNotAllowed = errors.New("system and call messages cannot have attachments")
 )
 
+// DefaultMaxReceivers caps receiver_ids per message when no limit is configured
+const DefaultMaxReceivers = 256
+
+// DefaultMaxGroupSize caps the participants of a conversation when no limit is configured
+const DefaultMaxGroupSize = 1000
+
 // ChatService implements the gRPC ChatService interface
 type ChatService struct {
 	chatv1.UnimplementedChatServiceServer
@@ -40,21 +57,72 @@ type ChatService struct {
 	queries           *repository.Queries
 	idempotencyCheck  idempotency.Checker
 	cloudinaryService *cloudinary.Service
+	profiles          profile.Resolver
+	attachments       AttachmentValidator
+	contentFilter     ContentFilter
+	events            EventSubscriber
+	duplicates        *DuplicateTracker
 	logger            *zap.Logger
 
+	// queryTimeout bounds each repository call (0 = only the caller's deadline applies)
+	queryTimeout time.Duration
+
+	// maxContentBytes caps message content (0 = unlimited)
+	maxContentBytes int
+
+	// maxReceivers caps receiver_ids per message (0 = DefaultMaxReceivers)
+	maxReceivers int
+
+	// maxGroupSize caps the participants of a conversation (0 = DefaultMaxGroupSize)
+	maxGroupSize int
+
+	// keepArchivedOnNewMessage leaves archived conversations archived when a new message arrives
+	keepArchivedOnNewMessage bool
+
+	// strictParticipants stops senders from joining existing untyped group conversations
+	strictParticipants bool
+
 	// Injectable functions for testing
-	getMessagesFn                 func(ctx context.Context, arg repository.GetMessagesParams) ([]repository.Message, error)
-	getConversationsForUserFn     func(ctx context.Context, arg repository.GetConversationsForUserParams) ([]repository.GetConversationsForUserRow, error)
-	markAsReadFn                  func(ctx context.Context, arg repository.MarkAsReadParams) error
-	beginTxFn                     func(ctx context.Context) (repository.DBTX, error)
-	upsertConversationFn          func(ctx context.Context, qtx *repository.Queries, id pgtype.UUID) (repository.Conversation, error)
-	addConversationParticipantsFn func(ctx context.Context, qtx *repository.Queries, params repository.AddConversationParticipantsParams) error
-	insertMessageFn               func(ctx context.Context, qtx *repository.Queries, params repository.InsertMessageParams) (repository.Message, error)
-	updateLastMessageFn           func(ctx context.Context, qtx *repository.Queries, params repository.UpdateConversationLastMessageParams) error
-	insertOutboxFn                func(ctx context.Context, qtx *repository.Queries, params repository.InsertOutboxParams) error
-	commitTxFn                    func(ctx context.Context, tx repository.DBTX) error
-	rollbackTxFn                  func(ctx context.Context, tx repository.DBTX) error
-	getConversationParticipantsFn func(ctx context.Context, qtx *repository.Queries, conversationID pgtype.UUID) ([]pgtype.UUID, error)
+	getMessagesFn                  func(ctx context.Context, arg repository.GetMessagesParams) ([]repository.Message, error)
+	isParticipantFn                func(ctx context.Context, arg repository.IsParticipantParams) (bool, error)
+	getConversationsForUserFn      func(ctx context.Context, arg repository.GetConversationsForUserParams) ([]repository.GetConversationsForUserRow, error)
+	markAsReadFn                   func(ctx context.Context, qtx *repository.Queries, arg repository.MarkAsReadParams) (pgtype.Timestamptz, error)
+	hasUnreadMessagesFn            func(ctx context.Context, qtx *repository.Queries, arg repository.HasUnreadMessagesParams) (bool, error)
+	countUnreadMessagesFn          func(ctx context.Context, qtx *repository.Queries, arg repository.CountUnreadMessagesParams) (int32, error)
+	beginTxFn                      func(ctx context.Context) (repository.DBTX, error)
+	upsertConversationFn           func(ctx context.Context, qtx *repository.Queries, id pgtype.UUID) (repository.UpsertConversationRow, error)
+	createConversationFn           func(ctx context.Context, qtx *repository.Queries, params repository.CreateConversationParams) (repository.Conversation, error)
+	addConversationParticipantsFn  func(ctx context.Context, qtx *repository.Queries, params repository.AddConversationParticipantsParams) (int64, error)
+	insertMessageFn                func(ctx context.Context, qtx *repository.Queries, params repository.InsertMessageParams) (repository.Message, error)
+	updateLastMessageFn            func(ctx context.Context, qtx *repository.Queries, params repository.UpdateConversationLastMessageParams) error
+	insertOutboxFn                 func(ctx context.Context, qtx *repository.Queries, params repository.InsertOutboxParams) error
+	insertOutboxBatchFn            func(ctx context.Context, qtx *repository.Queries, params repository.InsertOutboxBatchParams) (int64, error)
+	commitTxFn                     func(ctx context.Context, tx repository.DBTX) error
+	rollbackTxFn                   func(ctx context.Context, tx repository.DBTX) error
+	getConversationParticipantsFn  func(ctx context.Context, qtx *repository.Queries, conversationID pgtype.UUID) ([]pgtype.UUID, error)
+	insertMessageAttachmentsFn     func(ctx context.Context, qtx *repository.Queries, params repository.InsertMessageAttachmentsParams) error
+	getAttachmentsForMessagesFn    func(ctx context.Context, messageIDs []pgtype.UUID) ([]repository.GetAttachmentsForMessagesRow, error)
+	listParticipantsFn             func(ctx context.Context, conversationID pgtype.UUID) ([]repository.ListConversationParticipantsRow, error)
+	hideConversationFn             func(ctx context.Context, arg repository.HideConversationParams) (int64, error)
+	getMessageIDByIdempotencyKeyFn func(ctx context.Context, arg repository.GetMessageIDByIdempotencyKeyParams) (pgtype.UUID, error)
+	exportMessagesFn               func(ctx context.Context, arg repository.ExportMessagesParams) ([]repository.Message, error)
+	getConversationsByIDsFn        func(ctx context.Context, arg repository.GetConversationsByIDsParams) ([]repository.GetConversationsByIDsRow, error)
+	getParticipantReceiptsFn       func(ctx context.Context, conversationID pgtype.UUID) ([]repository.GetParticipantReceiptsRow, error)
+	markAsDeliveredFn              func(ctx context.Context, arg repository.MarkAsDeliveredParams) error
+	getMessageByIDFn               func(ctx context.Context, id pgtype.UUID) (repository.Message, error)
+	setConversationArchivedFn      func(ctx context.Context, arg repository.SetConversationArchivedParams) (int64, error)
+	setConversationRetentionFn     func(ctx context.Context, arg repository.SetConversationRetentionParams) (int64, error)
+	pinConversationFn              func(ctx context.Context, arg repository.PinConversationParams) (pgtype.Timestamptz, error)
+	unpinConversationFn            func(ctx context.Context, arg repository.UnpinConversationParams) (int64, error)
+	setNotificationLevelFn         func(ctx context.Context, arg repository.SetNotificationLevelParams) (int64, error)
+	insertMessageMentionsFn        func(ctx context.Context, qtx *repository.Queries, params repository.InsertMessageMentionsParams) error
+	getMentionsFn                  func(ctx context.Context, arg repository.GetMentionsParams) ([]repository.Message, error)
+	getNotificationLevelsFn        func(ctx context.Context, conversationID pgtype.UUID) ([]repository.GetConversationNotificationLevelsRow, error)
+	lockMessageForReactionsFn      func(ctx context.Context, qtx *repository.Queries, messageID pgtype.UUID) error
+	addReactionFn                  func(ctx context.Context, qtx *repository.Queries, arg repository.AddReactionParams) (int64, error)
+	removeReactionFn               func(ctx context.Context, qtx *repository.Queries, arg repository.RemoveReactionParams) (int64, error)
+	countReactionsFn               func(ctx context.Context, qtx *repository.Queries, arg repository.CountReactionsParams) (int32, error)
+	getReactionsForMessagesFn      func(ctx context.Context, arg repository.GetReactionsForMessagesParams) ([]repository.GetReactionsForMessagesRow, error)
 }
 
 // NewChatService creates a new ChatService instance
@@ -67,9 +135,12 @@ func NewChatService(
 		db:               db,
 		queries:          repository.New(db),
 		idempotencyCheck: idempotencyCheck,
+		attachments:      NoopAttachmentValidator{},
+		contentFilter:    NoopContentFilter{},
 		logger:           logger,
 	}
 	service.getMessagesFn = service.queries.GetMessages
+	service.getNotificationLevelsFn = service.queries.GetConversationNotificationLevels
 	return service
 }
 
@@ -85,6 +156,96 @@ func NewChatServiceWithCloudinary(
 	return service
 }
 
+// SetProfileResolver enables sender name/avatar on returned messages and outbox events
+func (s *ChatService) SetProfileResolver(resolver profile.Resolver) {
+	s.profiles = resolver
+}
+
+// SetAttachmentValidator installs an extra check on message attachments, e.g. a
+// stricter allowlist or a malware scan. Nil restores the default, which accepts everything.
+func (s *ChatService) SetAttachmentValidator(validator AttachmentValidator) {
+	if validator == nil {
+		validator = NoopAttachmentValidator{}
+	}
+	s.attachments = validator
+}
+
+// SetEventSubscriber enables the StreamEvents RPC
+func (s *ChatService) SetEventSubscriber(events EventSubscriber) {
+	s.events = events
+}
+
+// SetDuplicateTracker attributes idempotency duplicate hits to users and conversations
+func (s *ChatService) SetDuplicateTracker(tracker *DuplicateTracker) {
+	s.duplicates = tracker
+}
+
+// SetQueryTimeout bounds every database call made by the service.
+// It complements the server-side statement_timeout so a stalled connection
+// cannot hold a request (and a pool slot) indefinitely.
+func (s *ChatService) SetQueryTimeout(timeout time.Duration) {
+	s.queryTimeout = timeout
+}
+
+// SetMaxContentBytes caps the content of sent messages, in bytes.
+// Keep it in sync with the ws-gateway, whose read limit is derived from the same setting.
+func (s *ChatService) SetMaxContentBytes(limit int) {
+	s.maxContentBytes = limit
+}
+
+// SetMaxReceivers caps the number of receiver_ids a single message may carry.
+// A limit of 0 or less restores DefaultMaxReceivers.
+func (s *ChatService) SetMaxReceivers(limit int) {
+	s.maxReceivers = limit
+}
+
+// receiverLimit returns the effective receiver_ids cap
+func (s *ChatService) receiverLimit() int {
+	if s.maxReceivers <= 0 {
+		return DefaultMaxReceivers
+	}
+	return s.maxReceivers
+}
+
+// SetMaxGroupSize caps the participants of a conversation. Creating a larger conversation, or
+// sending a message whose receiver_ids would grow one past the cap, fails with FailedPrecondition;
+// conversations that are already larger keep working as long as nobody joins.
+// A limit of 0 or less restores DefaultMaxGroupSize.
+func (s *ChatService) SetMaxGroupSize(limit int) {
+	s.maxGroupSize = limit
+}
+
+// groupSizeLimit returns the effective participant cap
+func (s *ChatService) groupSizeLimit() int {
+	if s.maxGroupSize <= 0 {
+		return DefaultMaxGroupSize
+	}
+	return s.maxGroupSize
+}
+
+// SetKeepArchivedOnNewMessage controls what a new message does to archived conversations.
+// By default it moves them back to the main list of every participant that archived them;
+// when keep is true they stay archived until UnarchiveConversation.
+func (s *ChatService) SetKeepArchivedOnNewMessage(keep bool) {
+	s.keepArchivedOnNewMessage = keep
+}
+
+// SetStrictParticipants controls who may send into an existing conversation created by a first
+// message. By default any sender that names its id joins it; in strict mode only participants may
+// send, except that a second user may still join a conversation that has a single participant.
+// Typed conversations (CreateConversation) always enforce their own rules.
+func (s *ChatService) SetStrictParticipants(strict bool) {
+	s.strictParticipants = strict
+}
+
+// queryContext derives the context for a single repository call
+func (s *ChatService) queryContext(ctx context.Context) (context.Context, context.CancelFunc) {
+	if s.queryTimeout <= 0 {
+		return ctx, func() {}
+	}
+	return context.WithTimeout(ctx, s.queryTimeout)
+}
+
 // SendMessage handles sending a new message
 func (s *ChatService) SendMessage(ctx context.Context, req *chatv1.SendMessageRequest) (*chatv1.SendMessageResponse, error) {
 	// 1. Extract user_id from context (set by auth middleware)
@@ -101,19 +262,49 @@ func (s *ChatService) SendMessage(ctx context.Context, req *chatv1.SendMessageRe
 			zap.String("conversation_id", req.ConversationId),
 			zap.String("user_id", userID),
 		)
-		return nil, status.Error(codes.InvalidArgument, err.Error())
+		return nil, apierror.Validation(validationField(err), err.Error())
+	}
+
+	// System messages speak for the conversation, not a user: only trusted callers may post them
+	if req.Type == chatv1.MessageType_MESSAGE_TYPE_SYSTEM {
+		if err := auth.RequireRole(ctx, auth.RoleAdmin); err != nil {
+			return nil, err
+		}
+	}
+
+	// Rejected content never claims the idempotency key; flagged content is sent and marked for review
+	moderationFlags, err := s.filterContent(ctx, req.Content, req.Type)
+	if err != nil {
+		s.logger.Warn("content not accepted",
+			zap.Error(err),
+			zap.String("conversation_id", req.ConversationId),
+			zap.String("user_id", userID),
+		)
+		return nil, err
 	}
 
-	// 3. Check idempotency
-	err = s.idempotencyCheck.Check(ctx, req.IdempotencyKey)
+	// 3. Check idempotency, within the dedup window requested by the client if any
+	ttl, customTTL, err := idempotencyTTLFromContext(ctx)
+	if err != nil {
+		return nil, apierror.Validation(IdempotencyTTLHeader, err.Error())
+	}
+	if customTTL {
+		err = s.idempotencyCheck.CheckWithTTL(ctx, req.IdempotencyKey, ttl)
+	} else {
+		err = s.idempotencyCheck.Check(ctx, req.IdempotencyKey)
+	}
+	if s.duplicates != nil {
+		s.duplicates.RecordCheck(userID, req.ConversationId, err)
+	}
 	if err != nil {
 		if errors.Is(err, idempotency.ErrDuplicateRequest) {
 			s.logger.Warn("duplicate request detected",
 				zap.String("idempotency_key", req.IdempotencyKey),
 				zap.String("conversation_id", req.ConversationId),
 				zap.String("user_id", userID),
+				zap.String("user_hash", hashSubject(userID)),
 			)
-			return nil, status.Error(codes.AlreadyExists, "duplicate request: message already sent")
+			return nil, apierror.Duplicate("duplicate request: message already sent", req.IdempotencyKey)
 		}
 		s.logger.Error("idempotency check failed",
 			zap.Error(err),
@@ -122,27 +313,105 @@ func (s *ChatService) SendMessage(ctx context.Context, req *chatv1.SendMessageRe
 		return nil, status.Error(codes.Internal, "failed to check idempotency")
 	}
 
-	// 4. Execute transaction: upsert conversation + insert message + insert outbox
-	messageID, err := s.sendMessageTx(ctx, req, userID)
+	// 4. Let the attachment validator veto the message before anything is stored
+	if err := s.checkAttachments(ctx, req); err != nil {
+		s.logger.Warn("attachments not accepted",
+			zap.Error(err),
+			zap.String("conversation_id", req.ConversationId),
+			zap.String("user_id", userID),
+		)
+		// Nothing was stored: a retry (e.g. once a scan finished) must not be rejected as a duplicate
+		s.releaseIdempotencyKey(ctx, req.IdempotencyKey)
+		return nil, err
+	}
+
+	// 5. Execute transaction: upsert conversation + insert message + insert outbox
+	resp, err := s.sendMessageTx(ctx, req, userID, moderationFlags)
+	if ruleErr := conversationRuleError(err); ruleErr != nil {
+		s.logger.Warn("message rejected by conversation rules",
+			zap.Error(err),
+			zap.String("conversation_id", req.ConversationId),
+			zap.String("user_id", userID),
+		)
+		s.releaseIdempotencyKey(ctx, req.IdempotencyKey)
+		return nil, ruleErr
+	}
 	if err != nil {
 		s.logger.Error("transaction failed",
 			zap.Error(err),
 			zap.String("conversation_id", req.ConversationId),
 			zap.String("user_id", userID),
 		)
+		// Nothing was committed, so release the key; otherwise an honest retry is rejected as a duplicate
+		s.releaseIdempotencyKey(ctx, req.IdempotencyKey)
 		return nil, status.Error(codes.Internal, "failed to send message")
 	}
 
 	s.logger.Info("message sent successfully",
-		zap.String("message_id", messageID),
+		zap.String("message_id", resp.MessageId),
 		zap.String("conversation_id", req.ConversationId),
 		zap.String("user_id", userID),
+		zap.Bool("conversation_created", resp.ConversationCreated),
 	)
 
-	return &chatv1.SendMessageResponse{
-		MessageId: messageID,
-		Status:    "SENT",
-	}, nil
+	resp.Status = "SENT"
+	return resp, nil
+}
+
+// checkAttachments runs the configured AttachmentValidator and maps its error to a status
+func (s *ChatService) checkAttachments(ctx context.Context, req *chatv1.SendMessageRequest) error {
+	if s.attachments == nil || len(req.Attachments) == 0 {
+		return nil
+	}
+	if err := s.attachments.ValidateAttachments(ctx, req.Attachments); err != nil {
+		return attachmentValidationError(err)
+	}
+	return nil
+}
+
+// idempotencyReleaseTimeout bounds the key cleanup after a failed send
+const idempotencyReleaseTimeout = 2 * time.Second
+
+// releaseIdempotencyKey removes a key claimed by a request that failed.
+// It runs detached from ctx because a cancelled request is exactly the case that needs the cleanup.
+func (s *ChatService) releaseIdempotencyKey(ctx context.Context, key string) {
+	removeCtx, cancel := context.WithTimeout(context.WithoutCancel(ctx), idempotencyReleaseTimeout)
+	defer cancel()
+
+	if err := s.idempotencyCheck.Remove(removeCtx, key); err != nil {
+		// The key expires with its TTL; until then retries with it are rejected
+		s.logger.Error("failed to release idempotency key",
+			zap.Error(err),
+			zap.String("idempotency_key", key),
+		)
+	}
+}
+
+// validationField maps a SendMessage validation error to the offending request field
+func validationField(err error) string {
+	switch {
+	case errors.Is(err, ErrEmptyConversationID):
+		return "conversation_id"
+	case errors.Is(err, ErrEmptyIdempotencyKey):
+		return "idempotency_key"
+	case errors.Is(err, ErrEmptyContent), errors.Is(err, ErrContentTooLong):
+		return "content"
+	case errors.Is(err, ErrEmptyMediaURL), errors.Is(err, ErrInvalidMediaURL), errors.Is(err, ErrMediaNotAllowed):
+		return "media_url"
+	case errors.Is(err, ErrInvalidMessageType):
+		return "type"
+	case errors.Is(err, ErrTooManyReceivers), errors.Is(err, ErrInvalidReceiverID):
+		return "receiver_ids"
+	case errors.Is(err, ErrTooManyAttachments),
+		errors.Is(err, ErrAttachmentsNotAllowed),
+		errors.Is(err, ErrInvalidAttachmentURL),
+		errors.Is(err, ErrUnsupportedMimeType),
+		errors.Is(err, ErrInvalidAttachmentSize),
+		errors.Is(err, ErrInvalidAttachmentDims),
+		errors.Is(err, ErrAttachmentsTooLarge):
+		return "attachments"
+	}
+	return ""
 }
 
 // validateSendMessageRequest validates the SendMessage request
@@ -159,6 +428,19 @@ func (s *ChatService) validateSendMessageRequest(req *chatv1.SendMessageRequest)
 		return ErrEmptyIdempotencyKey
 	}
 
+	if s.maxContentBytes > 0 && len(req.Content) > s.maxContentBytes {
+		return fmt.Errorf("%w: at most %d bytes", ErrContentTooLong, s.maxContentBytes)
+	}
+
+	// The limit counts distinct receivers: repeating an id does not add a receiver
+	receivers, err := parseReceiverIDs(req.ReceiverIds)
+	if err != nil {
+		return err
+	}
+	if limit := s.receiverLimit(); len(receivers) > limit {
+		return fmt.Errorf("%w: at most %d", ErrTooManyReceivers, limit)
+	}
+
 	// Determine message type (default to TEXT if not specified)
 	msgType := req.Type
 	if msgType == chatv1.MessageType_MESSAGE_TYPE_UNSPECIFIED {
@@ -168,7 +450,8 @@ func (s *ChatService) validateSendMessageRequest(req *chatv1.SendMessageRequest)
 	// Validate based on message type
 	switch msgType {
 	case chatv1.MessageType_MESSAGE_TYPE_TEXT:
-		if req.Content == "" {
+		// Attachment-only messages (e.g. just a photo) need no text
+		if req.Content == "" && len(req.Attachments) == 0 {
 			return ErrEmptyContent
 		}
 	case chatv1.MessageType_MESSAGE_TYPE_IMAGE,
@@ -181,11 +464,23 @@ func (s *ChatService) validateSendMessageRequest(req *chatv1.SendMessageRequest)
 			return ErrInvalidMediaURL
 		}
 		// Content is optional for media messages (can be used as caption)
+	case chatv1.MessageType_MESSAGE_TYPE_SYSTEM,
+		chatv1.MessageType_MESSAGE_TYPE_CALL:
+		// Rendered from their text alone: "Alice renamed the group", "Missed voice call"
+		if req.Content == "" {
+			return ErrEmptyContent
+		}
+		if req.MediaUrl != "" {
+			return ErrMediaNotAllowed
+		}
+		if len(req.Attachments) > 0 {
+			return ErrAttachmentsNotAllowed
+		}
 	default:
-		return errors.New("invalid message type")
+		return ErrInvalidMessageType
 	}
 
-	return nil
+	return validateAttachments(req.Attachments)
 }
 
 // isValidURL validates URL format
@@ -194,6 +489,35 @@ func isValidURL(urlStr string) bool {
 	return err == nil && u.Scheme != "" && u.Host != ""
 }
 
+// messagePreview is the text shown for a message in conversation lists and notifications:
+// its content, or a placeholder for messages that only carry media or attachments
+func messagePreview(content string, msgType chatv1.MessageType, attachments []*chatv1.Attachment) string {
+	if content != "" {
+		return content
+	}
+
+	switch msgType {
+	case chatv1.MessageType_MESSAGE_TYPE_IMAGE:
+		return "[Hình ảnh]"
+	case chatv1.MessageType_MESSAGE_TYPE_VIDEO:
+		return "[Video]"
+	case chatv1.MessageType_MESSAGE_TYPE_FILE:
+		return "[Tệp đính kèm]"
+	}
+
+	// Attachment-only text message: describe the first attachment
+	if len(attachments) > 0 && attachments[0] != nil {
+		switch mime := attachments[0].MimeType; {
+		case strings.HasPrefix(mime, "image/"):
+			return "[Hình ảnh]"
+		case strings.HasPrefix(mime, "video/"):
+			return "[Video]"
+		}
+		return "[Tệp đính kèm]"
+	}
+	return ""
+}
+
 // getMessageTypeString converts proto MessageType to database string
 func getMessageTypeString(msgType chatv1.MessageType) string {
 	switch msgType {
@@ -203,6 +527,10 @@ func getMessageTypeString(msgType chatv1.MessageType) string {
 		return "VIDEO"
 	case chatv1.MessageType_MESSAGE_TYPE_FILE:
 		return "FILE"
+	case chatv1.MessageType_MESSAGE_TYPE_SYSTEM:
+		return "SYSTEM"
+	case chatv1.MessageType_MESSAGE_TYPE_CALL:
+		return "CALL"
 	default:
 		return "TEXT"
 	}
@@ -217,34 +545,52 @@ func getProtoMessageType(typeStr string) chatv1.MessageType {
 		return chatv1.MessageType_MESSAGE_TYPE_VIDEO
 	case "FILE":
 		return chatv1.MessageType_MESSAGE_TYPE_FILE
+	case "SYSTEM":
+		return chatv1.MessageType_MESSAGE_TYPE_SYSTEM
+	case "CALL":
+		return chatv1.MessageType_MESSAGE_TYPE_CALL
 	default:
 		return chatv1.MessageType_MESSAGE_TYPE_TEXT
 	}
 }
 
-// sendMessageTx executes the message sending in a transaction
-func (s *ChatService) sendMessageTx(ctx context.Context, req *chatv1.SendMessageRequest, userID string) (string, error) {
+// sendMessageTx executes the message sending in a transaction.
+// The response carries the new conversation when this message created it.
+func (s *ChatService) sendMessageTx(ctx context.Context, req *chatv1.SendMessageRequest, userID string, moderationFlags []string) (*chatv1.SendMessageResponse, error) {
 	// Parse UUIDs
 	conversationUUID, err := parseUUID(req.ConversationId)
 	if err != nil {
-		return "", fmt.Errorf("invalid conversation_id: %w", err)
+		return nil, fmt.Errorf("invalid conversation_id: %w", err)
 	}
 
 	senderUUID, err := parseUUID(userID)
 	if err != nil {
-		return "", fmt.Errorf("invalid user_id: %w", err)
+		return nil, fmt.Errorf("invalid user_id: %w", err)
 	}
 
 	// Parse receiver_ids if provided
 	receiverUUIDs, err := parseReceiverIDs(req.ReceiverIds)
 	if err != nil {
-		return "", err
+		return nil, err
+	}
+
+	// Resolve the sender profile before the transaction so the lookup never holds a connection
+	sender := s.resolveProfiles(ctx, []string{userID})[userID]
+
+	// Same for notification preferences; participants added by this message have the default
+	levels := s.notificationLevels(ctx, conversationUUID)
+
+	// And for the members that @username mentions refer to. System messages mention nobody.
+	var mentions mentionTokens
+	if req.Type != chatv1.MessageType_MESSAGE_TYPE_SYSTEM {
+		mentions = parseMentions(req.Content)
 	}
+	usernameIDs := s.mentionUsernames(ctx, conversationUUID, receiverUUIDs, mentions.usernames)
 
 	// Begin transaction
 	tx, err := s.beginTx(ctx)
 	if err != nil {
-		return "", fmt.Errorf("failed to begin transaction: %w", err)
+		return nil, fmt.Errorf("failed to begin transaction: %w", err)
 	}
 	defer func() { _ = s.rollbackTx(ctx, tx) }() // Rollback if not committed
 
@@ -260,24 +606,39 @@ func (s *ChatService) sendMessageTx(ctx context.Context, req *chatv1.SendMessage
 	}
 
 	// 1. Upsert conversation (ensure it exists)
-	_, err = s.upsertConversation(ctx, qtx, conversationUUID)
+	conversation, err := s.upsertConversation(ctx, qtx, conversationUUID)
 	if err != nil {
-		return "", fmt.Errorf("failed to upsert conversation: %w", err)
+		return nil, fmt.Errorf("failed to upsert conversation: %w", err)
+	}
+
+	// 1b. Typed conversations (CreateConversation) have fixed membership rules; in strict mode
+	// existing untyped ones only take messages from participants. A conversation this message
+	// creates has no members yet, so its sender is always allowed.
+	if conversation.Type.Valid || (s.strictParticipants && !conversation.Inserted) {
+		members, err := s.getConversationParticipants(ctx, qtx, conversationUUID)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get conversation participants: %w", err)
+		}
+		if conversation.Type.Valid {
+			err = checkTypedConversationSend(conversation.Type, members, senderUUID, receiverUUIDs)
+		} else {
+			err = checkStrictConversationSend(members, senderUUID)
+		}
+		if err != nil {
+			return nil, err
+		}
 	}
 
 	// 2. Add sender + receivers as participants using bulk insert
-	// Merge sender and receivers into allParticipants array
-	allParticipants := make([]pgtype.UUID, 0, len(receiverUUIDs)+1)
-	allParticipants = append(allParticipants, senderUUID)
-	allParticipants = append(allParticipants, receiverUUIDs...)
+	allParticipants := distinctParticipants(senderUUID, receiverUUIDs)
 
 	// Bulk insert all participants - ON CONFLICT DO NOTHING handles duplicates
-	err = s.addConversationParticipants(ctx, qtx, repository.AddConversationParticipantsParams{
+	joined, err := s.addConversationParticipants(ctx, qtx, repository.AddConversationParticipantsParams{
 		ConversationID: conversationUUID,
 		Column2:        allParticipants,
 	})
 	if err != nil {
-		return "", fmt.Errorf("failed to add participants: %w", err)
+		return nil, fmt.Errorf("failed to add participants: %w", err)
 	}
 
 	// 3. Insert message
@@ -299,24 +660,27 @@ func (s *ChatService) sendMessageTx(ctx context.Context, req *chatv1.SendMessage
 		Type:           getMessageTypeString(msgType),
 		MediaUrl:       mediaURL,
 		MediaMetadata:  nil, // Can be extended later
+		IdempotencyKey: idempotencyKeyParam(req.IdempotencyKey),
 	})
+	if isUniqueViolation(err, messageIdempotencyIndex) {
+		_ = s.rollbackTx(ctx, tx)
+		return s.replayStoredMessage(ctx, senderUUID, req.IdempotencyKey)
+	}
 	if err != nil {
-		return "", fmt.Errorf("failed to insert message: %w", err)
+		return nil, fmt.Errorf("failed to insert message: %w", err)
 	}
 
-	// 4. Update conversation last message (use content or "[Image]" for media)
-	lastMessageContent := req.Content
-	if msgType != chatv1.MessageType_MESSAGE_TYPE_TEXT && lastMessageContent == "" {
-		switch msgType {
-		case chatv1.MessageType_MESSAGE_TYPE_IMAGE:
-			lastMessageContent = "[Hình ảnh]"
-		case chatv1.MessageType_MESSAGE_TYPE_VIDEO:
-			lastMessageContent = "[Video]"
-		case chatv1.MessageType_MESSAGE_TYPE_FILE:
-			lastMessageContent = "[Tệp đính kèm]"
+	// 3b. Insert attachments (same transaction as the message)
+	if len(req.Attachments) > 0 {
+		err = s.insertMessageAttachments(ctx, qtx, buildInsertAttachmentsParams(message.ID, req.Attachments))
+		if err != nil {
+			return nil, fmt.Errorf("failed to insert attachments: %w", err)
 		}
 	}
 
+	// 4. Update conversation last message (use content or "[Image]" for media)
+	lastMessageContent := messagePreview(req.Content, msgType, req.Attachments)
+
 	err = s.updateLastMessage(ctx, qtx, repository.UpdateConversationLastMessageParams{
 		ID: conversationUUID,
 		LastMessageContent: pgtype.Text{
@@ -324,29 +688,46 @@ func (s *ChatService) sendMessageTx(ctx context.Context, req *chatv1.SendMessage
 			Valid:  true,
 		},
 		LastMessageAt: message.CreatedAt,
+		Unarchive:     !s.keepArchivedOnNewMessage,
 	})
 	if err != nil {
-		return "", fmt.Errorf("failed to update conversation last message: %w", err)
+		return nil, fmt.Errorf("failed to update conversation last message: %w", err)
 	}
 
 	// 5. Get all participants to determine receivers
 	participants, err := s.getConversationParticipants(ctx, qtx, conversationUUID)
 	if err != nil {
-		return "", fmt.Errorf("failed to get conversation participants: %w", err)
+		return nil, fmt.Errorf("failed to get conversation participants: %w", err)
+	}
+
+	// 5a. Nobody may join a conversation that is already full; the row lock taken by the upsert
+	// serializes concurrent sends, so the count is exact
+	if limit := s.groupSizeLimit(); joined > 0 && len(participants) > limit {
+		return nil, fmt.Errorf("%w: at most %d participants", ErrGroupTooLarge, limit)
 	}
 
 	// Filter out sender to get receiver_ids
-	receiverIDs := make([]string, 0, len(participants))
-	for _, p := range participants {
-		if p != senderUUID {
-			receiverIDs = append(receiverIDs, uuidToString(p))
+	receiverIDs := otherParticipantIDs(participants, senderUUID)
+
+	// 5b. Record the participants the message mentions, for their mentions inbox
+	mentioned := mentionedParticipants(mentions, usernameIDs, participants, senderUUID)
+	if len(mentioned) > 0 {
+		err = s.insertMessageMentions(ctx, qtx, repository.InsertMessageMentionsParams{
+			MessageID:      message.ID,
+			UserIds:        mentioned,
+			ConversationID: conversationUUID,
+			CreatedAt:      message.CreatedAt,
+		})
+		if err != nil {
+			return nil, fmt.Errorf("failed to insert mentions: %w", err)
 		}
 	}
 
-	// 6. Create outbox event payload with receiver_ids
-	payload, err := s.createMessageEventPayload(message, receiverIDs)
+	// 6. Create outbox event payload with receiver_ids and whether each of them should be alerted
+	deliveries := messageDeliveries(receiverIDs, levels, msgType, mentioned)
+	payload, err := s.createMessageEventPayload(message, sender, receiverIDs, deliveries, req.Attachments, joined > 0, moderationFlags, eventOriginFromContext(ctx))
 	if err != nil {
-		return "", fmt.Errorf("failed to create event payload: %w", err)
+		return nil, fmt.Errorf("failed to create event payload: %w", err)
 	}
 
 	// 7. Insert outbox event
@@ -356,19 +737,36 @@ func (s *ChatService) sendMessageTx(ctx context.Context, req *chatv1.SendMessage
 		Payload:       payload,
 	})
 	if err != nil {
-		return "", fmt.Errorf("failed to insert outbox: %w", err)
+		return nil, fmt.Errorf("failed to insert outbox: %w", err)
 	}
 
 	// Commit transaction
 	if err = s.commitTx(ctx, tx); err != nil {
-		return "", fmt.Errorf("failed to commit transaction: %w", err)
+		return nil, fmt.Errorf("failed to commit transaction: %w", err)
 	}
 
-	return uuidToString(message.ID), nil
+	resp := &chatv1.SendMessageResponse{MessageId: uuidToString(message.ID)}
+	if conversation.Inserted {
+		// Saves the sender a GetConversations round trip to learn about the new conversation
+		resp.ConversationCreated = true
+		resp.Conversation = &chatv1.Conversation{
+			Id:                 uuidToString(conversation.ID),
+			LastMessageContent: lastMessageContent,
+			LastMessageAt:      formatTimestamp(message.CreatedAt),
+			MessageTtlSeconds:  int64(conversation.MessageTtlSeconds.Int32),
+			Type:               conversationTypeProto(conversation.Type),
+			Name:               conversation.Name.String,
+		}
+	}
+	return resp, nil
 }
 
 // createMessageEventPayload creates the JSON payload for the outbox event
-func (s *ChatService) createMessageEventPayload(message repository.Message, receiverIDs []string) ([]byte, error) {
+// deliveries carries the per-receiver notify flag for push notifications (left out when nil)
+// newParticipants marks a send that added users to the conversation (sharded delivery broadcasts these)
+// moderationFlags, set by the content filter, mark the message for downstream review (left out when empty)
+// origin lets the delivery be traced back to the originating request and device
+func (s *ChatService) createMessageEventPayload(message repository.Message, sender profile.Profile, receiverIDs []string, deliveries []messageDelivery, attachments []*chatv1.Attachment, newParticipants bool, moderationFlags []string, origin eventOrigin) ([]byte, error) {
 	event := map[string]interface{}{
 		"event_type":      "message.sent",
 		"message_id":      uuidToString(message.ID),
@@ -380,11 +778,48 @@ func (s *ChatService) createMessageEventPayload(message repository.Message, rece
 		"created_at":      message.CreatedAt.Time.Format(time.RFC3339),
 	}
 
+	if deliveries != nil {
+		event["deliveries"] = deliveries
+	}
+
+	if len(moderationFlags) > 0 {
+		event["moderation"] = map[string]interface{}{
+			"flagged": true,
+			"flags":   moderationFlags,
+		}
+	}
+
 	// Add media_url if present
 	if message.MediaUrl.Valid {
 		event["media_url"] = message.MediaUrl.String
 	}
 
+	// Add attachments if present
+	if len(attachments) > 0 {
+		event["attachments"] = attachmentEventPayload(attachments)
+	}
+
+	// Content-less messages carry a placeholder for notifications and previews
+	if message.Content == "" {
+		if preview := messagePreview("", getProtoMessageType(message.Type), attachments); preview != "" {
+			event["preview"] = preview
+		}
+	}
+
+	// Add sender profile if resolved
+	if sender.DisplayName != "" {
+		event["sender_name"] = sender.DisplayName
+	}
+	if sender.AvatarURL != "" {
+		event["sender_avatar_url"] = sender.AvatarURL
+	}
+
+	if newParticipants {
+		event["new_participants"] = true
+	}
+
+	origin.addTo(event)
+
 	payload, err := json.Marshal(event)
 	if err != nil {
 		return nil, fmt.Errorf("failed to marshal event: %w", err)
@@ -403,24 +838,76 @@ func parseUUID(uuidStr string) (pgtype.UUID, error) {
 	return uuid, nil
 }
 
-// parseReceiverIDs parses an array of UUID strings to []pgtype.UUID
-// Returns an error with invalid UUID details on failure
+// parseUserID parses a user id supplied in a request. Unlike parseUUID it only accepts the
+// canonical 36 character form, and rejects the nil UUID, which never names a user.
+func parseUserID(id string) (pgtype.UUID, error) {
+	if len(id) != 36 {
+		return pgtype.UUID{}, fmt.Errorf("expected 36 characters, got %d", len(id))
+	}
+	parsed, err := uuid.Parse(id)
+	if err != nil {
+		return pgtype.UUID{}, err
+	}
+	if parsed == uuid.Nil {
+		return pgtype.UUID{}, errors.New("nil UUID")
+	}
+	return pgtype.UUID{Bytes: parsed, Valid: true}, nil
+}
+
+// parseReceiverIDs parses an array of user ids to []pgtype.UUID
+// Returns an error wrapping ErrInvalidReceiverID with the offending id on failure
 func parseReceiverIDs(receiverIDs []string) ([]pgtype.UUID, error) {
 	if len(receiverIDs) == 0 {
 		return nil, nil
 	}
 
+	// Duplicates are dropped (first occurrence wins) so each receiver is inserted once
 	result := make([]pgtype.UUID, 0, len(receiverIDs))
+	seen := make(map[[16]byte]struct{}, len(receiverIDs))
 	for _, rid := range receiverIDs {
-		uuid, err := parseUUID(rid)
+		id, err := parseUserID(rid)
 		if err != nil {
-			return nil, fmt.Errorf("invalid receiver_id: %s", rid)
+			return nil, fmt.Errorf("%w: %q", ErrInvalidReceiverID, rid)
 		}
-		result = append(result, uuid)
+		if _, dup := seen[id.Bytes]; dup {
+			continue
+		}
+		seen[id.Bytes] = struct{}{}
+		result = append(result, id)
 	}
 	return result, nil
 }
 
+// distinctParticipants returns the sender followed by each receiver once. A sender listing
+// itself or a receiver twice in receiver_ids is not inserted twice.
+func distinctParticipants(sender pgtype.UUID, receivers []pgtype.UUID) []pgtype.UUID {
+	result := make([]pgtype.UUID, 0, len(receivers)+1)
+	seen := make(map[pgtype.UUID]struct{}, len(receivers)+1)
+	for _, id := range append([]pgtype.UUID{sender}, receivers...) {
+		if _, dup := seen[id]; dup {
+			continue
+		}
+		seen[id] = struct{}{}
+		result = append(result, id)
+	}
+	return result
+}
+
+// otherParticipantIDs returns the receiver_ids of an event: every participant except self, each once,
+// so a user is never delivered the same event twice
+func otherParticipantIDs(participants []pgtype.UUID, self pgtype.UUID) []string {
+	ids := make([]string, 0, len(participants))
+	seen := make(map[pgtype.UUID]struct{}, len(participants))
+	for _, p := range participants {
+		if _, dup := seen[p]; dup || p == self {
+			continue
+		}
+		seen[p] = struct{}{}
+		ids = append(ids, uuidToString(p))
+	}
+	return ids
+}
+
 // uuidToString converts pgtype.UUID to string
 func uuidToString(uuid pgtype.UUID) string {
 	if !uuid.Valid {
@@ -442,29 +929,70 @@ func (s *ChatService) GetMessages(ctx context.Context, req *chatv1.GetMessagesRe
 	}
 
 	if req.ConversationId == "" {
-		return nil, status.Error(codes.InvalidArgument, "conversation_id is required")
+		return nil, apierror.Validation("conversation_id", "conversation_id is required")
 	}
 
 	conversationUUID, err := parseUUID(req.ConversationId)
 	if err != nil {
-		return nil, status.Error(codes.InvalidArgument, "invalid conversation_id")
+		return nil, apierror.Validation("conversation_id", "invalid conversation_id")
 	}
 
 	limit := sanitizeLimit(req.Limit)
 
-	var before pgtype.Timestamptz
-	if req.BeforeTimestamp != "" {
-		beforeTs, err := parseTimestampToPgtype(req.BeforeTimestamp)
+	// cursor takes precedence; before_timestamp also accepts a next_cursor
+	// value so that existing clients keep paging correctly.
+	var before pageCursor
+	if req.Cursor != "" {
+		before, err = decodePageCursor(req.Cursor)
+		if err != nil {
+			return nil, apierror.Validation("cursor", "invalid cursor")
+		}
+	} else if req.BeforeTimestamp != "" {
+		before, err = decodePageCursor(req.BeforeTimestamp)
 		if err != nil {
-			return nil, status.Error(codes.InvalidArgument, "invalid before_timestamp, must be RFC3339")
+			return nil, apierror.Validation("before_timestamp", "invalid before_timestamp, must be RFC3339 or a next_cursor")
 		}
-		before = beforeTs
 	}
 
-	params := repository.GetMessagesParams{
+	// Extract user_id from context (set by auth middleware)
+	userID, err := getUserIDFromContext(ctx)
+	if err != nil {
+		s.logger.Error("failed to get user_id from context", zap.Error(err))
+		return nil, err
+	}
+
+	userUUID, err := parseUUID(userID)
+	if err != nil {
+		return nil, apierror.Validation("user_id", "invalid user_id")
+	}
+
+	// Only participants may read the conversation history
+	isMember, err := s.isParticipant(ctx, repository.IsParticipantParams{
 		ConversationID: conversationUUID,
-		Before:         before,
-		Limit:          limit,
+		UserID:         userUUID,
+	})
+	if err != nil {
+		s.logger.Error("failed to check conversation membership",
+			zap.Error(err),
+			zap.String("conversation_id", req.ConversationId),
+			zap.String("user_id", userID),
+		)
+		return nil, status.Error(codes.Internal, "failed to fetch messages")
+	}
+	if !isMember {
+		s.logger.Warn("user is not a participant of conversation",
+			zap.String("conversation_id", req.ConversationId),
+			zap.String("user_id", userID),
+		)
+		return nil, apierror.New(codes.PermissionDenied, apierror.CodePermissionDenied, "not a participant of this conversation", nil)
+	}
+
+	params := repository.GetMessagesParams{
+		ConversationID:  conversationUUID,
+		BeforeCreatedAt: before.Timestamp,
+		BeforeID:        before.ID,
+		Limit:           limit,
+		IncludeDeleted:  req.IncludeDeleted,
 	}
 
 	messages, err := s.getMessages(ctx, params)
@@ -476,6 +1004,40 @@ func (s *ChatService) GetMessages(ctx context.Context, req *chatv1.GetMessagesRe
 		return nil, status.Error(codes.Internal, "failed to fetch messages")
 	}
 
+	attachmentsByMessage, err := s.loadAttachments(ctx, messages)
+	if err != nil {
+		s.logger.Error("failed to fetch attachments",
+			zap.Error(err),
+			zap.String("conversation_id", req.ConversationId),
+		)
+		return nil, status.Error(codes.Internal, "failed to fetch messages")
+	}
+
+	reactionsByMessage, err := s.loadReactions(ctx, messages, userUUID)
+	if err != nil {
+		s.logger.Error("failed to fetch reactions",
+			zap.Error(err),
+			zap.String("conversation_id", req.ConversationId),
+		)
+		return nil, status.Error(codes.Internal, "failed to fetch messages")
+	}
+
+	// Delivery status is only shown on the requester's own messages
+	var receipts []repository.GetParticipantReceiptsRow
+	withStatus := sentByUser(messages, userUUID)
+	if withStatus {
+		receipts, err = s.getParticipantReceipts(ctx, conversationUUID)
+		if err != nil {
+			s.logger.Error("failed to fetch delivery receipts",
+				zap.Error(err),
+				zap.String("conversation_id", req.ConversationId),
+			)
+			return nil, status.Error(codes.Internal, "failed to fetch messages")
+		}
+	}
+
+	senders := s.resolveProfiles(ctx, distinctSenderIDs(messages))
+
 	respMessages := make([]*chatv1.ChatMessage, 0, len(messages))
 	for _, msg := range messages {
 		chatMsg := &chatv1.ChatMessage{
@@ -489,12 +1051,23 @@ func (s *ChatService) GetMessages(ctx context.Context, req *chatv1.GetMessagesRe
 		if msg.MediaUrl.Valid {
 			chatMsg.MediaUrl = msg.MediaUrl.String
 		}
+		chatMsg.Attachments = attachmentsByMessage[msg.ID]
+		chatMsg.Reactions = reactionsByMessage[msg.ID]
+		if sender, ok := senders[chatMsg.SenderId]; ok {
+			chatMsg.SenderName = sender.DisplayName
+			chatMsg.SenderAvatarUrl = sender.AvatarURL
+		}
+		if withStatus && msg.SenderID == userUUID {
+			chatMsg.Status = messageStatus(msg, receipts)
+		}
+		applyMessageRevisions(chatMsg, msg)
 		respMessages = append(respMessages, chatMsg)
 	}
 
 	nextCursor := ""
 	if len(messages) > 0 {
-		nextCursor = formatTimestamp(messages[len(messages)-1].CreatedAt)
+		last := messages[len(messages)-1]
+		nextCursor = encodePageCursor(last.CreatedAt, last.ID)
 	}
 
 	return &chatv1.GetMessagesResponse{
@@ -518,24 +1091,27 @@ func (s *ChatService) GetConversations(ctx context.Context, req *chatv1.GetConve
 
 	userUUID, err := parseUUID(userID)
 	if err != nil {
-		return nil, status.Error(codes.InvalidArgument, "invalid user_id")
+		return nil, apierror.Validation("user_id", "invalid user_id")
 	}
 
 	limit := sanitizeLimit(req.Limit)
 
-	var before pgtype.Timestamptz
+	var before pageCursor
 	if req.Cursor != "" {
-		beforeTs, err := parseTimestampToPgtype(req.Cursor)
+		before, err = decodePageCursor(req.Cursor)
 		if err != nil {
-			return nil, status.Error(codes.InvalidArgument, "invalid cursor, must be RFC3339")
+			return nil, apierror.Validation("cursor", "invalid cursor")
 		}
-		before = beforeTs
 	}
 
 	params := repository.GetConversationsForUserParams{
-		UserID:  userUUID,
-		Column2: before,
-		Limit:   limit,
+		UserID:          userUUID,
+		IncludeArchived: req.IncludeArchived,
+		OnlyArchived:    req.OnlyArchived,
+		BeforeSortAt:    before.Timestamp,
+		BeforePinned:    before.Pinned,
+		BeforeID:        before.ID,
+		Limit:           limit,
 	}
 
 	conversations, err := s.getConversationsForUser(ctx, params)
@@ -559,12 +1135,24 @@ func (s *ChatService) GetConversations(ctx context.Context, req *chatv1.GetConve
 			LastMessageContent: lastMessageContent,
 			LastMessageAt:      formatTimestamp(conv.LastMessageAt),
 			UnreadCount:        int32(conv.UnreadCount),
+			ArchivedAt:         formatTimestamp(conv.ArchivedAt),
+			MessageTtlSeconds:  int64(conv.MessageTtlSeconds.Int32),
+			Type:               conversationTypeProto(conv.Type),
+			Name:               conv.Name.String,
+			Pinned:             conv.PinnedAt.Valid,
+			PinnedAt:           formatTimestamp(conv.PinnedAt),
+			NotificationLevel:  notificationLevelProto(conv.NotificationLevel),
 		})
 	}
 
 	nextCursor := ""
 	if len(conversations) > 0 {
-		nextCursor = formatTimestamp(conversations[len(conversations)-1].LastMessageAt)
+		last := conversations[len(conversations)-1]
+		if last.PinnedAt.Valid {
+			nextCursor = encodePinnedPageCursor(last.PinnedAt, last.ID)
+		} else {
+			nextCursor = encodePageCursor(last.LastMessageAt, last.ID)
+		}
 	}
 
 	return &chatv1.GetConversationsResponse{
@@ -573,14 +1161,33 @@ func (s *ChatService) GetConversations(ctx context.Context, req *chatv1.GetConve
 	}, nil
 }
 
-// MarkAsRead marks all messages in a conversation as read for a user.
-func (s *ChatService) MarkAsRead(ctx context.Context, req *chatv1.MarkAsReadRequest) (*chatv1.MarkAsReadResponse, error) {
+// maxConversationIDsPerRequest bounds GetConversationsByIds
+const maxConversationIDsPerRequest = 100
+
+// GetConversationsByIds returns the list metadata of specific conversations in one query,
+// so clients can refresh a cached subset without paging. Ids the user does not
+// participate in (or has hidden) are silently dropped.
+func (s *ChatService) GetConversationsByIds(ctx context.Context, req *chatv1.GetConversationsByIdsRequest) (*chatv1.GetConversationsByIdsResponse, error) {
 	if req == nil {
 		return nil, status.Error(codes.InvalidArgument, "request cannot be nil")
 	}
 
-	if req.ConversationId == "" {
-		return nil, status.Error(codes.InvalidArgument, "conversation_id is required")
+	if len(req.ConversationIds) > maxConversationIDsPerRequest {
+		return nil, apierror.Validation("conversation_ids", fmt.Sprintf("at most %d conversation_ids per request", maxConversationIDsPerRequest))
+	}
+
+	conversationUUIDs := make([]pgtype.UUID, 0, len(req.ConversationIds))
+	seen := make(map[pgtype.UUID]struct{}, len(req.ConversationIds))
+	for _, id := range req.ConversationIds {
+		conversationUUID, err := parseUUID(id)
+		if err != nil {
+			return nil, apierror.Validation("conversation_ids", "invalid conversation_id: "+id)
+		}
+		if _, dup := seen[conversationUUID]; dup {
+			continue
+		}
+		seen[conversationUUID] = struct{}{}
+		conversationUUIDs = append(conversationUUIDs, conversationUUID)
 	}
 
 	// Extract user_id from context (set by auth middleware)
@@ -590,49 +1197,906 @@ func (s *ChatService) MarkAsRead(ctx context.Context, req *chatv1.MarkAsReadRequ
 		return nil, err
 	}
 
-	conversationUUID, err := parseUUID(req.ConversationId)
+	userUUID, err := parseUUID(userID)
 	if err != nil {
-		return nil, status.Error(codes.InvalidArgument, "invalid conversation_id")
+		return nil, apierror.Validation("user_id", "invalid user_id")
 	}
 
-	userUUID, err := parseUUID(userID)
-	if err != nil {
-		return nil, status.Error(codes.InvalidArgument, "invalid user_id")
+	if len(conversationUUIDs) == 0 {
+		return &chatv1.GetConversationsByIdsResponse{Conversations: []*chatv1.Conversation{}}, nil
 	}
 
-	err = s.markAsRead(ctx, repository.MarkAsReadParams{
-		ConversationID: conversationUUID,
-		UserID:         userUUID,
+	conversations, err := s.getConversationsByIDs(ctx, repository.GetConversationsByIDsParams{
+		UserID: userUUID,
+		Ids:    conversationUUIDs,
 	})
 	if err != nil {
-		s.logger.Error("failed to mark conversation as read",
+		s.logger.Error("failed to fetch conversations by ids",
 			zap.Error(err),
-			zap.String("conversation_id", req.ConversationId),
 			zap.String("user_id", userID),
+			zap.Int("requested", len(conversationUUIDs)),
 		)
-		return nil, status.Error(codes.Internal, "failed to mark conversation as read")
+		return nil, status.Error(codes.Internal, "failed to fetch conversations")
 	}
 
-	return &chatv1.MarkAsReadResponse{
-		Success: true,
-	}, nil
-}
+	respConversations := make([]*chatv1.Conversation, 0, len(conversations))
+	for _, conv := range conversations {
+		var lastMessageContent string
+		if conv.LastMessageContent.Valid {
+			lastMessageContent = conv.LastMessageContent.String
+		}
 
-func (s *ChatService) markAsRead(ctx context.Context, params repository.MarkAsReadParams) error {
-	if s.markAsReadFn != nil {
-		return s.markAsReadFn(ctx, params)
+		respConversations = append(respConversations, &chatv1.Conversation{
+			Id:                 uuidToString(conv.ID),
+			LastMessageContent: lastMessageContent,
+			LastMessageAt:      formatTimestamp(conv.LastMessageAt),
+			UnreadCount:        int32(conv.UnreadCount),
+			ArchivedAt:         formatTimestamp(conv.ArchivedAt),
+			MessageTtlSeconds:  int64(conv.MessageTtlSeconds.Int32),
+			Type:               conversationTypeProto(conv.Type),
+			Name:               conv.Name.String,
+			Pinned:             conv.PinnedAt.Valid,
+			PinnedAt:           formatTimestamp(conv.PinnedAt),
+			NotificationLevel:  notificationLevelProto(conv.NotificationLevel),
+		})
 	}
-	return s.queries.MarkAsRead(ctx, params)
+
+	return &chatv1.GetConversationsByIdsResponse{
+		Conversations: respConversations,
+	}, nil
+}
+
+// GetParticipants returns the members of a conversation the requester belongs to.
+func (s *ChatService) GetParticipants(ctx context.Context, req *chatv1.GetParticipantsRequest) (*chatv1.GetParticipantsResponse, error) {
+	if req == nil {
+		return nil, status.Error(codes.InvalidArgument, "request cannot be nil")
+	}
+
+	if req.ConversationId == "" {
+		return nil, apierror.Validation("conversation_id", "conversation_id is required")
+	}
+
+	conversationUUID, err := parseUUID(req.ConversationId)
+	if err != nil {
+		return nil, apierror.Validation("conversation_id", "invalid conversation_id")
+	}
+
+	// Extract user_id from context (set by auth middleware)
+	userID, err := getUserIDFromContext(ctx)
+	if err != nil {
+		s.logger.Error("failed to get user_id from context", zap.Error(err))
+		return nil, err
+	}
+
+	userUUID, err := parseUUID(userID)
+	if err != nil {
+		return nil, apierror.Validation("user_id", "invalid user_id")
+	}
+
+	// Only participants may see who else is in the conversation
+	isMember, err := s.isParticipant(ctx, repository.IsParticipantParams{
+		ConversationID: conversationUUID,
+		UserID:         userUUID,
+	})
+	if err != nil {
+		s.logger.Error("failed to check conversation membership",
+			zap.Error(err),
+			zap.String("conversation_id", req.ConversationId),
+			zap.String("user_id", userID),
+		)
+		return nil, status.Error(codes.Internal, "failed to fetch participants")
+	}
+	if !isMember {
+		s.logger.Warn("user is not a participant of conversation",
+			zap.String("conversation_id", req.ConversationId),
+			zap.String("user_id", userID),
+		)
+		return nil, apierror.New(codes.PermissionDenied, apierror.CodePermissionDenied, "not a participant of this conversation", nil)
+	}
+
+	participants, err := s.listParticipants(ctx, conversationUUID)
+	if err != nil {
+		s.logger.Error("failed to fetch participants",
+			zap.Error(err),
+			zap.String("conversation_id", req.ConversationId),
+		)
+		return nil, status.Error(codes.Internal, "failed to fetch participants")
+	}
+
+	respParticipants := make([]*chatv1.Participant, 0, len(participants))
+	for _, p := range participants {
+		respParticipants = append(respParticipants, &chatv1.Participant{
+			UserId:   uuidToString(p.UserID),
+			JoinedAt: formatTimestamp(p.JoinedAt),
+		})
+	}
+
+	return &chatv1.GetParticipantsResponse{
+		Participants: respParticipants,
+	}, nil
+}
+
+// DeleteConversation hides a conversation from the requester's list without
+// affecting other participants. The next message in the conversation re-surfaces it.
+func (s *ChatService) DeleteConversation(ctx context.Context, req *chatv1.DeleteConversationRequest) (*chatv1.DeleteConversationResponse, error) {
+	if req == nil {
+		return nil, status.Error(codes.InvalidArgument, "request cannot be nil")
+	}
+
+	if req.ConversationId == "" {
+		return nil, apierror.Validation("conversation_id", "conversation_id is required")
+	}
+
+	conversationUUID, err := parseUUID(req.ConversationId)
+	if err != nil {
+		return nil, apierror.Validation("conversation_id", "invalid conversation_id")
+	}
+
+	// Extract user_id from context (set by auth middleware)
+	userID, err := getUserIDFromContext(ctx)
+	if err != nil {
+		s.logger.Error("failed to get user_id from context", zap.Error(err))
+		return nil, err
+	}
+
+	userUUID, err := parseUUID(userID)
+	if err != nil {
+		return nil, apierror.Validation("user_id", "invalid user_id")
+	}
+
+	rows, err := s.hideConversation(ctx, repository.HideConversationParams{
+		ConversationID: conversationUUID,
+		UserID:         userUUID,
+	})
+	if err != nil {
+		s.logger.Error("failed to hide conversation",
+			zap.Error(err),
+			zap.String("conversation_id", req.ConversationId),
+			zap.String("user_id", userID),
+		)
+		return nil, status.Error(codes.Internal, "failed to delete conversation")
+	}
+	if rows == 0 {
+		s.logger.Warn("user is not a participant of conversation",
+			zap.String("conversation_id", req.ConversationId),
+			zap.String("user_id", userID),
+		)
+		return nil, apierror.New(codes.PermissionDenied, apierror.CodePermissionDenied, "not a participant of this conversation", nil)
+	}
+
+	s.logger.Info("conversation hidden for user",
+		zap.String("conversation_id", req.ConversationId),
+		zap.String("user_id", userID),
+	)
+
+	return &chatv1.DeleteConversationResponse{Success: true}, nil
+}
+
+// ArchiveConversation moves a conversation to the requester's archived list.
+// It keeps receiving messages; see SetKeepArchivedOnNewMessage for what a new message does.
+func (s *ChatService) ArchiveConversation(ctx context.Context, req *chatv1.ArchiveConversationRequest) (*chatv1.ArchiveConversationResponse, error) {
+	if req == nil {
+		return nil, status.Error(codes.InvalidArgument, "request cannot be nil")
+	}
+	if err := s.setArchived(ctx, req.ConversationId, true); err != nil {
+		return nil, err
+	}
+	return &chatv1.ArchiveConversationResponse{Success: true}, nil
+}
+
+// UnarchiveConversation moves an archived conversation back to the requester's main list.
+func (s *ChatService) UnarchiveConversation(ctx context.Context, req *chatv1.UnarchiveConversationRequest) (*chatv1.UnarchiveConversationResponse, error) {
+	if req == nil {
+		return nil, status.Error(codes.InvalidArgument, "request cannot be nil")
+	}
+	if err := s.setArchived(ctx, req.ConversationId, false); err != nil {
+		return nil, err
+	}
+	return &chatv1.UnarchiveConversationResponse{Success: true}, nil
+}
+
+// setArchived archives or restores a conversation for the user in ctx. Both are idempotent.
+func (s *ChatService) setArchived(ctx context.Context, conversationID string, archived bool) error {
+	if conversationID == "" {
+		return apierror.Validation("conversation_id", "conversation_id is required")
+	}
+
+	conversationUUID, err := parseUUID(conversationID)
+	if err != nil {
+		return apierror.Validation("conversation_id", "invalid conversation_id")
+	}
+
+	// Extract user_id from context (set by auth middleware)
+	userID, err := getUserIDFromContext(ctx)
+	if err != nil {
+		s.logger.Error("failed to get user_id from context", zap.Error(err))
+		return err
+	}
+
+	userUUID, err := parseUUID(userID)
+	if err != nil {
+		return apierror.Validation("user_id", "invalid user_id")
+	}
+
+	rows, err := s.setConversationArchived(ctx, repository.SetConversationArchivedParams{
+		Archived:       archived,
+		ConversationID: conversationUUID,
+		UserID:         userUUID,
+	})
+	if err != nil {
+		s.logger.Error("failed to update conversation archive state",
+			zap.Error(err),
+			zap.String("conversation_id", conversationID),
+			zap.String("user_id", userID),
+			zap.Bool("archived", archived),
+		)
+		if archived {
+			return status.Error(codes.Internal, "failed to archive conversation")
+		}
+		return status.Error(codes.Internal, "failed to unarchive conversation")
+	}
+	if rows == 0 {
+		s.logger.Warn("user is not a participant of conversation",
+			zap.String("conversation_id", conversationID),
+			zap.String("user_id", userID),
+		)
+		return apierror.New(codes.PermissionDenied, apierror.CodePermissionDenied, "not a participant of this conversation", nil)
+	}
+
+	s.logger.Info("conversation archive state updated",
+		zap.String("conversation_id", conversationID),
+		zap.String("user_id", userID),
+		zap.Bool("archived", archived),
+	)
+	return nil
+}
+
+// CreateConversation creates a typed conversation with the caller and participant_ids as members.
+// A direct conversation has exactly two participants; a group has the caller and at least one other user.
+// Unlike conversations created implicitly by SendMessage, only members can send into it.
+func (s *ChatService) CreateConversation(ctx context.Context, req *chatv1.CreateConversationRequest) (*chatv1.CreateConversationResponse, error) {
+	if req == nil {
+		return nil, status.Error(codes.InvalidArgument, "request cannot be nil")
+	}
+	convType, err := conversationTypeColumn(req.Type)
+	if err != nil {
+		return nil, apierror.Validation("type", err.Error())
+	}
+	name, err := conversationName(req.Name, convType)
+	if err != nil {
+		return nil, apierror.Validation("name", err.Error())
+	}
+	if limit := s.receiverLimit(); len(req.ParticipantIds) > limit {
+		return nil, apierror.Validation("participant_ids", fmt.Sprintf("at most %d participant_ids", limit))
+	}
+
+	// Extract user_id from context (set by auth middleware)
+	userID, err := getUserIDFromContext(ctx)
+	if err != nil {
+		s.logger.Error("failed to get user_id from context", zap.Error(err))
+		return nil, err
+	}
+	userUUID, err := parseUUID(userID)
+	if err != nil {
+		return nil, apierror.Validation("user_id", "invalid user_id")
+	}
+
+	others, err := createParticipants(req.ParticipantIds, userUUID, convType)
+	if err != nil {
+		return nil, apierror.Validation("participant_ids", err.Error())
+	}
+	if limit := s.groupSizeLimit(); len(others)+1 > limit {
+		return nil, groupTooLargeError(fmt.Errorf("%w: at most %d participants", ErrGroupTooLarge, limit))
+	}
+
+	conversation, err := s.createConversationTx(ctx, repository.CreateConversationParams{
+		Type: pgtype.Text{String: convType, Valid: true},
+		Name: name,
+	}, append([]pgtype.UUID{userUUID}, others...))
+	if err != nil {
+		s.logger.Error("failed to create conversation",
+			zap.Error(err),
+			zap.String("type", convType),
+			zap.String("user_id", userID),
+		)
+		return nil, status.Error(codes.Internal, "failed to create conversation")
+	}
+
+	conversationID := uuidToString(conversation.ID)
+	s.logger.Info("conversation created",
+		zap.String("conversation_id", conversationID),
+		zap.String("type", convType),
+		zap.String("user_id", userID),
+		zap.Int("participants", len(others)+1),
+	)
+	return &chatv1.CreateConversationResponse{
+		ConversationId: conversationID,
+		Conversation: &chatv1.Conversation{
+			Id:            conversationID,
+			LastMessageAt: formatTimestamp(conversation.LastMessageAt),
+			Type:          conversationTypeProto(conversation.Type),
+			Name:          conversation.Name.String,
+		},
+	}, nil
+}
+
+// createConversationTx inserts a typed conversation and its participants atomically
+func (s *ChatService) createConversationTx(ctx context.Context, params repository.CreateConversationParams, participants []pgtype.UUID) (repository.Conversation, error) {
+	tx, err := s.beginTx(ctx)
+	if err != nil {
+		return repository.Conversation{}, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer func() { _ = s.rollbackTx(ctx, tx) }() // Rollback if not committed
+
+	var qtx *repository.Queries
+	if pgxTx, ok := tx.(pgx.Tx); ok {
+		qtx = s.queries.WithTx(pgxTx)
+	} else {
+		qtx = repository.New(tx)
+	}
+
+	conversation, err := s.createConversation(ctx, qtx, params)
+	if err != nil {
+		return repository.Conversation{}, fmt.Errorf("failed to insert conversation: %w", err)
+	}
+	if _, err := s.addConversationParticipants(ctx, qtx, repository.AddConversationParticipantsParams{
+		ConversationID: conversation.ID,
+		Column2:        participants,
+	}); err != nil {
+		return repository.Conversation{}, fmt.Errorf("failed to add participants: %w", err)
+	}
+
+	if err := s.commitTx(ctx, tx); err != nil {
+		return repository.Conversation{}, fmt.Errorf("failed to commit transaction: %w", err)
+	}
+	return conversation, nil
+}
+
+// SetConversationRetention sets how long messages of a conversation are kept; older messages
+// are deleted by the retention purge job. A TTL of 0 keeps messages forever.
+// The setting applies to every participant, and any participant may change it.
+func (s *ChatService) SetConversationRetention(ctx context.Context, req *chatv1.SetConversationRetentionRequest) (*chatv1.SetConversationRetentionResponse, error) {
+	if req == nil {
+		return nil, status.Error(codes.InvalidArgument, "request cannot be nil")
+	}
+	if req.ConversationId == "" {
+		return nil, apierror.Validation("conversation_id", "conversation_id is required")
+	}
+	conversationUUID, err := parseUUID(req.ConversationId)
+	if err != nil {
+		return nil, apierror.Validation("conversation_id", "invalid conversation_id")
+	}
+	ttl, err := messageTTLParam(req.MessageTtlSeconds)
+	if err != nil {
+		return nil, apierror.Validation("message_ttl_seconds", err.Error())
+	}
+
+	// Extract user_id from context (set by auth middleware)
+	userID, err := getUserIDFromContext(ctx)
+	if err != nil {
+		s.logger.Error("failed to get user_id from context", zap.Error(err))
+		return nil, err
+	}
+	userUUID, err := parseUUID(userID)
+	if err != nil {
+		return nil, apierror.Validation("user_id", "invalid user_id")
+	}
+
+	// Retention deletes history for everyone, so only participants may change it
+	isMember, err := s.isParticipant(ctx, repository.IsParticipantParams{
+		ConversationID: conversationUUID,
+		UserID:         userUUID,
+	})
+	if err != nil {
+		s.logger.Error("failed to check conversation membership",
+			zap.Error(err),
+			zap.String("conversation_id", req.ConversationId),
+			zap.String("user_id", userID),
+		)
+		return nil, status.Error(codes.Internal, "failed to set conversation retention")
+	}
+	if !isMember {
+		s.logger.Warn("user is not a participant of conversation",
+			zap.String("conversation_id", req.ConversationId),
+			zap.String("user_id", userID),
+		)
+		return nil, apierror.New(codes.PermissionDenied, apierror.CodePermissionDenied, "not a participant of this conversation", nil)
+	}
+
+	rows, err := s.setConversationRetention(ctx, repository.SetConversationRetentionParams{
+		MessageTtlSeconds: ttl,
+		ID:                conversationUUID,
+	})
+	if err != nil {
+		s.logger.Error("failed to set conversation retention",
+			zap.Error(err),
+			zap.String("conversation_id", req.ConversationId),
+			zap.String("user_id", userID),
+		)
+		return nil, status.Error(codes.Internal, "failed to set conversation retention")
+	}
+	if rows == 0 {
+		return nil, apierror.New(codes.NotFound, apierror.CodeNotFound, "conversation not found", nil)
+	}
+
+	s.logger.Info("conversation retention updated",
+		zap.String("conversation_id", req.ConversationId),
+		zap.String("user_id", userID),
+		zap.Int64("message_ttl_seconds", req.MessageTtlSeconds),
+	)
+	return &chatv1.SetConversationRetentionResponse{
+		Success:           true,
+		MessageTtlSeconds: req.MessageTtlSeconds,
+	}, nil
+}
+
+// MarkAsRead marks the messages in a conversation as read for a user: all of them, or only
+// those up to up_to_message_id. The read pointer never moves backwards.
+func (s *ChatService) MarkAsRead(ctx context.Context, req *chatv1.MarkAsReadRequest) (*chatv1.MarkAsReadResponse, error) {
+	if req == nil {
+		return nil, status.Error(codes.InvalidArgument, "request cannot be nil")
+	}
+
+	if req.ConversationId == "" {
+		return nil, apierror.Validation("conversation_id", "conversation_id is required")
+	}
+
+	// Extract user_id from context (set by auth middleware)
+	userID, err := getUserIDFromContext(ctx)
+	if err != nil {
+		s.logger.Error("failed to get user_id from context", zap.Error(err))
+		return nil, err
+	}
+
+	conversationUUID, err := parseUUID(req.ConversationId)
+	if err != nil {
+		return nil, apierror.Validation("conversation_id", "invalid conversation_id")
+	}
+
+	userUUID, err := parseUUID(userID)
+	if err != nil {
+		return nil, apierror.Validation("user_id", "invalid user_id")
+	}
+
+	// Read up to the last rendered message, so one that arrived meanwhile stays unread
+	var readUpTo pgtype.Timestamptz
+	if req.UpToMessageId != "" {
+		readUpTo, err = s.readUpToMessage(ctx, conversationUUID, req.UpToMessageId)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	if err := s.markAsReadTx(ctx, conversationUUID, userUUID, readUpTo); err != nil {
+		s.logger.Error("failed to mark conversation as read",
+			zap.Error(err),
+			zap.String("conversation_id", req.ConversationId),
+			zap.String("user_id", userID),
+		)
+		return nil, status.Error(codes.Internal, "failed to mark conversation as read")
+	}
+
+	return &chatv1.MarkAsReadResponse{
+		Success: true,
+	}, nil
+}
+
+// MarkAsDelivered records that the conversation's messages so far reached one of the user's devices.
+// Senders see it as the DELIVERED status in GetMessages.
+func (s *ChatService) MarkAsDelivered(ctx context.Context, req *chatv1.MarkAsDeliveredRequest) (*chatv1.MarkAsDeliveredResponse, error) {
+	if req == nil {
+		return nil, status.Error(codes.InvalidArgument, "request cannot be nil")
+	}
+
+	if req.ConversationId == "" {
+		return nil, apierror.Validation("conversation_id", "conversation_id is required")
+	}
+
+	// Extract user_id from context (set by auth middleware)
+	userID, err := getUserIDFromContext(ctx)
+	if err != nil {
+		s.logger.Error("failed to get user_id from context", zap.Error(err))
+		return nil, err
+	}
+
+	conversationUUID, err := parseUUID(req.ConversationId)
+	if err != nil {
+		return nil, apierror.Validation("conversation_id", "invalid conversation_id")
+	}
+
+	userUUID, err := parseUUID(userID)
+	if err != nil {
+		return nil, apierror.Validation("user_id", "invalid user_id")
+	}
+
+	// Not a participant: no row is updated, keep the call a no-op like MarkAsRead
+	err = s.markAsDelivered(ctx, repository.MarkAsDeliveredParams{
+		ConversationID: conversationUUID,
+		UserID:         userUUID,
+	})
+	if err != nil {
+		s.logger.Error("failed to mark conversation as delivered",
+			zap.Error(err),
+			zap.String("conversation_id", req.ConversationId),
+			zap.String("user_id", userID),
+		)
+		return nil, status.Error(codes.Internal, "failed to mark conversation as delivered")
+	}
+
+	return &chatv1.MarkAsDeliveredResponse{
+		Success: true,
+	}, nil
+}
+
+// readUpToMessage returns the created_at of the message a MarkAsRead reads up to.
+// The message must belong to the conversation being marked.
+func (s *ChatService) readUpToMessage(ctx context.Context, conversationUUID pgtype.UUID, messageID string) (pgtype.Timestamptz, error) {
+	messageUUID, err := parseUUID(messageID)
+	if err != nil {
+		return pgtype.Timestamptz{}, apierror.Validation("up_to_message_id", "invalid up_to_message_id")
+	}
+
+	msg, err := s.getMessageByID(ctx, messageUUID)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return pgtype.Timestamptz{}, apierror.New(codes.NotFound, apierror.CodeNotFound, "message not found", nil)
+		}
+		s.logger.Error("failed to fetch message",
+			zap.Error(err),
+			zap.String("message_id", messageID),
+		)
+		return pgtype.Timestamptz{}, status.Error(codes.Internal, "failed to mark conversation as read")
+	}
+	if msg.ConversationID != conversationUUID {
+		return pgtype.Timestamptz{}, apierror.Validation("up_to_message_id", "message is not in this conversation")
+	}
+	return msg.CreatedAt, nil
+}
+
+// markAsReadTx advances last_read_at (to readUpTo when valid, otherwise to now) and, when
+// the user actually had unread messages from other participants up to that point, writes a
+// conversation.read outbox event in the same transaction so senders can render "Seen", and a
+// conversation.read_self event so the reader's other devices clear their unread badge.
+func (s *ChatService) markAsReadTx(ctx context.Context, conversationUUID, userUUID pgtype.UUID, readUpTo pgtype.Timestamptz) error {
+	tx, err := s.beginTx(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer func() { _ = s.rollbackTx(ctx, tx) }() // Rollback if not committed
+
+	var qtx *repository.Queries
+	if pgxTx, ok := tx.(pgx.Tx); ok {
+		qtx = s.queries.WithTx(pgxTx)
+	} else {
+		qtx = repository.New(tx)
+	}
+
+	// 1. Check for unread messages before moving the read pointer
+	hadUnread, err := s.hasUnreadMessages(ctx, qtx, repository.HasUnreadMessagesParams{
+		ConversationID: conversationUUID,
+		UserID:         userUUID,
+		ReadUpTo:       readUpTo,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to check unread messages: %w", err)
+	}
+
+	// 2. Update last_read_at
+	readAt, err := s.markAsRead(ctx, qtx, repository.MarkAsReadParams{
+		ConversationID: conversationUUID,
+		UserID:         userUUID,
+		ReadUpTo:       readUpTo,
+	})
+	if err != nil {
+		// Not a participant, or no message past the read pointer: nothing to mark, keep the call
+		// a no-op without writing. hadUnread implies a message to move past, so no event is lost.
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil
+		}
+		return fmt.Errorf("failed to update last_read_at: %w", err)
+	}
+
+	// 3. Emit read receipt only if something was actually read (avoid event spam)
+	if hadUnread {
+		participants, err := s.getConversationParticipants(ctx, qtx, conversationUUID)
+		if err != nil {
+			return fmt.Errorf("failed to get conversation participants: %w", err)
+		}
+
+		receiverIDs := otherParticipantIDs(participants, userUUID)
+
+		payload, err := createReadEventPayload(conversationUUID, userUUID, readAt, receiverIDs, eventOriginFromContext(ctx))
+		if err != nil {
+			return fmt.Errorf("failed to create event payload: %w", err)
+		}
+
+		err = s.insertOutbox(ctx, qtx, repository.InsertOutboxParams{
+			AggregateType: "conversation",
+			AggregateID:   conversationUUID,
+			Payload:       payload,
+		})
+		if err != nil {
+			return fmt.Errorf("failed to insert outbox: %w", err)
+		}
+
+		// 4. Sync the reader's other devices with the count left unread after the move
+		unreadCount, err := s.countUnreadMessages(ctx, qtx, repository.CountUnreadMessagesParams{
+			ConversationID: conversationUUID,
+			UserID:         userUUID,
+		})
+		if err != nil {
+			return fmt.Errorf("failed to count unread messages: %w", err)
+		}
+
+		payload, err = createReadSelfEventPayload(conversationUUID, userUUID, readAt, unreadCount, eventOriginFromContext(ctx))
+		if err != nil {
+			return fmt.Errorf("failed to create event payload: %w", err)
+		}
+
+		err = s.insertOutbox(ctx, qtx, repository.InsertOutboxParams{
+			AggregateType: "conversation",
+			AggregateID:   conversationUUID,
+			Payload:       payload,
+		})
+		if err != nil {
+			return fmt.Errorf("failed to insert outbox: %w", err)
+		}
+	}
+
+	if err = s.commitTx(ctx, tx); err != nil {
+		return fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	return nil
+}
+
+// EventTypeReadSelf is the event that syncs read state across the reader's own devices
+const EventTypeReadSelf = "conversation.read_self"
+
+// createReadSelfEventPayload creates the JSON payload for the conversation.read_self outbox event.
+// It has no receiver_ids: the gateway delivers it to the reader's devices other than origin_device_id
+// (all of them when the request named no device), and drops it when none is connected.
+// Clients set their badge to unread_count and their read pointer to read_at (never moving it back).
+func createReadSelfEventPayload(conversationID, userID pgtype.UUID, readAt pgtype.Timestamptz, unreadCount int32, origin eventOrigin) ([]byte, error) {
+	event := map[string]interface{}{
+		"event_type":      EventTypeReadSelf,
+		"conversation_id": uuidToString(conversationID),
+		"user_id":         uuidToString(userID),
+		"receiver_ids":    []string{},
+		"read_at":         formatTimestamp(readAt),
+		"unread_count":    unreadCount,
+	}
+	origin.addTo(event)
+
+	payload, err := json.Marshal(event)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal event: %w", err)
+	}
+
+	return payload, nil
+}
+
+// createReadEventPayload creates the JSON payload for the conversation.read outbox event
+func createReadEventPayload(conversationID, userID pgtype.UUID, readAt pgtype.Timestamptz, receiverIDs []string, origin eventOrigin) ([]byte, error) {
+	event := map[string]interface{}{
+		"event_type":      "conversation.read",
+		"conversation_id": uuidToString(conversationID),
+		"user_id":         uuidToString(userID),
+		"receiver_ids":    receiverIDs,
+		"read_at":         formatTimestamp(readAt),
+	}
+	origin.addTo(event)
+
+	payload, err := json.Marshal(event)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal event: %w", err)
+	}
+
+	return payload, nil
+}
+
+// markAsRead updates last_read_at, using injectable function if available
+func (s *ChatService) markAsRead(ctx context.Context, qtx *repository.Queries, params repository.MarkAsReadParams) (pgtype.Timestamptz, error) {
+	ctx, cancel := s.queryContext(ctx)
+	defer cancel()
+
+	if s.markAsReadFn != nil {
+		return s.markAsReadFn(ctx, qtx, params)
+	}
+	return qtx.MarkAsRead(ctx, params)
+}
+
+// hasUnreadMessages checks for unread messages from other participants, using injectable function if available
+func (s *ChatService) hasUnreadMessages(ctx context.Context, qtx *repository.Queries, params repository.HasUnreadMessagesParams) (bool, error) {
+	ctx, cancel := s.queryContext(ctx)
+	defer cancel()
+
+	if s.hasUnreadMessagesFn != nil {
+		return s.hasUnreadMessagesFn(ctx, qtx, params)
+	}
+	return qtx.HasUnreadMessages(ctx, params)
+}
+
+// countUnreadMessages counts the messages after the user's read pointer, using injectable function if available
+func (s *ChatService) countUnreadMessages(ctx context.Context, qtx *repository.Queries, params repository.CountUnreadMessagesParams) (int32, error) {
+	ctx, cancel := s.queryContext(ctx)
+	defer cancel()
+
+	if s.countUnreadMessagesFn != nil {
+		return s.countUnreadMessagesFn(ctx, qtx, params)
+	}
+	return qtx.CountUnreadMessages(ctx, params)
 }
 
 func (s *ChatService) getMessages(ctx context.Context, params repository.GetMessagesParams) ([]repository.Message, error) {
+	ctx, cancel := s.queryContext(ctx)
+	defer cancel()
+
 	if s.getMessagesFn != nil {
 		return s.getMessagesFn(ctx, params)
 	}
 	return s.queries.GetMessages(ctx, params)
 }
 
+// loadAttachments fetches attachments for a page of messages in a single query
+func (s *ChatService) loadAttachments(ctx context.Context, messages []repository.Message) (map[pgtype.UUID][]*chatv1.Attachment, error) {
+	if len(messages) == 0 {
+		return nil, nil
+	}
+
+	messageIDs := make([]pgtype.UUID, 0, len(messages))
+	for _, msg := range messages {
+		messageIDs = append(messageIDs, msg.ID)
+	}
+
+	rows, err := s.getAttachmentsForMessages(ctx, messageIDs)
+	if err != nil {
+		return nil, err
+	}
+
+	result := make(map[pgtype.UUID][]*chatv1.Attachment, len(rows))
+	for _, row := range rows {
+		result[row.MessageID] = append(result[row.MessageID], attachmentFromRow(row))
+	}
+	return result, nil
+}
+
+// resolveProfiles looks up sender profiles. Profiles are decoration only, so a
+// missing resolver or a lookup failure yields no profiles rather than an error.
+func (s *ChatService) resolveProfiles(ctx context.Context, userIDs []string) map[string]profile.Profile {
+	if s.profiles == nil || len(userIDs) == 0 {
+		return nil
+	}
+
+	profiles, err := s.profiles.Resolve(ctx, userIDs)
+	if err != nil {
+		s.logger.Warn("failed to resolve sender profiles",
+			zap.Error(err),
+			zap.Int("user_count", len(userIDs)),
+		)
+		return nil
+	}
+	return profiles
+}
+
+// distinctSenderIDs returns each sender of the page once, in first-seen order
+func distinctSenderIDs(messages []repository.Message) []string {
+	seen := make(map[pgtype.UUID]struct{}, len(messages))
+	ids := make([]string, 0, len(messages))
+	for _, msg := range messages {
+		if _, ok := seen[msg.SenderID]; ok {
+			continue
+		}
+		seen[msg.SenderID] = struct{}{}
+		ids = append(ids, uuidToString(msg.SenderID))
+	}
+	return ids
+}
+
+func (s *ChatService) getAttachmentsForMessages(ctx context.Context, messageIDs []pgtype.UUID) ([]repository.GetAttachmentsForMessagesRow, error) {
+	ctx, cancel := s.queryContext(ctx)
+	defer cancel()
+
+	if s.getAttachmentsForMessagesFn != nil {
+		return s.getAttachmentsForMessagesFn(ctx, messageIDs)
+	}
+	return s.queries.GetAttachmentsForMessages(ctx, messageIDs)
+}
+
+func (s *ChatService) isParticipant(ctx context.Context, params repository.IsParticipantParams) (bool, error) {
+	ctx, cancel := s.queryContext(ctx)
+	defer cancel()
+
+	if s.isParticipantFn != nil {
+		return s.isParticipantFn(ctx, params)
+	}
+	return s.queries.IsParticipant(ctx, params)
+}
+
+func (s *ChatService) listParticipants(ctx context.Context, conversationID pgtype.UUID) ([]repository.ListConversationParticipantsRow, error) {
+	ctx, cancel := s.queryContext(ctx)
+	defer cancel()
+
+	if s.listParticipantsFn != nil {
+		return s.listParticipantsFn(ctx, conversationID)
+	}
+	return s.queries.ListConversationParticipants(ctx, conversationID)
+}
+
+func (s *ChatService) getParticipantReceipts(ctx context.Context, conversationID pgtype.UUID) ([]repository.GetParticipantReceiptsRow, error) {
+	ctx, cancel := s.queryContext(ctx)
+	defer cancel()
+
+	if s.getParticipantReceiptsFn != nil {
+		return s.getParticipantReceiptsFn(ctx, conversationID)
+	}
+	return s.queries.GetParticipantReceipts(ctx, conversationID)
+}
+
+func (s *ChatService) markAsDelivered(ctx context.Context, params repository.MarkAsDeliveredParams) error {
+	ctx, cancel := s.queryContext(ctx)
+	defer cancel()
+
+	if s.markAsDeliveredFn != nil {
+		return s.markAsDeliveredFn(ctx, params)
+	}
+	return s.queries.MarkAsDelivered(ctx, params)
+}
+
+func (s *ChatService) getMessageByID(ctx context.Context, messageID pgtype.UUID) (repository.Message, error) {
+	ctx, cancel := s.queryContext(ctx)
+	defer cancel()
+
+	if s.getMessageByIDFn != nil {
+		return s.getMessageByIDFn(ctx, messageID)
+	}
+	return s.queries.GetMessageByID(ctx, messageID)
+}
+
+func (s *ChatService) setConversationArchived(ctx context.Context, params repository.SetConversationArchivedParams) (int64, error) {
+	ctx, cancel := s.queryContext(ctx)
+	defer cancel()
+
+	if s.setConversationArchivedFn != nil {
+		return s.setConversationArchivedFn(ctx, params)
+	}
+	return s.queries.SetConversationArchived(ctx, params)
+}
+
+func (s *ChatService) setConversationRetention(ctx context.Context, params repository.SetConversationRetentionParams) (int64, error) {
+	ctx, cancel := s.queryContext(ctx)
+	defer cancel()
+
+	if s.setConversationRetentionFn != nil {
+		return s.setConversationRetentionFn(ctx, params)
+	}
+	return s.queries.SetConversationRetention(ctx, params)
+}
+
+func (s *ChatService) hideConversation(ctx context.Context, params repository.HideConversationParams) (int64, error) {
+	ctx, cancel := s.queryContext(ctx)
+	defer cancel()
+
+	if s.hideConversationFn != nil {
+		return s.hideConversationFn(ctx, params)
+	}
+	return s.queries.HideConversation(ctx, params)
+}
+
+func (s *ChatService) getConversationsByIDs(ctx context.Context, params repository.GetConversationsByIDsParams) ([]repository.GetConversationsByIDsRow, error) {
+	ctx, cancel := s.queryContext(ctx)
+	defer cancel()
+
+	if s.getConversationsByIDsFn != nil {
+		return s.getConversationsByIDsFn(ctx, params)
+	}
+	return s.queries.GetConversationsByIDs(ctx, params)
+}
+
 func (s *ChatService) getConversationsForUser(ctx context.Context, params repository.GetConversationsForUserParams) ([]repository.GetConversationsForUserRow, error) {
+	ctx, cancel := s.queryContext(ctx)
+	defer cancel()
+
 	if s.getConversationsForUserFn != nil {
 		return s.getConversationsForUserFn(ctx, params)
 	}
@@ -648,15 +2112,33 @@ func (s *ChatService) beginTx(ctx context.Context) (repository.DBTX, error) {
 }
 
 // upsertConversation upserts a conversation, using injectable function if available
-func (s *ChatService) upsertConversation(ctx context.Context, qtx *repository.Queries, id pgtype.UUID) (repository.Conversation, error) {
+func (s *ChatService) upsertConversation(ctx context.Context, qtx *repository.Queries, id pgtype.UUID) (repository.UpsertConversationRow, error) {
+	ctx, cancel := s.queryContext(ctx)
+	defer cancel()
+
 	if s.upsertConversationFn != nil {
 		return s.upsertConversationFn(ctx, qtx, id)
 	}
 	return qtx.UpsertConversation(ctx, id)
 }
 
+// createConversation inserts a typed conversation, using injectable function if available
+func (s *ChatService) createConversation(ctx context.Context, qtx *repository.Queries, params repository.CreateConversationParams) (repository.Conversation, error) {
+	ctx, cancel := s.queryContext(ctx)
+	defer cancel()
+
+	if s.createConversationFn != nil {
+		return s.createConversationFn(ctx, qtx, params)
+	}
+	return qtx.CreateConversation(ctx, params)
+}
+
 // addConversationParticipants adds multiple participants to a conversation using bulk insert
-func (s *ChatService) addConversationParticipants(ctx context.Context, qtx *repository.Queries, params repository.AddConversationParticipantsParams) error {
+// It returns how many users joined
+func (s *ChatService) addConversationParticipants(ctx context.Context, qtx *repository.Queries, params repository.AddConversationParticipantsParams) (int64, error) {
+	ctx, cancel := s.queryContext(ctx)
+	defer cancel()
+
 	if s.addConversationParticipantsFn != nil {
 		return s.addConversationParticipantsFn(ctx, qtx, params)
 	}
@@ -665,6 +2147,9 @@ func (s *ChatService) addConversationParticipants(ctx context.Context, qtx *repo
 
 // insertMessage inserts a message, using injectable function if available
 func (s *ChatService) insertMessage(ctx context.Context, qtx *repository.Queries, params repository.InsertMessageParams) (repository.Message, error) {
+	ctx, cancel := s.queryContext(ctx)
+	defer cancel()
+
 	if s.insertMessageFn != nil {
 		return s.insertMessageFn(ctx, qtx, params)
 	}
@@ -673,20 +2158,77 @@ func (s *ChatService) insertMessage(ctx context.Context, qtx *repository.Queries
 
 // updateLastMessage updates the last message of a conversation, using injectable function if available
 func (s *ChatService) updateLastMessage(ctx context.Context, qtx *repository.Queries, params repository.UpdateConversationLastMessageParams) error {
+	ctx, cancel := s.queryContext(ctx)
+	defer cancel()
+
 	if s.updateLastMessageFn != nil {
 		return s.updateLastMessageFn(ctx, qtx, params)
 	}
 	return qtx.UpdateConversationLastMessage(ctx, params)
 }
 
+// insertMessageAttachments bulk inserts message attachments, using injectable function if available
+func (s *ChatService) insertMessageAttachments(ctx context.Context, qtx *repository.Queries, params repository.InsertMessageAttachmentsParams) error {
+	ctx, cancel := s.queryContext(ctx)
+	defer cancel()
+
+	if s.insertMessageAttachmentsFn != nil {
+		return s.insertMessageAttachmentsFn(ctx, qtx, params)
+	}
+	return qtx.InsertMessageAttachments(ctx, params)
+}
+
 // insertOutbox inserts an outbox event, using injectable function if available
 func (s *ChatService) insertOutbox(ctx context.Context, qtx *repository.Queries, params repository.InsertOutboxParams) error {
+	ctx, cancel := s.queryContext(ctx)
+	defer cancel()
+
 	if s.insertOutboxFn != nil {
 		return s.insertOutboxFn(ctx, qtx, params)
 	}
 	return qtx.InsertOutbox(ctx, params)
 }
 
+// insertOutboxBatch inserts several outbox events of one transaction in a single statement.
+// A single event takes the plain insertOutbox path.
+func (s *ChatService) insertOutboxBatch(ctx context.Context, qtx *repository.Queries, events []repository.InsertOutboxParams) error {
+	switch len(events) {
+	case 0:
+		return nil
+	case 1:
+		return s.insertOutbox(ctx, qtx, events[0])
+	}
+
+	params := repository.InsertOutboxBatchParams{
+		AggregateTypes: make([]string, len(events)),
+		AggregateIds:   make([]pgtype.UUID, len(events)),
+		Payloads:       make([][]byte, len(events)),
+	}
+	for i, event := range events {
+		params.AggregateTypes[i] = event.AggregateType
+		params.AggregateIds[i] = event.AggregateID
+		params.Payloads[i] = event.Payload
+	}
+
+	ctx, cancel := s.queryContext(ctx)
+	defer cancel()
+
+	var inserted int64
+	var err error
+	if s.insertOutboxBatchFn != nil {
+		inserted, err = s.insertOutboxBatchFn(ctx, qtx, params)
+	} else {
+		inserted, err = qtx.InsertOutboxBatch(ctx, params)
+	}
+	if err != nil {
+		return err
+	}
+	if inserted != int64(len(events)) {
+		return fmt.Errorf("inserted %d of %d outbox events", inserted, len(events))
+	}
+	return nil
+}
+
 // commitTx commits a transaction, using injectable function if available
 func (s *ChatService) commitTx(ctx context.Context, tx repository.DBTX) error {
 	if s.commitTxFn != nil {
@@ -713,6 +2255,9 @@ func (s *ChatService) rollbackTx(ctx context.Context, tx repository.DBTX) error
 
 // getConversationParticipants retrieves all participants of a conversation
 func (s *ChatService) getConversationParticipants(ctx context.Context, qtx *repository.Queries, conversationID pgtype.UUID) ([]pgtype.UUID, error) {
+	ctx, cancel := s.queryContext(ctx)
+	defer cancel()
+
 	if s.getConversationParticipantsFn != nil {
 		return s.getConversationParticipantsFn(ctx, qtx, conversationID)
 	}
@@ -755,11 +2300,17 @@ func formatTimestamp(ts pgtype.Timestamptz) string {
 	return ts.Time.Format(time.RFC3339Nano)
 }
 
+// requestIDFromContext retrieves the correlation id from context (set by request id middleware, "" if none)
+func requestIDFromContext(ctx context.Context) string {
+	requestID, _ := ctx.Value(ctxkeys.RequestIDKey).(string)
+	return requestID
+}
+
 // getUserIDFromContext retrieves user_id from context (set by auth middleware)
 func getUserIDFromContext(ctx context.Context) (string, error) {
 	userID, ok := ctx.Value(ctxkeys.UserIDKey).(string)
 	if !ok || userID == "" {
-		return "", status.Error(codes.Unauthenticated, "user_id not found in context")
+		return "", apierror.New(codes.Unauthenticated, apierror.CodeUnauthenticated, "user_id not found in context", nil)
 	}
 	return userID, nil
 }
@@ -0,0 +1,184 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	chatv1 "chat-service/api/chat/v1"
+	"chat-service/internal/repository"
+
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+const testArchiveConversationID = "550e8400-e29b-41d4-a716-446655440000"
+
+func TestArchiveConversation_ValidationErrors(t *testing.T) {
+	service := &ChatService{logger: zap.NewNop()}
+
+	tests := []struct {
+		name string
+		req  *chatv1.ArchiveConversationRequest
+	}{
+		{name: "nil request", req: nil},
+		{name: "empty conversation", req: &chatv1.ArchiveConversationRequest{}},
+		{name: "invalid uuid", req: &chatv1.ArchiveConversationRequest{ConversationId: "not-a-uuid"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			resp, err := service.ArchiveConversation(contextWithUserID(testReaderID), tt.req)
+			assert.Nil(t, resp)
+			assert.Equal(t, codes.InvalidArgument, status.Code(err))
+		})
+	}
+}
+
+func TestArchiveConversation_Success(t *testing.T) {
+	var captured repository.SetConversationArchivedParams
+
+	service := &ChatService{logger: zap.NewNop()}
+	service.setConversationArchivedFn = func(ctx context.Context, arg repository.SetConversationArchivedParams) (int64, error) {
+		captured = arg
+		return 1, nil
+	}
+
+	resp, err := service.ArchiveConversation(contextWithUserID(testReaderID), &chatv1.ArchiveConversationRequest{
+		ConversationId: testArchiveConversationID,
+	})
+
+	require.NoError(t, err)
+	assert.True(t, resp.Success)
+	assert.True(t, captured.Archived)
+	assert.Equal(t, mustParseUUID(t, testArchiveConversationID), captured.ConversationID)
+	assert.Equal(t, mustParseUUID(t, testReaderID), captured.UserID, "Only the requester's participant row should be archived")
+}
+
+func TestUnarchiveConversation_Success(t *testing.T) {
+	var captured repository.SetConversationArchivedParams
+
+	service := &ChatService{logger: zap.NewNop()}
+	service.setConversationArchivedFn = func(ctx context.Context, arg repository.SetConversationArchivedParams) (int64, error) {
+		captured = arg
+		return 1, nil
+	}
+
+	resp, err := service.UnarchiveConversation(contextWithUserID(testReaderID), &chatv1.UnarchiveConversationRequest{
+		ConversationId: testArchiveConversationID,
+	})
+
+	require.NoError(t, err)
+	assert.True(t, resp.Success)
+	assert.False(t, captured.Archived)
+	assert.Equal(t, mustParseUUID(t, testReaderID), captured.UserID)
+}
+
+func TestArchiveConversation_NotParticipant(t *testing.T) {
+	service := &ChatService{logger: zap.NewNop()}
+	service.setConversationArchivedFn = func(ctx context.Context, arg repository.SetConversationArchivedParams) (int64, error) {
+		return 0, nil
+	}
+
+	resp, err := service.UnarchiveConversation(contextWithUserID(testReaderID), &chatv1.UnarchiveConversationRequest{
+		ConversationId: testArchiveConversationID,
+	})
+
+	assert.Nil(t, resp)
+	assert.Equal(t, codes.PermissionDenied, status.Code(err))
+}
+
+func TestArchiveConversation_DatabaseError(t *testing.T) {
+	service := &ChatService{logger: zap.NewNop()}
+	service.setConversationArchivedFn = func(ctx context.Context, arg repository.SetConversationArchivedParams) (int64, error) {
+		return 0, errors.New("connection reset")
+	}
+
+	resp, err := service.ArchiveConversation(contextWithUserID(testReaderID), &chatv1.ArchiveConversationRequest{
+		ConversationId: testArchiveConversationID,
+	})
+
+	assert.Nil(t, resp)
+	assert.Equal(t, codes.Internal, status.Code(err))
+	assert.NotContains(t, err.Error(), "connection reset", "Internal errors should not leak details")
+}
+
+func TestGetConversations_ArchiveFilters(t *testing.T) {
+	archivedAt := time.Date(2024, 5, 1, 10, 0, 0, 0, time.UTC)
+	row := repository.GetConversationsForUserRow{
+		ID:         mustParseUUID(t, testArchiveConversationID),
+		ArchivedAt: pgtype.Timestamptz{Time: archivedAt, Valid: true},
+	}
+	row.LastMessageAt.Scan(archivedAt)
+
+	var captured repository.GetConversationsForUserParams
+	service := &ChatService{logger: zap.NewNop()}
+	service.getConversationsForUserFn = func(ctx context.Context, arg repository.GetConversationsForUserParams) ([]repository.GetConversationsForUserRow, error) {
+		captured = arg
+		return []repository.GetConversationsForUserRow{row}, nil
+	}
+
+	resp, err := service.GetConversations(contextWithUserID(testReaderID), &chatv1.GetConversationsRequest{OnlyArchived: true})
+	require.NoError(t, err)
+	assert.True(t, captured.OnlyArchived)
+	assert.False(t, captured.IncludeArchived)
+	require.Len(t, resp.Conversations, 1)
+	assert.Equal(t, archivedAt.Format(time.RFC3339Nano), resp.Conversations[0].ArchivedAt)
+
+	_, err = service.GetConversations(contextWithUserID(testReaderID), &chatv1.GetConversationsRequest{})
+	require.NoError(t, err)
+	assert.False(t, captured.IncludeArchived, "archived conversations are excluded by default")
+	assert.False(t, captured.OnlyArchived)
+}
+
+func TestSendMessage_UnarchivesOnNewMessage(t *testing.T) {
+	tests := []struct {
+		name          string
+		keepArchived  bool
+		wantUnarchive bool
+	}{
+		{name: "default unarchives", keepArchived: false, wantUnarchive: true},
+		{name: "keep archived", keepArchived: true, wantUnarchive: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockIdempotency := new(MockIdempotencyChecker)
+			mocks := newMockTransactionHelpers()
+
+			conversationID := mustParseUUID(t, testArchiveConversationID)
+			senderID := mustParseUUID(t, "660e8400-e29b-41d4-a716-446655440000")
+			messageID := mustParseUUID(t, "770e8400-e29b-41d4-a716-446655440000")
+			mocks.setupHappyPathTransaction(conversationID, senderID, messageID, "Hello")
+
+			var captured repository.UpdateConversationLastMessageParams
+			mocks.mockUpdateLastMessage = func(ctx context.Context, qtx *repository.Queries, params repository.UpdateConversationLastMessageParams) error {
+				captured = params
+				return nil
+			}
+
+			service := &ChatService{
+				idempotencyCheck: mockIdempotency,
+				logger:           zap.NewNop(),
+			}
+			service.SetKeepArchivedOnNewMessage(tt.keepArchived)
+			mocks.injectIntoService(service)
+
+			ctx := contextWithUserID(uuidToString(senderID))
+			mockIdempotency.On("Check", ctx, "key-123").Return(nil)
+
+			_, err := service.SendMessage(ctx, &chatv1.SendMessageRequest{
+				ConversationId: testArchiveConversationID,
+				Content:        "Hello",
+				IdempotencyKey: "key-123",
+			})
+			require.NoError(t, err)
+			assert.Equal(t, tt.wantUnarchive, captured.Unarchive)
+		})
+	}
+}
@@ -0,0 +1,293 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"testing"
+
+	chatv1 "chat-service/api/chat/v1"
+	"chat-service/internal/repository"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func TestValidateAttachments(t *testing.T) {
+	validImage := &chatv1.Attachment{Url: "https://cdn.example.com/a.png", MimeType: "image/png", Size: 1024, Width: 640, Height: 480}
+
+	tooMany := make([]*chatv1.Attachment, maxAttachmentsPerMessage+1)
+	for i := range tooMany {
+		tooMany[i] = validImage
+	}
+
+	tests := []struct {
+		name        string
+		attachments []*chatv1.Attachment
+		expectedErr error
+	}{
+		{name: "no attachments", attachments: nil, expectedErr: nil},
+		{name: "valid attachment", attachments: []*chatv1.Attachment{validImage}, expectedErr: nil},
+		{name: "too many attachments", attachments: tooMany, expectedErr: ErrTooManyAttachments},
+		{
+			name:        "invalid url",
+			attachments: []*chatv1.Attachment{{Url: "not-a-url", MimeType: "image/png", Size: 1}},
+			expectedErr: ErrInvalidAttachmentURL,
+		},
+		{
+			name:        "mime type not in allowlist",
+			attachments: []*chatv1.Attachment{{Url: "https://cdn.example.com/a.exe", MimeType: "application/x-msdownload", Size: 1}},
+			expectedErr: ErrUnsupportedMimeType,
+		},
+		{
+			name:        "zero size",
+			attachments: []*chatv1.Attachment{{Url: "https://cdn.example.com/a.png", MimeType: "image/png", Size: 0}},
+			expectedErr: ErrInvalidAttachmentSize,
+		},
+		{
+			name:        "negative dimensions",
+			attachments: []*chatv1.Attachment{{Url: "https://cdn.example.com/a.png", MimeType: "image/png", Size: 1, Width: -1}},
+			expectedErr: ErrInvalidAttachmentDims,
+		},
+		{
+			name: "total size over limit",
+			attachments: []*chatv1.Attachment{
+				{Url: "https://cdn.example.com/a.mp4", MimeType: "video/mp4", Size: maxAttachmentsTotalSize},
+				{Url: "https://cdn.example.com/b.png", MimeType: "image/png", Size: 1},
+			},
+			expectedErr: ErrAttachmentsTooLarge,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := validateAttachments(tt.attachments)
+			if tt.expectedErr != nil {
+				assert.ErrorIs(t, err, tt.expectedErr)
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
+
+func TestSendMessage_InvalidAttachment_ReturnsInvalidArgument(t *testing.T) {
+	service := &ChatService{logger: zap.NewNop()}
+
+	req := &chatv1.SendMessageRequest{
+		ConversationId: "550e8400-e29b-41d4-a716-446655440000",
+		Content:        "Hello",
+		IdempotencyKey: "key-123",
+		Attachments: []*chatv1.Attachment{
+			{Url: "https://cdn.example.com/a.exe", MimeType: "application/x-msdownload", Size: 1},
+		},
+	}
+
+	resp, err := service.SendMessage(contextWithUserID("660e8400-e29b-41d4-a716-446655440000"), req)
+	assert.Nil(t, resp)
+	assert.Equal(t, codes.InvalidArgument, status.Code(err))
+}
+
+func TestSendMessage_WithAttachments_StoredInTransactionAndOutbox(t *testing.T) {
+	mockIdempotency := new(MockIdempotencyChecker)
+	mocks := newMockTransactionHelpers()
+
+	conversationID := mustParseUUID(t, "550e8400-e29b-41d4-a716-446655440000")
+	senderID := mustParseUUID(t, "660e8400-e29b-41d4-a716-446655440000")
+	messageID := mustParseUUID(t, "770e8400-e29b-41d4-a716-446655440000")
+	mocks.setupHappyPathTransaction(conversationID, senderID, messageID, "See attached")
+
+	var capturedOutbox repository.InsertOutboxParams
+	mocks.mockInsertOutbox = func(ctx context.Context, qtx *repository.Queries, params repository.InsertOutboxParams) error {
+		capturedOutbox = params
+		return nil
+	}
+
+	service := &ChatService{
+		idempotencyCheck: mockIdempotency,
+		logger:           zap.NewNop(),
+	}
+	mocks.injectIntoService(service)
+
+	var capturedAttachments repository.InsertMessageAttachmentsParams
+	service.insertMessageAttachmentsFn = func(ctx context.Context, qtx *repository.Queries, params repository.InsertMessageAttachmentsParams) error {
+		capturedAttachments = params
+		return nil
+	}
+
+	ctx := contextWithUserID(uuidToString(senderID))
+	mockIdempotency.On("Check", ctx, "key-123").Return(nil)
+
+	req := &chatv1.SendMessageRequest{
+		ConversationId: uuidToString(conversationID),
+		Content:        "See attached",
+		IdempotencyKey: "key-123",
+		Attachments: []*chatv1.Attachment{
+			{Url: "https://cdn.example.com/a.png", MimeType: "image/png", Size: 1024, Width: 640, Height: 480},
+			{Url: "https://cdn.example.com/b.pdf", MimeType: "application/pdf", Size: 2048},
+		},
+	}
+
+	resp, err := service.SendMessage(ctx, req)
+	require.NoError(t, err)
+	require.NotNil(t, resp)
+
+	// Attachments inserted for the new message, in order
+	assert.Equal(t, messageID, capturedAttachments.MessageID)
+	assert.Equal(t, []string{"https://cdn.example.com/a.png", "https://cdn.example.com/b.pdf"}, capturedAttachments.Urls)
+	assert.Equal(t, []string{"image/png", "application/pdf"}, capturedAttachments.MimeTypes)
+	assert.Equal(t, []int64{1024, 2048}, capturedAttachments.Sizes)
+	assert.Equal(t, []int32{0, 1}, capturedAttachments.Positions)
+
+	// Outbox payload carries attachments
+	var payload map[string]interface{}
+	require.NoError(t, json.Unmarshal(capturedOutbox.Payload, &payload))
+	attachments, ok := payload["attachments"].([]interface{})
+	require.True(t, ok, "payload should contain attachments")
+	assert.Len(t, attachments, 2)
+	first := attachments[0].(map[string]interface{})
+	assert.Equal(t, "image/png", first["mime_type"])
+	assert.Equal(t, float64(640), first["width"])
+
+	mockIdempotency.AssertExpectations(t)
+}
+
+func TestSendMessage_InsertAttachmentsFailure_RollsBack(t *testing.T) {
+	mockIdempotency := new(MockIdempotencyChecker)
+	mocks := newMockTransactionHelpers()
+
+	conversationID := mustParseUUID(t, "550e8400-e29b-41d4-a716-446655440000")
+	senderID := mustParseUUID(t, "660e8400-e29b-41d4-a716-446655440000")
+	messageID := mustParseUUID(t, "770e8400-e29b-41d4-a716-446655440000")
+	mocks.setupHappyPathTransaction(conversationID, senderID, messageID, "See attached")
+
+	committed := false
+	mocks.mockCommitTx = func(ctx context.Context, tx repository.DBTX) error {
+		committed = true
+		return nil
+	}
+
+	service := &ChatService{
+		idempotencyCheck: mockIdempotency,
+		logger:           zap.NewNop(),
+	}
+	mocks.injectIntoService(service)
+	service.insertMessageAttachmentsFn = func(ctx context.Context, qtx *repository.Queries, params repository.InsertMessageAttachmentsParams) error {
+		return errors.New("insert failed")
+	}
+
+	ctx := contextWithUserID(uuidToString(senderID))
+	mockIdempotency.On("Check", ctx, "key-123").Return(nil)
+	mockIdempotency.On("Remove", mock.Anything, "key-123").Return(nil)
+
+	req := &chatv1.SendMessageRequest{
+		ConversationId: uuidToString(conversationID),
+		Content:        "See attached",
+		IdempotencyKey: "key-123",
+		Attachments: []*chatv1.Attachment{
+			{Url: "https://cdn.example.com/a.png", MimeType: "image/png", Size: 1024},
+		},
+	}
+
+	resp, err := service.SendMessage(ctx, req)
+	assert.Nil(t, resp)
+	assert.Equal(t, codes.Internal, status.Code(err))
+	assert.False(t, committed, "Transaction must not commit when attachments fail")
+}
+
+func TestSendMessage_AttachmentOnly(t *testing.T) {
+	mockIdempotency := new(MockIdempotencyChecker)
+	mocks := newMockTransactionHelpers()
+
+	conversationID := mustParseUUID(t, "550e8400-e29b-41d4-a716-446655440000")
+	senderID := mustParseUUID(t, "660e8400-e29b-41d4-a716-446655440000")
+	messageID := mustParseUUID(t, "770e8400-e29b-41d4-a716-446655440000")
+	mocks.setupHappyPathTransaction(conversationID, senderID, messageID, "")
+
+	var capturedLastMessage repository.UpdateConversationLastMessageParams
+	mocks.mockUpdateLastMessage = func(ctx context.Context, qtx *repository.Queries, params repository.UpdateConversationLastMessageParams) error {
+		capturedLastMessage = params
+		return nil
+	}
+	var capturedOutbox repository.InsertOutboxParams
+	mocks.mockInsertOutbox = func(ctx context.Context, qtx *repository.Queries, params repository.InsertOutboxParams) error {
+		capturedOutbox = params
+		return nil
+	}
+
+	service := &ChatService{
+		idempotencyCheck: mockIdempotency,
+		logger:           zap.NewNop(),
+	}
+	mocks.injectIntoService(service)
+	service.insertMessageAttachmentsFn = func(ctx context.Context, qtx *repository.Queries, params repository.InsertMessageAttachmentsParams) error {
+		return nil
+	}
+
+	ctx := contextWithUserID(uuidToString(senderID))
+	mockIdempotency.On("Check", ctx, "key-123").Return(nil)
+
+	req := &chatv1.SendMessageRequest{
+		ConversationId: uuidToString(conversationID),
+		IdempotencyKey: "key-123",
+		Attachments: []*chatv1.Attachment{
+			{Url: "https://cdn.example.com/a.png", MimeType: "image/png", Size: 1024},
+		},
+	}
+
+	resp, err := service.SendMessage(ctx, req)
+	require.NoError(t, err)
+	require.NotNil(t, resp)
+
+	assert.Equal(t, "[Hình ảnh]", capturedLastMessage.LastMessageContent.String)
+
+	var payload map[string]interface{}
+	require.NoError(t, json.Unmarshal(capturedOutbox.Payload, &payload))
+	assert.Equal(t, "", payload["content"])
+	assert.Equal(t, "[Hình ảnh]", payload["preview"])
+}
+
+func TestSendMessage_NoContentNoAttachments_ReturnsInvalidArgument(t *testing.T) {
+	service := &ChatService{logger: zap.NewNop()}
+
+	req := &chatv1.SendMessageRequest{
+		ConversationId: "550e8400-e29b-41d4-a716-446655440000",
+		IdempotencyKey: "key-123",
+	}
+
+	resp, err := service.SendMessage(contextWithUserID("660e8400-e29b-41d4-a716-446655440000"), req)
+	assert.Nil(t, resp)
+	assert.Equal(t, codes.InvalidArgument, status.Code(err))
+}
+
+func TestMessagePreview(t *testing.T) {
+	image := &chatv1.Attachment{MimeType: "image/png"}
+	video := &chatv1.Attachment{MimeType: "video/mp4"}
+	pdf := &chatv1.Attachment{MimeType: "application/pdf"}
+
+	tests := []struct {
+		name        string
+		content     string
+		msgType     chatv1.MessageType
+		attachments []*chatv1.Attachment
+		want        string
+	}{
+		{name: "content wins", content: "Hello", msgType: chatv1.MessageType_MESSAGE_TYPE_TEXT, attachments: []*chatv1.Attachment{image}, want: "Hello"},
+		{name: "image message", msgType: chatv1.MessageType_MESSAGE_TYPE_IMAGE, want: "[Hình ảnh]"},
+		{name: "file message", msgType: chatv1.MessageType_MESSAGE_TYPE_FILE, want: "[Tệp đính kèm]"},
+		{name: "image attachment", msgType: chatv1.MessageType_MESSAGE_TYPE_TEXT, attachments: []*chatv1.Attachment{image, pdf}, want: "[Hình ảnh]"},
+		{name: "video attachment", msgType: chatv1.MessageType_MESSAGE_TYPE_TEXT, attachments: []*chatv1.Attachment{video}, want: "[Video]"},
+		{name: "other attachment", msgType: chatv1.MessageType_MESSAGE_TYPE_TEXT, attachments: []*chatv1.Attachment{pdf}, want: "[Tệp đính kèm]"},
+		{name: "nothing", msgType: chatv1.MessageType_MESSAGE_TYPE_TEXT, want: ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, messagePreview(tt.content, tt.msgType, tt.attachments))
+		})
+	}
+}
@@ -60,13 +60,13 @@ func TestProperty_BackwardCompatibility_EmptyReceivers(t *testing.T) {
 			mocks.mockBeginTx = func(ctx context.Context) (repository.DBTX, error) {
 				return mocks.mockTx, nil
 			}
-			mocks.mockUpsertConversation = func(ctx context.Context, qtx *repository.Queries, id pgtype.UUID) (repository.Conversation, error) {
-				return repository.Conversation{ID: conversationUUID}, nil
+			mocks.mockUpsertConversation = func(ctx context.Context, qtx *repository.Queries, id pgtype.UUID) (repository.UpsertConversationRow, error) {
+				return repository.UpsertConversationRow{ID: conversationUUID}, nil
 			}
-			mocks.mockAddConversationParticipants = func(ctx context.Context, qtx *repository.Queries, params repository.AddConversationParticipantsParams) error {
+			mocks.mockAddConversationParticipants = func(ctx context.Context, qtx *repository.Queries, params repository.AddConversationParticipantsParams) (int64, error) {
 				// Capture the participants passed to the function
 				capturedParticipants = params.Column2
-				return nil
+				return 0, nil
 			}
 			mocks.mockInsertMessage = func(ctx context.Context, qtx *repository.Queries, params repository.InsertMessageParams) (repository.Message, error) {
 				msg := repository.Message{
@@ -163,12 +163,12 @@ func TestProperty_BackwardCompatibility_EmptyReceivers(t *testing.T) {
 			mocks.mockBeginTx = func(ctx context.Context) (repository.DBTX, error) {
 				return mocks.mockTx, nil
 			}
-			mocks.mockUpsertConversation = func(ctx context.Context, qtx *repository.Queries, id pgtype.UUID) (repository.Conversation, error) {
-				return repository.Conversation{ID: conversationUUID}, nil
+			mocks.mockUpsertConversation = func(ctx context.Context, qtx *repository.Queries, id pgtype.UUID) (repository.UpsertConversationRow, error) {
+				return repository.UpsertConversationRow{ID: conversationUUID}, nil
 			}
-			mocks.mockAddConversationParticipants = func(ctx context.Context, qtx *repository.Queries, params repository.AddConversationParticipantsParams) error {
+			mocks.mockAddConversationParticipants = func(ctx context.Context, qtx *repository.Queries, params repository.AddConversationParticipantsParams) (int64, error) {
 				capturedParticipants = params.Column2
-				return nil
+				return 0, nil
 			}
 			mocks.mockInsertMessage = func(ctx context.Context, qtx *repository.Queries, params repository.InsertMessageParams) (repository.Message, error) {
 				msg := repository.Message{
@@ -269,12 +269,12 @@ func TestProperty_BackwardCompatibility_EmptyReceivers(t *testing.T) {
 			mocks.mockBeginTx = func(ctx context.Context) (repository.DBTX, error) {
 				return mocks.mockTx, nil
 			}
-			mocks.mockUpsertConversation = func(ctx context.Context, qtx *repository.Queries, id pgtype.UUID) (repository.Conversation, error) {
-				return repository.Conversation{ID: conversationUUID}, nil
+			mocks.mockUpsertConversation = func(ctx context.Context, qtx *repository.Queries, id pgtype.UUID) (repository.UpsertConversationRow, error) {
+				return repository.UpsertConversationRow{ID: conversationUUID}, nil
 			}
-			mocks.mockAddConversationParticipants = func(ctx context.Context, qtx *repository.Queries, params repository.AddConversationParticipantsParams) error {
+			mocks.mockAddConversationParticipants = func(ctx context.Context, qtx *repository.Queries, params repository.AddConversationParticipantsParams) (int64, error) {
 				capturedParticipants = params.Column2
-				return nil
+				return 0, nil
 			}
 			mocks.mockInsertMessage = func(ctx context.Context, qtx *repository.Queries, params repository.InsertMessageParams) (repository.Message, error) {
 				msg := repository.Message{
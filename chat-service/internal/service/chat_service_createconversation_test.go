@@ -0,0 +1,427 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	chatv1 "chat-service/api/chat/v1"
+	"chat-service/internal/apierror"
+	"chat-service/internal/repository"
+
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+const (
+	testCreatorID     = "660e8400-e29b-41d4-a716-446655440000"
+	testParticipantID = "880e8400-e29b-41d4-a716-446655440000"
+	testOutsiderID    = "aa0e8400-e29b-41d4-a716-446655440000"
+)
+
+// createConversationCapture records what CreateConversation stored
+type createConversationCapture struct {
+	params       repository.CreateConversationParams
+	participants []pgtype.UUID
+	committed    bool
+}
+
+// newCreateConversationTestService returns a service whose conversation insert succeeds
+func newCreateConversationTestService(t *testing.T, captured *createConversationCapture) *ChatService {
+	conversationID := mustParseUUID(t, "550e8400-e29b-41d4-a716-446655440000")
+	mocks := newMockTransactionHelpers()
+	mocks.mockBeginTx = func(ctx context.Context) (repository.DBTX, error) {
+		return mocks.mockTx, nil
+	}
+	mocks.mockAddConversationParticipants = func(ctx context.Context, qtx *repository.Queries, params repository.AddConversationParticipantsParams) (int64, error) {
+		captured.participants = params.Column2
+		return int64(len(params.Column2)), nil
+	}
+	mocks.mockCommitTx = func(ctx context.Context, tx repository.DBTX) error {
+		captured.committed = true
+		return nil
+	}
+	mocks.mockRollbackTx = func(ctx context.Context, tx repository.DBTX) error {
+		return nil
+	}
+
+	service := &ChatService{logger: zap.NewNop()}
+	mocks.injectIntoService(service)
+	service.createConversationFn = func(ctx context.Context, qtx *repository.Queries, params repository.CreateConversationParams) (repository.Conversation, error) {
+		captured.params = params
+		conv := repository.Conversation{ID: conversationID, Type: params.Type, Name: params.Name}
+		conv.LastMessageAt.Scan(time.Now())
+		return conv, nil
+	}
+	return service
+}
+
+func TestCreateConversation_ValidationErrors(t *testing.T) {
+	tests := []struct {
+		name  string
+		req   *chatv1.CreateConversationRequest
+		field string
+	}{
+		{name: "nil request", req: nil},
+		{
+			name:  "missing type",
+			req:   &chatv1.CreateConversationRequest{ParticipantIds: []string{testParticipantID}},
+			field: "type",
+		},
+		{
+			name: "direct with two others",
+			req: &chatv1.CreateConversationRequest{
+				Type:           chatv1.ConversationType_CONVERSATION_TYPE_DIRECT,
+				ParticipantIds: []string{testParticipantID, testOutsiderID},
+			},
+			field: "participant_ids",
+		},
+		{
+			name: "direct with only the creator",
+			req: &chatv1.CreateConversationRequest{
+				Type:           chatv1.ConversationType_CONVERSATION_TYPE_DIRECT,
+				ParticipantIds: []string{testCreatorID},
+			},
+			field: "participant_ids",
+		},
+		{
+			name: "direct with a name",
+			req: &chatv1.CreateConversationRequest{
+				Type:           chatv1.ConversationType_CONVERSATION_TYPE_DIRECT,
+				ParticipantIds: []string{testParticipantID},
+				Name:           "us",
+			},
+			field: "name",
+		},
+		{
+			name:  "group without participants",
+			req:   &chatv1.CreateConversationRequest{Type: chatv1.ConversationType_CONVERSATION_TYPE_GROUP},
+			field: "participant_ids",
+		},
+		{
+			name: "group with invalid participant",
+			req: &chatv1.CreateConversationRequest{
+				Type:           chatv1.ConversationType_CONVERSATION_TYPE_GROUP,
+				ParticipantIds: []string{"not-a-uuid"},
+			},
+			field: "participant_ids",
+		},
+		{
+			name: "group name too long",
+			req: &chatv1.CreateConversationRequest{
+				Type:           chatv1.ConversationType_CONVERSATION_TYPE_GROUP,
+				ParticipantIds: []string{testParticipantID},
+				Name:           string(make([]rune, MaxConversationNameLength+1)),
+			},
+			field: "name",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var captured createConversationCapture
+			service := newCreateConversationTestService(t, &captured)
+
+			resp, err := service.CreateConversation(contextWithUserID(testCreatorID), tt.req)
+			assert.Nil(t, resp)
+			assert.Equal(t, codes.InvalidArgument, status.Code(err))
+			assert.False(t, captured.committed)
+		})
+	}
+}
+
+func TestCreateConversation_Success(t *testing.T) {
+	tests := []struct {
+		name             string
+		req              *chatv1.CreateConversationRequest
+		wantType         string
+		wantName         pgtype.Text
+		wantParticipants []string
+	}{
+		{
+			name: "direct",
+			req: &chatv1.CreateConversationRequest{
+				Type:           chatv1.ConversationType_CONVERSATION_TYPE_DIRECT,
+				ParticipantIds: []string{testParticipantID},
+			},
+			wantType:         "direct",
+			wantParticipants: []string{testCreatorID, testParticipantID},
+		},
+		{
+			name: "direct listing the creator too",
+			req: &chatv1.CreateConversationRequest{
+				Type:           chatv1.ConversationType_CONVERSATION_TYPE_DIRECT,
+				ParticipantIds: []string{testCreatorID, testParticipantID, testParticipantID},
+			},
+			wantType:         "direct",
+			wantParticipants: []string{testCreatorID, testParticipantID},
+		},
+		{
+			name: "named group",
+			req: &chatv1.CreateConversationRequest{
+				Type:           chatv1.ConversationType_CONVERSATION_TYPE_GROUP,
+				ParticipantIds: []string{testParticipantID, testOutsiderID},
+				Name:           "  Weekend trip  ",
+			},
+			wantType:         "group",
+			wantName:         pgtype.Text{String: "Weekend trip", Valid: true},
+			wantParticipants: []string{testCreatorID, testParticipantID, testOutsiderID},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var captured createConversationCapture
+			service := newCreateConversationTestService(t, &captured)
+
+			resp, err := service.CreateConversation(contextWithUserID(testCreatorID), tt.req)
+			require.NoError(t, err)
+			assert.Equal(t, "550e8400-e29b-41d4-a716-446655440000", resp.ConversationId)
+			assert.Equal(t, resp.ConversationId, resp.Conversation.Id)
+			assert.Equal(t, tt.req.Type, resp.Conversation.Type)
+			assert.NotEmpty(t, resp.Conversation.LastMessageAt)
+
+			assert.Equal(t, pgtype.Text{String: tt.wantType, Valid: true}, captured.params.Type)
+			assert.Equal(t, tt.wantName, captured.params.Name)
+			assert.Equal(t, tt.wantName.String, resp.Conversation.Name)
+			participants := make([]string, len(captured.participants))
+			for i, p := range captured.participants {
+				participants[i] = uuidToString(p)
+			}
+			assert.Equal(t, tt.wantParticipants, participants)
+			assert.True(t, captured.committed)
+		})
+	}
+}
+
+func TestCreateConversation_InsertError(t *testing.T) {
+	var captured createConversationCapture
+	service := newCreateConversationTestService(t, &captured)
+	service.createConversationFn = func(ctx context.Context, qtx *repository.Queries, params repository.CreateConversationParams) (repository.Conversation, error) {
+		return repository.Conversation{}, errors.New("db down")
+	}
+
+	_, err := service.CreateConversation(contextWithUserID(testCreatorID), &chatv1.CreateConversationRequest{
+		Type:           chatv1.ConversationType_CONVERSATION_TYPE_DIRECT,
+		ParticipantIds: []string{testParticipantID},
+	})
+	assert.Equal(t, codes.Internal, status.Code(err))
+	assert.False(t, captured.committed)
+}
+
+func TestSendMessage_TypedConversationRules(t *testing.T) {
+	tests := []struct {
+		name      string
+		convType  string
+		senderID  string
+		receivers []string
+		wantCode  codes.Code
+	}{
+		{name: "direct member", convType: "direct", senderID: testCreatorID, receivers: []string{testParticipantID}, wantCode: codes.OK},
+		{name: "direct outsider", convType: "direct", senderID: testOutsiderID, wantCode: codes.PermissionDenied},
+		{name: "direct third receiver", convType: "direct", senderID: testCreatorID, receivers: []string{testOutsiderID}, wantCode: codes.InvalidArgument},
+		{name: "group adds receiver", convType: "group", senderID: testCreatorID, receivers: []string{testOutsiderID}, wantCode: codes.OK},
+		{name: "group outsider", convType: "group", senderID: testOutsiderID, wantCode: codes.PermissionDenied},
+		{name: "untyped outsider joins", senderID: testOutsiderID, wantCode: codes.OK},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			conversationID := mustParseUUID(t, "550e8400-e29b-41d4-a716-446655440000")
+			senderID := mustParseUUID(t, tt.senderID)
+			messageID := mustParseUUID(t, "770e8400-e29b-41d4-a716-446655440000")
+			creatorID := mustParseUUID(t, testCreatorID)
+			participantID := mustParseUUID(t, testParticipantID)
+
+			mocks := newMockTransactionHelpers()
+			mocks.setupHappyPathTransaction(conversationID, senderID, messageID, "hi")
+			mocks.mockUpsertConversation = func(ctx context.Context, qtx *repository.Queries, id pgtype.UUID) (repository.UpsertConversationRow, error) {
+				return repository.UpsertConversationRow{ID: conversationID, Type: pgtype.Text{String: tt.convType, Valid: tt.convType != ""}}, nil
+			}
+			mocks.mockGetConversationParticipants = func(ctx context.Context, qtx *repository.Queries, convID pgtype.UUID) ([]pgtype.UUID, error) {
+				return []pgtype.UUID{creatorID, participantID}, nil
+			}
+			joined := false
+			mocks.mockAddConversationParticipants = func(ctx context.Context, qtx *repository.Queries, params repository.AddConversationParticipantsParams) (int64, error) {
+				joined = true
+				return 0, nil
+			}
+
+			mockIdempotency := new(MockIdempotencyChecker)
+			service := &ChatService{idempotencyCheck: mockIdempotency, logger: zap.NewNop()}
+			mocks.injectIntoService(service)
+
+			ctx := contextWithUserID(tt.senderID)
+			mockIdempotency.On("Check", ctx, "key-123").Return(nil)
+			if tt.wantCode != codes.OK {
+				mockIdempotency.On("Remove", mock.Anything, "key-123").Return(nil)
+			}
+
+			_, err := service.SendMessage(ctx, &chatv1.SendMessageRequest{
+				ConversationId: uuidToString(conversationID),
+				Content:        "hi",
+				IdempotencyKey: "key-123",
+				ReceiverIds:    tt.receivers,
+			})
+			assert.Equal(t, tt.wantCode, status.Code(err))
+			// A rejected sender never becomes a participant
+			assert.Equal(t, tt.wantCode == codes.OK, joined)
+			mockIdempotency.AssertExpectations(t)
+		})
+	}
+}
+
+func TestSendMessage_StrictParticipants(t *testing.T) {
+	tests := []struct {
+		name     string
+		strict   bool
+		inserted bool
+		members  []string
+		senderID string
+		wantCode codes.Code
+	}{
+		{name: "lenient outsider joins group", members: []string{testCreatorID, testParticipantID}, senderID: testOutsiderID, wantCode: codes.OK},
+		{name: "strict member", strict: true, members: []string{testCreatorID, testParticipantID}, senderID: testCreatorID, wantCode: codes.OK},
+		{name: "strict outsider of group", strict: true, members: []string{testCreatorID, testParticipantID}, senderID: testOutsiderID, wantCode: codes.PermissionDenied},
+		{name: "strict self-join of direct", strict: true, members: []string{testCreatorID}, senderID: testParticipantID, wantCode: codes.OK},
+		{name: "strict new conversation", strict: true, inserted: true, senderID: testOutsiderID, wantCode: codes.OK},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			conversationID := mustParseUUID(t, "550e8400-e29b-41d4-a716-446655440000")
+			senderID := mustParseUUID(t, tt.senderID)
+			messageID := mustParseUUID(t, "770e8400-e29b-41d4-a716-446655440000")
+
+			mocks := newMockTransactionHelpers()
+			mocks.setupHappyPathTransaction(conversationID, senderID, messageID, "hi")
+			mocks.mockUpsertConversation = func(ctx context.Context, qtx *repository.Queries, id pgtype.UUID) (repository.UpsertConversationRow, error) {
+				return repository.UpsertConversationRow{ID: conversationID, Inserted: tt.inserted}, nil
+			}
+			mocks.mockGetConversationParticipants = func(ctx context.Context, qtx *repository.Queries, convID pgtype.UUID) ([]pgtype.UUID, error) {
+				members := make([]pgtype.UUID, len(tt.members))
+				for i, m := range tt.members {
+					members[i] = mustParseUUID(t, m)
+				}
+				return members, nil
+			}
+			joined := false
+			mocks.mockAddConversationParticipants = func(ctx context.Context, qtx *repository.Queries, params repository.AddConversationParticipantsParams) (int64, error) {
+				joined = true
+				return 1, nil
+			}
+
+			mockIdempotency := new(MockIdempotencyChecker)
+			service := &ChatService{idempotencyCheck: mockIdempotency, logger: zap.NewNop()}
+			service.SetStrictParticipants(tt.strict)
+			mocks.injectIntoService(service)
+
+			ctx := contextWithUserID(tt.senderID)
+			mockIdempotency.On("Check", ctx, "key-123").Return(nil)
+			if tt.wantCode != codes.OK {
+				mockIdempotency.On("Remove", mock.Anything, "key-123").Return(nil)
+			}
+
+			_, err := service.SendMessage(ctx, &chatv1.SendMessageRequest{
+				ConversationId: uuidToString(conversationID),
+				Content:        "hi",
+				IdempotencyKey: "key-123",
+			})
+			assert.Equal(t, tt.wantCode, status.Code(err))
+			assert.Equal(t, tt.wantCode == codes.OK, joined, "a rejected sender never becomes a participant")
+			mockIdempotency.AssertExpectations(t)
+		})
+	}
+}
+
+func TestCreateConversation_MaxGroupSize(t *testing.T) {
+	var captured createConversationCapture
+	service := newCreateConversationTestService(t, &captured)
+	service.SetMaxGroupSize(3)
+	ctx := contextWithUserID(testCreatorID)
+
+	_, err := service.CreateConversation(ctx, &chatv1.CreateConversationRequest{
+		Type:           chatv1.ConversationType_CONVERSATION_TYPE_GROUP,
+		ParticipantIds: []string{testParticipantID, testOutsiderID, "bb0e8400-e29b-41d4-a716-446655440000"},
+	})
+	require.Equal(t, codes.FailedPrecondition, status.Code(err))
+	assert.Equal(t, apierror.CodeGroupTooLarge, apierror.Code(status.Convert(err)))
+	assert.False(t, captured.committed)
+
+	// The creator listing itself does not count twice
+	_, err = service.CreateConversation(ctx, &chatv1.CreateConversationRequest{
+		Type:           chatv1.ConversationType_CONVERSATION_TYPE_GROUP,
+		ParticipantIds: []string{testCreatorID, testParticipantID, testOutsiderID},
+	})
+	require.NoError(t, err)
+	assert.Len(t, captured.participants, 3)
+}
+
+func TestSendMessage_MaxGroupSize(t *testing.T) {
+	tests := []struct {
+		name     string
+		members  int   // participants after the insert
+		joined   int64 // participants the insert added
+		wantCode codes.Code
+	}{
+		{name: "joins up to the limit", members: 3, joined: 1, wantCode: codes.OK},
+		{name: "join past the limit", members: 4, joined: 1, wantCode: codes.FailedPrecondition},
+		{name: "oversized group without joins", members: 5, wantCode: codes.OK},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			conversationID := mustParseUUID(t, "550e8400-e29b-41d4-a716-446655440000")
+			senderID := mustParseUUID(t, testCreatorID)
+			messageID := mustParseUUID(t, "770e8400-e29b-41d4-a716-446655440000")
+
+			mocks := newMockTransactionHelpers()
+			mocks.setupHappyPathTransaction(conversationID, senderID, messageID, "hi")
+			mocks.mockAddConversationParticipants = func(ctx context.Context, qtx *repository.Queries, params repository.AddConversationParticipantsParams) (int64, error) {
+				return tt.joined, nil
+			}
+			mocks.mockGetConversationParticipants = func(ctx context.Context, qtx *repository.Queries, convID pgtype.UUID) ([]pgtype.UUID, error) {
+				members := []pgtype.UUID{senderID}
+				for _, id := range receiverIDs(tt.members - 1) {
+					members = append(members, mustParseUUID(t, id))
+				}
+				return members, nil
+			}
+			committed := false
+			mocks.mockCommitTx = func(ctx context.Context, tx repository.DBTX) error {
+				committed = true
+				return nil
+			}
+
+			mockIdempotency := new(MockIdempotencyChecker)
+			service := &ChatService{idempotencyCheck: mockIdempotency, logger: zap.NewNop()}
+			service.SetMaxGroupSize(3)
+			mocks.injectIntoService(service)
+
+			ctx := contextWithUserID(testCreatorID)
+			mockIdempotency.On("Check", ctx, "key-123").Return(nil)
+			if tt.wantCode != codes.OK {
+				mockIdempotency.On("Remove", mock.Anything, "key-123").Return(nil)
+			}
+
+			_, err := service.SendMessage(ctx, &chatv1.SendMessageRequest{
+				ConversationId: uuidToString(conversationID),
+				Content:        "hi",
+				IdempotencyKey: "key-123",
+				ReceiverIds:    []string{testOutsiderID},
+			})
+			assert.Equal(t, tt.wantCode, status.Code(err))
+			assert.Equal(t, tt.wantCode == codes.OK, committed)
+			if tt.wantCode != codes.OK {
+				assert.Equal(t, apierror.CodeGroupTooLarge, apierror.Code(status.Convert(err)))
+			}
+			mockIdempotency.AssertExpectations(t)
+		})
+	}
+}
@@ -0,0 +1,97 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	chatv1 "chat-service/api/chat/v1"
+	"chat-service/internal/repository"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+const testDeleteConversationID = "550e8400-e29b-41d4-a716-446655440000"
+
+func TestDeleteConversation_ValidationErrors(t *testing.T) {
+	service := &ChatService{logger: zap.NewNop()}
+
+	tests := []struct {
+		name string
+		req  *chatv1.DeleteConversationRequest
+	}{
+		{name: "nil request", req: nil},
+		{name: "empty conversation", req: &chatv1.DeleteConversationRequest{}},
+		{name: "invalid uuid", req: &chatv1.DeleteConversationRequest{ConversationId: "not-a-uuid"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			resp, err := service.DeleteConversation(contextWithUserID(testReaderID), tt.req)
+			assert.Nil(t, resp)
+			assert.Equal(t, codes.InvalidArgument, status.Code(err))
+		})
+	}
+}
+
+func TestDeleteConversation_Success(t *testing.T) {
+	var captured repository.HideConversationParams
+
+	service := &ChatService{logger: zap.NewNop()}
+	service.hideConversationFn = func(ctx context.Context, arg repository.HideConversationParams) (int64, error) {
+		captured = arg
+		return 1, nil
+	}
+
+	resp, err := service.DeleteConversation(contextWithUserID(testReaderID), &chatv1.DeleteConversationRequest{
+		ConversationId: testDeleteConversationID,
+	})
+
+	require.NoError(t, err)
+	assert.True(t, resp.Success)
+	assert.Equal(t, mustParseUUID(t, testDeleteConversationID), captured.ConversationID)
+	assert.Equal(t, mustParseUUID(t, testReaderID), captured.UserID, "Only the requester's participant row should be hidden")
+}
+
+func TestDeleteConversation_MissingUserID(t *testing.T) {
+	service := &ChatService{logger: zap.NewNop()}
+
+	resp, err := service.DeleteConversation(context.Background(), &chatv1.DeleteConversationRequest{
+		ConversationId: testDeleteConversationID,
+	})
+
+	assert.Nil(t, resp)
+	assert.Equal(t, codes.Unauthenticated, status.Code(err))
+}
+
+func TestDeleteConversation_NotParticipant(t *testing.T) {
+	service := &ChatService{logger: zap.NewNop()}
+	service.hideConversationFn = func(ctx context.Context, arg repository.HideConversationParams) (int64, error) {
+		return 0, nil
+	}
+
+	resp, err := service.DeleteConversation(contextWithUserID(testReaderID), &chatv1.DeleteConversationRequest{
+		ConversationId: testDeleteConversationID,
+	})
+
+	assert.Nil(t, resp)
+	assert.Equal(t, codes.PermissionDenied, status.Code(err))
+}
+
+func TestDeleteConversation_DBError(t *testing.T) {
+	service := &ChatService{logger: zap.NewNop()}
+	service.hideConversationFn = func(ctx context.Context, arg repository.HideConversationParams) (int64, error) {
+		return 0, errors.New("db error")
+	}
+
+	resp, err := service.DeleteConversation(contextWithUserID(testReaderID), &chatv1.DeleteConversationRequest{
+		ConversationId: testDeleteConversationID,
+	})
+
+	assert.Nil(t, resp)
+	assert.Equal(t, codes.Internal, status.Code(err))
+}
@@ -47,7 +47,7 @@ func TestGetConversations_HappyPath_DefaultParameters(t *testing.T) {
 	service.getConversationsForUserFn = func(ctx context.Context, arg repository.GetConversationsForUserParams) ([]repository.GetConversationsForUserRow, error) {
 		// Verify default parameters
 		assert.Equal(t, defaultMessagesLimit, arg.Limit, "Should use default limit")
-		assert.False(t, arg.Column2.Valid, "Cursor should not be set for default parameters")
+		assert.False(t, arg.BeforeSortAt.Valid, "Cursor should not be set for default parameters")
 		
 		return []repository.GetConversationsForUserRow{conv1, conv2}, nil
 	}
@@ -101,8 +101,8 @@ func TestGetConversations_HappyPath_WithCursorPagination(t *testing.T) {
 	
 	service.getConversationsForUserFn = func(ctx context.Context, arg repository.GetConversationsForUserParams) ([]repository.GetConversationsForUserRow, error) {
 		// Verify cursor is passed correctly
-		assert.True(t, arg.Column2.Valid, "Cursor should be set")
-		assert.Equal(t, cursorTime.Unix(), arg.Column2.Time.Unix(), "Cursor timestamp should match")
+		assert.True(t, arg.BeforeSortAt.Valid, "Cursor should be set")
+		assert.Equal(t, cursorTime.Unix(), arg.BeforeSortAt.Time.Unix(), "Cursor timestamp should match")
 		
 		return []repository.GetConversationsForUserRow{conv}, nil
 	}
@@ -121,6 +121,38 @@ func TestGetConversations_HappyPath_WithCursorPagination(t *testing.T) {
 	assert.NotEmpty(t, resp.NextCursor)
 }
 
+func TestGetConversations_CompositeCursorRoundTrip(t *testing.T) {
+	// Two conversations share last_message_at; the cursor must carry the id
+	// of the last row so the next page resumes strictly after it.
+	ts := time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC)
+	conv1 := repository.GetConversationsForUserRow{ID: mustParseUUID(t, "550e8400-e29b-41d4-a716-446655440002")}
+	conv1.LastMessageAt.Scan(ts)
+	conv2 := repository.GetConversationsForUserRow{ID: mustParseUUID(t, "550e8400-e29b-41d4-a716-446655440001")}
+	conv2.LastMessageAt.Scan(ts)
+
+	var calls []repository.GetConversationsForUserParams
+	service := &ChatService{logger: zap.NewNop()}
+	service.getConversationsForUserFn = func(ctx context.Context, arg repository.GetConversationsForUserParams) ([]repository.GetConversationsForUserRow, error) {
+		calls = append(calls, arg)
+		if len(calls) == 1 {
+			return []repository.GetConversationsForUserRow{conv1, conv2}, nil
+		}
+		return nil, nil
+	}
+
+	ctx := contextWithUserID("660e8400-e29b-41d4-a716-446655440000")
+	resp, err := service.GetConversations(ctx, &chatv1.GetConversationsRequest{Limit: 2})
+	assert.NoError(t, err)
+	assert.NotEmpty(t, resp.NextCursor)
+
+	_, err = service.GetConversations(ctx, &chatv1.GetConversationsRequest{Limit: 2, Cursor: resp.NextCursor})
+	assert.NoError(t, err)
+
+	assert.Len(t, calls, 2)
+	assert.True(t, ts.Equal(calls[1].BeforeSortAt.Time))
+	assert.Equal(t, conv2.ID, calls[1].BeforeID, "Cursor should carry the id of the last conversation")
+}
+
 func TestGetConversations_HappyPath_WithCustomLimit(t *testing.T) {
 	logger := zap.NewNop()
 	
@@ -0,0 +1,99 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	chatv1 "chat-service/api/chat/v1"
+	"chat-service/internal/repository"
+
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+const (
+	testBatchUserID = "660e8400-e29b-41d4-a716-446655440000"
+	testBatchConvA  = "550e8400-e29b-41d4-a716-446655440001"
+	testBatchConvB  = "550e8400-e29b-41d4-a716-446655440002"
+)
+
+func TestGetConversationsByIds_Success(t *testing.T) {
+	var captured repository.GetConversationsByIDsParams
+	service := &ChatService{logger: zap.NewNop()}
+	service.getConversationsByIDsFn = func(ctx context.Context, arg repository.GetConversationsByIDsParams) ([]repository.GetConversationsByIDsRow, error) {
+		captured = arg
+		// The user only participates in conversation A
+		return []repository.GetConversationsByIDsRow{{
+			ID:                 mustParseUUID(t, testBatchConvA),
+			LastMessageContent: pgtype.Text{String: "Hello", Valid: true},
+			LastMessageAt:      mustTimestamptz(t, time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC)),
+			UnreadCount:        2,
+		}}, nil
+	}
+
+	resp, err := service.GetConversationsByIds(contextWithUserID(testBatchUserID), &chatv1.GetConversationsByIdsRequest{
+		ConversationIds: []string{testBatchConvA, testBatchConvB, testBatchConvA},
+	})
+	require.NoError(t, err)
+
+	assert.Equal(t, mustParseUUID(t, testBatchUserID), captured.UserID)
+	assert.Equal(t, []pgtype.UUID{mustParseUUID(t, testBatchConvA), mustParseUUID(t, testBatchConvB)}, captured.Ids, "ids are deduplicated")
+
+	require.Len(t, resp.Conversations, 1, "conversations the user is not in are dropped")
+	conv := resp.Conversations[0]
+	assert.Equal(t, testBatchConvA, conv.Id)
+	assert.Equal(t, "Hello", conv.LastMessageContent)
+	assert.Equal(t, "2025-01-01T12:00:00Z", conv.LastMessageAt)
+	assert.Equal(t, int32(2), conv.UnreadCount)
+}
+
+func TestGetConversationsByIds_EmptyRequestSkipsQuery(t *testing.T) {
+	service := &ChatService{logger: zap.NewNop()}
+	service.getConversationsByIDsFn = func(ctx context.Context, arg repository.GetConversationsByIDsParams) ([]repository.GetConversationsByIDsRow, error) {
+		t.Fatal("query should not run without ids")
+		return nil, nil
+	}
+
+	resp, err := service.GetConversationsByIds(contextWithUserID(testBatchUserID), &chatv1.GetConversationsByIdsRequest{})
+	require.NoError(t, err)
+	assert.Empty(t, resp.Conversations)
+}
+
+func TestGetConversationsByIds_Errors(t *testing.T) {
+	tooMany := make([]string, maxConversationIDsPerRequest+1)
+	for i := range tooMany {
+		tooMany[i] = testBatchConvA
+	}
+
+	service := &ChatService{logger: zap.NewNop()}
+	service.getConversationsByIDsFn = func(ctx context.Context, arg repository.GetConversationsByIDsParams) ([]repository.GetConversationsByIDsRow, error) {
+		return nil, errors.New("database unavailable")
+	}
+
+	tests := []struct {
+		name    string
+		ctx     context.Context
+		req     *chatv1.GetConversationsByIdsRequest
+		errCode codes.Code
+	}{
+		{name: "nil request", ctx: contextWithUserID(testBatchUserID), req: nil, errCode: codes.InvalidArgument},
+		{name: "too many ids", ctx: contextWithUserID(testBatchUserID), req: &chatv1.GetConversationsByIdsRequest{ConversationIds: tooMany}, errCode: codes.InvalidArgument},
+		{name: "invalid uuid", ctx: contextWithUserID(testBatchUserID), req: &chatv1.GetConversationsByIdsRequest{ConversationIds: []string{"not-a-uuid"}}, errCode: codes.InvalidArgument},
+		{name: "unauthenticated", ctx: context.Background(), req: &chatv1.GetConversationsByIdsRequest{ConversationIds: []string{testBatchConvA}}, errCode: codes.Unauthenticated},
+		{name: "database error", ctx: contextWithUserID(testBatchUserID), req: &chatv1.GetConversationsByIdsRequest{ConversationIds: []string{testBatchConvA}}, errCode: codes.Internal},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			resp, err := service.GetConversationsByIds(tt.ctx, tt.req)
+			assert.Nil(t, resp)
+			assert.Equal(t, tt.errCode, status.Code(err))
+		})
+	}
+}
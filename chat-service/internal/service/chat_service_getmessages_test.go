@@ -8,13 +8,32 @@ import (
 
 	chatv1 "chat-service/api/chat/v1"
 	"chat-service/internal/repository"
+	"chat-service/pkg/profile"
 
+	"github.com/jackc/pgx/v5/pgtype"
 	"github.com/stretchr/testify/assert"
 	"go.uber.org/zap"
 	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/status"
 )
 
+const testReaderID = "990e8400-e29b-41d4-a716-446655440000"
+
+// allowParticipant is an isParticipantFn stub that treats every user as a member
+func allowParticipant(ctx context.Context, arg repository.IsParticipantParams) (bool, error) {
+	return true, nil
+}
+
+// noAttachments is a getAttachmentsForMessagesFn stub for messages without attachments
+func noAttachments(ctx context.Context, messageIDs []pgtype.UUID) ([]repository.GetAttachmentsForMessagesRow, error) {
+	return nil, nil
+}
+
+// noReactions is a getReactionsForMessagesFn stub for messages without reactions
+func noReactions(ctx context.Context, arg repository.GetReactionsForMessagesParams) ([]repository.GetReactionsForMessagesRow, error) {
+	return nil, nil
+}
+
 func TestGetMessages_ValidationErrors(t *testing.T) {
 	service := &ChatService{logger: zap.NewNop()}
 
@@ -50,6 +69,14 @@ func TestGetMessages_ValidationErrors(t *testing.T) {
 			},
 			errCode: codes.InvalidArgument,
 		},
+		{
+			name: "invalid cursor",
+			req: &chatv1.GetMessagesRequest{
+				ConversationId: "550e8400-e29b-41d4-a716-446655440000",
+				Cursor:         "invalid",
+			},
+			errCode: codes.InvalidArgument,
+		},
 	}
 
 	for _, tt := range tests {
@@ -67,12 +94,15 @@ func TestGetMessages_Success(t *testing.T) {
 	ts := time.Now().UTC()
 
 	service := &ChatService{
-		logger: logger,
+		logger:                      logger,
+		isParticipantFn:             allowParticipant,
+		getAttachmentsForMessagesFn: noAttachments,
+		getReactionsForMessagesFn:   noReactions,
 	}
 
 	service.getMessagesFn = func(ctx context.Context, arg repository.GetMessagesParams) ([]repository.Message, error) {
 		assert.Equal(t, defaultMessagesLimit, arg.Limit)
-		assert.False(t, arg.Before.Valid, "Before timestamp should not be set")
+		assert.False(t, arg.BeforeCreatedAt.Valid, "Before timestamp should not be set")
 
 		message := repository.Message{
 			ID:             mustParseUUID(t, "550e8400-e29b-41d4-a716-446655440000"),
@@ -89,7 +119,7 @@ func TestGetMessages_Success(t *testing.T) {
 		ConversationId: "660e8400-e29b-41d4-a716-446655440000",
 	}
 
-	resp, err := service.GetMessages(context.Background(), req)
+	resp, err := service.GetMessages(contextWithUserID(testReaderID), req)
 	assert.NoError(t, err)
 	assert.Len(t, resp.Messages, 1)
 	assert.Equal(t, "Hello world", resp.Messages[0].Content)
@@ -102,7 +132,10 @@ func TestGetMessages_WithBeforeTimestampAndLimit(t *testing.T) {
 	var capturedParams repository.GetMessagesParams
 
 	service := &ChatService{
-		logger: logger,
+		logger:                      logger,
+		isParticipantFn:             allowParticipant,
+		getAttachmentsForMessagesFn: noAttachments,
+		getReactionsForMessagesFn:   noReactions,
 	}
 
 	service.getMessagesFn = func(ctx context.Context, arg repository.GetMessagesParams) ([]repository.Message, error) {
@@ -116,18 +149,71 @@ func TestGetMessages_WithBeforeTimestampAndLimit(t *testing.T) {
 		BeforeTimestamp: "2025-01-02T15:04:05Z",
 	}
 
-	resp, err := service.GetMessages(context.Background(), req)
+	resp, err := service.GetMessages(contextWithUserID(testReaderID), req)
 	assert.NoError(t, err)
 	assert.NotNil(t, resp)
 	assert.Equal(t, maxMessagesLimit, capturedParams.Limit)
-	assert.NotNil(t, capturedParams.Before)
+	assert.True(t, capturedParams.BeforeCreatedAt.Valid)
+	assert.Equal(t, pgtype.UUID{Valid: true}, capturedParams.BeforeID, "Plain timestamps should compare against the nil UUID")
+}
+
+func TestGetMessages_WithCompositeCursor(t *testing.T) {
+	var capturedParams repository.GetMessagesParams
+
+	service := &ChatService{
+		logger:                      zap.NewNop(),
+		isParticipantFn:             allowParticipant,
+		getAttachmentsForMessagesFn: noAttachments,
+		getReactionsForMessagesFn:   noReactions,
+	}
+	service.getMessagesFn = func(ctx context.Context, arg repository.GetMessagesParams) ([]repository.Message, error) {
+		capturedParams = arg
+		return []repository.Message{}, nil
+	}
+
+	cursorTime := time.Date(2025, 1, 2, 15, 4, 5, 0, time.UTC)
+	cursorID := mustParseUUID(t, "550e8400-e29b-41d4-a716-446655440007")
+	cursor := encodePageCursor(mustTimestamptz(t, cursorTime), cursorID)
+
+	tests := []struct {
+		name string
+		req  *chatv1.GetMessagesRequest
+	}{
+		{
+			name: "cursor field",
+			req: &chatv1.GetMessagesRequest{
+				ConversationId:  "550e8400-e29b-41d4-a716-446655440000",
+				Cursor:          cursor,
+				BeforeTimestamp: "2020-01-01T00:00:00Z",
+			},
+		},
+		{
+			name: "next_cursor passed as before_timestamp",
+			req: &chatv1.GetMessagesRequest{
+				ConversationId:  "550e8400-e29b-41d4-a716-446655440000",
+				BeforeTimestamp: cursor,
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := service.GetMessages(contextWithUserID(testReaderID), tt.req)
+			assert.NoError(t, err)
+			assert.True(t, cursorTime.Equal(capturedParams.BeforeCreatedAt.Time))
+			assert.Equal(t, cursorID, capturedParams.BeforeID)
+		})
+	}
 }
 
 func TestGetMessages_EmptyResultSet(t *testing.T) {
 	logger := zap.NewNop()
 
 	service := &ChatService{
-		logger: logger,
+		logger:                      logger,
+		isParticipantFn:             allowParticipant,
+		getAttachmentsForMessagesFn: noAttachments,
+		getReactionsForMessagesFn:   noReactions,
 	}
 
 	service.getMessagesFn = func(ctx context.Context, arg repository.GetMessagesParams) ([]repository.Message, error) {
@@ -139,7 +225,7 @@ func TestGetMessages_EmptyResultSet(t *testing.T) {
 		ConversationId: "550e8400-e29b-41d4-a716-446655440000",
 	}
 
-	resp, err := service.GetMessages(context.Background(), req)
+	resp, err := service.GetMessages(contextWithUserID(testReaderID), req)
 	assert.NoError(t, err)
 	assert.NotNil(t, resp)
 	assert.Empty(t, resp.Messages, "Messages should be empty")
@@ -153,7 +239,10 @@ func TestGetMessages_PaginationCursorGeneration(t *testing.T) {
 	ts3 := time.Date(2025, 1, 1, 12, 2, 0, 0, time.UTC)
 
 	service := &ChatService{
-		logger: logger,
+		logger:                      logger,
+		isParticipantFn:             allowParticipant,
+		getAttachmentsForMessagesFn: noAttachments,
+		getReactionsForMessagesFn:   noReactions,
 	}
 
 	service.getMessagesFn = func(ctx context.Context, arg repository.GetMessagesParams) ([]repository.Message, error) {
@@ -188,7 +277,7 @@ func TestGetMessages_PaginationCursorGeneration(t *testing.T) {
 		ConversationId: "660e8400-e29b-41d4-a716-446655440000",
 	}
 
-	resp, err := service.GetMessages(context.Background(), req)
+	resp, err := service.GetMessages(contextWithUserID(testReaderID), req)
 	assert.NoError(t, err)
 	assert.NotNil(t, resp)
 	assert.Len(t, resp.Messages, 3, "Should return 3 messages")
@@ -207,10 +296,12 @@ func TestGetMessages_PaginationCursorGeneration(t *testing.T) {
 		assert.NotEmpty(t, msg.CreatedAt, "Message %d should have CreatedAt", i)
 	}
 	
-	// Verify pagination cursor is set to the last message's timestamp
+	// Verify pagination cursor points at the last message's (created_at, id)
 	assert.NotEmpty(t, resp.NextCursor, "NextCursor should be set")
-	expectedCursor := formatTimestamp(mustTimestamptz(t, ts3))
-	assert.Equal(t, expectedCursor, resp.NextCursor, "NextCursor should be the timestamp of the last message")
+	cursor, err := decodePageCursor(resp.NextCursor)
+	assert.NoError(t, err)
+	assert.Equal(t, formatTimestamp(mustTimestamptz(t, ts3)), formatTimestamp(cursor.Timestamp))
+	assert.Equal(t, "550e8400-e29b-41d4-a716-446655440003", uuidToString(cursor.ID), "NextCursor should carry the id of the last message")
 }
 
 func TestGetMessages_LimitSanitization(t *testing.T) {
@@ -243,7 +334,10 @@ func TestGetMessages_LimitSanitization(t *testing.T) {
 			var capturedLimit int32
 
 			service := &ChatService{
-				logger: logger,
+				logger:                      logger,
+				isParticipantFn:             allowParticipant,
+				getAttachmentsForMessagesFn: noAttachments,
+				getReactionsForMessagesFn:   noReactions,
 			}
 
 			service.getMessagesFn = func(ctx context.Context, arg repository.GetMessagesParams) ([]repository.Message, error) {
@@ -256,7 +350,7 @@ func TestGetMessages_LimitSanitization(t *testing.T) {
 				Limit:          tt.requestLimit,
 			}
 
-			resp, err := service.GetMessages(context.Background(), req)
+			resp, err := service.GetMessages(contextWithUserID(testReaderID), req)
 			assert.NoError(t, err)
 			assert.NotNil(t, resp)
 			assert.Equal(t, tt.expectedLimit, capturedLimit, "limit should be sanitized to %d", tt.expectedLimit)
@@ -268,7 +362,10 @@ func TestGetMessages_DBError(t *testing.T) {
 	logger := zap.NewNop()
 
 	service := &ChatService{
-		logger: logger,
+		logger:                      logger,
+		isParticipantFn:             allowParticipant,
+		getAttachmentsForMessagesFn: noAttachments,
+		getReactionsForMessagesFn:   noReactions,
 	}
 
 	service.getMessagesFn = func(ctx context.Context, arg repository.GetMessagesParams) ([]repository.Message, error) {
@@ -279,7 +376,7 @@ func TestGetMessages_DBError(t *testing.T) {
 		ConversationId: "550e8400-e29b-41d4-a716-446655440000",
 	}
 
-	resp, err := service.GetMessages(context.Background(), req)
+	resp, err := service.GetMessages(contextWithUserID(testReaderID), req)
 	assert.Nil(t, resp)
 	assert.Equal(t, codes.Internal, status.Code(err))
 }
@@ -288,7 +385,10 @@ func TestGetMessages_ContextCancellation(t *testing.T) {
 	logger := zap.NewNop()
 
 	service := &ChatService{
-		logger: logger,
+		logger:                      logger,
+		isParticipantFn:             allowParticipant,
+		getAttachmentsForMessagesFn: noAttachments,
+		getReactionsForMessagesFn:   noReactions,
 	}
 
 	service.getMessagesFn = func(ctx context.Context, arg repository.GetMessagesParams) ([]repository.Message, error) {
@@ -296,7 +396,7 @@ func TestGetMessages_ContextCancellation(t *testing.T) {
 	}
 
 	// Create a cancelled context
-	ctx, cancel := context.WithCancel(context.Background())
+	ctx, cancel := context.WithCancel(contextWithUserID(testReaderID))
 	cancel() // Cancel immediately
 
 	req := &chatv1.GetMessagesRequest{
@@ -309,3 +409,248 @@ func TestGetMessages_ContextCancellation(t *testing.T) {
 	assert.Error(t, err)
 	assert.Equal(t, codes.Internal, status.Code(err))
 }
+
+func TestGetMessages_Unauthenticated(t *testing.T) {
+	service := &ChatService{
+		logger:          zap.NewNop(),
+		isParticipantFn: allowParticipant,
+	}
+
+	req := &chatv1.GetMessagesRequest{
+		ConversationId: "550e8400-e29b-41d4-a716-446655440000",
+	}
+
+	resp, err := service.GetMessages(context.Background(), req)
+	assert.Nil(t, resp)
+	assert.Equal(t, codes.Unauthenticated, status.Code(err))
+}
+
+func TestGetMessages_NotParticipant(t *testing.T) {
+	var capturedParams repository.IsParticipantParams
+	getMessagesCalled := false
+
+	service := &ChatService{logger: zap.NewNop()}
+	service.isParticipantFn = func(ctx context.Context, arg repository.IsParticipantParams) (bool, error) {
+		capturedParams = arg
+		return false, nil
+	}
+	service.getMessagesFn = func(ctx context.Context, arg repository.GetMessagesParams) ([]repository.Message, error) {
+		getMessagesCalled = true
+		return nil, nil
+	}
+
+	req := &chatv1.GetMessagesRequest{
+		ConversationId: "550e8400-e29b-41d4-a716-446655440000",
+	}
+
+	resp, err := service.GetMessages(contextWithUserID(testReaderID), req)
+	assert.Nil(t, resp)
+	assert.Equal(t, codes.PermissionDenied, status.Code(err))
+	assert.False(t, getMessagesCalled, "Messages must not be fetched for non-participants")
+	assert.Equal(t, mustParseUUID(t, "550e8400-e29b-41d4-a716-446655440000"), capturedParams.ConversationID)
+	assert.Equal(t, mustParseUUID(t, testReaderID), capturedParams.UserID)
+}
+
+func TestGetMessages_MembershipCheckError(t *testing.T) {
+	service := &ChatService{logger: zap.NewNop()}
+	service.isParticipantFn = func(ctx context.Context, arg repository.IsParticipantParams) (bool, error) {
+		return false, errors.New("db error")
+	}
+
+	req := &chatv1.GetMessagesRequest{
+		ConversationId: "550e8400-e29b-41d4-a716-446655440000",
+	}
+
+	resp, err := service.GetMessages(contextWithUserID(testReaderID), req)
+	assert.Nil(t, resp)
+	assert.Equal(t, codes.Internal, status.Code(err))
+}
+
+func TestGetMessages_WithAttachments(t *testing.T) {
+	messageID := mustParseUUID(t, "550e8400-e29b-41d4-a716-446655440001")
+	var capturedIDs []pgtype.UUID
+
+	service := &ChatService{
+		logger:                    zap.NewNop(),
+		isParticipantFn:           allowParticipant,
+		getReactionsForMessagesFn: noReactions,
+	}
+	service.getMessagesFn = func(ctx context.Context, arg repository.GetMessagesParams) ([]repository.Message, error) {
+		msg := repository.Message{
+			ID:             messageID,
+			ConversationID: mustParseUUID(t, "660e8400-e29b-41d4-a716-446655440000"),
+			SenderID:       mustParseUUID(t, "770e8400-e29b-41d4-a716-446655440000"),
+			Content:        "See attached",
+			CreatedAt:      mustTimestamptz(t, time.Now()),
+		}
+		return []repository.Message{msg}, nil
+	}
+	service.getAttachmentsForMessagesFn = func(ctx context.Context, messageIDs []pgtype.UUID) ([]repository.GetAttachmentsForMessagesRow, error) {
+		capturedIDs = messageIDs
+		return []repository.GetAttachmentsForMessagesRow{
+			{MessageID: messageID, Url: "https://cdn.example.com/a.png", MimeType: "image/png", SizeBytes: 1024, Width: pgtype.Int4{Int32: 640, Valid: true}, Height: pgtype.Int4{Int32: 480, Valid: true}},
+			{MessageID: messageID, Url: "https://cdn.example.com/b.pdf", MimeType: "application/pdf", SizeBytes: 2048},
+		}, nil
+	}
+
+	req := &chatv1.GetMessagesRequest{
+		ConversationId: "660e8400-e29b-41d4-a716-446655440000",
+	}
+
+	resp, err := service.GetMessages(contextWithUserID(testReaderID), req)
+	assert.NoError(t, err)
+	assert.Equal(t, []pgtype.UUID{messageID}, capturedIDs, "Attachments should be loaded in one query for the page")
+	assert.Len(t, resp.Messages, 1)
+	assert.Len(t, resp.Messages[0].Attachments, 2)
+	assert.Equal(t, "image/png", resp.Messages[0].Attachments[0].MimeType)
+	assert.Equal(t, int32(640), resp.Messages[0].Attachments[0].Width)
+	assert.Equal(t, "application/pdf", resp.Messages[0].Attachments[1].MimeType)
+	assert.Equal(t, int32(0), resp.Messages[0].Attachments[1].Width)
+}
+
+// stubProfileResolver returns fixed profiles and records the requested ids
+type stubProfileResolver struct {
+	profiles  map[string]profile.Profile
+	err       error
+	requested []string
+}
+
+func (r *stubProfileResolver) Resolve(ctx context.Context, userIDs []string) (map[string]profile.Profile, error) {
+	r.requested = append(r.requested, userIDs...)
+	return r.profiles, r.err
+}
+
+func TestGetMessages_SenderProfiles(t *testing.T) {
+	const (
+		alice = "770e8400-e29b-41d4-a716-446655440000"
+		bob   = "770e8400-e29b-41d4-a716-446655440001"
+	)
+
+	service := &ChatService{
+		logger:                      zap.NewNop(),
+		isParticipantFn:             allowParticipant,
+		getAttachmentsForMessagesFn: noAttachments,
+		getReactionsForMessagesFn:   noReactions,
+	}
+	service.getMessagesFn = func(ctx context.Context, arg repository.GetMessagesParams) ([]repository.Message, error) {
+		return []repository.Message{
+			{ID: mustParseUUID(t, "550e8400-e29b-41d4-a716-446655440001"), SenderID: mustParseUUID(t, alice), Content: "one"},
+			{ID: mustParseUUID(t, "550e8400-e29b-41d4-a716-446655440002"), SenderID: mustParseUUID(t, bob), Content: "two"},
+			{ID: mustParseUUID(t, "550e8400-e29b-41d4-a716-446655440003"), SenderID: mustParseUUID(t, alice), Content: "three"},
+		}, nil
+	}
+
+	resolver := &stubProfileResolver{profiles: map[string]profile.Profile{
+		alice: {DisplayName: "Alice", AvatarURL: "https://cdn.example.com/alice.png"},
+	}}
+	service.SetProfileResolver(resolver)
+
+	resp, err := service.GetMessages(contextWithUserID(testReaderID), &chatv1.GetMessagesRequest{
+		ConversationId: "660e8400-e29b-41d4-a716-446655440000",
+	})
+	assert.NoError(t, err)
+
+	assert.Equal(t, []string{alice, bob}, resolver.requested, "Each sender should be resolved once per page")
+	assert.Equal(t, "Alice", resp.Messages[0].SenderName)
+	assert.Equal(t, "https://cdn.example.com/alice.png", resp.Messages[0].SenderAvatarUrl)
+	assert.Empty(t, resp.Messages[1].SenderName, "Unknown senders have no profile")
+	assert.Equal(t, "Alice", resp.Messages[2].SenderName)
+}
+
+func TestGetMessages_SenderProfileLookupFailure(t *testing.T) {
+	service := &ChatService{
+		logger:                      zap.NewNop(),
+		isParticipantFn:             allowParticipant,
+		getAttachmentsForMessagesFn: noAttachments,
+		getReactionsForMessagesFn:   noReactions,
+	}
+	service.getMessagesFn = func(ctx context.Context, arg repository.GetMessagesParams) ([]repository.Message, error) {
+		return []repository.Message{
+			{ID: mustParseUUID(t, "550e8400-e29b-41d4-a716-446655440001"), SenderID: mustParseUUID(t, "770e8400-e29b-41d4-a716-446655440000"), Content: "one"},
+		}, nil
+	}
+	service.SetProfileResolver(&stubProfileResolver{err: errors.New("redis down")})
+
+	resp, err := service.GetMessages(contextWithUserID(testReaderID), &chatv1.GetMessagesRequest{
+		ConversationId: "660e8400-e29b-41d4-a716-446655440000",
+	})
+
+	assert.NoError(t, err, "Profile lookup failures must not fail the read")
+	assert.Len(t, resp.Messages, 1)
+	assert.Empty(t, resp.Messages[0].SenderName)
+}
+
+func TestGetMessages_IncludeDeletedTombstones(t *testing.T) {
+	ts := time.Now().UTC()
+	liveID := mustParseUUID(t, "550e8400-e29b-41d4-a716-446655440001")
+	editedID := mustParseUUID(t, "550e8400-e29b-41d4-a716-446655440002")
+	deletedID := mustParseUUID(t, "550e8400-e29b-41d4-a716-446655440003")
+
+	history := func() []repository.Message {
+		live := repository.Message{ID: liveID, Content: "still here", Type: "TEXT"}
+		edited := repository.Message{ID: editedID, Content: "fixed typo", Type: "TEXT"}
+		edited.EditedAt.Scan(ts)
+		deleted := repository.Message{
+			ID:       deletedID,
+			Content:  "regret",
+			Type:     "IMAGE",
+			MediaUrl: pgtype.Text{String: "https://cdn.example.com/a.png", Valid: true},
+		}
+		deleted.DeletedAt.Scan(ts)
+		return []repository.Message{live, edited, deleted}
+	}
+
+	service := &ChatService{
+		logger:          zap.NewNop(),
+		isParticipantFn: allowParticipant,
+		getAttachmentsForMessagesFn: func(ctx context.Context, messageIDs []pgtype.UUID) ([]repository.GetAttachmentsForMessagesRow, error) {
+			return []repository.GetAttachmentsForMessagesRow{{MessageID: deletedID, Url: "https://cdn.example.com/a.png", MimeType: "image/png"}}, nil
+		},
+		getReactionsForMessagesFn: func(ctx context.Context, arg repository.GetReactionsForMessagesParams) ([]repository.GetReactionsForMessagesRow, error) {
+			return []repository.GetReactionsForMessagesRow{{MessageID: deletedID, Emoji: "👍", Count: 2}}, nil
+		},
+	}
+	service.getMessagesFn = func(ctx context.Context, arg repository.GetMessagesParams) ([]repository.Message, error) {
+		messages := history()
+		if !arg.IncludeDeleted {
+			return messages[:2], nil // the query leaves deleted messages out
+		}
+		return messages, nil
+	}
+
+	t.Run("default omits deleted", func(t *testing.T) {
+		resp, err := service.GetMessages(contextWithUserID(testReaderID), &chatv1.GetMessagesRequest{
+			ConversationId: "660e8400-e29b-41d4-a716-446655440000",
+		})
+		assert.NoError(t, err)
+		assert.Len(t, resp.Messages, 2)
+		for _, msg := range resp.Messages {
+			assert.False(t, msg.Deleted)
+		}
+	})
+
+	t.Run("include_deleted returns tombstones", func(t *testing.T) {
+		resp, err := service.GetMessages(contextWithUserID(testReaderID), &chatv1.GetMessagesRequest{
+			ConversationId: "660e8400-e29b-41d4-a716-446655440000",
+			IncludeDeleted: true,
+		})
+		assert.NoError(t, err)
+		assert.Len(t, resp.Messages, 3)
+
+		live, edited, tombstone := resp.Messages[0], resp.Messages[1], resp.Messages[2]
+		assert.False(t, live.Edited)
+		assert.Empty(t, live.EditedAt)
+
+		assert.True(t, edited.Edited)
+		assert.Equal(t, formatTimestamp(pgtype.Timestamptz{Time: ts, Valid: true}), edited.EditedAt)
+		assert.Equal(t, "fixed typo", edited.Content)
+
+		assert.True(t, tombstone.Deleted)
+		assert.Equal(t, uuidToString(deletedID), tombstone.Id)
+		assert.Equal(t, chatv1.MessageType_MESSAGE_TYPE_IMAGE, tombstone.Type)
+		assert.Empty(t, tombstone.Content)
+		assert.Empty(t, tombstone.MediaUrl)
+		assert.Empty(t, tombstone.Attachments)
+		assert.Empty(t, tombstone.Reactions)
+	})
+}
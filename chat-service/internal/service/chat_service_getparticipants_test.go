@@ -0,0 +1,131 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	chatv1 "chat-service/api/chat/v1"
+	"chat-service/internal/repository"
+
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+const testParticipantsConversationID = "550e8400-e29b-41d4-a716-446655440000"
+
+func TestGetParticipants_ValidationErrors(t *testing.T) {
+	service := &ChatService{logger: zap.NewNop()}
+
+	tests := []struct {
+		name string
+		req  *chatv1.GetParticipantsRequest
+	}{
+		{name: "nil request", req: nil},
+		{name: "empty conversation", req: &chatv1.GetParticipantsRequest{}},
+		{name: "invalid uuid", req: &chatv1.GetParticipantsRequest{ConversationId: "not-a-uuid"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			resp, err := service.GetParticipants(contextWithUserID(testReaderID), tt.req)
+			assert.Nil(t, resp)
+			assert.Equal(t, codes.InvalidArgument, status.Code(err))
+		})
+	}
+}
+
+func TestGetParticipants_Success(t *testing.T) {
+	joinedAt := time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC)
+	var capturedConversationID pgtype.UUID
+
+	service := &ChatService{
+		logger:          zap.NewNop(),
+		isParticipantFn: allowParticipant,
+	}
+	service.listParticipantsFn = func(ctx context.Context, conversationID pgtype.UUID) ([]repository.ListConversationParticipantsRow, error) {
+		capturedConversationID = conversationID
+		return []repository.ListConversationParticipantsRow{
+			{UserID: mustParseUUID(t, testReaderID), JoinedAt: mustTimestamptz(t, joinedAt)},
+			{UserID: mustParseUUID(t, "880e8400-e29b-41d4-a716-446655440000"), JoinedAt: mustTimestamptz(t, joinedAt.Add(time.Hour))},
+		}, nil
+	}
+
+	resp, err := service.GetParticipants(contextWithUserID(testReaderID), &chatv1.GetParticipantsRequest{
+		ConversationId: testParticipantsConversationID,
+	})
+
+	require.NoError(t, err)
+	assert.Equal(t, mustParseUUID(t, testParticipantsConversationID), capturedConversationID)
+	require.Len(t, resp.Participants, 2)
+	assert.Equal(t, testReaderID, resp.Participants[0].UserId)
+	assert.Equal(t, joinedAt.Format(time.RFC3339Nano), resp.Participants[0].JoinedAt)
+	assert.Equal(t, "880e8400-e29b-41d4-a716-446655440000", resp.Participants[1].UserId)
+	assert.Equal(t, joinedAt.Add(time.Hour).Format(time.RFC3339Nano), resp.Participants[1].JoinedAt)
+}
+
+func TestGetParticipants_MissingUserID(t *testing.T) {
+	service := &ChatService{logger: zap.NewNop()}
+
+	resp, err := service.GetParticipants(context.Background(), &chatv1.GetParticipantsRequest{
+		ConversationId: testParticipantsConversationID,
+	})
+
+	assert.Nil(t, resp)
+	assert.Equal(t, codes.Unauthenticated, status.Code(err))
+}
+
+func TestGetParticipants_NotParticipant(t *testing.T) {
+	listCalled := false
+
+	service := &ChatService{logger: zap.NewNop()}
+	service.isParticipantFn = func(ctx context.Context, arg repository.IsParticipantParams) (bool, error) {
+		return false, nil
+	}
+	service.listParticipantsFn = func(ctx context.Context, conversationID pgtype.UUID) ([]repository.ListConversationParticipantsRow, error) {
+		listCalled = true
+		return nil, nil
+	}
+
+	resp, err := service.GetParticipants(contextWithUserID(testReaderID), &chatv1.GetParticipantsRequest{
+		ConversationId: testParticipantsConversationID,
+	})
+
+	assert.Nil(t, resp)
+	assert.Equal(t, codes.PermissionDenied, status.Code(err))
+	assert.False(t, listCalled, "Participants must not be listed for non-members")
+}
+
+func TestGetParticipants_RepositoryErrors(t *testing.T) {
+	t.Run("membership check", func(t *testing.T) {
+		service := &ChatService{logger: zap.NewNop()}
+		service.isParticipantFn = func(ctx context.Context, arg repository.IsParticipantParams) (bool, error) {
+			return false, errors.New("db error")
+		}
+
+		_, err := service.GetParticipants(contextWithUserID(testReaderID), &chatv1.GetParticipantsRequest{
+			ConversationId: testParticipantsConversationID,
+		})
+		assert.Equal(t, codes.Internal, status.Code(err))
+	})
+
+	t.Run("list participants", func(t *testing.T) {
+		service := &ChatService{
+			logger:          zap.NewNop(),
+			isParticipantFn: allowParticipant,
+		}
+		service.listParticipantsFn = func(ctx context.Context, conversationID pgtype.UUID) ([]repository.ListConversationParticipantsRow, error) {
+			return nil, errors.New("db error")
+		}
+
+		_, err := service.GetParticipants(contextWithUserID(testReaderID), &chatv1.GetParticipantsRequest{
+			ConversationId: testParticipantsConversationID,
+		})
+		assert.Equal(t, codes.Internal, status.Code(err))
+	})
+}
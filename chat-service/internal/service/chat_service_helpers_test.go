@@ -55,6 +55,11 @@ func (m *MockIdempotencyChecker) Remove(ctx context.Context, key string) error {
 	return args.Error(0)
 }
 
+func (m *MockIdempotencyChecker) TTL(ctx context.Context, key string) (time.Duration, error) {
+	args := m.Called(ctx, key)
+	return args.Get(0).(time.Duration), args.Error(1)
+}
+
 // ============================================================================
 // TEST HELPER FUNCTIONS
 // ============================================================================
@@ -198,8 +203,8 @@ func (m *mockDBTX) Rollback(ctx context.Context) error {
 type mockTransactionHelpers struct {
 	mockTx                           *mockDBTX
 	mockBeginTx                      func(ctx context.Context) (repository.DBTX, error)
-	mockUpsertConversation           func(ctx context.Context, qtx *repository.Queries, id pgtype.UUID) (repository.Conversation, error)
-	mockAddConversationParticipants  func(ctx context.Context, qtx *repository.Queries, params repository.AddConversationParticipantsParams) error
+	mockUpsertConversation           func(ctx context.Context, qtx *repository.Queries, id pgtype.UUID) (repository.UpsertConversationRow, error)
+	mockAddConversationParticipants  func(ctx context.Context, qtx *repository.Queries, params repository.AddConversationParticipantsParams) (int64, error)
 	mockInsertMessage                func(ctx context.Context, qtx *repository.Queries, params repository.InsertMessageParams) (repository.Message, error)
 	mockUpdateLastMessage            func(ctx context.Context, qtx *repository.Queries, params repository.UpdateConversationLastMessageParams) error
 	mockGetConversationParticipants  func(ctx context.Context, qtx *repository.Queries, conversationID pgtype.UUID) ([]pgtype.UUID, error)
@@ -240,13 +245,13 @@ func (m *mockTransactionHelpers) setupHappyPathTransaction(
 		return m.mockTx, nil
 	}
 
-	m.mockUpsertConversation = func(ctx context.Context, qtx *repository.Queries, id pgtype.UUID) (repository.Conversation, error) {
-		return repository.Conversation{ID: conversationID}, nil
+	m.mockUpsertConversation = func(ctx context.Context, qtx *repository.Queries, id pgtype.UUID) (repository.UpsertConversationRow, error) {
+		return repository.UpsertConversationRow{ID: conversationID}, nil
 	}
 
 	// Use bulk insert for participants (sender + receivers)
-	m.mockAddConversationParticipants = func(ctx context.Context, qtx *repository.Queries, params repository.AddConversationParticipantsParams) error {
-		return nil
+	m.mockAddConversationParticipants = func(ctx context.Context, qtx *repository.Queries, params repository.AddConversationParticipantsParams) (int64, error) {
+		return 0, nil
 	}
 
 	m.mockInsertMessage = func(ctx context.Context, qtx *repository.Queries, params repository.InsertMessageParams) (repository.Message, error) {
@@ -308,8 +313,8 @@ func (m *mockTransactionHelpers) setupUpsertConversationError(err error) {
 		return m.mockTx, nil
 	}
 
-	m.mockUpsertConversation = func(ctx context.Context, qtx *repository.Queries, id pgtype.UUID) (repository.Conversation, error) {
-		return repository.Conversation{}, err
+	m.mockUpsertConversation = func(ctx context.Context, qtx *repository.Queries, id pgtype.UUID) (repository.UpsertConversationRow, error) {
+		return repository.UpsertConversationRow{}, err
 	}
 
 	m.mockRollbackTx = func(ctx context.Context, tx repository.DBTX) error {
@@ -323,12 +328,12 @@ func (m *mockTransactionHelpers) setupAddConversationParticipantsError(conversat
 		return m.mockTx, nil
 	}
 
-	m.mockUpsertConversation = func(ctx context.Context, qtx *repository.Queries, id pgtype.UUID) (repository.Conversation, error) {
-		return repository.Conversation{ID: conversationID}, nil
+	m.mockUpsertConversation = func(ctx context.Context, qtx *repository.Queries, id pgtype.UUID) (repository.UpsertConversationRow, error) {
+		return repository.UpsertConversationRow{ID: conversationID}, nil
 	}
 
-	m.mockAddConversationParticipants = func(ctx context.Context, qtx *repository.Queries, params repository.AddConversationParticipantsParams) error {
-		return err
+	m.mockAddConversationParticipants = func(ctx context.Context, qtx *repository.Queries, params repository.AddConversationParticipantsParams) (int64, error) {
+		return 0, err
 	}
 
 	m.mockRollbackTx = func(ctx context.Context, tx repository.DBTX) error {
@@ -345,13 +350,13 @@ func (m *mockTransactionHelpers) setupInsertMessageError(conversationID pgtype.U
 		return m.mockTx, nil
 	}
 
-	m.mockUpsertConversation = func(ctx context.Context, qtx *repository.Queries, id pgtype.UUID) (repository.Conversation, error) {
-		return repository.Conversation{ID: conversationID}, nil
+	m.mockUpsertConversation = func(ctx context.Context, qtx *repository.Queries, id pgtype.UUID) (repository.UpsertConversationRow, error) {
+		return repository.UpsertConversationRow{ID: conversationID}, nil
 	}
 
 	// Use bulk insert for participants
-	m.mockAddConversationParticipants = func(ctx context.Context, qtx *repository.Queries, params repository.AddConversationParticipantsParams) error {
-		return nil
+	m.mockAddConversationParticipants = func(ctx context.Context, qtx *repository.Queries, params repository.AddConversationParticipantsParams) (int64, error) {
+		return 0, nil
 	}
 
 	m.mockInsertMessage = func(ctx context.Context, qtx *repository.Queries, params repository.InsertMessageParams) (repository.Message, error) {
@@ -375,13 +380,13 @@ func (m *mockTransactionHelpers) setupInsertOutboxError(conversationID, senderID
 		return m.mockTx, nil
 	}
 
-	m.mockUpsertConversation = func(ctx context.Context, qtx *repository.Queries, id pgtype.UUID) (repository.Conversation, error) {
-		return repository.Conversation{ID: conversationID}, nil
+	m.mockUpsertConversation = func(ctx context.Context, qtx *repository.Queries, id pgtype.UUID) (repository.UpsertConversationRow, error) {
+		return repository.UpsertConversationRow{ID: conversationID}, nil
 	}
 
 	// Use bulk insert for participants
-	m.mockAddConversationParticipants = func(ctx context.Context, qtx *repository.Queries, params repository.AddConversationParticipantsParams) error {
-		return nil
+	m.mockAddConversationParticipants = func(ctx context.Context, qtx *repository.Queries, params repository.AddConversationParticipantsParams) (int64, error) {
+		return 0, nil
 	}
 
 	m.mockInsertMessage = func(ctx context.Context, qtx *repository.Queries, params repository.InsertMessageParams) (repository.Message, error) {
@@ -420,13 +425,13 @@ func (m *mockTransactionHelpers) setupCommitTxError(conversationID, senderID, me
 		return m.mockTx, nil
 	}
 
-	m.mockUpsertConversation = func(ctx context.Context, qtx *repository.Queries, id pgtype.UUID) (repository.Conversation, error) {
-		return repository.Conversation{ID: conversationID}, nil
+	m.mockUpsertConversation = func(ctx context.Context, qtx *repository.Queries, id pgtype.UUID) (repository.UpsertConversationRow, error) {
+		return repository.UpsertConversationRow{ID: conversationID}, nil
 	}
 
 	// Use bulk insert for participants
-	m.mockAddConversationParticipants = func(ctx context.Context, qtx *repository.Queries, params repository.AddConversationParticipantsParams) error {
-		return nil
+	m.mockAddConversationParticipants = func(ctx context.Context, qtx *repository.Queries, params repository.AddConversationParticipantsParams) (int64, error) {
+		return 0, nil
 	}
 
 	m.mockInsertMessage = func(ctx context.Context, qtx *repository.Queries, params repository.InsertMessageParams) (repository.Message, error) {
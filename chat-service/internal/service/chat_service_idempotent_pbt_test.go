@@ -63,15 +63,15 @@ func TestProperty_IdempotentParticipantInsertion(t *testing.T) {
 			mocks.mockBeginTx = func(ctx context.Context) (repository.DBTX, error) {
 				return mocks.mockTx, nil
 			}
-			mocks.mockUpsertConversation = func(ctx context.Context, qtx *repository.Queries, id pgtype.UUID) (repository.Conversation, error) {
-				return repository.Conversation{ID: conversationUUID}, nil
+			mocks.mockUpsertConversation = func(ctx context.Context, qtx *repository.Queries, id pgtype.UUID) (repository.UpsertConversationRow, error) {
+				return repository.UpsertConversationRow{ID: conversationUUID}, nil
 			}
 			// This mock simulates ON CONFLICT DO NOTHING - adds to set (no duplicates)
-			mocks.mockAddConversationParticipants = func(ctx context.Context, qtx *repository.Queries, params repository.AddConversationParticipantsParams) error {
+			mocks.mockAddConversationParticipants = func(ctx context.Context, qtx *repository.Queries, params repository.AddConversationParticipantsParams) (int64, error) {
 				for _, p := range params.Column2 {
 					participantSet[uuidToString(p)] = true
 				}
-				return nil // ON CONFLICT DO NOTHING - no error even if duplicate
+				return 0, nil // ON CONFLICT DO NOTHING - no error even if duplicate
 			}
 			mocks.mockInsertMessage = func(ctx context.Context, qtx *repository.Queries, params repository.InsertMessageParams) (repository.Message, error) {
 				messageUUID, _ := parseUUID(uuid.New().String())
@@ -196,15 +196,15 @@ func TestProperty_IdempotentParticipantInsertion(t *testing.T) {
 			mocks.mockBeginTx = func(ctx context.Context) (repository.DBTX, error) {
 				return mocks.mockTx, nil
 			}
-			mocks.mockUpsertConversation = func(ctx context.Context, qtx *repository.Queries, id pgtype.UUID) (repository.Conversation, error) {
-				return repository.Conversation{ID: conversationUUID}, nil
+			mocks.mockUpsertConversation = func(ctx context.Context, qtx *repository.Queries, id pgtype.UUID) (repository.UpsertConversationRow, error) {
+				return repository.UpsertConversationRow{ID: conversationUUID}, nil
 			}
-			mocks.mockAddConversationParticipants = func(ctx context.Context, qtx *repository.Queries, params repository.AddConversationParticipantsParams) error {
+			mocks.mockAddConversationParticipants = func(ctx context.Context, qtx *repository.Queries, params repository.AddConversationParticipantsParams) (int64, error) {
 				// Simulate ON CONFLICT DO NOTHING - add to set
 				for _, p := range params.Column2 {
 					participantSet[uuidToString(p)] = true
 				}
-				return nil
+				return 0, nil
 			}
 			mocks.mockInsertMessage = func(ctx context.Context, qtx *repository.Queries, params repository.InsertMessageParams) (repository.Message, error) {
 				messageUUID, _ := parseUUID(uuid.New().String())
@@ -310,15 +310,15 @@ func TestProperty_IdempotentParticipantInsertion(t *testing.T) {
 			mocks.mockBeginTx = func(ctx context.Context) (repository.DBTX, error) {
 				return mocks.mockTx, nil
 			}
-			mocks.mockUpsertConversation = func(ctx context.Context, qtx *repository.Queries, id pgtype.UUID) (repository.Conversation, error) {
-				return repository.Conversation{ID: conversationUUID}, nil
+			mocks.mockUpsertConversation = func(ctx context.Context, qtx *repository.Queries, id pgtype.UUID) (repository.UpsertConversationRow, error) {
+				return repository.UpsertConversationRow{ID: conversationUUID}, nil
 			}
-			mocks.mockAddConversationParticipants = func(ctx context.Context, qtx *repository.Queries, params repository.AddConversationParticipantsParams) error {
+			mocks.mockAddConversationParticipants = func(ctx context.Context, qtx *repository.Queries, params repository.AddConversationParticipantsParams) (int64, error) {
 				// Simulate ON CONFLICT DO NOTHING
 				for _, p := range params.Column2 {
 					participantSet[uuidToString(p)] = true
 				}
-				return nil
+				return 0, nil
 			}
 			mocks.mockInsertMessage = func(ctx context.Context, qtx *repository.Queries, params repository.InsertMessageParams) (repository.Message, error) {
 				messageUUID, _ := parseUUID(uuid.New().String())
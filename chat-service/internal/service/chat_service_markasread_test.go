@@ -2,32 +2,66 @@ package service
 
 import (
 	"context"
+	"encoding/json"
 	"errors"
 	"testing"
+	"time"
 
 	chatv1 "chat-service/api/chat/v1"
 	"chat-service/internal/repository"
 
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 	"go.uber.org/zap"
 	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
 	"google.golang.org/grpc/status"
 )
 
-func TestMarkAsRead_Success(t *testing.T) {
-	logger := zap.NewNop()
+// newMarkAsReadTestService builds a ChatService with the MarkAsRead transaction
+// mocked out. hadUnread controls whether a read receipt should be emitted; 2 messages
+// stay unread after the move.
+func newMarkAsReadTestService(t *testing.T, hadUnread bool, outbox *[]repository.InsertOutboxParams) (*ChatService, *repository.MarkAsReadParams) {
+	t.Helper()
 
 	var capturedParams repository.MarkAsReadParams
+	readAt := mustTimestamptz(t, time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC))
 
-	service := &ChatService{
-		logger: logger,
+	service := &ChatService{logger: zap.NewNop()}
+	service.beginTxFn = func(ctx context.Context) (repository.DBTX, error) {
+		return new(mockDBTX), nil
 	}
-
-	// Mock the MarkAsRead repository call using injectable function
-	service.markAsReadFn = func(ctx context.Context, arg repository.MarkAsReadParams) error {
+	service.hasUnreadMessagesFn = func(ctx context.Context, qtx *repository.Queries, arg repository.HasUnreadMessagesParams) (bool, error) {
+		return hadUnread, nil
+	}
+	service.markAsReadFn = func(ctx context.Context, qtx *repository.Queries, arg repository.MarkAsReadParams) (pgtype.Timestamptz, error) {
 		capturedParams = arg
+		return readAt, nil
+	}
+	service.countUnreadMessagesFn = func(ctx context.Context, qtx *repository.Queries, arg repository.CountUnreadMessagesParams) (int32, error) {
+		return 2, nil
+	}
+	service.getConversationParticipantsFn = func(ctx context.Context, qtx *repository.Queries, conversationID pgtype.UUID) ([]pgtype.UUID, error) {
+		return []pgtype.UUID{
+			mustParseUUID(t, "660e8400-e29b-41d4-a716-446655440000"),
+			mustParseUUID(t, "880e8400-e29b-41d4-a716-446655440000"),
+		}, nil
+	}
+	service.insertOutboxFn = func(ctx context.Context, qtx *repository.Queries, params repository.InsertOutboxParams) error {
+		*outbox = append(*outbox, params)
 		return nil
 	}
+	service.commitTxFn = func(ctx context.Context, tx repository.DBTX) error { return nil }
+	service.rollbackTxFn = func(ctx context.Context, tx repository.DBTX) error { return nil }
+
+	return service, &capturedParams
+}
+
+func TestMarkAsRead_Success(t *testing.T) {
+	var outbox []repository.InsertOutboxParams
+	service, capturedParams := newMarkAsReadTestService(t, true, &outbox)
 
 	// Create context with authenticated user
 	ctx := contextWithUserID("660e8400-e29b-41d4-a716-446655440000")
@@ -51,6 +85,211 @@ func TestMarkAsRead_Success(t *testing.T) {
 	assert.Equal(t, expectedUserID, capturedParams.UserID, "UserID should match")
 }
 
+func TestMarkAsRead_EmitsReadReceipt(t *testing.T) {
+	var outbox []repository.InsertOutboxParams
+	service, _ := newMarkAsReadTestService(t, true, &outbox)
+
+	ctx := contextWithUserID("660e8400-e29b-41d4-a716-446655440000")
+	req := &chatv1.MarkAsReadRequest{
+		ConversationId: "550e8400-e29b-41d4-a716-446655440000",
+	}
+
+	_, err := service.MarkAsRead(ctx, req)
+	require.NoError(t, err)
+	require.Len(t, outbox, 2, "A read receipt and a read_self event should be written to the outbox")
+
+	assert.Equal(t, "conversation", outbox[0].AggregateType)
+	assert.Equal(t, mustParseUUID(t, "550e8400-e29b-41d4-a716-446655440000"), outbox[0].AggregateID)
+
+	var payload map[string]interface{}
+	require.NoError(t, json.Unmarshal(outbox[0].Payload, &payload))
+	assert.Equal(t, "conversation.read", payload["event_type"])
+	assert.Equal(t, "550e8400-e29b-41d4-a716-446655440000", payload["conversation_id"])
+	assert.Equal(t, "660e8400-e29b-41d4-a716-446655440000", payload["user_id"])
+	assert.Equal(t, "2025-01-01T12:00:00Z", payload["read_at"])
+	// Reader is excluded from receivers
+	assert.Equal(t, []interface{}{"880e8400-e29b-41d4-a716-446655440000"}, payload["receiver_ids"])
+}
+
+func TestMarkAsRead_EmitsReadSelfEvent(t *testing.T) {
+	var outbox []repository.InsertOutboxParams
+	service, _ := newMarkAsReadTestService(t, true, &outbox)
+	var countParams repository.CountUnreadMessagesParams
+	service.countUnreadMessagesFn = func(ctx context.Context, qtx *repository.Queries, arg repository.CountUnreadMessagesParams) (int32, error) {
+		countParams = arg
+		return 3, nil
+	}
+
+	ctx := metadata.NewIncomingContext(contextWithUserID("660e8400-e29b-41d4-a716-446655440000"), metadata.Pairs(DeviceIDHeader, "phone"))
+	_, err := service.MarkAsRead(ctx, &chatv1.MarkAsReadRequest{
+		ConversationId: "550e8400-e29b-41d4-a716-446655440000",
+	})
+	require.NoError(t, err)
+	require.Len(t, outbox, 2)
+
+	assert.Equal(t, mustParseUUID(t, "550e8400-e29b-41d4-a716-446655440000"), countParams.ConversationID)
+	assert.Equal(t, mustParseUUID(t, "660e8400-e29b-41d4-a716-446655440000"), countParams.UserID)
+
+	assert.Equal(t, "conversation", outbox[1].AggregateType)
+	var payload map[string]interface{}
+	require.NoError(t, json.Unmarshal(outbox[1].Payload, &payload))
+	assert.Equal(t, map[string]interface{}{
+		"event_type":       EventTypeReadSelf,
+		"conversation_id":  "550e8400-e29b-41d4-a716-446655440000",
+		"user_id":          "660e8400-e29b-41d4-a716-446655440000",
+		"receiver_ids":     []interface{}{}, // delivered to the reader's devices, not to other participants
+		"read_at":          "2025-01-01T12:00:00Z",
+		"unread_count":     float64(3),
+		"origin_device_id": "phone",
+	}, payload)
+}
+
+func TestMarkAsRead_NoUnreadMessages_SkipsReadReceipt(t *testing.T) {
+	var outbox []repository.InsertOutboxParams
+	service, _ := newMarkAsReadTestService(t, false, &outbox)
+
+	ctx := contextWithUserID("660e8400-e29b-41d4-a716-446655440000")
+	req := &chatv1.MarkAsReadRequest{
+		ConversationId: "550e8400-e29b-41d4-a716-446655440000",
+	}
+
+	resp, err := service.MarkAsRead(ctx, req)
+	require.NoError(t, err)
+	assert.True(t, resp.Success)
+	assert.Empty(t, outbox, "No event should be emitted when nothing was unread")
+}
+
+func TestMarkAsRead_UpToMessage(t *testing.T) {
+	var outbox []repository.InsertOutboxParams
+	service, capturedParams := newMarkAsReadTestService(t, true, &outbox)
+
+	conversationID := mustParseUUID(t, "550e8400-e29b-41d4-a716-446655440000")
+	createdAt := mustTimestamptz(t, time.Date(2025, 1, 1, 11, 59, 0, 0, time.UTC))
+	var hasUnreadParams repository.HasUnreadMessagesParams
+	service.hasUnreadMessagesFn = func(ctx context.Context, qtx *repository.Queries, arg repository.HasUnreadMessagesParams) (bool, error) {
+		hasUnreadParams = arg
+		return true, nil
+	}
+	service.getMessageByIDFn = func(ctx context.Context, id pgtype.UUID) (repository.Message, error) {
+		assert.Equal(t, mustParseUUID(t, "770e8400-e29b-41d4-a716-446655440000"), id)
+		return repository.Message{ID: id, ConversationID: conversationID, CreatedAt: createdAt}, nil
+	}
+
+	ctx := contextWithUserID("660e8400-e29b-41d4-a716-446655440000")
+	_, err := service.MarkAsRead(ctx, &chatv1.MarkAsReadRequest{
+		ConversationId: "550e8400-e29b-41d4-a716-446655440000",
+		UpToMessageId:  "770e8400-e29b-41d4-a716-446655440000",
+	})
+	require.NoError(t, err)
+
+	// Both the unread check and the update are bounded by the message's created_at
+	assert.Equal(t, createdAt, capturedParams.ReadUpTo)
+	assert.Equal(t, createdAt, hasUnreadParams.ReadUpTo)
+	assert.Len(t, outbox, 2)
+}
+
+func TestMarkAsRead_WithoutUpToMessage_ReadsEverything(t *testing.T) {
+	var outbox []repository.InsertOutboxParams
+	service, capturedParams := newMarkAsReadTestService(t, true, &outbox)
+	service.getMessageByIDFn = func(ctx context.Context, id pgtype.UUID) (repository.Message, error) {
+		t.Fatal("no message lookup without up_to_message_id")
+		return repository.Message{}, nil
+	}
+
+	ctx := contextWithUserID("660e8400-e29b-41d4-a716-446655440000")
+	_, err := service.MarkAsRead(ctx, &chatv1.MarkAsReadRequest{
+		ConversationId: "550e8400-e29b-41d4-a716-446655440000",
+	})
+	require.NoError(t, err)
+	assert.False(t, capturedParams.ReadUpTo.Valid, "a NULL bound marks read up to now")
+}
+
+func TestMarkAsRead_UpToMessageErrors(t *testing.T) {
+	tests := []struct {
+		name        string
+		upToMessage string
+		message     repository.Message
+		lookupErr   error
+		wantCode    codes.Code
+	}{
+		{name: "invalid id", upToMessage: "not-a-uuid", wantCode: codes.InvalidArgument},
+		{name: "unknown message", upToMessage: "770e8400-e29b-41d4-a716-446655440000", lookupErr: pgx.ErrNoRows, wantCode: codes.NotFound},
+		{
+			name:        "message from another conversation",
+			upToMessage: "770e8400-e29b-41d4-a716-446655440000",
+			message:     repository.Message{ConversationID: mustParseUUID(t, "990e8400-e29b-41d4-a716-446655440000")},
+			wantCode:    codes.InvalidArgument,
+		},
+		{name: "lookup failure", upToMessage: "770e8400-e29b-41d4-a716-446655440000", lookupErr: errors.New("db down"), wantCode: codes.Internal},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var outbox []repository.InsertOutboxParams
+			service, _ := newMarkAsReadTestService(t, true, &outbox)
+			markedRead := false
+			service.markAsReadFn = func(ctx context.Context, qtx *repository.Queries, arg repository.MarkAsReadParams) (pgtype.Timestamptz, error) {
+				markedRead = true
+				return pgtype.Timestamptz{}, nil
+			}
+			service.getMessageByIDFn = func(ctx context.Context, id pgtype.UUID) (repository.Message, error) {
+				return tt.message, tt.lookupErr
+			}
+
+			ctx := contextWithUserID("660e8400-e29b-41d4-a716-446655440000")
+			_, err := service.MarkAsRead(ctx, &chatv1.MarkAsReadRequest{
+				ConversationId: "550e8400-e29b-41d4-a716-446655440000",
+				UpToMessageId:  tt.upToMessage,
+			})
+			assert.Equal(t, tt.wantCode, status.Code(err))
+			assert.False(t, markedRead)
+		})
+	}
+}
+
+func TestMarkAsRead_NotParticipant_NoOp(t *testing.T) {
+	var outbox []repository.InsertOutboxParams
+	service, _ := newMarkAsReadTestService(t, false, &outbox)
+	service.markAsReadFn = func(ctx context.Context, qtx *repository.Queries, arg repository.MarkAsReadParams) (pgtype.Timestamptz, error) {
+		return pgtype.Timestamptz{}, pgx.ErrNoRows
+	}
+
+	ctx := contextWithUserID("660e8400-e29b-41d4-a716-446655440000")
+	req := &chatv1.MarkAsReadRequest{
+		ConversationId: "550e8400-e29b-41d4-a716-446655440000",
+	}
+
+	resp, err := service.MarkAsRead(ctx, req)
+	require.NoError(t, err)
+	assert.True(t, resp.Success)
+	assert.Empty(t, outbox)
+}
+
+func TestMarkAsRead_NothingNewToRead_NoWrite(t *testing.T) {
+	var outbox []repository.InsertOutboxParams
+	service, _ := newMarkAsReadTestService(t, false, &outbox)
+	// The read pointer is already past every message: the conditional UPDATE matches no row
+	service.markAsReadFn = func(ctx context.Context, qtx *repository.Queries, arg repository.MarkAsReadParams) (pgtype.Timestamptz, error) {
+		return pgtype.Timestamptz{}, pgx.ErrNoRows
+	}
+	committed := false
+	service.commitTxFn = func(ctx context.Context, tx repository.DBTX) error {
+		committed = true
+		return nil
+	}
+
+	ctx := contextWithUserID("660e8400-e29b-41d4-a716-446655440000")
+	for i := 0; i < 3; i++ {
+		resp, err := service.MarkAsRead(ctx, &chatv1.MarkAsReadRequest{
+			ConversationId: "550e8400-e29b-41d4-a716-446655440000",
+		})
+		require.NoError(t, err)
+		assert.True(t, resp.Success)
+	}
+	assert.False(t, committed, "a no-op read is rolled back, not committed")
+	assert.Empty(t, outbox)
+}
+
 func TestMarkAsRead_AuthenticationError_MissingUserID(t *testing.T) {
 	logger := zap.NewNop()
 
@@ -127,15 +366,12 @@ func TestMarkAsRead_ValidationErrors(t *testing.T) {
 }
 
 func TestMarkAsRead_DatabaseError(t *testing.T) {
-	logger := zap.NewNop()
-
-	service := &ChatService{
-		logger: logger,
-	}
+	var outbox []repository.InsertOutboxParams
+	service, _ := newMarkAsReadTestService(t, true, &outbox)
 
 	// Mock the MarkAsRead repository call to return an error
-	service.markAsReadFn = func(ctx context.Context, arg repository.MarkAsReadParams) error {
-		return errors.New("database connection failed")
+	service.markAsReadFn = func(ctx context.Context, qtx *repository.Queries, arg repository.MarkAsReadParams) (pgtype.Timestamptz, error) {
+		return pgtype.Timestamptz{}, errors.New("database connection failed")
 	}
 
 	// Create context with authenticated user
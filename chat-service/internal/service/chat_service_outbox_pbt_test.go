@@ -76,11 +76,11 @@ func TestProperty_OutboxContainsReceiverIDs(t *testing.T) {
 			mocks.mockBeginTx = func(ctx context.Context) (repository.DBTX, error) {
 				return mocks.mockTx, nil
 			}
-			mocks.mockUpsertConversation = func(ctx context.Context, qtx *repository.Queries, id pgtype.UUID) (repository.Conversation, error) {
-				return repository.Conversation{ID: conversationUUID}, nil
+			mocks.mockUpsertConversation = func(ctx context.Context, qtx *repository.Queries, id pgtype.UUID) (repository.UpsertConversationRow, error) {
+				return repository.UpsertConversationRow{ID: conversationUUID}, nil
 			}
-			mocks.mockAddConversationParticipants = func(ctx context.Context, qtx *repository.Queries, params repository.AddConversationParticipantsParams) error {
-				return nil
+			mocks.mockAddConversationParticipants = func(ctx context.Context, qtx *repository.Queries, params repository.AddConversationParticipantsParams) (int64, error) {
+				return 0, nil
 			}
 			mocks.mockInsertMessage = func(ctx context.Context, qtx *repository.Queries, params repository.InsertMessageParams) (repository.Message, error) {
 				msg := repository.Message{
@@ -225,11 +225,11 @@ func TestProperty_OutboxContainsReceiverIDs(t *testing.T) {
 			mocks.mockBeginTx = func(ctx context.Context) (repository.DBTX, error) {
 				return mocks.mockTx, nil
 			}
-			mocks.mockUpsertConversation = func(ctx context.Context, qtx *repository.Queries, id pgtype.UUID) (repository.Conversation, error) {
-				return repository.Conversation{ID: conversationUUID}, nil
+			mocks.mockUpsertConversation = func(ctx context.Context, qtx *repository.Queries, id pgtype.UUID) (repository.UpsertConversationRow, error) {
+				return repository.UpsertConversationRow{ID: conversationUUID}, nil
 			}
-			mocks.mockAddConversationParticipants = func(ctx context.Context, qtx *repository.Queries, params repository.AddConversationParticipantsParams) error {
-				return nil
+			mocks.mockAddConversationParticipants = func(ctx context.Context, qtx *repository.Queries, params repository.AddConversationParticipantsParams) (int64, error) {
+				return 0, nil
 			}
 			mocks.mockInsertMessage = func(ctx context.Context, qtx *repository.Queries, params repository.InsertMessageParams) (repository.Message, error) {
 				msg := repository.Message{
@@ -354,11 +354,11 @@ func TestProperty_OutboxContainsReceiverIDs(t *testing.T) {
 			mocks.mockBeginTx = func(ctx context.Context) (repository.DBTX, error) {
 				return mocks.mockTx, nil
 			}
-			mocks.mockUpsertConversation = func(ctx context.Context, qtx *repository.Queries, id pgtype.UUID) (repository.Conversation, error) {
-				return repository.Conversation{ID: conversationUUID}, nil
+			mocks.mockUpsertConversation = func(ctx context.Context, qtx *repository.Queries, id pgtype.UUID) (repository.UpsertConversationRow, error) {
+				return repository.UpsertConversationRow{ID: conversationUUID}, nil
 			}
-			mocks.mockAddConversationParticipants = func(ctx context.Context, qtx *repository.Queries, params repository.AddConversationParticipantsParams) error {
-				return nil
+			mocks.mockAddConversationParticipants = func(ctx context.Context, qtx *repository.Queries, params repository.AddConversationParticipantsParams) (int64, error) {
+				return 0, nil
 			}
 			mocks.mockInsertMessage = func(ctx context.Context, qtx *repository.Queries, params repository.InsertMessageParams) (repository.Message, error) {
 				msg := repository.Message{
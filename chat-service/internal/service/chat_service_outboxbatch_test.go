@@ -0,0 +1,84 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"chat-service/internal/repository"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+func newOutboxBatchTestService() (*ChatService, *[]repository.InsertOutboxParams, *[]repository.InsertOutboxBatchParams) {
+	var singles []repository.InsertOutboxParams
+	var batches []repository.InsertOutboxBatchParams
+
+	service := &ChatService{logger: zap.NewNop()}
+	service.insertOutboxFn = func(ctx context.Context, qtx *repository.Queries, params repository.InsertOutboxParams) error {
+		singles = append(singles, params)
+		return nil
+	}
+	service.insertOutboxBatchFn = func(ctx context.Context, qtx *repository.Queries, params repository.InsertOutboxBatchParams) (int64, error) {
+		batches = append(batches, params)
+		return int64(len(params.Payloads)), nil
+	}
+	return service, &singles, &batches
+}
+
+func TestInsertOutboxBatch_SeveralEventsInOneStatement(t *testing.T) {
+	service, singles, batches := newOutboxBatchTestService()
+	conversationID := mustParseUUID(t, testReactionConversationID)
+	messageID := mustParseUUID(t, testReactionMessageID)
+
+	err := service.insertOutboxBatch(context.Background(), nil, []repository.InsertOutboxParams{
+		{AggregateType: "message", AggregateID: messageID, Payload: []byte(`{"event_type":"message.sent"}`)},
+		{AggregateType: "conversation", AggregateID: conversationID, Payload: []byte(`{"event_type":"reaction.added"}`)},
+	})
+
+	require.NoError(t, err)
+	assert.Empty(t, *singles)
+	require.Len(t, *batches, 1)
+	batch := (*batches)[0]
+	assert.Equal(t, []string{"message", "conversation"}, batch.AggregateTypes)
+	assert.Equal(t, messageID, batch.AggregateIds[0])
+	assert.Equal(t, conversationID, batch.AggregateIds[1])
+	assert.JSONEq(t, `{"event_type":"reaction.added"}`, string(batch.Payloads[1]))
+}
+
+func TestInsertOutboxBatch_SingleEventUsesPlainInsert(t *testing.T) {
+	service, singles, batches := newOutboxBatchTestService()
+
+	err := service.insertOutboxBatch(context.Background(), nil, []repository.InsertOutboxParams{
+		{AggregateType: "message", Payload: []byte(`{}`)},
+	})
+
+	require.NoError(t, err)
+	assert.Len(t, *singles, 1)
+	assert.Empty(t, *batches)
+
+	require.NoError(t, service.insertOutboxBatch(context.Background(), nil, nil))
+	assert.Len(t, *singles, 1, "nothing to insert")
+	assert.Empty(t, *batches)
+}
+
+func TestInsertOutboxBatch_Errors(t *testing.T) {
+	events := []repository.InsertOutboxParams{
+		{AggregateType: "message", Payload: []byte(`{}`)},
+		{AggregateType: "message", Payload: []byte(`{}`)},
+	}
+
+	service, _, _ := newOutboxBatchTestService()
+	service.insertOutboxBatchFn = func(ctx context.Context, qtx *repository.Queries, params repository.InsertOutboxBatchParams) (int64, error) {
+		return 0, errors.New("connection reset")
+	}
+	assert.Error(t, service.insertOutboxBatch(context.Background(), nil, events))
+
+	// A short insert must not pass silently
+	service.insertOutboxBatchFn = func(ctx context.Context, qtx *repository.Queries, params repository.InsertOutboxBatchParams) (int64, error) {
+		return 1, nil
+	}
+	assert.Error(t, service.insertOutboxBatch(context.Background(), nil, events))
+}
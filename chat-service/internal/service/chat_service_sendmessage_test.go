@@ -2,17 +2,24 @@ package service
 
 import (
 	"context"
+	"encoding/json"
 	"errors"
+	"fmt"
+	"strings"
 	"testing"
 	"time"
 
+	ctxkeys "chat-service/internal/context"
 	chatv1 "chat-service/internal/repository"
 	"chat-service/pkg/idempotency"
+	"chat-service/pkg/profile"
 
 	"github.com/jackc/pgx/v5/pgtype"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 	"go.uber.org/zap"
 	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
 	"google.golang.org/grpc/status"
 
 	chatv1pb "chat-service/api/chat/v1"
@@ -52,6 +59,17 @@ func TestValidateSendMessageRequest(t *testing.T) {
 			},
 			expectedErr: ErrEmptyContent,
 		},
+		{
+			name: "attachment without content",
+			req: &chatv1pb.SendMessageRequest{
+				ConversationId: "conv-123",
+				IdempotencyKey: "key-123",
+				Attachments: []*chatv1pb.Attachment{
+					{Url: "https://cdn.example.com/a.png", MimeType: "image/png", Size: 1024},
+				},
+			},
+			expectedErr: nil,
+		},
 		{
 			name: "empty idempotency_key",
 			req: &chatv1pb.SendMessageRequest{
@@ -109,6 +127,137 @@ func TestValidateSendMessageRequest_AllFields(t *testing.T) {
 	assert.NoError(t, err) // Should accept long content
 }
 
+func TestValidateSendMessageRequest_MaxContentBytes(t *testing.T) {
+	service := &ChatService{logger: zap.NewNop()}
+	service.SetMaxContentBytes(8)
+
+	req := &chatv1pb.SendMessageRequest{
+		ConversationId: "550e8400-e29b-41d4-a716-446655440000",
+		Content:        "12345678",
+		IdempotencyKey: "key-123",
+	}
+	assert.NoError(t, service.validateSendMessageRequest(req), "content at the limit is accepted")
+
+	// The limit counts bytes, not characters: "é" is two bytes in UTF-8
+	req.Content = "1234567é"
+	err := service.validateSendMessageRequest(req)
+	assert.ErrorIs(t, err, ErrContentTooLong)
+	assert.Equal(t, "content", validationField(err))
+}
+
+// receiverIDs builds n distinct receiver UUID strings
+func receiverIDs(n int) []string {
+	ids := make([]string, n)
+	for i := range ids {
+		ids[i] = fmt.Sprintf("660e8400-e29b-41d4-a716-%012d", i)
+	}
+	return ids
+}
+
+func TestValidateSendMessageRequest_MaxReceivers(t *testing.T) {
+	tests := []struct {
+		name    string
+		limit   int
+		count   int
+		repeat  int // times each id is listed (0 = once)
+		wantErr bool
+	}{
+		{name: "default limit boundary", count: DefaultMaxReceivers},
+		{name: "default limit exceeded", count: DefaultMaxReceivers + 1, wantErr: true},
+		{name: "configured limit boundary", limit: 3, count: 3},
+		{name: "configured limit exceeded", limit: 3, count: 4, wantErr: true},
+		{name: "repeated ids count once", limit: 3, count: 3, repeat: 4},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			service := &ChatService{logger: zap.NewNop()}
+			service.SetMaxReceivers(tt.limit)
+
+			req := &chatv1pb.SendMessageRequest{
+				ConversationId: "550e8400-e29b-41d4-a716-446655440000",
+				Content:        "hello",
+				IdempotencyKey: "key-123",
+				ReceiverIds:    receiverIDs(tt.count),
+			}
+			for i := 1; i < tt.repeat; i++ {
+				req.ReceiverIds = append(req.ReceiverIds, receiverIDs(tt.count)...)
+			}
+			err := service.validateSendMessageRequest(req)
+			if !tt.wantErr {
+				assert.NoError(t, err)
+				return
+			}
+			assert.ErrorIs(t, err, ErrTooManyReceivers)
+			assert.Equal(t, "receiver_ids", validationField(err))
+		})
+	}
+}
+
+func TestSendMessage_TooManyReceivers(t *testing.T) {
+	service := &ChatService{logger: zap.NewNop()}
+	service.SetMaxReceivers(2)
+
+	ctx := contextWithUserID("660e8400-e29b-41d4-a716-446655440000")
+	_, err := service.SendMessage(ctx, &chatv1pb.SendMessageRequest{
+		ConversationId: "550e8400-e29b-41d4-a716-446655440000",
+		Content:        "hello",
+		IdempotencyKey: "key-123",
+		ReceiverIds:    receiverIDs(3),
+	})
+	assert.Equal(t, codes.InvalidArgument, status.Code(err))
+}
+
+func TestParseReceiverIDs_Deduplicates(t *testing.T) {
+	ids := receiverIDs(2)
+	result, err := parseReceiverIDs([]string{ids[1], ids[0], ids[1], ids[0]})
+	require.NoError(t, err)
+	require.Len(t, result, 2)
+	assert.Equal(t, ids[1], uuidToString(result[0]), "first occurrence keeps its position")
+	assert.Equal(t, ids[0], uuidToString(result[1]))
+}
+
+func TestParseReceiverIDs_StrictFormat(t *testing.T) {
+	tests := []struct {
+		id      string
+		wantErr bool
+	}{
+		{id: "660e8400-e29b-41d4-a716-446655440000"},
+		{id: "660E8400-E29B-41D4-A716-446655440000"},
+		{id: "660e8400e29b41d4a716446655440000", wantErr: true},
+		{id: "{660e8400-e29b-41d4-a716-446655440000}", wantErr: true},
+		{id: "urn:uuid:660e8400-e29b-41d4-a716-446655440000", wantErr: true},
+		{id: "660e8400Xe29bX41d4Xa716X446655440000", wantErr: true},
+		{id: "00000000-0000-0000-0000-000000000000", wantErr: true},
+	}
+	for _, tt := range tests {
+		result, err := parseReceiverIDs([]string{tt.id})
+		if tt.wantErr {
+			assert.ErrorIs(t, err, ErrInvalidReceiverID, tt.id)
+			assert.Nil(t, result, tt.id)
+			continue
+		}
+		require.NoError(t, err, tt.id)
+		assert.Equal(t, strings.ToLower(tt.id), uuidToString(result[0]))
+	}
+}
+
+func TestSendMessage_InvalidReceiverID(t *testing.T) {
+	mockIdempotency := new(MockIdempotencyChecker)
+	service := &ChatService{idempotencyCheck: mockIdempotency, logger: zap.NewNop()}
+
+	_, err := service.SendMessage(contextWithUserID("660e8400-e29b-41d4-a716-446655440000"), &chatv1pb.SendMessageRequest{
+		ConversationId: "550e8400-e29b-41d4-a716-446655440000",
+		Content:        "hello",
+		IdempotencyKey: "key-123",
+		ReceiverIds:    []string{"770e8400-e29b-41d4-a716-446655440000", "770e8400e29b41d4a716446655440000"},
+	})
+	assert.Equal(t, codes.InvalidArgument, status.Code(err))
+	assert.Contains(t, err.Error(), "receiver_id")
+	// Rejected before the idempotency key is claimed
+	mockIdempotency.AssertNotCalled(t, "Check")
+}
+
 func TestSendMessage_ValidationError(t *testing.T) {
 	logger := zap.NewNop()
 	mockIdempotency := new(MockIdempotencyChecker)
@@ -135,6 +284,85 @@ func TestSendMessage_ValidationError(t *testing.T) {
 	mockIdempotency.AssertNotCalled(t, "Check")
 }
 
+func TestValidateSendMessageRequest_SystemAndCallTypes(t *testing.T) {
+	service := &ChatService{logger: zap.NewNop()}
+
+	for _, msgType := range []chatv1pb.MessageType{chatv1pb.MessageType_MESSAGE_TYPE_SYSTEM, chatv1pb.MessageType_MESSAGE_TYPE_CALL} {
+		t.Run(msgType.String(), func(t *testing.T) {
+			newReq := func() *chatv1pb.SendMessageRequest {
+				return &chatv1pb.SendMessageRequest{
+					ConversationId: "conv-123",
+					IdempotencyKey: "key-123",
+					Content:        "Missed voice call",
+					Type:           msgType,
+				}
+			}
+			assert.NoError(t, service.validateSendMessageRequest(newReq()))
+
+			noContent := newReq()
+			noContent.Content = ""
+			assert.ErrorIs(t, service.validateSendMessageRequest(noContent), ErrEmptyContent)
+
+			withMedia := newReq()
+			withMedia.MediaUrl = "https://cdn.example.com/a.png"
+			err := service.validateSendMessageRequest(withMedia)
+			assert.ErrorIs(t, err, ErrMediaNotAllowed)
+			assert.Equal(t, "media_url", validationField(err))
+
+			withAttachment := newReq()
+			withAttachment.Attachments = []*chatv1pb.Attachment{
+				{Url: "https://cdn.example.com/a.png", MimeType: "image/png", Size: 1024},
+			}
+			err = service.validateSendMessageRequest(withAttachment)
+			assert.ErrorIs(t, err, ErrAttachmentsNotAllowed)
+			assert.Equal(t, "attachments", validationField(err))
+		})
+	}
+
+	err := service.validateSendMessageRequest(&chatv1pb.SendMessageRequest{
+		ConversationId: "conv-123",
+		IdempotencyKey: "key-123",
+		Content:        "Hello",
+		Type:           chatv1pb.MessageType(99),
+	})
+	assert.ErrorIs(t, err, ErrInvalidMessageType)
+}
+
+func TestSendMessage_SystemMessageRequiresAdmin(t *testing.T) {
+	mockIdempotency := new(MockIdempotencyChecker)
+	service := &ChatService{
+		idempotencyCheck: mockIdempotency,
+		logger:           zap.NewNop(),
+	}
+
+	ctx := contextWithUserID("660e8400-e29b-41d4-a716-446655440000")
+	req := &chatv1pb.SendMessageRequest{
+		ConversationId: "550e8400-e29b-41d4-a716-446655440000",
+		Content:        "Alice renamed the group",
+		IdempotencyKey: "key-123",
+		Type:           chatv1pb.MessageType_MESSAGE_TYPE_SYSTEM,
+	}
+
+	resp, err := service.SendMessage(ctx, req)
+
+	assert.Nil(t, resp)
+	assert.Equal(t, codes.PermissionDenied, status.Code(err))
+	mockIdempotency.AssertNotCalled(t, "Check")
+}
+
+func TestMessageTypeMapping_RoundTrip(t *testing.T) {
+	for _, msgType := range []chatv1pb.MessageType{
+		chatv1pb.MessageType_MESSAGE_TYPE_TEXT,
+		chatv1pb.MessageType_MESSAGE_TYPE_IMAGE,
+		chatv1pb.MessageType_MESSAGE_TYPE_VIDEO,
+		chatv1pb.MessageType_MESSAGE_TYPE_FILE,
+		chatv1pb.MessageType_MESSAGE_TYPE_SYSTEM,
+		chatv1pb.MessageType_MESSAGE_TYPE_CALL,
+	} {
+		assert.Equal(t, msgType, getProtoMessageType(getMessageTypeString(msgType)), msgType.String())
+	}
+}
+
 func TestSendMessage_MissingUserIDInContext(t *testing.T) {
 	logger := zap.NewNop()
 	mockIdempotency := new(MockIdempotencyChecker)
@@ -154,9 +382,9 @@ func TestSendMessage_MissingUserIDInContext(t *testing.T) {
 			beginTxCalled = true
 			return nil, errors.New("should not be called")
 		},
-		upsertConversationFn: func(ctx context.Context, qtx *chatv1.Queries, id pgtype.UUID) (chatv1.Conversation, error) {
+		upsertConversationFn: func(ctx context.Context, qtx *chatv1.Queries, id pgtype.UUID) (chatv1.UpsertConversationRow, error) {
 			upsertConvCalled = true
-			return chatv1.Conversation{}, errors.New("should not be called")
+			return chatv1.UpsertConversationRow{}, errors.New("should not be called")
 		},
 		insertMessageFn: func(ctx context.Context, qtx *chatv1.Queries, params chatv1.InsertMessageParams) (chatv1.Message, error) {
 			insertMsgCalled = true
@@ -215,9 +443,9 @@ func TestSendMessage_DuplicateRequest(t *testing.T) {
 			beginTxCalled = true
 			return nil, errors.New("should not be called")
 		},
-		upsertConversationFn: func(ctx context.Context, qtx *chatv1.Queries, id pgtype.UUID) (chatv1.Conversation, error) {
+		upsertConversationFn: func(ctx context.Context, qtx *chatv1.Queries, id pgtype.UUID) (chatv1.UpsertConversationRow, error) {
 			upsertConvCalled = true
-			return chatv1.Conversation{}, errors.New("should not be called")
+			return chatv1.UpsertConversationRow{}, errors.New("should not be called")
 		},
 		insertMessageFn: func(ctx context.Context, qtx *chatv1.Queries, params chatv1.InsertMessageParams) (chatv1.Message, error) {
 			insertMsgCalled = true
@@ -267,9 +495,9 @@ func TestSendMessage_IdempotencyCheckError(t *testing.T) {
 			beginTxCalled = true
 			return nil, errors.New("should not be called")
 		},
-		upsertConversationFn: func(ctx context.Context, qtx *chatv1.Queries, id pgtype.UUID) (chatv1.Conversation, error) {
+		upsertConversationFn: func(ctx context.Context, qtx *chatv1.Queries, id pgtype.UUID) (chatv1.UpsertConversationRow, error) {
 			upsertConvCalled = true
-			return chatv1.Conversation{}, errors.New("should not be called")
+			return chatv1.UpsertConversationRow{}, errors.New("should not be called")
 		},
 		insertMessageFn: func(ctx context.Context, qtx *chatv1.Queries, params chatv1.InsertMessageParams) (chatv1.Message, error) {
 			insertMsgCalled = true
@@ -351,7 +579,7 @@ func TestCreateMessageEventPayload(t *testing.T) {
 	message.CreatedAt.Scan(time.Now())
 
 	receiverIDs := []string{"receiver-1", "receiver-2"}
-	payload, err := service.createMessageEventPayload(message, receiverIDs)
+	payload, err := service.createMessageEventPayload(message, profile.Profile{}, receiverIDs, nil, nil, false, nil, eventOrigin{})
 
 	assert.NoError(t, err)
 	assert.NotNil(t, payload)
@@ -361,6 +589,152 @@ func TestCreateMessageEventPayload(t *testing.T) {
 	assert.Contains(t, string(payload), "receiver-1")
 }
 
+func TestCreateMessageEventPayload_RequestID(t *testing.T) {
+	service := &ChatService{logger: zap.NewNop()}
+
+	msgUUID, _ := parseUUID("770e8400-e29b-41d4-a716-446655440000")
+	message := chatv1.Message{ID: msgUUID, Content: "Test message"}
+	message.CreatedAt.Scan(time.Now())
+
+	payload, err := service.createMessageEventPayload(message, profile.Profile{}, []string{"receiver-1"}, nil, nil, false, nil, eventOrigin{requestID: "req-123"})
+	require.NoError(t, err)
+
+	var event map[string]interface{}
+	require.NoError(t, json.Unmarshal(payload, &event))
+	assert.Equal(t, "req-123", event["request_id"])
+
+	// Omitted when the request had no id (e.g. internal callers)
+	payload, err = service.createMessageEventPayload(message, profile.Profile{}, []string{"receiver-1"}, nil, nil, false, nil, eventOrigin{})
+	require.NoError(t, err)
+	assert.NotContains(t, string(payload), "request_id")
+}
+
+func TestEventOriginFromContext(t *testing.T) {
+	base := context.WithValue(context.Background(), ctxkeys.RequestIDKey, "req-123")
+
+	ctx := metadata.NewIncomingContext(base, metadata.Pairs(DeviceIDHeader, "phone-1"))
+	assert.Equal(t, eventOrigin{requestID: "req-123", deviceID: "phone-1"}, eventOriginFromContext(ctx))
+
+	// Malformed device ids are dropped, the request id is kept
+	ctx = metadata.NewIncomingContext(base, metadata.Pairs(DeviceIDHeader, "phone 1"))
+	assert.Equal(t, eventOrigin{requestID: "req-123"}, eventOriginFromContext(ctx))
+
+	assert.Equal(t, eventOrigin{requestID: "req-123"}, eventOriginFromContext(base))
+}
+
+func TestCreateMessageEventPayload_OriginDeviceID(t *testing.T) {
+	service := &ChatService{logger: zap.NewNop()}
+
+	msgUUID, _ := parseUUID("770e8400-e29b-41d4-a716-446655440000")
+	message := chatv1.Message{ID: msgUUID, Content: "Test message"}
+	message.CreatedAt.Scan(time.Now())
+
+	payload, err := service.createMessageEventPayload(message, profile.Profile{}, []string{"receiver-1"}, nil, nil, false, nil, eventOrigin{deviceID: "phone-1"})
+	require.NoError(t, err)
+
+	var event map[string]interface{}
+	require.NoError(t, json.Unmarshal(payload, &event))
+	assert.Equal(t, "phone-1", event["origin_device_id"])
+
+	payload, err = service.createMessageEventPayload(message, profile.Profile{}, []string{"receiver-1"}, nil, nil, false, nil, eventOrigin{})
+	require.NoError(t, err)
+	assert.NotContains(t, string(payload), "origin_device_id")
+}
+
+func TestCreateMessageEventPayload_SenderProfile(t *testing.T) {
+	service := &ChatService{logger: zap.NewNop()}
+
+	msgUUID, _ := parseUUID("770e8400-e29b-41d4-a716-446655440000")
+	message := chatv1.Message{ID: msgUUID, Content: "Test message"}
+	message.CreatedAt.Scan(time.Now())
+
+	sender := profile.Profile{DisplayName: "Alice", AvatarURL: "https://cdn.example.com/alice.png"}
+	payload, err := service.createMessageEventPayload(message, sender, []string{"receiver-1"}, nil, nil, false, nil, eventOrigin{})
+	require.NoError(t, err)
+
+	var event map[string]interface{}
+	require.NoError(t, json.Unmarshal(payload, &event))
+	assert.Equal(t, "Alice", event["sender_name"])
+	assert.Equal(t, "https://cdn.example.com/alice.png", event["sender_avatar_url"])
+
+	// Omitted when the profile is unknown
+	payload, err = service.createMessageEventPayload(message, profile.Profile{}, []string{"receiver-1"}, nil, nil, false, nil, eventOrigin{})
+	require.NoError(t, err)
+	assert.NotContains(t, string(payload), "sender_name")
+	assert.NotContains(t, string(payload), "sender_avatar_url")
+}
+
+func TestCreateMessageEventPayload_NewParticipants(t *testing.T) {
+	service := &ChatService{logger: zap.NewNop()}
+
+	msgUUID, _ := parseUUID("770e8400-e29b-41d4-a716-446655440000")
+	message := chatv1.Message{ID: msgUUID, Content: "Test message"}
+	message.CreatedAt.Scan(time.Now())
+
+	payload, err := service.createMessageEventPayload(message, profile.Profile{}, []string{"receiver-1"}, nil, nil, true, nil, eventOrigin{})
+	require.NoError(t, err)
+
+	var event map[string]interface{}
+	require.NoError(t, json.Unmarshal(payload, &event))
+	assert.Equal(t, true, event["new_participants"])
+
+	// Omitted when nobody joined
+	payload, err = service.createMessageEventPayload(message, profile.Profile{}, []string{"receiver-1"}, nil, nil, false, nil, eventOrigin{})
+	require.NoError(t, err)
+	assert.NotContains(t, string(payload), "new_participants")
+}
+
+// TestSendMessage_FlagsNewParticipants verifies a send that adds users to the conversation marks its event
+func TestSendMessage_FlagsNewParticipants(t *testing.T) {
+	for _, tc := range []struct {
+		name   string
+		joined int64
+	}{
+		{name: "new participants", joined: 1},
+		{name: "existing participants", joined: 0},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			mockIdempotency := new(MockIdempotencyChecker)
+			mocks := newMockTransactionHelpers()
+
+			conversationID := mustParseUUID(t, "550e8400-e29b-41d4-a716-446655440000")
+			senderID := mustParseUUID(t, "660e8400-e29b-41d4-a716-446655440000")
+			messageID := mustParseUUID(t, "770e8400-e29b-41d4-a716-446655440000")
+			mocks.setupHappyPathTransaction(conversationID, senderID, messageID, "Hello")
+			mocks.mockAddConversationParticipants = func(ctx context.Context, qtx *chatv1.Queries, params chatv1.AddConversationParticipantsParams) (int64, error) {
+				return tc.joined, nil
+			}
+
+			var capturedOutbox chatv1.InsertOutboxParams
+			mocks.mockInsertOutbox = func(ctx context.Context, qtx *chatv1.Queries, params chatv1.InsertOutboxParams) error {
+				capturedOutbox = params
+				return nil
+			}
+
+			service := &ChatService{
+				idempotencyCheck: mockIdempotency,
+				logger:           zap.NewNop(),
+			}
+			mocks.injectIntoService(service)
+
+			ctx := contextWithUserID(uuidToString(senderID))
+			mockIdempotency.On("Check", ctx, "key-123").Return(nil)
+
+			_, err := service.SendMessage(ctx, &chatv1pb.SendMessageRequest{
+				ConversationId: uuidToString(conversationID),
+				Content:        "Hello",
+				IdempotencyKey: "key-123",
+			})
+			require.NoError(t, err)
+
+			var event map[string]interface{}
+			require.NoError(t, json.Unmarshal(capturedOutbox.Payload, &event))
+			_, flagged := event["new_participants"]
+			assert.Equal(t, tc.joined > 0, flagged)
+		})
+	}
+}
+
 func TestCreateMessageEventPayload_WithDifferentContent(t *testing.T) {
 	logger := zap.NewNop()
 	service := &ChatService{logger: logger}
@@ -406,7 +780,7 @@ func TestCreateMessageEventPayload_WithDifferentContent(t *testing.T) {
 			message.CreatedAt.Scan(time.Now())
 
 			receiverIDs := []string{"receiver-1"}
-			payload, err := service.createMessageEventPayload(message, receiverIDs)
+			payload, err := service.createMessageEventPayload(message, profile.Profile{}, receiverIDs, nil, nil, false, nil, eventOrigin{})
 
 			assert.NoError(t, err)
 			assert.NotNil(t, payload)
@@ -0,0 +1,136 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	chatv1 "chat-service/api/chat/v1"
+	"chat-service/internal/repository"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+const testRetentionConversationID = "550e8400-e29b-41d4-a716-446655440000"
+
+// newRetentionTestService returns a service whose caller is a participant and whose
+// retention update succeeds, capturing its params
+func newRetentionTestService(captured *repository.SetConversationRetentionParams) *ChatService {
+	service := &ChatService{logger: zap.NewNop()}
+	service.isParticipantFn = func(ctx context.Context, arg repository.IsParticipantParams) (bool, error) {
+		return true, nil
+	}
+	service.setConversationRetentionFn = func(ctx context.Context, arg repository.SetConversationRetentionParams) (int64, error) {
+		*captured = arg
+		return 1, nil
+	}
+	return service
+}
+
+func TestSetConversationRetention_ValidationErrors(t *testing.T) {
+	var captured repository.SetConversationRetentionParams
+	service := newRetentionTestService(&captured)
+
+	tests := []struct {
+		name string
+		req  *chatv1.SetConversationRetentionRequest
+	}{
+		{name: "nil request", req: nil},
+		{name: "empty conversation", req: &chatv1.SetConversationRetentionRequest{MessageTtlSeconds: 86400}},
+		{name: "invalid uuid", req: &chatv1.SetConversationRetentionRequest{ConversationId: "not-a-uuid", MessageTtlSeconds: 86400}},
+		{name: "negative ttl", req: &chatv1.SetConversationRetentionRequest{ConversationId: testRetentionConversationID, MessageTtlSeconds: -1}},
+		{name: "ttl below minimum", req: &chatv1.SetConversationRetentionRequest{ConversationId: testRetentionConversationID, MessageTtlSeconds: 59}},
+		{name: "ttl above maximum", req: &chatv1.SetConversationRetentionRequest{ConversationId: testRetentionConversationID, MessageTtlSeconds: 1 << 40}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			resp, err := service.SetConversationRetention(contextWithUserID(testReaderID), tt.req)
+			assert.Nil(t, resp)
+			assert.Equal(t, codes.InvalidArgument, status.Code(err))
+		})
+	}
+}
+
+func TestSetConversationRetention_Success(t *testing.T) {
+	tests := []struct {
+		name      string
+		ttl       int64
+		wantValid bool
+	}{
+		{name: "seven days", ttl: 7 * 86400, wantValid: true},
+		{name: "zero keeps messages forever", ttl: 0, wantValid: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var captured repository.SetConversationRetentionParams
+			service := newRetentionTestService(&captured)
+
+			resp, err := service.SetConversationRetention(contextWithUserID(testReaderID), &chatv1.SetConversationRetentionRequest{
+				ConversationId:    testRetentionConversationID,
+				MessageTtlSeconds: tt.ttl,
+			})
+
+			require.NoError(t, err)
+			assert.True(t, resp.Success)
+			assert.Equal(t, tt.ttl, resp.MessageTtlSeconds)
+			assert.Equal(t, mustParseUUID(t, testRetentionConversationID), captured.ID)
+			assert.Equal(t, tt.wantValid, captured.MessageTtlSeconds.Valid)
+			assert.Equal(t, int32(tt.ttl), captured.MessageTtlSeconds.Int32)
+		})
+	}
+}
+
+func TestSetConversationRetention_NotParticipant(t *testing.T) {
+	var captured repository.SetConversationRetentionParams
+	service := newRetentionTestService(&captured)
+	service.isParticipantFn = func(ctx context.Context, arg repository.IsParticipantParams) (bool, error) {
+		return false, nil
+	}
+	updated := false
+	service.setConversationRetentionFn = func(ctx context.Context, arg repository.SetConversationRetentionParams) (int64, error) {
+		updated = true
+		return 1, nil
+	}
+
+	_, err := service.SetConversationRetention(contextWithUserID(testReaderID), &chatv1.SetConversationRetentionRequest{
+		ConversationId:    testRetentionConversationID,
+		MessageTtlSeconds: 86400,
+	})
+
+	assert.Equal(t, codes.PermissionDenied, status.Code(err))
+	assert.False(t, updated, "non-participants must not change retention")
+}
+
+func TestSetConversationRetention_Errors(t *testing.T) {
+	tests := []struct {
+		name     string
+		rows     int64
+		err      error
+		wantCode codes.Code
+	}{
+		{name: "conversation missing", rows: 0, wantCode: codes.NotFound},
+		{name: "database error", err: errors.New("connection reset"), wantCode: codes.Internal},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var captured repository.SetConversationRetentionParams
+			service := newRetentionTestService(&captured)
+			service.setConversationRetentionFn = func(ctx context.Context, arg repository.SetConversationRetentionParams) (int64, error) {
+				return tt.rows, tt.err
+			}
+
+			_, err := service.SetConversationRetention(contextWithUserID(testReaderID), &chatv1.SetConversationRetentionRequest{
+				ConversationId:    testRetentionConversationID,
+				MessageTtlSeconds: 86400,
+			})
+			assert.Equal(t, tt.wantCode, status.Code(err))
+		})
+	}
+}
@@ -63,6 +63,7 @@ func TestProperty_TransactionAtomicityOnFailure(t *testing.T) {
 			// Create mock idempotency checker
 			mockIdempotency := new(MockIdempotencyChecker)
 			mockIdempotency.On("Check", mock.Anything, mock.Anything).Return(nil)
+			mockIdempotency.On("Remove", mock.Anything, mock.Anything).Return(nil)
 
 			// Create service
 			service := &ChatService{
@@ -75,13 +76,13 @@ func TestProperty_TransactionAtomicityOnFailure(t *testing.T) {
 			mocks.mockBeginTx = func(ctx context.Context) (repository.DBTX, error) {
 				return mocks.mockTx, nil
 			}
-			mocks.mockUpsertConversation = func(ctx context.Context, qtx *repository.Queries, id pgtype.UUID) (repository.Conversation, error) {
-				return repository.Conversation{ID: conversationUUID}, nil
+			mocks.mockUpsertConversation = func(ctx context.Context, qtx *repository.Queries, id pgtype.UUID) (repository.UpsertConversationRow, error) {
+				return repository.UpsertConversationRow{ID: conversationUUID}, nil
 			}
 			// Simulate failure during bulk participant insertion
-			mocks.mockAddConversationParticipants = func(ctx context.Context, qtx *repository.Queries, params repository.AddConversationParticipantsParams) error {
+			mocks.mockAddConversationParticipants = func(ctx context.Context, qtx *repository.Queries, params repository.AddConversationParticipantsParams) (int64, error) {
 				// Return error to simulate database failure
-				return errors.New("simulated database failure during participant insertion")
+				return 0, errors.New("simulated database failure during participant insertion")
 			}
 			// Track if message insertion is attempted (it should NOT be called)
 			mocks.mockInsertMessage = func(ctx context.Context, qtx *repository.Queries, params repository.InsertMessageParams) (repository.Message, error) {
@@ -172,6 +173,7 @@ func TestProperty_TransactionAtomicityOnFailure(t *testing.T) {
 			// Create mock idempotency checker
 			mockIdempotency := new(MockIdempotencyChecker)
 			mockIdempotency.On("Check", mock.Anything, mock.Anything).Return(nil)
+			mockIdempotency.On("Remove", mock.Anything, mock.Anything).Return(nil)
 
 			// Create service
 			service := &ChatService{
@@ -184,13 +186,13 @@ func TestProperty_TransactionAtomicityOnFailure(t *testing.T) {
 			mocks.mockBeginTx = func(ctx context.Context) (repository.DBTX, error) {
 				return mocks.mockTx, nil
 			}
-			mocks.mockUpsertConversation = func(ctx context.Context, qtx *repository.Queries, id pgtype.UUID) (repository.Conversation, error) {
-				return repository.Conversation{ID: conversationUUID}, nil
+			mocks.mockUpsertConversation = func(ctx context.Context, qtx *repository.Queries, id pgtype.UUID) (repository.UpsertConversationRow, error) {
+				return repository.UpsertConversationRow{ID: conversationUUID}, nil
 			}
 			// Participants are added successfully
-			mocks.mockAddConversationParticipants = func(ctx context.Context, qtx *repository.Queries, params repository.AddConversationParticipantsParams) error {
+			mocks.mockAddConversationParticipants = func(ctx context.Context, qtx *repository.Queries, params repository.AddConversationParticipantsParams) (int64, error) {
 				participantsAddedCount = len(params.Column2)
-				return nil
+				return 0, nil
 			}
 			// Simulate failure during message insertion
 			mocks.mockInsertMessage = func(ctx context.Context, qtx *repository.Queries, params repository.InsertMessageParams) (repository.Message, error) {
@@ -288,6 +290,7 @@ func TestProperty_TransactionAtomicityOnFailure(t *testing.T) {
 			// Create mock idempotency checker
 			mockIdempotency := new(MockIdempotencyChecker)
 			mockIdempotency.On("Check", mock.Anything, mock.Anything).Return(nil)
+			mockIdempotency.On("Remove", mock.Anything, mock.Anything).Return(nil)
 
 			// Create service
 			service := &ChatService{
@@ -300,11 +303,11 @@ func TestProperty_TransactionAtomicityOnFailure(t *testing.T) {
 			mocks.mockBeginTx = func(ctx context.Context) (repository.DBTX, error) {
 				return mocks.mockTx, nil
 			}
-			mocks.mockUpsertConversation = func(ctx context.Context, qtx *repository.Queries, id pgtype.UUID) (repository.Conversation, error) {
-				return repository.Conversation{ID: conversationUUID}, nil
+			mocks.mockUpsertConversation = func(ctx context.Context, qtx *repository.Queries, id pgtype.UUID) (repository.UpsertConversationRow, error) {
+				return repository.UpsertConversationRow{ID: conversationUUID}, nil
 			}
-			mocks.mockAddConversationParticipants = func(ctx context.Context, qtx *repository.Queries, params repository.AddConversationParticipantsParams) error {
-				return nil
+			mocks.mockAddConversationParticipants = func(ctx context.Context, qtx *repository.Queries, params repository.AddConversationParticipantsParams) (int64, error) {
+				return 0, nil
 			}
 			mocks.mockInsertMessage = func(ctx context.Context, qtx *repository.Queries, params repository.InsertMessageParams) (repository.Message, error) {
 				msg := repository.Message{
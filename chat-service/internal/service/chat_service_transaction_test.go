@@ -1,12 +1,21 @@
 package service
 
 import (
+	"context"
+	"encoding/json"
 	"errors"
 	"testing"
+	"time"
 
 	chatv1pb "chat-service/api/chat/v1"
+	chatv1 "chat-service/internal/repository"
+	"chat-service/pkg/idempotency"
+	"chat-service/pkg/profile"
 
+	"github.com/jackc/pgx/v5/pgtype"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
 	"go.uber.org/zap"
 	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/status"
@@ -97,6 +106,146 @@ func TestSendMessage_HappyPath_VerifyTransactionOrder(t *testing.T) {
 	mockIdempotency.AssertExpectations(t)
 }
 
+// TestSendMessage_OutboxCarriesSenderProfile verifies the resolved sender profile is denormalized into the event
+func TestSendMessage_OutboxCarriesSenderProfile(t *testing.T) {
+	mockIdempotency := new(MockIdempotencyChecker)
+	mockTxHelpers := newMockTransactionHelpers()
+
+	conversationID := mustParseUUID(t, "550e8400-e29b-41d4-a716-446655440000")
+	senderID := mustParseUUID(t, "660e8400-e29b-41d4-a716-446655440000")
+	messageID := mustParseUUID(t, "770e8400-e29b-41d4-a716-446655440000")
+	mockTxHelpers.setupHappyPathTransaction(conversationID, senderID, messageID, "Hello")
+
+	var outboxPayload []byte
+	mockTxHelpers.mockInsertOutbox = func(ctx context.Context, qtx *chatv1.Queries, params chatv1.InsertOutboxParams) error {
+		outboxPayload = params.Payload
+		return nil
+	}
+
+	service := &ChatService{
+		idempotencyCheck: mockIdempotency,
+		logger:           zap.NewNop(),
+	}
+	mockTxHelpers.injectIntoService(service)
+	service.SetProfileResolver(&stubProfileResolver{profiles: map[string]profile.Profile{
+		uuidToString(senderID): {DisplayName: "Alice", AvatarURL: "https://cdn.example.com/alice.png"},
+	}})
+
+	ctx := contextWithUserID(uuidToString(senderID))
+	mockIdempotency.On("Check", ctx, "profile-key").Return(nil)
+
+	_, err := service.SendMessage(ctx, &chatv1pb.SendMessageRequest{
+		ConversationId: uuidToString(conversationID),
+		Content:        "Hello",
+		IdempotencyKey: "profile-key",
+	})
+	require.NoError(t, err)
+
+	var event map[string]interface{}
+	require.NoError(t, json.Unmarshal(outboxPayload, &event))
+	assert.Equal(t, "Alice", event["sender_name"])
+	assert.Equal(t, "https://cdn.example.com/alice.png", event["sender_avatar_url"])
+}
+
+// TestSendMessage_DuplicateReceiversDeliveredOnce verifies a receiver listed several times (in any
+// spelling) and a sender listing itself are inserted and delivered once
+func TestSendMessage_DuplicateReceiversDeliveredOnce(t *testing.T) {
+	mockIdempotency := new(MockIdempotencyChecker)
+	mockTxHelpers := newMockTransactionHelpers()
+
+	conversationID := mustParseUUID(t, "550e8400-e29b-41d4-a716-446655440000")
+	senderID := mustParseUUID(t, "660e8400-e29b-41d4-a716-446655440000")
+	messageID := mustParseUUID(t, "770e8400-e29b-41d4-a716-446655440000")
+	receiverID := mustParseUUID(t, "880e8400-e29b-41d4-a716-446655440000")
+	mockTxHelpers.setupHappyPathTransaction(conversationID, senderID, messageID, "Hello")
+
+	var inserted []pgtype.UUID
+	mockTxHelpers.mockAddConversationParticipants = func(ctx context.Context, qtx *chatv1.Queries, params chatv1.AddConversationParticipantsParams) (int64, error) {
+		inserted = params.Column2
+		return 0, nil
+	}
+	mockTxHelpers.mockGetConversationParticipants = func(ctx context.Context, qtx *chatv1.Queries, convID pgtype.UUID) ([]pgtype.UUID, error) {
+		return []pgtype.UUID{senderID, receiverID, receiverID}, nil
+	}
+	var outboxPayload []byte
+	mockTxHelpers.mockInsertOutbox = func(ctx context.Context, qtx *chatv1.Queries, params chatv1.InsertOutboxParams) error {
+		outboxPayload = params.Payload
+		return nil
+	}
+
+	service := &ChatService{
+		idempotencyCheck: mockIdempotency,
+		logger:           zap.NewNop(),
+	}
+	mockTxHelpers.injectIntoService(service)
+
+	ctx := contextWithUserID(uuidToString(senderID))
+	mockIdempotency.On("Check", ctx, "dup-receivers-key").Return(nil)
+
+	_, err := service.SendMessage(ctx, &chatv1pb.SendMessageRequest{
+		ConversationId: uuidToString(conversationID),
+		Content:        "Hello",
+		IdempotencyKey: "dup-receivers-key",
+		ReceiverIds: []string{
+			"880e8400-e29b-41d4-a716-446655440000",
+			"880E8400-E29B-41D4-A716-446655440000",
+			uuidToString(senderID),
+			"880e8400-e29b-41d4-a716-446655440000",
+		},
+	})
+	require.NoError(t, err)
+
+	assert.Equal(t, []pgtype.UUID{senderID, receiverID}, inserted)
+
+	var event map[string]interface{}
+	require.NoError(t, json.Unmarshal(outboxPayload, &event))
+	assert.Equal(t, []interface{}{uuidToString(receiverID)}, event["receiver_ids"])
+}
+
+// TestSendMessage_ReturnsCreatedConversation verifies the first message of a conversation
+// returns it, so the client doesn't need to refetch its conversation list
+func TestSendMessage_ReturnsCreatedConversation(t *testing.T) {
+	for _, inserted := range []bool{true, false} {
+		mockIdempotency := new(MockIdempotencyChecker)
+		mockTxHelpers := newMockTransactionHelpers()
+
+		conversationID := mustParseUUID(t, "550e8400-e29b-41d4-a716-446655440000")
+		senderID := mustParseUUID(t, "660e8400-e29b-41d4-a716-446655440000")
+		messageID := mustParseUUID(t, "770e8400-e29b-41d4-a716-446655440000")
+		mockTxHelpers.setupHappyPathTransaction(conversationID, senderID, messageID, "Hello")
+		mockTxHelpers.mockUpsertConversation = func(ctx context.Context, qtx *chatv1.Queries, id pgtype.UUID) (chatv1.UpsertConversationRow, error) {
+			return chatv1.UpsertConversationRow{ID: id, Inserted: inserted}, nil
+		}
+
+		service := &ChatService{
+			idempotencyCheck: mockIdempotency,
+			logger:           zap.NewNop(),
+		}
+		mockTxHelpers.injectIntoService(service)
+
+		ctx := contextWithUserID(uuidToString(senderID))
+		mockIdempotency.On("Check", ctx, "created-key").Return(nil)
+
+		resp, err := service.SendMessage(ctx, &chatv1pb.SendMessageRequest{
+			ConversationId: uuidToString(conversationID),
+			Content:        "Hello",
+			IdempotencyKey: "created-key",
+		})
+		require.NoError(t, err)
+
+		assert.Equal(t, inserted, resp.ConversationCreated)
+		if !inserted {
+			assert.Nil(t, resp.Conversation, "existing conversations are not echoed back")
+			continue
+		}
+		require.NotNil(t, resp.Conversation)
+		assert.Equal(t, uuidToString(conversationID), resp.Conversation.Id)
+		assert.Equal(t, "Hello", resp.Conversation.LastMessageContent)
+		assert.NotEmpty(t, resp.Conversation.LastMessageAt)
+		assert.Zero(t, resp.Conversation.UnreadCount)
+	}
+}
+
 // TestMockTransactionHelpers_BeginTxError verifies transaction begin error handling
 func TestMockTransactionHelpers_BeginTxError(t *testing.T) {
 	logger := zap.NewNop()
@@ -116,6 +265,7 @@ func TestMockTransactionHelpers_BeginTxError(t *testing.T) {
 	// Mock idempotency check
 	ctx := contextWithUserID("660e8400-e29b-41d4-a716-446655440000")
 	mockIdempotency.On("Check", ctx, "test-key").Return(nil)
+	mockIdempotency.On("Remove", mock.Anything, "test-key").Return(nil)
 
 	req := &chatv1pb.SendMessageRequest{
 		ConversationId: "550e8400-e29b-41d4-a716-446655440000",
@@ -152,6 +302,7 @@ func TestMockTransactionHelpers_UpsertConversationError(t *testing.T) {
 	// Mock idempotency check
 	ctx := contextWithUserID("660e8400-e29b-41d4-a716-446655440000")
 	mockIdempotency.On("Check", ctx, "test-key").Return(nil)
+	mockIdempotency.On("Remove", mock.Anything, "test-key").Return(nil)
 
 	req := &chatv1pb.SendMessageRequest{
 		ConversationId: "550e8400-e29b-41d4-a716-446655440000",
@@ -190,6 +341,7 @@ func TestMockTransactionHelpers_InsertMessageError(t *testing.T) {
 	// Mock idempotency check
 	ctx := contextWithUserID("660e8400-e29b-41d4-a716-446655440000")
 	mockIdempotency.On("Check", ctx, "test-key").Return(nil)
+	mockIdempotency.On("Remove", mock.Anything, "test-key").Return(nil)
 
 	req := &chatv1pb.SendMessageRequest{
 		ConversationId: "550e8400-e29b-41d4-a716-446655440000",
@@ -231,6 +383,7 @@ func TestMockTransactionHelpers_CommitError(t *testing.T) {
 	// Mock idempotency check
 	ctx := contextWithUserID(uuidToString(senderID))
 	mockIdempotency.On("Check", ctx, "test-key").Return(nil)
+	mockIdempotency.On("Remove", mock.Anything, "test-key").Return(nil)
 
 	req := &chatv1pb.SendMessageRequest{
 		ConversationId: uuidToString(conversationID),
@@ -274,6 +427,7 @@ func TestSendMessage_TransactionBeginFailure(t *testing.T) {
 
 	// Mock idempotency check to succeed
 	mockIdempotency.On("Check", ctx, "key-123").Return(nil)
+	mockIdempotency.On("Remove", mock.Anything, "key-123").Return(nil)
 
 	resp, err := service.SendMessage(ctx, req)
 
@@ -310,6 +464,7 @@ func TestSendMessage_UpsertConversationFailure(t *testing.T) {
 
 	// Mock idempotency check to succeed
 	mockIdempotency.On("Check", ctx, "key-123").Return(nil)
+	mockIdempotency.On("Remove", mock.Anything, "key-123").Return(nil)
 
 	resp, err := service.SendMessage(ctx, req)
 
@@ -348,6 +503,7 @@ func TestSendMessage_InsertMessageFailure(t *testing.T) {
 
 	// Mock idempotency check to succeed
 	mockIdempotency.On("Check", ctx, "key-123").Return(nil)
+	mockIdempotency.On("Remove", mock.Anything, "key-123").Return(nil)
 
 	resp, err := service.SendMessage(ctx, req)
 
@@ -388,6 +544,7 @@ func TestSendMessage_InsertOutboxFailure(t *testing.T) {
 
 	// Mock idempotency check to succeed
 	mockIdempotency.On("Check", ctx, "key-123").Return(nil)
+	mockIdempotency.On("Remove", mock.Anything, "key-123").Return(nil)
 
 	resp, err := service.SendMessage(ctx, req)
 
@@ -428,6 +585,7 @@ func TestSendMessage_CommitFailure(t *testing.T) {
 
 	// Mock idempotency check to succeed
 	mockIdempotency.On("Check", ctx, "key-123").Return(nil)
+	mockIdempotency.On("Remove", mock.Anything, "key-123").Return(nil)
 
 	resp, err := service.SendMessage(ctx, req)
 
@@ -437,3 +595,149 @@ func TestSendMessage_CommitFailure(t *testing.T) {
 	assert.Contains(t, err.Error(), "failed to send message")
 	mockIdempotency.AssertExpectations(t)
 }
+
+// memoryIdempotencyChecker mirrors RedisChecker's SETNX semantics in memory,
+// so a test can observe whether a key is still claimed after a failed send
+type memoryIdempotencyChecker struct {
+	keys       map[string]bool
+	removeErr  error
+	removeErrs []error // ctx.Err() seen by each Remove call
+}
+
+func newMemoryIdempotencyChecker() *memoryIdempotencyChecker {
+	return &memoryIdempotencyChecker{keys: make(map[string]bool)}
+}
+
+func (m *memoryIdempotencyChecker) Check(ctx context.Context, key string) error {
+	return m.CheckWithTTL(ctx, key, idempotency.DefaultTTL)
+}
+
+func (m *memoryIdempotencyChecker) CheckWithTTL(_ context.Context, key string, _ time.Duration) error {
+	if m.keys[key] {
+		return idempotency.ErrDuplicateRequest
+	}
+	m.keys[key] = true
+	return nil
+}
+
+func (m *memoryIdempotencyChecker) Remove(ctx context.Context, key string) error {
+	m.removeErrs = append(m.removeErrs, ctx.Err())
+	if m.removeErr != nil {
+		return m.removeErr
+	}
+	delete(m.keys, key)
+	return nil
+}
+
+func (m *memoryIdempotencyChecker) TTL(_ context.Context, key string) (time.Duration, error) {
+	if !m.keys[key] {
+		return 0, idempotency.ErrKeyNotFound
+	}
+	return idempotency.DefaultTTL, nil
+}
+
+// TestSendMessage_RetryAfterFailedTransactionSucceeds verifies that a failed transaction
+// releases the idempotency key, so retrying with the same key is not rejected as a duplicate
+func TestSendMessage_RetryAfterFailedTransactionSucceeds(t *testing.T) {
+	checker := newMemoryIdempotencyChecker()
+	mockTxHelpers := newMockTransactionHelpers()
+
+	conversationID := mustParseUUID(t, "550e8400-e29b-41d4-a716-446655440000")
+	senderID := mustParseUUID(t, "660e8400-e29b-41d4-a716-446655440000")
+	messageID := mustParseUUID(t, "770e8400-e29b-41d4-a716-446655440000")
+	mockTxHelpers.setupHappyPathTransaction(conversationID, senderID, messageID, "Hello World")
+
+	// First commit fails, the retry commits
+	commits := 0
+	mockTxHelpers.mockCommitTx = func(ctx context.Context, tx chatv1.DBTX) error {
+		commits++
+		if commits == 1 {
+			return errors.New("connection reset")
+		}
+		return nil
+	}
+
+	service := &ChatService{
+		idempotencyCheck: checker,
+		logger:           zap.NewNop(),
+	}
+	mockTxHelpers.injectIntoService(service)
+
+	ctx := contextWithUserID(uuidToString(senderID))
+	req := &chatv1pb.SendMessageRequest{
+		ConversationId: uuidToString(conversationID),
+		Content:        "Hello World",
+		IdempotencyKey: "retry-key",
+	}
+
+	resp, err := service.SendMessage(ctx, req)
+	require.Error(t, err)
+	assert.Nil(t, resp)
+	assert.Equal(t, codes.Internal, status.Code(err))
+	assert.False(t, checker.keys["retry-key"], "key should be released after the failed transaction")
+
+	resp, err = service.SendMessage(ctx, req)
+	require.NoError(t, err)
+	assert.Equal(t, "SENT", resp.Status)
+	assert.True(t, checker.keys["retry-key"], "key should stay claimed after a successful send")
+
+	// A third attempt is a genuine duplicate
+	_, err = service.SendMessage(ctx, req)
+	assert.Equal(t, codes.AlreadyExists, status.Code(err))
+}
+
+// TestSendMessage_ReleasesKeyWhenRequestCancelled verifies the key is released even though
+// the request context is already done
+func TestSendMessage_ReleasesKeyWhenRequestCancelled(t *testing.T) {
+	checker := newMemoryIdempotencyChecker()
+	mockTxHelpers := newMockTransactionHelpers()
+	mockTxHelpers.setupBeginTxError(context.Canceled)
+
+	service := &ChatService{
+		idempotencyCheck: checker,
+		logger:           zap.NewNop(),
+	}
+	mockTxHelpers.injectIntoService(service)
+
+	ctx, cancel := context.WithCancel(contextWithUserID("660e8400-e29b-41d4-a716-446655440000"))
+	cancel()
+	req := &chatv1pb.SendMessageRequest{
+		ConversationId: "550e8400-e29b-41d4-a716-446655440000",
+		Content:        "Hello World",
+		IdempotencyKey: "cancelled-key",
+	}
+
+	_, err := service.SendMessage(ctx, req)
+	require.Error(t, err)
+
+	require.Len(t, checker.removeErrs, 1)
+	assert.NoError(t, checker.removeErrs[0], "Remove must not inherit the request's cancellation")
+	assert.False(t, checker.keys["cancelled-key"])
+}
+
+// TestSendMessage_ReleaseFailureKeepsOriginalError verifies a failed Remove doesn't mask the send error
+func TestSendMessage_ReleaseFailureKeepsOriginalError(t *testing.T) {
+	checker := newMemoryIdempotencyChecker()
+	checker.removeErr = errors.New("redis unavailable")
+	mockTxHelpers := newMockTransactionHelpers()
+	mockTxHelpers.setupBeginTxError(errors.New("connection pool exhausted"))
+
+	service := &ChatService{
+		idempotencyCheck: checker,
+		logger:           zap.NewNop(),
+	}
+	mockTxHelpers.injectIntoService(service)
+
+	ctx := contextWithUserID("660e8400-e29b-41d4-a716-446655440000")
+	req := &chatv1pb.SendMessageRequest{
+		ConversationId: "550e8400-e29b-41d4-a716-446655440000",
+		Content:        "Hello World",
+		IdempotencyKey: "key-123",
+	}
+
+	resp, err := service.SendMessage(ctx, req)
+
+	assert.Nil(t, resp)
+	assert.Equal(t, codes.Internal, status.Code(err))
+	assert.Contains(t, err.Error(), "failed to send message")
+}
@@ -196,13 +196,13 @@ func TestInjectableFunctions(t *testing.T) {
 
 	t.Run("upsertConversation uses injectable function", func(t *testing.T) {
 		called := false
-		expectedConv := repository.Conversation{
+		expectedConv := repository.UpsertConversationRow{
 			ID: mustParseUUID(t, "550e8400-e29b-41d4-a716-446655440000"),
 		}
 
 		service := &ChatService{
 			logger: logger,
-			upsertConversationFn: func(ctx context.Context, qtx *repository.Queries, id pgtype.UUID) (repository.Conversation, error) {
+			upsertConversationFn: func(ctx context.Context, qtx *repository.Queries, id pgtype.UUID) (repository.UpsertConversationRow, error) {
 				called = true
 				return expectedConv, nil
 			},
@@ -252,3 +252,37 @@ func TestGetConversations_MissingUserIDInContext(t *testing.T) {
 	assert.Equal(t, codes.Unauthenticated, status.Code(err))
 	assert.Contains(t, err.Error(), "user_id not found in context")
 }
+
+func TestQueryTimeout_AppliedToRepositoryCalls(t *testing.T) {
+	service := &ChatService{logger: zap.NewNop()}
+	service.SetQueryTimeout(50 * time.Millisecond)
+
+	var deadline time.Time
+	var hasDeadline bool
+	service.isParticipantFn = func(ctx context.Context, arg repository.IsParticipantParams) (bool, error) {
+		deadline, hasDeadline = ctx.Deadline()
+		<-ctx.Done()
+		return false, ctx.Err()
+	}
+
+	start := time.Now()
+	_, err := service.isParticipant(context.Background(), repository.IsParticipantParams{})
+
+	assert.ErrorIs(t, err, context.DeadlineExceeded, "A stalled query should be cut off by the query timeout")
+	assert.True(t, hasDeadline)
+	assert.WithinDuration(t, start.Add(50*time.Millisecond), deadline, 20*time.Millisecond)
+}
+
+func TestQueryTimeout_DisabledByDefault(t *testing.T) {
+	service := &ChatService{logger: zap.NewNop()}
+
+	var hasDeadline bool
+	service.isParticipantFn = func(ctx context.Context, arg repository.IsParticipantParams) (bool, error) {
+		_, hasDeadline = ctx.Deadline()
+		return true, nil
+	}
+
+	_, err := service.isParticipant(context.Background(), repository.IsParticipantParams{})
+	assert.NoError(t, err)
+	assert.False(t, hasDeadline, "Without a query timeout only the caller's deadline applies")
+}
@@ -0,0 +1,107 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+
+	chatv1 "chat-service/api/chat/v1"
+	"chat-service/internal/apierror"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// ErrContentRejected is wrapped by content filters that refuse a message; SendMessage answers
+// InvalidArgument. Any other filter error fails the send with Internal.
+var ErrContentRejected = errors.New("content rejected")
+
+// Moderation flags set by the built-in filters
+const (
+	ModerationFlagWordlist = "wordlist" // Content contains a word of the flagged wordlist
+)
+
+// ContentFilter decides whether message content may be sent. It either rejects the content with
+// an error wrapping ErrContentRejected, or accepts it, optionally with moderation flags: flagged
+// messages are sent as usual and their outbox event carries the flags for downstream review.
+// It runs before the idempotency check and the send transaction, so it may call out to a
+// moderation service without holding a database connection.
+type ContentFilter interface {
+	FilterContent(ctx context.Context, content string) (flags []string, err error)
+}
+
+// NoopContentFilter accepts all content unflagged. It is the default.
+type NoopContentFilter struct{}
+
+// FilterContent implements ContentFilter
+func (NoopContentFilter) FilterContent(context.Context, string) ([]string, error) {
+	return nil, nil
+}
+
+// WordlistContentFilter rejects content containing a blocked word and flags content containing
+// a flagged word. Words match whole words only, case-insensitively, so "class" does not match "ass".
+type WordlistContentFilter struct {
+	blocked *regexp.Regexp // nil = nothing is blocked
+	flagged *regexp.Regexp // nil = nothing is flagged
+}
+
+// NewWordlistContentFilter creates a filter for the given blocked and flagged words
+func NewWordlistContentFilter(blocked, flagged []string) *WordlistContentFilter {
+	return &WordlistContentFilter{
+		blocked: wordlistPattern(blocked),
+		flagged: wordlistPattern(flagged),
+	}
+}
+
+// FilterContent implements ContentFilter
+func (f *WordlistContentFilter) FilterContent(_ context.Context, content string) ([]string, error) {
+	if f.blocked != nil && f.blocked.MatchString(content) {
+		return nil, fmt.Errorf("%w: content contains a blocked word", ErrContentRejected)
+	}
+	if f.flagged != nil && f.flagged.MatchString(content) {
+		return []string{ModerationFlagWordlist}, nil
+	}
+	return nil, nil
+}
+
+// wordlistPattern builds a case-insensitive whole-word pattern matching any of words
+func wordlistPattern(words []string) *regexp.Regexp {
+	quoted := make([]string, 0, len(words))
+	for _, word := range words {
+		if word = strings.TrimSpace(word); word != "" {
+			quoted = append(quoted, regexp.QuoteMeta(word))
+		}
+	}
+	if len(quoted) == 0 {
+		return nil
+	}
+	return regexp.MustCompile(`(?i)(?:^|[^\pL\pN_])(?:` + strings.Join(quoted, "|") + `)(?:$|[^\pL\pN_])`)
+}
+
+// SetContentFilter installs a filter on message content, e.g. a wordlist or a moderation
+// service. Nil restores the default, which accepts everything.
+func (s *ChatService) SetContentFilter(filter ContentFilter) {
+	if filter == nil {
+		filter = NoopContentFilter{}
+	}
+	s.contentFilter = filter
+}
+
+// filterContent runs the configured ContentFilter and maps its error to a status.
+// System messages come from trusted callers and are not filtered, nor is empty content.
+func (s *ChatService) filterContent(ctx context.Context, content string, msgType chatv1.MessageType) ([]string, error) {
+	if s.contentFilter == nil || content == "" || msgType == chatv1.MessageType_MESSAGE_TYPE_SYSTEM {
+		return nil, nil
+	}
+	flags, err := s.contentFilter.FilterContent(ctx, content)
+	switch {
+	case err == nil:
+		return flags, nil
+	case errors.Is(err, ErrContentRejected):
+		return nil, apierror.Validation("content", err.Error())
+	}
+	// The filter itself failed (e.g. moderation service unreachable)
+	return nil, status.Error(codes.Internal, "failed to check content")
+}
@@ -0,0 +1,141 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"testing"
+
+	chatv1 "chat-service/api/chat/v1"
+	"chat-service/internal/repository"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// contentFilterFunc adapts a function to ContentFilter
+type contentFilterFunc func(ctx context.Context, content string) ([]string, error)
+
+func (f contentFilterFunc) FilterContent(ctx context.Context, content string) ([]string, error) {
+	return f(ctx, content)
+}
+
+func TestWordlistContentFilter(t *testing.T) {
+	filter := NewWordlistContentFilter([]string{"spamlink.example", " Scam "}, []string{"darn", ""})
+
+	tests := []struct {
+		content   string
+		wantFlags []string
+		wantErr   bool
+	}{
+		{content: "hello there"},
+		{content: "what a SCAM!", wantErr: true},
+		{content: "visit spamlink.example now", wantErr: true},
+		{content: "scampi for dinner", wantFlags: nil},
+		{content: "darn it", wantFlags: []string{ModerationFlagWordlist}},
+		{content: "Darn, a scam", wantErr: true},
+		{content: "darning socks"},
+	}
+	for _, tt := range tests {
+		flags, err := filter.FilterContent(context.Background(), tt.content)
+		if tt.wantErr {
+			assert.ErrorIs(t, err, ErrContentRejected, tt.content)
+			continue
+		}
+		assert.NoError(t, err, tt.content)
+		assert.Equal(t, tt.wantFlags, flags, tt.content)
+	}
+
+	flags, err := NewWordlistContentFilter(nil, nil).FilterContent(context.Background(), "scam")
+	assert.NoError(t, err)
+	assert.Nil(t, flags, "empty wordlists accept everything")
+}
+
+func TestFilterContent(t *testing.T) {
+	service := &ChatService{logger: zap.NewNop()}
+	service.SetContentFilter(contentFilterFunc(func(context.Context, string) ([]string, error) {
+		return nil, errors.New("moderation service unreachable")
+	}))
+
+	_, err := service.filterContent(context.Background(), "hi", chatv1.MessageType_MESSAGE_TYPE_TEXT)
+	assert.Equal(t, codes.Internal, status.Code(err))
+
+	_, err = service.filterContent(context.Background(), "hi", chatv1.MessageType_MESSAGE_TYPE_SYSTEM)
+	assert.NoError(t, err, "system messages are not filtered")
+	_, err = service.filterContent(context.Background(), "", chatv1.MessageType_MESSAGE_TYPE_IMAGE)
+	assert.NoError(t, err, "empty content is not filtered")
+
+	service.SetContentFilter(nil)
+	flags, err := service.filterContent(context.Background(), "hi", chatv1.MessageType_MESSAGE_TYPE_TEXT)
+	assert.NoError(t, err)
+	assert.Nil(t, flags)
+}
+
+func TestSendMessage_ContentFilter(t *testing.T) {
+	tests := []struct {
+		name       string
+		content    string
+		wantCode   codes.Code
+		moderation map[string]interface{}
+	}{
+		{name: "clean", content: "hello", wantCode: codes.OK},
+		{name: "flagged", content: "darn it", wantCode: codes.OK, moderation: map[string]interface{}{
+			"flagged": true,
+			"flags":   []interface{}{ModerationFlagWordlist},
+		}},
+		{name: "blocked", content: "great scam", wantCode: codes.InvalidArgument},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockIdempotency := new(MockIdempotencyChecker)
+			mocks := newMockTransactionHelpers()
+
+			conversationID := mustParseUUID(t, "550e8400-e29b-41d4-a716-446655440000")
+			senderID := mustParseUUID(t, "660e8400-e29b-41d4-a716-446655440000")
+			messageID := mustParseUUID(t, "770e8400-e29b-41d4-a716-446655440000")
+			mocks.setupHappyPathTransaction(conversationID, senderID, messageID, tt.content)
+			var payload []byte
+			mocks.mockInsertOutbox = func(ctx context.Context, qtx *repository.Queries, params repository.InsertOutboxParams) error {
+				payload = params.Payload
+				return nil
+			}
+
+			service := &ChatService{
+				idempotencyCheck: mockIdempotency,
+				logger:           zap.NewNop(),
+			}
+			mocks.injectIntoService(service)
+			service.SetContentFilter(NewWordlistContentFilter([]string{"scam"}, []string{"darn"}))
+
+			ctx := contextWithUserID(uuidToString(senderID))
+			if tt.wantCode == codes.OK {
+				mockIdempotency.On("Check", ctx, "key-123").Return(nil)
+			}
+
+			_, err := service.SendMessage(ctx, &chatv1.SendMessageRequest{
+				ConversationId: uuidToString(conversationID),
+				Content:        tt.content,
+				IdempotencyKey: "key-123",
+			})
+			assert.Equal(t, tt.wantCode, status.Code(err))
+			// Rejected content never claims the idempotency key, so there is nothing to release
+			mockIdempotency.AssertExpectations(t)
+			if tt.wantCode != codes.OK {
+				assert.Nil(t, payload)
+				return
+			}
+
+			var event map[string]interface{}
+			require.NoError(t, json.Unmarshal(payload, &event))
+			if tt.moderation == nil {
+				assert.NotContains(t, event, "moderation")
+				return
+			}
+			assert.Equal(t, tt.moderation, event["moderation"])
+		})
+	}
+}
@@ -0,0 +1,159 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"unicode/utf8"
+
+	chatv1 "chat-service/api/chat/v1"
+	"chat-service/internal/apierror"
+
+	"github.com/jackc/pgx/v5/pgtype"
+	"google.golang.org/grpc/codes"
+)
+
+// Values of the conversations.type column
+const (
+	conversationTypeDirect = "direct"
+	conversationTypeGroup  = "group"
+)
+
+// MaxConversationNameLength caps a group name, in characters
+const MaxConversationNameLength = 100
+
+// Conversation type errors
+var (
+	ErrInvalidConversationType = errors.New("type must be DIRECT or GROUP")
+	ErrDirectParticipants      = errors.New("direct conversations have exactly two participants")
+	ErrNoParticipants          = errors.New("participant_ids must name at least one other user")
+	ErrConversationNameTooLong = fmt.Errorf("name is longer than %d characters", MaxConversationNameLength)
+	ErrDirectConversationName  = errors.New("only group conversations have a name")
+
+	// ErrSenderNotParticipant: only participants may send into a typed conversation (or, in strict
+	// mode, an existing group conversation)
+	ErrSenderNotParticipant = errors.New("not a participant of this conversation")
+	// ErrDirectConversationClosed: receiver_ids cannot add a third user to a direct conversation
+	ErrDirectConversationClosed = errors.New("cannot add participants to a direct conversation")
+	// ErrGroupTooLarge: the conversation would have more participants than the configured maximum
+	ErrGroupTooLarge = errors.New("conversation is full")
+)
+
+// conversationTypeColumn maps a requested type to its column value
+func conversationTypeColumn(t chatv1.ConversationType) (string, error) {
+	switch t {
+	case chatv1.ConversationType_CONVERSATION_TYPE_DIRECT:
+		return conversationTypeDirect, nil
+	case chatv1.ConversationType_CONVERSATION_TYPE_GROUP:
+		return conversationTypeGroup, nil
+	}
+	return "", ErrInvalidConversationType
+}
+
+// conversationTypeProto maps a conversations.type column to the API enum (NULL = UNSPECIFIED)
+func conversationTypeProto(t pgtype.Text) chatv1.ConversationType {
+	switch t.String {
+	case conversationTypeDirect:
+		return chatv1.ConversationType_CONVERSATION_TYPE_DIRECT
+	case conversationTypeGroup:
+		return chatv1.ConversationType_CONVERSATION_TYPE_GROUP
+	}
+	return chatv1.ConversationType_CONVERSATION_TYPE_UNSPECIFIED
+}
+
+// conversationName normalises a requested name: trimmed, NULL when empty
+func conversationName(name string, convType string) (pgtype.Text, error) {
+	name = strings.TrimSpace(name)
+	if name == "" {
+		return pgtype.Text{}, nil
+	}
+	if convType == conversationTypeDirect {
+		return pgtype.Text{}, ErrDirectConversationName
+	}
+	if utf8.RuneCountInString(name) > MaxConversationNameLength {
+		return pgtype.Text{}, ErrConversationNameTooLong
+	}
+	return pgtype.Text{String: name, Valid: true}, nil
+}
+
+// createParticipants parses the other participants of a new conversation: duplicates and the
+// creator are dropped, and the count must fit the conversation type
+func createParticipants(ids []string, creator pgtype.UUID, convType string) ([]pgtype.UUID, error) {
+	parsed, err := parseReceiverIDs(ids)
+	if err != nil {
+		return nil, err
+	}
+
+	others := make([]pgtype.UUID, 0, len(parsed))
+	for _, id := range parsed {
+		if id != creator {
+			others = append(others, id)
+		}
+	}
+
+	switch {
+	case convType == conversationTypeDirect && len(others) != 1:
+		return nil, ErrDirectParticipants
+	case len(others) == 0:
+		return nil, ErrNoParticipants
+	}
+	return others, nil
+}
+
+// checkTypedConversationSend applies a typed conversation's membership rules to a new message.
+// participants are the current members; receivers are the receiver_ids of the message.
+func checkTypedConversationSend(convType pgtype.Text, participants []pgtype.UUID, sender pgtype.UUID, receivers []pgtype.UUID) error {
+	if !convType.Valid {
+		return nil
+	}
+
+	members := make(map[pgtype.UUID]struct{}, len(participants))
+	for _, p := range participants {
+		members[p] = struct{}{}
+	}
+	if _, ok := members[sender]; !ok {
+		return ErrSenderNotParticipant
+	}
+
+	if convType.String == conversationTypeDirect {
+		for _, r := range receivers {
+			if _, ok := members[r]; !ok {
+				return ErrDirectConversationClosed
+			}
+		}
+	}
+	return nil
+}
+
+// checkStrictConversationSend applies strict participant mode to an existing untyped conversation.
+// Members may always send. An outsider may only self-join a direct conversation, i.e. one with a
+// single participant waiting for the other side; anything larger is a group and needs membership.
+func checkStrictConversationSend(participants []pgtype.UUID, sender pgtype.UUID) error {
+	for _, p := range participants {
+		if p == sender {
+			return nil
+		}
+	}
+	if len(participants) < 2 {
+		return nil
+	}
+	return ErrSenderNotParticipant
+}
+
+// conversationRuleError maps a typed conversation rule violation to a status, or returns nil
+func conversationRuleError(err error) error {
+	switch {
+	case errors.Is(err, ErrSenderNotParticipant):
+		return apierror.New(codes.PermissionDenied, apierror.CodePermissionDenied, ErrSenderNotParticipant.Error(), nil)
+	case errors.Is(err, ErrDirectConversationClosed):
+		return apierror.Validation("receiver_ids", ErrDirectConversationClosed.Error())
+	case errors.Is(err, ErrGroupTooLarge):
+		return groupTooLargeError(err)
+	}
+	return nil
+}
+
+// groupTooLargeError maps an error wrapping ErrGroupTooLarge to its status
+func groupTooLargeError(err error) error {
+	return apierror.New(codes.FailedPrecondition, apierror.CodeGroupTooLarge, err.Error(), nil)
+}
@@ -0,0 +1,83 @@
+package service
+
+import (
+	"encoding/base64"
+	"errors"
+	"strings"
+
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+// cursorSeparator splits the timestamp and id inside a decoded page cursor
+const cursorSeparator = "|"
+
+// pinnedCursorMarker follows the id in a cursor taken inside the pinned section of the conversation list
+const pinnedCursorMarker = "pinned"
+
+// ErrInvalidCursor is returned when a page cursor cannot be decoded
+var ErrInvalidCursor = errors.New("invalid cursor")
+
+// pageCursor is a keyset position: the (timestamp, id) of the last row on a page.
+// Rows sharing a timestamp are ordered by id, so the pair is unique and
+// pagination never skips or repeats rows.
+type pageCursor struct {
+	Timestamp pgtype.Timestamptz
+	ID        pgtype.UUID
+	// Pinned is set when the position is a pinned conversation; Timestamp is then its pinned_at
+	Pinned bool
+}
+
+// encodePageCursor returns the opaque next_cursor for the given position ("" if the timestamp is unset)
+func encodePageCursor(ts pgtype.Timestamptz, id pgtype.UUID) string {
+	if !ts.Valid || !id.Valid {
+		return ""
+	}
+	raw := formatTimestamp(ts) + cursorSeparator + uuidToString(id)
+	return base64.RawURLEncoding.EncodeToString([]byte(raw))
+}
+
+// encodePinnedPageCursor returns the next_cursor for a pinned conversation at (pinned_at, id)
+func encodePinnedPageCursor(pinnedAt pgtype.Timestamptz, id pgtype.UUID) string {
+	if !pinnedAt.Valid || !id.Valid {
+		return ""
+	}
+	raw := formatTimestamp(pinnedAt) + cursorSeparator + uuidToString(id) + cursorSeparator + pinnedCursorMarker
+	return base64.RawURLEncoding.EncodeToString([]byte(raw))
+}
+
+// decodePageCursor parses a cursor produced by encodePageCursor or encodePinnedPageCursor.
+// A bare RFC3339 timestamp (the pre-composite format) is still accepted and
+// is paired with the nil UUID, which makes (ts, id) < (cursor_ts, nil) behave
+// like the old created_at < cursor_ts filter.
+func decodePageCursor(value string) (pageCursor, error) {
+	if ts, err := parseTimestampToPgtype(value); err == nil {
+		return pageCursor{Timestamp: ts, ID: pgtype.UUID{Valid: true}}, nil
+	}
+
+	raw, err := base64.RawURLEncoding.DecodeString(value)
+	if err != nil {
+		return pageCursor{}, ErrInvalidCursor
+	}
+
+	tsPart, idPart, ok := strings.Cut(string(raw), cursorSeparator)
+	if !ok {
+		return pageCursor{}, ErrInvalidCursor
+	}
+
+	ts, err := parseTimestampToPgtype(tsPart)
+	if err != nil || !ts.Valid {
+		return pageCursor{}, ErrInvalidCursor
+	}
+
+	idPart, marker, pinned := strings.Cut(idPart, cursorSeparator)
+	if pinned && marker != pinnedCursorMarker {
+		return pageCursor{}, ErrInvalidCursor
+	}
+
+	id, err := parseUUID(idPart)
+	if err != nil {
+		return pageCursor{}, ErrInvalidCursor
+	}
+
+	return pageCursor{Timestamp: ts, ID: id, Pinned: pinned}, nil
+}
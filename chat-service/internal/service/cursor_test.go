@@ -0,0 +1,82 @@
+package service
+
+import (
+	"encoding/base64"
+	"testing"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPageCursor_RoundTrip(t *testing.T) {
+	ts := mustTimestamptz(t, time.Date(2025, 1, 2, 15, 4, 5, 123456789, time.UTC))
+	id := mustParseUUID(t, "550e8400-e29b-41d4-a716-446655440001")
+
+	encoded := encodePageCursor(ts, id)
+	require.NotEmpty(t, encoded)
+	assert.NotContains(t, encoded, "|", "Cursor should be opaque")
+
+	cursor, err := decodePageCursor(encoded)
+	require.NoError(t, err)
+	assert.True(t, ts.Time.Equal(cursor.Timestamp.Time))
+	assert.Equal(t, id, cursor.ID)
+}
+
+func TestPageCursor_PinnedRoundTrip(t *testing.T) {
+	ts := mustTimestamptz(t, time.Date(2025, 1, 2, 15, 4, 5, 0, time.UTC))
+	id := mustParseUUID(t, "550e8400-e29b-41d4-a716-446655440001")
+
+	cursor, err := decodePageCursor(encodePinnedPageCursor(ts, id))
+	require.NoError(t, err)
+	assert.True(t, cursor.Pinned)
+	assert.True(t, ts.Time.Equal(cursor.Timestamp.Time))
+	assert.Equal(t, id, cursor.ID)
+
+	cursor, err = decodePageCursor(encodePageCursor(ts, id))
+	require.NoError(t, err)
+	assert.False(t, cursor.Pinned)
+}
+
+func TestPageCursor_CollidingTimestampsProduceDistinctCursors(t *testing.T) {
+	ts := mustTimestamptz(t, time.Date(2025, 1, 2, 15, 4, 5, 0, time.UTC))
+
+	a := encodePageCursor(ts, mustParseUUID(t, "550e8400-e29b-41d4-a716-446655440001"))
+	b := encodePageCursor(ts, mustParseUUID(t, "550e8400-e29b-41d4-a716-446655440002"))
+
+	assert.NotEqual(t, a, b)
+}
+
+func TestPageCursor_AcceptsLegacyTimestamp(t *testing.T) {
+	cursor, err := decodePageCursor("2025-01-02T15:04:05Z")
+	require.NoError(t, err)
+
+	assert.True(t, cursor.Timestamp.Valid)
+	assert.Equal(t, pgtype.UUID{Valid: true}, cursor.ID, "Legacy cursors compare against the nil UUID")
+}
+
+func TestPageCursor_EncodeUnsetTimestamp(t *testing.T) {
+	id := mustParseUUID(t, "550e8400-e29b-41d4-a716-446655440001")
+	assert.Empty(t, encodePageCursor(pgtype.Timestamptz{}, id))
+}
+
+func TestPageCursor_DecodeInvalid(t *testing.T) {
+	tests := []string{
+		"not-a-cursor",
+		"%%%",
+		encodeRawCursor("2025-01-02T15:04:05Z"),
+		encodeRawCursor("bad-time|550e8400-e29b-41d4-a716-446655440001"),
+		encodeRawCursor("2025-01-02T15:04:05Z|not-a-uuid"),
+		encodeRawCursor("2025-01-02T15:04:05Z|550e8400-e29b-41d4-a716-446655440001|starred"),
+	}
+
+	for _, value := range tests {
+		_, err := decodePageCursor(value)
+		assert.ErrorIs(t, err, ErrInvalidCursor, "value %q", value)
+	}
+}
+
+func encodeRawCursor(raw string) string {
+	return base64.RawURLEncoding.EncodeToString([]byte(raw))
+}
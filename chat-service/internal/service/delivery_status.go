@@ -0,0 +1,65 @@
+package service
+
+import (
+	chatv1 "chat-service/api/chat/v1"
+	"chat-service/internal/repository"
+
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+// messageStatus computes the sent/delivered/read status of msg as seen by its sender.
+//
+// The recipients are the other participants that had joined when the message was sent.
+// A recipient has read a message when their last_read_at reached it; reading implies delivery.
+// DELIVERED needs at least one recipient, READ needs all of them, so a direct chat reflects
+// its single recipient while a group shows READ only once everyone has read.
+func messageStatus(msg repository.Message, receipts []repository.GetParticipantReceiptsRow) chatv1.MessageStatus {
+	recipients, delivered, read := 0, 0, 0
+	for _, r := range receipts {
+		if r.UserID == msg.SenderID || joinedAfter(r, msg.CreatedAt) {
+			continue
+		}
+		recipients++
+		switch {
+		case hasRead(r, msg.CreatedAt):
+			read++
+			delivered++
+		case r.LastDeliveredAt.Valid && !r.LastDeliveredAt.Time.Before(msg.CreatedAt.Time):
+			delivered++
+		}
+	}
+
+	switch {
+	case recipients > 0 && read == recipients:
+		return chatv1.MessageStatus_MESSAGE_STATUS_READ
+	case delivered > 0:
+		return chatv1.MessageStatus_MESSAGE_STATUS_DELIVERED
+	default:
+		return chatv1.MessageStatus_MESSAGE_STATUS_SENT
+	}
+}
+
+// joinedAfter reports whether the participant joined after the message was sent
+func joinedAfter(r repository.GetParticipantReceiptsRow, createdAt pgtype.Timestamptz) bool {
+	return r.JoinedAt.Valid && r.JoinedAt.Time.After(createdAt.Time)
+}
+
+// hasRead reports whether the participant's read pointer covers a message sent at createdAt.
+// last_read_at starts at joined_at, which equals created_at of the message that added the
+// participant (same transaction), so the pointer must also have moved since joining.
+func hasRead(r repository.GetParticipantReceiptsRow, createdAt pgtype.Timestamptz) bool {
+	if !r.LastReadAt.Valid || r.LastReadAt.Time.Before(createdAt.Time) {
+		return false
+	}
+	return !r.JoinedAt.Valid || r.LastReadAt.Time.After(r.JoinedAt.Time)
+}
+
+// sentByUser reports whether any of messages was sent by userID
+func sentByUser(messages []repository.Message, userID pgtype.UUID) bool {
+	for _, msg := range messages {
+		if msg.SenderID == userID {
+			return true
+		}
+	}
+	return false
+}
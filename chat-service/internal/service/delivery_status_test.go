@@ -0,0 +1,241 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	chatv1 "chat-service/api/chat/v1"
+	"chat-service/internal/repository"
+
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+const (
+	testStatusSenderID     = "110e8400-e29b-41d4-a716-446655440000"
+	testStatusRecipientA   = "220e8400-e29b-41d4-a716-446655440000"
+	testStatusRecipientB   = "330e8400-e29b-41d4-a716-446655440000"
+	testStatusConversation = "440e8400-e29b-41d4-a716-446655440000"
+)
+
+var statusBaseTime = time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC)
+
+// statusAt returns a timestamp offset by minutes from statusBaseTime
+func statusAt(t *testing.T, minutes int) pgtype.Timestamptz {
+	return mustTimestamptz(t, statusBaseTime.Add(time.Duration(minutes)*time.Minute))
+}
+
+// receipt builds a participant receipt; negative minutes leave last_delivered_at NULL
+func receipt(t *testing.T, userID string, joined, read, delivered int) repository.GetParticipantReceiptsRow {
+	r := repository.GetParticipantReceiptsRow{
+		UserID:     mustParseUUID(t, userID),
+		JoinedAt:   statusAt(t, joined),
+		LastReadAt: statusAt(t, read),
+	}
+	if delivered >= 0 {
+		r.LastDeliveredAt = statusAt(t, delivered)
+	}
+	return r
+}
+
+func TestMessageStatus(t *testing.T) {
+	msg := repository.Message{SenderID: mustParseUUID(t, testStatusSenderID), CreatedAt: statusAt(t, 10)}
+	sender := receipt(t, testStatusSenderID, 0, 10, -1)
+
+	tests := []struct {
+		name     string
+		receipts []repository.GetParticipantReceiptsRow
+		want     chatv1.MessageStatus
+	}{
+		{
+			name:     "direct: not delivered",
+			receipts: []repository.GetParticipantReceiptsRow{sender, receipt(t, testStatusRecipientA, 0, 5, -1)},
+			want:     chatv1.MessageStatus_MESSAGE_STATUS_SENT,
+		},
+		{
+			name:     "direct: delivered before the message",
+			receipts: []repository.GetParticipantReceiptsRow{sender, receipt(t, testStatusRecipientA, 0, 5, 9)},
+			want:     chatv1.MessageStatus_MESSAGE_STATUS_SENT,
+		},
+		{
+			name:     "direct: delivered",
+			receipts: []repository.GetParticipantReceiptsRow{sender, receipt(t, testStatusRecipientA, 0, 5, 11)},
+			want:     chatv1.MessageStatus_MESSAGE_STATUS_DELIVERED,
+		},
+		{
+			name:     "direct: read implies delivered",
+			receipts: []repository.GetParticipantReceiptsRow{sender, receipt(t, testStatusRecipientA, 0, 12, -1)},
+			want:     chatv1.MessageStatus_MESSAGE_STATUS_READ,
+		},
+		{
+			name:     "direct: recipient added by this message has not read it",
+			receipts: []repository.GetParticipantReceiptsRow{sender, receipt(t, testStatusRecipientA, 10, 10, -1)},
+			want:     chatv1.MessageStatus_MESSAGE_STATUS_SENT,
+		},
+		{
+			name: "group: delivered to one recipient",
+			receipts: []repository.GetParticipantReceiptsRow{
+				sender,
+				receipt(t, testStatusRecipientA, 0, 5, 11),
+				receipt(t, testStatusRecipientB, 0, 5, -1),
+			},
+			want: chatv1.MessageStatus_MESSAGE_STATUS_DELIVERED,
+		},
+		{
+			name: "group: read by some recipients",
+			receipts: []repository.GetParticipantReceiptsRow{
+				sender,
+				receipt(t, testStatusRecipientA, 0, 12, -1),
+				receipt(t, testStatusRecipientB, 0, 5, -1),
+			},
+			want: chatv1.MessageStatus_MESSAGE_STATUS_DELIVERED,
+		},
+		{
+			name: "group: read by all recipients",
+			receipts: []repository.GetParticipantReceiptsRow{
+				sender,
+				receipt(t, testStatusRecipientA, 0, 12, -1),
+				receipt(t, testStatusRecipientB, 0, 11, 11),
+			},
+			want: chatv1.MessageStatus_MESSAGE_STATUS_READ,
+		},
+		{
+			name: "group: members who joined later are not recipients",
+			receipts: []repository.GetParticipantReceiptsRow{
+				sender,
+				receipt(t, testStatusRecipientA, 0, 12, -1),
+				receipt(t, testStatusRecipientB, 20, 20, -1),
+			},
+			want: chatv1.MessageStatus_MESSAGE_STATUS_READ,
+		},
+		{
+			name:     "no recipients",
+			receipts: []repository.GetParticipantReceiptsRow{sender},
+			want:     chatv1.MessageStatus_MESSAGE_STATUS_SENT,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, messageStatus(msg, tt.receipts))
+		})
+	}
+}
+
+func TestGetMessages_DeliveryStatus(t *testing.T) {
+	senderUUID := mustParseUUID(t, testStatusSenderID)
+	otherUUID := mustParseUUID(t, testStatusRecipientA)
+
+	service := &ChatService{
+		logger:                      zap.NewNop(),
+		isParticipantFn:             allowParticipant,
+		getAttachmentsForMessagesFn: noAttachments,
+		getReactionsForMessagesFn:   noReactions,
+	}
+	service.getMessagesFn = func(ctx context.Context, arg repository.GetMessagesParams) ([]repository.Message, error) {
+		return []repository.Message{
+			{ID: mustParseUUID(t, "550e8400-e29b-41d4-a716-446655440003"), SenderID: senderUUID, CreatedAt: statusAt(t, 30)},
+			{ID: mustParseUUID(t, "550e8400-e29b-41d4-a716-446655440002"), SenderID: otherUUID, CreatedAt: statusAt(t, 20)},
+			{ID: mustParseUUID(t, "550e8400-e29b-41d4-a716-446655440001"), SenderID: senderUUID, CreatedAt: statusAt(t, 10)},
+		}, nil
+	}
+
+	var receiptsCalls int
+	service.getParticipantReceiptsFn = func(ctx context.Context, conversationID pgtype.UUID) ([]repository.GetParticipantReceiptsRow, error) {
+		receiptsCalls++
+		assert.Equal(t, mustParseUUID(t, testStatusConversation), conversationID)
+		return []repository.GetParticipantReceiptsRow{
+			receipt(t, testStatusSenderID, 0, 30, -1),
+			receipt(t, testStatusRecipientA, 0, 15, 25),
+		}, nil
+	}
+
+	req := &chatv1.GetMessagesRequest{ConversationId: testStatusConversation}
+
+	resp, err := service.GetMessages(contextWithUserID(testStatusSenderID), req)
+	require.NoError(t, err)
+	require.Len(t, resp.Messages, 3)
+	assert.Equal(t, 1, receiptsCalls, "receipts are loaded once per page")
+
+	assert.Equal(t, chatv1.MessageStatus_MESSAGE_STATUS_SENT, resp.Messages[0].Status)
+	assert.Equal(t, chatv1.MessageStatus_MESSAGE_STATUS_UNSPECIFIED, resp.Messages[1].Status, "other users' messages carry no status")
+	assert.Equal(t, chatv1.MessageStatus_MESSAGE_STATUS_READ, resp.Messages[2].Status)
+
+	// A reader without own messages on the page does not load receipts
+	resp, err = service.GetMessages(contextWithUserID(testReaderID), req)
+	require.NoError(t, err)
+	assert.Equal(t, 1, receiptsCalls)
+	for _, msg := range resp.Messages {
+		assert.Equal(t, chatv1.MessageStatus_MESSAGE_STATUS_UNSPECIFIED, msg.Status)
+	}
+}
+
+func TestGetMessages_DeliveryStatusError(t *testing.T) {
+	service := &ChatService{
+		logger:                      zap.NewNop(),
+		isParticipantFn:             allowParticipant,
+		getAttachmentsForMessagesFn: noAttachments,
+		getReactionsForMessagesFn:   noReactions,
+	}
+	service.getMessagesFn = func(ctx context.Context, arg repository.GetMessagesParams) ([]repository.Message, error) {
+		return []repository.Message{{SenderID: mustParseUUID(t, testStatusSenderID), CreatedAt: statusAt(t, 10)}}, nil
+	}
+	service.getParticipantReceiptsFn = func(ctx context.Context, conversationID pgtype.UUID) ([]repository.GetParticipantReceiptsRow, error) {
+		return nil, errors.New("database unavailable")
+	}
+
+	resp, err := service.GetMessages(contextWithUserID(testStatusSenderID), &chatv1.GetMessagesRequest{ConversationId: testStatusConversation})
+	assert.Nil(t, resp)
+	assert.Equal(t, codes.Internal, status.Code(err))
+}
+
+func TestMarkAsDelivered_Success(t *testing.T) {
+	var captured repository.MarkAsDeliveredParams
+	service := &ChatService{logger: zap.NewNop()}
+	service.markAsDeliveredFn = func(ctx context.Context, arg repository.MarkAsDeliveredParams) error {
+		captured = arg
+		return nil
+	}
+
+	resp, err := service.MarkAsDelivered(contextWithUserID(testStatusRecipientA), &chatv1.MarkAsDeliveredRequest{
+		ConversationId: testStatusConversation,
+	})
+	require.NoError(t, err)
+	assert.True(t, resp.Success)
+	assert.Equal(t, mustParseUUID(t, testStatusConversation), captured.ConversationID)
+	assert.Equal(t, mustParseUUID(t, testStatusRecipientA), captured.UserID)
+}
+
+func TestMarkAsDelivered_Errors(t *testing.T) {
+	service := &ChatService{logger: zap.NewNop()}
+	service.markAsDeliveredFn = func(ctx context.Context, arg repository.MarkAsDeliveredParams) error {
+		return errors.New("database unavailable")
+	}
+
+	tests := []struct {
+		name    string
+		ctx     context.Context
+		req     *chatv1.MarkAsDeliveredRequest
+		errCode codes.Code
+	}{
+		{name: "nil request", ctx: contextWithUserID(testStatusRecipientA), req: nil, errCode: codes.InvalidArgument},
+		{name: "empty conversation", ctx: contextWithUserID(testStatusRecipientA), req: &chatv1.MarkAsDeliveredRequest{}, errCode: codes.InvalidArgument},
+		{name: "invalid uuid", ctx: contextWithUserID(testStatusRecipientA), req: &chatv1.MarkAsDeliveredRequest{ConversationId: "not-a-uuid"}, errCode: codes.InvalidArgument},
+		{name: "unauthenticated", ctx: context.Background(), req: &chatv1.MarkAsDeliveredRequest{ConversationId: testStatusConversation}, errCode: codes.Unauthenticated},
+		{name: "database error", ctx: contextWithUserID(testStatusRecipientA), req: &chatv1.MarkAsDeliveredRequest{ConversationId: testStatusConversation}, errCode: codes.Internal},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			resp, err := service.MarkAsDelivered(tt.ctx, tt.req)
+			assert.Nil(t, resp)
+			assert.Equal(t, tt.errCode, status.Code(err))
+		})
+	}
+}
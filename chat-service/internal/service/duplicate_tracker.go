@@ -0,0 +1,263 @@
+package service
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"sort"
+	"sync"
+	"time"
+
+	"chat-service/pkg/idempotency"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+)
+
+const (
+	// DefaultDuplicateTopN is how many of the noisiest users and conversations get their own series
+	DefaultDuplicateTopN = 10
+
+	// DefaultDuplicateWindow is the interval duplicate hits are counted over
+	DefaultDuplicateWindow = time.Minute
+
+	// DefaultDuplicateStormThreshold is the duplicate hits per window that flag a subject as storming
+	DefaultDuplicateStormThreshold = 20
+
+	// trackedPerTopN sizes the candidate table relative to TopN; a larger table
+	// makes the reported top-N more accurate under many distinct noisy subjects
+	trackedPerTopN = 8
+
+	// duplicateMetricsNamespace matches the API server's other metrics
+	duplicateMetricsNamespace = "chat_server"
+
+	duplicateKindUser         = "user"
+	duplicateKindConversation = "conversation"
+)
+
+// DuplicateTrackerConfig configures a DuplicateTracker
+type DuplicateTrackerConfig struct {
+	TopN           int
+	Window         time.Duration
+	StormThreshold int
+}
+
+// DuplicateTracker attributes idempotency duplicate hits to users and conversations.
+//
+// Per-subject series are the cardinality risk, so only the TopN noisiest users and
+// conversations of the last complete window are exported, and their series are deleted
+// once they drop out. Counting itself is bounded too: each kind keeps at most
+// TopN*trackedPerTopN candidates (Space-Saving), so a flood of distinct keys cannot
+// grow memory. User ids are exported hashed.
+type DuplicateTracker struct {
+	topN           int
+	window         time.Duration
+	stormThreshold int
+
+	mu            sync.Mutex
+	users         *heavyHitters
+	conversations *heavyHitters
+	published     map[string]map[string]struct{}
+
+	checks *prometheus.CounterVec
+	noisy  *prometheus.GaugeVec
+	storm  *prometheus.GaugeVec
+}
+
+// NewDuplicateTracker creates a tracker and registers its metrics on registry
+func NewDuplicateTracker(registry prometheus.Registerer, cfg DuplicateTrackerConfig) *DuplicateTracker {
+	if cfg.TopN <= 0 {
+		cfg.TopN = DefaultDuplicateTopN
+	}
+	if cfg.Window <= 0 {
+		cfg.Window = DefaultDuplicateWindow
+	}
+	if cfg.StormThreshold <= 0 {
+		cfg.StormThreshold = DefaultDuplicateStormThreshold
+	}
+
+	factory := promauto.With(registry)
+	capacity := cfg.TopN * trackedPerTopN
+
+	return &DuplicateTracker{
+		topN:           cfg.TopN,
+		window:         cfg.Window,
+		stormThreshold: cfg.StormThreshold,
+		users:          newHeavyHitters(capacity),
+		conversations:  newHeavyHitters(capacity),
+		published: map[string]map[string]struct{}{
+			duplicateKindUser:         {},
+			duplicateKindConversation: {},
+		},
+
+		checks: factory.NewCounterVec(prometheus.CounterOpts{
+			Namespace: duplicateMetricsNamespace,
+			Name:      "idempotency_checks_total",
+			Help:      "Idempotency checks by result: first, duplicate or error",
+		}, []string{"result"}),
+
+		noisy: factory.NewGaugeVec(prometheus.GaugeOpts{
+			Namespace: duplicateMetricsNamespace,
+			Name:      "idempotency_noisy_duplicates",
+			Help:      "Duplicate hits in the last window for the noisiest users (hashed) and conversations",
+		}, []string{"kind", "subject"}),
+
+		storm: factory.NewGaugeVec(prometheus.GaugeOpts{
+			Namespace: duplicateMetricsNamespace,
+			Name:      "idempotency_duplicate_storm",
+			Help:      "Users or conversations whose duplicate hits in the last window reached the storm threshold",
+		}, []string{"kind"}),
+	}
+}
+
+// RecordCheck counts the outcome of an idempotency check made by userID in conversationID
+func (t *DuplicateTracker) RecordCheck(userID, conversationID string, err error) {
+	switch {
+	case err == nil:
+		t.checks.WithLabelValues("first").Inc()
+		return
+	case !errors.Is(err, idempotency.ErrDuplicateRequest):
+		t.checks.WithLabelValues("error").Inc()
+		return
+	}
+
+	t.checks.WithLabelValues("duplicate").Inc()
+
+	t.mu.Lock()
+	t.users.add(hashSubject(userID))
+	t.conversations.add(conversationSubject(conversationID))
+	t.mu.Unlock()
+}
+
+// Run publishes the per-subject gauges once per window until ctx is cancelled
+func (t *DuplicateTracker) Run(ctx context.Context) {
+	ticker := time.NewTicker(t.window)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			t.Flush()
+		}
+	}
+}
+
+// Flush publishes the window that just ended and starts a new one
+func (t *DuplicateTracker) Flush() {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	t.publish(duplicateKindUser, t.users)
+	t.publish(duplicateKindConversation, t.conversations)
+	t.users.reset()
+	t.conversations.reset()
+}
+
+// publish replaces the kind's series with the current top-N and storm count
+func (t *DuplicateTracker) publish(kind string, counts *heavyHitters) {
+	top := counts.top(t.topN)
+
+	current := make(map[string]struct{}, len(top))
+	for _, entry := range top {
+		current[entry.subject] = struct{}{}
+		t.noisy.WithLabelValues(kind, entry.subject).Set(float64(entry.count))
+	}
+	for subject := range t.published[kind] {
+		if _, ok := current[subject]; !ok {
+			t.noisy.DeleteLabelValues(kind, subject)
+		}
+	}
+	t.published[kind] = current
+
+	t.storm.WithLabelValues(kind).Set(float64(counts.atLeast(t.stormThreshold)))
+}
+
+// hashSubject pseudonymises a user id for use as a label value.
+// Operators can match it against the user_hash field of the duplicate log line.
+func hashSubject(id string) string {
+	sum := sha256.Sum256([]byte(id))
+	return hex.EncodeToString(sum[:6])
+}
+
+// conversationSubject keeps well-formed conversation ids readable; anything else is
+// client-controlled text of arbitrary length and is folded into a single label value
+func conversationSubject(id string) string {
+	if _, err := parseUUID(id); err != nil {
+		return "invalid"
+	}
+	return id
+}
+
+// subjectCount is one entry of a top-N list
+type subjectCount struct {
+	subject string
+	count   int
+}
+
+// heavyHitters counts hits per subject in bounded memory using the Space-Saving
+// algorithm: when the table is full, the least-hit subject is replaced and the newcomer
+// inherits its count. Counts may over-estimate, but any subject hit more often than
+// total/capacity is guaranteed to be present.
+type heavyHitters struct {
+	capacity int
+	counts   map[string]int
+}
+
+func newHeavyHitters(capacity int) *heavyHitters {
+	return &heavyHitters{
+		capacity: capacity,
+		counts:   make(map[string]int, capacity),
+	}
+}
+
+func (h *heavyHitters) add(subject string) {
+	if _, ok := h.counts[subject]; ok || len(h.counts) < h.capacity {
+		h.counts[subject]++
+		return
+	}
+
+	minSubject, minCount := "", 0
+	for s, n := range h.counts {
+		if minSubject == "" || n < minCount {
+			minSubject, minCount = s, n
+		}
+	}
+	delete(h.counts, minSubject)
+	h.counts[subject] = minCount + 1
+}
+
+// top returns up to n subjects by descending count (ties broken by subject)
+func (h *heavyHitters) top(n int) []subjectCount {
+	entries := make([]subjectCount, 0, len(h.counts))
+	for s, c := range h.counts {
+		entries = append(entries, subjectCount{subject: s, count: c})
+	}
+	sort.Slice(entries, func(i, j int) bool {
+		if entries[i].count != entries[j].count {
+			return entries[i].count > entries[j].count
+		}
+		return entries[i].subject < entries[j].subject
+	})
+	if len(entries) > n {
+		entries = entries[:n]
+	}
+	return entries
+}
+
+// atLeast counts subjects with at least threshold hits
+func (h *heavyHitters) atLeast(threshold int) int {
+	n := 0
+	for _, c := range h.counts {
+		if c >= threshold {
+			n++
+		}
+	}
+	return n
+}
+
+func (h *heavyHitters) reset() {
+	h.counts = make(map[string]int, h.capacity)
+}
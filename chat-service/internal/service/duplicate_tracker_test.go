@@ -0,0 +1,83 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+
+	"chat-service/pkg/idempotency"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const trackerConversationID = "550e8400-e29b-41d4-a716-446655440000"
+
+func TestDuplicateTracker_CountsChecksByResult(t *testing.T) {
+	tracker := NewDuplicateTracker(prometheus.NewRegistry(), DuplicateTrackerConfig{})
+
+	tracker.RecordCheck("user-1", trackerConversationID, nil)
+	tracker.RecordCheck("user-1", trackerConversationID, nil)
+	tracker.RecordCheck("user-1", trackerConversationID, idempotency.ErrDuplicateRequest)
+	tracker.RecordCheck("user-1", trackerConversationID, errors.New("redis down"))
+
+	assert.Equal(t, 2.0, testutil.ToFloat64(tracker.checks.WithLabelValues("first")))
+	assert.Equal(t, 1.0, testutil.ToFloat64(tracker.checks.WithLabelValues("duplicate")))
+	assert.Equal(t, 1.0, testutil.ToFloat64(tracker.checks.WithLabelValues("error")))
+}
+
+func TestDuplicateTracker_PublishesTopNAndStorm(t *testing.T) {
+	tracker := NewDuplicateTracker(prometheus.NewRegistry(), DuplicateTrackerConfig{TopN: 2, StormThreshold: 5})
+
+	// user-a storms, user-b is noisy, user-c is below both of them
+	for i := 0; i < 6; i++ {
+		tracker.RecordCheck("user-a", trackerConversationID, idempotency.ErrDuplicateRequest)
+	}
+	for i := 0; i < 3; i++ {
+		tracker.RecordCheck("user-b", trackerConversationID, idempotency.ErrDuplicateRequest)
+	}
+	tracker.RecordCheck("user-c", "not-a-uuid", idempotency.ErrDuplicateRequest)
+	tracker.Flush()
+
+	assert.Equal(t, 6.0, testutil.ToFloat64(tracker.noisy.WithLabelValues(duplicateKindUser, hashSubject("user-a"))))
+	assert.Equal(t, 3.0, testutil.ToFloat64(tracker.noisy.WithLabelValues(duplicateKindUser, hashSubject("user-b"))))
+	assert.Equal(t, 9.0, testutil.ToFloat64(tracker.noisy.WithLabelValues(duplicateKindConversation, trackerConversationID)))
+	assert.Equal(t, 1.0, testutil.ToFloat64(tracker.noisy.WithLabelValues(duplicateKindConversation, "invalid")))
+	assert.Equal(t, 1.0, testutil.ToFloat64(tracker.storm.WithLabelValues(duplicateKindUser)))
+	assert.Equal(t, 1.0, testutil.ToFloat64(tracker.storm.WithLabelValues(duplicateKindConversation)))
+
+	// Only the top 2 of each kind are exported
+	series := testutil.CollectAndCount(tracker.noisy)
+	assert.Equal(t, 4, series)
+
+	// A quiet window removes every per-subject series and clears the storm
+	tracker.Flush()
+	assert.Equal(t, 0, testutil.CollectAndCount(tracker.noisy))
+	assert.Equal(t, 0.0, testutil.ToFloat64(tracker.storm.WithLabelValues(duplicateKindUser)))
+}
+
+func TestHeavyHitters_BoundedAndKeepsHeavySubject(t *testing.T) {
+	h := newHeavyHitters(4)
+
+	// One heavy subject among a flood of distinct one-off subjects
+	for i := 0; i < 1000; i++ {
+		h.add(fmt.Sprintf("subject-%d", i))
+		if i%2 == 0 {
+			h.add("heavy")
+		}
+	}
+
+	assert.LessOrEqual(t, len(h.counts), 4, "the table never grows past its capacity")
+	top := h.top(1)
+	require.Len(t, top, 1)
+	assert.Equal(t, "heavy", top[0].subject)
+	assert.GreaterOrEqual(t, top[0].count, 500, "Space-Saving never under-counts")
+}
+
+func TestHashSubject(t *testing.T) {
+	assert.Equal(t, hashSubject("user-1"), hashSubject("user-1"))
+	assert.NotEqual(t, hashSubject("user-1"), hashSubject("user-2"))
+	assert.Len(t, hashSubject("user-1"), 12)
+}
@@ -0,0 +1,42 @@
+package service
+
+import (
+	"context"
+
+	"chat-service/internal/ws"
+
+	"google.golang.org/grpc/metadata"
+)
+
+// DeviceIDHeader names the device a request comes from. Events caused by the request
+// carry it as origin_device_id, so the ws-gateway syncs the user's other devices
+// without echoing back to the one that acted.
+const DeviceIDHeader = "x-device-id"
+
+// eventOrigin identifies the request an outbox event was produced by
+type eventOrigin struct {
+	requestID string // Correlation id of the request
+	deviceID  string // Device of the acting user, "" if the client did not send one
+}
+
+// eventOriginFromContext collects the origin of the current request.
+// Malformed device ids are dropped: they only affect device sync, not the request itself.
+func eventOriginFromContext(ctx context.Context) eventOrigin {
+	origin := eventOrigin{requestID: requestIDFromContext(ctx)}
+	if md, ok := metadata.FromIncomingContext(ctx); ok {
+		if values := md.Get(DeviceIDHeader); len(values) > 0 && ws.ValidDeviceID(values[0]) {
+			origin.deviceID = values[0]
+		}
+	}
+	return origin
+}
+
+// addTo sets the origin fields of an event payload, omitting empty ones
+func (o eventOrigin) addTo(event map[string]interface{}) {
+	if o.requestID != "" {
+		event["request_id"] = o.requestID
+	}
+	if o.deviceID != "" {
+		event["origin_device_id"] = o.deviceID
+	}
+}
@@ -0,0 +1,233 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+
+	chatv1 "chat-service/api/chat/v1"
+	"chat-service/internal/apierror"
+	"chat-service/internal/auth"
+	"chat-service/internal/repository"
+
+	"github.com/jackc/pgx/v5/pgtype"
+	"go.uber.org/zap"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// Export page sizes: each page is one query and one chunk, so memory stays bounded by the page
+const (
+	DefaultExportPageSize = 500
+	MaxExportPageSize     = 1000
+)
+
+// exportLine is one JSON Lines record of an EXPORT_FORMAT_JSONL export.
+// Its shape is part of the export format, independent of the ChatMessage proto.
+type exportLine struct {
+	ID             string             `json:"id"`
+	ConversationID string             `json:"conversation_id"`
+	SenderID       string             `json:"sender_id"`
+	Type           string             `json:"type"`
+	Content        string             `json:"content"`
+	MediaURL       string             `json:"media_url,omitempty"`
+	Attachments    []exportAttachment `json:"attachments,omitempty"`
+	CreatedAt      string             `json:"created_at"`
+}
+
+type exportAttachment struct {
+	URL      string `json:"url"`
+	MimeType string `json:"mime_type"`
+	Size     int64  `json:"size"`
+	Width    int32  `json:"width,omitempty"`
+	Height   int32  `json:"height,omitempty"`
+}
+
+// ExportConversation streams a conversation's whole history, oldest first, one page per chunk.
+// Participants may export their conversations; admins may export any conversation.
+// Pages are read with a keyset cursor, so only one page is held in memory at a time.
+func (s *ChatService) ExportConversation(req *chatv1.ExportConversationRequest, stream chatv1.ChatService_ExportConversationServer) error {
+	ctx := stream.Context()
+
+	if req.ConversationId == "" {
+		return apierror.Validation("conversation_id", "conversation_id is required")
+	}
+	conversationUUID, err := parseUUID(req.ConversationId)
+	if err != nil {
+		return apierror.Validation("conversation_id", "invalid conversation_id")
+	}
+	switch req.Format {
+	case chatv1.ExportFormat_EXPORT_FORMAT_UNSPECIFIED,
+		chatv1.ExportFormat_EXPORT_FORMAT_MESSAGES,
+		chatv1.ExportFormat_EXPORT_FORMAT_JSONL:
+	default:
+		return apierror.Validation("format", "unsupported export format")
+	}
+	if req.PageSize < 0 || req.PageSize > MaxExportPageSize {
+		return apierror.Validation("page_size", fmt.Sprintf("page_size must be at most %d", MaxExportPageSize))
+	}
+	pageSize := req.PageSize
+	if pageSize == 0 {
+		pageSize = DefaultExportPageSize
+	}
+
+	userID, err := getUserIDFromContext(ctx)
+	if err != nil {
+		s.logger.Error("failed to get user_id from context", zap.Error(err))
+		return err
+	}
+	userUUID, err := parseUUID(userID)
+	if err != nil {
+		return apierror.Validation("user_id", "invalid user_id")
+	}
+
+	// Compliance exports: admins need not be participants
+	if !auth.HasAnyRole(ctx, auth.RoleAdmin) {
+		isMember, err := s.isParticipant(ctx, repository.IsParticipantParams{
+			ConversationID: conversationUUID,
+			UserID:         userUUID,
+		})
+		if err != nil {
+			s.logger.Error("failed to check conversation membership",
+				zap.Error(err),
+				zap.String("conversation_id", req.ConversationId),
+				zap.String("user_id", userID),
+			)
+			return status.Error(codes.Internal, "failed to export conversation")
+		}
+		if !isMember {
+			s.logger.Warn("user is not a participant of conversation",
+				zap.String("conversation_id", req.ConversationId),
+				zap.String("user_id", userID),
+			)
+			return apierror.New(codes.PermissionDenied, apierror.CodePermissionDenied, "not a participant of this conversation", nil)
+		}
+	}
+
+	s.logger.Info("conversation export started",
+		zap.String("conversation_id", req.ConversationId),
+		zap.String("user_id", userID),
+		zap.String("format", req.Format.String()),
+	)
+
+	params := repository.ExportMessagesParams{
+		ConversationID: conversationUUID,
+		Limit:          pageSize,
+	}
+	exported := 0
+	for {
+		messages, err := s.exportMessages(ctx, params)
+		if err != nil {
+			s.logger.Error("failed to fetch messages for export",
+				zap.Error(err),
+				zap.String("conversation_id", req.ConversationId),
+				zap.Int("exported", exported),
+			)
+			return status.Error(codes.Internal, "failed to export conversation")
+		}
+		if len(messages) == 0 {
+			break
+		}
+
+		attachmentsByMessage, err := s.loadAttachments(ctx, messages)
+		if err != nil {
+			s.logger.Error("failed to fetch attachments for export",
+				zap.Error(err),
+				zap.String("conversation_id", req.ConversationId),
+			)
+			return status.Error(codes.Internal, "failed to export conversation")
+		}
+
+		chunk, err := exportChunk(req.Format, messages, attachmentsByMessage)
+		if err != nil {
+			return status.Error(codes.Internal, "failed to export conversation")
+		}
+		if err := stream.Send(chunk); err != nil {
+			// The client went away; nothing left to report to it
+			s.logger.Info("conversation export aborted",
+				zap.String("conversation_id", req.ConversationId),
+				zap.Int("exported", exported),
+				zap.Error(err),
+			)
+			return err
+		}
+		exported += len(messages)
+
+		if len(messages) < int(pageSize) {
+			break
+		}
+		last := messages[len(messages)-1]
+		params.AfterCreatedAt = last.CreatedAt
+		params.AfterID = last.ID
+	}
+
+	s.logger.Info("conversation export finished",
+		zap.String("conversation_id", req.ConversationId),
+		zap.String("user_id", userID),
+		zap.Int("exported", exported),
+	)
+	return nil
+}
+
+// exportChunk renders one page of messages in the requested format
+func exportChunk(format chatv1.ExportFormat, messages []repository.Message, attachments map[pgtype.UUID][]*chatv1.Attachment) (*chatv1.ExportConversationChunk, error) {
+	chunk := &chatv1.ExportConversationChunk{MessageCount: int32(len(messages))}
+
+	if format != chatv1.ExportFormat_EXPORT_FORMAT_JSONL {
+		chunk.Messages = make([]*chatv1.ChatMessage, 0, len(messages))
+		for _, msg := range messages {
+			chatMsg := &chatv1.ChatMessage{
+				Id:             uuidToString(msg.ID),
+				ConversationId: uuidToString(msg.ConversationID),
+				SenderId:       uuidToString(msg.SenderID),
+				Content:        msg.Content,
+				CreatedAt:      formatTimestamp(msg.CreatedAt),
+				Type:           getProtoMessageType(msg.Type),
+				MediaUrl:       msg.MediaUrl.String,
+				Attachments:    attachments[msg.ID],
+			}
+			chunk.Messages = append(chunk.Messages, chatMsg)
+		}
+		return chunk, nil
+	}
+
+	var buf bytes.Buffer
+	enc := json.NewEncoder(&buf) // Encode terminates each record with a newline
+	enc.SetEscapeHTML(false)     // keep message content byte-for-byte readable
+	for _, msg := range messages {
+		line := exportLine{
+			ID:             uuidToString(msg.ID),
+			ConversationID: uuidToString(msg.ConversationID),
+			SenderID:       uuidToString(msg.SenderID),
+			Type:           msg.Type,
+			Content:        msg.Content,
+			MediaURL:       msg.MediaUrl.String,
+			CreatedAt:      formatTimestamp(msg.CreatedAt),
+		}
+		for _, a := range attachments[msg.ID] {
+			line.Attachments = append(line.Attachments, exportAttachment{
+				URL:      a.Url,
+				MimeType: a.MimeType,
+				Size:     a.Size,
+				Width:    a.Width,
+				Height:   a.Height,
+			})
+		}
+		if err := enc.Encode(line); err != nil {
+			return nil, fmt.Errorf("failed to encode export line: %w", err)
+		}
+	}
+	chunk.Data = buf.Bytes()
+	return chunk, nil
+}
+
+func (s *ChatService) exportMessages(ctx context.Context, params repository.ExportMessagesParams) ([]repository.Message, error) {
+	ctx, cancel := s.queryContext(ctx)
+	defer cancel()
+
+	if s.exportMessagesFn != nil {
+		return s.exportMessagesFn(ctx, params)
+	}
+	return s.queries.ExportMessages(ctx, params)
+}
@@ -0,0 +1,221 @@
+package service
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	chatv1 "chat-service/api/chat/v1"
+	"chat-service/internal/auth"
+	"chat-service/internal/repository"
+
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+const testExportConversationID = "550e8400-e29b-41d4-a716-446655440000"
+
+// fakeExportStream is a ChatService_ExportConversationServer that records sent chunks
+type fakeExportStream struct {
+	grpc.ServerStream
+	ctx     context.Context
+	chunks  []*chatv1.ExportConversationChunk
+	sendErr error
+}
+
+func (f *fakeExportStream) Context() context.Context {
+	return f.ctx
+}
+
+func (f *fakeExportStream) Send(chunk *chatv1.ExportConversationChunk) error {
+	if f.sendErr != nil {
+		return f.sendErr
+	}
+	f.chunks = append(f.chunks, chunk)
+	return nil
+}
+
+// exportHistory serves total messages, oldest first, through the keyset cursor
+// and records the cursor of every page request
+type exportHistory struct {
+	messages []repository.Message
+	pages    []repository.ExportMessagesParams
+}
+
+func newExportHistory(t *testing.T, total int) *exportHistory {
+	conversationID := mustParseUUID(t, testExportConversationID)
+	senderID := mustParseUUID(t, testReaderID)
+	start := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	h := &exportHistory{}
+	for i := 0; i < total; i++ {
+		msg := repository.Message{
+			ID:             mustParseUUID(t, fmt.Sprintf("770e8400-e29b-41d4-a716-%012d", i)),
+			ConversationID: conversationID,
+			SenderID:       senderID,
+			Content:        fmt.Sprintf("message %d <b>", i),
+			Type:           "TEXT",
+		}
+		// Pairs of messages share a timestamp so the id tiebreak is exercised
+		msg.CreatedAt.Scan(start.Add(time.Duration(i/2) * time.Second))
+		h.messages = append(h.messages, msg)
+	}
+	return h
+}
+
+func (h *exportHistory) page(ctx context.Context, arg repository.ExportMessagesParams) ([]repository.Message, error) {
+	h.pages = append(h.pages, arg)
+	start := 0
+	if arg.AfterCreatedAt.Valid {
+		for i, msg := range h.messages {
+			if msg.CreatedAt.Time.Equal(arg.AfterCreatedAt.Time) && msg.ID == arg.AfterID {
+				start = i + 1
+			}
+		}
+	}
+	end := min(start+int(arg.Limit), len(h.messages))
+	return h.messages[start:end], nil
+}
+
+func newExportTestService(history *exportHistory, member bool) *ChatService {
+	service := &ChatService{logger: zap.NewNop()}
+	service.isParticipantFn = func(ctx context.Context, arg repository.IsParticipantParams) (bool, error) {
+		return member, nil
+	}
+	service.exportMessagesFn = history.page
+	service.getAttachmentsForMessagesFn = func(ctx context.Context, messageIDs []pgtype.UUID) ([]repository.GetAttachmentsForMessagesRow, error) {
+		return nil, nil
+	}
+	return service
+}
+
+func TestExportConversation_PagesThroughHistory(t *testing.T) {
+	history := newExportHistory(t, 5)
+	service := newExportTestService(history, true)
+	stream := &fakeExportStream{ctx: contextWithUserID(testReaderID)}
+
+	err := service.ExportConversation(&chatv1.ExportConversationRequest{
+		ConversationId: testExportConversationID,
+		PageSize:       2,
+	}, stream)
+	require.NoError(t, err)
+
+	// 2 + 2 + 1 messages; the short last page ends the export without another query
+	require.Len(t, stream.chunks, 3)
+	require.Len(t, history.pages, 3)
+	assert.False(t, history.pages[0].AfterCreatedAt.Valid, "the first page starts at the oldest message")
+	assert.Equal(t, history.messages[1].ID, history.pages[1].AfterID)
+	assert.Equal(t, history.messages[3].ID, history.pages[2].AfterID)
+
+	var ids []string
+	for _, chunk := range stream.chunks {
+		assert.Equal(t, int32(len(chunk.Messages)), chunk.MessageCount)
+		assert.Empty(t, chunk.Data)
+		for _, msg := range chunk.Messages {
+			ids = append(ids, msg.Id)
+		}
+	}
+	want := make([]string, 0, len(history.messages))
+	for _, msg := range history.messages {
+		want = append(want, uuidToString(msg.ID))
+	}
+	assert.Equal(t, want, ids, "every message is exported once, oldest first")
+}
+
+func TestExportConversation_JSONLines(t *testing.T) {
+	history := newExportHistory(t, 3)
+	service := newExportTestService(history, true)
+	stream := &fakeExportStream{ctx: contextWithUserID(testReaderID)}
+
+	err := service.ExportConversation(&chatv1.ExportConversationRequest{
+		ConversationId: testExportConversationID,
+		Format:         chatv1.ExportFormat_EXPORT_FORMAT_JSONL,
+	}, stream)
+	require.NoError(t, err)
+	require.Len(t, stream.chunks, 1)
+	assert.Empty(t, stream.chunks[0].Messages)
+	assert.Equal(t, int32(3), stream.chunks[0].MessageCount)
+
+	scanner := bufio.NewScanner(bytes.NewReader(stream.chunks[0].Data))
+	var lines []exportLine
+	for scanner.Scan() {
+		var line exportLine
+		require.NoError(t, json.Unmarshal(scanner.Bytes(), &line))
+		lines = append(lines, line)
+	}
+	require.Len(t, lines, 3)
+	assert.Equal(t, uuidToString(history.messages[0].ID), lines[0].ID)
+	assert.Equal(t, "TEXT", lines[0].Type)
+	assert.Contains(t, string(stream.chunks[0].Data), "message 0 <b>", "content is not HTML-escaped")
+}
+
+func TestExportConversation_Authorization(t *testing.T) {
+	tests := []struct {
+		name     string
+		member   bool
+		roles    []string
+		wantCode codes.Code
+	}{
+		{name: "participant", member: true, wantCode: codes.OK},
+		{name: "outsider", wantCode: codes.PermissionDenied},
+		{name: "moderator outsider", roles: []string{auth.RoleModerator}, wantCode: codes.PermissionDenied},
+		{name: "admin outsider", roles: []string{auth.RoleAdmin}, wantCode: codes.OK},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			history := newExportHistory(t, 1)
+			service := newExportTestService(history, tt.member)
+			ctx := auth.SetRolesInContext(contextWithUserID(testReaderID), tt.roles)
+			stream := &fakeExportStream{ctx: ctx}
+
+			err := service.ExportConversation(&chatv1.ExportConversationRequest{ConversationId: testExportConversationID}, stream)
+			assert.Equal(t, tt.wantCode, status.Code(err))
+			if tt.wantCode != codes.OK {
+				assert.Empty(t, history.pages, "nothing is read for a caller without access")
+			}
+		})
+	}
+}
+
+func TestExportConversation_Errors(t *testing.T) {
+	tests := []struct {
+		name     string
+		req      *chatv1.ExportConversationRequest
+		queryErr error
+		sendErr  error
+		wantCode codes.Code
+	}{
+		{name: "missing conversation", req: &chatv1.ExportConversationRequest{}, wantCode: codes.InvalidArgument},
+		{name: "page size too large", req: &chatv1.ExportConversationRequest{ConversationId: testExportConversationID, PageSize: MaxExportPageSize + 1}, wantCode: codes.InvalidArgument},
+		{name: "unknown format", req: &chatv1.ExportConversationRequest{ConversationId: testExportConversationID, Format: 99}, wantCode: codes.InvalidArgument},
+		{name: "query failure", req: &chatv1.ExportConversationRequest{ConversationId: testExportConversationID}, queryErr: errors.New("db down"), wantCode: codes.Internal},
+		{name: "client gone", req: &chatv1.ExportConversationRequest{ConversationId: testExportConversationID}, sendErr: status.Error(codes.Canceled, "context canceled"), wantCode: codes.Canceled},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			history := newExportHistory(t, 3)
+			service := newExportTestService(history, true)
+			if tt.queryErr != nil {
+				service.exportMessagesFn = func(ctx context.Context, arg repository.ExportMessagesParams) ([]repository.Message, error) {
+					return nil, tt.queryErr
+				}
+			}
+			stream := &fakeExportStream{ctx: contextWithUserID(testReaderID), sendErr: tt.sendErr}
+
+			err := service.ExportConversation(tt.req, stream)
+			assert.Equal(t, tt.wantCode, status.Code(err))
+		})
+	}
+}
@@ -0,0 +1,107 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	chatv1 "chat-service/api/chat/v1"
+	"chat-service/internal/apierror"
+	"chat-service/internal/auth"
+	"chat-service/pkg/idempotency"
+
+	"go.uber.org/zap"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// InspectIdempotencyKey reports whether a client's idempotency key is still claimed and for how
+// long, for support cases where a client keeps getting "duplicate request" (admin only)
+func (s *ChatService) InspectIdempotencyKey(ctx context.Context, req *chatv1.InspectIdempotencyKeyRequest) (*chatv1.InspectIdempotencyKeyResponse, error) {
+	if err := auth.RequireRole(ctx, auth.RoleAdmin); err != nil {
+		return nil, err
+	}
+	if req == nil {
+		return nil, status.Error(codes.InvalidArgument, "request cannot be nil")
+	}
+	if req.Key == "" {
+		return nil, apierror.Validation("key", "key is required")
+	}
+
+	ttl, exists, err := s.idempotencyKeyTTL(ctx, req.Key)
+	if err != nil {
+		return nil, status.Error(codes.Internal, "failed to inspect idempotency key")
+	}
+
+	return &chatv1.InspectIdempotencyKeyResponse{
+		Key:        req.Key,
+		Exists:     exists,
+		TtlSeconds: ttlSeconds(ttl),
+	}, nil
+}
+
+// ClearIdempotencyKey releases a client's idempotency key so a send can be retried with it (admin only).
+// Clearing a key that does not exist succeeds with existed=false.
+func (s *ChatService) ClearIdempotencyKey(ctx context.Context, req *chatv1.ClearIdempotencyKeyRequest) (*chatv1.ClearIdempotencyKeyResponse, error) {
+	if err := auth.RequireRole(ctx, auth.RoleAdmin); err != nil {
+		return nil, err
+	}
+	if req == nil {
+		return nil, status.Error(codes.InvalidArgument, "request cannot be nil")
+	}
+	if req.Key == "" {
+		return nil, apierror.Validation("key", "key is required")
+	}
+
+	// Looked up first only to report whether there was anything to clear
+	ttl, existed, err := s.idempotencyKeyTTL(ctx, req.Key)
+	if err != nil {
+		return nil, status.Error(codes.Internal, "failed to clear idempotency key")
+	}
+	if existed {
+		if err := s.idempotencyCheck.Remove(ctx, req.Key); err != nil {
+			s.logger.Error("failed to remove idempotency key",
+				zap.Error(err),
+				zap.String("idempotency_key", req.Key),
+			)
+			return nil, status.Error(codes.Internal, "failed to clear idempotency key")
+		}
+	}
+
+	adminID, _ := getUserIDFromContext(ctx)
+	s.logger.Info("idempotency key cleared by admin",
+		zap.String("idempotency_key", req.Key),
+		zap.String("admin_id", adminID),
+		zap.Bool("existed", existed),
+		zap.Duration("remaining_ttl", ttl),
+	)
+
+	return &chatv1.ClearIdempotencyKeyResponse{
+		Key:     req.Key,
+		Existed: existed,
+	}, nil
+}
+
+// idempotencyKeyTTL looks up a key's remaining TTL; a missing key is not an error
+func (s *ChatService) idempotencyKeyTTL(ctx context.Context, key string) (time.Duration, bool, error) {
+	ttl, err := s.idempotencyCheck.TTL(ctx, key)
+	if errors.Is(err, idempotency.ErrKeyNotFound) {
+		return 0, false, nil
+	}
+	if err != nil {
+		s.logger.Error("failed to get idempotency key TTL",
+			zap.Error(err),
+			zap.String("idempotency_key", key),
+		)
+		return 0, false, err
+	}
+	return ttl, true, nil
+}
+
+// ttlSeconds converts a remaining TTL for the API, rounding up so a live key never reports 0
+func ttlSeconds(ttl time.Duration) int64 {
+	if ttl == idempotency.NoExpiry {
+		return -1
+	}
+	return int64((ttl + time.Second - 1) / time.Second)
+}
@@ -0,0 +1,115 @@
+package service
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	chatv1 "chat-service/api/chat/v1"
+	"chat-service/internal/auth"
+	"chat-service/pkg/idempotency"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func adminContext() context.Context {
+	return auth.SetRolesInContext(contextWithUserID(testReaderID), []string{auth.RoleAdmin})
+}
+
+func TestInspectIdempotencyKey(t *testing.T) {
+	tests := []struct {
+		name       string
+		ttl        time.Duration
+		ttlErr     error
+		wantExists bool
+		wantTTL    int64
+	}{
+		{name: "claimed key", ttl: 90*time.Minute + 500*time.Millisecond, wantExists: true, wantTTL: 5401},
+		{name: "key without expiry", ttl: idempotency.NoExpiry, wantExists: true, wantTTL: -1},
+		{name: "missing key", ttlErr: idempotency.ErrKeyNotFound, wantExists: false, wantTTL: 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			checker := new(MockIdempotencyChecker)
+			checker.On("TTL", mock.Anything, "client-key").Return(tt.ttl, tt.ttlErr)
+			service := NewChatService(nil, checker, zap.NewNop())
+
+			resp, err := service.InspectIdempotencyKey(adminContext(), &chatv1.InspectIdempotencyKeyRequest{Key: "client-key"})
+			require.NoError(t, err)
+			assert.Equal(t, "client-key", resp.Key)
+			assert.Equal(t, tt.wantExists, resp.Exists)
+			assert.Equal(t, tt.wantTTL, resp.TtlSeconds)
+			checker.AssertExpectations(t)
+		})
+	}
+}
+
+func TestClearIdempotencyKey(t *testing.T) {
+	checker := newMemoryIdempotencyChecker()
+	require.NoError(t, checker.Check(context.Background(), "client-key"))
+	service := NewChatService(nil, checker, zap.NewNop())
+
+	resp, err := service.ClearIdempotencyKey(adminContext(), &chatv1.ClearIdempotencyKeyRequest{Key: "client-key"})
+	require.NoError(t, err)
+	assert.True(t, resp.Existed)
+	assert.NoError(t, checker.Check(context.Background(), "client-key"), "the key can be claimed again")
+
+	delete(checker.keys, "client-key")
+	resp, err = service.ClearIdempotencyKey(adminContext(), &chatv1.ClearIdempotencyKeyRequest{Key: "client-key"})
+	require.NoError(t, err)
+	assert.False(t, resp.Existed, "clearing a missing key is not an error")
+	assert.Len(t, checker.removeErrs, 1, "nothing to remove the second time")
+}
+
+func TestIdempotencyKeyAdmin_Errors(t *testing.T) {
+	tests := []struct {
+		name     string
+		ctx      context.Context
+		key      string
+		setup    func(checker *MockIdempotencyChecker)
+		wantCode codes.Code
+	}{
+		{name: "no user", ctx: context.Background(), key: "client-key", wantCode: codes.Unauthenticated},
+		{name: "not an admin", ctx: contextWithUserID(testReaderID), key: "client-key", wantCode: codes.PermissionDenied},
+		{
+			name:     "moderator is not enough",
+			ctx:      auth.SetRolesInContext(contextWithUserID(testReaderID), []string{auth.RoleModerator}),
+			key:      "client-key",
+			wantCode: codes.PermissionDenied,
+		},
+		{name: "missing key", ctx: adminContext(), wantCode: codes.InvalidArgument},
+		{
+			name: "redis error",
+			ctx:  adminContext(),
+			key:  "client-key",
+			setup: func(checker *MockIdempotencyChecker) {
+				checker.On("TTL", mock.Anything, "client-key").Return(time.Duration(0), assert.AnError)
+			},
+			wantCode: codes.Internal,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			checker := new(MockIdempotencyChecker)
+			if tt.setup != nil {
+				tt.setup(checker)
+			}
+			service := NewChatService(nil, checker, zap.NewNop())
+
+			_, err := service.InspectIdempotencyKey(tt.ctx, &chatv1.InspectIdempotencyKeyRequest{Key: tt.key})
+			assert.Equal(t, tt.wantCode, status.Code(err), "inspect")
+
+			_, err = service.ClearIdempotencyKey(tt.ctx, &chatv1.ClearIdempotencyKeyRequest{Key: tt.key})
+			assert.Equal(t, tt.wantCode, status.Code(err), "clear")
+
+			checker.AssertNotCalled(t, "Remove", mock.Anything, mock.Anything)
+		})
+	}
+}
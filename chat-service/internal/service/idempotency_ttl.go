@@ -0,0 +1,67 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"strconv"
+	"time"
+
+	"google.golang.org/grpc/metadata"
+)
+
+// IdempotencyTTLHeader lets a client choose the dedup window of its idempotency key.
+// The value is a number of seconds ("600") or a Go duration ("10m"). It reaches
+// gRPC as metadata; the HTTP gateway forwards it like every other x- header.
+const IdempotencyTTLHeader = "x-idempotency-ttl"
+
+// Bounds applied to a requested idempotency TTL
+const (
+	// MinIdempotencyTTL keeps the window longer than any sane client retry loop
+	MinIdempotencyTTL = time.Minute
+	// MaxIdempotencyTTL caps how long a key may occupy Redis
+	MaxIdempotencyTTL = 7 * 24 * time.Hour
+)
+
+// ErrInvalidIdempotencyTTL is returned for malformed or non-positive x-idempotency-ttl values
+var ErrInvalidIdempotencyTTL = errors.New("x-idempotency-ttl must be a positive number of seconds or a duration like 10m")
+
+// idempotencyTTLFromContext returns the TTL requested through x-idempotency-ttl,
+// clamped to [MinIdempotencyTTL, MaxIdempotencyTTL]. ok is false when the header is absent.
+func idempotencyTTLFromContext(ctx context.Context) (ttl time.Duration, ok bool, err error) {
+	md, found := metadata.FromIncomingContext(ctx)
+	if !found {
+		return 0, false, nil
+	}
+	values := md.Get(IdempotencyTTLHeader)
+	if len(values) == 0 || values[0] == "" {
+		return 0, false, nil
+	}
+
+	ttl, err = parseIdempotencyTTL(values[0])
+	if err != nil {
+		return 0, false, err
+	}
+	return min(max(ttl, MinIdempotencyTTL), MaxIdempotencyTTL), true, nil
+}
+
+// parseIdempotencyTTL accepts whole seconds or a Go duration string
+func parseIdempotencyTTL(value string) (time.Duration, error) {
+	var ttl time.Duration
+	if seconds, err := strconv.ParseInt(value, 10, 64); err == nil {
+		if seconds > int64(MaxIdempotencyTTL/time.Second) {
+			// Clamped below; avoids overflowing the conversion
+			return MaxIdempotencyTTL, nil
+		}
+		ttl = time.Duration(seconds) * time.Second
+	} else {
+		ttl, err = time.ParseDuration(value)
+		if err != nil {
+			return 0, ErrInvalidIdempotencyTTL
+		}
+	}
+
+	if ttl <= 0 {
+		return 0, ErrInvalidIdempotencyTTL
+	}
+	return ttl, nil
+}
@@ -0,0 +1,104 @@
+package service
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	chatv1 "chat-service/api/chat/v1"
+	"chat-service/pkg/idempotency"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+func contextWithIdempotencyTTL(ctx context.Context, value string) context.Context {
+	return metadata.NewIncomingContext(ctx, metadata.Pairs(IdempotencyTTLHeader, value))
+}
+
+func TestIdempotencyTTLFromContext(t *testing.T) {
+	tests := []struct {
+		name    string
+		value   string
+		want    time.Duration
+		wantErr bool
+	}{
+		{name: "seconds", value: "600", want: 10 * time.Minute},
+		{name: "duration", value: "2h", want: 2 * time.Hour},
+		{name: "clamped to minimum", value: "5", want: MinIdempotencyTTL},
+		{name: "clamped to maximum", value: "720h", want: MaxIdempotencyTTL},
+		{name: "huge seconds clamped without overflow", value: "9223372036854775807", want: MaxIdempotencyTTL},
+		{name: "zero", value: "0", wantErr: true},
+		{name: "negative", value: "-60", wantErr: true},
+		{name: "negative duration", value: "-1h", wantErr: true},
+		{name: "garbage", value: "forever", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ttl, ok, err := idempotencyTTLFromContext(contextWithIdempotencyTTL(context.Background(), tt.value))
+			if tt.wantErr {
+				assert.ErrorIs(t, err, ErrInvalidIdempotencyTTL)
+				assert.False(t, ok)
+				return
+			}
+			require.NoError(t, err)
+			assert.True(t, ok)
+			assert.Equal(t, tt.want, ttl)
+		})
+	}
+}
+
+func TestIdempotencyTTLFromContext_Absent(t *testing.T) {
+	for _, ctx := range []context.Context{
+		context.Background(),
+		metadata.NewIncomingContext(context.Background(), metadata.Pairs("x-user-id", "user-1")),
+		contextWithIdempotencyTTL(context.Background(), ""),
+	} {
+		_, ok, err := idempotencyTTLFromContext(ctx)
+		assert.NoError(t, err)
+		assert.False(t, ok)
+	}
+}
+
+func TestSendMessage_CustomIdempotencyTTL(t *testing.T) {
+	mockIdempotency := new(MockIdempotencyChecker)
+	service := &ChatService{idempotencyCheck: mockIdempotency, logger: zap.NewNop()}
+
+	ctx := contextWithIdempotencyTTL(contextWithUserID("660e8400-e29b-41d4-a716-446655440000"), "300")
+	req := &chatv1.SendMessageRequest{
+		ConversationId: "550e8400-e29b-41d4-a716-446655440000",
+		Content:        "Hello",
+		IdempotencyKey: "key-123",
+	}
+
+	// A duplicate stops the request right after the check, which is all this test needs
+	mockIdempotency.On("CheckWithTTL", ctx, "key-123", 5*time.Minute).Return(idempotency.ErrDuplicateRequest)
+
+	_, err := service.SendMessage(ctx, req)
+	assert.Equal(t, codes.AlreadyExists, status.Code(err))
+	mockIdempotency.AssertExpectations(t)
+	mockIdempotency.AssertNotCalled(t, "Check", ctx, "key-123")
+}
+
+func TestSendMessage_InvalidIdempotencyTTL(t *testing.T) {
+	mockIdempotency := new(MockIdempotencyChecker)
+	service := &ChatService{idempotencyCheck: mockIdempotency, logger: zap.NewNop()}
+
+	ctx := contextWithIdempotencyTTL(contextWithUserID("660e8400-e29b-41d4-a716-446655440000"), "-1")
+	req := &chatv1.SendMessageRequest{
+		ConversationId: "550e8400-e29b-41d4-a716-446655440000",
+		Content:        "Hello",
+		IdempotencyKey: "key-123",
+	}
+
+	resp, err := service.SendMessage(ctx, req)
+	assert.Nil(t, resp)
+	assert.Equal(t, codes.InvalidArgument, status.Code(err))
+	mockIdempotency.AssertNotCalled(t, "Check")
+	mockIdempotency.AssertNotCalled(t, "CheckWithTTL")
+}
@@ -0,0 +1,233 @@
+package service
+
+import (
+	"context"
+	"regexp"
+	"strings"
+
+	chatv1 "chat-service/api/chat/v1"
+	"chat-service/internal/apierror"
+	"chat-service/internal/repository"
+
+	"github.com/jackc/pgx/v5/pgtype"
+	"go.uber.org/zap"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// mentionPattern matches @mentions of a user id or a username. The @ must not follow a word
+// character, so e-mail addresses are not mentions.
+var mentionPattern = regexp.MustCompile(`(?:^|[^\w@.])@(\w[\w.-]{0,63})`)
+
+// mentionTokens are the @mentions written in a message, not yet matched against its conversation
+type mentionTokens struct {
+	userIDs   map[string]struct{} // canonical user ids
+	usernames map[string]struct{} // lower-cased
+}
+
+// parseMentions extracts the @user_id and @username mentions of content
+func parseMentions(content string) mentionTokens {
+	var tokens mentionTokens
+	for _, m := range mentionPattern.FindAllStringSubmatch(content, -1) {
+		// Sentence punctuation after a mention is not part of it: "thanks @alice."
+		token := strings.TrimRight(m[1], ".-")
+		if token == "" {
+			continue
+		}
+		if id, err := parseUUID(token); err == nil {
+			if tokens.userIDs == nil {
+				tokens.userIDs = make(map[string]struct{})
+			}
+			tokens.userIDs[uuidToString(id)] = struct{}{}
+			continue
+		}
+		if tokens.usernames == nil {
+			tokens.usernames = make(map[string]struct{})
+		}
+		tokens.usernames[strings.ToLower(token)] = struct{}{}
+	}
+	return tokens
+}
+
+// mentionUsernames maps the mentioned usernames to the ids of the conversation members (existing
+// participants and the message's receiver_ids) that have them. Usernames come from the profile
+// resolver; without one, or if the lookup fails, only @user_id mentions are recognised.
+// It runs before the send transaction so the lookups never hold its connection.
+func (s *ChatService) mentionUsernames(ctx context.Context, conversationID pgtype.UUID, receiverIDs []pgtype.UUID, usernames map[string]struct{}) map[string]string {
+	if len(usernames) == 0 || s.profiles == nil {
+		return nil
+	}
+
+	members, err := s.listParticipants(ctx, conversationID)
+	if err != nil {
+		s.logger.Warn("failed to list participants for mentions",
+			zap.Error(err),
+			zap.String("conversation_id", uuidToString(conversationID)),
+		)
+		return nil
+	}
+
+	memberIDs := make([]string, 0, len(members)+len(receiverIDs))
+	for _, m := range members {
+		memberIDs = append(memberIDs, uuidToString(m.UserID))
+	}
+	for _, id := range receiverIDs {
+		memberIDs = append(memberIDs, uuidToString(id))
+	}
+
+	ids := make(map[string]string, len(usernames))
+	for id, p := range s.resolveProfiles(ctx, memberIDs) {
+		username := strings.ToLower(p.Username)
+		if _, ok := usernames[username]; ok && username != "" {
+			ids[username] = id
+		}
+	}
+	return ids
+}
+
+// mentionedParticipants returns the participants mentioned by a message, other than its sender.
+// Mentions of users outside the conversation are ignored.
+func mentionedParticipants(tokens mentionTokens, usernameIDs map[string]string, participants []pgtype.UUID, sender pgtype.UUID) []pgtype.UUID {
+	if len(tokens.userIDs) == 0 && len(usernameIDs) == 0 {
+		return nil
+	}
+
+	byUsername := make(map[string]struct{}, len(usernameIDs))
+	for _, id := range usernameIDs {
+		byUsername[id] = struct{}{}
+	}
+
+	var mentioned []pgtype.UUID
+	seen := make(map[pgtype.UUID]struct{}, len(participants))
+	for _, p := range participants {
+		if _, dup := seen[p]; dup || p == sender {
+			continue
+		}
+		seen[p] = struct{}{}
+		id := uuidToString(p)
+		_, byID := tokens.userIDs[id]
+		_, byName := byUsername[id]
+		if byID || byName {
+			mentioned = append(mentioned, p)
+		}
+	}
+	return mentioned
+}
+
+// GetMentions returns a page of the messages that mention the requester, newest first.
+// Messages of conversations the requester has left, and deleted ones, are not listed.
+func (s *ChatService) GetMentions(ctx context.Context, req *chatv1.GetMentionsRequest) (*chatv1.GetMentionsResponse, error) {
+	if req == nil {
+		return nil, status.Error(codes.InvalidArgument, "request cannot be nil")
+	}
+
+	var before pageCursor
+	if req.Cursor != "" {
+		var err error
+		before, err = decodePageCursor(req.Cursor)
+		if err != nil || before.Pinned {
+			return nil, apierror.Validation("cursor", "invalid cursor")
+		}
+	}
+
+	// Extract user_id from context (set by auth middleware)
+	userID, err := getUserIDFromContext(ctx)
+	if err != nil {
+		s.logger.Error("failed to get user_id from context", zap.Error(err))
+		return nil, err
+	}
+
+	userUUID, err := parseUUID(userID)
+	if err != nil {
+		return nil, apierror.Validation("user_id", "invalid user_id")
+	}
+
+	messages, err := s.getMentions(ctx, repository.GetMentionsParams{
+		UserID:          userUUID,
+		BeforeCreatedAt: before.Timestamp,
+		BeforeID:        before.ID,
+		Limit:           sanitizeLimit(req.Limit),
+	})
+	if err != nil {
+		s.logger.Error("failed to fetch mentions",
+			zap.Error(err),
+			zap.String("user_id", userID),
+		)
+		return nil, status.Error(codes.Internal, "failed to fetch mentions")
+	}
+
+	attachmentsByMessage, err := s.loadAttachments(ctx, messages)
+	if err != nil {
+		s.logger.Error("failed to fetch attachments",
+			zap.Error(err),
+			zap.String("user_id", userID),
+		)
+		return nil, status.Error(codes.Internal, "failed to fetch mentions")
+	}
+
+	reactionsByMessage, err := s.loadReactions(ctx, messages, userUUID)
+	if err != nil {
+		s.logger.Error("failed to fetch reactions",
+			zap.Error(err),
+			zap.String("user_id", userID),
+		)
+		return nil, status.Error(codes.Internal, "failed to fetch mentions")
+	}
+
+	senders := s.resolveProfiles(ctx, distinctSenderIDs(messages))
+
+	respMessages := make([]*chatv1.ChatMessage, 0, len(messages))
+	for _, msg := range messages {
+		chatMsg := &chatv1.ChatMessage{
+			Id:             uuidToString(msg.ID),
+			ConversationId: uuidToString(msg.ConversationID),
+			SenderId:       uuidToString(msg.SenderID),
+			Content:        msg.Content,
+			CreatedAt:      formatTimestamp(msg.CreatedAt),
+			Type:           getProtoMessageType(msg.Type),
+			Attachments:    attachmentsByMessage[msg.ID],
+			Reactions:      reactionsByMessage[msg.ID],
+		}
+		if msg.MediaUrl.Valid {
+			chatMsg.MediaUrl = msg.MediaUrl.String
+		}
+		if sender, ok := senders[chatMsg.SenderId]; ok {
+			chatMsg.SenderName = sender.DisplayName
+			chatMsg.SenderAvatarUrl = sender.AvatarURL
+		}
+		applyMessageRevisions(chatMsg, msg)
+		respMessages = append(respMessages, chatMsg)
+	}
+
+	// A mention's keyset position is its message's (created_at, id)
+	nextCursor := ""
+	if len(messages) > 0 {
+		last := messages[len(messages)-1]
+		nextCursor = encodePageCursor(last.CreatedAt, last.ID)
+	}
+
+	return &chatv1.GetMentionsResponse{
+		Messages:   respMessages,
+		NextCursor: nextCursor,
+	}, nil
+}
+
+func (s *ChatService) getMentions(ctx context.Context, params repository.GetMentionsParams) ([]repository.Message, error) {
+	ctx, cancel := s.queryContext(ctx)
+	defer cancel()
+
+	if s.getMentionsFn != nil {
+		return s.getMentionsFn(ctx, params)
+	}
+	return s.queries.GetMentions(ctx, params)
+}
+
+func (s *ChatService) insertMessageMentions(ctx context.Context, qtx *repository.Queries, params repository.InsertMessageMentionsParams) error {
+	ctx, cancel := s.queryContext(ctx)
+	defer cancel()
+
+	if s.insertMessageMentionsFn != nil {
+		return s.insertMessageMentionsFn(ctx, qtx, params)
+	}
+	return qtx.InsertMessageMentions(ctx, params)
+}
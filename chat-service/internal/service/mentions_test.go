@@ -0,0 +1,199 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+	"time"
+
+	chatv1 "chat-service/api/chat/v1"
+	"chat-service/internal/repository"
+	"chat-service/pkg/profile"
+
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+const (
+	testMentionSenderID = "660e8400-e29b-41d4-a716-446655440000"
+	testMentionAliceID  = "880e8400-e29b-41d4-a716-446655440001"
+	testMentionBobID    = "880e8400-e29b-41d4-a716-446655440002"
+)
+
+func TestParseMentions(t *testing.T) {
+	tokens := parseMentions("hey @Alice, @bob. and @880E8400-E29B-41D4-A716-446655440002 — mail carol@example.com")
+	assert.Equal(t, map[string]struct{}{"alice": {}, "bob": {}}, tokens.usernames)
+	assert.Equal(t, map[string]struct{}{testMentionBobID: {}}, tokens.userIDs, "ids are canonical")
+
+	tokens = parseMentions("@alice@bob")
+	assert.Equal(t, map[string]struct{}{"alice": {}}, tokens.usernames)
+
+	tokens = parseMentions("no mentions @ all")
+	assert.Nil(t, tokens.usernames)
+	assert.Nil(t, tokens.userIDs)
+}
+
+func TestMentionedParticipants(t *testing.T) {
+	sender := mustParseUUID(t, testMentionSenderID)
+	alice := mustParseUUID(t, testMentionAliceID)
+	bob := mustParseUUID(t, testMentionBobID)
+	participants := []pgtype.UUID{sender, alice, bob, alice}
+
+	tokens := parseMentions("@" + testMentionSenderID + " @" + testMentionAliceID + " @990e8400-e29b-41d4-a716-446655440000")
+	assert.Equal(t, []pgtype.UUID{alice}, mentionedParticipants(tokens, nil, participants, sender),
+		"the sender and non-members are not mentioned, each participant once")
+
+	byName := map[string]string{"bob": testMentionBobID}
+	assert.Equal(t, []pgtype.UUID{alice, bob}, mentionedParticipants(tokens, byName, participants, sender))
+
+	assert.Nil(t, mentionedParticipants(parseMentions("hello"), nil, participants, sender))
+}
+
+func TestMentionUsernames(t *testing.T) {
+	resolver := &stubProfileResolver{profiles: map[string]profile.Profile{
+		testMentionAliceID: {DisplayName: "Alice", Username: "Alice"},
+		testMentionBobID:   {DisplayName: "Bob", Username: "bob"},
+	}}
+	service := &ChatService{logger: zap.NewNop(), profiles: resolver}
+	service.listParticipantsFn = func(ctx context.Context, conversationID pgtype.UUID) ([]repository.ListConversationParticipantsRow, error) {
+		return []repository.ListConversationParticipantsRow{{UserID: mustParseUUID(t, testMentionAliceID)}}, nil
+	}
+
+	conversationID := mustParseUUID(t, testPinConversationID)
+	receivers := []pgtype.UUID{mustParseUUID(t, testMentionBobID)}
+	ids := service.mentionUsernames(context.Background(), conversationID, receivers, map[string]struct{}{"alice": {}, "carol": {}})
+	assert.Equal(t, map[string]string{"alice": testMentionAliceID}, ids)
+	assert.ElementsMatch(t, []string{testMentionAliceID, testMentionBobID}, resolver.requested, "members and new receivers are looked up")
+
+	// Nothing to look up without usernames
+	resolver.requested = nil
+	assert.Nil(t, service.mentionUsernames(context.Background(), conversationID, receivers, nil))
+	assert.Empty(t, resolver.requested)
+}
+
+func TestSendMessage_RecordsMentions(t *testing.T) {
+	conversationID := mustParseUUID(t, testPinConversationID)
+	senderID := mustParseUUID(t, testMentionSenderID)
+	messageID := mustParseUUID(t, "770e8400-e29b-41d4-a716-446655440000")
+	aliceID := mustParseUUID(t, testMentionAliceID)
+	bobID := mustParseUUID(t, testMentionBobID)
+	content := "@bob have you met @" + testMentionAliceID + "?"
+
+	mockIdempotency := new(MockIdempotencyChecker)
+	mocks := newMockTransactionHelpers()
+	mocks.setupHappyPathTransaction(conversationID, senderID, messageID, content)
+	mocks.mockGetConversationParticipants = func(ctx context.Context, qtx *repository.Queries, convID pgtype.UUID) ([]pgtype.UUID, error) {
+		return []pgtype.UUID{senderID, aliceID, bobID}, nil
+	}
+	var payload []byte
+	mocks.mockInsertOutbox = func(ctx context.Context, qtx *repository.Queries, params repository.InsertOutboxParams) error {
+		payload = params.Payload
+		return nil
+	}
+
+	service := &ChatService{
+		idempotencyCheck: mockIdempotency,
+		logger:           zap.NewNop(),
+		profiles: &stubProfileResolver{profiles: map[string]profile.Profile{
+			testMentionBobID: {Username: "bob"},
+		}},
+	}
+	mocks.injectIntoService(service)
+	service.listParticipantsFn = func(ctx context.Context, convID pgtype.UUID) ([]repository.ListConversationParticipantsRow, error) {
+		return []repository.ListConversationParticipantsRow{{UserID: senderID}, {UserID: aliceID}, {UserID: bobID}}, nil
+	}
+	var recorded repository.InsertMessageMentionsParams
+	service.insertMessageMentionsFn = func(ctx context.Context, qtx *repository.Queries, params repository.InsertMessageMentionsParams) error {
+		recorded = params
+		return nil
+	}
+
+	ctx := contextWithUserID(testMentionSenderID)
+	mockIdempotency.On("Check", ctx, "mention-key").Return(nil)
+
+	_, err := service.SendMessage(ctx, &chatv1.SendMessageRequest{
+		ConversationId: testPinConversationID,
+		Content:        content,
+		IdempotencyKey: "mention-key",
+	})
+	require.NoError(t, err)
+
+	assert.Equal(t, messageID, recorded.MessageID)
+	assert.Equal(t, conversationID, recorded.ConversationID)
+	assert.Equal(t, []pgtype.UUID{aliceID, bobID}, recorded.UserIds)
+	assert.True(t, recorded.CreatedAt.Valid, "mentions sort by the message's created_at")
+
+	var event struct {
+		Deliveries []messageDelivery `json:"deliveries"`
+	}
+	require.NoError(t, json.Unmarshal(payload, &event))
+	assert.Equal(t, []messageDelivery{
+		{UserID: testMentionAliceID, Notify: true, Mentioned: true},
+		{UserID: testMentionBobID, Notify: true, Mentioned: true},
+	}, event.Deliveries)
+}
+
+func TestGetMentions_Success(t *testing.T) {
+	createdAt := mustTimestamptz(t, time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC))
+	message := repository.Message{
+		ID:             mustParseUUID(t, "770e8400-e29b-41d4-a716-446655440000"),
+		ConversationID: mustParseUUID(t, testPinConversationID),
+		SenderID:       mustParseUUID(t, testMentionSenderID),
+		Content:        "@alice look",
+		Type:           "TEXT",
+		CreatedAt:      createdAt,
+	}
+
+	var calls []repository.GetMentionsParams
+	service := &ChatService{
+		logger:                      zap.NewNop(),
+		getAttachmentsForMessagesFn: noAttachments,
+		getReactionsForMessagesFn:   noReactions,
+	}
+	service.getMentionsFn = func(ctx context.Context, arg repository.GetMentionsParams) ([]repository.Message, error) {
+		calls = append(calls, arg)
+		if len(calls) > 1 {
+			return nil, nil
+		}
+		return []repository.Message{message}, nil
+	}
+
+	ctx := contextWithUserID(testMentionAliceID)
+	resp, err := service.GetMentions(ctx, &chatv1.GetMentionsRequest{})
+	require.NoError(t, err)
+	require.Len(t, resp.Messages, 1)
+	assert.Equal(t, "@alice look", resp.Messages[0].Content)
+	assert.Equal(t, testPinConversationID, resp.Messages[0].ConversationId)
+	assert.Equal(t, mustParseUUID(t, testMentionAliceID), calls[0].UserID)
+	assert.Equal(t, defaultMessagesLimit, calls[0].Limit)
+	assert.False(t, calls[0].BeforeCreatedAt.Valid)
+
+	resp, err = service.GetMentions(ctx, &chatv1.GetMentionsRequest{Cursor: resp.NextCursor, Limit: 10})
+	require.NoError(t, err)
+	assert.Empty(t, resp.Messages)
+	assert.Empty(t, resp.NextCursor)
+	assert.True(t, createdAt.Time.Equal(calls[1].BeforeCreatedAt.Time))
+	assert.Equal(t, message.ID, calls[1].BeforeID)
+	assert.Equal(t, int32(10), calls[1].Limit)
+}
+
+func TestGetMentions_Errors(t *testing.T) {
+	service := &ChatService{logger: zap.NewNop()}
+	service.getMentionsFn = func(ctx context.Context, arg repository.GetMentionsParams) ([]repository.Message, error) {
+		return nil, assert.AnError
+	}
+	ctx := contextWithUserID(testMentionAliceID)
+
+	_, err := service.GetMentions(ctx, &chatv1.GetMentionsRequest{Cursor: "not a cursor"})
+	assert.Equal(t, codes.InvalidArgument, status.Code(err))
+
+	_, err = service.GetMentions(ctx, &chatv1.GetMentionsRequest{})
+	assert.Equal(t, codes.Internal, status.Code(err))
+
+	_, err = service.GetMentions(context.Background(), &chatv1.GetMentionsRequest{})
+	assert.Equal(t, codes.Unauthenticated, status.Code(err))
+}
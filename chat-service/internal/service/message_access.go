@@ -0,0 +1,56 @@
+package service
+
+import (
+	"context"
+	"errors"
+
+	"chat-service/internal/apierror"
+	"chat-service/internal/repository"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+	"go.uber.org/zap"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// getMessageForParticipant loads one message and checks that userID participates in its
+// conversation. It is the shared access check of operations on a single message
+// (reactions, edits, replies, deletes), so they all fail the same way:
+// NotFound when the message does not exist, PermissionDenied when the user is not a
+// participant, Internal on database errors. The returned error is a gRPC status.
+func (s *ChatService) getMessageForParticipant(ctx context.Context, messageID, userID pgtype.UUID) (repository.Message, error) {
+	msg, err := s.getMessageByID(ctx, messageID)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return repository.Message{}, apierror.New(codes.NotFound, apierror.CodeNotFound, "message not found", nil)
+		}
+		s.logger.Error("failed to fetch message",
+			zap.Error(err),
+			zap.String("message_id", messageID.String()),
+		)
+		return repository.Message{}, status.Error(codes.Internal, "failed to fetch message")
+	}
+
+	isMember, err := s.isParticipant(ctx, repository.IsParticipantParams{
+		ConversationID: msg.ConversationID,
+		UserID:         userID,
+	})
+	if err != nil {
+		s.logger.Error("failed to check conversation membership",
+			zap.Error(err),
+			zap.String("conversation_id", msg.ConversationID.String()),
+			zap.String("user_id", userID.String()),
+		)
+		return repository.Message{}, status.Error(codes.Internal, "failed to fetch message")
+	}
+	if !isMember {
+		s.logger.Warn("user is not a participant of the message's conversation",
+			zap.String("message_id", messageID.String()),
+			zap.String("user_id", userID.String()),
+		)
+		return repository.Message{}, apierror.New(codes.PermissionDenied, apierror.CodePermissionDenied, "not a participant of this conversation", nil)
+	}
+
+	return msg, nil
+}
@@ -0,0 +1,86 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"chat-service/internal/repository"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+const (
+	testAccessMessageID      = "770e8400-e29b-41d4-a716-446655440000"
+	testAccessConversationID = "550e8400-e29b-41d4-a716-446655440000"
+	testAccessUserID         = "660e8400-e29b-41d4-a716-446655440000"
+)
+
+func TestGetMessageForParticipant_Success(t *testing.T) {
+	messageID := mustParseUUID(t, testAccessMessageID)
+	conversationID := mustParseUUID(t, testAccessConversationID)
+	userID := mustParseUUID(t, testAccessUserID)
+
+	var checked repository.IsParticipantParams
+	service := &ChatService{logger: zap.NewNop()}
+	service.getMessageByIDFn = func(ctx context.Context, id pgtype.UUID) (repository.Message, error) {
+		assert.Equal(t, messageID, id)
+		return repository.Message{ID: id, ConversationID: conversationID, Content: "Hello"}, nil
+	}
+	service.isParticipantFn = func(ctx context.Context, arg repository.IsParticipantParams) (bool, error) {
+		checked = arg
+		return true, nil
+	}
+
+	msg, err := service.getMessageForParticipant(context.Background(), messageID, userID)
+	require.NoError(t, err)
+	assert.Equal(t, "Hello", msg.Content)
+	assert.Equal(t, repository.IsParticipantParams{ConversationID: conversationID, UserID: userID}, checked,
+		"membership is checked against the message's own conversation")
+}
+
+func TestGetMessageForParticipant_Errors(t *testing.T) {
+	tests := []struct {
+		name           string
+		getErr         error
+		isMember       bool
+		participantErr error
+		errCode        codes.Code
+	}{
+		{name: "message not found", getErr: pgx.ErrNoRows, errCode: codes.NotFound},
+		{name: "database error loading message", getErr: errors.New("database unavailable"), errCode: codes.Internal},
+		{name: "not a participant", isMember: false, errCode: codes.PermissionDenied},
+		{name: "database error checking membership", participantErr: errors.New("database unavailable"), errCode: codes.Internal},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			participantChecked := false
+			service := &ChatService{logger: zap.NewNop()}
+			service.getMessageByIDFn = func(ctx context.Context, id pgtype.UUID) (repository.Message, error) {
+				if tt.getErr != nil {
+					return repository.Message{}, tt.getErr
+				}
+				return repository.Message{ID: id, ConversationID: mustParseUUID(t, testAccessConversationID)}, nil
+			}
+			service.isParticipantFn = func(ctx context.Context, arg repository.IsParticipantParams) (bool, error) {
+				participantChecked = true
+				return tt.isMember, tt.participantErr
+			}
+
+			msg, err := service.getMessageForParticipant(context.Background(),
+				mustParseUUID(t, testAccessMessageID), mustParseUUID(t, testAccessUserID))
+			assert.Equal(t, tt.errCode, status.Code(err))
+			assert.Equal(t, repository.Message{}, msg, "no message content leaks on error")
+			if tt.getErr != nil {
+				assert.False(t, participantChecked)
+			}
+		})
+	}
+}
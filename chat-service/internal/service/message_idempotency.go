@@ -0,0 +1,61 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	chatv1 "chat-service/api/chat/v1"
+	"chat-service/internal/repository"
+
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/jackc/pgx/v5/pgtype"
+	"go.uber.org/zap"
+)
+
+// messageIdempotencyIndex is the unique index on messages(sender_id, idempotency_key)
+const messageIdempotencyIndex = "idx_messages_sender_idempotency"
+
+// pgUniqueViolation is the SQLSTATE of a unique constraint violation
+const pgUniqueViolation = "23505"
+
+// isUniqueViolation reports whether err is a unique violation of the named constraint or index
+func isUniqueViolation(err error, constraint string) bool {
+	var pgErr *pgconn.PgError
+	return errors.As(err, &pgErr) && pgErr.Code == pgUniqueViolation && pgErr.ConstraintName == constraint
+}
+
+// idempotencyKeyParam stores the request's idempotency key with the message
+func idempotencyKeyParam(key string) pgtype.Text {
+	return pgtype.Text{String: key, Valid: key != ""}
+}
+
+// replayStoredMessage answers a send whose idempotency key is already stored with a message.
+// Redis let the request through (the key was lost, e.g. after a flush), but the database is
+// the source of truth: the message exists, so the retry gets its id instead of a second copy.
+// The transaction that hit the unique violation is aborted, so the lookup runs on the pool.
+func (s *ChatService) replayStoredMessage(ctx context.Context, sender pgtype.UUID, key string) (*chatv1.SendMessageResponse, error) {
+	messageID, err := s.getMessageIDByIdempotencyKey(ctx, repository.GetMessageIDByIdempotencyKeyParams{
+		SenderID:       sender,
+		IdempotencyKey: idempotencyKeyParam(key),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to load message for idempotency key: %w", err)
+	}
+
+	s.logger.Warn("idempotency key missing from redis, replaying stored message",
+		zap.String("idempotency_key", key),
+		zap.String("message_id", uuidToString(messageID)),
+	)
+	return &chatv1.SendMessageResponse{MessageId: uuidToString(messageID)}, nil
+}
+
+func (s *ChatService) getMessageIDByIdempotencyKey(ctx context.Context, arg repository.GetMessageIDByIdempotencyKeyParams) (pgtype.UUID, error) {
+	ctx, cancel := s.queryContext(ctx)
+	defer cancel()
+
+	if s.getMessageIDByIdempotencyKeyFn != nil {
+		return s.getMessageIDByIdempotencyKeyFn(ctx, arg)
+	}
+	return s.queries.GetMessageIDByIdempotencyKey(ctx, arg)
+}
@@ -0,0 +1,108 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+
+	chatv1 "chat-service/api/chat/v1"
+	"chat-service/internal/repository"
+
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func TestIsUniqueViolation(t *testing.T) {
+	violation := &pgconn.PgError{Code: pgUniqueViolation, ConstraintName: messageIdempotencyIndex}
+
+	assert.True(t, isUniqueViolation(violation, messageIdempotencyIndex))
+	assert.True(t, isUniqueViolation(fmt.Errorf("insert: %w", violation), messageIdempotencyIndex))
+	assert.False(t, isUniqueViolation(&pgconn.PgError{Code: pgUniqueViolation, ConstraintName: "messages_pkey"}, messageIdempotencyIndex))
+	assert.False(t, isUniqueViolation(&pgconn.PgError{Code: "23503", ConstraintName: messageIdempotencyIndex}, messageIdempotencyIndex))
+	assert.False(t, isUniqueViolation(errors.New("connection reset"), messageIdempotencyIndex))
+	assert.False(t, isUniqueViolation(nil, messageIdempotencyIndex))
+}
+
+func TestSendMessage_ReplaysStoredMessage(t *testing.T) {
+	tests := []struct {
+		name       string
+		insertErr  error
+		wantCode   codes.Code
+		wantReplay bool
+	}{
+		{
+			name:       "idempotency key already stored",
+			insertErr:  &pgconn.PgError{Code: pgUniqueViolation, ConstraintName: messageIdempotencyIndex},
+			wantCode:   codes.OK,
+			wantReplay: true,
+		},
+		{
+			name:      "other unique violation",
+			insertErr: &pgconn.PgError{Code: pgUniqueViolation, ConstraintName: "messages_pkey"},
+			wantCode:  codes.Internal,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			conversationID := mustParseUUID(t, "550e8400-e29b-41d4-a716-446655440000")
+			senderID := mustParseUUID(t, "660e8400-e29b-41d4-a716-446655440000")
+			messageID := mustParseUUID(t, "770e8400-e29b-41d4-a716-446655440000")
+			storedID := mustParseUUID(t, "990e8400-e29b-41d4-a716-446655440000")
+
+			mocks := newMockTransactionHelpers()
+			mocks.setupHappyPathTransaction(conversationID, senderID, messageID, "hello")
+			var inserted repository.InsertMessageParams
+			mocks.mockInsertMessage = func(ctx context.Context, qtx *repository.Queries, params repository.InsertMessageParams) (repository.Message, error) {
+				inserted = params
+				return repository.Message{}, tt.insertErr
+			}
+			committed := false
+			mocks.mockCommitTx = func(ctx context.Context, tx repository.DBTX) error {
+				committed = true
+				return nil
+			}
+
+			mockIdempotency := new(MockIdempotencyChecker)
+			service := &ChatService{idempotencyCheck: mockIdempotency, logger: zap.NewNop()}
+			mocks.injectIntoService(service)
+			var lookup repository.GetMessageIDByIdempotencyKeyParams
+			service.getMessageIDByIdempotencyKeyFn = func(ctx context.Context, arg repository.GetMessageIDByIdempotencyKeyParams) (pgtype.UUID, error) {
+				lookup = arg
+				return storedID, nil
+			}
+
+			ctx := contextWithUserID(uuidToString(senderID))
+			mockIdempotency.On("Check", ctx, "key-123").Return(nil)
+			if !tt.wantReplay {
+				mockIdempotency.On("Remove", mock.Anything, "key-123").Return(nil)
+			}
+
+			resp, err := service.SendMessage(ctx, &chatv1.SendMessageRequest{
+				ConversationId: uuidToString(conversationID),
+				Content:        "hello",
+				IdempotencyKey: "key-123",
+			})
+			assert.Equal(t, tt.wantCode, status.Code(err))
+			assert.Equal(t, pgtype.Text{String: "key-123", Valid: true}, inserted.IdempotencyKey, "the key is stored with the message")
+			assert.False(t, committed, "the aborted transaction is never committed")
+
+			if tt.wantReplay {
+				require.NotNil(t, resp)
+				assert.Equal(t, uuidToString(storedID), resp.MessageId)
+				assert.Equal(t, "SENT", resp.Status)
+				assert.Equal(t, senderID, lookup.SenderID)
+				assert.Equal(t, "key-123", lookup.IdempotencyKey.String)
+			}
+			// A replay keeps the key claimed; it names a stored message
+			mockIdempotency.AssertExpectations(t)
+		})
+	}
+}
@@ -0,0 +1,25 @@
+package service
+
+import (
+	chatv1 "chat-service/api/chat/v1"
+	"chat-service/internal/repository"
+)
+
+// applyMessageRevisions adds the sync markers of msg to chatMsg. An edited message reports
+// edited and edited_at. A deleted message becomes a tombstone: it keeps its id, sender, type and
+// position in the history, but its content, media, attachments and reactions are blanked, so a client that
+// cached it learns to drop its copy without the server handing the deleted content out again.
+func applyMessageRevisions(chatMsg *chatv1.ChatMessage, msg repository.Message) {
+	if msg.EditedAt.Valid {
+		chatMsg.Edited = true
+		chatMsg.EditedAt = formatTimestamp(msg.EditedAt)
+	}
+	if msg.DeletedAt.Valid {
+		chatMsg.Deleted = true
+		chatMsg.Content = ""
+		chatMsg.MediaUrl = ""
+		chatMsg.Attachments = nil
+		chatMsg.Reactions = nil
+		chatMsg.Status = chatv1.MessageStatus_MESSAGE_STATUS_UNSPECIFIED
+	}
+}
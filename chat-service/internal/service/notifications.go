@@ -0,0 +1,158 @@
+package service
+
+import (
+	"context"
+
+	chatv1 "chat-service/api/chat/v1"
+	"chat-service/internal/apierror"
+	"chat-service/internal/repository"
+
+	"github.com/jackc/pgx/v5/pgtype"
+	"go.uber.org/zap"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// Notification levels as stored in conversation_participants.notification_level (migration 000020)
+const (
+	notificationLevelAll      = "ALL"
+	notificationLevelMentions = "MENTIONS"
+	notificationLevelNone     = "NONE"
+)
+
+// messageDelivery tells a push-notification consumer whether a receiver of a message should be alerted.
+// Mentioned receivers are flagged so their alert can be sent with high priority.
+type messageDelivery struct {
+	UserID    string `json:"user_id"`
+	Notify    bool   `json:"notify"`
+	Mentioned bool   `json:"mentioned,omitempty"`
+}
+
+// SetNotificationLevel sets the requester's notification preference for a conversation. It only
+// changes the notify flag of later message events; messages and unread counts are unaffected.
+func (s *ChatService) SetNotificationLevel(ctx context.Context, req *chatv1.SetNotificationLevelRequest) (*chatv1.SetNotificationLevelResponse, error) {
+	if req == nil {
+		return nil, status.Error(codes.InvalidArgument, "request cannot be nil")
+	}
+
+	conversationUUID, userUUID, err := s.pinTarget(ctx, req.ConversationId)
+	if err != nil {
+		return nil, err
+	}
+
+	level, ok := notificationLevelString(req.NotificationLevel)
+	if !ok {
+		return nil, apierror.Validation("notification_level", "notification_level must be ALL, MENTIONS or NONE")
+	}
+
+	rows, err := s.setNotificationLevel(ctx, repository.SetNotificationLevelParams{
+		NotificationLevel: level,
+		ConversationID:    conversationUUID,
+		UserID:            userUUID,
+	})
+	if err != nil {
+		s.logger.Error("failed to set notification level",
+			zap.Error(err),
+			zap.String("conversation_id", req.ConversationId),
+			zap.String("user_id", uuidToString(userUUID)),
+		)
+		return nil, status.Error(codes.Internal, "failed to set notification level")
+	}
+	if rows == 0 {
+		return nil, s.notParticipantError(req.ConversationId, userUUID)
+	}
+
+	s.logger.Info("notification level set",
+		zap.String("conversation_id", req.ConversationId),
+		zap.String("user_id", uuidToString(userUUID)),
+		zap.String("notification_level", level),
+	)
+	return &chatv1.SetNotificationLevelResponse{
+		Success:           true,
+		NotificationLevel: req.NotificationLevel,
+	}, nil
+}
+
+// notificationLevelString maps a requested level to its stored value; UNSPECIFIED is not a level
+func notificationLevelString(level chatv1.NotificationLevel) (string, bool) {
+	switch level {
+	case chatv1.NotificationLevel_NOTIFICATION_LEVEL_ALL:
+		return notificationLevelAll, true
+	case chatv1.NotificationLevel_NOTIFICATION_LEVEL_MENTIONS:
+		return notificationLevelMentions, true
+	case chatv1.NotificationLevel_NOTIFICATION_LEVEL_NONE:
+		return notificationLevelNone, true
+	}
+	return "", false
+}
+
+func notificationLevelProto(level string) chatv1.NotificationLevel {
+	switch level {
+	case notificationLevelMentions:
+		return chatv1.NotificationLevel_NOTIFICATION_LEVEL_MENTIONS
+	case notificationLevelNone:
+		return chatv1.NotificationLevel_NOTIFICATION_LEVEL_NONE
+	}
+	return chatv1.NotificationLevel_NOTIFICATION_LEVEL_ALL
+}
+
+// messageDeliveries computes the notify flag of each receiver from its notification level and
+// the receivers the message mentions. Receivers without a level get every message; system
+// messages notify nobody.
+func messageDeliveries(receiverIDs []string, levels map[string]string, msgType chatv1.MessageType, mentioned []pgtype.UUID) []messageDelivery {
+	mentionedIDs := make(map[string]struct{}, len(mentioned))
+	for _, id := range mentioned {
+		mentionedIDs[uuidToString(id)] = struct{}{}
+	}
+
+	deliveries := make([]messageDelivery, len(receiverIDs))
+	for i, id := range receiverIDs {
+		_, isMentioned := mentionedIDs[id]
+		notify := msgType != chatv1.MessageType_MESSAGE_TYPE_SYSTEM
+		switch levels[id] {
+		case notificationLevelNone:
+			notify = false
+		case notificationLevelMentions:
+			notify = notify && isMentioned
+		}
+		deliveries[i] = messageDelivery{UserID: id, Notify: notify, Mentioned: isMentioned}
+	}
+	return deliveries
+}
+
+// notificationLevels loads the non-default notification levels of a conversation's participants,
+// keyed by user id. A failed lookup is logged and treated as everyone getting every message:
+// a muted conversation may then alert, but the message itself is never held back.
+func (s *ChatService) notificationLevels(ctx context.Context, conversationID pgtype.UUID) map[string]string {
+	if s.getNotificationLevelsFn == nil {
+		return nil
+	}
+
+	ctx, cancel := s.queryContext(ctx)
+	defer cancel()
+
+	rows, err := s.getNotificationLevelsFn(ctx, conversationID)
+	if err != nil {
+		s.logger.Warn("failed to load notification levels",
+			zap.Error(err),
+			zap.String("conversation_id", uuidToString(conversationID)),
+		)
+		return nil
+	}
+
+	levels := make(map[string]string, len(rows))
+	for _, row := range rows {
+		levels[uuidToString(row.UserID)] = row.NotificationLevel
+	}
+	return levels
+}
+
+func (s *ChatService) setNotificationLevel(ctx context.Context, params repository.SetNotificationLevelParams) (int64, error) {
+	ctx, cancel := s.queryContext(ctx)
+	defer cancel()
+
+	if s.setNotificationLevelFn != nil {
+		return s.setNotificationLevelFn(ctx, params)
+	}
+	return s.queries.SetNotificationLevel(ctx, params)
+}
@@ -0,0 +1,168 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+
+	chatv1 "chat-service/api/chat/v1"
+	"chat-service/internal/repository"
+
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+const (
+	testNotifyAllID      = "880e8400-e29b-41d4-a716-446655440001"
+	testNotifyMentionsID = "880e8400-e29b-41d4-a716-446655440002"
+	testNotifyMutedID    = "880e8400-e29b-41d4-a716-446655440003"
+)
+
+func TestSetNotificationLevel_Success(t *testing.T) {
+	var captured repository.SetNotificationLevelParams
+
+	service := &ChatService{logger: zap.NewNop()}
+	service.setNotificationLevelFn = func(ctx context.Context, arg repository.SetNotificationLevelParams) (int64, error) {
+		captured = arg
+		return 1, nil
+	}
+
+	resp, err := service.SetNotificationLevel(contextWithUserID(testReaderID), &chatv1.SetNotificationLevelRequest{
+		ConversationId:    testPinConversationID,
+		NotificationLevel: chatv1.NotificationLevel_NOTIFICATION_LEVEL_MENTIONS,
+	})
+	require.NoError(t, err)
+	assert.True(t, resp.Success)
+	assert.Equal(t, chatv1.NotificationLevel_NOTIFICATION_LEVEL_MENTIONS, resp.NotificationLevel)
+	assert.Equal(t, "MENTIONS", captured.NotificationLevel)
+	assert.Equal(t, mustParseUUID(t, testPinConversationID), captured.ConversationID)
+	assert.Equal(t, mustParseUUID(t, testReaderID), captured.UserID, "Only the requester's preference should change")
+}
+
+func TestSetNotificationLevel_Errors(t *testing.T) {
+	tests := []struct {
+		name           string
+		conversationID string
+		level          chatv1.NotificationLevel
+		rows           int64
+		err            error
+		wantCode       codes.Code
+	}{
+		{name: "missing conversation", level: chatv1.NotificationLevel_NOTIFICATION_LEVEL_NONE, wantCode: codes.InvalidArgument},
+		{name: "missing level", conversationID: testPinConversationID, wantCode: codes.InvalidArgument},
+		{name: "unknown level", conversationID: testPinConversationID, level: chatv1.NotificationLevel(42), wantCode: codes.InvalidArgument},
+		{name: "not a participant", conversationID: testPinConversationID, level: chatv1.NotificationLevel_NOTIFICATION_LEVEL_NONE, wantCode: codes.PermissionDenied},
+		{name: "database error", conversationID: testPinConversationID, level: chatv1.NotificationLevel_NOTIFICATION_LEVEL_NONE, err: assert.AnError, wantCode: codes.Internal},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			service := &ChatService{logger: zap.NewNop()}
+			service.setNotificationLevelFn = func(ctx context.Context, arg repository.SetNotificationLevelParams) (int64, error) {
+				return tt.rows, tt.err
+			}
+
+			_, err := service.SetNotificationLevel(contextWithUserID(testReaderID), &chatv1.SetNotificationLevelRequest{
+				ConversationId:    tt.conversationID,
+				NotificationLevel: tt.level,
+			})
+			assert.Equal(t, tt.wantCode, status.Code(err))
+		})
+	}
+}
+
+func TestMessageDeliveries(t *testing.T) {
+	receivers := []string{testNotifyAllID, testNotifyMentionsID, testNotifyMutedID}
+	levels := map[string]string{
+		testNotifyMentionsID: notificationLevelMentions,
+		testNotifyMutedID:    notificationLevelNone,
+	}
+	notified := func(deliveries []messageDelivery) []bool {
+		flags := make([]bool, len(deliveries))
+		for i, d := range deliveries {
+			assert.Equal(t, receivers[i], d.UserID, "receiver order is kept")
+			flags[i] = d.Notify
+		}
+		return flags
+	}
+
+	text := chatv1.MessageType_MESSAGE_TYPE_TEXT
+	assert.Equal(t, []bool{true, false, false}, notified(messageDeliveries(receivers, levels, text, nil)))
+
+	mentioned := []pgtype.UUID{mustParseUUID(t, testNotifyMentionsID), mustParseUUID(t, testNotifyMutedID)}
+	deliveries := messageDeliveries(receivers, levels, text, mentioned)
+	assert.Equal(t, []bool{true, true, false}, notified(deliveries), "mentions never unmute")
+	assert.False(t, deliveries[0].Mentioned)
+	assert.True(t, deliveries[1].Mentioned)
+	assert.True(t, deliveries[2].Mentioned)
+
+	system := chatv1.MessageType_MESSAGE_TYPE_SYSTEM
+	assert.Equal(t, []bool{false, false, false}, notified(messageDeliveries(receivers, levels, system, mentioned)))
+
+	assert.Equal(t, []bool{true, true, true}, notified(messageDeliveries(receivers, nil, text, nil)), "default is ALL")
+}
+
+func TestSendMessage_DeliveriesFollowNotificationLevels(t *testing.T) {
+	conversationID := mustParseUUID(t, testPinConversationID)
+	senderID := mustParseUUID(t, "660e8400-e29b-41d4-a716-446655440000")
+	messageID := mustParseUUID(t, "770e8400-e29b-41d4-a716-446655440000")
+	mutedID := mustParseUUID(t, testNotifyMutedID)
+
+	mockIdempotency := new(MockIdempotencyChecker)
+	mocks := newMockTransactionHelpers()
+	mocks.setupHappyPathTransaction(conversationID, senderID, messageID, "hello")
+	receiverID := mustParseUUID(t, "880e8400-e29b-41d4-a716-446655440000")
+	mocks.mockGetConversationParticipants = func(ctx context.Context, qtx *repository.Queries, convID pgtype.UUID) ([]pgtype.UUID, error) {
+		return []pgtype.UUID{senderID, receiverID, mutedID}, nil
+	}
+	var payload []byte
+	mocks.mockInsertOutbox = func(ctx context.Context, qtx *repository.Queries, params repository.InsertOutboxParams) error {
+		payload = params.Payload
+		return nil
+	}
+
+	service := &ChatService{idempotencyCheck: mockIdempotency, logger: zap.NewNop()}
+	mocks.injectIntoService(service)
+	service.getNotificationLevelsFn = func(ctx context.Context, convID pgtype.UUID) ([]repository.GetConversationNotificationLevelsRow, error) {
+		assert.Equal(t, conversationID, convID)
+		return []repository.GetConversationNotificationLevelsRow{{UserID: mutedID, NotificationLevel: notificationLevelNone}}, nil
+	}
+
+	ctx := contextWithUserID(uuidToString(senderID))
+	mockIdempotency.On("Check", ctx, "notify-key").Return(nil)
+
+	_, err := service.SendMessage(ctx, &chatv1.SendMessageRequest{
+		ConversationId: testPinConversationID,
+		Content:        "hello",
+		IdempotencyKey: "notify-key",
+	})
+	require.NoError(t, err)
+
+	var event struct {
+		ReceiverIDs []string          `json:"receiver_ids"`
+		Deliveries  []messageDelivery `json:"deliveries"`
+	}
+	require.NoError(t, json.Unmarshal(payload, &event))
+	assert.Equal(t, []string{"880e8400-e29b-41d4-a716-446655440000", testNotifyMutedID}, event.ReceiverIDs)
+	assert.Equal(t, []messageDelivery{
+		{UserID: "880e8400-e29b-41d4-a716-446655440000", Notify: true},
+		{UserID: testNotifyMutedID, Notify: false},
+	}, event.Deliveries)
+}
+
+func TestNotificationLevels_LookupFailureNotifiesEveryone(t *testing.T) {
+	service := &ChatService{logger: zap.NewNop()}
+	service.getNotificationLevelsFn = func(ctx context.Context, convID pgtype.UUID) ([]repository.GetConversationNotificationLevelsRow, error) {
+		return nil, assert.AnError
+	}
+
+	levels := service.notificationLevels(context.Background(), mustParseUUID(t, testPinConversationID))
+	assert.Nil(t, levels)
+
+	deliveries := messageDeliveries([]string{testNotifyMutedID}, levels, chatv1.MessageType_MESSAGE_TYPE_TEXT, nil)
+	assert.True(t, deliveries[0].Notify)
+}
@@ -0,0 +1,141 @@
+package service
+
+import (
+	"context"
+	"errors"
+
+	chatv1 "chat-service/api/chat/v1"
+	"chat-service/internal/apierror"
+	"chat-service/internal/repository"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+	"go.uber.org/zap"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// PinConversation pins a conversation to the top of the requester's list. Pinning is per user and
+// idempotent: pinning a pinned conversation keeps its pinned_at, and so its place among the pins.
+func (s *ChatService) PinConversation(ctx context.Context, req *chatv1.PinConversationRequest) (*chatv1.PinConversationResponse, error) {
+	if req == nil {
+		return nil, status.Error(codes.InvalidArgument, "request cannot be nil")
+	}
+
+	conversationUUID, userUUID, err := s.pinTarget(ctx, req.ConversationId)
+	if err != nil {
+		return nil, err
+	}
+
+	pinnedAt, err := s.pinConversation(ctx, repository.PinConversationParams{
+		ConversationID: conversationUUID,
+		UserID:         userUUID,
+	})
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, s.notParticipantError(req.ConversationId, userUUID)
+		}
+		s.logger.Error("failed to pin conversation",
+			zap.Error(err),
+			zap.String("conversation_id", req.ConversationId),
+			zap.String("user_id", uuidToString(userUUID)),
+		)
+		return nil, status.Error(codes.Internal, "failed to pin conversation")
+	}
+
+	s.logger.Info("conversation pinned",
+		zap.String("conversation_id", req.ConversationId),
+		zap.String("user_id", uuidToString(userUUID)),
+	)
+	return &chatv1.PinConversationResponse{
+		Success:  true,
+		PinnedAt: formatTimestamp(pinnedAt),
+	}, nil
+}
+
+// UnpinConversation returns a pinned conversation to its place by last_message_at. Idempotent.
+func (s *ChatService) UnpinConversation(ctx context.Context, req *chatv1.UnpinConversationRequest) (*chatv1.UnpinConversationResponse, error) {
+	if req == nil {
+		return nil, status.Error(codes.InvalidArgument, "request cannot be nil")
+	}
+
+	conversationUUID, userUUID, err := s.pinTarget(ctx, req.ConversationId)
+	if err != nil {
+		return nil, err
+	}
+
+	rows, err := s.unpinConversation(ctx, repository.UnpinConversationParams{
+		ConversationID: conversationUUID,
+		UserID:         userUUID,
+	})
+	if err != nil {
+		s.logger.Error("failed to unpin conversation",
+			zap.Error(err),
+			zap.String("conversation_id", req.ConversationId),
+			zap.String("user_id", uuidToString(userUUID)),
+		)
+		return nil, status.Error(codes.Internal, "failed to unpin conversation")
+	}
+	if rows == 0 {
+		return nil, s.notParticipantError(req.ConversationId, userUUID)
+	}
+
+	s.logger.Info("conversation unpinned",
+		zap.String("conversation_id", req.ConversationId),
+		zap.String("user_id", uuidToString(userUUID)),
+	)
+	return &chatv1.UnpinConversationResponse{Success: true}, nil
+}
+
+// pinTarget validates the conversation id and resolves the requester
+func (s *ChatService) pinTarget(ctx context.Context, conversationID string) (pgtype.UUID, pgtype.UUID, error) {
+	if conversationID == "" {
+		return pgtype.UUID{}, pgtype.UUID{}, apierror.Validation("conversation_id", "conversation_id is required")
+	}
+
+	conversationUUID, err := parseUUID(conversationID)
+	if err != nil {
+		return pgtype.UUID{}, pgtype.UUID{}, apierror.Validation("conversation_id", "invalid conversation_id")
+	}
+
+	// Extract user_id from context (set by auth middleware)
+	userID, err := getUserIDFromContext(ctx)
+	if err != nil {
+		s.logger.Error("failed to get user_id from context", zap.Error(err))
+		return pgtype.UUID{}, pgtype.UUID{}, err
+	}
+
+	userUUID, err := parseUUID(userID)
+	if err != nil {
+		return pgtype.UUID{}, pgtype.UUID{}, apierror.Validation("user_id", "invalid user_id")
+	}
+	return conversationUUID, userUUID, nil
+}
+
+func (s *ChatService) notParticipantError(conversationID string, userUUID pgtype.UUID) error {
+	s.logger.Warn("user is not a participant of conversation",
+		zap.String("conversation_id", conversationID),
+		zap.String("user_id", uuidToString(userUUID)),
+	)
+	return apierror.New(codes.PermissionDenied, apierror.CodePermissionDenied, "not a participant of this conversation", nil)
+}
+
+func (s *ChatService) pinConversation(ctx context.Context, params repository.PinConversationParams) (pgtype.Timestamptz, error) {
+	ctx, cancel := s.queryContext(ctx)
+	defer cancel()
+
+	if s.pinConversationFn != nil {
+		return s.pinConversationFn(ctx, params)
+	}
+	return s.queries.PinConversation(ctx, params)
+}
+
+func (s *ChatService) unpinConversation(ctx context.Context, params repository.UnpinConversationParams) (int64, error) {
+	ctx, cancel := s.queryContext(ctx)
+	defer cancel()
+
+	if s.unpinConversationFn != nil {
+		return s.unpinConversationFn(ctx, params)
+	}
+	return s.queries.UnpinConversation(ctx, params)
+}
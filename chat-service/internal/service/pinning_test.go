@@ -0,0 +1,140 @@
+package service
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	chatv1 "chat-service/api/chat/v1"
+	"chat-service/internal/repository"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+const testPinConversationID = "550e8400-e29b-41d4-a716-446655440000"
+
+func TestPinConversation_Success(t *testing.T) {
+	pinnedAt := mustTimestamptz(t, time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC))
+	var captured repository.PinConversationParams
+
+	service := &ChatService{logger: zap.NewNop()}
+	service.pinConversationFn = func(ctx context.Context, arg repository.PinConversationParams) (pgtype.Timestamptz, error) {
+		captured = arg
+		return pinnedAt, nil
+	}
+
+	resp, err := service.PinConversation(contextWithUserID(testReaderID), &chatv1.PinConversationRequest{
+		ConversationId: testPinConversationID,
+	})
+	require.NoError(t, err)
+	assert.True(t, resp.Success)
+	assert.Equal(t, "2025-01-01T12:00:00Z", resp.PinnedAt)
+	assert.Equal(t, mustParseUUID(t, testPinConversationID), captured.ConversationID)
+	assert.Equal(t, mustParseUUID(t, testReaderID), captured.UserID, "Only the requester's participant row should be pinned")
+}
+
+func TestUnpinConversation_Success(t *testing.T) {
+	var captured repository.UnpinConversationParams
+
+	service := &ChatService{logger: zap.NewNop()}
+	service.unpinConversationFn = func(ctx context.Context, arg repository.UnpinConversationParams) (int64, error) {
+		captured = arg
+		return 1, nil
+	}
+
+	resp, err := service.UnpinConversation(contextWithUserID(testReaderID), &chatv1.UnpinConversationRequest{
+		ConversationId: testPinConversationID,
+	})
+	require.NoError(t, err)
+	assert.True(t, resp.Success)
+	assert.Equal(t, mustParseUUID(t, testReaderID), captured.UserID)
+}
+
+func TestPinConversation_Errors(t *testing.T) {
+	tests := []struct {
+		name           string
+		conversationID string
+		pinErr         error
+		unpinRows      int64
+		unpinErr       error
+		wantCode       codes.Code
+	}{
+		{name: "missing conversation", wantCode: codes.InvalidArgument},
+		{name: "invalid conversation", conversationID: "not-a-uuid", wantCode: codes.InvalidArgument},
+		{name: "not a participant", conversationID: testPinConversationID, pinErr: pgx.ErrNoRows, unpinRows: 0, wantCode: codes.PermissionDenied},
+		{name: "database error", conversationID: testPinConversationID, pinErr: assert.AnError, unpinErr: assert.AnError, wantCode: codes.Internal},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			service := &ChatService{logger: zap.NewNop()}
+			service.pinConversationFn = func(ctx context.Context, arg repository.PinConversationParams) (pgtype.Timestamptz, error) {
+				return pgtype.Timestamptz{}, tt.pinErr
+			}
+			service.unpinConversationFn = func(ctx context.Context, arg repository.UnpinConversationParams) (int64, error) {
+				return tt.unpinRows, tt.unpinErr
+			}
+			ctx := contextWithUserID(testReaderID)
+
+			_, err := service.PinConversation(ctx, &chatv1.PinConversationRequest{ConversationId: tt.conversationID})
+			assert.Equal(t, tt.wantCode, status.Code(err), "pin")
+
+			_, err = service.UnpinConversation(ctx, &chatv1.UnpinConversationRequest{ConversationId: tt.conversationID})
+			assert.Equal(t, tt.wantCode, status.Code(err), "unpin")
+		})
+	}
+}
+
+func TestGetConversations_PinnedFirstCursor(t *testing.T) {
+	pinnedAt := mustTimestamptz(t, time.Date(2025, 1, 3, 9, 0, 0, 0, time.UTC))
+	lastMessageAt := mustTimestamptz(t, time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC))
+	pinned := repository.GetConversationsForUserRow{
+		ID:            mustParseUUID(t, "550e8400-e29b-41d4-a716-446655440001"),
+		LastMessageAt: lastMessageAt,
+		PinnedAt:      pinnedAt,
+	}
+	unpinned := repository.GetConversationsForUserRow{
+		ID:            mustParseUUID(t, "550e8400-e29b-41d4-a716-446655440002"),
+		LastMessageAt: lastMessageAt,
+	}
+
+	var calls []repository.GetConversationsForUserParams
+	pages := [][]repository.GetConversationsForUserRow{{pinned}, {unpinned}, nil}
+	service := &ChatService{logger: zap.NewNop()}
+	service.getConversationsForUserFn = func(ctx context.Context, arg repository.GetConversationsForUserParams) ([]repository.GetConversationsForUserRow, error) {
+		page := pages[len(calls)]
+		calls = append(calls, arg)
+		return page, nil
+	}
+	ctx := contextWithUserID(testReaderID)
+
+	// Page ending on a pinned conversation: the cursor continues the pinned section by pinned_at
+	resp, err := service.GetConversations(ctx, &chatv1.GetConversationsRequest{Limit: 1})
+	require.NoError(t, err)
+	require.Len(t, resp.Conversations, 1)
+	assert.True(t, resp.Conversations[0].Pinned)
+	assert.Equal(t, "2025-01-03T09:00:00Z", resp.Conversations[0].PinnedAt)
+
+	resp, err = service.GetConversations(ctx, &chatv1.GetConversationsRequest{Limit: 1, Cursor: resp.NextCursor})
+	require.NoError(t, err)
+	assert.True(t, calls[1].BeforePinned)
+	assert.True(t, pinnedAt.Time.Equal(calls[1].BeforeSortAt.Time), "pinned positions are keyed by pinned_at")
+	assert.Equal(t, pinned.ID, calls[1].BeforeID)
+
+	// Page ending on an unpinned conversation: the cursor stays out of the pinned section
+	require.Len(t, resp.Conversations, 1)
+	assert.False(t, resp.Conversations[0].Pinned)
+	assert.Empty(t, resp.Conversations[0].PinnedAt)
+
+	_, err = service.GetConversations(ctx, &chatv1.GetConversationsRequest{Limit: 1, Cursor: resp.NextCursor})
+	require.NoError(t, err)
+	assert.False(t, calls[2].BeforePinned)
+	assert.True(t, lastMessageAt.Time.Equal(calls[2].BeforeSortAt.Time))
+	assert.Equal(t, unpinned.ID, calls[2].BeforeID)
+}
@@ -0,0 +1,315 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"unicode"
+	"unicode/utf8"
+
+	chatv1 "chat-service/api/chat/v1"
+	"chat-service/internal/apierror"
+	"chat-service/internal/repository"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+	"go.uber.org/zap"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// MaxReactionEmojiBytes bounds a reaction. Long enough for ZWJ sequences such as family or flag emoji.
+const MaxReactionEmojiBytes = 64
+
+// Reaction delta events. They carry the new count of one emoji instead of the message's
+// whole reaction list, so a change on a popular message stays a small event.
+const (
+	EventTypeReactionAdded   = "reaction_added"
+	EventTypeReactionRemoved = "reaction_removed"
+)
+
+// AddReaction adds the caller's emoji reaction to a message. Adding a reaction the caller
+// already has is a no-op that returns the current count without emitting an event.
+func (s *ChatService) AddReaction(ctx context.Context, req *chatv1.AddReactionRequest) (*chatv1.AddReactionResponse, error) {
+	if req == nil {
+		return nil, status.Error(codes.InvalidArgument, "request cannot be nil")
+	}
+
+	count, err := s.changeReaction(ctx, req.MessageId, req.Emoji, true)
+	if err != nil {
+		return nil, err
+	}
+	return &chatv1.AddReactionResponse{
+		MessageId: req.MessageId,
+		Emoji:     req.Emoji,
+		Count:     count,
+	}, nil
+}
+
+// RemoveReaction removes the caller's emoji reaction from a message. Removing a reaction
+// the caller does not have is a no-op that returns the current count.
+func (s *ChatService) RemoveReaction(ctx context.Context, req *chatv1.RemoveReactionRequest) (*chatv1.RemoveReactionResponse, error) {
+	if req == nil {
+		return nil, status.Error(codes.InvalidArgument, "request cannot be nil")
+	}
+
+	count, err := s.changeReaction(ctx, req.MessageId, req.Emoji, false)
+	if err != nil {
+		return nil, err
+	}
+	return &chatv1.RemoveReactionResponse{
+		MessageId: req.MessageId,
+		Emoji:     req.Emoji,
+		Count:     count,
+	}, nil
+}
+
+// changeReaction validates and applies an add (add=true) or remove, returning the emoji's new count
+func (s *ChatService) changeReaction(ctx context.Context, messageID, emoji string, add bool) (int32, error) {
+	if messageID == "" {
+		return 0, apierror.Validation("message_id", "message_id is required")
+	}
+	if err := validateReactionEmoji(emoji); err != nil {
+		return 0, err
+	}
+
+	messageUUID, err := parseUUID(messageID)
+	if err != nil {
+		return 0, apierror.Validation("message_id", "invalid message_id")
+	}
+
+	// Extract user_id from context (set by auth middleware)
+	userID, err := getUserIDFromContext(ctx)
+	if err != nil {
+		s.logger.Error("failed to get user_id from context", zap.Error(err))
+		return 0, err
+	}
+
+	userUUID, err := parseUUID(userID)
+	if err != nil {
+		return 0, apierror.Validation("user_id", "invalid user_id")
+	}
+
+	msg, err := s.getMessageForParticipant(ctx, messageUUID, userUUID)
+	if err != nil {
+		return 0, err
+	}
+	if msg.DeletedAt.Valid {
+		return 0, apierror.New(codes.NotFound, apierror.CodeNotFound, "message not found", nil)
+	}
+
+	count, err := s.changeReactionTx(ctx, msg, userUUID, emoji, add)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			// Purged between the access check and the transaction
+			return 0, apierror.New(codes.NotFound, apierror.CodeNotFound, "message not found", nil)
+		}
+		s.logger.Error("failed to update reaction",
+			zap.Error(err),
+			zap.String("message_id", messageID),
+			zap.String("user_id", userID),
+			zap.Bool("add", add),
+		)
+		return 0, status.Error(codes.Internal, "failed to update reaction")
+	}
+	return count, nil
+}
+
+// validateReactionEmoji accepts one short token of printable characters; which emoji
+// are offered is up to the clients
+func validateReactionEmoji(emoji string) error {
+	if emoji == "" {
+		return apierror.Validation("emoji", "emoji is required")
+	}
+	if len(emoji) > MaxReactionEmojiBytes {
+		return apierror.Validation("emoji", fmt.Sprintf("emoji exceeds %d bytes", MaxReactionEmojiBytes))
+	}
+	if !utf8.ValidString(emoji) {
+		return apierror.Validation("emoji", "emoji must be valid UTF-8")
+	}
+	for _, r := range emoji {
+		if unicode.IsSpace(r) || unicode.IsControl(r) {
+			return apierror.Validation("emoji", "emoji cannot contain whitespace or control characters")
+		}
+	}
+	return nil
+}
+
+// changeReactionTx adds or removes the reaction and, when that changed anything, writes a
+// reaction delta outbox event in the same transaction. Changes on one message are serialized
+// by a row lock, so every event's count includes all changes committed before it.
+func (s *ChatService) changeReactionTx(ctx context.Context, msg repository.Message, userUUID pgtype.UUID, emoji string, add bool) (int32, error) {
+	tx, err := s.beginTx(ctx)
+	if err != nil {
+		return 0, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer func() { _ = s.rollbackTx(ctx, tx) }() // Rollback if not committed
+
+	var qtx *repository.Queries
+	if pgxTx, ok := tx.(pgx.Tx); ok {
+		qtx = s.queries.WithTx(pgxTx)
+	} else {
+		qtx = repository.New(tx)
+	}
+
+	// 1. Lock the message against concurrent reaction changes
+	if err := s.lockMessageForReactions(ctx, qtx, msg.ID); err != nil {
+		return 0, fmt.Errorf("failed to lock message: %w", err)
+	}
+
+	// 2. Add or remove the reaction
+	var changed int64
+	if add {
+		changed, err = s.addReaction(ctx, qtx, repository.AddReactionParams{MessageID: msg.ID, UserID: userUUID, Emoji: emoji})
+	} else {
+		changed, err = s.removeReaction(ctx, qtx, repository.RemoveReactionParams{MessageID: msg.ID, UserID: userUUID, Emoji: emoji})
+	}
+	if err != nil {
+		return 0, fmt.Errorf("failed to update reaction: %w", err)
+	}
+
+	// 3. Count after the change
+	count, err := s.countReactions(ctx, qtx, repository.CountReactionsParams{MessageID: msg.ID, Emoji: emoji})
+	if err != nil {
+		return 0, fmt.Errorf("failed to count reactions: %w", err)
+	}
+
+	// 4. Emit the delta only if something changed (repeated taps stay silent)
+	if changed > 0 {
+		participants, err := s.getConversationParticipants(ctx, qtx, msg.ConversationID)
+		if err != nil {
+			return 0, fmt.Errorf("failed to get conversation participants: %w", err)
+		}
+
+		receiverIDs := otherParticipantIDs(participants, userUUID)
+
+		eventType := EventTypeReactionRemoved
+		if add {
+			eventType = EventTypeReactionAdded
+		}
+		payload, err := createReactionEventPayload(eventType, msg, userUUID, emoji, count, receiverIDs, eventOriginFromContext(ctx))
+		if err != nil {
+			return 0, fmt.Errorf("failed to create event payload: %w", err)
+		}
+
+		err = s.insertOutbox(ctx, qtx, repository.InsertOutboxParams{
+			AggregateType: "message",
+			AggregateID:   msg.ID,
+			Payload:       payload,
+		})
+		if err != nil {
+			return 0, fmt.Errorf("failed to insert outbox: %w", err)
+		}
+	}
+
+	if err = s.commitTx(ctx, tx); err != nil {
+		return 0, fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	return count, nil
+}
+
+// createReactionEventPayload creates the JSON payload for a reaction_added/reaction_removed outbox event.
+// new_count is the emoji's count after the change: clients set their counter to it rather than
+// adding or subtracting one, so a missed or repeated event cannot leave the counter drifting.
+func createReactionEventPayload(eventType string, msg repository.Message, userID pgtype.UUID, emoji string, newCount int32, receiverIDs []string, origin eventOrigin) ([]byte, error) {
+	event := map[string]interface{}{
+		"event_type":      eventType,
+		"message_id":      uuidToString(msg.ID),
+		"conversation_id": uuidToString(msg.ConversationID),
+		"user_id":         uuidToString(userID),
+		"emoji":           emoji,
+		"new_count":       newCount,
+		"receiver_ids":    receiverIDs,
+	}
+	origin.addTo(event)
+
+	payload, err := json.Marshal(event)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal event: %w", err)
+	}
+
+	return payload, nil
+}
+
+// loadReactions fetches the reaction snapshot for a page of messages in a single query
+func (s *ChatService) loadReactions(ctx context.Context, messages []repository.Message, userUUID pgtype.UUID) (map[pgtype.UUID][]*chatv1.ReactionSummary, error) {
+	if len(messages) == 0 {
+		return nil, nil
+	}
+
+	messageIDs := make([]pgtype.UUID, 0, len(messages))
+	for _, msg := range messages {
+		messageIDs = append(messageIDs, msg.ID)
+	}
+
+	rows, err := s.getReactionsForMessages(ctx, repository.GetReactionsForMessagesParams{
+		UserID:     userUUID,
+		MessageIds: messageIDs,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	result := make(map[pgtype.UUID][]*chatv1.ReactionSummary, len(rows))
+	for _, row := range rows {
+		result[row.MessageID] = append(result[row.MessageID], &chatv1.ReactionSummary{
+			Emoji:       row.Emoji,
+			Count:       row.Count,
+			ReactedByMe: row.ReactedByMe,
+		})
+	}
+	return result, nil
+}
+
+func (s *ChatService) lockMessageForReactions(ctx context.Context, qtx *repository.Queries, messageID pgtype.UUID) error {
+	ctx, cancel := s.queryContext(ctx)
+	defer cancel()
+
+	if s.lockMessageForReactionsFn != nil {
+		return s.lockMessageForReactionsFn(ctx, qtx, messageID)
+	}
+	_, err := qtx.LockMessageForReactions(ctx, messageID)
+	return err
+}
+
+func (s *ChatService) addReaction(ctx context.Context, qtx *repository.Queries, params repository.AddReactionParams) (int64, error) {
+	ctx, cancel := s.queryContext(ctx)
+	defer cancel()
+
+	if s.addReactionFn != nil {
+		return s.addReactionFn(ctx, qtx, params)
+	}
+	return qtx.AddReaction(ctx, params)
+}
+
+func (s *ChatService) removeReaction(ctx context.Context, qtx *repository.Queries, params repository.RemoveReactionParams) (int64, error) {
+	ctx, cancel := s.queryContext(ctx)
+	defer cancel()
+
+	if s.removeReactionFn != nil {
+		return s.removeReactionFn(ctx, qtx, params)
+	}
+	return qtx.RemoveReaction(ctx, params)
+}
+
+func (s *ChatService) countReactions(ctx context.Context, qtx *repository.Queries, params repository.CountReactionsParams) (int32, error) {
+	ctx, cancel := s.queryContext(ctx)
+	defer cancel()
+
+	if s.countReactionsFn != nil {
+		return s.countReactionsFn(ctx, qtx, params)
+	}
+	return qtx.CountReactions(ctx, params)
+}
+
+func (s *ChatService) getReactionsForMessages(ctx context.Context, params repository.GetReactionsForMessagesParams) ([]repository.GetReactionsForMessagesRow, error) {
+	ctx, cancel := s.queryContext(ctx)
+	defer cancel()
+
+	if s.getReactionsForMessagesFn != nil {
+		return s.getReactionsForMessagesFn(ctx, params)
+	}
+	return s.queries.GetReactionsForMessages(ctx, params)
+}
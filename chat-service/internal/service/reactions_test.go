@@ -0,0 +1,271 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"strings"
+	"testing"
+
+	chatv1 "chat-service/api/chat/v1"
+	"chat-service/internal/repository"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+const (
+	testReactionMessageID      = "770e8400-e29b-41d4-a716-446655440000"
+	testReactionConversationID = "550e8400-e29b-41d4-a716-446655440000"
+	testReactionUserID         = "660e8400-e29b-41d4-a716-446655440000"
+	testReactionOtherID        = "880e8400-e29b-41d4-a716-446655440000"
+)
+
+// reactionStore is an in-memory message_reactions table for one message
+type reactionStore struct {
+	users  map[string]map[pgtype.UUID]bool // emoji -> users
+	locked int
+	outbox []repository.InsertOutboxParams
+}
+
+func newReactionTestService(t *testing.T) (*ChatService, *reactionStore) {
+	t.Helper()
+
+	store := &reactionStore{users: make(map[string]map[pgtype.UUID]bool)}
+	messageID := mustParseUUID(t, testReactionMessageID)
+	conversationID := mustParseUUID(t, testReactionConversationID)
+
+	service := &ChatService{logger: zap.NewNop()}
+	service.getMessageByIDFn = func(ctx context.Context, id pgtype.UUID) (repository.Message, error) {
+		if id != messageID {
+			return repository.Message{}, pgx.ErrNoRows
+		}
+		return repository.Message{ID: id, ConversationID: conversationID}, nil
+	}
+	service.isParticipantFn = allowParticipant
+	service.beginTxFn = func(ctx context.Context) (repository.DBTX, error) {
+		return new(mockDBTX), nil
+	}
+	service.lockMessageForReactionsFn = func(ctx context.Context, qtx *repository.Queries, id pgtype.UUID) error {
+		store.locked++
+		return nil
+	}
+	service.addReactionFn = func(ctx context.Context, qtx *repository.Queries, arg repository.AddReactionParams) (int64, error) {
+		if store.users[arg.Emoji] == nil {
+			store.users[arg.Emoji] = make(map[pgtype.UUID]bool)
+		}
+		if store.users[arg.Emoji][arg.UserID] {
+			return 0, nil
+		}
+		store.users[arg.Emoji][arg.UserID] = true
+		return 1, nil
+	}
+	service.removeReactionFn = func(ctx context.Context, qtx *repository.Queries, arg repository.RemoveReactionParams) (int64, error) {
+		if !store.users[arg.Emoji][arg.UserID] {
+			return 0, nil
+		}
+		delete(store.users[arg.Emoji], arg.UserID)
+		return 1, nil
+	}
+	service.countReactionsFn = func(ctx context.Context, qtx *repository.Queries, arg repository.CountReactionsParams) (int32, error) {
+		return int32(len(store.users[arg.Emoji])), nil
+	}
+	service.getConversationParticipantsFn = func(ctx context.Context, qtx *repository.Queries, id pgtype.UUID) ([]pgtype.UUID, error) {
+		return []pgtype.UUID{mustParseUUID(t, testReactionUserID), mustParseUUID(t, testReactionOtherID)}, nil
+	}
+	service.insertOutboxFn = func(ctx context.Context, qtx *repository.Queries, params repository.InsertOutboxParams) error {
+		store.outbox = append(store.outbox, params)
+		return nil
+	}
+	service.commitTxFn = func(ctx context.Context, tx repository.DBTX) error { return nil }
+	service.rollbackTxFn = func(ctx context.Context, tx repository.DBTX) error { return nil }
+
+	return service, store
+}
+
+func decodeReactionEvent(t *testing.T, params repository.InsertOutboxParams) map[string]interface{} {
+	t.Helper()
+	var payload map[string]interface{}
+	require.NoError(t, json.Unmarshal(params.Payload, &payload))
+	return payload
+}
+
+func TestAddReaction_EmitsDeltaEvent(t *testing.T) {
+	service, store := newReactionTestService(t)
+	store.users["👍"] = map[pgtype.UUID]bool{mustParseUUID(t, testReactionOtherID): true}
+
+	resp, err := service.AddReaction(contextWithUserID(testReactionUserID), &chatv1.AddReactionRequest{
+		MessageId: testReactionMessageID,
+		Emoji:     "👍",
+	})
+	require.NoError(t, err)
+	assert.Equal(t, int32(2), resp.Count)
+	assert.Equal(t, 1, store.locked, "the message is locked before the reaction changes")
+
+	require.Len(t, store.outbox, 1)
+	assert.Equal(t, "message", store.outbox[0].AggregateType)
+	assert.Equal(t, mustParseUUID(t, testReactionMessageID), store.outbox[0].AggregateID)
+
+	payload := decodeReactionEvent(t, store.outbox[0])
+	assert.Equal(t, map[string]interface{}{
+		"event_type":      EventTypeReactionAdded,
+		"message_id":      testReactionMessageID,
+		"conversation_id": testReactionConversationID,
+		"user_id":         testReactionUserID,
+		"emoji":           "👍",
+		"new_count":       float64(2),
+		"receiver_ids":    []interface{}{testReactionOtherID}, // the reactor syncs via origin_device_id
+	}, payload, "the delta carries no reaction list")
+}
+
+func TestRemoveReaction_EmitsDeltaEvent(t *testing.T) {
+	service, store := newReactionTestService(t)
+	store.users["🎉"] = map[pgtype.UUID]bool{mustParseUUID(t, testReactionUserID): true}
+
+	resp, err := service.RemoveReaction(contextWithUserID(testReactionUserID), &chatv1.RemoveReactionRequest{
+		MessageId: testReactionMessageID,
+		Emoji:     "🎉",
+	})
+	require.NoError(t, err)
+	assert.Equal(t, int32(0), resp.Count)
+
+	require.Len(t, store.outbox, 1)
+	payload := decodeReactionEvent(t, store.outbox[0])
+	assert.Equal(t, EventTypeReactionRemoved, payload["event_type"])
+	assert.Equal(t, float64(0), payload["new_count"])
+}
+
+func TestReaction_NoChangeIsSilent(t *testing.T) {
+	service, store := newReactionTestService(t)
+	ctx := contextWithUserID(testReactionUserID)
+	req := &chatv1.AddReactionRequest{MessageId: testReactionMessageID, Emoji: "❤️"}
+
+	_, err := service.AddReaction(ctx, req)
+	require.NoError(t, err)
+	resp, err := service.AddReaction(ctx, req)
+	require.NoError(t, err)
+	assert.Equal(t, int32(1), resp.Count, "a repeated add reports the current count")
+
+	removed, err := service.RemoveReaction(ctx, &chatv1.RemoveReactionRequest{MessageId: testReactionMessageID, Emoji: "😮"})
+	require.NoError(t, err)
+	assert.Equal(t, int32(0), removed.Count)
+
+	assert.Len(t, store.outbox, 1, "only the first add changed anything")
+}
+
+func TestReaction_Errors(t *testing.T) {
+	tests := []struct {
+		name      string
+		messageID string
+		emoji     string
+		setup     func(service *ChatService)
+		wantCode  codes.Code
+	}{
+		{name: "missing message id", emoji: "👍", wantCode: codes.InvalidArgument},
+		{name: "invalid message id", messageID: "not-a-uuid", emoji: "👍", wantCode: codes.InvalidArgument},
+		{name: "missing emoji", messageID: testReactionMessageID, wantCode: codes.InvalidArgument},
+		{name: "emoji with whitespace", messageID: testReactionMessageID, emoji: "👍 👍", wantCode: codes.InvalidArgument},
+		{name: "emoji too long", messageID: testReactionMessageID, emoji: strings.Repeat("👍", MaxReactionEmojiBytes/4+1), wantCode: codes.InvalidArgument},
+		{name: "unknown message", messageID: "770e8400-e29b-41d4-a716-446655440099", emoji: "👍", wantCode: codes.NotFound},
+		{
+			name:      "not a participant",
+			messageID: testReactionMessageID,
+			emoji:     "👍",
+			setup: func(service *ChatService) {
+				service.isParticipantFn = func(ctx context.Context, arg repository.IsParticipantParams) (bool, error) {
+					return false, nil
+				}
+			},
+			wantCode: codes.PermissionDenied,
+		},
+		{
+			name:      "deleted message",
+			messageID: testReactionMessageID,
+			emoji:     "👍",
+			setup: func(service *ChatService) {
+				service.getMessageByIDFn = func(ctx context.Context, id pgtype.UUID) (repository.Message, error) {
+					return repository.Message{ID: id, DeletedAt: pgtype.Timestamptz{Valid: true}}, nil
+				}
+			},
+			wantCode: codes.NotFound,
+		},
+		{
+			name:      "message purged before the lock",
+			messageID: testReactionMessageID,
+			emoji:     "👍",
+			setup: func(service *ChatService) {
+				service.lockMessageForReactionsFn = func(ctx context.Context, qtx *repository.Queries, id pgtype.UUID) error {
+					return pgx.ErrNoRows
+				}
+			},
+			wantCode: codes.NotFound,
+		},
+		{
+			name:      "database error",
+			messageID: testReactionMessageID,
+			emoji:     "👍",
+			setup: func(service *ChatService) {
+				service.countReactionsFn = func(ctx context.Context, qtx *repository.Queries, arg repository.CountReactionsParams) (int32, error) {
+					return 0, assert.AnError
+				}
+			},
+			wantCode: codes.Internal,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			service, store := newReactionTestService(t)
+			if tt.setup != nil {
+				tt.setup(service)
+			}
+
+			_, err := service.AddReaction(contextWithUserID(testReactionUserID), &chatv1.AddReactionRequest{
+				MessageId: tt.messageID,
+				Emoji:     tt.emoji,
+			})
+			assert.Equal(t, tt.wantCode, status.Code(err))
+			assert.Empty(t, store.outbox)
+		})
+	}
+}
+
+func TestGetMessages_ReactionSnapshot(t *testing.T) {
+	messageID := mustParseUUID(t, testReactionMessageID)
+	var captured repository.GetReactionsForMessagesParams
+
+	service := &ChatService{
+		logger:                      zap.NewNop(),
+		isParticipantFn:             allowParticipant,
+		getAttachmentsForMessagesFn: noAttachments,
+		getReactionsForMessagesFn: func(ctx context.Context, arg repository.GetReactionsForMessagesParams) ([]repository.GetReactionsForMessagesRow, error) {
+			captured = arg
+			return []repository.GetReactionsForMessagesRow{
+				{MessageID: messageID, Emoji: "👍", Count: 3, ReactedByMe: true},
+				{MessageID: messageID, Emoji: "😂", Count: 1},
+			}, nil
+		},
+	}
+	service.getMessagesFn = func(ctx context.Context, arg repository.GetMessagesParams) ([]repository.Message, error) {
+		return []repository.Message{{ID: messageID, Content: "hi", Type: "TEXT"}}, nil
+	}
+
+	resp, err := service.GetMessages(contextWithUserID(testReaderID), &chatv1.GetMessagesRequest{
+		ConversationId: testReactionConversationID,
+	})
+	require.NoError(t, err)
+	assert.Equal(t, []pgtype.UUID{messageID}, captured.MessageIds, "reactions are loaded in one query for the page")
+	assert.Equal(t, mustParseUUID(t, testReaderID), captured.UserID)
+
+	require.Len(t, resp.Messages, 1)
+	reactions := resp.Messages[0].Reactions
+	require.Len(t, reactions, 2)
+	assert.Equal(t, "👍", reactions[0].Emoji)
+	assert.Equal(t, int32(3), reactions[0].Count)
+	assert.True(t, reactions[0].ReactedByMe)
+	assert.False(t, reactions[1].ReactedByMe)
+}
@@ -0,0 +1,30 @@
+package service
+
+import (
+	"errors"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+// Bounds applied to a conversation's message TTL
+const (
+	// MinMessageTTL keeps the purge job from racing messages that are still being delivered
+	MinMessageTTL = time.Minute
+	// MaxMessageTTL keeps message_ttl_seconds within the INT column
+	MaxMessageTTL = 10 * 365 * 24 * time.Hour
+)
+
+// ErrInvalidMessageTTL is returned for a message_ttl_seconds outside the allowed range
+var ErrInvalidMessageTTL = errors.New("message_ttl_seconds must be 0 (keep forever) or between 60 seconds and 10 years")
+
+// messageTTLParam converts a requested TTL to its column value: NULL for 0, seconds otherwise
+func messageTTLParam(seconds int64) (pgtype.Int4, error) {
+	if seconds == 0 {
+		return pgtype.Int4{}, nil
+	}
+	if seconds < int64(MinMessageTTL/time.Second) || seconds > int64(MaxMessageTTL/time.Second) {
+		return pgtype.Int4{}, ErrInvalidMessageTTL
+	}
+	return pgtype.Int4{Int32: int32(seconds), Valid: true}, nil
+}
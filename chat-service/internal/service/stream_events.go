@@ -0,0 +1,80 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+
+	chatv1 "chat-service/api/chat/v1"
+
+	"go.uber.org/zap"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// EventSubscriber delivers the real-time events of a user's conversations.
+// Each item is a JSON-encoded outbox event, as sent to WebSocket clients.
+type EventSubscriber interface {
+	// Subscribe returns the event channel of userID's device and a function releasing it.
+	// The channel is closed when the subscription is dropped by the subscriber.
+	Subscribe(ctx context.Context, userID, deviceID string) (<-chan []byte, func())
+}
+
+// streamedEvent mirrors the event envelope published by the outbox
+type streamedEvent struct {
+	EventID       string          `json:"event_id"`
+	AggregateType string          `json:"aggregate_type"`
+	AggregateID   string          `json:"aggregate_id"`
+	Payload       json.RawMessage `json:"payload"`
+	CreatedAt     int64           `json:"created_at"`
+}
+
+// StreamEvents pushes the caller's conversation events until the stream is closed.
+// It is the gRPC alternative to the WebSocket gateway and has no HTTP binding.
+func (s *ChatService) StreamEvents(_ *chatv1.StreamEventsRequest, stream chatv1.ChatService_StreamEventsServer) error {
+	ctx := stream.Context()
+
+	userID, err := getUserIDFromContext(ctx)
+	if err != nil {
+		s.logger.Error("failed to get user_id from context", zap.Error(err))
+		return err
+	}
+
+	if s.events == nil {
+		return status.Error(codes.Unimplemented, "event streaming is not enabled")
+	}
+
+	// A stream replaces the WebSocket or stream of the same device only
+	events, unsubscribe := s.events.Subscribe(ctx, userID, eventOriginFromContext(ctx).deviceID)
+	defer unsubscribe()
+
+	s.logger.Info("event stream opened", zap.String("user_id", userID))
+	defer s.logger.Info("event stream closed", zap.String("user_id", userID))
+
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case data, ok := <-events:
+			if !ok {
+				// Replaced by a newer stream or evicted as a slow consumer
+				return status.Error(codes.Unavailable, "event stream closed, reconnect")
+			}
+
+			var event streamedEvent
+			if err := json.Unmarshal(data, &event); err != nil {
+				s.logger.Warn("dropping malformed event", zap.String("user_id", userID), zap.Error(err))
+				continue
+			}
+
+			if err := stream.Send(&chatv1.ChatEvent{
+				EventId:       event.EventID,
+				AggregateType: event.AggregateType,
+				AggregateId:   event.AggregateID,
+				Payload:       string(event.Payload),
+				CreatedAt:     event.CreatedAt,
+			}); err != nil {
+				return err
+			}
+		}
+	}
+}
@@ -0,0 +1,157 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	chatv1 "chat-service/api/chat/v1"
+	ctxkeys "chat-service/internal/context"
+
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// fakeEventSubscriber hands out a single channel and records unsubscribes
+type fakeEventSubscriber struct {
+	events       chan []byte
+	userID       string
+	deviceID     string
+	unsubscribed chan struct{}
+}
+
+func newFakeEventSubscriber() *fakeEventSubscriber {
+	return &fakeEventSubscriber{
+		events:       make(chan []byte, 4),
+		unsubscribed: make(chan struct{}),
+	}
+}
+
+func (f *fakeEventSubscriber) Subscribe(ctx context.Context, userID, deviceID string) (<-chan []byte, func()) {
+	f.userID = userID
+	f.deviceID = deviceID
+	return f.events, func() { close(f.unsubscribed) }
+}
+
+// fakeEventStream is a ChatService_StreamEventsServer that records sent events
+type fakeEventStream struct {
+	grpc.ServerStream
+	ctx     context.Context
+	mu      sync.Mutex
+	sent    []*chatv1.ChatEvent
+	sendErr error
+}
+
+func (f *fakeEventStream) Context() context.Context {
+	return f.ctx
+}
+
+func (f *fakeEventStream) Send(event *chatv1.ChatEvent) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if f.sendErr != nil {
+		return f.sendErr
+	}
+	f.sent = append(f.sent, event)
+	return nil
+}
+
+func (f *fakeEventStream) Sent() []*chatv1.ChatEvent {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return append([]*chatv1.ChatEvent(nil), f.sent...)
+}
+
+func authenticatedStreamContext(userID string) (context.Context, context.CancelFunc) {
+	return context.WithCancel(context.WithValue(context.Background(), ctxkeys.UserIDKey, userID))
+}
+
+func TestStreamEvents_PushesEventsUntilClientDisconnects(t *testing.T) {
+	require := require.New(t)
+
+	subscriber := newFakeEventSubscriber()
+	service := &ChatService{logger: zap.NewNop()}
+	service.SetEventSubscriber(subscriber)
+
+	ctx, cancel := authenticatedStreamContext("user-1")
+	stream := &fakeEventStream{ctx: ctx}
+
+	subscriber.events <- []byte(`{"event_id":"evt-1","aggregate_type":"message","aggregate_id":"msg-1","payload":{"content":"hi"},"created_at":1700000000000}`)
+	subscriber.events <- []byte(`not json`)
+
+	done := make(chan error, 1)
+	go func() { done <- service.StreamEvents(&chatv1.StreamEventsRequest{}, stream) }()
+
+	require.Eventually(func() bool { return len(stream.Sent()) == 1 }, time.Second, 5*time.Millisecond)
+	cancel()
+
+	select {
+	case err := <-done:
+		require.NoError(err)
+	case <-time.After(time.Second):
+		t.Fatal("stream did not end after client disconnect")
+	}
+
+	require.Equal("user-1", subscriber.userID)
+	<-subscriber.unsubscribed
+
+	event := stream.Sent()[0]
+	require.Equal("evt-1", event.EventId)
+	require.Equal("message", event.AggregateType)
+	require.Equal("msg-1", event.AggregateId)
+	require.JSONEq(`{"content":"hi"}`, event.Payload)
+	require.Equal(int64(1700000000000), event.CreatedAt)
+}
+
+func TestStreamEvents_ClosedSubscriptionAsksToReconnect(t *testing.T) {
+	subscriber := newFakeEventSubscriber()
+	close(subscriber.events)
+	service := &ChatService{logger: zap.NewNop()}
+	service.SetEventSubscriber(subscriber)
+
+	ctx, cancel := authenticatedStreamContext("user-1")
+	defer cancel()
+
+	err := service.StreamEvents(&chatv1.StreamEventsRequest{}, &fakeEventStream{ctx: ctx})
+	require.Equal(t, codes.Unavailable, status.Code(err))
+	<-subscriber.unsubscribed
+}
+
+func TestStreamEvents_SendErrorEndsStream(t *testing.T) {
+	subscriber := newFakeEventSubscriber()
+	subscriber.events <- []byte(`{"event_id":"evt-1","aggregate_type":"message","payload":{}}`)
+	service := &ChatService{logger: zap.NewNop()}
+	service.SetEventSubscriber(subscriber)
+
+	ctx, cancel := authenticatedStreamContext("user-1")
+	defer cancel()
+
+	sendErr := errors.New("transport closing")
+	err := service.StreamEvents(&chatv1.StreamEventsRequest{}, &fakeEventStream{ctx: ctx, sendErr: sendErr})
+	require.ErrorIs(t, err, sendErr)
+	<-subscriber.unsubscribed
+}
+
+func TestStreamEvents_Errors(t *testing.T) {
+	t.Run("unauthenticated", func(t *testing.T) {
+		service := &ChatService{logger: zap.NewNop()}
+		service.SetEventSubscriber(newFakeEventSubscriber())
+
+		err := service.StreamEvents(&chatv1.StreamEventsRequest{}, &fakeEventStream{ctx: context.Background()})
+		require.Equal(t, codes.Unauthenticated, status.Code(err))
+	})
+
+	t.Run("not enabled", func(t *testing.T) {
+		service := &ChatService{logger: zap.NewNop()}
+		ctx, cancel := authenticatedStreamContext("user-1")
+		defer cancel()
+
+		err := service.StreamEvents(&chatv1.StreamEventsRequest{}, &fakeEventStream{ctx: ctx})
+		require.Equal(t, codes.Unimplemented, status.Code(err))
+	})
+}
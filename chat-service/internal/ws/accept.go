@@ -0,0 +1,126 @@
+package ws
+
+import (
+	"encoding/json"
+	"math"
+	"net/http"
+	"strconv"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// Reasons a /ws request is turned away, used as metric labels
+const (
+	AcceptRejectRateLimited = "rate_limited" // Accept rate above WS_ACCEPT_RATE
+	AcceptRejectDraining    = "draining"     // Gateway shutting down
+)
+
+// AcceptLimiter is a token bucket over new /ws connections: rate accepts per second on average,
+// with bursts of up to burst. It only paces the accept path; established connections are not affected.
+type AcceptLimiter struct {
+	mu     sync.Mutex
+	rate   float64
+	burst  float64
+	tokens float64
+	last   time.Time
+
+	now func() time.Time // replaced in tests
+}
+
+// NewAcceptLimiter creates a limiter that starts full. A non-positive burst uses the rate (at least 1).
+func NewAcceptLimiter(rate float64, burst int) *AcceptLimiter {
+	if burst <= 0 {
+		burst = int(math.Max(1, math.Ceil(rate)))
+	}
+	l := &AcceptLimiter{rate: rate, burst: float64(burst), tokens: float64(burst), now: time.Now}
+	l.last = l.now()
+	return l
+}
+
+// Allow takes a token if one is available. Otherwise it returns false and how long until the next one.
+func (l *AcceptLimiter) Allow() (bool, time.Duration) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	now := l.now()
+	if elapsed := now.Sub(l.last); elapsed > 0 {
+		l.tokens = math.Min(l.burst, l.tokens+elapsed.Seconds()*l.rate)
+	}
+	l.last = now
+
+	if l.tokens >= 1 {
+		l.tokens--
+		return true, 0
+	}
+	return false, time.Duration((1 - l.tokens) / l.rate * float64(time.Second))
+}
+
+// AcceptMetrics tracks rejected /ws requests.
+type AcceptMetrics interface {
+	IncAcceptsRejected(reason string)
+}
+
+// AcceptGate turns /ws requests away with 503 and a jittered Retry-After when the accept limiter is
+// saturated or the gateway is draining, so a reconnect storm spreads out instead of piling onto
+// authentication and the upgrade. Rejections happen before authentication, which is the costly part.
+type AcceptGate struct {
+	limiter  *AcceptLimiter // nil accepts at any rate
+	jitter   *ReconnectJitter
+	metrics  AcceptMetrics
+	draining atomic.Bool
+}
+
+// NewAcceptGate creates a gate. limiter may be nil to only reject while draining.
+func NewAcceptGate(limiter *AcceptLimiter, jitter *ReconnectJitter) *AcceptGate {
+	if jitter == nil {
+		jitter = NewReconnectJitter(0, 0)
+	}
+	return &AcceptGate{limiter: limiter, jitter: jitter}
+}
+
+// SetMetrics sets the metrics for rejected requests.
+func (g *AcceptGate) SetMetrics(metrics AcceptMetrics) {
+	g.metrics = metrics
+}
+
+// SetDraining makes the gate reject every new connection; call it when shutdown starts.
+func (g *AcceptGate) SetDraining() {
+	g.draining.Store(true)
+}
+
+// Wrap returns next behind the gate.
+func (g *AcceptGate) Wrap(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if g.draining.Load() {
+			g.reject(w, AcceptRejectDraining, "server draining", 0)
+			return
+		}
+		if g.limiter != nil {
+			if ok, wait := g.limiter.Allow(); !ok {
+				g.reject(w, AcceptRejectRateLimited, "server busy", wait)
+				return
+			}
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+// reject answers 503. The delay is the wait for a free slot plus jitter; Retry-After is in whole
+// seconds (rounded up), the body carries the same delay in milliseconds for clients that can read it.
+func (g *AcceptGate) reject(w http.ResponseWriter, reason, message string, wait time.Duration) {
+	if g.metrics != nil {
+		g.metrics.IncAcceptsRejected(reason)
+	}
+
+	delay := wait + g.jitter.Next()
+	seconds := int64(math.Ceil(delay.Seconds()))
+
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Retry-After", strconv.FormatInt(seconds, 10))
+	w.WriteHeader(http.StatusServiceUnavailable)
+	_ = json.NewEncoder(w).Encode(map[string]interface{}{
+		"error":              message,
+		"reconnect_after_ms": delay.Milliseconds(),
+	})
+}
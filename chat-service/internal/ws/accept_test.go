@@ -0,0 +1,133 @@
+package ws
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// mockAcceptMetrics implements AcceptMetrics for testing
+type mockAcceptMetrics struct {
+	mu      sync.Mutex
+	reasons []string
+}
+
+func (m *mockAcceptMetrics) IncAcceptsRejected(reason string) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.reasons = append(m.reasons, reason)
+}
+
+func newTestLimiter(rate float64, burst int) (*AcceptLimiter, *time.Time) {
+	now := time.Date(2024, 1, 15, 10, 30, 0, 0, time.UTC)
+	l := NewAcceptLimiter(rate, burst)
+	l.now = func() time.Time { return now }
+	l.last = now
+	return l, &now
+}
+
+func TestAcceptLimiter_BurstThenRate(t *testing.T) {
+	l, now := newTestLimiter(10, 3)
+
+	for i := 0; i < 3; i++ {
+		ok, _ := l.Allow()
+		assert.True(t, ok, "burst accept %d", i)
+	}
+	ok, wait := l.Allow()
+	assert.False(t, ok)
+	assert.Equal(t, 100*time.Millisecond, wait)
+
+	// One token every 100ms
+	*now = now.Add(100 * time.Millisecond)
+	ok, _ = l.Allow()
+	assert.True(t, ok)
+	ok, _ = l.Allow()
+	assert.False(t, ok)
+
+	// Idle time refills up to the burst only
+	*now = now.Add(time.Minute)
+	for i := 0; i < 3; i++ {
+		ok, _ := l.Allow()
+		assert.True(t, ok)
+	}
+	ok, _ = l.Allow()
+	assert.False(t, ok)
+}
+
+func TestAcceptLimiter_DefaultBurst(t *testing.T) {
+	l, _ := newTestLimiter(0.5, 0)
+
+	ok, _ := l.Allow()
+	assert.True(t, ok, "burst is at least 1")
+	ok, wait := l.Allow()
+	assert.False(t, ok)
+	assert.Equal(t, 2*time.Second, wait)
+}
+
+func TestAcceptGate_RejectsWhenSaturated(t *testing.T) {
+	limiter, _ := newTestLimiter(1, 1)
+	jitter := NewReconnectJitter(time.Second, 5*time.Second)
+	jitter.int64n = func(n int64) int64 { return 1500 }
+	metrics := &mockAcceptMetrics{}
+
+	gate := NewAcceptGate(limiter, jitter)
+	gate.SetMetrics(metrics)
+	calls := 0
+	handler := gate.Wrap(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls++
+	}))
+
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/ws", nil))
+	assert.Equal(t, http.StatusOK, rec.Code)
+
+	rec = httptest.NewRecorder()
+	handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/ws", nil))
+	assert.Equal(t, http.StatusServiceUnavailable, rec.Code)
+	assert.Equal(t, 1, calls, "rejected before the handler")
+
+	// 1s until the next token + 1s minimum + 1.5s jitter, rounded up to whole seconds
+	assert.Equal(t, "4", rec.Header().Get("Retry-After"))
+	var body map[string]interface{}
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &body))
+	assert.Equal(t, "server busy", body["error"])
+	assert.Equal(t, float64(3500), body["reconnect_after_ms"])
+	assert.Equal(t, []string{AcceptRejectRateLimited}, metrics.reasons)
+}
+
+func TestAcceptGate_Draining(t *testing.T) {
+	metrics := &mockAcceptMetrics{}
+	gate := NewAcceptGate(nil, nil)
+	gate.SetMetrics(metrics)
+	handler := gate.Wrap(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/ws", nil))
+	assert.Equal(t, http.StatusOK, rec.Code, "no limiter: any rate is accepted")
+
+	gate.SetDraining()
+	rec = httptest.NewRecorder()
+	handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/ws", nil))
+	assert.Equal(t, http.StatusServiceUnavailable, rec.Code)
+	assert.NotEmpty(t, rec.Header().Get("Retry-After"))
+	assert.Equal(t, []string{AcceptRejectDraining}, metrics.reasons)
+}
+
+func TestMetrics_IncAcceptsRejected(t *testing.T) {
+	m := NewMetrics(prometheus.NewRegistry())
+
+	m.IncAcceptsRejected(AcceptRejectRateLimited)
+	m.IncAcceptsRejected(AcceptRejectRateLimited)
+	m.IncAcceptsRejected(AcceptRejectDraining)
+
+	assert.Equal(t, float64(2), testutil.ToFloat64(m.AcceptsRejected.WithLabelValues(AcceptRejectRateLimited)))
+	assert.Equal(t, float64(1), testutil.ToFloat64(m.AcceptsRejected.WithLabelValues(AcceptRejectDraining)))
+}
@@ -0,0 +1,91 @@
+package ws
+
+import (
+	"encoding/json"
+	"net/http"
+	"slices"
+
+	"chat-service/internal/auth"
+)
+
+// ConnectionDump is the body of the admin connection dump
+type ConnectionDump struct {
+	InstanceID  string           `json:"instance_id"`
+	Count       int              `json:"count"`
+	Connections []ConnectionInfo `json:"connections"`
+}
+
+// NewConnectionDumpHandler serves the live connections of this gateway with their metadata,
+// for incident debugging. It exposes client addresses, so it requires a bearer access token
+// with the admin role: 401 without a valid token, 403 for other roles.
+func NewConnectionDumpHandler(manager *ConnectionManager, verifier *auth.JWTVerifier) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet {
+			http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+		if !authorizeAdmin(w, r, verifier) {
+			return
+		}
+
+		connections := manager.Snapshot()
+		w.Header().Set("Content-Type", "application/json")
+		_ = json.NewEncoder(w).Encode(ConnectionDump{
+			InstanceID:  GetInstanceID(),
+			Count:       len(connections),
+			Connections: connections,
+		})
+	}
+}
+
+// PresenceResponse is the body of the presence lookup
+type PresenceResponse struct {
+	UserID    string   `json:"user_id"`
+	Instances []string `json:"instances"`
+}
+
+// NewPresenceHandler resolves which gateway instances hold the connections of the user_id
+// query parameter, for services that address instances directly. Like the connection dump it
+// requires a bearer access token with the admin role.
+func NewPresenceHandler(registry *PresenceRegistry, verifier *auth.JWTVerifier) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet {
+			http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+		if !authorizeAdmin(w, r, verifier) {
+			return
+		}
+
+		userID := r.URL.Query().Get("user_id")
+		if userID == "" {
+			http.Error(w, "user_id is required", http.StatusBadRequest)
+			return
+		}
+		instances, err := registry.Instances(r.Context(), userID)
+		if err != nil {
+			http.Error(w, "Presence lookup failed", http.StatusServiceUnavailable)
+			return
+		}
+		if instances == nil {
+			instances = []string{}
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		_ = json.NewEncoder(w).Encode(PresenceResponse{UserID: userID, Instances: instances})
+	}
+}
+
+// authorizeAdmin answers 401 without a valid access token and 403 for other roles than admin
+func authorizeAdmin(w http.ResponseWriter, r *http.Request, verifier *auth.JWTVerifier) bool {
+	claims, err := verifier.VerifyAuthorization(r.Header.Get(auth.AuthorizationHeader))
+	if err != nil {
+		http.Error(w, "Unauthorized", http.StatusUnauthorized)
+		return false
+	}
+	if !slices.Contains(claims.AllRoles(), auth.RoleAdmin) {
+		http.Error(w, "Forbidden", http.StatusForbidden)
+		return false
+	}
+	return true
+}
@@ -0,0 +1,160 @@
+package ws
+
+import (
+	"fmt"
+	"net"
+	"net/http"
+	"sort"
+	"strings"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+// MaxUserAgentLength bounds the user agent kept per connection; clients choose it freely
+const MaxUserAgentLength = 256
+
+// DefaultTrustedProxies are the peers whose X-Forwarded-For is believed when
+// WS_TRUSTED_PROXIES is not set: loopback and private networks, where the load
+// balancer and API Gateway run. Anything else connecting directly is the client.
+var DefaultTrustedProxies = []string{
+	"127.0.0.0/8", "::1/128",
+	"10.0.0.0/8", "172.16.0.0/12", "192.168.0.0/16", "fc00::/7",
+}
+
+// TrustedProxies is the set of networks allowed to report the client address in X-Forwarded-For
+type TrustedProxies []*net.IPNet
+
+// ParseTrustedProxies parses a comma-separated list of CIDRs or single IPs.
+// An empty spec returns DefaultTrustedProxies; "none" trusts no proxy.
+func ParseTrustedProxies(spec string) (TrustedProxies, error) {
+	spec = strings.TrimSpace(spec)
+	switch spec {
+	case "":
+		return ParseTrustedProxies(strings.Join(DefaultTrustedProxies, ","))
+	case "none":
+		return TrustedProxies{}, nil
+	}
+
+	var proxies TrustedProxies
+	for _, entry := range strings.Split(spec, ",") {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+		if !strings.Contains(entry, "/") {
+			ip := net.ParseIP(entry)
+			if ip == nil {
+				return nil, fmt.Errorf("invalid trusted proxy %q", entry)
+			}
+			bits := 128
+			if ip.To4() != nil {
+				ip, bits = ip.To4(), 32
+			}
+			proxies = append(proxies, &net.IPNet{IP: ip, Mask: net.CIDRMask(bits, bits)})
+			continue
+		}
+		_, network, err := net.ParseCIDR(entry)
+		if err != nil {
+			return nil, fmt.Errorf("invalid trusted proxy %q: %w", entry, err)
+		}
+		proxies = append(proxies, network)
+	}
+	return proxies, nil
+}
+
+// Contains reports whether ip belongs to a trusted proxy
+func (t TrustedProxies) Contains(ip net.IP) bool {
+	for _, network := range t {
+		if network.Contains(ip) {
+			return true
+		}
+	}
+	return false
+}
+
+// ClientIP returns the address of the client behind r.
+// X-Forwarded-For is only believed when the direct peer is a trusted proxy, and then it is
+// read right to left: every proxy appends the address it received the request from, so the
+// first address that is not a trusted proxy is the client. Entries further left were written
+// by the client itself and can be forged. Falls back to the peer address.
+func (t TrustedProxies) ClientIP(r *http.Request) string {
+	peer := r.RemoteAddr
+	if host, _, err := net.SplitHostPort(peer); err == nil {
+		peer = host
+	}
+	peerIP := net.ParseIP(peer)
+	if peerIP == nil || !t.Contains(peerIP) {
+		return peer
+	}
+
+	var hops []string
+	for _, header := range r.Header.Values("X-Forwarded-For") {
+		hops = append(hops, strings.Split(header, ",")...)
+	}
+	for i := len(hops) - 1; i >= 0; i-- {
+		ip := net.ParseIP(strings.TrimSpace(hops[i]))
+		if ip == nil {
+			// A malformed hop ends the trustworthy part of the chain
+			break
+		}
+		if !t.Contains(ip) {
+			return ip.String()
+		}
+		peerIP = ip
+	}
+	// Every hop was a trusted proxy (or there were none): the left-most trusted address is all we know
+	return peerIP.String()
+}
+
+// SetRequestInfo records the user agent and client address of the upgrade request.
+// Set it before adding the client to the ConnectionManager.
+func (c *Client) SetRequestInfo(r *http.Request, proxies TrustedProxies) {
+	userAgent := r.UserAgent()
+	if len(userAgent) > MaxUserAgentLength {
+		userAgent = userAgent[:MaxUserAgentLength]
+	}
+	c.UserAgent = userAgent
+	c.RemoteAddr = proxies.ClientIP(r)
+}
+
+// LogFields returns the connection metadata for log lines about this client
+func (c *Client) LogFields(userID string) []zap.Field {
+	return []zap.Field{
+		zap.String("user_id", userID),
+		zap.String("device_id", c.DeviceID),
+		zap.String("remote_addr", c.RemoteAddr),
+		zap.String("user_agent", c.UserAgent),
+	}
+}
+
+// ConnectionInfo describes one live connection, for the admin connection dump
+type ConnectionInfo struct {
+	UserID       string    `json:"user_id"`
+	DeviceID     string    `json:"device_id,omitempty"`
+	RemoteAddr   string    `json:"remote_addr"`
+	UserAgent    string    `json:"user_agent"`
+	ConnectedAt  time.Time `json:"connected_at"`
+	LastActivity time.Time `json:"last_activity"`
+}
+
+// Snapshot lists every registered connection, oldest first
+func (cm *ConnectionManager) Snapshot() []ConnectionInfo {
+	infos := make([]ConnectionInfo, 0, cm.Count())
+	for userID, clients := range cm.GetAllClients() {
+		for _, client := range clients {
+			infos = append(infos, ConnectionInfo{
+				UserID:       userID,
+				DeviceID:     client.DeviceID,
+				RemoteAddr:   client.RemoteAddr,
+				UserAgent:    client.UserAgent,
+				ConnectedAt:  client.ConnectedAt,
+				LastActivity: client.LastActivity(),
+			})
+		}
+	}
+	sort.Slice(infos, func(i, j int) bool {
+		return infos[i].ConnectedAt.Before(infos[j].ConnectedAt)
+	})
+	return infos
+}
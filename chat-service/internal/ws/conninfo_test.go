@@ -0,0 +1,158 @@
+package ws
+
+import (
+	"encoding/json"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"chat-service/internal/auth"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseTrustedProxies(t *testing.T) {
+	defaults, err := ParseTrustedProxies("")
+	require.NoError(t, err)
+	assert.Len(t, defaults, len(DefaultTrustedProxies))
+
+	none, err := ParseTrustedProxies("none")
+	require.NoError(t, err)
+	assert.Empty(t, none)
+
+	custom, err := ParseTrustedProxies(" 203.0.113.0/24, 198.51.100.7 ,2001:db8::1")
+	require.NoError(t, err)
+	require.Len(t, custom, 3)
+	assert.True(t, custom.Contains(mustParseIP(t, "203.0.113.9")))
+	assert.True(t, custom.Contains(mustParseIP(t, "198.51.100.7")))
+	assert.False(t, custom.Contains(mustParseIP(t, "198.51.100.8")))
+	assert.True(t, custom.Contains(mustParseIP(t, "2001:db8::1")))
+
+	_, err = ParseTrustedProxies("10.0.0.0/33")
+	assert.Error(t, err)
+	_, err = ParseTrustedProxies("load-balancer")
+	assert.Error(t, err)
+}
+
+func TestTrustedProxies_ClientIP(t *testing.T) {
+	proxies, err := ParseTrustedProxies("")
+	require.NoError(t, err)
+
+	tests := []struct {
+		name       string
+		remoteAddr string
+		forwarded  []string
+		want       string
+	}{
+		{name: "direct client", remoteAddr: "203.0.113.5:51000", want: "203.0.113.5"},
+		{name: "direct client forging the header", remoteAddr: "203.0.113.5:51000", forwarded: []string{"1.2.3.4"}, want: "203.0.113.5"},
+		{name: "behind load balancer", remoteAddr: "10.0.0.2:443", forwarded: []string{"198.51.100.7"}, want: "198.51.100.7"},
+		{name: "two proxies", remoteAddr: "10.0.0.2:443", forwarded: []string{"198.51.100.7, 10.0.0.9"}, want: "198.51.100.7"},
+		{name: "client-supplied entries are skipped", remoteAddr: "10.0.0.2:443", forwarded: []string{"1.2.3.4, 198.51.100.7"}, want: "198.51.100.7"},
+		{name: "repeated headers", remoteAddr: "10.0.0.2:443", forwarded: []string{"1.2.3.4", "198.51.100.7, 10.0.0.9"}, want: "198.51.100.7"},
+		{name: "malformed hop stops the walk", remoteAddr: "10.0.0.2:443", forwarded: []string{"198.51.100.7, garbage, 10.0.0.9"}, want: "10.0.0.9"},
+		{name: "trusted peer without header", remoteAddr: "10.0.0.2:443", want: "10.0.0.2"},
+		{name: "ipv6 client", remoteAddr: "[::1]:8080", forwarded: []string{"2001:db8::7"}, want: "2001:db8::7"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := httptest.NewRequest(http.MethodGet, "/ws", nil)
+			r.RemoteAddr = tt.remoteAddr
+			for _, value := range tt.forwarded {
+				r.Header.Add("X-Forwarded-For", value)
+			}
+			assert.Equal(t, tt.want, proxies.ClientIP(r))
+		})
+	}
+}
+
+func TestClient_SetRequestInfo(t *testing.T) {
+	r := httptest.NewRequest(http.MethodGet, "/ws", nil)
+	r.RemoteAddr = "203.0.113.5:51000"
+	r.Header.Set("User-Agent", strings.Repeat("a", MaxUserAgentLength+10))
+
+	client := NewClient(nil)
+	client.SetRequestInfo(r, TrustedProxies{})
+
+	assert.Equal(t, "203.0.113.5", client.RemoteAddr)
+	assert.Len(t, client.UserAgent, MaxUserAgentLength)
+}
+
+func TestConnectionDumpHandler(t *testing.T) {
+	const secret = "dump-secret"
+	manager := NewConnectionManager()
+
+	older := NewClient(nil)
+	older.DeviceID = "phone"
+	older.RemoteAddr = "198.51.100.7"
+	older.UserAgent = "ChatApp/1.0 (iOS)"
+	older.ConnectedAt = time.Now().Add(-time.Minute)
+	manager.Add("user-1", older)
+
+	newer := NewClient(nil)
+	newer.RemoteAddr = "203.0.113.5"
+	manager.Add("user-2", newer)
+
+	handler := NewConnectionDumpHandler(manager, auth.NewJWTVerifier(secret))
+	sign := func(claims jwt.MapClaims) string {
+		claims["exp"] = time.Now().Add(time.Hour).Unix()
+		token, err := jwt.NewWithClaims(jwt.SigningMethodHS256, claims).SignedString([]byte(secret))
+		require.NoError(t, err)
+		return "Bearer " + token
+	}
+
+	tests := []struct {
+		name          string
+		authorization string
+		wantStatus    int
+	}{
+		{name: "no token", wantStatus: http.StatusUnauthorized},
+		{name: "bad token", authorization: "Bearer nope", wantStatus: http.StatusUnauthorized},
+		{name: "not an admin", authorization: sign(jwt.MapClaims{"id": "user-1"}), wantStatus: http.StatusForbidden},
+		{name: "admin", authorization: sign(jwt.MapClaims{"id": "ops-1", "role": auth.RoleAdmin}), wantStatus: http.StatusOK},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := httptest.NewRequest(http.MethodGet, "/admin/connections", nil)
+			if tt.authorization != "" {
+				r.Header.Set("Authorization", tt.authorization)
+			}
+			w := httptest.NewRecorder()
+			handler(w, r)
+
+			require.Equal(t, tt.wantStatus, w.Code)
+			if tt.wantStatus != http.StatusOK {
+				assert.NotContains(t, w.Body.String(), "198.51.100.7")
+				return
+			}
+
+			var dump ConnectionDump
+			require.NoError(t, json.Unmarshal(w.Body.Bytes(), &dump))
+			assert.Equal(t, 2, dump.Count)
+			require.Len(t, dump.Connections, 2)
+			assert.Equal(t, ConnectionInfo{
+				UserID:       "user-1",
+				DeviceID:     "phone",
+				RemoteAddr:   "198.51.100.7",
+				UserAgent:    "ChatApp/1.0 (iOS)",
+				ConnectedAt:  dump.Connections[0].ConnectedAt,
+				LastActivity: dump.Connections[0].LastActivity,
+			}, dump.Connections[0], "oldest connection first")
+			assert.Equal(t, "user-2", dump.Connections[1].UserID)
+		})
+	}
+}
+
+func mustParseIP(t *testing.T, s string) net.IP {
+	t.Helper()
+	ip := net.ParseIP(s)
+	require.NotNil(t, ip, s)
+	return ip
+}
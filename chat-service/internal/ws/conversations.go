@@ -0,0 +1,59 @@
+package ws
+
+import (
+	"context"
+	"fmt"
+
+	"chat-service/internal/repository"
+
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+// DBConversationLister lists a user's conversations, and a conversation's participants,
+// from the chat database.
+type DBConversationLister struct {
+	queries *repository.Queries
+}
+
+// NewDBConversationLister creates a lister over a database connection or pool.
+func NewDBConversationLister(db repository.DBTX) *DBConversationLister {
+	return &DBConversationLister{queries: repository.New(db)}
+}
+
+// ListConversationIDs returns the IDs of all conversations userID participates in.
+func (l *DBConversationLister) ListConversationIDs(ctx context.Context, userID string) ([]string, error) {
+	var id pgtype.UUID
+	if err := id.Scan(userID); err != nil {
+		return nil, fmt.Errorf("invalid user id %q: %w", userID, err)
+	}
+
+	conversationIDs, err := l.queries.ListConversationIDsForUser(ctx, id)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list conversations: %w", err)
+	}
+
+	ids := make([]string, len(conversationIDs))
+	for i, conversationID := range conversationIDs {
+		ids[i] = conversationID.String()
+	}
+	return ids, nil
+}
+
+// ListParticipantIDs returns the user IDs of all participants of conversationID.
+func (l *DBConversationLister) ListParticipantIDs(ctx context.Context, conversationID string) ([]string, error) {
+	var id pgtype.UUID
+	if err := id.Scan(conversationID); err != nil {
+		return nil, fmt.Errorf("invalid conversation id %q: %w", conversationID, err)
+	}
+
+	userIDs, err := l.queries.GetConversationParticipants(ctx, id)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list participants: %w", err)
+	}
+
+	ids := make([]string, len(userIDs))
+	for i, userID := range userIDs {
+		ids[i] = userID.String()
+	}
+	return ids, nil
+}
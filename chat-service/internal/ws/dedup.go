@@ -0,0 +1,43 @@
+package ws
+
+import "sync"
+
+// recentEventIDsSize is how many delivered event IDs each connection remembers.
+// Outbox retries republish within seconds, so a short window catches them.
+const recentEventIDsSize = 128
+
+// eventIDRing remembers the last N event IDs in insertion order.
+// The outbox is at-least-once; this lets a connection drop events it already delivered.
+type eventIDRing struct {
+	mu   sync.Mutex
+	ids  []string
+	next int
+	seen map[string]struct{}
+}
+
+func newEventIDRing(size int) *eventIDRing {
+	return &eventIDRing{
+		ids:  make([]string, size),
+		seen: make(map[string]struct{}, size),
+	}
+}
+
+// Add records eventID and reports whether it was new.
+// Once the ring is full the oldest ID is evicted.
+func (r *eventIDRing) Add(eventID string) bool {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, ok := r.seen[eventID]; ok {
+		return false
+	}
+
+	if evicted := r.ids[r.next]; evicted != "" {
+		delete(r.seen, evicted)
+	}
+	r.ids[r.next] = eventID
+	r.seen[eventID] = struct{}{}
+	r.next = (r.next + 1) % len(r.ids)
+
+	return true
+}
@@ -0,0 +1,41 @@
+package ws
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestEventIDRing_DetectsDuplicates(t *testing.T) {
+	ring := newEventIDRing(4)
+
+	assert.True(t, ring.Add("event-1"))
+	assert.True(t, ring.Add("event-2"))
+	assert.False(t, ring.Add("event-1"), "redelivered event should be reported as seen")
+	assert.False(t, ring.Add("event-2"))
+}
+
+func TestEventIDRing_EvictsOldest(t *testing.T) {
+	ring := newEventIDRing(3)
+
+	for i := 1; i <= 4; i++ {
+		assert.True(t, ring.Add(fmt.Sprintf("event-%d", i)))
+	}
+
+	// event-1 was evicted by event-4; the rest are still remembered
+	assert.True(t, ring.Add("event-1"))
+	assert.False(t, ring.Add("event-4"))
+	assert.Len(t, ring.seen, 3, "seen set must stay bounded by the ring size")
+}
+
+func TestClient_MarkDelivered(t *testing.T) {
+	client := NewClient(nil)
+
+	assert.True(t, client.MarkDelivered("event-1"))
+	assert.False(t, client.MarkDelivered("event-1"))
+
+	// Events without an ID can't be deduplicated
+	assert.True(t, client.MarkDelivered(""))
+	assert.True(t, client.MarkDelivered(""))
+}
@@ -0,0 +1,50 @@
+package ws
+
+import (
+	"errors"
+	"net/http"
+)
+
+const (
+	// DeviceIDHeader identifies the device of a connection (and of API requests, which
+	// stamp it on their events as origin_device_id)
+	DeviceIDHeader = "X-Device-Id"
+	// DeviceIDQueryParam is the fallback for browser WebSockets, which cannot set headers
+	DeviceIDQueryParam = "device_id"
+	// MaxDeviceIDLength bounds device IDs chosen by clients
+	MaxDeviceIDLength = 64
+)
+
+// ErrInvalidDeviceID is returned for device IDs that are too long or contain
+// characters other than letters, digits, '-', '_', '.' and ':'
+var ErrInvalidDeviceID = errors.New("invalid device id")
+
+// DeviceIDFromRequest returns the device ID of a /ws upgrade request.
+// Priority: 1) X-Device-Id header, 2) device_id query parameter.
+// A missing device ID is valid: the connection uses the shared default device.
+func DeviceIDFromRequest(r *http.Request) (string, error) {
+	deviceID := r.Header.Get(DeviceIDHeader)
+	if deviceID == "" {
+		deviceID = r.URL.Query().Get(DeviceIDQueryParam)
+	}
+	if !ValidDeviceID(deviceID) {
+		return "", ErrInvalidDeviceID
+	}
+	return deviceID, nil
+}
+
+// ValidDeviceID reports whether deviceID is empty or a well-formed device ID
+func ValidDeviceID(deviceID string) bool {
+	if len(deviceID) > MaxDeviceIDLength {
+		return false
+	}
+	for _, c := range deviceID {
+		switch {
+		case c >= 'a' && c <= 'z', c >= 'A' && c <= 'Z', c >= '0' && c <= '9':
+		case c == '-', c == '_', c == '.', c == ':':
+		default:
+			return false
+		}
+	}
+	return true
+}
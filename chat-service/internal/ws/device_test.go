@@ -0,0 +1,48 @@
+package ws
+
+import (
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDeviceIDFromRequest(t *testing.T) {
+	tests := []struct {
+		name    string
+		header  string
+		query   string
+		want    string
+		wantErr bool
+	}{
+		{name: "header", header: "phone-1", want: "phone-1"},
+		{name: "header takes precedence", header: "phone-1", query: "laptop", want: "phone-1"},
+		{name: "query fallback", query: "web:3f2a", want: "web:3f2a"},
+		{name: "absent uses the default device", want: ""},
+		{name: "invalid characters", query: "phone 1", wantErr: true},
+		{name: "too long", header: strings.Repeat("a", MaxDeviceIDLength+1), wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := httptest.NewRequest("GET", "/ws", nil)
+			if tt.query != "" {
+				q := r.URL.Query()
+				q.Set(DeviceIDQueryParam, tt.query)
+				r.URL.RawQuery = q.Encode()
+			}
+			if tt.header != "" {
+				r.Header.Set(DeviceIDHeader, tt.header)
+			}
+
+			deviceID, err := DeviceIDFromRequest(r)
+			if tt.wantErr {
+				assert.ErrorIs(t, err, ErrInvalidDeviceID)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, tt.want, deviceID)
+		})
+	}
+}
@@ -0,0 +1,194 @@
+package ws
+
+import (
+	"context"
+	"encoding/json"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"chat-service/pkg/eventshard"
+
+	"go.uber.org/zap"
+)
+
+const (
+	// DefaultDispatchWorkers is how many events are routed in parallel
+	DefaultDispatchWorkers = 4
+
+	// DefaultDispatchQueueSize is how many events may wait for each worker
+	DefaultDispatchQueueSize = 1024
+
+	// DefaultDispatchBlockTimeout is how long a subscriber waits for room in a full queue before the event is dropped
+	DefaultDispatchBlockTimeout = time.Second
+)
+
+// DispatcherMetrics tracks the event queue in front of the Router.
+type DispatcherMetrics interface {
+	SetDispatchQueueDepth(depth int)
+	IncDispatchBlocked()
+	IncDispatchDropped()
+}
+
+// dispatchItem is a queued event and the callback to run once it was handled
+type dispatchItem struct {
+	event EventPayload
+	ack   func() // nil = nothing to acknowledge
+}
+
+// dispatchKey is the part of the inner payload that decides the worker
+type dispatchKey struct {
+	ConversationID string `json:"conversation_id"`
+}
+
+// EventDispatcher puts a fixed set of workers with bounded queues between a subscriber and
+// its handler (Router.HandleEvent), so a burst of events costs at most workers*queueSize queued
+// events instead of growing without bound, and slow routing (e.g. loading slim messages)
+// pushes back on the subscriber.
+//
+// Events of one conversation always go to the same worker, so they are delivered in the order
+// they were received. When a worker's queue is full, Handle blocks the subscriber for up to
+// blockTimeout: Redis buffers meanwhile (Pub/Sub in the client's receive buffer, Streams in the
+// stream itself). If the queue is still full after that, the event is dropped and counted;
+// clients recover it through the API like any other missed event. Subscribers that acknowledge
+// events (Redis Streams) use HandleAck instead, so dropped events stay unacknowledged.
+type EventDispatcher struct {
+	handler      EventHandler
+	logger       *zap.Logger
+	metrics      DispatcherMetrics
+	queues       []chan dispatchItem
+	blockTimeout time.Duration
+
+	depth atomic.Int64
+	wg    sync.WaitGroup
+}
+
+// NewEventDispatcher creates a dispatcher in front of handler.
+// Non-positive workers, queueSize or blockTimeout use the defaults.
+func NewEventDispatcher(handler EventHandler, logger *zap.Logger, workers, queueSize int, blockTimeout time.Duration) *EventDispatcher {
+	if workers <= 0 {
+		workers = DefaultDispatchWorkers
+	}
+	if queueSize <= 0 {
+		queueSize = DefaultDispatchQueueSize
+	}
+	if blockTimeout <= 0 {
+		blockTimeout = DefaultDispatchBlockTimeout
+	}
+
+	queues := make([]chan dispatchItem, workers)
+	for i := range queues {
+		queues[i] = make(chan dispatchItem, queueSize)
+	}
+	return &EventDispatcher{
+		handler:      handler,
+		logger:       logger,
+		queues:       queues,
+		blockTimeout: blockTimeout,
+	}
+}
+
+// SetMetrics sets the metrics recorder for the queue. Call before Start.
+func (d *EventDispatcher) SetMetrics(metrics DispatcherMetrics) {
+	d.metrics = metrics
+}
+
+// Start runs the workers until ctx is cancelled; events still queued then are discarded
+// without being acknowledged.
+// Use Wait to block until the workers have exited.
+func (d *EventDispatcher) Start(ctx context.Context) {
+	for _, queue := range d.queues {
+		d.wg.Add(1)
+		go d.work(ctx, queue)
+	}
+}
+
+// Wait blocks until the workers started by Start have exited.
+func (d *EventDispatcher) Wait() {
+	d.wg.Wait()
+}
+
+// Handle queues an event for its conversation's worker. It is an EventHandler, passed to
+// a subscriber in place of the Router. It returns once the event is queued or dropped.
+func (d *EventDispatcher) Handle(ctx context.Context, event EventPayload) {
+	d.HandleAck(ctx, event, nil)
+}
+
+// HandleAck is Handle for subscribers that acknowledge events: the worker calls ack after the
+// handler returned. Events that are dropped, or still queued when the workers stop, are never
+// acknowledged, so the subscriber can deliver them again.
+func (d *EventDispatcher) HandleAck(ctx context.Context, event EventPayload, ack func()) {
+	queue := d.queues[d.workerFor(event)]
+	item := dispatchItem{event: event, ack: ack}
+
+	select {
+	case queue <- item:
+		d.queued(1)
+		return
+	default:
+	}
+
+	// Full: hold the subscriber back instead of piling events up
+	if d.metrics != nil {
+		d.metrics.IncDispatchBlocked()
+	}
+	timer := time.NewTimer(d.blockTimeout)
+	defer timer.Stop()
+
+	select {
+	case queue <- item:
+		d.queued(1)
+	case <-timer.C:
+		d.logger.Warn("Dispatch queue full, dropping event",
+			zap.String("event_id", event.EventID),
+			zap.String("aggregate_type", event.AggregateType),
+			zap.Duration("waited", d.blockTimeout),
+		)
+		if d.metrics != nil {
+			d.metrics.IncDispatchDropped()
+		}
+	case <-ctx.Done():
+	}
+}
+
+// QueueDepth returns the number of events waiting for a worker
+func (d *EventDispatcher) QueueDepth() int {
+	return int(d.depth.Load())
+}
+
+// workerFor picks the worker of the event's conversation, falling back to its aggregate
+func (d *EventDispatcher) workerFor(event EventPayload) int {
+	if len(d.queues) == 1 {
+		return 0
+	}
+	key := event.AggregateID
+	var payload dispatchKey
+	if err := json.Unmarshal(event.Payload, &payload); err == nil && payload.ConversationID != "" {
+		key = payload.ConversationID
+	}
+	return eventshard.Of(key, len(d.queues))
+}
+
+func (d *EventDispatcher) work(ctx context.Context, queue chan dispatchItem) {
+	defer d.wg.Done()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case item := <-queue:
+			d.queued(-1)
+			d.handler(ctx, item.event)
+			if item.ack != nil {
+				item.ack()
+			}
+		}
+	}
+}
+
+// queued adjusts the queue depth by delta and publishes it
+func (d *EventDispatcher) queued(delta int64) {
+	depth := d.depth.Add(delta)
+	if d.metrics != nil {
+		d.metrics.SetDispatchQueueDepth(int(depth))
+	}
+}
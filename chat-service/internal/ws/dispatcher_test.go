@@ -0,0 +1,198 @@
+package ws
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+type mockDispatcherMetrics struct {
+	depth   atomic.Int64
+	blocked atomic.Int64
+	dropped atomic.Int64
+}
+
+func (m *mockDispatcherMetrics) SetDispatchQueueDepth(depth int) { m.depth.Store(int64(depth)) }
+func (m *mockDispatcherMetrics) IncDispatchBlocked()             { m.blocked.Add(1) }
+func (m *mockDispatcherMetrics) IncDispatchDropped()             { m.dropped.Add(1) }
+
+func conversationEvent(eventID, conversationID string) EventPayload {
+	payload, _ := json.Marshal(InnerMessagePayload{EventType: "message.sent", ConversationID: conversationID})
+	return EventPayload{EventID: eventID, AggregateType: "message", AggregateID: eventID, Payload: payload}
+}
+
+func TestEventDispatcher_KeepsConversationOrder(t *testing.T) {
+	var mu sync.Mutex
+	received := make(map[string][]string)
+	var wg sync.WaitGroup
+
+	dispatcher := NewEventDispatcher(func(ctx context.Context, event EventPayload) {
+		defer wg.Done()
+		var payload InnerMessagePayload
+		_ = json.Unmarshal(event.Payload, &payload)
+		mu.Lock()
+		received[payload.ConversationID] = append(received[payload.ConversationID], event.EventID)
+		mu.Unlock()
+	}, zap.NewNop(), 4, 100, time.Second)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	dispatcher.Start(ctx)
+
+	conversations := []string{"conv-a", "conv-b", "conv-c", "conv-d", "conv-e"}
+	for i := 0; i < 50; i++ {
+		for _, conversationID := range conversations {
+			wg.Add(1)
+			dispatcher.Handle(ctx, conversationEvent(fmt.Sprintf("%s-%02d", conversationID, i), conversationID))
+		}
+	}
+	wg.Wait()
+
+	for _, conversationID := range conversations {
+		events := received[conversationID]
+		require.Len(t, events, 50)
+		for i, eventID := range events {
+			assert.Equal(t, fmt.Sprintf("%s-%02d", conversationID, i), eventID, "events of a conversation stay in order")
+		}
+	}
+	assert.Zero(t, dispatcher.QueueDepth())
+}
+
+// stuckHandler blocks every event until released
+type stuckHandler struct {
+	started chan struct{}
+	release chan struct{}
+	handled atomic.Int64
+}
+
+func newStuckHandler() *stuckHandler {
+	return &stuckHandler{started: make(chan struct{}, 10), release: make(chan struct{})}
+}
+
+func (h *stuckHandler) handle(ctx context.Context, event EventPayload) {
+	h.started <- struct{}{}
+	<-h.release
+	h.handled.Add(1)
+}
+
+func TestEventDispatcher_FullQueue(t *testing.T) {
+	// One worker with room for one queued event; the handler is stuck until released
+	newDispatcher := func(t *testing.T, blockTimeout time.Duration) (*EventDispatcher, *stuckHandler, *mockDispatcherMetrics) {
+		handler := newStuckHandler()
+		metrics := &mockDispatcherMetrics{}
+		dispatcher := NewEventDispatcher(handler.handle, zap.NewNop(), 1, 1, blockTimeout)
+		dispatcher.SetMetrics(metrics)
+
+		ctx, cancel := context.WithCancel(context.Background())
+		t.Cleanup(cancel)
+		dispatcher.Start(ctx)
+
+		dispatcher.Handle(ctx, conversationEvent("event-1", "conv-1"))
+		<-handler.started // the worker holds event-1
+		dispatcher.Handle(ctx, conversationEvent("event-2", "conv-1"))
+		return dispatcher, handler, metrics
+	}
+
+	t.Run("drops after the block timeout", func(t *testing.T) {
+		dispatcher, handler, metrics := newDispatcher(t, 20*time.Millisecond)
+		assert.Equal(t, 1, dispatcher.QueueDepth())
+		assert.Equal(t, int64(1), metrics.depth.Load())
+
+		begin := time.Now()
+		dispatcher.Handle(context.Background(), conversationEvent("event-3", "conv-1"))
+		assert.GreaterOrEqual(t, time.Since(begin), 20*time.Millisecond, "the subscriber is held back first")
+		assert.Equal(t, int64(1), metrics.blocked.Load())
+		assert.Equal(t, int64(1), metrics.dropped.Load())
+		assert.Equal(t, 1, dispatcher.QueueDepth(), "the queue never grows past its size")
+
+		close(handler.release)
+		assert.Eventually(t, func() bool { return handler.handled.Load() == 2 }, time.Second, 5*time.Millisecond)
+		assert.Zero(t, dispatcher.QueueDepth())
+		assert.Zero(t, metrics.depth.Load())
+	})
+
+	t.Run("queues once room frees up within the timeout", func(t *testing.T) {
+		dispatcher, handler, metrics := newDispatcher(t, time.Second)
+
+		go func() {
+			time.Sleep(20 * time.Millisecond)
+			handler.release <- struct{}{} // event-1 finishes, the worker takes event-2
+		}()
+		dispatcher.Handle(context.Background(), conversationEvent("event-3", "conv-1"))
+		assert.Equal(t, int64(1), metrics.blocked.Load())
+		assert.Zero(t, metrics.dropped.Load())
+
+		close(handler.release)
+		assert.Eventually(t, func() bool { return handler.handled.Load() == 3 }, time.Second, 5*time.Millisecond)
+	})
+}
+
+func TestEventDispatcher_HandleAck(t *testing.T) {
+	handler := newStuckHandler()
+	dispatcher := NewEventDispatcher(handler.handle, zap.NewNop(), 1, 1, 10*time.Millisecond)
+	ctx, cancel := context.WithCancel(context.Background())
+	dispatcher.Start(ctx)
+
+	var acked sync.Map
+	ack := func(id string) func() {
+		return func() { acked.Store(id, true) }
+	}
+	isAcked := func(id string) bool {
+		_, ok := acked.Load(id)
+		return ok
+	}
+
+	dispatcher.HandleAck(ctx, conversationEvent("event-1", "conv-1"), ack("event-1"))
+	<-handler.started
+	assert.False(t, isAcked("event-1"), "acknowledged only after the handler returns")
+	dispatcher.HandleAck(ctx, conversationEvent("event-2", "conv-1"), ack("event-2"))
+	dispatcher.HandleAck(ctx, conversationEvent("event-3", "conv-1"), ack("event-3")) // dropped: the queue is full
+
+	handler.release <- struct{}{}
+	assert.Eventually(t, func() bool { return isAcked("event-1") }, time.Second, 5*time.Millisecond)
+
+	// event-2 is being handled when the workers stop; nothing else is acknowledged
+	<-handler.started
+	cancel()
+	close(handler.release)
+	dispatcher.Wait()
+	assert.True(t, isAcked("event-2"))
+	assert.False(t, isAcked("event-3"))
+}
+
+func TestEventDispatcher_StopsWithContext(t *testing.T) {
+	dispatcher := NewEventDispatcher(func(ctx context.Context, event EventPayload) {}, zap.NewNop(), 2, 1, time.Minute)
+	ctx, cancel := context.WithCancel(context.Background())
+	dispatcher.Start(ctx)
+	cancel()
+	dispatcher.Wait()
+
+	// Nothing drains the queues anymore: a full queue gives up on the cancelled context, not the timeout
+	done := make(chan struct{})
+	go func() {
+		for i := 0; i < 5; i++ {
+			dispatcher.Handle(ctx, conversationEvent("event", "conv-1"))
+		}
+		close(done)
+	}()
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatal("Handle blocked after the dispatcher stopped")
+	}
+}
+
+func TestNewEventDispatcher_Defaults(t *testing.T) {
+	dispatcher := NewEventDispatcher(nil, zap.NewNop(), 0, -1, 0)
+	assert.Len(t, dispatcher.queues, DefaultDispatchWorkers)
+	assert.Equal(t, DefaultDispatchQueueSize, cap(dispatcher.queues[0]))
+	assert.Equal(t, DefaultDispatchBlockTimeout, dispatcher.blockTimeout)
+}
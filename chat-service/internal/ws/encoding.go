@@ -0,0 +1,107 @@
+package ws
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"net/http"
+
+	"github.com/gorilla/websocket"
+	"github.com/vmihailenco/msgpack/v5"
+)
+
+const (
+	// EncodingJSON sends events as JSON text frames (the default)
+	EncodingJSON = "json"
+	// EncodingMsgpack sends events as MessagePack binary frames: the same maps, keys and
+	// values as the JSON events, with integers kept as integers
+	EncodingMsgpack = "msgpack"
+	// EncodingQueryParam selects the encoding for clients that cannot offer subprotocols
+	EncodingQueryParam = "encoding"
+)
+
+// ErrUnsupportedEncoding is returned for an encoding query parameter other than json or msgpack
+var ErrUnsupportedEncoding = errors.New("unsupported frame encoding")
+
+// EncodingFromRequest returns the frame encoding of a /ws upgrade request.
+// Priority: 1) the first json or msgpack subprotocol offered in Sec-WebSocket-Protocol,
+// 2) the encoding query parameter, 3) JSON. Other subprotocols are ignored.
+// When the encoding came from a subprotocol, that subprotocol is returned too:
+// the upgrade response must select it or browsers fail the connection.
+func EncodingFromRequest(r *http.Request) (encoding, subprotocol string, err error) {
+	for _, protocol := range websocket.Subprotocols(r) {
+		if protocol == EncodingJSON || protocol == EncodingMsgpack {
+			return protocol, protocol, nil
+		}
+	}
+
+	switch encoding := r.URL.Query().Get(EncodingQueryParam); encoding {
+	case "", EncodingJSON:
+		return EncodingJSON, "", nil
+	case EncodingMsgpack:
+		return EncodingMsgpack, "", nil
+	default:
+		return "", "", ErrUnsupportedEncoding
+	}
+}
+
+// EncodeFrame converts an event, queued as JSON, to the client's encoding.
+// It returns the WebSocket message type to write the frame with.
+func EncodeFrame(encoding string, data []byte) (int, []byte, error) {
+	if encoding != EncodingMsgpack {
+		return websocket.TextMessage, data, nil
+	}
+
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.UseNumber()
+	var v interface{}
+	if err := dec.Decode(&v); err != nil {
+		return 0, nil, err
+	}
+
+	var buf bytes.Buffer
+	enc := msgpack.NewEncoder(&buf)
+	enc.UseCompactInts(true)
+	enc.UseCompactFloats(true)
+	enc.SetSortMapKeys(true)
+	if err := enc.Encode(msgpackValue(v)); err != nil {
+		return 0, nil, err
+	}
+	return websocket.BinaryMessage, buf.Bytes(), nil
+}
+
+// DecodeFrame converts a frame received from a client in its encoding to JSON, which
+// is what the router reads. MessagePack frames must hold a map with string keys.
+func DecodeFrame(encoding string, data []byte) ([]byte, error) {
+	if encoding != EncodingMsgpack {
+		return data, nil
+	}
+
+	var v interface{}
+	if err := msgpack.Unmarshal(data, &v); err != nil {
+		return nil, err
+	}
+	return json.Marshal(v)
+}
+
+// msgpackValue replaces the JSON numbers in v with int64 where they are whole numbers
+// (ids, timestamps in milliseconds, counts) and float64 otherwise
+func msgpackValue(v interface{}) interface{} {
+	switch v := v.(type) {
+	case json.Number:
+		if n, err := v.Int64(); err == nil {
+			return n
+		}
+		f, _ := v.Float64()
+		return f
+	case map[string]interface{}:
+		for key, value := range v {
+			v[key] = msgpackValue(value)
+		}
+	case []interface{}:
+		for i, value := range v {
+			v[i] = msgpackValue(value)
+		}
+	}
+	return v
+}
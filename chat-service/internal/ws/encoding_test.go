@@ -0,0 +1,112 @@
+package ws
+
+import (
+	"encoding/json"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gorilla/websocket"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/vmihailenco/msgpack/v5"
+)
+
+func TestEncodingFromRequest(t *testing.T) {
+	tests := []struct {
+		name            string
+		protocols       string
+		query           string
+		want            string
+		wantSubprotocol string
+		wantErr         bool
+	}{
+		{name: "default", want: EncodingJSON},
+		{name: "query", query: "msgpack", want: EncodingMsgpack},
+		{name: "query json", query: "json", want: EncodingJSON},
+		{name: "subprotocol", protocols: "msgpack", want: EncodingMsgpack, wantSubprotocol: "msgpack"},
+		{name: "first known subprotocol wins", protocols: "chat.v2, json, msgpack", want: EncodingJSON, wantSubprotocol: "json"},
+		{name: "subprotocol takes precedence", protocols: "msgpack", query: "json", want: EncodingMsgpack, wantSubprotocol: "msgpack"},
+		{name: "unknown subprotocols fall back to the query", protocols: "chat.v2", query: "msgpack", want: EncodingMsgpack},
+		{name: "unsupported query", query: "cbor", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := httptest.NewRequest("GET", "/ws", nil)
+			if tt.query != "" {
+				q := r.URL.Query()
+				q.Set(EncodingQueryParam, tt.query)
+				r.URL.RawQuery = q.Encode()
+			}
+			if tt.protocols != "" {
+				r.Header.Set("Sec-WebSocket-Protocol", tt.protocols)
+			}
+
+			encoding, subprotocol, err := EncodingFromRequest(r)
+			if tt.wantErr {
+				assert.ErrorIs(t, err, ErrUnsupportedEncoding)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, encoding)
+			assert.Equal(t, tt.wantSubprotocol, subprotocol)
+		})
+	}
+}
+
+func TestEncodeFrame_JSONPassesThrough(t *testing.T) {
+	data := []byte(`{"type":"welcome"}`)
+	for _, encoding := range []string{"", EncodingJSON} {
+		messageType, frame, err := EncodeFrame(encoding, data)
+		require.NoError(t, err)
+		assert.Equal(t, websocket.TextMessage, messageType)
+		assert.Equal(t, data, frame)
+	}
+}
+
+func TestEncodeFrame_Msgpack(t *testing.T) {
+	data := []byte(`{"type":"message","server_time":1735732800123,"ratio":0.5,"receiver_ids":["a","b"],"payload":{"unread_count":3,"slim":true,"media_url":null}}`)
+
+	messageType, frame, err := EncodeFrame(EncodingMsgpack, data)
+	require.NoError(t, err)
+	assert.Equal(t, websocket.BinaryMessage, messageType)
+	assert.Less(t, len(frame), len(data))
+
+	var event map[string]interface{}
+	require.NoError(t, msgpack.Unmarshal(frame, &event))
+	assert.Equal(t, "message", event["type"])
+	assert.EqualValues(t, 1735732800123, event["server_time"], "timestamps stay integers")
+	assert.Equal(t, 0.5, event["ratio"])
+	assert.Equal(t, []interface{}{"a", "b"}, event["receiver_ids"])
+
+	payload := event["payload"].(map[string]interface{})
+	assert.EqualValues(t, 3, payload["unread_count"])
+	assert.Equal(t, true, payload["slim"])
+	assert.Contains(t, payload, "media_url")
+	assert.Nil(t, payload["media_url"])
+
+	_, _, err = EncodeFrame(EncodingMsgpack, []byte(`{"type":`))
+	assert.Error(t, err)
+}
+
+func TestDecodeFrame(t *testing.T) {
+	frame, err := msgpack.Marshal(map[string]interface{}{
+		"type":            "typing",
+		"conversation_id": "conv-1",
+	})
+	require.NoError(t, err)
+
+	data, err := DecodeFrame(EncodingMsgpack, frame)
+	require.NoError(t, err)
+	var msg TypingMessage
+	require.NoError(t, json.Unmarshal(data, &msg))
+	assert.Equal(t, TypingMessage{Type: EventTypeTyping, ConversationID: "conv-1"}, msg)
+
+	text := []byte(`{"type":"typing_stopped"}`)
+	data, err = DecodeFrame(EncodingJSON, text)
+	require.NoError(t, err)
+	assert.Equal(t, text, data, "JSON frames are read as they are")
+
+	_, err = DecodeFrame(EncodingMsgpack, []byte{0xc1})
+	assert.Error(t, err)
+}
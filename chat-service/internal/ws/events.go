@@ -14,6 +14,14 @@ const (
 
 	// EventTypeMessage is for chat message events from Redis Pub/Sub.
 	EventTypeMessage = "message"
+
+	// EventTypeServerDraining is sent when the gateway starts shutting down.
+	// Client should reconnect after reconnect_after_ms; the connection is closed shortly after.
+	EventTypeServerDraining = "server_draining"
+
+	// EventTypeUnreadSnapshot is sent right after welcome/reconnected with the unread counts of the
+	// user's first conversations, so badges render without a GetConversations call.
+	EventTypeUnreadSnapshot = "unread_snapshot"
 )
 
 // WelcomeEvent is sent to client upon successful WebSocket connection.
@@ -35,6 +43,25 @@ type ReconnectedEvent struct {
 	InstanceID     string `json:"instance_id"`
 }
 
+// ServerDrainingEvent advises the client to reconnect (to another gateway) after a jittered delay.
+type ServerDrainingEvent struct {
+	Type             string `json:"type"`
+	ServerTime       int64  `json:"server_time"`
+	ReconnectAfterMs int64  `json:"reconnect_after_ms"` // Wait this long before reconnecting
+	InstanceID       string `json:"instance_id"`
+}
+
+// UnreadSnapshotEvent carries unread counts as of server_time. Message events created
+// before server_time are already counted; truncated means the user has more conversations.
+type UnreadSnapshotEvent struct {
+	Type          string               `json:"type"`
+	ServerTime    int64                `json:"server_time"`
+	Conversations []ConversationUnread `json:"conversations"`
+	TotalUnread   int                  `json:"total_unread"` // Sum over conversations
+	Truncated     bool                 `json:"truncated"`
+	InstanceID    string               `json:"instance_id"`
+}
+
 // NewWelcomeEvent creates a welcome event for a new connection.
 func NewWelcomeEvent(userID string) *WelcomeEvent {
 	return &WelcomeEvent{
@@ -57,3 +84,32 @@ func NewReconnectedEvent(userID string, previousConnAt time.Time) *ReconnectedEv
 		InstanceID:     GetInstanceID(),
 	}
 }
+
+// NewServerDrainingEvent creates a drain advisory asking the client to wait reconnectAfter.
+func NewServerDrainingEvent(reconnectAfter time.Duration) *ServerDrainingEvent {
+	return &ServerDrainingEvent{
+		Type:             EventTypeServerDraining,
+		ServerTime:       time.Now().UnixMilli(),
+		ReconnectAfterMs: reconnectAfter.Milliseconds(),
+		InstanceID:       GetInstanceID(),
+	}
+}
+
+// NewUnreadSnapshotEvent creates an unread snapshot of counts taken at asOf.
+func NewUnreadSnapshotEvent(asOf time.Time, counts []ConversationUnread, truncated bool) *UnreadSnapshotEvent {
+	if counts == nil {
+		counts = []ConversationUnread{}
+	}
+	total := 0
+	for _, c := range counts {
+		total += c.UnreadCount
+	}
+	return &UnreadSnapshotEvent{
+		Type:          EventTypeUnreadSnapshot,
+		ServerTime:    asOf.UnixMilli(),
+		Conversations: counts,
+		TotalUnread:   total,
+		Truncated:     truncated,
+		InstanceID:    GetInstanceID(),
+	}
+}
@@ -0,0 +1,69 @@
+package ws
+
+import (
+	"context"
+
+	"go.uber.org/zap"
+)
+
+// StreamHub lets non-WebSocket transports (the gRPC StreamEvents RPC) receive the
+// same events as WebSocket clients. Each subscriber is registered in the
+// ConnectionManager as a client without a connection, so the Router fan-out,
+// per-connection dedup and slow-client eviction apply unchanged.
+type StreamHub struct {
+	manager    *ConnectionManager
+	shards     *ShardedSubscriber
+	logger     *zap.Logger
+	bufferSize int
+}
+
+// NewStreamHub creates a hub on top of the manager fed by a Router.
+// Non-positive bufferSize uses DefaultSendBufferSize.
+func NewStreamHub(manager *ConnectionManager, logger *zap.Logger, bufferSize int) *StreamHub {
+	return &StreamHub{
+		manager:    manager,
+		logger:     logger,
+		bufferSize: bufferSize,
+	}
+}
+
+// SetShardedSubscriber makes subscriptions follow the conversation shards of their user
+func (h *StreamHub) SetShardedSubscriber(shards *ShardedSubscriber) {
+	h.shards = shards
+}
+
+// Subscribe registers a device of userID and returns the channel its events are delivered on,
+// plus a function that unregisters it. The channel is closed when the subscription
+// is replaced by a newer one for the same device or evicted as a slow consumer.
+func (h *StreamHub) Subscribe(ctx context.Context, userID, deviceID string) (<-chan []byte, func()) {
+	client := NewClientWithBufferSize(nil, h.bufferSize)
+	client.DeviceID = deviceID
+	h.manager.Add(userID, client)
+
+	if h.shards != nil {
+		if err := h.shards.AddUser(ctx, userID); err != nil {
+			h.logger.Warn("Failed to subscribe conversation shards for stream",
+				zap.String("user_id", userID),
+				zap.Error(err),
+			)
+		}
+	}
+
+	unsubscribe := func() {
+		h.manager.Remove(userID, client)
+		if h.shards != nil {
+			h.shards.RemoveUser(userID)
+		}
+	}
+	return client.Send, unsubscribe
+}
+
+// Close ends every subscription so open streams return and clients reconnect elsewhere.
+// Call it before a graceful server stop, which otherwise waits for streams to finish.
+func (h *StreamHub) Close() {
+	for userID, clients := range h.manager.GetAllClients() {
+		for _, client := range clients {
+			h.manager.Remove(userID, client)
+		}
+	}
+}
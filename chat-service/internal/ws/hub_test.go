@@ -0,0 +1,104 @@
+package ws
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+func routedEvent(t *testing.T, eventID string, receiverIDs ...string) EventPayload {
+	t.Helper()
+	inner, err := json.Marshal(InnerMessagePayload{
+		EventType:      "message.sent",
+		ConversationID: "conv-1",
+		SenderID:       "sender",
+		ReceiverIDs:    receiverIDs,
+	})
+	require.NoError(t, err)
+	return EventPayload{EventID: eventID, AggregateType: "message", AggregateID: "msg-1", Payload: inner}
+}
+
+// TestStreamHub_ReceivesRoutedEvents verifies stream subscribers share the Router fan-out
+func TestStreamHub_ReceivesRoutedEvents(t *testing.T) {
+	require := require.New(t)
+
+	manager := NewConnectionManager()
+	router := NewRouter(manager, zap.NewNop(), nil)
+	hub := NewStreamHub(manager, zap.NewNop(), 4)
+
+	events, unsubscribe := hub.Subscribe(context.Background(), "user-1", "")
+	defer unsubscribe()
+
+	router.HandleEvent(context.Background(), routedEvent(t, "evt-1", "user-1", ""))
+	router.HandleEvent(context.Background(), routedEvent(t, "evt-1", "user-1", "")) // re-published duplicate
+	router.HandleEvent(context.Background(), routedEvent(t, "evt-2", "user-2", ""))
+
+	select {
+	case data := <-events:
+		var event EventPayload
+		require.NoError(json.Unmarshal(data, &event))
+		require.Equal("evt-1", event.EventID)
+	case <-time.After(time.Second):
+		t.Fatal("expected routed event")
+	}
+	require.Empty(events, "duplicates and other users' events must not be delivered")
+}
+
+// TestStreamHub_UnsubscribeCleansUp verifies the subscription is removed and its channel closed
+func TestStreamHub_UnsubscribeCleansUp(t *testing.T) {
+	require := require.New(t)
+
+	manager := NewConnectionManager()
+	hub := NewStreamHub(manager, zap.NewNop(), 4)
+
+	events, unsubscribe := hub.Subscribe(context.Background(), "user-1", "")
+	require.Equal(1, manager.Count())
+
+	unsubscribe()
+	require.Zero(manager.Count())
+
+	_, open := <-events
+	require.False(open)
+}
+
+// TestStreamHub_NewerSubscriptionReplacesOlder verifies a second stream for the same user closes the first
+func TestStreamHub_NewerSubscriptionReplacesOlder(t *testing.T) {
+	require := require.New(t)
+
+	manager := NewConnectionManager()
+	hub := NewStreamHub(manager, zap.NewNop(), 4)
+
+	first, unsubscribeFirst := hub.Subscribe(context.Background(), "user-1", "")
+	_, unsubscribeSecond := hub.Subscribe(context.Background(), "user-1", "")
+	defer unsubscribeSecond()
+
+	_, open := <-first
+	require.False(open)
+
+	// The stale unsubscribe must not remove the newer subscription
+	unsubscribeFirst()
+	require.Equal(1, manager.Count())
+}
+
+// TestStreamHub_CloseEndsAllSubscriptions verifies Close releases every open stream
+func TestStreamHub_CloseEndsAllSubscriptions(t *testing.T) {
+	require := require.New(t)
+
+	manager := NewConnectionManager()
+	hub := NewStreamHub(manager, zap.NewNop(), 4)
+
+	first, _ := hub.Subscribe(context.Background(), "user-1", "")
+	second, _ := hub.Subscribe(context.Background(), "user-2", "")
+
+	hub.Close()
+	require.Zero(manager.Count())
+
+	_, open := <-first
+	require.False(open)
+	_, open = <-second
+	require.False(open)
+}
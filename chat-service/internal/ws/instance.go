@@ -1,31 +1,99 @@
 package ws
 
 import (
+	"fmt"
 	"os"
+	"strings"
 	"sync"
 
 	"github.com/google/uuid"
 )
 
+// Instance ID strategies (WS_INSTANCE_ID_STRATEGY)
+const (
+	// InstanceIDAuto uses WS_GATEWAY_INSTANCE_ID when set, otherwise a short random ID (the default)
+	InstanceIDAuto = "auto"
+	// InstanceIDEnv requires WS_GATEWAY_INSTANCE_ID, so a missing value fails startup instead of going unnoticed
+	InstanceIDEnv = "env"
+	// InstanceIDHostname uses the hostname, e.g. the pod name of a StatefulSet
+	InstanceIDHostname = "hostname"
+	// InstanceIDUUID generates a full UUID on every start; unique, but never stable across restarts
+	InstanceIDUUID = "uuid"
+)
+
+const instanceIDEnv = "WS_GATEWAY_INSTANCE_ID"
+
 var (
 	instanceID     string
 	instanceIDOnce sync.Once
+
+	// hostname is swapped in tests
+	hostname = os.Hostname
 )
 
 // GetInstanceID returns a unique identifier for this gateway instance.
 // The ID is generated once and cached for the lifetime of the process.
 // It can be overridden by setting the WS_GATEWAY_INSTANCE_ID environment variable.
+// Processes that call ConfigureInstanceID at startup get the configured ID instead.
 func GetInstanceID() string {
 	instanceIDOnce.Do(func() {
-		if id := os.Getenv("WS_GATEWAY_INSTANCE_ID"); id != "" {
-			instanceID = id
-		} else {
-			instanceID = uuid.New().String()[:8] // Short UUID for readability
-		}
+		instanceID = autoInstanceID()
 	})
 	return instanceID
 }
 
+// ConfigureInstanceID fixes the instance ID with the given strategy ("" means auto) and returns it.
+// Call it at startup before anything reads GetInstanceID: consumer groups, presence, metrics labels and
+// logs must all see the same ID, so an ID that was already handed out is never replaced.
+func ConfigureInstanceID(strategy string) (string, error) {
+	id, err := resolveInstanceID(strategy)
+	if err != nil {
+		return "", err
+	}
+	instanceIDOnce.Do(func() {
+		instanceID = id
+	})
+	if instanceID != id && strategy != "" && strategy != InstanceIDAuto {
+		return "", fmt.Errorf("instance id already in use as %q before strategy %q was applied", instanceID, strategy)
+	}
+	return instanceID, nil
+}
+
+// resolveInstanceID computes the ID for a strategy without caching it
+func resolveInstanceID(strategy string) (string, error) {
+	switch strategy {
+	case "", InstanceIDAuto:
+		return autoInstanceID(), nil
+	case InstanceIDEnv:
+		id := strings.TrimSpace(os.Getenv(instanceIDEnv))
+		if id == "" {
+			return "", fmt.Errorf("%s is required when the instance id strategy is %q", instanceIDEnv, InstanceIDEnv)
+		}
+		return id, nil
+	case InstanceIDHostname:
+		name, err := hostname()
+		if err != nil {
+			return "", fmt.Errorf("failed to read hostname: %w", err)
+		}
+		name = strings.TrimSpace(name)
+		if name == "" {
+			return "", fmt.Errorf("hostname is empty")
+		}
+		return name, nil
+	case InstanceIDUUID:
+		return uuid.New().String(), nil
+	default:
+		return "", fmt.Errorf("unknown instance id strategy %q (expected auto, env, hostname or uuid)", strategy)
+	}
+}
+
+func autoInstanceID() string {
+	if id := os.Getenv(instanceIDEnv); id != "" {
+		return id
+	}
+	return uuid.New().String()[:8] // Short UUID for readability
+}
+
 // ResetInstanceID resets the instance ID (for testing purposes only).
 func ResetInstanceID() {
 	instanceIDOnce = sync.Once{}
@@ -69,3 +69,65 @@ func TestResetInstanceID(t *testing.T) {
 	// For a more robust test, we'd mock the UUID generator
 	assert.NotEmpty(t, id2)
 }
+
+func TestConfigureInstanceID_Strategies(t *testing.T) {
+	defer ResetInstanceID()
+	originalHostname := hostname
+	defer func() { hostname = originalHostname }()
+	hostname = func() (string, error) { return "ws-gateway-2", nil }
+
+	tests := []struct {
+		name     string
+		strategy string
+		env      string
+		want     string
+		wantLen  int
+		wantErr  bool
+	}{
+		{name: "auto with env", strategy: InstanceIDAuto, env: "gateway-1", want: "gateway-1"},
+		{name: "default without env", strategy: "", wantLen: 8},
+		{name: "env", strategy: InstanceIDEnv, env: "gateway-1", want: "gateway-1"},
+		{name: "env missing", strategy: InstanceIDEnv, wantErr: true},
+		{name: "hostname", strategy: InstanceIDHostname, env: "ignored", want: "ws-gateway-2"},
+		{name: "uuid", strategy: InstanceIDUUID, wantLen: 36},
+		{name: "unknown", strategy: "pod", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ResetInstanceID()
+			t.Setenv("WS_GATEWAY_INSTANCE_ID", tt.env)
+
+			id, err := ConfigureInstanceID(tt.strategy)
+			if tt.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			if tt.want != "" {
+				assert.Equal(t, tt.want, id)
+			}
+			if tt.wantLen != 0 {
+				assert.Len(t, id, tt.wantLen)
+			}
+			// Everything reading the accessor afterwards sees the configured ID
+			assert.Equal(t, id, GetInstanceID())
+		})
+	}
+}
+
+func TestConfigureInstanceID_NeverReplacesIssuedID(t *testing.T) {
+	ResetInstanceID()
+	defer ResetInstanceID()
+	t.Setenv("WS_GATEWAY_INSTANCE_ID", "")
+
+	issued := GetInstanceID()
+
+	_, err := ConfigureInstanceID(InstanceIDUUID)
+	assert.Error(t, err)
+	assert.Equal(t, issued, GetInstanceID())
+
+	id, err := ConfigureInstanceID(InstanceIDAuto)
+	require.NoError(t, err)
+	assert.Equal(t, issued, id)
+}
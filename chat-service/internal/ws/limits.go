@@ -0,0 +1,49 @@
+package ws
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"time"
+
+	"github.com/gorilla/websocket"
+)
+
+// frameOverheadBytes leaves room for the JSON envelope around message content
+const frameOverheadBytes = 4096
+
+// closeWriteWait bounds writing the close frame for an oversized message
+const closeWriteWait = time.Second
+
+// ErrMessageTooLarge is returned by ReadMessage for frames over the read limit
+var ErrMessageTooLarge = errors.New("websocket message exceeds read limit")
+
+// ReadLimitForContent returns the read limit for frames carrying up to maxContentBytes
+// of message content, so a frame is never rejected for content the API would accept.
+// JSON can escape each content byte to at most six bytes (\u00XX).
+func ReadLimitForContent(maxContentBytes int) int64 {
+	return 6*int64(maxContentBytes) + frameOverheadBytes
+}
+
+// ReadMessage reads the next data message from conn, buffering at most limit bytes.
+// A larger message gets a 1009 (message too big) close frame whose reason states the
+// limit, and ErrMessageTooLarge; the caller must then drop the connection.
+func ReadMessage(conn *websocket.Conn, limit int64) ([]byte, error) {
+	_, r, err := conn.NextReader()
+	if err != nil {
+		return nil, err
+	}
+
+	data, err := io.ReadAll(io.LimitReader(r, limit+1))
+	if err != nil {
+		return nil, err
+	}
+	if int64(len(data)) > limit {
+		reason := fmt.Sprintf("message exceeds %d bytes", limit)
+		_ = conn.WriteControl(websocket.CloseMessage,
+			websocket.FormatCloseMessage(websocket.CloseMessageTooBig, reason),
+			time.Now().Add(closeWriteWait))
+		return nil, ErrMessageTooLarge
+	}
+	return data, nil
+}
@@ -0,0 +1,73 @@
+package ws
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gorilla/websocket"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// readLimitServer echoes frames read with ReadMessage and reports the read error
+func readLimitServer(t *testing.T, limit int64, readErr chan<- error) *httptest.Server {
+	t.Helper()
+	upgrader := websocket.Upgrader{}
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		conn, err := upgrader.Upgrade(w, r, nil)
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+		for {
+			data, err := ReadMessage(conn, limit)
+			if err != nil {
+				readErr <- err
+				return
+			}
+			_ = conn.WriteMessage(websocket.TextMessage, data)
+		}
+	}))
+}
+
+func TestReadMessage_WithinLimit(t *testing.T) {
+	readErr := make(chan error, 1)
+	server := readLimitServer(t, 16, readErr)
+	defer server.Close()
+
+	conn, _, err := websocket.DefaultDialer.Dial("ws"+strings.TrimPrefix(server.URL, "http"), nil)
+	require.NoError(t, err)
+	defer conn.Close()
+
+	payload := strings.Repeat("a", 16)
+	require.NoError(t, conn.WriteMessage(websocket.TextMessage, []byte(payload)))
+	_, echoed, err := conn.ReadMessage()
+	require.NoError(t, err)
+	assert.Equal(t, payload, string(echoed))
+}
+
+func TestReadMessage_OverLimitSendsCloseFrame(t *testing.T) {
+	readErr := make(chan error, 1)
+	server := readLimitServer(t, 16, readErr)
+	defer server.Close()
+
+	conn, _, err := websocket.DefaultDialer.Dial("ws"+strings.TrimPrefix(server.URL, "http"), nil)
+	require.NoError(t, err)
+	defer conn.Close()
+
+	require.NoError(t, conn.WriteMessage(websocket.TextMessage, []byte(strings.Repeat("a", 17))))
+
+	_, _, err = conn.ReadMessage()
+	var closeErr *websocket.CloseError
+	require.ErrorAs(t, err, &closeErr)
+	assert.Equal(t, websocket.CloseMessageTooBig, closeErr.Code)
+	assert.Equal(t, "message exceeds 16 bytes", closeErr.Text)
+	assert.ErrorIs(t, <-readErr, ErrMessageTooLarge)
+}
+
+func TestReadLimitForContent(t *testing.T) {
+	// Content made only of control characters is escaped to \u00XX, six bytes per byte
+	assert.Equal(t, int64(6*100+frameOverheadBytes), ReadLimitForContent(100))
+}
@@ -2,7 +2,9 @@ package ws
 
 import (
 	"context"
+	"errors"
 	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/gorilla/websocket"
@@ -22,20 +24,78 @@ type Client struct {
 	// WaitGroup to track active goroutines (readPump, writePump)
 	wg sync.WaitGroup
 
+	// goroutines mirrors the WaitGroup counter so the reaper can spot connections
+	// whose pumps exited without removing them; started is set by the first AddGoroutine
+	goroutines atomic.Int32
+	started    atomic.Bool
+
+	// lastActivity is the Unix time in nanoseconds of the last frame or pong from the peer
+	lastActivity atomic.Int64
+
+	// released guards per-connection bookkeeping that must run exactly once
+	released atomic.Bool
+
 	// ConnectedAt is when this client connected (for gap sync)
 	ConnectedAt time.Time
+
+	// DeviceID identifies the user's device; each device keeps its own connection.
+	// Clients without one share the empty device and replace each other.
+	// Set it before adding the client to the ConnectionManager.
+	DeviceID string
+
+	// UserAgent and RemoteAddr come from the upgrade request (see SetRequestInfo); for logs and the admin dump
+	UserAgent  string
+	RemoteAddr string
+
+	// Encoding is the frame encoding negotiated at the handshake (see EncodingFromRequest).
+	// Send always carries JSON; the pumps convert to and from this encoding. Empty means JSON.
+	Encoding string
+
+	// recentEvents drops re-published outbox events already sent on this connection
+	recentEvents *eventIDRing
+
+	// reconnectAfter is the delay (ns) given in the drain advisory, repeated in the close frame
+	reconnectAfter atomic.Int64
 }
 
-// NewClient creates a new Client with a cancellable context.
+// DefaultSendBufferSize is the number of outgoing messages queued per client
+// before the client is treated as slow and disconnected.
+const DefaultSendBufferSize = 256
+
+// NewClient creates a new Client with a cancellable context and the default send buffer.
 func NewClient(conn *websocket.Conn) *Client {
+	return NewClientWithBufferSize(conn, DefaultSendBufferSize)
+}
+
+// NewClientWithBufferSize creates a new Client whose Send channel holds bufferSize messages.
+// A larger buffer rides out slow mobile networks; a smaller one drops dead connections sooner
+// and costs less memory per connection. Non-positive sizes use DefaultSendBufferSize.
+func NewClientWithBufferSize(conn *websocket.Conn, bufferSize int) *Client {
+	if bufferSize <= 0 {
+		bufferSize = DefaultSendBufferSize
+	}
+
 	ctx, cancel := context.WithCancel(context.Background())
-	return &Client{
+	client := &Client{
 		Conn:        conn,
-		Send:        make(chan []byte, 256), // Buffered channel for outgoing messages
+		Send:        make(chan []byte, bufferSize), // Buffered channel for outgoing messages
 		ctx:         ctx,
 		cancel:      cancel,
 		ConnectedAt: time.Now(),
+
+		recentEvents: newEventIDRing(recentEventIDsSize),
+	}
+	client.lastActivity.Store(client.ConnectedAt.UnixNano())
+	return client
+}
+
+// MarkDelivered records eventID for this connection and reports whether it is new.
+// Events without an ID are always treated as new.
+func (c *Client) MarkDelivered(eventID string) bool {
+	if eventID == "" || c.recentEvents == nil {
+		return true
 	}
+	return c.recentEvents.Add(eventID)
 }
 
 // Context returns the client's context for goroutine lifecycle management.
@@ -47,14 +107,41 @@ func (c *Client) Context() context.Context {
 // Call this before starting a goroutine for this client.
 func (c *Client) AddGoroutine() {
 	c.wg.Add(1)
+	c.goroutines.Add(1)
+	c.started.Store(true)
 }
 
 // DoneGoroutine decrements the WaitGroup counter.
 // Call this when a goroutine for this client exits.
 func (c *Client) DoneGoroutine() {
+	c.goroutines.Add(-1)
 	c.wg.Done()
 }
 
+// ActiveGoroutines returns the number of running goroutines registered with AddGoroutine.
+func (c *Client) ActiveGoroutines() int {
+	return int(c.goroutines.Load())
+}
+
+// Touch records activity from the peer (a received frame or pong).
+func (c *Client) Touch() {
+	c.lastActivity.Store(time.Now().UnixNano())
+}
+
+// LastActivity returns when the peer was last heard from; ConnectedAt until the first Touch.
+func (c *Client) LastActivity() time.Time {
+	if ns := c.lastActivity.Load(); ns != 0 {
+		return time.Unix(0, ns)
+	}
+	return c.ConnectedAt
+}
+
+// Release reports true on its first call only. Connection bookkeeping (metrics,
+// subscriptions) runs behind it, so it happens once whether the pumps or the reaper clean up.
+func (c *Client) Release() bool {
+	return c.released.CompareAndSwap(false, true)
+}
+
 // Wait blocks until all goroutines for this client have exited.
 func (c *Client) Wait() {
 	c.wg.Wait()
@@ -76,6 +163,28 @@ func (c *Client) Close() {
 	}
 }
 
+// Errors of TrySend
+var (
+	ErrClientClosed   = errors.New("client closed")
+	ErrSendBufferFull = errors.New("client send buffer full")
+)
+
+// TrySend queues message without blocking. It holds the client lock, so a concurrent Close
+// cannot close Send between the closed check and the send.
+func (c *Client) TrySend(message []byte) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.closed {
+		return ErrClientClosed
+	}
+	select {
+	case c.Send <- message:
+		return nil
+	default:
+		return ErrSendBufferFull
+	}
+}
+
 // IsClosed returns whether the client is closed.
 func (c *Client) IsClosed() bool {
 	c.mu.Lock()
@@ -83,178 +192,241 @@ func (c *Client) IsClosed() bool {
 	return c.closed
 }
 
-// userHistory tracks when a user was last connected (for reconnection detection).
+// deviceKey identifies one device of a user.
+type deviceKey struct {
+	userID   string
+	deviceID string
+}
+
+// userHistory tracks when a device was last connected (for reconnection detection).
 type userHistory struct {
 	lastConnectedAt    time.Time
 	lastDisconnectedAt time.Time
 }
 
 // ConnectionManager handles WebSocket connections for users.
+// A user may be connected from several devices at once; each device holds at most one connection.
 // It is thread-safe.
 type ConnectionManager struct {
-	mu          sync.RWMutex
-	connections map[string]*Client
-	// history tracks user connection history for reconnection detection
-	history map[string]*userHistory
+	mu sync.RWMutex
+	// connections maps user ID to that user's connections by device ID
+	connections map[string]map[string]*Client
+	// count is the total number of connections across users and devices
+	count int
+	// history tracks device connection history for reconnection detection
+	history map[deviceKey]*userHistory
 }
 
 // NewConnectionManager creates a new ConnectionManager.
 func NewConnectionManager() *ConnectionManager {
 	return &ConnectionManager{
-		connections: make(map[string]*Client),
-		history:     make(map[string]*userHistory),
+		connections: make(map[string]map[string]*Client),
+		history:     make(map[deviceKey]*userHistory),
 	}
 }
 
 // AddResult contains information about the Add operation.
 type AddResult struct {
-	// IsReconnect is true if there was a previous connection for this user.
+	// IsReconnect is true if there was a previous connection for this device.
 	IsReconnect bool
 	// PreviousConnectedAt is when the previous connection was established.
 	// Only valid if IsReconnect is true.
 	PreviousConnectedAt time.Time
 }
 
-// Add adds a client for a user.
-// If a client already exists, it is closed and replaced.
+// Add adds a client for a user on the client's device.
+// If the device already has a client, it is closed and replaced; other devices are kept.
 // Returns AddResult with reconnection information.
 func (cm *ConnectionManager) Add(userID string, client *Client) AddResult {
 	cm.mu.Lock()
 	defer cm.mu.Unlock()
 
 	result := AddResult{}
+	key := deviceKey{userID: userID, deviceID: client.DeviceID}
+
+	devices, ok := cm.connections[userID]
+	if !ok {
+		devices = make(map[string]*Client)
+		cm.connections[userID] = devices
+	}
 
 	// Check if there's an existing active connection (replace scenario)
-	if oldClient, ok := cm.connections[userID]; ok {
+	if oldClient, ok := devices[client.DeviceID]; ok {
 		result.IsReconnect = true
 		result.PreviousConnectedAt = oldClient.ConnectedAt
 		oldClient.Close()
 		if oldClient.Conn != nil {
 			_ = oldClient.Conn.Close()
 		}
-	} else if hist, ok := cm.history[userID]; ok {
-		// User had a previous connection that was closed - this is a reconnect
+		cm.count--
+	} else if hist, ok := cm.history[key]; ok {
+		// Device had a previous connection that was closed - this is a reconnect
 		result.IsReconnect = true
 		result.PreviousConnectedAt = hist.lastConnectedAt
 	}
 
 	// Update history
-	cm.history[userID] = &userHistory{
+	cm.history[key] = &userHistory{
 		lastConnectedAt: client.ConnectedAt,
 	}
 
-	cm.connections[userID] = client
+	devices[client.DeviceID] = client
+	cm.count++
 	return result
 }
 
-// Remove removes the client for a user and performs graceful cleanup.
-// It checks if the client being removed is the current one to avoid race conditions.
-// The cleanup process:
-// 1. Remove from map (so no new messages are sent)
-// 2. Close client (cancel context, close send channel)
-// 3. Close WebSocket connection (triggers goroutines to exit)
-// Note: This does NOT wait for goroutines - they will exit on their own
-func (cm *ConnectionManager) Remove(userID string, client *Client) {
-	cm.mu.Lock()
-
-	currentClient, ok := cm.connections[userID]
+// detach removes the given client (or, when client is nil, every client of the user)
+// from the map and returns the removed clients. Callers must hold cm.mu.
+func (cm *ConnectionManager) detach(userID string, client *Client) []*Client {
+	devices, ok := cm.connections[userID]
 	if !ok {
-		cm.mu.Unlock()
-		return
+		return nil
 	}
 
-	// If a specific client is provided, only remove if it matches
-	if client != nil && currentClient != client {
-		cm.mu.Unlock()
-		return
+	var removed []*Client
+	for deviceID, current := range devices {
+		// If a specific client is provided, only remove if it matches
+		if client != nil && current != client {
+			continue
+		}
+		delete(devices, deviceID)
+		removed = append(removed, current)
+
+		// Update history with disconnect time
+		if hist, ok := cm.history[deviceKey{userID: userID, deviceID: deviceID}]; ok {
+			hist.lastDisconnectedAt = time.Now()
+		}
 	}
 
-	// Update history with disconnect time
-	if hist, ok := cm.history[userID]; ok {
-		hist.lastDisconnectedAt = time.Now()
+	cm.count -= len(removed)
+	if len(devices) == 0 {
+		delete(cm.connections, userID)
 	}
+	return removed
+}
 
+// Remove removes the client for a user and performs graceful cleanup.
+// It checks if the client being removed is the current one for its device to avoid
+// race conditions; a nil client removes every device of the user.
+// The cleanup process:
+// 1. Remove from map (so no new messages are sent)
+// 2. Close client (cancel context, close send channel)
+// 3. Close WebSocket connection (triggers goroutines to exit)
+// Note: This does NOT wait for goroutines - they will exit on their own
+// Returns whether a client was removed.
+func (cm *ConnectionManager) Remove(userID string, client *Client) bool {
+	cm.mu.Lock()
 	// Remove from map first (prevents new messages from being queued)
-	delete(cm.connections, userID)
+	removed := cm.detach(userID, client)
 	cm.mu.Unlock()
 
-	// Close client (signals goroutines to stop via context cancellation)
-	currentClient.Close()
+	for _, current := range removed {
+		// Close client (signals goroutines to stop via context cancellation)
+		current.Close()
 
-	// Close WebSocket connection (triggers read/write errors in goroutines)
-	if currentClient.Conn != nil {
-		_ = currentClient.Conn.Close()
+		// Close WebSocket connection (triggers read/write errors in goroutines)
+		if current.Conn != nil {
+			_ = current.Conn.Close()
+		}
 	}
+	return len(removed) > 0
 }
 
 // RemoveAndWait removes the client and waits for all goroutines to exit.
 // Use this for graceful shutdown scenarios where you need to ensure cleanup is complete.
 func (cm *ConnectionManager) RemoveAndWait(userID string, client *Client) {
 	cm.mu.Lock()
+	removed := cm.detach(userID, client)
+	cm.mu.Unlock()
 
-	currentClient, ok := cm.connections[userID]
-	if !ok {
-		cm.mu.Unlock()
-		return
-	}
-
-	if client != nil && currentClient != client {
-		cm.mu.Unlock()
-		return
+	for _, current := range removed {
+		current.Close()
+		if current.Conn != nil {
+			_ = current.Conn.Close()
+		}
 	}
 
-	// Update history with disconnect time
-	if hist, ok := cm.history[userID]; ok {
-		hist.lastDisconnectedAt = time.Now()
+	// Wait for goroutines to finish
+	for _, current := range removed {
+		current.Wait()
 	}
+}
 
-	delete(cm.connections, userID)
-	cm.mu.Unlock()
+// Get retrieves the most recently connected client of a user, on any device.
+// Use Clients to reach every device.
+func (cm *ConnectionManager) Get(userID string) (*Client, bool) {
+	cm.mu.RLock()
+	defer cm.mu.RUnlock()
 
-	currentClient.Close()
-	if currentClient.Conn != nil {
-		_ = currentClient.Conn.Close()
+	var latest *Client
+	for _, client := range cm.connections[userID] {
+		if latest == nil || client.ConnectedAt.After(latest.ConnectedAt) {
+			latest = client
+		}
 	}
-
-	// Wait for goroutines to finish
-	currentClient.Wait()
+	return latest, latest != nil
 }
 
-// Get retrieves the client for a user.
-func (cm *ConnectionManager) Get(userID string) (*Client, bool) {
+// GetDevice retrieves the client of one device of a user.
+func (cm *ConnectionManager) GetDevice(userID, deviceID string) (*Client, bool) {
 	cm.mu.RLock()
 	defer cm.mu.RUnlock()
 
-	client, ok := cm.connections[userID]
+	client, ok := cm.connections[userID][deviceID]
 	return client, ok
 }
 
-// SendToUser sends a message to a specific user.
-// Returns true if the message was queued, false if user not found or channel full.
-func (cm *ConnectionManager) SendToUser(userID string, message []byte) bool {
+// Clients returns the clients of all devices of a user.
+func (cm *ConnectionManager) Clients(userID string) []*Client {
 	cm.mu.RLock()
-	client, ok := cm.connections[userID]
-	cm.mu.RUnlock()
+	defer cm.mu.RUnlock()
 
-	if !ok || client.IsClosed() {
-		return false
+	devices := cm.connections[userID]
+	if len(devices) == 0 {
+		return nil
+	}
+	clients := make([]*Client, 0, len(devices))
+	for _, client := range devices {
+		clients = append(clients, client)
 	}
+	return clients
+}
 
-	select {
-	case client.Send <- message:
-		return true
-	default:
-		// Channel full, message dropped
+// SendToUser sends a message to every device of a specific user.
+// Returns true if the message was queued for at least one device,
+// false if user not found or all channels are full or closed.
+func (cm *ConnectionManager) SendToUser(userID string, message []byte) bool {
+	sent := false
+	for _, client := range cm.Clients(userID) {
+		if trySend(client, message) {
+			sent = true
+		}
+	}
+	return sent
+}
+
+// SendToDevice sends a message to one device of a user.
+// Returns true if the message was queued, false if the device is not connected or its channel is full.
+func (cm *ConnectionManager) SendToDevice(userID, deviceID string, message []byte) bool {
+	client, ok := cm.GetDevice(userID, deviceID)
+	if !ok {
 		return false
 	}
+	return trySend(client, message)
 }
 
-// Count returns the number of active connections.
+// trySend queues message on client without blocking.
+func trySend(client *Client, message []byte) bool {
+	// Closed or channel full, message dropped
+	return client.TrySend(message) == nil
+}
+
+// Count returns the number of active connections across all devices.
 func (cm *ConnectionManager) Count() int {
 	cm.mu.RLock()
 	defer cm.mu.RUnlock()
 
-	return len(cm.connections)
+	return cm.count
 }
 
 // GetAllUserIDs returns all connected user IDs.
@@ -269,14 +441,16 @@ func (cm *ConnectionManager) GetAllUserIDs() []string {
 	return userIDs
 }
 
-// GetAllClients returns all connected clients.
-func (cm *ConnectionManager) GetAllClients() map[string]*Client {
+// GetAllClients returns all connected clients by user ID.
+func (cm *ConnectionManager) GetAllClients() map[string][]*Client {
 	cm.mu.RLock()
 	defer cm.mu.RUnlock()
 
-	clients := make(map[string]*Client, len(cm.connections))
-	for userID, client := range cm.connections {
-		clients[userID] = client
+	clients := make(map[string][]*Client, len(cm.connections))
+	for userID, devices := range cm.connections {
+		for _, client := range devices {
+			clients[userID] = append(clients[userID], client)
+		}
 	}
 	return clients
 }
@@ -18,6 +18,15 @@ func TestNewClient(t *testing.T) {
 	assert.False(t, client.IsClosed())
 }
 
+func TestNewClientWithBufferSize(t *testing.T) {
+	assert.Equal(t, DefaultSendBufferSize, cap(NewClient(nil).Send))
+	assert.Equal(t, 16, cap(NewClientWithBufferSize(nil, 16).Send))
+
+	// Invalid sizes fall back to the default
+	assert.Equal(t, DefaultSendBufferSize, cap(NewClientWithBufferSize(nil, 0).Send))
+	assert.Equal(t, DefaultSendBufferSize, cap(NewClientWithBufferSize(nil, -1).Send))
+}
+
 func TestClient_Close(t *testing.T) {
 	client := NewClient(nil)
 
@@ -358,3 +367,95 @@ func TestConnectionManager_Add_MultipleReconnects(t *testing.T) {
 	assert.True(t, result3.IsReconnect)
 	assert.Equal(t, client2.ConnectedAt, result3.PreviousConnectedAt)
 }
+
+func newDeviceClient(deviceID string) *Client {
+	client := NewClient(nil)
+	client.DeviceID = deviceID
+	return client
+}
+
+func TestConnectionManager_MultipleDevices(t *testing.T) {
+	cm := NewConnectionManager()
+	userID := "user-1"
+
+	phone := newDeviceClient("phone")
+	laptop := newDeviceClient("laptop")
+
+	assert.False(t, cm.Add(userID, phone).IsReconnect)
+	assert.False(t, cm.Add(userID, laptop).IsReconnect, "a new device is not a reconnect")
+
+	assert.Equal(t, 2, cm.Count())
+	assert.Equal(t, []string{userID}, cm.GetAllUserIDs())
+	assert.ElementsMatch(t, []*Client{phone, laptop}, cm.Clients(userID))
+	assert.ElementsMatch(t, []*Client{phone, laptop}, cm.GetAllClients()[userID])
+	assert.False(t, phone.IsClosed(), "devices do not replace each other")
+
+	got, ok := cm.GetDevice(userID, "laptop")
+	assert.True(t, ok)
+	assert.Equal(t, laptop, got)
+
+	// Removing one device keeps the other
+	assert.True(t, cm.Remove(userID, phone))
+	assert.Equal(t, 1, cm.Count())
+	_, ok = cm.GetDevice(userID, "phone")
+	assert.False(t, ok)
+	got, ok = cm.Get(userID)
+	assert.True(t, ok)
+	assert.Equal(t, laptop, got)
+}
+
+func TestConnectionManager_Add_ReplacesSameDevice(t *testing.T) {
+	cm := NewConnectionManager()
+	userID := "user-1"
+
+	phone1 := newDeviceClient("phone")
+	laptop := newDeviceClient("laptop")
+	cm.Add(userID, phone1)
+	cm.Add(userID, laptop)
+
+	phone2 := newDeviceClient("phone")
+	result := cm.Add(userID, phone2)
+
+	assert.True(t, result.IsReconnect)
+	assert.Equal(t, phone1.ConnectedAt, result.PreviousConnectedAt)
+	assert.True(t, phone1.IsClosed())
+	assert.False(t, laptop.IsClosed())
+	assert.Equal(t, 2, cm.Count())
+
+	// The stale pump of the replaced connection must not remove the new one
+	assert.False(t, cm.Remove(userID, phone1))
+	assert.Equal(t, 2, cm.Count())
+}
+
+func TestConnectionManager_RemoveAllDevices(t *testing.T) {
+	cm := NewConnectionManager()
+	phone := newDeviceClient("phone")
+	laptop := newDeviceClient("laptop")
+	cm.Add("user-1", phone)
+	cm.Add("user-1", laptop)
+	cm.Add("user-2", NewClient(nil))
+
+	assert.True(t, cm.Remove("user-1", nil))
+	assert.True(t, phone.IsClosed())
+	assert.True(t, laptop.IsClosed())
+	assert.Equal(t, 1, cm.Count())
+	assert.Empty(t, cm.Clients("user-1"))
+}
+
+func TestConnectionManager_SendToDevice(t *testing.T) {
+	cm := NewConnectionManager()
+	phone := newDeviceClient("phone")
+	laptop := newDeviceClient("laptop")
+	cm.Add("user-1", phone)
+	cm.Add("user-1", laptop)
+
+	assert.True(t, cm.SendToDevice("user-1", "laptop", []byte("hello")))
+	assert.False(t, cm.SendToDevice("user-1", "tablet", []byte("hello")))
+	assert.Len(t, laptop.Send, 1)
+	assert.Empty(t, phone.Send)
+
+	// SendToUser reaches every device
+	assert.True(t, cm.SendToUser("user-1", []byte("all")))
+	assert.Len(t, laptop.Send, 2)
+	assert.Len(t, phone.Send, 1)
+}
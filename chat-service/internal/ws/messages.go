@@ -0,0 +1,156 @@
+package ws
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"chat-service/internal/repository"
+
+	"github.com/jackc/pgx/v5/pgtype"
+	"go.uber.org/zap"
+)
+
+// DefaultMessageLoadTimeout bounds loading the message of a slim event; events are handled
+// one at a time, so a slow database must not stall delivery for long.
+const DefaultMessageLoadTimeout = 2 * time.Second
+
+// MessageBody is the part of a message event left out of slim events.
+type MessageBody struct {
+	Content     string              `json:"content"`
+	Type        string              `json:"type,omitempty"`
+	MediaURL    string              `json:"media_url,omitempty"`
+	Attachments []AttachmentPayload `json:"attachments,omitempty"`
+}
+
+// AttachmentPayload is an attachment as it appears in message events.
+type AttachmentPayload struct {
+	URL      string `json:"url"`
+	MimeType string `json:"mime_type"`
+	Size     int64  `json:"size"`
+	Width    int32  `json:"width,omitempty"`
+	Height   int32  `json:"height,omitempty"`
+}
+
+// MessageLoader loads the body of a message announced by a slim event.
+type MessageLoader interface {
+	LoadMessage(ctx context.Context, messageID string) (MessageBody, error)
+}
+
+// DBMessageLoader loads messages from the chat database.
+type DBMessageLoader struct {
+	queries *repository.Queries
+}
+
+// NewDBMessageLoader creates a loader over a database connection or pool.
+func NewDBMessageLoader(db repository.DBTX) *DBMessageLoader {
+	return &DBMessageLoader{queries: repository.New(db)}
+}
+
+// LoadMessage returns the content, type, media and attachments of messageID.
+func (l *DBMessageLoader) LoadMessage(ctx context.Context, messageID string) (MessageBody, error) {
+	var id pgtype.UUID
+	if err := id.Scan(messageID); err != nil {
+		return MessageBody{}, fmt.Errorf("invalid message id %q: %w", messageID, err)
+	}
+
+	message, err := l.queries.GetMessageByID(ctx, id)
+	if err != nil {
+		return MessageBody{}, fmt.Errorf("failed to get message: %w", err)
+	}
+	if message.DeletedAt.Valid {
+		// Deleted before its event was delivered: deliver it without content
+		return MessageBody{Type: message.Type}, nil
+	}
+
+	attachments, err := l.queries.GetAttachmentsForMessages(ctx, []pgtype.UUID{id})
+	if err != nil {
+		return MessageBody{}, fmt.Errorf("failed to get attachments: %w", err)
+	}
+
+	body := MessageBody{
+		Content:  message.Content,
+		Type:     message.Type,
+		MediaURL: message.MediaUrl.String,
+	}
+	for _, a := range attachments {
+		body.Attachments = append(body.Attachments, AttachmentPayload{
+			URL:      a.Url,
+			MimeType: a.MimeType,
+			Size:     a.SizeBytes,
+			Width:    a.Width.Int32,
+			Height:   a.Height.Int32,
+		})
+	}
+	return body, nil
+}
+
+// SetMessageLoader lets the router complete slim events (published ids-only because they
+// were oversized) with the message loaded by the loader. Without a loader, slim events are
+// delivered as they are and clients fetch the message through the API.
+func (r *Router) SetMessageLoader(loader MessageLoader) {
+	r.messages = loader
+}
+
+// hasLocalRecipient reports whether any user the event may be delivered to is connected here.
+func (r *Router) hasLocalRecipient(payload InnerMessagePayload) bool {
+	for _, receiverID := range payload.ReceiverIDs {
+		if len(r.manager.Clients(receiverID)) > 0 {
+			return true
+		}
+	}
+	actorID := payload.actorID()
+	return actorID != "" && len(r.manager.Clients(actorID)) > 0
+}
+
+// hydrateSlimEvent returns event with the message body loaded back into its payload.
+// On failure the slim event is returned unchanged.
+func (r *Router) hydrateSlimEvent(ctx context.Context, event EventPayload, messageID string) EventPayload {
+	loadCtx, cancel := context.WithTimeout(ctx, DefaultMessageLoadTimeout)
+	defer cancel()
+
+	body, err := r.messages.LoadMessage(loadCtx, messageID)
+	if err != nil {
+		r.logger.Warn("Failed to load message of slim event, delivering it slim",
+			zap.String("event_id", event.EventID),
+			zap.String("message_id", messageID),
+			zap.Error(err),
+		)
+		return event
+	}
+
+	payload, err := mergeMessageBody(event.Payload, body)
+	if err != nil {
+		r.logger.Error("Failed to complete slim event",
+			zap.String("event_id", event.EventID),
+			zap.Error(err),
+		)
+		return event
+	}
+	event.Payload = payload
+	return event
+}
+
+// mergeMessageBody adds the fields of body to a slim payload and clears its slim flag.
+func mergeMessageBody(payload json.RawMessage, body MessageBody) (json.RawMessage, error) {
+	var fields map[string]json.RawMessage
+	if err := json.Unmarshal(payload, &fields); err != nil {
+		return nil, err
+	}
+
+	bodyJSON, err := json.Marshal(body)
+	if err != nil {
+		return nil, err
+	}
+	var bodyFields map[string]json.RawMessage
+	if err := json.Unmarshal(bodyJSON, &bodyFields); err != nil {
+		return nil, err
+	}
+
+	for name, value := range bodyFields {
+		fields[name] = value
+	}
+	delete(fields, "slim")
+	return json.Marshal(fields)
+}
@@ -0,0 +1,114 @@
+package ws
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+// fakeMessageLoader returns a fixed body and counts loads
+type fakeMessageLoader struct {
+	body  MessageBody
+	err   error
+	loads int
+}
+
+func (l *fakeMessageLoader) LoadMessage(ctx context.Context, messageID string) (MessageBody, error) {
+	l.loads++
+	return l.body, l.err
+}
+
+func newSlimEvent(receiverIDs ...string) EventPayload {
+	payload, _ := json.Marshal(map[string]interface{}{
+		"event_type":      "message.sent",
+		"message_id":      "msg-123",
+		"conversation_id": "conv-456",
+		"sender_id":       "sender-789",
+		"receiver_ids":    receiverIDs,
+		"slim":            true,
+	})
+	return EventPayload{EventID: "event-slim", AggregateType: "message", AggregateID: "msg-123", Payload: payload}
+}
+
+// deliveredPayload reads the inner payload of the event delivered to client
+func deliveredPayload(t *testing.T, client *Client) map[string]interface{} {
+	t.Helper()
+	select {
+	case msg := <-client.Send:
+		var event EventPayload
+		require.NoError(t, json.Unmarshal(msg, &event))
+		var fields map[string]interface{}
+		require.NoError(t, json.Unmarshal(event.Payload, &fields))
+		return fields
+	default:
+		t.Fatal("expected a delivered event")
+		return nil
+	}
+}
+
+func TestRouter_SlimEvent_LoadsMessage(t *testing.T) {
+	manager := NewConnectionManager()
+	router := NewRouter(manager, zap.NewNop(), nil)
+	loader := &fakeMessageLoader{body: MessageBody{
+		Content:     "a very long message",
+		Type:        "text",
+		Attachments: []AttachmentPayload{{URL: "https://cdn.example.com/a.png", MimeType: "image/png", Size: 10}},
+	}}
+	router.SetMessageLoader(loader)
+
+	client := &Client{Send: make(chan []byte, 10)}
+	manager.Add("user-1", client)
+
+	router.HandleEvent(context.Background(), newSlimEvent("user-1"))
+
+	fields := deliveredPayload(t, client)
+	assert.Equal(t, "a very long message", fields["content"])
+	assert.Equal(t, "msg-123", fields["message_id"])
+	assert.Len(t, fields["attachments"], 1)
+	assert.NotContains(t, fields, "slim")
+	assert.Equal(t, 1, loader.loads)
+}
+
+func TestRouter_SlimEvent_NoLocalRecipientSkipsLoad(t *testing.T) {
+	router := NewRouter(NewConnectionManager(), zap.NewNop(), nil)
+	loader := &fakeMessageLoader{}
+	router.SetMessageLoader(loader)
+
+	router.HandleEvent(context.Background(), newSlimEvent("user-elsewhere"))
+
+	assert.Equal(t, 0, loader.loads, "gateways without a recipient must not query the database")
+}
+
+func TestRouter_SlimEvent_DeliveredSlim(t *testing.T) {
+	tests := []struct {
+		name   string
+		loader MessageLoader
+	}{
+		{name: "no loader", loader: nil},
+		{name: "load fails", loader: &fakeMessageLoader{err: errors.New("db down")}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			manager := NewConnectionManager()
+			router := NewRouter(manager, zap.NewNop(), nil)
+			if tt.loader != nil {
+				router.SetMessageLoader(tt.loader)
+			}
+
+			client := &Client{Send: make(chan []byte, 10)}
+			manager.Add("user-1", client)
+
+			router.HandleEvent(context.Background(), newSlimEvent("user-1"))
+
+			fields := deliveredPayload(t, client)
+			assert.Equal(t, true, fields["slim"])
+			assert.NotContains(t, fields, "content")
+		})
+	}
+}
@@ -28,6 +28,27 @@ type Metrics struct {
 
 	// Message latency histogram (optional, for future use)
 	MessageLatency prometheus.Histogram
+
+	// Redis Pub/Sub resubscriptions (counter with labels)
+	Resubscriptions *prometheus.CounterVec
+
+	// Zombie connections force-removed by the reaper (counter with labels)
+	ReapedConnections *prometheus.CounterVec
+
+	// Constant 1 labelled with the instance ID, to join other series to the instance
+	InstanceInfo *prometheus.GaugeVec
+
+	// Events waiting for a dispatch worker (gauge)
+	DispatchQueueDepth prometheus.Gauge
+
+	// Events that found their dispatch queue full and held the subscriber back (counter)
+	DispatchBlocked prometheus.Counter
+
+	// Events dropped because their dispatch queue stayed full (counter)
+	DispatchDropped prometheus.Counter
+
+	// /ws requests turned away with 503 (counter with labels)
+	AcceptsRejected *prometheus.CounterVec
 }
 
 // NewMetrics creates and registers all Prometheus metrics.
@@ -71,6 +92,48 @@ func NewMetrics(registry prometheus.Registerer) *Metrics {
 			Help:      "Latency of message delivery to WebSocket clients",
 			Buckets:   []float64{0.001, 0.005, 0.01, 0.025, 0.05, 0.1, 0.25, 0.5, 1},
 		}),
+
+		Resubscriptions: factory.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "pubsub_resubscriptions_total",
+			Help:      "Total number of Redis Pub/Sub resubscriptions after a connection drop",
+		}, []string{"reason"}), // reason: "channel_closed", "connection_reset"
+
+		ReapedConnections: factory.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "reaped_connections_total",
+			Help:      "Total number of zombie connections force-removed by the reaper",
+		}, []string{"reason"}), // reason: "closed", "pumps_exited", "idle"
+
+		InstanceInfo: factory.NewGaugeVec(prometheus.GaugeOpts{
+			Namespace: namespace,
+			Name:      "instance_info",
+			Help:      "Always 1; the instance_id label is the ID this gateway uses for presence, stream consumer groups and logs",
+		}, []string{"instance_id"}),
+
+		DispatchQueueDepth: factory.NewGauge(prometheus.GaugeOpts{
+			Namespace: namespace,
+			Name:      "dispatch_queue_depth",
+			Help:      "Number of received events waiting for a dispatch worker",
+		}),
+
+		DispatchBlocked: factory.NewCounter(prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "dispatch_blocked_total",
+			Help:      "Total number of events that found their dispatch queue full and waited for room",
+		}),
+
+		DispatchDropped: factory.NewCounter(prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "dispatch_dropped_total",
+			Help:      "Total number of events dropped because their dispatch queue stayed full",
+		}),
+
+		AcceptsRejected: factory.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "accepts_rejected_total",
+			Help:      "Total number of /ws requests rejected with 503 before authentication",
+		}, []string{"reason"}), // reason: "rate_limited", "draining"
 	}
 
 	return m
@@ -108,6 +171,42 @@ func (m *Metrics) IncReconnections() {
 	m.Reconnections.Inc()
 }
 
+// IncResubscriptions increments the Pub/Sub resubscriptions counter.
+func (m *Metrics) IncResubscriptions(reason string) {
+	m.Resubscriptions.WithLabelValues(reason).Inc()
+}
+
+// IncReapedConnections increments the reaped connections counter.
+func (m *Metrics) IncReapedConnections(reason string) {
+	m.ReapedConnections.WithLabelValues(reason).Inc()
+}
+
+// SetDispatchQueueDepth sets the dispatch queue depth gauge.
+func (m *Metrics) SetDispatchQueueDepth(depth int) {
+	m.DispatchQueueDepth.Set(float64(depth))
+}
+
+// IncDispatchBlocked increments the blocked dispatch counter.
+func (m *Metrics) IncDispatchBlocked() {
+	m.DispatchBlocked.Inc()
+}
+
+// IncDispatchDropped increments the dropped dispatch counter.
+func (m *Metrics) IncDispatchDropped() {
+	m.DispatchDropped.Inc()
+}
+
+// IncAcceptsRejected increments the rejected accepts counter.
+func (m *Metrics) IncAcceptsRejected(reason string) {
+	m.AcceptsRejected.WithLabelValues(reason).Inc()
+}
+
+// SetInstanceID publishes the instance ID as the instance_info label.
+func (m *Metrics) SetInstanceID(id string) {
+	m.InstanceInfo.Reset()
+	m.InstanceInfo.WithLabelValues(id).Set(1)
+}
+
 // DefaultMetrics creates metrics with the default Prometheus registry.
 func DefaultMetrics() *Metrics {
 	return NewMetrics(prometheus.DefaultRegisterer)
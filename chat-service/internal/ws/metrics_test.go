@@ -4,6 +4,7 @@ import (
 	"testing"
 
 	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 )
@@ -158,3 +159,30 @@ func TestMetrics_ImplementsRouterMetrics(t *testing.T) {
 	// Verify Metrics implements RouterMetrics interface
 	var _ RouterMetrics = m
 }
+
+func TestMetrics_IncResubscriptions(t *testing.T) {
+	registry := prometheus.NewRegistry()
+	m := NewMetrics(registry)
+
+	// Verify Metrics implements SubscriberMetrics interface
+	var _ SubscriberMetrics = m
+
+	m.IncResubscriptions(ResubscribeChannelClosed)
+	m.IncResubscriptions(ResubscribeChannelClosed)
+	m.IncResubscriptions(ResubscribeConnectionReset)
+
+	assert.Equal(t, 2.0, testutil.ToFloat64(m.Resubscriptions.WithLabelValues(ResubscribeChannelClosed)))
+	assert.Equal(t, 1.0, testutil.ToFloat64(m.Resubscriptions.WithLabelValues(ResubscribeConnectionReset)))
+}
+
+func TestMetrics_SetInstanceID(t *testing.T) {
+	registry := prometheus.NewRegistry()
+	m := NewMetrics(registry)
+
+	m.SetInstanceID("gateway-0")
+	m.SetInstanceID("gateway-1")
+
+	// Only the current ID is exported
+	assert.Equal(t, 1, testutil.CollectAndCount(m.InstanceInfo))
+	assert.Equal(t, float64(1), testutil.ToFloat64(m.InstanceInfo.WithLabelValues("gateway-1")))
+}
@@ -0,0 +1,155 @@
+package ws
+
+import (
+	"context"
+	"strconv"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+	"go.uber.org/zap"
+)
+
+// presenceKeyPrefix + user ID is a sorted set of the gateway instances holding connections of
+// the user, each scored with the Unix time in milliseconds at which its entry expires
+const presenceKeyPrefix = "ws:presence:"
+
+const (
+	// DefaultPresenceRefreshInterval is how often a gateway renews the presence of its connected users
+	DefaultPresenceRefreshInterval = 30 * time.Second
+
+	// DefaultPresenceMissedHeartbeats is how many refreshes in a row an entry outlives by default
+	DefaultPresenceMissedHeartbeats = 3
+
+	// DefaultPresenceTTL is how long a presence entry lives without a refresh. Entries of a gateway
+	// that died without cleaning up stop being resolved after at most this long.
+	DefaultPresenceTTL = DefaultPresenceMissedHeartbeats * DefaultPresenceRefreshInterval
+
+	// presenceRefreshBatch bounds the commands of one refresh pipeline
+	presenceRefreshBatch = 500
+)
+
+// PresenceRegistry records in Redis which gateway instances hold the connections of each user,
+// so a publisher can address the instances of a user instead of broadcasting to all of them.
+// Each instance writes only its own entries; they expire unless refreshed, which takes care of
+// gateways that crash with connections registered.
+type PresenceRegistry struct {
+	client     *redis.Client
+	logger     *zap.Logger
+	instanceID string
+	ttl        time.Duration
+
+	now func() time.Time // replaced in tests
+}
+
+// NewPresenceRegistry creates a registry writing entries for instanceID. A non-positive ttl uses
+// DefaultPresenceTTL. Any process with the Redis client may use it to resolve users.
+func NewPresenceRegistry(client *redis.Client, logger *zap.Logger, instanceID string, ttl time.Duration) *PresenceRegistry {
+	if ttl <= 0 {
+		ttl = DefaultPresenceTTL
+	}
+	return &PresenceRegistry{
+		client:     client,
+		logger:     logger,
+		instanceID: instanceID,
+		ttl:        ttl,
+		now:        time.Now,
+	}
+}
+
+// PresenceTTL returns the entry lifetime for refreshes every interval: ttl when it outlasts one
+// interval, otherwise DefaultPresenceMissedHeartbeats intervals. A TTL at or below the interval
+// would let entries of a healthy gateway lapse between two refreshes.
+func PresenceTTL(interval, ttl time.Duration) time.Duration {
+	if interval <= 0 {
+		interval = DefaultPresenceRefreshInterval
+	}
+	if ttl > interval {
+		return ttl
+	}
+	return DefaultPresenceMissedHeartbeats * interval
+}
+
+func presenceKey(userID string) string {
+	return presenceKeyPrefix + userID
+}
+
+// Register records that this instance holds a connection of userID.
+func (p *PresenceRegistry) Register(ctx context.Context, userID string) error {
+	_, err := p.client.Pipelined(ctx, func(pipe redis.Pipeliner) error {
+		p.add(ctx, pipe, userID, p.now())
+		return nil
+	})
+	return err
+}
+
+// Unregister removes the entry of this instance for userID; call it once the user's last
+// connection to this instance is gone.
+func (p *PresenceRegistry) Unregister(ctx context.Context, userID string) error {
+	return p.client.ZRem(ctx, presenceKey(userID), p.instanceID).Err()
+}
+
+// Refresh renews the entries of this instance for userIDs.
+func (p *PresenceRegistry) Refresh(ctx context.Context, userIDs []string) error {
+	now := p.now()
+	for start := 0; start < len(userIDs); start += presenceRefreshBatch {
+		batch := userIDs[start:min(start+presenceRefreshBatch, len(userIDs))]
+		_, err := p.client.Pipelined(ctx, func(pipe redis.Pipeliner) error {
+			for _, userID := range batch {
+				p.add(ctx, pipe, userID, now)
+			}
+			return nil
+		})
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// add queues the entry of this instance for userID, expiring one TTL after now. The key itself
+// expires with the newest entry, so users who never come back leave nothing behind.
+func (p *PresenceRegistry) add(ctx context.Context, pipe redis.Pipeliner, userID string, now time.Time) {
+	key := presenceKey(userID)
+	pipe.ZAdd(ctx, key, redis.Z{Score: float64(now.Add(p.ttl).UnixMilli()), Member: p.instanceID})
+	pipe.PExpire(ctx, key, p.ttl)
+}
+
+// Instances returns the instances currently holding connections of userID, in no particular
+// order; none when the user is offline. Expired entries are dropped on the way.
+func (p *PresenceRegistry) Instances(ctx context.Context, userID string) ([]string, error) {
+	key := presenceKey(userID)
+	now := strconv.FormatInt(p.now().UnixMilli(), 10)
+
+	var live *redis.StringSliceCmd
+	_, err := p.client.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+		pipe.ZRemRangeByScore(ctx, key, "-inf", now)
+		live = pipe.ZRangeByScore(ctx, key, &redis.ZRangeBy{Min: "(" + now, Max: "+inf"})
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return live.Val(), nil
+}
+
+// Run refreshes the presence of the users connected to manager every interval until ctx is
+// cancelled. A non-positive interval uses DefaultPresenceRefreshInterval.
+func (p *PresenceRegistry) Run(ctx context.Context, manager *ConnectionManager, interval time.Duration) {
+	if interval <= 0 {
+		interval = DefaultPresenceRefreshInterval
+	}
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			userIDs := manager.GetAllUserIDs()
+			if err := p.Refresh(ctx, userIDs); err != nil {
+				p.logger.Warn("Failed to refresh presence", zap.Int("users", len(userIDs)), zap.Error(err))
+			}
+		}
+	}
+}
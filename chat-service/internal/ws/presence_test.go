@@ -0,0 +1,201 @@
+package ws
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"chat-service/internal/auth"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/redis/go-redis/v9"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+func newTestPresence(client *redis.Client, instanceID string, now *time.Time) *PresenceRegistry {
+	p := NewPresenceRegistry(client, zap.NewNop(), instanceID, time.Minute)
+	p.now = func() time.Time { return *now }
+	return p
+}
+
+func TestPresenceRegistry_ResolvesInstances(t *testing.T) {
+	_, client := setupTestRedis(t)
+	ctx := context.Background()
+	now := time.Now()
+	gatewayA := newTestPresence(client, "gw-a", &now)
+	gatewayB := newTestPresence(client, "gw-b", &now)
+
+	require.NoError(t, gatewayA.Register(ctx, "user-1"))
+	require.NoError(t, gatewayB.Register(ctx, "user-1"))
+	require.NoError(t, gatewayB.Register(ctx, "user-1"), "registering twice keeps one entry")
+	require.NoError(t, gatewayB.Register(ctx, "user-2"))
+
+	instances, err := gatewayA.Instances(ctx, "user-1")
+	require.NoError(t, err)
+	assert.ElementsMatch(t, []string{"gw-a", "gw-b"}, instances)
+
+	require.NoError(t, gatewayB.Unregister(ctx, "user-1"))
+	instances, err = gatewayA.Instances(ctx, "user-1")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"gw-a"}, instances)
+
+	instances, err = gatewayA.Instances(ctx, "user-3")
+	require.NoError(t, err)
+	assert.Empty(t, instances, "offline users resolve to no instance")
+}
+
+func TestPresenceRegistry_StaleEntriesExpire(t *testing.T) {
+	mr, client := setupTestRedis(t)
+	ctx := context.Background()
+	now := time.Now()
+	crashed := newTestPresence(client, "gw-crashed", &now)
+	alive := newTestPresence(client, "gw-alive", &now)
+
+	require.NoError(t, crashed.Register(ctx, "user-1"))
+	require.NoError(t, alive.Register(ctx, "user-1"))
+
+	// Only the live gateway refreshes its users
+	now = now.Add(45 * time.Second)
+	require.NoError(t, alive.Refresh(ctx, []string{"user-1"}))
+	now = now.Add(30 * time.Second)
+
+	instances, err := alive.Instances(ctx, "user-1")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"gw-alive"}, instances)
+	members, err := client.ZRange(ctx, presenceKey("user-1"), 0, -1).Result()
+	require.NoError(t, err)
+	assert.Equal(t, []string{"gw-alive"}, members, "stale entries are dropped when read")
+
+	// Keys of users nobody refreshes expire with their last entry
+	mr.FastForward(2 * time.Minute)
+	assert.False(t, mr.Exists(presenceKey("user-1")))
+}
+
+func TestPresenceTTL(t *testing.T) {
+	assert.Equal(t, 45*time.Second, PresenceTTL(10*time.Second, 45*time.Second))
+	assert.Equal(t, 30*time.Second, PresenceTTL(10*time.Second, 0), "defaults to a few missed heartbeats")
+	assert.Equal(t, 30*time.Second, PresenceTTL(10*time.Second, 10*time.Second), "must outlast one interval")
+	assert.Equal(t, DefaultPresenceTTL, PresenceTTL(0, 0))
+}
+
+func TestPresenceRegistry_MissedHeartbeatsExpire(t *testing.T) {
+	mr, client := setupTestRedis(t)
+	ctx := context.Background()
+	interval := 10 * time.Second
+	ttl := PresenceTTL(interval, 0)
+	now := time.Now()
+	presence := NewPresenceRegistry(client, zap.NewNop(), "gw-a", ttl)
+	presence.now = func() time.Time { return now }
+
+	require.NoError(t, presence.Register(ctx, "user-1"))
+	// Heartbeats on time keep the entry
+	for i := 0; i < 5; i++ {
+		now = now.Add(interval)
+		mr.FastForward(interval)
+		require.NoError(t, presence.Refresh(ctx, []string{"user-1"}))
+	}
+
+	// The gateway dies: the next heartbeats never come. A late heartbeat or two is tolerated...
+	now = now.Add(ttl - time.Millisecond)
+	mr.FastForward(ttl - time.Millisecond)
+	instances, err := presence.Instances(ctx, "user-1")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"gw-a"}, instances)
+
+	// ...but once the TTL has passed since the last one, the user is offline and the key is gone
+	now = now.Add(time.Millisecond)
+	mr.FastForward(time.Millisecond)
+	instances, err = presence.Instances(ctx, "user-1")
+	require.NoError(t, err)
+	assert.Empty(t, instances)
+	assert.False(t, mr.Exists(presenceKey("user-1")))
+}
+
+func TestPresenceRegistry_RunStopsRefreshing(t *testing.T) {
+	_, client := setupTestRedis(t)
+	manager := NewConnectionManager()
+	manager.Add("user-1", NewClient(nil))
+
+	interval := 20 * time.Millisecond
+	presence := NewPresenceRegistry(client, zap.NewNop(), "gw-a", PresenceTTL(interval, 0))
+	ctx, cancel := context.WithCancel(context.Background())
+	go presence.Run(ctx, manager, interval)
+
+	online := func() bool {
+		instances, err := presence.Instances(context.Background(), "user-1")
+		return err == nil && len(instances) == 1
+	}
+	require.Eventually(t, online, time.Second, 5*time.Millisecond)
+	// Outlives the TTL while the heartbeat runs
+	time.Sleep(4 * interval)
+	assert.True(t, online(), "refreshed entries stay live")
+
+	// Simulate a crash: heartbeats stop without unregistering
+	cancel()
+	assert.Eventually(t, func() bool { return !online() }, time.Second, 5*time.Millisecond)
+}
+
+func TestPresenceRegistry_Run(t *testing.T) {
+	_, client := setupTestRedis(t)
+	manager := NewConnectionManager()
+	manager.Add("user-1", NewClient(nil))
+
+	presence := NewPresenceRegistry(client, zap.NewNop(), "gw-a", time.Minute)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go presence.Run(ctx, manager, 10*time.Millisecond)
+
+	assert.Eventually(t, func() bool {
+		instances, err := presence.Instances(context.Background(), "user-1")
+		return err == nil && len(instances) == 1 && instances[0] == "gw-a"
+	}, time.Second, 10*time.Millisecond, "connected users are registered by the refresh")
+}
+
+func TestPresenceHandler(t *testing.T) {
+	const secret = "presence-secret"
+	_, client := setupTestRedis(t)
+	presence := NewPresenceRegistry(client, zap.NewNop(), "gw-a", time.Minute)
+	require.NoError(t, presence.Register(context.Background(), "user-1"))
+
+	handler := NewPresenceHandler(presence, auth.NewJWTVerifier(secret))
+	claims := jwt.MapClaims{"id": "router", "role": auth.RoleAdmin, "exp": time.Now().Add(time.Hour).Unix()}
+	token, err := jwt.NewWithClaims(jwt.SigningMethodHS256, claims).SignedString([]byte(secret))
+	require.NoError(t, err)
+
+	tests := []struct {
+		name          string
+		query         string
+		authorization string
+		wantStatus    int
+		wantInstances []string
+	}{
+		{name: "no token", query: "?user_id=user-1", wantStatus: http.StatusUnauthorized},
+		{name: "missing user", authorization: "Bearer " + token, wantStatus: http.StatusBadRequest},
+		{name: "connected user", query: "?user_id=user-1", authorization: "Bearer " + token, wantStatus: http.StatusOK, wantInstances: []string{"gw-a"}},
+		{name: "offline user", query: "?user_id=user-2", authorization: "Bearer " + token, wantStatus: http.StatusOK, wantInstances: []string{}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := httptest.NewRequest(http.MethodGet, "/internal/presence"+tt.query, nil)
+			if tt.authorization != "" {
+				r.Header.Set("Authorization", tt.authorization)
+			}
+			w := httptest.NewRecorder()
+			handler(w, r)
+
+			require.Equal(t, tt.wantStatus, w.Code)
+			if tt.wantStatus != http.StatusOK {
+				return
+			}
+			var resp PresenceResponse
+			require.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+			assert.Equal(t, PresenceResponse{UserID: r.URL.Query().Get("user_id"), Instances: tt.wantInstances}, resp)
+		})
+	}
+}
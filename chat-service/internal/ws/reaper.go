@@ -0,0 +1,142 @@
+package ws
+
+import (
+	"context"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+// Reasons a connection is reaped, used as metric labels
+const (
+	ReapReasonClosed      = "closed"       // Client closed but still registered
+	ReapReasonPumpsExited = "pumps_exited" // Read and write pumps gone without removing the client
+	ReapReasonIdle        = "idle"         // No frame or pong from the peer within the idle timeout
+)
+
+const (
+	// DefaultReaperInterval is how often the reaper audits the connection manager
+	DefaultReaperInterval = time.Minute
+
+	// DefaultReaperIdleTimeout is how long a connection may stay silent before it is reaped.
+	// Healthy peers answer every ping, so this only triggers when the read pump is stuck.
+	DefaultReaperIdleTimeout = 3 * time.Minute
+)
+
+// ReaperMetrics tracks reaped connections.
+type ReaperMetrics interface {
+	IncReapedConnections(reason string)
+}
+
+// Reaper periodically removes zombie connections from the ConnectionManager:
+// clients whose pumps exited (or never cleaned up after closing) but are still
+// registered, and connections that went silent. Without it such entries leak
+// for the lifetime of the gateway and keep receiving fan-out.
+type Reaper struct {
+	manager     *ConnectionManager
+	logger      *zap.Logger
+	metrics     ReaperMetrics
+	interval    time.Duration
+	idleTimeout time.Duration
+
+	// onReap runs for each reaped client, e.g. to release its subscriptions
+	onReap func(userID string, client *Client)
+}
+
+// NewReaper creates a reaper. Non-positive durations use the defaults.
+func NewReaper(manager *ConnectionManager, logger *zap.Logger, interval, idleTimeout time.Duration) *Reaper {
+	if interval <= 0 {
+		interval = DefaultReaperInterval
+	}
+	if idleTimeout <= 0 {
+		idleTimeout = DefaultReaperIdleTimeout
+	}
+	return &Reaper{
+		manager:     manager,
+		logger:      logger,
+		interval:    interval,
+		idleTimeout: idleTimeout,
+	}
+}
+
+// SetMetrics sets the metrics recorder for reaped connections.
+func (r *Reaper) SetMetrics(metrics ReaperMetrics) {
+	r.metrics = metrics
+}
+
+// SetOnReap registers a callback run after a client is removed by the reaper.
+func (r *Reaper) SetOnReap(onReap func(userID string, client *Client)) {
+	r.onReap = onReap
+}
+
+// Run audits connections every interval until ctx is cancelled.
+func (r *Reaper) Run(ctx context.Context) {
+	ticker := time.NewTicker(r.interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case now := <-ticker.C:
+			r.Sweep(now)
+		}
+	}
+}
+
+// Sweep removes the zombie connections as of now and returns how many were reaped.
+func (r *Reaper) Sweep(now time.Time) int {
+	reaped := 0
+	for userID, clients := range r.manager.GetAllClients() {
+		for _, client := range clients {
+			if r.reap(userID, client, now) {
+				reaped++
+			}
+		}
+	}
+	return reaped
+}
+
+// reap removes client if it is a zombie and reports whether it did.
+func (r *Reaper) reap(userID string, client *Client, now time.Time) bool {
+	reason := r.zombieReason(client, now)
+	if reason == "" {
+		return false
+	}
+	// Another goroutine may have removed or replaced the client since the snapshot
+	if !r.manager.Remove(userID, client) {
+		return false
+	}
+
+	r.logger.Warn("Reaped zombie connection",
+		zap.String("user_id", userID),
+		zap.String("device_id", client.DeviceID),
+		zap.String("reason", reason),
+		zap.Time("last_activity", client.LastActivity()),
+	)
+	if r.metrics != nil {
+		r.metrics.IncReapedConnections(reason)
+	}
+	if r.onReap != nil {
+		r.onReap(userID, client)
+	}
+	return true
+}
+
+// zombieReason returns why client should be reaped, or "" if it is healthy.
+// Clients without pumps (gRPC stream subscriptions) are only reaped once closed.
+func (r *Reaper) zombieReason(client *Client, now time.Time) string {
+	if client.IsClosed() {
+		return ReapReasonClosed
+	}
+	if !client.started.Load() {
+		return ""
+	}
+	if client.ActiveGoroutines() == 0 {
+		return ReapReasonPumpsExited
+	}
+	if now.Sub(client.LastActivity()) > r.idleTimeout {
+		return ReapReasonIdle
+	}
+	return ""
+}
@@ -0,0 +1,115 @@
+package ws
+
+import (
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/zap"
+)
+
+// mockReaperMetrics implements ReaperMetrics for testing
+type mockReaperMetrics struct {
+	mu      sync.Mutex
+	reasons []string
+}
+
+func (m *mockReaperMetrics) IncReapedConnections(reason string) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.reasons = append(m.reasons, reason)
+}
+
+// TestReaper_Sweep verifies only zombie connections are removed, each with its reason
+func TestReaper_Sweep(t *testing.T) {
+	manager := NewConnectionManager()
+	metrics := &mockReaperMetrics{}
+	reaper := NewReaper(manager, zap.NewNop(), time.Minute, time.Minute)
+	reaper.SetMetrics(metrics)
+
+	var released []string
+	reaper.SetOnReap(func(userID string, client *Client) {
+		released = append(released, userID)
+	})
+
+	// Healthy: both pumps running and recently active
+	healthy := NewClient(nil)
+	healthy.AddGoroutine()
+	healthy.AddGoroutine()
+	manager.Add("healthy", healthy)
+
+	// Pumps exited without removing the client
+	leaked := NewClient(nil)
+	leaked.AddGoroutine()
+	leaked.AddGoroutine()
+	leaked.DoneGoroutine()
+	leaked.DoneGoroutine()
+	manager.Add("leaked", leaked)
+
+	// Silent for longer than the idle timeout
+	idle := NewClient(nil)
+	idle.AddGoroutine()
+	idle.lastActivity.Store(time.Now().Add(-2 * time.Minute).UnixNano())
+	manager.Add("idle", idle)
+
+	// Closed but still registered
+	closed := NewClient(nil)
+	manager.Add("closed", closed)
+	closed.Close()
+
+	// Stream subscription: no pumps, never active, must be kept
+	stream := NewClient(nil)
+	manager.Add("stream", stream)
+
+	assert.Equal(t, 3, reaper.Sweep(time.Now()))
+
+	assert.ElementsMatch(t, []string{"healthy", "stream"}, manager.GetAllUserIDs())
+	assert.ElementsMatch(t, []string{"leaked", "idle", "closed"}, released)
+	assert.ElementsMatch(t, []string{ReapReasonPumpsExited, ReapReasonIdle, ReapReasonClosed}, metrics.reasons)
+	assert.True(t, idle.IsClosed(), "reaping closes the client so its pumps exit")
+}
+
+// TestReaper_ActivityKeepsConnection verifies Touch defers the idle reap
+func TestReaper_ActivityKeepsConnection(t *testing.T) {
+	manager := NewConnectionManager()
+	reaper := NewReaper(manager, zap.NewNop(), time.Minute, time.Minute)
+
+	client := NewClient(nil)
+	client.AddGoroutine()
+	manager.Add("user-1", client)
+
+	assert.Zero(t, reaper.Sweep(time.Now().Add(30*time.Second)))
+	assert.Equal(t, 1, reaper.Sweep(time.Now().Add(2*time.Minute)))
+
+	client = NewClient(nil)
+	client.AddGoroutine()
+	manager.Add("user-2", client)
+	client.lastActivity.Store(time.Now().Add(-2 * time.Minute).UnixNano())
+	client.Touch()
+	assert.Zero(t, reaper.Sweep(time.Now()))
+}
+
+// TestReaper_SkipsReplacedClient verifies a client replaced after the snapshot is not reaped twice
+func TestReaper_SkipsReplacedClient(t *testing.T) {
+	manager := NewConnectionManager()
+	reaper := NewReaper(manager, zap.NewNop(), 0, 0)
+	reaper.SetOnReap(func(userID string, client *Client) {
+		t.Fatalf("unexpected reap of %s", userID)
+	})
+
+	stale := NewClient(nil)
+	stale.AddGoroutine()
+	stale.DoneGoroutine()
+	manager.Add("user-1", stale)
+	assert.True(t, manager.Remove("user-1", stale))
+	assert.False(t, manager.Remove("user-1", stale), "second remove is a no-op")
+
+	assert.Zero(t, reaper.Sweep(time.Now()))
+}
+
+func TestClient_Release(t *testing.T) {
+	client := NewClient(nil)
+	assert.True(t, client.Release())
+	assert.False(t, client.Release(), "bookkeeping runs once per client")
+}
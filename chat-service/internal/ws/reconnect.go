@@ -0,0 +1,86 @@
+package ws
+
+import (
+	"encoding/json"
+	"math/rand/v2"
+	"time"
+
+	"github.com/gorilla/websocket"
+)
+
+// Reconnect window for clients told to go away (WS_RECONNECT_MIN_MS, WS_RECONNECT_MAX_MS)
+const (
+	DefaultReconnectMin = time.Second
+	DefaultReconnectMax = 15 * time.Second
+
+	// DefaultDrainGrace is how long shutdown waits after the drain advisory before closing
+	// connections (WS_DRAIN_GRACE_MS), so the advisory is flushed and clients can leave on their own
+	DefaultDrainGrace = time.Second
+)
+
+// ReconnectJitter spreads the reconnects of clients dropped together over a window, so a gateway
+// restart or a saturated accept path does not bring every client back in the same second.
+type ReconnectJitter struct {
+	min, max time.Duration
+
+	// int64n returns a uniform value in [0, n); replaced in tests
+	int64n func(n int64) int64
+}
+
+// NewReconnectJitter creates a jitter over [min, max]. A non-positive min uses DefaultReconnectMin,
+// a max below min uses DefaultReconnectMax (or min, if that is larger).
+func NewReconnectJitter(min, max time.Duration) *ReconnectJitter {
+	if min <= 0 {
+		min = DefaultReconnectMin
+	}
+	if max < min {
+		max = DefaultReconnectMax
+	}
+	if max < min {
+		max = min
+	}
+	return &ReconnectJitter{min: min, max: max, int64n: rand.Int64N}
+}
+
+// Next returns a random delay in [min, max], rounded to the millisecond clients work with.
+func (j *ReconnectJitter) Next() time.Duration {
+	window := (j.max - j.min).Milliseconds()
+	return j.min.Truncate(time.Millisecond) + time.Duration(j.int64n(window+1))*time.Millisecond
+}
+
+// goingAwayReason is the close frame reason of a shutdown; it must stay under 123 bytes
+type goingAwayReason struct {
+	Reason           string `json:"reason"`
+	ReconnectAfterMs int64  `json:"reconnect_after_ms"`
+}
+
+// GoingAwayMessage formats the close frame sent when the gateway shuts down. The reason is JSON
+// carrying reconnect_after_ms, so clients that only see the close event still back off.
+// A zero reconnectAfter leaves the delay out, e.g. when the connection is replaced by a newer one.
+func GoingAwayMessage(reconnectAfter time.Duration) []byte {
+	if reconnectAfter <= 0 {
+		return websocket.FormatCloseMessage(websocket.CloseGoingAway, "connection closed")
+	}
+	reason, _ := json.Marshal(goingAwayReason{
+		Reason:           "server shutting down",
+		ReconnectAfterMs: reconnectAfter.Milliseconds(),
+	})
+	return websocket.FormatCloseMessage(websocket.CloseGoingAway, string(reason))
+}
+
+// AdviseDrain tells a client the gateway is shutting down: it queues a server_draining event and
+// remembers the delay so the close frame that follows carries the same reconnect_after_ms.
+// Returns false if the event could not be queued (client closed or its buffer full).
+func AdviseDrain(client *Client, reconnectAfter time.Duration) bool {
+	client.reconnectAfter.Store(int64(reconnectAfter))
+	data, err := json.Marshal(NewServerDrainingEvent(reconnectAfter))
+	if err != nil {
+		return false
+	}
+	return trySend(client, data)
+}
+
+// ReconnectAfter returns the delay given to the client by AdviseDrain, or 0 if it was not drained.
+func (c *Client) ReconnectAfter() time.Duration {
+	return time.Duration(c.reconnectAfter.Load())
+}
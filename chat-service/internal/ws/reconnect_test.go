@@ -0,0 +1,72 @@
+package ws
+
+import (
+	"encoding/binary"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/gorilla/websocket"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewReconnectJitter_Defaults(t *testing.T) {
+	j := NewReconnectJitter(0, 0)
+	assert.Equal(t, DefaultReconnectMin, j.min)
+	assert.Equal(t, DefaultReconnectMax, j.max)
+
+	// A min above the default max gives a fixed delay
+	j = NewReconnectJitter(time.Minute, 0)
+	assert.Equal(t, time.Minute, j.max)
+}
+
+func TestReconnectJitter_Next(t *testing.T) {
+	j := NewReconnectJitter(time.Second, 3*time.Second)
+
+	j.int64n = func(n int64) int64 { return 0 }
+	assert.Equal(t, time.Second, j.Next())
+	j.int64n = func(n int64) int64 { return n - 1 }
+	assert.Equal(t, 3*time.Second, j.Next(), "max is inclusive")
+
+	j.int64n = NewReconnectJitter(0, 0).int64n
+	seen := make(map[time.Duration]bool)
+	for i := 0; i < 100; i++ {
+		d := j.Next()
+		assert.GreaterOrEqual(t, d, time.Second)
+		assert.LessOrEqual(t, d, 3*time.Second)
+		seen[d] = true
+	}
+	assert.Greater(t, len(seen), 1, "delays are spread")
+}
+
+func TestGoingAwayMessage(t *testing.T) {
+	msg := GoingAwayMessage(2500 * time.Millisecond)
+	require.LessOrEqual(t, len(msg), 125, "control frame payload limit")
+	assert.Equal(t, uint16(websocket.CloseGoingAway), binary.BigEndian.Uint16(msg))
+
+	var reason goingAwayReason
+	require.NoError(t, json.Unmarshal(msg[2:], &reason))
+	assert.Equal(t, "server shutting down", reason.Reason)
+	assert.Equal(t, int64(2500), reason.ReconnectAfterMs)
+
+	msg = GoingAwayMessage(0)
+	assert.Equal(t, "connection closed", string(msg[2:]))
+}
+
+func TestAdviseDrain(t *testing.T) {
+	client := NewClient(nil)
+	assert.Zero(t, client.ReconnectAfter())
+
+	require.True(t, AdviseDrain(client, 4200*time.Millisecond))
+	assert.Equal(t, 4200*time.Millisecond, client.ReconnectAfter())
+
+	var event ServerDrainingEvent
+	require.NoError(t, json.Unmarshal(<-client.Send, &event))
+	assert.Equal(t, EventTypeServerDraining, event.Type)
+	assert.Equal(t, int64(4200), event.ReconnectAfterMs)
+	assert.NotEmpty(t, event.InstanceID)
+
+	client.Close()
+	assert.False(t, AdviseDrain(client, time.Second), "closed clients are skipped")
+}
@@ -3,6 +3,8 @@ package ws
 import (
 	"context"
 	"encoding/json"
+	"errors"
+	"fmt"
 
 	"go.uber.org/zap"
 )
@@ -16,7 +18,48 @@ type InnerMessagePayload struct {
 	SenderID       string   `json:"sender_id"`
 	ReceiverIDs    []string `json:"receiver_ids"`
 	Content        string   `json:"content"`
+	Type           string   `json:"type,omitempty"` // Message type: TEXT, IMAGE, VIDEO, FILE, SYSTEM, CALL
 	CreatedAt      string   `json:"created_at"`
+	RequestID      string   `json:"request_id,omitempty"`       // Correlation id of the originating API request
+	UserID         string   `json:"user_id,omitempty"`          // Acting user of non-message events (e.g. the reader of conversation.read, the reactor of reaction_added)
+	OriginDeviceID string   `json:"origin_device_id,omitempty"` // Device the acting user sent the request from
+	Slim           bool     `json:"slim,omitempty"`             // Oversized message published ids-only; content must be loaded
+}
+
+// Outbox event types with their own routing rules
+const (
+	// Read receipt for the other participants; the reader's devices get readSelfEvent instead
+	conversationReadEvent = "conversation.read"
+	// Read state of the reader for their own devices; it carries no receiver_ids
+	readSelfEvent = "conversation.read_self"
+)
+
+// messageTypeSystem marks system messages (renames, membership changes); they are not the
+// sender's own words, so every device of the sender shows them like any participant's
+const messageTypeSystem = "SYSTEM"
+
+// actorID returns the user whose action produced the event
+func (p InnerMessagePayload) actorID() string {
+	if p.SenderID != "" {
+		return p.SenderID
+	}
+	return p.UserID
+}
+
+// DeliveryError is the failure to queue an event on one connection of a recipient.
+// Fan-out carries on with the other connections and recipients.
+type DeliveryError struct {
+	UserID   string
+	DeviceID string
+	Err      error // ErrClientClosed or ErrSendBufferFull
+}
+
+func (e *DeliveryError) Error() string {
+	return fmt.Sprintf("deliver to user %s device %q: %v", e.UserID, e.DeviceID, e.Err)
+}
+
+func (e *DeliveryError) Unwrap() error {
+	return e.Err
 }
 
 // RouterMetrics tracks routing statistics.
@@ -32,6 +75,22 @@ type Router struct {
 	manager *ConnectionManager
 	logger  *zap.Logger
 	metrics RouterMetrics
+
+	// echoToSender also delivers message events to the sender's own connection,
+	// so clients can reconcile optimistic bubbles with the final message_id/created_at.
+	echoToSender bool
+
+	// typing tracks typing sessions of local clients; nil disables typing indicators
+	typing *TypingTracker
+
+	// participants authorizes typists and lists their receivers; nil ignores typing frames
+	participants ParticipantLister
+
+	// typingRelay carries typing events to and from other gateways; nil keeps them local
+	typingRelay *TypingRelay
+
+	// messages completes slim message events before delivery; nil delivers them slim
+	messages MessageLoader
 }
 
 // NewRouter creates a new message router.
@@ -43,12 +102,18 @@ func NewRouter(manager *ConnectionManager, logger *zap.Logger, metrics RouterMet
 	}
 }
 
+// SetEchoToSender enables or disables delivering message events back to the sender.
+// Opt-in: it doubles fan-out for one-to-one chats.
+func (r *Router) SetEchoToSender(enabled bool) {
+	r.echoToSender = enabled
+}
+
 // HandleEvent processes an event received from Redis Pub/Sub.
 // It extracts receiver_ids and dispatches to connected clients.
 func (r *Router) HandleEvent(ctx context.Context, event EventPayload) {
-	// Only handle message events
-	if event.AggregateType != "message" {
-		r.logger.Debug("Ignoring non-message event",
+	// Only handle events that carry receiver_ids (messages, reactions, read receipts)
+	if !isRoutableAggregate(event.AggregateType) {
+		r.logger.Debug("Ignoring non-routable event",
 			zap.String("event_id", event.EventID),
 			zap.String("aggregate_type", event.AggregateType),
 		)
@@ -65,6 +130,11 @@ func (r *Router) HandleEvent(ctx context.Context, event EventPayload) {
 		return
 	}
 
+	// Oversized messages arrive ids-only: load the body once, only if someone here receives it
+	if innerPayload.Slim && r.messages != nil && r.hasLocalRecipient(innerPayload) {
+		event = r.hydrateSlimEvent(ctx, event, innerPayload.MessageID)
+	}
+
 	// Prepare the message to send to clients (full event)
 	messageJSON, err := json.Marshal(event)
 	if err != nil {
@@ -75,18 +145,95 @@ func (r *Router) HandleEvent(ctx context.Context, event EventPayload) {
 		return
 	}
 
-	// Route to each receiver
+	if failures := r.fanOut(event, innerPayload, messageJSON); len(failures) > 0 {
+		// Each failure was logged and counted as dropped; summarize once per event
+		r.logger.Warn("Event not delivered to some connections",
+			zap.String("event_id", event.EventID),
+			zap.String("request_id", innerPayload.RequestID),
+			zap.Int("failed", len(failures)),
+			zap.Error(errors.Join(failures...)),
+		)
+	}
+}
+
+// fanOut queues the event on every connection it is addressed to and returns the
+// per-connection failures. A failed connection never stops delivery to the others.
+func (r *Router) fanOut(event EventPayload, innerPayload InnerMessagePayload, messageJSON []byte) []error {
+	var failures []error
+
+	// Route to every device of each receiver
 	for _, receiverID := range innerPayload.ReceiverIDs {
-		r.dispatchToUser(receiverID, messageJSON, event.EventID)
+		failures = append(failures, r.dispatchToUser(receiverID, messageJSON, event.EventID, innerPayload.RequestID, "")...)
+	}
+
+	switch {
+	case innerPayload.EventType == readSelfEvent:
+		// Only for the reader's own devices; a no-op when none but the origin device is connected here
+		failures = append(failures, r.dispatchToUser(innerPayload.UserID, messageJSON, event.EventID, innerPayload.RequestID, innerPayload.OriginDeviceID)...)
+	case r.shouldEchoToSender(event, innerPayload):
+		// Echo message back to every device of the sender as delivery confirmation (opt-in)
+		failures = append(failures, r.dispatchToUser(innerPayload.SenderID, messageJSON, event.EventID, innerPayload.RequestID, "")...)
+	case r.shouldSyncActorDevices(innerPayload):
+		// Sync the acting user's other devices; the origin device already knows
+		failures = append(failures, r.dispatchToUser(innerPayload.actorID(), messageJSON, event.EventID, innerPayload.RequestID, innerPayload.OriginDeviceID)...)
+	}
+	return failures
+}
+
+// shouldSyncActorDevices reports whether the acting user's other devices should receive
+// this event: a message sent or a reaction on one device shows up on the rest.
+// Only requests that name their device carry origin_device_id. Read receipts are left
+// out, the reader's devices are synced by the conversation.read_self event.
+func (r *Router) shouldSyncActorDevices(payload InnerMessagePayload) bool {
+	actorID := payload.actorID()
+	if payload.OriginDeviceID == "" || actorID == "" || payload.EventType == conversationReadEvent {
+		return false
+	}
+	// Actor already listed as receiver - all devices got it
+	for _, receiverID := range payload.ReceiverIDs {
+		if receiverID == actorID {
+			return false
+		}
 	}
+	return true
 }
 
-// dispatchToUser attempts to send a message to a specific user.
+// shouldEchoToSender reports whether the sender should also receive this event.
+// System messages always reach the sender; other messages only with echoToSender.
+func (r *Router) shouldEchoToSender(event EventPayload, payload InnerMessagePayload) bool {
+	if event.AggregateType != "message" || payload.SenderID == "" {
+		return false
+	}
+	if !r.echoToSender && payload.Type != messageTypeSystem {
+		return false
+	}
+	// Sender already listed as receiver - avoid delivering twice
+	for _, receiverID := range payload.ReceiverIDs {
+		if receiverID == payload.SenderID {
+			return false
+		}
+	}
+	return true
+}
+
+// isRoutableAggregate reports whether events of the given aggregate type
+// should be fanned out to their receiver_ids.
+func isRoutableAggregate(aggregateType string) bool {
+	switch aggregateType {
+	case "message", "conversation":
+		return true
+	default:
+		return false
+	}
+}
+
+// dispatchToUser attempts to send a message to every device of a specific user,
+// except skipDeviceID when set, and returns a DeliveryError per device that failed.
 // If the user is not connected to this gateway, the message is ignored (local filtering).
-func (r *Router) dispatchToUser(userID string, message []byte, eventID string) {
+func (r *Router) dispatchToUser(userID string, message []byte, eventID, requestID, skipDeviceID string) []error {
 	// Local lookup - check if user is connected to THIS gateway
-	client, ok := r.manager.Get(userID)
-	if !ok {
+	clients := r.manager.Clients(userID)
+	if len(clients) == 0 {
 		// User not connected to this gateway - this is EXPECTED in multi-gateway setup
 		// Don't count as "dropped" - it's just local filtering (ignore silently)
 		r.logger.Debug("User not connected to this gateway, ignoring",
@@ -94,9 +241,37 @@ func (r *Router) dispatchToUser(userID string, message []byte, eventID string) {
 			zap.String("event_id", eventID),
 		)
 		// NO metric increment here - not an error!
-		return
+		return nil
+	}
+
+	var failures []error
+	for _, client := range clients {
+		if skipDeviceID != "" && client.DeviceID == skipDeviceID {
+			continue
+		}
+		if err := r.dispatchToClient(userID, client, message, eventID, requestID); err != nil {
+			failures = append(failures, &DeliveryError{UserID: userID, DeviceID: client.DeviceID, Err: err})
+		}
 	}
+	return failures
+}
 
+// DispatchToDevice sends a message to one device of a user, e.g. to address only
+// the device that made a request. Returns false if the device is not connected
+// to this gateway or its send buffer is full.
+func (r *Router) DispatchToDevice(userID, deviceID string, message []byte) bool {
+	if !r.manager.SendToDevice(userID, deviceID, message) {
+		return false
+	}
+	if r.metrics != nil {
+		r.metrics.IncMessagesSent()
+	}
+	return true
+}
+
+// dispatchToClient sends a message to one connection of userID.
+// It returns ErrClientClosed or ErrSendBufferFull if the message was dropped.
+func (r *Router) dispatchToClient(userID string, client *Client, message []byte, eventID, requestID string) error {
 	// Check if client is closed
 	if client.IsClosed() {
 		r.logger.Debug("Client connection closed, skipping",
@@ -107,26 +282,48 @@ func (r *Router) dispatchToUser(userID string, message []byte, eventID string) {
 		if r.metrics != nil {
 			r.metrics.IncMessagesDropped()
 		}
-		return
+		return ErrClientClosed
+	}
+
+	// The outbox is at-least-once: skip events this connection already received
+	if !client.MarkDelivered(eventID) {
+		r.logger.Debug("Duplicate event for connection, skipping",
+			zap.String("user_id", userID),
+			zap.String("event_id", eventID),
+			zap.String("request_id", requestID),
+		)
+		return nil
 	}
 
 	// Dispatch message through the client's send channel (thread-safe)
 	// The writePump goroutine will handle actual WebSocket write
-	select {
-	case client.Send <- message:
+	err := client.TrySend(message)
+	switch {
+	case err == nil:
 		r.logger.Debug("Message dispatched to user",
 			zap.String("user_id", userID),
 			zap.String("event_id", eventID),
+			zap.String("request_id", requestID),
 		)
 		if r.metrics != nil {
 			r.metrics.IncMessagesSent()
 		}
+	case errors.Is(err, ErrClientClosed):
+		// Closed since the check above; TrySend holds the client lock, so this cannot panic
+		r.logger.Debug("Client connection closed, skipping",
+			zap.String("user_id", userID),
+			zap.String("event_id", eventID),
+		)
+		if r.metrics != nil {
+			r.metrics.IncMessagesDropped()
+		}
 	default:
 		// Channel full - client is a "slow client" (network lag, app crashed but socket not closed)
 		// MUST forcefully close this connection to prevent memory leak
 		r.logger.Warn("Slow client detected, closing connection",
 			zap.String("user_id", userID),
 			zap.String("event_id", eventID),
+			zap.String("request_id", requestID),
 		)
 		if r.metrics != nil {
 			r.metrics.IncMessagesDropped()
@@ -134,4 +331,5 @@ func (r *Router) dispatchToUser(userID string, message []byte, eventID string) {
 		// Forcefully close the connection - this will trigger cleanup in readPump/writePump
 		r.manager.Remove(userID, client)
 	}
+	return err
 }
@@ -116,8 +116,8 @@ func TestRouter_HandleEvent_IgnoresNonMessageEvents(t *testing.T) {
 	// Create non-message event
 	event := EventPayload{
 		EventID:       "event-001",
-		AggregateType: "conversation", // Not "message"
-		AggregateID:   "conv-123",
+		AggregateType: "user", // Not a routable aggregate
+		AggregateID:   "user-123",
 		Payload:       []byte(`{}`),
 		CreatedAt:     time.Now().UnixMilli(),
 	}
@@ -137,6 +137,129 @@ func TestRouter_HandleEvent_IgnoresNonMessageEvents(t *testing.T) {
 	assert.Equal(t, int64(0), metrics.GetMessagesDropped())
 }
 
+func TestRouter_HandleEvent_ReadReceiptRouting(t *testing.T) {
+	logger := zap.NewNop()
+	manager := NewConnectionManager()
+	metrics := &mockMetrics{}
+	router := NewRouter(manager, logger, metrics)
+
+	senderID := "user-1"
+	client := &Client{Send: make(chan []byte, 10)}
+	manager.Add(senderID, client)
+
+	event := EventPayload{
+		EventID:       "event-002",
+		AggregateType: "conversation",
+		AggregateID:   "conv-123",
+		Payload:       []byte(`{"event_type":"conversation.read","conversation_id":"conv-123","user_id":"user-2","receiver_ids":["user-1"]}`),
+		CreatedAt:     time.Now().UnixMilli(),
+	}
+
+	router.HandleEvent(context.Background(), event)
+
+	select {
+	case msg := <-client.Send:
+		var received EventPayload
+		require.NoError(t, json.Unmarshal(msg, &received))
+		assert.Equal(t, event.EventID, received.EventID)
+		assert.Equal(t, "conversation", received.AggregateType)
+	case <-time.After(100 * time.Millisecond):
+		t.Fatal("sender did not receive read receipt")
+	}
+
+	assert.Equal(t, int64(1), metrics.GetMessagesSent())
+}
+
+func TestRouter_HandleEvent_EchoToSender(t *testing.T) {
+	newEvent := func(receivers ...string) EventPayload {
+		innerJSON, _ := json.Marshal(InnerMessagePayload{
+			EventType:   "message.sent",
+			MessageID:   "msg-123",
+			SenderID:    "sender-1",
+			ReceiverIDs: receivers,
+		})
+		return EventPayload{EventID: "event-003", AggregateType: "message", Payload: innerJSON}
+	}
+
+	t.Run("disabled by default", func(t *testing.T) {
+		manager := NewConnectionManager()
+		metrics := &mockMetrics{}
+		router := NewRouter(manager, zap.NewNop(), metrics)
+
+		sender := &Client{Send: make(chan []byte, 10)}
+		manager.Add("sender-1", sender)
+
+		router.HandleEvent(context.Background(), newEvent("user-2"))
+
+		assert.Len(t, sender.Send, 0, "sender should not receive its own message")
+	})
+
+	t.Run("enabled delivers to sender", func(t *testing.T) {
+		manager := NewConnectionManager()
+		metrics := &mockMetrics{}
+		router := NewRouter(manager, zap.NewNop(), metrics)
+		router.SetEchoToSender(true)
+
+		sender := &Client{Send: make(chan []byte, 10)}
+		receiver := &Client{Send: make(chan []byte, 10)}
+		manager.Add("sender-1", sender)
+		manager.Add("user-2", receiver)
+
+		router.HandleEvent(context.Background(), newEvent("user-2"))
+
+		assert.Len(t, sender.Send, 1)
+		assert.Len(t, receiver.Send, 1)
+		assert.Equal(t, int64(2), metrics.GetMessagesSent())
+	})
+
+	t.Run("enabled does not duplicate when sender is a receiver", func(t *testing.T) {
+		manager := NewConnectionManager()
+		router := NewRouter(manager, zap.NewNop(), &mockMetrics{})
+		router.SetEchoToSender(true)
+
+		sender := &Client{Send: make(chan []byte, 10)}
+		manager.Add("sender-1", sender)
+
+		router.HandleEvent(context.Background(), newEvent("sender-1"))
+
+		assert.Len(t, sender.Send, 1)
+	})
+
+	t.Run("system messages reach the sender without echo", func(t *testing.T) {
+		manager := NewConnectionManager()
+		router := NewRouter(manager, zap.NewNop(), &mockMetrics{})
+
+		sender := &Client{Send: make(chan []byte, 10)}
+		manager.Add("sender-1", sender)
+
+		innerJSON, _ := json.Marshal(InnerMessagePayload{
+			EventType:   "message.sent",
+			MessageID:   "msg-124",
+			SenderID:    "sender-1",
+			ReceiverIDs: []string{"user-2"},
+			Type:        "SYSTEM",
+		})
+		router.HandleEvent(context.Background(), EventPayload{EventID: "event-004", AggregateType: "message", Payload: innerJSON})
+
+		assert.Len(t, sender.Send, 1)
+	})
+
+	t.Run("read receipts are not echoed", func(t *testing.T) {
+		manager := NewConnectionManager()
+		router := NewRouter(manager, zap.NewNop(), &mockMetrics{})
+		router.SetEchoToSender(true)
+
+		sender := &Client{Send: make(chan []byte, 10)}
+		manager.Add("sender-1", sender)
+
+		event := newEvent("user-2")
+		event.AggregateType = "conversation"
+		router.HandleEvent(context.Background(), event)
+
+		assert.Len(t, sender.Send, 0)
+	})
+}
+
 func TestRouter_HandleEvent_ClosedClient(t *testing.T) {
 	logger := zap.NewNop()
 	manager := NewConnectionManager()
@@ -205,6 +328,75 @@ func TestRouter_HandleEvent_FullSendChannel(t *testing.T) {
 	assert.False(t, exists, "slow client should be removed from manager")
 }
 
+func TestRouter_HandleEvent_PartialFailureDeliversToHealthyClients(t *testing.T) {
+	manager := NewConnectionManager()
+	metrics := &mockMetrics{}
+	router := NewRouter(manager, zap.NewNop(), metrics)
+
+	// Failing connections sit between healthy ones in the receiver list
+	healthy1 := &Client{Send: make(chan []byte, 10)}
+	closed := &Client{Send: make(chan []byte, 10)}
+	closed.Close()
+	full := &Client{Send: make(chan []byte, 1)}
+	full.Send <- []byte("blocking message")
+	healthyPhone := &Client{DeviceID: "phone", Send: make(chan []byte, 10)}
+	closedLaptop := &Client{DeviceID: "laptop", Send: make(chan []byte, 10)}
+	closedLaptop.Close()
+	manager.Add("user-1", healthy1)
+	manager.Add("user-2", closed)
+	manager.Add("user-3", full)
+	manager.Add("user-4", healthyPhone)
+	manager.Add("user-4", closedLaptop)
+
+	innerPayload := InnerMessagePayload{
+		EventType:   "message.sent",
+		SenderID:    "user-0",
+		ReceiverIDs: []string{"user-2", "user-1", "user-3", "user-4"},
+	}
+	innerJSON, _ := json.Marshal(innerPayload)
+	event := EventPayload{
+		EventID:       "event-001",
+		AggregateType: "message",
+		Payload:       innerJSON,
+	}
+
+	failures := router.fanOut(event, innerPayload, innerJSON)
+	require.Len(t, failures, 3)
+	var deliveryErr *DeliveryError
+	require.ErrorAs(t, failures[0], &deliveryErr)
+	assert.Equal(t, "user-2", deliveryErr.UserID)
+	assert.ErrorIs(t, failures[0], ErrClientClosed)
+	assert.ErrorIs(t, failures[1], ErrSendBufferFull)
+	require.ErrorAs(t, failures[2], &deliveryErr)
+	assert.Equal(t, "laptop", deliveryErr.DeviceID)
+	assert.ErrorIs(t, failures[2], ErrClientClosed)
+
+	assert.Len(t, healthy1.Send, 1)
+	assert.Len(t, healthyPhone.Send, 1)
+	assert.Equal(t, int64(2), metrics.GetMessagesSent())
+	assert.Equal(t, int64(3), metrics.GetMessagesDropped())
+	_, exists := manager.Get("user-3")
+	assert.False(t, exists, "slow client should be removed from manager")
+
+	// HandleEvent fans out the same way; the slow client is gone by now
+	router.HandleEvent(context.Background(), EventPayload{
+		EventID:       "event-002",
+		AggregateType: "message",
+		Payload:       innerJSON,
+	})
+	assert.Len(t, healthy1.Send, 2)
+	assert.Len(t, healthyPhone.Send, 2)
+}
+
+func TestClient_TrySendAfterClose(t *testing.T) {
+	client := &Client{Send: make(chan []byte, 1)}
+	require.NoError(t, client.TrySend([]byte("first")))
+	assert.ErrorIs(t, client.TrySend([]byte("second")), ErrSendBufferFull)
+
+	client.Close()
+	assert.ErrorIs(t, client.TrySend([]byte("third")), ErrClientClosed, "must not panic on the closed channel")
+}
+
 func TestRouter_HandleEvent_InvalidPayload(t *testing.T) {
 	logger := zap.NewNop()
 	manager := NewConnectionManager()
@@ -289,3 +481,256 @@ func TestRouter_NilMetrics(t *testing.T) {
 type mockConn struct {
 	*websocket.Conn
 }
+
+func TestRouter_HandleEvent_DropsDuplicateEventPerConnection(t *testing.T) {
+	logger := zap.NewNop()
+	manager := NewConnectionManager()
+	metrics := &mockMetrics{}
+	router := NewRouter(manager, logger, metrics)
+
+	client1 := &Client{Send: make(chan []byte, 10), recentEvents: newEventIDRing(recentEventIDsSize)}
+	client2 := &Client{Send: make(chan []byte, 10), recentEvents: newEventIDRing(recentEventIDsSize)}
+	manager.Add("user-1", client1)
+	manager.Add("user-2", client2)
+
+	innerJSON, _ := json.Marshal(InnerMessagePayload{
+		EventType:   "message.sent",
+		MessageID:   "msg-123",
+		SenderID:    "sender-789",
+		ReceiverIDs: []string{"user-1", "user-2"},
+	})
+	event := EventPayload{
+		EventID:       "event-001",
+		AggregateType: "message",
+		AggregateID:   "msg-123",
+		Payload:       innerJSON,
+	}
+
+	// The outbox processor re-publishes the same event after a retry
+	router.HandleEvent(context.Background(), event)
+	router.HandleEvent(context.Background(), event)
+
+	assert.Len(t, client1.Send, 1, "user-1 should receive the event once")
+	assert.Len(t, client2.Send, 1, "user-2 should receive the event once")
+	assert.Equal(t, int64(2), metrics.GetMessagesSent())
+	assert.Equal(t, int64(0), metrics.GetMessagesDropped(), "duplicates are not delivery failures")
+
+	// A different event still goes through
+	event.EventID = "event-002"
+	router.HandleEvent(context.Background(), event)
+	assert.Len(t, client1.Send, 2)
+}
+
+func TestRouter_HandleEvent_DeliversToAllReceiverDevices(t *testing.T) {
+	manager := NewConnectionManager()
+	metrics := &mockMetrics{}
+	router := NewRouter(manager, zap.NewNop(), metrics)
+
+	phone := &Client{DeviceID: "phone", Send: make(chan []byte, 10)}
+	laptop := &Client{DeviceID: "laptop", Send: make(chan []byte, 10)}
+	manager.Add("user-1", phone)
+	manager.Add("user-1", laptop)
+
+	innerJSON, _ := json.Marshal(InnerMessagePayload{
+		EventType:   "message.sent",
+		SenderID:    "user-2",
+		ReceiverIDs: []string{"user-1"},
+	})
+	router.HandleEvent(context.Background(), EventPayload{
+		EventID:       "event-001",
+		AggregateType: "message",
+		Payload:       innerJSON,
+	})
+
+	assert.Len(t, phone.Send, 1)
+	assert.Len(t, laptop.Send, 1)
+	assert.Equal(t, int64(2), metrics.GetMessagesSent())
+}
+
+func TestRouter_HandleEvent_SyncsActorDevicesExceptOrigin(t *testing.T) {
+	tests := []struct {
+		name          string
+		aggregateType string
+		payload       InnerMessagePayload
+	}{
+		{
+			name:          "message sent from another device",
+			aggregateType: "message",
+			payload: InnerMessagePayload{
+				EventType:      "message.sent",
+				SenderID:       "user-1",
+				ReceiverIDs:    []string{"user-2"},
+				OriginDeviceID: "phone",
+			},
+		},
+		{
+			name:          "reaction added on another device",
+			aggregateType: "message",
+			payload: InnerMessagePayload{
+				EventType:      "reaction_added",
+				MessageID:      "msg-123",
+				UserID:         "user-1",
+				ReceiverIDs:    []string{"user-2"},
+				OriginDeviceID: "phone",
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			manager := NewConnectionManager()
+			router := NewRouter(manager, zap.NewNop(), &mockMetrics{})
+
+			phone := &Client{DeviceID: "phone", Send: make(chan []byte, 10)}
+			laptop := &Client{DeviceID: "laptop", Send: make(chan []byte, 10)}
+			receiver := &Client{Send: make(chan []byte, 10)}
+			manager.Add("user-1", phone)
+			manager.Add("user-1", laptop)
+			manager.Add("user-2", receiver)
+
+			innerJSON, _ := json.Marshal(tt.payload)
+			router.HandleEvent(context.Background(), EventPayload{
+				EventID:       "event-001",
+				AggregateType: tt.aggregateType,
+				Payload:       innerJSON,
+			})
+
+			assert.Len(t, receiver.Send, 1)
+			assert.Len(t, laptop.Send, 1, "other devices of the actor are synced")
+			assert.Empty(t, phone.Send, "origin device is not re-pushed")
+		})
+	}
+}
+
+func TestRouter_HandleEvent_ReadSelf(t *testing.T) {
+	newReadSelfEvent := func(originDeviceID string) EventPayload {
+		innerJSON, _ := json.Marshal(InnerMessagePayload{
+			EventType:      "conversation.read_self",
+			ConversationID: "conv-123",
+			UserID:         "user-1",
+			ReceiverIDs:    []string{},
+			OriginDeviceID: originDeviceID,
+		})
+		return EventPayload{EventID: "event-001", AggregateType: "conversation", Payload: innerJSON}
+	}
+
+	t.Run("other devices of the reader", func(t *testing.T) {
+		manager := NewConnectionManager()
+		metrics := &mockMetrics{}
+		router := NewRouter(manager, zap.NewNop(), metrics)
+
+		phone := &Client{DeviceID: "phone", Send: make(chan []byte, 10)}
+		laptop := &Client{DeviceID: "laptop", Send: make(chan []byte, 10)}
+		tablet := &Client{DeviceID: "tablet", Send: make(chan []byte, 10)}
+		other := &Client{Send: make(chan []byte, 10)}
+		manager.Add("user-1", phone)
+		manager.Add("user-1", laptop)
+		manager.Add("user-1", tablet)
+		manager.Add("user-2", other)
+
+		router.HandleEvent(context.Background(), newReadSelfEvent("phone"))
+
+		assert.Empty(t, phone.Send, "the device that read already knows")
+		assert.Len(t, laptop.Send, 1)
+		assert.Len(t, tablet.Send, 1)
+		assert.Empty(t, other.Send, "other participants get conversation.read instead")
+		assert.Equal(t, int64(2), metrics.GetMessagesSent())
+	})
+
+	t.Run("no origin device reaches every device", func(t *testing.T) {
+		manager := NewConnectionManager()
+		router := NewRouter(manager, zap.NewNop(), &mockMetrics{})
+
+		phone := &Client{DeviceID: "phone", Send: make(chan []byte, 10)}
+		laptop := &Client{DeviceID: "laptop", Send: make(chan []byte, 10)}
+		manager.Add("user-1", phone)
+		manager.Add("user-1", laptop)
+
+		router.HandleEvent(context.Background(), newReadSelfEvent(""))
+
+		assert.Len(t, phone.Send, 1)
+		assert.Len(t, laptop.Send, 1)
+	})
+
+	t.Run("no other device connected is a no-op", func(t *testing.T) {
+		manager := NewConnectionManager()
+		metrics := &mockMetrics{}
+		router := NewRouter(manager, zap.NewNop(), metrics)
+
+		phone := &Client{DeviceID: "phone", Send: make(chan []byte, 10)}
+		manager.Add("user-1", phone)
+
+		router.HandleEvent(context.Background(), newReadSelfEvent("phone"))
+
+		assert.Empty(t, phone.Send)
+		assert.Zero(t, metrics.GetMessagesSent())
+		assert.Zero(t, metrics.GetMessagesDropped())
+	})
+}
+
+func TestRouter_HandleEvent_ReadReceiptDoesNotSyncReader(t *testing.T) {
+	manager := NewConnectionManager()
+	router := NewRouter(manager, zap.NewNop(), &mockMetrics{})
+
+	phone := &Client{DeviceID: "phone", Send: make(chan []byte, 10)}
+	laptop := &Client{DeviceID: "laptop", Send: make(chan []byte, 10)}
+	receiver := &Client{Send: make(chan []byte, 10)}
+	manager.Add("user-1", phone)
+	manager.Add("user-1", laptop)
+	manager.Add("user-2", receiver)
+
+	innerJSON, _ := json.Marshal(InnerMessagePayload{
+		EventType:      "conversation.read",
+		UserID:         "user-1",
+		ReceiverIDs:    []string{"user-2"},
+		OriginDeviceID: "phone",
+	})
+	router.HandleEvent(context.Background(), EventPayload{
+		EventID:       "event-001",
+		AggregateType: "conversation",
+		Payload:       innerJSON,
+	})
+
+	assert.Len(t, receiver.Send, 1)
+	assert.Empty(t, laptop.Send, "the reader's devices are synced by conversation.read_self")
+}
+
+func TestRouter_HandleEvent_NoOriginDeviceDoesNotSyncActor(t *testing.T) {
+	manager := NewConnectionManager()
+	router := NewRouter(manager, zap.NewNop(), &mockMetrics{})
+
+	sender := &Client{DeviceID: "phone", Send: make(chan []byte, 10)}
+	manager.Add("user-1", sender)
+
+	innerJSON, _ := json.Marshal(InnerMessagePayload{
+		EventType:   "message.sent",
+		SenderID:    "user-1",
+		ReceiverIDs: []string{"user-2"},
+	})
+	router.HandleEvent(context.Background(), EventPayload{
+		EventID:       "event-001",
+		AggregateType: "message",
+		Payload:       innerJSON,
+	})
+
+	assert.Empty(t, sender.Send)
+}
+
+func TestRouter_DispatchToDevice(t *testing.T) {
+	manager := NewConnectionManager()
+	metrics := &mockMetrics{}
+	router := NewRouter(manager, zap.NewNop(), metrics)
+
+	phone := &Client{DeviceID: "phone", Send: make(chan []byte, 10)}
+	laptop := &Client{DeviceID: "laptop", Send: make(chan []byte, 10)}
+	manager.Add("user-1", phone)
+	manager.Add("user-1", laptop)
+
+	assert.True(t, router.DispatchToDevice("user-1", "laptop", []byte("hello")))
+	assert.False(t, router.DispatchToDevice("user-1", "tablet", []byte("hello")))
+	assert.False(t, router.DispatchToDevice("user-2", "laptop", []byte("hello")))
+
+	assert.Len(t, laptop.Send, 1)
+	assert.Empty(t, phone.Send)
+	assert.Equal(t, int64(1), metrics.GetMessagesSent())
+}
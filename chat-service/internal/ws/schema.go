@@ -0,0 +1,36 @@
+package ws
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+)
+
+// SupportedSchemaVersion is the newest outbox event layout this gateway can read
+// (outbox.SchemaVersion of the processors it is deployed with, or newer)
+const SupportedSchemaVersion = 1
+
+// ErrUnsupportedSchemaVersion is returned by DecodeEvent for events newer than SupportedSchemaVersion
+var ErrUnsupportedSchemaVersion = errors.New("unsupported event schema version")
+
+// DecodeEvent parses an event published by the outbox processor according to its schema_version,
+// returning it in the current layout. Unknown fields are ignored at every version, which is
+// what lets producers add optional fields without a version bump. Events of a version newer than
+// SupportedSchemaVersion are rejected rather than misread.
+func DecodeEvent(data []byte) (EventPayload, error) {
+	var event EventPayload
+	if err := json.Unmarshal(data, &event); err != nil {
+		return EventPayload{}, err
+	}
+
+	switch event.SchemaVersion {
+	case 0:
+		// Published before events were versioned; the layout is that of version 1
+		event.SchemaVersion = 1
+		return event, nil
+	case 1:
+		return event, nil
+	default:
+		return EventPayload{}, fmt.Errorf("%w: %d (supported up to %d)", ErrUnsupportedSchemaVersion, event.SchemaVersion, SupportedSchemaVersion)
+	}
+}
@@ -0,0 +1,54 @@
+package ws
+
+import (
+	"context"
+	"testing"
+
+	"github.com/redis/go-redis/v9"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+func TestDecodeEvent(t *testing.T) {
+	t.Run("current version", func(t *testing.T) {
+		event, err := DecodeEvent([]byte(`{"schema_version":1,"event_id":"e1","aggregate_type":"message","aggregate_id":"m1","payload":{"content":"hi"},"created_at":42,"added_later":true}`))
+		require.NoError(t, err)
+		assert.Equal(t, 1, event.SchemaVersion)
+		assert.Equal(t, "e1", event.EventID)
+		assert.Equal(t, "message", event.AggregateType)
+		assert.Equal(t, int64(42), event.CreatedAt)
+		assert.JSONEq(t, `{"content":"hi"}`, string(event.Payload))
+	})
+
+	t.Run("unversioned events read as version 1", func(t *testing.T) {
+		event, err := DecodeEvent([]byte(`{"event_id":"e1","aggregate_type":"message","payload":{}}`))
+		require.NoError(t, err)
+		assert.Equal(t, 1, event.SchemaVersion)
+		assert.Equal(t, "e1", event.EventID)
+	})
+
+	t.Run("newer version", func(t *testing.T) {
+		_, err := DecodeEvent([]byte(`{"schema_version":2,"event_id":"e1"}`))
+		assert.ErrorIs(t, err, ErrUnsupportedSchemaVersion)
+	})
+
+	t.Run("malformed", func(t *testing.T) {
+		_, err := DecodeEvent([]byte(`{"schema_version":"one"}`))
+		assert.Error(t, err)
+		assert.NotErrorIs(t, err, ErrUnsupportedSchemaVersion)
+	})
+}
+
+func TestSubscriber_SkipsUnsupportedSchemaVersion(t *testing.T) {
+	var handled []EventPayload
+	sub := NewSubscriber(nil, zap.NewNop(), func(ctx context.Context, event EventPayload) {
+		handled = append(handled, event)
+	})
+
+	sub.processMessage(context.Background(), &redis.Message{Payload: `{"schema_version":2,"event_id":"future"}`})
+	sub.processMessage(context.Background(), &redis.Message{Payload: `{"schema_version":1,"event_id":"current"}`})
+
+	require.Len(t, handled, 1)
+	assert.Equal(t, "current", handled[0].EventID)
+}
@@ -0,0 +1,297 @@
+package ws
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"sync"
+	"time"
+
+	"chat-service/pkg/eventshard"
+
+	"github.com/redis/go-redis/v9"
+	"go.uber.org/zap"
+)
+
+// shardSubscribeTimeout bounds SUBSCRIBE/UNSUBSCRIBE calls made outside a request context.
+const shardSubscribeTimeout = 5 * time.Second
+
+// ConversationLister returns the IDs of the conversations a user participates in.
+type ConversationLister interface {
+	ListConversationIDs(ctx context.Context, userID string) ([]string, error)
+}
+
+// membershipPayload is the part of an event payload announcing new participants.
+type membershipPayload struct {
+	ConversationID  string   `json:"conversation_id"`
+	SenderID        string   `json:"sender_id"`
+	ReceiverIDs     []string `json:"receiver_ids"`
+	NewParticipants bool     `json:"new_participants"`
+}
+
+// shardedUser tracks the connections and shards of one connected user.
+type shardedUser struct {
+	conns  int
+	shards map[int]struct{}
+}
+
+// ShardedSubscriber receives chat events from Pub/Sub shard channels (chat:events:{n}).
+// It subscribes only to the shards of the conversations its connected users belong to,
+// reference-counted across users, plus the ChannelName broadcast channel. Events that
+// add participants arrive on the broadcast channel and subscribe the new members' shards.
+type ShardedSubscriber struct {
+	redis   *redis.Client
+	logger  *zap.Logger
+	handler EventHandler
+	lister  ConversationLister
+	shards  int
+
+	mu      sync.Mutex
+	pubsub  *redis.PubSub
+	running bool
+	cancel  context.CancelFunc
+	users   map[string]*shardedUser
+	refs    map[int]int // shard -> number of users needing it
+}
+
+var _ EventSource = (*ShardedSubscriber)(nil)
+
+// NewShardedSubscriber creates a subscriber over the given number of shards.
+func NewShardedSubscriber(redisClient *redis.Client, logger *zap.Logger, handler EventHandler, lister ConversationLister, shards int) *ShardedSubscriber {
+	return &ShardedSubscriber{
+		redis:   redisClient,
+		logger:  logger,
+		handler: handler,
+		lister:  lister,
+		shards:  shards,
+		users:   make(map[string]*shardedUser),
+		refs:    make(map[int]int),
+	}
+}
+
+// Start subscribes to the broadcast channel and begins listening.
+// Shard channels are added as users connect. go-redis resubscribes all of them
+// itself when the connection is re-established.
+func (s *ShardedSubscriber) Start(ctx context.Context) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.running {
+		return nil
+	}
+
+	// Users added before Start keep their shards
+	channels := []string{ChannelName}
+	for shard := range s.refs {
+		channels = append(channels, eventshard.Channel(ChannelName, shard))
+	}
+
+	pubsub := s.redis.Subscribe(ctx, channels...)
+	if _, err := pubsub.Receive(ctx); err != nil {
+		_ = pubsub.Close()
+		return err
+	}
+
+	ctx, cancel := context.WithCancel(ctx)
+	s.pubsub = pubsub
+	s.cancel = cancel
+	s.running = true
+
+	s.logger.Info("Subscribed to sharded Redis Pub/Sub",
+		zap.String("broadcast_channel", ChannelName),
+		zap.Int("shards", s.shards),
+	)
+
+	go s.listen(ctx, pubsub.Channel())
+	return nil
+}
+
+// listen handles messages until the subscription is closed.
+func (s *ShardedSubscriber) listen(ctx context.Context, ch <-chan *redis.Message) {
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case msg, ok := <-ch:
+			if !ok {
+				s.logger.Info("Sharded Pub/Sub channel closed")
+				return
+			}
+			s.processMessage(ctx, msg)
+		}
+	}
+}
+
+// processMessage parses a message, tracks announced memberships and calls the handler.
+func (s *ShardedSubscriber) processMessage(ctx context.Context, msg *redis.Message) {
+	event, err := DecodeEvent([]byte(msg.Payload))
+	if err != nil {
+		s.logger.Error("Failed to decode event",
+			zap.Error(err),
+			zap.String("channel", msg.Channel),
+		)
+		return
+	}
+
+	s.observeMembership(ctx, event)
+
+	if s.handler != nil {
+		s.handler(ctx, event)
+	}
+}
+
+// observeMembership subscribes the conversation's shard for connected users who just joined it,
+// so later events of the conversation (published on its shard) reach them.
+func (s *ShardedSubscriber) observeMembership(ctx context.Context, event EventPayload) {
+	var payload membershipPayload
+	if err := json.Unmarshal(event.Payload, &payload); err != nil || !payload.NewParticipants || payload.ConversationID == "" {
+		return
+	}
+
+	shard := eventshard.Of(payload.ConversationID, s.shards)
+	members := append([]string{payload.SenderID}, payload.ReceiverIDs...)
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	var added []int
+	for _, userID := range members {
+		if user, ok := s.users[userID]; ok {
+			added = append(added, s.addShardsLocked(user, []int{shard})...)
+		}
+	}
+	s.subscribeLocked(ctx, added)
+}
+
+// AddUser records a new connection of userID and subscribes the shards of its conversations.
+// Call RemoveUser when the connection closes.
+func (s *ShardedSubscriber) AddUser(ctx context.Context, userID string) error {
+	conversationIDs, err := s.lister.ListConversationIDs(ctx, userID)
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	user, ok := s.users[userID]
+	if !ok {
+		user = &shardedUser{shards: make(map[int]struct{})}
+		s.users[userID] = user
+	}
+	user.conns++
+
+	if err != nil {
+		// Still tracked: new conversations announced on the broadcast channel reach the user
+		return err
+	}
+
+	shards := make([]int, 0, len(conversationIDs))
+	for _, id := range conversationIDs {
+		shards = append(shards, eventshard.Of(id, s.shards))
+	}
+	s.subscribeLocked(ctx, s.addShardsLocked(user, shards))
+	return nil
+}
+
+// RemoveUser records a closed connection of userID. When its last connection closes,
+// shards no other connected user needs are unsubscribed.
+func (s *ShardedSubscriber) RemoveUser(userID string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	user, ok := s.users[userID]
+	if !ok {
+		return
+	}
+	user.conns--
+	if user.conns > 0 {
+		return
+	}
+	delete(s.users, userID)
+
+	var released []string
+	for shard := range user.shards {
+		s.refs[shard]--
+		if s.refs[shard] <= 0 {
+			delete(s.refs, shard)
+			released = append(released, eventshard.Channel(ChannelName, shard))
+		}
+	}
+
+	if len(released) == 0 || s.pubsub == nil {
+		return
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), shardSubscribeTimeout)
+	defer cancel()
+	if err := s.pubsub.Unsubscribe(ctx, released...); err != nil {
+		s.logger.Warn("Failed to unsubscribe shard channels", zap.Strings("channels", released), zap.Error(err))
+	}
+}
+
+// addShardsLocked adds shards to a user and returns those no other user needed yet.
+func (s *ShardedSubscriber) addShardsLocked(user *shardedUser, shards []int) []int {
+	var added []int
+	for _, shard := range shards {
+		if _, ok := user.shards[shard]; ok {
+			continue
+		}
+		user.shards[shard] = struct{}{}
+		s.refs[shard]++
+		if s.refs[shard] == 1 {
+			added = append(added, shard)
+		}
+	}
+	return added
+}
+
+// subscribeLocked subscribes the given shard channels.
+func (s *ShardedSubscriber) subscribeLocked(ctx context.Context, shards []int) {
+	if len(shards) == 0 || s.pubsub == nil {
+		return
+	}
+	channels := make([]string, len(shards))
+	for i, shard := range shards {
+		channels[i] = eventshard.Channel(ChannelName, shard)
+	}
+
+	ctx, cancel := context.WithTimeout(context.WithoutCancel(ctx), shardSubscribeTimeout)
+	defer cancel()
+	if err := s.pubsub.Subscribe(ctx, channels...); err != nil {
+		s.logger.Warn("Failed to subscribe shard channels", zap.Strings("channels", channels), zap.Error(err))
+	}
+}
+
+// SubscribedShards returns the number of shard channels currently subscribed.
+func (s *ShardedSubscriber) SubscribedShards() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return len(s.refs)
+}
+
+// Stop closes the subscription.
+func (s *ShardedSubscriber) Stop() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if !s.running {
+		return nil
+	}
+	s.running = false
+
+	if s.cancel != nil {
+		s.cancel()
+	}
+	if s.pubsub != nil {
+		if err := s.pubsub.Close(); err != nil && !errors.Is(err, redis.ErrClosed) {
+			s.logger.Error("Failed to close Pub/Sub", zap.Error(err))
+			return err
+		}
+		s.pubsub = nil
+	}
+
+	s.logger.Info("Sharded subscriber stopped")
+	return nil
+}
+
+// IsRunning returns whether the subscriber is currently running.
+func (s *ShardedSubscriber) IsRunning() bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.running
+}
@@ -0,0 +1,204 @@
+package ws
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"chat-service/pkg/eventshard"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+const testShards = 4
+
+// stubConversationLister returns fixed conversations per user
+type stubConversationLister struct {
+	conversations map[string][]string
+	err           error
+}
+
+func (l *stubConversationLister) ListConversationIDs(ctx context.Context, userID string) ([]string, error) {
+	return l.conversations[userID], l.err
+}
+
+// conversationsOnDistinctShards returns n conversation IDs that map to n different shards
+func conversationsOnDistinctShards(t *testing.T, n int) []string {
+	t.Helper()
+	seen := make(map[int]bool)
+	var ids []string
+	for i := 0; len(ids) < n && i < 1000; i++ {
+		id := fmt.Sprintf("conv-%d", i)
+		if shard := eventshard.Of(id, testShards); !seen[shard] {
+			seen[shard] = true
+			ids = append(ids, id)
+		}
+	}
+	require.Len(t, ids, n)
+	return ids
+}
+
+func publishConversationEvent(t *testing.T, mr *miniredis.Miniredis, channel, eventID, conversationID string, newParticipants bool, receiverIDs ...string) {
+	t.Helper()
+	inner, err := json.Marshal(membershipPayload{
+		ConversationID:  conversationID,
+		ReceiverIDs:     receiverIDs,
+		NewParticipants: newParticipants,
+	})
+	require.NoError(t, err)
+	data, err := json.Marshal(EventPayload{EventID: eventID, AggregateType: "message", Payload: inner})
+	require.NoError(t, err)
+	mr.Publish(channel, string(data))
+}
+
+func shardChannel(conversationID string) string {
+	return eventshard.ChannelFor(ChannelName, conversationID, testShards)
+}
+
+func startShardedSubscriber(t *testing.T, lister ConversationLister) (*miniredis.Miniredis, *ShardedSubscriber, *eventRecorder) {
+	t.Helper()
+	mr, client := setupTestRedis(t)
+	recorder := &eventRecorder{}
+	sub := NewShardedSubscriber(client, zap.NewNop(), recorder.handle, lister, testShards)
+	require.NoError(t, sub.Start(context.Background()))
+	t.Cleanup(func() { _ = sub.Stop() })
+	return mr, sub, recorder
+}
+
+func TestShardedSubscriber_ReceivesOnlyOwnShards(t *testing.T) {
+	convs := conversationsOnDistinctShards(t, 2)
+	lister := &stubConversationLister{conversations: map[string][]string{"user-1": {convs[0]}}}
+	mr, sub, recorder := startShardedSubscriber(t, lister)
+
+	require.NoError(t, sub.AddUser(context.Background(), "user-1"))
+	assert.Equal(t, 1, sub.SubscribedShards())
+	waitForSubscription(t, mr, shardChannel(convs[0]))
+	assert.NotContains(t, mr.PubSubChannels(""), shardChannel(convs[1]))
+
+	publishConversationEvent(t, mr, shardChannel(convs[1]), "other-shard", convs[1], false)
+	publishConversationEvent(t, mr, shardChannel(convs[0]), "own-shard", convs[0], false)
+
+	require.Eventually(t, func() bool { return len(recorder.ids()) == 1 }, time.Second, 10*time.Millisecond)
+	assert.Equal(t, []string{"own-shard"}, recorder.ids())
+}
+
+func TestShardedSubscriber_RefCountsShardsAcrossUsers(t *testing.T) {
+	convs := conversationsOnDistinctShards(t, 2)
+	lister := &stubConversationLister{conversations: map[string][]string{
+		"user-1": {convs[0], convs[1]},
+		"user-2": {convs[0]},
+	}}
+	mr, sub, _ := startShardedSubscriber(t, lister)
+	ctx := context.Background()
+
+	require.NoError(t, sub.AddUser(ctx, "user-1"))
+	require.NoError(t, sub.AddUser(ctx, "user-2"))
+	assert.Equal(t, 2, sub.SubscribedShards())
+
+	sub.RemoveUser("user-1")
+	assert.Equal(t, 1, sub.SubscribedShards(), "shard still needed by user-2")
+	require.Eventually(t, func() bool {
+		channels := mr.PubSubChannels("")
+		return !contains(channels, shardChannel(convs[1])) && contains(channels, shardChannel(convs[0]))
+	}, time.Second, 10*time.Millisecond)
+
+	sub.RemoveUser("user-2")
+	assert.Zero(t, sub.SubscribedShards())
+	require.Eventually(t, func() bool {
+		return !contains(mr.PubSubChannels(""), shardChannel(convs[0]))
+	}, time.Second, 10*time.Millisecond)
+	assert.Contains(t, mr.PubSubChannels(""), ChannelName, "broadcast channel stays subscribed")
+}
+
+func TestShardedSubscriber_KeepsShardsWhileUserHasConnections(t *testing.T) {
+	convs := conversationsOnDistinctShards(t, 1)
+	lister := &stubConversationLister{conversations: map[string][]string{"user-1": convs}}
+	_, sub, _ := startShardedSubscriber(t, lister)
+	ctx := context.Background()
+
+	// Reconnect: the new connection is added before the old one is removed
+	require.NoError(t, sub.AddUser(ctx, "user-1"))
+	require.NoError(t, sub.AddUser(ctx, "user-1"))
+	sub.RemoveUser("user-1")
+	assert.Equal(t, 1, sub.SubscribedShards())
+
+	sub.RemoveUser("user-1")
+	assert.Zero(t, sub.SubscribedShards())
+}
+
+// TestShardedSubscriber_NewParticipantsSubscribeShard verifies a user added to a conversation
+// after connecting receives its later events
+func TestShardedSubscriber_NewParticipantsSubscribeShard(t *testing.T) {
+	convs := conversationsOnDistinctShards(t, 1)
+	lister := &stubConversationLister{}
+	mr, sub, recorder := startShardedSubscriber(t, lister)
+
+	require.NoError(t, sub.AddUser(context.Background(), "user-1"))
+	assert.Zero(t, sub.SubscribedShards())
+
+	// First message of a new conversation is broadcast
+	publishConversationEvent(t, mr, ChannelName, "first", convs[0], true, "user-1")
+	require.Eventually(t, func() bool { return sub.SubscribedShards() == 1 }, time.Second, 10*time.Millisecond)
+	waitForSubscription(t, mr, shardChannel(convs[0]))
+
+	publishConversationEvent(t, mr, shardChannel(convs[0]), "second", convs[0], false, "user-1")
+	require.Eventually(t, func() bool { return len(recorder.ids()) == 2 }, time.Second, 10*time.Millisecond)
+	assert.Equal(t, []string{"first", "second"}, recorder.ids())
+}
+
+func TestShardedSubscriber_NewParticipantsIgnoredForOtherUsers(t *testing.T) {
+	convs := conversationsOnDistinctShards(t, 1)
+	mr, sub, recorder := startShardedSubscriber(t, &stubConversationLister{})
+
+	publishConversationEvent(t, mr, ChannelName, "first", convs[0], true, "user-elsewhere")
+	require.Eventually(t, func() bool { return len(recorder.ids()) == 1 }, time.Second, 10*time.Millisecond)
+	assert.Zero(t, sub.SubscribedShards())
+}
+
+func TestShardedSubscriber_ListerErrorStillTracksUser(t *testing.T) {
+	convs := conversationsOnDistinctShards(t, 1)
+	mr, sub, _ := startShardedSubscriber(t, &stubConversationLister{err: errors.New("db down")})
+
+	assert.Error(t, sub.AddUser(context.Background(), "user-1"))
+
+	publishConversationEvent(t, mr, ChannelName, "first", convs[0], true, "user-1")
+	require.Eventually(t, func() bool { return sub.SubscribedShards() == 1 }, time.Second, 10*time.Millisecond)
+}
+
+func TestShardedSubscriber_StartStop(t *testing.T) {
+	_, client := setupTestRedis(t)
+	convs := conversationsOnDistinctShards(t, 1)
+	lister := &stubConversationLister{conversations: map[string][]string{"user-1": convs}}
+	sub := NewShardedSubscriber(client, zap.NewNop(), nil, lister, testShards)
+
+	// Users tracked before Start are subscribed on Start
+	require.NoError(t, sub.AddUser(context.Background(), "user-1"))
+	require.NoError(t, sub.Start(context.Background()))
+	assert.True(t, sub.IsRunning())
+	require.NoError(t, sub.Start(context.Background()))
+
+	require.NoError(t, sub.Stop())
+	assert.False(t, sub.IsRunning())
+	require.NoError(t, sub.Stop())
+}
+
+// waitForSubscription waits until the SUBSCRIBE sent for channel reached Redis
+func waitForSubscription(t *testing.T, mr *miniredis.Miniredis, channel string) {
+	t.Helper()
+	require.Eventually(t, func() bool { return contains(mr.PubSubChannels(""), channel) }, time.Second, 10*time.Millisecond)
+}
+
+func contains(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,288 @@
+package ws
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+	"go.uber.org/zap"
+)
+
+const (
+	// StreamName is the Redis Stream the outbox processor appends chat events to in stream mode.
+	StreamName = "chat:events:stream"
+
+	// StreamPayloadField is the stream entry field holding the JSON EventPayload.
+	StreamPayloadField = "payload"
+
+	// StreamGroupPrefix prefixes the consumer group of each gateway instance.
+	// Every instance needs every event (its users may be in any conversation),
+	// so each one reads through its own group rather than sharing one.
+	StreamGroupPrefix = "ws-gateway:"
+
+	// Stream read settings
+	streamReadBlock = 2 * time.Second
+	streamReadCount = 100
+)
+
+// EventSource delivers chat events from Redis to a handler (Pub/Sub or Stream).
+type EventSource interface {
+	Start(ctx context.Context) error
+	Stop() error
+	IsRunning() bool
+}
+
+var (
+	_ EventSource = (*Subscriber)(nil)
+	_ EventSource = (*StreamSubscriber)(nil)
+)
+
+// AckEventHandler takes an event to handle asynchronously and calls ack once it was handled.
+// A handler that gives up on an event (drops it, or stops first) never calls ack.
+type AckEventHandler func(ctx context.Context, event EventPayload, ack func())
+
+// StreamSubscriber consumes chat events from a Redis Stream through a consumer group
+// (XREADGROUP/XACK). Entries are acknowledged after the handler ran, so events published
+// while the gateway was down, or read but not handled before a crash, are delivered
+// when it comes back with the same consumer name.
+type StreamSubscriber struct {
+	redis      *redis.Client
+	logger     *zap.Logger
+	handler    EventHandler
+	ackHandler AckEventHandler // replaces handler when set (see SetAckHandler)
+	group      string
+	consumer   string
+
+	block time.Duration
+	count int64
+
+	// Backoff between attempts after a Redis error
+	initialReconnectDelay time.Duration
+	maxReconnectDelay     time.Duration
+
+	mu      sync.Mutex
+	running bool
+	cancel  context.CancelFunc
+	done    chan struct{}
+}
+
+// NewStreamSubscriber creates a stream subscriber reading as consumer through the group
+// StreamGroupPrefix+consumer. consumer must be stable across restarts (see GetInstanceID)
+// for the gateway to resume where it left off.
+func NewStreamSubscriber(redisClient *redis.Client, logger *zap.Logger, handler EventHandler, consumer string) *StreamSubscriber {
+	return &StreamSubscriber{
+		redis:                 redisClient,
+		logger:                logger,
+		handler:               handler,
+		group:                 StreamGroupPrefix + consumer,
+		consumer:              consumer,
+		block:                 streamReadBlock,
+		count:                 streamReadCount,
+		initialReconnectDelay: initialReconnectDelay,
+		maxReconnectDelay:     maxReconnectDelay,
+	}
+}
+
+// Start creates the consumer group if needed and begins reading.
+// This method is non-blocking and starts a goroutine that retries on Redis errors.
+func (s *StreamSubscriber) Start(ctx context.Context) error {
+	s.mu.Lock()
+	if s.running {
+		s.mu.Unlock()
+		return nil
+	}
+	s.running = true
+
+	ctx, cancel := context.WithCancel(ctx)
+	s.cancel = cancel
+	done := make(chan struct{})
+	s.done = done
+	s.mu.Unlock()
+
+	if err := s.ensureGroup(ctx); err != nil {
+		s.mu.Lock()
+		s.running = false
+		s.mu.Unlock()
+		cancel()
+		return err
+	}
+
+	s.logger.Info("Consuming Redis Stream",
+		zap.String("stream", StreamName),
+		zap.String("group", s.group),
+		zap.String("consumer", s.consumer),
+	)
+
+	go func() {
+		defer close(done)
+		s.consume(ctx)
+	}()
+
+	return nil
+}
+
+// ensureGroup creates the consumer group at the end of the stream.
+// An existing group keeps its position, which is what lets a restart resume.
+func (s *StreamSubscriber) ensureGroup(ctx context.Context) error {
+	err := s.redis.XGroupCreateMkStream(ctx, StreamName, s.group, "$").Err()
+	if err != nil && !strings.HasPrefix(err.Error(), "BUSYGROUP") {
+		return err
+	}
+	return nil
+}
+
+// consume first drains entries delivered to this consumer but never acknowledged
+// (a crash between read and ack), then reads new entries until ctx is cancelled.
+func (s *StreamSubscriber) consume(ctx context.Context) {
+	pendingCursor := "0"
+	delay := s.initialReconnectDelay
+
+	for ctx.Err() == nil {
+		readID := ">"
+		if pendingCursor != "" {
+			readID = pendingCursor
+		}
+
+		streams, err := s.redis.XReadGroup(ctx, &redis.XReadGroupArgs{
+			Group:    s.group,
+			Consumer: s.consumer,
+			Streams:  []string{StreamName, readID},
+			Count:    s.count,
+			Block:    s.block,
+		}).Result()
+		if errors.Is(err, redis.Nil) {
+			continue // block timed out without new entries
+		}
+		if err != nil {
+			if ctx.Err() != nil {
+				break
+			}
+			s.logger.Error("Failed to read from Redis Stream",
+				zap.Error(err),
+				zap.Duration("next_retry", delay),
+			)
+			// The stream or group was deleted (e.g. FLUSHALL): recreate it
+			if strings.HasPrefix(err.Error(), "NOGROUP") {
+				_ = s.ensureGroup(ctx)
+			}
+			select {
+			case <-ctx.Done():
+			case <-time.After(delay):
+			}
+			delay *= reconnectBackoffMulti
+			if delay > s.maxReconnectDelay {
+				delay = s.maxReconnectDelay
+			}
+			continue
+		}
+		delay = s.initialReconnectDelay
+		if ctx.Err() != nil {
+			break // stopped while blocked: leave what we read pending for the next start
+		}
+
+		var messages []redis.XMessage
+		for _, stream := range streams {
+			messages = append(messages, stream.Messages...)
+		}
+
+		if pendingCursor != "" {
+			if len(messages) == 0 {
+				pendingCursor = "" // backlog drained, switch to new entries
+				continue
+			}
+			pendingCursor = messages[len(messages)-1].ID
+			s.logger.Info("Redelivering unacknowledged stream entries", zap.Int("count", len(messages)))
+		}
+
+		for _, msg := range messages {
+			if ctx.Err() != nil {
+				break
+			}
+			s.processEntry(ctx, msg)
+		}
+	}
+
+	s.logger.Info("Stream subscriber context cancelled, stopping consumer")
+}
+
+// SetAckHandler replaces the handler with one that handles entries asynchronously, e.g.
+// EventDispatcher.HandleAck: each entry is acknowledged when the handler calls ack, and entries
+// it never acknowledges stay pending until the next Start. Call before Start.
+func (s *StreamSubscriber) SetAckHandler(handler AckEventHandler) {
+	s.ackHandler = handler
+}
+
+// processEntry handles one stream entry and acknowledges it.
+// Malformed entries are acknowledged too, otherwise they would be redelivered forever.
+func (s *StreamSubscriber) processEntry(ctx context.Context, msg redis.XMessage) {
+	raw, _ := msg.Values[StreamPayloadField].(string)
+
+	if event, err := DecodeEvent([]byte(raw)); err != nil {
+		s.logger.Error("Failed to decode stream entry",
+			zap.Error(err),
+			zap.String("entry_id", msg.ID),
+			zap.String("payload", raw),
+		)
+	} else {
+		s.logger.Debug("Received event from Redis Stream",
+			zap.String("entry_id", msg.ID),
+			zap.String("event_id", event.EventID),
+			zap.String("aggregate_type", event.AggregateType),
+		)
+		if s.ackHandler != nil {
+			s.ackHandler(ctx, event, func() { s.ack(ctx, msg.ID) })
+			return
+		}
+		if s.handler != nil {
+			s.handler(ctx, event)
+		}
+	}
+
+	s.ack(ctx, msg.ID)
+}
+
+// ack acknowledges a handled entry
+func (s *StreamSubscriber) ack(ctx context.Context, id string) {
+	if err := s.redis.XAck(ctx, StreamName, s.group, id).Err(); err != nil && ctx.Err() == nil {
+		// Left pending: redelivered after the next restart, the per-connection dedup drops repeats
+		s.logger.Warn("Failed to acknowledge stream entry",
+			zap.String("entry_id", id),
+			zap.Error(err),
+		)
+	}
+}
+
+// Stop stops reading and waits for the consumer goroutine to exit (at most one
+// block interval). Entries read but not yet acknowledged stay pending and are
+// redelivered on the next Start.
+func (s *StreamSubscriber) Stop() error {
+	s.mu.Lock()
+	if !s.running {
+		s.mu.Unlock()
+		return nil
+	}
+
+	s.running = false
+	if s.cancel != nil {
+		s.cancel()
+	}
+	done := s.done
+	s.mu.Unlock()
+
+	if done != nil {
+		<-done
+	}
+
+	s.logger.Info("Stream subscriber stopped")
+	return nil
+}
+
+// IsRunning returns whether the subscriber is currently running.
+func (s *StreamSubscriber) IsRunning() bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.running
+}
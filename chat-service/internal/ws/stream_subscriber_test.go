@@ -0,0 +1,231 @@
+package ws
+
+import (
+	"context"
+	"encoding/json"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+// eventRecorder collects the events passed to a handler
+type eventRecorder struct {
+	mu     sync.Mutex
+	events []EventPayload
+}
+
+func (r *eventRecorder) handle(ctx context.Context, event EventPayload) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.events = append(r.events, event)
+}
+
+func (r *eventRecorder) ids() []string {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	ids := make([]string, len(r.events))
+	for i, e := range r.events {
+		ids[i] = e.EventID
+	}
+	return ids
+}
+
+func newTestStreamSubscriber(client *redis.Client, handler EventHandler, consumer string) *StreamSubscriber {
+	sub := NewStreamSubscriber(client, zap.NewNop(), handler, consumer)
+	sub.block = 50 * time.Millisecond
+	sub.initialReconnectDelay = 10 * time.Millisecond
+	return sub
+}
+
+func addStreamEvent(t *testing.T, client *redis.Client, eventID string) {
+	t.Helper()
+	data, err := json.Marshal(EventPayload{
+		EventID:       eventID,
+		AggregateType: "message",
+		AggregateID:   "conv-123",
+		CreatedAt:     time.Now().UnixMilli(),
+	})
+	require.NoError(t, err)
+	require.NoError(t, client.XAdd(context.Background(), &redis.XAddArgs{
+		Stream: StreamName,
+		Values: map[string]interface{}{StreamPayloadField: data},
+	}).Err())
+}
+
+func pendingCount(t *testing.T, client *redis.Client, group string) int64 {
+	t.Helper()
+	pending, err := client.XPending(context.Background(), StreamName, group).Result()
+	require.NoError(t, err)
+	return pending.Count
+}
+
+func TestStreamSubscriber_StartStop(t *testing.T) {
+	_, client := setupTestRedis(t)
+	sub := newTestStreamSubscriber(client, nil, "gw-1")
+
+	require.NoError(t, sub.Start(context.Background()))
+	assert.True(t, sub.IsRunning())
+	require.NoError(t, sub.Start(context.Background()), "starting again should be a no-op")
+
+	require.NoError(t, sub.Stop())
+	assert.False(t, sub.IsRunning())
+	require.NoError(t, sub.Stop(), "stopping again should be a no-op")
+
+	groups, err := client.XInfoGroups(context.Background(), StreamName).Result()
+	require.NoError(t, err)
+	require.Len(t, groups, 1)
+	assert.Equal(t, StreamGroupPrefix+"gw-1", groups[0].Name)
+}
+
+func TestStreamSubscriber_ReceiveAndAck(t *testing.T) {
+	_, client := setupTestRedis(t)
+	recorder := &eventRecorder{}
+	sub := newTestStreamSubscriber(client, recorder.handle, "gw-1")
+
+	require.NoError(t, sub.Start(context.Background()))
+	defer sub.Stop()
+
+	addStreamEvent(t, client, "event-1")
+	addStreamEvent(t, client, "event-2")
+
+	require.Eventually(t, func() bool { return len(recorder.ids()) == 2 }, time.Second, 10*time.Millisecond)
+	assert.Equal(t, []string{"event-1", "event-2"}, recorder.ids())
+	require.Eventually(t, func() bool { return pendingCount(t, client, sub.group) == 0 }, time.Second, 10*time.Millisecond)
+}
+
+// TestStreamSubscriber_ResumesAfterRestart verifies events published while the gateway was down are delivered
+func TestStreamSubscriber_ResumesAfterRestart(t *testing.T) {
+	_, client := setupTestRedis(t)
+	recorder := &eventRecorder{}
+
+	first := newTestStreamSubscriber(client, recorder.handle, "gw-1")
+	require.NoError(t, first.Start(context.Background()))
+	addStreamEvent(t, client, "before-restart")
+	require.Eventually(t, func() bool { return len(recorder.ids()) == 1 }, time.Second, 10*time.Millisecond)
+	require.NoError(t, first.Stop())
+
+	// Published while no gateway is consuming: Pub/Sub would drop these
+	addStreamEvent(t, client, "during-restart-1")
+	addStreamEvent(t, client, "during-restart-2")
+
+	second := newTestStreamSubscriber(client, recorder.handle, "gw-1")
+	require.NoError(t, second.Start(context.Background()))
+	defer second.Stop()
+
+	require.Eventually(t, func() bool { return len(recorder.ids()) == 3 }, time.Second, 10*time.Millisecond)
+	assert.Equal(t, []string{"before-restart", "during-restart-1", "during-restart-2"}, recorder.ids())
+}
+
+// TestStreamSubscriber_RedeliversPendingEntries verifies entries read but never acknowledged
+// (crash between read and ack) are handled on the next start
+func TestStreamSubscriber_RedeliversPendingEntries(t *testing.T) {
+	_, client := setupTestRedis(t)
+	ctx := context.Background()
+	group := StreamGroupPrefix + "gw-1"
+
+	require.NoError(t, client.XGroupCreateMkStream(ctx, StreamName, group, "$").Err())
+	addStreamEvent(t, client, "unacked")
+	// A previous run read the entry and died before acknowledging it
+	_, err := client.XReadGroup(ctx, &redis.XReadGroupArgs{
+		Group:    group,
+		Consumer: "gw-1",
+		Streams:  []string{StreamName, ">"},
+	}).Result()
+	require.NoError(t, err)
+	require.Equal(t, int64(1), pendingCount(t, client, group))
+
+	recorder := &eventRecorder{}
+	sub := newTestStreamSubscriber(client, recorder.handle, "gw-1")
+	require.NoError(t, sub.Start(ctx))
+	defer sub.Stop()
+
+	addStreamEvent(t, client, "new")
+
+	require.Eventually(t, func() bool { return len(recorder.ids()) == 2 }, time.Second, 10*time.Millisecond)
+	assert.Equal(t, []string{"unacked", "new"}, recorder.ids())
+	require.Eventually(t, func() bool { return pendingCount(t, client, group) == 0 }, time.Second, 10*time.Millisecond)
+}
+
+// TestStreamSubscriber_GroupPerInstance verifies every gateway instance receives every event
+func TestStreamSubscriber_GroupPerInstance(t *testing.T) {
+	_, client := setupTestRedis(t)
+	recorderA := &eventRecorder{}
+	recorderB := &eventRecorder{}
+
+	subA := newTestStreamSubscriber(client, recorderA.handle, "gw-a")
+	subB := newTestStreamSubscriber(client, recorderB.handle, "gw-b")
+	require.NoError(t, subA.Start(context.Background()))
+	defer subA.Stop()
+	require.NoError(t, subB.Start(context.Background()))
+	defer subB.Stop()
+
+	addStreamEvent(t, client, "event-1")
+
+	require.Eventually(t, func() bool {
+		return len(recorderA.ids()) == 1 && len(recorderB.ids()) == 1
+	}, time.Second, 10*time.Millisecond)
+}
+
+// TestStreamSubscriber_InvalidEntryAcked verifies malformed entries are skipped without blocking the stream
+func TestStreamSubscriber_InvalidEntryAcked(t *testing.T) {
+	_, client := setupTestRedis(t)
+	recorder := &eventRecorder{}
+	sub := newTestStreamSubscriber(client, recorder.handle, "gw-1")
+
+	require.NoError(t, sub.Start(context.Background()))
+	defer sub.Stop()
+
+	require.NoError(t, client.XAdd(context.Background(), &redis.XAddArgs{
+		Stream: StreamName,
+		Values: map[string]interface{}{StreamPayloadField: "invalid json {{{"},
+	}).Err())
+	addStreamEvent(t, client, "valid-event")
+
+	require.Eventually(t, func() bool { return len(recorder.ids()) == 1 }, time.Second, 10*time.Millisecond)
+	assert.Equal(t, []string{"valid-event"}, recorder.ids())
+	require.Eventually(t, func() bool { return pendingCount(t, client, sub.group) == 0 }, time.Second, 10*time.Millisecond)
+}
+
+// TestStreamSubscriber_AckHandler verifies entries are acknowledged only once the async handler
+// calls ack, and entries it gives up on are redelivered on the next start
+func TestStreamSubscriber_AckHandler(t *testing.T) {
+	_, client := setupTestRedis(t)
+	recorder := &eventRecorder{}
+	sub := newTestStreamSubscriber(client, nil, "gw-1")
+	sub.SetAckHandler(func(ctx context.Context, event EventPayload, ack func()) {
+		recorder.handle(ctx, event)
+		if event.EventID != "dropped" {
+			ack()
+		}
+	})
+
+	require.NoError(t, sub.Start(context.Background()))
+	addStreamEvent(t, client, "handled")
+	addStreamEvent(t, client, "dropped")
+	require.Eventually(t, func() bool { return len(recorder.ids()) == 2 }, time.Second, 10*time.Millisecond)
+	assert.Equal(t, int64(1), pendingCount(t, client, sub.group))
+	require.NoError(t, sub.Stop())
+
+	redelivered := &eventRecorder{}
+	second := newTestStreamSubscriber(client, redelivered.handle, "gw-1")
+	require.NoError(t, second.Start(context.Background()))
+	defer second.Stop()
+
+	require.Eventually(t, func() bool { return len(redelivered.ids()) == 1 }, time.Second, 10*time.Millisecond)
+	assert.Equal(t, []string{"dropped"}, redelivered.ids())
+	require.Eventually(t, func() bool { return pendingCount(t, client, sub.group) == 0 }, time.Second, 10*time.Millisecond)
+}
+
+func TestStreamSubscriber_StartFailsWithoutRedis(t *testing.T) {
+	mr, client := setupTestRedis(t)
+	mr.SetError("connection refused")
+
+	sub := newTestStreamSubscriber(client, nil, "gw-1")
+	assert.Error(t, sub.Start(context.Background()))
+	assert.False(t, sub.IsRunning())
+}
@@ -3,6 +3,7 @@ package ws
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"sync"
 	"time"
 
@@ -22,6 +23,7 @@ const (
 
 // EventPayload represents the JSON payload received from Redis Pub/Sub.
 type EventPayload struct {
+	SchemaVersion int             `json:"schema_version"` // See DecodeEvent
 	EventID       string          `json:"event_id"`
 	AggregateType string          `json:"aggregate_type"`
 	AggregateID   string          `json:"aggregate_id"`
@@ -41,14 +43,32 @@ type MessagePayload struct {
 // EventHandler is called when an event is received from Redis Pub/Sub.
 type EventHandler func(ctx context.Context, event EventPayload)
 
+// SubscriberMetrics tracks Pub/Sub subscription health.
+type SubscriberMetrics interface {
+	IncResubscriptions(reason string)
+}
+
+// Resubscription reasons
+const (
+	// ResubscribeChannelClosed: the Pub/Sub channel closed and we subscribed again
+	ResubscribeChannelClosed = "channel_closed"
+	// ResubscribeConnectionReset: go-redis re-established the connection and re-sent SUBSCRIBE itself
+	ResubscribeConnectionReset = "connection_reset"
+)
+
 // Subscriber subscribes to Redis Pub/Sub channel and processes events.
 type Subscriber struct {
 	redis   *redis.Client
 	logger  *zap.Logger
 	handler EventHandler
-	pubsub  *redis.PubSub
+	metrics SubscriberMetrics
+
+	// Backoff between resubscribe attempts
+	initialReconnectDelay time.Duration
+	maxReconnectDelay     time.Duration
 
 	mu      sync.Mutex
+	pubsub  *redis.PubSub
 	running bool
 	cancel  context.CancelFunc
 }
@@ -56,12 +76,18 @@ type Subscriber struct {
 // NewSubscriber creates a new Redis Pub/Sub subscriber.
 func NewSubscriber(redisClient *redis.Client, logger *zap.Logger, handler EventHandler) *Subscriber {
 	return &Subscriber{
-		redis:   redisClient,
-		logger:  logger,
-		handler: handler,
+		redis:                 redisClient,
+		logger:                logger,
+		handler:               handler,
+		initialReconnectDelay: initialReconnectDelay,
+		maxReconnectDelay:     maxReconnectDelay,
 	}
 }
 
+// SetMetrics enables resubscription metrics. Call before Start.
+func (s *Subscriber) SetMetrics(metrics SubscriberMetrics) {
+	s.metrics = metrics
+}
 
 // Start begins listening to the Redis Pub/Sub channel.
 // This method is non-blocking and starts a goroutine with auto-reconnection.
@@ -72,17 +98,18 @@ func (s *Subscriber) Start(ctx context.Context) error {
 		return nil
 	}
 	s.running = true
-	s.mu.Unlock()
 
 	// Create cancellable context
 	ctx, cancel := context.WithCancel(ctx)
 	s.cancel = cancel
+	s.mu.Unlock()
 
 	// Initial subscription
 	if err := s.subscribe(ctx); err != nil {
 		s.mu.Lock()
 		s.running = false
 		s.mu.Unlock()
+		cancel()
 		return err
 	}
 
@@ -94,83 +121,119 @@ func (s *Subscriber) Start(ctx context.Context) error {
 
 // subscribe creates a new subscription to the Redis Pub/Sub channel.
 func (s *Subscriber) subscribe(ctx context.Context) error {
-	s.pubsub = s.redis.Subscribe(ctx, ChannelName)
+	pubsub := s.redis.Subscribe(ctx, ChannelName)
 
 	// Wait for subscription confirmation
-	_, err := s.pubsub.Receive(ctx)
-	if err != nil {
+	if _, err := pubsub.Receive(ctx); err != nil {
+		_ = pubsub.Close()
 		return err
 	}
 
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	// Stop ran while we were subscribing; don't leak the new connection
+	if ctx.Err() != nil {
+		_ = pubsub.Close()
+		return ctx.Err()
+	}
+	s.pubsub = pubsub
+
 	s.logger.Info("Subscribed to Redis Pub/Sub channel", zap.String("channel", ChannelName))
 	return nil
 }
 
-// listenWithReconnect continuously reads messages and auto-reconnects on failure.
-func (s *Subscriber) listenWithReconnect(ctx context.Context) {
-	reconnectDelay := initialReconnectDelay
+// currentPubSub returns the active subscription.
+func (s *Subscriber) currentPubSub() *redis.PubSub {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.pubsub
+}
+
+// dropPubSub closes and forgets a subscription whose channel has closed.
+func (s *Subscriber) dropPubSub() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.pubsub != nil {
+		_ = s.pubsub.Close()
+		s.pubsub = nil
+	}
+}
 
+// listenWithReconnect continuously reads messages and resubscribes when the channel closes.
+func (s *Subscriber) listenWithReconnect(ctx context.Context) {
 	for {
-		// Check if we should stop
-		select {
-		case <-ctx.Done():
+		// Listen until channel closes
+		s.listen(ctx)
+
+		// Check if we should stop (context might have been cancelled)
+		if ctx.Err() != nil {
 			s.logger.Info("Subscriber context cancelled, stopping listener")
 			return
-		default:
 		}
 
-		// Listen until channel closes
-		s.listen(ctx)
+		// Channel closed unexpectedly: events published from now on are lost until we resubscribe
+		lostAt := time.Now()
+		s.dropPubSub()
+		s.logger.Warn("Pub/Sub channel closed, resubscribing")
 
-		// Check again if we should stop (context might have been cancelled)
-		select {
-		case <-ctx.Done():
+		attempts, ok := s.resubscribe(ctx)
+		if !ok {
 			s.logger.Info("Subscriber context cancelled during reconnect")
 			return
-		default:
 		}
 
-		// Channel closed unexpectedly, attempt reconnection
-		s.logger.Warn("Pub/Sub connection lost, attempting reconnection",
-			zap.Duration("delay", reconnectDelay),
+		s.logger.Warn("Resubscribed to Redis Pub/Sub, events published during the gap were not delivered",
+			zap.Duration("gap", time.Since(lostAt)),
+			zap.Int("attempts", attempts),
 		)
+		if s.metrics != nil {
+			s.metrics.IncResubscriptions(ResubscribeChannelClosed)
+		}
+	}
+}
+
+// resubscribe retries subscribe with exponential backoff until it succeeds.
+// It returns false only when ctx is cancelled.
+func (s *Subscriber) resubscribe(ctx context.Context) (int, bool) {
+	delay := s.initialReconnectDelay
 
+	for attempt := 1; ; attempt++ {
 		// Wait before reconnecting with backoff
 		select {
 		case <-ctx.Done():
-			return
-		case <-time.After(reconnectDelay):
+			return attempt - 1, false
+		case <-time.After(delay):
 		}
 
-		// Close old pubsub if exists
-		if s.pubsub != nil {
-			_ = s.pubsub.Close()
+		err := s.subscribe(ctx)
+		if err == nil {
+			return attempt, true
 		}
-
-		// Attempt to reconnect
-		if err := s.subscribe(ctx); err != nil {
-			s.logger.Error("Failed to reconnect to Redis Pub/Sub",
-				zap.Error(err),
-				zap.Duration("next_retry", reconnectDelay),
-			)
-
-			// Increase backoff delay
-			reconnectDelay *= reconnectBackoffMulti
-			if reconnectDelay > maxReconnectDelay {
-				reconnectDelay = maxReconnectDelay
-			}
-			continue
+		if ctx.Err() != nil {
+			return attempt, false
 		}
 
-		// Successfully reconnected, reset delay
-		s.logger.Info("Successfully reconnected to Redis Pub/Sub")
-		reconnectDelay = initialReconnectDelay
+		// Increase backoff delay
+		delay *= reconnectBackoffMulti
+		if delay > s.maxReconnectDelay {
+			delay = s.maxReconnectDelay
+		}
+		s.logger.Error("Failed to resubscribe to Redis Pub/Sub",
+			zap.Error(err),
+			zap.Int("attempt", attempt),
+			zap.Duration("next_retry", delay),
+		)
 	}
 }
 
 // listen reads messages from the Pub/Sub channel until it closes.
 func (s *Subscriber) listen(ctx context.Context) {
-	ch := s.pubsub.Channel()
+	pubsub := s.currentPubSub()
+	if pubsub == nil {
+		return
+	}
+	// Subscription confirmations show up here when go-redis reconnects on its own
+	ch := pubsub.ChannelWithSubscriptions()
 
 	for {
 		select {
@@ -184,16 +247,29 @@ func (s *Subscriber) listen(ctx context.Context) {
 				return
 			}
 
-			s.processMessage(ctx, msg)
+			switch msg := msg.(type) {
+			case *redis.Message:
+				s.processMessage(ctx, msg)
+			case *redis.Subscription:
+				// The initial confirmation was consumed by subscribe, so this is a reconnect
+				if msg.Kind == "subscribe" {
+					s.logger.Warn("Redis Pub/Sub connection was reset and resubscribed, events published during the gap were not delivered",
+						zap.String("channel", msg.Channel),
+					)
+					if s.metrics != nil {
+						s.metrics.IncResubscriptions(ResubscribeConnectionReset)
+					}
+				}
+			}
 		}
 	}
 }
 
 // processMessage parses and handles a single message.
 func (s *Subscriber) processMessage(ctx context.Context, msg *redis.Message) {
-	var event EventPayload
-	if err := json.Unmarshal([]byte(msg.Payload), &event); err != nil {
-		s.logger.Error("Failed to unmarshal event",
+	event, err := DecodeEvent([]byte(msg.Payload))
+	if err != nil {
+		s.logger.Error("Failed to decode event",
 			zap.Error(err),
 			zap.String("payload", msg.Payload),
 		)
@@ -228,7 +304,8 @@ func (s *Subscriber) Stop() error {
 	}
 
 	if s.pubsub != nil {
-		if err := s.pubsub.Close(); err != nil {
+		// Already closed when the connection was lost; nothing left to release
+		if err := s.pubsub.Close(); err != nil && !errors.Is(err, redis.ErrClosed) {
 			s.logger.Error("Failed to close Pub/Sub", zap.Error(err))
 			return err
 		}
@@ -273,6 +273,97 @@ func TestSubscriber_AutoReconnect(t *testing.T) {
 	assert.True(t, sub.IsRunning())
 }
 
+// resubscribeCounter records IncResubscriptions calls
+type resubscribeCounter struct {
+	mu      sync.Mutex
+	reasons []string
+}
+
+func (c *resubscribeCounter) IncResubscriptions(reason string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.reasons = append(c.reasons, reason)
+}
+
+func (c *resubscribeCounter) snapshot() []string {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return append([]string(nil), c.reasons...)
+}
+
+func TestSubscriber_ResubscribesAfterPubSubClosed(t *testing.T) {
+	mr, client := setupTestRedis(t)
+	logger := zap.NewNop()
+
+	received := make(chan EventPayload, 10)
+	handler := func(ctx context.Context, event EventPayload) {
+		received <- event
+	}
+
+	sub := NewSubscriber(client, logger, handler)
+	sub.initialReconnectDelay = 10 * time.Millisecond
+	metrics := &resubscribeCounter{}
+	sub.SetMetrics(metrics)
+
+	require.NoError(t, sub.Start(context.Background()))
+	defer sub.Stop()
+
+	// Kill the underlying subscription, as a dropped connection that go-redis gave up on would
+	original := sub.currentPubSub()
+	require.NoError(t, original.Close())
+
+	require.Eventually(t, func() bool {
+		return len(metrics.snapshot()) == 1
+	}, 2*time.Second, 10*time.Millisecond, "subscriber should resubscribe")
+	assert.Equal(t, []string{ResubscribeChannelClosed}, metrics.snapshot())
+	assert.NotSame(t, original, sub.currentPubSub())
+	assert.True(t, sub.IsRunning())
+
+	// Events published after recovery are delivered again
+	eventJSON, _ := json.Marshal(EventPayload{EventID: "event-after-resubscribe", AggregateType: "message"})
+	require.Eventually(t, func() bool {
+		return mr.Publish(ChannelName, string(eventJSON)) == 1
+	}, time.Second, 10*time.Millisecond, "miniredis should see the new subscriber")
+
+	select {
+	case event := <-received:
+		assert.Equal(t, "event-after-resubscribe", event.EventID)
+	case <-time.After(time.Second):
+		t.Fatal("event not delivered after resubscribe")
+	}
+}
+
+func TestSubscriber_StopDuringResubscribeBackoff(t *testing.T) {
+	_, client := setupTestRedis(t)
+	logger := zap.NewNop()
+
+	sub := NewSubscriber(client, logger, func(ctx context.Context, event EventPayload) {})
+	sub.initialReconnectDelay = time.Hour
+	metrics := &resubscribeCounter{}
+	sub.SetMetrics(metrics)
+
+	require.NoError(t, sub.Start(context.Background()))
+	require.NoError(t, sub.currentPubSub().Close())
+	require.Eventually(t, func() bool {
+		return sub.currentPubSub() == nil
+	}, time.Second, 10*time.Millisecond, "subscriber should notice the closed channel")
+
+	// Stop must not wait for the backoff
+	done := make(chan struct{})
+	go func() {
+		assert.NoError(t, sub.Stop())
+		close(done)
+	}()
+
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatal("Stop blocked during resubscribe backoff")
+	}
+	assert.False(t, sub.IsRunning())
+	assert.Empty(t, metrics.snapshot())
+}
+
 func TestSubscriber_ReconnectBackoff(t *testing.T) {
 	// This test verifies the backoff logic exists
 	// by checking the constants are properly defined
@@ -0,0 +1,34 @@
+package ws
+
+import (
+	"net/http"
+	"time"
+)
+
+// Gateway HTTP server timeouts (WS_HTTP_READ_TIMEOUT_MS, WS_HTTP_WRITE_TIMEOUT_MS, WS_HANDSHAKE_TIMEOUT_MS)
+const (
+	DefaultHTTPReadTimeout  = 10 * time.Second
+	DefaultHTTPWriteTimeout = 10 * time.Second
+	DefaultHandshakeTimeout = 10 * time.Second
+)
+
+// WithoutServerTimeouts lets a WebSocket upgrade handler escape the http.Server's ReadTimeout and
+// WriteTimeout, which are sized for short HTTP requests (health checks, metrics, admin). Both count
+// from the moment the request is read, so when authentication before the upgrade is slow the
+// response (a rejection, or the handshake with an upgrader that keeps the deadlines) is silently
+// dropped. The request gets handshakeTimeout instead (0 = no deadline). Upgrading hijacks the
+// connection and gorilla/websocket clears its deadlines; from then on the pumps set their own
+// (pongWait for reads, writeWait per write), so the server timeouts never reach a live socket.
+func WithoutServerTimeouts(next http.Handler, handshakeTimeout time.Duration) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var deadline time.Time
+		if handshakeTimeout > 0 {
+			deadline = time.Now().Add(handshakeTimeout)
+		}
+		// Only fails when w does not expose the connection; the server's deadlines then stay
+		rc := http.NewResponseController(w)
+		_ = rc.SetReadDeadline(deadline)
+		_ = rc.SetWriteDeadline(deadline)
+		next.ServeHTTP(w, r)
+	})
+}
@@ -0,0 +1,104 @@
+package ws
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gorilla/websocket"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// newSlowServer serves handler after a 100ms pause, longer than the server's 50ms read and
+// write timeouts, like a slow authentication step before the upgrade would
+func newSlowServer(t *testing.T, handler http.Handler, wrap func(http.Handler) http.Handler) *httptest.Server {
+	t.Helper()
+	slow := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		time.Sleep(100 * time.Millisecond)
+		handler.ServeHTTP(w, r)
+	})
+	if wrap != nil {
+		slow = wrap(slow).ServeHTTP
+	}
+
+	server := httptest.NewUnstartedServer(slow)
+	server.Config.ReadTimeout = 50 * time.Millisecond
+	server.Config.WriteTimeout = 50 * time.Millisecond
+	server.Start()
+	t.Cleanup(server.Close)
+	return server
+}
+
+// rejectHandler answers like serveWs does for a bad token
+var rejectHandler = http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+	http.Error(w, "Unauthorized", http.StatusUnauthorized)
+})
+
+// echoHandler upgrades and echoes messages back
+var echoHandler = http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+	upgrader := websocket.Upgrader{}
+	conn, err := upgrader.Upgrade(w, r, nil)
+	if err != nil {
+		return
+	}
+	defer conn.Close()
+	for {
+		kind, data, err := conn.ReadMessage()
+		if err != nil {
+			return
+		}
+		if err := conn.WriteMessage(kind, data); err != nil {
+			return
+		}
+	}
+})
+
+func withHandshakeTimeout(timeout time.Duration) func(http.Handler) http.Handler {
+	return func(h http.Handler) http.Handler {
+		return WithoutServerTimeouts(h, timeout)
+	}
+}
+
+func TestWithoutServerTimeouts_PreUpgradeResponse(t *testing.T) {
+	t.Run("server write timeout drops a slow response", func(t *testing.T) {
+		server := newSlowServer(t, rejectHandler, nil)
+
+		_, err := http.Get(server.URL)
+		assert.Error(t, err, "the client sees the connection close instead of a status")
+	})
+
+	t.Run("wrapped handler answers", func(t *testing.T) {
+		server := newSlowServer(t, rejectHandler, withHandshakeTimeout(time.Second))
+
+		resp, err := http.Get(server.URL)
+		require.NoError(t, err)
+		resp.Body.Close()
+		assert.Equal(t, http.StatusUnauthorized, resp.StatusCode)
+	})
+
+	t.Run("handshake timeout still applies", func(t *testing.T) {
+		server := newSlowServer(t, rejectHandler, withHandshakeTimeout(20*time.Millisecond))
+
+		_, err := http.Get(server.URL)
+		assert.Error(t, err)
+	})
+}
+
+func TestWithoutServerTimeouts_UpgradedConnection(t *testing.T) {
+	server := newSlowServer(t, echoHandler, withHandshakeTimeout(time.Second))
+
+	conn, _, err := websocket.DefaultDialer.Dial("ws"+strings.TrimPrefix(server.URL, "http"), nil)
+	require.NoError(t, err)
+	defer conn.Close()
+
+	// The connection stays usable well past both server timeouts
+	time.Sleep(100 * time.Millisecond)
+	require.NoError(t, conn.WriteMessage(websocket.TextMessage, []byte("ping")))
+	_ = conn.SetReadDeadline(time.Now().Add(time.Second))
+	_, data, err := conn.ReadMessage()
+	require.NoError(t, err)
+	assert.Equal(t, "ping", string(data))
+}
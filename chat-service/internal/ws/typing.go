@@ -0,0 +1,416 @@
+package ws
+
+import (
+	"context"
+	"encoding/json"
+	"sync"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+// Typing frames exchanged with WebSocket clients
+const (
+	// EventTypeTyping is sent by a client while its user is typing and forwarded
+	// to the other participants once per typing session.
+	EventTypeTyping = "typing"
+
+	// EventTypeTypingStopped ends a typing session. Clients send it explicitly;
+	// the gateway also emits it when a session expires or the typist disconnects.
+	EventTypeTypingStopped = "typing_stopped"
+)
+
+const (
+	// DefaultTypingTimeout is how long a typing session lasts without a refresh
+	DefaultTypingTimeout = 5 * time.Second
+
+	// DefaultTypingStartsPerMinute caps the typing sessions a user may start per minute, across
+	// conversations. Each start is relayed to every gateway, so this bounds what one client can publish.
+	DefaultTypingStartsPerMinute = 60
+
+	// typingLookupTimeout bounds the participant lookup of a session start; it runs on the typist's read pump
+	typingLookupTimeout = time.Second
+
+	// typingStartWindow is the fixed window the start limit is counted over
+	typingStartWindow = time.Minute
+)
+
+// TypingMessage is a typing frame sent by a client over the WebSocket.
+// The typist is always the authenticated user of the connection; the receivers
+// are the other participants of the conversation, looked up by the gateway.
+type TypingMessage struct {
+	Type           string `json:"type"`
+	ConversationID string `json:"conversation_id"`
+}
+
+// ParticipantLister returns the user IDs of a conversation's participants.
+type ParticipantLister interface {
+	ListParticipantIDs(ctx context.Context, conversationID string) ([]string, error)
+}
+
+// TypingEvent is delivered to the other participants of a conversation.
+// Typing events are ephemeral: they bypass the outbox and are never replayed.
+type TypingEvent struct {
+	Type           string `json:"type"`
+	ConversationID string `json:"conversation_id"`
+	UserID         string `json:"user_id"`
+	Expired        bool   `json:"expired,omitempty"` // Synthetic stop: no refresh within the idle window or the typist disconnected
+	ServerTime     int64  `json:"server_time"`       // Unix timestamp in milliseconds
+}
+
+// TypingStop describes a typing session that ended and who must be told
+type TypingStop struct {
+	ConversationID string
+	UserID         string
+	ReceiverIDs    []string
+}
+
+type typingKey struct {
+	conversationID string
+	userID         string
+}
+
+type typingSession struct {
+	lastTypingAt time.Time
+	receiverIDs  []string
+}
+
+// TypingTracker keeps the last typing time per (conversation, user) so sessions
+// whose client crashed without sending typing_stopped can be expired.
+type TypingTracker struct {
+	mu       sync.Mutex
+	timeout  time.Duration
+	sessions map[typingKey]typingSession
+
+	// maxStarts limits the sessions each user starts per typingStartWindow; 0 is unlimited
+	maxStarts   int
+	windowStart time.Time
+	starts      map[string]int
+}
+
+// NewTypingTracker creates a tracker expiring sessions idle for longer than timeout.
+// Non-positive timeout uses DefaultTypingTimeout.
+func NewTypingTracker(timeout time.Duration) *TypingTracker {
+	if timeout <= 0 {
+		timeout = DefaultTypingTimeout
+	}
+	return &TypingTracker{
+		timeout:  timeout,
+		sessions: make(map[typingKey]typingSession),
+	}
+}
+
+// SetStartLimit caps the sessions each user may start per minute; 0 removes the cap.
+// Typing frames over the cap are ignored until the next minute, refreshes and stops are not limited.
+func (t *TypingTracker) SetStartLimit(perMinute int) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.maxStarts = perMinute
+}
+
+// Timeout returns the idle window after which a session expires
+func (t *TypingTracker) Timeout() time.Duration {
+	return t.timeout
+}
+
+// Refresh moves the deadline of the session of userID in conversationID to now.
+// It reports false when there is no active session to refresh.
+func (t *TypingTracker) Refresh(conversationID, userID string, now time.Time) bool {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	key := typingKey{conversationID: conversationID, userID: userID}
+	session, active := t.sessions[key]
+	if !active {
+		return false
+	}
+	session.lastTypingAt = now
+	t.sessions[key] = session
+	return true
+}
+
+// AllowStart counts a session start of userID against the start limit.
+// It reports false when the user is over the limit and the start must be ignored.
+func (t *TypingTracker) AllowStart(userID string, now time.Time) bool {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	if t.maxStarts <= 0 {
+		return true
+	}
+	if t.starts == nil || now.Sub(t.windowStart) >= typingStartWindow {
+		t.starts = make(map[string]int)
+		t.windowStart = now
+	}
+	if t.starts[userID] >= t.maxStarts {
+		return false
+	}
+	t.starts[userID]++
+	return true
+}
+
+// Start records a session of userID in conversationID notifying receiverIDs.
+// It reports whether this starts a new session; a session started concurrently is only refreshed.
+func (t *TypingTracker) Start(conversationID, userID string, receiverIDs []string, now time.Time) bool {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	key := typingKey{conversationID: conversationID, userID: userID}
+	if session, active := t.sessions[key]; active {
+		session.lastTypingAt = now
+		t.sessions[key] = session
+		return false
+	}
+	t.sessions[key] = typingSession{lastTypingAt: now, receiverIDs: receiverIDs}
+	return true
+}
+
+// Stop ends the session of userID in conversationID.
+// ok is false when there was no active session.
+func (t *TypingTracker) Stop(conversationID, userID string) (TypingStop, bool) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	key := typingKey{conversationID: conversationID, userID: userID}
+	session, ok := t.sessions[key]
+	if !ok {
+		return TypingStop{}, false
+	}
+	delete(t.sessions, key)
+	return TypingStop{ConversationID: conversationID, UserID: userID, ReceiverIDs: session.receiverIDs}, true
+}
+
+// StopUser ends every session of userID, e.g. when the user disconnects
+func (t *TypingTracker) StopUser(userID string) []TypingStop {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	var stops []TypingStop
+	for key, session := range t.sessions {
+		if key.userID != userID {
+			continue
+		}
+		delete(t.sessions, key)
+		stops = append(stops, TypingStop{ConversationID: key.conversationID, UserID: userID, ReceiverIDs: session.receiverIDs})
+	}
+	return stops
+}
+
+// Expire ends the sessions not refreshed within the timeout before now
+func (t *TypingTracker) Expire(now time.Time) []TypingStop {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	var stops []TypingStop
+	for key, session := range t.sessions {
+		if now.Sub(session.lastTypingAt) < t.timeout {
+			continue
+		}
+		delete(t.sessions, key)
+		stops = append(stops, TypingStop{ConversationID: key.conversationID, UserID: key.userID, ReceiverIDs: session.receiverIDs})
+	}
+	return stops
+}
+
+// Count returns the number of active typing sessions
+func (t *TypingTracker) Count() int {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return len(t.sessions)
+}
+
+// SetTypingTracker enables typing indicators on the router. Sessions are only started by
+// participants of the conversation, so typing frames are ignored until SetParticipantLister too.
+func (r *Router) SetTypingTracker(tracker *TypingTracker) {
+	r.typing = tracker
+}
+
+// SetParticipantLister sets where typing sessions look up the participants of a conversation.
+func (r *Router) SetParticipantLister(participants ParticipantLister) {
+	r.participants = participants
+}
+
+// HandleClientMessage processes a frame sent by userID's client.
+// Unknown or malformed frames are ignored: clients send chat messages through the API.
+func (r *Router) HandleClientMessage(userID string, data []byte) {
+	var msg TypingMessage
+	if err := json.Unmarshal(data, &msg); err != nil {
+		r.logger.Debug("Ignoring malformed client frame", zap.String("user_id", userID), zap.Error(err))
+		return
+	}
+
+	switch msg.Type {
+	case EventTypeTyping, EventTypeTypingStopped:
+		r.HandleTyping(userID, msg)
+	default:
+		r.logger.Debug("Ignoring client frame", zap.String("user_id", userID), zap.String("type", msg.Type))
+	}
+}
+
+// HandleTyping starts, refreshes or stops the typing session of userID.
+// Only session starts and stops are forwarded, so clients may refresh as often as they like.
+// A start looks up the conversation: frames of non-participants are dropped, and the
+// other participants are the receivers of the session until it ends.
+func (r *Router) HandleTyping(userID string, msg TypingMessage) {
+	if r.typing == nil || r.participants == nil || msg.ConversationID == "" {
+		return
+	}
+
+	now := time.Now()
+	if msg.Type == EventTypeTypingStopped {
+		if stop, ok := r.typing.Stop(msg.ConversationID, userID); ok {
+			r.dispatchTypingStop(stop, false, now)
+		}
+		return
+	}
+
+	if r.typing.Refresh(msg.ConversationID, userID, now) {
+		return
+	}
+	// The limit is counted before the lookup, so it also bounds the queries one client can cause
+	if !r.typing.AllowStart(userID, now) {
+		return
+	}
+	receiverIDs, ok := r.typingReceivers(userID, msg.ConversationID)
+	if !ok {
+		return
+	}
+	if !r.typing.Start(msg.ConversationID, userID, receiverIDs, now) {
+		return
+	}
+	r.dispatchTyping(receiverIDs, TypingEvent{
+		Type:           EventTypeTyping,
+		ConversationID: msg.ConversationID,
+		UserID:         userID,
+		ServerTime:     now.UnixMilli(),
+	})
+}
+
+// StopTyping emits typing_stopped for every session of a disconnected user
+func (r *Router) StopTyping(userID string) {
+	if r.typing == nil {
+		return
+	}
+	now := time.Now()
+	for _, stop := range r.typing.StopUser(userID) {
+		r.dispatchTypingStop(stop, true, now)
+	}
+}
+
+// ExpireTyping emits a synthetic typing_stopped for sessions idle past the timeout
+func (r *Router) ExpireTyping(now time.Time) {
+	if r.typing == nil {
+		return
+	}
+	for _, stop := range r.typing.Expire(now) {
+		r.dispatchTypingStop(stop, true, now)
+	}
+}
+
+// RunTypingExpiry sweeps idle typing sessions until ctx is cancelled.
+// Sessions end between one and one and a half timeouts after their last refresh.
+func (r *Router) RunTypingExpiry(ctx context.Context) {
+	if r.typing == nil {
+		return
+	}
+
+	ticker := time.NewTicker(r.typing.Timeout() / 2)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case now := <-ticker.C:
+			r.ExpireTyping(now)
+		}
+	}
+}
+
+func (r *Router) dispatchTypingStop(stop TypingStop, expired bool, now time.Time) {
+	r.dispatchTyping(stop.ReceiverIDs, TypingEvent{
+		Type:           EventTypeTypingStopped,
+		ConversationID: stop.ConversationID,
+		UserID:         stop.UserID,
+		Expired:        expired,
+		ServerTime:     now.UnixMilli(),
+	})
+}
+
+// dispatchTyping delivers a typing event of a local client to its receivers on this gateway and,
+// with a relay, on the others. Only sessions that passed the participant check get here, and their
+// receivers are the looked up participants. Relaying is best-effort like delivery: a failure is only logged.
+func (r *Router) dispatchTyping(receiverIDs []string, event TypingEvent) {
+	r.deliverTyping(receiverIDs, event)
+
+	if r.typingRelay == nil || len(receiverIDs) == 0 {
+		return
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), typingPublishTimeout)
+	defer cancel()
+	if err := r.typingRelay.Publish(ctx, receiverIDs, event); err != nil {
+		r.logger.Warn("Failed to relay typing event",
+			zap.String("conversation_id", event.ConversationID),
+			zap.String("user_id", event.UserID),
+			zap.Error(err),
+		)
+	}
+}
+
+// deliverTyping delivers a typing event to every device of the receivers connected to this gateway.
+// Typing events are best-effort: a full send buffer drops the event instead of
+// evicting the client, since the next typing frame or stop supersedes it anyway.
+func (r *Router) deliverTyping(receiverIDs []string, event TypingEvent) {
+	data, err := json.Marshal(event)
+	if err != nil {
+		r.logger.Error("Failed to marshal typing event", zap.Error(err))
+		return
+	}
+
+	for _, receiverID := range receiverIDs {
+		for _, client := range r.manager.Clients(receiverID) {
+			if !trySend(client, data) {
+				r.logger.Debug("Dropping typing event for closed or full connection",
+					zap.String("user_id", receiverID),
+					zap.String("device_id", client.DeviceID),
+					zap.String("conversation_id", event.ConversationID),
+				)
+			}
+		}
+	}
+}
+
+// typingReceivers returns the participants of conversationID other than userID.
+// ok is false when userID is not a participant or the lookup failed.
+func (r *Router) typingReceivers(userID, conversationID string) ([]string, bool) {
+	ctx, cancel := context.WithTimeout(context.Background(), typingLookupTimeout)
+	defer cancel()
+
+	participantIDs, err := r.participants.ListParticipantIDs(ctx, conversationID)
+	if err != nil {
+		r.logger.Warn("Failed to look up typing receivers",
+			zap.String("conversation_id", conversationID),
+			zap.String("user_id", userID),
+			zap.Error(err),
+		)
+		return nil, false
+	}
+
+	member := false
+	receivers := make([]string, 0, len(participantIDs))
+	for _, participantID := range participantIDs {
+		if participantID == userID {
+			member = true
+			continue
+		}
+		receivers = append(receivers, participantID)
+	}
+	if !member {
+		r.logger.Debug("Ignoring typing frame of a non-participant",
+			zap.String("conversation_id", conversationID),
+			zap.String("user_id", userID),
+		)
+		return nil, false
+	}
+	return receivers, true
+}
@@ -0,0 +1,135 @@
+package ws
+
+import (
+	"context"
+	"encoding/json"
+	"sync"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+	"go.uber.org/zap"
+)
+
+// TypingChannelName is the Redis Pub/Sub channel relaying typing events between gateways.
+// It is separate from the event channels: typing never goes through the outbox, and Pub/Sub keeps
+// nothing, so a gateway that is down when an event is relayed simply misses it.
+const TypingChannelName = "chat:typing"
+
+// typingPublishTimeout bounds relaying one typing event; it runs on the typist's read pump
+const typingPublishTimeout = time.Second
+
+// typingRelayMessage is a typing event on TypingChannelName with the receivers to deliver it to
+type typingRelayMessage struct {
+	InstanceID  string      `json:"instance_id"`  // Publishing gateway; it already delivered locally
+	ReceiverIDs []string    `json:"receiver_ids"` // Participants looked up by the publishing gateway
+	Event       TypingEvent `json:"event"`
+}
+
+// TypingRelay carries typing events to receivers connected to other gateway instances. Each
+// gateway publishes the typing starts and stops of its own clients and delivers the events
+// published by the others to its local connections.
+type TypingRelay struct {
+	redis      *redis.Client
+	logger     *zap.Logger
+	instanceID string
+
+	mu      sync.Mutex
+	pubsub  *redis.PubSub
+	running bool
+	wg      sync.WaitGroup
+}
+
+// NewTypingRelay creates a relay publishing as instanceID.
+func NewTypingRelay(redisClient *redis.Client, logger *zap.Logger, instanceID string) *TypingRelay {
+	return &TypingRelay{
+		redis:      redisClient,
+		logger:     logger,
+		instanceID: instanceID,
+	}
+}
+
+// Publish relays a typing event of a local client to the other gateways. Other gateways deliver
+// to receiverIDs as they are, so they must be the participants looked up for the session, never
+// IDs taken from a client frame.
+func (t *TypingRelay) Publish(ctx context.Context, receiverIDs []string, event TypingEvent) error {
+	data, err := json.Marshal(typingRelayMessage{
+		InstanceID:  t.instanceID,
+		ReceiverIDs: receiverIDs,
+		Event:       event,
+	})
+	if err != nil {
+		return err
+	}
+	return t.redis.Publish(ctx, TypingChannelName, data).Err()
+}
+
+// Start subscribes to typing events of other gateways and passes them to deliver until Stop.
+// go-redis resubscribes after connection losses; events relayed meanwhile are lost.
+func (t *TypingRelay) Start(ctx context.Context, deliver func(receiverIDs []string, event TypingEvent)) error {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	if t.running {
+		return nil
+	}
+
+	pubsub := t.redis.Subscribe(ctx, TypingChannelName)
+	if _, err := pubsub.Receive(ctx); err != nil {
+		_ = pubsub.Close()
+		return err
+	}
+	t.pubsub = pubsub
+	t.running = true
+
+	t.wg.Add(1)
+	go func() {
+		defer t.wg.Done()
+		for msg := range pubsub.Channel() {
+			t.handle(msg.Payload, deliver)
+		}
+	}()
+
+	t.logger.Info("Typing relay started", zap.String("channel", TypingChannelName))
+	return nil
+}
+
+func (t *TypingRelay) handle(payload string, deliver func(receiverIDs []string, event TypingEvent)) {
+	var msg typingRelayMessage
+	if err := json.Unmarshal([]byte(payload), &msg); err != nil {
+		t.logger.Debug("Ignoring malformed typing relay message", zap.Error(err))
+		return
+	}
+	if msg.InstanceID == t.instanceID {
+		return
+	}
+	deliver(msg.ReceiverIDs, msg.Event)
+}
+
+// Stop unsubscribes and waits for the delivery loop to exit.
+func (t *TypingRelay) Stop() error {
+	t.mu.Lock()
+	if !t.running {
+		t.mu.Unlock()
+		return nil
+	}
+	t.running = false
+	err := t.pubsub.Close()
+	t.mu.Unlock()
+
+	t.wg.Wait()
+	return err
+}
+
+// SetTypingRelay relays the typing events of local clients to other gateways and delivers
+// theirs here. Without a relay, only receivers connected to this gateway are notified.
+func (r *Router) SetTypingRelay(relay *TypingRelay) {
+	r.typingRelay = relay
+}
+
+// StartTypingRelay starts delivering typing events relayed by other gateways.
+func (r *Router) StartTypingRelay(ctx context.Context) error {
+	if r.typingRelay == nil {
+		return nil
+	}
+	return r.typingRelay.Start(ctx, r.deliverTyping)
+}
@@ -0,0 +1,81 @@
+package ws
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+func TestTypingRelay_DeliversAcrossGateways(t *testing.T) {
+	_, redisClient := setupTestRedis(t)
+	ctx := context.Background()
+
+	gatewayA, clientsA := newTypingRouter(t, time.Minute, "user-1", "user-2")
+	gatewayB, clientsB := newTypingRouter(t, time.Minute, "user-3")
+	for instanceID, router := range map[string]*Router{"gw-a": gatewayA, "gw-b": gatewayB} {
+		relay := NewTypingRelay(redisClient, zap.NewNop(), instanceID)
+		router.SetTypingRelay(relay)
+		require.NoError(t, router.StartTypingRelay(ctx))
+		t.Cleanup(func() { _ = relay.Stop() })
+	}
+
+	gatewayA.HandleClientMessage("user-1", typingFrame(t, EventTypeTyping))
+
+	local := receiveTypingEvent(t, clientsA["user-2"])
+	assert.Equal(t, EventTypeTyping, local.Type)
+
+	require.Eventually(t, func() bool { return len(clientsB["user-3"].Send) == 1 }, time.Second, 10*time.Millisecond,
+		"a receiver on another gateway gets the event")
+	assert.Equal(t, local, receiveTypingEvent(t, clientsB["user-3"]))
+
+	gatewayA.HandleClientMessage("user-1", typingFrame(t, EventTypeTypingStopped))
+	assert.Equal(t, EventTypeTypingStopped, receiveTypingEvent(t, clientsA["user-2"]).Type)
+	require.Eventually(t, func() bool { return len(clientsB["user-3"].Send) == 1 }, time.Second, 10*time.Millisecond)
+	assert.Equal(t, EventTypeTypingStopped, receiveTypingEvent(t, clientsB["user-3"]).Type)
+
+	// The publishing gateway ignores its own relayed events, so local receivers get each event once
+	time.Sleep(50 * time.Millisecond)
+	assert.Empty(t, clientsA["user-2"].Send)
+}
+
+// TestTypingRelay_OnlyRelaysParticipants verifies frames failing the participant check are not
+// published, and client supplied receivers never reach other gateways
+func TestTypingRelay_OnlyRelaysParticipants(t *testing.T) {
+	_, redisClient := setupTestRedis(t)
+	ctx := context.Background()
+
+	gatewayA, _ := newTypingRouter(t, time.Minute, "user-1")
+	gatewayB, clientsB := newTypingRouter(t, time.Minute, "user-3", "stranger")
+	for instanceID, router := range map[string]*Router{"gw-a": gatewayA, "gw-b": gatewayB} {
+		relay := NewTypingRelay(redisClient, zap.NewNop(), instanceID)
+		router.SetTypingRelay(relay)
+		require.NoError(t, router.StartTypingRelay(ctx))
+		t.Cleanup(func() { _ = relay.Stop() })
+	}
+
+	gatewayA.HandleClientMessage("intruder", []byte(`{"type":"typing","conversation_id":"conv-1","receiver_ids":["user-3","stranger"]}`))
+	gatewayA.HandleClientMessage("user-1", []byte(`{"type":"typing","conversation_id":"conv-1","receiver_ids":["stranger"]}`))
+
+	require.Eventually(t, func() bool { return len(clientsB["user-3"].Send) == 1 }, time.Second, 10*time.Millisecond)
+	event := receiveTypingEvent(t, clientsB["user-3"])
+	assert.Equal(t, "user-1", event.UserID, "only the participant's session is relayed")
+
+	time.Sleep(50 * time.Millisecond)
+	assert.Empty(t, clientsB["user-3"].Send)
+	assert.Empty(t, clientsB["stranger"].Send, "client supplied receivers are not relayed")
+}
+
+func TestTypingRelay_IgnoresMalformedMessages(t *testing.T) {
+	relay := NewTypingRelay(nil, zap.NewNop(), "gw-a")
+	delivered := 0
+	deliver := func([]string, TypingEvent) { delivered++ }
+
+	relay.handle("not json", deliver)
+	relay.handle(`{"instance_id":"gw-a","receiver_ids":["user-2"],"event":{"type":"typing"}}`, deliver)
+	relay.handle(`{"instance_id":"gw-b","receiver_ids":["user-2"],"event":{"type":"typing"}}`, deliver)
+	assert.Equal(t, 1, delivered)
+}
@@ -0,0 +1,271 @@
+package ws
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+// stubParticipants maps conversation IDs to their participants
+type stubParticipants map[string][]string
+
+func (p stubParticipants) ListParticipantIDs(ctx context.Context, conversationID string) ([]string, error) {
+	return p[conversationID], nil
+}
+
+// errParticipants fails every lookup
+type errParticipants struct{}
+
+func (errParticipants) ListParticipantIDs(context.Context, string) ([]string, error) {
+	return nil, errors.New("database unavailable")
+}
+
+// typingParticipants are the participants of conv-1, the conversation of typingFrame
+var typingParticipants = stubParticipants{"conv-1": {"user-1", "user-2", "user-3"}}
+
+func newTypingRouter(t *testing.T, timeout time.Duration, userIDs ...string) (*Router, map[string]*Client) {
+	t.Helper()
+	manager := NewConnectionManager()
+	router := NewRouter(manager, zap.NewNop(), nil)
+	router.SetTypingTracker(NewTypingTracker(timeout))
+	router.SetParticipantLister(typingParticipants)
+
+	clients := make(map[string]*Client, len(userIDs))
+	for _, userID := range userIDs {
+		client := &Client{Send: make(chan []byte, 10)}
+		manager.Add(userID, client)
+		clients[userID] = client
+	}
+	return router, clients
+}
+
+func receiveTypingEvent(t *testing.T, client *Client) TypingEvent {
+	t.Helper()
+	select {
+	case data := <-client.Send:
+		var event TypingEvent
+		require.NoError(t, json.Unmarshal(data, &event))
+		return event
+	default:
+		t.Fatal("expected typing event")
+		return TypingEvent{}
+	}
+}
+
+func typingFrame(t *testing.T, msgType string) []byte {
+	t.Helper()
+	data, err := json.Marshal(TypingMessage{Type: msgType, ConversationID: "conv-1"})
+	require.NoError(t, err)
+	return data
+}
+
+func TestTypingTracker_Expire(t *testing.T) {
+	tracker := NewTypingTracker(5 * time.Second)
+	start := time.Now()
+
+	assert.False(t, tracker.Refresh("conv-1", "user-1", start), "no session to refresh yet")
+	assert.True(t, tracker.Start("conv-1", "user-1", []string{"user-2"}, start))
+	assert.True(t, tracker.Refresh("conv-1", "user-1", start.Add(3*time.Second)), "refresh continues the session")
+	assert.True(t, tracker.Start("conv-2", "user-1", []string{"user-3"}, start))
+
+	stops := tracker.Expire(start.Add(6 * time.Second))
+	require.Len(t, stops, 1, "the refreshed session is still active")
+	assert.Equal(t, TypingStop{ConversationID: "conv-2", UserID: "user-1", ReceiverIDs: []string{"user-3"}}, stops[0])
+
+	assert.Len(t, tracker.Expire(start.Add(8*time.Second)), 1)
+	assert.Zero(t, tracker.Count())
+}
+
+func TestTypingTracker_StopUser(t *testing.T) {
+	tracker := NewTypingTracker(0)
+	assert.Equal(t, DefaultTypingTimeout, tracker.Timeout())
+
+	now := time.Now()
+	tracker.Start("conv-1", "user-1", nil, now)
+	tracker.Start("conv-2", "user-1", nil, now)
+	tracker.Start("conv-1", "user-2", nil, now)
+	assert.False(t, tracker.Start("conv-1", "user-2", nil, now), "a session started concurrently is only refreshed")
+
+	assert.Len(t, tracker.StopUser("user-1"), 2)
+	assert.Equal(t, 1, tracker.Count())
+
+	_, ok := tracker.Stop("conv-1", "user-1")
+	assert.False(t, ok)
+}
+
+// TestRouter_TypingForwardsSessionStartOnce verifies refreshes are not re-broadcast
+func TestRouter_TypingForwardsSessionStartOnce(t *testing.T) {
+	router, clients := newTypingRouter(t, time.Minute, "user-1", "user-2", "user-3")
+
+	router.HandleClientMessage("user-1", typingFrame(t, EventTypeTyping))
+	router.HandleClientMessage("user-1", typingFrame(t, EventTypeTyping))
+
+	for _, receiverID := range []string{"user-2", "user-3"} {
+		event := receiveTypingEvent(t, clients[receiverID])
+		assert.Equal(t, EventTypeTyping, event.Type)
+		assert.Equal(t, "conv-1", event.ConversationID)
+		assert.Equal(t, "user-1", event.UserID)
+		assert.Empty(t, clients[receiverID].Send, "one event per session, no duplicates")
+	}
+	assert.Empty(t, clients["user-1"].Send, "the typist is not notified")
+}
+
+// TestRouter_ExplicitTypingStopped verifies a client can end its session
+func TestRouter_ExplicitTypingStopped(t *testing.T) {
+	router, clients := newTypingRouter(t, time.Minute, "user-2")
+
+	router.HandleClientMessage("user-1", typingFrame(t, EventTypeTyping))
+	receiveTypingEvent(t, clients["user-2"])
+
+	router.HandleClientMessage("user-1", typingFrame(t, EventTypeTypingStopped))
+	event := receiveTypingEvent(t, clients["user-2"])
+	assert.Equal(t, EventTypeTypingStopped, event.Type)
+	assert.Equal(t, "user-1", event.UserID)
+	assert.False(t, event.Expired)
+
+	// A stop without an active session is not forwarded
+	router.HandleClientMessage("user-1", typingFrame(t, EventTypeTypingStopped))
+	assert.Empty(t, clients["user-2"].Send)
+}
+
+// TestRouter_TypingExpiresWithoutStop verifies the synthetic typing_stopped after the idle window
+func TestRouter_TypingExpiresWithoutStop(t *testing.T) {
+	router, clients := newTypingRouter(t, 5*time.Second, "user-2")
+
+	router.HandleClientMessage("user-1", typingFrame(t, EventTypeTyping))
+	receiveTypingEvent(t, clients["user-2"])
+
+	router.ExpireTyping(time.Now().Add(time.Second))
+	assert.Empty(t, clients["user-2"].Send, "still within the idle window")
+
+	router.ExpireTyping(time.Now().Add(6 * time.Second))
+	event := receiveTypingEvent(t, clients["user-2"])
+	assert.Equal(t, EventTypeTypingStopped, event.Type)
+	assert.Equal(t, "conv-1", event.ConversationID)
+	assert.True(t, event.Expired)
+}
+
+// TestRouter_StopTypingOnDisconnect verifies a disconnecting typist ends its sessions
+func TestRouter_StopTypingOnDisconnect(t *testing.T) {
+	router, clients := newTypingRouter(t, time.Minute, "user-2")
+
+	router.HandleClientMessage("user-1", typingFrame(t, EventTypeTyping))
+	receiveTypingEvent(t, clients["user-2"])
+
+	router.StopTyping("user-1")
+	event := receiveTypingEvent(t, clients["user-2"])
+	assert.Equal(t, EventTypeTypingStopped, event.Type)
+	assert.True(t, event.Expired)
+}
+
+// TestRouter_TypingDisabled verifies frames are ignored without a tracker
+func TestRouter_TypingDisabled(t *testing.T) {
+	manager := NewConnectionManager()
+	router := NewRouter(manager, zap.NewNop(), nil)
+	client := &Client{Send: make(chan []byte, 10)}
+	manager.Add("user-2", client)
+
+	router.HandleClientMessage("user-1", typingFrame(t, EventTypeTyping))
+	router.HandleClientMessage("user-1", []byte("not json"))
+	router.StopTyping("user-1")
+	router.ExpireTyping(time.Now())
+
+	assert.Empty(t, client.Send)
+}
+
+// TestRouter_TypingFullBufferDoesNotEvict verifies typing events are dropped, not fatal, for slow clients
+func TestRouter_TypingFullBufferDoesNotEvict(t *testing.T) {
+	manager := NewConnectionManager()
+	router := NewRouter(manager, zap.NewNop(), nil)
+	router.SetTypingTracker(NewTypingTracker(time.Minute))
+	router.SetParticipantLister(typingParticipants)
+	client := &Client{Send: make(chan []byte, 1)}
+	client.Send <- []byte("pending")
+	manager.Add("user-2", client)
+
+	router.HandleClientMessage("user-1", typingFrame(t, EventTypeTyping))
+
+	_, connected := manager.Get("user-2")
+	assert.True(t, connected)
+	assert.Len(t, client.Send, 1)
+}
+
+func TestTypingTracker_StartLimit(t *testing.T) {
+	tracker := NewTypingTracker(time.Minute)
+	tracker.SetStartLimit(2)
+	now := time.Now()
+
+	assert.True(t, tracker.AllowStart("user-1", now))
+	assert.True(t, tracker.AllowStart("user-1", now))
+	assert.False(t, tracker.AllowStart("user-1", now), "third start within the minute is ignored")
+	assert.True(t, tracker.AllowStart("user-2", now), "the limit is per user")
+	assert.True(t, tracker.AllowStart("user-1", now.Add(time.Minute)), "the next minute allows new starts")
+}
+
+// TestRouter_TypingStartLimit verifies starts over the limit are ignored but refreshes and stops are not
+func TestRouter_TypingStartLimit(t *testing.T) {
+	router, clients := newTypingRouter(t, time.Minute, "user-2")
+	router.typing.SetStartLimit(1)
+
+	router.HandleClientMessage("user-1", typingFrame(t, EventTypeTyping))
+	receiveTypingEvent(t, clients["user-2"])
+	router.HandleClientMessage("user-1", typingFrame(t, EventTypeTyping))
+	assert.Empty(t, clients["user-2"].Send, "refreshes are not limited nor forwarded")
+
+	router.HandleClientMessage("user-1", typingFrame(t, EventTypeTypingStopped))
+	assert.Equal(t, EventTypeTypingStopped, receiveTypingEvent(t, clients["user-2"]).Type)
+	router.HandleClientMessage("user-1", typingFrame(t, EventTypeTyping))
+	assert.Empty(t, clients["user-2"].Send, "restarting counts as a start")
+	assert.Zero(t, router.typing.Count(), "an ignored start leaves no session to stop")
+}
+
+// TestRouter_TypingNonParticipantDropped verifies a typist outside the conversation reaches nobody
+func TestRouter_TypingNonParticipantDropped(t *testing.T) {
+	router, clients := newTypingRouter(t, time.Minute, "user-2", "user-3")
+
+	router.HandleClientMessage("stranger", typingFrame(t, EventTypeTyping))
+	router.HandleClientMessage("stranger", typingFrame(t, EventTypeTypingStopped))
+
+	assert.Empty(t, clients["user-2"].Send)
+	assert.Empty(t, clients["user-3"].Send)
+	assert.Zero(t, router.typing.Count(), "no session is recorded for a non-participant")
+}
+
+// TestRouter_TypingIgnoresClientReceivers verifies receivers come from the conversation,
+// never from receiver_ids sent by the client
+func TestRouter_TypingIgnoresClientReceivers(t *testing.T) {
+	router, clients := newTypingRouter(t, time.Minute, "user-2", "stranger")
+
+	router.HandleClientMessage("user-1", []byte(`{"type":"typing","conversation_id":"conv-1","receiver_ids":["stranger"]}`))
+
+	assert.Equal(t, EventTypeTyping, receiveTypingEvent(t, clients["user-2"]).Type, "participants are notified")
+	assert.Empty(t, clients["stranger"].Send, "client supplied receivers outside the conversation are ignored")
+
+	router.HandleClientMessage("user-1", []byte(`{"type":"typing_stopped","conversation_id":"conv-1","receiver_ids":["stranger"]}`))
+	assert.Equal(t, EventTypeTypingStopped, receiveTypingEvent(t, clients["user-2"]).Type)
+	assert.Empty(t, clients["stranger"].Send)
+}
+
+// TestRouter_TypingWithoutParticipants verifies typing frames are rejected when participants
+// can't be checked: without a lister, or when the lookup fails
+func TestRouter_TypingWithoutParticipants(t *testing.T) {
+	manager := NewConnectionManager()
+	router := NewRouter(manager, zap.NewNop(), nil)
+	router.SetTypingTracker(NewTypingTracker(time.Minute))
+	client := &Client{Send: make(chan []byte, 10)}
+	manager.Add("user-2", client)
+
+	router.HandleClientMessage("user-1", typingFrame(t, EventTypeTyping))
+	assert.Empty(t, client.Send, "no lister, no typing")
+
+	router.SetParticipantLister(errParticipants{})
+	router.HandleClientMessage("user-1", typingFrame(t, EventTypeTyping))
+	assert.Empty(t, client.Send, "a failed lookup drops the frame")
+	assert.Zero(t, router.typing.Count())
+}
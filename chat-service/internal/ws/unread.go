@@ -0,0 +1,89 @@
+package ws
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"chat-service/internal/repository"
+
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+const (
+	// DefaultUnreadSnapshotLimit is how many conversations the unread snapshot covers (WS_UNREAD_SNAPSHOT_LIMIT)
+	DefaultUnreadSnapshotLimit = 50
+
+	// DefaultUnreadSnapshotTimeout bounds the snapshot query; it runs before the pumps start,
+	// so a slow database must not hold a new connection for long.
+	DefaultUnreadSnapshotTimeout = 2 * time.Second
+)
+
+// ConversationUnread is the unread count of one conversation in an unread snapshot.
+type ConversationUnread struct {
+	ConversationID string `json:"conversation_id"`
+	UnreadCount    int    `json:"unread_count"`
+	LastMessageAt  int64  `json:"last_message_at"` // Unix timestamp in milliseconds
+}
+
+// UnreadCounter lists the unread counts of a user's first conversations, in conversation list order.
+type UnreadCounter interface {
+	ListUnreadCounts(ctx context.Context, userID string, limit int) ([]ConversationUnread, error)
+}
+
+// DBUnreadCounter reads unread counts from the chat database.
+type DBUnreadCounter struct {
+	queries *repository.Queries
+}
+
+// NewDBUnreadCounter creates a counter over a database connection or pool.
+func NewDBUnreadCounter(db repository.DBTX) *DBUnreadCounter {
+	return &DBUnreadCounter{queries: repository.New(db)}
+}
+
+// ListUnreadCounts returns the unread counts of the first limit conversations of userID's list
+// (pinned first, archived and hidden ones left out), the same counts GetConversations reports.
+func (c *DBUnreadCounter) ListUnreadCounts(ctx context.Context, userID string, limit int) ([]ConversationUnread, error) {
+	var id pgtype.UUID
+	if err := id.Scan(userID); err != nil {
+		return nil, fmt.Errorf("invalid user id %q: %w", userID, err)
+	}
+
+	rows, err := c.queries.GetUnreadCountsForUser(ctx, repository.GetUnreadCountsForUserParams{
+		UserID: id,
+		Limit:  int32(limit),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to count unread messages: %w", err)
+	}
+
+	counts := make([]ConversationUnread, len(rows))
+	for i, row := range rows {
+		counts[i] = ConversationUnread{
+			ConversationID: row.ID.String(),
+			UnreadCount:    int(row.UnreadCount),
+			LastMessageAt:  row.LastMessageAt.Time.UnixMilli(),
+		}
+	}
+	return counts, nil
+}
+
+// BuildUnreadSnapshot loads the unread counts of userID's first limit conversations into an
+// unread_snapshot event. A non-positive limit uses DefaultUnreadSnapshotLimit.
+func BuildUnreadSnapshot(ctx context.Context, counter UnreadCounter, userID string, limit int) (*UnreadSnapshotEvent, error) {
+	if limit <= 0 {
+		limit = DefaultUnreadSnapshotLimit
+	}
+
+	// Taken before the query: message events created up to it are already in the counts
+	asOf := time.Now()
+
+	ctx, cancel := context.WithTimeout(ctx, DefaultUnreadSnapshotTimeout)
+	defer cancel()
+
+	counts, err := counter.ListUnreadCounts(ctx, userID, limit)
+	if err != nil {
+		return nil, err
+	}
+	return NewUnreadSnapshotEvent(asOf, counts, len(counts) >= limit), nil
+}
@@ -0,0 +1,94 @@
+package ws
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// stubUnreadCounter implements UnreadCounter for testing
+type stubUnreadCounter struct {
+	counts   []ConversationUnread
+	err      error
+	limit    int
+	deadline bool
+}
+
+func (c *stubUnreadCounter) ListUnreadCounts(ctx context.Context, userID string, limit int) ([]ConversationUnread, error) {
+	c.limit = limit
+	_, c.deadline = ctx.Deadline()
+	if c.err != nil {
+		return nil, c.err
+	}
+	if len(c.counts) > limit {
+		return c.counts[:limit], nil
+	}
+	return c.counts, nil
+}
+
+func TestBuildUnreadSnapshot(t *testing.T) {
+	counter := &stubUnreadCounter{counts: []ConversationUnread{
+		{ConversationID: "conv-pinned", UnreadCount: 0, LastMessageAt: 1000},
+		{ConversationID: "conv-1", UnreadCount: 3, LastMessageAt: 3000},
+		{ConversationID: "conv-2", UnreadCount: 2, LastMessageAt: 2000},
+	}}
+
+	before := time.Now().UnixMilli()
+	event, err := BuildUnreadSnapshot(context.Background(), counter, "user-1", 10)
+	require.NoError(t, err)
+
+	assert.Equal(t, EventTypeUnreadSnapshot, event.Type)
+	assert.Equal(t, 10, counter.limit)
+	assert.True(t, counter.deadline, "query is bounded")
+	assert.GreaterOrEqual(t, event.ServerTime, before)
+	assert.Equal(t, counter.counts, event.Conversations, "list order is kept")
+	assert.Equal(t, 5, event.TotalUnread)
+	assert.False(t, event.Truncated)
+}
+
+func TestBuildUnreadSnapshot_Truncated(t *testing.T) {
+	counter := &stubUnreadCounter{counts: []ConversationUnread{
+		{ConversationID: "conv-1", UnreadCount: 1},
+		{ConversationID: "conv-2", UnreadCount: 1},
+		{ConversationID: "conv-3", UnreadCount: 1},
+	}}
+
+	event, err := BuildUnreadSnapshot(context.Background(), counter, "user-1", 2)
+	require.NoError(t, err)
+	assert.Len(t, event.Conversations, 2)
+	assert.Equal(t, 2, event.TotalUnread)
+	assert.True(t, event.Truncated)
+
+	_, err = BuildUnreadSnapshot(context.Background(), counter, "user-1", 0)
+	require.NoError(t, err)
+	assert.Equal(t, DefaultUnreadSnapshotLimit, counter.limit)
+}
+
+func TestBuildUnreadSnapshot_Error(t *testing.T) {
+	counter := &stubUnreadCounter{err: errors.New("connection refused")}
+
+	_, err := BuildUnreadSnapshot(context.Background(), counter, "user-1", 10)
+	assert.Error(t, err)
+}
+
+func TestUnreadSnapshotEvent_JSON(t *testing.T) {
+	ResetInstanceID()
+
+	event := NewUnreadSnapshotEvent(time.UnixMilli(1700000000000), nil, false)
+	data, err := json.Marshal(event)
+	require.NoError(t, err)
+
+	var parsed map[string]interface{}
+	require.NoError(t, json.Unmarshal(data, &parsed))
+	assert.Equal(t, "unread_snapshot", parsed["type"])
+	assert.Equal(t, float64(1700000000000), parsed["server_time"])
+	assert.Equal(t, []interface{}{}, parsed["conversations"], "empty list, not null")
+	assert.Equal(t, float64(0), parsed["total_unread"])
+	assert.Equal(t, false, parsed["truncated"])
+	assert.NotEmpty(t, parsed["instance_id"])
+}
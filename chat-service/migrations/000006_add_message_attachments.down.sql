@@ -0,0 +1,5 @@
+-- migrations/000006_add_message_attachments.down.sql
+-- Rollback message attachments
+
+DROP INDEX IF EXISTS idx_message_attachments_message_id;
+DROP TABLE IF EXISTS message_attachments;
@@ -0,0 +1,17 @@
+-- migrations/000006_add_message_attachments.up.sql
+-- Structured attachments for messages (multiple files per message)
+
+CREATE TABLE message_attachments (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    message_id UUID NOT NULL REFERENCES messages(id) ON DELETE CASCADE,
+    url TEXT NOT NULL,
+    mime_type VARCHAR(100) NOT NULL,
+    size_bytes BIGINT NOT NULL CHECK (size_bytes > 0),
+    width INT,
+    height INT,
+    position INT NOT NULL DEFAULT 0,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+-- Index for loading attachments of a page of messages
+CREATE INDEX idx_message_attachments_message_id ON message_attachments(message_id, position);
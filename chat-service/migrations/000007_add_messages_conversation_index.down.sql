@@ -0,0 +1,4 @@
+-- migrations/000007_add_messages_conversation_index.down.sql
+-- Rollback composite messages index
+
+DROP INDEX IF EXISTS idx_messages_conversation_created_at;
@@ -0,0 +1,5 @@
+-- migrations/000007_add_messages_conversation_index.up.sql
+-- Composite index for per-conversation message scans
+-- (GetMessages pagination and unread counts in GetConversationsForUser)
+
+CREATE INDEX IF NOT EXISTS idx_messages_conversation_created_at ON messages(conversation_id, created_at DESC);
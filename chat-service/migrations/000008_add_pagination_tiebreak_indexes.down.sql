@@ -0,0 +1,7 @@
+-- Rollback composite pagination indexes
+
+CREATE INDEX IF NOT EXISTS idx_conversations_last_message_at ON conversations(last_message_at DESC);
+DROP INDEX IF EXISTS idx_conversations_last_message_at_id;
+
+CREATE INDEX IF NOT EXISTS idx_messages_conversation_created_at ON messages(conversation_id, created_at DESC);
+DROP INDEX IF EXISTS idx_messages_conversation_created_at_id;
@@ -0,0 +1,9 @@
+-- Composite (timestamp, id) indexes backing keyset pagination.
+-- The id column breaks ties between rows sharing a timestamp so that
+-- GetMessages / GetConversationsForUser never skip or repeat rows across pages.
+
+CREATE INDEX IF NOT EXISTS idx_messages_conversation_created_at_id ON messages(conversation_id, created_at DESC, id DESC);
+DROP INDEX IF EXISTS idx_messages_conversation_created_at;
+
+CREATE INDEX IF NOT EXISTS idx_conversations_last_message_at_id ON conversations(last_message_at DESC, id DESC);
+DROP INDEX IF EXISTS idx_conversations_last_message_at;
@@ -0,0 +1,3 @@
+-- Rollback per-user conversation hiding
+
+ALTER TABLE conversation_participants DROP COLUMN IF EXISTS hidden_at;
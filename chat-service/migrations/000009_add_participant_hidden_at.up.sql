@@ -0,0 +1,4 @@
+-- Per-user soft delete: a participant can hide a conversation from their own list.
+-- hidden_at is cleared again when a new message arrives in the conversation.
+
+ALTER TABLE conversation_participants ADD COLUMN hidden_at TIMESTAMPTZ;
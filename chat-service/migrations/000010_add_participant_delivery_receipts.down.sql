@@ -0,0 +1,3 @@
+-- Rollback delivery receipts
+
+ALTER TABLE conversation_participants DROP COLUMN IF EXISTS last_delivered_at;
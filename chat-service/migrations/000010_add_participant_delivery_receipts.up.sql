@@ -0,0 +1,4 @@
+-- Delivery receipts: per-participant watermark of the newest message delivered to one of their devices.
+-- Together with last_read_at it yields the sent/delivered/read status of messages (NULL = nothing delivered yet).
+
+ALTER TABLE conversation_participants ADD COLUMN last_delivered_at TIMESTAMPTZ;
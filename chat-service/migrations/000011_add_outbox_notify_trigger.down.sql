@@ -0,0 +1,4 @@
+-- Rollback outbox insert notifications
+
+DROP TRIGGER IF EXISTS outbox_notify_inserted ON outbox;
+DROP FUNCTION IF EXISTS notify_outbox_inserted();
@@ -0,0 +1,14 @@
+-- Wake the outbox processor as soon as events are committed instead of waiting for the next poll.
+-- One notification per statement; Postgres delivers it on commit and folds duplicates within a transaction.
+
+CREATE OR REPLACE FUNCTION notify_outbox_inserted() RETURNS trigger AS $$
+BEGIN
+    PERFORM pg_notify('outbox_inserted', '');
+    RETURN NULL;
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE TRIGGER outbox_notify_inserted
+    AFTER INSERT ON outbox
+    FOR EACH STATEMENT
+    EXECUTE FUNCTION notify_outbox_inserted();
@@ -0,0 +1,3 @@
+-- Rollback per-user conversation archiving
+
+ALTER TABLE conversation_participants DROP COLUMN IF EXISTS archived_at;
@@ -0,0 +1,4 @@
+-- Per-user archive: an archived conversation leaves the main list but keeps receiving messages.
+-- Unless the service runs with KEEP_ARCHIVED_ON_NEW_MESSAGE, a new message clears archived_at again.
+
+ALTER TABLE conversation_participants ADD COLUMN archived_at TIMESTAMPTZ;
@@ -0,0 +1,4 @@
+-- Rollback per-conversation message retention
+
+DROP INDEX IF EXISTS idx_conversations_message_ttl;
+ALTER TABLE conversations DROP COLUMN IF EXISTS message_ttl_seconds;
@@ -0,0 +1,6 @@
+-- Per-conversation message retention: messages older than message_ttl_seconds are purged by the
+-- retention job (NULL = kept forever). The partial index keeps the job's scan to opted-in conversations.
+
+ALTER TABLE conversations ADD COLUMN message_ttl_seconds INT CHECK (message_ttl_seconds > 0);
+
+CREATE INDEX IF NOT EXISTS idx_conversations_message_ttl ON conversations(id) WHERE message_ttl_seconds IS NOT NULL;
@@ -0,0 +1,4 @@
+-- Rollback conversation type and name
+
+ALTER TABLE conversations DROP COLUMN IF EXISTS name;
+ALTER TABLE conversations DROP COLUMN IF EXISTS type;
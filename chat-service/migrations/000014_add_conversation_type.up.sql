@@ -0,0 +1,5 @@
+-- Conversations created with CreateConversation carry a type and, for groups, a name.
+-- NULL type = created implicitly by the first SendMessage, with no membership rules.
+
+ALTER TABLE conversations ADD COLUMN type VARCHAR(16) CHECK (type IN ('direct', 'group'));
+ALTER TABLE conversations ADD COLUMN name TEXT;
@@ -0,0 +1,4 @@
+-- Rollback message idempotency keys
+
+DROP INDEX IF EXISTS idx_messages_sender_idempotency;
+ALTER TABLE messages DROP COLUMN IF EXISTS idempotency_key;
@@ -0,0 +1,8 @@
+-- Idempotency keys are also stored with the message, so a retry is recognised even when Redis
+-- has lost the key (flush, failover). Messages sent before this migration have no key.
+
+ALTER TABLE messages ADD COLUMN idempotency_key TEXT;
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_messages_sender_idempotency
+    ON messages(sender_id, idempotency_key)
+    WHERE idempotency_key IS NOT NULL;
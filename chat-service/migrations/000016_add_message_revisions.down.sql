@@ -0,0 +1,4 @@
+-- Rollback message soft delete and edit markers
+
+ALTER TABLE messages DROP COLUMN IF EXISTS edited_at;
+ALTER TABLE messages DROP COLUMN IF EXISTS deleted_at;
@@ -0,0 +1,5 @@
+-- Soft delete and edit markers. Deleted messages keep their row so sync clients can be told
+-- about them (GetMessages include_deleted returns them as tombstones); edits stamp edited_at.
+
+ALTER TABLE messages ADD COLUMN deleted_at TIMESTAMPTZ;
+ALTER TABLE messages ADD COLUMN edited_at TIMESTAMPTZ;
@@ -0,0 +1,3 @@
+-- Rollback message reactions
+
+DROP TABLE IF EXISTS message_reactions;
@@ -0,0 +1,10 @@
+-- Emoji reactions: one row per (message, user, emoji). Counts are aggregated on read;
+-- clients keep them current from reaction_added/reaction_removed events.
+
+CREATE TABLE message_reactions (
+    message_id UUID NOT NULL REFERENCES messages(id) ON DELETE CASCADE,
+    user_id UUID NOT NULL,
+    emoji TEXT NOT NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    PRIMARY KEY (message_id, emoji, user_id)
+);
@@ -0,0 +1,4 @@
+-- Rollback conversation pinning
+
+DROP INDEX IF EXISTS idx_conversation_participants_pinned;
+ALTER TABLE conversation_participants DROP COLUMN IF EXISTS pinned_at;
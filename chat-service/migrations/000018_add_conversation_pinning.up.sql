@@ -0,0 +1,6 @@
+-- Per-user pinning: pinned conversations lead the user's list, most recently pinned first.
+
+ALTER TABLE conversation_participants ADD COLUMN pinned_at TIMESTAMPTZ;
+
+-- A user pins a handful of conversations; the list reads them ahead of the rest
+CREATE INDEX IF NOT EXISTS idx_conversation_participants_pinned ON conversation_participants(user_id, pinned_at DESC) WHERE pinned_at IS NOT NULL;
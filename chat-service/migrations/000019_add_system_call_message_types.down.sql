@@ -0,0 +1,16 @@
+-- Rollback system and call message types; existing ones become plain text
+
+UPDATE messages SET type = 'TEXT' WHERE type IN ('SYSTEM', 'CALL');
+
+ALTER TABLE messages DROP CONSTRAINT IF EXISTS chk_media_has_url;
+ALTER TABLE messages
+ADD CONSTRAINT chk_media_has_url
+CHECK (
+  (type = 'TEXT' AND media_url IS NULL) OR
+  (type IN ('IMAGE', 'VIDEO', 'FILE') AND media_url IS NOT NULL)
+);
+
+ALTER TABLE messages DROP CONSTRAINT IF EXISTS chk_message_type;
+ALTER TABLE messages
+ADD CONSTRAINT chk_message_type
+CHECK (type IN ('TEXT', 'IMAGE', 'VIDEO', 'FILE'));
@@ -0,0 +1,15 @@
+-- System messages (membership changes, renames) and call events are stored as messages.
+-- Neither carries media; system messages are not counted as unread.
+
+ALTER TABLE messages DROP CONSTRAINT IF EXISTS chk_message_type;
+ALTER TABLE messages
+ADD CONSTRAINT chk_message_type
+CHECK (type IN ('TEXT', 'IMAGE', 'VIDEO', 'FILE', 'SYSTEM', 'CALL'));
+
+ALTER TABLE messages DROP CONSTRAINT IF EXISTS chk_media_has_url;
+ALTER TABLE messages
+ADD CONSTRAINT chk_media_has_url
+CHECK (
+  (type IN ('TEXT', 'SYSTEM', 'CALL') AND media_url IS NULL) OR
+  (type IN ('IMAGE', 'VIDEO', 'FILE') AND media_url IS NOT NULL)
+);
@@ -0,0 +1,4 @@
+-- Rollback per-conversation notification preferences
+
+ALTER TABLE conversation_participants DROP CONSTRAINT IF EXISTS chk_notification_level;
+ALTER TABLE conversation_participants DROP COLUMN IF EXISTS notification_level;
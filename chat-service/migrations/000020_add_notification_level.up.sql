@@ -0,0 +1,7 @@
+-- Per-user notification preference of a conversation: push-notify every message, only mentions, or nothing.
+-- It only drives the notify flag of message events; muted conversations still get messages and unread counts.
+
+ALTER TABLE conversation_participants ADD COLUMN notification_level TEXT NOT NULL DEFAULT 'ALL';
+
+ALTER TABLE conversation_participants ADD CONSTRAINT chk_notification_level
+    CHECK (notification_level IN ('ALL', 'MENTIONS', 'NONE'));
@@ -0,0 +1,3 @@
+-- Rollback message mentions
+
+DROP TABLE IF EXISTS message_mentions;
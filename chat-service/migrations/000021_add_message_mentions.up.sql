@@ -0,0 +1,13 @@
+-- Mentions: one row per (message, mentioned participant), written with the message.
+-- created_at is the message's, so the mentions inbox pages in message order.
+
+CREATE TABLE message_mentions (
+    message_id UUID NOT NULL REFERENCES messages(id) ON DELETE CASCADE,
+    user_id UUID NOT NULL,
+    conversation_id UUID NOT NULL,
+    created_at TIMESTAMPTZ NOT NULL,
+    PRIMARY KEY (message_id, user_id)
+);
+
+-- The mentions inbox reads a user's newest mentions first
+CREATE INDEX IF NOT EXISTS idx_message_mentions_user_created_at ON message_mentions(user_id, created_at DESC, message_id DESC);
@@ -0,0 +1,29 @@
+// Package eventshard maps conversations to Redis Pub/Sub shard channels.
+//
+// With sharding enabled the outbox publishes each event on the shard channel of its
+// conversation and every ws-gateway subscribes only to the shards of the conversations
+// its connected users belong to. Publisher and subscriber must agree on the channel
+// for a conversation, so both use this package.
+package eventshard
+
+import (
+	"hash/fnv"
+	"strconv"
+)
+
+// Of returns the shard (0..shards-1) of a conversation
+func Of(conversationID string, shards int) int {
+	h := fnv.New32a()
+	_, _ = h.Write([]byte(conversationID))
+	return int(h.Sum32() % uint32(shards))
+}
+
+// Channel returns the shard channel name for a shard: "{base}:{shard}"
+func Channel(base string, shard int) string {
+	return base + ":" + strconv.Itoa(shard)
+}
+
+// ChannelFor returns the shard channel of a conversation
+func ChannelFor(base, conversationID string, shards int) string {
+	return Channel(base, Of(conversationID, shards))
+}
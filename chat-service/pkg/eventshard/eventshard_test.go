@@ -0,0 +1,45 @@
+package eventshard
+
+import "testing"
+
+func TestOf_Stable(t *testing.T) {
+	const id = "550e8400-e29b-41d4-a716-446655440000"
+	first := Of(id, 16)
+	for i := 0; i < 10; i++ {
+		if got := Of(id, 16); got != first {
+			t.Fatalf("expected shard %d, got %d", first, got)
+		}
+	}
+}
+
+func TestOf_InRange(t *testing.T) {
+	ids := []string{"a", "b", "c", "550e8400-e29b-41d4-a716-446655440000", ""}
+	for _, id := range ids {
+		if shard := Of(id, 4); shard < 0 || shard >= 4 {
+			t.Errorf("shard %d of %q out of range", shard, id)
+		}
+	}
+	if shard := Of("anything", 1); shard != 0 {
+		t.Errorf("expected shard 0 with a single shard, got %d", shard)
+	}
+}
+
+func TestOf_Spreads(t *testing.T) {
+	seen := make(map[int]bool)
+	for i := 0; i < 200; i++ {
+		seen[Of(string(rune('a'+i%26))+string(rune('0'+i/26)), 8)] = true
+	}
+	if len(seen) != 8 {
+		t.Errorf("expected all 8 shards to be used, got %d", len(seen))
+	}
+}
+
+func TestChannel(t *testing.T) {
+	if got := Channel("chat:events", 3); got != "chat:events:3" {
+		t.Errorf("expected chat:events:3, got %s", got)
+	}
+	id := "550e8400-e29b-41d4-a716-446655440000"
+	if got, want := ChannelFor("chat:events", id, 8), Channel("chat:events", Of(id, 8)); got != want {
+		t.Errorf("expected %s, got %s", want, got)
+	}
+}
@@ -13,6 +13,7 @@ import (
 var (
 	ErrDuplicateRequest = errors.New("duplicate request detected")
 	ErrInvalidKey       = errors.New("invalid idempotency key")
+	ErrKeyNotFound      = errors.New("idempotency key not found")
 )
 
 // Constants
@@ -22,6 +23,9 @@ const (
 	
 	// KeyPrefix is the prefix for all idempotency keys in Redis
 	KeyPrefix = "idempotency:"
+
+	// NoExpiry is the TTL reported for a key that exists but never expires
+	NoExpiry time.Duration = -1
 )
 
 // Checker provides idempotency checking functionality using Redis
@@ -36,6 +40,10 @@ type Checker interface {
 	
 	// Remove deletes an idempotency key (useful for cleanup or testing).
 	Remove(ctx context.Context, key string) error
+
+	// TTL returns the remaining time-to-live of a key.
+	// Returns ErrKeyNotFound if the key does not exist, and NoExpiry if it never expires.
+	TTL(ctx context.Context, key string) (time.Duration, error)
 }
 
 // RedisChecker implements Checker using Redis SETNX
@@ -52,8 +60,12 @@ func NewRedisChecker(client *redis.Client) *RedisChecker {
 	}
 }
 
-// NewRedisCheckerWithTTL creates a new Redis-based idempotency checker with custom TTL
+// NewRedisCheckerWithTTL creates a new Redis-based idempotency checker with custom TTL.
+// A non-positive ttl falls back to DefaultTTL so keys always expire.
 func NewRedisCheckerWithTTL(client *redis.Client, ttl time.Duration) *RedisChecker {
+	if ttl <= 0 {
+		ttl = DefaultTTL
+	}
 	return &RedisChecker{
 		client: client,
 		ttl:    ttl,
@@ -65,18 +77,27 @@ func (r *RedisChecker) Check(ctx context.Context, key string) error {
 	return r.CheckWithTTL(ctx, key, r.ttl)
 }
 
-// CheckWithTTL verifies idempotency with custom TTL
+// CheckWithTTL verifies idempotency with custom TTL.
+// The TTL only applies when the key is claimed: a duplicate check never touches the
+// existing key, so a client retrying with the same key cannot keep it alive forever.
 func (r *RedisChecker) CheckWithTTL(ctx context.Context, key string, ttl time.Duration) error {
 	if key == "" {
 		return ErrInvalidKey
 	}
+
+	// go-redis sends SETNX without expiry for 0 and KEEPTTL for -1; both would claim
+	// the key forever, so only a positive TTL is passed through
+	if ttl <= 0 {
+		ttl = r.ttl
+	}
 	
 	// Build the full Redis key with prefix
 	redisKey := buildRedisKey(key)
 	
-	// Use SETNX (SET if Not eXists) to atomically check and set
+	// Use SET NX EX to atomically check and set
 	// Returns true if the key was set (first request)
-	// Returns false if the key already exists (duplicate request)
+	// Returns false if the key already exists (duplicate request); the key and its
+	// remaining TTL are left untouched, so re-checks never extend the dedup window
 	success, err := r.client.SetNX(ctx, redisKey, "1", ttl).Result()
 	if err != nil {
 		return fmt.Errorf("failed to check idempotency: %w", err)
@@ -99,6 +120,27 @@ func (r *RedisChecker) Remove(ctx context.Context, key string) error {
 	return r.client.Del(ctx, redisKey).Err()
 }
 
+// TTL returns the remaining time-to-live of an idempotency key
+func (r *RedisChecker) TTL(ctx context.Context, key string) (time.Duration, error) {
+	if key == "" {
+		return 0, ErrInvalidKey
+	}
+
+	// Redis answers -2 for a missing key and -1 for a key without expiry;
+	// go-redis passes both through as raw durations
+	ttl, err := r.client.TTL(ctx, buildRedisKey(key)).Result()
+	if err != nil {
+		return 0, fmt.Errorf("failed to get idempotency key TTL: %w", err)
+	}
+	switch ttl {
+	case -2:
+		return 0, ErrKeyNotFound
+	case -1:
+		return NoExpiry, nil
+	}
+	return ttl, nil
+}
+
 // buildRedisKey constructs the full Redis key with prefix
 func buildRedisKey(key string) string {
 	return KeyPrefix + key
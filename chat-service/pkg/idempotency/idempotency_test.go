@@ -6,6 +6,7 @@ import (
 	"testing"
 	"time"
 
+	"github.com/alicebob/miniredis/v2"
 	"github.com/go-redis/redismock/v9"
 	"github.com/redis/go-redis/v9"
 )
@@ -337,3 +338,108 @@ func TestRedisChecker_Interface(t *testing.T) {
 	var _ Checker = (*RedisChecker)(nil)
 }
 
+
+func TestRedisChecker_DuplicateCheckKeepsTTL(t *testing.T) {
+	mr := miniredis.RunT(t)
+	client := redis.NewClient(&redis.Options{Addr: mr.Addr()})
+	defer client.Close()
+
+	checker := NewRedisCheckerWithTTL(client, time.Hour)
+	ctx := context.Background()
+	redisKey := buildRedisKey("retried-key")
+
+	if err := checker.Check(ctx, "retried-key"); err != nil {
+		t.Fatalf("first check: expected no error, got %v", err)
+	}
+
+	mr.FastForward(10 * time.Minute)
+	before := mr.TTL(redisKey)
+
+	// Re-checks, including one asking for a longer window, are duplicates and leave the expiry alone
+	if err := checker.Check(ctx, "retried-key"); !errors.Is(err, ErrDuplicateRequest) {
+		t.Errorf("re-check: expected ErrDuplicateRequest, got %v", err)
+	}
+	if err := checker.CheckWithTTL(ctx, "retried-key", 24*time.Hour); !errors.Is(err, ErrDuplicateRequest) {
+		t.Errorf("re-check with TTL: expected ErrDuplicateRequest, got %v", err)
+	}
+
+	if after := mr.TTL(redisKey); after != before || after != 50*time.Minute {
+		t.Errorf("expected TTL to stay at %v (50m), got %v", before, after)
+	}
+
+	// The key expires at its original deadline despite the retries
+	mr.FastForward(50 * time.Minute)
+	if err := checker.Check(ctx, "retried-key"); err != nil {
+		t.Errorf("check after expiry: expected no error, got %v", err)
+	}
+}
+
+func TestRedisChecker_NonPositiveTTLStillExpires(t *testing.T) {
+	mr := miniredis.RunT(t)
+	client := redis.NewClient(&redis.Options{Addr: mr.Addr()})
+	defer client.Close()
+
+	checker := NewRedisCheckerWithTTL(client, 0)
+	if checker.ttl != DefaultTTL {
+		t.Errorf("expected TTL to fall back to %v, got %v", DefaultTTL, checker.ttl)
+	}
+
+	ctx := context.Background()
+	for _, ttl := range []time.Duration{0, -1, -time.Minute} {
+		key := "ttl-" + ttl.String()
+		if err := checker.CheckWithTTL(ctx, key, ttl); err != nil {
+			t.Fatalf("ttl %v: expected no error, got %v", ttl, err)
+		}
+		if got := mr.TTL(buildRedisKey(key)); got != DefaultTTL {
+			t.Errorf("ttl %v: expected key to expire after %v, got %v", ttl, DefaultTTL, got)
+		}
+	}
+}
+
+func TestRedisChecker_TTL(t *testing.T) {
+	mr := miniredis.RunT(t)
+	client := redis.NewClient(&redis.Options{Addr: mr.Addr()})
+	defer client.Close()
+
+	checker := NewRedisCheckerWithTTL(client, time.Hour)
+	ctx := context.Background()
+
+	if _, err := checker.TTL(ctx, ""); !errors.Is(err, ErrInvalidKey) {
+		t.Errorf("empty key: expected ErrInvalidKey, got %v", err)
+	}
+	if _, err := checker.TTL(ctx, "missing"); !errors.Is(err, ErrKeyNotFound) {
+		t.Errorf("missing key: expected ErrKeyNotFound, got %v", err)
+	}
+
+	if err := checker.Check(ctx, "claimed"); err != nil {
+		t.Fatalf("check: expected no error, got %v", err)
+	}
+	mr.FastForward(15 * time.Minute)
+	if ttl, err := checker.TTL(ctx, "claimed"); err != nil || ttl != 45*time.Minute {
+		t.Errorf("claimed key: expected 45m, got %v (err %v)", ttl, err)
+	}
+
+	// A key written without expiry (by hand, or by an older release) is reported as such
+	if err := mr.Set(buildRedisKey("forever"), "1"); err != nil {
+		t.Fatalf("set: %v", err)
+	}
+	if ttl, err := checker.TTL(ctx, "forever"); err != nil || ttl != NoExpiry {
+		t.Errorf("key without expiry: expected NoExpiry, got %v (err %v)", ttl, err)
+	}
+}
+
+func TestRedisChecker_TTL_RedisError(t *testing.T) {
+	client, mock := redismock.NewClientMock()
+	checker := NewRedisChecker(client)
+
+	redisErr := errors.New("redis connection error")
+	mock.ExpectTTL(KeyPrefix + "test-key").SetErr(redisErr)
+
+	_, err := checker.TTL(context.Background(), "test-key")
+	if !errors.Is(err, redisErr) {
+		t.Errorf("expected error to wrap Redis error, got %v", err)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("unmet expectations: %v", err)
+	}
+}
@@ -0,0 +1,100 @@
+// Package profile resolves display information (name, avatar) for chat users.
+//
+// The chat database does not own user profiles. A Resolver looks them up from
+// wherever they live: the RedisResolver reads a profile cache that the user
+// service keeps populated, and other implementations (e.g. a user-service
+// client) can be plugged in without touching the chat service.
+package profile
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// KeyPrefix is the prefix of the per-user profile hash in Redis
+const KeyPrefix = "user:profile:"
+
+// Hash fields read from each profile key
+const (
+	FieldDisplayName = "display_name"
+	FieldAvatarURL   = "avatar_url"
+	FieldUsername    = "username"
+)
+
+// Profile is the public display information of a user
+type Profile struct {
+	DisplayName string
+	AvatarURL   string
+	Username    string // handle used in @mentions
+}
+
+// Resolver looks up profiles for a set of users.
+// Unknown users are simply absent from the returned map.
+type Resolver interface {
+	Resolve(ctx context.Context, userIDs []string) (map[string]Profile, error)
+}
+
+// NoopResolver resolves no profiles; messages are returned without sender details
+type NoopResolver struct{}
+
+// Resolve always returns an empty result
+func (NoopResolver) Resolve(ctx context.Context, userIDs []string) (map[string]Profile, error) {
+	return nil, nil
+}
+
+// RedisResolver reads profiles from hashes at user:profile:{user_id}
+type RedisResolver struct {
+	client redis.Cmdable
+}
+
+// NewRedisResolver creates a Redis-backed profile resolver
+func NewRedisResolver(client redis.Cmdable) *RedisResolver {
+	return &RedisResolver{client: client}
+}
+
+// Resolve fetches all requested profiles in a single pipelined round trip
+func (r *RedisResolver) Resolve(ctx context.Context, userIDs []string) (map[string]Profile, error) {
+	if len(userIDs) == 0 {
+		return nil, nil
+	}
+
+	pipe := r.client.Pipeline()
+	cmds := make([]*redis.SliceCmd, len(userIDs))
+	for i, id := range userIDs {
+		cmds[i] = pipe.HMGet(ctx, buildRedisKey(id), FieldDisplayName, FieldAvatarURL, FieldUsername)
+	}
+	if _, err := pipe.Exec(ctx); err != nil && !errors.Is(err, redis.Nil) {
+		return nil, fmt.Errorf("failed to resolve profiles: %w", err)
+	}
+
+	profiles := make(map[string]Profile, len(userIDs))
+	for i, cmd := range cmds {
+		values, err := cmd.Result()
+		if err != nil || len(values) != 3 {
+			continue
+		}
+		p := Profile{
+			DisplayName: stringValue(values[0]),
+			AvatarURL:   stringValue(values[1]),
+			Username:    stringValue(values[2]),
+		}
+		if p == (Profile{}) {
+			continue
+		}
+		profiles[userIDs[i]] = p
+	}
+	return profiles, nil
+}
+
+// buildRedisKey constructs the profile hash key for a user
+func buildRedisKey(userID string) string {
+	return KeyPrefix + userID
+}
+
+func stringValue(v interface{}) string {
+	s, _ := v.(string)
+	return s
+}
@@ -0,0 +1,74 @@
+package profile
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/go-redis/redismock/v9"
+)
+
+func TestNoopResolver(t *testing.T) {
+	profiles, err := NoopResolver{}.Resolve(context.Background(), []string{"user-1"})
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if len(profiles) != 0 {
+		t.Errorf("expected no profiles, got %v", profiles)
+	}
+}
+
+func TestRedisResolver_Resolve(t *testing.T) {
+	client, mock := redismock.NewClientMock()
+	resolver := NewRedisResolver(client)
+
+	mock.ExpectHMGet(KeyPrefix+"user-1", FieldDisplayName, FieldAvatarURL, FieldUsername).
+		SetVal([]interface{}{"Alice", "https://cdn.example.com/alice.png", "alice"})
+	mock.ExpectHMGet(KeyPrefix+"user-2", FieldDisplayName, FieldAvatarURL, FieldUsername).
+		SetVal([]interface{}{nil, nil, nil})
+
+	profiles, err := resolver.Resolve(context.Background(), []string{"user-1", "user-2"})
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	want := Profile{DisplayName: "Alice", AvatarURL: "https://cdn.example.com/alice.png", Username: "alice"}
+	if got := profiles["user-1"]; got != want {
+		t.Errorf("expected %+v, got %+v", want, got)
+	}
+	if _, ok := profiles["user-2"]; ok {
+		t.Error("expected unknown user to be absent")
+	}
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("unfulfilled expectations: %v", err)
+	}
+}
+
+func TestRedisResolver_Resolve_Empty(t *testing.T) {
+	client, mock := redismock.NewClientMock()
+	resolver := NewRedisResolver(client)
+
+	profiles, err := resolver.Resolve(context.Background(), nil)
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if profiles != nil {
+		t.Errorf("expected nil profiles, got %v", profiles)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("unexpected redis calls: %v", err)
+	}
+}
+
+func TestRedisResolver_Resolve_Error(t *testing.T) {
+	client, mock := redismock.NewClientMock()
+	resolver := NewRedisResolver(client)
+
+	mock.ExpectHMGet(KeyPrefix+"user-1", FieldDisplayName, FieldAvatarURL, FieldUsername).
+		SetErr(errors.New("connection refused"))
+
+	if _, err := resolver.Resolve(context.Background(), []string{"user-1"}); err == nil {
+		t.Error("expected error when redis fails")
+	}
+}
@@ -1,18 +1,28 @@
 package main
 
 import (
+	"context"
+	"errors"
 	"log"
 	"net/http"
+	"os"
+	"os/signal"
+	"sync"
+	"syscall"
 
+	"live-service/internal/chat"
 	"live-service/internal/config"
+	"live-service/internal/events"
 	"live-service/internal/handler"
 	"live-service/internal/middleware"
+	"live-service/internal/presence"
 	"live-service/internal/repository"
 	"live-service/internal/service"
 	"live-service/internal/websocket"
 	"live-service/pkg/utils"
 
 	"github.com/gin-gonic/gin"
+	"github.com/redis/go-redis/v9"
 )
 
 func main() {
@@ -27,21 +37,74 @@ func main() {
 	if err != nil {
 		log.Fatalf("Failed to connect to database: %v", err)
 	}
-	defer db.Close()
 
 	// Initialize repositories
 	liveRepo := repository.NewLiveRepository(db)
 
-	// Initialize services
-	liveService := service.NewLiveService(liveRepo, cfg)
+	// Initialize SRS health checker
+	srsHealthChecker := utils.NewSRSHealthChecker(cfg.SRS.ServerIP, cfg.SRS.APIPort)
+	streamStats := utils.NewStreamStatsCache(srsHealthChecker, cfg.SRS.StatsCacheTTL)
+
+	// Stream chat rooms live in chat-service; disabled when CHAT_SERVICE_URL is unset
+	var chatRooms service.ChatRoomProvider
+	if cfg.Chat.ServiceURL != "" {
+		chatRooms = chat.NewRoomClient(cfg.Chat.ServiceURL, cfg.Chat.Timeout)
+	}
 
 	// Initialize WebSocket hub
 	wsHub := websocket.NewHub()
 	go wsHub.Run()
 
+	// Viewer list lives in Redis; disabled when REDIS_URL is unset
+	var viewerPresence service.ViewerPresence
+	if cfg.Presence.RedisURL != "" {
+		redisOpts, err := redis.ParseURL(cfg.Presence.RedisURL)
+		if err != nil {
+			log.Fatalf("Invalid REDIS_URL: %v", err)
+		}
+		redisClient := redis.NewClient(redisOpts)
+		defer redisClient.Close()
+		viewerPresence = presence.NewViewerStore(redisClient, cfg.Presence.TTL)
+	} else {
+		log.Printf("[presence] REDIS_URL is not set, the viewer list is disabled")
+	}
+
+	// Initialize services
+	liveService := service.NewLiveService(liveRepo, cfg, streamStats, chatRooms, wsHub, viewerPresence, srsHealthChecker)
+
+	// Expire scheduled streams that never went live
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	var background sync.WaitGroup
+	sweeper := service.NewScheduleSweeper(liveRepo, cfg.Schedule.GracePeriod, cfg.Schedule.SweepInterval)
+	background.Add(1)
+	go func() {
+		defer background.Done()
+		sweeper.Run(ctx)
+	}()
+
+	// Relay stream.started/stream.ended from the outbox; only logged when EVENTS_PUBLISH_URL is unset
+	var eventPublisher events.Publisher = events.LogPublisher{}
+	if cfg.Events.PublishURL != "" {
+		eventPublisher = events.NewHTTPPublisher(cfg.Events.PublishURL, cfg.Events.Timeout)
+	} else {
+		log.Printf("[event_relay] EVENTS_PUBLISH_URL is not set, stream events are only logged")
+	}
+	relay := events.NewRelay(repository.NewOutboxRepository(db), eventPublisher, events.RelayConfig{
+		PollInterval: cfg.Events.PollInterval,
+		BatchSize:    cfg.Events.BatchSize,
+		MaxRetries:   cfg.Events.MaxRetries,
+		Retention:    cfg.Events.Retention,
+	})
+	background.Add(1)
+	go func() {
+		defer background.Done()
+		relay.Run(ctx)
+	}()
+
 	// Initialize handlers
 	liveHandler := handler.NewLiveHandler(liveService)
-	wsHandler := handler.NewWebSocketHandler(wsHub)
+	wsHandler := handler.NewWebSocketHandler(wsHub, liveService)
 
 	// Setup router
 	router := gin.Default()
@@ -58,30 +121,47 @@ func main() {
 		{
 			live.POST("/create", middleware.Auth(), liveHandler.CreateStream)
 			live.GET("/feed", liveHandler.ListStreams)
+			live.GET("/search", liveHandler.SearchStreams)
 			// OptionalAuth allows owner to see their stream key while keeping endpoint public
 			live.GET("/:id", middleware.OptionalAuth(), liveHandler.GetStreamDetail)
 			live.GET("/:id/webrtc", middleware.OptionalAuth(), liveHandler.GetWebRTCInfo)
+			live.POST("/:id/rotate-key", middleware.Auth(), liveHandler.RotateStreamKey)
+			live.POST("/:id/bans", middleware.Auth(), liveHandler.BanViewer)
+			live.POST("/:id/cohosts", middleware.Auth(), liveHandler.AddCohost)
+			live.DELETE("/:id/cohosts/:user_id", middleware.Auth(), liveHandler.RemoveCohost)
+			live.GET("/:id/cohost", middleware.Auth(), liveHandler.GetCohostCredentials)
 		}
 
 		// Webhook routes for SRS callbacks
-		// Protected by IP whitelist - only SRS server can call these
+		// Protected by HMAC signature (when SRS_WEBHOOK_SECRET is set) and optionally by IP whitelist
 		callbacks := v1.Group("/callbacks")
-		callbacks.Use(middleware.SRSWebhookWhitelist(cfg.SRS.ServerIP))
+		if cfg.SRS.WebhookIPWhitelist {
+			callbacks.Use(middleware.SRSWebhookWhitelist(cfg.SRS.ServerIP))
+		}
+		if cfg.SRS.WebhookSecret != "" {
+			callbacks.Use(middleware.WebhookSignature(cfg.SRS.WebhookSecret, cfg.SRS.WebhookSignatureHeader))
+		} else {
+			log.Printf("[callbacks] SRS_WEBHOOK_SECRET is not set, callback signatures are not verified")
+		}
 		{
 			callbacks.POST("/on_publish", liveHandler.OnPublish)
 			callbacks.POST("/on_unpublish", liveHandler.OnUnpublish)
+			callbacks.POST("/on_play", liveHandler.OnPlay)
+			callbacks.POST("/on_stop", liveHandler.OnStop)
+			callbacks.POST("/on_hls", liveHandler.OnHLS)
+			callbacks.POST("/on_dvr", liveHandler.OnDVR)
 		}
 
 		// Real-time viewer count endpoint
 		live.GET("/:id/viewers", wsHandler.GetViewerCount)
+		// Who is watching (owner only) and the heartbeat keeping signed-in viewers listed
+		live.GET("/:id/viewers/list", middleware.Auth(), liveHandler.GetStreamViewers)
+		live.POST("/:id/viewers/heartbeat", middleware.Auth(), liveHandler.HeartbeatViewer)
 	}
 
 	// WebSocket routes (outside /api/v1 for cleaner URLs)
 	router.GET("/ws/live/:id", wsHandler.HandleWebSocket)
 
-	// Initialize SRS health checker
-	srsHealthChecker := utils.NewSRSHealthChecker(cfg.SRS.ServerIP, cfg.SRS.APIPort)
-
 	// Health check - API service only
 	router.GET("/health", func(c *gin.Context) {
 		c.JSON(http.StatusOK, gin.H{"status": "ok"})
@@ -105,9 +185,48 @@ func main() {
 		})
 	})
 
+	srv := &http.Server{
+		Addr:         ":" + cfg.Server.Port,
+		Handler:      router,
+		ReadTimeout:  cfg.Server.ReadTimeout,
+		WriteTimeout: cfg.Server.WriteTimeout,
+	}
+	// WebSocket connections are hijacked and not tracked by Shutdown, close them explicitly
+	srv.RegisterOnShutdown(func() {
+		wsHub.CloseAll("server shutting down")
+	})
+
 	// Start server
-	log.Printf("Starting server on port %s", cfg.Server.Port)
-	if err := router.Run(":" + cfg.Server.Port); err != nil {
-		log.Fatalf("Failed to start server: %v", err)
+	go func() {
+		log.Printf("Starting server on port %s", cfg.Server.Port)
+		if err := srv.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			log.Fatalf("Failed to start server: %v", err)
+		}
+	}()
+
+	// Wait for SIGINT/SIGTERM
+	quit := make(chan os.Signal, 1)
+	signal.Notify(quit, syscall.SIGINT, syscall.SIGTERM)
+	sig := <-quit
+	log.Printf("Received %s, shutting down", sig)
+
+	cancel()
+
+	// Shutdown stops accepting connections and waits for in-flight requests
+	// (e.g. on_publish/on_unpublish callbacks) to finish
+	shutdownCtx, shutdownCancel := context.WithTimeout(context.Background(), cfg.Server.ShutdownTimeout)
+	defer shutdownCancel()
+	if err := srv.Shutdown(shutdownCtx); err != nil {
+		log.Printf("Server shutdown did not complete cleanly: %v", err)
+	}
+
+	// The sweeper and relay stop on the cancelled context; let an in-flight sweep or flush
+	// return before the database goes away
+	background.Wait()
+
+	// Close the database only after handlers and background workers have drained
+	if err := db.Close(); err != nil {
+		log.Printf("Failed to close database: %v", err)
 	}
+	log.Printf("Server stopped")
 }
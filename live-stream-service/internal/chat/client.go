@@ -0,0 +1,98 @@
+package chat
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// roomNamespace scopes stream chat room ids so they never collide with regular conversations
+var roomNamespace = uuid.MustParse("6f1c2b5e-3d4a-4f8e-9b7c-2a1d0e5f4c3b")
+
+// RoomID returns the conversation id of a stream's chat room
+// It is derived from the stream ID, so retried callbacks always address the same room
+func RoomID(streamID string) string {
+	return uuid.NewSHA1(roomNamespace, []byte("live:"+streamID)).String()
+}
+
+// RoomClient opens and closes stream chat rooms in chat-service
+// chat-service creates conversations on their first message, so a room is opened by posting
+// an announcement as the stream owner through the SendMessage RPC (HTTP gateway: POST /v1/messages)
+type RoomClient struct {
+	baseURL    string
+	httpClient *http.Client
+}
+
+// NewRoomClient creates a client for the chat-service HTTP gateway at baseURL
+func NewRoomClient(baseURL string, timeout time.Duration) *RoomClient {
+	return &RoomClient{
+		baseURL: strings.TrimRight(baseURL, "/"),
+		httpClient: &http.Client{
+			Timeout: timeout,
+		},
+	}
+}
+
+// sendMessageRequest mirrors chat.v1.SendMessageRequest (JSON field names from the gateway)
+type sendMessageRequest struct {
+	ConversationID string `json:"conversation_id"`
+	Content        string `json:"content"`
+	IdempotencyKey string `json:"idempotency_key"`
+}
+
+// OpenRoom creates the chat room for a stream that just went LIVE and returns its conversation id
+func (c *RoomClient) OpenRoom(ctx context.Context, streamID, ownerID, title string) (string, error) {
+	conversationID := RoomID(streamID)
+	err := c.sendMessage(ctx, ownerID, sendMessageRequest{
+		ConversationID: conversationID,
+		Content:        fmt.Sprintf("%s is live", title),
+		IdempotencyKey: "live:" + streamID + ":open",
+	})
+	if err != nil {
+		return "", err
+	}
+	return conversationID, nil
+}
+
+// CloseRoom posts the end-of-stream notice to a stream's chat room
+// chat-service has no notion of closed conversations; live-service stops exposing the room instead
+func (c *RoomClient) CloseRoom(ctx context.Context, streamID, ownerID, conversationID string) error {
+	return c.sendMessage(ctx, ownerID, sendMessageRequest{
+		ConversationID: conversationID,
+		Content:        "Stream ended",
+		IdempotencyKey: "live:" + streamID + ":close",
+	})
+}
+
+func (c *RoomClient) sendMessage(ctx context.Context, senderID string, msg sendMessageRequest) error {
+	body, err := json.Marshal(msg)
+	if err != nil {
+		return fmt.Errorf("failed to encode message: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, c.baseURL+"/v1/messages", bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	// chat-service trusts x-user-id from internal callers (normally set by the API gateway)
+	req.Header.Set("X-User-ID", senderID)
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("chat service unreachable: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("chat service returned status %d", resp.StatusCode)
+	}
+
+	return nil
+}
@@ -0,0 +1,51 @@
+package chat
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRoomID_Deterministic(t *testing.T) {
+	assert.Equal(t, RoomID("V1StGXR8_Z5jdHi6B-myT"), RoomID("V1StGXR8_Z5jdHi6B-myT"))
+	assert.NotEqual(t, RoomID("V1StGXR8_Z5jdHi6B-myT"), RoomID("other-stream"))
+}
+
+func TestOpenRoom_PostsAnnouncementAsOwner(t *testing.T) {
+	var got sendMessageRequest
+	var senderID string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/v1/messages", r.URL.Path)
+		senderID = r.Header.Get("X-User-ID")
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&got))
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	client := NewRoomClient(server.URL, time.Second)
+	conversationID, err := client.OpenRoom(context.Background(), "V1StGXR8_Z5jdHi6B-myT", "550e8400-e29b-41d4-a716-446655440000", "My Stream")
+
+	require.NoError(t, err)
+	assert.Equal(t, RoomID("V1StGXR8_Z5jdHi6B-myT"), conversationID)
+	assert.Equal(t, conversationID, got.ConversationID)
+	assert.Equal(t, "550e8400-e29b-41d4-a716-446655440000", senderID)
+	assert.Equal(t, "live:V1StGXR8_Z5jdHi6B-myT:open", got.IdempotencyKey)
+}
+
+func TestOpenRoom_ChatServiceError(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer server.Close()
+
+	client := NewRoomClient(server.URL, time.Second)
+	_, err := client.OpenRoom(context.Background(), "V1StGXR8_Z5jdHi6B-myT", "550e8400-e29b-41d4-a716-446655440000", "My Stream")
+
+	assert.Error(t, err)
+}
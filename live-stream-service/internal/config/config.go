@@ -6,8 +6,12 @@ import (
 	"encoding/base64"
 	"fmt"
 	"log"
+	"path/filepath"
+	"strings"
 	"time"
 
+	"live-service/pkg/utils"
+
 	"github.com/jmoiron/sqlx"
 	"github.com/joho/godotenv"
 	_ "github.com/lib/pq"
@@ -15,15 +19,22 @@ import (
 )
 
 type Config struct {
-	Server   ServerConfig   `mapstructure:"server"`
-	Database DatabaseConfig `mapstructure:"database"`
-	SRS      SRSConfig      `mapstructure:"srs"`
-	GCS      GCSConfig      `mapstructure:"gcs"`
-	CDN      CDNConfig      `mapstructure:"cdn"`
-	Auth     AuthConfig     `mapstructure:"auth"`
-	Budget   BudgetConfig   `mapstructure:"budget"`
-	TURN     TURNConfig     `mapstructure:"turn"`
-	Env      string         `mapstructure:"env"`
+	Server    ServerConfig    `mapstructure:"server"`
+	Database  DatabaseConfig  `mapstructure:"database"`
+	SRS       SRSConfig       `mapstructure:"srs"`
+	GCS       GCSConfig       `mapstructure:"gcs"`
+	CDN       CDNConfig       `mapstructure:"cdn"`
+	Auth      AuthConfig      `mapstructure:"auth"`
+	Budget    BudgetConfig    `mapstructure:"budget"`
+	TURN      TURNConfig      `mapstructure:"turn"`
+	Schedule  ScheduleConfig  `mapstructure:"schedule"`
+	Chat      ChatConfig      `mapstructure:"chat"`
+	Thumbnail ThumbnailConfig `mapstructure:"thumbnail"`
+	Events    EventsConfig    `mapstructure:"events"`
+	StreamKey StreamKeyConfig `mapstructure:"stream_key"`
+	Presence  PresenceConfig  `mapstructure:"presence"`
+	Recording RecordingConfig `mapstructure:"recording"`
+	Env       string          `mapstructure:"env"`
 }
 
 type ServerConfig struct {
@@ -31,6 +42,8 @@ type ServerConfig struct {
 	Host         string        `mapstructure:"host"`
 	ReadTimeout  time.Duration `mapstructure:"read_timeout"`
 	WriteTimeout time.Duration `mapstructure:"write_timeout"`
+	// How long in-flight requests (SRS callbacks) may take to finish on SIGINT/SIGTERM
+	ShutdownTimeout time.Duration `mapstructure:"shutdown_timeout"`
 }
 
 type DatabaseConfig struct {
@@ -54,6 +67,14 @@ type SRSConfig struct {
 	WebRTCPort  int    `mapstructure:"webrtc_port"`
 	APIPort     int    `mapstructure:"api_port"`
 	CallbackURL string `mapstructure:"callback_url"`
+	App         string `mapstructure:"app"` // SRS app name, e.g. "live" in rtmp://server/live/stream_id
+	// How long per-stream stats from the SRS API are reused before querying again
+	StatsCacheTTL time.Duration `mapstructure:"stats_cache_ttl"`
+	// Shared secret for HMAC-SHA256 callback signatures; signature checks are disabled when empty
+	WebhookSecret          string `mapstructure:"webhook_secret"`
+	WebhookSignatureHeader string `mapstructure:"webhook_signature_header"`
+	// Keep the source IP whitelist as a second layer (source IPs are unreliable behind NAT)
+	WebhookIPWhitelist bool `mapstructure:"webhook_ip_whitelist"`
 }
 
 type GCSConfig struct {
@@ -85,6 +106,51 @@ type TURNConfig struct {
 	Secret        string        `mapstructure:"secret"`
 	ExternalIP    string        `mapstructure:"external_ip"`
 	CredentialTTL time.Duration `mapstructure:"credential_ttl"`
+	ForceRelay    bool          `mapstructure:"force_relay"` // Hint clients to use iceTransportPolicy "relay" (TURN only)
+}
+
+type ScheduleConfig struct {
+	GracePeriod   time.Duration `mapstructure:"grace_period"`   // How long after scheduled_start_at a stream may still go live
+	SweepInterval time.Duration `mapstructure:"sweep_interval"` // How often overdue scheduled streams are expired
+}
+
+type ThumbnailConfig struct {
+	PlaceholderURL string `mapstructure:"placeholder_url"` // Shown for streams without a captured snapshot
+}
+
+type ChatConfig struct {
+	ServiceURL string        `mapstructure:"service_url"` // chat-service HTTP gateway, empty disables stream chat rooms
+	Timeout    time.Duration `mapstructure:"timeout"`     // Per-call timeout, keeps SRS callbacks fast when chat is down
+}
+
+// EventsConfig controls delivery of stream.started/stream.ended events from the stream outbox
+type EventsConfig struct {
+	PublishURL   string        `mapstructure:"publish_url"`   // Endpoint events are POSTed to, empty only logs them
+	Timeout      time.Duration `mapstructure:"timeout"`       // Per-event request timeout
+	PollInterval time.Duration `mapstructure:"poll_interval"` // How often the outbox is polled
+	BatchSize    int           `mapstructure:"batch_size"`    // Events relayed per poll
+	MaxRetries   int           `mapstructure:"max_retries"`   // Failed deliveries before an event is given up
+	Retention    time.Duration `mapstructure:"retention"`     // How long published events are kept
+}
+
+// StreamKeyConfig controls the format of newly issued stream keys
+// Changing it only affects new and rotated keys; existing keys are legacy until their owners rotate them
+type StreamKeyConfig struct {
+	Prefix       string `mapstructure:"prefix"`        // Namespace before the random part, e.g. a tenant on a shared SRS
+	Length       int    `mapstructure:"length"`        // Number of random characters
+	Charset      string `mapstructure:"charset"`       // Alphabet of the random part (URL safe characters only)
+	RejectLegacy bool   `mapstructure:"reject_legacy"` // Refuse to publish with keys not in the current format
+}
+
+// PresenceConfig controls the Redis-backed list of who is watching each stream
+type PresenceConfig struct {
+	RedisURL string        `mapstructure:"redis_url"` // redis://[:password@]host:port/db, empty disables the viewer list
+	TTL      time.Duration `mapstructure:"ttl"`       // Viewers not seen (on_play or heartbeat) for this long are dropped
+}
+
+// RecordingConfig locates the DVR files SRS reports through on_dvr
+type RecordingConfig struct {
+	Dir string `mapstructure:"dir"` // SRS output root served under CDN_BASE_URL (the /data volume); files outside it are not stored
 }
 
 func Load() (*Config, error) {
@@ -120,6 +186,9 @@ func (c *Config) Validate() error {
 	if c.Database.DBName == "" {
 		return fmt.Errorf("database name is required")
 	}
+	if _, err := utils.NewStreamKeyGenerator(c.StreamKey.Prefix, c.StreamKey.Charset, c.StreamKey.Length); err != nil {
+		return err
+	}
 	if c.Auth.JWTSecret == "" || c.Auth.JWTSecret == "your-super-secret-jwt-key-change-in-production" {
 		log.Println("WARNING: Using default JWT secret. Please set JWT_SECRET in production!")
 	}
@@ -140,20 +209,89 @@ func (c *Config) IsProduction() bool {
 // Format: rtmp://server/live/stream_id?token=stream_key
 // This keeps stream_key secret - only visible in OBS, not in playback URLs
 func (c *Config) GetRTMPURL(streamID string, streamKey string) string {
-	return fmt.Sprintf("rtmp://%s:%d/live/%s?token=%s", c.SRS.ServerIP, c.SRS.RTMPPort, streamID, streamKey)
+	return fmt.Sprintf("rtmp://%s:%d/%s/%s?token=%s", c.SRS.ServerIP, c.SRS.RTMPPort, c.SRSApp(), streamID, streamKey)
 }
 
 // GetWebRTCURL constructs the WebRTC publish URL with token authentication
 // Format: webrtc://server/live/stream_id?token=stream_key
 func (c *Config) GetWebRTCURL(streamID string, streamKey string) string {
-	return fmt.Sprintf("webrtc://%s/live/%s?token=%s", c.SRS.ServerIP, streamID, streamKey)
+	return fmt.Sprintf("webrtc://%s/%s/%s?token=%s", c.SRS.ServerIP, c.SRSApp(), streamID, streamKey)
 }
 
 // GetHLSURL constructs the HLS playback URL using stream ID (public, no token)
 // Format: https://cdn/live/stream_id.m3u8
+// Mirrors srs.conf: hls_m3u8_file [app]/[stream].m3u8 on __defaultVhost__ (vhost is not part of the path)
+// Falls back to the SRS http_server when no CDN is configured
 // Viewers only see the stream ID, never the secret stream_key
 func (c *Config) GetHLSURL(streamID string) string {
-	return fmt.Sprintf("%s/live/%s.m3u8", c.CDN.BaseURL, streamID)
+	baseURL := c.CDN.BaseURL
+	if baseURL == "" {
+		baseURL = fmt.Sprintf("http://%s:%d", c.SRS.ServerIP, c.SRS.HTTPPort)
+	}
+	return fmt.Sprintf("%s/%s/%s.m3u8", baseURL, c.SRSApp(), streamID)
+}
+
+// GetThumbnailURL constructs the snapshot URL of a stream
+// Mirrors the snapshot transcode engine in srs.conf: output /data/[app]/[stream].jpg next to the HLS playlist
+func (c *Config) GetThumbnailURL(streamID string) string {
+	baseURL := c.CDN.BaseURL
+	if baseURL == "" {
+		baseURL = fmt.Sprintf("http://%s:%d", c.SRS.ServerIP, c.SRS.HTTPPort)
+	}
+	return fmt.Sprintf("%s/%s/%s.jpg", baseURL, c.SRSApp(), streamID)
+}
+
+// RecordingPath resolves the file of an on_dvr callback to its path relative to the recording dir
+// SRS reports the dvr_path as configured, so relative files are resolved against its cwd
+// Returns false for files outside the recording dir, which the CDN cannot serve
+func (c *Config) RecordingPath(cwd, file string) (string, bool) {
+	if file == "" {
+		return "", false
+	}
+	if !filepath.IsAbs(file) {
+		file = filepath.Join(cwd, file)
+	}
+	rel, err := filepath.Rel(filepath.Clean(c.Recording.Dir), filepath.Clean(file))
+	if err != nil || rel == "." || rel == ".." || strings.HasPrefix(rel, "../") {
+		return "", false
+	}
+	return filepath.ToSlash(rel), true
+}
+
+// GetRecordingURL constructs the playback URL of a DVR file from its path relative to the recording dir
+// Mirrors srs.conf: dvr_path /data/recordings/[app]/[stream]/[timestamp].mp4 next to the HLS output
+func (c *Config) GetRecordingURL(path string) string {
+	baseURL := c.CDN.BaseURL
+	if baseURL == "" {
+		baseURL = fmt.Sprintf("http://%s:%d", c.SRS.ServerIP, c.SRS.HTTPPort)
+	}
+	return fmt.Sprintf("%s/%s", baseURL, path)
+}
+
+// SRSApp returns the configured SRS app name, defaulting to "live"
+func (c *Config) SRSApp() string {
+	if c.SRS.App == "" {
+		return "live"
+	}
+	return c.SRS.App
+}
+
+// GetWHIPURL constructs the WHIP publish endpoint on the SRS HTTP API with token authentication
+// SRS checks ?token= through the on_publish callback, same as RTMP/WebRTC publish
+func (c *Config) GetWHIPURL(streamID string, streamKey string) string {
+	return fmt.Sprintf("%s/rtc/v1/whip/?app=%s&stream=%s&token=%s",
+		c.srsAPIBase(), c.SRSApp(), streamID, streamKey)
+}
+
+// GetWHEPURL constructs the WHEP playback endpoint on the SRS HTTP API (public, no token)
+func (c *Config) GetWHEPURL(streamID string) string {
+	return fmt.Sprintf("%s/rtc/v1/whep/?app=%s&stream=%s",
+		c.srsAPIBase(), c.SRSApp(), streamID)
+}
+
+// srsAPIBase returns the base URL of the SRS HTTP API (serves WHIP/WHEP)
+func (c *Config) srsAPIBase() string {
+	return fmt.Sprintf("http://%s:%d", c.SRS.ServerIP, c.SRS.APIPort)
 }
 
 // TURNCredentials holds time-limited TURN credentials (RFC 5766)
@@ -215,6 +353,15 @@ func (c *Config) GetICEServers() []ICEServer {
 	return servers
 }
 
+// ICETransportPolicy returns the RTCPeerConnection iceTransportPolicy hint for clients
+// "relay" is only returned when a TURN server is configured, otherwise clients could not connect at all
+func (c *Config) ICETransportPolicy() string {
+	if c.TURN.ForceRelay && c.TURN.Secret != "" && c.TURN.ExternalIP != "" {
+		return "relay"
+	}
+	return "all"
+}
+
 // GetDSN returns the PostgreSQL connection string
 func (c *Config) GetDSN() string {
 	return fmt.Sprintf("host=%s port=%d user=%s password=%s dbname=%s sslmode=%s",
@@ -236,6 +383,7 @@ func setDefaults() {
 	_ = viper.BindEnv("server.host", "SERVER_HOST")
 	_ = viper.BindEnv("server.read_timeout", "SERVER_READ_TIMEOUT")
 	_ = viper.BindEnv("server.write_timeout", "SERVER_WRITE_TIMEOUT")
+	_ = viper.BindEnv("server.shutdown_timeout", "SERVER_SHUTDOWN_TIMEOUT")
 
 	// Database bindings
 	_ = viper.BindEnv("database.host", "DB_HOST")
@@ -257,6 +405,11 @@ func setDefaults() {
 	_ = viper.BindEnv("srs.webrtc_port", "SRS_WEBRTC_PORT")
 	_ = viper.BindEnv("srs.api_port", "SRS_API_PORT")
 	_ = viper.BindEnv("srs.callback_url", "SRS_CALLBACK_URL")
+	_ = viper.BindEnv("srs.app", "SRS_APP")
+	_ = viper.BindEnv("srs.stats_cache_ttl", "SRS_STATS_CACHE_TTL")
+	_ = viper.BindEnv("srs.webhook_secret", "SRS_WEBHOOK_SECRET")
+	_ = viper.BindEnv("srs.webhook_signature_header", "SRS_WEBHOOK_SIGNATURE_HEADER")
+	_ = viper.BindEnv("srs.webhook_ip_whitelist", "SRS_WEBHOOK_IP_WHITELIST")
 
 	// GCS bindings
 	_ = viper.BindEnv("gcs.bucket_name", "GCS_BUCKET_NAME")
@@ -283,6 +436,39 @@ func setDefaults() {
 	_ = viper.BindEnv("turn.secret", "TURN_SECRET")
 	_ = viper.BindEnv("turn.external_ip", "TURN_EXTERNAL_IP")
 	_ = viper.BindEnv("turn.credential_ttl", "TURN_CREDENTIAL_TTL")
+	_ = viper.BindEnv("turn.force_relay", "TURN_FORCE_RELAY")
+
+	// Schedule bindings
+	_ = viper.BindEnv("schedule.grace_period", "SCHEDULE_GRACE_PERIOD")
+	_ = viper.BindEnv("schedule.sweep_interval", "SCHEDULE_SWEEP_INTERVAL")
+
+	// Thumbnail bindings
+	_ = viper.BindEnv("thumbnail.placeholder_url", "THUMBNAIL_PLACEHOLDER_URL")
+
+	// Chat bindings
+	_ = viper.BindEnv("chat.service_url", "CHAT_SERVICE_URL")
+	_ = viper.BindEnv("chat.timeout", "CHAT_TIMEOUT")
+
+	// Events bindings
+	_ = viper.BindEnv("events.publish_url", "EVENTS_PUBLISH_URL")
+	_ = viper.BindEnv("events.timeout", "EVENTS_TIMEOUT")
+	_ = viper.BindEnv("events.poll_interval", "EVENTS_POLL_INTERVAL")
+	_ = viper.BindEnv("events.batch_size", "EVENTS_BATCH_SIZE")
+	_ = viper.BindEnv("events.max_retries", "EVENTS_MAX_RETRIES")
+	_ = viper.BindEnv("events.retention", "EVENTS_RETENTION")
+
+	// Stream key bindings
+	_ = viper.BindEnv("stream_key.prefix", "STREAM_KEY_PREFIX")
+	_ = viper.BindEnv("stream_key.length", "STREAM_KEY_LENGTH")
+	_ = viper.BindEnv("stream_key.charset", "STREAM_KEY_CHARSET")
+	_ = viper.BindEnv("stream_key.reject_legacy", "STREAM_KEY_REJECT_LEGACY")
+
+	// Presence bindings
+	_ = viper.BindEnv("presence.redis_url", "REDIS_URL")
+	_ = viper.BindEnv("presence.ttl", "PRESENCE_TTL")
+
+	// Recording bindings
+	_ = viper.BindEnv("recording.dir", "RECORDING_DIR")
 
 	// Environment defaults
 	viper.SetDefault("env", "development")
@@ -292,6 +478,7 @@ func setDefaults() {
 	viper.SetDefault("server.host", "localhost")
 	viper.SetDefault("server.read_timeout", 30*time.Second)
 	viper.SetDefault("server.write_timeout", 30*time.Second)
+	viper.SetDefault("server.shutdown_timeout", 15*time.Second)
 
 	// Database defaults - optimized for 50+ concurrent streams
 	viper.SetDefault("database.host", "localhost")
@@ -313,6 +500,11 @@ func setDefaults() {
 	viper.SetDefault("srs.webrtc_port", 1985)
 	viper.SetDefault("srs.api_port", 1985)
 	viper.SetDefault("srs.callback_url", "http://localhost:8080/api/v1/callbacks")
+	viper.SetDefault("srs.app", "live")
+	viper.SetDefault("srs.stats_cache_ttl", 5*time.Second)
+	viper.SetDefault("srs.webhook_secret", "")
+	viper.SetDefault("srs.webhook_signature_header", "X-SRS-Signature")
+	viper.SetDefault("srs.webhook_ip_whitelist", true)
 
 	// GCS defaults
 	viper.SetDefault("gcs.bucket_name", "social-app-live-hls-staging")
@@ -339,6 +531,39 @@ func setDefaults() {
 	viper.SetDefault("turn.secret", "")
 	viper.SetDefault("turn.external_ip", "")
 	viper.SetDefault("turn.credential_ttl", 24*time.Hour)
+	viper.SetDefault("turn.force_relay", false)
+
+	// Schedule defaults
+	viper.SetDefault("schedule.grace_period", 30*time.Minute)
+	viper.SetDefault("schedule.sweep_interval", time.Minute)
+
+	// Thumbnail defaults
+	viper.SetDefault("thumbnail.placeholder_url", "https://cdn.example.com/static/live-placeholder.jpg")
+
+	// Chat defaults
+	viper.SetDefault("chat.service_url", "")
+	viper.SetDefault("chat.timeout", 2*time.Second)
+
+	// Events defaults
+	viper.SetDefault("events.publish_url", "")
+	viper.SetDefault("events.timeout", 5*time.Second)
+	viper.SetDefault("events.poll_interval", time.Second)
+	viper.SetDefault("events.batch_size", 100)
+	viper.SetDefault("events.max_retries", 10)
+	viper.SetDefault("events.retention", 7*24*time.Hour)
+
+	// Stream key defaults (43 base62 characters = 256 bits)
+	viper.SetDefault("stream_key.prefix", "live")
+	viper.SetDefault("stream_key.length", utils.DefaultStreamKeyLength)
+	viper.SetDefault("stream_key.charset", utils.DefaultStreamKeyCharset)
+	viper.SetDefault("stream_key.reject_legacy", false)
+
+	// Presence defaults
+	viper.SetDefault("presence.redis_url", "")
+	viper.SetDefault("presence.ttl", 90*time.Second)
+
+	// Recording defaults (the SRS /data volume, see docker-compose.yml)
+	viper.SetDefault("recording.dir", "/data")
 }
 
 func InitDB(cfg *Config) (*sqlx.DB, error) {
@@ -2,13 +2,20 @@ package entity
 
 import (
 	"database/sql/driver"
+	"encoding/base64"
 	"errors"
+	"fmt"
+	"strconv"
+	"strings"
 	"time"
 
 	"github.com/google/uuid"
 	nanoid "github.com/matoous/go-nanoid/v2"
 )
 
+// ErrInvalidCursor is returned when a feed cursor cannot be decoded
+var ErrInvalidCursor = errors.New("invalid cursor")
+
 // LiveSessionStatus represents the status of a live session
 type LiveSessionStatus string
 
@@ -16,6 +23,9 @@ const (
 	StatusIdle  LiveSessionStatus = "IDLE"  // Stream created but not started
 	StatusLive  LiveSessionStatus = "LIVE"  // Stream is currently live
 	StatusEnded LiveSessionStatus = "ENDED" // Stream has ended
+
+	StatusScheduled LiveSessionStatus = "SCHEDULED" // Stream announced for a future start time
+	StatusExpired   LiveSessionStatus = "EXPIRED"   // Scheduled stream never went live within the grace period
 )
 
 // Scan implements sql.Scanner for LiveSessionStatus
@@ -76,17 +86,32 @@ type LiveSession struct {
 	RTMPUrl     *string           `json:"rtmp_url,omitempty" db:"rtmp_url"`
 	WebRTCUrl   *string           `json:"webrtc_url,omitempty" db:"webrtc_url"`
 	HLSUrl      *string           `json:"hls_url,omitempty" db:"hls_url"`
-	ViewerCount int               `json:"viewer_count" db:"viewer_count"`
-	StartedAt   *time.Time        `json:"started_at,omitempty" db:"started_at"`
-	EndedAt     *time.Time        `json:"ended_at,omitempty" db:"ended_at"`
-	CreatedAt   time.Time         `json:"created_at" db:"created_at"`
-	UpdatedAt   time.Time         `json:"updated_at" db:"updated_at"`
+	// Snapshot for the feed, set once the stream produces video
+	ThumbnailURL *string `json:"thumbnail_url,omitempty" db:"thumbnail_url"`
+	ViewerCount  int     `json:"viewer_count" db:"viewer_count"`
+	// Scheduling
+	ScheduledStartAt *time.Time `json:"scheduled_start_at,omitempty" db:"scheduled_start_at"`
+	// SRS client_id of the current publisher (used to detect callback retries)
+	PublisherClientID *string `json:"-" db:"publisher_client_id"`
+	// Co-host publishing the stream, nil while the owner publishes
+	PublisherCohostID *string `json:"-" db:"publisher_cohost_id"`
+	// chat-service conversation of the stream's chat room (nil if chat was unavailable at go-live)
+	ChatConversationID *string `json:"chat_conversation_id,omitempty" db:"chat_conversation_id"`
+	// Analytics
+	PeakViewerCount int        `json:"peak_viewer_count" db:"peak_viewer_count"`
+	DurationSeconds *int       `json:"duration_seconds,omitempty" db:"duration_seconds"` // Set when stream ends
+	StartedAt       *time.Time `json:"started_at,omitempty" db:"started_at"`
+	EndedAt         *time.Time `json:"ended_at,omitempty" db:"ended_at"`
+	CreatedAt       time.Time  `json:"created_at" db:"created_at"`
+	UpdatedAt       time.Time  `json:"updated_at" db:"updated_at"`
 }
 
 // CreateStreamRequest represents the request to create a new stream
 type CreateStreamRequest struct {
 	Title       string `json:"title" binding:"required,min=1,max=255"`
 	Description string `json:"description" binding:"max=1000"`
+	// Optional: announce the stream for a future time (creates a SCHEDULED stream)
+	ScheduledStartAt *time.Time `json:"scheduled_start_at"`
 }
 
 // CreateStreamResponse represents the response after creating a stream
@@ -96,20 +121,142 @@ type CreateStreamResponse struct {
 	RTMPUrl   string `json:"rtmp_url"`
 	WebRTCUrl string `json:"webrtc_url"`
 	HLSUrl    string `json:"hls_url"`
+	// Set for scheduled streams
+	Status           LiveSessionStatus `json:"status"`
+	ScheduledStartAt *time.Time        `json:"scheduled_start_at,omitempty"`
+}
+
+// RotateStreamKeyResponse represents the response after rotating a stream key
+type RotateStreamKeyResponse struct {
+	ID        string `json:"id"` // NanoID
+	StreamKey string `json:"stream_key"`
+	RTMPUrl   string `json:"rtmp_url"`
+	WebRTCUrl string `json:"webrtc_url"`
+}
+
+// BanViewerRequest represents the request to ban a viewer from a stream
+type BanViewerRequest struct {
+	UserID string `json:"user_id" binding:"required"` // UUID of the viewer
+	Reason string `json:"reason" binding:"max=500"`
+}
+
+// ViewerBan is a viewer banned from a single stream
+type ViewerBan struct {
+	StreamID string    `json:"-" db:"stream_id"`
+	UserID   string    `json:"user_id" db:"user_id"`
+	Reason   *string   `json:"reason,omitempty" db:"reason"`
+	BannedAt time.Time `json:"banned_at" db:"banned_at"`
+}
+
+// ViewerBanListResponse lists the viewers banned from a stream, newest first
+type ViewerBanListResponse struct {
+	StreamID string      `json:"stream_id"` // NanoID
+	Bans     []ViewerBan `json:"bans"`
+}
+
+// AddCohostRequest represents the request to let another user publish to a stream
+type AddCohostRequest struct {
+	UserID string `json:"user_id" binding:"required"` // UUID of the co-host
+}
+
+// StreamCohost is a user allowed to publish to a stream they don't own
+type StreamCohost struct {
+	StreamID  string    `json:"-" db:"stream_id"`
+	UserID    string    `json:"user_id" db:"user_id"`
+	Token     string    `json:"-" db:"token"` // Secret publish token, only shown to the co-host
+	GrantedAt time.Time `json:"granted_at" db:"granted_at"`
+}
+
+// CohostListResponse lists the co-hosts of a stream, oldest first
+type CohostListResponse struct {
+	StreamID string         `json:"stream_id"` // NanoID
+	Cohosts  []StreamCohost `json:"cohosts"`
+}
+
+// CohostCredentialsResponse carries a co-host's publish URLs; the co-host token takes the place of the stream key
+type CohostCredentialsResponse struct {
+	ID           string            `json:"id"` // NanoID
+	Status       LiveSessionStatus `json:"status"`
+	Token        string            `json:"token"`
+	RTMPUrl      string            `json:"rtmp_url"`
+	WebRTCUrl    string            `json:"webrtc_url"`
+	WHIPEndpoint string            `json:"whip_endpoint"`
+}
+
+// StreamRecording is one DVR file SRS wrote while a stream was LIVE
+type StreamRecording struct {
+	StreamID   string    `db:"stream_id"`
+	FilePath   string    `db:"file_path"` // Relative to the recording dir, e.g. "recordings/live/{id}/{ms}.mp4"
+	StartedAt  time.Time `db:"started_at"`
+	DurationMs int64     `db:"duration_ms"`
+	RecordedAt time.Time `db:"recorded_at"` // When on_dvr reported the file closed
+}
+
+// RecordingFile is one part of a stream's VOD; players play the parts in sequence order
+type RecordingFile struct {
+	Sequence        int       `json:"sequence"` // 1-based position in the recording
+	URL             string    `json:"url"`
+	StartedAt       time.Time `json:"started_at"`
+	DurationSeconds float64   `json:"duration_seconds"`
+	// Start of this part within the concatenated recording, the sum of the earlier parts' durations
+	OffsetSeconds float64 `json:"offset_seconds"`
+}
+
+// StreamRecordingInfo is the VOD of an ENDED stream that was recorded
+type StreamRecordingInfo struct {
+	DurationSeconds float64         `json:"duration_seconds"` // Total of all parts
+	Files           []RecordingFile `json:"files"`
+}
+
+// StreamViewer is a signed-in user currently watching a stream
+type StreamViewer struct {
+	UserID     string    `json:"user_id"`      // UUID
+	LastSeenAt time.Time `json:"last_seen_at"` // Last on_play or heartbeat
+}
+
+// ListStreamViewersParams represents cursor pagination for a stream's viewer list
+type ListStreamViewersParams struct {
+	Limit  int    `form:"limit" binding:"omitempty,min=1,max=100"`
+	Cursor string `form:"cursor"`
+}
+
+// StreamViewersResponse lists the signed-in viewers of a stream ordered by user_id
+// Total only counts signed-in viewers; anonymous playback is included in viewer_count alone
+// NextCursor is empty when there are no more results
+type StreamViewersResponse struct {
+	StreamID   string         `json:"stream_id"` // NanoID
+	Viewers    []StreamViewer `json:"viewers"`
+	Total      int            `json:"total"`
+	Limit      int            `json:"limit"`
+	NextCursor string         `json:"next_cursor,omitempty"`
+}
+
+// EncodeViewerCursor returns an opaque URL-safe cursor pointing after userID
+func EncodeViewerCursor(userID string) string {
+	return base64.RawURLEncoding.EncodeToString([]byte(userID))
+}
+
+// DecodeViewerCursor parses a cursor produced by EncodeViewerCursor
+func DecodeViewerCursor(cursor string) (string, error) {
+	raw, err := base64.RawURLEncoding.DecodeString(cursor)
+	if err != nil || !IsValidUUID(string(raw)) {
+		return "", ErrInvalidCursor
+	}
+	return string(raw), nil
 }
 
 // ListStreamsResponse represents the response for listing streams
+// NextCursor is empty when there are no more results
 type ListStreamsResponse struct {
 	Streams    []LiveStreamInfo `json:"streams"`
-	Total      int              `json:"total"`
-	Page       int              `json:"page"`
+	Total      int              `json:"total"` // Total streams matching the status filter
 	Limit      int              `json:"limit"`
-	TotalPages int              `json:"total_pages"`
+	NextCursor string           `json:"next_cursor,omitempty"`
 }
 
 // LiveStreamInfo represents basic stream information for listing
 type LiveStreamInfo struct {
-	ID          string            `json:"id"` // NanoID
+	ID          string            `json:"id"`      // NanoID
 	UserID      string            `json:"user_id"` // UUID
 	Title       string            `json:"title"`
 	Status      LiveSessionStatus `json:"status"`
@@ -117,16 +264,19 @@ type LiveStreamInfo struct {
 	HLSUrl      *string           `json:"hls_url,omitempty"`
 	StartedAt   *time.Time        `json:"started_at,omitempty"`
 	CreatedAt   time.Time         `json:"created_at"`
+	// Set for scheduled streams
+	ScheduledStartAt *time.Time `json:"scheduled_start_at,omitempty"`
+	// Stream snapshot, or the configured placeholder when none was captured
+	ThumbnailURL string `json:"thumbnail_url,omitempty"`
 	// User info (to be populated from user service)
-	Username  string `json:"username,omitempty"`
-	Avatar    string `json:"avatar,omitempty"`
-	Thumbnail string `json:"thumbnail,omitempty"`
+	Username string `json:"username,omitempty"`
+	Avatar   string `json:"avatar,omitempty"`
 }
 
 // StreamDetailResponse represents detailed stream information
 type StreamDetailResponse struct {
-	ID          string            `json:"id"` // NanoID
-	UserID      string            `json:"user_id"` // UUID
+	ID          string            `json:"id"`                   // NanoID
+	UserID      string            `json:"user_id"`              // UUID
 	StreamKey   string            `json:"stream_key,omitempty"` // Only shown to owner
 	Title       string            `json:"title"`
 	Description *string           `json:"description,omitempty"`
@@ -138,38 +288,174 @@ type StreamDetailResponse struct {
 	StartedAt   *time.Time        `json:"started_at,omitempty"`
 	EndedAt     *time.Time        `json:"ended_at,omitempty"`
 	CreatedAt   time.Time         `json:"created_at"`
+	// Stream snapshot, or the configured placeholder when none was captured
+	ThumbnailURL string `json:"thumbnail_url,omitempty"`
+	// Set for scheduled streams
+	ScheduledStartAt *time.Time `json:"scheduled_start_at,omitempty"`
+	// Post-stream stats (only for ENDED streams)
+	PeakViewerCount *int `json:"peak_viewer_count,omitempty"`
+	DurationSeconds *int `json:"duration_seconds,omitempty"`
+	// VOD playback (only for ENDED streams that were recorded)
+	Recording *StreamRecordingInfo `json:"recording,omitempty"`
+	// Ingest health from SRS (only for LIVE streams, omitted if SRS is unreachable)
+	Health *StreamHealth `json:"health,omitempty"`
+	// Live chat (only for LIVE streams); chat_available is false when the room couldn't be created
+	ChatConversationID *string `json:"chat_conversation_id,omitempty"`
+	ChatAvailable      bool    `json:"chat_available"`
+	// Only shown to owner: the key predates the current key format and should be rotated
+	StreamKeyOutdated bool `json:"stream_key_outdated,omitempty"`
 	// User info
 	Username string `json:"username,omitempty"`
 	Avatar   string `json:"avatar,omitempty"`
 	IsOwner  bool   `json:"is_owner"`
 }
 
-// PaginationParams represents pagination parameters
-type PaginationParams struct {
-	Page  int `form:"page" binding:"min=1"`
-	Limit int `form:"limit" binding:"min=1,max=100"`
+// StreamHealth is the ingest health of a LIVE stream as reported by SRS
+type StreamHealth struct {
+	BitrateKbps int     `json:"bitrate_kbps"`
+	FPS         float64 `json:"fps"` // 0 until SRS has been sampled twice
+	VideoCodec  string  `json:"video_codec,omitempty"`
+	AudioCodec  string  `json:"audio_codec,omitempty"`
+	Width       int     `json:"width,omitempty"`
+	Height      int     `json:"height,omitempty"`
 }
 
-// DefaultPagination returns default pagination values
-func DefaultPagination() PaginationParams {
-	return PaginationParams{Page: 1, Limit: 20}
+// Feed sort orders
+const (
+	SortByViewers = "viewers" // viewer_count DESC (default)
+	SortByRecent  = "recent"  // created_at DESC
+	// SortByScheduled orders upcoming streams by scheduled_start_at ASC (status=SCHEDULED only)
+	SortByScheduled = "scheduled"
+)
+
+// ListStreamsParams represents cursor pagination and filter parameters for the feed
+type ListStreamsParams struct {
+	Limit  int               `form:"limit" binding:"omitempty,min=1,max=100"`
+	Cursor string            `form:"cursor"`
+	Status LiveSessionStatus `form:"status"`
+	Sort   string            `form:"sort"`
 }
 
-// Offset calculates the offset for database queries
-func (p PaginationParams) Offset() int {
-	return (p.Page - 1) * p.Limit
+// DefaultListStreamsParams returns default feed params: LIVE streams
+// Sort is resolved by ResolveSort once the status filter is known
+func DefaultListStreamsParams() ListStreamsParams {
+	return ListStreamsParams{Limit: 20, Status: StatusLive}
+}
+
+// ResolveSort applies the default sort for the status filter and validates the combination
+// Upcoming (SCHEDULED) streams default to soonest first; everything else to most viewers first
+func (p *ListStreamsParams) ResolveSort() bool {
+	if p.Sort == "" {
+		p.Sort = SortByViewers
+		if p.Status == StatusScheduled {
+			p.Sort = SortByScheduled
+		}
+	}
+
+	switch p.Sort {
+	case SortByViewers, SortByRecent:
+		return true
+	case SortByScheduled:
+		return p.Status == StatusScheduled
+	default:
+		return false
+	}
+}
+
+// SearchStreamsParams represents a title search over public streams
+// Pagination, status filter and sort behave exactly like the feed
+type SearchStreamsParams struct {
+	Query string `form:"q" binding:"required,max=100"`
+	ListStreamsParams
+}
+
+// FeedCursor is the position of the last stream on a feed page
+// The full (viewer_count, created_at, scheduled_start_at, id) tuple is kept so ordering is stable for every sort
+type FeedCursor struct {
+	ViewerCount      int
+	CreatedAt        time.Time
+	ScheduledStartAt time.Time // Zero for unscheduled streams
+	ID               string
+}
+
+// NewFeedCursor builds a cursor pointing at the given session
+func NewFeedCursor(session *LiveSession) FeedCursor {
+	cursor := FeedCursor{ViewerCount: session.ViewerCount, CreatedAt: session.CreatedAt, ID: session.ID}
+	if session.ScheduledStartAt != nil {
+		cursor.ScheduledStartAt = *session.ScheduledStartAt
+	}
+	return cursor
+}
+
+// Encode returns an opaque URL-safe cursor string
+// Format (before base64): viewer_count|created_at(RFC3339Nano)|scheduled_start_at(RFC3339Nano or empty)|id
+func (c FeedCursor) Encode() string {
+	scheduled := ""
+	if !c.ScheduledStartAt.IsZero() {
+		scheduled = c.ScheduledStartAt.UTC().Format(time.RFC3339Nano)
+	}
+	raw := fmt.Sprintf("%d|%s|%s|%s", c.ViewerCount, c.CreatedAt.UTC().Format(time.RFC3339Nano), scheduled, c.ID)
+	return base64.RawURLEncoding.EncodeToString([]byte(raw))
+}
+
+// DecodeFeedCursor parses a cursor produced by FeedCursor.Encode
+func DecodeFeedCursor(cursor string) (*FeedCursor, error) {
+	raw, err := base64.RawURLEncoding.DecodeString(cursor)
+	if err != nil {
+		return nil, ErrInvalidCursor
+	}
+
+	parts := strings.SplitN(string(raw), "|", 4)
+	if len(parts) != 4 || parts[3] == "" {
+		return nil, ErrInvalidCursor
+	}
+
+	viewerCount, err := strconv.Atoi(parts[0])
+	if err != nil {
+		return nil, ErrInvalidCursor
+	}
+	createdAt, err := time.Parse(time.RFC3339Nano, parts[1])
+	if err != nil {
+		return nil, ErrInvalidCursor
+	}
+
+	var scheduledStartAt time.Time
+	if parts[2] != "" {
+		scheduledStartAt, err = time.Parse(time.RFC3339Nano, parts[2])
+		if err != nil {
+			return nil, ErrInvalidCursor
+		}
+	}
+
+	return &FeedCursor{ViewerCount: viewerCount, CreatedAt: createdAt, ScheduledStartAt: scheduledStartAt, ID: parts[3]}, nil
 }
 
 // IsValidStatus checks if the status is valid
 func (s LiveSessionStatus) IsValid() bool {
 	switch s {
-	case StatusIdle, StatusLive, StatusEnded:
+	case StatusIdle, StatusLive, StatusEnded, StatusScheduled, StatusExpired:
+		return true
+	default:
+		return false
+	}
+}
+
+// IsPublic reports whether streams in this status are visible to other users
+// IDLE streams are unannounced drafts and EXPIRED ones never happened
+func (s LiveSessionStatus) IsPublic() bool {
+	switch s {
+	case StatusLive, StatusScheduled, StatusEnded:
 		return true
 	default:
 		return false
 	}
 }
 
+// PublicStatuses lists every status for which IsPublic is true
+func PublicStatuses() []LiveSessionStatus {
+	return []LiveSessionStatus{StatusLive, StatusScheduled, StatusEnded}
+}
+
 // String returns the string representation of the status
 func (s LiveSessionStatus) String() string {
 	return string(s)
@@ -182,8 +468,10 @@ func (s LiveSessionStatus) CanTransitionTo(target LiveSessionStatus) bool {
 		return target == StatusLive || target == StatusEnded
 	case StatusLive:
 		return target == StatusEnded
-	case StatusEnded:
-		return false // Cannot transition from ended
+	case StatusScheduled:
+		return target == StatusLive || target == StatusEnded || target == StatusExpired
+	case StatusEnded, StatusExpired:
+		return false // Cannot transition from ended/expired
 	default:
 		return false
 	}
@@ -191,14 +479,17 @@ func (s LiveSessionStatus) CanTransitionTo(target LiveSessionStatus) bool {
 
 // WebRTCInfoResponse represents WebRTC connection info for a stream
 type WebRTCInfoResponse struct {
-	ID            string            `json:"id"` // NanoID
-	Status        LiveSessionStatus `json:"status"`
-	PublishURL    string            `json:"publish_url"`              // webrtc://ip/live/stream_key (owner only)
-	PlayURL       string            `json:"play_url"`                 // webrtc://ip/live/stream_key
-	WHIPEndpoint  string            `json:"whip_endpoint,omitempty"`  // WHIP publish endpoint
-	WHEPEndpoint  string            `json:"whep_endpoint,omitempty"`  // WHEP play endpoint
-	ICEServers    []ICEServer       `json:"ice_servers"`              // STUN/TURN servers
-	IsOwner       bool              `json:"is_owner"`
+	ID           string            `json:"id"` // NanoID
+	Status       LiveSessionStatus `json:"status"`
+	PublishURL   string            `json:"publish_url,omitempty"`   // webrtc://ip/live/stream_key (owner only)
+	PlayURL      string            `json:"play_url"`                // webrtc://ip/live/stream_key
+	WHIPEndpoint string            `json:"whip_endpoint,omitempty"` // WHIP publish endpoint (owner only, while publishable)
+	WHEPEndpoint string            `json:"whep_endpoint,omitempty"` // WHEP play endpoint
+	HLSUrl       string            `json:"hls_url,omitempty"`       // HLS playback URL (only when LIVE)
+	ICEServers   []ICEServer       `json:"ice_servers"`             // STUN/TURN servers
+	// RTCPeerConnection iceTransportPolicy hint: "all" or "relay" (TURN only)
+	ICETransportPolicy string `json:"ice_transport_policy"`
+	IsOwner            bool   `json:"is_owner"`
 }
 
 // ICEServer represents a STUN/TURN server for WebRTC
@@ -211,16 +502,19 @@ type ICEServer struct {
 // SRSCallbackRequest represents the webhook request from SRS server
 // Documentation: https://ossrs.io/lts/en-us/docs/v5/doc/http-callback
 type SRSCallbackRequest struct {
-	Action   string `json:"action" form:"action"`     // Event type: on_publish, on_unpublish, etc.
-	ClientID string `json:"client_id" form:"client_id"` // SRS client ID
-	IP       string `json:"ip" form:"ip"`             // Client IP address
-	Vhost    string `json:"vhost" form:"vhost"`       // Virtual host
-	App      string `json:"app" form:"app"`           // Application name (e.g., "live")
-	Stream   string `json:"stream" form:"stream"`     // Stream name (this is our stream_key)
-	Param    string `json:"param" form:"param"`       // URL parameters
-	ServerID string `json:"server_id" form:"server_id"` // SRS server ID
+	Action    string `json:"action" form:"action"`         // Event type: on_publish, on_unpublish, etc.
+	ClientID  string `json:"client_id" form:"client_id"`   // SRS client ID
+	IP        string `json:"ip" form:"ip"`                 // Client IP address
+	Vhost     string `json:"vhost" form:"vhost"`           // Virtual host
+	App       string `json:"app" form:"app"`               // Application name (e.g., "live")
+	Stream    string `json:"stream" form:"stream"`         // Stream name (this is our stream_key)
+	Param     string `json:"param" form:"param"`           // URL parameters
+	ServerID  string `json:"server_id" form:"server_id"`   // SRS server ID
 	ServiceID string `json:"service_id" form:"service_id"` // SRS service ID
-	TcUrl    string `json:"tcUrl" form:"tcUrl"`       // RTMP tcUrl
+	TcUrl     string `json:"tcUrl" form:"tcUrl"`           // RTMP tcUrl
+	// on_dvr only: the closed DVR file, relative to cwd unless absolute
+	Cwd  string `json:"cwd" form:"cwd"`
+	File string `json:"file" form:"file"`
 }
 
 // SRSCallbackResponse represents the response to SRS webhook
@@ -239,17 +533,31 @@ func (r *SRSCallbackRequest) GetStreamID() string {
 // GetToken extracts the authentication token from URL params
 // Param field contains "?token=xxx" or empty string
 func (r *SRSCallbackRequest) GetToken() string {
+	return r.GetParam("token")
+}
+
+// GetViewerID extracts the signed-in viewer from URL params
+// Players append ?user_id={uuid} to the playback URL; anything else is an anonymous viewer ("")
+func (r *SRSCallbackRequest) GetViewerID() string {
+	userID := r.GetParam("user_id")
+	if !IsValidUUID(userID) {
+		return ""
+	}
+	return userID
+}
+
+// GetParam returns the value of a URL parameter, or "" if it is missing or empty
+// Param can be "?token=abc123&user_id=..." or "token=abc123&user_id=..."
+func (r *SRSCallbackRequest) GetParam(name string) string {
 	if r.Param == "" {
 		return ""
 	}
-	// Parse ?token=xxx format
-	// Param can be "?token=abc123" or "token=abc123"
 	param := r.Param
 	if len(param) > 0 && param[0] == '?' {
 		param = param[1:]
 	}
-	// Simple parsing for token=value
-	const prefix = "token="
+	// Simple parsing for name=value
+	prefix := name + "="
 	for _, part := range splitParams(param) {
 		if len(part) > len(prefix) && part[:len(prefix)] == prefix {
 			return part[len(prefix):]
@@ -0,0 +1,21 @@
+package entity
+
+import "time"
+
+// Stream lifecycle events published to downstream services (notifications, feed ranking)
+const (
+	EventStreamStarted = "stream.started" // Stream went LIVE
+	EventStreamEnded   = "stream.ended"   // Stream went from LIVE to ENDED
+	EventCohostJoined  = "cohost.joined"  // A co-host took the stream LIVE, follows its stream.started
+)
+
+// StreamEvent is a row of the stream outbox
+type StreamEvent struct {
+	ID         int64     `db:"id"` // Outbox order
+	EventID    string    `db:"event_id"`
+	EventType  string    `db:"event_type"`
+	StreamID   string    `db:"stream_id"` // NanoID
+	Payload    []byte    `db:"payload"`   // JSON stream metadata at the time of the event
+	CreatedAt  time.Time `db:"created_at"`
+	RetryCount int       `db:"retry_count"`
+}
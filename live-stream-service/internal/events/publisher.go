@@ -0,0 +1,93 @@
+package events
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+	"time"
+
+	"live-service/internal/entity"
+)
+
+// Publisher delivers stream lifecycle events to downstream services
+type Publisher interface {
+	Publish(ctx context.Context, event entity.StreamEvent) error
+}
+
+// Envelope is the JSON body delivered for every event
+// Consumers deduplicate redeliveries by event_id: an event is sent again if the relay fails to record its delivery
+type Envelope struct {
+	EventID   string          `json:"event_id"`
+	EventType string          `json:"event_type"` // stream.started or stream.ended
+	StreamID  string          `json:"stream_id"`  // NanoID
+	Payload   json.RawMessage `json:"payload"`
+	CreatedAt time.Time       `json:"created_at"`
+}
+
+// NewEnvelope wraps an outbox event for delivery
+func NewEnvelope(event entity.StreamEvent) Envelope {
+	return Envelope{
+		EventID:   event.EventID,
+		EventType: event.EventType,
+		StreamID:  event.StreamID,
+		Payload:   json.RawMessage(event.Payload),
+		CreatedAt: event.CreatedAt,
+	}
+}
+
+// HTTPPublisher POSTs events as JSON to an endpoint, e.g. the ingest of the message bus bridge
+// The event type and id are repeated in headers so the receiver can route without parsing the body
+type HTTPPublisher struct {
+	url        string
+	httpClient *http.Client
+}
+
+// NewHTTPPublisher creates a publisher that posts every event to url
+func NewHTTPPublisher(url string, timeout time.Duration) *HTTPPublisher {
+	return &HTTPPublisher{
+		url: url,
+		httpClient: &http.Client{
+			Timeout: timeout,
+		},
+	}
+}
+
+// Publish sends one event; any non-2xx response is a failure and the event is retried
+func (p *HTTPPublisher) Publish(ctx context.Context, event entity.StreamEvent) error {
+	body, err := json.Marshal(NewEnvelope(event))
+	if err != nil {
+		return fmt.Errorf("failed to encode event: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, p.url, bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("X-Event-Type", event.EventType)
+	req.Header.Set("X-Event-ID", event.EventID)
+
+	resp, err := p.httpClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("event endpoint unreachable: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return fmt.Errorf("event endpoint returned status %d", resp.StatusCode)
+	}
+
+	return nil
+}
+
+// LogPublisher only logs events, for local development without a downstream endpoint
+type LogPublisher struct{}
+
+// Publish logs the event and always succeeds
+func (LogPublisher) Publish(ctx context.Context, event entity.StreamEvent) error {
+	log.Printf("[event_relay] %s for stream %s (event: %s): %s", event.EventType, event.StreamID, event.EventID, event.Payload)
+	return nil
+}
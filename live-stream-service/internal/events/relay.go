@@ -0,0 +1,133 @@
+package events
+
+import (
+	"context"
+	"log"
+	"time"
+
+	"live-service/internal/entity"
+	"live-service/internal/repository"
+)
+
+// RelayConfig tunes the event relay; zero values use the defaults
+type RelayConfig struct {
+	PollInterval time.Duration // How often the outbox is polled (default 1s)
+	BatchSize    int           // Events relayed per poll (default 100)
+	MaxRetries   int           // Failed deliveries before an event is given up (default 10)
+	BaseBackoff  time.Duration // Wait before the first retry, doubled on every failure (default 1s)
+	Retention    time.Duration // How long published events are kept (default 7 days)
+}
+
+const (
+	DefaultPollInterval = time.Second
+	DefaultBatchSize    = 100
+	DefaultMaxRetries   = 10
+	DefaultBaseBackoff  = time.Second
+	DefaultRetention    = 7 * 24 * time.Hour
+
+	// pruneInterval is how often published events past the retention are deleted
+	pruneInterval = time.Hour
+)
+
+// Relay moves stream events from the outbox to a Publisher
+// Delivery is at-least-once: an event whose delivery could not be recorded is sent again
+type Relay struct {
+	repo      repository.OutboxRepository
+	publisher Publisher
+	cfg       RelayConfig
+	lastPrune time.Time
+}
+
+// NewRelay creates a relay publishing the outbox of repo to publisher
+func NewRelay(repo repository.OutboxRepository, publisher Publisher, cfg RelayConfig) *Relay {
+	if cfg.PollInterval <= 0 {
+		cfg.PollInterval = DefaultPollInterval
+	}
+	if cfg.BatchSize <= 0 {
+		cfg.BatchSize = DefaultBatchSize
+	}
+	if cfg.MaxRetries <= 0 {
+		cfg.MaxRetries = DefaultMaxRetries
+	}
+	if cfg.BaseBackoff <= 0 {
+		cfg.BaseBackoff = DefaultBaseBackoff
+	}
+	if cfg.Retention <= 0 {
+		cfg.Retention = DefaultRetention
+	}
+	return &Relay{
+		repo:      repo,
+		publisher: publisher,
+		cfg:       cfg,
+	}
+}
+
+// Run relays until ctx is cancelled
+func (r *Relay) Run(ctx context.Context) {
+	ticker := time.NewTicker(r.cfg.PollInterval)
+	defer ticker.Stop()
+
+	log.Printf("[event_relay] started (interval: %s, batch: %d, max retries: %d)", r.cfg.PollInterval, r.cfg.BatchSize, r.cfg.MaxRetries)
+
+	for {
+		select {
+		case <-ctx.Done():
+			log.Printf("[event_relay] stopped")
+			return
+		case <-ticker.C:
+			r.Flush(ctx)
+			r.prune(ctx)
+		}
+	}
+}
+
+// Flush relays pending events until the outbox has no full batch left or a delivery fails
+func (r *Relay) Flush(ctx context.Context) {
+	for ctx.Err() == nil {
+		published, failed, err := r.repo.RelayPending(ctx, r.cfg.BatchSize, r.cfg.MaxRetries, r.cfg.BaseBackoff, r.publish)
+		if err != nil {
+			log.Printf("[event_relay] ERROR: failed to relay events: %v", err)
+			return
+		}
+		if published > 0 {
+			log.Printf("[event_relay] published %d event(s)", published)
+		}
+		// Failures wait for their backoff; a short batch means the outbox is drained
+		if failed > 0 || published < r.cfg.BatchSize {
+			return
+		}
+	}
+}
+
+func (r *Relay) publish(ctx context.Context, event entity.StreamEvent) error {
+	err := r.publisher.Publish(ctx, event)
+	if err == nil {
+		return nil
+	}
+
+	if event.RetryCount+1 >= r.cfg.MaxRetries {
+		log.Printf("[event_relay] ERROR: giving up on %s for stream %s (event: %s) after %d attempts: %v",
+			event.EventType, event.StreamID, event.EventID, event.RetryCount+1, err)
+	} else {
+		log.Printf("[event_relay] WARNING: failed to publish %s for stream %s (event: %s, attempt %d): %v",
+			event.EventType, event.StreamID, event.EventID, event.RetryCount+1, err)
+	}
+	return err
+}
+
+// prune deletes published events past the retention, at most once per pruneInterval
+func (r *Relay) prune(ctx context.Context) {
+	if time.Since(r.lastPrune) < pruneInterval {
+		return
+	}
+	r.lastPrune = time.Now()
+
+	pruned, err := r.repo.PrunePublished(ctx, time.Now().Add(-r.cfg.Retention))
+	if err != nil {
+		log.Printf("[event_relay] ERROR: failed to prune published events: %v", err)
+		return
+	}
+	if pruned > 0 {
+		log.Printf("[event_relay] pruned %d published event(s)", pruned)
+	}
+}
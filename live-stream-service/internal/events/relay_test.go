@@ -0,0 +1,139 @@
+package events
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"live-service/internal/entity"
+	"live-service/internal/repository"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func testEvent() entity.StreamEvent {
+	return entity.StreamEvent{
+		ID:        1,
+		EventID:   "2f7c1c1e-8f0a-4a57-9a43-0c7d0f4f5b11",
+		EventType: entity.EventStreamStarted,
+		StreamID:  "V1StGXR8_Z5jdHi6B-myT",
+		Payload:   []byte(`{"stream_id":"V1StGXR8_Z5jdHi6B-myT","title":"My Stream"}`),
+		CreatedAt: time.Date(2024, 1, 15, 10, 30, 0, 0, time.UTC),
+	}
+}
+
+func TestHTTPPublisher_PostsEnvelope(t *testing.T) {
+	var got Envelope
+	var eventType, eventID string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		eventType = r.Header.Get("X-Event-Type")
+		eventID = r.Header.Get("X-Event-ID")
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&got))
+		w.WriteHeader(http.StatusAccepted)
+	}))
+	defer server.Close()
+
+	err := NewHTTPPublisher(server.URL, time.Second).Publish(context.Background(), testEvent())
+
+	require.NoError(t, err)
+	assert.Equal(t, entity.EventStreamStarted, eventType)
+	assert.Equal(t, "2f7c1c1e-8f0a-4a57-9a43-0c7d0f4f5b11", eventID)
+	assert.Equal(t, "V1StGXR8_Z5jdHi6B-myT", got.StreamID)
+	assert.JSONEq(t, `{"stream_id":"V1StGXR8_Z5jdHi6B-myT","title":"My Stream"}`, string(got.Payload))
+}
+
+func TestHTTPPublisher_ErrorStatus(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer server.Close()
+
+	err := NewHTTPPublisher(server.URL, time.Second).Publish(context.Background(), testEvent())
+
+	assert.Error(t, err)
+}
+
+// stubOutbox hands out batches of a fixed size until remaining runs out
+type stubOutbox struct {
+	remaining int
+	calls     int
+	err       error
+}
+
+func (o *stubOutbox) RelayPending(ctx context.Context, limit, maxRetries int, baseBackoff time.Duration, publish repository.PublishFunc) (int, int, error) {
+	o.calls++
+	if o.err != nil {
+		return 0, 0, o.err
+	}
+	published, failed := 0, 0
+	for i := 0; i < limit && o.remaining > 0; i++ {
+		o.remaining--
+		if err := publish(ctx, testEvent()); err != nil {
+			failed++
+			continue
+		}
+		published++
+	}
+	return published, failed, nil
+}
+
+func (o *stubOutbox) PrunePublished(ctx context.Context, before time.Time) (int64, error) {
+	return 0, nil
+}
+
+type countingPublisher struct {
+	published int
+	err       error
+}
+
+func (p *countingPublisher) Publish(ctx context.Context, event entity.StreamEvent) error {
+	if p.err != nil {
+		return p.err
+	}
+	p.published++
+	return nil
+}
+
+func TestRelay_FlushDrainsFullBatches(t *testing.T) {
+	outbox := &stubOutbox{remaining: 25}
+	publisher := &countingPublisher{}
+	relay := NewRelay(outbox, publisher, RelayConfig{BatchSize: 10})
+
+	relay.Flush(context.Background())
+
+	assert.Equal(t, 25, publisher.published)
+	assert.Equal(t, 3, outbox.calls, "stops after the first short batch")
+}
+
+func TestRelay_FlushStopsOnFailure(t *testing.T) {
+	outbox := &stubOutbox{remaining: 25}
+	relay := NewRelay(outbox, &countingPublisher{err: errors.New("bus unavailable")}, RelayConfig{BatchSize: 10})
+
+	relay.Flush(context.Background())
+
+	assert.Equal(t, 1, outbox.calls, "failed events wait for their backoff")
+}
+
+func TestRelay_FlushStopsOnRepositoryError(t *testing.T) {
+	outbox := &stubOutbox{err: errors.New("connection refused")}
+	relay := NewRelay(outbox, &countingPublisher{}, RelayConfig{})
+
+	relay.Flush(context.Background())
+
+	assert.Equal(t, 1, outbox.calls)
+}
+
+func TestNewRelay_Defaults(t *testing.T) {
+	relay := NewRelay(&stubOutbox{}, LogPublisher{}, RelayConfig{})
+
+	assert.Equal(t, DefaultPollInterval, relay.cfg.PollInterval)
+	assert.Equal(t, DefaultBatchSize, relay.cfg.BatchSize)
+	assert.Equal(t, DefaultMaxRetries, relay.cfg.MaxRetries)
+	assert.Equal(t, DefaultBaseBackoff, relay.cfg.BaseBackoff)
+	assert.Equal(t, DefaultRetention, relay.cfg.Retention)
+}
@@ -2,7 +2,9 @@ package handler
 
 import (
 	"errors"
+	"fmt"
 	"net/http"
+	"strings"
 
 	"live-service/internal/entity"
 	"live-service/internal/repository"
@@ -58,6 +60,14 @@ func (h *LiveHandler) CreateStream(c *gin.Context) {
 	// Create stream
 	resp, err := h.service.CreateStream(c.Request.Context(), userID, &req)
 	if err != nil {
+		if errors.Is(err, service.ErrInvalidSchedule) {
+			c.JSON(http.StatusBadRequest, ErrorResponse{
+				Error:   "invalid_schedule",
+				Message: "scheduled_start_at must be in the future",
+			})
+			return
+		}
+
 		// Check for duplicate key error
 		if errors.Is(err, repository.ErrDuplicateKey) {
 			c.JSON(http.StatusConflict, ErrorResponse{
@@ -77,26 +87,536 @@ func (h *LiveHandler) CreateStream(c *gin.Context) {
 	c.JSON(http.StatusCreated, resp)
 }
 
+// RotateStreamKey handles POST /api/v1/live/:id/rotate-key
+// @Summary Rotate stream key
+// @Description Generates a new stream key for a stream that is not live. The old key is rejected by on_publish afterwards
+// @Tags live
+// @Accept json
+// @Produce json
+// @Param id path string true "Stream ID (NanoID)"
+// @Success 200 {object} entity.RotateStreamKeyResponse
+// @Failure 401 {object} ErrorResponse
+// @Failure 403 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Failure 409 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Router /api/v1/live/{id}/rotate-key [post]
+func (h *LiveHandler) RotateStreamKey(c *gin.Context) {
+	// Get user ID (UUID) from context (set by auth middleware)
+	userIDVal, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, ErrorResponse{
+			Error:   "unauthorized",
+			Message: "User authentication required",
+		})
+		return
+	}
+	userID := userIDVal.(string)
+
+	streamID := c.Param("id")
+	if streamID == "" {
+		c.JSON(http.StatusBadRequest, ErrorResponse{
+			Error:   "invalid_id",
+			Message: "Stream ID is required",
+		})
+		return
+	}
+
+	resp, err := h.service.RotateStreamKey(c.Request.Context(), streamID, userID)
+	if err != nil {
+		switch {
+		case errors.Is(err, repository.ErrNotFound):
+			c.JSON(http.StatusNotFound, ErrorResponse{
+				Error:   "not_found",
+				Message: "Stream not found",
+			})
+		case errors.Is(err, service.ErrNotStreamOwner):
+			c.JSON(http.StatusForbidden, ErrorResponse{
+				Error:   "forbidden",
+				Message: "Only the stream owner can rotate the stream key",
+			})
+		case errors.Is(err, service.ErrStreamIsLive):
+			c.JSON(http.StatusConflict, ErrorResponse{
+				Error:   "stream_live",
+				Message: "Cannot rotate the stream key while the stream is live",
+			})
+		default:
+			c.JSON(http.StatusInternalServerError, ErrorResponse{
+				Error:   "rotate_failed",
+				Message: "Failed to rotate stream key",
+			})
+		}
+		return
+	}
+
+	c.JSON(http.StatusOK, resp)
+}
+
 // ErrorResponse represents an API error response
 type ErrorResponse struct {
 	Error   string `json:"error"`
 	Message string `json:"message"`
 }
 
+// BanViewer handles POST /api/v1/live/:id/bans
+// @Summary Ban a viewer from a stream
+// @Description Owner only. Bans a viewer from the stream chat, disconnects their open chat connections and returns the updated ban list
+// @Tags live
+// @Accept json
+// @Produce json
+// @Param id path string true "Stream ID (NanoID)"
+// @Param request body entity.BanViewerRequest true "Viewer to ban"
+// @Success 200 {object} entity.ViewerBanListResponse
+// @Failure 400 {object} ErrorResponse
+// @Failure 401 {object} ErrorResponse
+// @Failure 403 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Router /api/v1/live/{id}/bans [post]
+func (h *LiveHandler) BanViewer(c *gin.Context) {
+	// Get user ID (UUID) from context (set by auth middleware)
+	userIDVal, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, ErrorResponse{
+			Error:   "unauthorized",
+			Message: "User authentication required",
+		})
+		return
+	}
+	userID := userIDVal.(string)
+
+	streamID := c.Param("id")
+	if streamID == "" {
+		c.JSON(http.StatusBadRequest, ErrorResponse{
+			Error:   "invalid_id",
+			Message: "Stream ID is required",
+		})
+		return
+	}
+
+	var req entity.BanViewerRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{
+			Error:   "invalid_request",
+			Message: err.Error(),
+		})
+		return
+	}
+	if !entity.IsValidUUID(req.UserID) {
+		c.JSON(http.StatusBadRequest, ErrorResponse{
+			Error:   "invalid_user_id",
+			Message: "user_id must be a valid UUID",
+		})
+		return
+	}
+
+	resp, err := h.service.BanViewer(c.Request.Context(), streamID, userID, &req)
+	if err != nil {
+		switch {
+		case errors.Is(err, repository.ErrNotFound):
+			c.JSON(http.StatusNotFound, ErrorResponse{
+				Error:   "not_found",
+				Message: "Stream not found",
+			})
+		case errors.Is(err, service.ErrNotStreamOwner):
+			c.JSON(http.StatusForbidden, ErrorResponse{
+				Error:   "forbidden",
+				Message: "Only the stream owner can ban viewers",
+			})
+		case errors.Is(err, service.ErrCannotBanOwner):
+			c.JSON(http.StatusBadRequest, ErrorResponse{
+				Error:   "invalid_user_id",
+				Message: "Cannot ban yourself from your own stream",
+			})
+		default:
+			c.JSON(http.StatusInternalServerError, ErrorResponse{
+				Error:   "ban_failed",
+				Message: "Failed to ban viewer",
+			})
+		}
+		return
+	}
+
+	c.JSON(http.StatusOK, resp)
+}
+
+// AddCohost handles POST /api/v1/live/:id/cohosts
+// @Summary Add a co-host to a stream
+// @Description Owner only. Lets another user publish to the stream with their own token (see GET /api/v1/live/{id}/cohost). Returns the updated co-host list
+// @Tags live
+// @Accept json
+// @Produce json
+// @Param id path string true "Stream ID (NanoID)"
+// @Param request body entity.AddCohostRequest true "Co-host to add"
+// @Success 200 {object} entity.CohostListResponse
+// @Failure 400 {object} ErrorResponse
+// @Failure 401 {object} ErrorResponse
+// @Failure 403 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Failure 409 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Router /api/v1/live/{id}/cohosts [post]
+func (h *LiveHandler) AddCohost(c *gin.Context) {
+	// Get user ID (UUID) from context (set by auth middleware)
+	userIDVal, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, ErrorResponse{
+			Error:   "unauthorized",
+			Message: "User authentication required",
+		})
+		return
+	}
+	userID := userIDVal.(string)
+
+	streamID := c.Param("id")
+	if streamID == "" {
+		c.JSON(http.StatusBadRequest, ErrorResponse{
+			Error:   "invalid_id",
+			Message: "Stream ID is required",
+		})
+		return
+	}
+
+	var req entity.AddCohostRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{
+			Error:   "invalid_request",
+			Message: err.Error(),
+		})
+		return
+	}
+	if !entity.IsValidUUID(req.UserID) {
+		c.JSON(http.StatusBadRequest, ErrorResponse{
+			Error:   "invalid_user_id",
+			Message: "user_id must be a valid UUID",
+		})
+		return
+	}
+
+	resp, err := h.service.AddCohost(c.Request.Context(), streamID, userID, &req)
+	if err != nil {
+		switch {
+		case errors.Is(err, repository.ErrNotFound):
+			c.JSON(http.StatusNotFound, ErrorResponse{
+				Error:   "not_found",
+				Message: "Stream not found",
+			})
+		case errors.Is(err, service.ErrNotStreamOwner):
+			c.JSON(http.StatusForbidden, ErrorResponse{
+				Error:   "forbidden",
+				Message: "Only the stream owner can add co-hosts",
+			})
+		case errors.Is(err, service.ErrCannotCohostOwner):
+			c.JSON(http.StatusBadRequest, ErrorResponse{
+				Error:   "invalid_user_id",
+				Message: "Cannot add yourself as a co-host",
+			})
+		case errors.Is(err, service.ErrStreamAlreadyEnded), errors.Is(err, service.ErrStreamExpired):
+			c.JSON(http.StatusConflict, ErrorResponse{
+				Error:   "stream_over",
+				Message: "Stream can no longer be published",
+			})
+		case errors.Is(err, service.ErrTooManyCohosts):
+			c.JSON(http.StatusConflict, ErrorResponse{
+				Error:   "too_many_cohosts",
+				Message: fmt.Sprintf("A stream can have at most %d co-hosts", service.MaxCohosts),
+			})
+		case errors.Is(err, service.ErrCohostBanned):
+			c.JSON(http.StatusConflict, ErrorResponse{
+				Error:   "cohost_banned",
+				Message: "User is banned from this stream",
+			})
+		default:
+			c.JSON(http.StatusInternalServerError, ErrorResponse{
+				Error:   "cohost_failed",
+				Message: "Failed to add co-host",
+			})
+		}
+		return
+	}
+
+	c.JSON(http.StatusOK, resp)
+}
+
+// RemoveCohost handles DELETE /api/v1/live/:id/cohosts/:user_id
+// @Summary Remove a co-host from a stream
+// @Description Owner only. Revokes the co-host's token; an ongoing publish continues until it stops. Returns the updated co-host list
+// @Tags live
+// @Produce json
+// @Param id path string true "Stream ID (NanoID)"
+// @Param user_id path string true "Co-host user ID (UUID)"
+// @Success 200 {object} entity.CohostListResponse
+// @Failure 401 {object} ErrorResponse
+// @Failure 403 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Router /api/v1/live/{id}/cohosts/{user_id} [delete]
+func (h *LiveHandler) RemoveCohost(c *gin.Context) {
+	// Get user ID (UUID) from context (set by auth middleware)
+	userIDVal, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, ErrorResponse{
+			Error:   "unauthorized",
+			Message: "User authentication required",
+		})
+		return
+	}
+	userID := userIDVal.(string)
+
+	streamID := c.Param("id")
+	if streamID == "" {
+		c.JSON(http.StatusBadRequest, ErrorResponse{
+			Error:   "invalid_id",
+			Message: "Stream ID is required",
+		})
+		return
+	}
+
+	resp, err := h.service.RemoveCohost(c.Request.Context(), streamID, userID, c.Param("user_id"))
+	if err != nil {
+		switch {
+		case errors.Is(err, repository.ErrNotFound):
+			c.JSON(http.StatusNotFound, ErrorResponse{
+				Error:   "not_found",
+				Message: "Stream not found",
+			})
+		case errors.Is(err, service.ErrCohostNotFound):
+			c.JSON(http.StatusNotFound, ErrorResponse{
+				Error:   "cohost_not_found",
+				Message: "User is not a co-host of this stream",
+			})
+		case errors.Is(err, service.ErrNotStreamOwner):
+			c.JSON(http.StatusForbidden, ErrorResponse{
+				Error:   "forbidden",
+				Message: "Only the stream owner can remove co-hosts",
+			})
+		default:
+			c.JSON(http.StatusInternalServerError, ErrorResponse{
+				Error:   "cohost_failed",
+				Message: "Failed to remove co-host",
+			})
+		}
+		return
+	}
+
+	c.JSON(http.StatusOK, resp)
+}
+
+// GetCohostCredentials handles GET /api/v1/live/:id/cohost
+// @Summary Get co-host publish URLs
+// @Description Co-host only. Returns the caller's co-host token and the RTMP/WebRTC/WHIP URLs to publish with it
+// @Tags live
+// @Produce json
+// @Param id path string true "Stream ID (NanoID)"
+// @Success 200 {object} entity.CohostCredentialsResponse
+// @Failure 401 {object} ErrorResponse
+// @Failure 403 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Router /api/v1/live/{id}/cohost [get]
+func (h *LiveHandler) GetCohostCredentials(c *gin.Context) {
+	// Get user ID (UUID) from context (set by auth middleware)
+	userIDVal, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, ErrorResponse{
+			Error:   "unauthorized",
+			Message: "User authentication required",
+		})
+		return
+	}
+	userID := userIDVal.(string)
+
+	streamID := c.Param("id")
+	if streamID == "" {
+		c.JSON(http.StatusBadRequest, ErrorResponse{
+			Error:   "invalid_id",
+			Message: "Stream ID is required",
+		})
+		return
+	}
+
+	resp, err := h.service.GetCohostCredentials(c.Request.Context(), streamID, userID)
+	if err != nil {
+		switch {
+		case errors.Is(err, repository.ErrNotFound):
+			c.JSON(http.StatusNotFound, ErrorResponse{
+				Error:   "not_found",
+				Message: "Stream not found",
+			})
+		case errors.Is(err, service.ErrNotCohost):
+			c.JSON(http.StatusForbidden, ErrorResponse{
+				Error:   "forbidden",
+				Message: "Not a co-host of this stream",
+			})
+		default:
+			c.JSON(http.StatusInternalServerError, ErrorResponse{
+				Error:   "cohost_failed",
+				Message: "Failed to retrieve co-host credentials",
+			})
+		}
+		return
+	}
+
+	c.JSON(http.StatusOK, resp)
+}
+
+// GetStreamViewers handles GET /api/v1/live/:id/viewers/list
+// @Summary List who is watching a stream
+// @Description Owner only. Cursor-paginated list of signed-in viewers ordered by user_id. Anonymous viewers are only part of viewer_count
+// @Tags live
+// @Accept json
+// @Produce json
+// @Param id path string true "Stream ID (NanoID)"
+// @Param limit query int false "Items per page" default(20)
+// @Param cursor query string false "Opaque cursor from previous response's next_cursor"
+// @Success 200 {object} entity.StreamViewersResponse
+// @Failure 400 {object} ErrorResponse
+// @Failure 401 {object} ErrorResponse
+// @Failure 403 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Failure 503 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Router /api/v1/live/{id}/viewers/list [get]
+func (h *LiveHandler) GetStreamViewers(c *gin.Context) {
+	// Get user ID (UUID) from context (set by auth middleware)
+	userIDVal, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, ErrorResponse{
+			Error:   "unauthorized",
+			Message: "User authentication required",
+		})
+		return
+	}
+	userID := userIDVal.(string)
+
+	streamID := c.Param("id")
+	if streamID == "" {
+		c.JSON(http.StatusBadRequest, ErrorResponse{
+			Error:   "invalid_id",
+			Message: "Stream ID is required",
+		})
+		return
+	}
+
+	params := entity.ListStreamViewersParams{Limit: 20}
+	if err := c.ShouldBindQuery(&params); err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{
+			Error:   "invalid_params",
+			Message: "Invalid pagination parameters: " + err.Error(),
+		})
+		return
+	}
+
+	resp, err := h.service.GetStreamViewers(c.Request.Context(), streamID, userID, params)
+	if err != nil {
+		switch {
+		case errors.Is(err, entity.ErrInvalidCursor):
+			c.JSON(http.StatusBadRequest, ErrorResponse{
+				Error:   "invalid_cursor",
+				Message: "Invalid cursor",
+			})
+		case errors.Is(err, repository.ErrNotFound):
+			c.JSON(http.StatusNotFound, ErrorResponse{
+				Error:   "not_found",
+				Message: "Stream not found",
+			})
+		case errors.Is(err, service.ErrNotStreamOwner):
+			c.JSON(http.StatusForbidden, ErrorResponse{
+				Error:   "forbidden",
+				Message: "Only the stream owner can list viewers",
+			})
+		case errors.Is(err, service.ErrPresenceDisabled):
+			c.JSON(http.StatusServiceUnavailable, ErrorResponse{
+				Error:   "presence_disabled",
+				Message: "Viewer list is not available",
+			})
+		default:
+			c.JSON(http.StatusInternalServerError, ErrorResponse{
+				Error:   "list_failed",
+				Message: "Failed to retrieve viewers",
+			})
+		}
+		return
+	}
+
+	c.JSON(http.StatusOK, resp)
+}
+
+// HeartbeatViewer handles POST /api/v1/live/:id/viewers/heartbeat
+// @Summary Keep a viewer on the viewer list
+// @Description Players call it periodically (more often than PRESENCE_TTL) while playing with ?user_id=. Viewers that stop sending it drop off the list even if on_stop is missed
+// @Tags live
+// @Produce json
+// @Param id path string true "Stream ID (NanoID)"
+// @Success 204
+// @Failure 401 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Failure 503 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Router /api/v1/live/{id}/viewers/heartbeat [post]
+func (h *LiveHandler) HeartbeatViewer(c *gin.Context) {
+	// Get user ID (UUID) from context (set by auth middleware)
+	userIDVal, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, ErrorResponse{
+			Error:   "unauthorized",
+			Message: "User authentication required",
+		})
+		return
+	}
+	userID := userIDVal.(string)
+
+	streamID := c.Param("id")
+	if streamID == "" {
+		c.JSON(http.StatusBadRequest, ErrorResponse{
+			Error:   "invalid_id",
+			Message: "Stream ID is required",
+		})
+		return
+	}
+
+	if err := h.service.HeartbeatViewer(c.Request.Context(), streamID, userID); err != nil {
+		switch {
+		case errors.Is(err, service.ErrNotWatching):
+			// Expired or never started playing: the player has to restart playback to be listed again
+			c.JSON(http.StatusNotFound, ErrorResponse{
+				Error:   "not_watching",
+				Message: "Not watching this stream",
+			})
+		case errors.Is(err, service.ErrPresenceDisabled):
+			c.JSON(http.StatusServiceUnavailable, ErrorResponse{
+				Error:   "presence_disabled",
+				Message: "Viewer list is not available",
+			})
+		default:
+			c.JSON(http.StatusInternalServerError, ErrorResponse{
+				Error:   "heartbeat_failed",
+				Message: "Failed to refresh viewer",
+			})
+		}
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
 // ListStreams handles GET /api/v1/live/feed
 // @Summary List live streams
-// @Description Get paginated list of currently live streams
+// @Description Get cursor-paginated list of streams. Defaults to LIVE streams ordered by viewer count
 // @Tags live
 // @Accept json
 // @Produce json
-// @Param page query int false "Page number" default(1)
 // @Param limit query int false "Items per page" default(20)
+// @Param cursor query string false "Opaque cursor from previous response's next_cursor"
+// @Param status query string false "Status filter (LIVE, ENDED, IDLE, SCHEDULED, EXPIRED)" default(LIVE)
+// @Param sort query string false "Sort order (viewers, recent, scheduled). Defaults to scheduled for status=SCHEDULED, otherwise viewers"
 // @Success 200 {object} entity.ListStreamsResponse
+// @Failure 400 {object} ErrorResponse
 // @Failure 500 {object} ErrorResponse
 // @Router /api/v1/live/feed [get]
 func (h *LiveHandler) ListStreams(c *gin.Context) {
-	// Parse pagination params
-	params := entity.DefaultPagination()
+	// Parse pagination and filter params
+	params := entity.DefaultListStreamsParams()
 	if err := c.ShouldBindQuery(&params); err != nil {
 		c.JSON(http.StatusBadRequest, ErrorResponse{
 			Error:   "invalid_params",
@@ -106,15 +626,37 @@ func (h *LiveHandler) ListStreams(c *gin.Context) {
 	}
 
 	// Ensure valid defaults
-	if params.Page < 1 {
-		params.Page = 1
-	}
 	if params.Limit < 1 || params.Limit > 100 {
 		params.Limit = 20
 	}
+	if params.Status == "" {
+		params.Status = entity.StatusLive
+	}
+	params.Status = entity.LiveSessionStatus(strings.ToUpper(string(params.Status)))
+	if !params.Status.IsValid() {
+		c.JSON(http.StatusBadRequest, ErrorResponse{
+			Error:   "invalid_params",
+			Message: "status must be one of LIVE, ENDED, IDLE, SCHEDULED, EXPIRED",
+		})
+		return
+	}
+	if !params.ResolveSort() {
+		c.JSON(http.StatusBadRequest, ErrorResponse{
+			Error:   "invalid_params",
+			Message: "sort must be one of viewers, recent, scheduled (scheduled requires status=SCHEDULED)",
+		})
+		return
+	}
 
 	resp, err := h.service.ListStreams(c.Request.Context(), params)
 	if err != nil {
+		if errors.Is(err, entity.ErrInvalidCursor) {
+			c.JSON(http.StatusBadRequest, ErrorResponse{
+				Error:   "invalid_cursor",
+				Message: "Invalid cursor",
+			})
+			return
+		}
 		c.JSON(http.StatusInternalServerError, ErrorResponse{
 			Error:   "list_failed",
 			Message: "Failed to retrieve streams",
@@ -125,6 +667,77 @@ func (h *LiveHandler) ListStreams(c *gin.Context) {
 	c.JSON(http.StatusOK, resp)
 }
 
+// SearchStreams handles GET /api/v1/live/search
+// @Summary Search streams
+// @Description Search public (LIVE, SCHEDULED, ENDED) stream titles. Same response shape and pagination as the feed
+// @Tags live
+// @Accept json
+// @Produce json
+// @Param q query string true "Search text (max 100 characters)"
+// @Param limit query int false "Items per page" default(20)
+// @Param cursor query string false "Opaque cursor from previous response's next_cursor"
+// @Param status query string false "Status filter (LIVE, SCHEDULED, ENDED). Defaults to all three"
+// @Param sort query string false "Sort order (viewers, recent, scheduled). scheduled requires status=SCHEDULED" default(viewers)
+// @Success 200 {object} entity.ListStreamsResponse
+// @Failure 400 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Router /api/v1/live/search [get]
+func (h *LiveHandler) SearchStreams(c *gin.Context) {
+	params := entity.SearchStreamsParams{ListStreamsParams: entity.ListStreamsParams{Limit: 20}}
+	if err := c.ShouldBindQuery(&params); err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{
+			Error:   "invalid_params",
+			Message: "Invalid search parameters: " + err.Error(),
+		})
+		return
+	}
+
+	params.Query = strings.TrimSpace(params.Query)
+	if params.Query == "" {
+		c.JSON(http.StatusBadRequest, ErrorResponse{
+			Error:   "invalid_params",
+			Message: "q is required",
+		})
+		return
+	}
+	if params.Limit < 1 || params.Limit > 100 {
+		params.Limit = 20
+	}
+	params.Status = entity.LiveSessionStatus(strings.ToUpper(string(params.Status)))
+	if params.Status != "" && !params.Status.IsPublic() {
+		c.JSON(http.StatusBadRequest, ErrorResponse{
+			Error:   "invalid_params",
+			Message: "status must be one of LIVE, SCHEDULED, ENDED",
+		})
+		return
+	}
+	if !params.ResolveSort() {
+		c.JSON(http.StatusBadRequest, ErrorResponse{
+			Error:   "invalid_params",
+			Message: "sort must be one of viewers, recent, scheduled (scheduled requires status=SCHEDULED)",
+		})
+		return
+	}
+
+	resp, err := h.service.SearchStreams(c.Request.Context(), params)
+	if err != nil {
+		if errors.Is(err, entity.ErrInvalidCursor) {
+			c.JSON(http.StatusBadRequest, ErrorResponse{
+				Error:   "invalid_cursor",
+				Message: "Invalid cursor",
+			})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, ErrorResponse{
+			Error:   "search_failed",
+			Message: "Failed to search streams",
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, resp)
+}
+
 // GetStreamDetail handles GET /api/v1/live/:id
 // @Summary Get stream details
 // @Description Get detailed information about a specific stream
@@ -175,7 +788,7 @@ func (h *LiveHandler) GetStreamDetail(c *gin.Context) {
 
 // GetWebRTCInfo handles GET /api/v1/live/:id/webrtc
 // @Summary Get WebRTC connection info
-// @Description Get WebRTC URLs and ICE servers for publishing or playing a stream
+// @Description Get WebRTC/WHEP playback URLs and ICE servers for a stream. Owners also get WHIP and WebRTC publish URLs while the stream can still be published
 // @Tags live
 // @Accept json
 // @Produce json
@@ -262,7 +875,8 @@ func (h *LiveHandler) OnPublish(c *gin.Context) {
 	// Validate stream and update status
 	// If token exists, use new auth flow (stream_id + token)
 	// Otherwise fallback to old flow (stream_key only)
-	if err := h.service.HandleOnPublish(ctx, streamID, token); err != nil {
+	// client_id lets the service accept SRS retries of the same callback
+	if err := h.service.HandleOnPublish(ctx, streamID, token, req.ClientID); err != nil {
 		c.JSON(http.StatusForbidden, entity.SRSCallbackResponse{Code: 1})
 		return
 	}
@@ -312,3 +926,131 @@ func (h *LiveHandler) OnUnpublish(c *gin.Context) {
 	// Always return 200 for unpublish - idempotent operation
 	c.JSON(http.StatusOK, entity.SRSCallbackResponse{Code: 0})
 }
+
+// OnPlay handles SRS callback when a viewer starts playing a stream
+// POST /api/v1/callbacks/on_play
+// @Summary SRS on_play webhook
+// @Description Increments the viewer count of a live stream (deduplicated by client_id) and lists the viewer given by ?user_id=
+// @Tags callbacks
+// @Accept json
+// @Produce json
+// @Param request body entity.SRSCallbackRequest true "SRS callback request"
+// @Success 200 {object} entity.SRSCallbackResponse
+// @Router /api/v1/callbacks/on_play [post]
+func (h *LiveHandler) OnPlay(c *gin.Context) {
+	var req entity.SRSCallbackRequest
+
+	// SRS sends data as form-urlencoded or JSON
+	if err := c.ShouldBind(&req); err != nil {
+		// Never block playback because of viewer tracking
+		c.JSON(http.StatusOK, entity.SRSCallbackResponse{Code: 0})
+		return
+	}
+
+	streamID := req.GetStreamID()
+	if streamID == "" {
+		c.JSON(http.StatusOK, entity.SRSCallbackResponse{Code: 0})
+		return
+	}
+
+	// Errors are logged in service layer but we always allow playback
+	_ = h.service.HandleOnPlay(c.Request.Context(), streamID, req.ClientID, req.GetViewerID())
+
+	c.JSON(http.StatusOK, entity.SRSCallbackResponse{Code: 0})
+}
+
+// OnHLS handles SRS callback when an HLS segment is written
+// POST /api/v1/callbacks/on_hls
+// @Summary SRS on_hls webhook
+// @Description Records the stream thumbnail on the first HLS segment after go-live
+// @Tags callbacks
+// @Accept json
+// @Produce json
+// @Param request body entity.SRSCallbackRequest true "SRS callback request"
+// @Success 200 {object} entity.SRSCallbackResponse
+// @Router /api/v1/callbacks/on_hls [post]
+func (h *LiveHandler) OnHLS(c *gin.Context) {
+	var req entity.SRSCallbackRequest
+
+	// SRS sends data as form-urlencoded or JSON
+	if err := c.ShouldBind(&req); err != nil {
+		// Never disturb segment delivery because of thumbnails
+		c.JSON(http.StatusOK, entity.SRSCallbackResponse{Code: 0})
+		return
+	}
+
+	streamID := req.GetStreamID()
+	if streamID == "" {
+		c.JSON(http.StatusOK, entity.SRSCallbackResponse{Code: 0})
+		return
+	}
+
+	// Errors are logged in service layer; a missing thumbnail falls back to the placeholder
+	_ = h.service.HandleOnHLS(c.Request.Context(), streamID)
+
+	c.JSON(http.StatusOK, entity.SRSCallbackResponse{Code: 0})
+}
+
+// OnDVR handles SRS callback when a DVR file is closed
+// POST /api/v1/callbacks/on_dvr
+// @Summary SRS on_dvr webhook
+// @Description Stores a recorded file of a stream for VOD playback once the stream has ENDED
+// @Tags callbacks
+// @Accept json
+// @Produce json
+// @Param request body entity.SRSCallbackRequest true "SRS callback request"
+// @Success 200 {object} entity.SRSCallbackResponse
+// @Router /api/v1/callbacks/on_dvr [post]
+func (h *LiveHandler) OnDVR(c *gin.Context) {
+	var req entity.SRSCallbackRequest
+
+	// SRS sends data as form-urlencoded or JSON
+	if err := c.ShouldBind(&req); err != nil {
+		// The file is already written; a failed callback only loses its VOD entry
+		c.JSON(http.StatusOK, entity.SRSCallbackResponse{Code: 0})
+		return
+	}
+
+	streamID := req.GetStreamID()
+	if streamID == "" {
+		c.JSON(http.StatusOK, entity.SRSCallbackResponse{Code: 0})
+		return
+	}
+
+	// Errors are logged in service layer but we always return success
+	_ = h.service.HandleOnDVR(c.Request.Context(), streamID, req.Cwd, req.File)
+
+	c.JSON(http.StatusOK, entity.SRSCallbackResponse{Code: 0})
+}
+
+// OnStop handles SRS callback when a viewer stops playing a stream
+// POST /api/v1/callbacks/on_stop
+// @Summary SRS on_stop webhook
+// @Description Decrements the viewer count of a stream (never below zero) and unlists the viewer given by ?user_id=
+// @Tags callbacks
+// @Accept json
+// @Produce json
+// @Param request body entity.SRSCallbackRequest true "SRS callback request"
+// @Success 200 {object} entity.SRSCallbackResponse
+// @Router /api/v1/callbacks/on_stop [post]
+func (h *LiveHandler) OnStop(c *gin.Context) {
+	var req entity.SRSCallbackRequest
+
+	// SRS sends data as form-urlencoded or JSON
+	if err := c.ShouldBind(&req); err != nil {
+		c.JSON(http.StatusOK, entity.SRSCallbackResponse{Code: 0})
+		return
+	}
+
+	streamID := req.GetStreamID()
+	if streamID == "" {
+		c.JSON(http.StatusOK, entity.SRSCallbackResponse{Code: 0})
+		return
+	}
+
+	// Errors are logged in service layer but we always return success
+	_ = h.service.HandleOnStop(c.Request.Context(), streamID, req.ClientID, req.GetViewerID())
+
+	// Always return 200 for stop - viewer already disconnected
+	c.JSON(http.StatusOK, entity.SRSCallbackResponse{Code: 0})
+}
@@ -68,7 +68,7 @@ func setupTestEnvironment() error {
 	}
 
 	testRepo = repository.NewLiveRepository(testDB)
-	testService = service.NewLiveService(testRepo, testConfig)
+	testService = service.NewLiveService(testRepo, testConfig, nil, nil, nil, nil, nil)
 	testHandler = handler.NewLiveHandler(testService)
 
 	testRouter = setupRouter()
@@ -87,12 +87,15 @@ func setupRouter() *gin.Engine {
 			live.POST("/create", middleware.Auth(), testHandler.CreateStream)
 			live.GET("/feed", testHandler.ListStreams)
 			live.GET("/:id", testHandler.GetStreamDetail)
+			live.POST("/:id/rotate-key", middleware.Auth(), testHandler.RotateStreamKey)
 		}
 
 		callbacks := v1.Group("/callbacks")
 		{
 			callbacks.POST("/on_publish", testHandler.OnPublish)
 			callbacks.POST("/on_unpublish", testHandler.OnUnpublish)
+			callbacks.POST("/on_play", testHandler.OnPlay)
+			callbacks.POST("/on_stop", testHandler.OnStop)
 		}
 	}
 
@@ -149,9 +152,14 @@ func getStreamStatus(t *testing.T, streamID string) entity.LiveSessionStatus {
 
 // simulateOnPublish simulates SRS on_publish webhook
 func simulateOnPublish(t *testing.T, streamID string, token string) int {
+	return simulateOnPublishFromClient(t, streamID, token, "test-client-123")
+}
+
+// simulateOnPublishFromClient simulates SRS on_publish webhook from a specific SRS client
+func simulateOnPublishFromClient(t *testing.T, streamID string, token string, clientID string) int {
 	reqBody := map[string]string{
 		"action":    "on_publish",
-		"client_id": "test-client-123",
+		"client_id": clientID,
 		"ip":        "127.0.0.1",
 		"vhost":     "__defaultVhost__",
 		"app":       "live",
@@ -190,6 +198,37 @@ func simulateOnUnpublish(t *testing.T, streamID string) int {
 	return w.Code
 }
 
+// simulatePlayback simulates SRS on_play / on_stop webhooks for a viewer
+func simulatePlayback(t *testing.T, action string, streamID string, clientID string) int {
+	reqBody := map[string]string{
+		"action":    action,
+		"client_id": clientID,
+		"ip":        "127.0.0.1",
+		"vhost":     "__defaultVhost__",
+		"app":       "live",
+		"stream":    streamID,
+	}
+	body, _ := json.Marshal(reqBody)
+
+	req := httptest.NewRequest(http.MethodPost, "/api/v1/callbacks/"+action, bytes.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+
+	w := httptest.NewRecorder()
+	testRouter.ServeHTTP(w, req)
+
+	return w.Code
+}
+
+// getViewerCount retrieves current viewer_count from database
+func getViewerCount(t *testing.T, streamID string) int {
+	var viewerCount int
+	err := testDB.Get(&viewerCount, "SELECT viewer_count FROM live_sessions WHERE id = $1", streamID)
+	if err != nil {
+		t.Fatalf("Failed to get viewer_count: %v", err)
+	}
+	return viewerCount
+}
+
 // ===========================================
 // Task 8: Integration Test - RTMP Publish
 // ===========================================
@@ -253,13 +292,34 @@ func TestOnPublish_DuplicatePublish(t *testing.T) {
 		t.Fatalf("First on_publish failed: %d", code)
 	}
 
-	// Second publish - should fail (already LIVE)
-	code = simulateOnPublish(t, stream.ID, stream.StreamKey)
+	// Second publish from a different client - should fail (already LIVE)
+	code = simulateOnPublishFromClient(t, stream.ID, stream.StreamKey, "test-client-456")
 	if code != http.StatusForbidden {
 		t.Errorf("Expected 403 for duplicate publish, got %d", code)
 	}
 }
 
+func TestOnPublish_RetrySameClientIdempotent(t *testing.T) {
+	userID := "550e8400-e29b-41d4-a716-446655440016"
+	stream := createTestStream(t, userID, "Test Stream - Publish Retry")
+	defer cleanupTestStream(t, stream.ID)
+
+	code := simulateOnPublish(t, stream.ID, stream.StreamKey)
+	if code != http.StatusOK {
+		t.Fatalf("First on_publish failed: %d", code)
+	}
+
+	// SRS retries the same callback after a timeout - must be accepted as a no-op
+	code = simulateOnPublish(t, stream.ID, stream.StreamKey)
+	if code != http.StatusOK {
+		t.Errorf("Expected 200 for retried on_publish, got %d", code)
+	}
+
+	if status := getStreamStatus(t, stream.ID); status != entity.StatusLive {
+		t.Errorf("Expected status LIVE, got %s", status)
+	}
+}
+
 // ===========================================
 // Task 9: Integration Test - Stop Stream
 // ===========================================
@@ -617,3 +677,279 @@ func TestWebhookIdempotency(t *testing.T) {
 		t.Errorf("Expected status ENDED after multiple unpublish, got %s", status)
 	}
 }
+
+func TestOnPlayOnStop_ViewerCount(t *testing.T) {
+	userID := "550e8400-e29b-41d4-a716-44665544000e"
+	stream := createTestStream(t, userID, "Test Stream - Viewer Count")
+	defer cleanupTestStream(t, stream.ID)
+
+	if code := simulateOnPublish(t, stream.ID, stream.StreamKey); code != http.StatusOK {
+		t.Fatalf("Publish failed: %d", code)
+	}
+
+	// Two viewers join, one of them with a duplicate on_play
+	simulatePlayback(t, "on_play", stream.ID, "viewer-1")
+	simulatePlayback(t, "on_play", stream.ID, "viewer-2")
+	simulatePlayback(t, "on_play", stream.ID, "viewer-2")
+	if count := getViewerCount(t, stream.ID); count != 2 {
+		t.Errorf("Expected viewer_count 2, got %d", count)
+	}
+
+	// Out-of-order / duplicate on_stop must not push count below the real number of viewers
+	simulatePlayback(t, "on_stop", stream.ID, "viewer-unknown")
+	simulatePlayback(t, "on_stop", stream.ID, "viewer-1")
+	simulatePlayback(t, "on_stop", stream.ID, "viewer-1")
+	if count := getViewerCount(t, stream.ID); count != 1 {
+		t.Errorf("Expected viewer_count 1, got %d", count)
+	}
+
+	// Count is exposed via stream detail
+	req := httptest.NewRequest(http.MethodGet, "/api/v1/live/"+stream.ID, nil)
+	w := httptest.NewRecorder()
+	testRouter.ServeHTTP(w, req)
+
+	var detail entity.StreamDetailResponse
+	if err := json.Unmarshal(w.Body.Bytes(), &detail); err != nil {
+		t.Fatalf("Failed to parse response: %v", err)
+	}
+	if detail.ViewerCount != 1 {
+		t.Errorf("Expected detail viewer_count 1, got %d", detail.ViewerCount)
+	}
+
+	simulatePlayback(t, "on_stop", stream.ID, "viewer-2")
+	simulatePlayback(t, "on_stop", stream.ID, "viewer-2")
+	if count := getViewerCount(t, stream.ID); count != 0 {
+		t.Errorf("Expected viewer_count 0, got %d", count)
+	}
+}
+
+func TestOnPlay_StreamNotLive(t *testing.T) {
+	userID := "550e8400-e29b-41d4-a716-44665544000f"
+	stream := createTestStream(t, userID, "Test Stream - Play Not Live")
+	defer cleanupTestStream(t, stream.ID)
+
+	// on_play always returns 200 but doesn't count viewers for IDLE streams
+	if code := simulatePlayback(t, "on_play", stream.ID, "viewer-1"); code != http.StatusOK {
+		t.Errorf("Expected 200, got %d", code)
+	}
+	if count := getViewerCount(t, stream.ID); count != 0 {
+		t.Errorf("Expected viewer_count 0, got %d", count)
+	}
+}
+
+func TestGetStreamDetail_HLSUrlOnlyWhenLive(t *testing.T) {
+	userID := "550e8400-e29b-41d4-a716-446655440010"
+	stream := createTestStream(t, userID, "Test Stream - HLS URL")
+	defer cleanupTestStream(t, stream.ID)
+
+	getDetail := func() entity.StreamDetailResponse {
+		req := httptest.NewRequest(http.MethodGet, "/api/v1/live/"+stream.ID, nil)
+		w := httptest.NewRecorder()
+		testRouter.ServeHTTP(w, req)
+
+		var detail entity.StreamDetailResponse
+		if err := json.Unmarshal(w.Body.Bytes(), &detail); err != nil {
+			t.Fatalf("Failed to parse response: %v", err)
+		}
+		return detail
+	}
+
+	// IDLE: no playlist yet
+	if detail := getDetail(); detail.HLSUrl != nil {
+		t.Errorf("Expected no hls_url for IDLE stream, got %s", *detail.HLSUrl)
+	}
+
+	// LIVE: playlist URL derived from config
+	if code := simulateOnPublish(t, stream.ID, stream.StreamKey); code != http.StatusOK {
+		t.Fatalf("Publish failed: %d", code)
+	}
+	detail := getDetail()
+	if detail.HLSUrl == nil {
+		t.Fatal("Expected hls_url for LIVE stream")
+	}
+	if *detail.HLSUrl != testConfig.GetHLSURL(stream.ID) {
+		t.Errorf("Expected hls_url %s, got %s", testConfig.GetHLSURL(stream.ID), *detail.HLSUrl)
+	}
+
+	// ENDED: playlist no longer served
+	simulateOnUnpublish(t, stream.ID)
+	if detail := getDetail(); detail.HLSUrl != nil {
+		t.Errorf("Expected no hls_url for ENDED stream, got %s", *detail.HLSUrl)
+	}
+}
+
+func TestListStreams_CursorAndFilterValidation(t *testing.T) {
+	tests := []struct {
+		name  string
+		query string
+		code  int
+	}{
+		{name: "defaults", query: "", code: http.StatusOK},
+		{name: "status filter lowercase", query: "?status=ended&sort=recent", code: http.StatusOK},
+		{name: "invalid status", query: "?status=PAUSED", code: http.StatusBadRequest},
+		{name: "invalid sort", query: "?sort=popular", code: http.StatusBadRequest},
+		{name: "invalid cursor", query: "?cursor=not-a-cursor", code: http.StatusBadRequest},
+		{name: "limit out of range", query: "?limit=500", code: http.StatusBadRequest},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, "/api/v1/live/feed"+tt.query, nil)
+			w := httptest.NewRecorder()
+			testRouter.ServeHTTP(w, req)
+
+			if w.Code != tt.code {
+				t.Errorf("Expected %d, got %d - %s", tt.code, w.Code, w.Body.String())
+			}
+		})
+	}
+}
+
+func TestRotateStreamKey_OldKeyRejected(t *testing.T) {
+	userID := "550e8400-e29b-41d4-a716-446655440011"
+	stream := createTestStream(t, userID, "Test Stream - Rotate Key")
+	defer cleanupTestStream(t, stream.ID)
+
+	rotate := func(asUser string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodPost, "/api/v1/live/"+stream.ID+"/rotate-key", nil)
+		req.Header.Set("X-User-ID", asUser)
+		w := httptest.NewRecorder()
+		testRouter.ServeHTTP(w, req)
+		return w
+	}
+
+	// Only the owner may rotate
+	if w := rotate("550e8400-e29b-41d4-a716-446655440099"); w.Code != http.StatusForbidden {
+		t.Errorf("Expected 403 for non-owner, got %d", w.Code)
+	}
+
+	w := rotate(userID)
+	if w.Code != http.StatusOK {
+		t.Fatalf("Expected 200, got %d - %s", w.Code, w.Body.String())
+	}
+	var resp entity.RotateStreamKeyResponse
+	if err := json.Unmarshal(w.Body.Bytes(), &resp); err != nil {
+		t.Fatalf("Failed to parse response: %v", err)
+	}
+	if resp.StreamKey == "" || resp.StreamKey == stream.StreamKey {
+		t.Fatalf("Expected a new stream key, got %q", resp.StreamKey)
+	}
+	if resp.RTMPUrl != testConfig.GetRTMPURL(stream.ID, resp.StreamKey) {
+		t.Errorf("Unexpected RTMP URL: %s", resp.RTMPUrl)
+	}
+
+	// Old key is rejected, new key is accepted
+	if code := simulateOnPublish(t, stream.ID, stream.StreamKey); code != http.StatusForbidden {
+		t.Errorf("Expected 403 for old key, got %d", code)
+	}
+	if code := simulateOnPublish(t, stream.ID, resp.StreamKey); code != http.StatusOK {
+		t.Errorf("Expected 200 for new key, got %d", code)
+	}
+
+	// Cannot rotate while LIVE
+	if w := rotate(userID); w.Code != http.StatusConflict {
+		t.Errorf("Expected 409 while live, got %d", w.Code)
+	}
+}
+
+func TestGetStreamDetail_StatsAfterEnd(t *testing.T) {
+	userID := "550e8400-e29b-41d4-a716-446655440012"
+	stream := createTestStream(t, userID, "Test Stream - Stats")
+	defer cleanupTestStream(t, stream.ID)
+
+	if code := simulateOnPublish(t, stream.ID, stream.StreamKey); code != http.StatusOK {
+		t.Fatalf("Publish failed: %d", code)
+	}
+	simulatePlayback(t, "on_play", stream.ID, "viewer-1")
+	simulatePlayback(t, "on_play", stream.ID, "viewer-2")
+	simulatePlayback(t, "on_stop", stream.ID, "viewer-1")
+	simulateOnUnpublish(t, stream.ID)
+
+	req := httptest.NewRequest(http.MethodGet, "/api/v1/live/"+stream.ID, nil)
+	w := httptest.NewRecorder()
+	testRouter.ServeHTTP(w, req)
+
+	var detail entity.StreamDetailResponse
+	if err := json.Unmarshal(w.Body.Bytes(), &detail); err != nil {
+		t.Fatalf("Failed to parse response: %v", err)
+	}
+	if detail.PeakViewerCount == nil || *detail.PeakViewerCount != 2 {
+		t.Errorf("Expected peak_viewer_count 2, got %v", detail.PeakViewerCount)
+	}
+	if detail.DurationSeconds == nil || *detail.DurationSeconds < 0 {
+		t.Errorf("Expected duration_seconds to be set, got %v", detail.DurationSeconds)
+	}
+}
+
+func TestScheduledStream_GoesLiveOnPublish(t *testing.T) {
+	userID := "550e8400-e29b-41d4-a716-446655440013"
+	scheduledAt := time.Now().Add(time.Hour).UTC()
+	body, _ := json.Marshal(map[string]interface{}{
+		"title":              "Test Stream - Scheduled",
+		"scheduled_start_at": scheduledAt,
+	})
+
+	req := httptest.NewRequest(http.MethodPost, "/api/v1/live/create", bytes.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("X-User-ID", userID)
+	w := httptest.NewRecorder()
+	testRouter.ServeHTTP(w, req)
+	if w.Code != http.StatusCreated {
+		t.Fatalf("Failed to create scheduled stream: %d - %s", w.Code, w.Body.String())
+	}
+
+	var stream entity.CreateStreamResponse
+	if err := json.Unmarshal(w.Body.Bytes(), &stream); err != nil {
+		t.Fatalf("Failed to parse response: %v", err)
+	}
+	defer cleanupTestStream(t, stream.ID)
+
+	if status := getStreamStatus(t, stream.ID); status != entity.StatusScheduled {
+		t.Fatalf("Expected status SCHEDULED, got %s", status)
+	}
+
+	if code := simulateOnPublish(t, stream.ID, stream.StreamKey); code != http.StatusOK {
+		t.Fatalf("Publish failed: %d", code)
+	}
+	if status := getStreamStatus(t, stream.ID); status != entity.StatusLive {
+		t.Errorf("Expected status LIVE after publish, got %s", status)
+	}
+}
+
+func TestScheduledStream_PastScheduleRejected(t *testing.T) {
+	body, _ := json.Marshal(map[string]interface{}{
+		"title":              "Test Stream - Past Schedule",
+		"scheduled_start_at": time.Now().Add(-time.Hour).UTC(),
+	})
+
+	req := httptest.NewRequest(http.MethodPost, "/api/v1/live/create", bytes.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("X-User-ID", "550e8400-e29b-41d4-a716-446655440014")
+	w := httptest.NewRecorder()
+	testRouter.ServeHTTP(w, req)
+
+	if w.Code != http.StatusBadRequest {
+		t.Errorf("Expected 400, got %d", w.Code)
+	}
+}
+
+func TestOnPublish_BannedUserRejected(t *testing.T) {
+	userID := "550e8400-e29b-41d4-a716-446655440015"
+	stream := createTestStream(t, userID, "Test Stream - Banned User")
+	defer cleanupTestStream(t, stream.ID)
+
+	if _, err := testDB.Exec("INSERT INTO banned_users (user_id, reason) VALUES ($1, 'test')", userID); err != nil {
+		t.Fatalf("Failed to ban user: %v", err)
+	}
+	defer testDB.Exec("DELETE FROM banned_users WHERE user_id = $1", userID)
+
+	// Valid stream key but banned owner: SRS must reject
+	code := simulateOnPublish(t, stream.ID, stream.StreamKey)
+	if code != http.StatusForbidden {
+		t.Errorf("Expected 403 for banned user, got %d", code)
+	}
+
+	if status := getStreamStatus(t, stream.ID); status != entity.StatusIdle {
+		t.Errorf("Expected status to remain IDLE, got %s", status)
+	}
+}
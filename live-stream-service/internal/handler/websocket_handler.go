@@ -5,6 +5,7 @@ import (
 	"net/http"
 
 	"live-service/internal/entity"
+	"live-service/internal/service"
 	"live-service/internal/websocket"
 
 	"github.com/gin-gonic/gin"
@@ -22,13 +23,15 @@ var upgrader = gorilla.Upgrader{
 
 // WebSocketHandler handles WebSocket connections
 type WebSocketHandler struct {
-	hub *websocket.Hub
+	hub     *websocket.Hub
+	service service.LiveService
 }
 
 // NewWebSocketHandler creates a new WebSocket handler
-func NewWebSocketHandler(hub *websocket.Hub) *WebSocketHandler {
+func NewWebSocketHandler(hub *websocket.Hub, liveService service.LiveService) *WebSocketHandler {
 	return &WebSocketHandler{
-		hub: hub,
+		hub:     hub,
+		service: liveService,
 	}
 }
 
@@ -45,6 +48,7 @@ func NewWebSocketHandler(hub *websocket.Hub) *WebSocketHandler {
 // @Param username query string false "Display username"
 // @Success 101 {string} string "Switching Protocols"
 // @Failure 400 {object} ErrorResponse
+// @Failure 403 {object} ErrorResponse
 // @Router /ws/live/{id} [get]
 func (h *WebSocketHandler) HandleWebSocket(c *gin.Context) {
 	// Parse stream ID (NanoID) from path
@@ -75,6 +79,24 @@ func (h *WebSocketHandler) HandleWebSocket(c *gin.Context) {
 		return
 	}
 
+	// Viewers banned by the stream owner can't rejoin the chat
+	banned, err := h.service.IsViewerBanned(c.Request.Context(), streamID, userID)
+	if err != nil {
+		log.Printf("WebSocket ban check failed for user %s on stream %s: %v", userID, streamID, err)
+		c.JSON(http.StatusInternalServerError, ErrorResponse{
+			Error:   "ban_check_failed",
+			Message: "Failed to join stream chat",
+		})
+		return
+	}
+	if banned {
+		c.JSON(http.StatusForbidden, ErrorResponse{
+			Error:   "banned",
+			Message: "You are banned from this stream",
+		})
+		return
+	}
+
 	// Parse username from query param (optional)
 	username := c.Query("username")
 	if username == "" {
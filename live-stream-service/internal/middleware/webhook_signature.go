@@ -0,0 +1,61 @@
+package middleware
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"io"
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+// maxWebhookBodySize bounds how much of a callback body is read for signature checks
+const maxWebhookBodySize = 1 << 20
+
+// SignWebhookBody returns the hex HMAC-SHA256 of body under secret
+// This is the value expected in the signature header (optionally prefixed with "sha256=")
+func SignWebhookBody(secret string, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write(body)
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// WebhookSignature creates a middleware that verifies an HMAC-SHA256 signature of the request body
+// Requests without a valid signature in header are rejected with 403
+// The body is restored afterwards so handlers can still bind it
+func WebhookSignature(secret, header string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		signature := strings.TrimPrefix(strings.TrimSpace(c.GetHeader(header)), "sha256=")
+		if signature == "" {
+			c.AbortWithStatusJSON(http.StatusForbidden, gin.H{
+				"code":    1,
+				"message": "Missing signature",
+			})
+			return
+		}
+
+		body, err := io.ReadAll(io.LimitReader(c.Request.Body, maxWebhookBodySize))
+		if err != nil {
+			c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{
+				"code":    1,
+				"message": "Failed to read request body",
+			})
+			return
+		}
+		c.Request.Body = io.NopCloser(bytes.NewReader(body))
+
+		expected := SignWebhookBody(secret, body)
+		if !hmac.Equal([]byte(strings.ToLower(signature)), []byte(expected)) {
+			c.AbortWithStatusJSON(http.StatusForbidden, gin.H{
+				"code":    1,
+				"message": "Invalid signature",
+			})
+			return
+		}
+
+		c.Next()
+	}
+}
@@ -0,0 +1,59 @@
+package middleware
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+)
+
+const testWebhookSecret = "test-secret"
+
+func newSignedRouter() *gin.Engine {
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.POST("/on_publish", WebhookSignature(testWebhookSecret, "X-SRS-Signature"), func(c *gin.Context) {
+		body, _ := io.ReadAll(c.Request.Body)
+		c.String(http.StatusOK, string(body))
+	})
+	return router
+}
+
+func TestWebhookSignature(t *testing.T) {
+	body := `{"action":"on_publish","stream":"abc"}`
+
+	tests := []struct {
+		name       string
+		signature  string
+		wantStatus int
+	}{
+		{"valid", SignWebhookBody(testWebhookSecret, []byte(body)), http.StatusOK},
+		{"valid with prefix", "sha256=" + SignWebhookBody(testWebhookSecret, []byte(body)), http.StatusOK},
+		{"missing", "", http.StatusForbidden},
+		{"wrong secret", SignWebhookBody("other-secret", []byte(body)), http.StatusForbidden},
+		{"tampered body", SignWebhookBody(testWebhookSecret, []byte(`{"action":"on_publish"}`)), http.StatusForbidden},
+	}
+
+	router := newSignedRouter()
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodPost, "/on_publish", strings.NewReader(body))
+			if tt.signature != "" {
+				req.Header.Set("X-SRS-Signature", tt.signature)
+			}
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+
+			if w.Code != tt.wantStatus {
+				t.Fatalf("status = %d, want %d", w.Code, tt.wantStatus)
+			}
+			// Handler must still see the original body
+			if tt.wantStatus == http.StatusOK && w.Body.String() != body {
+				t.Errorf("handler body = %q, want %q", w.Body.String(), body)
+			}
+		})
+	}
+}
@@ -0,0 +1,175 @@
+package presence
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// DefaultTTL is how long a viewer stays listed without a heartbeat
+const DefaultTTL = 90 * time.Second
+
+// Viewer is a signed-in user currently watching a stream
+type Viewer struct {
+	UserID     string
+	LastSeenAt time.Time
+}
+
+// ViewerStore keeps the signed-in viewers of each stream in Redis
+//
+// Per stream:
+//   - live:viewers:{stream_id}:seen     ZSET user_id -> last seen (unix ms), drives expiry
+//   - live:viewers:{stream_id}:users    ZSET user_id -> 0, lexicographic order for stable pagination
+//   - live:viewers:{stream_id}:clients  HASH user_id -> playing clients, a user may watch on several devices
+//
+// A user is listed from their first on_play until their last client stops. Viewers whose on_stop is
+// missed drop out once they have not been seen (on_play or heartbeat) for ttl. The keys themselves
+// expire after ttl without activity, so an ended stream leaves nothing behind.
+type ViewerStore struct {
+	client *redis.Client
+	ttl    time.Duration
+	now    func() time.Time
+}
+
+// NewViewerStore creates a store; a non-positive ttl uses DefaultTTL
+func NewViewerStore(client *redis.Client, ttl time.Duration) *ViewerStore {
+	if ttl <= 0 {
+		ttl = DefaultTTL
+	}
+	return &ViewerStore{client: client, ttl: ttl, now: time.Now}
+}
+
+func keys(streamID string) []string {
+	prefix := "live:viewers:" + streamID
+	return []string{prefix + ":seen", prefix + ":users", prefix + ":clients"}
+}
+
+// joinScript adds one playing client of a user. KEYS: seen, users, clients. ARGV: user_id, now_ms, ttl_ms
+var joinScript = redis.NewScript(`
+redis.call('ZADD', KEYS[1], ARGV[2], ARGV[1])
+redis.call('ZADD', KEYS[2], 0, ARGV[1])
+redis.call('HINCRBY', KEYS[3], ARGV[1], 1)
+for _, key in ipairs(KEYS) do
+	redis.call('PEXPIRE', key, ARGV[3])
+end
+return 1
+`)
+
+// leaveScript removes one playing client of a user, and the user with their last client.
+// KEYS: seen, users, clients. ARGV: user_id
+var leaveScript = redis.NewScript(`
+local clients = redis.call('HINCRBY', KEYS[3], ARGV[1], -1)
+if clients > 0 then
+	return 0
+end
+redis.call('HDEL', KEYS[3], ARGV[1])
+redis.call('ZREM', KEYS[1], ARGV[1])
+redis.call('ZREM', KEYS[2], ARGV[1])
+return 1
+`)
+
+// heartbeatScript refreshes a listed user; unknown (never joined or expired) users are not added.
+// KEYS: seen, users, clients. ARGV: user_id, now_ms, ttl_ms
+var heartbeatScript = redis.NewScript(`
+if not redis.call('ZSCORE', KEYS[1], ARGV[1]) then
+	return 0
+end
+redis.call('ZADD', KEYS[1], 'XX', ARGV[2], ARGV[1])
+for _, key in ipairs(KEYS) do
+	redis.call('PEXPIRE', key, ARGV[3])
+end
+return 1
+`)
+
+// pruneScript removes users not seen since the cutoff. KEYS: seen, users, clients. ARGV: cutoff_ms
+var pruneScript = redis.NewScript(`
+local expired = redis.call('ZRANGEBYSCORE', KEYS[1], '-inf', '(' .. ARGV[1])
+for _, user in ipairs(expired) do
+	redis.call('ZREM', KEYS[1], user)
+	redis.call('ZREM', KEYS[2], user)
+	redis.call('HDEL', KEYS[3], user)
+end
+return #expired
+`)
+
+// Join records that a client of userID started playing streamID
+func (s *ViewerStore) Join(ctx context.Context, streamID, userID string) error {
+	now := s.now().UnixMilli()
+	if err := joinScript.Run(ctx, s.client, keys(streamID), userID, now, s.ttl.Milliseconds()).Err(); err != nil {
+		return fmt.Errorf("failed to add viewer: %w", err)
+	}
+	return nil
+}
+
+// Leave records that a client of userID stopped playing streamID
+// Returns true if that was the user's last client, so they are no longer listed
+func (s *ViewerStore) Leave(ctx context.Context, streamID, userID string) (bool, error) {
+	left, err := leaveScript.Run(ctx, s.client, keys(streamID), userID).Int()
+	if err != nil {
+		return false, fmt.Errorf("failed to remove viewer: %w", err)
+	}
+	return left == 1, nil
+}
+
+// Heartbeat keeps a listed viewer from expiring
+// Returns false if the user is not listed (never played the stream, or already expired)
+func (s *ViewerStore) Heartbeat(ctx context.Context, streamID, userID string) (bool, error) {
+	now := s.now().UnixMilli()
+	listed, err := heartbeatScript.Run(ctx, s.client, keys(streamID), userID, now, s.ttl.Milliseconds()).Int()
+	if err != nil {
+		return false, fmt.Errorf("failed to refresh viewer: %w", err)
+	}
+	return listed == 1, nil
+}
+
+// List returns up to limit viewers ordered by user_id, starting after the user_id in after (empty for the first page),
+// and the total number of viewers. Expired viewers are dropped first.
+func (s *ViewerStore) List(ctx context.Context, streamID, after string, limit int) ([]Viewer, int, error) {
+	k := keys(streamID)
+	cutoff := s.now().Add(-s.ttl).UnixMilli()
+	if err := pruneScript.Run(ctx, s.client, k, cutoff).Err(); err != nil {
+		return nil, 0, fmt.Errorf("failed to prune viewers: %w", err)
+	}
+
+	start := "-"
+	if after != "" {
+		start = "(" + after
+	}
+
+	pipe := s.client.Pipeline()
+	usersCmd := pipe.ZRangeByLex(ctx, k[1], &redis.ZRangeBy{Min: start, Max: "+", Count: int64(limit)})
+	totalCmd := pipe.ZCard(ctx, k[1])
+	if _, err := pipe.Exec(ctx); err != nil {
+		return nil, 0, fmt.Errorf("failed to list viewers: %w", err)
+	}
+
+	userIDs := usersCmd.Val()
+	if len(userIDs) == 0 {
+		return []Viewer{}, int(totalCmd.Val()), nil
+	}
+
+	// Users that left between the two reads have no score and are skipped
+	scores, err := s.client.ZMScore(ctx, k[0], userIDs...).Result()
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to read viewer activity: %w", err)
+	}
+
+	viewers := make([]Viewer, 0, len(userIDs))
+	for i, userID := range userIDs {
+		if scores[i] == 0 {
+			continue
+		}
+		viewers = append(viewers, Viewer{UserID: userID, LastSeenAt: time.UnixMilli(int64(scores[i])).UTC()})
+	}
+	return viewers, int(totalCmd.Val()), nil
+}
+
+// Clear removes every viewer of a stream, e.g. when it ends
+func (s *ViewerStore) Clear(ctx context.Context, streamID string) error {
+	if err := s.client.Del(ctx, keys(streamID)...).Err(); err != nil {
+		return fmt.Errorf("failed to clear viewers: %w", err)
+	}
+	return nil
+}
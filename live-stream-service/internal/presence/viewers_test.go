@@ -0,0 +1,167 @@
+package presence
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/redis/go-redis/v9"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const (
+	testStream = "V1StGXR8_Z5jdHi6B-myT"
+	userA      = "11111111-1111-4111-8111-111111111111"
+	userB      = "22222222-2222-4222-8222-222222222222"
+	userC      = "33333333-3333-4333-8333-333333333333"
+)
+
+func newTestStore(t *testing.T) (*miniredis.Miniredis, *ViewerStore, *time.Time) {
+	mr := miniredis.RunT(t)
+	client := redis.NewClient(&redis.Options{Addr: mr.Addr()})
+	t.Cleanup(func() { _ = client.Close() })
+
+	now := time.Date(2024, 1, 15, 10, 30, 0, 0, time.UTC)
+	store := NewViewerStore(client, time.Minute)
+	store.now = func() time.Time { return now }
+	return mr, store, &now
+}
+
+func userIDs(viewers []Viewer) []string {
+	ids := make([]string, 0, len(viewers))
+	for _, v := range viewers {
+		ids = append(ids, v.UserID)
+	}
+	return ids
+}
+
+func TestViewerStore_JoinAndLeave(t *testing.T) {
+	_, store, now := newTestStore(t)
+	ctx := context.Background()
+
+	require.NoError(t, store.Join(ctx, testStream, userB))
+	require.NoError(t, store.Join(ctx, testStream, userA))
+
+	viewers, total, err := store.List(ctx, testStream, "", 10)
+	require.NoError(t, err)
+	assert.Equal(t, 2, total)
+	assert.Equal(t, []string{userA, userB}, userIDs(viewers), "ordered by user_id")
+	assert.Equal(t, *now, viewers[0].LastSeenAt)
+
+	left, err := store.Leave(ctx, testStream, userA)
+	require.NoError(t, err)
+	assert.True(t, left)
+
+	viewers, total, err = store.List(ctx, testStream, "", 10)
+	require.NoError(t, err)
+	assert.Equal(t, 1, total)
+	assert.Equal(t, []string{userB}, userIDs(viewers))
+}
+
+func TestViewerStore_ListedUntilLastClientStops(t *testing.T) {
+	_, store, _ := newTestStore(t)
+	ctx := context.Background()
+
+	// Same user watching on a phone and a laptop
+	require.NoError(t, store.Join(ctx, testStream, userA))
+	require.NoError(t, store.Join(ctx, testStream, userA))
+
+	left, err := store.Leave(ctx, testStream, userA)
+	require.NoError(t, err)
+	assert.False(t, left)
+	_, total, err := store.List(ctx, testStream, "", 10)
+	require.NoError(t, err)
+	assert.Equal(t, 1, total)
+
+	left, err = store.Leave(ctx, testStream, userA)
+	require.NoError(t, err)
+	assert.True(t, left)
+	_, total, err = store.List(ctx, testStream, "", 10)
+	require.NoError(t, err)
+	assert.Equal(t, 0, total)
+
+	// A duplicate on_stop does not leave a negative client count behind
+	_, err = store.Leave(ctx, testStream, userA)
+	require.NoError(t, err)
+	require.NoError(t, store.Join(ctx, testStream, userA))
+	left, err = store.Leave(ctx, testStream, userA)
+	require.NoError(t, err)
+	assert.True(t, left)
+}
+
+func TestViewerStore_MissedStopExpires(t *testing.T) {
+	_, store, now := newTestStore(t)
+	ctx := context.Background()
+
+	require.NoError(t, store.Join(ctx, testStream, userA))
+	require.NoError(t, store.Join(ctx, testStream, userB))
+
+	// Only userB keeps sending heartbeats
+	*now = now.Add(45 * time.Second)
+	listed, err := store.Heartbeat(ctx, testStream, userB)
+	require.NoError(t, err)
+	assert.True(t, listed)
+
+	*now = now.Add(30 * time.Second)
+	viewers, total, err := store.List(ctx, testStream, "", 10)
+	require.NoError(t, err)
+	assert.Equal(t, 1, total)
+	assert.Equal(t, []string{userB}, userIDs(viewers))
+
+	// An expired viewer is not brought back by a late heartbeat
+	listed, err = store.Heartbeat(ctx, testStream, userA)
+	require.NoError(t, err)
+	assert.False(t, listed)
+}
+
+func TestViewerStore_HeartbeatUnknownViewer(t *testing.T) {
+	_, store, _ := newTestStore(t)
+	ctx := context.Background()
+
+	listed, err := store.Heartbeat(ctx, testStream, userA)
+	require.NoError(t, err)
+	assert.False(t, listed)
+
+	_, total, err := store.List(ctx, testStream, "", 10)
+	require.NoError(t, err)
+	assert.Equal(t, 0, total)
+}
+
+func TestViewerStore_Pagination(t *testing.T) {
+	_, store, _ := newTestStore(t)
+	ctx := context.Background()
+
+	for _, user := range []string{userC, userA, userB} {
+		require.NoError(t, store.Join(ctx, testStream, user))
+	}
+
+	page, total, err := store.List(ctx, testStream, "", 2)
+	require.NoError(t, err)
+	assert.Equal(t, 3, total)
+	assert.Equal(t, []string{userA, userB}, userIDs(page))
+
+	page, _, err = store.List(ctx, testStream, userB, 2)
+	require.NoError(t, err)
+	assert.Equal(t, []string{userC}, userIDs(page))
+
+	page, _, err = store.List(ctx, testStream, userC, 2)
+	require.NoError(t, err)
+	assert.Empty(t, page)
+}
+
+func TestViewerStore_KeysExpireAndClear(t *testing.T) {
+	mr, store, _ := newTestStore(t)
+	ctx := context.Background()
+
+	require.NoError(t, store.Join(ctx, testStream, userA))
+	for _, key := range keys(testStream) {
+		assert.Equal(t, time.Minute, mr.TTL(key), key)
+	}
+
+	require.NoError(t, store.Clear(ctx, testStream))
+	for _, key := range keys(testStream) {
+		assert.False(t, mr.Exists(key), key)
+	}
+}
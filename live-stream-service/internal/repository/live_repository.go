@@ -10,6 +10,7 @@ import (
 	"live-service/internal/entity"
 
 	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
 )
 
 // Common repository errors
@@ -30,18 +31,45 @@ type LiveRepository interface {
 	GetByUserID(ctx context.Context, userID string, limit, offset int) ([]entity.LiveSession, error)
 	ListByStatus(ctx context.Context, status entity.LiveSessionStatus, limit, offset int) ([]entity.LiveSession, error)
 	ListLive(ctx context.Context, limit, offset int) ([]entity.LiveSession, error)
+	ListFeed(ctx context.Context, status entity.LiveSessionStatus, sort string, cursor *entity.FeedCursor, limit int) ([]entity.LiveSession, error)
+	SearchFeed(ctx context.Context, query string, statuses []entity.LiveSessionStatus, sort string, cursor *entity.FeedCursor, limit int) ([]entity.LiveSession, error)
+	CountSearch(ctx context.Context, query string, statuses []entity.LiveSessionStatus) (int, error)
 	CountByStatus(ctx context.Context, status entity.LiveSessionStatus) (int, error)
 	CountByUserID(ctx context.Context, userID string) (int, error)
+	IsUserBanned(ctx context.Context, userID string) (bool, error)
 
 	// Update operations
 	Update(ctx context.Context, session *entity.LiveSession) error
 	UpdateURLs(ctx context.Context, id string, rtmpURL, webrtcURL, hlsURL string) error
+	RotateStreamKey(ctx context.Context, id string, streamKey, rtmpURL, webrtcURL string) error
 	UpdateStatus(ctx context.Context, id string, status entity.LiveSessionStatus) error
 	UpdateViewerCount(ctx context.Context, id string, count int) error
-	IncrementViewerCount(ctx context.Context, id string) error
-	DecrementViewerCount(ctx context.Context, id string) error
-	SetStarted(ctx context.Context, id string) error
+	IncrementViewerCount(ctx context.Context, id string) (int, error)
+	DecrementViewerCount(ctx context.Context, id string) (int, error)
+	SetStarted(ctx context.Context, id string, clientID string) error
+	SetStartedByCohost(ctx context.Context, id string, clientID string, cohostID string) error
+	SetChatConversation(ctx context.Context, id string, conversationID string) error
+	SetThumbnail(ctx context.Context, id string, thumbnailURL string) (bool, error)
 	SetEnded(ctx context.Context, id string) error
+	ExpireScheduled(ctx context.Context, deadline time.Time) (int64, error)
+
+	// Viewer tracking (SRS on_play/on_stop)
+	AddViewer(ctx context.Context, id string, clientID string) (int, bool, error)
+	RemoveViewer(ctx context.Context, id string, clientID string) (int, bool, error)
+
+	// Per-stream viewer bans
+	BanViewer(ctx context.Context, id string, userID string, reason string) error
+	ListViewerBans(ctx context.Context, id string) ([]entity.ViewerBan, error)
+	IsViewerBanned(ctx context.Context, id string, userID string) (bool, error)
+
+	// Co-hosts allowed to publish to a stream
+	AddCohost(ctx context.Context, id string, userID string, token string) error
+	RemoveCohost(ctx context.Context, id string, userID string) error
+	ListCohosts(ctx context.Context, id string) ([]entity.StreamCohost, error)
+
+	// DVR recordings (SRS on_dvr)
+	AddRecording(ctx context.Context, recording *entity.StreamRecording) (bool, error)
+	ListRecordings(ctx context.Context, id string) ([]entity.StreamRecording, error)
 
 	// Delete operations
 	Delete(ctx context.Context, id string) error
@@ -65,9 +93,9 @@ func (r *liveRepository) Create(ctx context.Context, session *entity.LiveSession
 	query := `
 		INSERT INTO live_sessions (
 			id, user_id, stream_key, title, description, status, 
-			rtmp_url, webrtc_url, hls_url, viewer_count
+			rtmp_url, webrtc_url, hls_url, viewer_count, scheduled_start_at
 		) VALUES (
-			$1, $2, $3, $4, $5, $6, $7, $8, $9, $10
+			$1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11
 		) RETURNING created_at, updated_at`
 
 	err := r.db.QueryRowxContext(ctx, query,
@@ -81,6 +109,7 @@ func (r *liveRepository) Create(ctx context.Context, session *entity.LiveSession
 		session.WebRTCUrl,
 		session.HLSUrl,
 		session.ViewerCount,
+		session.ScheduledStartAt,
 	).Scan(&session.CreatedAt, &session.UpdatedAt)
 
 	if err != nil {
@@ -97,8 +126,8 @@ func (r *liveRepository) GetByID(ctx context.Context, id string) (*entity.LiveSe
 	var session entity.LiveSession
 	query := `
 		SELECT id, user_id, stream_key, title, description, status,
-			   rtmp_url, webrtc_url, hls_url, viewer_count,
-			   started_at, ended_at, created_at, updated_at
+			   rtmp_url, webrtc_url, hls_url, thumbnail_url, viewer_count, peak_viewer_count, duration_seconds,
+			   scheduled_start_at, publisher_client_id, publisher_cohost_id, chat_conversation_id, started_at, ended_at, created_at, updated_at
 		FROM live_sessions 
 		WHERE id = $1`
 
@@ -117,8 +146,8 @@ func (r *liveRepository) GetByStreamKey(ctx context.Context, streamKey string) (
 	var session entity.LiveSession
 	query := `
 		SELECT id, user_id, stream_key, title, description, status,
-			   rtmp_url, webrtc_url, hls_url, viewer_count,
-			   started_at, ended_at, created_at, updated_at
+			   rtmp_url, webrtc_url, hls_url, thumbnail_url, viewer_count, peak_viewer_count, duration_seconds,
+			   scheduled_start_at, publisher_client_id, publisher_cohost_id, chat_conversation_id, started_at, ended_at, created_at, updated_at
 		FROM live_sessions 
 		WHERE stream_key = $1`
 
@@ -137,8 +166,8 @@ func (r *liveRepository) GetByUserID(ctx context.Context, userID string, limit,
 	var sessions []entity.LiveSession
 	query := `
 		SELECT id, user_id, stream_key, title, description, status,
-			   rtmp_url, webrtc_url, hls_url, viewer_count,
-			   started_at, ended_at, created_at, updated_at
+			   rtmp_url, webrtc_url, hls_url, thumbnail_url, viewer_count, peak_viewer_count, duration_seconds,
+			   scheduled_start_at, publisher_client_id, publisher_cohost_id, chat_conversation_id, started_at, ended_at, created_at, updated_at
 		FROM live_sessions 
 		WHERE user_id = $1
 		ORDER BY created_at DESC
@@ -156,8 +185,8 @@ func (r *liveRepository) ListByStatus(ctx context.Context, status entity.LiveSes
 	var sessions []entity.LiveSession
 	query := `
 		SELECT id, user_id, stream_key, title, description, status,
-			   rtmp_url, webrtc_url, hls_url, viewer_count,
-			   started_at, ended_at, created_at, updated_at
+			   rtmp_url, webrtc_url, hls_url, thumbnail_url, viewer_count, peak_viewer_count, duration_seconds,
+			   scheduled_start_at, publisher_client_id, publisher_cohost_id, chat_conversation_id, started_at, ended_at, created_at, updated_at
 		FROM live_sessions 
 		WHERE status = $1
 		ORDER BY started_at DESC NULLS LAST, created_at DESC
@@ -175,6 +204,118 @@ func (r *liveRepository) ListLive(ctx context.Context, limit, offset int) ([]ent
 	return r.ListByStatus(ctx, entity.StatusLive, limit, offset)
 }
 
+// ListFeed lists sessions with keyset (cursor) pagination
+// sort=viewers orders by viewer_count DESC, sort=recent by created_at DESC,
+// sort=scheduled by scheduled_start_at ASC (soonest first); id breaks ties
+// A nil cursor returns the first page
+func (r *liveRepository) ListFeed(ctx context.Context, status entity.LiveSessionStatus, sort string, cursor *entity.FeedCursor, limit int) ([]entity.LiveSession, error) {
+	var sessions []entity.LiveSession
+
+	args := []interface{}{status}
+	where := "WHERE status = $1"
+	where, orderBy, args := feedOrder(where, sort, cursor, args)
+
+	args = append(args, limit)
+	query := fmt.Sprintf(`
+		SELECT id, user_id, stream_key, title, description, status,
+			   rtmp_url, webrtc_url, hls_url, thumbnail_url, viewer_count, peak_viewer_count, duration_seconds,
+			   scheduled_start_at, publisher_client_id, publisher_cohost_id, chat_conversation_id, started_at, ended_at, created_at, updated_at
+		FROM live_sessions 
+		%s
+		%s
+		LIMIT $%d`, where, orderBy, len(args))
+
+	err := r.db.SelectContext(ctx, &sessions, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list feed: %w", err)
+	}
+
+	return sessions, nil
+}
+
+// titleSearchCondition matches titles against a plain-text query using idx_live_sessions_title_search
+// The to_tsvector expression must stay identical to the index definition for the index to be used
+const titleSearchCondition = `to_tsvector('simple', title) @@ plainto_tsquery('simple', $1)
+		AND status::text = ANY($2)
+		AND NOT EXISTS (SELECT 1 FROM banned_users b WHERE b.user_id = live_sessions.user_id)`
+
+// SearchFeed searches stream titles within the given statuses, excluding banned creators
+// Results are ordered and paginated like ListFeed so clients can reuse feed rendering
+func (r *liveRepository) SearchFeed(ctx context.Context, query string, statuses []entity.LiveSessionStatus, sort string, cursor *entity.FeedCursor, limit int) ([]entity.LiveSession, error) {
+	var sessions []entity.LiveSession
+
+	args := []interface{}{query, pq.Array(statusStrings(statuses))}
+	where := "WHERE " + titleSearchCondition
+	where, orderBy, args := feedOrder(where, sort, cursor, args)
+
+	args = append(args, limit)
+	sqlQuery := fmt.Sprintf(`
+		SELECT id, user_id, stream_key, title, description, status,
+			   rtmp_url, webrtc_url, hls_url, thumbnail_url, viewer_count, peak_viewer_count, duration_seconds,
+			   scheduled_start_at, publisher_client_id, publisher_cohost_id, chat_conversation_id, started_at, ended_at, created_at, updated_at
+		FROM live_sessions 
+		%s
+		%s
+		LIMIT $%d`, where, orderBy, len(args))
+
+	err := r.db.SelectContext(ctx, &sessions, sqlQuery, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to search streams: %w", err)
+	}
+
+	return sessions, nil
+}
+
+// CountSearch counts every stream matched by SearchFeed for the same query and statuses
+func (r *liveRepository) CountSearch(ctx context.Context, query string, statuses []entity.LiveSessionStatus) (int, error) {
+	var count int
+	sqlQuery := `SELECT COUNT(*) FROM live_sessions WHERE ` + titleSearchCondition
+
+	err := r.db.GetContext(ctx, &count, sqlQuery, query, pq.Array(statusStrings(statuses)))
+	if err != nil {
+		return 0, fmt.Errorf("failed to count search results: %w", err)
+	}
+
+	return count, nil
+}
+
+// feedOrder appends the keyset condition for cursor to where and returns the matching ORDER BY
+// Cursor placeholders are numbered after the existing args
+func feedOrder(where string, sort string, cursor *entity.FeedCursor, args []interface{}) (string, string, []interface{}) {
+	n := len(args)
+	var orderBy string
+	switch sort {
+	case entity.SortByRecent:
+		orderBy = "ORDER BY created_at DESC, id DESC"
+		if cursor != nil {
+			args = append(args, cursor.CreatedAt, cursor.ID)
+			where += fmt.Sprintf(" AND (created_at, id) < ($%d, $%d)", n+1, n+2)
+		}
+	case entity.SortByScheduled:
+		orderBy = "ORDER BY scheduled_start_at ASC, id ASC"
+		where += " AND scheduled_start_at IS NOT NULL"
+		if cursor != nil {
+			args = append(args, cursor.ScheduledStartAt, cursor.ID)
+			where += fmt.Sprintf(" AND (scheduled_start_at, id) > ($%d, $%d)", n+1, n+2)
+		}
+	default:
+		orderBy = "ORDER BY viewer_count DESC, created_at DESC, id DESC"
+		if cursor != nil {
+			args = append(args, cursor.ViewerCount, cursor.CreatedAt, cursor.ID)
+			where += fmt.Sprintf(" AND (viewer_count, created_at, id) < ($%d, $%d, $%d)", n+1, n+2, n+3)
+		}
+	}
+	return where, orderBy, args
+}
+
+func statusStrings(statuses []entity.LiveSessionStatus) []string {
+	out := make([]string, len(statuses))
+	for i, status := range statuses {
+		out[i] = string(status)
+	}
+	return out
+}
+
 func (r *liveRepository) CountByStatus(ctx context.Context, status entity.LiveSessionStatus) (int, error) {
 	var count int
 	query := `SELECT COUNT(*) FROM live_sessions WHERE status = $1`
@@ -199,6 +340,19 @@ func (r *liveRepository) CountByUserID(ctx context.Context, userID string) (int,
 	return count, nil
 }
 
+// IsUserBanned checks the banned_users table (primary key lookup, cheap enough for webhooks)
+func (r *liveRepository) IsUserBanned(ctx context.Context, userID string) (bool, error) {
+	var banned bool
+	query := `SELECT EXISTS(SELECT 1 FROM banned_users WHERE user_id = $1)`
+
+	err := r.db.GetContext(ctx, &banned, query, userID)
+	if err != nil {
+		return false, fmt.Errorf("failed to check banned user: %w", err)
+	}
+
+	return banned, nil
+}
+
 func (r *liveRepository) Update(ctx context.Context, session *entity.LiveSession) error {
 	query := `
 		UPDATE live_sessions SET
@@ -243,6 +397,80 @@ func (r *liveRepository) UpdateURLs(ctx context.Context, id string, rtmpURL, web
 	return checkRowsAffected(result)
 }
 
+// RotateStreamKey replaces the stream key (and the publish URLs that embed it)
+// Only allowed while the stream is not LIVE - returns ErrInvalidStatus otherwise
+func (r *liveRepository) RotateStreamKey(ctx context.Context, id string, streamKey, rtmpURL, webrtcURL string) error {
+	query := `
+		UPDATE live_sessions 
+		SET stream_key = $1, rtmp_url = $2, webrtc_url = $3 
+		WHERE id = $4 AND status <> $5`
+
+	result, err := r.db.ExecContext(ctx, query, streamKey, rtmpURL, webrtcURL, id, entity.StatusLive)
+	if err != nil {
+		if isDuplicateKeyError(err) {
+			return ErrDuplicateKey
+		}
+		return fmt.Errorf("failed to rotate stream key: %w", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get rows affected: %w", err)
+	}
+
+	if rowsAffected == 0 {
+		// Distinguish missing stream from a stream that went LIVE
+		if _, err := r.GetByID(ctx, id); err != nil {
+			return err
+		}
+		return ErrInvalidStatus
+	}
+
+	return nil
+}
+
+// SetChatConversation records the chat-service conversation of the stream's chat room
+func (r *liveRepository) SetChatConversation(ctx context.Context, id string, conversationID string) error {
+	query := `UPDATE live_sessions SET chat_conversation_id = $1 WHERE id = $2`
+
+	result, err := r.db.ExecContext(ctx, query, conversationID, id)
+	if err != nil {
+		return fmt.Errorf("failed to set chat conversation: %w", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get rows affected: %w", err)
+	}
+
+	if rowsAffected == 0 {
+		return ErrNotFound
+	}
+
+	return nil
+}
+
+// SetThumbnail stores the thumbnail of a LIVE stream that doesn't have one yet
+// Returns false when nothing changed, so repeated on_hls callbacks stay a single cheap UPDATE
+func (r *liveRepository) SetThumbnail(ctx context.Context, id string, thumbnailURL string) (bool, error) {
+	query := `
+		UPDATE live_sessions 
+		SET thumbnail_url = $1 
+		WHERE id = $2 AND status = $3 AND thumbnail_url IS NULL`
+
+	result, err := r.db.ExecContext(ctx, query, thumbnailURL, id, entity.StatusLive)
+	if err != nil {
+		return false, fmt.Errorf("failed to set thumbnail: %w", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return false, fmt.Errorf("failed to get rows affected: %w", err)
+	}
+
+	return rowsAffected > 0, nil
+}
+
 func (r *liveRepository) UpdateStatus(ctx context.Context, id string, status entity.LiveSessionStatus) error {
 	query := `UPDATE live_sessions SET status = $1 WHERE id = $2`
 
@@ -265,36 +493,88 @@ func (r *liveRepository) UpdateViewerCount(ctx context.Context, id string, count
 	return checkRowsAffected(result)
 }
 
-func (r *liveRepository) IncrementViewerCount(ctx context.Context, id string) error {
-	query := `UPDATE live_sessions SET viewer_count = viewer_count + 1 WHERE id = $1`
-
-	result, err := r.db.ExecContext(ctx, query, id)
-	if err != nil {
-		return fmt.Errorf("failed to increment viewer count: %w", err)
-	}
+// IncrementViewerCount adds one viewer in a single statement and returns the new viewer_count
+// The count is never read-modified-written in app code, so concurrent callers can't lose updates
+func (r *liveRepository) IncrementViewerCount(ctx context.Context, id string) (int, error) {
+	return r.addViewerCount(ctx, id, 1)
+}
 
-	return checkRowsAffected(result)
+// DecrementViewerCount removes one viewer in a single statement and returns the new viewer_count (never below zero)
+func (r *liveRepository) DecrementViewerCount(ctx context.Context, id string) (int, error) {
+	return r.addViewerCount(ctx, id, -1)
 }
 
-func (r *liveRepository) DecrementViewerCount(ctx context.Context, id string) error {
-	query := `UPDATE live_sessions SET viewer_count = GREATEST(viewer_count - 1, 0) WHERE id = $1`
+func (r *liveRepository) addViewerCount(ctx context.Context, id string, delta int) (int, error) {
+	query := `
+		UPDATE live_sessions
+		SET viewer_count = GREATEST(viewer_count + $2, 0),
+			peak_viewer_count = GREATEST(peak_viewer_count, viewer_count + $2)
+		WHERE id = $1
+		RETURNING viewer_count`
 
-	result, err := r.db.ExecContext(ctx, query, id)
+	var count int
+	err := r.db.QueryRowxContext(ctx, query, id, delta).Scan(&count)
 	if err != nil {
-		return fmt.Errorf("failed to decrement viewer count: %w", err)
+		if errors.Is(err, sql.ErrNoRows) {
+			return 0, ErrNotFound
+		}
+		return 0, fmt.Errorf("failed to update viewer count: %w", err)
 	}
 
-	return checkRowsAffected(result)
+	return count, nil
+}
+
+// SetStarted transitions the stream to LIVE and records the SRS client_id of the publisher
+// so retried on_publish callbacks from the same client can be recognized
+// The stream.started outbox event is written by the same statement, so it exists exactly when the transition happened
+func (r *liveRepository) SetStarted(ctx context.Context, id string, clientID string) error {
+	return r.setStarted(ctx, id, clientID, "")
 }
 
-func (r *liveRepository) SetStarted(ctx context.Context, id string) error {
+// SetStartedByCohost starts a stream published by one of its co-hosts
+// cohost.joined is written right after stream.started, by the same statement
+func (r *liveRepository) SetStartedByCohost(ctx context.Context, id string, clientID string, cohostID string) error {
+	return r.setStarted(ctx, id, clientID, cohostID)
+}
+
+func (r *liveRepository) setStarted(ctx context.Context, id string, clientID string, cohostID string) error {
 	now := time.Now()
+	// Both IDLE and SCHEDULED streams may go live
+	// seq keeps stream.started ahead of cohost.joined in the outbox
 	query := `
-		UPDATE live_sessions 
-		SET status = $1, started_at = $2 
-		WHERE id = $3 AND status = $4`
-
-	result, err := r.db.ExecContext(ctx, query, entity.StatusLive, now, id, entity.StatusIdle)
+		WITH started AS (
+			UPDATE live_sessions 
+			SET status = $1, started_at = $2, publisher_client_id = NULLIF($6, ''), publisher_cohost_id = NULLIF($9::text, '') 
+			WHERE id = $3 AND status IN ($4, $5)
+			RETURNING id, user_id, title, description, status, hls_url, scheduled_start_at, started_at
+		), events AS (
+			SELECT 1 AS seq, $7::text AS event_type, id, jsonb_build_object(
+				'stream_id', id,
+				'user_id', user_id,
+				'title', title,
+				'description', description,
+				'status', status,
+				'hls_url', hls_url,
+				'scheduled_start_at', scheduled_start_at,
+				'started_at', started_at
+			) AS payload
+			FROM started
+			UNION ALL
+			SELECT 2, $8::text, id, jsonb_build_object(
+				'stream_id', id,
+				'owner_id', user_id,
+				'user_id', $9::text,
+				'title', title,
+				'started_at', started_at
+			)
+			FROM started
+			WHERE $9::text <> ''
+		)
+		INSERT INTO stream_outbox (event_type, stream_id, payload)
+		SELECT event_type, id, payload FROM events ORDER BY seq`
+
+	result, err := r.db.ExecContext(ctx, query, entity.StatusLive, now, id, entity.StatusIdle, entity.StatusScheduled, clientID,
+		entity.EventStreamStarted, entity.EventCohostJoined, cohostID)
 	if err != nil {
 		return fmt.Errorf("failed to set started: %w", err)
 	}
@@ -313,12 +593,34 @@ func (r *liveRepository) SetStarted(ctx context.Context, id string) error {
 
 func (r *liveRepository) SetEnded(ctx context.Context, id string) error {
 	now := time.Now()
+	// Clear tracked viewers together with the status change so late on_stop
+	// callbacks for this session become no-ops
+	// duration_seconds is derived from started_at so analytics don't need to recompute it
+	// The stream.ended outbox event is written by the same statement
 	query := `
-		UPDATE live_sessions 
-		SET status = $1, ended_at = $2, viewer_count = 0 
-		WHERE id = $3 AND status = $4`
-
-	result, err := r.db.ExecContext(ctx, query, entity.StatusEnded, now, id, entity.StatusLive)
+		WITH cleared AS (
+			DELETE FROM stream_viewers WHERE stream_id = $3
+		), ended AS (
+			UPDATE live_sessions 
+			SET status = $1, ended_at = $2, viewer_count = 0,
+				duration_seconds = GREATEST(EXTRACT(EPOCH FROM ($2 - started_at))::INTEGER, 0)
+			WHERE id = $3 AND status = $4
+			RETURNING id, user_id, title, status, started_at, ended_at, duration_seconds, peak_viewer_count
+		)
+		INSERT INTO stream_outbox (event_type, stream_id, payload)
+		SELECT $5, id, jsonb_build_object(
+			'stream_id', id,
+			'user_id', user_id,
+			'title', title,
+			'status', status,
+			'started_at', started_at,
+			'ended_at', ended_at,
+			'duration_seconds', duration_seconds,
+			'peak_viewer_count', peak_viewer_count
+		)
+		FROM ended`
+
+	result, err := r.db.ExecContext(ctx, query, entity.StatusEnded, now, id, entity.StatusLive, entity.EventStreamEnded)
 	if err != nil {
 		return fmt.Errorf("failed to set ended: %w", err)
 	}
@@ -335,6 +637,228 @@ func (r *liveRepository) SetEnded(ctx context.Context, id string) error {
 	return nil
 }
 
+// ExpireScheduled marks SCHEDULED streams whose scheduled_start_at is before deadline as EXPIRED
+// Returns the number of expired streams
+func (r *liveRepository) ExpireScheduled(ctx context.Context, deadline time.Time) (int64, error) {
+	query := `
+		UPDATE live_sessions 
+		SET status = $1, ended_at = CURRENT_TIMESTAMP 
+		WHERE status = $2 AND scheduled_start_at < $3`
+
+	result, err := r.db.ExecContext(ctx, query, entity.StatusExpired, entity.StatusScheduled, deadline)
+	if err != nil {
+		return 0, fmt.Errorf("failed to expire scheduled streams: %w", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return 0, fmt.Errorf("failed to get rows affected: %w", err)
+	}
+
+	return rowsAffected, nil
+}
+
+// AddViewer registers a viewer (SRS client_id) on a LIVE stream and increments viewer_count
+// (raising peak_viewer_count if needed) in a single statement, returning the new viewer_count.
+// Returns false if the stream is not LIVE or the client is already counted,
+// so duplicate on_play callbacks don't inflate the count.
+func (r *liveRepository) AddViewer(ctx context.Context, id string, clientID string) (int, bool, error) {
+	query := `
+		WITH joined AS (
+			INSERT INTO stream_viewers (stream_id, client_id)
+			SELECT id, $2 FROM live_sessions WHERE id = $1 AND status = $3
+			ON CONFLICT (stream_id, client_id) DO NOTHING
+			RETURNING stream_id
+		)
+		UPDATE live_sessions 
+		SET viewer_count = viewer_count + 1,
+			peak_viewer_count = GREATEST(peak_viewer_count, viewer_count + 1)
+		WHERE id IN (SELECT stream_id FROM joined)
+		RETURNING viewer_count`
+
+	var count int
+	err := r.db.QueryRowxContext(ctx, query, id, clientID, entity.StatusLive).Scan(&count)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return 0, false, nil
+		}
+		return 0, false, fmt.Errorf("failed to add viewer: %w", err)
+	}
+
+	return count, true, nil
+}
+
+// RemoveViewer unregisters a viewer and decrements viewer_count in a single statement, returning the new viewer_count.
+// Returns false if the client was never counted (duplicate or out-of-order on_stop),
+// in which case viewer_count is left untouched. The count never goes below zero.
+func (r *liveRepository) RemoveViewer(ctx context.Context, id string, clientID string) (int, bool, error) {
+	query := `
+		WITH left_viewer AS (
+			DELETE FROM stream_viewers
+			WHERE stream_id = $1 AND client_id = $2
+			RETURNING stream_id
+		)
+		UPDATE live_sessions 
+		SET viewer_count = GREATEST(viewer_count - 1, 0) 
+		WHERE id IN (SELECT stream_id FROM left_viewer)
+		RETURNING viewer_count`
+
+	var count int
+	err := r.db.QueryRowxContext(ctx, query, id, clientID).Scan(&count)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return 0, false, nil
+		}
+		return 0, false, fmt.Errorf("failed to remove viewer: %w", err)
+	}
+
+	return count, true, nil
+}
+
+// BanViewer bans a viewer from a stream; banning an already banned viewer keeps the original ban
+// A banned co-host loses their publish rights in the same statement
+func (r *liveRepository) BanViewer(ctx context.Context, id string, userID string, reason string) error {
+	query := `
+		WITH revoked AS (
+			DELETE FROM stream_cohosts WHERE stream_id = $1 AND user_id = $2
+		)
+		INSERT INTO stream_bans (stream_id, user_id, reason)
+		VALUES ($1, $2, NULLIF($3, ''))
+		ON CONFLICT (stream_id, user_id) DO NOTHING`
+
+	_, err := r.db.ExecContext(ctx, query, id, userID, reason)
+	if err != nil {
+		return fmt.Errorf("failed to ban viewer: %w", err)
+	}
+
+	return nil
+}
+
+// ListViewerBans lists the viewers banned from a stream, newest first
+func (r *liveRepository) ListViewerBans(ctx context.Context, id string) ([]entity.ViewerBan, error) {
+	bans := []entity.ViewerBan{}
+	query := `
+		SELECT stream_id, user_id, reason, banned_at
+		FROM stream_bans
+		WHERE stream_id = $1
+		ORDER BY banned_at DESC, user_id`
+
+	err := r.db.SelectContext(ctx, &bans, query, id)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list viewer bans: %w", err)
+	}
+
+	return bans, nil
+}
+
+// IsViewerBanned checks whether a viewer is banned from a stream
+func (r *liveRepository) IsViewerBanned(ctx context.Context, id string, userID string) (bool, error) {
+	var banned bool
+	query := `SELECT EXISTS(SELECT 1 FROM stream_bans WHERE stream_id = $1 AND user_id = $2)`
+
+	err := r.db.GetContext(ctx, &banned, query, id, userID)
+	if err != nil {
+		return false, fmt.Errorf("failed to check viewer ban: %w", err)
+	}
+
+	return banned, nil
+}
+
+// AddCohost allows userID to publish to a stream with their own token
+// Adding an existing co-host keeps their current token
+func (r *liveRepository) AddCohost(ctx context.Context, id string, userID string, token string) error {
+	query := `
+		INSERT INTO stream_cohosts (stream_id, user_id, token)
+		VALUES ($1, $2, $3)
+		ON CONFLICT (stream_id, user_id) DO NOTHING`
+
+	_, err := r.db.ExecContext(ctx, query, id, userID, token)
+	if err != nil {
+		return fmt.Errorf("failed to add co-host: %w", err)
+	}
+
+	return nil
+}
+
+// RemoveCohost revokes a co-host's publish rights, ErrNotFound if userID is not a co-host
+func (r *liveRepository) RemoveCohost(ctx context.Context, id string, userID string) error {
+	query := `DELETE FROM stream_cohosts WHERE stream_id = $1 AND user_id = $2`
+
+	result, err := r.db.ExecContext(ctx, query, id, userID)
+	if err != nil {
+		return fmt.Errorf("failed to remove co-host: %w", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get rows affected: %w", err)
+	}
+
+	if rowsAffected == 0 {
+		return ErrNotFound
+	}
+
+	return nil
+}
+
+// ListCohosts lists the co-hosts of a stream with their tokens, oldest first
+func (r *liveRepository) ListCohosts(ctx context.Context, id string) ([]entity.StreamCohost, error) {
+	cohosts := []entity.StreamCohost{}
+	query := `
+		SELECT stream_id, user_id, token, granted_at
+		FROM stream_cohosts
+		WHERE stream_id = $1
+		ORDER BY granted_at, user_id`
+
+	err := r.db.SelectContext(ctx, &cohosts, query, id)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list co-hosts: %w", err)
+	}
+
+	return cohosts, nil
+}
+
+// AddRecording stores a DVR file of a stream that has gone live (LIVE, or ENDED when on_dvr follows on_unpublish)
+// Returns false when nothing was stored: a retried callback for a known file, or an unknown or never started stream
+func (r *liveRepository) AddRecording(ctx context.Context, recording *entity.StreamRecording) (bool, error) {
+	query := `
+		INSERT INTO stream_recordings (stream_id, file_path, started_at, duration_ms)
+		SELECT id, $2, $3, $4 FROM live_sessions
+		WHERE id = $1 AND status IN ($5, $6)
+		ON CONFLICT (stream_id, file_path) DO NOTHING`
+
+	result, err := r.db.ExecContext(ctx, query,
+		recording.StreamID, recording.FilePath, recording.StartedAt, recording.DurationMs,
+		entity.StatusLive, entity.StatusEnded)
+	if err != nil {
+		return false, fmt.Errorf("failed to add recording: %w", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return false, fmt.Errorf("failed to get rows affected: %w", err)
+	}
+
+	return rowsAffected > 0, nil
+}
+
+// ListRecordings lists the DVR files of a stream in playback order
+func (r *liveRepository) ListRecordings(ctx context.Context, id string) ([]entity.StreamRecording, error) {
+	recordings := []entity.StreamRecording{}
+	query := `
+		SELECT stream_id, file_path, started_at, duration_ms, recorded_at
+		FROM stream_recordings
+		WHERE stream_id = $1
+		ORDER BY started_at, file_path`
+
+	err := r.db.SelectContext(ctx, &recordings, query, id)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list recordings: %w", err)
+	}
+
+	return recordings, nil
+}
+
 func (r *liveRepository) Delete(ctx context.Context, id string) error {
 	query := `DELETE FROM live_sessions WHERE id = $1`
 
@@ -5,7 +5,10 @@ package repository
 
 import (
 	"context"
+	"encoding/json"
+	"errors"
 	"fmt"
+	"sync"
 	"testing"
 	"time"
 
@@ -74,13 +77,15 @@ func (s *LiveRepositoryTestSuite) TearDownSuite() {
 func (s *LiveRepositoryTestSuite) SetupTest() {
 	// Clean up table before each test
 	s.db.ExecContext(s.ctx, "TRUNCATE TABLE live_sessions CASCADE")
+	s.db.ExecContext(s.ctx, "TRUNCATE TABLE banned_users")
+	s.db.ExecContext(s.ctx, "TRUNCATE TABLE stream_outbox")
 }
 
 func (s *LiveRepositoryTestSuite) runMigrations() {
 	// Create enum type
 	_, err := s.db.ExecContext(s.ctx, `
 		DO $$ BEGIN
-			CREATE TYPE session_status AS ENUM ('IDLE', 'LIVE', 'ENDED');
+			CREATE TYPE session_status AS ENUM ('IDLE', 'LIVE', 'ENDED', 'SCHEDULED', 'EXPIRED');
 		EXCEPTION
 			WHEN duplicate_object THEN null;
 		END $$;
@@ -99,7 +104,14 @@ func (s *LiveRepositoryTestSuite) runMigrations() {
 			rtmp_url VARCHAR(500),
 			webrtc_url VARCHAR(500),
 			hls_url VARCHAR(500),
+			thumbnail_url VARCHAR(500),
 			viewer_count INTEGER NOT NULL DEFAULT 0,
+			peak_viewer_count INTEGER NOT NULL DEFAULT 0,
+			duration_seconds INTEGER,
+			scheduled_start_at TIMESTAMP WITH TIME ZONE,
+			publisher_client_id VARCHAR(64),
+			publisher_cohost_id VARCHAR(36),
+			chat_conversation_id VARCHAR(36),
 			started_at TIMESTAMP WITH TIME ZONE,
 			ended_at TIMESTAMP WITH TIME ZONE,
 			created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP,
@@ -107,6 +119,87 @@ func (s *LiveRepositoryTestSuite) runMigrations() {
 		)
 	`)
 	require.NoError(s.T(), err)
+
+	// Create viewers table
+	_, err = s.db.ExecContext(s.ctx, `
+		CREATE TABLE IF NOT EXISTS stream_viewers (
+			stream_id VARCHAR(21) NOT NULL REFERENCES live_sessions(id) ON DELETE CASCADE,
+			client_id VARCHAR(64) NOT NULL,
+			joined_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP,
+			PRIMARY KEY (stream_id, client_id)
+		)
+	`)
+	require.NoError(s.T(), err)
+
+	// Create banned users table
+	_, err = s.db.ExecContext(s.ctx, `
+		CREATE TABLE IF NOT EXISTS banned_users (
+			user_id VARCHAR(36) PRIMARY KEY,
+			reason TEXT,
+			banned_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP
+		)
+	`)
+	require.NoError(s.T(), err)
+
+	// Create stream bans table
+	_, err = s.db.ExecContext(s.ctx, `
+		CREATE TABLE IF NOT EXISTS stream_bans (
+			stream_id VARCHAR(21) NOT NULL REFERENCES live_sessions(id) ON DELETE CASCADE,
+			user_id VARCHAR(36) NOT NULL,
+			reason TEXT,
+			banned_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP,
+			PRIMARY KEY (stream_id, user_id)
+		)
+	`)
+	require.NoError(s.T(), err)
+
+	// Create stream co-hosts table
+	_, err = s.db.ExecContext(s.ctx, `
+		CREATE TABLE IF NOT EXISTS stream_cohosts (
+			stream_id VARCHAR(21) NOT NULL REFERENCES live_sessions(id) ON DELETE CASCADE,
+			user_id VARCHAR(36) NOT NULL,
+			token VARCHAR(255) NOT NULL,
+			granted_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP,
+			PRIMARY KEY (stream_id, user_id)
+		)
+	`)
+	require.NoError(s.T(), err)
+
+	// Create stream recordings table
+	_, err = s.db.ExecContext(s.ctx, `
+		CREATE TABLE IF NOT EXISTS stream_recordings (
+			stream_id VARCHAR(21) NOT NULL REFERENCES live_sessions(id) ON DELETE CASCADE,
+			file_path VARCHAR(500) NOT NULL,
+			started_at TIMESTAMP WITH TIME ZONE NOT NULL,
+			duration_ms BIGINT NOT NULL,
+			recorded_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP,
+			PRIMARY KEY (stream_id, file_path)
+		)
+	`)
+	require.NoError(s.T(), err)
+
+	// Stream outbox
+	_, err = s.db.ExecContext(s.ctx, `
+		CREATE TABLE IF NOT EXISTS stream_outbox (
+			id BIGSERIAL PRIMARY KEY,
+			event_id UUID NOT NULL DEFAULT gen_random_uuid(),
+			event_type VARCHAR(50) NOT NULL,
+			stream_id VARCHAR(21) NOT NULL,
+			payload JSONB NOT NULL,
+			created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP,
+			published_at TIMESTAMP WITH TIME ZONE,
+			retry_count INTEGER NOT NULL DEFAULT 0,
+			last_retry_at TIMESTAMP WITH TIME ZONE,
+			last_error TEXT
+		)
+	`)
+	require.NoError(s.T(), err)
+
+	// Title search index
+	_, err = s.db.ExecContext(s.ctx, `
+		CREATE INDEX IF NOT EXISTS idx_live_sessions_title_search ON live_sessions USING GIN (to_tsvector('simple', title))
+	`)
+	require.NoError(s.T(), err)
 }
 
 // Helper to create a test session
@@ -252,6 +345,154 @@ func (s *LiveRepositoryTestSuite) TestListLive_Success() {
 	assert.Len(s.T(), sessions, 3)
 }
 
+func (s *LiveRepositoryTestSuite) TestListFeed_ViewersCursor() {
+	// Create live sessions with distinct viewer counts
+	for i := 1; i <= 5; i++ {
+		userID := fmt.Sprintf("550e8400-e29b-41d4-a716-44665544000%d", i)
+		session := s.createTestSession(userID, fmt.Sprintf("live_%s_%032x", userID, 130+i), "Live Stream")
+		session.Status = entity.StatusLive
+		session.ViewerCount = i * 10
+		err := s.repo.Create(s.ctx, session)
+		require.NoError(s.T(), err)
+	}
+
+	page1, err := s.repo.ListFeed(s.ctx, entity.StatusLive, entity.SortByViewers, nil, 3)
+	require.NoError(s.T(), err)
+	require.Len(s.T(), page1, 3)
+	assert.Equal(s.T(), 50, page1[0].ViewerCount)
+	assert.Equal(s.T(), 30, page1[2].ViewerCount)
+
+	cursor := entity.NewFeedCursor(&page1[2])
+	page2, err := s.repo.ListFeed(s.ctx, entity.StatusLive, entity.SortByViewers, &cursor, 3)
+	require.NoError(s.T(), err)
+	require.Len(s.T(), page2, 2)
+	assert.Equal(s.T(), 20, page2[0].ViewerCount)
+	assert.Equal(s.T(), 10, page2[1].ViewerCount)
+}
+
+func (s *LiveRepositoryTestSuite) TestListFeed_RecentCursor() {
+	var ids []string
+	for i := 1; i <= 4; i++ {
+		userID := fmt.Sprintf("550e8400-e29b-41d4-a716-44665544000%d", i)
+		session := s.createTestSession(userID, fmt.Sprintf("live_%s_%032x", userID, 140+i), "Ended Stream")
+		session.Status = entity.StatusEnded
+		err := s.repo.Create(s.ctx, session)
+		require.NoError(s.T(), err)
+		ids = append(ids, session.ID)
+	}
+
+	page1, err := s.repo.ListFeed(s.ctx, entity.StatusEnded, entity.SortByRecent, nil, 2)
+	require.NoError(s.T(), err)
+	require.Len(s.T(), page1, 2)
+
+	cursor := entity.NewFeedCursor(&page1[1])
+	page2, err := s.repo.ListFeed(s.ctx, entity.StatusEnded, entity.SortByRecent, &cursor, 2)
+	require.NoError(s.T(), err)
+	require.Len(s.T(), page2, 2)
+
+	// Pages don't overlap and together cover every session
+	seen := map[string]bool{}
+	for _, session := range append(page1, page2...) {
+		assert.False(s.T(), seen[session.ID], "duplicate session across pages")
+		seen[session.ID] = true
+	}
+	for _, id := range ids {
+		assert.True(s.T(), seen[id])
+	}
+}
+
+func (s *LiveRepositoryTestSuite) TestListFeed_StatusFilter() {
+	userID := "550e8400-e29b-41d4-a716-446655440000"
+	live := s.createTestSession(userID, fmt.Sprintf("live_%s_%032x", userID, 150), "Live")
+	live.Status = entity.StatusLive
+	require.NoError(s.T(), s.repo.Create(s.ctx, live))
+	idle := s.createTestSession(userID, fmt.Sprintf("live_%s_%032x", userID, 151), "Idle")
+	require.NoError(s.T(), s.repo.Create(s.ctx, idle))
+
+	sessions, err := s.repo.ListFeed(s.ctx, entity.StatusIdle, entity.SortByViewers, nil, 10)
+	require.NoError(s.T(), err)
+	require.Len(s.T(), sessions, 1)
+	assert.Equal(s.T(), idle.ID, sessions[0].ID)
+}
+
+func (s *LiveRepositoryTestSuite) TestListFeed_ScheduledSoonestFirst() {
+	userID := "550e8400-e29b-41d4-a716-446655440000"
+	now := time.Now()
+	for i, offset := range []time.Duration{3 * time.Hour, time.Hour, 2 * time.Hour} {
+		session := s.createTestSession(userID, fmt.Sprintf("live_%s_%032x", userID, 160+i), "Upcoming")
+		session.Status = entity.StatusScheduled
+		scheduledAt := now.Add(offset)
+		session.ScheduledStartAt = &scheduledAt
+		require.NoError(s.T(), s.repo.Create(s.ctx, session))
+	}
+
+	page1, err := s.repo.ListFeed(s.ctx, entity.StatusScheduled, entity.SortByScheduled, nil, 2)
+	require.NoError(s.T(), err)
+	require.Len(s.T(), page1, 2)
+	assert.True(s.T(), page1[0].ScheduledStartAt.Before(*page1[1].ScheduledStartAt))
+
+	cursor := entity.NewFeedCursor(&page1[1])
+	page2, err := s.repo.ListFeed(s.ctx, entity.StatusScheduled, entity.SortByScheduled, &cursor, 2)
+	require.NoError(s.T(), err)
+	require.Len(s.T(), page2, 1)
+	assert.True(s.T(), page2[0].ScheduledStartAt.After(*page1[1].ScheduledStartAt))
+}
+
+func (s *LiveRepositoryTestSuite) TestSearchFeed_PublicTitleMatches() {
+	userID := "550e8400-e29b-41d4-a716-446655440000"
+	for i, tc := range []struct {
+		title  string
+		status entity.LiveSessionStatus
+	}{
+		{"Speedrun any% attempts", entity.StatusLive},
+		{"Chill speedrun practice", entity.StatusEnded},
+		{"Speedrun draft", entity.StatusIdle},
+		{"Cooking stream", entity.StatusLive},
+	} {
+		session := s.createTestSession(userID, fmt.Sprintf("live_%s_%032x", userID, 170+i), tc.title)
+		session.Status = tc.status
+		require.NoError(s.T(), s.repo.Create(s.ctx, session))
+	}
+
+	sessions, err := s.repo.SearchFeed(s.ctx, "speedrun", entity.PublicStatuses(), entity.SortByRecent, nil, 10)
+	require.NoError(s.T(), err)
+	require.Len(s.T(), sessions, 2, "IDLE streams are not public")
+
+	count, err := s.repo.CountSearch(s.ctx, "speedrun", entity.PublicStatuses())
+	require.NoError(s.T(), err)
+	assert.Equal(s.T(), 2, count)
+
+	// Status filter narrows the search
+	sessions, err = s.repo.SearchFeed(s.ctx, "speedrun", []entity.LiveSessionStatus{entity.StatusLive}, entity.SortByViewers, nil, 10)
+	require.NoError(s.T(), err)
+	require.Len(s.T(), sessions, 1)
+	assert.Equal(s.T(), "Speedrun any% attempts", sessions[0].Title)
+
+	// Cursor pagination works the same way as the feed
+	page1, err := s.repo.SearchFeed(s.ctx, "speedrun", entity.PublicStatuses(), entity.SortByRecent, nil, 1)
+	require.NoError(s.T(), err)
+	require.Len(s.T(), page1, 1)
+	cursor := entity.NewFeedCursor(&page1[0])
+	page2, err := s.repo.SearchFeed(s.ctx, "speedrun", entity.PublicStatuses(), entity.SortByRecent, &cursor, 1)
+	require.NoError(s.T(), err)
+	require.Len(s.T(), page2, 1)
+	assert.NotEqual(s.T(), page1[0].ID, page2[0].ID)
+}
+
+func (s *LiveRepositoryTestSuite) TestSearchFeed_ExcludesBannedCreators() {
+	userID := "550e8400-e29b-41d4-a716-446655440000"
+	session := s.createTestSession(userID, fmt.Sprintf("live_%s_%032x", userID, 180), "Speedrun")
+	session.Status = entity.StatusLive
+	require.NoError(s.T(), s.repo.Create(s.ctx, session))
+
+	_, err := s.db.ExecContext(s.ctx, "INSERT INTO banned_users (user_id) VALUES ($1)", userID)
+	require.NoError(s.T(), err)
+
+	sessions, err := s.repo.SearchFeed(s.ctx, "speedrun", entity.PublicStatuses(), entity.SortByViewers, nil, 10)
+	require.NoError(s.T(), err)
+	assert.Empty(s.T(), sessions)
+}
+
 func (s *LiveRepositoryTestSuite) TestCountByStatus_Success() {
 	// Create sessions with different statuses
 	user1 := "550e8400-e29b-41d4-a716-446655440000"
@@ -279,6 +520,140 @@ func (s *LiveRepositoryTestSuite) TestCountByStatus_Success() {
 
 // ==================== UPDATE TESTS ====================
 
+func (s *LiveRepositoryTestSuite) TestIsUserBanned() {
+	bannedUser := "550e8400-e29b-41d4-a716-446655440000"
+	otherUser := "550e8400-e29b-41d4-a716-446655440001"
+	_, err := s.db.ExecContext(s.ctx, "INSERT INTO banned_users (user_id, reason) VALUES ($1, 'spam')", bannedUser)
+	require.NoError(s.T(), err)
+
+	banned, err := s.repo.IsUserBanned(s.ctx, bannedUser)
+	assert.NoError(s.T(), err)
+	assert.True(s.T(), banned)
+
+	banned, err = s.repo.IsUserBanned(s.ctx, otherUser)
+	assert.NoError(s.T(), err)
+	assert.False(s.T(), banned)
+}
+
+func (s *LiveRepositoryTestSuite) TestBanViewer_ListAndCheck() {
+	userID := "550e8400-e29b-41d4-a716-446655440000"
+	viewerID := "7c9e6679-7425-40de-944b-e07fc1f90ae7"
+	session := s.createTestSession(userID, fmt.Sprintf("live_%s_%032x", userID, 190), "Moderated")
+	require.NoError(s.T(), s.repo.Create(s.ctx, session))
+
+	banned, err := s.repo.IsViewerBanned(s.ctx, session.ID, viewerID)
+	require.NoError(s.T(), err)
+	assert.False(s.T(), banned)
+
+	require.NoError(s.T(), s.repo.BanViewer(s.ctx, session.ID, viewerID, "spam"))
+	// Banning twice keeps a single entry
+	require.NoError(s.T(), s.repo.BanViewer(s.ctx, session.ID, viewerID, ""))
+
+	banned, err = s.repo.IsViewerBanned(s.ctx, session.ID, viewerID)
+	require.NoError(s.T(), err)
+	assert.True(s.T(), banned)
+
+	bans, err := s.repo.ListViewerBans(s.ctx, session.ID)
+	require.NoError(s.T(), err)
+	require.Len(s.T(), bans, 1)
+	assert.Equal(s.T(), viewerID, bans[0].UserID)
+	require.NotNil(s.T(), bans[0].Reason)
+	assert.Equal(s.T(), "spam", *bans[0].Reason)
+}
+
+func (s *LiveRepositoryTestSuite) TestCohosts_AddListRemove() {
+	userID := "550e8400-e29b-41d4-a716-446655440000"
+	cohostID := "7c9e6679-7425-40de-944b-e07fc1f90ae7"
+	session := s.createTestSession(userID, fmt.Sprintf("live_%s_%032x", userID, 191), "Co-streamed")
+	require.NoError(s.T(), s.repo.Create(s.ctx, session))
+
+	cohosts, err := s.repo.ListCohosts(s.ctx, session.ID)
+	require.NoError(s.T(), err)
+	assert.Empty(s.T(), cohosts)
+
+	require.NoError(s.T(), s.repo.AddCohost(s.ctx, session.ID, cohostID, "live_cohostToken1"))
+	// Adding again keeps the first token
+	require.NoError(s.T(), s.repo.AddCohost(s.ctx, session.ID, cohostID, "live_cohostToken2"))
+
+	cohosts, err = s.repo.ListCohosts(s.ctx, session.ID)
+	require.NoError(s.T(), err)
+	require.Len(s.T(), cohosts, 1)
+	assert.Equal(s.T(), cohostID, cohosts[0].UserID)
+	assert.Equal(s.T(), "live_cohostToken1", cohosts[0].Token)
+
+	require.NoError(s.T(), s.repo.RemoveCohost(s.ctx, session.ID, cohostID))
+	assert.ErrorIs(s.T(), s.repo.RemoveCohost(s.ctx, session.ID, cohostID), ErrNotFound)
+
+	cohosts, err = s.repo.ListCohosts(s.ctx, session.ID)
+	require.NoError(s.T(), err)
+	assert.Empty(s.T(), cohosts)
+}
+
+func (s *LiveRepositoryTestSuite) TestBanViewer_RevokesCohost() {
+	userID := "550e8400-e29b-41d4-a716-446655440000"
+	cohostID := "7c9e6679-7425-40de-944b-e07fc1f90ae7"
+	session := s.createTestSession(userID, fmt.Sprintf("live_%s_%032x", userID, 192), "Co-streamed")
+	require.NoError(s.T(), s.repo.Create(s.ctx, session))
+	require.NoError(s.T(), s.repo.AddCohost(s.ctx, session.ID, cohostID, "live_cohostToken1"))
+
+	require.NoError(s.T(), s.repo.SetStartedByCohost(s.ctx, session.ID, "client-1", cohostID))
+	live, err := s.repo.GetByID(s.ctx, session.ID)
+	require.NoError(s.T(), err)
+	require.NotNil(s.T(), live.PublisherCohostID)
+	assert.Equal(s.T(), cohostID, *live.PublisherCohostID)
+
+	require.NoError(s.T(), s.repo.BanViewer(s.ctx, session.ID, cohostID, "abuse"))
+
+	cohosts, err := s.repo.ListCohosts(s.ctx, session.ID)
+	require.NoError(s.T(), err)
+	assert.Empty(s.T(), cohosts, "a banned co-host can't publish again")
+}
+
+func (s *LiveRepositoryTestSuite) TestRecordings_AddList() {
+	userID := "550e8400-e29b-41d4-a716-446655440000"
+	session := s.createTestSession(userID, fmt.Sprintf("live_%s_%032x", userID, 212), "Recorded")
+	require.NoError(s.T(), s.repo.Create(s.ctx, session))
+
+	start := time.Now().Add(-time.Hour).Truncate(time.Millisecond)
+	first := &entity.StreamRecording{StreamID: session.ID, FilePath: "recordings/live/a/1.mp4", StartedAt: start, DurationMs: 1800000}
+	second := &entity.StreamRecording{StreamID: session.ID, FilePath: "recordings/live/a/2.mp4", StartedAt: start.Add(30 * time.Minute), DurationMs: 60000}
+
+	// Streams that never went live record nothing
+	added, err := s.repo.AddRecording(s.ctx, first)
+	require.NoError(s.T(), err)
+	assert.False(s.T(), added)
+
+	require.NoError(s.T(), s.repo.SetStarted(s.ctx, session.ID, "client-1"))
+	added, err = s.repo.AddRecording(s.ctx, second)
+	require.NoError(s.T(), err)
+	assert.True(s.T(), added)
+
+	// on_dvr of the last file may follow on_unpublish
+	require.NoError(s.T(), s.repo.SetEnded(s.ctx, session.ID))
+	added, err = s.repo.AddRecording(s.ctx, first)
+	require.NoError(s.T(), err)
+	assert.True(s.T(), added)
+
+	// Retried callbacks keep the stored file
+	retry := *first
+	retry.DurationMs = 1900000
+	added, err = s.repo.AddRecording(s.ctx, &retry)
+	require.NoError(s.T(), err)
+	assert.False(s.T(), added)
+
+	recordings, err := s.repo.ListRecordings(s.ctx, session.ID)
+	require.NoError(s.T(), err)
+	require.Len(s.T(), recordings, 2)
+	assert.Equal(s.T(), "recordings/live/a/1.mp4", recordings[0].FilePath, "ordered by start time")
+	assert.Equal(s.T(), int64(1800000), recordings[0].DurationMs)
+	assert.True(s.T(), start.Equal(recordings[0].StartedAt))
+	assert.Equal(s.T(), "recordings/live/a/2.mp4", recordings[1].FilePath)
+
+	added, err = s.repo.AddRecording(s.ctx, &entity.StreamRecording{StreamID: "missing-stream-id-0000", FilePath: "x.mp4", StartedAt: start})
+	require.NoError(s.T(), err)
+	assert.False(s.T(), added)
+}
+
 func (s *LiveRepositoryTestSuite) TestUpdate_Success() {
 	userID := "550e8400-e29b-41d4-a716-446655440000"
 	session := s.createTestSession(userID, fmt.Sprintf("live_%s_%032x", userID, 100), "Original Title")
@@ -330,11 +705,13 @@ func (s *LiveRepositoryTestSuite) TestIncrementViewerCount_Success() {
 	err := s.repo.Create(s.ctx, session)
 	require.NoError(s.T(), err)
 
-	err = s.repo.IncrementViewerCount(s.ctx, session.ID)
+	count, err := s.repo.IncrementViewerCount(s.ctx, session.ID)
 	assert.NoError(s.T(), err)
+	assert.Equal(s.T(), 1, count)
 
-	err = s.repo.IncrementViewerCount(s.ctx, session.ID)
+	count, err = s.repo.IncrementViewerCount(s.ctx, session.ID)
 	assert.NoError(s.T(), err)
+	assert.Equal(s.T(), 2, count)
 
 	found, _ := s.repo.GetByID(s.ctx, session.ID)
 	assert.Equal(s.T(), 2, found.ViewerCount)
@@ -349,8 +726,9 @@ func (s *LiveRepositoryTestSuite) TestDecrementViewerCount_Success() {
 	// Set initial count
 	s.repo.UpdateViewerCount(s.ctx, session.ID, 5)
 
-	err = s.repo.DecrementViewerCount(s.ctx, session.ID)
+	count, err := s.repo.DecrementViewerCount(s.ctx, session.ID)
 	assert.NoError(s.T(), err)
+	assert.Equal(s.T(), 4, count)
 
 	found, _ := s.repo.GetByID(s.ctx, session.ID)
 	assert.Equal(s.T(), 4, found.ViewerCount)
@@ -363,20 +741,243 @@ func (s *LiveRepositoryTestSuite) TestDecrementViewerCount_NotBelowZero() {
 	require.NoError(s.T(), err)
 
 	// Decrement from 0
-	err = s.repo.DecrementViewerCount(s.ctx, session.ID)
+	_, err = s.repo.DecrementViewerCount(s.ctx, session.ID)
 	assert.NoError(s.T(), err)
 
 	found, _ := s.repo.GetByID(s.ctx, session.ID)
 	assert.Equal(s.T(), 0, found.ViewerCount)
 }
 
+func (s *LiveRepositoryTestSuite) TestIncrementViewerCount_Concurrent() {
+	userID := "550e8400-e29b-41d4-a716-446655440000"
+	session := s.createTestSession(userID, fmt.Sprintf("live_%s_%032x", userID, 210), "Test")
+	err := s.repo.Create(s.ctx, session)
+	require.NoError(s.T(), err)
+
+	const workers = 50
+	counts := make(chan int, workers)
+	var wg sync.WaitGroup
+	for i := 0; i < workers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			count, err := s.repo.IncrementViewerCount(s.ctx, session.ID)
+			assert.NoError(s.T(), err)
+			counts <- count
+		}()
+	}
+	wg.Wait()
+	close(counts)
+
+	// Every caller must observe a distinct authoritative value, i.e. no update was lost
+	seen := make(map[int]bool)
+	for count := range counts {
+		assert.False(s.T(), seen[count], "count %d returned twice", count)
+		seen[count] = true
+	}
+	assert.Len(s.T(), seen, workers)
+
+	found, _ := s.repo.GetByID(s.ctx, session.ID)
+	assert.Equal(s.T(), workers, found.ViewerCount)
+	assert.Equal(s.T(), workers, found.PeakViewerCount)
+}
+
+func (s *LiveRepositoryTestSuite) TestAddViewer_Concurrent() {
+	userID := "550e8400-e29b-41d4-a716-446655440000"
+	session := s.createTestSession(userID, fmt.Sprintf("live_%s_%032x", userID, 211), "Test")
+	session.Status = entity.StatusLive
+	err := s.repo.Create(s.ctx, session)
+	require.NoError(s.T(), err)
+
+	// Each client joins twice; duplicates must not be counted
+	const clients = 25
+	var wg sync.WaitGroup
+	for i := 0; i < clients*2; i++ {
+		wg.Add(1)
+		go func(clientID string) {
+			defer wg.Done()
+			_, _, err := s.repo.AddViewer(s.ctx, session.ID, clientID)
+			assert.NoError(s.T(), err)
+		}(fmt.Sprintf("client-%d", i%clients))
+	}
+	wg.Wait()
+
+	found, _ := s.repo.GetByID(s.ctx, session.ID)
+	assert.Equal(s.T(), clients, found.ViewerCount)
+}
+
+func (s *LiveRepositoryTestSuite) TestAddViewer_Success() {
+	userID := "550e8400-e29b-41d4-a716-446655440000"
+	session := s.createTestSession(userID, fmt.Sprintf("live_%s_%032x", userID, 120), "Test")
+	session.Status = entity.StatusLive
+	err := s.repo.Create(s.ctx, session)
+	require.NoError(s.T(), err)
+
+	_, added, err := s.repo.AddViewer(s.ctx, session.ID, "client-1")
+	assert.NoError(s.T(), err)
+	assert.True(s.T(), added)
+
+	count, added, err := s.repo.AddViewer(s.ctx, session.ID, "client-2")
+	assert.NoError(s.T(), err)
+	assert.True(s.T(), added)
+	assert.Equal(s.T(), 2, count)
+
+	found, _ := s.repo.GetByID(s.ctx, session.ID)
+	assert.Equal(s.T(), 2, found.ViewerCount)
+}
+
+func (s *LiveRepositoryTestSuite) TestAddViewer_DuplicateClient() {
+	userID := "550e8400-e29b-41d4-a716-446655440000"
+	session := s.createTestSession(userID, fmt.Sprintf("live_%s_%032x", userID, 121), "Test")
+	session.Status = entity.StatusLive
+	err := s.repo.Create(s.ctx, session)
+	require.NoError(s.T(), err)
+
+	_, added, err := s.repo.AddViewer(s.ctx, session.ID, "client-1")
+	require.NoError(s.T(), err)
+	assert.True(s.T(), added)
+
+	// Duplicate on_play callback must not be counted twice
+	_, added, err = s.repo.AddViewer(s.ctx, session.ID, "client-1")
+	assert.NoError(s.T(), err)
+	assert.False(s.T(), added)
+
+	found, _ := s.repo.GetByID(s.ctx, session.ID)
+	assert.Equal(s.T(), 1, found.ViewerCount)
+}
+
+func (s *LiveRepositoryTestSuite) TestAddViewer_StreamNotLive() {
+	userID := "550e8400-e29b-41d4-a716-446655440000"
+	session := s.createTestSession(userID, fmt.Sprintf("live_%s_%032x", userID, 122), "Test")
+	err := s.repo.Create(s.ctx, session) // IDLE
+	require.NoError(s.T(), err)
+
+	_, added, err := s.repo.AddViewer(s.ctx, session.ID, "client-1")
+	assert.NoError(s.T(), err)
+	assert.False(s.T(), added)
+
+	found, _ := s.repo.GetByID(s.ctx, session.ID)
+	assert.Equal(s.T(), 0, found.ViewerCount)
+}
+
+func (s *LiveRepositoryTestSuite) TestRemoveViewer_Success() {
+	userID := "550e8400-e29b-41d4-a716-446655440000"
+	session := s.createTestSession(userID, fmt.Sprintf("live_%s_%032x", userID, 123), "Test")
+	session.Status = entity.StatusLive
+	err := s.repo.Create(s.ctx, session)
+	require.NoError(s.T(), err)
+
+	_, _, err = s.repo.AddViewer(s.ctx, session.ID, "client-1")
+	require.NoError(s.T(), err)
+	_, _, err = s.repo.AddViewer(s.ctx, session.ID, "client-2")
+	require.NoError(s.T(), err)
+
+	_, removed, err := s.repo.RemoveViewer(s.ctx, session.ID, "client-1")
+	assert.NoError(s.T(), err)
+	assert.True(s.T(), removed)
+
+	found, _ := s.repo.GetByID(s.ctx, session.ID)
+	assert.Equal(s.T(), 1, found.ViewerCount)
+}
+
+func (s *LiveRepositoryTestSuite) TestRemoveViewer_UnknownClient() {
+	userID := "550e8400-e29b-41d4-a716-446655440000"
+	session := s.createTestSession(userID, fmt.Sprintf("live_%s_%032x", userID, 124), "Test")
+	session.Status = entity.StatusLive
+	err := s.repo.Create(s.ctx, session)
+	require.NoError(s.T(), err)
+
+	_, _, err = s.repo.AddViewer(s.ctx, session.ID, "client-1")
+	require.NoError(s.T(), err)
+
+	// on_stop for a client that never played (or was already stopped) is ignored
+	_, removed, err := s.repo.RemoveViewer(s.ctx, session.ID, "client-unknown")
+	assert.NoError(s.T(), err)
+	assert.False(s.T(), removed)
+
+	_, removed, err = s.repo.RemoveViewer(s.ctx, session.ID, "client-1")
+	require.NoError(s.T(), err)
+	assert.True(s.T(), removed)
+
+	// Duplicate on_stop must not push the count below zero
+	_, removed, err = s.repo.RemoveViewer(s.ctx, session.ID, "client-1")
+	assert.NoError(s.T(), err)
+	assert.False(s.T(), removed)
+
+	found, _ := s.repo.GetByID(s.ctx, session.ID)
+	assert.Equal(s.T(), 0, found.ViewerCount)
+}
+
+func (s *LiveRepositoryTestSuite) TestAddViewer_TracksPeak() {
+	userID := "550e8400-e29b-41d4-a716-446655440000"
+	session := s.createTestSession(userID, fmt.Sprintf("live_%s_%032x", userID, 126), "Test")
+	session.Status = entity.StatusLive
+	err := s.repo.Create(s.ctx, session)
+	require.NoError(s.T(), err)
+
+	for _, clientID := range []string{"client-1", "client-2", "client-3"} {
+		_, _, err = s.repo.AddViewer(s.ctx, session.ID, clientID)
+		require.NoError(s.T(), err)
+	}
+	_, _, err = s.repo.RemoveViewer(s.ctx, session.ID, "client-1")
+	require.NoError(s.T(), err)
+	_, _, err = s.repo.RemoveViewer(s.ctx, session.ID, "client-2")
+	require.NoError(s.T(), err)
+	_, _, err = s.repo.AddViewer(s.ctx, session.ID, "client-4")
+	require.NoError(s.T(), err)
+
+	// Peak stays at the highest concurrent count
+	found, _ := s.repo.GetByID(s.ctx, session.ID)
+	assert.Equal(s.T(), 2, found.ViewerCount)
+	assert.Equal(s.T(), 3, found.PeakViewerCount)
+}
+
+func (s *LiveRepositoryTestSuite) TestSetEnded_ComputesDuration() {
+	userID := "550e8400-e29b-41d4-a716-446655440000"
+	session := s.createTestSession(userID, fmt.Sprintf("live_%s_%032x", userID, 127), "Test")
+	session.Status = entity.StatusLive
+	err := s.repo.Create(s.ctx, session)
+	require.NoError(s.T(), err)
+	_, err = s.db.ExecContext(s.ctx, "UPDATE live_sessions SET started_at = $1 WHERE id = $2", time.Now().Add(-90*time.Second), session.ID)
+	require.NoError(s.T(), err)
+
+	err = s.repo.SetEnded(s.ctx, session.ID)
+	require.NoError(s.T(), err)
+
+	found, _ := s.repo.GetByID(s.ctx, session.ID)
+	require.NotNil(s.T(), found.DurationSeconds)
+	assert.InDelta(s.T(), 90, *found.DurationSeconds, 2)
+}
+
+func (s *LiveRepositoryTestSuite) TestSetEnded_ClearsViewers() {
+	userID := "550e8400-e29b-41d4-a716-446655440000"
+	session := s.createTestSession(userID, fmt.Sprintf("live_%s_%032x", userID, 125), "Test")
+	session.Status = entity.StatusLive
+	err := s.repo.Create(s.ctx, session)
+	require.NoError(s.T(), err)
+
+	_, _, err = s.repo.AddViewer(s.ctx, session.ID, "client-1")
+	require.NoError(s.T(), err)
+
+	err = s.repo.SetEnded(s.ctx, session.ID)
+	require.NoError(s.T(), err)
+
+	// Late on_stop after the stream ended is a no-op
+	_, removed, err := s.repo.RemoveViewer(s.ctx, session.ID, "client-1")
+	assert.NoError(s.T(), err)
+	assert.False(s.T(), removed)
+
+	found, _ := s.repo.GetByID(s.ctx, session.ID)
+	assert.Equal(s.T(), 0, found.ViewerCount)
+}
+
 func (s *LiveRepositoryTestSuite) TestSetStarted_Success() {
 	userID := "550e8400-e29b-41d4-a716-446655440000"
 	session := s.createTestSession(userID, fmt.Sprintf("live_%s_%032x", userID, 106), "Test")
 	err := s.repo.Create(s.ctx, session)
 	require.NoError(s.T(), err)
 
-	err = s.repo.SetStarted(s.ctx, session.ID)
+	err = s.repo.SetStarted(s.ctx, session.ID, "client-1")
 
 	assert.NoError(s.T(), err)
 
@@ -385,6 +986,78 @@ func (s *LiveRepositoryTestSuite) TestSetStarted_Success() {
 	assert.NotNil(s.T(), found.StartedAt)
 }
 
+func (s *LiveRepositoryTestSuite) TestSetStarted_FromScheduled() {
+	userID := "550e8400-e29b-41d4-a716-446655440000"
+	session := s.createTestSession(userID, fmt.Sprintf("live_%s_%032x", userID, 170), "Test")
+	session.Status = entity.StatusScheduled
+	scheduledAt := time.Now().Add(time.Hour)
+	session.ScheduledStartAt = &scheduledAt
+	require.NoError(s.T(), s.repo.Create(s.ctx, session))
+
+	err := s.repo.SetStarted(s.ctx, session.ID, "client-1")
+	assert.NoError(s.T(), err)
+
+	found, _ := s.repo.GetByID(s.ctx, session.ID)
+	assert.Equal(s.T(), entity.StatusLive, found.Status)
+}
+
+func (s *LiveRepositoryTestSuite) TestExpireScheduled_Success() {
+	userID := "550e8400-e29b-41d4-a716-446655440000"
+	now := time.Now()
+
+	overdue := s.createTestSession(userID, fmt.Sprintf("live_%s_%032x", userID, 171), "Overdue")
+	overdue.Status = entity.StatusScheduled
+	overdueAt := now.Add(-time.Hour)
+	overdue.ScheduledStartAt = &overdueAt
+	require.NoError(s.T(), s.repo.Create(s.ctx, overdue))
+
+	upcoming := s.createTestSession(userID, fmt.Sprintf("live_%s_%032x", userID, 172), "Upcoming")
+	upcoming.Status = entity.StatusScheduled
+	upcomingAt := now.Add(time.Hour)
+	upcoming.ScheduledStartAt = &upcomingAt
+	require.NoError(s.T(), s.repo.Create(s.ctx, upcoming))
+
+	expired, err := s.repo.ExpireScheduled(s.ctx, now.Add(-30*time.Minute))
+	require.NoError(s.T(), err)
+	assert.Equal(s.T(), int64(1), expired)
+
+	found, _ := s.repo.GetByID(s.ctx, overdue.ID)
+	assert.Equal(s.T(), entity.StatusExpired, found.Status)
+	found, _ = s.repo.GetByID(s.ctx, upcoming.ID)
+	assert.Equal(s.T(), entity.StatusScheduled, found.Status)
+
+	// Expired streams can no longer go live
+	err = s.repo.SetStarted(s.ctx, overdue.ID, "client-1")
+	assert.ErrorIs(s.T(), err, ErrInvalidStatus)
+}
+
+func (s *LiveRepositoryTestSuite) TestSetThumbnail_OnlyOnceWhileLive() {
+	userID := "550e8400-e29b-41d4-a716-446655440000"
+	session := s.createTestSession(userID, fmt.Sprintf("live_%s_%032x", userID, 200), "Thumbnail")
+	require.NoError(s.T(), s.repo.Create(s.ctx, session))
+
+	// Not live yet: no snapshot to point at
+	updated, err := s.repo.SetThumbnail(s.ctx, session.ID, "https://cdn.test/live/first.jpg")
+	require.NoError(s.T(), err)
+	assert.False(s.T(), updated)
+
+	require.NoError(s.T(), s.repo.SetStarted(s.ctx, session.ID, "client-1"))
+
+	updated, err = s.repo.SetThumbnail(s.ctx, session.ID, "https://cdn.test/live/first.jpg")
+	require.NoError(s.T(), err)
+	assert.True(s.T(), updated)
+
+	// Later segments don't overwrite it
+	updated, err = s.repo.SetThumbnail(s.ctx, session.ID, "https://cdn.test/live/second.jpg")
+	require.NoError(s.T(), err)
+	assert.False(s.T(), updated)
+
+	found, err := s.repo.GetByID(s.ctx, session.ID)
+	require.NoError(s.T(), err)
+	require.NotNil(s.T(), found.ThumbnailURL)
+	assert.Equal(s.T(), "https://cdn.test/live/first.jpg", *found.ThumbnailURL)
+}
+
 func (s *LiveRepositoryTestSuite) TestSetStarted_InvalidStatus() {
 	userID := "550e8400-e29b-41d4-a716-446655440000"
 	session := s.createTestSession(userID, fmt.Sprintf("live_%s_%032x", userID, 107), "Test")
@@ -392,7 +1065,7 @@ func (s *LiveRepositoryTestSuite) TestSetStarted_InvalidStatus() {
 	err := s.repo.Create(s.ctx, session)
 	require.NoError(s.T(), err)
 
-	err = s.repo.SetStarted(s.ctx, session.ID)
+	err = s.repo.SetStarted(s.ctx, session.ID, "client-1")
 
 	assert.ErrorIs(s.T(), err, ErrInvalidStatus)
 }
@@ -492,17 +1165,168 @@ func (s *LiveRepositoryTestSuite) TestUpdateViewerCount_NotFound() {
 }
 
 func (s *LiveRepositoryTestSuite) TestIncrementViewerCount_NotFound() {
-	err := s.repo.IncrementViewerCount(s.ctx, "nonexistent_stream_id")
+	_, err := s.repo.IncrementViewerCount(s.ctx, "nonexistent_stream_id")
 
 	assert.ErrorIs(s.T(), err, ErrNotFound)
 }
 
 func (s *LiveRepositoryTestSuite) TestDecrementViewerCount_NotFound() {
-	err := s.repo.DecrementViewerCount(s.ctx, "nonexistent_stream_id")
+	_, err := s.repo.DecrementViewerCount(s.ctx, "nonexistent_stream_id")
 
 	assert.ErrorIs(s.T(), err, ErrNotFound)
 }
 
+// ==================== OUTBOX TESTS ====================
+
+// outboxEvents returns the outbox events of a stream in order
+func (s *LiveRepositoryTestSuite) outboxEvents(streamID string) []entity.StreamEvent {
+	var events []entity.StreamEvent
+	err := s.db.SelectContext(s.ctx, &events, `
+		SELECT id, event_id, event_type, stream_id, payload, created_at, retry_count
+		FROM stream_outbox WHERE stream_id = $1 ORDER BY id`, streamID)
+	require.NoError(s.T(), err)
+	return events
+}
+
+func (s *LiveRepositoryTestSuite) TestSetStartedAndEnded_WriteOutboxEvents() {
+	userID := "550e8400-e29b-41d4-a716-446655440000"
+	session := s.createTestSession(userID, fmt.Sprintf("live_%s_%032x", userID, 140), "Launch Party")
+	require.NoError(s.T(), s.repo.Create(s.ctx, session))
+
+	require.NoError(s.T(), s.repo.SetStarted(s.ctx, session.ID, "client-1"))
+	require.NoError(s.T(), s.repo.SetEnded(s.ctx, session.ID))
+
+	events := s.outboxEvents(session.ID)
+	require.Len(s.T(), events, 2)
+	assert.Equal(s.T(), entity.EventStreamStarted, events[0].EventType)
+	assert.Equal(s.T(), entity.EventStreamEnded, events[1].EventType)
+	assert.NotEqual(s.T(), events[0].EventID, events[1].EventID)
+
+	var started map[string]interface{}
+	require.NoError(s.T(), json.Unmarshal(events[0].Payload, &started))
+	assert.Equal(s.T(), session.ID, started["stream_id"])
+	assert.Equal(s.T(), userID, started["user_id"])
+	assert.Equal(s.T(), "Launch Party", started["title"])
+	assert.Equal(s.T(), "LIVE", started["status"])
+	assert.NotNil(s.T(), started["started_at"])
+
+	var ended map[string]interface{}
+	require.NoError(s.T(), json.Unmarshal(events[1].Payload, &ended))
+	assert.Equal(s.T(), "ENDED", ended["status"])
+	assert.NotNil(s.T(), ended["ended_at"])
+	assert.Contains(s.T(), ended, "duration_seconds")
+	assert.Contains(s.T(), ended, "peak_viewer_count")
+}
+
+func (s *LiveRepositoryTestSuite) TestSetStarted_RejectedTransitionWritesNoEvent() {
+	userID := "550e8400-e29b-41d4-a716-446655440000"
+	session := s.createTestSession(userID, fmt.Sprintf("live_%s_%032x", userID, 141), "Test")
+	session.Status = entity.StatusEnded
+	require.NoError(s.T(), s.repo.Create(s.ctx, session))
+
+	assert.ErrorIs(s.T(), s.repo.SetStarted(s.ctx, session.ID, "client-1"), ErrInvalidStatus)
+	assert.ErrorIs(s.T(), s.repo.SetEnded(s.ctx, session.ID), ErrInvalidStatus)
+	assert.Empty(s.T(), s.outboxEvents(session.ID))
+}
+
+func (s *LiveRepositoryTestSuite) TestRelayPending_PublishesInOrder() {
+	outbox := NewOutboxRepository(s.db)
+	userID := "550e8400-e29b-41d4-a716-446655440000"
+	session := s.createTestSession(userID, fmt.Sprintf("live_%s_%032x", userID, 142), "Test")
+	require.NoError(s.T(), s.repo.Create(s.ctx, session))
+	require.NoError(s.T(), s.repo.SetStarted(s.ctx, session.ID, "client-1"))
+	require.NoError(s.T(), s.repo.SetEnded(s.ctx, session.ID))
+
+	var delivered []string
+	published, failed, err := outbox.RelayPending(s.ctx, 10, 3, time.Second, func(ctx context.Context, event entity.StreamEvent) error {
+		delivered = append(delivered, event.EventType)
+		return nil
+	})
+	require.NoError(s.T(), err)
+	assert.Equal(s.T(), 2, published)
+	assert.Zero(s.T(), failed)
+	assert.Equal(s.T(), []string{entity.EventStreamStarted, entity.EventStreamEnded}, delivered)
+
+	// Published events are not relayed again
+	published, _, err = outbox.RelayPending(s.ctx, 10, 3, time.Second, func(ctx context.Context, event entity.StreamEvent) error {
+		s.T().Fatalf("unexpected redelivery of %s", event.EventType)
+		return nil
+	})
+	require.NoError(s.T(), err)
+	assert.Zero(s.T(), published)
+
+	// Pruned once past the retention
+	pruned, err := outbox.PrunePublished(s.ctx, time.Now().Add(time.Minute))
+	require.NoError(s.T(), err)
+	assert.Equal(s.T(), int64(2), pruned)
+}
+
+func (s *LiveRepositoryTestSuite) TestRelayPending_FailureHoldsBackLaterEvents() {
+	outbox := NewOutboxRepository(s.db)
+	userID := "550e8400-e29b-41d4-a716-446655440000"
+	session := s.createTestSession(userID, fmt.Sprintf("live_%s_%032x", userID, 143), "Test")
+	require.NoError(s.T(), s.repo.Create(s.ctx, session))
+	require.NoError(s.T(), s.repo.SetStarted(s.ctx, session.ID, "client-1"))
+	require.NoError(s.T(), s.repo.SetEnded(s.ctx, session.ID))
+
+	failing := func(ctx context.Context, event entity.StreamEvent) error {
+		return errors.New("bus unavailable")
+	}
+	published, failed, err := outbox.RelayPending(s.ctx, 10, 2, time.Hour, failing)
+	require.NoError(s.T(), err)
+	assert.Zero(s.T(), published)
+	assert.Equal(s.T(), 1, failed, "stream.ended waits for stream.started")
+
+	// stream.started is backing off, so nothing of the stream is deliverable
+	published, failed, err = outbox.RelayPending(s.ctx, 10, 2, time.Hour, failing)
+	require.NoError(s.T(), err)
+	assert.Zero(s.T(), published+failed)
+
+	events := s.outboxEvents(session.ID)
+	assert.Equal(s.T(), 1, events[0].RetryCount)
+	assert.Zero(s.T(), events[1].RetryCount)
+
+	// After the last retry stream.started is given up and no longer holds back stream.ended
+	_, err = s.db.ExecContext(s.ctx, "UPDATE stream_outbox SET retry_count = 2 WHERE id = $1", events[0].ID)
+	require.NoError(s.T(), err)
+	var delivered []string
+	published, _, err = outbox.RelayPending(s.ctx, 10, 2, time.Hour, func(ctx context.Context, event entity.StreamEvent) error {
+		delivered = append(delivered, event.EventType)
+		return nil
+	})
+	require.NoError(s.T(), err)
+	assert.Equal(s.T(), 1, published)
+	assert.Equal(s.T(), []string{entity.EventStreamEnded}, delivered)
+}
+
+func (s *LiveRepositoryTestSuite) TestSetStartedByCohost_WritesJoinedEvent() {
+	userID := "550e8400-e29b-41d4-a716-446655440000"
+	cohostID := "7c9e6679-7425-40de-944b-e07fc1f90ae7"
+	session := s.createTestSession(userID, fmt.Sprintf("live_%s_%032x", userID, 142), "Guest Night")
+	require.NoError(s.T(), s.repo.Create(s.ctx, session))
+
+	require.NoError(s.T(), s.repo.SetStartedByCohost(s.ctx, session.ID, "client-1", cohostID))
+
+	updated, err := s.repo.GetByID(s.ctx, session.ID)
+	require.NoError(s.T(), err)
+	assert.Equal(s.T(), entity.StatusLive, updated.Status)
+
+	events := s.outboxEvents(session.ID)
+	require.Len(s.T(), events, 2)
+	assert.Equal(s.T(), entity.EventStreamStarted, events[0].EventType)
+	assert.Equal(s.T(), entity.EventCohostJoined, events[1].EventType)
+
+	var joined map[string]interface{}
+	require.NoError(s.T(), json.Unmarshal(events[1].Payload, &joined))
+	assert.Equal(s.T(), session.ID, joined["stream_id"])
+	assert.Equal(s.T(), userID, joined["owner_id"])
+	assert.Equal(s.T(), cohostID, joined["user_id"])
+
+	// Already LIVE: nothing is written
+	assert.ErrorIs(s.T(), s.repo.SetStartedByCohost(s.ctx, session.ID, "client-2", cohostID), ErrInvalidStatus)
+	assert.Len(s.T(), s.outboxEvents(session.ID), 2)
+}
+
 // ==================== RUN SUITE ====================
 
 func TestLiveRepositorySuite(t *testing.T) {
@@ -0,0 +1,111 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"live-service/internal/entity"
+
+	"github.com/jmoiron/sqlx"
+)
+
+// PublishFunc delivers one outbox event to downstream services
+type PublishFunc func(ctx context.Context, event entity.StreamEvent) error
+
+// OutboxRepository relays stream outbox events (written by SetStarted/SetEnded)
+type OutboxRepository interface {
+	// RelayPending passes up to limit deliverable events to publish in outbox order and records the outcome
+	// Returns the number of published and failed events
+	RelayPending(ctx context.Context, limit, maxRetries int, baseBackoff time.Duration, publish PublishFunc) (int, int, error)
+	// PrunePublished deletes events published before the given time
+	PrunePublished(ctx context.Context, before time.Time) (int64, error)
+}
+
+type outboxRepository struct {
+	db *sqlx.DB
+}
+
+// NewOutboxRepository creates a new OutboxRepository instance
+func NewOutboxRepository(db *sqlx.DB) OutboxRepository {
+	return &outboxRepository{db: db}
+}
+
+// RelayPending locks the next deliverable events (SKIP LOCKED, so several replicas can relay at once),
+// publishes them one by one and settles each in the same transaction.
+// An event is deliverable when it has retries left and its backoff (baseBackoff doubled per failure) has passed.
+// Events of a stream never overtake each other: while an earlier event of the stream is pending,
+// later ones wait, so consumers never see stream.ended before stream.started.
+// Events that used up maxRetries stay in the table (published_at NULL) with their last error for inspection.
+func (r *outboxRepository) RelayPending(ctx context.Context, limit, maxRetries int, baseBackoff time.Duration, publish PublishFunc) (int, int, error) {
+	tx, err := r.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return 0, 0, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	query := `
+		SELECT id, event_id, event_type, stream_id, payload, created_at, retry_count
+		FROM stream_outbox o
+		WHERE published_at IS NULL AND retry_count < $2
+			AND (last_retry_at IS NULL OR last_retry_at <= NOW() - make_interval(secs => $3 * power(2, retry_count - 1)))
+			AND NOT EXISTS (
+				SELECT 1 FROM stream_outbox earlier
+				WHERE earlier.stream_id = o.stream_id AND earlier.id < o.id
+					AND earlier.published_at IS NULL AND earlier.retry_count < $2
+			)
+		ORDER BY id
+		LIMIT $1
+		FOR UPDATE SKIP LOCKED`
+
+	var events []entity.StreamEvent
+	if err := tx.SelectContext(ctx, &events, query, limit, maxRetries, baseBackoff.Seconds()); err != nil {
+		return 0, 0, fmt.Errorf("failed to fetch pending events: %w", err)
+	}
+
+	published, failed := 0, 0
+	blocked := make(map[string]bool) // streams with a failed event in this batch
+	for _, event := range events {
+		if blocked[event.StreamID] {
+			continue
+		}
+
+		if publishErr := publish(ctx, event); publishErr != nil {
+			blocked[event.StreamID] = true
+			failed++
+			_, err := tx.ExecContext(ctx, `
+				UPDATE stream_outbox
+				SET retry_count = retry_count + 1, last_retry_at = NOW(), last_error = $2
+				WHERE id = $1`, event.ID, publishErr.Error())
+			if err != nil {
+				return 0, 0, fmt.Errorf("failed to record failed event: %w", err)
+			}
+			continue
+		}
+
+		published++
+		if _, err := tx.ExecContext(ctx, "UPDATE stream_outbox SET published_at = NOW() WHERE id = $1", event.ID); err != nil {
+			return 0, 0, fmt.Errorf("failed to mark event published: %w", err)
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return 0, 0, fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	return published, failed, nil
+}
+
+func (r *outboxRepository) PrunePublished(ctx context.Context, before time.Time) (int64, error) {
+	result, err := r.db.ExecContext(ctx, "DELETE FROM stream_outbox WHERE published_at < $1", before)
+	if err != nil {
+		return 0, fmt.Errorf("failed to prune published events: %w", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return 0, fmt.Errorf("failed to get rows affected: %w", err)
+	}
+
+	return rowsAffected, nil
+}
@@ -5,51 +5,149 @@ import (
 	"errors"
 	"fmt"
 	"log"
+	"time"
 
 	"live-service/internal/config"
 	"live-service/internal/entity"
+	"live-service/internal/presence"
 	"live-service/internal/repository"
 	"live-service/pkg/utils"
 )
 
+// MaxCohosts is the number of co-hosts a stream may have
+const MaxCohosts = 5
+
 // Service errors
 var (
 	ErrStreamKeyGeneration = fmt.Errorf("failed to generate stream key")
 	ErrStreamCreation      = fmt.Errorf("failed to create stream")
 	ErrInvalidStreamKey    = fmt.Errorf("invalid stream key")
+	ErrLegacyStreamKey     = fmt.Errorf("%w: legacy key format, rotate the stream key", ErrInvalidStreamKey)
 	ErrStreamNotFound      = fmt.Errorf("stream not found")
 	ErrInvalidTransition   = fmt.Errorf("invalid status transition")
 	ErrDuplicatePublish    = fmt.Errorf("stream already publishing")
 	ErrStreamAlreadyEnded  = fmt.Errorf("stream already ended")
+	ErrNotStreamOwner      = fmt.Errorf("not the stream owner")
+	ErrStreamIsLive        = fmt.Errorf("stream is live")
+	ErrInvalidSchedule     = fmt.Errorf("scheduled_start_at must be in the future")
+	ErrStreamExpired       = fmt.Errorf("scheduled stream expired")
+	ErrUserBanned          = fmt.Errorf("user is banned")
+	ErrCannotBanOwner      = fmt.Errorf("stream owner cannot ban themselves")
+	ErrPresenceDisabled    = fmt.Errorf("viewer presence is not enabled")
+	ErrNotWatching         = fmt.Errorf("viewer is not watching this stream")
+	ErrNotCohost           = fmt.Errorf("not a co-host of the stream")
+	ErrCohostNotFound      = fmt.Errorf("co-host not found")
+	ErrCannotCohostOwner   = fmt.Errorf("stream owner cannot be their own co-host")
+	ErrTooManyCohosts      = fmt.Errorf("too many co-hosts")
+	ErrCohostBanned        = fmt.Errorf("user is banned from this stream")
 )
 
 type LiveService interface {
 	CreateStream(ctx context.Context, userID string, req *entity.CreateStreamRequest) (*entity.CreateStreamResponse, error)
 	GetStreamDetail(ctx context.Context, id string, userID string) (*entity.StreamDetailResponse, error)
-	ListStreams(ctx context.Context, params entity.PaginationParams) (*entity.ListStreamsResponse, error)
+	ListStreams(ctx context.Context, params entity.ListStreamsParams) (*entity.ListStreamsResponse, error)
+	SearchStreams(ctx context.Context, params entity.SearchStreamsParams) (*entity.ListStreamsResponse, error)
 	GetWebRTCInfo(ctx context.Context, id string, userID string) (*entity.WebRTCInfoResponse, error)
+	RotateStreamKey(ctx context.Context, id string, userID string) (*entity.RotateStreamKeyResponse, error)
+	// Moderation: owners ban viewers from their stream chat
+	BanViewer(ctx context.Context, id string, ownerID string, req *entity.BanViewerRequest) (*entity.ViewerBanListResponse, error)
+	IsViewerBanned(ctx context.Context, id string, userID string) (bool, error)
+	// Co-hosts: owners let other users publish to their stream with their own token
+	AddCohost(ctx context.Context, id string, ownerID string, req *entity.AddCohostRequest) (*entity.CohostListResponse, error)
+	RemoveCohost(ctx context.Context, id string, ownerID string, userID string) (*entity.CohostListResponse, error)
+	GetCohostCredentials(ctx context.Context, id string, userID string) (*entity.CohostCredentialsResponse, error)
+	// Presence: owners list who is watching; viewers heartbeat to stay listed
+	GetStreamViewers(ctx context.Context, id string, ownerID string, params entity.ListStreamViewersParams) (*entity.StreamViewersResponse, error)
+	HeartbeatViewer(ctx context.Context, id string, userID string) error
 	// Webhook handlers
 	// streamID: the stream ID (NanoID)
-	// token: the secret stream key from ?token= param (e.g., "sk_abc123")
-	HandleOnPublish(ctx context.Context, streamID string, token string) error
+	// token: the secret stream key or a co-host token from ?token= param (e.g., "live_Xk3fQ9bT2mWz...")
+	// clientID: the SRS client_id of the publisher (used to accept callback retries)
+	HandleOnPublish(ctx context.Context, streamID string, token string, clientID string) error
 	HandleOnUnpublish(ctx context.Context, streamID string) error
+	// clientID: the SRS client_id of the viewer connection
+	// userID: the signed-in viewer from ?user_id= param, empty for anonymous viewers
+	HandleOnPlay(ctx context.Context, streamID string, clientID string, userID string) error
+	HandleOnStop(ctx context.Context, streamID string, clientID string, userID string) error
+	// Called for every HLS segment; records the stream thumbnail once video is flowing
+	HandleOnHLS(ctx context.Context, streamID string) error
+	// Called when SRS closes a DVR file; cwd and file locate it as configured in srs.conf
+	HandleOnDVR(ctx context.Context, streamID string, cwd string, file string) error
+}
+
+// StreamStatsProvider returns per-stream ingest stats from the media server
+type StreamStatsProvider interface {
+	Get(ctx context.Context, app, name string) (*utils.StreamStats, error)
+}
+
+// ChatRoomProvider manages the chat-service conversation backing a stream's live chat
+type ChatRoomProvider interface {
+	OpenRoom(ctx context.Context, streamID, ownerID, title string) (string, error)
+	CloseRoom(ctx context.Context, streamID, ownerID, conversationID string) error
+}
+
+// ViewerKicker disconnects a user from a stream's realtime chat
+type ViewerKicker interface {
+	KickUser(streamID, userID, reason string) int
+}
+
+// PublisherKicker disconnects a publisher from the media server
+type PublisherKicker interface {
+	KickClient(ctx context.Context, clientID string) error
+}
+
+// ViewerPresence tracks which signed-in users are watching each stream
+type ViewerPresence interface {
+	Join(ctx context.Context, streamID, userID string) error
+	Leave(ctx context.Context, streamID, userID string) (bool, error)
+	Heartbeat(ctx context.Context, streamID, userID string) (bool, error)
+	List(ctx context.Context, streamID, after string, limit int) ([]presence.Viewer, int, error)
+	Clear(ctx context.Context, streamID string) error
 }
 
 type liveService struct {
-	repo   repository.LiveRepository
-	config *config.Config
+	repo       repository.LiveRepository
+	config     *config.Config
+	keys       *utils.StreamKeyGenerator
+	stats      StreamStatsProvider // optional, nil disables health in GetStreamDetail
+	chatRooms  ChatRoomProvider    // optional, nil disables stream chat rooms
+	kicker     ViewerKicker        // optional, nil skips disconnecting banned viewers
+	viewers    ViewerPresence      // optional, nil disables the viewer list
+	publishers PublisherKicker     // optional, nil leaves a banned co-host's running publish alone
 }
 
-func NewLiveService(repo repository.LiveRepository, config *config.Config) LiveService {
+func NewLiveService(repo repository.LiveRepository, config *config.Config, stats StreamStatsProvider, chatRooms ChatRoomProvider, kicker ViewerKicker, viewers ViewerPresence, publishers PublisherKicker) LiveService {
+	// config.Validate rejects unusable key settings at startup; fall back to the defaults just in case
+	keys, err := utils.NewStreamKeyGenerator(config.StreamKey.Prefix, config.StreamKey.Charset, config.StreamKey.Length)
+	if err != nil {
+		log.Printf("[stream_key] WARNING: %v, using the default key format", err)
+		keys, _ = utils.NewStreamKeyGenerator(utils.StreamKeyPrefix, "", 0)
+	}
+
 	return &liveService{
-		repo:   repo,
-		config: config,
+		repo:       repo,
+		config:     config,
+		keys:       keys,
+		stats:      stats,
+		chatRooms:  chatRooms,
+		kicker:     kicker,
+		viewers:    viewers,
+		publishers: publishers,
 	}
 }
 
 func (s *liveService) CreateStream(ctx context.Context, userID string, req *entity.CreateStreamRequest) (*entity.CreateStreamResponse, error) {
+	// Scheduled streams are announced ahead of time and go live on first publish
+	status := entity.StatusIdle
+	if req.ScheduledStartAt != nil {
+		if !req.ScheduledStartAt.After(time.Now()) {
+			return nil, ErrInvalidSchedule
+		}
+		status = entity.StatusScheduled
+	}
+
 	// Generate secure stream key (secret token)
-	streamKey, err := utils.GenerateStreamKeyFromUUID(userID)
+	streamKey, err := s.keys.Generate()
 	if err != nil {
 		return nil, fmt.Errorf("%w: %v", ErrStreamKeyGeneration, err)
 	}
@@ -70,8 +168,10 @@ func (s *liveService) CreateStream(ctx context.Context, userID string, req *enti
 		StreamKey:   streamKey,
 		Title:       req.Title,
 		Description: description,
-		Status:      entity.StatusIdle,
+		Status:      status,
 		ViewerCount: 0,
+		// Optional announcement time
+		ScheduledStartAt: req.ScheduledStartAt,
 	}
 
 	// Save to database
@@ -103,6 +203,9 @@ func (s *liveService) CreateStream(ctx context.Context, userID string, req *enti
 		RTMPUrl:   rtmpURL,
 		WebRTCUrl: webrtcURL,
 		HLSUrl:    hlsURL,
+
+		Status:           session.Status,
+		ScheduledStartAt: session.ScheduledStartAt,
 	}, nil
 }
 
@@ -124,20 +227,40 @@ func (s *liveService) GetStreamDetail(ctx context.Context, id string, userID str
 		Title:       session.Title,
 		Description: session.Description,
 		Status:      session.Status,
-		HLSUrl:      session.HLSUrl,
+		HLSUrl:      s.hlsPlaybackURL(session),
 		ViewerCount: session.ViewerCount,
 		StartedAt:   session.StartedAt,
 		EndedAt:     session.EndedAt,
 		CreatedAt:   session.CreatedAt,
 		IsOwner:     isOwner,
+
+		ThumbnailURL: s.thumbnailURL(session),
+
+		ScheduledStartAt: session.ScheduledStartAt,
+
 		// TODO: Populate from user service
 		Username: username,
 		Avatar:   "",
 	}
 
+	// Post-stream stats for creators and viewers once the stream is over
+	if session.Status == entity.StatusEnded {
+		peak := session.PeakViewerCount
+		resp.PeakViewerCount = &peak
+		resp.DurationSeconds = session.DurationSeconds
+		resp.Recording = s.recordingInfo(ctx, session.ID)
+	}
+
+	if session.Status == entity.StatusLive {
+		resp.Health = s.streamHealth(ctx, session.ID)
+		resp.ChatConversationID = session.ChatConversationID
+		resp.ChatAvailable = session.ChatConversationID != nil
+	}
+
 	// Only show sensitive info to owner
 	if isOwner {
 		resp.StreamKey = session.StreamKey
+		resp.StreamKeyOutdated = !s.keys.IsCurrent(session.StreamKey)
 		if session.RTMPUrl != nil {
 			resp.RTMPUrl = *session.RTMPUrl
 		}
@@ -149,20 +272,137 @@ func (s *liveService) GetStreamDetail(ctx context.Context, id string, userID str
 	return resp, nil
 }
 
-func (s *liveService) ListStreams(ctx context.Context, params entity.PaginationParams) (*entity.ListStreamsResponse, error) {
-	// Get live streams with pagination
-	sessions, err := s.repo.ListLive(ctx, params.Limit, params.Offset())
+// recordingInfo lists the VOD parts of a stream with their offsets in the concatenated recording
+// Returns nil when the stream was not recorded; failures are logged and the field is omitted
+func (s *liveService) recordingInfo(ctx context.Context, streamID string) *entity.StreamRecordingInfo {
+	recordings, err := s.repo.ListRecordings(ctx, streamID)
+	if err != nil {
+		log.Printf("[recording] WARNING: failed to list recordings of stream %s: %v", streamID, err)
+		return nil
+	}
+	if len(recordings) == 0 {
+		return nil
+	}
+
+	info := &entity.StreamRecordingInfo{Files: make([]entity.RecordingFile, 0, len(recordings))}
+	for i, recording := range recordings {
+		duration := float64(recording.DurationMs) / 1000
+		info.Files = append(info.Files, entity.RecordingFile{
+			Sequence:        i + 1,
+			URL:             s.config.GetRecordingURL(recording.FilePath),
+			StartedAt:       recording.StartedAt,
+			DurationSeconds: duration,
+			OffsetSeconds:   info.DurationSeconds,
+		})
+		info.DurationSeconds += duration
+	}
+	return info
+}
+
+// streamHealth fetches ingest stats from SRS; failures are logged and the field is omitted
+func (s *liveService) streamHealth(ctx context.Context, streamID string) *entity.StreamHealth {
+	if s.stats == nil {
+		return nil
+	}
+
+	stats, err := s.stats.Get(ctx, s.config.SRSApp(), streamID)
+	if err != nil {
+		if !errors.Is(err, utils.ErrSRSStreamNotFound) {
+			log.Printf("[stream_health] failed to fetch SRS stats for %s: %v", streamID, err)
+		}
+		return nil
+	}
+
+	return &entity.StreamHealth{
+		BitrateKbps: stats.BitrateKbps,
+		FPS:         stats.FPS,
+		VideoCodec:  stats.VideoCodec,
+		AudioCodec:  stats.AudioCodec,
+		Width:       stats.Width,
+		Height:      stats.Height,
+	}
+}
+
+func (s *liveService) ListStreams(ctx context.Context, params entity.ListStreamsParams) (*entity.ListStreamsResponse, error) {
+	var cursor *entity.FeedCursor
+	if params.Cursor != "" {
+		var err error
+		cursor, err = entity.DecodeFeedCursor(params.Cursor)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	// Fetch one extra row to know whether another page exists
+	sessions, err := s.repo.ListFeed(ctx, params.Status, params.Sort, cursor, params.Limit+1)
 	if err != nil {
 		return nil, fmt.Errorf("failed to list streams: %w", err)
 	}
 
-	// Get total count for pagination
-	total, err := s.repo.CountByStatus(ctx, entity.StatusLive)
+	nextCursor := ""
+	if len(sessions) > params.Limit {
+		sessions = sessions[:params.Limit]
+		nextCursor = entity.NewFeedCursor(&sessions[len(sessions)-1]).Encode()
+	}
+
+	// Get total count for the status filter
+	total, err := s.repo.CountByStatus(ctx, params.Status)
 	if err != nil {
 		return nil, fmt.Errorf("failed to count streams: %w", err)
 	}
 
-	// Convert to response format
+	return &entity.ListStreamsResponse{
+		Streams:    s.toStreamInfos(sessions),
+		Total:      total,
+		Limit:      params.Limit,
+		NextCursor: nextCursor,
+	}, nil
+}
+
+// SearchStreams searches public stream titles, paginated like the feed
+// An empty status filter searches every public status
+func (s *liveService) SearchStreams(ctx context.Context, params entity.SearchStreamsParams) (*entity.ListStreamsResponse, error) {
+	var cursor *entity.FeedCursor
+	if params.Cursor != "" {
+		var err error
+		cursor, err = entity.DecodeFeedCursor(params.Cursor)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	statuses := entity.PublicStatuses()
+	if params.Status != "" {
+		statuses = []entity.LiveSessionStatus{params.Status}
+	}
+
+	// Fetch one extra row to know whether another page exists
+	sessions, err := s.repo.SearchFeed(ctx, params.Query, statuses, params.Sort, cursor, params.Limit+1)
+	if err != nil {
+		return nil, fmt.Errorf("failed to search streams: %w", err)
+	}
+
+	nextCursor := ""
+	if len(sessions) > params.Limit {
+		sessions = sessions[:params.Limit]
+		nextCursor = entity.NewFeedCursor(&sessions[len(sessions)-1]).Encode()
+	}
+
+	total, err := s.repo.CountSearch(ctx, params.Query, statuses)
+	if err != nil {
+		return nil, fmt.Errorf("failed to count search results: %w", err)
+	}
+
+	return &entity.ListStreamsResponse{
+		Streams:    s.toStreamInfos(sessions),
+		Total:      total,
+		Limit:      params.Limit,
+		NextCursor: nextCursor,
+	}, nil
+}
+
+// toStreamInfos converts sessions to the public feed representation
+func (s *liveService) toStreamInfos(sessions []entity.LiveSession) []entity.LiveStreamInfo {
 	streams := make([]entity.LiveStreamInfo, len(sessions))
 	for i, session := range sessions {
 		username := "user"
@@ -175,24 +415,20 @@ func (s *liveService) ListStreams(ctx context.Context, params entity.PaginationP
 			Title:       session.Title,
 			Status:      session.Status,
 			ViewerCount: session.ViewerCount,
-			HLSUrl:      session.HLSUrl,
+			HLSUrl:      s.hlsPlaybackURL(&session),
 			StartedAt:   session.StartedAt,
 			CreatedAt:   session.CreatedAt,
+
+			ThumbnailURL: s.thumbnailURL(&session),
+
+			ScheduledStartAt: session.ScheduledStartAt,
+
 			// TODO: Populate from user service
 			Username: username,
 			Avatar:   "",
 		}
 	}
-
-	totalPages := (total + params.Limit - 1) / params.Limit
-
-	return &entity.ListStreamsResponse{
-		Streams:    streams,
-		Total:      total,
-		Page:       params.Page,
-		Limit:      params.Limit,
-		TotalPages: totalPages,
-	}, nil
+	return streams
 }
 
 // GetWebRTCInfo returns WebRTC connection info for a stream
@@ -204,17 +440,6 @@ func (s *liveService) GetWebRTCInfo(ctx context.Context, id string, userID strin
 	}
 
 	isOwner := userID != "" && session.UserID == userID
-	serverIP := s.config.SRS.ServerIP
-	streamID := session.ID
-	streamKey := session.StreamKey
-
-	// Play URL uses stream ID (public, no token needed for viewing)
-	// Format: webrtc://server/live/stream_id
-	playURL := fmt.Sprintf("webrtc://%s/live/%s", serverIP, streamID)
-
-	// WHEP endpoint for viewers (uses stream ID)
-	apiBase := fmt.Sprintf("http://%s:%d", serverIP, s.config.SRS.APIPort)
-	whepEndpoint := fmt.Sprintf("%s/rtc/v1/whep/?app=live&stream=%s", apiBase, streamID)
 
 	// Get ICE servers from config (includes STUN + TURN with dynamic credentials)
 	configICEServers := s.config.GetICEServers()
@@ -230,28 +455,389 @@ func (s *liveService) GetWebRTCInfo(ctx context.Context, id string, userID strin
 	}
 
 	resp := &entity.WebRTCInfoResponse{
-		ID:           session.ID,
-		Status:       session.Status,
-		PlayURL:      playURL,
-		WHEPEndpoint: whepEndpoint,
-		ICEServers:   iceServers,
-		IsOwner:      isOwner,
+		ID:     session.ID,
+		Status: session.Status,
+		// Play URL uses stream ID (public, no token needed for viewing)
+		// Format: webrtc://server/live/stream_id
+		PlayURL: fmt.Sprintf("webrtc://%s/%s/%s", s.config.SRS.ServerIP, s.config.SRSApp(), session.ID),
+		// WHEP endpoint for viewers (uses stream ID)
+		WHEPEndpoint:       s.config.GetWHEPURL(session.ID),
+		ICEServers:         iceServers,
+		ICETransportPolicy: s.config.ICETransportPolicy(),
+		IsOwner:            isOwner,
 	}
 
-	// Only show publish URLs to owner (includes secret token)
-	if isOwner {
-		// Publish URL with token: webrtc://server/live/stream_id?token=stream_key
-		resp.PublishURL = fmt.Sprintf("webrtc://%s/live/%s?token=%s", serverIP, streamID, streamKey)
-		resp.WHIPEndpoint = fmt.Sprintf("%s/rtc/v1/whip/?app=live&stream=%s&token=%s", apiBase, streamID, streamKey)
+	// HLS fallback for players without WebRTC support
+	if hlsURL := s.hlsPlaybackURL(session); hlsURL != nil {
+		resp.HLSUrl = *hlsURL
+	}
+
+	// Only show publish URLs to owner (includes secret token), and only while on_publish would accept them
+	if isOwner && s.canPublish(session) {
+		resp.PublishURL = s.config.GetWebRTCURL(session.ID, session.StreamKey)
+		resp.WHIPEndpoint = s.config.GetWHIPURL(session.ID, session.StreamKey)
 	}
 
 	return resp, nil
 }
 
+// canPublish reports whether the stream could still be published with its current key
+func (s *liveService) canPublish(session *entity.LiveSession) bool {
+	if !s.keyAccepted(session.StreamKey) {
+		return false
+	}
+	switch session.Status {
+	case entity.StatusEnded, entity.StatusExpired:
+		return false
+	default:
+		return true
+	}
+}
+
+// keyAccepted reports whether on_publish accepts a stored key by its format
+// Keys in the current format always are; keys issued before (legacy live_{uuid}_{hex} keys, or another
+// prefix/charset/length) keep working until STREAM_KEY_REJECT_LEGACY is set, giving owners time to rotate
+func (s *liveService) keyAccepted(streamKey string) bool {
+	if s.keys.IsCurrent(streamKey) {
+		return true
+	}
+	return !s.config.StreamKey.RejectLegacy && streamKey != ""
+}
+
+// RotateStreamKey issues a new stream key for a non-live stream owned by userID
+// The old key stops working immediately: on_publish compares against the stored key
+func (s *liveService) RotateStreamKey(ctx context.Context, id string, userID string) (*entity.RotateStreamKeyResponse, error) {
+	session, err := s.repo.GetByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	if session.UserID != userID {
+		return nil, ErrNotStreamOwner
+	}
+	if session.Status == entity.StatusLive {
+		return nil, ErrStreamIsLive
+	}
+
+	streamKey, err := s.keys.Generate()
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrStreamKeyGeneration, err)
+	}
+
+	rtmpURL := s.config.GetRTMPURL(session.ID, streamKey)
+	webrtcURL := s.config.GetWebRTCURL(session.ID, streamKey)
+
+	if err := s.repo.RotateStreamKey(ctx, session.ID, streamKey, rtmpURL, webrtcURL); err != nil {
+		if errors.Is(err, repository.ErrInvalidStatus) {
+			// Went LIVE between the read and the update
+			return nil, ErrStreamIsLive
+		}
+		return nil, err
+	}
+
+	log.Printf("[RotateStreamKey] stream %s key rotated (user: %s, old: %s)", session.ID, userID, utils.MaskStreamKey(session.StreamKey))
+
+	return &entity.RotateStreamKeyResponse{
+		ID:        session.ID,
+		StreamKey: streamKey,
+		RTMPUrl:   rtmpURL,
+		WebRTCUrl: webrtcURL,
+	}, nil
+}
+
+// hlsPlaybackURL returns the HLS .m3u8 URL for a session, or nil if the stream is not LIVE
+// SRS only writes the playlist while publishing, so IDLE/ENDED streams have nothing to play
+func (s *liveService) hlsPlaybackURL(session *entity.LiveSession) *string {
+	if session.Status != entity.StatusLive {
+		return nil
+	}
+	hlsURL := s.config.GetHLSURL(session.ID)
+	return &hlsURL
+}
+
+// thumbnailURL returns the captured snapshot, falling back to the placeholder
+func (s *liveService) thumbnailURL(session *entity.LiveSession) string {
+	if session.ThumbnailURL != nil {
+		return *session.ThumbnailURL
+	}
+	return s.config.Thumbnail.PlaceholderURL
+}
+
+// BanViewer bans a viewer from a stream owned by ownerID and kicks them from the stream chat
+// A banned co-host loses their publish rights and is disconnected if they are publishing
+// Returns the updated ban list
+func (s *liveService) BanViewer(ctx context.Context, id string, ownerID string, req *entity.BanViewerRequest) (*entity.ViewerBanListResponse, error) {
+	session, err := s.repo.GetByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	if session.UserID != ownerID {
+		return nil, ErrNotStreamOwner
+	}
+	if req.UserID == ownerID {
+		return nil, ErrCannotBanOwner
+	}
+
+	if err := s.repo.BanViewer(ctx, session.ID, req.UserID, req.Reason); err != nil {
+		return nil, err
+	}
+
+	if s.kicker != nil {
+		s.kicker.KickUser(session.ID, req.UserID, "banned from this stream")
+	}
+	s.kickCohostPublisher(ctx, session.ID, req.UserID)
+
+	log.Printf("[BanViewer] user %s banned from stream %s (owner: %s)", req.UserID, session.ID, ownerID)
+
+	bans, err := s.repo.ListViewerBans(ctx, session.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	return &entity.ViewerBanListResponse{
+		StreamID: session.ID,
+		Bans:     bans,
+	}, nil
+}
+
+// kickCohostPublisher disconnects userID from SRS if they are the co-host publishing the stream
+// The session is read again after the ban so a publish that started meanwhile is cut too
+// SRS then fires on_unpublish, which ends the stream as usual
+func (s *liveService) kickCohostPublisher(ctx context.Context, id string, userID string) {
+	if s.publishers == nil {
+		return
+	}
+
+	session, err := s.repo.GetByID(ctx, id)
+	if err != nil {
+		log.Printf("[BanViewer] WARNING: failed to check the publisher of stream %s: %v", id, err)
+		return
+	}
+	if session.Status != entity.StatusLive || session.PublisherClientID == nil ||
+		session.PublisherCohostID == nil || *session.PublisherCohostID != userID {
+		return
+	}
+
+	if err := s.publishers.KickClient(ctx, *session.PublisherClientID); err != nil {
+		log.Printf("[BanViewer] WARNING: failed to disconnect co-host %s publishing stream %s: %v", userID, session.ID, err)
+		return
+	}
+	log.Printf("[BanViewer] co-host %s disconnected from stream %s (client: %s)", userID, session.ID, *session.PublisherClientID)
+}
+
+// IsViewerBanned reports whether userID is banned from the stream's chat
+func (s *liveService) IsViewerBanned(ctx context.Context, id string, userID string) (bool, error) {
+	return s.repo.IsViewerBanned(ctx, id, userID)
+}
+
+// AddCohost lets req.UserID publish to a stream owned by ownerID
+// Returns the updated co-host list; the co-host fetches their token with GetCohostCredentials
+func (s *liveService) AddCohost(ctx context.Context, id string, ownerID string, req *entity.AddCohostRequest) (*entity.CohostListResponse, error) {
+	session, err := s.repo.GetByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	if session.UserID != ownerID {
+		return nil, ErrNotStreamOwner
+	}
+	if req.UserID == ownerID {
+		return nil, ErrCannotCohostOwner
+	}
+	switch session.Status {
+	case entity.StatusEnded:
+		return nil, ErrStreamAlreadyEnded
+	case entity.StatusExpired:
+		return nil, ErrStreamExpired
+	}
+
+	// Users banned from the stream can't publish to it
+	banned, err := s.repo.IsViewerBanned(ctx, session.ID, req.UserID)
+	if err != nil {
+		return nil, err
+	}
+	if banned {
+		return nil, ErrCohostBanned
+	}
+
+	cohosts, err := s.repo.ListCohosts(ctx, session.ID)
+	if err != nil {
+		return nil, err
+	}
+	for _, c := range cohosts {
+		if c.UserID == req.UserID {
+			// Already a co-host: keep their token
+			return &entity.CohostListResponse{StreamID: session.ID, Cohosts: cohosts}, nil
+		}
+	}
+	if len(cohosts) >= MaxCohosts {
+		return nil, ErrTooManyCohosts
+	}
+
+	token, err := s.keys.Generate()
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrStreamKeyGeneration, err)
+	}
+	if err := s.repo.AddCohost(ctx, session.ID, req.UserID, token); err != nil {
+		return nil, err
+	}
+
+	log.Printf("[AddCohost] user %s may publish to stream %s (owner: %s)", req.UserID, session.ID, ownerID)
+
+	return s.cohostList(ctx, session.ID)
+}
+
+// RemoveCohost revokes a co-host's publish rights
+// An ongoing publish is not cut; the co-host just can't publish again
+func (s *liveService) RemoveCohost(ctx context.Context, id string, ownerID string, userID string) (*entity.CohostListResponse, error) {
+	session, err := s.repo.GetByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	if session.UserID != ownerID {
+		return nil, ErrNotStreamOwner
+	}
+
+	if err := s.repo.RemoveCohost(ctx, session.ID, userID); err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			return nil, ErrCohostNotFound
+		}
+		return nil, err
+	}
+
+	log.Printf("[RemoveCohost] user %s may no longer publish to stream %s (owner: %s)", userID, session.ID, ownerID)
+
+	return s.cohostList(ctx, session.ID)
+}
+
+func (s *liveService) cohostList(ctx context.Context, streamID string) (*entity.CohostListResponse, error) {
+	cohosts, err := s.repo.ListCohosts(ctx, streamID)
+	if err != nil {
+		return nil, err
+	}
+	return &entity.CohostListResponse{StreamID: streamID, Cohosts: cohosts}, nil
+}
+
+// GetCohostCredentials returns the publish URLs of userID, a co-host of the stream
+func (s *liveService) GetCohostCredentials(ctx context.Context, id string, userID string) (*entity.CohostCredentialsResponse, error) {
+	session, err := s.repo.GetByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	cohosts, err := s.repo.ListCohosts(ctx, session.ID)
+	if err != nil {
+		return nil, err
+	}
+	for _, c := range cohosts {
+		if c.UserID != userID {
+			continue
+		}
+		return &entity.CohostCredentialsResponse{
+			ID:           session.ID,
+			Status:       session.Status,
+			Token:        c.Token,
+			RTMPUrl:      s.config.GetRTMPURL(session.ID, c.Token),
+			WebRTCUrl:    s.config.GetWebRTCURL(session.ID, c.Token),
+			WHIPEndpoint: s.config.GetWHIPURL(session.ID, c.Token),
+		}, nil
+	}
+
+	return nil, ErrNotCohost
+}
+
+// matchCohost returns the co-host whose token is token, or "" if none matches
+// Every token is compared in constant time, the token is attacker controlled
+func (s *liveService) matchCohost(ctx context.Context, streamID string, token string) (string, error) {
+	cohosts, err := s.repo.ListCohosts(ctx, streamID)
+	if err != nil {
+		return "", err
+	}
+
+	cohostID := ""
+	for _, c := range cohosts {
+		if utils.StreamKeysEqual(c.Token, token) {
+			cohostID = c.UserID
+		}
+	}
+	return cohostID, nil
+}
+
+// GetStreamViewers lists the signed-in viewers of a stream owned by ownerID
+func (s *liveService) GetStreamViewers(ctx context.Context, id string, ownerID string, params entity.ListStreamViewersParams) (*entity.StreamViewersResponse, error) {
+	if s.viewers == nil {
+		return nil, ErrPresenceDisabled
+	}
+
+	if params.Limit <= 0 || params.Limit > 100 {
+		params.Limit = 20
+	}
+	after := ""
+	if params.Cursor != "" {
+		var err error
+		if after, err = entity.DecodeViewerCursor(params.Cursor); err != nil {
+			return nil, err
+		}
+	}
+
+	session, err := s.repo.GetByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if session.UserID != ownerID {
+		return nil, ErrNotStreamOwner
+	}
+
+	// Fetch one extra to know whether there is a next page
+	listed, total, err := s.viewers.List(ctx, session.ID, after, params.Limit+1)
+	if err != nil {
+		return nil, err
+	}
+
+	nextCursor := ""
+	if len(listed) > params.Limit {
+		listed = listed[:params.Limit]
+		nextCursor = entity.EncodeViewerCursor(listed[len(listed)-1].UserID)
+	}
+
+	viewers := make([]entity.StreamViewer, 0, len(listed))
+	for _, v := range listed {
+		viewers = append(viewers, entity.StreamViewer{UserID: v.UserID, LastSeenAt: v.LastSeenAt})
+	}
+
+	return &entity.StreamViewersResponse{
+		StreamID:   session.ID,
+		Viewers:    viewers,
+		Total:      total,
+		Limit:      params.Limit,
+		NextCursor: nextCursor,
+	}, nil
+}
+
+// HeartbeatViewer keeps userID on the stream's viewer list while they keep watching
+// Players send it more often than the presence TTL, so viewers whose on_stop is missed expire
+func (s *liveService) HeartbeatViewer(ctx context.Context, id string, userID string) error {
+	if s.viewers == nil {
+		return ErrPresenceDisabled
+	}
+
+	listed, err := s.viewers.Heartbeat(ctx, id, userID)
+	if err != nil {
+		return err
+	}
+	if !listed {
+		return ErrNotWatching
+	}
+	return nil
+}
+
 // HandleOnPublish validates stream credentials and updates session status to LIVE
-// New auth flow: streamID (NanoID) + token (from ?token= param)
+// New auth flow: streamID (NanoID) + token (from ?token= param), the token being the stream key or a co-host token
 // Fallback: streamID only (treated as stream_key for backward compatibility)
-func (s *liveService) HandleOnPublish(ctx context.Context, streamID string, token string) error {
+// SRS retries callbacks on timeout: a LIVE stream re-published by the same client_id is accepted as a no-op
+func (s *liveService) HandleOnPublish(ctx context.Context, streamID string, token string, clientID string) error {
 	if streamID == "" {
 		log.Printf("[on_publish] ERROR: empty stream ID")
 		return ErrInvalidStreamKey
@@ -259,6 +845,8 @@ func (s *liveService) HandleOnPublish(ctx context.Context, streamID string, toke
 
 	var session *entity.LiveSession
 	var err error
+	// Set when a co-host publishes instead of the owner
+	var cohostID string
 
 	// New auth flow: streamID is NanoID, token is the secret key
 	if token != "" {
@@ -273,14 +861,23 @@ func (s *liveService) HandleOnPublish(ctx context.Context, streamID string, toke
 			return fmt.Errorf("database error: %w", err)
 		}
 
-		// Validate token matches stream_key
-		if session.StreamKey != token {
-			maskedToken := utils.MaskStreamKey(token)
-			log.Printf("[on_publish] REJECTED: invalid token for stream %s (token: %s)", streamID, maskedToken)
-			return fmt.Errorf("%w: invalid token", ErrInvalidStreamKey)
+		// Validate token matches stream_key (constant time, the token is attacker controlled)
+		if utils.StreamKeysEqual(session.StreamKey, token) {
+			log.Printf("[on_publish] Token auth: stream %s validated", streamID)
+		} else {
+			// Not the owner's key: it may be one of the co-host tokens
+			cohostID, err = s.matchCohost(ctx, session.ID, token)
+			if err != nil {
+				log.Printf("[on_publish] ERROR: co-host lookup failed for stream %s: %v", streamID, err)
+				return fmt.Errorf("database error: %w", err)
+			}
+			if cohostID == "" {
+				maskedToken := utils.MaskStreamKey(token)
+				log.Printf("[on_publish] REJECTED: invalid token for stream %s (token: %s)", streamID, maskedToken)
+				return fmt.Errorf("%w: invalid token", ErrInvalidStreamKey)
+			}
+			log.Printf("[on_publish] Co-host auth: stream %s validated (co-host: %s)", streamID, cohostID)
 		}
-
-		log.Printf("[on_publish] Token auth: stream %s validated", streamID)
 	} else {
 		// Fallback: streamID is actually the stream_key (old behavior)
 		maskedKey := utils.MaskStreamKey(streamID)
@@ -296,9 +893,54 @@ func (s *liveService) HandleOnPublish(ctx context.Context, streamID string, toke
 		log.Printf("[on_publish] Legacy auth: stream %s (key: %s)", session.ID, maskedKey)
 	}
 
+	// Keys issued before the current key format are phased out (co-host tokens are checked above)
+	if cohostID == "" && !s.keys.IsCurrent(session.StreamKey) {
+		if !s.keyAccepted(session.StreamKey) {
+			log.Printf("[on_publish] REJECTED: legacy stream key for stream %s, owner must rotate it", session.ID)
+			return ErrLegacyStreamKey
+		}
+		log.Printf("[on_publish] WARNING: stream %s published with a legacy stream key", session.ID)
+	}
+
+	// Moderation: banned users can't go live even with a valid key
+	banned, err := s.repo.IsUserBanned(ctx, session.UserID)
+	if err != nil {
+		log.Printf("[on_publish] ERROR: ban check failed for user %s: %v", session.UserID, err)
+		return fmt.Errorf("database error: %w", err)
+	}
+	if banned {
+		log.Printf("[on_publish] REJECTED: user %s is banned (stream %s)", session.UserID, session.ID)
+		return fmt.Errorf("%w: %s", ErrUserBanned, session.UserID)
+	}
+	if cohostID != "" {
+		banned, err = s.repo.IsUserBanned(ctx, cohostID)
+		if err != nil {
+			log.Printf("[on_publish] ERROR: ban check failed for co-host %s: %v", cohostID, err)
+			return fmt.Errorf("database error: %w", err)
+		}
+		if banned {
+			log.Printf("[on_publish] REJECTED: co-host %s is banned (stream %s)", cohostID, session.ID)
+			return fmt.Errorf("%w: %s", ErrUserBanned, cohostID)
+		}
+		// The owner may have banned the co-host from this stream after adding them
+		banned, err = s.repo.IsViewerBanned(ctx, session.ID, cohostID)
+		if err != nil {
+			log.Printf("[on_publish] ERROR: stream ban check failed for co-host %s: %v", cohostID, err)
+			return fmt.Errorf("database error: %w", err)
+		}
+		if banned {
+			log.Printf("[on_publish] REJECTED: co-host %s is banned from stream %s", cohostID, session.ID)
+			return fmt.Errorf("%w: %s", ErrUserBanned, cohostID)
+		}
+	}
+
 	// Check current status
 	switch session.Status {
 	case entity.StatusLive:
+		if isSamePublisher(session, clientID) {
+			log.Printf("[on_publish] INFO: retried callback for stream %s (client: %s)", session.ID, clientID)
+			return nil
+		}
 		log.Printf("[on_publish] REJECTED: duplicate publish for stream %s", session.ID)
 		return fmt.Errorf("%w: stream %s is already live", ErrDuplicatePublish, session.ID)
 
@@ -306,7 +948,11 @@ func (s *liveService) HandleOnPublish(ctx context.Context, streamID string, toke
 		log.Printf("[on_publish] REJECTED: stream %s already ended", session.ID)
 		return fmt.Errorf("%w: stream %s has ended", ErrStreamAlreadyEnded, session.ID)
 
-	case entity.StatusIdle:
+	case entity.StatusExpired:
+		log.Printf("[on_publish] REJECTED: scheduled stream %s expired", session.ID)
+		return fmt.Errorf("%w: stream %s missed its go-live window", ErrStreamExpired, session.ID)
+
+	case entity.StatusIdle, entity.StatusScheduled:
 		// Valid transition
 	default:
 		log.Printf("[on_publish] REJECTED: unknown status %s for stream %s", session.Status, session.ID)
@@ -314,8 +960,18 @@ func (s *liveService) HandleOnPublish(ctx context.Context, streamID string, toke
 	}
 
 	// Update status to LIVE
-	if err := s.repo.SetStarted(ctx, session.ID); err != nil {
+	if cohostID != "" {
+		err = s.repo.SetStartedByCohost(ctx, session.ID, clientID, cohostID)
+	} else {
+		err = s.repo.SetStarted(ctx, session.ID, clientID)
+	}
+	if err != nil {
 		if errors.Is(err, repository.ErrInvalidStatus) {
+			// A concurrent retry of the same callback may have won the race
+			if current, getErr := s.repo.GetByID(ctx, session.ID); getErr == nil && current.Status == entity.StatusLive && isSamePublisher(current, clientID) {
+				log.Printf("[on_publish] INFO: concurrent retry for stream %s (client: %s)", session.ID, clientID)
+				return nil
+			}
 			log.Printf("[on_publish] REJECTED: race condition for stream %s", session.ID)
 			return fmt.Errorf("%w: stream state changed", ErrDuplicatePublish)
 		}
@@ -323,10 +979,42 @@ func (s *liveService) HandleOnPublish(ctx context.Context, streamID string, toke
 		return fmt.Errorf("failed to start stream: %w", err)
 	}
 
-	log.Printf("[on_publish] SUCCESS: stream %s started (user: %s)", session.ID, session.UserID)
+	if cohostID != "" {
+		log.Printf("[on_publish] SUCCESS: stream %s started by co-host %s (owner: %s)", session.ID, cohostID, session.UserID)
+	} else {
+		log.Printf("[on_publish] SUCCESS: stream %s started (user: %s)", session.ID, session.UserID)
+	}
+
+	s.openChatRoom(ctx, session)
 	return nil
 }
 
+// openChatRoom creates the stream's chat room in chat-service
+// Failures never block going live: the stream just has no chat (chat_available=false)
+func (s *liveService) openChatRoom(ctx context.Context, session *entity.LiveSession) {
+	if s.chatRooms == nil {
+		return
+	}
+
+	conversationID, err := s.chatRooms.OpenRoom(ctx, session.ID, session.UserID, session.Title)
+	if err != nil {
+		log.Printf("[on_publish] WARNING: chat unavailable for stream %s: %v", session.ID, err)
+		return
+	}
+
+	if err := s.repo.SetChatConversation(ctx, session.ID, conversationID); err != nil {
+		log.Printf("[on_publish] WARNING: failed to save chat room for stream %s: %v", session.ID, err)
+		return
+	}
+
+	log.Printf("[on_publish] Chat room %s opened for stream %s", conversationID, session.ID)
+}
+
+// isSamePublisher reports whether clientID is the recorded publisher of a session
+func isSamePublisher(session *entity.LiveSession, clientID string) bool {
+	return clientID != "" && session.PublisherClientID != nil && *session.PublisherClientID == clientID
+}
+
 // HandleOnUnpublish updates session status to ENDED when stream stops
 // streamID can be NanoID (new flow) or stream_key (legacy)
 func (s *liveService) HandleOnUnpublish(ctx context.Context, streamID string) error {
@@ -376,5 +1064,146 @@ func (s *liveService) HandleOnUnpublish(ctx context.Context, streamID string) er
 	}
 
 	log.Printf("[on_unpublish] SUCCESS: stream %s ended (user: %s)", session.ID, session.UserID)
+
+	if s.viewers != nil {
+		if err := s.viewers.Clear(ctx, session.ID); err != nil {
+			log.Printf("[on_unpublish] WARNING: failed to clear viewers of stream %s: %v", session.ID, err)
+		}
+	}
+
+	// The room stays in chat-service for history but is no longer exposed once the stream ends
+	if s.chatRooms != nil && session.ChatConversationID != nil {
+		if err := s.chatRooms.CloseRoom(ctx, session.ID, session.UserID, *session.ChatConversationID); err != nil {
+			log.Printf("[on_unpublish] WARNING: failed to close chat room for stream %s: %v", session.ID, err)
+		}
+	}
+	return nil
+}
+
+// HandleOnPlay counts a new viewer when SRS starts playing a stream
+// Viewers are tracked by client_id so duplicate callbacks are ignored
+// Playback is never rejected here - only the viewer count and viewer list are affected
+func (s *liveService) HandleOnPlay(ctx context.Context, streamID string, clientID string, userID string) error {
+	if streamID == "" || clientID == "" {
+		log.Printf("[on_play] WARNING: missing stream ID or client ID (stream: %s, client: %s)", streamID, clientID)
+		return nil
+	}
+
+	// Counted in a single UPDATE ... RETURNING, so concurrent on_play callbacks can't lose updates
+	count, added, err := s.repo.AddViewer(ctx, streamID, clientID)
+	if err != nil {
+		log.Printf("[on_play] ERROR: failed to add viewer %s to stream %s: %v", clientID, streamID, err)
+		return fmt.Errorf("failed to add viewer: %w", err)
+	}
+
+	if !added {
+		log.Printf("[on_play] INFO: viewer %s not counted for stream %s (duplicate or stream not live)", clientID, streamID)
+		return nil
+	}
+
+	log.Printf("[on_play] SUCCESS: viewer %s joined stream %s (viewers: %d)", clientID, streamID, count)
+
+	// The count covers every client; only signed-in viewers are listed
+	if s.viewers != nil && userID != "" {
+		if err := s.viewers.Join(ctx, streamID, userID); err != nil {
+			log.Printf("[on_play] WARNING: failed to list user %s as a viewer of stream %s: %v", userID, streamID, err)
+		}
+	}
+	return nil
+}
+
+// HandleOnStop removes a viewer when SRS stops playing a stream
+// Unknown client_ids (duplicate or out-of-order on_stop) leave the count untouched
+func (s *liveService) HandleOnStop(ctx context.Context, streamID string, clientID string, userID string) error {
+	if streamID == "" || clientID == "" {
+		log.Printf("[on_stop] WARNING: missing stream ID or client ID (stream: %s, client: %s)", streamID, clientID)
+		return nil
+	}
+
+	count, removed, err := s.repo.RemoveViewer(ctx, streamID, clientID)
+	if err != nil {
+		log.Printf("[on_stop] ERROR: failed to remove viewer %s from stream %s: %v", clientID, streamID, err)
+		return fmt.Errorf("failed to remove viewer: %w", err)
+	}
+
+	if !removed {
+		log.Printf("[on_stop] INFO: viewer %s was not tracked for stream %s", clientID, streamID)
+		return nil
+	}
+
+	log.Printf("[on_stop] SUCCESS: viewer %s left stream %s (viewers: %d)", clientID, streamID, count)
+
+	// A user watching on several clients stays listed until the last one stops
+	if s.viewers != nil && userID != "" {
+		if _, err := s.viewers.Leave(ctx, streamID, userID); err != nil {
+			log.Printf("[on_stop] WARNING: failed to unlist user %s as a viewer of stream %s: %v", userID, streamID, err)
+		}
+	}
+	return nil
+}
+
+// HandleOnHLS records the thumbnail of a LIVE stream on its first HLS segment
+// A segment means SRS is receiving video, so the snapshot engine has frames to grab
+// Later segments are no-ops (SetThumbnail only fills an empty thumbnail_url)
+func (s *liveService) HandleOnHLS(ctx context.Context, streamID string) error {
+	if streamID == "" {
+		return nil
+	}
+
+	updated, err := s.repo.SetThumbnail(ctx, streamID, s.config.GetThumbnailURL(streamID))
+	if err != nil {
+		log.Printf("[on_hls] ERROR: failed to set thumbnail for stream %s: %v", streamID, err)
+		return err
+	}
+
+	if updated {
+		log.Printf("[on_hls] Thumbnail set for stream %s", streamID)
+	}
+	return nil
+}
+
+// HandleOnDVR stores a DVR file SRS closed (a segment, or the last file at unpublish) for VOD playback
+// SRS reports no duration: it runs from the start time in the file name until the callback
+// Retried callbacks for a stored file are no-ops, so the first (closest) duration is kept
+func (s *liveService) HandleOnDVR(ctx context.Context, streamID string, cwd string, file string) error {
+	if streamID == "" {
+		return nil
+	}
+
+	path, ok := s.config.RecordingPath(cwd, file)
+	if !ok {
+		log.Printf("[on_dvr] WARNING: file %q of stream %s is outside the recording dir %s", file, streamID, s.config.Recording.Dir)
+		return nil
+	}
+
+	now := time.Now()
+	startedAt, ok := utils.ParseDVRStartTime(path)
+	if !ok {
+		// Still playable; ordered by arrival with an unknown (zero) duration
+		log.Printf("[on_dvr] WARNING: no start time in file name %s of stream %s", path, streamID)
+		startedAt = now
+	}
+	durationMs := now.Sub(startedAt).Milliseconds()
+	if durationMs < 0 {
+		durationMs = 0
+	}
+
+	added, err := s.repo.AddRecording(ctx, &entity.StreamRecording{
+		StreamID:   streamID,
+		FilePath:   path,
+		StartedAt:  startedAt,
+		DurationMs: durationMs,
+	})
+	if err != nil {
+		log.Printf("[on_dvr] ERROR: failed to store recording %s of stream %s: %v", path, streamID, err)
+		return err
+	}
+
+	if !added {
+		log.Printf("[on_dvr] INFO: recording %s not stored for stream %s (duplicate or stream never went live)", path, streamID)
+		return nil
+	}
+
+	log.Printf("[on_dvr] SUCCESS: recording %s stored for stream %s (%dms)", path, streamID, durationMs)
 	return nil
 }
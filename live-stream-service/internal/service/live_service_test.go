@@ -0,0 +1,890 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"testing"
+	"time"
+
+	"live-service/internal/config"
+	"live-service/internal/entity"
+	"live-service/internal/presence"
+	"live-service/internal/repository"
+	"live-service/pkg/utils"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// stubLiveRepository implements the repository calls used by on_publish.
+// Unimplemented methods panic via the embedded nil interface.
+type stubLiveRepository struct {
+	repository.LiveRepository
+	session    *entity.LiveSession
+	banned     bool
+	started    bool
+	viewerBans []entity.ViewerBan
+	// SRS client_ids counted as viewers
+	viewerClients map[string]bool
+	cohosts       []entity.StreamCohost
+	// Platform bans of users other than the owner (banned covers every user)
+	bannedUsers map[string]bool
+	// Co-host who started the stream, empty if the owner did
+	startedBy string
+	// DVR files stored by on_dvr, in insertion order
+	recordings []entity.StreamRecording
+}
+
+func (r *stubLiveRepository) GetByID(ctx context.Context, id string) (*entity.LiveSession, error) {
+	if r.session == nil || r.session.ID != id {
+		return nil, repository.ErrNotFound
+	}
+	return r.session, nil
+}
+
+func (r *stubLiveRepository) IsUserBanned(ctx context.Context, userID string) (bool, error) {
+	return r.banned || r.bannedUsers[userID], nil
+}
+
+func (r *stubLiveRepository) SetStarted(ctx context.Context, id string, clientID string) error {
+	r.started = true
+	r.session.Status = entity.StatusLive
+	r.session.PublisherClientID = &clientID
+	return nil
+}
+
+func (r *stubLiveRepository) SetStartedByCohost(ctx context.Context, id string, clientID string, cohostID string) error {
+	r.startedBy = cohostID
+	r.session.PublisherCohostID = &cohostID
+	return r.SetStarted(ctx, id, clientID)
+}
+
+func (r *stubLiveRepository) AddCohost(ctx context.Context, id string, userID string, token string) error {
+	r.cohosts = append(r.cohosts, entity.StreamCohost{StreamID: id, UserID: userID, Token: token})
+	return nil
+}
+
+func (r *stubLiveRepository) RemoveCohost(ctx context.Context, id string, userID string) error {
+	for i, c := range r.cohosts {
+		if c.UserID == userID {
+			r.cohosts = append(r.cohosts[:i], r.cohosts[i+1:]...)
+			return nil
+		}
+	}
+	return repository.ErrNotFound
+}
+
+func (r *stubLiveRepository) ListCohosts(ctx context.Context, id string) ([]entity.StreamCohost, error) {
+	return append([]entity.StreamCohost{}, r.cohosts...), nil
+}
+
+func (r *stubLiveRepository) SetChatConversation(ctx context.Context, id string, conversationID string) error {
+	r.session.ChatConversationID = &conversationID
+	return nil
+}
+
+func (r *stubLiveRepository) RotateStreamKey(ctx context.Context, id string, streamKey, rtmpURL, webrtcURL string) error {
+	r.session.StreamKey = streamKey
+	return nil
+}
+
+func (r *stubLiveRepository) AddRecording(ctx context.Context, recording *entity.StreamRecording) (bool, error) {
+	if r.session == nil || r.session.ID != recording.StreamID ||
+		(r.session.Status != entity.StatusLive && r.session.Status != entity.StatusEnded) {
+		return false, nil
+	}
+	for _, existing := range r.recordings {
+		if existing.FilePath == recording.FilePath {
+			return false, nil
+		}
+	}
+	r.recordings = append(r.recordings, *recording)
+	return true, nil
+}
+
+func (r *stubLiveRepository) ListRecordings(ctx context.Context, id string) ([]entity.StreamRecording, error) {
+	recordings := append([]entity.StreamRecording{}, r.recordings...)
+	sort.Slice(recordings, func(i, j int) bool { return recordings[i].StartedAt.Before(recordings[j].StartedAt) })
+	return recordings, nil
+}
+
+func (r *stubLiveRepository) BanViewer(ctx context.Context, id string, userID string, reason string) error {
+	r.viewerBans = append(r.viewerBans, entity.ViewerBan{StreamID: id, UserID: userID})
+	// Like the repository, a ban revokes the user's co-host rights
+	_ = r.RemoveCohost(ctx, id, userID)
+	return nil
+}
+
+func (r *stubLiveRepository) IsViewerBanned(ctx context.Context, id string, userID string) (bool, error) {
+	for _, ban := range r.viewerBans {
+		if ban.StreamID == id && ban.UserID == userID {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+func (r *stubLiveRepository) ListViewerBans(ctx context.Context, id string) ([]entity.ViewerBan, error) {
+	return r.viewerBans, nil
+}
+
+func TestHandleOnPublish_BannedUserRejected(t *testing.T) {
+	repo := &stubLiveRepository{
+		session: &entity.LiveSession{
+			ID:        "V1StGXR8_Z5jdHi6B-myT",
+			UserID:    "550e8400-e29b-41d4-a716-446655440000",
+			StreamKey: "live_550e8400-e29b-41d4-a716-446655440000_0123456789abcdef",
+			Status:    entity.StatusIdle,
+		},
+		banned: true,
+	}
+	svc := NewLiveService(repo, &config.Config{}, nil, nil, nil, nil, nil)
+
+	err := svc.HandleOnPublish(context.Background(), repo.session.ID, repo.session.StreamKey, "client-1")
+
+	require.Error(t, err)
+	assert.True(t, errors.Is(err, ErrUserBanned))
+	assert.False(t, repo.started, "banned user's stream must not go live")
+}
+
+func TestHandleOnPublish_NotBannedGoesLive(t *testing.T) {
+	repo := &stubLiveRepository{
+		session: &entity.LiveSession{
+			ID:        "V1StGXR8_Z5jdHi6B-myT",
+			UserID:    "550e8400-e29b-41d4-a716-446655440000",
+			StreamKey: "live_550e8400-e29b-41d4-a716-446655440000_0123456789abcdef",
+			Status:    entity.StatusIdle,
+		},
+	}
+	svc := NewLiveService(repo, &config.Config{}, nil, nil, nil, nil, nil)
+
+	err := svc.HandleOnPublish(context.Background(), repo.session.ID, repo.session.StreamKey, "client-1")
+
+	require.NoError(t, err)
+	assert.True(t, repo.started)
+}
+
+func TestHandleOnPublish_RetryFromSameClient(t *testing.T) {
+	repo := &stubLiveRepository{
+		session: &entity.LiveSession{
+			ID:        "V1StGXR8_Z5jdHi6B-myT",
+			UserID:    "550e8400-e29b-41d4-a716-446655440000",
+			StreamKey: "live_550e8400-e29b-41d4-a716-446655440000_0123456789abcdef",
+			Status:    entity.StatusIdle,
+		},
+	}
+	svc := NewLiveService(repo, &config.Config{}, nil, nil, nil, nil, nil)
+	ctx := context.Background()
+
+	require.NoError(t, svc.HandleOnPublish(ctx, repo.session.ID, repo.session.StreamKey, "client-1"))
+
+	// SRS re-delivers the same callback: accepted as a no-op
+	assert.NoError(t, svc.HandleOnPublish(ctx, repo.session.ID, repo.session.StreamKey, "client-1"))
+
+	// A different client publishing while LIVE is a genuine duplicate
+	err := svc.HandleOnPublish(ctx, repo.session.ID, repo.session.StreamKey, "client-2")
+	assert.ErrorIs(t, err, ErrDuplicatePublish)
+
+	// Missing client_id can't be matched and is rejected
+	err = svc.HandleOnPublish(ctx, repo.session.ID, repo.session.StreamKey, "")
+	assert.ErrorIs(t, err, ErrDuplicatePublish)
+}
+
+type stubStatsProvider struct {
+	stats *utils.StreamStats
+	err   error
+	calls int
+}
+
+func (p *stubStatsProvider) Get(ctx context.Context, app, name string) (*utils.StreamStats, error) {
+	p.calls++
+	return p.stats, p.err
+}
+
+func TestGetStreamDetail_HealthOnlyWhileLive(t *testing.T) {
+	repo := &stubLiveRepository{
+		session: &entity.LiveSession{
+			ID:     "V1StGXR8_Z5jdHi6B-myT",
+			UserID: "550e8400-e29b-41d4-a716-446655440000",
+			Status: entity.StatusLive,
+		},
+	}
+	stats := &stubStatsProvider{stats: &utils.StreamStats{BitrateKbps: 2500, FPS: 30, VideoCodec: "H264", AudioCodec: "AAC"}}
+	svc := NewLiveService(repo, &config.Config{}, stats, nil, nil, nil, nil)
+	ctx := context.Background()
+
+	detail, err := svc.GetStreamDetail(ctx, repo.session.ID, "")
+	require.NoError(t, err)
+	require.NotNil(t, detail.Health)
+	assert.Equal(t, 2500, detail.Health.BitrateKbps)
+	assert.Equal(t, "H264", detail.Health.VideoCodec)
+
+	// SRS being unreachable must not fail the detail request
+	stats.err = errors.New("SRS server unreachable")
+	detail, err = svc.GetStreamDetail(ctx, repo.session.ID, "")
+	require.NoError(t, err)
+	assert.Nil(t, detail.Health)
+
+	repo.session.Status = entity.StatusIdle
+	stats.calls = 0
+	detail, err = svc.GetStreamDetail(ctx, repo.session.ID, "")
+	require.NoError(t, err)
+	assert.Nil(t, detail.Health)
+	assert.Zero(t, stats.calls, "SRS is not queried for streams that aren't LIVE")
+}
+
+func TestGetWebRTCInfo_WHIPOnlyForOwnerWhilePublishable(t *testing.T) {
+	repo := &stubLiveRepository{
+		session: &entity.LiveSession{
+			ID:        "V1StGXR8_Z5jdHi6B-myT",
+			UserID:    "550e8400-e29b-41d4-a716-446655440000",
+			StreamKey: "live_550e8400-e29b-41d4-a716-446655440000_0123456789abcdef",
+			Status:    entity.StatusIdle,
+		},
+	}
+	cfg := &config.Config{SRS: config.SRSConfig{ServerIP: "srs.example.com", APIPort: 1985, App: "live"}}
+	svc := NewLiveService(repo, cfg, nil, nil, nil, nil, nil)
+	ctx := context.Background()
+
+	info, err := svc.GetWebRTCInfo(ctx, repo.session.ID, repo.session.UserID)
+	require.NoError(t, err)
+	assert.Equal(t, "http://srs.example.com:1985/rtc/v1/whep/?app=live&stream=V1StGXR8_Z5jdHi6B-myT", info.WHEPEndpoint)
+	assert.Equal(t, "http://srs.example.com:1985/rtc/v1/whip/?app=live&stream=V1StGXR8_Z5jdHi6B-myT&token="+repo.session.StreamKey, info.WHIPEndpoint)
+	assert.Equal(t, "all", info.ICETransportPolicy)
+
+	// Viewers only get playback endpoints
+	info, err = svc.GetWebRTCInfo(ctx, repo.session.ID, "other-user")
+	require.NoError(t, err)
+	assert.NotEmpty(t, info.WHEPEndpoint)
+	assert.Empty(t, info.WHIPEndpoint)
+	assert.Empty(t, info.PublishURL)
+
+	// on_publish would reject an ended stream, so no WHIP URL is handed out
+	repo.session.Status = entity.StatusEnded
+	info, err = svc.GetWebRTCInfo(ctx, repo.session.ID, repo.session.UserID)
+	require.NoError(t, err)
+	assert.Empty(t, info.WHIPEndpoint)
+	assert.Empty(t, info.PublishURL)
+}
+
+type stubChatRooms struct {
+	err error
+}
+
+func (c *stubChatRooms) OpenRoom(ctx context.Context, streamID, ownerID, title string) (string, error) {
+	if c.err != nil {
+		return "", c.err
+	}
+	return "7b0c1c51-1c8e-5f5e-9b1a-4f1d2c3b4a59", nil
+}
+
+func (c *stubChatRooms) CloseRoom(ctx context.Context, streamID, ownerID, conversationID string) error {
+	return c.err
+}
+
+func TestHandleOnPublish_OpensChatRoom(t *testing.T) {
+	repo := &stubLiveRepository{
+		session: &entity.LiveSession{
+			ID:        "V1StGXR8_Z5jdHi6B-myT",
+			UserID:    "550e8400-e29b-41d4-a716-446655440000",
+			StreamKey: "live_550e8400-e29b-41d4-a716-446655440000_0123456789abcdef",
+			Status:    entity.StatusIdle,
+		},
+	}
+	svc := NewLiveService(repo, &config.Config{}, nil, &stubChatRooms{}, nil, nil, nil)
+	ctx := context.Background()
+
+	require.NoError(t, svc.HandleOnPublish(ctx, repo.session.ID, repo.session.StreamKey, "client-1"))
+
+	detail, err := svc.GetStreamDetail(ctx, repo.session.ID, "")
+	require.NoError(t, err)
+	assert.True(t, detail.ChatAvailable)
+	require.NotNil(t, detail.ChatConversationID)
+	assert.Equal(t, "7b0c1c51-1c8e-5f5e-9b1a-4f1d2c3b4a59", *detail.ChatConversationID)
+}
+
+func TestHandleOnPublish_ChatUnavailableStillGoesLive(t *testing.T) {
+	repo := &stubLiveRepository{
+		session: &entity.LiveSession{
+			ID:        "V1StGXR8_Z5jdHi6B-myT",
+			UserID:    "550e8400-e29b-41d4-a716-446655440000",
+			StreamKey: "live_550e8400-e29b-41d4-a716-446655440000_0123456789abcdef",
+			Status:    entity.StatusIdle,
+		},
+	}
+	svc := NewLiveService(repo, &config.Config{}, nil, &stubChatRooms{err: errors.New("chat service unreachable")}, nil, nil, nil)
+	ctx := context.Background()
+
+	require.NoError(t, svc.HandleOnPublish(ctx, repo.session.ID, repo.session.StreamKey, "client-1"))
+	assert.True(t, repo.started)
+
+	detail, err := svc.GetStreamDetail(ctx, repo.session.ID, "")
+	require.NoError(t, err)
+	assert.False(t, detail.ChatAvailable)
+	assert.Nil(t, detail.ChatConversationID)
+}
+
+type stubKicker struct {
+	kicked []string
+}
+
+func (k *stubKicker) KickUser(streamID, userID, reason string) int {
+	k.kicked = append(k.kicked, userID)
+	return 1
+}
+
+func TestBanViewer_OwnerOnly(t *testing.T) {
+	repo := &stubLiveRepository{
+		session: &entity.LiveSession{
+			ID:     "V1StGXR8_Z5jdHi6B-myT",
+			UserID: "550e8400-e29b-41d4-a716-446655440000",
+			Status: entity.StatusLive,
+		},
+	}
+	kicker := &stubKicker{}
+	svc := NewLiveService(repo, &config.Config{}, nil, nil, kicker, nil, nil)
+	ctx := context.Background()
+	viewerID := "7c9e6679-7425-40de-944b-e07fc1f90ae7"
+
+	_, err := svc.BanViewer(ctx, repo.session.ID, viewerID, &entity.BanViewerRequest{UserID: viewerID})
+	assert.ErrorIs(t, err, ErrNotStreamOwner)
+
+	_, err = svc.BanViewer(ctx, repo.session.ID, repo.session.UserID, &entity.BanViewerRequest{UserID: repo.session.UserID})
+	assert.ErrorIs(t, err, ErrCannotBanOwner)
+	assert.Empty(t, repo.viewerBans)
+
+	resp, err := svc.BanViewer(ctx, repo.session.ID, repo.session.UserID, &entity.BanViewerRequest{UserID: viewerID, Reason: "spam"})
+	require.NoError(t, err)
+	require.Len(t, resp.Bans, 1)
+	assert.Equal(t, viewerID, resp.Bans[0].UserID)
+	assert.Equal(t, []string{viewerID}, kicker.kicked, "banned viewer is disconnected from chat")
+}
+
+// stubPublisherKicker records the SRS clients it disconnects
+type stubPublisherKicker struct {
+	kicked []string
+}
+
+func (k *stubPublisherKicker) KickClient(ctx context.Context, clientID string) error {
+	k.kicked = append(k.kicked, clientID)
+	return nil
+}
+
+func TestBanViewer_RevokesCohost(t *testing.T) {
+	cohostID := "7c9e6679-7425-40de-944b-e07fc1f90ae7"
+	newRepo := func() *stubLiveRepository {
+		return &stubLiveRepository{
+			session: &entity.LiveSession{
+				ID:        "V1StGXR8_Z5jdHi6B-myT",
+				UserID:    "550e8400-e29b-41d4-a716-446655440000",
+				StreamKey: "live_Xk3fQ9bT2mWz0123456789abcdefghijklmnopqrs",
+				Status:    entity.StatusIdle,
+			},
+			cohosts: []entity.StreamCohost{
+				{StreamID: "V1StGXR8_Z5jdHi6B-myT", UserID: cohostID, Token: "live_Co7hostToken0123456789abcdefghijklmnopqr"},
+			},
+		}
+	}
+	ctx := context.Background()
+
+	t.Run("publishing co-host is disconnected", func(t *testing.T) {
+		repo := newRepo()
+		publishers := &stubPublisherKicker{}
+		svc := NewLiveService(repo, &config.Config{}, nil, nil, nil, nil, publishers)
+		require.NoError(t, svc.HandleOnPublish(ctx, repo.session.ID, repo.cohosts[0].Token, "client-1"))
+
+		_, err := svc.BanViewer(ctx, repo.session.ID, repo.session.UserID, &entity.BanViewerRequest{UserID: cohostID})
+		require.NoError(t, err)
+		assert.Empty(t, repo.cohosts, "banned co-host loses their publish rights")
+		assert.Equal(t, []string{"client-1"}, publishers.kicked)
+	})
+
+	t.Run("owner's publish is left alone", func(t *testing.T) {
+		repo := newRepo()
+		publishers := &stubPublisherKicker{}
+		svc := NewLiveService(repo, &config.Config{}, nil, nil, nil, nil, publishers)
+		require.NoError(t, svc.HandleOnPublish(ctx, repo.session.ID, repo.session.StreamKey, "client-1"))
+
+		_, err := svc.BanViewer(ctx, repo.session.ID, repo.session.UserID, &entity.BanViewerRequest{UserID: cohostID})
+		require.NoError(t, err)
+		assert.Empty(t, repo.cohosts)
+		assert.Empty(t, publishers.kicked)
+	})
+
+	t.Run("banned user can't be added back", func(t *testing.T) {
+		repo := newRepo()
+		svc := NewLiveService(repo, &config.Config{}, nil, nil, nil, nil, nil)
+
+		_, err := svc.BanViewer(ctx, repo.session.ID, repo.session.UserID, &entity.BanViewerRequest{UserID: cohostID})
+		require.NoError(t, err)
+		_, err = svc.AddCohost(ctx, repo.session.ID, repo.session.UserID, &entity.AddCohostRequest{UserID: cohostID})
+		assert.ErrorIs(t, err, ErrCohostBanned)
+		assert.Empty(t, repo.cohosts)
+	})
+}
+
+// stubViewerPresence keeps viewers in memory: user_id -> playing clients
+type stubViewerPresence struct {
+	clients map[string]int
+}
+
+func (p *stubViewerPresence) Join(ctx context.Context, streamID, userID string) error {
+	p.clients[userID]++
+	return nil
+}
+
+func (p *stubViewerPresence) Leave(ctx context.Context, streamID, userID string) (bool, error) {
+	p.clients[userID]--
+	if p.clients[userID] > 0 {
+		return false, nil
+	}
+	delete(p.clients, userID)
+	return true, nil
+}
+
+func (p *stubViewerPresence) Heartbeat(ctx context.Context, streamID, userID string) (bool, error) {
+	return p.clients[userID] > 0, nil
+}
+
+func (p *stubViewerPresence) List(ctx context.Context, streamID, after string, limit int) ([]presence.Viewer, int, error) {
+	ids := make([]string, 0, len(p.clients))
+	for id := range p.clients {
+		if id > after {
+			ids = append(ids, id)
+		}
+	}
+	sort.Strings(ids)
+	if len(ids) > limit {
+		ids = ids[:limit]
+	}
+	viewers := make([]presence.Viewer, 0, len(ids))
+	for _, id := range ids {
+		viewers = append(viewers, presence.Viewer{UserID: id})
+	}
+	return viewers, len(p.clients), nil
+}
+
+func (p *stubViewerPresence) Clear(ctx context.Context, streamID string) error {
+	p.clients = map[string]int{}
+	return nil
+}
+
+func (r *stubLiveRepository) AddViewer(ctx context.Context, id string, clientID string) (int, bool, error) {
+	if r.viewerClients == nil {
+		r.viewerClients = map[string]bool{}
+	}
+	if r.session.Status != entity.StatusLive || r.viewerClients[clientID] {
+		return len(r.viewerClients), false, nil
+	}
+	r.viewerClients[clientID] = true
+	return len(r.viewerClients), true, nil
+}
+
+func (r *stubLiveRepository) RemoveViewer(ctx context.Context, id string, clientID string) (int, bool, error) {
+	if !r.viewerClients[clientID] {
+		return len(r.viewerClients), false, nil
+	}
+	delete(r.viewerClients, clientID)
+	return len(r.viewerClients), true, nil
+}
+
+func TestHandleOnPlay_ListsSignedInViewers(t *testing.T) {
+	repo := &stubLiveRepository{
+		session: &entity.LiveSession{
+			ID:     "V1StGXR8_Z5jdHi6B-myT",
+			UserID: "550e8400-e29b-41d4-a716-446655440000",
+			Status: entity.StatusLive,
+		},
+	}
+	viewers := &stubViewerPresence{clients: map[string]int{}}
+	svc := NewLiveService(repo, &config.Config{}, nil, nil, nil, viewers, nil)
+	ctx := context.Background()
+	viewerID := "7c9e6679-7425-40de-944b-e07fc1f90ae7"
+
+	require.NoError(t, svc.HandleOnPlay(ctx, repo.session.ID, "client-1", viewerID))
+	require.NoError(t, svc.HandleOnPlay(ctx, repo.session.ID, "client-1", viewerID)) // SRS retry
+	require.NoError(t, svc.HandleOnPlay(ctx, repo.session.ID, "client-2", ""))       // anonymous
+	assert.Equal(t, map[string]int{viewerID: 1}, viewers.clients, "retries and anonymous viewers are not listed")
+	assert.Len(t, repo.viewerClients, 2, "anonymous viewers are still counted")
+
+	require.NoError(t, svc.HeartbeatViewer(ctx, repo.session.ID, viewerID))
+
+	require.NoError(t, svc.HandleOnStop(ctx, repo.session.ID, "client-1", viewerID))
+	require.NoError(t, svc.HandleOnStop(ctx, repo.session.ID, "client-1", viewerID)) // duplicate on_stop
+	assert.Empty(t, viewers.clients)
+
+	assert.ErrorIs(t, svc.HeartbeatViewer(ctx, repo.session.ID, viewerID), ErrNotWatching)
+}
+
+func TestGetStreamViewers_OwnerOnlyPaginated(t *testing.T) {
+	repo := &stubLiveRepository{
+		session: &entity.LiveSession{
+			ID:     "V1StGXR8_Z5jdHi6B-myT",
+			UserID: "550e8400-e29b-41d4-a716-446655440000",
+			Status: entity.StatusLive,
+		},
+	}
+	viewers := &stubViewerPresence{clients: map[string]int{
+		"11111111-1111-4111-8111-111111111111": 1,
+		"22222222-2222-4222-8222-222222222222": 1,
+		"33333333-3333-4333-8333-333333333333": 2,
+	}}
+	svc := NewLiveService(repo, &config.Config{}, nil, nil, nil, viewers, nil)
+	ctx := context.Background()
+
+	_, err := svc.GetStreamViewers(ctx, repo.session.ID, "7c9e6679-7425-40de-944b-e07fc1f90ae7", entity.ListStreamViewersParams{})
+	assert.ErrorIs(t, err, ErrNotStreamOwner)
+
+	_, err = svc.GetStreamViewers(ctx, "unknown", repo.session.UserID, entity.ListStreamViewersParams{})
+	assert.ErrorIs(t, err, repository.ErrNotFound)
+
+	_, err = svc.GetStreamViewers(ctx, repo.session.ID, repo.session.UserID, entity.ListStreamViewersParams{Cursor: "not-a-cursor"})
+	assert.ErrorIs(t, err, entity.ErrInvalidCursor)
+
+	page, err := svc.GetStreamViewers(ctx, repo.session.ID, repo.session.UserID, entity.ListStreamViewersParams{Limit: 2})
+	require.NoError(t, err)
+	assert.Equal(t, 3, page.Total)
+	require.Len(t, page.Viewers, 2)
+	assert.Equal(t, "22222222-2222-4222-8222-222222222222", page.Viewers[1].UserID)
+	require.NotEmpty(t, page.NextCursor)
+
+	page, err = svc.GetStreamViewers(ctx, repo.session.ID, repo.session.UserID, entity.ListStreamViewersParams{Limit: 2, Cursor: page.NextCursor})
+	require.NoError(t, err)
+	require.Len(t, page.Viewers, 1)
+	assert.Equal(t, "33333333-3333-4333-8333-333333333333", page.Viewers[0].UserID)
+	assert.Empty(t, page.NextCursor)
+}
+
+func TestGetStreamViewers_PresenceDisabled(t *testing.T) {
+	svc := NewLiveService(&stubLiveRepository{}, &config.Config{}, nil, nil, nil, nil, nil)
+
+	_, err := svc.GetStreamViewers(context.Background(), "V1StGXR8_Z5jdHi6B-myT", "550e8400-e29b-41d4-a716-446655440000", entity.ListStreamViewersParams{})
+	assert.ErrorIs(t, err, ErrPresenceDisabled)
+	assert.ErrorIs(t, svc.HeartbeatViewer(context.Background(), "V1StGXR8_Z5jdHi6B-myT", "550e8400-e29b-41d4-a716-446655440000"), ErrPresenceDisabled)
+}
+
+func TestGetStreamDetail_ThumbnailPlaceholder(t *testing.T) {
+	repo := &stubLiveRepository{
+		session: &entity.LiveSession{
+			ID:     "V1StGXR8_Z5jdHi6B-myT",
+			UserID: "550e8400-e29b-41d4-a716-446655440000",
+			Status: entity.StatusLive,
+		},
+	}
+	cfg := &config.Config{Thumbnail: config.ThumbnailConfig{PlaceholderURL: "https://cdn.test/static/placeholder.jpg"}}
+	svc := NewLiveService(repo, cfg, nil, nil, nil, nil, nil)
+	ctx := context.Background()
+
+	detail, err := svc.GetStreamDetail(ctx, repo.session.ID, "")
+	require.NoError(t, err)
+	assert.Equal(t, "https://cdn.test/static/placeholder.jpg", detail.ThumbnailURL)
+
+	thumbnail := "https://cdn.test/live/V1StGXR8_Z5jdHi6B-myT.jpg"
+	repo.session.ThumbnailURL = &thumbnail
+	detail, err = svc.GetStreamDetail(ctx, repo.session.ID, "")
+	require.NoError(t, err)
+	assert.Equal(t, thumbnail, detail.ThumbnailURL)
+}
+
+func TestHandleOnPublish_WrongTokenRejected(t *testing.T) {
+	repo := &stubLiveRepository{
+		session: &entity.LiveSession{
+			ID:        "V1StGXR8_Z5jdHi6B-myT",
+			UserID:    "550e8400-e29b-41d4-a716-446655440000",
+			StreamKey: "live_550e8400-e29b-41d4-a716-446655440000_0123456789abcdef",
+			Status:    entity.StatusIdle,
+		},
+	}
+	svc := NewLiveService(repo, &config.Config{}, nil, nil, nil, nil, nil)
+
+	err := svc.HandleOnPublish(context.Background(), repo.session.ID, repo.session.StreamKey[:len(repo.session.StreamKey)-1]+"0", "client-1")
+
+	assert.ErrorIs(t, err, ErrInvalidStreamKey)
+	assert.False(t, repo.started)
+}
+
+func TestCohost_OwnerManagesCohosts(t *testing.T) {
+	repo := &stubLiveRepository{
+		session: &entity.LiveSession{
+			ID:     "V1StGXR8_Z5jdHi6B-myT",
+			UserID: "550e8400-e29b-41d4-a716-446655440000",
+			Status: entity.StatusIdle,
+		},
+	}
+	svc := NewLiveService(repo, &config.Config{}, nil, nil, nil, nil, nil)
+	ctx := context.Background()
+	cohostID := "7c9e6679-7425-40de-944b-e07fc1f90ae7"
+
+	_, err := svc.AddCohost(ctx, repo.session.ID, cohostID, &entity.AddCohostRequest{UserID: cohostID})
+	assert.ErrorIs(t, err, ErrNotStreamOwner)
+	_, err = svc.AddCohost(ctx, repo.session.ID, repo.session.UserID, &entity.AddCohostRequest{UserID: repo.session.UserID})
+	assert.ErrorIs(t, err, ErrCannotCohostOwner)
+
+	resp, err := svc.AddCohost(ctx, repo.session.ID, repo.session.UserID, &entity.AddCohostRequest{UserID: cohostID})
+	require.NoError(t, err)
+	require.Len(t, resp.Cohosts, 1)
+	token := repo.cohosts[0].Token
+	assert.Regexp(t, `^[A-Za-z0-9]{43}$`, token)
+
+	// Adding again keeps the token
+	_, err = svc.AddCohost(ctx, repo.session.ID, repo.session.UserID, &entity.AddCohostRequest{UserID: cohostID})
+	require.NoError(t, err)
+	require.Len(t, repo.cohosts, 1)
+	assert.Equal(t, token, repo.cohosts[0].Token)
+
+	// Only the co-host gets their publish URLs
+	creds, err := svc.GetCohostCredentials(ctx, repo.session.ID, cohostID)
+	require.NoError(t, err)
+	assert.Equal(t, token, creds.Token)
+	assert.Contains(t, creds.RTMPUrl, token)
+	_, err = svc.GetCohostCredentials(ctx, repo.session.ID, repo.session.UserID)
+	assert.ErrorIs(t, err, ErrNotCohost)
+
+	_, err = svc.RemoveCohost(ctx, repo.session.ID, cohostID, cohostID)
+	assert.ErrorIs(t, err, ErrNotStreamOwner)
+	resp, err = svc.RemoveCohost(ctx, repo.session.ID, repo.session.UserID, cohostID)
+	require.NoError(t, err)
+	assert.Empty(t, resp.Cohosts)
+	_, err = svc.RemoveCohost(ctx, repo.session.ID, repo.session.UserID, cohostID)
+	assert.ErrorIs(t, err, ErrCohostNotFound)
+}
+
+func TestCohost_Limit(t *testing.T) {
+	repo := &stubLiveRepository{
+		session: &entity.LiveSession{
+			ID:     "V1StGXR8_Z5jdHi6B-myT",
+			UserID: "550e8400-e29b-41d4-a716-446655440000",
+			Status: entity.StatusIdle,
+		},
+	}
+	svc := NewLiveService(repo, &config.Config{}, nil, nil, nil, nil, nil)
+	ctx := context.Background()
+
+	for i := 0; i < MaxCohosts; i++ {
+		_, err := svc.AddCohost(ctx, repo.session.ID, repo.session.UserID, &entity.AddCohostRequest{UserID: fmt.Sprintf("7c9e6679-7425-40de-944b-e07fc1f90a%02d", i)})
+		require.NoError(t, err)
+	}
+	_, err := svc.AddCohost(ctx, repo.session.ID, repo.session.UserID, &entity.AddCohostRequest{UserID: "7c9e6679-7425-40de-944b-e07fc1f90aff"})
+	assert.ErrorIs(t, err, ErrTooManyCohosts)
+}
+
+func TestHandleOnPublish_CohostToken(t *testing.T) {
+	cohostID := "7c9e6679-7425-40de-944b-e07fc1f90ae7"
+	newRepo := func() *stubLiveRepository {
+		return &stubLiveRepository{
+			session: &entity.LiveSession{
+				ID:        "V1StGXR8_Z5jdHi6B-myT",
+				UserID:    "550e8400-e29b-41d4-a716-446655440000",
+				StreamKey: "live_Xk3fQ9bT2mWz0123456789abcdefghijklmnopqrs",
+				Status:    entity.StatusIdle,
+			},
+			cohosts: []entity.StreamCohost{
+				{StreamID: "V1StGXR8_Z5jdHi6B-myT", UserID: cohostID, Token: "live_Co7hostToken0123456789abcdefghijklmnopqr"},
+			},
+		}
+	}
+	ctx := context.Background()
+
+	t.Run("co-host token goes live as the co-host", func(t *testing.T) {
+		repo := newRepo()
+		svc := NewLiveService(repo, &config.Config{}, nil, nil, nil, nil, nil)
+
+		require.NoError(t, svc.HandleOnPublish(ctx, repo.session.ID, repo.cohosts[0].Token, "client-1"))
+		assert.True(t, repo.started)
+		assert.Equal(t, cohostID, repo.startedBy)
+	})
+
+	t.Run("owner key still goes live as the owner", func(t *testing.T) {
+		repo := newRepo()
+		svc := NewLiveService(repo, &config.Config{}, nil, nil, nil, nil, nil)
+
+		require.NoError(t, svc.HandleOnPublish(ctx, repo.session.ID, repo.session.StreamKey, "client-1"))
+		assert.True(t, repo.started)
+		assert.Empty(t, repo.startedBy)
+	})
+
+	t.Run("revoked co-host is rejected", func(t *testing.T) {
+		repo := newRepo()
+		token := repo.cohosts[0].Token
+		repo.cohosts = nil
+		svc := NewLiveService(repo, &config.Config{}, nil, nil, nil, nil, nil)
+
+		err := svc.HandleOnPublish(ctx, repo.session.ID, token, "client-1")
+		assert.ErrorIs(t, err, ErrInvalidStreamKey)
+		assert.False(t, repo.started)
+	})
+
+	t.Run("banned co-host is rejected", func(t *testing.T) {
+		repo := newRepo()
+		repo.bannedUsers = map[string]bool{cohostID: true}
+		svc := NewLiveService(repo, &config.Config{}, nil, nil, nil, nil, nil)
+
+		err := svc.HandleOnPublish(ctx, repo.session.ID, repo.cohosts[0].Token, "client-1")
+		assert.ErrorIs(t, err, ErrUserBanned)
+		assert.False(t, repo.started)
+	})
+
+	t.Run("co-host banned from the stream is rejected", func(t *testing.T) {
+		repo := newRepo()
+		repo.viewerBans = []entity.ViewerBan{{StreamID: repo.session.ID, UserID: cohostID}}
+		svc := NewLiveService(repo, &config.Config{}, nil, nil, nil, nil, nil)
+
+		err := svc.HandleOnPublish(ctx, repo.session.ID, repo.cohosts[0].Token, "client-1")
+		assert.ErrorIs(t, err, ErrUserBanned)
+		assert.False(t, repo.started)
+	})
+
+	t.Run("co-host can't take over a live stream", func(t *testing.T) {
+		repo := newRepo()
+		svc := NewLiveService(repo, &config.Config{}, nil, nil, nil, nil, nil)
+
+		require.NoError(t, svc.HandleOnPublish(ctx, repo.session.ID, repo.session.StreamKey, "client-1"))
+		err := svc.HandleOnPublish(ctx, repo.session.ID, repo.cohosts[0].Token, "client-2")
+		assert.ErrorIs(t, err, ErrDuplicatePublish)
+	})
+}
+
+func TestStreamKey_LegacyMigration(t *testing.T) {
+	newRepo := func() *stubLiveRepository {
+		return &stubLiveRepository{
+			session: &entity.LiveSession{
+				ID:        "V1StGXR8_Z5jdHi6B-myT",
+				UserID:    "550e8400-e29b-41d4-a716-446655440000",
+				StreamKey: "live_550e8400-e29b-41d4-a716-446655440000_0123456789abcdef",
+				Status:    entity.StatusIdle,
+			},
+		}
+	}
+	cfg := &config.Config{StreamKey: config.StreamKeyConfig{Prefix: "live"}}
+	ctx := context.Background()
+
+	t.Run("legacy keys keep working and are flagged to their owner", func(t *testing.T) {
+		repo := newRepo()
+		svc := NewLiveService(repo, cfg, nil, nil, nil, nil, nil)
+
+		detail, err := svc.GetStreamDetail(ctx, repo.session.ID, repo.session.UserID)
+		require.NoError(t, err)
+		assert.True(t, detail.StreamKeyOutdated)
+
+		require.NoError(t, svc.HandleOnPublish(ctx, repo.session.ID, repo.session.StreamKey, "client-1"))
+		assert.True(t, repo.started)
+	})
+
+	t.Run("rotating issues a key in the current format", func(t *testing.T) {
+		repo := newRepo()
+		svc := NewLiveService(repo, cfg, nil, nil, nil, nil, nil)
+
+		resp, err := svc.RotateStreamKey(ctx, repo.session.ID, repo.session.UserID)
+		require.NoError(t, err)
+		assert.Regexp(t, `^live_[A-Za-z0-9]{43}$`, resp.StreamKey)
+
+		detail, err := svc.GetStreamDetail(ctx, repo.session.ID, repo.session.UserID)
+		require.NoError(t, err)
+		assert.False(t, detail.StreamKeyOutdated)
+	})
+
+	t.Run("legacy keys are refused once rejected", func(t *testing.T) {
+		repo := newRepo()
+		strict := &config.Config{StreamKey: config.StreamKeyConfig{Prefix: "live", RejectLegacy: true}}
+		svc := NewLiveService(repo, strict, nil, nil, nil, nil, nil)
+
+		err := svc.HandleOnPublish(ctx, repo.session.ID, repo.session.StreamKey, "client-1")
+		assert.ErrorIs(t, err, ErrLegacyStreamKey)
+		assert.ErrorIs(t, err, ErrInvalidStreamKey)
+		assert.False(t, repo.started)
+
+		info, err := svc.GetWebRTCInfo(ctx, repo.session.ID, repo.session.UserID)
+		require.NoError(t, err)
+		assert.Empty(t, info.WHIPEndpoint, "no publish URL for a key on_publish would refuse")
+
+		_, err = svc.RotateStreamKey(ctx, repo.session.ID, repo.session.UserID)
+		require.NoError(t, err)
+		require.NoError(t, svc.HandleOnPublish(ctx, repo.session.ID, repo.session.StreamKey, "client-1"))
+		assert.True(t, repo.started)
+	})
+}
+
+func TestHandleOnDVR_StoresRecordings(t *testing.T) {
+	repo := &stubLiveRepository{
+		session: &entity.LiveSession{
+			ID:     "V1StGXR8_Z5jdHi6B-myT",
+			UserID: "550e8400-e29b-41d4-a716-446655440000",
+			Status: entity.StatusLive,
+		},
+	}
+	cfg := &config.Config{Recording: config.RecordingConfig{Dir: "/data"}}
+	svc := NewLiveService(repo, cfg, nil, nil, nil, nil, nil)
+	ctx := context.Background()
+
+	started := time.Now().Add(-90 * time.Second).UnixMilli()
+	file := fmt.Sprintf("/data/recordings/live/V1StGXR8_Z5jdHi6B-myT/%d.mp4", started)
+	require.NoError(t, svc.HandleOnDVR(ctx, repo.session.ID, "/usr/local/srs", file))
+	require.Len(t, repo.recordings, 1)
+	recording := repo.recordings[0]
+	assert.Equal(t, fmt.Sprintf("recordings/live/V1StGXR8_Z5jdHi6B-myT/%d.mp4", started), recording.FilePath)
+	assert.True(t, recording.StartedAt.Equal(time.UnixMilli(started)))
+	assert.InDelta(t, 90000, recording.DurationMs, 1000, "duration runs from the file name timestamp to the callback")
+
+	// A retried callback keeps the first duration
+	require.NoError(t, svc.HandleOnDVR(ctx, repo.session.ID, "/usr/local/srs", file))
+	assert.Len(t, repo.recordings, 1)
+
+	// Relative files resolve against SRS's cwd
+	cfg.Recording.Dir = "/usr/local/srs/objs/nginx/html"
+	require.NoError(t, svc.HandleOnDVR(ctx, repo.session.ID, "/usr/local/srs", "./objs/nginx/html/recordings/live/V1StGXR8_Z5jdHi6B-myT/1.mp4"))
+	require.Len(t, repo.recordings, 2)
+	assert.Equal(t, "recordings/live/V1StGXR8_Z5jdHi6B-myT/1.mp4", repo.recordings[1].FilePath)
+
+	// Files the CDN can't serve are skipped
+	require.NoError(t, svc.HandleOnDVR(ctx, repo.session.ID, "/usr/local/srs", "/tmp/recordings/2.mp4"))
+	require.NoError(t, svc.HandleOnDVR(ctx, repo.session.ID, "/usr/local/srs", "../escape/3.mp4"))
+	assert.Len(t, repo.recordings, 2)
+}
+
+func TestGetStreamDetail_RecordingOnlyWhenEnded(t *testing.T) {
+	start := time.Date(2024, 1, 15, 10, 30, 0, 0, time.UTC)
+	repo := &stubLiveRepository{
+		session: &entity.LiveSession{
+			ID:     "V1StGXR8_Z5jdHi6B-myT",
+			UserID: "550e8400-e29b-41d4-a716-446655440000",
+			Status: entity.StatusLive,
+		},
+		// on_dvr callbacks may arrive out of order
+		recordings: []entity.StreamRecording{
+			{StreamID: "V1StGXR8_Z5jdHi6B-myT", FilePath: "recordings/live/V1StGXR8_Z5jdHi6B-myT/2.mp4", StartedAt: start.Add(30 * time.Minute), DurationMs: 912400},
+			{StreamID: "V1StGXR8_Z5jdHi6B-myT", FilePath: "recordings/live/V1StGXR8_Z5jdHi6B-myT/1.mp4", StartedAt: start, DurationMs: 1800000},
+		},
+	}
+	cfg := &config.Config{CDN: config.CDNConfig{BaseURL: "https://cdn.test"}}
+	svc := NewLiveService(repo, cfg, nil, nil, nil, nil, nil)
+	ctx := context.Background()
+
+	detail, err := svc.GetStreamDetail(ctx, repo.session.ID, "")
+	require.NoError(t, err)
+	assert.Nil(t, detail.Recording, "VOD is only offered once the stream has ended")
+
+	repo.session.Status = entity.StatusEnded
+	detail, err = svc.GetStreamDetail(ctx, repo.session.ID, "")
+	require.NoError(t, err)
+	require.NotNil(t, detail.Recording)
+	assert.InDelta(t, 2712.4, detail.Recording.DurationSeconds, 0.001)
+	require.Len(t, detail.Recording.Files, 2)
+	assert.Equal(t, entity.RecordingFile{
+		Sequence:        1,
+		URL:             "https://cdn.test/recordings/live/V1StGXR8_Z5jdHi6B-myT/1.mp4",
+		StartedAt:       start,
+		DurationSeconds: 1800,
+		OffsetSeconds:   0,
+	}, detail.Recording.Files[0])
+	assert.Equal(t, 2, detail.Recording.Files[1].Sequence)
+	assert.Equal(t, "https://cdn.test/recordings/live/V1StGXR8_Z5jdHi6B-myT/2.mp4", detail.Recording.Files[1].URL)
+	assert.Equal(t, float64(1800), detail.Recording.Files[1].OffsetSeconds)
+
+	// Streams that weren't recorded have no recording
+	repo.recordings = nil
+	detail, err = svc.GetStreamDetail(ctx, repo.session.ID, "")
+	require.NoError(t, err)
+	assert.Nil(t, detail.Recording)
+}
@@ -0,0 +1,59 @@
+package service
+
+import (
+	"context"
+	"log"
+	"time"
+
+	"live-service/internal/repository"
+)
+
+// ScheduleSweeper periodically expires scheduled streams that nobody published
+// within gracePeriod after their scheduled_start_at
+type ScheduleSweeper struct {
+	repo        repository.LiveRepository
+	gracePeriod time.Duration
+	interval    time.Duration
+}
+
+// NewScheduleSweeper creates a sweeper that runs every interval
+func NewScheduleSweeper(repo repository.LiveRepository, gracePeriod, interval time.Duration) *ScheduleSweeper {
+	return &ScheduleSweeper{
+		repo:        repo,
+		gracePeriod: gracePeriod,
+		interval:    interval,
+	}
+}
+
+// Run sweeps until ctx is cancelled
+func (s *ScheduleSweeper) Run(ctx context.Context) {
+	ticker := time.NewTicker(s.interval)
+	defer ticker.Stop()
+
+	log.Printf("[schedule_sweeper] started (grace: %s, interval: %s)", s.gracePeriod, s.interval)
+
+	for {
+		select {
+		case <-ctx.Done():
+			log.Printf("[schedule_sweeper] stopped")
+			return
+		case <-ticker.C:
+			s.Sweep(ctx)
+		}
+	}
+}
+
+// Sweep marks overdue SCHEDULED streams as EXPIRED once
+func (s *ScheduleSweeper) Sweep(ctx context.Context) {
+	deadline := time.Now().Add(-s.gracePeriod)
+
+	expired, err := s.repo.ExpireScheduled(ctx, deadline)
+	if err != nil {
+		log.Printf("[schedule_sweeper] ERROR: failed to expire scheduled streams: %v", err)
+		return
+	}
+
+	if expired > 0 {
+		log.Printf("[schedule_sweeper] expired %d scheduled stream(s) scheduled before %s", expired, deadline.Format(time.RFC3339))
+	}
+}
@@ -226,6 +226,21 @@ func (c *Client) Close() {
 	}
 }
 
+// Kick disconnects the client with a policy-violation close frame carrying reason
+func (c *Client) Kick(reason string) {
+	c.Disconnect(websocket.ClosePolicyViolation, reason)
+}
+
+// Disconnect sends a close frame with code and reason, then closes the connection
+// WriteControl is safe to call concurrently with WritePump
+func (c *Client) Disconnect(code int, reason string) {
+	closeMsg := websocket.FormatCloseMessage(code, reason)
+	if err := c.conn.WriteControl(websocket.CloseMessage, closeMsg, time.Now().Add(writeWait)); err != nil {
+		log.Printf("Failed to send close frame to user %s: %v", c.userID, err)
+	}
+	c.Close()
+}
+
 // StreamID returns the stream ID (NanoID) this client is connected to
 func (c *Client) StreamID() string {
 	return c.streamID
@@ -5,6 +5,8 @@ import (
 	"log"
 	"sync"
 	"time"
+
+	"github.com/gorilla/websocket"
 )
 
 // Hub maintains the set of active clients and broadcasts messages to clients
@@ -279,6 +281,32 @@ func (h *Hub) SendToClient(client *Client, msg *Message) {
 	client.Send(data)
 }
 
+// KickUser disconnects every connection of userID from a stream room and returns how many were closed
+// The clients' ReadPump unregisters them once the connection is gone
+func (h *Hub) KickUser(streamID, userID, reason string) int {
+	kicked := 0
+	for _, client := range h.GetClientsInStream(streamID) {
+		if client.UserID() == userID {
+			client.Kick(reason)
+			kicked++
+		}
+	}
+	if kicked > 0 {
+		log.Printf("Kicked user %s from stream %s (%d connections)", userID, streamID, kicked)
+	}
+	return kicked
+}
+
+// CloseAll disconnects every client, used on server shutdown
+// WebSocket connections are hijacked, so http.Server.Shutdown doesn't close them
+func (h *Hub) CloseAll(reason string) {
+	for _, streamID := range h.GetActiveStreams() {
+		for _, client := range h.GetClientsInStream(streamID) {
+			client.Disconnect(websocket.CloseGoingAway, reason)
+		}
+	}
+}
+
 // GetActiveStreams returns list of stream IDs (NanoID) with active viewers
 func (h *Hub) GetActiveStreams() []string {
 	h.mu.RLock()
@@ -0,0 +1,2 @@
+-- Drop stream viewers table
+DROP TABLE IF EXISTS stream_viewers;
@@ -0,0 +1,8 @@
+-- Track active viewers per stream so SRS on_play/on_stop callbacks are idempotent
+-- One row per SRS client_id; viewer_count is only changed when a row is inserted/deleted
+CREATE TABLE IF NOT EXISTS stream_viewers (
+    stream_id VARCHAR(21) NOT NULL REFERENCES live_sessions(id) ON DELETE CASCADE,
+    client_id VARCHAR(64) NOT NULL,
+    joined_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (stream_id, client_id)
+);
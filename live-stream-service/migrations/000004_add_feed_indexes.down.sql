@@ -0,0 +1,3 @@
+-- Drop feed pagination indexes
+DROP INDEX IF EXISTS idx_live_sessions_feed_recent;
+DROP INDEX IF EXISTS idx_live_sessions_feed_viewers;
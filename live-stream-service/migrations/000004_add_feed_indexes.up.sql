@@ -0,0 +1,5 @@
+-- Indexes for keyset pagination of the live feed
+-- sort=viewers: (viewer_count, created_at, id) DESC within a status
+CREATE INDEX IF NOT EXISTS idx_live_sessions_feed_viewers ON live_sessions(status, viewer_count DESC, created_at DESC, id DESC);
+-- sort=recent: (created_at, id) DESC within a status
+CREATE INDEX IF NOT EXISTS idx_live_sessions_feed_recent ON live_sessions(status, created_at DESC, id DESC);
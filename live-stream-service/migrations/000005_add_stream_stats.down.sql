@@ -0,0 +1,3 @@
+-- Drop post-stream analytics columns
+ALTER TABLE live_sessions DROP COLUMN IF EXISTS duration_seconds;
+ALTER TABLE live_sessions DROP COLUMN IF EXISTS peak_viewer_count;
@@ -0,0 +1,5 @@
+-- Post-stream analytics
+-- peak_viewer_count: highest concurrent viewer_count seen (raised on each on_play)
+-- duration_seconds: ended_at - started_at, computed on on_unpublish
+ALTER TABLE live_sessions ADD COLUMN IF NOT EXISTS peak_viewer_count INTEGER NOT NULL DEFAULT 0;
+ALTER TABLE live_sessions ADD COLUMN IF NOT EXISTS duration_seconds INTEGER;
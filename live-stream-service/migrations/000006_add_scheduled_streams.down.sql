@@ -0,0 +1,7 @@
+-- Drop scheduled streams support
+-- Postgres cannot drop enum values; fold the new statuses back into ENDED instead
+DROP INDEX IF EXISTS idx_live_sessions_scheduled;
+
+UPDATE live_sessions SET status = 'ENDED' WHERE status::text IN ('SCHEDULED', 'EXPIRED');
+
+ALTER TABLE live_sessions DROP COLUMN IF EXISTS scheduled_start_at;
@@ -0,0 +1,11 @@
+-- Scheduled streams
+-- SCHEDULED: announced for scheduled_start_at, goes LIVE on first publish
+-- EXPIRED: nobody published within the grace period after scheduled_start_at
+ALTER TYPE session_status ADD VALUE IF NOT EXISTS 'SCHEDULED';
+ALTER TYPE session_status ADD VALUE IF NOT EXISTS 'EXPIRED';
+
+ALTER TABLE live_sessions ADD COLUMN IF NOT EXISTS scheduled_start_at TIMESTAMP WITH TIME ZONE;
+
+-- Upcoming feed (status = SCHEDULED ORDER BY scheduled_start_at) and the expiry sweeper
+CREATE INDEX IF NOT EXISTS idx_live_sessions_scheduled ON live_sessions(status, scheduled_start_at, id)
+    WHERE scheduled_start_at IS NOT NULL;
@@ -0,0 +1,2 @@
+-- Drop banned users table
+DROP TABLE IF EXISTS banned_users;
@@ -0,0 +1,8 @@
+-- Moderation: users banned from going live
+-- Checked on every on_publish, so user_id is the primary key (single index lookup)
+CREATE TABLE IF NOT EXISTS banned_users (
+    -- UUID from upstream user service
+    user_id VARCHAR(36) PRIMARY KEY,
+    reason TEXT,
+    banned_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
@@ -0,0 +1,2 @@
+-- Drop publisher client id
+ALTER TABLE live_sessions DROP COLUMN IF EXISTS publisher_client_id;
@@ -0,0 +1,3 @@
+-- SRS client_id of the current publisher
+-- SRS retries on_publish on timeout; a retry from the same client_id is accepted instead of rejected as a duplicate
+ALTER TABLE live_sessions ADD COLUMN IF NOT EXISTS publisher_client_id VARCHAR(64);
@@ -0,0 +1,2 @@
+-- Drop title search index
+DROP INDEX IF EXISTS idx_live_sessions_title_search;
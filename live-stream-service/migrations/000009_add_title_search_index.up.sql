@@ -0,0 +1,3 @@
+-- Full-text index for stream title search (GET /api/v1/live/search)
+-- 'simple' config: no stemming or stop words, titles are short and often not English
+CREATE INDEX IF NOT EXISTS idx_live_sessions_title_search ON live_sessions USING GIN (to_tsvector('simple', title));
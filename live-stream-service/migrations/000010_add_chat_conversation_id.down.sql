@@ -0,0 +1,2 @@
+-- Drop chat conversation id
+ALTER TABLE live_sessions DROP COLUMN IF EXISTS chat_conversation_id;
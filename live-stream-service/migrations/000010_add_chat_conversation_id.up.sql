@@ -0,0 +1,3 @@
+-- chat-service conversation backing the stream's live chat
+-- NULL when chat-service was unavailable at go-live (chat unavailable for this stream)
+ALTER TABLE live_sessions ADD COLUMN IF NOT EXISTS chat_conversation_id VARCHAR(36);
@@ -0,0 +1,2 @@
+-- Drop stream bans
+DROP TABLE IF EXISTS stream_bans;
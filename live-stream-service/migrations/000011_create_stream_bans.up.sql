@@ -0,0 +1,9 @@
+-- Viewers banned from a single stream by its owner
+-- Banned viewers are kicked from the stream chat and can't reconnect
+CREATE TABLE IF NOT EXISTS stream_bans (
+    stream_id VARCHAR(21) NOT NULL REFERENCES live_sessions(id) ON DELETE CASCADE,
+    user_id VARCHAR(36) NOT NULL,
+    reason TEXT,
+    banned_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (stream_id, user_id)
+);
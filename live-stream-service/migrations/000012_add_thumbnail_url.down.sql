@@ -0,0 +1,2 @@
+-- Drop thumbnail url
+ALTER TABLE live_sessions DROP COLUMN IF EXISTS thumbnail_url;
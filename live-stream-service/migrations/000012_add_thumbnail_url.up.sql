@@ -0,0 +1,3 @@
+-- Snapshot of the stream shown in the feed
+-- Set on the first HLS segment after go-live; NULL until then (API falls back to a placeholder)
+ALTER TABLE live_sessions ADD COLUMN IF NOT EXISTS thumbnail_url VARCHAR(500);
@@ -0,0 +1,2 @@
+-- Drop stream outbox
+DROP TABLE IF EXISTS stream_outbox;
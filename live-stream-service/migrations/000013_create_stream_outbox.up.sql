@@ -0,0 +1,21 @@
+-- Transactional outbox for stream lifecycle events (stream.started / stream.ended)
+-- Rows are written in the same statement as the status change and relayed to downstream services
+-- (notifications, feed ranking) by the event relay, so an event is never lost or sent for a rolled back change
+CREATE TABLE IF NOT EXISTS stream_outbox (
+    -- Insertion order, events leave in this order
+    id BIGSERIAL PRIMARY KEY,
+    -- Stable id for consumers to deduplicate redeliveries
+    event_id UUID NOT NULL DEFAULT gen_random_uuid(),
+    event_type VARCHAR(50) NOT NULL,
+    -- No foreign key: events of a deleted stream are still delivered
+    stream_id VARCHAR(21) NOT NULL,
+    payload JSONB NOT NULL,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    published_at TIMESTAMP WITH TIME ZONE,
+    retry_count INTEGER NOT NULL DEFAULT 0,
+    last_retry_at TIMESTAMP WITH TIME ZONE,
+    last_error TEXT
+);
+
+CREATE INDEX IF NOT EXISTS idx_stream_outbox_pending ON stream_outbox(id) WHERE published_at IS NULL;
+CREATE INDEX IF NOT EXISTS idx_stream_outbox_stream_pending ON stream_outbox(stream_id, id) WHERE published_at IS NULL;
@@ -0,0 +1,2 @@
+-- Drop stream co-hosts
+DROP TABLE IF EXISTS stream_cohosts;
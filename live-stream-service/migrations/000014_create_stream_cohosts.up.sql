@@ -0,0 +1,9 @@
+-- Users the stream owner allowed to publish to their stream
+-- Each co-host publishes with their own token, so revoking one co-host never touches the owner's stream key
+CREATE TABLE IF NOT EXISTS stream_cohosts (
+    stream_id VARCHAR(21) NOT NULL REFERENCES live_sessions(id) ON DELETE CASCADE,
+    user_id VARCHAR(36) NOT NULL,
+    token VARCHAR(255) NOT NULL,
+    granted_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (stream_id, user_id)
+);
@@ -0,0 +1,2 @@
+-- Drop stream recordings
+DROP TABLE IF EXISTS stream_recordings;
@@ -0,0 +1,10 @@
+-- DVR files of a stream, reported by the SRS on_dvr callback as each file is closed
+-- A session can have several files (segmented DVR, or one per reconnect); they play back in started_at order
+CREATE TABLE IF NOT EXISTS stream_recordings (
+    stream_id VARCHAR(21) NOT NULL REFERENCES live_sessions(id) ON DELETE CASCADE,
+    file_path VARCHAR(500) NOT NULL, -- Relative to the recording dir, which the CDN serves
+    started_at TIMESTAMP WITH TIME ZONE NOT NULL,
+    duration_ms BIGINT NOT NULL,
+    recorded_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (stream_id, file_path)
+);
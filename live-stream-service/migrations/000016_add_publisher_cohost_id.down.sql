@@ -0,0 +1,2 @@
+-- Drop publisher cohost id
+ALTER TABLE live_sessions DROP COLUMN IF EXISTS publisher_cohost_id;
@@ -0,0 +1,3 @@
+-- Co-host publishing the stream, NULL while the owner publishes
+-- Banning a co-host from the stream uses it to cut their running publish
+ALTER TABLE live_sessions ADD COLUMN IF NOT EXISTS publisher_cohost_id VARCHAR(36);
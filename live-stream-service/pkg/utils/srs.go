@@ -3,8 +3,14 @@ package utils
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"net/http"
+	"net/url"
+	"path"
+	"strconv"
+	"strings"
+	"sync"
 	"time"
 )
 
@@ -95,6 +101,18 @@ func (c *CDNURLBuilder) BuildThumbnailURL(streamKey string) string {
 	return fmt.Sprintf("%s/live/%s.jpg", c.BaseURL, streamKey)
 }
 
+// ParseDVRStartTime reads the start of a DVR file from its name
+// srs.conf names DVR files [timestamp].mp4, the Unix time in milliseconds when SRS opened the file
+func ParseDVRStartTime(file string) (time.Time, bool) {
+	name := path.Base(file)
+	name = strings.TrimSuffix(name, path.Ext(name))
+	ms, err := strconv.ParseInt(name, 10, 64)
+	if err != nil || ms <= 0 {
+		return time.Time{}, false
+	}
+	return time.UnixMilli(ms), true
+}
+
 // SRSHealthChecker provides health check functionality for SRS server
 type SRSHealthChecker struct {
 	apiURL     string
@@ -133,6 +151,48 @@ type SRSSummaryResponse struct {
 	} `json:"data"`
 }
 
+// SRSStream represents a single stream from SRS /api/v1/streams endpoint
+type SRSStream struct {
+	ID        string `json:"id"`
+	Name      string `json:"name"`
+	Vhost     string `json:"vhost"`
+	App       string `json:"app"`
+	Clients   int    `json:"clients"`
+	Frames    int64  `json:"frames"`
+	SendBytes int64  `json:"send_bytes"`
+	RecvBytes int64  `json:"recv_bytes"`
+	Kbps      struct {
+		Recv30s int `json:"recv_30s"`
+		Send30s int `json:"send_30s"`
+	} `json:"kbps"`
+	Publish struct {
+		Active bool   `json:"active"`
+		CID    string `json:"cid"`
+	} `json:"publish"`
+	Video *struct {
+		Codec   string `json:"codec"`
+		Profile string `json:"profile"`
+		Level   string `json:"level"`
+		Width   int    `json:"width"`
+		Height  int    `json:"height"`
+	} `json:"video"`
+	Audio *struct {
+		Codec      string `json:"codec"`
+		SampleRate int    `json:"sample_rate"`
+		Channel    int    `json:"channel"`
+	} `json:"audio"`
+}
+
+// SRSStreamsResponse represents the response from SRS /api/v1/streams endpoint
+type SRSStreamsResponse struct {
+	Code    int         `json:"code"`
+	Server  string      `json:"server"`
+	Streams []SRSStream `json:"streams"`
+}
+
+// ErrSRSStreamNotFound indicates SRS is not currently serving the stream
+var ErrSRSStreamNotFound = errors.New("stream not found on SRS")
+
 // NewSRSHealthChecker creates a new health checker
 func NewSRSHealthChecker(serverIP string, apiPort int) *SRSHealthChecker {
 	return &SRSHealthChecker{
@@ -208,7 +268,186 @@ func (h *SRSHealthChecker) GetSummary(ctx context.Context) (*SRSSummaryResponse,
 	return &summary, nil
 }
 
+// GetStream retrieves a single stream by app and name from the SRS streams API
+// SRS has no lookup by name, so the stream list is fetched and filtered
+func (h *SRSHealthChecker) GetStream(ctx context.Context, app, name string) (*SRSStream, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, h.apiURL+"/api/v1/streams?count=1000", nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+
+	resp, err := h.httpClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("SRS server unreachable: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("SRS server returned status %d", resp.StatusCode)
+	}
+
+	var streams SRSStreamsResponse
+	if err := json.NewDecoder(resp.Body).Decode(&streams); err != nil {
+		return nil, fmt.Errorf("failed to decode response: %w", err)
+	}
+
+	if streams.Code != 0 {
+		return nil, fmt.Errorf("SRS returned error code: %d", streams.Code)
+	}
+
+	for i := range streams.Streams {
+		if streams.Streams[i].App == app && streams.Streams[i].Name == name {
+			return &streams.Streams[i], nil
+		}
+	}
+
+	return nil, ErrSRSStreamNotFound
+}
+
+// KickClient disconnects an SRS client, e.g. a publisher that lost its publish rights
+// Kicking a publisher makes SRS fire on_unpublish, which ends the stream as usual
+func (h *SRSHealthChecker) KickClient(ctx context.Context, clientID string) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodDelete, h.apiURL+"/api/v1/clients/"+url.PathEscape(clientID), nil)
+	if err != nil {
+		return fmt.Errorf("failed to create request: %w", err)
+	}
+
+	resp, err := h.httpClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("SRS server unreachable: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("SRS server returned status %d", resp.StatusCode)
+	}
+
+	var result struct {
+		Code int `json:"code"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+		return fmt.Errorf("failed to decode response: %w", err)
+	}
+
+	if result.Code != 0 {
+		return fmt.Errorf("SRS returned error code: %d", result.Code)
+	}
+
+	return nil
+}
+
 // IsAlive is a simple check if SRS is responding
 func (h *SRSHealthChecker) IsAlive(ctx context.Context) bool {
 	return h.CheckHealth(ctx) == nil
 }
+
+// StreamStats is a per-stream health snapshot derived from the SRS streams API
+type StreamStats struct {
+	BitrateKbps int
+	FPS         float64 // Derived from frame counter deltas between samples, 0 until two samples exist
+	VideoCodec  string
+	AudioCodec  string
+	Width       int
+	Height      int
+	Clients     int
+}
+
+// srsStreamGetter is the subset of SRSHealthChecker used by StreamStatsCache
+type srsStreamGetter interface {
+	GetStream(ctx context.Context, app, name string) (*SRSStream, error)
+}
+
+// statsEvictTTLs is how many TTLs an entry may go unrefreshed before it is evicted. Entries stay
+// past one TTL so the next sample can derive fps; only streams nobody asks about anymore (e.g.
+// ended ones, which are no longer sampled) reach this age.
+const statsEvictTTLs = 10
+
+type cachedStreamStats struct {
+	stats     StreamStats
+	frames    int64
+	sampledAt time.Time
+}
+
+// StreamStatsCache caches per-stream stats for a short TTL so detail requests don't hammer SRS
+type StreamStatsCache struct {
+	client  srsStreamGetter
+	ttl     time.Duration
+	mu      sync.Mutex
+	entries map[string]*cachedStreamStats
+	now     func() time.Time
+}
+
+// NewStreamStatsCache creates a stats cache backed by the SRS streams API
+func NewStreamStatsCache(client *SRSHealthChecker, ttl time.Duration) *StreamStatsCache {
+	return newStreamStatsCache(client, ttl)
+}
+
+func newStreamStatsCache(client srsStreamGetter, ttl time.Duration) *StreamStatsCache {
+	return &StreamStatsCache{
+		client:  client,
+		ttl:     ttl,
+		entries: make(map[string]*cachedStreamStats),
+		now:     time.Now,
+	}
+}
+
+// Get returns cached stats for app/name, refreshing from SRS when the entry is older than ttl
+func (c *StreamStatsCache) Get(ctx context.Context, app, name string) (*StreamStats, error) {
+	key := app + "/" + name
+	now := c.now()
+
+	c.mu.Lock()
+	prev := c.entries[key]
+	if prev != nil && now.Sub(prev.sampledAt) < c.ttl {
+		stats := prev.stats
+		c.mu.Unlock()
+		return &stats, nil
+	}
+	c.mu.Unlock()
+
+	stream, err := c.client.GetStream(ctx, app, name)
+	if err != nil {
+		if errors.Is(err, ErrSRSStreamNotFound) {
+			c.mu.Lock()
+			delete(c.entries, key)
+			c.mu.Unlock()
+		}
+		return nil, err
+	}
+
+	stats := StreamStats{
+		BitrateKbps: stream.Kbps.Recv30s,
+		Clients:     stream.Clients,
+	}
+	if stream.Video != nil {
+		stats.VideoCodec = stream.Video.Codec
+		stats.Width = stream.Video.Width
+		stats.Height = stream.Video.Height
+	}
+	if stream.Audio != nil {
+		stats.AudioCodec = stream.Audio.Codec
+	}
+	// SRS only exposes a total frame counter; fps is the rate between two samples
+	if prev != nil {
+		elapsed := now.Sub(prev.sampledAt).Seconds()
+		if elapsed > 0 && stream.Frames >= prev.frames {
+			stats.FPS = float64(stream.Frames-prev.frames) / elapsed
+		}
+	}
+
+	c.mu.Lock()
+	c.evictStale(now)
+	c.entries[key] = &cachedStreamStats{stats: stats, frames: stream.Frames, sampledAt: now}
+	c.mu.Unlock()
+
+	return &stats, nil
+}
+
+// evictStale drops entries not refreshed for statsEvictTTLs TTLs; c.mu must be held
+func (c *StreamStatsCache) evictStale(now time.Time) {
+	for key, entry := range c.entries {
+		if now.Sub(entry.sampledAt) >= statsEvictTTLs*c.ttl {
+			delete(c.entries, key)
+		}
+	}
+}
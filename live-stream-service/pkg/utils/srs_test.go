@@ -0,0 +1,137 @@
+package utils
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+type fakeSRSStreams struct {
+	stream *SRSStream
+	err    error
+	calls  int
+}
+
+func (f *fakeSRSStreams) GetStream(ctx context.Context, app, name string) (*SRSStream, error) {
+	f.calls++
+	if f.err != nil {
+		return nil, f.err
+	}
+	s := *f.stream
+	return &s, nil
+}
+
+func TestStreamStatsCache_ReusesWithinTTL(t *testing.T) {
+	fake := &fakeSRSStreams{stream: &SRSStream{App: "live", Name: "abc", Frames: 100}}
+	fake.stream.Kbps.Recv30s = 2500
+	cache := newStreamStatsCache(fake, 5*time.Second)
+	now := time.Unix(1700000000, 0)
+	cache.now = func() time.Time { return now }
+
+	stats, err := cache.Get(context.Background(), "live", "abc")
+	if err != nil {
+		t.Fatalf("Get: %v", err)
+	}
+	if stats.BitrateKbps != 2500 || stats.FPS != 0 {
+		t.Fatalf("unexpected first sample: %+v", stats)
+	}
+
+	now = now.Add(2 * time.Second)
+	if _, err := cache.Get(context.Background(), "live", "abc"); err != nil {
+		t.Fatalf("Get: %v", err)
+	}
+	if fake.calls != 1 {
+		t.Fatalf("expected cached result within TTL, SRS called %d times", fake.calls)
+	}
+
+	// After the TTL a new sample is taken and fps derived from the frame delta
+	now = now.Add(8 * time.Second)
+	fake.stream.Frames = 400
+	stats, err = cache.Get(context.Background(), "live", "abc")
+	if err != nil {
+		t.Fatalf("Get: %v", err)
+	}
+	if fake.calls != 2 {
+		t.Fatalf("expected refresh after TTL, SRS called %d times", fake.calls)
+	}
+	if stats.FPS != 30 {
+		t.Fatalf("expected 30 fps, got %v", stats.FPS)
+	}
+}
+
+func TestStreamStatsCache_EvictsStaleEntries(t *testing.T) {
+	fake := &fakeSRSStreams{stream: &SRSStream{App: "live", Name: "abc"}}
+	cache := newStreamStatsCache(fake, 5*time.Second)
+	now := time.Unix(1700000000, 0)
+	cache.now = func() time.Time { return now }
+
+	if _, err := cache.Get(context.Background(), "live", "ended"); err != nil {
+		t.Fatalf("Get: %v", err)
+	}
+	now = now.Add(30 * time.Second)
+	if _, err := cache.Get(context.Background(), "live", "live"); err != nil {
+		t.Fatalf("Get: %v", err)
+	}
+	if len(cache.entries) != 2 {
+		t.Fatalf("expected entries younger than %d TTLs to be kept, got %d", statsEvictTTLs, len(cache.entries))
+	}
+
+	// The ended stream is never sampled again; the next refresh of another stream evicts it
+	now = now.Add(30 * time.Second)
+	if _, err := cache.Get(context.Background(), "live", "live"); err != nil {
+		t.Fatalf("Get: %v", err)
+	}
+	if _, ok := cache.entries["live/ended"]; ok || len(cache.entries) != 1 {
+		t.Fatalf("expected only live/live to remain, got %v", cache.entries)
+	}
+}
+
+func TestStreamStatsCache_PropagatesErrors(t *testing.T) {
+	fake := &fakeSRSStreams{err: ErrSRSStreamNotFound}
+	cache := newStreamStatsCache(fake, 5*time.Second)
+
+	if _, err := cache.Get(context.Background(), "live", "missing"); !errors.Is(err, ErrSRSStreamNotFound) {
+		t.Fatalf("expected ErrSRSStreamNotFound, got %v", err)
+	}
+}
+
+func TestSRSHealthChecker_KickClient(t *testing.T) {
+	var method, path string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		method, path = r.Method, r.URL.Path
+		if path == "/api/v1/clients/gone" {
+			w.Write([]byte(`{"code":2049}`))
+			return
+		}
+		w.Write([]byte(`{"code":0}`))
+	}))
+	defer srv.Close()
+	h := &SRSHealthChecker{apiURL: srv.URL, httpClient: srv.Client()}
+
+	if err := h.KickClient(context.Background(), "7h2k9q1z"); err != nil {
+		t.Fatalf("expected kick to succeed, got %v", err)
+	}
+	if method != http.MethodDelete || path != "/api/v1/clients/7h2k9q1z" {
+		t.Fatalf("expected DELETE /api/v1/clients/7h2k9q1z, got %s %s", method, path)
+	}
+
+	if err := h.KickClient(context.Background(), "gone"); err == nil {
+		t.Fatal("expected an error for an SRS error code")
+	}
+}
+
+func TestParseDVRStartTime(t *testing.T) {
+	started, ok := ParseDVRStartTime("recordings/live/abc/1705314600000.mp4")
+	if !ok || !started.Equal(time.UnixMilli(1705314600000)) {
+		t.Fatalf("expected 1705314600000ms, got %v (ok=%v)", started, ok)
+	}
+
+	for _, file := range []string{"recordings/live/abc/abc.mp4", "recordings/live/abc/.mp4", "0.flv", ""} {
+		if _, ok := ParseDVRStartTime(file); ok {
+			t.Fatalf("expected no start time in %q", file)
+		}
+	}
+}
@@ -2,9 +2,11 @@ package utils
 
 import (
 	"crypto/rand"
+	"crypto/subtle"
 	"encoding/hex"
 	"errors"
 	"fmt"
+	"math"
 	"regexp"
 	"strings"
 )
@@ -18,6 +20,21 @@ const (
 
 	// MinKeyLength is the minimum number of random bytes (16 hex chars = 128 bits entropy)
 	MinKeyLength = 8
+
+	// DefaultStreamKeyCharset is the alphabet of generated keys (base62, safe in RTMP/WebRTC URLs)
+	DefaultStreamKeyCharset = "abcdefghijklmnopqrstuvwxyzABCDEFGHIJKLMNOPQRSTUVWXYZ0123456789"
+
+	// DefaultStreamKeyLength is the number of random characters (43 base62 chars = 256 bits entropy)
+	DefaultStreamKeyLength = 43
+
+	// MaxStreamKeyLength bounds the random part so keys fit live_sessions.stream_key (VARCHAR 255)
+	MaxStreamKeyLength = 200
+
+	// MinStreamKeyEntropyBits is the least entropy a generator may be configured with
+	MinStreamKeyEntropyBits = 128
+
+	// urlSafeKeyChars are the characters allowed in a charset (RFC 3986 unreserved, no escaping in ?token=)
+	urlSafeKeyChars = "abcdefghijklmnopqrstuvwxyzABCDEFGHIJKLMNOPQRSTUVWXYZ0123456789-._~"
 )
 
 var (
@@ -27,13 +44,124 @@ var (
 	// ErrInsufficientEntropy indicates not enough random bytes were requested
 	ErrInsufficientEntropy = errors.New("insufficient entropy: minimum 8 bytes required")
 
+	// ErrInvalidKeyConfig indicates a stream key generator configuration is unusable
+	ErrInvalidKeyConfig = errors.New("invalid stream key configuration")
+
 	// streamKeyPattern matches the format: live_u{userID}_{hex} (legacy int64 userID)
 	streamKeyPattern = regexp.MustCompile(`^live_u(\d+)_([a-f0-9]{16,64})$`)
 
 	// streamKeyUUIDPattern matches the format: live_{uuid}_{hex} (new UUID userID)
 	streamKeyUUIDPattern = regexp.MustCompile(`^live_([a-f0-9-]{36})_([a-f0-9]{16,64})$`)
+
+	// streamKeyPrefixPattern restricts prefixes to a short tenant/namespace label
+	streamKeyPrefixPattern = regexp.MustCompile(`^[A-Za-z0-9-]{0,32}$`)
 )
 
+// StreamKeyGenerator creates stream keys of the form {prefix}_{random} ({random} without a prefix)
+// The random part is drawn from crypto/rand without modulo bias, so every key has exactly EntropyBits of entropy
+// The prefix namespaces keys, e.g. per tenant on a shared SRS; it is not secret
+type StreamKeyGenerator struct {
+	prefix  string // including the "_" separator, empty without a prefix
+	charset string
+	allowed [256]bool
+	length  int
+}
+
+// NewStreamKeyGenerator creates a generator; an empty charset or non-positive length uses the default
+// Fails with ErrInvalidKeyConfig for prefixes outside [A-Za-z0-9-]{0,32}, charsets with characters that
+// need URL escaping or duplicates, and combinations below MinStreamKeyEntropyBits
+func NewStreamKeyGenerator(prefix, charset string, length int) (*StreamKeyGenerator, error) {
+	if charset == "" {
+		charset = DefaultStreamKeyCharset
+	}
+	if length <= 0 {
+		length = DefaultStreamKeyLength
+	}
+
+	if !streamKeyPrefixPattern.MatchString(prefix) {
+		return nil, fmt.Errorf("%w: prefix must be at most 32 of [A-Za-z0-9-]", ErrInvalidKeyConfig)
+	}
+	if length > MaxStreamKeyLength {
+		return nil, fmt.Errorf("%w: length must be at most %d", ErrInvalidKeyConfig, MaxStreamKeyLength)
+	}
+
+	g := &StreamKeyGenerator{charset: charset, length: length}
+	if prefix != "" {
+		g.prefix = prefix + "_"
+	}
+	for i := 0; i < len(charset); i++ {
+		c := charset[i]
+		if !strings.ContainsRune(urlSafeKeyChars, rune(c)) {
+			return nil, fmt.Errorf("%w: charset character %q is not URL safe", ErrInvalidKeyConfig, c)
+		}
+		if g.allowed[c] {
+			return nil, fmt.Errorf("%w: charset character %q is repeated", ErrInvalidKeyConfig, c)
+		}
+		g.allowed[c] = true
+	}
+
+	if bits := g.EntropyBits(); bits < MinStreamKeyEntropyBits {
+		return nil, fmt.Errorf("%w: %d characters of a %d character charset give %.0f bits of entropy, need %d",
+			ErrInvalidKeyConfig, length, len(charset), bits, MinStreamKeyEntropyBits)
+	}
+
+	return g, nil
+}
+
+// EntropyBits returns the entropy of the random part of generated keys
+func (g *StreamKeyGenerator) EntropyBits() float64 {
+	return float64(g.length) * math.Log2(float64(len(g.charset)))
+}
+
+// Generate returns a new random stream key
+func (g *StreamKeyGenerator) Generate() (string, error) {
+	n := len(g.charset)
+	// Bytes at or above limit are rejected: the rest map onto the charset evenly
+	limit := 256 - 256%n
+
+	key := make([]byte, 0, len(g.prefix)+g.length)
+	key = append(key, g.prefix...)
+
+	buf := make([]byte, g.length+g.length/2)
+	for len(key) < len(g.prefix)+g.length {
+		if _, err := rand.Read(buf); err != nil {
+			return "", fmt.Errorf("failed to generate random bytes: %w", err)
+		}
+		for _, b := range buf {
+			if int(b) >= limit {
+				continue
+			}
+			key = append(key, g.charset[int(b)%n])
+			if len(key) == len(g.prefix)+g.length {
+				break
+			}
+		}
+	}
+
+	return string(key), nil
+}
+
+// IsCurrent reports whether a key has the format this generator produces
+// Keys issued before the current configuration (legacy live_{uuid}_{hex} keys, another prefix or charset)
+// are not current; owners replace them by rotating the key
+func (g *StreamKeyGenerator) IsCurrent(streamKey string) bool {
+	if len(streamKey) != len(g.prefix)+g.length || !strings.HasPrefix(streamKey, g.prefix) {
+		return false
+	}
+	for i := len(g.prefix); i < len(streamKey); i++ {
+		if !g.allowed[streamKey[i]] {
+			return false
+		}
+	}
+	return true
+}
+
+// StreamKeysEqual compares a presented token with a stored stream key in constant time,
+// so response timing reveals nothing about how much of the key matched (only its length, which is not secret)
+func StreamKeysEqual(stored, presented string) bool {
+	return subtle.ConstantTimeCompare([]byte(stored), []byte(presented)) == 1
+}
+
 // GenerateStreamKeyFromUUID generates a secure random stream key with UUID userID
 // Format: live_{uuid}_{randomHex}
 func GenerateStreamKeyFromUUID(userID string) (string, error) {
@@ -0,0 +1,100 @@
+package utils
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestStreamKeyGenerator_Defaults(t *testing.T) {
+	g, err := NewStreamKeyGenerator("live", "", 0)
+	require.NoError(t, err)
+	assert.InDelta(t, 256, g.EntropyBits(), 1)
+
+	key, err := g.Generate()
+	require.NoError(t, err)
+	assert.True(t, strings.HasPrefix(key, "live_"))
+	assert.Len(t, key, len("live_")+DefaultStreamKeyLength)
+	assert.True(t, g.IsCurrent(key))
+
+	other, err := g.Generate()
+	require.NoError(t, err)
+	assert.NotEqual(t, key, other)
+}
+
+func TestStreamKeyGenerator_CustomFormat(t *testing.T) {
+	g, err := NewStreamKeyGenerator("tenant-a", "0123456789abcdef", 40)
+	require.NoError(t, err)
+
+	key, err := g.Generate()
+	require.NoError(t, err)
+	assert.Regexp(t, `^tenant-a_[0-9a-f]{40}$`, key)
+
+	// No prefix: the key is just the random part
+	g, err = NewStreamKeyGenerator("", "0123456789abcdef", 32)
+	require.NoError(t, err)
+	key, err = g.Generate()
+	require.NoError(t, err)
+	assert.Regexp(t, `^[0-9a-f]{32}$`, key)
+}
+
+func TestStreamKeyGenerator_UsesWholeCharset(t *testing.T) {
+	// 62 does not divide 256: rejection sampling must still reach every character
+	g, err := NewStreamKeyGenerator("", "", 200)
+	require.NoError(t, err)
+
+	seen := make(map[rune]bool)
+	for i := 0; i < 20; i++ {
+		key, err := g.Generate()
+		require.NoError(t, err)
+		for _, c := range key {
+			seen[c] = true
+		}
+	}
+	assert.Len(t, seen, len(DefaultStreamKeyCharset))
+}
+
+func TestStreamKeyGenerator_InvalidConfig(t *testing.T) {
+	tests := []struct {
+		name    string
+		prefix  string
+		charset string
+		length  int
+	}{
+		{name: "prefix with separator", prefix: "tenant_a"},
+		{name: "prefix too long", prefix: strings.Repeat("a", 33)},
+		{name: "charset needs escaping", charset: "abcdefghijklmnop&="},
+		{name: "repeated charset character", charset: "aabcdefghijklmnop"},
+		{name: "too little entropy", charset: "0123456789abcdef", length: 31},
+		{name: "too long", length: MaxStreamKeyLength + 1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := NewStreamKeyGenerator(tt.prefix, tt.charset, tt.length)
+			assert.True(t, errors.Is(err, ErrInvalidKeyConfig), "got %v", err)
+		})
+	}
+}
+
+func TestStreamKeyGenerator_IsCurrent(t *testing.T) {
+	g, err := NewStreamKeyGenerator("live", "0123456789abcdef", 32)
+	require.NoError(t, err)
+
+	assert.True(t, g.IsCurrent("live_0123456789abcdef0123456789abcdef"))
+	assert.False(t, g.IsCurrent("live_550e8400-e29b-41d4-a716-446655440000_0123456789abcdef"), "legacy format")
+	assert.False(t, g.IsCurrent("other_0123456789abcdef0123456789abcdef"), "another prefix")
+	assert.False(t, g.IsCurrent("live_0123456789abcdef0123456789abcdeX"), "outside the charset")
+	assert.False(t, g.IsCurrent("live_0123456789abcdef"), "too short")
+	assert.False(t, g.IsCurrent(""))
+}
+
+func TestStreamKeysEqual(t *testing.T) {
+	assert.True(t, StreamKeysEqual("live_abc", "live_abc"))
+	assert.False(t, StreamKeysEqual("live_abc", "live_abd"))
+	assert.False(t, StreamKeysEqual("live_abc", "live_ab"))
+	assert.False(t, StreamKeysEqual("live_abc", ""))
+}